@@ -0,0 +1,245 @@
+// Command doctor checks that a deployment is set up to run the server. It
+// reads the same environment, and .env file, as cmd/web and reports, as a
+// table:
+//
+//   - every problem with the configuration, not just the first
+//   - whether TOKEN_SECRET and ANSWERS_KEY are set and long enough
+//   - whether the database answers and its schema is at the version the
+//     binary expects
+//   - whether the SMTP relay and the blob store can be reached, when
+//     configured
+//
+// Sessions are kept in the database and CSRF is checked by the
+// Sec-Fetch-Site header, so neither has a secret of its own to check.
+//
+// It exits 1 if any check fails.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"firecrest/internal/config"
+	"firecrest/internal/database"
+	"firecrest/internal/mail"
+	"firecrest/internal/migrate"
+	"firecrest/internal/secret"
+	"firecrest/internal/storage"
+	"firecrest/internal/token"
+)
+
+// checkTimeout bounds each check that reaches out to another service.
+const checkTimeout = 10 * time.Second
+
+// probeKey is the blob written, read back and deleted to check the store.
+const probeKey = "doctor/probe"
+
+// result is the outcome of one check. A nil err is a pass.
+type result struct {
+	check  string
+	err    error
+	detail string
+}
+
+func main() {
+	// Load .env file in development
+	_ = godotenv.Load()
+
+	if !run(context.Background(), os.Stdout) {
+		os.Exit(1)
+	}
+}
+
+// run checks the deployment, writes the table of results to w and reports
+// whether every check passed.
+func run(ctx context.Context, w io.Writer) bool {
+	results := diagnose(ctx)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL")
+	ok := true
+	for _, r := range results {
+		status, detail := "PASS", r.detail
+		if r.err != nil {
+			ok = false
+			status, detail = "FAIL", r.err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.check, status, detail)
+	}
+	tw.Flush()
+	return ok
+}
+
+// diagnose runs every check. The configuration is used as far as it could
+// be read, so one bad setting does not hide problems further on.
+func diagnose(ctx context.Context) []result {
+	cfg, err := config.Read()
+	results := checkConfig(errors.Join(err, cfg.Validate()))
+	results = append(results, checkSecrets(cfg)...)
+	results = append(results, checkDatabase(ctx, cfg)...)
+	results = append(results, checkSMTP(ctx, cfg), checkStorage(ctx, cfg))
+	return results
+}
+
+// checkConfig lists each problem in err on a row of its own.
+func checkConfig(err error) []result {
+	if err == nil {
+		return []result{{check: "config", detail: "loaded"}}
+	}
+	var results []result
+	for _, problem := range problems(err) {
+		results = append(results, result{check: "config", err: problem})
+	}
+	return results
+}
+
+// problems flattens the errors joined in err.
+func problems(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, problems(e)...)
+	}
+	return errs
+}
+
+// checkSecrets checks the keys the server signs and encrypts with. In
+// development they may be left unset, as the server then makes up its own.
+func checkSecrets(cfg config.Config) []result {
+	tokenSecret := result{check: "token secret", detail: strconv.Itoa(len(cfg.TokenSecret)) + " characters"}
+	switch {
+	case cfg.TokenSecret == "" && cfg.IsDevelopment():
+		tokenSecret.detail = "not set, so a random key is used and emailed links break on restart"
+	case len(cfg.TokenSecret) < token.MinKeyLength:
+		tokenSecret.err = fmt.Errorf("TOKEN_SECRET must be at least %d characters, got %d", token.MinKeyLength, len(cfg.TokenSecret))
+	}
+
+	answersKey := result{check: "answers key", detail: strconv.Itoa(len(cfg.AnswersKey)) + " bytes"}
+	switch {
+	case cfg.AnswersKey == nil && cfg.IsDevelopment():
+		answersKey.detail = "not set, so a random key is used and answers saved before a restart cannot be read"
+	case len(cfg.AnswersKey) != secret.KeyLength:
+		answersKey.err = fmt.Errorf("ANSWERS_KEY must be %d bytes, base64-encoded, got %d", secret.KeyLength, len(cfg.AnswersKey))
+	}
+	return []result{tokenSecret, answersKey}
+}
+
+// checkDatabase connects to the database, once, and compares its schema
+// version with the migrations the binary carries.
+func checkDatabase(ctx context.Context, cfg config.Config) []result {
+	dbCfg := cfg.DB
+	dbCfg.ConnectRetries = 0
+	dbCfg.MaxConns, dbCfg.MinConns = 1, 0
+	if dbCfg.ConnectTimeout <= 0 || dbCfg.ConnectTimeout > checkTimeout {
+		dbCfg.ConnectTimeout = checkTimeout
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	pool, err := database.Connect(ctx, dbCfg, logger, nil)
+	if err != nil {
+		return []result{
+			{check: "database", err: err},
+			{check: "migrations", err: errors.New("not checked without a database connection")},
+		}
+	}
+	defer pool.Close()
+	results := []result{{check: "database", detail: net.JoinHostPort(dbCfg.Host, dbCfg.Port) + "/" + dbCfg.Name}}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	current, latest, err := migrate.Status(ctx, pool)
+	migrations := result{check: "migrations", detail: fmt.Sprintf("at version %d", current)}
+	switch {
+	case err != nil:
+		migrations.err = err
+	case current > latest:
+		migrations.err = fmt.Errorf("database is at version %d, newer than this binary's %d", current, latest)
+	case current < latest && cfg.DBAutoMigrate:
+		migrations.detail = fmt.Sprintf("at version %d of %d, the rest applied at startup by DB_AUTO_MIGRATE", current, latest)
+	case current < latest:
+		migrations.err = fmt.Errorf("database is at version %d, this binary expects %d", current, latest)
+	}
+	return append(results, migrations)
+}
+
+// checkSMTP signs in to the SMTP relay, if there is one.
+func checkSMTP(ctx context.Context, cfg config.Config) result {
+	if cfg.SMTP.Host == "" {
+		return result{check: "smtp", detail: "not configured, so emails are logged"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(cfg.SMTP.Host, strconv.Itoa(cfg.SMTP.Port))
+	if err := mail.NewSMTPMailer(cfg.SMTP).Check(ctx); err != nil {
+		return result{check: "smtp", err: fmt.Errorf("%s: %w", addr, err)}
+	}
+	return result{check: "smtp", detail: addr}
+}
+
+// checkStorage writes a blob to the store, reads it back and deletes it.
+func checkStorage(ctx context.Context, cfg config.Config) result {
+	var store storage.BlobStore
+	var detail string
+	var err error
+	switch cfg.Storage.Driver {
+	case config.StorageLocal:
+		// The signing key only matters for URLs, which are not checked
+		key := make([]byte, token.MinKeyLength)
+		rand.Read(key)
+		store, err = storage.NewLocalStore(cfg.Storage.Dir, "/media", key)
+		detail = "local directory " + cfg.Storage.Dir
+	case config.StorageS3:
+		store, err = storage.NewS3Store(cfg.Storage.S3, &http.Client{Timeout: checkTimeout})
+		detail = "s3 bucket " + cfg.Storage.S3.Bucket + " at " + cfg.Storage.S3.Endpoint
+	default:
+		err = fmt.Errorf("unknown driver %q", cfg.Storage.Driver)
+	}
+	if err != nil {
+		return result{check: "storage", err: err}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	if err := probe(ctx, store); err != nil {
+		return result{check: "storage", err: fmt.Errorf("%s: %w", detail, err)}
+	}
+	return result{check: "storage", detail: detail}
+}
+
+// probe round-trips a blob through store.
+func probe(ctx context.Context, store storage.BlobStore) error {
+	const content = "firecrest doctor"
+	if err := store.Put(ctx, probeKey, strings.NewReader(content), "text/plain"); err != nil {
+		return err
+	}
+	rc, err := store.Get(ctx, probeKey)
+	if err != nil {
+		return err
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+	if string(got) != content {
+		return errors.New("read back a different blob than was written")
+	}
+	return store.Delete(ctx, probeKey)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// closedPort returns a local port nothing listens on.
+func closedPort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	l.Close()
+	return port
+}
+
+func TestRun(t *testing.T) {
+	t.Run("lists every problem with a broken deployment", func(t *testing.T) {
+		port := closedPort(t)
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("BASE_URL", "/events")
+		t.Setenv("DB_HOST", "127.0.0.1")
+		t.Setenv("DB_PORT", port)
+		t.Setenv("DB_MAX_CONNS", "many")
+		t.Setenv("DB_CONNECT_TIMEOUT", "1s")
+		t.Setenv("SMTP_HOST", "127.0.0.1")
+		t.Setenv("SMTP_PORT", port)
+		t.Setenv("TOKEN_SECRET", "short")
+		t.Setenv("ANSWERS_KEY", "not base64!")
+		t.Setenv("STORAGE_DRIVER", "s3")
+		t.Setenv("S3_ENDPOINT", "http://127.0.0.1:"+port)
+		t.Setenv("S3_REGION", "eu-west-2")
+		t.Setenv("S3_BUCKET", "firecrest")
+		t.Setenv("S3_ACCESS_KEY_ID", "")
+		t.Setenv("S3_SECRET_ACCESS_KEY", "")
+
+		var out bytes.Buffer
+		if run(context.Background(), &out) {
+			t.Fatalf("expected a failure, got\n%s", out.String())
+		}
+
+		for _, want := range []string{
+			"invalid DB_MAX_CONNS",
+			"invalid ANSWERS_KEY",
+			"BASE_URL must be an absolute URL",
+			"TOKEN_SECRET must be at least 32 characters",
+			"ANSWERS_KEY must be 32 bytes",
+			"S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required",
+		} {
+			if !hasRow(out.String(), "config", "FAIL", want) {
+				t.Errorf("expected a failed config row mentioning %q, got\n%s", want, out.String())
+			}
+		}
+		for _, row := range [][2]string{
+			{"token secret", "got 5"},
+			{"answers key", "got 0"},
+			{"database", "unreachable"},
+			{"migrations", "not checked"},
+			{"smtp", "127.0.0.1:" + port},
+			{"storage", "S3 region, bucket and credentials are required"},
+		} {
+			if !hasRow(out.String(), row[0], "FAIL", row[1]) {
+				t.Errorf("expected a failed %s row mentioning %q, got\n%s", row[0], row[1], out.String())
+			}
+		}
+	})
+
+	t.Run("passes a local store it can write to", func(t *testing.T) {
+		t.Setenv("DB_PORT", closedPort(t))
+		t.Setenv("DB_CONNECT_TIMEOUT", "1s")
+		t.Setenv("STORAGE_DRIVER", "local")
+		t.Setenv("STORAGE_DIR", t.TempDir())
+
+		var out bytes.Buffer
+		run(context.Background(), &out)
+
+		if !hasRow(out.String(), "storage", "PASS", "local directory") {
+			t.Errorf("expected the storage check to pass, got\n%s", out.String())
+		}
+		if !hasRow(out.String(), "smtp", "PASS", "not configured") {
+			t.Errorf("expected the smtp check to pass without a relay, got\n%s", out.String())
+		}
+	})
+}
+
+// hasRow reports whether the table in out has a row for check with the
+// status and a detail containing detail.
+func hasRow(out, check, status, detail string) bool {
+	for line := range strings.Lines(out) {
+		rest, ok := strings.CutPrefix(line, check+" ")
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		if strings.HasPrefix(rest, status) && strings.Contains(rest, detail) {
+			return true
+		}
+	}
+	return false
+}
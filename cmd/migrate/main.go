@@ -0,0 +1,146 @@
+// Command migrate applies and rolls back the database schema. It reads the
+// same environment, and .env file, as cmd/web, and takes one of:
+//
+//	up        apply every pending migration
+//	down N    roll back the N most recently applied migrations
+//	status    print the database's schema version and the latest
+//
+// Production deployments run "migrate up" before starting the new server,
+// which refuses to start on a schema it does not expect. It exits 1 if the
+// command fails and 2 if it is not understood.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+
+	"firecrest/internal/config"
+	"firecrest/internal/database"
+	"firecrest/internal/migrate"
+)
+
+const usage = `usage: migrate up | down N | status`
+
+// command is a subcommand ready to run against the database, with what it
+// prints written to w.
+type command func(ctx context.Context, pool *pgxpool.Pool, w io.Writer) error
+
+func main() {
+	// Load .env file in development
+	_ = godotenv.Load()
+
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run parses and runs the subcommand in args and returns the exit status.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	cmd, err := parse(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n%s\n", err, usage)
+		return 2
+	}
+
+	cfg, err := config.Read()
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid configuration: %v\n", err)
+		return 1
+	}
+	logger := slog.New(slog.NewTextHandler(stderr, nil))
+	pool, err := database.Connect(ctx, cfg.DB, logger, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer pool.Close()
+
+	if err := cmd(ctx, pool, stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// parse returns the command args ask for.
+func parse(args []string) (command, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no command given")
+	}
+	switch name, rest := args[0], args[1:]; {
+	case name == "up" && len(rest) == 0:
+		return up, nil
+	case name == "status" && len(rest) == 0:
+		return status, nil
+	case name == "down" && len(rest) == 1:
+		n, err := strconv.Atoi(rest[0])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("down takes a number of migrations of at least 1, got %q", rest[0])
+		}
+		return down(n), nil
+	case name == "up" || name == "status" || name == "down":
+		return nil, fmt.Errorf("wrong number of arguments to %s", name)
+	default:
+		return nil, fmt.Errorf("unknown command %q", name)
+	}
+}
+
+func up(ctx context.Context, pool *pgxpool.Pool, w io.Writer) error {
+	from, _, err := migrate.Status(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if err := migrate.Up(ctx, pool); err != nil {
+		return err
+	}
+	return report(ctx, pool, w, from)
+}
+
+func down(n int) command {
+	return func(ctx context.Context, pool *pgxpool.Pool, w io.Writer) error {
+		from, _, err := migrate.Status(ctx, pool)
+		if err != nil {
+			return err
+		}
+		if err := migrate.Down(ctx, pool, n); err != nil {
+			return err
+		}
+		return report(ctx, pool, w, from)
+	}
+}
+
+// report prints the version a change took the database from and to.
+func report(ctx context.Context, pool *pgxpool.Pool, w io.Writer, from int) error {
+	to, _, err := migrate.Status(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if to == from {
+		fmt.Fprintf(w, "already at version %d\n", to)
+		return nil
+	}
+	fmt.Fprintf(w, "migrated from version %d to %d\n", from, to)
+	return nil
+}
+
+func status(ctx context.Context, pool *pgxpool.Pool, w io.Writer) error {
+	current, latest, err := migrate.Status(ctx, pool)
+	if err != nil {
+		return err
+	}
+	switch {
+	case current > latest:
+		fmt.Fprintf(w, "at version %d, newer than this binary's %d\n", current, latest)
+	case current < latest:
+		fmt.Fprintf(w, "at version %d of %d, %d pending\n", current, latest, latest-current)
+	default:
+		fmt.Fprintf(w, "at version %d, up to date\n", current)
+	}
+	return nil
+}
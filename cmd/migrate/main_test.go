@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "rejects no command", want: "no command given"},
+		{name: "rejects unknown commands", args: []string{"sideways"}, want: `unknown command "sideways"`},
+		{name: "rejects down without a count", args: []string{"down"}, want: "wrong number of arguments to down"},
+		{name: "rejects down by zero", args: []string{"down", "0"}, want: `got "0"`},
+		{name: "rejects down by a word", args: []string{"down", "all"}, want: `got "all"`},
+		{name: "rejects arguments to up", args: []string{"up", "1"}, want: "wrong number of arguments to up"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(context.Background(), tt.args, &stdout, &stderr); code != 2 {
+				t.Errorf("expected exit status 2, got %d", code)
+			}
+			if !strings.Contains(stderr.String(), tt.want) || !strings.Contains(stderr.String(), usage) {
+				t.Errorf("expected %q and the usage, got %q", tt.want, stderr.String())
+			}
+		})
+	}
+
+	t.Run("fails without a database", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen: %v", err)
+		}
+		port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+		l.Close()
+		t.Setenv("DB_HOST", "127.0.0.1")
+		t.Setenv("DB_PORT", port)
+		t.Setenv("DB_CONNECT_TIMEOUT", "1s")
+		t.Setenv("DB_CONNECT_RETRIES", "0")
+
+		var stdout, stderr bytes.Buffer
+		if code := run(context.Background(), []string{"status"}, &stdout, &stderr); code != 1 {
+			t.Errorf("expected exit status 1, got %d", code)
+		}
+		if !strings.Contains(stderr.String(), "failed to connect to database") {
+			t.Errorf("expected a connection error, got %q", stderr.String())
+		}
+	})
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/repository"
+	"firecrest/internal/service"
+	"firecrest/ui/viewmodels"
+)
+
+// apiListCacheControl is sent with list responses so clients and proxies can
+// reuse the catalogue for a short while.
+const apiListCacheControl = "public, max-age=60"
+
+// apiAvailabilityCacheControl lets browsers and proxies reuse an event's
+// availability for a minute, which is as fresh as the widget needs it.
+// Counts come from the registration counter's cache behind it.
+const apiAvailabilityCacheControl = "public, max-age=60"
+
+// apiPreflightMaxAge is how long, in seconds, browsers may remember that an
+// origin is allowed to read an event's availability.
+const apiPreflightMaxAge = "3600"
+
+type moneyDTO struct {
+	Amount   int32  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type eventDTO struct {
+	ID             int64   `json:"id"`
+	OrganisationID int64   `json:"organisationId"`
+	Name           string  `json:"name"`
+	Slug           string  `json:"slug"`
+	Year           int32   `json:"year"`
+	CreatedAt      *string `json:"createdAt"`
+	UpdatedAt      *string `json:"updatedAt"`
+}
+
+type eventDetailDTO struct {
+	eventDTO
+	Races []raceDTO `json:"races"`
+}
+
+type raceDTO struct {
+	ID                    int64     `json:"id"`
+	Slug                  string    `json:"slug"`
+	Name                  string    `json:"name"`
+	RegistrationOpenDate  *string   `json:"registrationOpenDate"`
+	RegistrationCloseDate *string   `json:"registrationCloseDate"`
+	Capacity              int32     `json:"capacity"`
+	Registered            int       `json:"registered"`
+	SpotsRemaining        int       `json:"spotsRemaining"`
+	Price                 *moneyDTO `json:"price"`
+}
+
+type entrantDTO struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	// Email is null once the entrant's account has been deleted.
+	Email        *string `json:"email"`
+	Deleted      bool    `json:"deleted"`
+	Status       string  `json:"status"`
+	Source       string  `json:"source"`
+	Bib          *string `json:"bib"`
+	Team         *string `json:"team"`
+	RegisteredAt *string `json:"registeredAt"`
+}
+
+// availabilityDTO is how full a race is, for the availability widget.
+// State is one of open, not-yet-open, sold-out and closed.
+type availabilityDTO struct {
+	Name       string `json:"name"`
+	Capacity   int32  `json:"capacity"`
+	Registered int    `json:"registered"`
+	State      string `json:"state"`
+}
+
+type availabilityResponse struct {
+	Data []availabilityDTO `json:"data"`
+}
+
+type entrantListResponse struct {
+	Data []entrantDTO `json:"data"`
+}
+
+type paginationDTO struct {
+	Page    int   `json:"page"`
+	PerPage int   `json:"perPage"`
+	Total   int64 `json:"total"`
+}
+
+type eventListResponse struct {
+	Data       []eventDTO    `json:"data"`
+	Pagination paginationDTO `json:"pagination"`
+}
+
+// formatTimestamp renders a timestamp as an RFC3339 UTC string, or nil when unset.
+func formatTimestamp(ts pgtype.Timestamptz) *string {
+	if !ts.Valid {
+		return nil
+	}
+	s := ts.Time.UTC().Format(time.RFC3339)
+	return &s
+}
+
+func newEventDTO(e db.Event) eventDTO {
+	return eventDTO{
+		ID:             e.ID,
+		OrganisationID: e.OrganisationID,
+		Name:           e.Name,
+		Slug:           e.Slug,
+		Year:           e.Year,
+		CreatedAt:      formatTimestamp(e.CreatedAt),
+		UpdatedAt:      formatTimestamp(e.UpdatedAt),
+	}
+}
+
+func newRaceDTO(a service.RaceAvailability) raceDTO {
+	dto := raceDTO{
+		ID:                    a.Race.ID,
+		Slug:                  a.Race.Slug,
+		Name:                  a.Race.Name,
+		RegistrationOpenDate:  formatTimestamp(a.Race.RegistrationOpenDate),
+		RegistrationCloseDate: formatTimestamp(a.Race.RegistrationCloseDate),
+		Capacity:              a.Race.MaxCapacity,
+		Registered:            a.Registered,
+		SpotsRemaining:        a.SpotsRemaining(),
+	}
+	if a.Race.PriceUnits.Valid {
+		dto.Price = &moneyDTO{
+			Amount:   a.Race.PriceUnits.Int32,
+			Currency: a.Race.Currency.String,
+		}
+	}
+	return dto
+}
+
+func newEntrantDTO(row db.ListRaceEntrantsRow) entrantDTO {
+	dto := entrantDTO{
+		ID:           row.ID,
+		FirstName:    row.FirstName,
+		LastName:     row.LastName,
+		Deleted:      row.AnonymisedAt.Valid,
+		Status:       string(row.Status),
+		Source:       string(row.Source),
+		RegisteredAt: formatTimestamp(row.CreatedAt),
+	}
+	if !dto.Deleted {
+		dto.Email = &row.Email
+	}
+	if row.Bib.Valid {
+		dto.Bib = &row.Bib.String
+	}
+	if row.TeamName.Valid {
+		dto.Team = &row.TeamName.String
+	}
+	return dto
+}
+
+// parsePageParam reads a positive integer query parameter, returning def when absent.
+func parsePageParam(r *http.Request, name string, def int) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func (app *application) apiListEvents(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	errs := service.FieldErrors{}
+	page, ok := parsePageParam(r, "page", 1)
+	if !ok {
+		errs.Add("page", "page must be an integer")
+	}
+	perPage, ok := parsePageParam(r, "per_page", service.DefaultEventsPerPage)
+	if !ok {
+		errs.Add("per_page", "per_page must be an integer")
+	}
+	if err := errs.Err(); err != nil {
+		app.apiError(w, r, err)
+		return
+	}
+
+	result, err := app.eventService.ListEventsPage(ctx, service.ListEventsParams{
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		app.apiError(w, r, err)
+		return
+	}
+
+	data := make([]eventDTO, 0, len(result.Events))
+	for _, e := range result.Events {
+		data = append(data, newEventDTO(e))
+	}
+
+	w.Header().Set("Cache-Control", apiListCacheControl)
+	app.writeJSON(w, http.StatusOK, eventListResponse{
+		Data: data,
+		Pagination: paginationDTO{
+			Page:    result.Page,
+			PerPage: result.PerPage,
+			Total:   result.Total,
+		},
+	})
+}
+
+func (app *application) apiEventDetail(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.apiLoadEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	races, err := app.raceService.ListRaces(ctx, event.ID)
+	if err != nil {
+		app.apiError(w, r, err)
+		return
+	}
+
+	dto := eventDetailDTO{
+		eventDTO: newEventDTO(event),
+		Races:    make([]raceDTO, 0, len(races)),
+	}
+	for _, race := range races {
+		dto.Races = append(dto.Races, newRaceDTO(race))
+	}
+
+	app.writeJSON(w, http.StatusOK, dto)
+}
+
+func (app *application) apiRaceDetail(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.apiLoadEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	race, err := app.raceService.GetRace(ctx, event.ID, r.PathValue("raceSlug"))
+	if err != nil {
+		app.apiError(w, r, notFoundAs(err, service.ErrRaceNotFound))
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, newRaceDTO(race))
+}
+
+func (app *application) apiRaceEntrants(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.apiError(w, r, service.ErrRaceNotFound)
+		return
+	}
+
+	race, err := app.raceService.GetRaceByID(ctx, id)
+	if err != nil {
+		app.apiError(w, r, notFoundAs(err, service.ErrRaceNotFound))
+		return
+	}
+
+	// As in the admin pages, races the token's user cannot manage are
+	// hidden rather than forbidden, so their IDs cannot be probed
+	user, _ := getUserFromContext(r)
+	allowed, err := app.organisationService.CanManageEvent(ctx, user.ID, race.EventID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		app.apiError(w, r, err)
+		return
+	}
+	if !allowed {
+		app.apiError(w, r, service.ErrRaceNotFound)
+		return
+	}
+
+	entrants, err := app.registrationService.ListRaceEntrants(ctx, race.ID)
+	if err != nil {
+		app.apiError(w, r, err)
+		return
+	}
+
+	data := make([]entrantDTO, 0, len(entrants))
+	for _, row := range entrants {
+		data = append(data, newEntrantDTO(row))
+	}
+	app.writeJSON(w, http.StatusOK, entrantListResponse{Data: data})
+}
+
+// apiEventAvailability serves how full each of the event's races is. It
+// needs no token, and pages on the websites the organisation allows may
+// read it from the browser.
+func (app *application) apiEventAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.apiLoadEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+	org, err := app.organisationService.GetOrganisation(ctx, event.OrganisationID)
+	if err != nil {
+		app.apiError(w, r, err)
+		return
+	}
+
+	races, err := app.raceService.ListRacesByEvents(ctx, []int64{event.ID})
+	if err != nil {
+		app.apiError(w, r, err)
+		return
+	}
+	counts, err := app.registrationCounter.CountByRace(ctx, event.ID)
+	if err != nil {
+		app.apiError(w, r, err)
+		return
+	}
+
+	now := app.clock.Now()
+	data := make([]availabilityDTO, 0, len(races[event.ID]))
+	for _, race := range races[event.ID] {
+		registered := counts[race.ID]
+		data = append(data, availabilityDTO{
+			Name:       race.Name,
+			Capacity:   race.MaxCapacity,
+			Registered: registered,
+			State:      viewmodels.NewRaceViewModel(race, registered, now).StateName(),
+		})
+	}
+
+	setEmbedCORS(w, r, org)
+	w.Header().Set("Cache-Control", apiAvailabilityCacheControl)
+	app.writeJSON(w, http.StatusOK, availabilityResponse{Data: data})
+}
+
+// apiEventAvailabilityPreflight answers a browser asking whether a page on
+// another website may read the event's availability. Websites the
+// organisation does not allow are answered without CORS headers, which
+// browsers take as a refusal.
+func (app *application) apiEventAvailabilityPreflight(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.apiLoadEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+	org, err := app.organisationService.GetOrganisation(ctx, event.OrganisationID)
+	if err != nil {
+		app.apiError(w, r, err)
+		return
+	}
+
+	if setEmbedCORS(w, r, org) {
+		w.Header().Set("Access-Control-Allow-Methods", http.MethodGet)
+		w.Header().Set("Access-Control-Max-Age", apiPreflightMaxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setEmbedCORS lets the request's origin read the response if the
+// organisation allows it to embed availability, reporting whether it does.
+// Origins the organisation lists are allowed by name; with none listed, an
+// organisation that opts in to public embedding allows any.
+func setEmbedCORS(w http.ResponseWriter, r *http.Request, org db.Organisation) bool {
+	w.Header().Add("Vary", "Origin")
+
+	origin := r.Header.Get("Origin")
+	switch {
+	case len(org.EmbedOrigins) > 0:
+		if origin == "" || !slices.ContainsFunc(org.EmbedOrigins, func(allowed string) bool {
+			return strings.EqualFold(allowed, origin)
+		}) {
+			return false
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	case org.EmbedPublic:
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	default:
+		return false
+	}
+	return true
+}
+
+// apiLoadEvent fetches the latest edition of the event named by the {slug}
+// path value, writing a problem response and returning false if it cannot be
+// loaded.
+func (app *application) apiLoadEvent(ctx context.Context, w http.ResponseWriter, r *http.Request) (db.Event, bool) {
+	event, err := app.eventService.FindEventBySlug(ctx, r.PathValue("slug"))
+	if err != nil {
+		app.apiError(w, r, notFoundAs(err, service.ErrEventNotFound))
+		return db.Event{}, false
+	}
+	return event, true
+}
+
+// notFoundAs returns notFound in place of a bare repository.ErrNotFound, so
+// the problem says what was missing. Errors that already say so, and any
+// others, are returned as they are.
+func notFoundAs(err error, notFound *apperr.Error) error {
+	if errors.Is(err, repository.ErrNotFound) && apperr.CodeOf(err) == apperr.CodeNotFound {
+		return notFound
+	}
+	return err
+}
@@ -0,0 +1,684 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/servicemocks"
+	"firecrest/internal/repository"
+	"firecrest/internal/service"
+)
+
+// decodeProblem decodes a problem details response body, failing the test
+// if it was not sent as one.
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) problem {
+	t.Helper()
+
+	if got := rr.Header().Get("Content-Type"); got != problemContentType {
+		t.Fatalf("expected content type %q, got %q", problemContentType, got)
+	}
+	var body problem
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode problem body: %v", err)
+	}
+	return body
+}
+
+func TestAPIListEvents(t *testing.T) {
+	validatingEventSvc := func() *servicemocks.EventServiceMock {
+		return &servicemocks.EventServiceMock{
+			ListEventsPageFunc: func(ctx context.Context, params service.ListEventsParams) (service.EventPage, error) {
+				if err := params.Validate(); err != nil {
+					return service.EventPage{}, err
+				}
+				return service.EventPage{Page: params.Page, PerPage: params.PerPage}, nil
+			},
+		}
+	}
+
+	t.Run("returns paginated events as JSON", func(t *testing.T) {
+		created := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+		var gotParams service.ListEventsParams
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListEventsPageFunc: func(ctx context.Context, params service.ListEventsParams) (service.EventPage, error) {
+				gotParams = params
+				return service.EventPage{
+					Events: []db.Event{{
+						ID:             7,
+						OrganisationID: 3,
+						Name:           "Lakes Trail Run",
+						Slug:           "lakes-trail-run",
+						Year:           2026,
+						CreatedAt:      pgtype.Timestamptz{Time: created, Valid: true},
+					}},
+					Page:    params.Page,
+					PerPage: params.PerPage,
+					Total:   41,
+				}, nil
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events?page=3&per_page=20", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.apiListEvents(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if gotParams.Page != 3 || gotParams.PerPage != 20 {
+			t.Errorf("expected page 3 per_page 20, got %+v", gotParams)
+		}
+		if got := rr.Header().Get("Cache-Control"); got != apiListCacheControl {
+			t.Errorf("expected Cache-Control %q, got %q", apiListCacheControl, got)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("expected JSON content type, got %q", got)
+		}
+
+		var body struct {
+			Data       []map[string]any `json:"data"`
+			Pagination paginationDTO    `json:"pagination"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if len(body.Data) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(body.Data))
+		}
+		if body.Data[0]["organisationId"] != float64(3) {
+			t.Errorf("expected camelCase organisationId 3, got %v", body.Data[0]["organisationId"])
+		}
+		if body.Data[0]["createdAt"] != "2026-03-01T09:30:00Z" {
+			t.Errorf("expected RFC3339 createdAt, got %v", body.Data[0]["createdAt"])
+		}
+		if body.Pagination.Total != 41 || body.Pagination.Page != 3 {
+			t.Errorf("unexpected pagination: %+v", body.Pagination)
+		}
+	})
+
+	t.Run("uses default pagination when params are absent", func(t *testing.T) {
+		app := newTestApplication(validatingEventSvc(), &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.apiListEvents(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+
+		var body eventListResponse
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if body.Pagination.Page != 1 || body.Pagination.PerPage != service.DefaultEventsPerPage {
+			t.Errorf("expected default pagination, got %+v", body.Pagination)
+		}
+		if body.Data == nil {
+			t.Error("expected empty data array, got null")
+		}
+	})
+
+	malformed := []struct {
+		name  string
+		query string
+	}{
+		{name: "non-numeric page", query: "page=abc"},
+		{name: "non-numeric per_page", query: "per_page=ten"},
+		{name: "zero page", query: "page=0"},
+		{name: "negative page", query: "page=-2"},
+		{name: "per_page above maximum", query: "per_page=101"},
+	}
+
+	for _, tt := range malformed {
+		t.Run("returns 422 for "+tt.name, func(t *testing.T) {
+			app := newTestApplication(validatingEventSvc(), &servicemocks.UserServiceMock{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/events?"+tt.query, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			app.apiListEvents(rr, req)
+
+			if rr.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+			}
+			if got := decodeProblem(t, rr); got.Code != "invalid_input" || got.Detail == "" {
+				t.Errorf("unexpected error body: %+v", got)
+			}
+		})
+	}
+
+	t.Run("returns 500 on service error", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListEventsPageFunc: func(ctx context.Context, params service.ListEventsParams) (service.EventPage, error) {
+				return service.EventPage{}, errors.New("database error")
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.apiListEvents(rr, req)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+		if got := decodeProblem(t, rr); got.Code != "internal_error" {
+			t.Errorf("expected internal_error code, got %q", got.Code)
+		}
+	})
+}
+
+func TestAPIEventDetail(t *testing.T) {
+	t.Run("returns event with races and remaining spots", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			FindEventBySlugFunc: func(ctx context.Context, slug string) (db.Event, error) {
+				return db.Event{ID: 1, Name: "Peak Ultra", Slug: slug}, nil
+			},
+		}
+		mockRaceSvc := &servicemocks.RaceServiceMock{
+			ListRacesFunc: func(ctx context.Context, eventID int64) ([]service.RaceAvailability, error) {
+				return []service.RaceAvailability{{
+					Race: db.Race{
+						ID:          5,
+						EventID:     eventID,
+						Name:        "50K",
+						Slug:        "50k",
+						MaxCapacity: 300,
+						PriceUnits:  pgtype.Int4{Int32: 8500, Valid: true},
+						Currency:    pgtype.Text{String: "GBP", Valid: true},
+					},
+					Registered: 245,
+				}}, nil
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+		app.raceService = mockRaceSvc
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/peak-ultra", http.NoBody)
+		req.SetPathValue("slug", "peak-ultra")
+		rr := httptest.NewRecorder()
+
+		app.apiEventDetail(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+
+		var body eventDetailDTO
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if len(body.Races) != 1 {
+			t.Fatalf("expected 1 race, got %d", len(body.Races))
+		}
+		race := body.Races[0]
+		if race.SpotsRemaining != 55 {
+			t.Errorf("expected 55 spots remaining, got %d", race.SpotsRemaining)
+		}
+		if race.Price == nil || race.Price.Amount != 8500 || race.Price.Currency != "GBP" {
+			t.Errorf("unexpected price: %+v", race.Price)
+		}
+	})
+
+	t.Run("returns 404 for non-existent event", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			FindEventBySlugFunc: func(ctx context.Context, slug string) (db.Event, error) {
+				return db.Event{}, repository.ErrNotFound
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/missing", http.NoBody)
+		req.SetPathValue("slug", "missing")
+		rr := httptest.NewRecorder()
+
+		app.apiEventDetail(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if got := decodeProblem(t, rr); got.Code != "event_not_found" || got.Detail != "event not found" {
+			t.Errorf("unexpected error body: %+v", got)
+		}
+	})
+}
+
+func TestAPIEventAvailability(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	newApp := func(org db.Organisation) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			FindEventBySlugFunc: func(ctx context.Context, slug string) (db.Event, error) {
+				if slug != "peak-ultra" {
+					return db.Event{}, repository.ErrNotFound
+				}
+				return db.Event{ID: 1, OrganisationID: org.ID, Slug: slug}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			GetOrganisationFunc: func(ctx context.Context, id int64) (db.Organisation, error) {
+				return org, nil
+			},
+		}
+		app.raceService = &servicemocks.RaceServiceMock{
+			ListRacesByEventsFunc: func(ctx context.Context, eventIDs []int64) (map[int64][]db.Race, error) {
+				return map[int64][]db.Race{1: {
+					{ID: 5, EventID: 1, Name: "50K", MaxCapacity: 300},
+					{ID: 6, EventID: 1, Name: "10K", MaxCapacity: 100},
+					{ID: 7, EventID: 1, Name: "Relay", MaxCapacity: 20, RegistrationOpenDate: pgtype.Timestamptz{Time: now.Add(time.Hour), Valid: true}},
+				}}, nil
+			},
+		}
+		app.registrationCounter = &servicemocks.RegistrationCounterMock{
+			CountByRaceFunc: func(ctx context.Context, eventID int64) (map[int64]int, error) {
+				return map[int64]int{5: 245, 6: 100}, nil
+			},
+		}
+		app.clock = fixedClock(now)
+		return app
+	}
+	request := func(method, origin string) *http.Request {
+		req := httptest.NewRequest(method, "/api/v1/events/peak-ultra/availability", http.NoBody)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		return req
+	}
+	serve := func(app *application, req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+		return rr
+	}
+	listed := db.Organisation{ID: 3, EmbedOrigins: []string{"https://peakrunners.example"}}
+
+	t.Run("reports each race's places without a token", func(t *testing.T) {
+		rr := serve(newApp(listed), request(http.MethodGet, ""))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		var body availabilityResponse
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		want := []availabilityDTO{
+			{Name: "50K", Capacity: 300, Registered: 245, State: "open"},
+			{Name: "10K", Capacity: 100, Registered: 100, State: "sold-out"},
+			{Name: "Relay", Capacity: 20, Registered: 0, State: "not-yet-open"},
+		}
+		if !slices.Equal(body.Data, want) {
+			t.Errorf("unexpected availability: %+v", body.Data)
+		}
+	})
+
+	t.Run("may be cached for a minute by anyone", func(t *testing.T) {
+		rr := serve(newApp(listed), request(http.MethodGet, "https://peakrunners.example"))
+
+		if got := rr.Header().Get("Cache-Control"); got != "public, max-age=60" {
+			t.Errorf("expected a minute in shared caches, got %q", got)
+		}
+		if !slices.Contains(rr.Header().Values("Vary"), "Origin") {
+			t.Errorf("expected caches to keep a copy per origin, got Vary %q", rr.Header().Values("Vary"))
+		}
+	})
+
+	t.Run("lets listed websites read it", func(t *testing.T) {
+		rr := serve(newApp(listed), request(http.MethodGet, "https://peakrunners.example"))
+
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://peakrunners.example" {
+			t.Errorf("expected the listed origin allowed, got %q", got)
+		}
+	})
+
+	t.Run("keeps it from websites not listed", func(t *testing.T) {
+		org := listed
+		org.EmbedPublic = true
+		rr := serve(newApp(org), request(http.MethodGet, "https://elsewhere.example"))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected no origin allowed, got %q", got)
+		}
+	})
+
+	t.Run("lets any website read it when public and none are listed", func(t *testing.T) {
+		rr := serve(newApp(db.Organisation{ID: 3, EmbedPublic: true}), request(http.MethodGet, "https://elsewhere.example"))
+
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("expected any origin allowed, got %q", got)
+		}
+	})
+
+	t.Run("keeps it from every website by default", func(t *testing.T) {
+		rr := serve(newApp(db.Organisation{ID: 3}), request(http.MethodGet, "https://peakrunners.example"))
+
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected no origin allowed, got %q", got)
+		}
+	})
+
+	t.Run("answers the preflight of a listed website", func(t *testing.T) {
+		rr := serve(newApp(listed), request(http.MethodOptions, "https://peakrunners.example"))
+
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("expected status %d, got %d", http.StatusNoContent, rr.Code)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://peakrunners.example" {
+			t.Errorf("expected the listed origin allowed, got %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); got != http.MethodGet {
+			t.Errorf("expected GET allowed, got %q", got)
+		}
+		if rr.Header().Get("Access-Control-Max-Age") == "" {
+			t.Error("expected the preflight to be remembered")
+		}
+	})
+
+	t.Run("refuses the preflight of a website not listed", func(t *testing.T) {
+		rr := serve(newApp(listed), request(http.MethodOptions, "https://elsewhere.example"))
+
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("expected status %d, got %d", http.StatusNoContent, rr.Code)
+		}
+		for _, header := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods"} {
+			if got := rr.Header().Get(header); got != "" {
+				t.Errorf("expected no %s, got %q", header, got)
+			}
+		}
+	})
+
+	t.Run("lets other websites load the widget script", func(t *testing.T) {
+		rr := serve(newApp(listed), httptest.NewRequest(http.MethodGet, "/static/js/availability-widget.js", http.NoBody))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Cross-Origin-Resource-Policy"); got != "cross-origin" {
+			t.Errorf("expected the script loadable from other sites, got %q", got)
+		}
+	})
+
+	t.Run("returns 404 for a non-existent event", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/missing/availability", http.NoBody)
+		rr := serve(newApp(listed), req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if got := decodeProblem(t, rr); got.Code != "event_not_found" {
+			t.Errorf("unexpected error body: %+v", got)
+		}
+	})
+}
+
+func TestAPIRaceDetail(t *testing.T) {
+	t.Run("returns 404 for non-existent race", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			FindEventBySlugFunc: func(ctx context.Context, slug string) (db.Event, error) {
+				return db.Event{ID: 1, Slug: slug}, nil
+			},
+		}
+		mockRaceSvc := &servicemocks.RaceServiceMock{
+			GetRaceFunc: func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+				return service.RaceAvailability{}, repository.ErrNotFound
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+		app.raceService = mockRaceSvc
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/peak-ultra/races/missing", http.NoBody)
+		req.SetPathValue("slug", "peak-ultra")
+		req.SetPathValue("raceSlug", "missing")
+		rr := httptest.NewRecorder()
+
+		app.apiRaceDetail(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if got := decodeProblem(t, rr); got.Code != "race_not_found" || got.Detail != "race not found" {
+			t.Errorf("unexpected error body: %+v", got)
+		}
+	})
+
+	t.Run("returns 404 when the event does not exist", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			FindEventBySlugFunc: func(ctx context.Context, slug string) (db.Event, error) {
+				return db.Event{}, repository.ErrNotFound
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/missing/races/50k", http.NoBody)
+		req.SetPathValue("slug", "missing")
+		req.SetPathValue("raceSlug", "50k")
+		rr := httptest.NewRecorder()
+
+		app.apiRaceDetail(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if got := decodeProblem(t, rr); got.Detail != "event not found" {
+			t.Errorf("expected event not found detail, got %q", got.Detail)
+		}
+	})
+}
+
+// serveAPI sends a GET for path through the application's full router,
+// presenting token as a bearer token unless it is empty.
+func serveAPI(app *application, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, req)
+	return rr
+}
+
+// tokenServiceWith authenticates each of the given plaintext tokens as a
+// token of user 5 with its scopes, rejecting any other.
+func tokenServiceWith(scopes map[string][]string) *servicemocks.TokenServiceMock {
+	return &servicemocks.TokenServiceMock{
+		AuthenticateFunc: func(ctx context.Context, plaintext string) (db.ApiToken, db.User, error) {
+			s, ok := scopes[plaintext]
+			if !ok {
+				return db.ApiToken{}, db.User{}, service.ErrInvalidAPIToken
+			}
+			return db.ApiToken{ID: 1, UserID: 5, Scopes: s}, db.User{ID: 5, Role: db.UserRoleOrganizer}, nil
+		},
+	}
+}
+
+func TestAPIRaceEntrants(t *testing.T) {
+	newApp := func() *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.tokenService = tokenServiceWith(map[string][]string{
+			"entrants-token": {service.ScopeReadEntrants},
+			"events-token":   {service.ScopeReadEvents},
+		})
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+				switch id {
+				case 7:
+					return db.Race{ID: 7, EventID: 70}, nil
+				case 8:
+					return db.Race{ID: 8, EventID: 80}, nil
+				}
+				return db.Race{}, repository.ErrNotFound
+			},
+		}
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			CanManageEventFunc: func(ctx context.Context, userID, eventID int64) (bool, error) {
+				return userID == 5 && eventID == 70, nil
+			},
+		}
+		app.registrationService = &servicemocks.RegistrationServiceMock{
+			ListRaceEntrantsFunc: func(ctx context.Context, raceID int64) ([]db.ListRaceEntrantsRow, error) {
+				return []db.ListRaceEntrantsRow{
+					{
+						ID:        11,
+						Status:    db.RegistrationStatusConfirmed,
+						Source:    db.RegistrationSourceOnline,
+						Bib:       pgtype.Text{String: "101", Valid: true},
+						CreatedAt: pgtype.Timestamptz{Time: time.Date(2026, 4, 2, 8, 0, 0, 0, time.UTC), Valid: true},
+						Email:     "ada@example.com",
+						FirstName: "Ada",
+						LastName:  "Lovelace",
+					},
+					{
+						ID:           12,
+						Status:       db.RegistrationStatusConfirmed,
+						Source:       db.RegistrationSourceOnline,
+						Email:        "deleted-12@anonymised.invalid",
+						AnonymisedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+					},
+				}, nil
+			},
+		}
+		return app
+	}
+
+	t.Run("lists entrants for a read:entrants token", func(t *testing.T) {
+		rr := serveAPI(newApp(), "/api/v1/races/7/entrants", "entrants-token")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var body entrantListResponse
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if len(body.Data) != 2 {
+			t.Fatalf("expected 2 entrants, got %d", len(body.Data))
+		}
+		first := body.Data[0]
+		if first.FirstName != "Ada" || first.Email == nil || *first.Email != "ada@example.com" {
+			t.Errorf("unexpected entrant %+v", first)
+		}
+		if first.Bib == nil || *first.Bib != "101" || first.Status != "confirmed" {
+			t.Errorf("expected bib 101 and confirmed status, got %+v", first)
+		}
+		if first.RegisteredAt == nil || *first.RegisteredAt != "2026-04-02T08:00:00Z" {
+			t.Errorf("unexpected registeredAt %v", first.RegisteredAt)
+		}
+		if deleted := body.Data[1]; !deleted.Deleted || deleted.Email != nil {
+			t.Errorf("expected a deleted entrant without an email, got %+v", deleted)
+		}
+	})
+
+	t.Run("rejects a read:events token", func(t *testing.T) {
+		rr := serveAPI(newApp(), "/api/v1/races/7/entrants", "events-token")
+
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, rr.Code)
+		}
+		if got := decodeProblem(t, rr).Code; got != "insufficient_scope" {
+			t.Errorf("expected insufficient_scope, got %q", got)
+		}
+	})
+
+	t.Run("requires a token", func(t *testing.T) {
+		rr := serveAPI(newApp(), "/api/v1/races/7/entrants", "")
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+		if got := rr.Header().Get("WWW-Authenticate"); got != "Bearer" {
+			t.Errorf("expected a bearer challenge, got %q", got)
+		}
+	})
+
+	t.Run("rejects revoked tokens", func(t *testing.T) {
+		rr := serveAPI(newApp(), "/api/v1/races/7/entrants", "revoked-token")
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+		if got := decodeProblem(t, rr).Code; got != "invalid_token" {
+			t.Errorf("expected invalid_token, got %q", got)
+		}
+		if got := rr.Header().Get("WWW-Authenticate"); got != `Bearer error="invalid_token"` {
+			t.Errorf("unexpected challenge %q", got)
+		}
+	})
+
+	t.Run("rejects other authorization schemes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/races/7/entrants", http.NoBody)
+		req.SetBasicAuth("timer", "secret")
+		rr := httptest.NewRecorder()
+		newApp().routes().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("hides races the token's user cannot manage", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/races/8/entrants", "/api/v1/races/9/entrants", "/api/v1/races/x/entrants"} {
+			rr := serveAPI(newApp(), path, "entrants-token")
+			if rr.Code != http.StatusNotFound {
+				t.Errorf("expected %s to be not found, got %d", path, rr.Code)
+			}
+		}
+	})
+}
+
+func TestAPIEventScopes(t *testing.T) {
+	newApp := func() *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			ListEventsPageFunc: func(ctx context.Context, params service.ListEventsParams) (service.EventPage, error) {
+				return service.EventPage{Page: params.Page, PerPage: params.PerPage}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.tokenService = tokenServiceWith(map[string][]string{
+			"entrants-token": {service.ScopeReadEntrants},
+			"events-token":   {service.ScopeReadEvents},
+		})
+		return app
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"stays open to anonymous clients", "", http.StatusOK},
+		{"accepts a read:events token", "events-token", http.StatusOK},
+		{"rejects a token without read:events", "entrants-token", http.StatusForbidden},
+		{"rejects revoked tokens", "revoked-token", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveAPI(newApp(), "/api/v1/events", tt.token)
+			if rr.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/bus"
+	"firecrest/ui/viewmodels"
+)
+
+const (
+	// availabilityHeartbeat is how often an idle availability stream sends
+	// a comment, so proxies do not close it for inactivity.
+	availabilityHeartbeat = 25 * time.Second
+	// streamWriteTimeout bounds each write to a stream. A client that
+	// stops reading is dropped rather than holding the handler.
+	streamWriteTimeout = 10 * time.Second
+)
+
+// availabilityHub tells the availability streams open on an event's page
+// when an entry to it is made or cancelled. Each stream holds at most one
+// pending notice: changes arriving faster than it can send them are merged,
+// and it sends the counts as they are once it catches up.
+type availabilityHub struct {
+	heartbeat time.Duration
+
+	mu       sync.Mutex
+	watchers map[int64]map[chan struct{}]struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// newAvailabilityHub returns a hub whose streams send a heartbeat at the
+// given interval.
+func newAvailabilityHub(heartbeat time.Duration) *availabilityHub {
+	return &availabilityHub{
+		heartbeat: heartbeat,
+		watchers:  make(map[int64]map[chan struct{}]struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// subscribe notifies the hub of the entries made and cancelled on b. The
+// registration counter must have subscribed first, so the counts streams
+// read afterwards are current.
+func (h *availabilityHub) subscribe(b *bus.Bus) {
+	notify := func(ctx context.Context, c bus.RegistrationChange) error {
+		h.notify(c.EventID)
+		return nil
+	}
+	bus.Subscribe(b, bus.RegistrationCreated, "availability streams", notify)
+	bus.Subscribe(b, bus.RegistrationCancelled, "availability streams", notify)
+}
+
+// watch returns a channel receiving a value after entries to the event
+// change, and a function that stops watching.
+func (h *availabilityHub) watch(eventID int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.watchers[eventID] == nil {
+		h.watchers[eventID] = make(map[chan struct{}]struct{})
+	}
+	h.watchers[eventID][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.watchers[eventID], ch)
+		if len(h.watchers[eventID]) == 0 {
+			delete(h.watchers, eventID)
+		}
+	}
+}
+
+// notify wakes the event's streams without waiting for any of them.
+func (h *availabilityHub) notify(eventID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.watchers[eventID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// watching returns the number of streams open on the event.
+func (h *availabilityHub) watching(eventID int64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.watchers[eventID])
+}
+
+// stop ends every stream, so the server can shut down without waiting for
+// their clients to leave. EventSource clients reconnect on their own.
+func (h *availabilityHub) stop() {
+	h.stopOnce.Do(func() { close(h.done) })
+}
+
+// raceAvailabilityDTO is how full a race is, for the event page.
+type raceAvailabilityDTO struct {
+	Slug       string `json:"slug"`
+	Capacity   int    `json:"capacity"`
+	Registered int    `json:"registered"`
+	State      string `json:"state"`
+}
+
+// eventAvailabilityDTO is how full an event and each of its races are.
+type eventAvailabilityDTO struct {
+	Capacity   int                   `json:"capacity"`
+	Registered int                   `json:"registered"`
+	Races      []raceAvailabilityDTO `json:"races"`
+}
+
+// eventAvailabilityStream serves Server-Sent Events carrying how full the
+// event's races are: once when the stream opens, then after each entry made
+// or cancelled. Races are read once, so a change to a race's capacity shows
+// when the page is next loaded.
+func (app *application) eventAvailabilityStream(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	event, ok := app.loadPublicEvent(ctx, w, r)
+	if !ok {
+		cancel()
+		return
+	}
+	races, err := app.raceService.ListRacesByEvents(ctx, []int64{event.ID})
+	cancel()
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	updates, unwatch := app.availability.watch(event.ID)
+	defer unwatch()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	// Proxies such as nginx would otherwise hold events back in a buffer
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(app.availability.heartbeat)
+	defer heartbeat.Stop()
+
+	// The page may have been rendered, or cached, before the latest entry
+	err = app.sendAvailability(w, rc, r, event, races[event.ID])
+	for err == nil {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-app.availability.done:
+			return
+		case <-updates:
+			err = app.sendAvailability(w, rc, r, event, races[event.ID])
+		case <-heartbeat.C:
+			err = writeStream(w, rc, ": heartbeat\n\n")
+		}
+	}
+	// A client that went away mid-write is not worth a warning
+	if r.Context().Err() == nil && !app.clientGone(r, err) {
+		app.logger.Warn("availability stream ended", "request_id", getRequestID(r), "event_id", event.ID, "error", err)
+	}
+}
+
+// sendAvailability sends the races' current counts as an availability
+// event.
+func (app *application) sendAvailability(w http.ResponseWriter, rc *http.ResponseController, r *http.Request, event db.Event, races []db.Race) error {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+	counts, err := app.registrationCounter.CountByRace(ctx, event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count registrations: %w", err)
+	}
+
+	now := app.clock.Now()
+	data := eventAvailabilityDTO{Races: make([]raceAvailabilityDTO, 0, len(races))}
+	for _, race := range races {
+		vm := viewmodels.NewRaceViewModel(race, counts[race.ID], now)
+		data.Capacity += vm.Capacity
+		data.Registered += vm.Registered
+		data.Races = append(data.Races, raceAvailabilityDTO{
+			Slug:       vm.Slug,
+			Capacity:   vm.Capacity,
+			Registered: vm.Registered,
+			State:      vm.StateName(),
+		})
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return writeStream(w, rc, "event: availability\ndata: "+string(payload)+"\n\n")
+}
+
+// writeStream writes an event or comment to a Server-Sent Events stream and
+// flushes it to the client. Each write has streamWriteTimeout to finish,
+// in place of the server's WriteTimeout, which would end the stream.
+func writeStream(w io.Writer, rc *http.ResponseController, msg string) error {
+	if err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := io.WriteString(w, msg); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/bus"
+	"firecrest/internal/mocks/servicemocks"
+	"firecrest/internal/repository"
+)
+
+// streamRecorder is an http.ResponseWriter for handlers that stream. The
+// body may be read while the handler writes, and each flush is signalled on
+// flushed.
+type streamRecorder struct {
+	header  http.Header
+	flushed chan struct{}
+
+	mu     sync.Mutex
+	status int
+	body   strings.Builder
+}
+
+func newStreamRecorder() *streamRecorder {
+	return &streamRecorder{header: make(http.Header), flushed: make(chan struct{}, 100)}
+}
+
+func (rec *streamRecorder) Header() http.Header { return rec.header }
+
+func (rec *streamRecorder) WriteHeader(status int) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *streamRecorder) Write(p []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.body.Write(p)
+}
+
+func (rec *streamRecorder) Flush() {
+	rec.flushed <- struct{}{}
+}
+
+// Body returns what has been written so far.
+func (rec *streamRecorder) Body() string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.body.String()
+}
+
+// waitFlush waits for the handler to flush what it has written.
+func (rec *streamRecorder) waitFlush(t *testing.T) {
+	t.Helper()
+	select {
+	case <-rec.flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the stream; got %q", rec.Body())
+	}
+}
+
+// availabilityEvents returns the data of each availability event in body.
+func availabilityEvents(t *testing.T, body string) []eventAvailabilityDTO {
+	t.Helper()
+	var events []eventAvailabilityDTO
+	for msg := range strings.SplitSeq(body, "\n\n") {
+		data, ok := strings.CutPrefix(msg, "event: availability\ndata: ")
+		if !ok {
+			continue
+		}
+		var e eventAvailabilityDTO
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			t.Fatalf("invalid event data %q: %v", data, err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// newAvailabilityApp returns an app serving event 7, whose 10K and half
+// marathon have the entries in counts.
+func newAvailabilityApp(counts func() map[int64]int) *application {
+	app := newTestApplication(&servicemocks.EventServiceMock{
+		GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+			if slug != "harbour-run" {
+				return db.Event{}, repository.ErrNotFound
+			}
+			return db.Event{ID: 7, Year: year, Slug: slug, Name: "Harbour Run"}, nil
+		},
+	}, &servicemocks.UserServiceMock{})
+	app.raceService = &servicemocks.RaceServiceMock{
+		ListRacesByEventsFunc: func(ctx context.Context, eventIDs []int64) (map[int64][]db.Race, error) {
+			return map[int64][]db.Race{7: {
+				{ID: 1, EventID: 7, Slug: "10k", MaxCapacity: 100},
+				{ID: 2, EventID: 7, Slug: "half", MaxCapacity: 50},
+			}}, nil
+		},
+	}
+	app.registrationCounter = &servicemocks.RegistrationCounterMock{
+		CountByRaceFunc: func(ctx context.Context, eventID int64) (map[int64]int, error) {
+			return counts(), nil
+		},
+	}
+	return app
+}
+
+// openStream serves a request for event 7's stream through the app's
+// routes until ctx is cancelled, closing done when the handler returns.
+func openStream(ctx context.Context, app *application, rec *streamRecorder) (done chan struct{}) {
+	done = make(chan struct{})
+	handler := app.routes()
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/events/2026/harbour-run/availability/stream", http.NoBody)
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(rec, req)
+	}()
+	return done
+}
+
+// waitClosed waits for a stream's handler to return.
+func waitClosed(t *testing.T, done chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the stream to end")
+	}
+}
+
+func TestEventAvailabilityStream(t *testing.T) {
+	t.Run("sends the counts on opening and after each entry made or cancelled", func(t *testing.T) {
+		var mu sync.Mutex
+		counts := map[int64]int{1: 40, 2: 49}
+		app := newAvailabilityApp(func() map[int64]int {
+			mu.Lock()
+			defer mu.Unlock()
+			return map[int64]int{1: counts[1], 2: counts[2]}
+		})
+		events := bus.New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		app.availability.subscribe(events)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		rec := newStreamRecorder()
+		done := openStream(ctx, app, rec)
+		rec.waitFlush(t)
+
+		if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+			t.Errorf("expected an event stream, got %q", got)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("expected the stream kept out of caches, got %q", got)
+		}
+		want := `event: availability` + "\n" + `data: {"capacity":150,"registered":89,"races":[{"slug":"10k","capacity":100,"registered":40,"state":"open"},{"slug":"half","capacity":50,"registered":49,"state":"open"}]}` + "\n\n"
+		if rec.Body() != want {
+			t.Fatalf("expected\n%q\ngot\n%q", want, rec.Body())
+		}
+
+		mu.Lock()
+		counts[2] = 50
+		mu.Unlock()
+		bus.Publish(ctx, events, bus.RegistrationCreated, bus.RegistrationChange{RegistrationID: 9, RaceID: 2, EventID: 7})
+		rec.waitFlush(t)
+
+		mu.Lock()
+		counts[1] = 39
+		mu.Unlock()
+		bus.Publish(ctx, events, bus.RegistrationCancelled, bus.RegistrationChange{RegistrationID: 3, RaceID: 1, EventID: 7})
+		rec.waitFlush(t)
+
+		got := availabilityEvents(t, rec.Body())
+		if len(got) != 3 {
+			t.Fatalf("expected 3 events, got %d in %q", len(got), rec.Body())
+		}
+		if half := got[1].Races[1]; half.Registered != 50 || half.State != "sold-out" {
+			t.Errorf("expected the half marathon sold out, got %+v", half)
+		}
+		if got[2].Registered != 89 || got[2].Races[0].Registered != 39 {
+			t.Errorf("expected the cancellation counted, got %+v", got[2])
+		}
+
+		cancel()
+		waitClosed(t, done)
+	})
+
+	t.Run("ignores entries to other events", func(t *testing.T) {
+		app := newAvailabilityApp(func() map[int64]int { return nil })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		rec := newStreamRecorder()
+		done := openStream(ctx, app, rec)
+		rec.waitFlush(t)
+
+		app.availability.notify(8)
+		app.availability.notify(7)
+		rec.waitFlush(t)
+
+		if n := len(availabilityEvents(t, rec.Body())); n != 2 {
+			t.Errorf("expected 2 events, got %d", n)
+		}
+		cancel()
+		waitClosed(t, done)
+	})
+
+	t.Run("sends heartbeats while idle", func(t *testing.T) {
+		app := newAvailabilityApp(func() map[int64]int { return nil })
+		app.availability = newAvailabilityHub(10 * time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		rec := newStreamRecorder()
+		done := openStream(ctx, app, rec)
+		rec.waitFlush(t)
+		rec.waitFlush(t)
+
+		if !strings.HasSuffix(rec.Body(), "\n\n: heartbeat\n\n") {
+			t.Errorf("expected a heartbeat comment, got %q", rec.Body())
+		}
+		cancel()
+		waitClosed(t, done)
+	})
+
+	t.Run("merges changes the client has not caught up with", func(t *testing.T) {
+		app := newAvailabilityApp(func() map[int64]int { return nil })
+		updates, unwatch := app.availability.watch(7)
+		defer unwatch()
+
+		for range 100 {
+			app.availability.notify(7)
+		}
+		if n := len(updates); n != 1 {
+			t.Errorf("expected one pending notice, got %d", n)
+		}
+	})
+
+	t.Run("ends streams when the server shuts down", func(t *testing.T) {
+		app := newAvailabilityApp(func() map[int64]int { return nil })
+
+		rec := newStreamRecorder()
+		done := openStream(context.Background(), app, rec)
+		rec.waitFlush(t)
+
+		app.availability.stop()
+		waitClosed(t, done)
+		if n := app.availability.watching(7); n != 0 {
+			t.Errorf("expected no streams left, got %d", n)
+		}
+	})
+
+	t.Run("does not find unknown events", func(t *testing.T) {
+		app := newAvailabilityApp(func() map[int64]int { return nil })
+
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/events/2026/nonexistent/availability/stream", http.NoBody))
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got == "text/event-stream" {
+			t.Error("expected an error page, not a stream")
+		}
+	})
+
+	t.Run("leaves nothing running after clients disconnect", func(t *testing.T) {
+		app := newAvailabilityApp(func() map[int64]int { return nil })
+		before := runtime.NumGoroutine()
+
+		const clients = 20
+		cancels := make([]context.CancelFunc, clients)
+		dones := make([]chan struct{}, clients)
+		for i := range clients {
+			ctx, cancel := context.WithCancel(context.Background())
+			rec := newStreamRecorder()
+			cancels[i], dones[i] = cancel, openStream(ctx, app, rec)
+			rec.waitFlush(t)
+		}
+		if n := app.availability.watching(7); n != clients {
+			t.Fatalf("expected %d streams, got %d", clients, n)
+		}
+
+		for i := range clients {
+			cancels[i]()
+			waitClosed(t, dones[i])
+		}
+		if n := app.availability.watching(7); n != 0 {
+			t.Errorf("expected no streams left, got %d", n)
+		}
+		// Goroutines take a moment to exit after closing their channel
+		deadline := time.Now().Add(5 * time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if after := runtime.NumGoroutine(); after > before {
+			t.Errorf("expected at most %d goroutines, got %d", before, after)
+		}
+	})
+}
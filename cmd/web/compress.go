@@ -0,0 +1,298 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressOptions controls which responses the compress middleware encodes.
+type compressOptions struct {
+	// MinSize is the smallest body, in bytes, worth compressing.
+	MinSize int
+	// ContentTypes lists the media types eligible for compression.
+	ContentTypes []string
+}
+
+// defaultCompressOptions compresses text responses of at least 1KB.
+func defaultCompressOptions() compressOptions {
+	return compressOptions{
+		MinSize: 1024,
+		ContentTypes: []string{
+			"text/html",
+			"text/css",
+			"application/json",
+			"text/csv",
+			"application/gpx+xml",
+		},
+	}
+}
+
+var (
+	gzipWriterPool = sync.Pool{
+		New: func() any {
+			w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+			return w
+		},
+	}
+	zstdWriterPool = sync.Pool{
+		New: func() any {
+			w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+			return w
+		},
+	}
+)
+
+// compressor is the subset of gzip.Writer and zstd.Encoder used by the middleware.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compress negotiates Accept-Encoding and compresses eligible responses with
+// zstd or gzip. Range requests are passed through untouched.
+func compress(opts compressOptions) func(http.Handler) http.Handler {
+	types := make(map[string]bool, len(opts.ContentTypes))
+	for _, t := range opts.ContentTypes {
+		types[strings.ToLower(t)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       negotiateEncoding(r.Header.Get("Accept-Encoding")),
+				minSize:        opts.MinSize,
+				types:          types,
+				status:         http.StatusOK,
+			}
+			defer cw.Close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks the preferred supported encoding from an
+// Accept-Encoding header, favouring zstd over gzip at equal quality. It
+// returns "" when neither is acceptable.
+func negotiateEncoding(header string) string {
+	zstdQ, gzipQ, anyQ := -1.0, -1.0, -1.0
+
+	for part := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "zstd":
+			zstdQ = q
+		case "gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+
+	if zstdQ < 0 {
+		zstdQ = anyQ
+	}
+	if gzipQ < 0 {
+		gzipQ = anyQ
+	}
+
+	switch {
+	case zstdQ > 0 && zstdQ >= gzipQ:
+		return "zstd"
+	case gzipQ > 0:
+		return "gzip"
+	default:
+		return ""
+	}
+}
+
+// compressWriter buffers the start of a response until it knows whether the
+// body is large enough, and of the right type, to be worth compressing.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+	types    map[string]bool
+
+	status      int
+	wroteHeader bool
+	buffering   bool
+	decided     bool
+	buf         []byte
+	enc         compressor
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+
+	// Responses without a body have nothing to compress.
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.passthrough()
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	if !cw.buffering {
+		h := cw.Header()
+		if h.Get("Content-Type") == "" {
+			h.Set("Content-Type", http.DetectContentType(p))
+		}
+		if !cw.eligible() {
+			cw.passthrough()
+			return cw.ResponseWriter.Write(p)
+		}
+
+		h.Add("Vary", "Accept-Encoding")
+		if cw.encoding == "" || cw.declaredSmall() {
+			cw.passthrough()
+			return cw.ResponseWriter.Write(p)
+		}
+		cw.buffering = true
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// eligible reports whether the response's content type may be compressed.
+func (cw *compressWriter) eligible() bool {
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, _ := strings.Cut(h.Get("Content-Type"), ";")
+	return cw.types[strings.ToLower(strings.TrimSpace(mediaType))]
+}
+
+// declaredSmall reports whether the handler set a Content-Length below the minimum size.
+func (cw *compressWriter) declaredSmall() bool {
+	n, err := strconv.Atoi(cw.Header().Get("Content-Length"))
+	return err == nil && n < cw.minSize
+}
+
+// passthrough sends the response uncompressed, including anything buffered so far.
+func (cw *compressWriter) passthrough() {
+	cw.decided = true
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) > 0 {
+		//nolint:errcheck // Write errors surface on the handler's next write
+		cw.ResponseWriter.Write(cw.buf)
+		cw.buf = nil
+	}
+}
+
+func (cw *compressWriter) startCompression() error {
+	cw.decided = true
+
+	switch cw.encoding {
+	case "zstd":
+		cw.enc = zstdWriterPool.Get().(*zstd.Encoder)
+	default:
+		cw.enc = gzipWriterPool.Get().(*gzip.Writer)
+	}
+	cw.enc.Reset(cw.ResponseWriter)
+
+	h := cw.Header()
+	h.Set("Content-Encoding", cw.encoding)
+	h.Del("Content-Length")
+	// The encoded bytes differ from the uncompressed ones, so a strong
+	// validator no longer describes them
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	_, err := cw.enc.Write(cw.buf)
+	cw.buf = nil
+	return err
+}
+
+// Close flushes any buffered body and returns the encoder to its pool.
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			// The handler wrote nothing; let net/http send its default response.
+			cw.decided = true
+			return nil
+		}
+		cw.passthrough()
+		return nil
+	}
+	if cw.enc == nil {
+		return nil
+	}
+
+	err := cw.enc.Close()
+	cw.enc.Reset(io.Discard)
+	switch enc := cw.enc.(type) {
+	case *zstd.Encoder:
+		zstdWriterPool.Put(enc)
+	case *gzip.Writer:
+		gzipWriterPool.Put(enc)
+	}
+	cw.enc = nil
+	return err
+}
+
+// Flush sends buffered data to the client. A response flushed before reaching
+// the minimum size is sent uncompressed.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
+		cw.passthrough()
+	}
+	if cw.enc != nil {
+		//nolint:errcheck // Flush has no way to report the error
+		cw.enc.Flush()
+	}
+	// The writers beneath may only expose Flush through Unwrap
+	//nolint:errcheck // Flush has no way to report the error
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying ResponseWriter to http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"firecrest/internal/mocks/servicemocks"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "gzip", want: "gzip"},
+		{header: "gzip, deflate, br, zstd", want: "zstd"},
+		{header: "zstd;q=0.5, gzip", want: "gzip"},
+		{header: "gzip;q=0, zstd;q=0", want: ""},
+		{header: "*", want: "zstd"},
+		{header: "*;q=0.1, gzip;q=0.8, zstd;q=0", want: "gzip"},
+		{header: "identity", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := negotiateEncoding(tt.header); got != tt.want {
+				t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	largeHTML := "<!DOCTYPE html><html><body>" + strings.Repeat("<p>Peak District Ultra</p>", 100) + "</body></html>"
+
+	serve := func(h http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		compress(defaultCompressOptions())(h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	htmlHandler := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			io.WriteString(w, body)
+		}
+	}
+
+	t.Run("gzips large HTML responses", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		rr := serve(htmlHandler(largeHTML), req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("expected gzip Content-Encoding, got %q", got)
+		}
+		if got := rr.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("expected Vary: Accept-Encoding, got %q", got)
+		}
+
+		zr, err := gzip.NewReader(rr.Body)
+		if err != nil {
+			t.Fatalf("failed to open gzip body: %v", err)
+		}
+		body, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("failed to read gzip body: %v", err)
+		}
+		if string(body) != largeHTML {
+			t.Error("decompressed body does not match original")
+		}
+	})
+
+	t.Run("prefers zstd when offered", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip, deflate, br, zstd")
+
+		rr := serve(htmlHandler(largeHTML), req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "zstd" {
+			t.Fatalf("expected zstd Content-Encoding, got %q", got)
+		}
+
+		zr, err := zstd.NewReader(rr.Body)
+		if err != nil {
+			t.Fatalf("failed to open zstd body: %v", err)
+		}
+		defer zr.Close()
+		body, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("failed to read zstd body: %v", err)
+		}
+		if string(body) != largeHTML {
+			t.Error("decompressed body does not match original")
+		}
+	})
+
+	t.Run("weakens a strong ETag on compressed responses", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		rr := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"abc123"`)
+			htmlHandler(largeHTML)(w, r)
+		}, req)
+
+		if got := rr.Header().Get("ETag"); got != `W/"abc123"` {
+			t.Errorf("expected a weak ETag, got %q", got)
+		}
+	})
+
+	t.Run("compresses a body written in small chunks", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/export.csv", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		var want bytes.Buffer
+		rr := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			for i := 0; i < 200; i++ {
+				line := "Jane,Runner,jane@example.com\n"
+				want.WriteString(line)
+				io.WriteString(w, line)
+			}
+		}, req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("expected gzip Content-Encoding, got %q", got)
+		}
+		zr, err := gzip.NewReader(rr.Body)
+		if err != nil {
+			t.Fatalf("failed to open gzip body: %v", err)
+		}
+		body, _ := io.ReadAll(zr)
+		if !bytes.Equal(body, want.Bytes()) {
+			t.Error("decompressed body does not match original")
+		}
+	})
+
+	t.Run("passes small responses through untouched", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		rr := serve(htmlHandler("<p>small</p>"), req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("expected no Content-Encoding, got %q", got)
+		}
+		if rr.Body.String() != "<p>small</p>" {
+			t.Errorf("expected body to be unchanged, got %q", rr.Body.String())
+		}
+	})
+
+	t.Run("skips ineligible content types", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/static/photo.png", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		rr := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(bytes.Repeat([]byte{0x89}, 4096))
+		}, req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("expected no Content-Encoding, got %q", got)
+		}
+		if got := rr.Header().Get("Vary"); got != "" {
+			t.Errorf("expected no Vary header, got %q", got)
+		}
+		if rr.Body.Len() != 4096 {
+			t.Errorf("expected 4096 byte body, got %d", rr.Body.Len())
+		}
+	})
+
+	t.Run("skips responses that are already encoded", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		rr := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "br")
+			io.WriteString(w, strings.Repeat("x", 2048))
+		}, req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "br" {
+			t.Errorf("expected Content-Encoding to be left as br, got %q", got)
+		}
+		if rr.Body.Len() != 2048 {
+			t.Errorf("expected body to be unchanged, got %d bytes", rr.Body.Len())
+		}
+	})
+
+	t.Run("skips range requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Range", "bytes=0-99")
+
+		rr := serve(htmlHandler(largeHTML), req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("expected no Content-Encoding, got %q", got)
+		}
+	})
+
+	t.Run("sets Vary without compressing when client does not accept encoding", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+
+		rr := serve(htmlHandler(largeHTML), req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("expected no Content-Encoding, got %q", got)
+		}
+		if got := rr.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("expected Vary: Accept-Encoding, got %q", got)
+		}
+		if rr.Body.String() != largeHTML {
+			t.Error("expected body to be unchanged")
+		}
+	})
+
+	t.Run("honours configured minimum size and types", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		opts := compressOptions{MinSize: 10, ContentTypes: []string{"text/plain"}}
+		rr := httptest.NewRecorder()
+		compress(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "hello, firecrest")
+		})).ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Errorf("expected gzip Content-Encoding, got %q", got)
+		}
+	})
+
+	t.Run("preserves status codes through the logging middleware", func(t *testing.T) {
+		var logs bytes.Buffer
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+		h := app.logRequest(compress(defaultCompressOptions())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			io.WriteString(w, largeHTML)
+		})))
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/events", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Errorf("expected gzip Content-Encoding, got %q", got)
+		}
+		if !strings.Contains(logs.String(), "status=422") {
+			t.Errorf("expected logged status 422, got logs:\n%s", logs.String())
+		}
+	})
+
+	t.Run("flushes through writers that only unwrap", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h := compress(defaultCompressOptions())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, ": hello\n\n")
+			w.(http.Flusher).Flush()
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+		h.ServeHTTP(&statusRecorder{ResponseWriter: rr}, req)
+
+		if !rr.Flushed {
+			t.Error("expected the flush to reach the recorder")
+		}
+	})
+
+	t.Run("passes bodiless responses through", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		rr := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}, req)
+
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d", http.StatusNoContent, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("expected no Content-Encoding, got %q", got)
+		}
+	})
+}
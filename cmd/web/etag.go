@@ -0,0 +1,79 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/internal/i18n"
+	"firecrest/ui"
+)
+
+// pageCacheControl lets browsers keep a copy of a page but makes them check
+// it is current before reuse. Pages depend on the session, so shared caches
+// must not store them.
+const pageCacheControl = "private, no-cache"
+
+// pageETag returns a weak entity tag for a page rendered from parts. It also
+// covers the signed-in user, the page's language and the static asset
+// version, which every page depends on. The tag is weak because the compress middleware changes the
+// bytes sent without changing the page.
+func pageETag(r *http.Request, parts ...any) string {
+	h := sha256.New()
+	var userID int64
+	if user, ok := getUserFromContext(r); ok {
+		userID = user.ID
+	}
+	fmt.Fprintf(h, "%s\x00%d\x00%s\x00", ui.AssetsVersion(), userID, i18n.Lang(r.Context()))
+	for _, part := range parts {
+		fmt.Fprintf(h, "%v\x00", part)
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+}
+
+// notModified sets the page's validators and reports whether the copy the
+// client already holds, named by If-None-Match, is still current. If so it
+// has answered 304 Not Modified and the handler must not write a body.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", pageCacheControl)
+
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, using the
+// weak comparison RFC 9110 requires for it.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// latestUpdate returns the most recent of the given updated_at values, or
+// the zero time if none are set.
+func latestUpdate(times ...pgtype.Timestamptz) time.Time {
+	var latest time.Time
+	for _, t := range times {
+		if t.Valid && t.Time.After(latest) {
+			latest = t.Time
+		}
+	}
+	return latest.UTC()
+}
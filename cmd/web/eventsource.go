@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/config"
+	"firecrest/internal/service"
+	"firecrest/ui/viewmodels"
+)
+
+// EventSource supplies the events shown on the home page and event pages.
+// Each method also returns the parts of the page's ETag: values that change
+// whenever the page would.
+type EventSource interface {
+	// HomeEvents returns the home page's event cards at now.
+	HomeEvents(ctx context.Context, now time.Time) ([]viewmodels.EventViewModel, []any, error)
+	// Event returns the page of the event's edition in year with slug at
+	// now. It returns repository.ErrNotFound for an unknown event.
+	Event(ctx context.Context, year int32, slug string, now time.Time) (viewmodels.EventViewModel, []any, error)
+}
+
+// events returns the source of the home and event pages' events: the mock
+// source when one was selected at startup, otherwise the services.
+func (app *application) events() EventSource {
+	if app.mockEvents != nil {
+		return app.mockEvents
+	}
+	return serviceEventSource{
+		events: app.eventService,
+		races:  app.raceService,
+		photos: app.photoService,
+		orgs:   app.organisationService,
+	}
+}
+
+// mockEventSourceFor returns the mock event source when cfg enables mock
+// data, which it never does in production, and nil otherwise.
+func mockEventSourceFor(cfg config.Config, now time.Time) *MockEventSource {
+	if !cfg.MockDataEnabled() {
+		return nil
+	}
+	return NewMockEventSource(now)
+}
+
+// serviceEventSource reads events from the database through the services.
+type serviceEventSource struct {
+	events service.EventService
+	races  service.RaceService
+	photos service.PhotoService
+	orgs   service.OrganisationService
+}
+
+func (s serviceEventSource) HomeEvents(ctx context.Context, now time.Time) ([]viewmodels.EventViewModel, []any, error) {
+	rows, err := s.events.ListEventsWithStats(ctx, service.ListEventsParams{
+		Page:    1,
+		PerPage: service.DefaultEventsPerPage,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vms := viewmodels.NewEventStatsViewModels(rows, now)
+
+	// Registration counts change without touching updated_at, and a race
+	// leaving the list need not leave a later updated_at behind, so the
+	// totals are part of the tag, as are badges that appear with the
+	// passing of time
+	var parts []any
+	var updated []pgtype.Timestamptz
+	for i, row := range rows {
+		parts = append(parts, row.Event.ID, row.RaceCount, row.TotalCapacity, row.MinPriceUnits,
+			row.PriceCurrency, row.RegistrationClosesAt, row.Registered, vms[i].Badges())
+		updated = append(updated, row.Event.UpdatedAt, row.RacesUpdatedAt)
+	}
+	return vms, append(parts, latestUpdate(updated...)), nil
+}
+
+func (s serviceEventSource) Event(ctx context.Context, year int32, slug string, now time.Time) (viewmodels.EventViewModel, []any, error) {
+	event, err := s.events.GetEvent(ctx, year, slug)
+	if err != nil {
+		return viewmodels.EventViewModel{}, nil, err
+	}
+
+	races, err := s.races.ListRaces(ctx, event.ID)
+	if err != nil {
+		return viewmodels.EventViewModel{}, nil, err
+	}
+
+	raceRows := make([]db.Race, len(races))
+	for i, race := range races {
+		raceRows[i] = race.Race
+	}
+	prices, err := s.races.CurrentPrices(ctx, raceRows, now)
+	if err != nil {
+		return viewmodels.EventViewModel{}, nil, err
+	}
+
+	vms := make([]viewmodels.RaceViewModel, 0, len(races))
+	parts := []any{event.ID}
+	updated := []pgtype.Timestamptz{event.UpdatedAt}
+	for _, race := range races {
+		vm := viewmodels.NewRaceViewModel(race.Race, race.Registered, now)
+		setRacePrice(&vm, prices[race.Race.ID], service.EventLocation(event))
+		if race.Route != nil {
+			vm.Route = &viewmodels.RouteViewModel{
+				DistanceMetres:      race.Route.DistanceMetres,
+				ElevationGainMetres: race.Route.ElevationGainMetres,
+			}
+		}
+		vm.SetWaves(race.Waves)
+		vms = append(vms, vm)
+		// A route uploaded or replaced leaves the race's updated_at alone,
+		// waves keep their own, and prices change with the time and with
+		// entries
+		parts = append(parts, race.Race.ID, race.Registered, vm.State, race.Route, len(race.Waves), vm.Price, vm.PriceNote)
+		updated = append(updated, race.Race.UpdatedAt)
+		for _, wave := range race.Waves {
+			updated = append(updated, wave.UpdatedAt)
+		}
+	}
+
+	photos, err := s.photos.ListPhotos(ctx, event.ID)
+	if err != nil {
+		return viewmodels.EventViewModel{}, nil, err
+	}
+
+	org, err := s.orgs.GetOrganisation(ctx, event.OrganisationID)
+	if err != nil {
+		return viewmodels.EventViewModel{}, nil, err
+	}
+
+	detail := viewmodels.NewEventDetailViewModel(event, vms, now)
+	detail.Organizer = org.Name
+	detail.TimeZone = service.EventLocation(event)
+	detail.Branding = viewmodels.NewBrandingViewModel(org)
+	// Branding changes leave the event's updated_at alone
+	parts = append(parts, org.Name, org.BrandColour, org.LogoKey, org.BannerKey)
+	for _, photo := range photos {
+		detail.Photos = append(detail.Photos, viewmodels.EventPhotoURL(year, slug, photo.ID))
+		// Photos come and go without touching the event's updated_at
+		parts = append(parts, photo.ID)
+	}
+
+	if event.SeriesID.Valid {
+		editions, err := s.events.ListEventsInSeries(ctx, event.SeriesID.Int64)
+		if err != nil {
+			return viewmodels.EventViewModel{}, nil, err
+		}
+		detail.PreviousEditions = viewmodels.NewEditionViewModels(editions, event.Year)
+		// Results published on an earlier edition leave its updated_at alone
+		for _, e := range editions {
+			parts = append(parts, e.Event.ID, e.ResultRaceSlugs)
+			updated = append(updated, e.Event.UpdatedAt)
+		}
+	}
+	parts = append(parts, detail.Badges())
+	return detail, append(parts, latestUpdate(updated...)), nil
+}
+
+// setRacePrice shows price as what an entry to the race costs now, with the
+// date it changes on read in loc.
+func setRacePrice(vm *viewmodels.RaceViewModel, price service.Price, loc *time.Location) {
+	var next *int32
+	var until time.Time
+	if price.Next != nil {
+		next = &price.Next.Units
+	}
+	if !price.Until.IsZero() {
+		until = price.Until.In(loc)
+	}
+	vm.SetPrice(price.Units, next, until, price.PlacesLeft)
+}
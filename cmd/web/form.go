@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"firecrest/internal/service"
+)
+
+// decodeForm parses the request's form and copies it into the struct dst
+// points to. Each field to fill names its form key in a form tag, optionally
+// followed by rules:
+//
+//	Name string `form:"name,required,max=200"`
+//
+// required rejects an empty value and max=N one longer than N characters. A
+// label tag names the field in messages, defaulting to the key with
+// underscores as spaces. Strings are trimmed, integers parsed and bools set
+// for "on", "true" or "1". String slices collect every non-empty value sent
+// under the key, as from a group of checkboxes; required then rejects none
+// and max applies to each.
+//
+// Problems with the values are returned as service.FieldErrors keyed by form
+// key, after every field has been filled in; values that break a rule are
+// kept whole so the form can show them again. Any other error means the body
+// could not be read. decodeForm panics if dst is not a pointer to a struct or
+// a tag is malformed.
+func decodeForm(r *http.Request, dst any) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("decodeForm: dst must be a pointer to a struct, got %T", dst))
+	}
+	v = v.Elem()
+
+	errs := service.FieldErrors{}
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		tag, ok := field.Tag.Lookup("form")
+		if !ok || tag == "-" {
+			continue
+		}
+		rules := parseFormTag(field, tag)
+		if field.Type == reflect.TypeFor[[]string]() {
+			values := formValues(r.PostForm[rules.key])
+			if msg := rules.checkAll(values); msg != "" {
+				errs.Add(rules.key, msg)
+			}
+			v.Field(i).Set(reflect.ValueOf(values))
+			continue
+		}
+		value := strings.TrimSpace(r.PostForm.Get(rules.key))
+		if msg := rules.check(value); msg != "" {
+			errs.Add(rules.key, msg)
+		}
+		if msg := setFormValue(v.Field(i), value); msg != "" {
+			errs.Add(rules.key, rules.label+" "+msg)
+		}
+	}
+	return errs.Err()
+}
+
+// formRules are the rules a form tag sets for one field.
+type formRules struct {
+	key      string
+	label    string
+	required bool
+	max      int
+}
+
+// parseFormTag reads the form and label tags of field.
+func parseFormTag(field reflect.StructField, tag string) formRules {
+	parts := strings.Split(tag, ",")
+	rules := formRules{key: parts[0]}
+	for _, opt := range parts[1:] {
+		switch {
+		case opt == "required":
+			rules.required = true
+		case strings.HasPrefix(opt, "max="):
+			n, err := strconv.Atoi(strings.TrimPrefix(opt, "max="))
+			if err != nil || n <= 0 {
+				panic(fmt.Sprintf("decodeForm: field %s has a malformed max rule %q", field.Name, opt))
+			}
+			rules.max = n
+		default:
+			panic(fmt.Sprintf("decodeForm: field %s has an unknown rule %q", field.Name, opt))
+		}
+	}
+	if rules.key == "" {
+		panic("decodeForm: field " + field.Name + " has no form key")
+	}
+
+	rules.label = field.Tag.Get("label")
+	if rules.label == "" {
+		rules.label = strings.ReplaceAll(rules.key, "_", " ")
+	}
+	return rules
+}
+
+// check returns why value breaks the rules, or "".
+func (f formRules) check(value string) string {
+	if f.required && value == "" {
+		return f.label + " is required"
+	}
+	if f.max > 0 && utf8.RuneCountInString(value) > f.max {
+		return fmt.Sprintf("%s must be at most %d characters", f.label, f.max)
+	}
+	return ""
+}
+
+// checkAll returns why the values sent under one key break the rules, or "".
+func (f formRules) checkAll(values []string) string {
+	if f.required && len(values) == 0 {
+		return f.label + " is required"
+	}
+	for _, value := range values {
+		if msg := (formRules{label: f.label, max: f.max}).check(value); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// formValues returns the trimmed values, leaving out empty ones.
+func formValues(raw []string) []string {
+	var values []string
+	for _, value := range raw {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// setFormValue stores value in field, returning why it could not be
+// converted, or "". Empty values leave the field at its zero value.
+func setFormValue(field reflect.Value, value string) string {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		field.SetBool(value == "on" || value == "true" || value == "1")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value == "" {
+			return ""
+		}
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return "must be a number"
+		}
+		field.SetInt(n)
+	default:
+		panic("decodeForm: unsupported field type " + field.Type().String())
+	}
+	return ""
+}
+
+// FormState is a submitted form decoded into Values, with the problems
+// found with it keyed by form key, for handlers that show a form again with
+// what was typed until it is right. Values still holds what was submitted
+// when there are errors, so fields a page must never show again, such as
+// passwords, are left out of its view model.
+type FormState[T any] struct {
+	Values T
+	Errors map[string]string
+}
+
+// decodeFormState decodes the request's form into Values as decodeForm
+// does, keeping problems with the values as Errors. An error means the body
+// could not be read.
+func decodeFormState[T any](r *http.Request) (FormState[T], error) {
+	var state FormState[T]
+	err := decodeForm(r, &state.Values)
+	if msgs, ok := fieldErrors(err); ok {
+		state.Errors = msgs
+	} else if err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// Invalid reports whether any problem has been found with the form.
+func (f *FormState[T]) Invalid() bool {
+	return len(f.Errors) > 0
+}
+
+// AddError records msg against the field with the form key, replacing any
+// problem already found with it.
+func (f *FormState[T]) AddError(key, msg string) {
+	if f.Errors == nil {
+		f.Errors = make(map[string]string)
+	}
+	f.Errors[key] = msg
+}
+
+// AddFieldErrors records the per-field problems err reports, as a service
+// rejecting the values does, and reports whether there were any.
+func (f *FormState[T]) AddFieldErrors(err error) bool {
+	msgs, ok := fieldErrors(err)
+	for key, msg := range msgs {
+		f.AddError(key, msg)
+	}
+	return ok
+}
+
+// fieldErrors returns the per-field messages held by err, ready to show
+// beside each field, or false if err does not report problems with fields.
+func fieldErrors(err error) (map[string]string, bool) {
+	var errs service.FieldErrors
+	if !errors.As(err, &errs) {
+		return nil, false
+	}
+	msgs := make(map[string]string, len(errs))
+	for field, msg := range errs {
+		msgs[field] = capitalise(msg)
+	}
+	return msgs, true
+}
+
+// capitalise upper-cases the first letter of s.
+func capitalise(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
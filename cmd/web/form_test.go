@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"firecrest/internal/service"
+)
+
+func newDecodeRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestDecodeForm(t *testing.T) {
+	type testForm struct {
+		Name    string `form:"name,required,max=5"`
+		Year    int32  `form:"year,required"`
+		Count   int    `form:"count"`
+		Agree   bool   `form:"agree"`
+		Ignored string
+	}
+
+	t.Run("fills in each tagged field", func(t *testing.T) {
+		var got testForm
+		err := decodeForm(newDecodeRequest(url.Values{
+			"name":    {"  Ada "},
+			"year":    {"2026"},
+			"count":   {"3"},
+			"agree":   {"on"},
+			"Ignored": {"x"},
+		}), &got)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := testForm{Name: "Ada", Year: 2026, Count: 3, Agree: true}
+		if got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("reports missing required fields", func(t *testing.T) {
+		var got testForm
+		msgs, ok := fieldErrors(decodeForm(newDecodeRequest(url.Values{"name": {"   "}}), &got))
+		if !ok {
+			t.Fatal("expected field errors")
+		}
+		if msgs["name"] != "Name is required" || msgs["year"] != "Year is required" {
+			t.Errorf("unexpected messages: %v", msgs)
+		}
+		if _, ok := msgs["count"]; ok {
+			t.Error("expected optional fields to be left alone")
+		}
+	})
+
+	t.Run("reports values that are not numbers", func(t *testing.T) {
+		var got testForm
+		err := decodeForm(newDecodeRequest(url.Values{"name": {"Ada"}, "year": {"twenty"}, "count": {"1.5"}}), &got)
+		if !errors.Is(err, service.ErrInvalidInput) {
+			t.Fatalf("expected invalid input, got %v", err)
+		}
+		msgs, _ := fieldErrors(err)
+		if msgs["year"] != "Year must be a number" || msgs["count"] != "Count must be a number" {
+			t.Errorf("unexpected messages: %v", msgs)
+		}
+		if got.Name != "Ada" {
+			t.Errorf("expected the valid fields to be filled in, got %+v", got)
+		}
+	})
+
+	t.Run("rejects values over the maximum length without truncating them", func(t *testing.T) {
+		var got testForm
+		msgs, ok := fieldErrors(decodeForm(newDecodeRequest(url.Values{"name": {"Adélaïde"}, "year": {"2026"}}), &got))
+		if !ok || msgs["name"] != "Name must be at most 5 characters" {
+			t.Errorf("unexpected messages: %v", msgs)
+		}
+		if got.Name != "Adélaïde" {
+			t.Errorf("expected the value to be kept whole, got %q", got.Name)
+		}
+	})
+
+	t.Run("counts characters rather than bytes", func(t *testing.T) {
+		var got testForm
+		if err := decodeForm(newDecodeRequest(url.Values{"name": {"Zoë"}, "year": {"2026"}}), &got); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("names fields by their label", func(t *testing.T) {
+		type labelled struct {
+			OrganisationID int64  `form:"organisation_id,required"`
+			Website        string `form:"website,required" label:"web address"`
+		}
+		var got labelled
+		msgs, _ := fieldErrors(decodeForm(newDecodeRequest(url.Values{}), &got))
+		if msgs["organisation_id"] != "Organisation id is required" || msgs["website"] != "Web address is required" {
+			t.Errorf("unexpected messages: %v", msgs)
+		}
+	})
+
+	t.Run("collects every value of a string slice", func(t *testing.T) {
+		type checkboxes struct {
+			Scopes []string `form:"scopes,required,max=5"`
+		}
+		var got checkboxes
+		err := decodeForm(newDecodeRequest(url.Values{"scopes": {" read ", "", "write"}}), &got)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got.Scopes, []string{"read", "write"}) {
+			t.Errorf("expected the non-empty values, got %q", got.Scopes)
+		}
+
+		msgs, _ := fieldErrors(decodeForm(newDecodeRequest(url.Values{"scopes": {""}}), &got))
+		if msgs["scopes"] != "Scopes is required" {
+			t.Errorf("expected a slice with no values to be missing, got %v", msgs)
+		}
+		msgs, _ = fieldErrors(decodeForm(newDecodeRequest(url.Values{"scopes": {"read", "delete"}}), &got))
+		if msgs["scopes"] != "Scopes must be at most 5 characters" {
+			t.Errorf("expected each value to be checked, got %v", msgs)
+		}
+	})
+
+	t.Run("returns errors reading the body as they are", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/?bad=%zz", http.NoBody)
+		var got testForm
+		err := decodeForm(req, &got)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if _, ok := fieldErrors(err); ok {
+			t.Errorf("expected a parse error rather than field errors, got %v", err)
+		}
+	})
+
+	t.Run("panics unless given a pointer to a struct", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected decodeForm to panic")
+			}
+		}()
+		var got testForm
+		_ = decodeForm(newDecodeRequest(url.Values{}), got)
+	})
+}
+
+func TestDecodeFormState(t *testing.T) {
+	type testForm struct {
+		Email    string `form:"email,required"`
+		Password string `form:"password,required"`
+	}
+
+	t.Run("keeps the values beside their problems", func(t *testing.T) {
+		state, err := decodeFormState[testForm](newDecodeRequest(url.Values{"email": {" jane@example.com "}}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if state.Values.Email != "jane@example.com" {
+			t.Errorf("expected the email kept, got %q", state.Values.Email)
+		}
+		if !state.Invalid() || state.Errors["password"] != "Password is required" {
+			t.Errorf("expected a password error, got %v", state.Errors)
+		}
+	})
+
+	t.Run("adds problems found later", func(t *testing.T) {
+		state, err := decodeFormState[testForm](newDecodeRequest(url.Values{"email": {"jane"}, "password": {"secret"}}))
+		if err != nil || state.Invalid() {
+			t.Fatalf("expected a valid form, got %v (err %v)", state.Errors, err)
+		}
+
+		if state.AddFieldErrors(errors.New("database unavailable")) {
+			t.Error("expected an error without field problems to add none")
+		}
+		state.AddError("password", "Password is too short")
+		if !state.AddFieldErrors(service.FieldErrors{"email": "invalid email address"}) {
+			t.Error("expected field problems to be added")
+		}
+		want := map[string]string{"email": "Invalid email address", "password": "Password is too short"}
+		if !reflect.DeepEqual(state.Errors, want) {
+			t.Errorf("expected %v, got %v", want, state.Errors)
+		}
+	})
+}
+
+// The event form's length limits must agree with the service's, or one of
+// them rejects input the other allows.
+func TestEventFormLimits(t *testing.T) {
+	limits := map[string]int{
+		"description": service.MaxEventDescriptionLength,
+		"location":    service.MaxEventLocationLength,
+	}
+	typ := reflect.TypeFor[eventForm]()
+	for key, want := range limits {
+		found := false
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			rules := parseFormTag(field, field.Tag.Get("form"))
+			if rules.key != key {
+				continue
+			}
+			found = true
+			if rules.max != want {
+				t.Errorf("expected %s to allow %d characters, got %d", key, want, rules.max)
+			}
+		}
+		if !found {
+			t.Errorf("expected eventForm to have a %s field", key)
+		}
+	}
+}
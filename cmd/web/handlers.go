@@ -1,11 +1,26 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"firecrest/db"
+	"firecrest/internal/export"
+	"firecrest/internal/repository"
 	"firecrest/internal/service"
+	"firecrest/internal/stripe"
 	"firecrest/ui/templates"
+	"firecrest/ui/templates/account"
+	"firecrest/ui/templates/admin"
 	"firecrest/ui/templates/auth"
 	"firecrest/ui/viewmodels"
 )
@@ -19,22 +34,490 @@ func (app *application) health(w http.ResponseWriter, r *http.Request) {
 }
 
 func (app *application) home(w http.ResponseWriter, r *http.Request) {
-	// Use mock data for UI development
-	events := viewmodels.GetMockEvents()
-	app.render(r.Context(), w, http.StatusOK, templates.Home(events))
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	vms, parts, err := app.events().HomeEvents(ctx, app.clock.Now())
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	if notModified(w, r, pageETag(r, parts...)) {
+		return
+	}
+
+	app.render(r.Context(), w, http.StatusOK, templates.Home(vms))
+}
+
+// eventListing lists a year's events, by default the current year or, when
+// it has none, the latest year that does.
+func (app *application) eventListing(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	years, err := app.eventService.ListYears(ctx)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	year := listingYear(years, app.clock.Now())
+	if raw := r.URL.Query().Get("year"); raw != "" {
+		y, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || y < 1 {
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		}
+		year = int32(y)
+	}
+	includePast := r.URL.Query().Get("include_past") == "1"
+	var bucket viewmodels.DistanceBucket
+	if raw := r.URL.Query().Get("distance"); raw != "" {
+		b, ok := viewmodels.ParseDistanceBucket(raw)
+		if !ok {
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		}
+		bucket = b
+	}
+
+	events, err := app.eventService.ListEventsByYear(ctx, year, includePast)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vms, err := app.eventCards(ctx, events, distanceUnit(r))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	// The filters fetch just the results with htmx from the same URLs as the
+	// full page, so caches must keep the two answers apart
+	w.Header().Add("Vary", "HX-Request")
+	page := viewmodels.NewEventListingViewModel(year, years, includePast, bucket, vms)
+	if isHTMX(r) {
+		app.renderPartial(w, r, http.StatusOK, templates.EventResults(page))
+		return
+	}
+	app.render(r.Context(), w, http.StatusOK, templates.EventListing(page))
+}
+
+// listingYear returns the year the event listing opens on: the current year
+// if it has events, otherwise the latest year that does. years is latest
+// first.
+func listingYear(years []int32, now time.Time) int32 {
+	current := int32(now.Year())
+	if len(years) == 0 || slices.Contains(years, current) {
+		return current
+	}
+	return years[0]
+}
+
+func (app *application) eventArchive(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	archive, err := app.eventService.ListArchive(ctx)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	// Load every year's cards at once, then split them back into years
+	var events []db.Event
+	for _, year := range archive {
+		events = append(events, year.Events...)
+	}
+	vms, err := app.eventCards(ctx, events, distanceUnit(r))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	page := viewmodels.EventArchiveViewModel{}
+	for _, year := range archive {
+		n := len(year.Events)
+		page.Years = append(page.Years, viewmodels.ArchiveYear{Year: year.Year, Events: vms[:n]})
+		vms = vms[n:]
+	}
+
+	app.render(r.Context(), w, http.StatusOK, templates.EventArchive(page))
+}
+
+// eventCards builds listing cards for events, with their races' current
+// prices and registration counts and their distances in unit.
+func (app *application) eventCards(ctx context.Context, events []db.Event, unit db.DistanceUnit) ([]viewmodels.EventViewModel, error) {
+	eventIDs := make([]int64, 0, len(events))
+	for _, e := range events {
+		eventIDs = append(eventIDs, e.ID)
+	}
+
+	races, err := app.raceService.ListRacesByEvents(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+	var all []db.Race
+	for _, eventRaces := range races {
+		all = append(all, eventRaces...)
+	}
+	now := app.clock.Now()
+	current, err := app.raceService.CurrentPrices(ctx, all, now)
+	if err != nil {
+		return nil, err
+	}
+	prices := make(map[int64]int32, len(current))
+	for id, price := range current {
+		prices[id] = price.Units
+	}
+	registered, err := app.registrationCounter.CountByEvents(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+	vms := viewmodels.NewEventListViewModels(events, races, prices, registered, now)
+	for i := range vms {
+		vms[i].ShowDistancesIn(unit)
+	}
+	return vms, nil
+}
+
+// distanceUnit returns the unit the signed-in user reads distances in, and
+// miles for anyone signed out.
+func distanceUnit(r *http.Request) db.DistanceUnit {
+	if user, ok := getUserFromContext(r); ok && user.DistanceUnit != "" {
+		return user.DistanceUnit
+	}
+	return db.DistanceUnitMiles
 }
 
 func (app *application) eventView(w http.ResponseWriter, r *http.Request) {
-	slug := r.PathValue("slug")
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	year, ok := pathYear(r)
+	if !ok {
+		app.notFound(w, r)
+		return
+	}
+	detail, parts, err := app.events().Event(ctx, year, r.PathValue("slug"), app.clock.Now())
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	unit := distanceUnit(r)
+	detail.ShowDistancesIn(unit)
+	detail.SiteURL = app.publicBaseURL
+	parts = append(parts, unit)
+
+	// A flash is shown once, so a page carrying one must not be reused
+	if app.hasFlashes(r) {
+		w.Header().Set("Cache-Control", pageCacheControl)
+	} else if notModified(w, r, pageETag(r, parts...)) {
+		return
+	}
+
+	flashes := app.getAllFlashes(r)
+	app.render(r.Context(), w, http.StatusOK, templates.Event(detail, flashes))
+}
+
+// legacyEventView redirects an event page URL from before pages were found
+// by year and slug to the latest edition.
+func (app *application) legacyEventView(w http.ResponseWriter, r *http.Request) {
+	app.redirectLegacyEvent(w, r, "")
+}
+
+// legacyRaceResults redirects a results page URL from before pages were
+// found by year and slug to the latest edition's.
+func (app *application) legacyRaceResults(w http.ResponseWriter, r *http.Request) {
+	app.redirectLegacyEvent(w, r, "/results/"+r.PathValue("raceSlug"))
+}
+
+// redirectLegacyEvent permanently redirects to suffix under the URL of the
+// latest edition of the event named by the {slug} path value, keeping the
+// query. Slugs used by more than one organisation cannot be resolved and
+// are not found.
+func (app *application) redirectLegacyEvent(w http.ResponseWriter, r *http.Request, suffix string) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, err := app.eventService.FindEventBySlug(ctx, r.PathValue("slug"))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	target := viewmodels.EventURL(event.Year, event.Slug) + suffix
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
+
+// contactLimit and contactWindow bound how many contact forms one client
+// may send, to the organisers of any event: enough for a few honest
+// questions, too few for a spammer.
+const (
+	contactLimit  = 5
+	contactWindow = time.Hour
+)
+
+func (app *application) contactView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadPublicEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+	stamp, err := app.contactService.FormStamp()
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	vm := viewmodels.NewContactViewModel(event, stamp, service.MaxEnquiryLength)
+	app.render(r.Context(), w, http.StatusOK, templates.Contact(vm, app.getAllFlashes(r)))
+}
+
+// contactForm is a question for an event's organisers as submitted. Website
+// is the honeypot field, hidden from people; the service checks the rest in
+// full.
+type contactForm struct {
+	Name    string `form:"name,required"`
+	Email   string `form:"email,required"`
+	Message string `form:"message,required"`
+	Website string `form:"website"`
+	Stamp   string `form:"stamp"`
+}
+
+func (app *application) contactPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadPublicEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+	form, err := decodeFormState[contactForm](r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	input := form.Values
+
+	eventURL := viewmodels.EventURL(event.Year, event.Slug)
+	if !form.Invalid() {
+		err = app.contactService.SendEnquiry(ctx, event, service.EnquiryInput{
+			Name:     input.Name,
+			Email:    input.Email,
+			Message:  input.Message,
+			Honeypot: input.Website,
+			Stamp:    input.Stamp,
+		})
+		switch {
+		case err == nil, errors.Is(err, service.ErrSpam):
+			// Bots are told they succeeded, so learn nothing from trying
+			app.addFlash(r, FlashSuccess, "Your message has been sent to the organisers")
+			http.Redirect(w, r, eventURL, http.StatusSeeOther)
+			return
+		case errors.Is(err, service.ErrContactFormTiming):
+			form.AddError("form", "Please check your message and send it again")
+		default:
+			if !form.AddFieldErrors(err) {
+				app.handleServiceError(w, r, err)
+				return
+			}
+		}
+	}
+
+	// The form is served with a fresh stamp, so the time taken to check it
+	// counts towards the next send
+	stamp, err := app.contactService.FormStamp()
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	vm := viewmodels.NewContactViewModel(event, stamp, service.MaxEnquiryLength)
+	vm.Name, vm.Email, vm.Message, vm.Errors = input.Name, input.Email, input.Message, form.Errors
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, templates.Contact(vm, app.getAllFlashes(r)))
+}
+
+// loadPublicEvent fetches the published event named by the {year} and
+// {slug} path values, writing a 404 if there is none.
+func (app *application) loadPublicEvent(ctx context.Context, w http.ResponseWriter, r *http.Request) (db.Event, bool) {
+	year, ok := pathYear(r)
+	if !ok {
+		app.notFound(w, r)
+		return db.Event{}, false
+	}
+	event, err := app.eventService.GetEvent(ctx, year, r.PathValue("slug"))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return db.Event{}, false
+	}
+	return event, true
+}
+
+// pathYear returns the {year} path value of a public event URL, reporting
+// false if it is not a year.
+func pathYear(r *http.Request) (int32, bool) {
+	year, err := strconv.ParseInt(r.PathValue("year"), 10, 32)
+	return int32(year), err == nil
+}
+
+func (app *application) raceResults(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
 
-	// Use mock data for UI development
-	event := viewmodels.GetMockEvent(slug)
-	if event == nil {
-		app.notFound(w)
+	year, ok := pathYear(r)
+	if !ok {
+		app.notFound(w, r)
+		return
+	}
+	event, err := app.eventService.GetEvent(ctx, year, r.PathValue("slug"))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	race, err := app.raceService.GetRace(ctx, event.ID, r.PathValue("raceSlug"))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	query := service.ResultsQuery{
+		Sort:   service.ParseResultSort(r.URL.Query().Get("sort")),
+		Desc:   r.URL.Query().Get("order") == "desc",
+		Search: strings.TrimSpace(r.URL.Query().Get("q")),
+	}
+	// Results still being checked are simply not there
+	results, err := app.resultService.PublishedResults(ctx, race.Race.ID, query)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	app.render(r.Context(), w, http.StatusOK, templates.RaceResults(viewmodels.ResultsPageViewModel{
+		EventName: event.Name,
+		EventSlug: event.Slug,
+		EventYear: event.Year,
+		RaceName:  race.Race.Name,
+		RaceSlug:  race.Race.Slug,
+		Sort:      string(query.Sort),
+		Desc:      query.Desc,
+		Search:    query.Search,
+		Results:   viewmodels.NewResultViewModels(results),
+	}))
+}
+
+func (app *application) raceRoute(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	year, ok := pathYear(r)
+	if !ok {
+		app.notFound(w, r)
+		return
+	}
+	event, err := app.eventService.GetEvent(ctx, year, r.PathValue("slug"))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	race, err := app.raceService.GetRace(ctx, event.ID, r.PathValue("raceSlug"))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	gpx, err := app.raceService.RouteGPX(ctx, race.Race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gpx+xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.gpx"`, event.Slug, race.Race.Slug))
+	//nolint:errcheck // the client may have gone away
+	w.Write(gpx)
+}
+
+// eventPhoto redirects to a short-lived URL for one of an event's photos.
+// Event pages link here rather than to the store, as a cached page would
+// outlive the URLs signed when it was rendered.
+func (app *application) eventPhoto(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	year, ok := pathYear(r)
+	if !ok {
+		app.notFound(w, r)
+		return
+	}
+	photoID, err := strconv.ParseInt(r.PathValue("photoID"), 10, 64)
+	if err != nil || photoID < 1 {
+		app.notFound(w, r)
+		return
+	}
+	event, err := app.eventService.GetEvent(ctx, year, r.PathValue("slug"))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	photo, err := app.photoService.GetPhoto(ctx, event.ID, photoID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	url, err := app.photoService.PhotoURL(ctx, photo)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// organisationLogo redirects to a short-lived URL for the organisation's
+// logo, as eventPhoto does for photos
+func (app *application) organisationLogo(w http.ResponseWriter, r *http.Request) {
+	app.brandingImage(w, r, func(org db.Organisation) string { return org.LogoKey.String })
+}
+
+// organisationBanner redirects to a short-lived URL for the organisation's
+// banner
+func (app *application) organisationBanner(w http.ResponseWriter, r *http.Request) {
+	app.brandingImage(w, r, func(org db.Organisation) string { return org.BannerKey.String })
+}
+
+// brandingImage redirects to the branding image key picks out of the
+// organisation named by the {id} path value, writing a 404 if it has none.
+func (app *application) brandingImage(w http.ResponseWriter, r *http.Request, key func(db.Organisation) string) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+	org, err := app.organisationService.GetOrganisation(ctx, id)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	if key(org) == "" {
+		app.notFound(w, r)
+		return
+	}
+	url, err := app.brandingService.BrandingImageURL(ctx, key(org))
+	if err != nil {
+		app.handleServiceError(w, r, err)
 		return
 	}
 
-	app.render(r.Context(), w, http.StatusOK, templates.Event(*event))
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, url, http.StatusFound)
 }
 
 /*
@@ -42,26 +525,35 @@ func (app *application) eventView(w http.ResponseWriter, r *http.Request) {
 =================
 */
 func (app *application) signInView(w http.ResponseWriter, r *http.Request) {
+	next, _ := safeRedirectPath(r.URL.Query().Get("next"))
 	flashes := app.getAllFlashes(r)
-	app.render(r.Context(), w, http.StatusOK, auth.SignIn(flashes))
+	app.render(r.Context(), w, http.StatusOK, auth.SignIn(next, flashes))
 }
 
 func (app *application) signInPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
 	// Parse form
 	if err := r.ParseForm(); err != nil {
-		app.clientError(w, http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest)
 		return
 	}
 
 	email := r.PostForm.Get("email")
 	password := r.PostForm.Get("password")
 	rememberMe := r.PostForm.Get("remember_me") == "on"
+	// The page to return to is kept through failed attempts, and dropped
+	// if it is not one of ours
+	next, _ := safeRedirectPath(r.PostForm.Get("next"))
 
 	// Authenticate user
-	result, err := app.authService.SignIn(r.Context(), service.SignInInput{
+	result, err := app.authService.SignIn(ctx, service.SignInInput{
 		Email:      email,
 		Password:   password,
 		RememberMe: rememberMe,
+		IPAddress:  getClientIP(r),
+		UserAgent:  r.UserAgent(),
 	})
 
 	if err != nil {
@@ -73,75 +565,148 @@ func (app *application) signInPost(w http.ResponseWriter, r *http.Request) {
 			app.addFlash(r, FlashWarning, "Please verify your email address before signing in")
 		case errors.Is(err, service.ErrAccountLocked):
 			app.addFlash(r, FlashError, "Your account has been locked due to too many failed login attempts. Please try again later.")
+		case errors.Is(err, service.ErrAccountDisabled):
+			app.addFlash(r, FlashError, "Your account has been deactivated. Please contact us if you think this is a mistake.")
 		case errors.Is(err, service.ErrInvalidInput):
 			app.addFlash(r, FlashError, "Please provide both email and password")
 		default:
-			app.serverError(w, r, err)
+			app.handleServiceError(w, r, err)
 			return
 		}
-		http.Redirect(w, r, "/auth/sign-in", http.StatusSeeOther)
+		http.Redirect(w, r, signInURL(next), http.StatusSeeOther)
 		return
 	}
 
-	// Regenerate session token
-	if err := app.sessionManager.RenewToken(r.Context()); err != nil {
+	// Regenerate the session token and store the user in it
+	if err := app.startSession(ctx, r, result.User.ID, result.RememberMe); err != nil {
+		if errors.Is(err, service.ErrTooManySessions) {
+			app.addFlash(r, FlashError, "You are signed in on too many devices. Sign out on one of them, then try again.")
+			http.Redirect(w, r, signInURL(next), http.StatusSeeOther)
+			return
+		}
 		app.serverError(w, r, err)
 		return
 	}
-
-	// Store userID in session
-	app.sessionManager.Put(r.Context(), "userID", result.User.ID)
+	if !result.EmailVerified {
+		app.sessionManager.Put(r.Context(), sessionEmailUnverifiedKey, true)
+	}
 
 	app.addFlash(r, FlashSuccess, "Welcome back!")
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	if next == "" {
+		next = "/"
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
 }
 
 func (app *application) signUpView(w http.ResponseWriter, r *http.Request) {
 	flashes := app.getAllFlashes(r)
-	app.render(r.Context(), w, http.StatusOK, auth.SignUp(flashes))
+	app.render(r.Context(), w, http.StatusOK, auth.SignUp(viewmodels.SignUpFormViewModel{}, flashes))
+}
+
+// signUpForm is the sign-up form as submitted. The service checks the
+// email and password in full.
+type signUpForm struct {
+	FirstName   string `form:"first_name,required"`
+	LastName    string `form:"last_name,required"`
+	Email       string `form:"email,required"`
+	Password    string `form:"password,required"`
+	DateOfBirth string `form:"date_of_birth"`
 }
 
 func (app *application) signUpPost(w http.ResponseWriter, r *http.Request) {
-	// Parse form
-	if err := r.ParseForm(); err != nil {
-		app.clientError(w, http.StatusBadRequest)
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	form, err := decodeFormState[signUpForm](r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
 		return
 	}
+	input := form.Values
 
-	email := r.PostForm.Get("email")
-	password := r.PostForm.Get("password")
-	firstName := r.PostForm.Get("first_name")
-	lastName := r.PostForm.Get("last_name")
-
-	// Create user
-	_, err := app.authService.SignUp(r.Context(), service.SignUpInput{
-		Email:     email,
-		Password:  password,
-		FirstName: firstName,
-		LastName:  lastName,
-	})
+	dob, ok := parseFormDate(input.DateOfBirth)
+	if !ok {
+		form.AddError("date_of_birth", "Date of birth must be a date")
+	}
 
-	if err != nil {
+	if !form.Invalid() {
+		_, err = app.authService.SignUp(ctx, service.SignUpInput{
+			Email:       input.Email,
+			Password:    input.Password,
+			FirstName:   input.FirstName,
+			LastName:    input.LastName,
+			DateOfBirth: dob,
+		})
 		// Handle specific errors
 		switch {
+		case err == nil:
+			app.addFlash(r, FlashSuccess, "Account created successfully! Please check your email to verify your account.")
+			http.Redirect(w, r, "/auth/sign-in", http.StatusSeeOther)
+			return
 		case errors.Is(err, service.ErrEmailExists):
-			app.addFlash(r, FlashError, "An account with this email already exists")
+			form.AddError("email", "An account with this email already exists")
 		case errors.Is(err, service.ErrInvalidInput):
-			app.addFlash(r, FlashError, err.Error())
+			if !form.AddFieldErrors(err) {
+				app.addFlash(r, FlashError, err.Error())
+			}
 		default:
-			app.serverError(w, r, err)
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	// Everything typed is shown again but the password
+	vm := viewmodels.SignUpFormViewModel{
+		FirstName:   input.FirstName,
+		LastName:    input.LastName,
+		Email:       input.Email,
+		DateOfBirth: input.DateOfBirth,
+		Errors:      form.Errors,
+	}
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, auth.SignUp(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	err := app.authService.VerifyEmailToken(ctx, r.URL.Query().Get("token"))
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidToken) {
+			app.handleServiceError(w, r, err)
 			return
 		}
-		http.Redirect(w, r, "/auth/sign-up", http.StatusSeeOther)
+		app.addFlash(r, FlashError, "This verification link is invalid or has expired")
+		http.Redirect(w, r, "/auth/sign-in", http.StatusSeeOther)
 		return
 	}
 
-	app.addFlash(r, FlashSuccess, "Account created successfully! Please check your email to verify your account.")
+	// Users signed in during the grace period carry on where they are
+	if app.isAuthenticated(r) {
+		app.sessionManager.Remove(r.Context(), sessionEmailUnverifiedKey)
+		app.addFlash(r, FlashSuccess, "Your email has been verified.")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	app.addFlash(r, FlashSuccess, "Your email has been verified. You can now sign in.")
 	http.Redirect(w, r, "/auth/sign-in", http.StatusSeeOther)
 }
 
 func (app *application) signOut(w http.ResponseWriter, r *http.Request) {
-	// Destroy the session
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	// Revoke the session's record so it drops off the user's devices
+	if sessionID := app.getSessionID(r); sessionID > 0 {
+		err := app.sessionService.RevokeSession(ctx, app.getRealUserID(r), sessionID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	// Destroy the session, with the remember-me deadline it carries, and
+	// expire its cookie
 	if err := app.sessionManager.Destroy(r.Context()); err != nil {
 		app.serverError(w, r, err)
 		return
@@ -151,31 +716,3917 @@ func (app *application) signOut(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
-func (app *application) adminCreatePost(w http.ResponseWriter, r *http.Request) {
-	_, err := app.eventService.CreateEvent(r.Context(), service.CreateEventInput{
-		OrganisationID: 1,
-		Name:           "Lincoln 10k",
-		Slug:           "lincoln-10k",
-	})
-	if err != nil {
+// signOutEverywhere signs the user out of every device, this one included,
+// as after a lost phone or a password seen over someone's shoulder.
+func (app *application) signOutEverywhere(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	userID := app.getUserID(r)
+	if _, err := app.sessionService.SignOutEverywhere(ctx, userID); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	if err := app.destroyUserSessions(r, userID); err != nil {
 		app.serverError(w, r, err)
 		return
 	}
 
-	// Redirect to home page after creating event
+	app.addFlash(r, FlashInfo, "You have been signed out of every device")
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
-func (app *application) adminCreateUser(w http.ResponseWriter, r *http.Request) {
-	_, err := app.userService.CreateUser(r.Context(), service.CreateUserInput{
-		Email:     "user@example.com",
-		FirstName: "Kristian",
-		LastName:  "Roebuck",
-		Role:      "admin",
-	})
+/*
+* ACCOUNT HANDLERS
+=================
+*/
+func (app *application) accountRegistrations(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
 
+	regs, err := app.registrationService.ListUserRegistrations(ctx, app.getUserID(r))
 	if err != nil {
-		app.serverError(w, r, err)
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	vms := make([]viewmodels.RegistrationViewModel, 0, len(regs))
+	for _, reg := range regs {
+		vms = append(vms, viewmodels.NewRegistrationViewModel(reg.ListRegistrationsByUserRow, reg.CanCancel, reg.CanTransfer, reg.CanEdit))
+	}
+
+	flashes := app.getAllFlashes(r)
+	app.render(r.Context(), w, http.StatusOK, account.Registrations(viewmodels.NewAccountRegistrationsViewModel(vms, app.clock.Now()), flashes))
+}
+
+// registerForm is the form posted to enter a race. Races with a
+// questionnaire are entered in two steps: the first post shows the
+// questions, and the second carries the answers with Questionnaire set.
+type registerForm struct {
+	DiscountCode string `form:"discount_code"`
+	// WaveID is the start wave the entrant asked for; zero leaves the
+	// choice to Firecrest
+	WaveID                int64  `form:"wave_id"`
+	Questionnaire         bool   `form:"questionnaire"`
+	EmergencyContactName  string `form:"emergency_contact_name"`
+	EmergencyContactPhone string `form:"emergency_contact_phone"`
+	MedicalConditions     string `form:"medical_conditions"`
+	Club                  string `form:"club"`
+	EstimatedFinish       string `form:"estimated_finish"`
+	// DateOfBirth is asked for by races with a minimum age, from entrants
+	// who have not given it before
+	DateOfBirth string `form:"date_of_birth"`
+}
+
+// customValues returns the values posted for custom fields, keyed by field
+// ID. An unticked checkbox posts nothing, and is read as blank.
+func customValues(form url.Values) map[int64]string {
+	values := map[int64]string{}
+	for key := range form {
+		id, ok := strings.CutPrefix(key, "custom_")
+		if !ok {
+			continue
+		}
+		if fieldID, err := strconv.ParseInt(id, 10, 64); err == nil {
+			values[fieldID] = form.Get(key)
+		}
+	}
+	return values
+}
+
+// answers returns the questionnaire answers posted.
+func (f registerForm) answers() service.Answers {
+	return service.Answers{
+		EmergencyContactName:  strings.TrimSpace(f.EmergencyContactName),
+		EmergencyContactPhone: strings.TrimSpace(f.EmergencyContactPhone),
+		MedicalConditions:     strings.TrimSpace(f.MedicalConditions),
+		Club:                  strings.TrimSpace(f.Club),
+		EstimatedFinish:       strings.TrimSpace(f.EstimatedFinish),
+	}
+}
+
+func (app *application) registerPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	var input registerForm
+	if err := decodeForm(r, &input); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	year, ok := pathYear(r)
+	if !ok {
+		app.notFound(w, r)
+		return
+	}
+	event, err := app.eventService.GetEvent(ctx, year, r.PathValue("slug"))
+	var race service.RaceAvailability
+	if err == nil {
+		race, err = app.raceService.GetRace(ctx, event.ID, r.PathValue("raceSlug"))
+	}
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	eventURL := viewmodels.EventURL(event.Year, event.Slug)
+	answers := input.answers()
+	custom := customValues(r.PostForm)
+	user, _ := getUserFromContext(r)
+
+	// Custom fields may all be optional, so races with any are always
+	// shown the questionnaire before the entry is made
+	if !input.Questionnaire {
+		fields, err := app.raceService.CustomFields(ctx, race.Race.ID)
+		if err != nil {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		if len(fields) > 0 {
+			app.renderQuestionnaire(w, r, input, answers, custom, event, race.Race, nil)
+			return
+		}
+	}
+
+	if input.DateOfBirth != "" && !user.DateOfBirth.Valid {
+		// Kept on the account, so it is only asked for once
+		dob, ok := parseFormDate(input.DateOfBirth)
+		if !ok {
+			app.renderQuestionnaire(w, r, input, answers, custom, event, race.Race, map[string]string{"date_of_birth": "Date of birth must be a date"})
+			return
+		}
+		_, err := app.userService.UpdateProfile(ctx, app.getUserID(r), service.ProfileInput{
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+			DateOfBirth:  dob,
+			DistanceUnit: user.DistanceUnit,
+		})
+		if errs, ok := fieldErrors(err); ok {
+			app.renderQuestionnaire(w, r, input, answers, custom, event, race.Race, errs)
+			return
+		}
+		if err != nil {
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	reg, err := app.registrationService.Register(ctx, app.getUserID(r), race.Race, input.WaveID, input.DiscountCode, answers, custom)
+	if errs, ok := fieldErrors(err); ok {
+		app.renderQuestionnaire(w, r, input, answers, custom, event, race.Race, errs)
+		return
+	}
+	switch {
+	case err == nil:
+		app.addFlash(r, FlashSuccess, fmt.Sprintf("You're registered for the %s", race.Race.Name))
+		http.Redirect(w, r, viewmodels.AccountRegistrationURL(reg.ID), http.StatusSeeOther)
+	case errors.Is(err, service.ErrAlreadyRegistered):
+		app.addFlash(r, FlashInfo, fmt.Sprintf("You're already registered for the %s. Here's your entry", race.Race.Name))
+		http.Redirect(w, r, viewmodels.AccountRegistrationURL(reg.ID), http.StatusSeeOther)
+	case errors.Is(err, service.ErrRegistrationClosed):
+		app.addFlash(r, FlashError, fmt.Sprintf("Registration for the %s is not open", race.Race.Name))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	case errors.Is(err, service.ErrRaceFull):
+		app.addFlash(r, FlashError, fmt.Sprintf("Sorry, the %s is full", race.Race.Name))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	case errors.Is(err, service.ErrWaveNotFound):
+		app.addFlash(r, FlashError, fmt.Sprintf("That start wave is no longer available for the %s. Please choose another", race.Race.Name))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	case errors.Is(err, service.ErrEmailNotVerified):
+		app.addFlash(r, FlashWarning, fmt.Sprintf("Please verify your email address before entering the %s. We sent you a link when you signed up", race.Race.Name))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	case errors.Is(err, service.ErrTooYoung):
+		app.addFlash(r, FlashError, fmt.Sprintf("Sorry, the %s is only open to entrants aged %d and over on race day", race.Race.Name, race.Race.MinAge.Int32))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	case errors.Is(err, service.ErrDiscountCodeNotFound):
+		app.addFlash(r, FlashError, fmt.Sprintf("We don't recognise the discount code %s", input.DiscountCode))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	case errors.Is(err, service.ErrDiscountCodeWrongRace):
+		app.addFlash(r, FlashError, fmt.Sprintf("The discount code %s can't be used for the %s", input.DiscountCode, race.Race.Name))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	case errors.Is(err, service.ErrDiscountCodeNotYetValid):
+		app.addFlash(r, FlashError, fmt.Sprintf("The discount code %s can't be used yet", input.DiscountCode))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	case errors.Is(err, service.ErrDiscountCodeExpired):
+		app.addFlash(r, FlashError, fmt.Sprintf("The discount code %s has expired", input.DiscountCode))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	case errors.Is(err, service.ErrDiscountCodeExhausted):
+		app.addFlash(r, FlashError, fmt.Sprintf("The discount code %s has been used up", input.DiscountCode))
+		http.Redirect(w, r, eventURL, http.StatusSeeOther)
+	default:
+		app.handleServiceError(w, r, err)
+	}
+}
+
+// renderQuestionnaire shows the race's questionnaire with its custom
+// fields, filled in with answers and custom. Entrants who have not seen it
+// yet are shown it without errors.
+func (app *application) renderQuestionnaire(w http.ResponseWriter, r *http.Request, input registerForm, answers service.Answers, custom map[int64]string, event db.Event, race db.Race, errs map[string]string) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	questions, err := app.raceService.RequiredQuestions(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	required := make([]string, len(questions))
+	given := make(map[string]string, len(questions))
+	for i, q := range questions {
+		required[i] = string(q)
+		given[string(q)] = answers.Get(q)
+	}
+
+	fields, err := app.raceService.CustomFields(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	status := http.StatusUnprocessableEntity
+	if !input.Questionnaire {
+		status, errs = http.StatusOK, nil
+	}
+	vm := viewmodels.NewQuestionnaireViewModel(race, event, required, given, errs)
+	for _, f := range fields {
+		vm.CustomQuestions = append(vm.CustomQuestions, viewmodels.CustomQuestion{
+			Name:     f.FormName(),
+			Label:    f.Label,
+			Kind:     string(f.Kind),
+			Options:  f.Options,
+			Required: f.Required,
+			Answer:   custom[f.ID],
+			Error:    errs[f.FormName()],
+		})
+	}
+	vm.DiscountCode = input.DiscountCode
+	vm.WaveID = input.WaveID
+	user, _ := getUserFromContext(r)
+	vm.AskDateOfBirth = race.MinAge.Valid && (!user.DateOfBirth.Valid || input.DateOfBirth != "")
+	vm.DateOfBirth = input.DateOfBirth
+	app.render(r.Context(), w, status, templates.Questionnaire(vm))
+}
+
+func (app *application) cancelRegistrationPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	registrationID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || registrationID < 1 {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	cancellation, err := app.registrationService.CancelRegistration(ctx, app.getUserID(r), registrationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAlreadyCancelled):
+			app.addFlash(r, FlashInfo, "This registration has already been cancelled")
+			http.Redirect(w, r, "/account/registrations", http.StatusSeeOther)
+		case errors.Is(err, service.ErrCancellationClosed):
+			app.addFlash(r, FlashError, "The cancellation deadline for this race has passed")
+			http.Redirect(w, r, "/account/registrations", http.StatusSeeOther)
+		default:
+			app.handleServiceError(w, r, err)
+		}
+		return
+	}
+
+	message := "Registration cancelled"
+	if cancellation.RefundUnits > 0 {
+		message = "Registration cancelled. Your refund is on its way"
+	}
+	app.addFlash(r, FlashSuccess, message)
+	http.Redirect(w, r, "/account/registrations", http.StatusSeeOther)
+}
+
+func (app *application) transferRegistrationPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	registrationID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || registrationID < 1 {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := app.registrationService.TransferRegistration(ctx, app.getUserID(r), registrationID, r.PostForm.Get("email"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			app.addFlash(r, FlashError, err.Error())
+		case errors.Is(err, service.ErrAlreadyCancelled):
+			app.addFlash(r, FlashInfo, "This registration can no longer be transferred")
+		case errors.Is(err, service.ErrTransferClosed):
+			app.addFlash(r, FlashError, "The transfer deadline for this race has passed")
+		case errors.Is(err, service.ErrRecipientRegistered):
+			app.addFlash(r, FlashError, "That runner is already registered for this race")
+		default:
+			app.handleServiceError(w, r, err)
+			return
+		}
+		http.Redirect(w, r, "/account/registrations", http.StatusSeeOther)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Your place has been transferred to "+transfer.RecipientEmail+". We've emailed them a link to accept it.")
+	http.Redirect(w, r, "/account/registrations", http.StatusSeeOther)
+}
+
+func (app *application) registrationDetailsView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	registrationID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || registrationID < 1 {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	details, err := app.registrationService.RegistrationDetails(ctx, app.getUserID(r), registrationID)
+	if err != nil {
+		app.registrationDetailsError(w, r, registrationID, err)
+		return
+	}
+	app.renderRegistrationDetails(w, r, http.StatusOK, details, details.Answers, nil)
+}
+
+func (app *application) registrationDetailsPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	registrationID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || registrationID < 1 {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	var answers service.Answers
+	for _, q := range service.Questions {
+		answers.Set(q, strings.TrimSpace(r.PostForm.Get(string(q))))
+	}
+
+	userID := app.getUserID(r)
+	err = app.registrationService.UpdateRegistrationDetails(ctx, userID, registrationID, answers)
+	if err == nil {
+		app.addFlash(r, FlashSuccess, "Your details have been saved")
+		http.Redirect(w, r, viewmodels.AccountRegistrationURL(registrationID), http.StatusSeeOther)
+		return
+	}
+	errs, invalid := fieldErrors(err)
+	if !invalid {
+		app.registrationDetailsError(w, r, registrationID, err)
+		return
+	}
+
+	details, err := app.registrationService.RegistrationDetails(ctx, userID, registrationID)
+	if err != nil {
+		app.registrationDetailsError(w, r, registrationID, err)
+		return
+	}
+	app.renderRegistrationDetails(w, r, http.StatusUnprocessableEntity, details, answers, errs)
+}
+
+// registrationDetailsError sends the entrant back to their registrations
+// once their details can no longer be changed, and handles anything else
+// as a service error. Registrations that are not theirs are not found.
+func (app *application) registrationDetailsError(w http.ResponseWriter, r *http.Request, registrationID int64, err error) {
+	if errors.Is(err, service.ErrEditClosed) {
+		app.addFlash(r, FlashError, "Your race is too close to change your details. Contact the organisers if something is wrong.")
+		http.Redirect(w, r, viewmodels.AccountRegistrationURL(registrationID), http.StatusSeeOther)
+		return
+	}
+	app.handleServiceError(w, r, err)
+}
+
+// renderRegistrationDetails shows the form for changing the registration's
+// details filled in with answers. Locked answers always show what is
+// stored, as they cannot be changed.
+func (app *application) renderRegistrationDetails(w http.ResponseWriter, r *http.Request, status int, details service.RegistrationDetails, answers service.Answers, errs map[string]string) {
+	asked := make([]string, len(details.Questions))
+	given := make(map[string]string, len(details.Questions))
+	for i, q := range details.Questions {
+		asked[i] = string(q)
+		given[string(q)] = answers.Get(q)
+		if details.IsLocked(q) {
+			given[string(q)] = details.Answers.Get(q)
+		}
+	}
+	required := make([]string, len(details.Required))
+	for i, q := range details.Required {
+		required[i] = string(q)
+	}
+	locked := make([]string, len(details.Locked))
+	for i, q := range details.Locked {
+		locked[i] = string(q)
+	}
+
+	vm := viewmodels.NewRegistrationDetailsViewModel(details.RegistrationID, details.RaceName, details.EventName, asked, required, locked, given, errs, details.EditableUntil)
+	app.render(r.Context(), w, status, account.RegistrationDetails(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) profileView(w http.ResponseWriter, r *http.Request) {
+	user, _ := getUserFromContext(r)
+	app.render(r.Context(), w, http.StatusOK, account.Profile(viewmodels.NewProfileViewModel(user), app.getAllFlashes(r)))
+}
+
+// profileForm is the user's details as submitted. The service checks them
+// in full.
+type profileForm struct {
+	FirstName    string `form:"first_name,required"`
+	LastName     string `form:"last_name,required"`
+	DateOfBirth  string `form:"date_of_birth"`
+	DistanceUnit string `form:"distance_unit"`
+}
+
+func (app *application) profilePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	var input profileForm
+	err := decodeForm(r, &input)
+	formErrors, invalid := fieldErrors(err)
+	if err != nil && !invalid {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	dob, ok := parseFormDate(input.DateOfBirth)
+	if !ok {
+		if formErrors == nil {
+			formErrors = make(map[string]string)
+		}
+		formErrors["date_of_birth"], invalid = "Date of birth must be a date", true
+	}
+
+	user, _ := getUserFromContext(r)
+	if !invalid {
+		_, err = app.userService.UpdateProfile(ctx, user.ID, service.ProfileInput{
+			FirstName:    input.FirstName,
+			LastName:     input.LastName,
+			DateOfBirth:  dob,
+			DistanceUnit: db.DistanceUnit(input.DistanceUnit),
+		})
+		if err == nil {
+			app.addFlash(r, FlashSuccess, "Your details have been saved")
+			http.Redirect(w, r, "/account/profile", http.StatusSeeOther)
+			return
+		}
+		if formErrors, invalid = fieldErrors(err); !invalid {
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	form := viewmodels.ProfileViewModel{
+		FirstName:    input.FirstName,
+		LastName:     input.LastName,
+		Email:        user.Email,
+		DateOfBirth:  input.DateOfBirth,
+		DistanceUnit: db.DistanceUnit(input.DistanceUnit),
+		Errors:       formErrors,
+	}
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, account.Profile(form, app.getAllFlashes(r)))
+}
+
+func (app *application) deleteAccountView(w http.ResponseWriter, r *http.Request) {
+	app.render(r.Context(), w, http.StatusOK, account.DeleteAccount(viewmodels.DeleteAccountViewModel{}, app.getAllFlashes(r)))
+}
+
+// deleteAccountForm is the confirmation posted to delete an account.
+type deleteAccountForm struct {
+	Password string `form:"password,required"`
+}
+
+func (app *application) deleteAccountPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	var input deleteAccountForm
+	err := decodeForm(r, &input)
+	formErrors, invalid := fieldErrors(err)
+	if err != nil && !invalid {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	if !invalid {
+		userID := app.getUserID(r)
+		err = app.authService.DeleteAccount(ctx, userID, input.Password)
+		switch {
+		case err == nil:
+			if err := app.destroyUserSessions(r, userID); err != nil {
+				app.serverError(w, r, err)
+				return
+			}
+			app.addFlash(r, FlashSuccess, "Your account has been deleted")
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return
+		case errors.Is(err, service.ErrInvalidCredentials):
+			formErrors = map[string]string{"password": "That password is not correct"}
+		default:
+			if formErrors, invalid = fieldErrors(err); !invalid {
+				app.serverError(w, r, err)
+				return
+			}
+		}
+	}
+
+	form := viewmodels.DeleteAccountViewModel{Errors: formErrors}
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, account.DeleteAccount(form, app.getAllFlashes(r)))
+}
+
+// dataExportView shows where the user's latest request for a copy of their
+// data has got to or, given the token from the emailed link, downloads it.
+func (app *application) dataExportView(w http.ResponseWriter, r *http.Request) {
+	if tok := r.URL.Query().Get("token"); tok != "" {
+		app.downloadDataExport(w, r, tok)
+		return
+	}
+
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	latest, err := app.dataExportService.LatestExport(ctx, app.getUserID(r))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	vm := viewmodels.NewDataExportViewModel(latest, app.clock.Now())
+	app.render(r.Context(), w, http.StatusOK, account.DataExport(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) dataExportPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	if _, err := app.dataExportService.RequestExport(ctx, app.getUserID(r)); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "We're preparing a copy of your data and will email you when it's ready")
+	http.Redirect(w, r, "/account/data-export", http.StatusSeeOther)
+}
+
+// downloadDataExport sends the archive the token links to. The archive may
+// be large, so only opening it is bounded by the database timeout. The
+// token is checked against the signed-in user rather than anyone they are
+// impersonated by, who could otherwise use it up.
+func (app *application) downloadDataExport(w http.ResponseWriter, r *http.Request, tok string) {
+	archive, err := app.dataExportService.OpenExport(r.Context(), app.getRealUserID(r), tok)
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidToken) {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		app.addFlash(r, FlashError, "This download link is invalid, has expired or has already been used")
+		http.Redirect(w, r, "/account/data-export", http.StatusSeeOther)
+		return
+	}
+	defer archive.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="personal-data.zip"`)
+	w.Header().Set("Cache-Control", "no-store")
+	// The status is sent with the first bytes, so a failure part way
+	// through can only be logged
+	if _, err := io.Copy(w, archive); err != nil && !app.clientGone(r, err) {
+		app.logger.Error("failed to send data export", "request_id", getRequestID(r), "error", err)
+	}
+}
+
+func (app *application) apiTokensView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	tokens, err := app.tokenService.ListAPITokens(ctx, app.getUserID(r))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	vm := viewmodels.NewAPITokensViewModel(tokens, app.clock.Now())
+	app.render(r.Context(), w, http.StatusOK, account.APITokens(vm, app.getAllFlashes(r)))
+}
+
+// apiTokenForm is the form posted to create an API token.
+type apiTokenForm struct {
+	Label         string   `form:"label,required,max=100"`
+	Scopes        []string `form:"scopes,required"`
+	ExpiresInDays int      `form:"expires_in" label:"expiry"`
+}
+
+func (app *application) createAPITokenPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	var input apiTokenForm
+	err := decodeForm(r, &input)
+	formErrors, invalid := fieldErrors(err)
+	if err != nil && !invalid {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	userID := app.getUserID(r)
+	var created service.CreatedAPIToken
+	if !invalid {
+		created, err = app.tokenService.CreateAPIToken(ctx, userID, service.CreateAPITokenParams{
+			Label:     input.Label,
+			Scopes:    input.Scopes,
+			ExpiresIn: time.Duration(input.ExpiresInDays) * 24 * time.Hour,
+		})
+		if err != nil {
+			if formErrors, invalid = fieldErrors(err); !invalid {
+				app.handleServiceError(w, r, err)
+				return
+			}
+		}
+	}
+
+	tokens, err := app.tokenService.ListAPITokens(ctx, userID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vm := viewmodels.NewAPITokensViewModel(tokens, app.clock.Now())
+
+	if invalid {
+		vm.Label = input.Label
+		vm.Scopes = input.Scopes
+		vm.ExpiresIn = ""
+		if input.ExpiresInDays > 0 {
+			vm.ExpiresIn = strconv.Itoa(input.ExpiresInDays)
+		}
+		vm.Errors = formErrors
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, account.APITokens(vm, app.getAllFlashes(r)))
+		return
+	}
+
+	// The token is shown in this response alone, rather than after a
+	// redirect, so it is never kept in the session
+	vm.CreatedToken = created.Plaintext
+	w.Header().Set("Cache-Control", "no-store")
+	app.render(r.Context(), w, http.StatusOK, account.APITokens(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) revokeAPITokenPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	if err := app.tokenService.RevokeAPIToken(ctx, app.getUserID(r), id); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "API token revoked")
+	http.Redirect(w, r, "/account/tokens", http.StatusSeeOther)
+}
+
+func (app *application) sessionsView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	sessions, err := app.sessionService.ListSessions(ctx, app.getRealUserID(r))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	vm := viewmodels.NewSessionsViewModel(sessions, app.getSessionID(r))
+	app.render(r.Context(), w, http.StatusOK, account.Sessions(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) revokeSessionPost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+	// Signing this device out is signing out
+	if id == app.getSessionID(r) {
+		app.signOut(w, r)
+		return
+	}
+
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	if err := app.sessionService.RevokeSession(ctx, app.getRealUserID(r), id); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Signed out of that device")
+	http.Redirect(w, r, "/account/sessions", http.StatusSeeOther)
+}
+
+func (app *application) revokeOtherSessionsPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	n, err := app.sessionService.RevokeOtherSessions(ctx, app.getRealUserID(r), app.getSessionID(r))
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	message := fmt.Sprintf("Signed out of %d other devices", n)
+	if n == 1 {
+		message = "Signed out of 1 other device"
+	}
+	app.addFlash(r, FlashSuccess, message)
+	http.Redirect(w, r, "/account/sessions", http.StatusSeeOther)
+}
+
+func (app *application) acceptTransfers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	err := app.registrationService.AcceptTransfers(ctx, r.URL.Query().Get("token"))
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidToken) {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		app.addFlash(r, FlashError, "This transfer link is invalid or has expired")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Your race place has been accepted")
+	if app.isAuthenticated(r) {
+		http.Redirect(w, r, "/account/registrations", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/auth/sign-in", http.StatusSeeOther)
+}
+
+func (app *application) unsubscribe(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	err := app.authService.Unsubscribe(ctx, r.URL.Query().Get("token"))
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidToken) {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		app.addFlash(r, FlashError, "This unsubscribe link is invalid or has expired")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "You won't receive any more race reminders")
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (app *application) acceptInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	err := app.organisationService.AcceptInvitations(ctx, r.URL.Query().Get("token"))
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidToken) {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		app.addFlash(r, FlashError, "This invitation link is invalid or has expired")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "You have joined the organisation")
+	if app.isAuthenticated(r) {
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/auth/sign-in", http.StatusSeeOther)
+}
+
+/*
+* ADMIN HANDLERS
+=================
+*/
+func (app *application) adminDashboard(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	user, _ := getUserFromContext(r)
+	orgs, err := app.managedOrganisations(ctx, user)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	flashes := app.getAllFlashes(r)
+	if len(orgs) == 0 {
+		app.render(r.Context(), w, http.StatusOK, admin.Dashboard(viewmodels.DashboardViewModel{}, flashes))
+		return
+	}
+
+	orgID, err := app.currentOrganisation(ctx, r, orgs)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	// Links to an organisation's dashboard switch to it
+	if v := r.URL.Query().Get("organisation"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		}
+		if !slices.ContainsFunc(orgs, func(o db.Organisation) bool { return o.ID == id }) {
+			app.notFound(w, r)
+			return
+		}
+		if err := app.useOrganisation(ctx, r, id); err != nil {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		orgID = id
+	}
+
+	stats, err := app.eventService.GetEventStats(ctx, orgID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	vm := viewmodels.NewDashboardViewModel(orgID, orgs, stats)
+	vm.CanManageMembers, err = app.organisationService.CanManageMembers(ctx, user.ID, orgID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	// Site admins see every organisation but only hear about those they
+	// are members of
+	member, err := app.organisationService.GetMembership(ctx, user.ID, orgID)
+	switch {
+	case err == nil:
+		vm.Notifications = member.NotificationPreference
+	case !errors.Is(err, repository.ErrNotFound):
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.render(r.Context(), w, http.StatusOK, admin.Dashboard(vm, flashes))
+}
+
+func (app *application) adminNotificationsPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	orgID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || orgID < 1 {
+		app.notFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	user, _ := getUserFromContext(r)
+	pref := db.NotificationPreference(r.PostForm.Get("notifications"))
+	if err := app.organisationService.SetNotificationPreference(ctx, user.ID, orgID, pref); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Notification settings saved")
+	http.Redirect(w, r, viewmodels.DashboardURL(orgID), http.StatusSeeOther)
+}
+
+func (app *application) adminCreateView(w http.ResponseWriter, r *http.Request) {
+	form := viewmodels.EventFormViewModel{
+		OrganisationID: app.sessionManager.GetInt64(r.Context(), sessionOrganisationIDKey),
+		Year:           strconv.Itoa(app.clock.Now().Year()),
+		Timezone:       service.DefaultEventTimezone,
+	}
+	app.renderEventForm(w, r, http.StatusOK, form)
+}
+
+// eventForm is the admin event form as submitted. The length limits match
+// service.MaxEventDescriptionLength and service.MaxEventLocationLength.
+type eventForm struct {
+	OrganisationID int64  `form:"organisation_id,required" label:"organisation"`
+	Name           string `form:"name,required"`
+	Year           int32  `form:"year,required"`
+	Slug           string `form:"slug"`
+	Description    string `form:"description,max=10000"`
+	Location       string `form:"location,max=200"`
+	ImageURL       string `form:"image_url" label:"image URL"`
+	Timezone       string `form:"timezone,max=64" label:"time zone"`
+}
+
+func (app *application) adminCreatePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	state, err := decodeFormState[eventForm](r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	input := state.Values
+
+	form := viewmodels.EventFormViewModel{
+		OrganisationID: input.OrganisationID,
+		Name:           input.Name,
+		// The submitted text, so a year that is not a number is shown again
+		Year:        strings.TrimSpace(r.PostForm.Get("year")),
+		Slug:        input.Slug,
+		Description: input.Description,
+		Location:    input.Location,
+		ImageURL:    input.ImageURL,
+		Timezone:    strings.TrimSpace(input.Timezone),
+	}
+
+	// Fall back to a slug generated from the name
+	if form.Slug == "" {
+		form.Slug = service.NormalizeSlug(form.Name)
+	}
+
+	if _, bad := state.Errors["organisation_id"]; bad || input.OrganisationID <= 0 {
+		state.AddError("organisation_id", "Please choose an organisation")
+	} else {
+		user, _ := getUserFromContext(r)
+		allowed, err := app.organisationService.CanCreateEvents(ctx, user.ID, input.OrganisationID)
+		if err != nil {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		if !allowed {
+			state.AddError("organisation_id", "You cannot create events for this organisation")
+		}
+	}
+
+	if suggestion := service.NormalizeSlug(form.Slug); form.Slug == "" {
+		state.AddError("slug", "Slug is required")
+	} else if suggestion != form.Slug {
+		// A valid slug normalises to itself, so anything else gets a suggestion
+		if suggestion == "" {
+			state.AddError("slug", "Slug must contain letters or numbers")
+		} else {
+			state.AddError("slug", "Slug can only use lowercase letters, numbers and single hyphens, and must not be a reserved word. Try "+suggestion+" instead")
+		}
+	}
+	if form.ImageURL != "" && service.ValidateImageURL(form.ImageURL) != nil {
+		state.AddError("image_url", "Image URL must start with https://")
+	}
+
+	if state.Invalid() {
+		form.Errors = state.Errors
+		app.renderEventForm(w, r, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	_, err = app.eventService.CreateEvent(ctx, service.CreateEventInput{
+		OrganisationID: input.OrganisationID,
+		Name:           form.Name,
+		Slug:           form.Slug,
+		Year:           input.Year,
+		Description:    form.Description,
+		Location:       form.Location,
+		ImageURL:       form.ImageURL,
+		Timezone:       form.Timezone,
+	})
+	if err != nil {
+		// Handle specific errors
+		if !state.AddFieldErrors(err) {
+			switch {
+			case errors.Is(err, service.ErrSlugTaken):
+				state.AddError("slug", fmt.Sprintf("Your organisation already uses the slug %s for an event in %d", form.Slug, input.Year))
+			case errors.Is(err, service.ErrInvalidInput):
+				state.AddError("form", err.Error())
+			default:
+				app.handleServiceError(w, r, err)
+				return
+			}
+		}
+		form.Errors = state.Errors
+		app.renderEventForm(w, r, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	// New events start as drafts, so the public page would not find it yet
+	app.addFlash(r, FlashSuccess, "Event created as a draft. Publish it once its races are ready")
+	http.Redirect(w, r, viewmodels.DashboardURL(input.OrganisationID), http.StatusSeeOther)
+}
+
+// renderEventForm renders the admin event form with the organisation options populated.
+func (app *application) renderEventForm(w http.ResponseWriter, r *http.Request, status int, form viewmodels.EventFormViewModel) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	user, _ := getUserFromContext(r)
+	orgs, err := app.managedOrganisations(ctx, user)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	form.Organisations = viewmodels.NewOrganisationOptions(orgs)
+
+	flashes := app.getAllFlashes(r)
+	app.render(r.Context(), w, status, admin.CreateEvent(form, flashes))
+}
+
+func (app *application) adminDuplicateView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	form := viewmodels.NewDuplicateEventViewModel(event)
+	app.render(r.Context(), w, http.StatusOK, admin.DuplicateEvent(form, app.getAllFlashes(r)))
+}
+
+func (app *application) adminDuplicatePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form := viewmodels.NewDuplicateEventViewModel(event)
+	form.NewYear = strings.TrimSpace(r.PostForm.Get("year"))
+
+	year, err := strconv.ParseInt(form.NewYear, 10, 32)
+	if err != nil {
+		form.Errors["year"] = "Year must be a number"
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.DuplicateEvent(form, app.getAllFlashes(r)))
+		return
+	}
+
+	duplicate, err := app.eventService.DuplicateEvent(ctx, event.ID, int32(year))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrConflict):
+			form.Errors["year"] = fmt.Sprintf("%s already has an event in %d", event.Name, year)
+		case errors.Is(err, service.ErrInvalidInput):
+			form.Errors["form"] = err.Error()
+		default:
+			app.handleServiceError(w, r, err)
+			return
+		}
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.DuplicateEvent(form, app.getAllFlashes(r)))
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, fmt.Sprintf("Event duplicated into %d as a draft", duplicate.Year))
+	http.Redirect(w, r, viewmodels.DashboardURL(event.OrganisationID), http.StatusSeeOther)
+}
+
+func (app *application) adminEventSeriesView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	events, err := app.eventService.ListOrganisationEvents(ctx, event.OrganisationID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	form := viewmodels.NewEventSeriesViewModel(event, events)
+	app.render(r.Context(), w, http.StatusOK, admin.EventSeries(form, app.getAllFlashes(r)))
+}
+
+// adminEventSeriesPost links the event to the series of the chosen event,
+// or unlinks it for a series_event_id of 0.
+func (app *application) adminEventSeriesPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	events, err := app.eventService.ListOrganisationEvents(ctx, event.OrganisationID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	form := viewmodels.NewEventSeriesViewModel(event, events)
+	form.SeriesEventID = strings.TrimSpace(r.PostForm.Get("series_event_id"))
+
+	seriesEventID, err := strconv.ParseInt(form.SeriesEventID, 10, 64)
+	if err != nil {
+		form.Errors["series_event_id"] = "Choose an event"
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EventSeries(form, app.getAllFlashes(r)))
+		return
+	}
+
+	if err := app.eventService.SetEventSeries(ctx, event, seriesEventID); err != nil {
+		if errs, ok := fieldErrors(err); ok {
+			form.Errors = errs
+			app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EventSeries(form, app.getAllFlashes(r)))
+			return
+		}
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	if seriesEventID == 0 {
+		app.addFlash(r, FlashSuccess, event.Name+" is no longer linked to other editions")
+	} else {
+		app.addFlash(r, FlashSuccess, event.Name+" is linked to its other editions")
+	}
+	http.Redirect(w, r, viewmodels.DashboardURL(event.OrganisationID), http.StatusSeeOther)
+}
+
+func (app *application) adminPublishEventPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	err := app.eventService.PublishEvent(ctx, event.ID)
+	switch {
+	case err == nil:
+		app.addFlash(r, FlashSuccess, event.Name+" is published")
+	case errors.Is(err, service.ErrEventHasNoRaces):
+		app.addFlash(r, FlashError, "Add a race before publishing "+event.Name)
+	case errors.Is(err, service.ErrNoRegistrationWindow):
+		app.addFlash(r, FlashError, "Give every race registration open and close dates before publishing "+event.Name)
+	case errors.Is(err, service.ErrEventURLTaken):
+		app.addFlash(r, FlashError, fmt.Sprintf("Another organisation has already published an event at %s, so %s can't be published with that slug", viewmodels.EventURL(event.Year, event.Slug), event.Name))
+	default:
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, viewmodels.DashboardURL(event.OrganisationID), http.StatusSeeOther)
+}
+
+func (app *application) adminArchiveEventPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.eventService.ArchiveEvent(ctx, event.ID); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.addFlash(r, FlashSuccess, event.Name+" is archived and no longer public")
+	http.Redirect(w, r, viewmodels.DashboardURL(event.OrganisationID), http.StatusSeeOther)
+}
+
+// registrationTimeseries is the data for an event's registrations chart.
+type registrationTimeseries struct {
+	Granularity service.Granularity  `json:"granularity"`
+	Buckets     []registrationBucket `json:"buckets"`
+}
+
+// registrationBucket is one period of a registrationTimeseries.
+type registrationBucket struct {
+	Date       string `json:"date"`
+	Count      int64  `json:"count"`
+	Cumulative int64  `json:"cumulative"`
+}
+
+// adminRegistrationTimeseries answers with the data for an event's
+// registrations-over-time chart, counted by the day or, with
+// granularity=week, by the week.
+func (app *application) adminRegistrationTimeseries(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	granularity := service.Granularity(r.URL.Query().Get("granularity"))
+	if granularity == "" {
+		granularity = service.GranularityDay
+	}
+	buckets, err := app.eventService.RegistrationTimeseries(ctx, event, granularity)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			app.clientError(w, r, http.StatusBadRequest)
+		} else {
+			app.handleServiceError(w, r, err)
+		}
+		return
+	}
+
+	data := registrationTimeseries{Granularity: granularity, Buckets: make([]registrationBucket, 0, len(buckets))}
+	for _, b := range buckets {
+		data.Buckets = append(data.Buckets, registrationBucket{Date: b.Date.Format(time.DateOnly), Count: b.Count, Cumulative: b.Cumulative})
+	}
+	app.writeJSON(w, http.StatusOK, data)
+}
+
+func (app *application) adminDiscountCodesView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	vm, err := app.discountCodesPage(ctx, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.render(r.Context(), w, http.StatusOK, admin.DiscountCodes(vm, app.getAllFlashes(r)))
+}
+
+// discountCodeForm is the form posted to create a discount code. The amount
+// off and validity window are parsed by hand, as money and datetime-local
+// values.
+type discountCodeForm struct {
+	Code       string `form:"code,required"`
+	RaceID     int64  `form:"race_id" label:"race"`
+	PercentOff int32  `form:"percent_off" label:"percentage off"`
+	AmountOff  string `form:"amount_off"`
+	MaxUses    int32  `form:"max_uses" label:"maximum uses"`
+	ValidFrom  string `form:"valid_from"`
+	ValidUntil string `form:"valid_until"`
+}
+
+func (app *application) adminCreateDiscountCodePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	var input discountCodeForm
+	decodeErr := decodeForm(r, &input)
+	formErrors, ok := fieldErrors(decodeErr)
+	if decodeErr != nil && !ok {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	if formErrors == nil {
+		formErrors = make(map[string]string)
+	}
+
+	vm, err := app.discountCodesPage(ctx, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	// The submitted text, so numbers that do not parse are shown again
+	vm.Code = input.Code
+	vm.RaceID = strings.TrimSpace(r.PostForm.Get("race_id"))
+	vm.PercentOff = strings.TrimSpace(r.PostForm.Get("percent_off"))
+	vm.AmountOff = input.AmountOff
+	vm.MaxUses = strings.TrimSpace(r.PostForm.Get("max_uses"))
+	vm.ValidFrom, vm.ValidUntil = input.ValidFrom, input.ValidUntil
+
+	params := service.CreateDiscountCodeParams{
+		EventID:    event.ID,
+		RaceID:     input.RaceID,
+		Code:       input.Code,
+		PercentOff: input.PercentOff,
+		MaxUses:    input.MaxUses,
+	}
+	if input.AmountOff != "" {
+		amount, err := viewmodels.ParseMoney(input.AmountOff, vm.Currency(input.RaceID))
+		if err != nil || amount.Units > math.MaxInt32 {
+			formErrors["amount_off"] = "Amount off must be an amount such as 5.00"
+		}
+		params.AmountOffUnits = int32(amount.Units)
+	}
+	var valid bool
+	if params.ValidFrom, valid = parseFormTime(input.ValidFrom); !valid {
+		formErrors["valid_from"] = "Valid from must be a date and time"
+	}
+	if params.ValidUntil, valid = parseFormTime(input.ValidUntil); !valid {
+		formErrors["valid_until"] = "Valid until must be a date and time"
+	}
+
+	if len(formErrors) == 0 {
+		code, err := app.discountService.CreateCode(ctx, params)
+		if err == nil {
+			app.addFlash(r, FlashSuccess, "Discount code "+code.Code+" created")
+			http.Redirect(w, r, viewmodels.DiscountCodesURL(event.ID), http.StatusSeeOther)
+			return
+		}
+		msgs, invalid := fieldErrors(err)
+		switch {
+		case invalid:
+			formErrors = msgs
+		case errors.Is(err, service.ErrInvalidInput):
+			formErrors["form"] = err.Error()
+		default:
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	vm.Errors = formErrors
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.DiscountCodes(vm, app.getAllFlashes(r)))
+}
+
+// parseFormTime reads a datetime-local input as UTC, reporting whether it
+// could. An empty value is the zero time.
+func parseFormTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(viewmodels.DiscountTimeLayout, value)
+	return t, err == nil
+}
+
+// parseFormDate reads a date input, reporting whether it could. An empty
+// value is the zero time.
+func parseFormDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	return t, err == nil
+}
+
+// discountCodesPage loads the discount codes page for an event.
+func (app *application) discountCodesPage(ctx context.Context, event db.Event) (viewmodels.DiscountCodesViewModel, error) {
+	races, err := app.raceService.ListRaces(ctx, event.ID)
+	if err != nil {
+		return viewmodels.DiscountCodesViewModel{}, err
+	}
+	codes, err := app.discountService.ListCodes(ctx, event.ID)
+	if err != nil {
+		return viewmodels.DiscountCodesViewModel{}, err
+	}
+	eventRaces := make([]db.Race, 0, len(races))
+	for _, race := range races {
+		eventRaces = append(eventRaces, race.Race)
+	}
+	return viewmodels.NewDiscountCodesViewModel(event, eventRaces, codes), nil
+}
+
+func (app *application) adminPhotosView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	vm, err := app.photosPage(ctx, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.render(r.Context(), w, http.StatusOK, admin.EventPhotos(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) adminEnquiriesView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	enquiries, err := app.contactService.ListEnquiries(ctx, event.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vm := viewmodels.NewEnquiriesViewModel(event, enquiries, service.EventLocation(event))
+	app.render(r.Context(), w, http.StatusOK, admin.Enquiries(vm, app.getAllFlashes(r)))
+}
+
+// maxPhotoUploadBytes bounds a photo upload: the image and the multipart
+// framing around it.
+const maxPhotoUploadBytes = service.MaxPhotoBytes + 64<<10
+
+func (app *application) adminUploadPhotoPost(w http.ResponseWriter, r *http.Request) {
+	// Photos take longer to upload than ordinary requests
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(importTimeout)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetReadDeadline(deadline)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetWriteDeadline(deadline)
+
+	ctx, cancel := context.WithTimeout(r.Context(), importTimeout)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPhotoUploadBytes)
+	file, err := importFile(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	var uploadErr string
+	if file == nil {
+		uploadErr = "Choose a JPEG or PNG photo to upload"
+	} else {
+		_, err := app.photoService.UploadPhoto(ctx, event.ID, file)
+		if err == nil {
+			app.addFlash(r, FlashSuccess, "Photo uploaded")
+			http.Redirect(w, r, viewmodels.EventPhotosURL(event.ID), http.StatusSeeOther)
+			return
+		}
+
+		var tooLarge *http.MaxBytesError
+		msgs, invalid := fieldErrors(err)
+		switch {
+		case errors.As(err, &tooLarge):
+			uploadErr = fmt.Sprintf("The file is too large; photos are limited to %d MB", service.MaxPhotoBytes>>20)
+		case invalid:
+			uploadErr = msgs["photo"]
+		default:
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	vm, err := app.photosPage(ctx, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vm.Error = uploadErr
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EventPhotos(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) adminDeletePhotoPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+	photoID, err := strconv.ParseInt(r.PathValue("photoID"), 10, 64)
+	if err != nil || photoID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	if err := app.photoService.DeletePhoto(ctx, event.ID, photoID); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.addFlash(r, FlashSuccess, "Photo deleted")
+	http.Redirect(w, r, viewmodels.EventPhotosURL(event.ID), http.StatusSeeOther)
+}
+
+// movePhotoForm is the form posted to move a photo one place up or down
+// the gallery.
+type movePhotoForm struct {
+	Direction string `form:"direction,required"`
+}
+
+func (app *application) adminMovePhotoPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	event, ok := app.loadManagedEvent(ctx, w, r)
+	if !ok {
+		return
+	}
+	photoID, err := strconv.ParseInt(r.PathValue("photoID"), 10, 64)
+	if err != nil || photoID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	var input movePhotoForm
+	if err := decodeForm(r, &input); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	var offset int
+	switch input.Direction {
+	case "up":
+		offset = -1
+	case "down":
+		offset = 1
+	default:
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	if err := app.photoService.MovePhoto(ctx, event.ID, photoID, offset); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, viewmodels.EventPhotosURL(event.ID), http.StatusSeeOther)
+}
+
+// photosPage loads the photos page for an event, with a viewable URL for
+// each photo.
+func (app *application) photosPage(ctx context.Context, event db.Event) (viewmodels.EventPhotosViewModel, error) {
+	photos, err := app.photoService.ListPhotos(ctx, event.ID)
+	if err != nil {
+		return viewmodels.EventPhotosViewModel{}, err
+	}
+	urls := make([]string, 0, len(photos))
+	for _, photo := range photos {
+		url, err := app.photoService.PhotoURL(ctx, photo)
+		if err != nil {
+			return viewmodels.EventPhotosViewModel{}, err
+		}
+		urls = append(urls, url)
+	}
+	return viewmodels.NewEventPhotosViewModel(event, photos, urls, service.MaxPhotoBytes), nil
+}
+
+func (app *application) adminEditRaceView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	form, err := app.editRacePage(ctx, r, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.render(r.Context(), w, http.StatusOK, admin.EditRace(form, app.getAllFlashes(r)))
+}
+
+// editRaceForm is the form posted to change a race's capacity. Lowering
+// the capacity is only saved once Confirm is set.
+type editRaceForm struct {
+	MaxCapacity int32 `form:"max_capacity,required" label:"capacity"`
+	Confirm     bool  `form:"confirm"`
+}
+
+func (app *application) adminEditRacePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	var input editRaceForm
+	decodeErr := decodeForm(r, &input)
+	formErrors, invalid := fieldErrors(decodeErr)
+	if decodeErr != nil && !invalid {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form, err := app.editRacePage(ctx, r, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	form.NewCapacity = strings.TrimSpace(r.PostForm.Get("max_capacity"))
+
+	switch {
+	case invalid:
+		form.Errors = formErrors
+	case input.MaxCapacity == race.MaxCapacity:
+		app.addFlash(r, FlashInfo, fmt.Sprintf("%s already has %d places", race.Name, race.MaxCapacity))
+		http.Redirect(w, r, viewmodels.EntrantsURL(race.ID), http.StatusSeeOther)
+		return
+	case input.MaxCapacity < race.MaxCapacity && !input.Confirm:
+		form.ConfirmReduction = true
+		app.render(r.Context(), w, http.StatusOK, admin.EditRace(form, app.getAllFlashes(r)))
+		return
+	default:
+		user, _ := getUserFromContext(r)
+		updated, err := app.raceService.UpdateRaceCapacity(ctx, race.ID, input.MaxCapacity, user.ID)
+		if err == nil {
+			app.addFlash(r, FlashSuccess, fmt.Sprintf("%s now has %d places", updated.Name, updated.MaxCapacity))
+			http.Redirect(w, r, viewmodels.EntrantsURL(race.ID), http.StatusSeeOther)
+			return
+		}
+		msgs, invalid := fieldErrors(err)
+		switch {
+		case invalid:
+			form.Errors = msgs
+		case errors.Is(err, service.ErrInvalidInput):
+			form.Errors["max_capacity"] = err.Error()
+		default:
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EditRace(form, app.getAllFlashes(r)))
+}
+
+// editRacePage prepares the race edit form, with how many places the
+// race's entries hold and its distance in the organiser's unit.
+func (app *application) editRacePage(ctx context.Context, r *http.Request, race db.Race, event db.Event) (viewmodels.EditRaceViewModel, error) {
+	availability, err := app.raceService.GetRace(ctx, event.ID, race.Slug)
+	if err != nil {
+		return viewmodels.EditRaceViewModel{}, err
+	}
+	form := viewmodels.NewEditRaceViewModel(availability.Race, event, availability.Registered)
+	form.MaxRouteMB = service.MaxRouteBytes >> 20
+	form.SetDistanceUnit(distanceUnit(r))
+
+	route, err := app.raceService.GetRoute(ctx, race.ID)
+	switch {
+	case err == nil:
+		form.Route = &viewmodels.RouteViewModel{
+			DistanceMetres:      route.DistanceMetres,
+			ElevationGainMetres: route.ElevationGainMetres,
+		}
+	case !errors.Is(err, repository.ErrNotFound):
+		return viewmodels.EditRaceViewModel{}, err
+	}
+
+	questions, err := app.raceService.RequiredQuestions(ctx, race.ID)
+	if err != nil {
+		return viewmodels.EditRaceViewModel{}, err
+	}
+	for _, q := range questions {
+		form.RequiredQuestions = append(form.RequiredQuestions, string(q))
+	}
+	locked, err := app.raceService.LockedQuestions(ctx, race.ID)
+	if err != nil {
+		return viewmodels.EditRaceViewModel{}, err
+	}
+	for _, q := range locked {
+		form.LockedQuestions = append(form.LockedQuestions, string(q))
+	}
+
+	waves, err := app.registrationService.ListWaves(ctx, race.ID)
+	if err != nil {
+		return viewmodels.EditRaceViewModel{}, err
+	}
+	form.Waves = viewmodels.NewWaveForms(race.ID, waves, service.EventLocation(event))
+
+	tiers, err := app.raceService.ListPriceTiers(ctx, race.ID)
+	if err != nil {
+		return viewmodels.EditRaceViewModel{}, err
+	}
+	for _, t := range tiers {
+		form.PriceTiers = append(form.PriceTiers, viewmodels.NewPriceTierRow(availability.Race, t.Tier, t.Taken, service.EventLocation(event)))
+	}
+
+	fields, err := app.raceService.CustomFields(ctx, race.ID)
+	if err != nil {
+		return viewmodels.EditRaceViewModel{}, err
+	}
+	form.CustomFields = newCustomFieldForms(race.ID, fields)
+	form.MaxCustomFields = service.MaxCustomFields
+	return form, nil
+}
+
+// waveForm is the form posted to add or change a race's start wave. The
+// start is read in the event's time zone.
+type waveForm struct {
+	Name     string `form:"name"`
+	StartsAt string `form:"starts_at"`
+	Capacity int    `form:"capacity"`
+}
+
+// decodeWaveForm reads a posted wave, returning its problems as field
+// errors. ok is false, with a response written, if the request could not
+// be read at all.
+func (app *application) decodeWaveForm(w http.ResponseWriter, r *http.Request) (service.WaveInput, map[string]string, bool) {
+	var input waveForm
+	decodeErr := decodeForm(r, &input)
+	errs, invalid := fieldErrors(decodeErr)
+	if decodeErr != nil && !invalid {
+		app.clientError(w, r, http.StatusBadRequest)
+		return service.WaveInput{}, nil, false
+	}
+	startsAt, parsed := parseFormTime(input.StartsAt)
+	if !parsed {
+		if errs == nil {
+			errs = map[string]string{}
+		}
+		errs["starts_at"] = "Start time must be a date and time"
+	}
+	return service.WaveInput{Name: input.Name, StartsAt: startsAt, Capacity: input.Capacity}, errs, true
+}
+
+// renderWaveErrors shows the race's edit page with the posted wave and its
+// problems in place of the wave's form.
+func (app *application) renderWaveErrors(ctx context.Context, w http.ResponseWriter, r *http.Request, race db.Race, event db.Event, waveID int64, errs map[string]string) {
+	form, err := app.editRacePage(ctx, r, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	posted := viewmodels.WaveForm{
+		RaceID:   race.ID,
+		ID:       waveID,
+		Name:     r.PostForm.Get("name"),
+		StartsAt: r.PostForm.Get("starts_at"),
+		Capacity: r.PostForm.Get("capacity"),
+		Errors:   errs,
+	}
+	if waveID == 0 {
+		form.NewWave = posted
+	}
+	for i, wave := range form.Waves {
+		if wave.ID == waveID {
+			posted.Taken = wave.Taken
+			form.Waves[i] = posted
+		}
+	}
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EditRace(form, app.getAllFlashes(r)))
+}
+
+func (app *application) adminRaceWavesPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	input, errs, ok := app.decodeWaveForm(w, r)
+	if !ok {
+		return
+	}
+
+	if errs == nil {
+		wave, err := app.registrationService.CreateWave(ctx, event, race.ID, input)
+		if err == nil {
+			app.addFlash(r, FlashSuccess, fmt.Sprintf("%s added to %s", wave.Name, race.Name))
+			http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+			return
+		}
+		var invalid bool
+		if errs, invalid = fieldErrors(err); !invalid {
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+	app.renderWaveErrors(ctx, w, r, race, event, 0, errs)
+}
+
+func (app *application) adminRaceWavePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	waveID, err := strconv.ParseInt(r.PathValue("waveID"), 10, 64)
+	if err != nil || waveID < 1 {
+		app.notFound(w, r)
+		return
+	}
+	input, errs, ok := app.decodeWaveForm(w, r)
+	if !ok {
+		return
+	}
+
+	if errs == nil {
+		wave, err := app.registrationService.UpdateWave(ctx, event, race, waveID, input)
+		if err == nil {
+			app.addFlash(r, FlashSuccess, fmt.Sprintf("%s saved", wave.Name))
+			http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+			return
+		}
+		var invalid bool
+		if errs, invalid = fieldErrors(err); !invalid {
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+	app.renderWaveErrors(ctx, w, r, race, event, waveID, errs)
+}
+
+func (app *application) adminRaceWaveDeletePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, _, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	waveID, err := strconv.ParseInt(r.PathValue("waveID"), 10, 64)
+	if err != nil || waveID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	err = app.registrationService.DeleteWave(ctx, race.ID, waveID)
+	switch {
+	case err == nil:
+		app.addFlash(r, FlashSuccess, fmt.Sprintf("Wave deleted from %s", race.Name))
+		http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+	case errors.Is(err, service.ErrWaveInUse):
+		app.addFlash(r, FlashError, "Entrants hold places in this wave, so it can't be deleted")
+		http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+	default:
+		app.handleServiceError(w, r, err)
+	}
+}
+
+// priceTierForm is the form posted to add a price tier to a race. Its dates
+// are read in the event's time zone and its price in the race's currency.
+type priceTierForm struct {
+	Name      string `form:"name"`
+	Price     string `form:"price"`
+	ValidFrom string `form:"valid_from"`
+	ValidTo   string `form:"valid_to"`
+	Capacity  int    `form:"capacity"`
+}
+
+func (app *application) adminRacePriceTiersPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	var input priceTierForm
+	decodeErr := decodeForm(r, &input)
+	errs, invalid := fieldErrors(decodeErr)
+	if decodeErr != nil && !invalid {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	if errs == nil {
+		errs = map[string]string{}
+	}
+
+	tier := service.PriceTierInput{Name: input.Name, Capacity: input.Capacity}
+	amount, err := viewmodels.ParseMoney(input.Price, viewmodels.RaceCurrency(race))
+	if err != nil || amount.Units > math.MaxInt32 {
+		errs["price"] = "Price must be an amount such as 65.00"
+	}
+	tier.PriceUnits = int32(amount.Units)
+	var valid bool
+	if tier.ValidFrom, valid = parseFormTime(input.ValidFrom); !valid {
+		errs["valid_from"] = "From must be a date and time"
+	}
+	if tier.ValidTo, valid = parseFormTime(input.ValidTo); !valid {
+		errs["valid_to"] = "Until must be a date and time"
+	}
+
+	if len(errs) == 0 {
+		created, err := app.raceService.CreatePriceTier(ctx, event, race.ID, tier)
+		if err == nil {
+			app.addFlash(r, FlashSuccess, fmt.Sprintf("%s price added to %s", created.Name, race.Name))
+			http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+			return
+		}
+		if errs, invalid = fieldErrors(err); !invalid {
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	form, err := app.editRacePage(ctx, r, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	form.NewPriceTier = viewmodels.PriceTierForm{
+		RaceID:    race.ID,
+		Name:      input.Name,
+		Price:     input.Price,
+		ValidFrom: input.ValidFrom,
+		ValidTo:   input.ValidTo,
+		Capacity:  r.PostForm.Get("capacity"),
+		Errors:    errs,
+	}
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EditRace(form, app.getAllFlashes(r)))
+}
+
+func (app *application) adminRacePriceTierDeletePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, _, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	tierID, err := strconv.ParseInt(r.PathValue("tierID"), 10, 64)
+	if err != nil || tierID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	if err := app.raceService.DeletePriceTier(ctx, race.ID, tierID); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.addFlash(r, FlashSuccess, fmt.Sprintf("Price tier deleted from %s", race.Name))
+	http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+}
+
+// customFieldForm is the form posted to add or change a race's custom
+// field. A select field's options are typed one per line.
+type customFieldForm struct {
+	Label    string `form:"label"`
+	Kind     string `form:"kind"`
+	Options  string `form:"options"`
+	Required bool   `form:"required"`
+}
+
+// input returns the field's definition as posted.
+func (f customFieldForm) input() service.CustomFieldInput {
+	var options []string
+	if strings.TrimSpace(f.Options) != "" {
+		options = strings.Split(f.Options, "\n")
+	}
+	return service.CustomFieldInput{Label: f.Label, Kind: f.Kind, Options: options, Required: f.Required}
+}
+
+// newCustomFieldForms prepares a form for each of the race's custom fields.
+func newCustomFieldForms(raceID int64, fields []service.CustomField) []viewmodels.CustomFieldForm {
+	forms := make([]viewmodels.CustomFieldForm, len(fields))
+	for i, f := range fields {
+		forms[i] = viewmodels.CustomFieldForm{
+			RaceID:   raceID,
+			ID:       f.ID,
+			Label:    f.Label,
+			Kind:     string(f.Kind),
+			Options:  strings.Join(f.Options, "\n"),
+			Required: f.Required,
+			First:    i == 0,
+			Last:     i == len(fields)-1,
+		}
+	}
+	return forms
+}
+
+// renderCustomFieldErrors shows the race's edit page with the posted field
+// and its problems in place of the field's form.
+func (app *application) renderCustomFieldErrors(ctx context.Context, w http.ResponseWriter, r *http.Request, race db.Race, event db.Event, fieldID int64, input customFieldForm, errs map[string]string) {
+	form, err := app.editRacePage(ctx, r, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	posted := viewmodels.CustomFieldForm{
+		RaceID:   race.ID,
+		ID:       fieldID,
+		Label:    input.Label,
+		Kind:     input.Kind,
+		Options:  input.Options,
+		Required: input.Required,
+		Errors:   errs,
+	}
+	if fieldID == 0 {
+		form.NewCustomField = posted
+	}
+	for i, field := range form.CustomFields {
+		if field.ID == fieldID {
+			posted.First, posted.Last = field.First, field.Last
+			form.CustomFields[i] = posted
+		}
+	}
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EditRace(form, app.getAllFlashes(r)))
+}
+
+func (app *application) adminRaceCustomFieldsPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	var input customFieldForm
+	if err := decodeForm(r, &input); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	field, err := app.raceService.CreateCustomField(ctx, race.ID, input.input())
+	if errs, ok := fieldErrors(err); ok {
+		app.renderCustomFieldErrors(ctx, w, r, race, event, 0, input, errs)
+		return
+	}
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.addFlash(r, FlashSuccess, fmt.Sprintf("%s added to %s", field.Label, race.Name))
+	http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+}
+
+func (app *application) adminRaceCustomFieldPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	fieldID, err := strconv.ParseInt(r.PathValue("fieldID"), 10, 64)
+	if err != nil || fieldID < 1 {
+		app.notFound(w, r)
+		return
+	}
+	var input customFieldForm
+	if err := decodeForm(r, &input); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	field, err := app.raceService.UpdateCustomField(ctx, race.ID, fieldID, input.input())
+	if errs, ok := fieldErrors(err); ok {
+		app.renderCustomFieldErrors(ctx, w, r, race, event, fieldID, input, errs)
+		return
+	}
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.addFlash(r, FlashSuccess, fmt.Sprintf("%s saved", field.Label))
+	http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+}
+
+func (app *application) adminRaceCustomFieldDeletePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, _, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	fieldID, err := strconv.ParseInt(r.PathValue("fieldID"), 10, 64)
+	if err != nil || fieldID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	if err := app.raceService.DeleteCustomField(ctx, race.ID, fieldID); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.addFlash(r, FlashSuccess, fmt.Sprintf("Field removed from %s", race.Name))
+	http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+}
+
+func (app *application) adminRaceCustomFieldMovePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, _, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	fieldID, err := strconv.ParseInt(r.PathValue("fieldID"), 10, 64)
+	if err != nil || fieldID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	var input movePhotoForm
+	if err := decodeForm(r, &input); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	var offset int
+	switch input.Direction {
+	case "up":
+		offset = -1
+	case "down":
+		offset = 1
+	default:
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	if err := app.raceService.MoveCustomField(ctx, race.ID, fieldID, offset); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+}
+
+// customFieldOrderForm is the form posted when custom fields are dragged
+// into a new order, listing every field's ID in that order.
+type customFieldOrderForm struct {
+	FieldIDs []string `form:"field_id"`
+}
+
+func (app *application) adminRaceCustomFieldOrderPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, _, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	var input customFieldOrderForm
+	if err := decodeForm(r, &input); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	ids := make([]int64, len(input.FieldIDs))
+	for i, v := range input.FieldIDs {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		}
+		ids[i] = id
+	}
+
+	if err := app.raceService.ReorderCustomFields(ctx, race.ID, ids); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+}
+
+// raceQuestionsForm is the form posted to choose the questions a race's
+// entrants must answer, and those they cannot change once paid.
+type raceQuestionsForm struct {
+	Questions []string `form:"questions"`
+	Locked    []string `form:"locked"`
+}
+
+func (app *application) adminRaceQuestionsPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, _, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	var input raceQuestionsForm
+	if err := decodeForm(r, &input); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	err := app.raceService.SetRequiredQuestions(ctx, race.ID, input.Questions)
+	if err == nil {
+		err = app.raceService.SetLockedQuestions(ctx, race.ID, input.Locked)
+	}
+	switch {
+	case err == nil:
+		app.addFlash(r, FlashSuccess, fmt.Sprintf("Questionnaire saved for %s", race.Name))
+		http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+	case errors.Is(err, service.ErrInvalidInput):
+		app.clientError(w, r, http.StatusBadRequest)
+	default:
+		app.handleServiceError(w, r, err)
+	}
+}
+
+// raceMinAgeForm is the form posted to set a race's minimum age. Blank
+// lets any age enter.
+type raceMinAgeForm struct {
+	MinAge int `form:"min_age"`
+}
+
+func (app *application) adminRaceMinAgePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	var input raceMinAgeForm
+	decodeErr := decodeForm(r, &input)
+	formErrors, invalid := fieldErrors(decodeErr)
+	if decodeErr != nil && !invalid {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	if !invalid {
+		updated, err := app.raceService.SetMinAge(ctx, race.ID, input.MinAge)
+		if err == nil {
+			msg := fmt.Sprintf("Entrants of any age may enter %s", updated.Name)
+			if updated.MinAge.Valid {
+				msg = fmt.Sprintf("Entrants to %s must be %d or over on race day", updated.Name, updated.MinAge.Int32)
+			}
+			app.addFlash(r, FlashSuccess, msg)
+			http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+			return
+		}
+		if formErrors, invalid = fieldErrors(err); !invalid {
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	form, err := app.editRacePage(ctx, r, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	form.MinAge = strings.TrimSpace(r.PostForm.Get("min_age"))
+	form.Errors = formErrors
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EditRace(form, app.getAllFlashes(r)))
+}
+
+// raceDistanceForm is the form posted to set a race's distance, typed in
+// either unit, and climb in metres. Blank leaves either unknown.
+type raceDistanceForm struct {
+	Distance      string `form:"distance"`
+	DistanceUnit  string `form:"distance_unit"`
+	ElevationGain int    `form:"elevation_gain"`
+}
+
+func (app *application) adminRaceDistancePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	var input raceDistanceForm
+	decodeErr := decodeForm(r, &input)
+	formErrors, invalid := fieldErrors(decodeErr)
+	if decodeErr != nil && !invalid {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	unit := db.DistanceUnit(input.DistanceUnit)
+	if unit != db.DistanceUnitMiles && unit != db.DistanceUnitKm {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	var distance viewmodels.Distance
+	if strings.TrimSpace(input.Distance) != "" {
+		d, err := viewmodels.ParseDistance(input.Distance, unit)
+		if err != nil {
+			if formErrors == nil {
+				formErrors = make(map[string]string)
+			}
+			formErrors["distance"] = "Enter the distance as a number, such as 26.2"
+			invalid = true
+		}
+		distance = d
+	}
+
+	if !invalid {
+		updated, err := app.raceService.SetDistance(ctx, race.ID, int(distance), input.ElevationGain)
+		if err == nil {
+			app.addFlash(r, FlashSuccess, fmt.Sprintf("Saved the distance of %s", updated.Name))
+			http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+			return
+		}
+		if formErrors, invalid = fieldErrors(err); !invalid {
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	form, err := app.editRacePage(ctx, r, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	form.Distance = strings.TrimSpace(input.Distance)
+	form.DistanceUnit = unit
+	form.ElevationGain = strings.TrimSpace(r.PostForm.Get("elevation_gain"))
+	form.Errors = formErrors
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EditRace(form, app.getAllFlashes(r)))
+}
+
+// maxRouteUploadBytes bounds a route upload: the GPX file and the multipart
+// form around it.
+const maxRouteUploadBytes = service.MaxRouteBytes + 64<<10
+
+func (app *application) adminRaceRoutePost(w http.ResponseWriter, r *http.Request) {
+	// Route files take longer to upload than ordinary requests
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(importTimeout)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetReadDeadline(deadline)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetWriteDeadline(deadline)
+
+	ctx, cancel := context.WithTimeout(r.Context(), importTimeout)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRouteUploadBytes)
+	file, err := importFile(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	var uploadErr string
+	if file == nil {
+		uploadErr = "Choose a GPX file to upload"
+	} else {
+		stats, err := app.raceService.UploadRoute(ctx, race.ID, file)
+		if err == nil {
+			route := viewmodels.RouteViewModel{DistanceMetres: stats.DistanceMetres, ElevationGainMetres: stats.ElevationGainMetres}
+			app.addFlash(r, FlashSuccess, fmt.Sprintf("Route uploaded: %s with %s", route.DistanceLabel(), route.ClimbLabel()))
+			http.Redirect(w, r, viewmodels.EditRaceURL(race.ID), http.StatusSeeOther)
+			return
+		}
+
+		var tooLarge *http.MaxBytesError
+		msgs, invalid := fieldErrors(err)
+		switch {
+		case errors.As(err, &tooLarge):
+			uploadErr = fmt.Sprintf("The file is too large; routes are limited to %d MB", service.MaxRouteBytes>>20)
+		case invalid:
+			uploadErr = msgs["route"]
+		default:
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	form, err := app.editRacePage(ctx, r, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	form.Errors["route"] = uploadErr
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.EditRace(form, app.getAllFlashes(r)))
+}
+
+func (app *application) adminEntrantsView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	entrants, err := app.registrationService.ListRaceEntrants(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	vm := viewmodels.NewEntrantsViewModel(race, event, entrants, service.EntrantCategories(entrants))
+	app.render(r.Context(), w, http.StatusOK, admin.Entrants(vm, app.getAllFlashes(r)))
+}
+
+// adminExportEntrants downloads the race's active entrants as CSV, for
+// timing systems and spreadsheets. Questionnaire answers follow the entry
+// details; medical ones only for organisers who can read them.
+func (app *application) adminExportEntrants(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	entrants, err := app.registrationService.ListRaceEntrants(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	answers, err := app.registrationService.ListRaceAnswers(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	user, _ := getUserFromContext(r)
+	medical, err := app.organisationService.CanReadMedical(ctx, user.ID, event.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	fields, err := app.raceService.CustomFields(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	versions, err := app.raceService.CustomFieldVersions(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	custom, err := app.registrationService.ListRaceCustomAnswers(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	columns := append(entrantColumns(medical), customFieldColumns(fields, versions, custom)...)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, format.Filename(race.Slug+"-entrants")))
+
+	// The status is sent with the first row, so a failure part way through
+	// can only be logged
+	err = func() error {
+		out, err := export.New(format, w, header)
+		if err != nil {
+			return err
+		}
+		for _, e := range viewmodels.NewEntrantsViewModel(race, event, entrants, service.EntrantCategories(entrants)).Entrants {
+			if e.Status == db.RegistrationStatusCancelled {
+				continue
+			}
+			row := make([]string, len(columns))
+			for i, c := range columns {
+				row[i] = c.value(e, answers[e.ID])
+			}
+			if err := out.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return out.Close()
+	}()
+	if err != nil && !app.clientGone(r, err) {
+		app.logger.Error("failed to write entrant export", "request_id", getRequestID(r), "uri", r.URL.RequestURI(), "error", err)
+	}
+}
+
+// entrantColumn is a column of the entrant export.
+type entrantColumn struct {
+	name  string
+	value func(e viewmodels.EntrantViewModel, answers service.Answers) string
+}
+
+// entrantColumns returns the columns of the entrant export, the same in
+// every format: the entrant's details, then their answers to each
+// question. Medical answers are left out unless medical is set.
+func entrantColumns(medical bool) []entrantColumn {
+	columns := []entrantColumn{
+		{"bib", func(e viewmodels.EntrantViewModel, _ service.Answers) string { return e.Bib }},
+		{"name", func(e viewmodels.EntrantViewModel, _ service.Answers) string { return e.Name }},
+		{"email", func(e viewmodels.EntrantViewModel, _ service.Answers) string { return e.Email }},
+		{"team", func(e viewmodels.EntrantViewModel, _ service.Answers) string { return e.Team }},
+		{"wave", func(e viewmodels.EntrantViewModel, _ service.Answers) string { return e.Wave }},
+		{"wave_start", func(e viewmodels.EntrantViewModel, _ service.Answers) string { return e.WaveStart }},
+		{"category", func(e viewmodels.EntrantViewModel, _ service.Answers) string { return e.Category }},
+		{"status", func(e viewmodels.EntrantViewModel, _ service.Answers) string { return e.StatusLabel() }},
+		{"checked_in", func(e viewmodels.EntrantViewModel, _ service.Answers) string { return e.CheckedInAt }},
+	}
+	for _, q := range service.Questions {
+		if q.Medical() && !medical {
+			continue
+		}
+		columns = append(columns, entrantColumn{string(q), func(_ viewmodels.EntrantViewModel, a service.Answers) string { return a.Get(q) }})
+	}
+	return columns
+}
+
+// customFieldColumns returns a column of the entrant export for each of the
+// race's custom fields, headed by its label, in form order. Fields removed
+// since entrants answered them follow, headed by their last label, so no
+// answer given is left out.
+func customFieldColumns(fields []service.CustomField, versions service.CustomFieldVersions, answers map[int64]service.CustomAnswers) []entrantColumn {
+	ids := make([]int64, len(fields))
+	for i, f := range fields {
+		ids[i] = f.ID
+	}
+	var removed []int64
+	for _, a := range answers {
+		for id := range a {
+			if !slices.Contains(ids, id) && !slices.Contains(removed, id) {
+				removed = append(removed, id)
+			}
+		}
+	}
+	slices.Sort(removed)
+
+	var columns []entrantColumn
+	for _, id := range append(ids, removed...) {
+		field, ok := versions.Latest(id)
+		if !ok {
+			continue
+		}
+		columns = append(columns, entrantColumn{field.Label, func(e viewmodels.EntrantViewModel, _ service.Answers) string {
+			return answers[e.ID][id].Text()
+		}})
+	}
+	return columns
+}
+
+// assignBibsForm is the form posted to number a race's entrants.
+type assignBibsForm struct {
+	StartAt int `form:"start_at,required" label:"first bib"`
+}
+
+func (app *application) adminAssignBibsPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, _, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	var input assignBibsForm
+	if err := decodeForm(r, &input); err != nil {
+		formErrors, invalid := fieldErrors(err)
+		if !invalid {
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		}
+		app.addFlash(r, FlashError, formErrors["start_at"])
+		http.Redirect(w, r, viewmodels.EntrantsURL(race.ID), http.StatusSeeOther)
+		return
+	}
+
+	assigned, err := app.registrationService.AssignBibNumbers(ctx, race.ID, input.StartAt)
+	switch {
+	case err == nil && assigned == 0:
+		app.addFlash(r, FlashInfo, "Every confirmed entrant already has a bib")
+	case err == nil:
+		app.addFlash(r, FlashSuccess, fmt.Sprintf("Bibs assigned to %d confirmed %s", assigned, pluralEntrants(assigned)))
+	case errors.Is(err, service.ErrInvalidBib):
+		app.addFlash(r, FlashError, "The first bib must be a positive number")
+	case errors.Is(err, repository.ErrConflict):
+		app.addFlash(r, FlashError, "Bibs changed while they were being assigned; please try again")
+	default:
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, viewmodels.EntrantsURL(race.ID), http.StatusSeeOther)
+}
+
+// pluralEntrants returns "entrant" or "entrants" to follow n.
+func pluralEntrants(n int) string {
+	if n == 1 {
+		return "entrant"
+	}
+	return "entrants"
+}
+
+// setBibForm is the form posted to give one entrant a bib.
+type setBibForm struct {
+	Bib int `form:"bib,required"`
+}
+
+func (app *application) adminSetBibPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	registrationID, err := strconv.ParseInt(r.PathValue("registrationID"), 10, 64)
+	if err != nil || registrationID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	// Only the race's own entrants may be numbered through its page
+	entrants, err := app.registrationService.ListRaceEntrants(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vm := viewmodels.NewEntrantsViewModel(race, event, entrants, service.EntrantCategories(entrants))
+	i := slices.IndexFunc(vm.Entrants, func(e viewmodels.EntrantViewModel) bool { return e.ID == registrationID })
+	if i < 0 {
+		app.notFound(w, r)
+		return
+	}
+	entrant := vm.Entrants[i]
+
+	var input setBibForm
+	if err := decodeForm(r, &input); err != nil {
+		formErrors, invalid := fieldErrors(err)
+		if !invalid {
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		}
+		app.addFlash(r, FlashError, formErrors["bib"])
+		http.Redirect(w, r, viewmodels.EntrantsURL(race.ID), http.StatusSeeOther)
+		return
+	}
+
+	err = app.registrationService.SetBib(ctx, registrationID, input.Bib)
+	switch {
+	case err == nil:
+		app.addFlash(r, FlashSuccess, fmt.Sprintf("%s is now bib %d", entrant.Name, input.Bib))
+	case errors.Is(err, service.ErrInvalidBib):
+		app.addFlash(r, FlashError, "Bibs must be positive numbers")
+	case errors.Is(err, repository.ErrConflict):
+		app.addFlash(r, FlashError, fmt.Sprintf("Bib %d is already taken", input.Bib))
+	case errors.Is(err, repository.ErrNotFound):
+		app.addFlash(r, FlashError, "Cancelled entries cannot be given a bib")
+	default:
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, viewmodels.EntrantsURL(race.ID), http.StatusSeeOther)
+}
+
+// adminCheckInView is the race-day check-in page, listing the confirmed
+// entrants matching the q search. htmx asks for just the list as the search
+// is typed.
+func (app *application) adminCheckInView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	app.renderCheckIn(ctx, w, r, race, event, r.URL.Query().Get("q"))
+}
+
+// renderCheckIn writes the check-in page, or just its list for htmx.
+func (app *application) renderCheckIn(ctx context.Context, w http.ResponseWriter, r *http.Request, race db.Race, event db.Event, query string) {
+	entrants, err := app.registrationService.ListRaceEntrants(ctx, race.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	vm := viewmodels.NewCheckInViewModel(race, event, entrants, query, app.clock.Now().Add(-service.CheckInUndoWindow))
+	w.Header().Add("Vary", "HX-Request")
+	if isHTMX(r) {
+		app.renderPartial(w, r, http.StatusOK, admin.CheckInList(vm, app.getAllFlashes(r)))
+		return
+	}
+	app.render(r.Context(), w, http.StatusOK, admin.CheckIn(vm, app.getAllFlashes(r)))
+}
+
+// checkInForm is the form posted to check an entrant in, or with
+// checked_in false to undo it. Posting the state wanted rather than
+// flipping the stored one means two marshals checking the same entrant in
+// at once both leave them checked in. q carries the search back to the
+// page.
+type checkInForm struct {
+	CheckedIn bool   `form:"checked_in"`
+	Query     string `form:"q"`
+}
+
+func (app *application) adminCheckInPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	registrationID, err := strconv.ParseInt(r.PathValue("registrationID"), 10, 64)
+	if err != nil || registrationID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	var input checkInForm
+	if err := decodeForm(r, &input); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	if input.CheckedIn {
+		err = app.registrationService.CheckIn(ctx, race.ID, registrationID)
+	} else {
+		err = app.registrationService.UndoCheckIn(ctx, race.ID, registrationID)
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrCheckInFinal):
+		app.addFlash(r, FlashError, fmt.Sprintf("Check-ins can only be undone within %d minutes", int(service.CheckInUndoWindow.Minutes())))
+	case errors.Is(err, repository.ErrNotFound):
+		app.notFound(w, r)
+		return
+	default:
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	if isHTMX(r) {
+		app.renderCheckIn(ctx, w, r, race, event, input.Query)
+		return
+	}
+	vm := viewmodels.CheckInViewModel{RaceID: race.ID, Query: strings.TrimSpace(input.Query)}
+	http.Redirect(w, r, vm.URL(), http.StatusSeeOther)
+}
+
+// importTimeout bounds the upload and database work of an entrant import or
+// results upload, which may run to thousands of rows.
+const importTimeout = time.Minute
+
+// maxImportBytes bounds the size of an uploaded entrant import or results file.
+const maxImportBytes = 10 << 20
+
+func (app *application) adminImportEntrantsView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	form := viewmodels.NewImportEntrantsViewModel(race, event, app.importMaxRows)
+	app.render(r.Context(), w, http.StatusOK, admin.ImportEntrants(form, app.getAllFlashes(r)))
+}
+
+func (app *application) adminImportEntrantsPost(w http.ResponseWriter, r *http.Request) {
+	// Large files take longer to upload and import than ordinary requests
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(importTimeout)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetReadDeadline(deadline)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetWriteDeadline(deadline)
+
+	ctx, cancel := context.WithTimeout(r.Context(), importTimeout)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+	form := viewmodels.NewImportEntrantsViewModel(race, event, app.importMaxRows)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	file, err := importFile(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	if file == nil {
+		form.Error = "Choose a CSV file to import"
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.ImportEntrants(form, app.getAllFlashes(r)))
+		return
+	}
+
+	report, err := app.registrationService.ImportEntrants(ctx, race, file)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			form.Error = fmt.Sprintf("The file is too large; imports are limited to %d MB", maxImportBytes>>20)
+		case errors.Is(err, service.ErrInvalidInput), errors.Is(err, service.ErrRaceFull):
+			form.Error = "Nothing was imported: " + err.Error()
+		default:
+			app.handleServiceError(w, r, err)
+			return
+		}
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.ImportEntrants(form, app.getAllFlashes(r)))
+		return
+	}
+
+	form.Report = &viewmodels.ImportReportViewModel{
+		Imported: report.Imported,
+		Created:  report.Count(service.ImportRowCreated),
+		Skipped:  report.Count(service.ImportRowSkippedDuplicate),
+		Failed:   report.Count(service.ImportRowError),
+	}
+	for _, row := range report.Rows {
+		form.Report.Rows = append(form.Report.Rows, viewmodels.ImportRowViewModel{
+			Line:    row.Line,
+			Email:   row.Email,
+			Status:  string(row.Status),
+			Message: row.Message,
+		})
+	}
+
+	status := http.StatusOK
+	if !report.Imported {
+		status = http.StatusUnprocessableEntity
+	}
+	app.render(r.Context(), w, status, admin.ImportEntrants(form, app.getAllFlashes(r)))
+}
+
+func (app *application) adminResultsView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	form, err := app.adminResultsForm(ctx, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.render(r.Context(), w, http.StatusOK, admin.RaceResults(form, app.getAllFlashes(r)))
+}
+
+func (app *application) adminResultsPost(w http.ResponseWriter, r *http.Request) {
+	// Large files take longer to upload and check than ordinary requests
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(importTimeout)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetReadDeadline(deadline)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetWriteDeadline(deadline)
+
+	ctx, cancel := context.WithTimeout(r.Context(), importTimeout)
+	defer cancel()
+
+	race, event, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	file, err := importFile(r)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	var report service.ResultsReport
+	var uploadErr string
+	if file == nil {
+		uploadErr = "Choose a CSV file to upload"
+	} else if report, err = app.resultService.UploadResults(ctx, race, file); err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			uploadErr = fmt.Sprintf("The file is too large; uploads are limited to %d MB", maxImportBytes>>20)
+		case errors.Is(err, service.ErrInvalidInput):
+			uploadErr = "Nothing was saved: " + err.Error()
+		default:
+			app.handleServiceError(w, r, err)
+			return
+		}
+	}
+
+	if uploadErr == "" && len(report.Errors) == 0 {
+		app.addFlash(r, FlashSuccess, fmt.Sprintf("Uploaded %d results. Check them below, then publish them", report.Saved))
+		http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+		return
+	}
+
+	form, err := app.adminResultsForm(ctx, race, event)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	form.Error = uploadErr
+	for _, row := range report.Errors {
+		form.Errors = append(form.Errors, viewmodels.ResultErrorViewModel{Line: row.Line, Message: row.Message})
+	}
+	app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.RaceResults(form, app.getAllFlashes(r)))
+}
+
+func (app *application) adminPublishResultsPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	race, _, ok := app.loadManagedRace(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	resultsURL := "/admin/races/" + strconv.FormatInt(race.ID, 10) + "/results"
+	if err := app.resultService.PublishResults(ctx, race.ID); err != nil {
+		if !errors.Is(err, service.ErrNoResults) {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		app.addFlash(r, FlashError, "Upload results before publishing them")
+		http.Redirect(w, r, resultsURL, http.StatusSeeOther)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Results published")
+	http.Redirect(w, r, resultsURL, http.StatusSeeOther)
+}
+
+// adminResultsForm prepares the results page with the race's current results.
+func (app *application) adminResultsForm(ctx context.Context, race db.Race, event db.Event) (viewmodels.AdminResultsViewModel, error) {
+	results, err := app.resultService.ListResults(ctx, race.ID)
+	if err != nil {
+		return viewmodels.AdminResultsViewModel{}, err
+	}
+	return viewmodels.NewAdminResultsViewModel(race, event, results, app.importMaxRows), nil
+}
+
+// importFile returns the uploaded file part of a multipart import form
+// without buffering it, or nil if no file was chosen.
+func importFile(r *http.Request) (io.Reader, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" && part.FileName() != "" {
+			return part, nil
+		}
+	}
+}
+
+// loadManagedEvent fetches the event named by the {id} path value, writing a
+// 404 if it does not exist or the user cannot manage its organisation.
+func (app *application) loadManagedEvent(ctx context.Context, w http.ResponseWriter, r *http.Request) (db.Event, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return db.Event{}, false
+	}
+
+	return app.loadManagedEventByID(ctx, w, r, id)
+}
+
+// loadManagedRace fetches the race named by the {id} path value and its
+// event, writing a 404 if either does not exist or the user cannot manage
+// the event's organisation.
+func (app *application) loadManagedRace(ctx context.Context, w http.ResponseWriter, r *http.Request) (db.Race, db.Event, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return db.Race{}, db.Event{}, false
+	}
+
+	race, err := app.raceService.GetRaceByID(ctx, id)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return db.Race{}, db.Event{}, false
+	}
+
+	event, ok := app.loadManagedEventByID(ctx, w, r, race.EventID)
+	return race, event, ok
+}
+
+// loadManagedEventByID is loadManagedEvent for an event ID found elsewhere.
+func (app *application) loadManagedEventByID(ctx context.Context, w http.ResponseWriter, r *http.Request, id int64) (db.Event, bool) {
+	event, err := app.eventService.GetEventByID(ctx, id)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return db.Event{}, false
+	}
+
+	// Events of organisations the user is not a member of are hidden
+	// rather than forbidden, so their IDs cannot be probed
+	user, _ := getUserFromContext(r)
+	allowed, err := app.organisationService.CanManageEvent(ctx, user.ID, event.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return db.Event{}, false
+	}
+	if !allowed {
+		app.notFound(w, r)
+		return db.Event{}, false
+	}
+	// Following a link into another of the user's organisations switches
+	// to it
+	if err := app.useOrganisation(ctx, r, event.OrganisationID); err != nil {
+		app.handleServiceError(w, r, err)
+		return db.Event{}, false
+	}
+
+	return event, true
+}
+
+// managedOrganisations returns the organisations the user works in: every
+// organisation for admins, and those they are a member of for organisers.
+func (app *application) managedOrganisations(ctx context.Context, user db.User) ([]db.Organisation, error) {
+	if user.Role == db.UserRoleAdmin {
+		return app.organisationService.ListOrganisations(ctx)
+	}
+	return app.organisationService.ListOrganisationsForUser(ctx, user.ID)
+}
+
+// currentOrganisation returns the organisation among orgs that admin pages
+// are scoped to: the one chosen in this session, else the one the user last
+// chose, else the first. orgs must not be empty.
+func (app *application) currentOrganisation(ctx context.Context, r *http.Request, orgs []db.Organisation) (int64, error) {
+	managed := func(id int64) bool {
+		return slices.ContainsFunc(orgs, func(o db.Organisation) bool { return o.ID == id })
+	}
+	if id := app.sessionManager.GetInt64(r.Context(), sessionOrganisationIDKey); managed(id) {
+		return id, nil
+	}
+
+	user, _ := getUserFromContext(r)
+	id, err := app.organisationService.LastUsedOrganisation(ctx, user.ID)
+	switch {
+	case err == nil && managed(id):
+		return id, nil
+	case err != nil && !errors.Is(err, repository.ErrNotFound):
+		return 0, err
+	}
+	return orgs[0].ID, nil
+}
+
+// useOrganisation scopes admin pages to the organisation from now on, and
+// remembers it as the one the user last used so that it is their default
+// next time they sign in. Admins impersonating someone leave the user's
+// default alone.
+func (app *application) useOrganisation(ctx context.Context, r *http.Request, orgID int64) error {
+	if app.sessionManager.GetInt64(r.Context(), sessionOrganisationIDKey) == orgID {
+		return nil
+	}
+	app.sessionManager.Put(r.Context(), sessionOrganisationIDKey, orgID)
+	if nav := viewmodels.NavFromContext(r.Context()); nav.Organisations != nil {
+		nav.Organisations.CurrentID = orgID
+	}
+
+	if _, impersonating := r.Context().Value(contextKeyRealUser).(db.User); impersonating {
+		return nil
+	}
+	user, _ := getUserFromContext(r)
+	return app.organisationService.UseOrganisation(ctx, user.ID, orgID)
+}
+
+func (app *application) adminSwitchOrganisationPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	orgID, err := strconv.ParseInt(r.PostFormValue("organisation_id"), 10, 64)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	user, _ := getUserFromContext(r)
+	orgs, err := app.managedOrganisations(ctx, user)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	if !slices.ContainsFunc(orgs, func(o db.Organisation) bool { return o.ID == orgID }) {
+		app.notFound(w, r)
+		return
+	}
+
+	if err := app.useOrganisation(ctx, r, orgID); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+}
+
+func (app *application) adminMembersView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	vm, err := app.membersPage(ctx, org)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	app.render(r.Context(), w, http.StatusOK, admin.Members(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) adminInviteMemberPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	email := strings.TrimSpace(r.PostForm.Get("email"))
+	role := db.OrganisationRole(r.PostForm.Get("role"))
+	user, _ := getUserFromContext(r)
+	invite, err := app.organisationService.InviteMember(ctx, user.ID, org.ID, email, role)
+	if err != nil {
+		formErrors := make(map[string]string)
+		switch {
+		case errors.Is(err, service.ErrAlreadyMember):
+			formErrors["email"] = "This person is already a member"
+		case errors.Is(err, service.ErrInvalidInput):
+			formErrors["form"] = err.Error()
+		default:
+			app.handleServiceError(w, r, err)
+			return
+		}
+
+		vm, err := app.membersPage(ctx, org)
+		if err != nil {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		vm.Email, vm.Role, vm.Errors = email, string(role), formErrors
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.Members(vm, app.getAllFlashes(r)))
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Invitation sent to "+invite.Email)
+	http.Redirect(w, r, viewmodels.MembersViewModel{OrganisationID: org.ID}.ActionURL(), http.StatusSeeOther)
+}
+
+func (app *application) adminRemoveMemberPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.ParseInt(r.PathValue("userID"), 10, 64)
+	if err != nil || memberID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	user, _ := getUserFromContext(r)
+	err = app.organisationService.RemoveMember(ctx, user.ID, org.ID, memberID)
+	switch {
+	case err == nil:
+		app.addFlash(r, FlashSuccess, "Member removed")
+	case errors.Is(err, service.ErrOwnerRemoval):
+		app.addFlash(r, FlashError, "Owners cannot be removed")
+	case errors.Is(err, repository.ErrNotFound):
+		app.addFlash(r, FlashError, "That person is not a member")
+	default:
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, viewmodels.MembersViewModel{OrganisationID: org.ID}.ActionURL(), http.StatusSeeOther)
+}
+
+func (app *application) adminContactEmailPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	email := strings.TrimSpace(r.PostForm.Get("contact_email"))
+	err := app.contactService.SetContactEmail(ctx, org.ID, email)
+	if err != nil {
+		formErrors, invalid := fieldErrors(err)
+		if !invalid {
+			app.handleServiceError(w, r, err)
+			return
+		}
+
+		vm, err := app.membersPage(ctx, org)
+		if err != nil {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		vm.ContactEmail, vm.Errors = email, formErrors
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.Members(vm, app.getAllFlashes(r)))
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Contact address saved")
+	http.Redirect(w, r, viewmodels.MembersViewModel{OrganisationID: org.ID}.ActionURL(), http.StatusSeeOther)
+}
+
+func (app *application) adminEmbeddingPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	origins := r.PostForm.Get("embed_origins")
+	public := r.PostForm.Get("embed_public") != ""
+	err := app.organisationService.SetEmbedding(ctx, org.ID, public, strings.Split(origins, "\n"))
+	if err != nil {
+		formErrors, invalid := fieldErrors(err)
+		if !invalid {
+			app.handleServiceError(w, r, err)
+			return
+		}
+
+		vm, err := app.membersPage(ctx, org)
+		if err != nil {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		vm.EmbedPublic, vm.EmbedOrigins, vm.Errors = public, origins, formErrors
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.Members(vm, app.getAllFlashes(r)))
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Availability widget settings saved")
+	http.Redirect(w, r, viewmodels.MembersViewModel{OrganisationID: org.ID}.ActionURL(), http.StatusSeeOther)
+}
+
+// membersPage loads the members page for an organisation.
+func (app *application) membersPage(ctx context.Context, org db.Organisation) (viewmodels.MembersViewModel, error) {
+	members, err := app.organisationService.ListMembers(ctx, org.ID)
+	if err != nil {
+		return viewmodels.MembersViewModel{}, err
+	}
+	invitations, err := app.organisationService.ListPendingInvitations(ctx, org.ID)
+	if err != nil {
+		return viewmodels.MembersViewModel{}, err
+	}
+	vm := viewmodels.NewMembersViewModel(org, members, invitations)
+	vm.SiteURL = app.publicBaseURL
+	return vm, nil
+}
+
+func (app *application) adminBrandingView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+	vm := viewmodels.NewBrandingFormViewModel(org, service.MaxPhotoBytes)
+	app.render(r.Context(), w, http.StatusOK, admin.Branding(vm, app.getAllFlashes(r)))
+}
+
+// maxBrandingUploadBytes bounds the branding form: a logo and a banner,
+// each no larger than a photo, and the rest of the form around them.
+const maxBrandingUploadBytes = 2*service.MaxPhotoBytes + 64<<10
+
+// brandingFormMemory is how much of the branding form is held in memory
+// while it is parsed. Larger files wait on disk.
+const brandingFormMemory = 1 << 20
+
+func (app *application) adminBrandingPost(w http.ResponseWriter, r *http.Request) {
+	// Images take longer to upload than ordinary requests
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(importTimeout)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetReadDeadline(deadline)
+	//nolint:errcheck // unsupported writers keep the server's timeouts
+	rc.SetWriteDeadline(deadline)
+
+	ctx, cancel := context.WithTimeout(r.Context(), importTimeout)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	vm := viewmodels.NewBrandingFormViewModel(org, service.MaxPhotoBytes)
+	r.Body = http.MaxBytesReader(w, r.Body, maxBrandingUploadBytes)
+	if err := r.ParseMultipartForm(brandingFormMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if !errors.As(err, &tooLarge) {
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		}
+		vm.Errors = map[string]string{
+			"form": fmt.Sprintf("The files are too large; images are limited to %d MB each", service.MaxPhotoBytes>>20),
+		}
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.Branding(vm, app.getAllFlashes(r)))
+		return
+	}
+	//nolint:errcheck // leftover temporary files are not the user's problem
+	defer r.MultipartForm.RemoveAll()
+
+	input := service.BrandingInput{
+		Colour:       r.PostForm.Get("brand_colour"),
+		RemoveLogo:   r.PostForm.Get("remove_logo") != "",
+		RemoveBanner: r.PostForm.Get("remove_banner") != "",
+	}
+	for name, into := range map[string]*io.Reader{"logo": &input.Logo, "banner": &input.Banner} {
+		file, _, err := r.FormFile(name)
+		if errors.Is(err, http.ErrMissingFile) {
+			continue
+		}
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		*into = file
+	}
+
+	_, err := app.brandingService.SetBranding(ctx, org.ID, input)
+	if err != nil {
+		formErrors, invalid := fieldErrors(err)
+		if !invalid {
+			app.handleServiceError(w, r, err)
+			return
+		}
+		vm.Colour, vm.Errors = input.Colour, formErrors
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.Branding(vm, app.getAllFlashes(r)))
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Branding saved")
+	http.Redirect(w, r, viewmodels.BrandingURL(org.ID), http.StatusSeeOther)
+}
+
+func (app *application) adminWebhooksView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	endpoints, err := app.webhookService.ListEndpoints(ctx, org.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vm := viewmodels.NewWebhooksViewModel(org, endpoints)
+	app.render(r.Context(), w, http.StatusOK, admin.Webhooks(vm, app.getAllFlashes(r)))
+}
+
+// webhookForm is the form posted to add a webhook endpoint. The length
+// limit matches service.MaxWebhookURLLength.
+type webhookForm struct {
+	URL    string   `form:"url,required,max=2000" label:"URL"`
+	Events []string `form:"events,required"`
+}
+
+func (app *application) adminCreateWebhookPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	var input webhookForm
+	err := decodeForm(r, &input)
+	formErrors, invalid := fieldErrors(err)
+	if err != nil && !invalid {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	var created service.CreatedWebhookEndpoint
+	if !invalid {
+		created, err = app.webhookService.CreateEndpoint(ctx, org.ID, service.WebhookEndpointInput{
+			URL:    input.URL,
+			Events: input.Events,
+		})
+		if err != nil {
+			if formErrors, invalid = fieldErrors(err); !invalid {
+				app.handleServiceError(w, r, err)
+				return
+			}
+		}
+	}
+
+	endpoints, err := app.webhookService.ListEndpoints(ctx, org.ID)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vm := viewmodels.NewWebhooksViewModel(org, endpoints)
+
+	if invalid {
+		vm.URL, vm.Events, vm.Errors = input.URL, input.Events, formErrors
+		app.render(r.Context(), w, http.StatusUnprocessableEntity, admin.Webhooks(vm, app.getAllFlashes(r)))
+		return
+	}
+
+	// The secret is shown in this response alone, as API tokens are, so
+	// it is never kept in the session
+	vm.CreatedSecret = created.Secret
+	w.Header().Set("Cache-Control", "no-store")
+	app.render(r.Context(), w, http.StatusOK, admin.Webhooks(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) adminDeleteWebhookPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("webhookID"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	if err := app.webhookService.DeleteEndpoint(ctx, org.ID, id); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	app.addFlash(r, FlashSuccess, "Webhook endpoint deleted")
+	http.Redirect(w, r, viewmodels.WebhooksURL(org.ID), http.StatusSeeOther)
+}
+
+func (app *application) adminWebhookLogView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("webhookID"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	log, err := app.webhookService.DeliveryLog(ctx, org.ID, id)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vm := viewmodels.NewWebhookLogViewModel(org, log.Endpoint, log.Deliveries, log.Attempts)
+	app.render(r.Context(), w, http.StatusOK, admin.WebhookLog(vm))
+}
+
+// paymentRange reads the payments report's days from the from and to
+// query parameters, dates with to included, and returns them as the
+// instants the report runs from and until, in UTC. Either left out
+// defaults to the current month's.
+func (app *application) paymentRange(r *http.Request) (from, until time.Time, ok bool) {
+	now := app.clock.Now().UTC()
+	from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	until = from.AddDate(0, 1, 0)
+
+	q := r.URL.Query()
+	if s := q.Get("from"); s != "" {
+		t, err := time.Parse(viewmodels.PaymentDateLayout, s)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+	if s := q.Get("to"); s != "" {
+		t, err := time.Parse(viewmodels.PaymentDateLayout, s)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		until = t.AddDate(0, 0, 1)
+	}
+	return from, until, true
+}
+
+func (app *application) adminPaymentsView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	from, until, ok := app.paymentRange(r)
+	if !ok {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	report, err := app.paymentService.Report(ctx, org.ID, from, until)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vm := viewmodels.NewPaymentsViewModel(org, from, until, report)
+	app.render(r.Context(), w, http.StatusOK, admin.Payments(vm))
+}
+
+// paymentColumns heads the payments export. Amounts are in major units
+// without a symbol, and fee is left blank where Stripe did not report one.
+var paymentColumns = []string{
+	"date", "reference", "registration", "entrant", "email", "event", "race",
+	"status", "currency", "gross", "fee", "refunded", "net",
+}
+
+func (app *application) adminExportPayments(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	from, until, ok := app.paymentRange(r)
+	if !ok {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	org, ok := app.loadManagedOrganisation(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	report, err := app.paymentService.Report(ctx, org.ID, from, until)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	vm := viewmodels.NewPaymentsViewModel(org, from, until, report)
+
+	w.Header().Set("Content-Type", export.CSV.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.CSV.Filename("payments-"+vm.From+"-to-"+vm.To)))
+
+	// The status is sent with the first row, so a failure part way through
+	// can only be logged
+	err = func() error {
+		out, err := export.New(export.CSV, w, paymentColumns)
+		if err != nil {
+			return err
+		}
+		for _, p := range vm.Payments {
+			fee := ""
+			if p.Fee != nil {
+				fee = p.Fee.Amount()
+			}
+			if err := out.WriteRow([]string{
+				p.Date, p.Reference, strconv.FormatInt(p.RegistrationID, 10), p.Entrant, p.Email, p.Event, p.Race,
+				string(p.Status), p.Gross.Currency, p.Gross.Amount(), fee, p.Refunded.Amount(), p.Net.Amount(),
+			}); err != nil {
+				return err
+			}
+		}
+		return out.Close()
+	}()
+	if err != nil && !app.clientGone(r, err) {
+		app.logger.Error("failed to write payments export", "request_id", getRequestID(r), "uri", r.URL.RequestURI(), "error", err)
+	}
+}
+
+// maxStripeEventBytes bounds the body of a Stripe webhook event. Charges
+// are a few kilobytes even with their balance transaction expanded.
+const maxStripeEventBytes = 1 << 20
+
+// stripeWebhook records the charges Stripe tells us about, for the payments
+// report. Charges are matched to registrations by the registration_id in
+// their metadata; those without one were not taken by us and are ignored.
+// Stripe retries events not answered with a 2xx, so events that can never
+// be recorded are logged and acknowledged.
+func (app *application) stripeWebhook(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxStripeEventBytes)
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	if err := stripe.Verify(payload, r.Header.Get(stripe.SignatureHeader), app.stripeWebhookSecret, app.clock.Now()); err != nil {
+		app.logger.Warn("rejected Stripe event", "request_id", getRequestID(r), "error", err)
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	event, err := stripe.ParseEvent(payload)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	switch event.Type {
+	case stripe.EventChargeSucceeded, stripe.EventChargeRefunded, stripe.EventChargeFailed:
+	default:
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	charge, err := event.Charge()
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	registrationID, err := strconv.ParseInt(charge.Metadata["registration_id"], 10, 64)
+	if err != nil {
+		app.logger.Info("ignored Stripe charge without a registration", "event", event.ID, "charge", charge.ID)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	input := service.ProviderCharge{
+		Reference:      charge.ID,
+		RegistrationID: registrationID,
+		AmountUnits:    charge.Amount,
+		Currency:       charge.Currency,
+		RefundedUnits:  charge.AmountRefunded,
+		Failed:         event.Type == stripe.EventChargeFailed,
+		CreatedAt:      time.Unix(charge.Created, 0),
+	}
+	if fee, ok := charge.Fee(); ok {
+		input.FeeUnits = &fee
+	}
+
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+	if _, err := app.paymentService.RecordCharge(ctx, input); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			app.logger.Warn("ignored Stripe charge for an unknown registration", "event", event.ID, "charge", charge.ID, "registration_id", registrationID)
+		case errors.Is(err, service.ErrInvalidInput):
+			app.logger.Warn("rejected Stripe charge", "event", event.ID, "charge", charge.ID, "error", err)
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		default:
+			app.serverError(w, r, err)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// loadManagedOrganisation fetches the organisation named by the {id} path
+// value, writing a 404 if it does not exist or the user cannot manage its
+// members.
+func (app *application) loadManagedOrganisation(ctx context.Context, w http.ResponseWriter, r *http.Request) (db.Organisation, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return db.Organisation{}, false
+	}
+
+	user, _ := getUserFromContext(r)
+	allowed, err := app.organisationService.CanManageMembers(ctx, user.ID, id)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return db.Organisation{}, false
+	}
+	if !allowed {
+		app.notFound(w, r)
+		return db.Organisation{}, false
+	}
+
+	org, err := app.organisationService.GetOrganisation(ctx, id)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return db.Organisation{}, false
+	}
+	if err := app.useOrganisation(ctx, r, org.ID); err != nil {
+		app.handleServiceError(w, r, err)
+		return db.Organisation{}, false
+	}
+	return org, true
+}
+
+func (app *application) adminUsersView(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	page, ok := parsePageParam(r, "page", 1)
+	if !ok {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	params := service.ListUsersParams{
+		Query:   strings.TrimSpace(r.URL.Query().Get("q")),
+		Role:    db.UserRole(r.URL.Query().Get("role")),
+		Page:    page,
+		PerPage: service.DefaultUsersPerPage,
+	}
+	result, err := app.userService.ListUsers(ctx, params)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	viewer, _ := getUserFromContext(r)
+	vm := viewmodels.NewUsersViewModel(params.Query, params.Role, result.Users, viewer.ID, app.clock.Now())
+	vm.Page, vm.PerPage, vm.Total = result.Page, result.PerPage, result.Total
+	app.render(r.Context(), w, http.StatusOK, admin.Users(vm, app.getAllFlashes(r)))
+}
+
+func (app *application) adminUnlockUserPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	targetID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || targetID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	user, _ := getUserFromContext(r)
+	err = app.authService.UnlockAccount(ctx, user.ID, targetID)
+	switch {
+	case err == nil:
+		app.addFlash(r, FlashSuccess, "Account unlocked")
+	default:
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+func (app *application) adminUserRolePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	targetID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || targetID < 1 {
+		app.notFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	user, _ := getUserFromContext(r)
+	role := db.UserRole(r.PostForm.Get("role"))
+	err = app.userService.UpdateUserRole(ctx, user.ID, targetID, role)
+	switch {
+	case err == nil:
+		app.addFlash(r, FlashSuccess, "Role changed to "+viewmodels.UserRoleLabel(role))
+	case errors.Is(err, service.ErrOwnAccount):
+		app.addFlash(r, FlashError, "You cannot change your own role")
+	default:
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+func (app *application) adminUserActivePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	targetID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || targetID < 1 {
+		app.notFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+	active, err := strconv.ParseBool(r.PostForm.Get("active"))
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	user, _ := getUserFromContext(r)
+	err = app.userService.SetUserActive(ctx, user.ID, targetID, active)
+	switch {
+	case err == nil && active:
+		app.addFlash(r, FlashSuccess, "Account reactivated")
+	case err == nil:
+		app.addFlash(r, FlashSuccess, "Account deactivated")
+	case errors.Is(err, service.ErrOwnAccount):
+		app.addFlash(r, FlashError, "You cannot deactivate your own account")
+	default:
+		app.handleServiceError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+// stopImpersonatingPattern is the route impersonating admins stop by, which
+// guardImpersonation lets through unaudited as StopImpersonation records it.
+const stopImpersonatingPattern = "POST " + viewmodels.StopImpersonatingURL
+
+// adminImpersonatePost starts the signed-in admin acting as the user, so
+// support staff see the site as they do. The session keeps the admin's own
+// ID alongside the user's.
+func (app *application) adminImpersonatePost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	targetID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || targetID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	admin, _ := getUserFromContext(r)
+	target, err := app.userService.StartImpersonation(ctx, admin.ID, targetID)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrOwnAccount):
+		app.addFlash(r, FlashError, "You cannot impersonate yourself")
+		http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+		return
+	case errors.Is(err, service.ErrCannotImpersonate):
+		app.addFlash(r, FlashError, "Admins and deactivated accounts cannot be impersonated")
+		http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+		return
+	default:
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	if err := app.sessionManager.RenewToken(r.Context()); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	app.sessionManager.Put(r.Context(), sessionImpersonatedUserIDKey, target.ID)
+	app.addFlash(r, FlashInfo, fmt.Sprintf("You are now impersonating %s", target.Email))
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// stopImpersonatingPost returns the admin to their own identity.
+func (app *application) stopImpersonatingPost(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	admin, _ := getRealUserFromContext(r)
+	user, _ := getUserFromContext(r)
+	if user.ID == admin.ID {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	if err := app.userService.StopImpersonation(ctx, admin.ID, user.ID); err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Remove(r.Context(), sessionImpersonatedUserIDKey)
+	if err := app.sessionManager.RenewToken(r.Context()); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	app.addFlash(r, FlashInfo, fmt.Sprintf("You are no longer impersonating %s", user.Email))
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+func (app *application) adminCreateUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	_, err := app.userService.CreateUser(ctx, service.CreateUserInput{
+		Email:     "user@example.com",
+		FirstName: "Kristian",
+		LastName:  "Roebuck",
+		Role:      "admin",
+	})
+
+	if err != nil {
+		app.handleServiceError(w, r, err)
 		return
 	}
 
@@ -1,88 +1,133 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"firecrest/db"
+	"firecrest/internal/i18n"
+	"firecrest/internal/metrics"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/mocks/servicemocks"
 	"firecrest/internal/repository"
 	"firecrest/internal/service"
+	"firecrest/internal/session"
+	"firecrest/internal/stripe"
 )
 
-// mockEventService implements service.EventService for testing.
-type mockEventService struct {
-	listEventsFunc  func(ctx context.Context) ([]db.Event, error)
-	getEventFunc    func(ctx context.Context, slug string) (db.Event, error)
-	createEventFunc func(ctx context.Context, input service.CreateEventInput) (db.Event, error)
-}
-
-func (m *mockEventService) ListEvents(ctx context.Context) ([]db.Event, error) {
-	if m.listEventsFunc != nil {
-		return m.listEventsFunc(ctx)
+// memberOrganisationService returns an organisation service for an
+// organiser who is a member only of organisation orgID. events maps the IDs
+// of the events that exist to their organisations.
+func memberOrganisationService(orgID int64, events map[int64]int64) *servicemocks.OrganisationServiceMock {
+	return &servicemocks.OrganisationServiceMock{
+		ListOrganisationsForUserFunc: func(ctx context.Context, userID int64) ([]db.Organisation, error) {
+			return []db.Organisation{{ID: orgID, Name: "Peak Running Co"}}, nil
+		},
+		CanManageEventFunc: func(ctx context.Context, userID, eventID int64) (bool, error) {
+			eventOrgID, ok := events[eventID]
+			if !ok {
+				return false, repository.ErrNotFound
+			}
+			return eventOrgID == orgID, nil
+		},
+		CanCreateEventsFunc: func(ctx context.Context, userID, organisationID int64) (bool, error) {
+			return organisationID == orgID, nil
+		},
 	}
-	return nil, nil
 }
 
-func (m *mockEventService) GetEvent(ctx context.Context, slug string) (db.Event, error) {
-	if m.getEventFunc != nil {
-		return m.getEventFunc(ctx, slug)
+func newTestApplication(eventSvc service.EventService, userSvc service.UserService) *application {
+	return &application{
+		logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
+		sessionManager:      scs.New(),
+		eventService:        eventSvc,
+		userService:         userSvc,
+		authService:         &servicemocks.AuthServiceMock{},
+		organisationService: &servicemocks.OrganisationServiceMock{},
+		raceService:         &servicemocks.RaceServiceMock{},
+		registrationCounter: &servicemocks.RegistrationCounterMock{},
+		registrationService: &servicemocks.RegistrationServiceMock{},
+		discountService:     &servicemocks.DiscountServiceMock{},
+		photoService:        &servicemocks.PhotoServiceMock{},
+		brandingService:     &servicemocks.BrandingServiceMock{},
+		resultService:       &servicemocks.ResultServiceMock{},
+		tokenService:        &servicemocks.TokenServiceMock{},
+		sessionService:      &servicemocks.SessionServiceMock{},
+		webhookService:      &servicemocks.WebhookServiceMock{},
+		contactService:      &servicemocks.ContactServiceMock{},
+		dataExportService:   &servicemocks.DataExportServiceMock{},
+		paymentService:      &servicemocks.PaymentServiceMock{},
+		availability:        newAvailabilityHub(availabilityHeartbeat),
+		contactLimiter:      newRateLimiter(contactLimit, contactWindow, service.RealClock{}),
+		metrics:             metrics.New(),
+		clock:               service.RealClock{},
+		translations:        i18n.Must(i18n.New(slog.New(slog.NewTextHandler(io.Discard, nil)))),
+		sessionLifetime:     24 * time.Hour,
+		rememberMeLifetime:  30 * 24 * time.Hour,
+		importMaxRows:       10000,
+		serveMetrics:        true,
+		publicBaseURL:       "https://firecrest.example",
 	}
-	return db.Event{}, nil
 }
 
-func (m *mockEventService) CreateEvent(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
-	if m.createEventFunc != nil {
-		return m.createEventFunc(ctx, input)
-	}
-	return db.Event{}, nil
-}
+// fixedClock is a service.Clock that always reports the same instant.
+type fixedClock time.Time
 
-// mockUserService implements service.UserService for testing.
-type mockUserService struct {
-	getUserFunc    func(ctx context.Context, id int64) (db.User, error)
-	createUserFunc func(ctx context.Context, input service.CreateUserInput) (db.User, error)
-}
+func (c fixedClock) Now() time.Time { return time.Time(c) }
 
-func (m *mockUserService) GetUser(ctx context.Context, id int64) (db.User, error) {
-	if m.getUserFunc != nil {
-		return m.getUserFunc(ctx, id)
-	}
-	return db.User{}, nil
+// storedSessions returns the test application's session manager, which
+// keeps sessions in memory for tests to read and plant.
+func storedSessions(app *application) *scs.SessionManager {
+	return app.sessionManager.(*scs.SessionManager)
 }
 
-func (m *mockUserService) CreateUser(ctx context.Context, input service.CreateUserInput) (db.User, error) {
-	if m.createUserFunc != nil {
-		return m.createUserFunc(ctx, input)
-	}
-	return db.User{}, nil
+// withSession wraps a handler in the session middleware so it can read and write flashes.
+func withSession(app *application, h http.HandlerFunc) http.Handler {
+	return app.sessionManager.LoadAndSave(h)
 }
 
-func newTestApplication(eventSvc service.EventService, userSvc service.UserService) *application {
-	return &application{
-		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
-		eventService: eventSvc,
-		userService:  userSvc,
+func TestHome(t *testing.T) {
+	// listed returns rows for events without races
+	listed := func(events ...db.Event) []db.ListEventsWithStatsRow {
+		rows := make([]db.ListEventsWithStatsRow, 0, len(events))
+		for _, e := range events {
+			rows = append(rows, db.ListEventsWithStatsRow{Event: e})
+		}
+		return rows
 	}
-}
 
-func TestHome(t *testing.T) {
 	t.Run("returns 200 and renders events", func(t *testing.T) {
-		mockEventSvc := &mockEventService{
-			listEventsFunc: func(ctx context.Context) ([]db.Event, error) {
-				return []db.Event{
-					{ID: 1, Name: "Test Event 1", Slug: "test-event-1"},
-					{ID: 2, Name: "Test Event 2", Slug: "test-event-2"},
-				}, nil
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListEventsWithStatsFunc: func(ctx context.Context, params service.ListEventsParams) ([]db.ListEventsWithStatsRow, error) {
+				return listed(
+					db.Event{ID: 1, Name: "Test Event 1", Slug: "test-event-1"},
+					db.Event{ID: 2, Name: "Test Event 2", Slug: "test-event-2"},
+				), nil
 			},
 		}
 
-		app := newTestApplication(mockEventSvc, &mockUserService{})
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
 
 		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
 		rr := httptest.NewRecorder()
@@ -94,14 +139,73 @@ func TestHome(t *testing.T) {
 		}
 	})
 
+	t.Run("renders registration badges from one listing query", func(t *testing.T) {
+		events := make([]db.Event, 20)
+		for i := range events {
+			events[i] = db.Event{ID: int64(i + 1), Name: fmt.Sprintf("Event %d", i+1), Slug: fmt.Sprintf("event-%d", i+1)}
+		}
+		rows := listed(events...)
+		rows[0].RaceCount, rows[0].TotalCapacity, rows[0].Registered = 2, 500, 342
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListEventsWithStatsFunc: func(ctx context.Context, params service.ListEventsParams) ([]db.ListEventsWithStatsRow, error) {
+				return rows, nil
+			},
+		}
+		mockRaceSvc := &servicemocks.RaceServiceMock{}
+		mockCounter := &servicemocks.RegistrationCounterMock{}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+		app.raceService = mockRaceSvc
+		app.registrationCounter = mockCounter
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.home(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		calls := mockEventSvc.ListEventsWithStatsCalls()
+		if len(calls) != 1 || calls[0].Params.Page != 1 || calls[0].Params.PerPage != service.DefaultEventsPerPage {
+			t.Errorf("expected one listing of the first page, got %+v", calls)
+		}
+		if n, m := len(mockRaceSvc.ListRacesByEventsCalls()), len(mockCounter.CountByEventsCalls()); n != 0 || m != 0 {
+			t.Errorf("expected no race or count lookups, got %d and %d", n, m)
+		}
+		if !strings.Contains(rr.Body.String(), "342/500 registered") {
+			t.Error("expected registration badge in response body")
+		}
+	})
+
+	t.Run("renders urgency badges", func(t *testing.T) {
+		rows := listed(db.Event{ID: 1, Name: "Test Event 1", Slug: "test-event-1"})
+		rows[0].RaceCount, rows[0].TotalCapacity, rows[0].Registered = 1, 100, 100
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListEventsWithStatsFunc: func(ctx context.Context, params service.ListEventsParams) ([]db.ListEventsWithStatsRow, error) {
+				return rows, nil
+			},
+		}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.home(rr, req)
+
+		if !strings.Contains(rr.Body.String(), `data-event-badge="sold-out"`) {
+			t.Error("expected a sold out badge in response body")
+		}
+	})
+
 	t.Run("returns 200 with empty events list", func(t *testing.T) {
-		mockEventSvc := &mockEventService{
-			listEventsFunc: func(ctx context.Context) ([]db.Event, error) {
-				return []db.Event{}, nil
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListEventsWithStatsFunc: func(ctx context.Context, params service.ListEventsParams) ([]db.ListEventsWithStatsRow, error) {
+				return []db.ListEventsWithStatsRow{}, nil
 			},
 		}
 
-		app := newTestApplication(mockEventSvc, &mockUserService{})
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
 
 		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
 		rr := httptest.NewRecorder()
@@ -113,14 +217,57 @@ func TestHome(t *testing.T) {
 		}
 	})
 
+	t.Run("answers a conditional GET with 304 until a count changes", func(t *testing.T) {
+		registered := int64(10)
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListEventsWithStatsFunc: func(ctx context.Context, params service.ListEventsParams) ([]db.ListEventsWithStatsRow, error) {
+				rows := listed(db.Event{ID: 1, Name: "Test Event 1", Slug: "test-event-1"})
+				rows[0].Registered = registered
+				return rows, nil
+			},
+		}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+		get := func(etag string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			rr := httptest.NewRecorder()
+			app.home(rr, req)
+			return rr
+		}
+
+		first := get("")
+		etag := first.Header().Get("ETag")
+		if first.Code != http.StatusOK || etag == "" {
+			t.Fatalf("expected 200 with an ETag, got %d and %q", first.Code, etag)
+		}
+		if got := first.Header().Get("Cache-Control"); got != "private, no-cache" {
+			t.Errorf("expected private, no-cache, got %q", got)
+		}
+
+		rr := get(etag)
+		if rr.Code != http.StatusNotModified {
+			t.Fatalf("expected status %d, got %d", http.StatusNotModified, rr.Code)
+		}
+		if rr.Body.Len() != 0 {
+			t.Errorf("expected no body, got %d bytes", rr.Body.Len())
+		}
+
+		registered++
+		if rr := get(etag); rr.Code != http.StatusOK {
+			t.Errorf("expected status %d after a new registration, got %d", http.StatusOK, rr.Code)
+		}
+	})
+
 	t.Run("returns 500 on service error", func(t *testing.T) {
-		mockEventSvc := &mockEventService{
-			listEventsFunc: func(ctx context.Context) ([]db.Event, error) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListEventsWithStatsFunc: func(ctx context.Context, params service.ListEventsParams) ([]db.ListEventsWithStatsRow, error) {
 				return nil, errors.New("database connection failed")
 			},
 		}
 
-		app := newTestApplication(mockEventSvc, &mockUserService{})
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
 
 		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
 		rr := httptest.NewRecorder()
@@ -133,10 +280,299 @@ func TestHome(t *testing.T) {
 	})
 }
 
+func TestEventListing(t *testing.T) {
+	t.Run("lists the requested year with tabs from the distinct years", func(t *testing.T) {
+		var gotYear int32
+		var gotIncludePast bool
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListYearsFunc: func(ctx context.Context) ([]int32, error) {
+				return []int32{2027, 2026, 2025}, nil
+			},
+			ListEventsByYearFunc: func(ctx context.Context, year int32, includePast bool) ([]db.Event, error) {
+				gotYear, gotIncludePast = year, includePast
+				return []db.Event{{ID: 1, Name: "Spring 10K", Slug: "spring-10k", Year: year}}, nil
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events?year=2025", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.eventListing(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if gotYear != 2025 || gotIncludePast {
+			t.Errorf("expected 2025 without past events, got %d including past %v", gotYear, gotIncludePast)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "Spring 10K") {
+			t.Error("expected event in response body")
+		}
+		for _, href := range []string{`href="/events?year=2027"`, `href="/events?year=2026"`, `href="/events?year=2025"`} {
+			if !strings.Contains(body, href) {
+				t.Errorf("expected year tab %s in response body", href)
+			}
+		}
+	})
+
+	t.Run("includes past events when asked", func(t *testing.T) {
+		var gotIncludePast bool
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListEventsByYearFunc: func(ctx context.Context, year int32, includePast bool) ([]db.Event, error) {
+				gotIncludePast = includePast
+				return nil, nil
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events?year=2026&include_past=1", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.eventListing(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if !gotIncludePast {
+			t.Error("expected past events to be included")
+		}
+	})
+
+	t.Run("opens on the latest year when the current year has no events", func(t *testing.T) {
+		var gotYear int32
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListYearsFunc: func(ctx context.Context) ([]int32, error) {
+				return []int32{1999, 1998}, nil
+			},
+			ListEventsByYearFunc: func(ctx context.Context, year int32, includePast bool) ([]db.Event, error) {
+				gotYear = year
+				return nil, nil
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.eventListing(rr, req)
+
+		if gotYear != 1999 {
+			t.Errorf("expected 1999, got %d", gotYear)
+		}
+	})
+
+	t.Run("renders only the results for htmx", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			ListYearsFunc: func(ctx context.Context) ([]int32, error) {
+				return []int32{2026, 2025}, nil
+			},
+			ListEventsByYearFunc: func(ctx context.Context, year int32, includePast bool) ([]db.Event, error) {
+				return []db.Event{{ID: 1, Name: "Spring 10K", Slug: "spring-10k", Year: year}}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+
+		get := func(headers map[string]string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/events?year=2025", http.NoBody)
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			app.eventListing(rr, req)
+			return rr
+		}
+
+		full := get(nil)
+		partial := get(map[string]string{"HX-Request": "true"})
+		restore := get(map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"})
+
+		for name, rr := range map[string]*httptest.ResponseRecorder{"full": full, "partial": partial, "restore": restore} {
+			if rr.Code != http.StatusOK {
+				t.Fatalf("%s: expected status %d, got %d", name, http.StatusOK, rr.Code)
+			}
+			if got := rr.Header().Get("Vary"); got != "HX-Request" {
+				t.Errorf("%s: expected Vary: HX-Request, got %q", name, got)
+			}
+			if !strings.Contains(rr.Body.String(), "Spring 10K") || !strings.Contains(rr.Body.String(), `id="event-results"`) {
+				t.Errorf("%s: expected the results in the response body", name)
+			}
+		}
+		if !strings.Contains(full.Body.String(), "<html") || !strings.Contains(restore.Body.String(), "<html") {
+			t.Error("expected full pages outside htmx swaps")
+		}
+		body := partial.Body.String()
+		if strings.Contains(body, "<html") || !strings.HasPrefix(body, "<section") {
+			t.Errorf("expected only the results fragment, got:\n%s", body)
+		}
+		if got := partial.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+			t.Errorf("expected an HTML fragment, got Content-Type %q", got)
+		}
+		if !strings.Contains(body, `hx-get="/events?year=2026" hx-push-url="true"`) {
+			t.Error("expected the year tabs to fetch with htmx and keep the URL")
+		}
+	})
+
+	t.Run("filters by distance and shows it in the reader's unit", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			ListYearsFunc: func(ctx context.Context) ([]int32, error) {
+				return []int32{2026}, nil
+			},
+			ListEventsByYearFunc: func(ctx context.Context, year int32, includePast bool) ([]db.Event, error) {
+				return []db.Event{
+					{ID: 1, Name: "Spring 10K", Slug: "spring-10k", Year: year},
+					{ID: 2, Name: "City Marathon", Slug: "city-marathon", Year: year},
+				}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			ListRacesByEventsFunc: func(ctx context.Context, eventIDs []int64) (map[int64][]db.Race, error) {
+				return map[int64][]db.Race{
+					1: {{ID: 10, EventID: 1, MaxCapacity: 100, DistanceMetres: pgtype.Int4{Int32: 10000, Valid: true}}},
+					2: {{ID: 20, EventID: 2, MaxCapacity: 100, DistanceMetres: pgtype.Int4{Int32: 42195, Valid: true}}},
+				}, nil
+			},
+		}
+
+		get := func(target string, user *db.User) string {
+			req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+			if user != nil {
+				req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, *user))
+			}
+			rr := httptest.NewRecorder()
+			app.eventListing(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("%s: expected status %d, got %d", target, http.StatusOK, rr.Code)
+			}
+			return rr.Body.String()
+		}
+
+		body := get("/events?year=2026&distance=marathon", nil)
+		if strings.Contains(body, "Spring 10K") || !strings.Contains(body, "City Marathon") {
+			t.Error("expected only the marathon listed")
+		}
+		if !strings.Contains(body, "26.2 miles") {
+			t.Error("expected the distance in miles for a visitor")
+		}
+		if !strings.Contains(body, `href="/events?distance=marathon&amp;include_past=1&amp;year=2026"`) {
+			t.Error("expected the past events toggle to keep the distance")
+		}
+
+		body = get("/events?year=2026", &db.User{ID: 5, DistanceUnit: db.DistanceUnitKm})
+		if !strings.Contains(body, "Spring 10K") || !strings.Contains(body, "10 km") || !strings.Contains(body, "42.2 km") {
+			t.Error("expected every event, with distances in kilometres")
+		}
+	})
+
+	t.Run("returns 400 for an unknown distance", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events?distance=50k", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.eventListing(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("returns 400 for an invalid year", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+		for _, year := range []string{"abc", "0", "-1"} {
+			req := httptest.NewRequest(http.MethodGet, "/events?year="+year, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			app.eventListing(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("year %q: expected status %d, got %d", year, http.StatusBadRequest, rr.Code)
+			}
+		}
+	})
+
+	t.Run("returns 500 on service error", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListYearsFunc: func(ctx context.Context) ([]int32, error) {
+				return nil, errors.New("database connection failed")
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.eventListing(rr, req)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+}
+
+func TestEventArchive(t *testing.T) {
+	t.Run("renders past events under their years", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListArchiveFunc: func(ctx context.Context) ([]service.EventYear, error) {
+				return []service.EventYear{
+					{Year: 2026, Events: []db.Event{{ID: 1, Name: "Closed 10K", Slug: "closed-10k"}}},
+					{Year: 2025, Events: []db.Event{{ID: 2, Name: "Old Marathon", Slug: "old-marathon"}}},
+				}, nil
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events/archive", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.eventArchive(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		i2026 := strings.Index(body, `data-archive-year="2026"`)
+		i2025 := strings.Index(body, `data-archive-year="2025"`)
+		if i2026 < 0 || i2025 < 0 || i2026 > i2025 {
+			t.Fatal("expected 2026 then 2025 in response body")
+		}
+		closed := strings.Index(body, "Closed 10K")
+		old := strings.Index(body, "Old Marathon")
+		if closed < i2026 || closed > i2025 || old < i2025 {
+			t.Error("expected each event under its own year")
+		}
+	})
+
+	t.Run("returns 500 on service error", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			ListArchiveFunc: func(ctx context.Context) ([]service.EventYear, error) {
+				return nil, errors.New("database connection failed")
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events/archive", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.eventArchive(rr, req)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+}
+
 func TestEventView(t *testing.T) {
 	t.Run("returns 200 for valid event", func(t *testing.T) {
-		mockEventSvc := &mockEventService{
-			getEventFunc: func(ctx context.Context, slug string) (db.Event, error) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
 				if slug == "test-event" {
 					return db.Event{
 						ID:   1,
@@ -148,95 +584,8292 @@ func TestEventView(t *testing.T) {
 			},
 		}
 
-		app := newTestApplication(mockEventSvc, &mockUserService{})
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
 
-		req := httptest.NewRequest(http.MethodGet, "/events/test-event", http.NoBody)
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
 		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
 		rr := httptest.NewRecorder()
 
-		app.eventView(rr, req)
+		withSession(app, app.eventView).ServeHTTP(rr, req)
 
 		if rr.Code != http.StatusOK {
 			t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
 		}
 	})
 
-	t.Run("returns 404 for non-existent event", func(t *testing.T) {
-		mockEventSvc := &mockEventService{
-			getEventFunc: func(ctx context.Context, slug string) (db.Event, error) {
-				return db.Event{}, repository.ErrNotFound
+	t.Run("links the gallery to each photo", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{ID: 1, Name: "Test Event", Slug: "test-event", Year: 2026}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.photoService = &servicemocks.PhotoServiceMock{
+			ListPhotosFunc: func(ctx context.Context, eventID int64) ([]db.EventPhoto, error) {
+				return []db.EventPhoto{{ID: 31, EventID: eventID}, {ID: 30, EventID: eventID}}, nil
 			},
 		}
 
-		app := newTestApplication(mockEventSvc, &mockUserService{})
-
-		req := httptest.NewRequest(http.MethodGet, "/events/non-existent", http.NoBody)
-		req.SetPathValue("slug", "non-existent")
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
 		rr := httptest.NewRecorder()
 
-		app.eventView(rr, req)
+		withSession(app, app.eventView).ServeHTTP(rr, req)
 
-		if rr.Code != http.StatusNotFound {
-			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		first := strings.Index(body, `src="/events/2026/test-event/photos/31"`)
+		second := strings.Index(body, `src="/events/2026/test-event/photos/30"`)
+		if !strings.Contains(body, "data-event-photos") || first < 0 || second < first {
+			t.Errorf("expected the photos in gallery order, got %s", body)
 		}
 	})
 
-	t.Run("returns 400 for empty slug", func(t *testing.T) {
-		mockEventSvc := &mockEventService{
-			getEventFunc: func(ctx context.Context, slug string) (db.Event, error) {
-				return db.Event{}, service.ErrInvalidInput
+	t.Run("describes the event to search engines", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{ID: 1, Name: "Test </script> Event", Slug: "test-event", Year: 2026, Timezone: "Europe/London"}, nil
 			},
-		}
-		app := newTestApplication(mockEventSvc, &mockUserService{})
+		}, &servicemocks.UserServiceMock{})
+		app.publicBaseURL = "https://firecrest.example"
 
-		req := httptest.NewRequest(http.MethodGet, "/events/", http.NoBody)
-		req.SetPathValue("slug", "")
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
 		rr := httptest.NewRecorder()
 
-		app.eventView(rr, req)
+		withSession(app, app.eventView).ServeHTTP(rr, req)
 
-		if rr.Code != http.StatusBadRequest {
-			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		body := rr.Body.String()
+		_, script, ok := strings.Cut(body, `<script type="application/ld+json">`)
+		script, _, _ = strings.Cut(script, "</script>")
+		var doc struct {
+			Type string `json:"@type"`
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		}
+		if !ok || json.Unmarshal([]byte(script), &doc) != nil {
+			t.Fatalf("expected a JSON-LD script in the page, got %s", body)
+		}
+		if doc.Type != "SportsEvent" || doc.Name != "Test </script> Event" || doc.URL != "https://firecrest.example/events/2026/test-event" {
+			t.Errorf("unexpected structured data %+v", doc)
 		}
 	})
 
-	t.Run("returns 400 for slug exceeding 100 characters", func(t *testing.T) {
-		mockEventSvc := &mockEventService{
-			getEventFunc: func(ctx context.Context, slug string) (db.Event, error) {
-				return db.Event{}, service.ErrInvalidInput
+	t.Run("brands the page with the organisation's colour and images", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{ID: 1, OrganisationID: 7, Name: "Test Event", Slug: "test-event", Year: 2026}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			GetOrganisationFunc: func(ctx context.Context, id int64) (db.Organisation, error) {
+				return db.Organisation{
+					ID:          id,
+					Name:        "Peak Running Co",
+					BrandColour: pgtype.Text{String: "#1a2b3c", Valid: true},
+					LogoKey:     pgtype.Text{String: "organisations/7/logo-a.png", Valid: true},
+					BannerKey:   pgtype.Text{String: "organisations/7/banner-a.jpg", Valid: true},
+				}, nil
 			},
 		}
-		app := newTestApplication(mockEventSvc, &mockUserService{})
-		longSlug := strings.Repeat("a", 101)
 
-		req := httptest.NewRequest(http.MethodGet, "/events/"+longSlug, http.NoBody)
-		req.SetPathValue("slug", longSlug)
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
 		rr := httptest.NewRecorder()
 
-		app.eventView(rr, req)
+		withSession(app, app.eventView).ServeHTTP(rr, req)
 
-		if rr.Code != http.StatusBadRequest {
-			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			`style="--color-primary: #1a2b3c; --color-primary-foreground: #ffffff;"`,
+			`src="/organisations/7/logo"`,
+			`src="/organisations/7/banner"`,
+			"Peak Running Co",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected page to contain %q", want)
+			}
 		}
 	})
 
-	t.Run("returns 500 on service error", func(t *testing.T) {
-		mockEventSvc := &mockEventService{
-			getEventFunc: func(ctx context.Context, slug string) (db.Event, error) {
-				return db.Event{}, errors.New("database connection failed")
+	t.Run("keeps the site's look without branding", func(t *testing.T) {
+		for name, org := range map[string]db.Organisation{
+			"unset": {ID: 7, Name: "Peak Running Co"},
+			// Saved before the colour was checked, or written by hand
+			"malformed": {ID: 7, Name: "Peak Running Co", BrandColour: pgtype.Text{String: "red; background:url(https://evil.example/x.png)", Valid: true}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				app := newTestApplication(&servicemocks.EventServiceMock{
+					GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+						return db.Event{ID: 1, OrganisationID: 7, Name: "Test Event", Slug: "test-event", Year: 2026}, nil
+					},
+				}, &servicemocks.UserServiceMock{})
+				app.organisationService = &servicemocks.OrganisationServiceMock{
+					GetOrganisationFunc: func(ctx context.Context, id int64) (db.Organisation, error) {
+						return org, nil
+					},
+				}
+
+				req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+				req.SetPathValue("slug", "test-event")
+				req.SetPathValue("year", "2026")
+				rr := httptest.NewRecorder()
+
+				withSession(app, app.eventView).ServeHTTP(rr, req)
+
+				if rr.Code != http.StatusOK {
+					t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+				}
+				body := rr.Body.String()
+				for _, unwanted := range []string{"--color-primary", "evil.example", "data-brand-logo", "data-brand-banner"} {
+					if strings.Contains(body, unwanted) {
+						t.Errorf("expected page not to contain %q", unwanted)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("lists previous editions newest first with their results", func(t *testing.T) {
+		series := pgtype.Int8{Int64: 1, Valid: true}
+		var gotSeries int64
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{ID: 3, Name: "Snowdonia Marathon", Slug: "snowdonia", Year: 2027, SeriesID: series}, nil
+			},
+			ListEventsInSeriesFunc: func(ctx context.Context, seriesID int64) ([]db.ListEventsInSeriesRow, error) {
+				gotSeries = seriesID
+				return []db.ListEventsInSeriesRow{
+					{Event: db.Event{ID: 3, Name: "Snowdonia Marathon", Slug: "snowdonia", Year: 2027}},
+					{Event: db.Event{ID: 2, Name: "Snowdonia Marathon", Slug: "snowdonia", Year: 2026}, ResultRaceNames: []string{"Marathon"}, ResultRaceSlugs: []string{"marathon"}},
+					{Event: db.Event{ID: 1, Name: "Snowdonia Marathon", Slug: "snowdonia-marathon", Year: 2025}},
+				}, nil
 			},
+		}, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2027/snowdonia", http.NoBody)
+		req.SetPathValue("slug", "snowdonia")
+		req.SetPathValue("year", "2027")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.eventView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if gotSeries != 1 {
+			t.Errorf("expected series 1 to be listed, got %d", gotSeries)
+		}
+		body := rr.Body.String()
+		if strings.Contains(body, `data-edition="2027"`) {
+			t.Error("expected the edition shown not to be listed as a previous one")
+		}
+		newer := strings.Index(body, `href="/events/2026/snowdonia"`)
+		older := strings.Index(body, `href="/events/2025/snowdonia-marathon"`)
+		if !strings.Contains(body, "data-previous-editions") || newer < 0 || older < newer {
+			t.Errorf("expected 2026 then 2025, got %s", body)
+		}
+		if !strings.Contains(body, `href="/events/2026/snowdonia/results/marathon"`) {
+			t.Error("expected a link to the 2026 marathon's results")
+		}
+	})
+
+	t.Run("renders a registration button for each race state", func(t *testing.T) {
+		now := time.Date(2026, time.May, 1, 12, 0, 0, 0, time.UTC)
+		window := func(opens, closes time.Time) (pgtype.Timestamptz, pgtype.Timestamptz) {
+			return pgtype.Timestamptz{Time: opens, Valid: true}, pgtype.Timestamptz{Time: closes, Valid: true}
+		}
+		race := func(slug string, capacity int32, opens, closes time.Time) db.Race {
+			o, c := window(opens, closes)
+			return db.Race{Slug: slug, Name: strings.ToUpper(slug), MaxCapacity: capacity, RegistrationOpenDate: o, RegistrationCloseDate: c}
 		}
 
-		app := newTestApplication(mockEventSvc, &mockUserService{})
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{ID: 1, Name: "Test Event", Slug: "test-event", Year: 2026}, nil
+			},
+		}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+		app.clock = fixedClock(now)
+		app.raceService = &servicemocks.RaceServiceMock{
+			ListRacesFunc: func(ctx context.Context, eventID int64) ([]service.RaceAvailability, error) {
+				if eventID != 1 {
+					t.Errorf("expected races for event 1, got %d", eventID)
+				}
+				return []service.RaceAvailability{
+					{Race: race("5k", 50, now.AddDate(0, -1, 0), now.AddDate(0, 1, 0)), Registered: 10, Route: &service.RouteStats{DistanceMetres: 5020, ElevationGainMetres: 1240}},
+					{Race: race("10k", 50, now.AddDate(0, 0, 7), now.AddDate(0, 1, 0))},
+					{Race: race("half", 50, now.AddDate(0, -1, 0), now.AddDate(0, 1, 0)), Registered: 50},
+					{Race: race("full", 50, now.AddDate(0, -2, 0), now)},
+				}, nil
+			},
+		}
 
-		req := httptest.NewRequest(http.MethodGet, "/events/test-event", http.NoBody)
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
 		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
 		rr := httptest.NewRecorder()
 
-		app.eventView(rr, req)
+		withSession(app, app.eventView).ServeHTTP(rr, req)
 
-		if rr.Code != http.StatusInternalServerError {
-			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			`data-race="5k" data-race-state="open"`,
+			`data-race-register="5k"`,
+			`action="/events/2026/test-event/races/5k/register"`,
+			`data-race="10k" data-race-state="not-yet-open"`,
+			"Opens on 8 May 2026",
+			`data-race="half" data-race-state="sold-out"`,
+			"Sold out",
+			`data-race="full" data-race-state="closed"`,
+			"5.0 km",
+			"1,240 m climb",
+			`href="/events/2026/test-event/races/5k/route.gpx"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		if strings.Count(body, "data-race-register=") != 1 {
+			t.Errorf("expected only the open race to be registrable")
+		}
+		if strings.Count(body, "data-race-route") != 1 {
+			t.Errorf("expected only the race with a route to show one")
+		}
+	})
+
+	t.Run("returns 500 when races fail to load", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{ID: 1, Slug: "test-event"}, nil
+			},
+		}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			ListRacesFunc: func(ctx context.Context, eventID int64) ([]service.RaceAvailability, error) {
+				return nil, errors.New("db down")
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.eventView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+
+	t.Run("renders the 404 page for non-existent event", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{}, fmt.Errorf("failed to get event: %w", repository.ErrNotFound)
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/non-existent", http.NoBody)
+		req.SetPathValue("slug", "non-existent")
+		req.SetPathValue("year", "2026")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.eventView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), `data-error-page="404"`) {
+			t.Errorf("expected the not found page, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("renders the 404 page when the event goes while its races load", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{ID: 1, Slug: "test-event"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			ListRacesFunc: func(ctx context.Context, eventID int64) ([]service.RaceAvailability, error) {
+				return nil, repository.ErrNotFound
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.eventView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("returns 400 for empty slug", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{}, service.ErrInvalidInput
+			},
+		}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/", http.NoBody)
+		req.SetPathValue("slug", "")
+		req.SetPathValue("year", "2026")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.eventView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("returns 400 for slug exceeding 100 characters", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{}, service.ErrInvalidInput
+			},
+		}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+		longSlug := strings.Repeat("a", 101)
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/"+longSlug, http.NoBody)
+		req.SetPathValue("slug", longSlug)
+		req.SetPathValue("year", "2026")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.eventView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("returns 500 on service error", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{}, errors.New("database connection failed")
+			},
+		}
+
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.eventView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+
+	t.Run("answers a conditional GET with 304 until the event changes", func(t *testing.T) {
+		event := db.Event{ID: 1, Name: "Test Event", Slug: "test-event"}
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return event, nil
+			},
+		}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+		get := func(etag string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+			req.SetPathValue("slug", "test-event")
+			req.SetPathValue("year", "2026")
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			rr := httptest.NewRecorder()
+			withSession(app, app.eventView).ServeHTTP(rr, req)
+			return rr
+		}
+
+		etag := get("").Header().Get("ETag")
+		if etag == "" {
+			t.Fatal("expected an ETag")
+		}
+
+		rr := get(etag)
+		if rr.Code != http.StatusNotModified {
+			t.Fatalf("expected status %d, got %d", http.StatusNotModified, rr.Code)
+		}
+		if rr.Body.Len() != 0 {
+			t.Errorf("expected no body, got %d bytes", rr.Body.Len())
+		}
+
+		event.UpdatedAt = pgtype.Timestamptz{Time: time.Date(2026, time.May, 1, 9, 0, 0, 0, time.UTC), Valid: true}
+		if rr := get(etag); rr.Code != http.StatusOK {
+			t.Errorf("expected status %d after an edit, got %d", http.StatusOK, rr.Code)
+		}
+	})
+
+	t.Run("does not send an ETag while a flash is waiting", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{ID: 1, Name: "Test Event", Slug: "test-event", Year: 2026}, nil
+			},
+		}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
+		rr := httptest.NewRecorder()
+
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			app.addFlash(r, FlashSuccess, "You're registered")
+			app.eventView(w, r)
+		}).ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("ETag"); got != "" {
+			t.Errorf("expected no ETag, got %q", got)
+		}
+		if !strings.Contains(rr.Body.String(), "You&#39;re registered") {
+			t.Error("expected the flash in the response body")
+		}
+	})
+
+	t.Run("renders the description as sanitised markdown", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{
+					ID:          1,
+					Name:        "Test Event",
+					Slug:        "test-event",
+					Location:    "Castleton",
+					ImageUrl:    "https://example.com/hero.jpg",
+					Description: "A **hilly** route.\n\n<script>alert('xss')</script>\n\n<img src=x onerror=alert(1)> [map](javascript:alert(1))",
+				}, nil
+			},
+		}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/test-event", http.NoBody)
+		req.SetPathValue("slug", "test-event")
+		req.SetPathValue("year", "2026")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.eventView).ServeHTTP(rr, req)
+
+		body := rr.Body.String()
+		for _, want := range []string{"<strong>hilly</strong>", "Castleton", `src="https://example.com/hero.jpg"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		for _, banned := range []string{"alert('xss')", "onerror", "javascript:"} {
+			if strings.Contains(body, banned) {
+				t.Errorf("expected body not to contain %q", banned)
+			}
+		}
+	})
+
+	t.Run("returns 404 for a year that is not a number", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{}
+		app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/events/next/test-event", http.NoBody))
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if calls := mockEventSvc.GetEventCalls(); len(calls) != 0 {
+			t.Errorf("expected no lookup, got %+v", calls)
+		}
+	})
+}
+
+func TestLegacyEventURLs(t *testing.T) {
+	// half-marathon is run by two organisations, so its old URL could mean
+	// either of their events
+	mockEventSvc := &servicemocks.EventServiceMock{
+		FindEventBySlugFunc: func(ctx context.Context, slug string) (db.Event, error) {
+			if slug != "lincoln-10k" {
+				return db.Event{}, repository.ErrNotFound
+			}
+			return db.Event{ID: 4, Slug: slug, Year: 2027}, nil
+		},
+	}
+	app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{name: "redirects an event page to its latest edition", path: "/events/lincoln-10k", wantStatus: http.StatusMovedPermanently, wantLocation: "/events/2027/lincoln-10k"},
+		{name: "redirects a results page keeping its query", path: "/events/lincoln-10k/results/10k?sort=time&order=desc", wantStatus: http.StatusMovedPermanently, wantLocation: "/events/2027/lincoln-10k/results/10k?sort=time&order=desc"},
+		{name: "returns 404 for a slug more than one organisation uses", path: "/events/half-marathon", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if got := rr.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("expected a redirect to %q, got %q", tt.wantLocation, got)
+			}
+		})
+	}
+}
+
+func TestAdminDashboard(t *testing.T) {
+	organiser := db.User{ID: 5, Role: db.UserRoleOrganizer}
+	orgs := []db.Organisation{{ID: 7, Name: "Peak Running Co"}, {ID: 9, Name: "Lakes Events"}}
+	fixture := []db.GetEventStatsRow{
+		{
+			ID:                  1,
+			Name:                "Peak District Ultra",
+			Slug:                "peak-district-ultra",
+			Year:                2026,
+			Status:              db.EventStatusPublished,
+			Registrations:       150,
+			Capacity:            200,
+			RecentRegistrations: 12,
+			RevenueCurrencies:   []string{"EUR", "GBP"},
+			RevenueUnits:        []int64{45000, 650000},
+		},
+		{
+			ID:                3,
+			Name:              "Winter Fell Race",
+			Slug:              "winter-fell-race",
+			Year:              2026,
+			Status:            db.EventStatusDraft,
+			Capacity:          80,
+			RevenueCurrencies: []string{"GBP"},
+			RevenueUnits:      []int64{0},
+		},
+	}
+
+	newDashboardApp := func() (*application, *int64) {
+		var gotOrgID int64
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventStatsFunc: func(ctx context.Context, organisationID int64) ([]db.GetEventStatsRow, error) {
+				gotOrgID = organisationID
+				return fixture, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			ListOrganisationsForUserFunc: func(ctx context.Context, userID int64) ([]db.Organisation, error) {
+				return orgs, nil
+			},
+		}
+		return app, &gotOrgID
+	}
+
+	serve := func(app *application, target string, user db.User) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, user))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminDashboard).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("renders aggregates for the first organisation", func(t *testing.T) {
+		app, gotOrgID := newDashboardApp()
+
+		rr := serve(app, "/admin/dashboard", organiser)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if *gotOrgID != 7 {
+			t.Errorf("expected stats for organisation 7, got %d", *gotOrgID)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			"Peak District Ultra",
+			"150/200 (75%)",
+			"£6,500.00",
+			"€450.00",
+			"Winter Fell Race",
+			"0/80 (0%)",
+			"£0.00",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected dashboard to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("badges each event with its status", func(t *testing.T) {
+		app, _ := newDashboardApp()
+
+		body := serve(app, "/admin/dashboard", organiser).Body.String()
+
+		for _, want := range []string{
+			"Published",
+			"Draft",
+			`action="/admin/events/1/archive"`,
+			`action="/admin/events/3/publish"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected dashboard to contain %q", want)
+			}
+		}
+		if strings.Contains(body, `action="/admin/events/1/publish"`) {
+			t.Error("expected no publish button for a published event")
+		}
+	})
+
+	t.Run("shows the selected organisation", func(t *testing.T) {
+		app, gotOrgID := newDashboardApp()
+
+		rr := serve(app, "/admin/dashboard?organisation=9", organiser)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if *gotOrgID != 9 {
+			t.Errorf("expected stats for organisation 9, got %d", *gotOrgID)
+		}
+	})
+
+	t.Run("returns 404 for an organisation the user does not belong to", func(t *testing.T) {
+		app, _ := newDashboardApp()
+
+		rr := serve(app, "/admin/dashboard?organisation=11", organiser)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("returns 400 for a malformed organisation id", func(t *testing.T) {
+		app, _ := newDashboardApp()
+
+		rr := serve(app, "/admin/dashboard?organisation=abc", organiser)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("lets admins view every organisation", func(t *testing.T) {
+		app, gotOrgID := newDashboardApp()
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			ListOrganisationsFunc: func(ctx context.Context) ([]db.Organisation, error) {
+				return []db.Organisation{{ID: 11, Name: "Welsh Mountain Events"}}, nil
+			},
+		}
+
+		rr := serve(app, "/admin/dashboard?organisation=11", db.User{ID: 1, Role: db.UserRoleAdmin})
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if *gotOrgID != 11 {
+			t.Errorf("expected stats for organisation 11, got %d", *gotOrgID)
+		}
+	})
+
+	t.Run("shows members how they hear about new entries", func(t *testing.T) {
+		app, _ := newDashboardApp()
+		app.organisationService.(*servicemocks.OrganisationServiceMock).GetMembershipFunc = func(ctx context.Context, userID, organisationID int64) (db.OrganisationMember, error) {
+			return db.OrganisationMember{NotificationPreference: db.NotificationPreferenceDailyDigest}, nil
+		}
+
+		body := serve(app, "/admin/dashboard", organiser).Body.String()
+
+		if !strings.Contains(body, `action="/admin/organisations/7/notifications"`) {
+			t.Error("expected a notification form for the organisation")
+		}
+		if !strings.Contains(body, `<option value="daily_digest" selected>`) {
+			t.Errorf("expected the daily digest selected, got:\n%s", body)
+		}
+	})
+
+	t.Run("leaves out notifications for admins who are not members", func(t *testing.T) {
+		app, _ := newDashboardApp()
+		app.organisationService.(*servicemocks.OrganisationServiceMock).GetMembershipFunc = func(ctx context.Context, userID, organisationID int64) (db.OrganisationMember, error) {
+			return db.OrganisationMember{}, repository.ErrNotFound
+		}
+
+		rr := serve(app, "/admin/dashboard", organiser)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if strings.Contains(rr.Body.String(), "data-notifications-form") {
+			t.Error("expected no notification form")
+		}
+	})
+
+	t.Run("renders an empty state without organisations", func(t *testing.T) {
+		app, gotOrgID := newDashboardApp()
+		app.organisationService = &servicemocks.OrganisationServiceMock{}
+
+		rr := serve(app, "/admin/dashboard", organiser)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if *gotOrgID != 0 {
+			t.Error("expected no stats to be loaded")
+		}
+		if !strings.Contains(rr.Body.String(), "not a member of any organisation") {
+			t.Error("expected empty state message")
+		}
+	})
+
+	t.Run("returns 500 when stats cannot be loaded", func(t *testing.T) {
+		app, _ := newDashboardApp()
+		app.eventService = &servicemocks.EventServiceMock{
+			GetEventStatsFunc: func(ctx context.Context, organisationID int64) ([]db.GetEventStatsRow, error) {
+				return nil, errors.New("database connection failed")
+			},
+		}
+
+		rr := serve(app, "/admin/dashboard", organiser)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+	t.Run("defaults to the organisation last used", func(t *testing.T) {
+		app, gotOrgID := newDashboardApp()
+		app.organisationService.(*servicemocks.OrganisationServiceMock).LastUsedOrganisationFunc = func(ctx context.Context, userID int64) (int64, error) {
+			return 9, nil
+		}
+
+		rr := serve(app, "/admin/dashboard", organiser)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if *gotOrgID != 9 {
+			t.Errorf("expected stats for organisation 9, got %d", *gotOrgID)
+		}
+	})
+}
+
+func TestOrganisationSwitcher(t *testing.T) {
+	organiser := db.User{ID: 5, Role: db.UserRoleOrganizer}
+	// Events 4 and 6 belong to the organiser's organisations; event 8 to
+	// one they are not a member of
+	eventOrgs := map[int64]int64{4: 7, 6: 9, 8: 11}
+
+	var gotOrgID int64
+	app := newTestApplication(&servicemocks.EventServiceMock{
+		GetEventStatsFunc: func(ctx context.Context, organisationID int64) ([]db.GetEventStatsRow, error) {
+			gotOrgID = organisationID
+			return nil, nil
+		},
+		GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+			return db.Event{ID: id, OrganisationID: eventOrgs[id], Name: "Harbour 10K", Timezone: "Europe/London"}, nil
+		},
+	}, &servicemocks.UserServiceMock{
+		GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+			return organiser, nil
+		},
+	})
+	orgService := &servicemocks.OrganisationServiceMock{
+		ListOrganisationsForUserFunc: func(ctx context.Context, userID int64) ([]db.Organisation, error) {
+			return []db.Organisation{{ID: 7, Name: "Peak Running Co"}, {ID: 9, Name: "Lakes Events"}}, nil
+		},
+		CanManageEventFunc: func(ctx context.Context, userID, eventID int64) (bool, error) {
+			return eventOrgs[eventID] != 11, nil
+		},
+		LastUsedOrganisationFunc: func(ctx context.Context, userID int64) (int64, error) {
+			return 0, repository.ErrNotFound
+		},
+	}
+	app.organisationService = orgService
+	routes := app.routes()
+	cookie := signedIn(t, app, httptest.NewRequest(http.MethodGet, "/", http.NoBody), organiser).Cookies()[0]
+
+	serve := func(method, target string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+	// dashboardOrg returns the organisation the dashboard now shows
+	dashboardOrg := func(t *testing.T) int64 {
+		t.Helper()
+		rr := serve(http.MethodGet, "/admin/dashboard", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		return gotOrgID
+	}
+
+	t.Run("lists the user's organisations in the header", func(t *testing.T) {
+		if got := dashboardOrg(t); got != 7 {
+			t.Fatalf("expected organisation 7 by default, got %d", got)
+		}
+
+		body := serve(http.MethodGet, "/admin/dashboard", nil).Body.String()
+		for _, want := range []string{
+			`action="/admin/organisations/switch"`,
+			`<option value="7" selected>Peak Running Co</option>`,
+			`<option value="9">Lakes Events</option>`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected header to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("keeps the organisation switched to across requests", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/admin/organisations/switch", url.Values{"organisation_id": {"9"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/dashboard" {
+			t.Fatalf("expected a redirect to the dashboard, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if got := dashboardOrg(t); got != 9 {
+			t.Errorf("expected organisation 9 after switching, got %d", got)
+		}
+		calls := orgService.UseOrganisationCalls()
+		if len(calls) == 0 || calls[len(calls)-1].UserID != organiser.ID || calls[len(calls)-1].OrganisationID != 9 {
+			t.Errorf("expected organisation 9 remembered as last used, got %+v", calls)
+		}
+	})
+
+	t.Run("switches on following a link into another organisation", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/admin/events/4/enquiries", nil)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), `<option value="7" selected>`) {
+			t.Error("expected the header to show the organisation switched to")
+		}
+		if got := dashboardOrg(t); got != 7 {
+			t.Errorf("expected organisation 7 after the link, got %d", got)
+		}
+	})
+
+	t.Run("returns 404 for another organisation's events", func(t *testing.T) {
+		serve(http.MethodPost, "/admin/organisations/switch", url.Values{"organisation_id": {"9"}})
+
+		rr := serve(http.MethodGet, "/admin/events/8/enquiries", nil)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if got := dashboardOrg(t); got != 9 {
+			t.Errorf("expected organisation 9 kept, got %d", got)
+		}
+	})
+
+	t.Run("returns 404 for switching to another organisation", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/admin/organisations/switch", url.Values{"organisation_id": {"11"}})
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("returns 400 for a malformed organisation id", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/admin/organisations/switch", url.Values{"organisation_id": {"abc"}})
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
+
+func TestAdminNotificationsPost(t *testing.T) {
+	organiser := db.User{ID: 5, Role: db.UserRoleOrganizer}
+
+	serve := func(app *application, form url.Values, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/organisations/"+id+"/notifications", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminNotificationsPost).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("saves the member's preference", func(t *testing.T) {
+		var gotUser, gotOrg int64
+		var gotPref db.NotificationPreference
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			SetNotificationPreferenceFunc: func(ctx context.Context, userID, organisationID int64, pref db.NotificationPreference) error {
+				gotUser, gotOrg, gotPref = userID, organisationID, pref
+				return nil
+			},
+		}
+
+		rr := serve(app, url.Values{"notifications": {"immediate"}}, "7")
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/dashboard?organisation=7" {
+			t.Fatalf("expected a redirect to the dashboard, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if gotUser != organiser.ID || gotOrg != 7 || gotPref != db.NotificationPreferenceImmediate {
+			t.Errorf("expected immediate for user %d in organisation 7, got %q for user %d in %d", organiser.ID, gotPref, gotUser, gotOrg)
+		}
+	})
+
+	t.Run("returns 404 for an organisation the user does not belong to", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			SetNotificationPreferenceFunc: func(ctx context.Context, userID, organisationID int64, pref db.NotificationPreference) error {
+				return repository.ErrNotFound
+			},
+		}
+
+		if rr := serve(app, url.Values{"notifications": {"none"}}, "9"); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("returns 400 for an unknown preference", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			SetNotificationPreferenceFunc: func(ctx context.Context, userID, organisationID int64, pref db.NotificationPreference) error {
+				return service.ErrInvalidInput
+			},
+		}
+
+		if rr := serve(app, url.Values{"notifications": {"weekly"}}, "7"); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
+
+func TestAdminCreateView(t *testing.T) {
+	admin := db.User{ID: 1, Role: db.UserRoleAdmin}
+
+	t.Run("renders form with organisation options", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			ListOrganisationsFunc: func(ctx context.Context) ([]db.Organisation, error) {
+				return []db.Organisation{{ID: 7, Name: "Peak Running Co"}}, nil
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/events/new", http.NoBody)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, admin))
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.adminCreateView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Peak Running Co") {
+			t.Error("expected organisation option in form")
+		}
+		for _, field := range []string{`name="description"`, `name="location"`, `name="image_url"`, `data-char-count="description"`} {
+			if !strings.Contains(rr.Body.String(), field) {
+				t.Errorf("expected form to contain %s", field)
+			}
+		}
+	})
+
+	t.Run("returns 500 when organisations cannot be loaded", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			ListOrganisationsFunc: func(ctx context.Context) ([]db.Organisation, error) {
+				return nil, errors.New("database connection failed")
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/events/new", http.NoBody)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, admin))
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.adminCreateView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+
+	t.Run("offers organisers only their own organisations", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			ListOrganisationsFunc: func(ctx context.Context) ([]db.Organisation, error) {
+				return []db.Organisation{{ID: 7, Name: "Peak Running Co"}, {ID: 8, Name: "Other Club"}}, nil
+			},
+			ListOrganisationsForUserFunc: func(ctx context.Context, userID int64) ([]db.Organisation, error) {
+				return []db.Organisation{{ID: 7, Name: "Peak Running Co"}}, nil
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/events/new", http.NoBody)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, db.User{ID: 3, Role: db.UserRoleOrganizer}))
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.adminCreateView).ServeHTTP(rr, req)
+
+		if !strings.Contains(rr.Body.String(), "Peak Running Co") || strings.Contains(rr.Body.String(), "Other Club") {
+			t.Errorf("expected only the organiser's organisation to be offered, got:\n%s", rr.Body.String())
+		}
+	})
+}
+
+func TestAdminCreatePost(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	newFormRequest := func(form url.Values) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/events", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+	}
+	// The organiser is a member of organisation 1
+	newApp := func(eventSvc *servicemocks.EventServiceMock) *application {
+		app := newTestApplication(eventSvc, &servicemocks.UserServiceMock{})
+		app.organisationService = memberOrganisationService(1, nil)
+		return app
+	}
+
+	t.Run("creates a draft event and redirects to the dashboard", func(t *testing.T) {
+		var captured service.CreateEventInput
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				captured = input
+				return db.Event{ID: 1, Name: input.Name, Slug: input.Slug, Year: input.Year}, nil
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"1"},
+			"name":            {"Lincoln 10k"},
+			"year":            {"2026"},
+			"slug":            {"lincoln-10k"},
+		}))
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != "/admin/dashboard?organisation=1" {
+			t.Errorf("expected redirect to the organisation's dashboard, got %q", loc)
+		}
+		if captured.OrganisationID != 1 || captured.Year != 2026 {
+			t.Errorf("unexpected input passed to service: %+v", captured)
+		}
+	})
+
+	t.Run("generates slug from name when blank", func(t *testing.T) {
+		var captured service.CreateEventInput
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				captured = input
+				return db.Event{ID: 1, Slug: input.Slug}, nil
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"1"},
+			"name":            {"Ras Yr Wyddfa Café"},
+			"year":            {"2026"},
+		}))
+
+		if captured.Slug != "ras-yr-wyddfa-cafe" {
+			t.Errorf("expected generated slug %q, got %q", "ras-yr-wyddfa-cafe", captured.Slug)
+		}
+	})
+
+	t.Run("re-renders with inline error for non-numeric year", func(t *testing.T) {
+		called := false
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				called = true
+				return db.Event{}, nil
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"1"},
+			"name":            {"Lincoln 10k"},
+			"year":            {"next year"},
+		}))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if called {
+			t.Error("expected service not to be called")
+		}
+		if !strings.Contains(rr.Body.String(), "Year must be a number") {
+			t.Error("expected year error in response body")
+		}
+	})
+
+	t.Run("re-renders with suggested slug for malformed slug", func(t *testing.T) {
+		called := false
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				called = true
+				return db.Event{}, nil
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"1"},
+			"name":            {"Lincoln 10k"},
+			"year":            {"2026"},
+			"slug":            {"Lincoln 10K/2026"},
+		}))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if called {
+			t.Error("expected service not to be called")
+		}
+		if !strings.Contains(rr.Body.String(), "Try lincoln-10k-2026 instead") {
+			t.Error("expected suggested slug in response body")
+		}
+	})
+
+	t.Run("re-renders with slug error when slug is taken", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				return db.Event{}, service.ErrSlugTaken
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"1"},
+			"name":            {"Lincoln 10k"},
+			"year":            {"2026"},
+			"slug":            {"lincoln-10k"},
+		}))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "Your organisation already uses the slug lincoln-10k for an event in 2026") {
+			t.Error("expected slug error in response body")
+		}
+		if !strings.Contains(body, `value="Lincoln 10k"`) {
+			t.Error("expected submitted name to be re-populated")
+		}
+	})
+
+	t.Run("re-renders with service validation error", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				return db.Event{}, fmt.Errorf("%w: year must be 2025 or later", service.ErrInvalidInput)
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"1"},
+			"name":            {"Lincoln 10k"},
+			"year":            {"2020"},
+		}))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "year must be 2025 or later") {
+			t.Error("expected validation error in response body")
+		}
+	})
+
+	t.Run("re-renders with the service's field errors beside their fields", func(t *testing.T) {
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				return db.Event{}, service.FieldErrors{"year": "year must be 2025 or later"}
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"1"},
+			"name":            {"Lincoln 10k"},
+			"year":            {"2020"},
+		}))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Year must be 2025 or later") {
+			t.Error("expected the year error in response body")
+		}
+	})
+
+	t.Run("passes description, location and image to the service", func(t *testing.T) {
+		var captured service.CreateEventInput
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				captured = input
+				return db.Event{ID: 1, Slug: input.Slug}, nil
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"1"},
+			"name":            {"Lincoln 10k"},
+			"year":            {"2026"},
+			"description":     {"  A *flat* course  "},
+			"location":        {"Lincoln"},
+			"image_url":       {"https://example.com/hero.jpg"},
+		}))
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if captured.Description != "A *flat* course" || captured.Location != "Lincoln" || captured.ImageURL != "https://example.com/hero.jpg" {
+			t.Errorf("unexpected input passed to service: %+v", captured)
+		}
+	})
+
+	t.Run("rejects organisations the organiser cannot create events for", func(t *testing.T) {
+		called := false
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				called = true
+				return db.Event{}, nil
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"2"},
+			"name":            {"Lincoln 10k"},
+			"year":            {"2026"},
+			"slug":            {"lincoln-10k"},
+		}))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "You cannot create events for this organisation") {
+			t.Error("expected organisation error in response body")
+		}
+		if called {
+			t.Error("expected service not to be called")
+		}
+	})
+
+	t.Run("re-renders with inline errors for invalid details", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			field   string
+			value   string
+			wantErr string
+		}{
+			{name: "long description", field: "description", value: strings.Repeat("a", service.MaxEventDescriptionLength+1), wantErr: "Description must be at most 10000 characters"},
+			{name: "long location", field: "location", value: strings.Repeat("a", service.MaxEventLocationLength+1), wantErr: "Location must be at most 200 characters"},
+			{name: "http image", field: "image_url", value: "http://example.com/hero.jpg", wantErr: "Image URL must start with https://"},
+			{name: "javascript image", field: "image_url", value: "javascript:alert(1)", wantErr: "Image URL must start with https://"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				called := false
+				mockEventSvc := &servicemocks.EventServiceMock{
+					CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+						called = true
+						return db.Event{}, nil
+					},
+				}
+				app := newApp(mockEventSvc)
+
+				rr := httptest.NewRecorder()
+				withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+					"organisation_id": {"1"},
+					"name":            {"Lincoln 10k"},
+					"year":            {"2026"},
+					tt.field:          {tt.value},
+				}))
+
+				if rr.Code != http.StatusUnprocessableEntity {
+					t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+				}
+				if called {
+					t.Error("expected service not to be called")
+				}
+				if !strings.Contains(rr.Body.String(), tt.wantErr) {
+					t.Errorf("expected %q in response body", tt.wantErr)
+				}
+			})
+		}
+	})
+
+	t.Run("passes the time zone to the service and shows its error", func(t *testing.T) {
+		var captured service.CreateEventInput
+		mockEventSvc := &servicemocks.EventServiceMock{
+			CreateEventFunc: func(ctx context.Context, input service.CreateEventInput) (db.Event, error) {
+				captured = input
+				return db.Event{}, service.FieldErrors{"timezone": "timezone must be an IANA time zone such as Europe/London"}
+			},
+		}
+		app := newApp(mockEventSvc)
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCreatePost).ServeHTTP(rr, newFormRequest(url.Values{
+			"organisation_id": {"1"},
+			"name":            {"Lincoln 10k"},
+			"year":            {"2026"},
+			"timezone":        {" Europe/Lincoln "},
+		}))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if captured.Timezone != "Europe/Lincoln" {
+			t.Errorf("expected the trimmed time zone to be passed on, got %q", captured.Timezone)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "IANA time zone") || !strings.Contains(body, `value="Europe/Lincoln"`) {
+			t.Errorf("expected the time zone error and value to be shown, got:\n%s", body)
+		}
+	})
+}
+
+func TestAdminDuplicateEvent(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	source := db.Event{ID: 4, OrganisationID: 7, Name: "Lincoln 10k", Slug: "lincoln-10k", Year: 2026}
+
+	newApp := func(eventSvc *servicemocks.EventServiceMock) *application {
+		eventSvc.GetEventByIDFunc = func(ctx context.Context, id int64) (db.Event, error) {
+			if id != source.ID {
+				return db.Event{}, repository.ErrNotFound
+			}
+			return source, nil
+		}
+		app := newTestApplication(eventSvc, &servicemocks.UserServiceMock{})
+		app.organisationService = memberOrganisationService(7, map[int64]int64{source.ID: source.OrganisationID})
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, method, id string, form url.Values, user db.User) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/events/"+id+"/duplicate", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, user))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("renders confirmation form defaulting to next year", func(t *testing.T) {
+		app := newApp(&servicemocks.EventServiceMock{})
+
+		rr := serve(app, app.adminDuplicateView, http.MethodGet, "4", nil, organiser)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, `action="/admin/events/4/duplicate"`) {
+			t.Error("expected form to post to the duplicate URL")
+		}
+		if !strings.Contains(body, `value="2027"`) {
+			t.Error("expected year to default to 2027")
+		}
+	})
+
+	t.Run("duplicates event and redirects to the dashboard", func(t *testing.T) {
+		var gotID int64
+		var gotYear int32
+		app := newApp(&servicemocks.EventServiceMock{
+			DuplicateEventFunc: func(ctx context.Context, eventID int64, newYear int32) (db.Event, error) {
+				gotID, gotYear = eventID, newYear
+				return db.Event{ID: 5, Slug: "lincoln-10k", Year: newYear}, nil
+			},
+		})
+
+		rr := serve(app, app.adminDuplicatePost, http.MethodPost, "4", url.Values{"year": {"2027"}}, organiser)
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != "/admin/dashboard?organisation=7" {
+			t.Errorf("expected redirect to the organisation's dashboard, got %q", loc)
+		}
+		if gotID != 4 || gotYear != 2027 {
+			t.Errorf("expected event 4 duplicated into 2027, got %d into %d", gotID, gotYear)
+		}
+	})
+
+	t.Run("re-renders with error when the year already exists", func(t *testing.T) {
+		app := newApp(&servicemocks.EventServiceMock{
+			DuplicateEventFunc: func(ctx context.Context, eventID int64, newYear int32) (db.Event, error) {
+				return db.Event{}, repository.ErrConflict
+			},
+		})
+
+		rr := serve(app, app.adminDuplicatePost, http.MethodPost, "4", url.Values{"year": {"2027"}}, organiser)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Lincoln 10k already has an event in 2027") {
+			t.Error("expected conflict error in response body")
+		}
+	})
+
+	t.Run("re-renders with error for non-numeric year", func(t *testing.T) {
+		called := false
+		app := newApp(&servicemocks.EventServiceMock{
+			DuplicateEventFunc: func(ctx context.Context, eventID int64, newYear int32) (db.Event, error) {
+				called = true
+				return db.Event{}, nil
+			},
+		})
+
+		rr := serve(app, app.adminDuplicatePost, http.MethodPost, "4", url.Values{"year": {"next"}}, organiser)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if called {
+			t.Error("expected service not to be called")
+		}
+	})
+
+	t.Run("returns 404 for another organisation's event", func(t *testing.T) {
+		called := false
+		app := newApp(&servicemocks.EventServiceMock{
+			DuplicateEventFunc: func(ctx context.Context, eventID int64, newYear int32) (db.Event, error) {
+				called = true
+				return db.Event{}, nil
+			},
+		})
+		app.organisationService = memberOrganisationService(8, map[int64]int64{source.ID: source.OrganisationID})
+
+		rr := serve(app, app.adminDuplicatePost, http.MethodPost, "4", url.Values{"year": {"2027"}}, organiser)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if called {
+			t.Error("expected service not to be called")
+		}
+	})
+
+	t.Run("allows admins to duplicate any event", func(t *testing.T) {
+		app := newApp(&servicemocks.EventServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			CanManageEventFunc: func(ctx context.Context, userID, eventID int64) (bool, error) {
+				return userID == 1, nil
+			},
+		}
+
+		rr := serve(app, app.adminDuplicateView, http.MethodGet, "4", nil, db.User{ID: 1, Role: db.UserRoleAdmin})
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+	})
+
+	t.Run("returns 404 for unknown event", func(t *testing.T) {
+		app := newApp(&servicemocks.EventServiceMock{})
+
+		rr := serve(app, app.adminDuplicateView, http.MethodGet, "99", nil, organiser)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestAdminEventSeries(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	series := pgtype.Int8{Int64: 2, Valid: true}
+	event := db.Event{ID: 4, OrganisationID: 7, Name: "Lincoln 10k", Slug: "lincoln-10k", Year: 2026, SeriesID: series}
+	events := []db.Event{
+		{ID: 9, OrganisationID: 7, Name: "Boston 5k", Year: 2026},
+		{ID: 4, OrganisationID: 7, Name: "Lincoln 10k", Year: 2026, SeriesID: series},
+		{ID: 2, OrganisationID: 7, Name: "Lincoln 10k", Year: 2025, SeriesID: series},
+	}
+
+	newApp := func(eventSvc *servicemocks.EventServiceMock) *application {
+		eventSvc.GetEventByIDFunc = func(ctx context.Context, id int64) (db.Event, error) {
+			if id != event.ID {
+				return db.Event{}, repository.ErrNotFound
+			}
+			return event, nil
+		}
+		eventSvc.ListOrganisationEventsFunc = func(ctx context.Context, organisationID int64) ([]db.Event, error) {
+			return events, nil
+		}
+		app := newTestApplication(eventSvc, &servicemocks.UserServiceMock{})
+		app.organisationService = memberOrganisationService(7, map[int64]int64{event.ID: event.OrganisationID})
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, method string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/events/4/series", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", "4")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("lists the series and the events it could join", func(t *testing.T) {
+		app := newApp(&servicemocks.EventServiceMock{})
+
+		rr := serve(app, app.adminEventSeriesView, http.MethodGet, nil)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, `data-series-edition="2"`) {
+			t.Error("expected the 2025 edition to be listed in the series")
+		}
+		if !strings.Contains(body, `<option value="9">Boston 5k 2026</option>`) || strings.Contains(body, `<option value="2">`) {
+			t.Errorf("expected only events outside the series to be offered, got %s", body)
+		}
+		if !strings.Contains(body, "data-series-leave") {
+			t.Error("expected a way to leave the series")
+		}
+	})
+
+	t.Run("links the event and redirects to the dashboard", func(t *testing.T) {
+		var gotSeriesEvent int64 = -1
+		app := newApp(&servicemocks.EventServiceMock{
+			SetEventSeriesFunc: func(ctx context.Context, e db.Event, seriesEventID int64) error {
+				gotSeriesEvent = seriesEventID
+				return nil
+			},
+		})
+
+		rr := serve(app, app.adminEventSeriesPost, http.MethodPost, url.Values{"series_event_id": {"9"}})
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != "/admin/dashboard?organisation=7" {
+			t.Errorf("expected redirect to the organisation's dashboard, got %q", loc)
+		}
+		if gotSeriesEvent != 9 {
+			t.Errorf("expected the series of event 9, got %d", gotSeriesEvent)
+		}
+	})
+
+	t.Run("unlinks the event for zero", func(t *testing.T) {
+		var gotSeriesEvent int64 = -1
+		app := newApp(&servicemocks.EventServiceMock{
+			SetEventSeriesFunc: func(ctx context.Context, e db.Event, seriesEventID int64) error {
+				gotSeriesEvent = seriesEventID
+				return nil
+			},
+		})
+
+		rr := serve(app, app.adminEventSeriesPost, http.MethodPost, url.Values{"series_event_id": {"0"}})
+
+		if rr.Code != http.StatusSeeOther || gotSeriesEvent != 0 {
+			t.Errorf("expected the event to leave its series, got status %d and %d", rr.Code, gotSeriesEvent)
+		}
+	})
+
+	t.Run("re-renders with the service's field errors", func(t *testing.T) {
+		app := newApp(&servicemocks.EventServiceMock{
+			SetEventSeriesFunc: func(ctx context.Context, e db.Event, seriesEventID int64) error {
+				return service.FieldErrors{"series_event_id": "choose another of your events"}
+			},
+		})
+
+		rr := serve(app, app.adminEventSeriesPost, http.MethodPost, url.Values{"series_event_id": {"4"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Choose another of your events") {
+			t.Error("expected the field error in the response body")
+		}
+	})
+
+	t.Run("re-renders without an event chosen", func(t *testing.T) {
+		eventSvc := &servicemocks.EventServiceMock{}
+		app := newApp(eventSvc)
+
+		rr := serve(app, app.adminEventSeriesPost, http.MethodPost, url.Values{"series_event_id": {""}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if len(eventSvc.SetEventSeriesCalls()) != 0 {
+			t.Error("expected service not to be called")
+		}
+	})
+}
+
+func TestAdminRegistrationTimeseries(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+
+	newApp := func(memberOf int64) *application {
+		loc, _ := time.LoadLocation("Europe/London")
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Peak District Ultra"}, nil
+			},
+			RegistrationTimeseriesFunc: func(ctx context.Context, event db.Event, granularity service.Granularity) ([]service.RegistrationBucket, error) {
+				if granularity != service.GranularityDay && granularity != service.GranularityWeek {
+					return nil, service.ErrInvalidInput
+				}
+				return []service.RegistrationBucket{
+					{Date: time.Date(2026, time.January, 31, 0, 0, 0, 0, loc), Count: 2, Cumulative: 2},
+					{Date: time.Date(2026, time.February, 1, 0, 0, 0, 0, loc), Count: 0, Cumulative: 2},
+				}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.organisationService = memberOrganisationService(memberOf, map[int64]int64{4: 7})
+		return app
+	}
+	get := func(app *application, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/events/4/registrations/timeseries"+query, http.NoBody)
+		req.SetPathValue("id", "4")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminRegistrationTimeseries).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("answers with the buckets as JSON", func(t *testing.T) {
+		app := newApp(7)
+
+		rr := get(app, "?granularity=week")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("expected JSON, got %q", got)
+		}
+		want := `{"granularity":"week","buckets":[{"date":"2026-01-31","count":2,"cumulative":2},{"date":"2026-02-01","count":0,"cumulative":2}]}`
+		if got := strings.TrimSpace(rr.Body.String()); got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	})
+
+	t.Run("counts by day by default", func(t *testing.T) {
+		app := newApp(7)
+
+		if rr := get(app, ""); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"granularity":"day"`) {
+			t.Errorf("expected daily buckets, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("rejects unknown granularities", func(t *testing.T) {
+		app := newApp(7)
+
+		if rr := get(app, "?granularity=month"); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("returns 404 to other organisations", func(t *testing.T) {
+		app := newApp(8)
+
+		if rr := get(app, ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestAdminDiscountCodes(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	event := db.Event{ID: 4, OrganisationID: 7, Name: "Lincoln 10k", Slug: "lincoln-10k", Year: 2026}
+	race := db.Race{ID: 20, EventID: 4, Name: "10K", Slug: "10k", Currency: pgtype.Text{String: "GBP", Valid: true}}
+
+	newApp := func(discountSvc *servicemocks.DiscountServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				if id != event.ID {
+					return db.Event{}, repository.ErrNotFound
+				}
+				return event, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			ListRacesFunc: func(ctx context.Context, eventID int64) ([]service.RaceAvailability, error) {
+				return []service.RaceAvailability{{Race: race}}, nil
+			},
+		}
+		app.organisationService = memberOrganisationService(7, map[int64]int64{event.ID: 7, 9: 8})
+		app.discountService = discountSvc
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, method, id string, form url.Values) (*httptest.ResponseRecorder, string) {
+		req := httptest.NewRequest(method, "/admin/events/"+id+"/discounts", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		var flash string
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+			flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashSuccess)
+		}).ServeHTTP(rr, req)
+		return rr, flash
+	}
+
+	t.Run("lists the event's codes", func(t *testing.T) {
+		app := newApp(&servicemocks.DiscountServiceMock{
+			ListCodesFunc: func(ctx context.Context, eventID int64) ([]db.DiscountCode, error) {
+				return []db.DiscountCode{
+					{Code: "EARLY", PercentOff: pgtype.Int4{Int32: 10, Valid: true}, Uses: 3, MaxUses: pgtype.Int4{Int32: 10, Valid: true}},
+					{
+						Code:           "CLUB",
+						RaceID:         pgtype.Int8{Int64: race.ID, Valid: true},
+						AmountOffUnits: pgtype.Int4{Int32: 500, Valid: true},
+						ValidUntil:     pgtype.Timestamptz{Time: time.Date(2026, 5, 31, 23, 0, 0, 0, time.UTC), Valid: true},
+					},
+				}, nil
+			},
+		})
+
+		rr, _ := serve(app, app.adminDiscountCodesView, http.MethodGet, "4", nil)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			`data-discount-code="EARLY"`, "10% off", "All races", "3 of 10", "Any time",
+			`data-discount-code="CLUB"`, "£5.00 off", "10K", "Until 31 May 2026 23:00",
+			`action="/admin/events/4/discounts"`, `<option value="20">10K</option>`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("creates a code and returns to the list", func(t *testing.T) {
+		var got service.CreateDiscountCodeParams
+		app := newApp(&servicemocks.DiscountServiceMock{
+			CreateCodeFunc: func(ctx context.Context, params service.CreateDiscountCodeParams) (db.DiscountCode, error) {
+				got = params
+				return db.DiscountCode{Code: params.Code}, nil
+			},
+		})
+
+		rr, flash := serve(app, app.adminCreateDiscountCodePost, http.MethodPost, "4", url.Values{
+			"code":        {"Spring"},
+			"race_id":     {"20"},
+			"amount_off":  {"5.50"},
+			"max_uses":    {"10"},
+			"valid_from":  {"2026-05-01T09:00"},
+			"valid_until": {""},
+		})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/events/4/discounts" {
+			t.Fatalf("expected a redirect to the codes, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		want := service.CreateDiscountCodeParams{
+			EventID:        4,
+			RaceID:         20,
+			Code:           "Spring",
+			AmountOffUnits: 550,
+			MaxUses:        10,
+			ValidFrom:      time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC),
+		}
+		if got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+		if flash != "Discount code Spring created" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("shows problems with the form", func(t *testing.T) {
+		app := newApp(&servicemocks.DiscountServiceMock{
+			CreateCodeFunc: func(ctx context.Context, params service.CreateDiscountCodeParams) (db.DiscountCode, error) {
+				t.Error("expected no code to be created")
+				return db.DiscountCode{}, nil
+			},
+		})
+
+		rr, _ := serve(app, app.adminCreateDiscountCodePost, http.MethodPost, "4", url.Values{
+			"code":       {"Spring"},
+			"amount_off": {"five"},
+			"max_uses":   {"ten"},
+			"valid_from": {"tomorrow"},
+		})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			"Amount off must be an amount such as 5.00", "Maximum uses must be a number",
+			"Valid from must be a date and time", `value="Spring"`, `value="ten"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("shows the service's field errors", func(t *testing.T) {
+		app := newApp(&servicemocks.DiscountServiceMock{
+			CreateCodeFunc: func(ctx context.Context, params service.CreateDiscountCodeParams) (db.DiscountCode, error) {
+				return db.DiscountCode{}, service.FieldErrors{"code": "this event already has that code"}
+			},
+		})
+
+		rr, _ := serve(app, app.adminCreateDiscountCodePost, http.MethodPost, "4", url.Values{"code": {"EARLY"}, "percent_off": {"10"}})
+
+		if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), "This event already has that code") {
+			t.Errorf("expected the duplicate code to be reported, got %d", rr.Code)
+		}
+	})
+
+	t.Run("returns 404 for another organisation's event", func(t *testing.T) {
+		app := newApp(&servicemocks.DiscountServiceMock{})
+
+		rr, _ := serve(app, app.adminDiscountCodesView, http.MethodGet, "9", nil)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestAdminEventStatus(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	event := db.Event{ID: 4, OrganisationID: 7, Name: "Lincoln 10k", Slug: "lincoln-10k", Year: 2026, Status: db.EventStatusDraft}
+
+	newApp := func(eventSvc *servicemocks.EventServiceMock, memberOf int64) *application {
+		eventSvc.GetEventByIDFunc = func(ctx context.Context, id int64) (db.Event, error) {
+			if id != event.ID {
+				return db.Event{}, repository.ErrNotFound
+			}
+			return event, nil
+		}
+		app := newTestApplication(eventSvc, &servicemocks.UserServiceMock{})
+		app.organisationService = memberOrganisationService(memberOf, map[int64]int64{event.ID: event.OrganisationID})
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, action string) (*httptest.ResponseRecorder, string) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/events/4/"+action, http.NoBody)
+		req.SetPathValue("id", "4")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		var flash string
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+			flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashSuccess) + app.sessionManager.GetString(r.Context(), "flash_"+FlashError)
+		}).ServeHTTP(rr, req)
+		return rr, flash
+	}
+
+	t.Run("publishes the event and returns to the dashboard", func(t *testing.T) {
+		var published int64
+		app := newApp(&servicemocks.EventServiceMock{
+			PublishEventFunc: func(ctx context.Context, id int64) error {
+				published = id
+				return nil
+			},
+		}, 7)
+
+		rr, flash := serve(app, app.adminPublishEventPost, "publish")
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != "/admin/dashboard?organisation=7" {
+			t.Errorf("expected redirect to the organisation's dashboard, got %q", loc)
+		}
+		if published != event.ID {
+			t.Errorf("expected event %d published, got %d", event.ID, published)
+		}
+		if flash != "Lincoln 10k is published" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("explains why an event cannot be published", func(t *testing.T) {
+		tests := []struct {
+			err  error
+			want string
+		}{
+			{err: service.ErrEventHasNoRaces, want: "Add a race"},
+			{err: fmt.Errorf("%w: 10K", service.ErrNoRegistrationWindow), want: "registration open and close dates"},
+			{err: service.ErrEventURLTaken, want: "Another organisation has already published an event at /events/2026/lincoln-10k"},
+		}
+
+		for _, tt := range tests {
+			app := newApp(&servicemocks.EventServiceMock{
+				PublishEventFunc: func(ctx context.Context, id int64) error {
+					return tt.err
+				},
+			}, 7)
+
+			rr, flash := serve(app, app.adminPublishEventPost, "publish")
+
+			if rr.Code != http.StatusSeeOther {
+				t.Errorf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+			}
+			if !strings.Contains(flash, tt.want) {
+				t.Errorf("expected a flash mentioning %q, got %q", tt.want, flash)
+			}
+		}
+	})
+
+	t.Run("archives the event", func(t *testing.T) {
+		var archived int64
+		app := newApp(&servicemocks.EventServiceMock{
+			ArchiveEventFunc: func(ctx context.Context, id int64) error {
+				archived = id
+				return nil
+			},
+		}, 7)
+
+		rr, _ := serve(app, app.adminArchiveEventPost, "archive")
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if archived != event.ID {
+			t.Errorf("expected event %d archived, got %d", event.ID, archived)
+		}
+	})
+
+	t.Run("returns 404 for another organisation's event", func(t *testing.T) {
+		called := false
+		app := newApp(&servicemocks.EventServiceMock{
+			PublishEventFunc: func(ctx context.Context, id int64) error {
+				called = true
+				return nil
+			},
+			ArchiveEventFunc: func(ctx context.Context, id int64) error {
+				called = true
+				return nil
+			},
+		}, 8)
+
+		for action, h := range map[string]http.HandlerFunc{"publish": app.adminPublishEventPost, "archive": app.adminArchiveEventPost} {
+			if rr, _ := serve(app, h, action); rr.Code != http.StatusNotFound {
+				t.Errorf("expected %s to return %d, got %d", action, http.StatusNotFound, rr.Code)
+			}
+		}
+		if called {
+			t.Error("expected the event's status not to change")
+		}
+	})
+}
+
+func TestAdminImportEntrants(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "10K", MaxCapacity: 100}
+
+	newApp := func(orgID int64, registrationSvc *servicemocks.RegistrationServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				if id != race.EventID {
+					return db.Event{}, repository.ErrNotFound
+				}
+				return db.Event{ID: id, OrganisationID: 7, Name: "Lincoln 10k"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+				if id != race.ID {
+					return db.Race{}, repository.ErrNotFound
+				}
+				return race, nil
+			},
+		}
+		app.organisationService = memberOrganisationService(orgID, map[int64]int64{race.EventID: 7})
+		app.registrationService = registrationSvc
+		return app
+	}
+
+	upload := func(app *application, id, filename, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		if filename != "" {
+			fw, err := mw.CreateFormFile("file", filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.WriteString(fw, content)
+		}
+		mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/races/"+id+"/entrants/import", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.SetPathValue("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminImportEntrantsPost).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("renders the upload form", func(t *testing.T) {
+		app := newApp(7, &servicemocks.RegistrationServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/races/20/entrants/import", http.NoBody)
+		req.SetPathValue("id", "20")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminImportEntrantsView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, `action="/admin/races/20/entrants/import"`) || !strings.Contains(body, `enctype="multipart/form-data"`) {
+			t.Error("expected a multipart form posting to the import URL")
+		}
+	})
+
+	t.Run("passes the uploaded file to the service and renders the report", func(t *testing.T) {
+		var gotCSV string
+		var gotRace db.Race
+		app := newApp(7, &servicemocks.RegistrationServiceMock{
+			ImportEntrantsFunc: func(ctx context.Context, r db.Race, file io.Reader) (service.ImportReport, error) {
+				gotRace = r
+				b, _ := io.ReadAll(file)
+				gotCSV = string(b)
+				return service.ImportReport{Imported: true, Rows: []service.ImportRow{
+					{Line: 2, Email: "jane@example.com", Status: service.ImportRowCreated},
+					{Line: 3, Email: "jane@example.com", Status: service.ImportRowSkippedDuplicate, Message: "same email address as line 2"},
+				}}, nil
+			},
+		})
+
+		rr := upload(app, "20", "entrants.csv", "email,first name,last name\njane@example.com,Jane,Smith\n")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if gotRace.ID != race.ID {
+			t.Errorf("expected import into race %d, got %d", race.ID, gotRace.ID)
+		}
+		if !strings.HasPrefix(gotCSV, "email,first name,last name\n") {
+			t.Errorf("expected the uploaded file to reach the service, got %q", gotCSV)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"1 created, 1 skipped as duplicates", `data-import-row="created"`, `data-import-row="skipped-duplicate"`, "same email address as line 2"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("renders row errors when nothing was imported", func(t *testing.T) {
+		app := newApp(7, &servicemocks.RegistrationServiceMock{
+			ImportEntrantsFunc: func(ctx context.Context, r db.Race, file io.Reader) (service.ImportReport, error) {
+				return service.ImportReport{Rows: []service.ImportRow{
+					{Line: 4, Email: "bad", Status: service.ImportRowError, Message: "invalid email format"},
+				}}, nil
+			},
+		})
+
+		rr := upload(app, "20", "entrants.csv", "email,first name,last name\n")
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "Nothing was imported") || !strings.Contains(body, "invalid email format") {
+			t.Errorf("expected the failed rows to be reported, got:\n%s", body)
+		}
+	})
+
+	t.Run("shows file level errors inline", func(t *testing.T) {
+		app := newApp(7, &servicemocks.RegistrationServiceMock{
+			ImportEntrantsFunc: func(ctx context.Context, r db.Race, file io.Reader) (service.ImportReport, error) {
+				return service.ImportReport{}, fmt.Errorf("%w: 10K has room for 100 entrants", service.ErrRaceFull)
+			},
+		})
+
+		rr := upload(app, "20", "entrants.csv", "email,first name,last name\n")
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "10K has room for 100 entrants") {
+			t.Error("expected the capacity error to be shown")
+		}
+	})
+
+	t.Run("asks for a file when none was chosen", func(t *testing.T) {
+		app := newApp(7, &servicemocks.RegistrationServiceMock{
+			ImportEntrantsFunc: func(ctx context.Context, r db.Race, file io.Reader) (service.ImportReport, error) {
+				t.Error("expected the service not to be called")
+				return service.ImportReport{}, nil
+			},
+		})
+
+		rr := upload(app, "20", "", "")
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Choose a CSV file") {
+			t.Error("expected a prompt to choose a file")
+		}
+	})
+
+	t.Run("returns 404 for another organisation's race", func(t *testing.T) {
+		app := newApp(8, &servicemocks.RegistrationServiceMock{
+			ImportEntrantsFunc: func(ctx context.Context, r db.Race, file io.Reader) (service.ImportReport, error) {
+				t.Error("expected the service not to be called")
+				return service.ImportReport{}, nil
+			},
+		})
+
+		if rr := upload(app, "20", "entrants.csv", "email\n"); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("returns 404 for an unknown race", func(t *testing.T) {
+		app := newApp(7, &servicemocks.RegistrationServiceMock{})
+
+		if rr := upload(app, "99", "entrants.csv", "email\n"); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestAdminEntrants(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "10K"}
+
+	app := newTestApplication(&servicemocks.EventServiceMock{
+		GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+			return db.Event{ID: id, OrganisationID: 7, Name: "Lincoln 10k"}, nil
+		},
+	}, &servicemocks.UserServiceMock{})
+	app.raceService = &servicemocks.RaceServiceMock{
+		GetRaceByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+			if id != race.ID {
+				return db.Race{}, repository.ErrNotFound
+			}
+			return race, nil
+		},
+	}
+	app.organisationService = memberOrganisationService(7, map[int64]int64{race.EventID: 7})
+	app.registrationService = &servicemocks.RegistrationServiceMock{
+		ListRaceEntrantsFunc: func(ctx context.Context, raceID int64) ([]db.ListRaceEntrantsRow, error) {
+			return []db.ListRaceEntrantsRow{
+				{ID: 1, Status: db.RegistrationStatusConfirmed, Email: "jane@example.com", FirstName: "Jane", LastName: "Runner", TeamName: pgtype.Text{String: "Harriers A", Valid: true}},
+				{
+					ID:           2,
+					Status:       db.RegistrationStatusConfirmed,
+					Email:        "deleted-9@anonymised.invalid",
+					AnonymisedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+				},
+			}, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/races/20/entrants", http.NoBody)
+	req.SetPathValue("id", "20")
+	req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+	rr := httptest.NewRecorder()
+	withSession(app, app.adminEntrantsView).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "Jane Runner") || !strings.Contains(body, "jane@example.com") {
+		t.Error("expected the entrant to be listed")
+	}
+	if !strings.Contains(body, "<th scope=\"col\" class=\"py-2 pr-4\">Team</th>") || !strings.Contains(body, "Harriers A") {
+		t.Error("expected the entrant's team to be listed")
+	}
+	if !strings.Contains(body, "Deleted user") || strings.Contains(body, "anonymised.invalid") {
+		t.Error("expected the deleted entrant to be listed without their details")
+	}
+}
+
+func TestAdminEditRace(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "10K", Slug: "10k", MaxCapacity: 100}
+
+	newApp := func(updateFunc func(ctx context.Context, raceID int64, capacity int32, userID int64) (db.Race, error)) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Lincoln 10k"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+				if id != race.ID {
+					return db.Race{}, repository.ErrNotFound
+				}
+				return race, nil
+			},
+			GetRaceFunc: func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+				return service.RaceAvailability{Race: race, Registered: 42}, nil
+			},
+			GetRouteFunc: func(ctx context.Context, raceID int64) (service.RouteStats, error) {
+				return service.RouteStats{DistanceMetres: 10040, ElevationGainMetres: 85}, nil
+			},
+			UpdateRaceCapacityFunc: updateFunc,
+		}
+		app.organisationService = memberOrganisationService(7, map[int64]int64{race.EventID: 7})
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, method string, form url.Values) (*httptest.ResponseRecorder, string) {
+		req := httptest.NewRequest(method, "/admin/races/20/edit", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", "20")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		var flash string
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+			for _, kind := range []string{FlashSuccess, FlashInfo, FlashError} {
+				flash += app.sessionManager.GetString(r.Context(), "flash_"+kind)
+			}
+		}).ServeHTTP(rr, req)
+		return rr, flash
+	}
+
+	unexpectedUpdate := func(t *testing.T) func(ctx context.Context, raceID int64, capacity int32, userID int64) (db.Race, error) {
+		return func(ctx context.Context, raceID int64, capacity int32, userID int64) (db.Race, error) {
+			t.Error("expected the capacity not to be saved")
+			return db.Race{}, nil
+		}
+	}
+
+	t.Run("shows the capacity and places taken", func(t *testing.T) {
+		app := newApp(nil)
+
+		rr, _ := serve(app, app.adminEditRaceView, http.MethodGet, nil)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"42 of 100 places are taken", `value="100"`, `action="/admin/races/20/edit"`, `action="/admin/races/20/route"`, "The route is 10.0 km with 85 m climb"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("raises the capacity straight away", func(t *testing.T) {
+		var gotCapacity int32
+		var gotUser int64
+		app := newApp(func(ctx context.Context, raceID int64, capacity int32, userID int64) (db.Race, error) {
+			gotCapacity, gotUser = capacity, userID
+			updated := race
+			updated.MaxCapacity = capacity
+			return updated, nil
+		})
+
+		rr, flash := serve(app, app.adminEditRacePost, http.MethodPost, url.Values{"max_capacity": {"150"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/entrants" {
+			t.Fatalf("expected a redirect to the entrants, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if gotCapacity != 150 || gotUser != organiser.ID {
+			t.Errorf("expected capacity 150 set by the organiser, got %d by %d", gotCapacity, gotUser)
+		}
+		if flash != "10K now has 150 places" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("asks before lowering the capacity", func(t *testing.T) {
+		app := newApp(unexpectedUpdate(t))
+
+		rr, _ := serve(app, app.adminEditRacePost, http.MethodPost, url.Values{"max_capacity": {"80"}})
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"data-confirm-reduction", "from 100 to 80", `name="confirm" value="true"`, `value="80"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("lowers the capacity once confirmed", func(t *testing.T) {
+		var gotCapacity int32
+		app := newApp(func(ctx context.Context, raceID int64, capacity int32, userID int64) (db.Race, error) {
+			gotCapacity = capacity
+			updated := race
+			updated.MaxCapacity = capacity
+			return updated, nil
+		})
+
+		rr, _ := serve(app, app.adminEditRacePost, http.MethodPost, url.Values{"max_capacity": {"80"}, "confirm": {"true"}})
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if gotCapacity != 80 {
+			t.Errorf("expected capacity 80, got %d", gotCapacity)
+		}
+	})
+
+	t.Run("shows why the capacity cannot go lower", func(t *testing.T) {
+		app := newApp(func(ctx context.Context, raceID int64, capacity int32, userID int64) (db.Race, error) {
+			return db.Race{}, service.FieldErrors{"max_capacity": "capacity cannot be less than the 42 places already taken"}
+		})
+
+		rr, _ := serve(app, app.adminEditRacePost, http.MethodPost, url.Values{"max_capacity": {"30"}, "confirm": {"true"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, "Capacity cannot be less than the 42 places already taken") {
+			t.Error("expected the floor to be shown")
+		}
+	})
+
+	t.Run("leaves an unchanged capacity alone", func(t *testing.T) {
+		app := newApp(unexpectedUpdate(t))
+
+		rr, flash := serve(app, app.adminEditRacePost, http.MethodPost, url.Values{"max_capacity": {"100"}})
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if flash != "10K already has 100 places" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("rejects a capacity that is not a number", func(t *testing.T) {
+		app := newApp(unexpectedUpdate(t))
+
+		rr, _ := serve(app, app.adminEditRacePost, http.MethodPost, url.Values{"max_capacity": {"lots"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, "Capacity must be a number") {
+			t.Error("expected a number error")
+		}
+	})
+}
+
+func TestAdminRaceRoute(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "Ultra 50K", Slug: "ultra-50k", MaxCapacity: 100}
+
+	newApp := func(uploadFunc func(ctx context.Context, raceID int64, r io.Reader) (service.RouteStats, error)) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Peak District Ultra"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+				return race, nil
+			},
+			GetRaceFunc: func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+				return service.RaceAvailability{Race: race}, nil
+			},
+			GetRouteFunc: func(ctx context.Context, raceID int64) (service.RouteStats, error) {
+				return service.RouteStats{}, repository.ErrNotFound
+			},
+			UploadRouteFunc: uploadFunc,
+		}
+		app.organisationService = memberOrganisationService(7, map[int64]int64{race.EventID: 7})
+		return app
+	}
+
+	upload := func(app *application, filename string, content []byte) (*httptest.ResponseRecorder, string) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		if filename != "" {
+			fw, err := mw.CreateFormFile("file", filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write(content)
+		}
+		mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/races/20/route", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.SetPathValue("id", "20")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		var flash string
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			app.adminRaceRoutePost(w, r)
+			flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashSuccess)
+		}).ServeHTTP(rr, req)
+		return rr, flash
+	}
+
+	t.Run("stores the route and shows its figures", func(t *testing.T) {
+		var got string
+		app := newApp(func(ctx context.Context, raceID int64, r io.Reader) (service.RouteStats, error) {
+			b, err := io.ReadAll(r)
+			got = string(b)
+			return service.RouteStats{Points: 900, DistanceMetres: 50120, ElevationGainMetres: 1840}, err
+		})
+
+		rr, flash := upload(app, "ultra.gpx", []byte("<gpx></gpx>"))
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/edit" {
+			t.Fatalf("expected a redirect to the edit page, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if got != "<gpx></gpx>" {
+			t.Errorf("expected the uploaded file to reach the service, got %q", got)
+		}
+		if flash != "Route uploaded: 50.1 km with 1,840 m climb" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("shows why a file was refused", func(t *testing.T) {
+		app := newApp(func(ctx context.Context, raceID int64, r io.Reader) (service.RouteStats, error) {
+			return service.RouteStats{}, service.FieldErrors{"route": "route must be a GPX file"}
+		})
+
+		rr, _ := upload(app, "entrants.csv", []byte("name,email\n"))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, "Route must be a GPX file") {
+			t.Error("expected the reason to be shown on the form")
+		}
+	})
+
+	t.Run("refuses oversized uploads", func(t *testing.T) {
+		app := newApp(func(ctx context.Context, raceID int64, r io.Reader) (service.RouteStats, error) {
+			_, err := io.ReadAll(r)
+			return service.RouteStats{}, err
+		})
+
+		rr, _ := upload(app, "huge.gpx", bytes.Repeat([]byte(" "), maxRouteUploadBytes))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, "routes are limited to 5 MB") {
+			t.Error("expected the size limit to be shown")
+		}
+	})
+
+	t.Run("asks for a file", func(t *testing.T) {
+		app := newApp(nil)
+
+		rr, _ := upload(app, "", nil)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, "Choose a GPX file to upload") {
+			t.Error("expected to be asked for a file")
+		}
+	})
+}
+
+func TestAdminPhotos(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+
+	newApp := func(photoSvc *servicemocks.PhotoServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Peak District Ultra"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		if photoSvc.PhotoURLFunc == nil {
+			photoSvc.PhotoURLFunc = func(ctx context.Context, photo db.EventPhoto) (string, error) {
+				return fmt.Sprintf("/media/photo-%d.jpg?signature=x", photo.ID), nil
+			}
+		}
+		app.photoService = photoSvc
+		app.organisationService = memberOrganisationService(7, map[int64]int64{4: 7})
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, req *http.Request) (*httptest.ResponseRecorder, string) {
+		req.SetPathValue("id", "4")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		var flash string
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+			flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashSuccess)
+		}).ServeHTTP(rr, req)
+		return rr, flash
+	}
+
+	upload := func(app *application, filename string, content []byte) (*httptest.ResponseRecorder, string) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		if filename != "" {
+			fw, err := mw.CreateFormFile("file", filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write(content)
+		}
+		mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/events/4/photos", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return serve(app, app.adminUploadPhotoPost, req)
+	}
+
+	t.Run("lists the gallery in order", func(t *testing.T) {
+		app := newApp(&servicemocks.PhotoServiceMock{
+			ListPhotosFunc: func(ctx context.Context, eventID int64) ([]db.EventPhoto, error) {
+				return []db.EventPhoto{{ID: 31, EventID: eventID, Width: 1600, Height: 1200}, {ID: 30, EventID: eventID}}, nil
+			},
+		})
+
+		rr, _ := serve(app, app.adminPhotosView, httptest.NewRequest(http.MethodGet, "/admin/events/4/photos", http.NoBody))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			`data-photo="31"`,
+			`src="/media/photo-31.jpg?signature=x"`,
+			`action="/admin/events/4/photos/31/move"`,
+			`action="/admin/events/4/photos/30/delete"`,
+			`enctype="multipart/form-data"`,
+			"up to 10 MB",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		if strings.Index(body, `data-photo="31"`) > strings.Index(body, `data-photo="30"`) {
+			t.Error("expected the photos in gallery order")
+		}
+	})
+
+	t.Run("stores an uploaded photo", func(t *testing.T) {
+		var got string
+		app := newApp(&servicemocks.PhotoServiceMock{
+			UploadPhotoFunc: func(ctx context.Context, eventID int64, r io.Reader) (db.EventPhoto, error) {
+				if eventID != 4 {
+					t.Errorf("expected a photo for event 4, got %d", eventID)
+				}
+				b, err := io.ReadAll(r)
+				got = string(b)
+				return db.EventPhoto{ID: 30, EventID: eventID}, err
+			},
+		})
+
+		rr, flash := upload(app, "start.jpg", []byte("jpeg bytes"))
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/events/4/photos" {
+			t.Fatalf("expected a redirect to the photos page, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if got != "jpeg bytes" {
+			t.Errorf("expected the uploaded file to reach the service, got %q", got)
+		}
+		if flash != "Photo uploaded" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("shows why a photo was refused", func(t *testing.T) {
+		app := newApp(&servicemocks.PhotoServiceMock{
+			UploadPhotoFunc: func(ctx context.Context, eventID int64, r io.Reader) (db.EventPhoto, error) {
+				return db.EventPhoto{}, service.FieldErrors{"photo": "photo must be a JPEG or PNG image"}
+			},
+		})
+
+		rr, _ := upload(app, "route.gpx", []byte("<gpx></gpx>"))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, "Photo must be a JPEG or PNG image") {
+			t.Error("expected the reason to be shown on the form")
+		}
+	})
+
+	t.Run("refuses oversized uploads", func(t *testing.T) {
+		app := newApp(&servicemocks.PhotoServiceMock{
+			UploadPhotoFunc: func(ctx context.Context, eventID int64, r io.Reader) (db.EventPhoto, error) {
+				_, err := io.ReadAll(r)
+				return db.EventPhoto{}, err
+			},
+		})
+
+		rr, _ := upload(app, "huge.jpg", bytes.Repeat([]byte{0xff}, maxPhotoUploadBytes))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, "photos are limited to 10 MB") {
+			t.Error("expected the size limit to be shown")
+		}
+	})
+
+	t.Run("asks for a file", func(t *testing.T) {
+		app := newApp(&servicemocks.PhotoServiceMock{})
+
+		rr, _ := upload(app, "", nil)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, "Choose a JPEG or PNG photo to upload") {
+			t.Error("expected to be asked for a file")
+		}
+	})
+
+	t.Run("deletes a photo", func(t *testing.T) {
+		var deleted int64
+		app := newApp(&servicemocks.PhotoServiceMock{
+			DeletePhotoFunc: func(ctx context.Context, eventID, photoID int64) error {
+				if eventID != 4 {
+					t.Errorf("expected a photo of event 4, got %d", eventID)
+				}
+				deleted = photoID
+				return nil
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/events/4/photos/30/delete", http.NoBody)
+		req.SetPathValue("photoID", "30")
+		rr, flash := serve(app, app.adminDeletePhotoPost, req)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/events/4/photos" {
+			t.Fatalf("expected a redirect to the photos page, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if deleted != 30 || flash != "Photo deleted" {
+			t.Errorf("expected photo 30 deleted, got %d with flash %q", deleted, flash)
+		}
+	})
+
+	t.Run("returns 404 for another event's photo", func(t *testing.T) {
+		app := newApp(&servicemocks.PhotoServiceMock{
+			DeletePhotoFunc: func(ctx context.Context, eventID, photoID int64) error {
+				return repository.ErrNotFound
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/events/4/photos/99/delete", http.NoBody)
+		req.SetPathValue("photoID", "99")
+		if rr, _ := serve(app, app.adminDeletePhotoPost, req); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("moves a photo", func(t *testing.T) {
+		tests := []struct {
+			direction string
+			want      int
+		}{
+			{direction: "up", want: -1},
+			{direction: "down", want: 1},
+		}
+		for _, tt := range tests {
+			var offset int
+			app := newApp(&servicemocks.PhotoServiceMock{
+				MovePhotoFunc: func(ctx context.Context, eventID, photoID int64, o int) error {
+					offset = o
+					return nil
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/events/4/photos/30/move", strings.NewReader("direction="+tt.direction))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("photoID", "30")
+			rr, _ := serve(app, app.adminMovePhotoPost, req)
+
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+			}
+			if offset != tt.want {
+				t.Errorf("expected moving %s to be offset %d, got %d", tt.direction, tt.want, offset)
+			}
+		}
+	})
+
+	t.Run("rejects unknown directions", func(t *testing.T) {
+		app := newApp(&servicemocks.PhotoServiceMock{})
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/events/4/photos/30/move", strings.NewReader("direction=sideways"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("photoID", "30")
+		if rr, _ := serve(app, app.adminMovePhotoPost, req); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
+
+func TestAdminRaceQuestions(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "Ultra 50K", Slug: "ultra-50k", MaxCapacity: 100}
+
+	newApp := func(raceSvc *servicemocks.RaceServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Peak District Ultra"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		raceSvc.GetRaceByIDFunc = func(ctx context.Context, id int64) (db.Race, error) {
+			return race, nil
+		}
+		raceSvc.GetRaceFunc = func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+			return service.RaceAvailability{Race: race}, nil
+		}
+		raceSvc.GetRouteFunc = func(ctx context.Context, raceID int64) (service.RouteStats, error) {
+			return service.RouteStats{}, repository.ErrNotFound
+		}
+		app.raceService = raceSvc
+		app.organisationService = memberOrganisationService(7, map[int64]int64{race.EventID: 7})
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, method string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/races/20/questions", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", "20")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("ticks the questions the race requires", func(t *testing.T) {
+		app := newApp(&servicemocks.RaceServiceMock{
+			RequiredQuestionsFunc: func(ctx context.Context, raceID int64) ([]service.Question, error) {
+				return []service.Question{service.QuestionMedicalConditions}, nil
+			},
+			LockedQuestionsFunc: func(ctx context.Context, raceID int64) ([]service.Question, error) {
+				return []service.Question{service.QuestionClub}, nil
+			},
+		})
+
+		rr := serve(app, app.adminEditRaceView, http.MethodGet, nil)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, `value="medical_conditions" checked`) {
+			t.Error("expected the medical question to be ticked")
+		}
+		if strings.Contains(body, `name="questions" value="club" checked`) {
+			t.Error("expected the club question not to be required")
+		}
+		if !strings.Contains(body, `name="locked" value="club" checked`) {
+			t.Error("expected the club question to be locked")
+		}
+	})
+
+	t.Run("saves the questions ticked", func(t *testing.T) {
+		var got, locked []string
+		app := newApp(&servicemocks.RaceServiceMock{
+			SetRequiredQuestionsFunc: func(ctx context.Context, raceID int64, names []string) error {
+				got = names
+				return nil
+			},
+			SetLockedQuestionsFunc: func(ctx context.Context, raceID int64, names []string) error {
+				locked = names
+				return nil
+			},
+		})
+
+		rr := serve(app, app.adminRaceQuestionsPost, http.MethodPost, url.Values{"questions": {"club", "estimated_finish"}, "locked": {"club"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/edit" {
+			t.Fatalf("expected a redirect to the edit page, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if !slices.Equal(got, []string{"club", "estimated_finish"}) {
+			t.Errorf("expected club and estimated_finish, got %v", got)
+		}
+		if !slices.Equal(locked, []string{"club"}) {
+			t.Errorf("expected the club locked, got %v", locked)
+		}
+	})
+
+	t.Run("refuses unknown questions", func(t *testing.T) {
+		app := newApp(&servicemocks.RaceServiceMock{
+			SetRequiredQuestionsFunc: func(ctx context.Context, raceID int64, names []string) error {
+				return service.ErrInvalidInput
+			},
+		})
+
+		if rr := serve(app, app.adminRaceQuestionsPost, http.MethodPost, url.Values{"questions": {"shoe_size"}}); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
+
+func TestAdminRaceDistance(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer, DistanceUnit: db.DistanceUnitKm}
+	race := db.Race{ID: 20, EventID: 4, Name: "Ultra 50K", Slug: "ultra-50k", MaxCapacity: 100,
+		DistanceMetres: pgtype.Int4{Int32: 50000, Valid: true}, ElevationGainMetres: pgtype.Int4{Int32: 2350, Valid: true}}
+
+	newApp := func(raceSvc *servicemocks.RaceServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Peak District Ultra"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		raceSvc.GetRaceByIDFunc = func(ctx context.Context, id int64) (db.Race, error) {
+			return race, nil
+		}
+		raceSvc.GetRaceFunc = func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+			return service.RaceAvailability{Race: race}, nil
+		}
+		raceSvc.GetRouteFunc = func(ctx context.Context, raceID int64) (service.RouteStats, error) {
+			return service.RouteStats{}, repository.ErrNotFound
+		}
+		raceSvc.RequiredQuestionsFunc = func(ctx context.Context, raceID int64) ([]service.Question, error) {
+			return nil, nil
+		}
+		raceSvc.LockedQuestionsFunc = func(ctx context.Context, raceID int64) ([]service.Question, error) {
+			return nil, nil
+		}
+		raceSvc.ListPriceTiersFunc = func(ctx context.Context, raceID int64) ([]service.PriceTier, error) {
+			return nil, nil
+		}
+		app.registrationService = &servicemocks.RegistrationServiceMock{
+			ListWavesFunc: func(ctx context.Context, raceID int64) ([]db.ListRaceWavesRow, error) {
+				return nil, nil
+			},
+		}
+		app.raceService = raceSvc
+		app.organisationService = memberOrganisationService(7, map[int64]int64{race.EventID: 7})
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, method string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/races/20/distance", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", "20")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("fills in the distance in the organiser's unit", func(t *testing.T) {
+		app := newApp(&servicemocks.RaceServiceMock{})
+
+		rr := serve(app, app.adminEditRaceView, http.MethodGet, nil)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{`value="50"`, `value="km" selected`, `value="2350"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected %s in the form", want)
+			}
+		}
+	})
+
+	t.Run("converts miles to metres on save", func(t *testing.T) {
+		var distance, climb int
+		app := newApp(&servicemocks.RaceServiceMock{
+			SetDistanceFunc: func(ctx context.Context, raceID int64, distanceMetres, elevationGainMetres int) (db.Race, error) {
+				distance, climb = distanceMetres, elevationGainMetres
+				return race, nil
+			},
+		})
+
+		rr := serve(app, app.adminRaceDistancePost, http.MethodPost, url.Values{"distance": {"26.2"}, "distance_unit": {"miles"}, "elevation_gain": {"400"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/edit" {
+			t.Fatalf("expected a redirect to the edit page, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if distance != 42165 || climb != 400 {
+			t.Errorf("expected 42165 m with 400 m climb, got %d m with %d m", distance, climb)
+		}
+	})
+
+	t.Run("clears a blank distance", func(t *testing.T) {
+		distance := -1
+		app := newApp(&servicemocks.RaceServiceMock{
+			SetDistanceFunc: func(ctx context.Context, raceID int64, distanceMetres, elevationGainMetres int) (db.Race, error) {
+				distance = distanceMetres
+				return race, nil
+			},
+		})
+
+		if rr := serve(app, app.adminRaceDistancePost, http.MethodPost, url.Values{"distance": {""}, "distance_unit": {"km"}}); rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if distance != 0 {
+			t.Errorf("expected the distance cleared, got %d", distance)
+		}
+	})
+
+	t.Run("shows what was typed back with the problem", func(t *testing.T) {
+		app := newApp(&servicemocks.RaceServiceMock{})
+
+		rr := serve(app, app.adminRaceDistancePost, http.MethodPost, url.Values{"distance": {"a marathon"}, "distance_unit": {"miles"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "Enter the distance as a number") || !strings.Contains(body, `value="a marathon"`) {
+			t.Error("expected the distance error and what was typed")
+		}
+	})
+
+	t.Run("refuses an unknown unit", func(t *testing.T) {
+		app := newApp(&servicemocks.RaceServiceMock{})
+
+		if rr := serve(app, app.adminRaceDistancePost, http.MethodPost, url.Values{"distance": {"10"}, "distance_unit": {"furlongs"}}); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
+
+func TestAdminRaceWaves(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "10K", Slug: "10k", MaxCapacity: 100}
+	waves := []db.ListRaceWavesRow{
+		{RaceWave: db.RaceWave{ID: 5, RaceID: 20, Name: "Wave A", StartsAt: pgtype.Timestamptz{Time: time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC), Valid: true}, Capacity: 50}, Taken: 12},
+		{RaceWave: db.RaceWave{ID: 6, RaceID: 20, Name: "Wave B", StartsAt: pgtype.Timestamptz{Time: time.Date(2026, 5, 2, 9, 20, 0, 0, time.UTC), Valid: true}, Capacity: 50}},
+	}
+
+	newApp := func(regSvc *servicemocks.RegistrationServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Lincoln Run"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+				return race, nil
+			},
+			GetRaceFunc: func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+				return service.RaceAvailability{Race: race}, nil
+			},
+			GetRouteFunc: func(ctx context.Context, raceID int64) (service.RouteStats, error) {
+				return service.RouteStats{}, repository.ErrNotFound
+			},
+		}
+		regSvc.ListWavesFunc = func(ctx context.Context, raceID int64) ([]db.ListRaceWavesRow, error) {
+			return waves, nil
+		}
+		app.registrationService = regSvc
+		app.organisationService = memberOrganisationService(7, map[int64]int64{race.EventID: 7})
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, method, waveID string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/races/20/waves", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", "20")
+		if waveID != "" {
+			req.SetPathValue("waveID", waveID)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("lists the race's waves", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{})
+
+		rr := serve(app, app.adminEditRaceView, http.MethodGet, "", nil)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{`data-wave="Wave A"`, `value="2026-05-02T10:20"`, "12 entered", `action="/admin/races/20/waves/6/delete"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+		if strings.Contains(body, `action="/admin/races/20/waves/5/delete"`) {
+			t.Error("expected no delete button for a wave with entrants")
+		}
+	})
+
+	t.Run("adds a wave", func(t *testing.T) {
+		var got service.WaveInput
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			CreateWaveFunc: func(ctx context.Context, event db.Event, raceID int64, input service.WaveInput) (db.RaceWave, error) {
+				got = input
+				return db.RaceWave{ID: 7, RaceID: raceID, Name: input.Name}, nil
+			},
+		})
+
+		rr := serve(app, app.adminRaceWavesPost, http.MethodPost, "", url.Values{"name": {"Wave C"}, "starts_at": {"2026-05-02T09:40"}, "capacity": {"40"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/edit" {
+			t.Fatalf("expected a redirect to the edit page, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		want := service.WaveInput{Name: "Wave C", StartsAt: time.Date(2026, 5, 2, 9, 40, 0, 0, time.UTC), Capacity: 40}
+		if got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("shows the problems with a new wave", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			CreateWaveFunc: func(ctx context.Context, event db.Event, raceID int64, input service.WaveInput) (db.RaceWave, error) {
+				return db.RaceWave{}, service.FieldErrors{"name": "a wave with this name already exists"}
+			},
+		})
+
+		rr := serve(app, app.adminRaceWavesPost, http.MethodPost, "", url.Values{"name": {"Wave A"}, "starts_at": {"2026-05-02T09:40"}, "capacity": {"40"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "A wave with this name already exists") {
+			t.Error("expected the name error to be shown")
+		}
+	})
+
+	t.Run("refuses start times it cannot read", func(t *testing.T) {
+		regSvc := &servicemocks.RegistrationServiceMock{}
+		app := newApp(regSvc)
+
+		rr := serve(app, app.adminRaceWavePost, http.MethodPost, "5", url.Values{"name": {"Wave A"}, "starts_at": {"nine o'clock"}, "capacity": {"50"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Start time must be a date and time") {
+			t.Error("expected the start time error to be shown")
+		}
+		if len(regSvc.UpdateWaveCalls()) != 0 {
+			t.Error("expected the wave not to be saved")
+		}
+	})
+
+	t.Run("answers not found for another race's wave", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			UpdateWaveFunc: func(ctx context.Context, event db.Event, race db.Race, waveID int64, input service.WaveInput) (db.RaceWave, error) {
+				return db.RaceWave{}, repository.ErrNotFound
+			},
+		})
+
+		rr := serve(app, app.adminRaceWavePost, http.MethodPost, "99", url.Values{"name": {"Wave A"}, "starts_at": {"2026-05-02T09:00"}, "capacity": {"50"}})
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("keeps waves with entrants", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			DeleteWaveFunc: func(ctx context.Context, raceID, waveID int64) error {
+				return service.ErrWaveInUse
+			},
+		})
+
+		rr := serve(app, app.adminRaceWaveDeletePost, http.MethodPost, "5", nil)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/edit" {
+			t.Fatalf("expected a redirect to the edit page, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+	})
+}
+
+func TestAdminRacePriceTiers(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "10K", Slug: "10k", MaxCapacity: 100, PriceUnits: pgtype.Int4{Int32: 7500, Valid: true}}
+	tiers := []service.PriceTier{
+		{Tier: db.RacePriceTier{ID: 5, RaceID: 20, Name: "First 100", PriceUnits: 5500, Capacity: pgtype.Int4{Int32: 100, Valid: true}}, Taken: 12},
+		{Tier: db.RacePriceTier{ID: 6, RaceID: 20, Name: "Early bird", PriceUnits: 6500, ValidTo: pgtype.Timestamptz{Time: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true}}},
+	}
+
+	newApp := func(raceSvc *servicemocks.RaceServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Lincoln Run"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		raceSvc.GetRaceByIDFunc = func(ctx context.Context, id int64) (db.Race, error) {
+			return race, nil
+		}
+		raceSvc.GetRaceFunc = func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+			return service.RaceAvailability{Race: race}, nil
+		}
+		raceSvc.GetRouteFunc = func(ctx context.Context, raceID int64) (service.RouteStats, error) {
+			return service.RouteStats{}, repository.ErrNotFound
+		}
+		raceSvc.ListPriceTiersFunc = func(ctx context.Context, raceID int64) ([]service.PriceTier, error) {
+			return tiers, nil
+		}
+		app.raceService = raceSvc
+		app.registrationService = &servicemocks.RegistrationServiceMock{}
+		app.organisationService = memberOrganisationService(7, map[int64]int64{race.EventID: 7})
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, method, tierID string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/races/20/prices", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", "20")
+		if tierID != "" {
+			req.SetPathValue("tierID", tierID)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("lists the race's tiers", func(t *testing.T) {
+		app := newApp(&servicemocks.RaceServiceMock{})
+
+		rr := serve(app, app.adminEditRaceView, http.MethodGet, "", nil)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{`data-price-tier="First 100"`, "£55.00", "12 of 100", "1 Mar 2026 00:00", "an entry costs £75.00", `action="/admin/races/20/prices/6/delete"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("adds a tier", func(t *testing.T) {
+		var got service.PriceTierInput
+		app := newApp(&servicemocks.RaceServiceMock{
+			CreatePriceTierFunc: func(ctx context.Context, event db.Event, raceID int64, input service.PriceTierInput) (db.RacePriceTier, error) {
+				got = input
+				return db.RacePriceTier{ID: 7, RaceID: raceID, Name: input.Name}, nil
+			},
+		})
+
+		rr := serve(app, app.adminRacePriceTiersPost, http.MethodPost, "", url.Values{"name": {"Standard"}, "price": {"70.00"}, "valid_from": {"2026-03-01T00:00"}, "valid_to": {"2026-05-01T00:00"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/edit" {
+			t.Fatalf("expected a redirect to the edit page, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		want := service.PriceTierInput{
+			Name:       "Standard",
+			PriceUnits: 7000,
+			ValidFrom:  time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			ValidTo:    time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		}
+		if got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("shows an overlap with the posted tier", func(t *testing.T) {
+		app := newApp(&servicemocks.RaceServiceMock{
+			CreatePriceTierFunc: func(ctx context.Context, event db.Event, raceID int64, input service.PriceTierInput) (db.RacePriceTier, error) {
+				return db.RacePriceTier{}, service.FieldErrors{"valid_from": "dates overlap the Early bird tier"}
+			},
+		})
+
+		rr := serve(app, app.adminRacePriceTiersPost, http.MethodPost, "", url.Values{"name": {"Winter"}, "price": {"60.00"}, "valid_to": {"2026-02-01T00:00"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"Dates overlap the Early bird tier", `value="Winter"`, `value="2026-02-01T00:00"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("refuses prices it cannot read", func(t *testing.T) {
+		raceSvc := &servicemocks.RaceServiceMock{}
+		app := newApp(raceSvc)
+
+		rr := serve(app, app.adminRacePriceTiersPost, http.MethodPost, "", url.Values{"name": {"Standard"}, "price": {"seventy"}, "capacity": {"50"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Price must be an amount such as 65.00") {
+			t.Error("expected the price error to be shown")
+		}
+		if len(raceSvc.CreatePriceTierCalls()) != 0 {
+			t.Error("expected the tier not to be saved")
+		}
+	})
+
+	t.Run("deletes a tier", func(t *testing.T) {
+		var gotRace, gotTier int64
+		app := newApp(&servicemocks.RaceServiceMock{
+			DeletePriceTierFunc: func(ctx context.Context, raceID, tierID int64) error {
+				gotRace, gotTier = raceID, tierID
+				return nil
+			},
+		})
+
+		rr := serve(app, app.adminRacePriceTierDeletePost, http.MethodPost, "6", nil)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/edit" {
+			t.Fatalf("expected a redirect to the edit page, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if gotRace != 20 || gotTier != 6 {
+			t.Errorf("expected tier 6 of race 20 deleted, got %d of %d", gotTier, gotRace)
+		}
+	})
+
+	t.Run("answers not found for another race's tier", func(t *testing.T) {
+		app := newApp(&servicemocks.RaceServiceMock{
+			DeletePriceTierFunc: func(ctx context.Context, raceID, tierID int64) error {
+				return repository.ErrNotFound
+			},
+		})
+
+		rr := serve(app, app.adminRacePriceTierDeletePost, http.MethodPost, "99", nil)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestAdminBibs(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "10K", Slug: "10k"}
+	entrants := []db.ListRaceEntrantsRow{
+		{
+			ID: 1, Status: db.RegistrationStatusConfirmed, Bib: pgtype.Text{String: "101", Valid: true}, Email: "jane@example.com", FirstName: "Jane", LastName: "Runner", TeamName: pgtype.Text{String: "Harriers A", Valid: true},
+			DateOfBirth:  pgtype.Date{Time: time.Date(1980, time.May, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+			RaceDay:      pgtype.Date{Time: time.Date(2026, time.May, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+			WaveName:     pgtype.Text{String: "Wave B", Valid: true},
+			WaveStartsAt: pgtype.Timestamptz{Time: time.Date(2026, time.May, 2, 9, 20, 0, 0, time.UTC), Valid: true},
+			CheckedInAt:  pgtype.Timestamptz{Time: time.Date(2026, time.May, 2, 8, 45, 0, 0, time.UTC), Valid: true},
+		},
+		{ID: 2, Status: db.RegistrationStatusConfirmed, Email: "eve@example.com", FirstName: "=SUM(A1)", LastName: "Eve"},
+		{ID: 3, Status: db.RegistrationStatusCancelled, Email: "sam@example.com", FirstName: "Sam", LastName: "Gone"},
+	}
+
+	newApp := func(registrationSvc *servicemocks.RegistrationServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Lincoln 10k"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+				if id != race.ID {
+					return db.Race{}, repository.ErrNotFound
+				}
+				return race, nil
+			},
+		}
+		app.organisationService = memberOrganisationService(7, map[int64]int64{race.EventID: 7})
+		registrationSvc.ListRaceEntrantsFunc = func(ctx context.Context, raceID int64) ([]db.ListRaceEntrantsRow, error) {
+			return entrants, nil
+		}
+		app.registrationService = registrationSvc
+		return app
+	}
+
+	serve := func(app *application, h http.HandlerFunc, regID string, form url.Values) (*httptest.ResponseRecorder, string) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/races/20/bibs", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", "20")
+		req.SetPathValue("registrationID", regID)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		var flash string
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			h(w, r)
+			for _, kind := range []string{FlashSuccess, FlashInfo, FlashError} {
+				flash += app.sessionManager.GetString(r.Context(), "flash_"+kind)
+			}
+		}).ServeHTTP(rr, req)
+		return rr, flash
+	}
+
+	t.Run("assigns bibs from the first number given", func(t *testing.T) {
+		var gotRaceID int64
+		var gotStart int
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			AssignBibNumbersFunc: func(ctx context.Context, raceID int64, startAt int) (int, error) {
+				gotRaceID, gotStart = raceID, startAt
+				return 2, nil
+			},
+		})
+
+		rr, flash := serve(app, app.adminAssignBibsPost, "", url.Values{"start_at": {"100"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/entrants" {
+			t.Fatalf("expected a redirect to the entrants, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if gotRaceID != race.ID || gotStart != 100 {
+			t.Errorf("expected bibs from 100 for race 20, got %d for race %d", gotStart, gotRaceID)
+		}
+		if flash != "Bibs assigned to 2 confirmed entrants" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("says so when every entrant already has a bib", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			AssignBibNumbersFunc: func(ctx context.Context, raceID int64, startAt int) (int, error) {
+				return 0, nil
+			},
+		})
+
+		if _, flash := serve(app, app.adminAssignBibsPost, "", url.Values{"start_at": {"1"}}); flash != "Every confirmed entrant already has a bib" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("asks for the first bib", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			AssignBibNumbersFunc: func(ctx context.Context, raceID int64, startAt int) (int, error) {
+				t.Error("expected the service not to be called")
+				return 0, nil
+			},
+		})
+
+		if _, flash := serve(app, app.adminAssignBibsPost, "", url.Values{}); flash != "First bib is required" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("sets an entrant's bib", func(t *testing.T) {
+		var gotID int64
+		var gotBib int
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			SetBibFunc: func(ctx context.Context, registrationID int64, bib int) error {
+				gotID, gotBib = registrationID, bib
+				return nil
+			},
+		})
+
+		rr, flash := serve(app, app.adminSetBibPost, "2", url.Values{"bib": {"7"}})
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if gotID != 2 || gotBib != 7 {
+			t.Errorf("expected registration 2 to get bib 7, got %d for %d", gotBib, gotID)
+		}
+		if flash != "=SUM(A1) Eve is now bib 7" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("rejects a bib that is already taken", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			SetBibFunc: func(ctx context.Context, registrationID int64, bib int) error {
+				return repository.ErrConflict
+			},
+		})
+
+		if _, flash := serve(app, app.adminSetBibPost, "2", url.Values{"bib": {"101"}}); flash != "Bib 101 is already taken" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("returns 404 for another race's registration", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			SetBibFunc: func(ctx context.Context, registrationID int64, bib int) error {
+				t.Error("expected the service not to be called")
+				return nil
+			},
+		})
+
+		if rr, _ := serve(app, app.adminSetBibPost, "99", url.Values{"bib": {"7"}}); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("exports active entrants with their bibs", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/races/20/entrants/export", http.NoBody)
+		req.SetPathValue("id", "20")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminExportEntrants).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename="10k-entrants.csv"` {
+			t.Errorf("unexpected Content-Disposition %q", got)
+		}
+		want := "bib,name,email,team,wave,wave_start,category,status,checked_in,club,estimated_finish\n" +
+			"101,Jane Runner,jane@example.com,Harriers A,Wave B,2026-05-02 09:20,V40,Confirmed,2026-05-02 08:45,,\n" +
+			",'=SUM(A1) Eve,eve@example.com,,,,,Confirmed,,,\n"
+		if got := rr.Body.String(); got != want {
+			t.Errorf("expected CSV:\n%s\ngot:\n%s", want, got)
+		}
+	})
+
+	exportAnswers := func(t *testing.T, medical bool) string {
+		t.Helper()
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			ListRaceAnswersFunc: func(ctx context.Context, raceID int64) (map[int64]service.Answers, error) {
+				return map[int64]service.Answers{
+					1: {EmergencyContactName: "Sam Runner", EmergencyContactPhone: "07700 900123", MedicalConditions: "Asthma", Club: "=Harriers", EstimatedFinish: "0:45"},
+				}, nil
+			},
+		})
+		orgs := app.organisationService.(*servicemocks.OrganisationServiceMock)
+		orgs.CanReadMedicalFunc = func(ctx context.Context, userID, eventID int64) (bool, error) {
+			return medical, nil
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/races/20/entrants/export", http.NoBody)
+		req.SetPathValue("id", "20")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminExportEntrants).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		return rr.Body.String()
+	}
+
+	t.Run("exports questionnaire answers without medical details for staff", func(t *testing.T) {
+		got := exportAnswers(t, false)
+		want := "bib,name,email,team,wave,wave_start,category,status,checked_in,club,estimated_finish\n" +
+			"101,Jane Runner,jane@example.com,Harriers A,Wave B,2026-05-02 09:20,V40,Confirmed,2026-05-02 08:45,'=Harriers,0:45\n" +
+			",'=SUM(A1) Eve,eve@example.com,,,,,Confirmed,,,\n"
+		if got != want {
+			t.Errorf("expected CSV:\n%s\ngot:\n%s", want, got)
+		}
+	})
+
+	t.Run("exports medical details to organisers who can read them", func(t *testing.T) {
+		got := exportAnswers(t, true)
+		want := "bib,name,email,team,wave,wave_start,category,status,checked_in,emergency_contact_name,emergency_contact_phone,medical_conditions,club,estimated_finish\n" +
+			"101,Jane Runner,jane@example.com,Harriers A,Wave B,2026-05-02 09:20,V40,Confirmed,2026-05-02 08:45,Sam Runner,07700 900123,Asthma,'=Harriers,0:45\n" +
+			",'=SUM(A1) Eve,eve@example.com,,,,,Confirmed,,,,,,\n"
+		if got != want {
+			t.Errorf("expected CSV:\n%s\ngot:\n%s", want, got)
+		}
+	})
+
+	t.Run("exports custom field answers headed by label", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			ListRaceCustomAnswersFunc: func(ctx context.Context, raceID int64) (map[int64]service.CustomAnswers, error) {
+				return map[int64]service.CustomAnswers{
+					1: {5: {Version: 1, Value: "M"}, 6: {Version: 1, Value: true}, 7: {Version: 1, Value: "Vegan"}},
+					2: {6: {Version: 1, Value: false}},
+				}, nil
+			},
+		})
+		races := app.raceService.(*servicemocks.RaceServiceMock)
+		races.CustomFieldsFunc = func(ctx context.Context, raceID int64) ([]service.CustomField, error) {
+			return []service.CustomField{
+				{ID: 6, Version: 1, Label: "Ferry crossing", Kind: db.CustomFieldKindCheckbox},
+				{ID: 5, Version: 2, Label: "Shirt size", Kind: db.CustomFieldKindSelect},
+			}, nil
+		}
+		races.CustomFieldVersionsFunc = func(ctx context.Context, raceID int64) (service.CustomFieldVersions, error) {
+			return service.CustomFieldVersions{
+				5: {{ID: 5, Version: 1, Label: "T-shirt size"}, {ID: 5, Version: 2, Label: "Shirt size"}},
+				6: {{ID: 6, Version: 1, Label: "Ferry crossing"}},
+				7: {{ID: 7, Version: 1, Label: "Meal"}},
+			}, nil
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/races/20/entrants/export", http.NoBody)
+		req.SetPathValue("id", "20")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminExportEntrants).ServeHTTP(rr, req)
+
+		want := "bib,name,email,team,wave,wave_start,category,status,checked_in,club,estimated_finish,Ferry crossing,Shirt size,Meal\n" +
+			"101,Jane Runner,jane@example.com,Harriers A,Wave B,2026-05-02 09:20,V40,Confirmed,2026-05-02 08:45,,,Yes,M,Vegan\n" +
+			",'=SUM(A1) Eve,eve@example.com,,,,,Confirmed,,,,No,,\n"
+		if got := rr.Body.String(); got != want {
+			t.Errorf("expected CSV:\n%s\ngot:\n%s", want, got)
+		}
+	})
+
+	exportAs := func(t *testing.T, format string) *httptest.ResponseRecorder {
+		t.Helper()
+		app := newApp(&servicemocks.RegistrationServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/races/20/entrants/export?format="+format, http.NoBody)
+		req.SetPathValue("id", "20")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminExportEntrants).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("exports active entrants as JSON", func(t *testing.T) {
+		rr := exportAs(t, "json")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("unexpected Content-Type %q", got)
+		}
+		if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename="10k-entrants.json"` {
+			t.Errorf("unexpected Content-Disposition %q", got)
+		}
+		var got []map[string]string
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to parse JSON: %v", err)
+		}
+		if len(got) != 2 || got[0]["bib"] != "101" || got[1]["name"] != "=SUM(A1) Eve" {
+			t.Errorf("expected both active entrants, got %v", got)
+		}
+	})
+
+	t.Run("exports active entrants as an Excel workbook", func(t *testing.T) {
+		rr := exportAs(t, "xlsx")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+			t.Errorf("unexpected Content-Type %q", got)
+		}
+		if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename="10k-entrants.xlsx"` {
+			t.Errorf("unexpected Content-Disposition %q", got)
+		}
+		body := rr.Body.Bytes()
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			t.Fatalf("failed to open workbook: %v", err)
+		}
+		f, err := zr.Open("xl/worksheets/sheet1.xml")
+		if err != nil {
+			t.Fatalf("failed to open worksheet: %v", err)
+		}
+		defer f.Close()
+		var sheet struct {
+			Rows []struct{} `xml:"sheetData>row"`
+		}
+		if err := xml.NewDecoder(f).Decode(&sheet); err != nil {
+			t.Fatalf("failed to parse worksheet: %v", err)
+		}
+		if len(sheet.Rows) != 3 {
+			t.Errorf("expected a header and both active entrants, got %d rows", len(sheet.Rows))
+		}
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		if rr := exportAs(t, "xls"); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
+
+func TestAdminCheckIn(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "10K", Slug: "10k"}
+	now := time.Date(2026, time.May, 2, 8, 30, 0, 0, time.UTC)
+	entrants := []db.ListRaceEntrantsRow{
+		{ID: 1, Status: db.RegistrationStatusConfirmed, Bib: pgtype.Text{String: "101", Valid: true}, FirstName: "Jane", LastName: "Runner"},
+		{
+			ID: 2, Status: db.RegistrationStatusConfirmed, Bib: pgtype.Text{String: "7", Valid: true}, FirstName: "Ada", LastName: "Lovelace",
+			CheckedInAt: pgtype.Timestamptz{Time: now.Add(-2 * time.Minute), Valid: true},
+		},
+		{
+			ID: 3, Status: db.RegistrationStatusConfirmed, Bib: pgtype.Text{String: "12", Valid: true}, FirstName: "Sam", LastName: "Early",
+			CheckedInAt: pgtype.Timestamptz{Time: now.Add(-10 * time.Minute), Valid: true},
+		},
+		{ID: 4, Status: db.RegistrationStatusPending, FirstName: "Pat", LastName: "Unpaid"},
+		{ID: 5, Status: db.RegistrationStatusCancelled, Bib: pgtype.Text{String: "10", Valid: true}, FirstName: "Jo", LastName: "Gone"},
+	}
+
+	newApp := func(registrationSvc *servicemocks.RegistrationServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 7, Name: "Lincoln 10k"}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.clock = fixedClock(now)
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+				return race, nil
+			},
+		}
+		app.organisationService = memberOrganisationService(7, map[int64]int64{race.EventID: 7})
+		registrationSvc.ListRaceEntrantsFunc = func(ctx context.Context, raceID int64) ([]db.ListRaceEntrantsRow, error) {
+			return entrants, nil
+		}
+		app.registrationService = registrationSvc
+		return app
+	}
+
+	view := func(t *testing.T, query string, htmx bool) *httptest.ResponseRecorder {
+		t.Helper()
+		app := newApp(&servicemocks.RegistrationServiceMock{})
+		req := httptest.NewRequest(http.MethodGet, "/admin/races/20/checkin?q="+url.QueryEscape(query), http.NoBody)
+		req.SetPathValue("id", "20")
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminCheckInView).ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		return rr
+	}
+
+	listed := func(body string) []string {
+		var names []string
+		for _, name := range []string{"Jane Runner", "Ada Lovelace", "Sam Early", "Pat Unpaid", "Jo Gone"} {
+			if strings.Contains(body, name) {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	t.Run("lists confirmed entrants with the running count", func(t *testing.T) {
+		body := view(t, "", false).Body.String()
+
+		if got := listed(body); !slices.Equal(got, []string{"Jane Runner", "Ada Lovelace", "Sam Early"}) {
+			t.Errorf("expected only the confirmed entrants, got %v", got)
+		}
+		if !strings.Contains(body, "2 of 3 checked in") {
+			t.Errorf("expected the checked-in count, got:\n%s", body)
+		}
+		if !strings.Contains(body, "htmx.min.js") || !strings.Contains(body, `hx-trigger="input changed delay:300ms, search"`) {
+			t.Error("expected the search to update as it is typed")
+		}
+		if n := strings.Count(body, ">Undo<"); n != 1 {
+			t.Errorf("expected only the recent check-in to be undoable, got %d undo buttons", n)
+		}
+	})
+
+	t.Run("searches by name or bib", func(t *testing.T) {
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			{query: "ada", want: []string{"Ada Lovelace"}},
+			{query: "  RUNNER ", want: []string{"Jane Runner"}},
+			{query: "1", want: []string{"Jane Runner", "Sam Early"}},
+			{query: "7", want: []string{"Ada Lovelace"}},
+			{query: "10", want: []string{"Jane Runner"}},
+			{query: "unpaid", want: nil},
+		}
+		for _, tt := range tests {
+			body := view(t, tt.query, false).Body.String()
+			if got := listed(body); !slices.Equal(got, tt.want) {
+				t.Errorf("search %q: expected %v, got %v", tt.query, tt.want, got)
+			}
+			if !strings.Contains(body, "2 of 3 checked in") {
+				t.Errorf("search %q: expected the count of every entrant", tt.query)
+			}
+		}
+	})
+
+	t.Run("returns just the list to htmx", func(t *testing.T) {
+		rr := view(t, "ada", true)
+		body := rr.Body.String()
+
+		if strings.Contains(body, "<html") || !strings.Contains(body, `id="checkin"`) {
+			t.Errorf("expected only the list, got:\n%s", body)
+		}
+		if got := rr.Header().Values("Vary"); !slices.Contains(got, "HX-Request") {
+			t.Errorf("expected Vary: HX-Request, got %q", got)
+		}
+	})
+
+	post := func(app *application, regID string, form url.Values, htmx bool) (*httptest.ResponseRecorder, string) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/races/20/checkin/"+regID, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		req.SetPathValue("id", "20")
+		req.SetPathValue("registrationID", regID)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		var flash string
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			app.adminCheckInPost(w, r)
+			flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashError)
+		}).ServeHTTP(rr, req)
+		return rr, flash
+	}
+
+	t.Run("checks an entrant in", func(t *testing.T) {
+		var gotRace, gotReg int64
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			CheckInFunc: func(ctx context.Context, raceID, registrationID int64) error {
+				gotRace, gotReg = raceID, registrationID
+				return nil
+			},
+		})
+
+		rr, _ := post(app, "1", url.Values{"checked_in": {"true"}, "q": {"jane"}}, false)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/races/20/checkin?q=jane" {
+			t.Errorf("expected a redirect back to the search, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if gotRace != race.ID || gotReg != 1 {
+			t.Errorf("expected registration 1 of race %d checked in, got %d of %d", race.ID, gotReg, gotRace)
+		}
+	})
+
+	t.Run("undoes a check-in and redraws the list for htmx", func(t *testing.T) {
+		var undone int64
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			UndoCheckInFunc: func(ctx context.Context, raceID, registrationID int64) error {
+				undone = registrationID
+				return nil
+			},
+		})
+
+		rr, _ := post(app, "2", url.Values{"checked_in": {"false"}, "q": {"ada"}}, true)
+
+		if rr.Code != http.StatusOK || undone != 2 {
+			t.Fatalf("expected registration 2 undone, got status %d and %d", rr.Code, undone)
+		}
+		if got := listed(rr.Body.String()); !slices.Equal(got, []string{"Ada Lovelace"}) {
+			t.Errorf("expected the list for the search, got %v", got)
+		}
+	})
+
+	t.Run("explains an undo that is too late", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			UndoCheckInFunc: func(ctx context.Context, raceID, registrationID int64) error {
+				return service.ErrCheckInFinal
+			},
+		})
+
+		if _, flash := post(app, "3", url.Values{"checked_in": {"false"}}, false); flash != "Check-ins can only be undone within 5 minutes" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("404s for registrations outside the race", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			CheckInFunc: func(ctx context.Context, raceID, registrationID int64) error {
+				return repository.ErrNotFound
+			},
+		})
+
+		if rr, _ := post(app, "99", url.Values{"checked_in": {"true"}}, false); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestAccountRegistrations(t *testing.T) {
+	at := func(t time.Time) pgtype.Timestamptz { return pgtype.Timestamptz{Time: t, Valid: true} }
+	year, month, day := time.Now().Date()
+	startOfToday := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+
+	regs := []service.UserRegistration{
+		{
+			ListRegistrationsByUserRow: db.ListRegistrationsByUserRow{
+				ID: 1, RaceName: "Yesterday 10K", EventName: "Lincoln 10k", EventSlug: "lincoln-10k",
+				Status: db.RegistrationStatusConfirmed, StartsAt: at(startOfToday.Add(-time.Second)),
+			},
+		},
+		{
+			ListRegistrationsByUserRow: db.ListRegistrationsByUserRow{
+				ID: 2, RaceName: "Today Ultra", EventName: "Peak District Ultra", EventSlug: "peak-district-ultra",
+				Status: db.RegistrationStatusConfirmed, StartsAt: at(startOfToday), Bib: pgtype.Text{String: "101", Valid: true},
+				PaymentStatus: db.NullPaymentStatus{PaymentStatus: db.PaymentStatusSucceeded, Valid: true},
+			},
+			CanCancel:   true,
+			CanTransfer: true,
+			CanEdit:     true,
+		},
+		{
+			ListRegistrationsByUserRow: db.ListRegistrationsByUserRow{
+				ID: 3, RaceName: "Gifted Marathon", EventName: "Lakes Marathon", EventSlug: "lakes-marathon",
+				Status: db.RegistrationStatusConfirmed, TransferPending: true,
+			},
+		},
+	}
+
+	t.Run("groups a race starting today as upcoming", func(t *testing.T) {
+		var gotUserID int64
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.registrationService = &servicemocks.RegistrationServiceMock{
+			ListUserRegistrationsFunc: func(ctx context.Context, userID int64) ([]service.UserRegistration, error) {
+				gotUserID = userID
+				return regs, nil
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/account/registrations", http.NoBody)
+		rr := httptest.NewRecorder()
+		app.sessionManager.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			app.sessionManager.Put(r.Context(), "userID", int64(7))
+			app.accountRegistrations(w, r)
+		})).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if gotUserID != 7 {
+			t.Errorf("expected registrations for user 7, got %d", gotUserID)
+		}
+
+		body := rr.Body.String()
+		upcoming := strings.Index(body, `data-registrations="upcoming"`)
+		past := strings.Index(body, `data-registrations="past"`)
+		if upcoming < 0 || past < 0 {
+			t.Fatalf("expected upcoming and past sections, got:\n%s", body)
+		}
+		today := strings.Index(body, "Today Ultra")
+		if today < upcoming || today > past {
+			t.Error("expected a race starting today to be listed as upcoming")
+		}
+		if yesterday := strings.Index(body, "Yesterday 10K"); yesterday < past {
+			t.Error("expected a race that started yesterday to be listed as past")
+		}
+		if !strings.Contains(body, `action="/account/registrations/2/cancel"`) {
+			t.Error("expected a cancel button for the cancellable registration")
+		}
+		if strings.Contains(body, `action="/account/registrations/1/cancel"`) {
+			t.Error("expected no cancel button for a registration that can no longer be cancelled")
+		}
+		if !strings.Contains(body, "Paid") {
+			t.Error("expected payment status to be shown")
+		}
+		if !strings.Contains(body, `action="/account/registrations/2/transfer"`) {
+			t.Error("expected a transfer form for the transferable registration")
+		}
+		if !strings.Contains(body, `href="/account/registrations/2/edit"`) || strings.Contains(body, `href="/account/registrations/1/edit"`) {
+			t.Error("expected an edit link for the editable registration only")
+		}
+		if strings.Contains(body, `action="/account/registrations/3/transfer"`) {
+			t.Error("expected no transfer form for a registration awaiting acceptance")
+		}
+		if strings.Count(body, "data-transfer-pending") != 1 {
+			t.Error("expected the registration awaiting acceptance to be marked pending")
+		}
+		if strings.Count(body, "data-bib") != 1 || !strings.Contains(body, "Bib 101") {
+			t.Error("expected the assigned bib to be shown")
+		}
+	})
+
+	t.Run("renders an empty state linking to events", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/account/registrations", http.NoBody)
+		rr := httptest.NewRecorder()
+		withSession(app, app.accountRegistrations).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "data-empty-state") {
+			t.Error("expected empty state")
+		}
+	})
+
+	t.Run("returns 500 on service error", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.registrationService = &servicemocks.RegistrationServiceMock{
+			ListUserRegistrationsFunc: func(ctx context.Context, userID int64) ([]service.UserRegistration, error) {
+				return nil, errors.New("database error")
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/account/registrations", http.NoBody)
+		rr := httptest.NewRecorder()
+		withSession(app, app.accountRegistrations).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+}
+
+func TestRegisterPost(t *testing.T) {
+	race := db.Race{ID: 20, EventID: 1, Name: "10K", Slug: "10k"}
+
+	newApp := func(registrationSvc *servicemocks.RegistrationServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				if slug != "lincoln-10k" {
+					return db.Event{}, repository.ErrNotFound
+				}
+				return db.Event{ID: 1, Slug: slug, Year: year}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceFunc: func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+				if eventID != race.EventID || slug != race.Slug {
+					return service.RaceAvailability{}, repository.ErrNotFound
+				}
+				return service.RaceAvailability{Race: race}, nil
+			},
+		}
+		app.registrationService = registrationSvc
+		return app
+	}
+	post := func(app *application, eventSlug, raceSlug string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/events/2026/"+eventSlug+"/races/"+raceSlug+"/register", http.NoBody)
+		req.SetPathValue("slug", eventSlug)
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("raceSlug", raceSlug)
+		rr := httptest.NewRecorder()
+		withSession(app, app.registerPost).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("registers and shows the new entry", func(t *testing.T) {
+		var gotRace db.Race
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				gotRace = r
+				return db.Registration{ID: 100}, nil
+			},
+		})
+
+		rr := post(app, "lincoln-10k", "10k")
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/registrations#registration-100" {
+			t.Errorf("expected a redirect to the entry, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if gotRace.ID != race.ID {
+			t.Errorf("expected race %d, got %d", race.ID, gotRace.ID)
+		}
+	})
+
+	t.Run("links to the entry the user already holds", func(t *testing.T) {
+		var flash string
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				return db.Registration{ID: 55}, service.ErrAlreadyRegistered
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/events/2026/lincoln-10k/races/10k/register", http.NoBody)
+		req.SetPathValue("slug", "lincoln-10k")
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("raceSlug", "10k")
+		rr := httptest.NewRecorder()
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			app.registerPost(w, r)
+			flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashInfo)
+		}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/registrations#registration-55" {
+			t.Errorf("expected a redirect to the existing entry, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if !strings.Contains(flash, "already registered for the 10K") {
+			t.Errorf("expected a friendly message, got %q", flash)
+		}
+	})
+
+	t.Run("passes on the discount code", func(t *testing.T) {
+		var gotCode string
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				gotCode = discountCode
+				return db.Registration{ID: 100}, nil
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/events/2026/lincoln-10k/races/10k/register", strings.NewReader("discount_code=+early+"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("slug", "lincoln-10k")
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("raceSlug", "10k")
+		rr := httptest.NewRecorder()
+		withSession(app, app.registerPost).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusSeeOther || gotCode != "early" {
+			t.Errorf("expected the trimmed code to be used, got %q (status %d)", gotCode, rr.Code)
+		}
+	})
+
+	postForm := func(app *application, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/events/2026/lincoln-10k/races/10k/register", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("slug", "lincoln-10k")
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("raceSlug", "10k")
+		rr := httptest.NewRecorder()
+		withSession(app, app.registerPost).ServeHTTP(rr, req)
+		return rr
+	}
+	withQuestions := func(app *application) *application {
+		app.raceService.(*servicemocks.RaceServiceMock).RequiredQuestionsFunc = func(ctx context.Context, raceID int64) ([]service.Question, error) {
+			return []service.Question{service.QuestionEmergencyContactPhone, service.QuestionClub}, nil
+		}
+		return app
+	}
+
+	t.Run("passes on the chosen wave and keeps it while asking questions", func(t *testing.T) {
+		var gotWave int64
+		app := withQuestions(newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				gotWave = waveID
+				return db.Registration{}, service.FieldErrors{"emergency_contact_phone": "emergency contact phone is required"}
+			},
+		}))
+
+		rr := postForm(app, url.Values{"wave_id": {"6"}})
+
+		if gotWave != 6 {
+			t.Errorf("expected wave 6, got %d", gotWave)
+		}
+		if !strings.Contains(rr.Body.String(), `name="wave_id" value="6"`) {
+			t.Error("expected the wave to be carried through the questionnaire")
+		}
+	})
+
+	t.Run("returns to the event when the wave has gone", func(t *testing.T) {
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				return db.Registration{}, service.ErrWaveNotFound
+			},
+		})
+
+		rr := postForm(app, url.Values{"wave_id": {"99"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/events/2026/lincoln-10k" {
+			t.Errorf("expected a redirect to the event, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+	})
+
+	t.Run("asks the race's questions before taking the entry", func(t *testing.T) {
+		app := withQuestions(newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				return db.Registration{}, service.FieldErrors{"emergency_contact_phone": "emergency contact phone is required"}
+			},
+		}))
+
+		rr := postForm(app, url.Values{"discount_code": {"EARLY"}})
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"data-questionnaire", `name="emergency_contact_phone"`, `name="club"`, `name="discount_code" value="EARLY"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		if strings.Contains(body, `name="medical_conditions"`) || strings.Contains(body, "is required") {
+			t.Errorf("expected only the required questions and no errors, got %s", body)
+		}
+	})
+
+	t.Run("shows what is wrong with the answers", func(t *testing.T) {
+		app := withQuestions(newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				return db.Registration{}, service.FieldErrors{"emergency_contact_phone": "emergency contact phone must be a phone number"}
+			},
+		}))
+
+		rr := postForm(app, url.Values{"questionnaire": {"true"}, "emergency_contact_phone": {"not a number"}, "club": {"Harriers"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"Emergency contact phone must be a phone number", `value="Harriers"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("passes on the trimmed answers", func(t *testing.T) {
+		var got service.Answers
+		app := newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				got = answers
+				return db.Registration{ID: 100}, nil
+			},
+		})
+
+		rr := postForm(app, url.Values{"questionnaire": {"true"}, "emergency_contact_phone": {" 07700 900123 "}, "club": {"Harriers"}})
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if want := (service.Answers{EmergencyContactPhone: "07700 900123", Club: "Harriers"}); got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	withCustomFields := func(app *application) *application {
+		app.raceService.(*servicemocks.RaceServiceMock).CustomFieldsFunc = func(ctx context.Context, raceID int64) ([]service.CustomField, error) {
+			return []service.CustomField{
+				{ID: 5, Version: 1, Label: "T-shirt size", Kind: db.CustomFieldKindSelect, Options: []string{"S", "M", "L"}, Required: true},
+				{ID: 6, Version: 1, Label: "Ferry crossing", Kind: db.CustomFieldKindCheckbox},
+			}, nil
+		}
+		return app
+	}
+
+	t.Run("asks the race's custom fields before taking the entry", func(t *testing.T) {
+		registrations := &servicemocks.RegistrationServiceMock{}
+		app := withCustomFields(newApp(registrations))
+
+		rr := postForm(app, url.Values{})
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"data-questionnaire", `name="custom_5"`, `<option value="M">M</option>`, `name="custom_6"`, "Ferry crossing"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		if len(registrations.RegisterCalls()) != 0 {
+			t.Error("expected no entry made before the fields were shown")
+		}
+	})
+
+	t.Run("passes on custom answers and shows their errors", func(t *testing.T) {
+		var got map[int64]string
+		app := withCustomFields(newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				got = custom
+				return db.Registration{}, service.FieldErrors{"custom_5": "T-shirt size must be one of the choices given"}
+			},
+		}))
+
+		rr := postForm(app, url.Values{"questionnaire": {"true"}, "custom_5": {"XXL"}, "custom_6": {"1"}, "custom_x": {"ignored"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if len(got) != 2 || got[5] != "XXL" || got[6] != "1" {
+			t.Errorf("expected the custom answers passed on, got %v", got)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"T-shirt size must be one of the choices given", `name="custom_6" value="1" checked`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+	})
+
+	withMinAge := func(app *application) *application {
+		app.raceService.(*servicemocks.RaceServiceMock).GetRaceFunc = func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+			r := race
+			r.MinAge = pgtype.Int4{Int32: 18, Valid: true}
+			return service.RaceAvailability{Race: r}, nil
+		}
+		return app
+	}
+
+	t.Run("asks for a date of birth for races with a minimum age", func(t *testing.T) {
+		app := withMinAge(newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				return db.Registration{}, service.FieldErrors{"date_of_birth": "date of birth is required to enter this race"}
+			},
+		}))
+
+		rr := postForm(app, url.Values{})
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"data-questionnaire", "data-min-age", `name="date_of_birth"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("keeps a newly given date of birth on the account", func(t *testing.T) {
+		var got service.ProfileInput
+		app := withMinAge(newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				return db.Registration{ID: 100}, nil
+			},
+		}))
+		app.userService = &servicemocks.UserServiceMock{
+			UpdateProfileFunc: func(ctx context.Context, userID int64, input service.ProfileInput) (db.User, error) {
+				got = input
+				return db.User{ID: userID}, nil
+			},
+		}
+
+		rr := postForm(app, url.Values{"questionnaire": {"true"}, "date_of_birth": {"1990-04-02"}})
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if want := time.Date(1990, 4, 2, 0, 0, 0, 0, time.UTC); !got.DateOfBirth.Equal(want) {
+			t.Errorf("expected date of birth %v, got %v", want, got.DateOfBirth)
+		}
+	})
+
+	t.Run("turns away entrants under the minimum age", func(t *testing.T) {
+		var flash string
+		app := withMinAge(newApp(&servicemocks.RegistrationServiceMock{
+			RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+				return db.Registration{}, fmt.Errorf("%w: entrants must be 18 or over on race day", service.ErrTooYoung)
+			},
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/events/2026/lincoln-10k/races/10k/register", http.NoBody)
+		req.SetPathValue("slug", "lincoln-10k")
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("raceSlug", "10k")
+		rr := httptest.NewRecorder()
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			app.registerPost(w, r)
+			flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashError)
+		}).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/events/2026/lincoln-10k" {
+			t.Errorf("expected a redirect to the event, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if want := "Sorry, the 10K is only open to entrants aged 18 and over on race day"; flash != want {
+			t.Errorf("expected %q, got %q", want, flash)
+		}
+	})
+
+	discountTests := []struct {
+		err  error
+		want string
+	}{
+		{service.ErrDiscountCodeNotFound, "We don't recognise the discount code EARLY"},
+		{service.ErrDiscountCodeWrongRace, "The discount code EARLY can't be used for the 10K"},
+		{service.ErrDiscountCodeNotYetValid, "The discount code EARLY can't be used yet"},
+		{service.ErrDiscountCodeExpired, "The discount code EARLY has expired"},
+		{service.ErrDiscountCodeExhausted, "The discount code EARLY has been used up"},
+	}
+	for _, tt := range discountTests {
+		t.Run("explains "+tt.err.Error(), func(t *testing.T) {
+			var flash string
+			app := newApp(&servicemocks.RegistrationServiceMock{
+				RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+					return db.Registration{}, tt.err
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/events/2026/lincoln-10k/races/10k/register", strings.NewReader("discount_code=EARLY"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("slug", "lincoln-10k")
+			req.SetPathValue("year", "2026")
+			req.SetPathValue("raceSlug", "10k")
+			rr := httptest.NewRecorder()
+			withSession(app, func(w http.ResponseWriter, r *http.Request) {
+				app.registerPost(w, r)
+				flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashError)
+			}).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/events/2026/lincoln-10k" {
+				t.Errorf("expected a redirect to the event, got %d to %q", rr.Code, rr.Header().Get("Location"))
+			}
+			if flash != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, flash)
+			}
+		})
+	}
+
+	tests := []struct {
+		name     string
+		event    string
+		race     string
+		err      error
+		want     int
+		location string
+	}{
+		{name: "links to the account page when the entry is unknown", event: "lincoln-10k", race: "10k", err: service.ErrAlreadyRegistered, want: http.StatusSeeOther, location: "/account/registrations"},
+		{name: "returns to the event when registration is closed", event: "lincoln-10k", race: "10k", err: service.ErrRegistrationClosed, want: http.StatusSeeOther, location: "/events/2026/lincoln-10k"},
+		{name: "returns to the event when the race is full", event: "lincoln-10k", race: "10k", err: service.ErrRaceFull, want: http.StatusSeeOther, location: "/events/2026/lincoln-10k"},
+		{name: "returns to the event until the email is verified", event: "lincoln-10k", race: "10k", err: service.ErrEmailNotVerified, want: http.StatusSeeOther, location: "/events/2026/lincoln-10k"},
+		{name: "returns 404 for an unknown event", event: "nope", race: "10k", want: http.StatusNotFound},
+		{name: "returns 404 for an unknown race", event: "lincoln-10k", race: "nope", want: http.StatusNotFound},
+		{name: "returns 500 on service error", event: "lincoln-10k", race: "10k", err: errors.New("database error"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApp(&servicemocks.RegistrationServiceMock{
+				RegisterFunc: func(ctx context.Context, userID int64, r db.Race, waveID int64, discountCode string, answers service.Answers, custom map[int64]string) (db.Registration, error) {
+					return db.Registration{}, tt.err
+				},
+			})
+
+			rr := post(app, tt.event, tt.race)
+
+			if rr.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rr.Code)
+			}
+			if got := rr.Header().Get("Location"); got != tt.location {
+				t.Errorf("expected a redirect to %q, got %q", tt.location, got)
+			}
+		})
+	}
+}
+
+func TestCancelRegistrationPost(t *testing.T) {
+	newCancelRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/account/registrations/"+id+"/cancel", http.NoBody)
+		req.SetPathValue("id", id)
+		return req
+	}
+
+	t.Run("cancels and redirects with a flash", func(t *testing.T) {
+		var gotID int64
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.registrationService = &servicemocks.RegistrationServiceMock{
+			CancelRegistrationFunc: func(ctx context.Context, userID, registrationID int64) (service.Cancellation, error) {
+				gotID = registrationID
+				return service.Cancellation{RegistrationID: registrationID, RefundUnits: 6500}, nil
+			},
+		}
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.cancelRegistrationPost).ServeHTTP(rr, newCancelRequest("42"))
+
+		if rr.Code != http.StatusSeeOther {
+			t.Errorf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if gotID != 42 {
+			t.Errorf("expected registration 42, got %d", gotID)
+		}
+	})
+
+	tests := []struct {
+		name string
+		id   string
+		err  error
+		want int
+	}{
+		{name: "returns 400 for non-numeric id", id: "abc", want: http.StatusBadRequest},
+		{name: "returns 400 for zero id", id: "0", want: http.StatusBadRequest},
+		{name: "returns 404 for unknown registration", id: "42", err: repository.ErrNotFound, want: http.StatusNotFound},
+		{name: "returns 403 for someone else's registration", id: "42", err: service.ErrForbidden, want: http.StatusForbidden},
+		{name: "redirects when the deadline has passed", id: "42", err: service.ErrCancellationClosed, want: http.StatusSeeOther},
+		{name: "redirects when already cancelled", id: "42", err: service.ErrAlreadyCancelled, want: http.StatusSeeOther},
+		{name: "returns 500 on service error", id: "42", err: errors.New("database error"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			app.registrationService = &servicemocks.RegistrationServiceMock{
+				CancelRegistrationFunc: func(ctx context.Context, userID, registrationID int64) (service.Cancellation, error) {
+					return service.Cancellation{}, tt.err
+				},
+			}
+
+			rr := httptest.NewRecorder()
+			withSession(app, app.cancelRegistrationPost).ServeHTTP(rr, newCancelRequest(tt.id))
+
+			if rr.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
+	}
+}
+
+func TestTransferRegistrationPost(t *testing.T) {
+	newTransferRequest := func(id, email string) *http.Request {
+		form := url.Values{"email": {email}}
+		req := httptest.NewRequest(http.MethodPost, "/account/registrations/"+id+"/transfer", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", id)
+		return req
+	}
+
+	t.Run("transfers and redirects with a flash", func(t *testing.T) {
+		var gotID int64
+		var gotEmail string
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.registrationService = &servicemocks.RegistrationServiceMock{
+			TransferRegistrationFunc: func(ctx context.Context, ownerUserID, registrationID int64, recipientEmail string) (service.Transfer, error) {
+				gotID, gotEmail = registrationID, recipientEmail
+				return service.Transfer{RegistrationID: registrationID, RecipientEmail: "sam@example.com", Invited: true}, nil
+			},
+		}
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.transferRegistrationPost).ServeHTTP(rr, newTransferRequest("42", "sam@example.com"))
+
+		if rr.Code != http.StatusSeeOther {
+			t.Errorf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if rr.Header().Get("Location") != "/account/registrations" {
+			t.Errorf("expected redirect to /account/registrations, got %q", rr.Header().Get("Location"))
+		}
+		if gotID != 42 || gotEmail != "sam@example.com" {
+			t.Errorf("expected registration 42 to sam@example.com, got %d to %q", gotID, gotEmail)
+		}
+	})
+
+	tests := []struct {
+		name string
+		id   string
+		err  error
+		want int
+	}{
+		{name: "returns 400 for non-numeric id", id: "abc", want: http.StatusBadRequest},
+		{name: "returns 404 for unknown registration", id: "42", err: repository.ErrNotFound, want: http.StatusNotFound},
+		{name: "returns 403 for someone else's registration", id: "42", err: service.ErrForbidden, want: http.StatusForbidden},
+		{name: "redirects when the cutoff has passed", id: "42", err: service.ErrTransferClosed, want: http.StatusSeeOther},
+		{name: "redirects for an invalid email", id: "42", err: service.ErrInvalidInput, want: http.StatusSeeOther},
+		{name: "redirects when the recipient is already registered", id: "42", err: service.ErrRecipientRegistered, want: http.StatusSeeOther},
+		{name: "redirects when already cancelled", id: "42", err: service.ErrAlreadyCancelled, want: http.StatusSeeOther},
+		{name: "returns 500 on service error", id: "42", err: errors.New("database error"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			app.registrationService = &servicemocks.RegistrationServiceMock{
+				TransferRegistrationFunc: func(ctx context.Context, ownerUserID, registrationID int64, recipientEmail string) (service.Transfer, error) {
+					return service.Transfer{}, tt.err
+				},
+			}
+
+			rr := httptest.NewRecorder()
+			withSession(app, app.transferRegistrationPost).ServeHTTP(rr, newTransferRequest(tt.id, "sam@example.com"))
+
+			if rr.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
+	}
+}
+
+func TestRegistrationDetails(t *testing.T) {
+	entrant := db.User{ID: 7, Role: db.UserRoleEntrant}
+	details := service.RegistrationDetails{
+		RegistrationID: 42,
+		RaceName:       "Ultra 50K",
+		EventName:      "Peak District Ultra",
+		Answers:        service.Answers{EmergencyContactName: "Alex Hill", Club: "Dark Peak Fell Runners"},
+		Questions:      []service.Question{service.QuestionEmergencyContactName, service.QuestionClub},
+		Required:       []service.Question{service.QuestionEmergencyContactName},
+		Locked:         []service.Question{service.QuestionClub},
+		EditableUntil:  time.Date(2026, 6, 6, 9, 0, 0, 0, time.UTC),
+	}
+
+	// newApp serves entrant 7 their registration 42 and nobody else's
+	newApp := func(update func(answers service.Answers) error) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.registrationService = &servicemocks.RegistrationServiceMock{
+			RegistrationDetailsFunc: func(ctx context.Context, userID, registrationID int64) (service.RegistrationDetails, error) {
+				if userID != entrant.ID || registrationID != details.RegistrationID {
+					return service.RegistrationDetails{}, repository.ErrNotFound
+				}
+				return details, nil
+			},
+			UpdateRegistrationDetailsFunc: func(ctx context.Context, userID, registrationID int64, answers service.Answers) error {
+				if userID != entrant.ID || registrationID != details.RegistrationID {
+					return repository.ErrNotFound
+				}
+				return update(answers)
+			},
+		}
+		return app
+	}
+	serve := func(app *application, h http.HandlerFunc, method, id string, user db.User, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/account/registrations/"+id+"/edit", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, user))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("shows the answers with locked ones read-only", func(t *testing.T) {
+		app := newApp(nil)
+
+		rr := serve(app, app.registrationDetailsView, http.MethodGet, "42", entrant, nil)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{`value="Alex Hill"`, "Sat 6 June 2026", "fix this once your entry is paid for"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+		club := body[strings.Index(body, `name="club"`):]
+		if !strings.Contains(club[:strings.Index(club, ">")], "readonly") {
+			t.Error("expected the club to be read-only")
+		}
+	})
+
+	t.Run("saves the answers posted", func(t *testing.T) {
+		var got service.Answers
+		app := newApp(func(answers service.Answers) error {
+			got = answers
+			return nil
+		})
+
+		rr := serve(app, app.registrationDetailsPost, http.MethodPost, "42", entrant, url.Values{
+			"emergency_contact_name": {" Sam Hill "},
+			"club":                   {"Dark Peak Fell Runners"},
+		})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/registrations#registration-42" {
+			t.Fatalf("expected a redirect to the registration, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if got.EmergencyContactName != "Sam Hill" || got.Club != "Dark Peak Fell Runners" {
+			t.Errorf("expected the trimmed answers, got %+v", got)
+		}
+	})
+
+	t.Run("shows the form again for invalid answers", func(t *testing.T) {
+		app := newApp(func(answers service.Answers) error {
+			return service.FieldErrors{"club": "club can no longer be changed, as your entry is paid for"}
+		})
+
+		rr := serve(app, app.registrationDetailsPost, http.MethodPost, "42", entrant, url.Values{
+			"emergency_contact_name": {"Sam Hill"},
+			"club":                   {"Totley AC"},
+		})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "Club can no longer be changed") || !strings.Contains(body, `value="Sam Hill"`) {
+			t.Error("expected the error with the answers posted")
+		}
+		if strings.Contains(body, "Totley AC") || !strings.Contains(body, `value="Dark Peak Fell Runners"`) {
+			t.Error("expected the locked club to show what is stored")
+		}
+	})
+
+	t.Run("returns 404 for someone else's registration", func(t *testing.T) {
+		app := newApp(func(answers service.Answers) error {
+			t.Error("expected nothing to be saved")
+			return nil
+		})
+		other := db.User{ID: 8, Role: db.UserRoleEntrant}
+
+		if rr := serve(app, app.registrationDetailsView, http.MethodGet, "42", other, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d viewing, got %d", http.StatusNotFound, rr.Code)
+		}
+		if rr := serve(app, app.registrationDetailsPost, http.MethodPost, "42", other, url.Values{"club": {"Totley AC"}}); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d saving, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("redirects with a flash once the race is too close", func(t *testing.T) {
+		app := newApp(nil)
+		app.registrationService.(*servicemocks.RegistrationServiceMock).RegistrationDetailsFunc = func(ctx context.Context, userID, registrationID int64) (service.RegistrationDetails, error) {
+			return service.RegistrationDetails{}, service.ErrEditClosed
+		}
+
+		rr := serve(app, app.registrationDetailsView, http.MethodGet, "42", entrant, nil)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/registrations#registration-42" {
+			t.Errorf("expected a redirect to the registration, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+	})
+
+	t.Run("returns 400 for a non-numeric id", func(t *testing.T) {
+		app := newApp(nil)
+
+		if rr := serve(app, app.registrationDetailsView, http.MethodGet, "abc", entrant, nil); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
+
+func TestAcceptTransfers(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		want     int
+		location string
+	}{
+		{name: "redirects to sign in after accepting", want: http.StatusSeeOther, location: "/auth/sign-in"},
+		{name: "redirects home for an invalid token", err: service.ErrInvalidToken, want: http.StatusSeeOther, location: "/"},
+		{name: "returns 500 on service error", err: errors.New("database error"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotToken string
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			app.registrationService = &servicemocks.RegistrationServiceMock{
+				AcceptTransfersFunc: func(ctx context.Context, token string) error {
+					gotToken = token
+					return tt.err
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/transfers/accept?token=abc123", http.NoBody)
+			rr := httptest.NewRecorder()
+			withSession(app, app.acceptTransfers).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rr.Code)
+			}
+			if gotToken != "abc123" {
+				t.Errorf("expected token abc123, got %q", gotToken)
+			}
+			if tt.location != "" && rr.Header().Get("Location") != tt.location {
+				t.Errorf("expected redirect to %s, got %q", tt.location, rr.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "redirects home after unsubscribing", want: http.StatusSeeOther},
+		{name: "redirects home for an invalid token", err: service.ErrInvalidToken, want: http.StatusSeeOther},
+		{name: "returns 500 on service error", err: errors.New("database error"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotToken string
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			app.authService = &servicemocks.AuthServiceMock{
+				UnsubscribeFunc: func(ctx context.Context, token string) error {
+					gotToken = token
+					return tt.err
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/email/unsubscribe?token=abc123", http.NoBody)
+			rr := httptest.NewRecorder()
+			withSession(app, app.unsubscribe).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rr.Code)
+			}
+			if gotToken != "abc123" {
+				t.Errorf("expected token abc123, got %q", gotToken)
+			}
+			if tt.want == http.StatusSeeOther && rr.Header().Get("Location") != "/" {
+				t.Errorf("expected redirect to /, got %q", rr.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestAcceptInvitations(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		want     int
+		location string
+	}{
+		{name: "redirects to sign in after accepting", want: http.StatusSeeOther, location: "/auth/sign-in"},
+		{name: "redirects home for an invalid token", err: service.ErrInvalidToken, want: http.StatusSeeOther, location: "/"},
+		{name: "returns 500 on service error", err: errors.New("database error"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotToken string
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			app.organisationService = &servicemocks.OrganisationServiceMock{
+				AcceptInvitationsFunc: func(ctx context.Context, token string) error {
+					gotToken = token
+					return tt.err
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/organisations/invitations/accept?token=abc123", http.NoBody)
+			rr := httptest.NewRecorder()
+			withSession(app, app.acceptInvitations).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rr.Code)
+			}
+			if gotToken != "abc123" {
+				t.Errorf("expected token abc123, got %q", gotToken)
+			}
+			if tt.location != "" && rr.Header().Get("Location") != tt.location {
+				t.Errorf("expected redirect to %s, got %q", tt.location, rr.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestAdminMembers(t *testing.T) {
+	owner := db.User{ID: 2, Role: db.UserRoleOrganizer}
+	const orgID int64 = 7
+
+	// The owner can manage organisation 7's members and no other's
+	newApp := func(orgSvc *servicemocks.OrganisationServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		orgSvc.CanManageMembersFunc = func(ctx context.Context, userID, organisationID int64) (bool, error) {
+			return userID == owner.ID && organisationID == orgID, nil
+		}
+		orgSvc.GetOrganisationFunc = func(ctx context.Context, id int64) (db.Organisation, error) {
+			return db.Organisation{ID: id, Name: "Peak Running Co"}, nil
+		}
+		app.organisationService = orgSvc
+		return app
+	}
+	serve := func(app *application, h http.HandlerFunc, method, target string, form url.Values, pathValues ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for i := 0; i < len(pathValues); i += 2 {
+			req.SetPathValue(pathValues[i], pathValues[i+1])
+		}
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, owner))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("lists members and pending invitations", func(t *testing.T) {
+		app := newApp(&servicemocks.OrganisationServiceMock{
+			ListMembersFunc: func(ctx context.Context, organisationID int64) ([]db.ListOrganisationMembersRow, error) {
+				return []db.ListOrganisationMembersRow{
+					{UserID: 2, Role: db.OrganisationRoleOwner, Email: "jane@example.com", FirstName: "Jane", LastName: "Runner"},
+					{UserID: 4, Role: db.OrganisationRoleStaff, Email: "sam@example.com"},
+				}, nil
+			},
+			ListPendingInvitationsFunc: func(ctx context.Context, organisationID int64) ([]db.ListPendingOrganisationInvitationsRow, error) {
+				return []db.ListPendingOrganisationInvitationsRow{{Email: "alex@example.com", Role: db.OrganisationRoleAdmin}}, nil
+			},
+		})
+
+		rr := serve(app, app.adminMembersView, http.MethodGet, "/admin/organisations/7/members", nil, "id", "7")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"Jane Runner", `data-member="sam@example.com"`, "alex@example.com", `action="/admin/organisations/7/members/4/remove"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected page to contain %q", want)
+			}
+		}
+		if strings.Contains(body, `action="/admin/organisations/7/members/2/remove"`) {
+			t.Error("expected no remove button for the owner")
+		}
+	})
+
+	t.Run("returns 404 for another organisation", func(t *testing.T) {
+		app := newApp(&servicemocks.OrganisationServiceMock{})
+
+		rr := serve(app, app.adminMembersView, http.MethodGet, "/admin/organisations/8/members", nil, "id", "8")
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("invites a member and redirects with a flash", func(t *testing.T) {
+		var gotEmail string
+		var gotRole db.OrganisationRole
+		app := newApp(&servicemocks.OrganisationServiceMock{
+			InviteMemberFunc: func(ctx context.Context, inviterID, organisationID int64, email string, role db.OrganisationRole) (service.Invite, error) {
+				gotEmail, gotRole = email, role
+				return service.Invite{Email: "sam@example.com", Role: role}, nil
+			},
+		})
+
+		rr := serve(app, app.adminInviteMemberPost, http.MethodPost, "/admin/organisations/7/members",
+			url.Values{"email": {"sam@example.com"}, "role": {"admin"}}, "id", "7")
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != "/admin/organisations/7/members" {
+			t.Errorf("expected redirect to the members page, got %q", loc)
+		}
+		if gotEmail != "sam@example.com" || gotRole != db.OrganisationRoleAdmin {
+			t.Errorf("expected an admin invitation for sam@example.com, got %q as %q", gotEmail, gotRole)
+		}
+	})
+
+	t.Run("re-renders with an error for an existing member", func(t *testing.T) {
+		app := newApp(&servicemocks.OrganisationServiceMock{
+			InviteMemberFunc: func(ctx context.Context, inviterID, organisationID int64, email string, role db.OrganisationRole) (service.Invite, error) {
+				return service.Invite{}, service.ErrAlreadyMember
+			},
+		})
+
+		rr := serve(app, app.adminInviteMemberPost, http.MethodPost, "/admin/organisations/7/members",
+			url.Values{"email": {"sam@example.com"}, "role": {"staff"}}, "id", "7")
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "This person is already a member") {
+			t.Error("expected member error in response body")
+		}
+	})
+
+	t.Run("removes a member", func(t *testing.T) {
+		var gotUserID int64
+		app := newApp(&servicemocks.OrganisationServiceMock{
+			RemoveMemberFunc: func(ctx context.Context, removerID, organisationID, userID int64) error {
+				gotUserID = userID
+				return nil
+			},
+		})
+
+		rr := serve(app, app.adminRemoveMemberPost, http.MethodPost, "/admin/organisations/7/members/4/remove", nil, "id", "7", "userID", "4")
+
+		if rr.Code != http.StatusSeeOther {
+			t.Errorf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if gotUserID != 4 {
+			t.Errorf("expected user 4 to be removed, got %d", gotUserID)
+		}
+	})
+
+	t.Run("does not remove members of another organisation", func(t *testing.T) {
+		app := newApp(&servicemocks.OrganisationServiceMock{
+			RemoveMemberFunc: func(ctx context.Context, removerID, organisationID, userID int64) error {
+				t.Error("expected the service not to be called")
+				return nil
+			},
+		})
+
+		rr := serve(app, app.adminRemoveMemberPost, http.MethodPost, "/admin/organisations/8/members/4/remove", nil, "id", "8", "userID", "4")
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("sets the contact address", func(t *testing.T) {
+		app := newApp(&servicemocks.OrganisationServiceMock{})
+		var gotEmail string
+		app.contactService = &servicemocks.ContactServiceMock{
+			SetContactEmailFunc: func(ctx context.Context, organisationID int64, email string) error {
+				gotEmail = email
+				return nil
+			},
+		}
+
+		rr := serve(app, app.adminContactEmailPost, http.MethodPost, "/admin/organisations/7/contact", url.Values{"contact_email": {"races@peak.example"}}, "id", "7")
+
+		if rr.Code != http.StatusSeeOther || gotEmail != "races@peak.example" {
+			t.Errorf("expected the address saved and a redirect, got %d and %q", rr.Code, gotEmail)
+		}
+	})
+
+	t.Run("shows a contact address that will not do", func(t *testing.T) {
+		app := newApp(&servicemocks.OrganisationServiceMock{})
+		app.contactService = &servicemocks.ContactServiceMock{
+			SetContactEmailFunc: func(ctx context.Context, organisationID int64, email string) error {
+				return service.FieldErrors{"contact_email": "email must be a valid email address"}
+			},
+		}
+
+		rr := serve(app, app.adminContactEmailPost, http.MethodPost, "/admin/organisations/7/contact", url.Values{"contact_email": {"races"}}, "id", "7")
+
+		if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), `value="races"`) {
+			t.Errorf("expected the form shown again with the address, got %d", rr.Code)
+		}
+	})
+
+	t.Run("sets which websites may show the availability widget", func(t *testing.T) {
+		var gotPublic bool
+		var gotOrigins []string
+		app := newApp(&servicemocks.OrganisationServiceMock{
+			SetEmbeddingFunc: func(ctx context.Context, organisationID int64, public bool, origins []string) error {
+				gotPublic, gotOrigins = public, origins
+				return nil
+			},
+		})
+
+		form := url.Values{"embed_origins": {"https://peak.example\r\nhttps://club.example"}, "embed_public": {"true"}}
+		rr := serve(app, app.adminEmbeddingPost, http.MethodPost, "/admin/organisations/7/embedding", form, "id", "7")
+
+		if rr.Code != http.StatusSeeOther {
+			t.Errorf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if !gotPublic || len(gotOrigins) != 2 || strings.TrimSpace(gotOrigins[0]) != "https://peak.example" || gotOrigins[1] != "https://club.example" {
+			t.Errorf("expected each line passed on, got public %v and %q", gotPublic, gotOrigins)
+		}
+	})
+
+	t.Run("shows websites that will not do", func(t *testing.T) {
+		app := newApp(&servicemocks.OrganisationServiceMock{
+			SetEmbeddingFunc: func(ctx context.Context, organisationID int64, public bool, origins []string) error {
+				return service.FieldErrors{"embed_origins": `"peak" is not a website address such as https://club.example`}
+			},
+		})
+
+		rr := serve(app, app.adminEmbeddingPost, http.MethodPost, "/admin/organisations/7/embedding", url.Values{"embed_origins": {"peak"}}, "id", "7")
+
+		if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), ">peak</textarea>") {
+			t.Errorf("expected the form shown again with the websites, got %d", rr.Code)
+		}
+	})
+}
+
+func TestAdminBranding(t *testing.T) {
+	owner := db.User{ID: 2, Role: db.UserRoleOrganizer}
+	const orgID int64 = 7
+
+	// The owner can manage organisation 7 and no other
+	newApp := func(branding *servicemocks.BrandingServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			CanManageMembersFunc: func(ctx context.Context, userID, organisationID int64) (bool, error) {
+				return userID == owner.ID && organisationID == orgID, nil
+			},
+			GetOrganisationFunc: func(ctx context.Context, id int64) (db.Organisation, error) {
+				return db.Organisation{ID: id, Name: "Peak Running Co", LogoKey: pgtype.Text{String: "organisations/7/logo-a.png", Valid: true}}, nil
+			},
+		}
+		app.brandingService = branding
+		return app
+	}
+	serve := func(app *application, h http.HandlerFunc, req *http.Request, id string) *httptest.ResponseRecorder {
+		req.SetPathValue("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, owner))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+	post := func(app *application, id string, fields map[string]string, logo string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for name, value := range fields {
+			mw.WriteField(name, value)
+		}
+		fw, err := mw.CreateFormFile("logo", logo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(fw, "logo bytes")
+		// A file input left empty
+		if _, err := mw.CreateFormFile("banner", ""); err != nil {
+			t.Fatal(err)
+		}
+		mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/organisations/"+id+"/branding", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return serve(app, app.adminBrandingPost, req, id)
+	}
+
+	t.Run("shows the form with a preview", func(t *testing.T) {
+		app := newApp(&servicemocks.BrandingServiceMock{})
+
+		rr := serve(app, app.adminBrandingView, httptest.NewRequest(http.MethodGet, "/admin/organisations/7/branding", http.NoBody), "7")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{`action="/admin/organisations/7/branding"`, `enctype="multipart/form-data"`, "data-branding-preview", `src="/organisations/7/logo"`, `name="remove_logo"`, `value="#16a34a"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected page to contain %q", want)
+			}
+		}
+		if strings.Contains(body, `name="remove_banner"`) {
+			t.Error("expected no option to remove a banner that is not set")
+		}
+	})
+
+	t.Run("returns 404 for another organisation", func(t *testing.T) {
+		app := newApp(&servicemocks.BrandingServiceMock{})
+
+		rr := serve(app, app.adminBrandingView, httptest.NewRequest(http.MethodGet, "/admin/organisations/8/branding", http.NoBody), "8")
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("saves the branding and redirects", func(t *testing.T) {
+		var got service.BrandingInput
+		var gotLogo string
+		app := newApp(&servicemocks.BrandingServiceMock{
+			SetBrandingFunc: func(ctx context.Context, organisationID int64, input service.BrandingInput) (db.Organisation, error) {
+				got = input
+				if input.Logo != nil {
+					b, _ := io.ReadAll(input.Logo)
+					gotLogo = string(b)
+				}
+				return db.Organisation{ID: organisationID}, nil
+			},
+		})
+
+		rr := post(app, "7", map[string]string{"brand_colour": "#1A2B3C", "remove_banner": "1"}, "logo.png")
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusSeeOther, rr.Code, rr.Body.String())
+		}
+		if got := rr.Header().Get("Location"); got != "/admin/organisations/7/branding" {
+			t.Errorf("unexpected redirect to %q", got)
+		}
+		if got.Colour != "#1A2B3C" || !got.RemoveBanner || got.RemoveLogo {
+			t.Errorf("unexpected input %+v", got)
+		}
+		if gotLogo != "logo bytes" || got.Banner != nil {
+			t.Errorf("expected the logo alone, got logo %q and banner %v", gotLogo, got.Banner)
+		}
+	})
+
+	t.Run("re-renders the form with the service's errors", func(t *testing.T) {
+		app := newApp(&servicemocks.BrandingServiceMock{
+			SetBrandingFunc: func(ctx context.Context, organisationID int64, input service.BrandingInput) (db.Organisation, error) {
+				return db.Organisation{}, service.FieldErrors{"brand_colour": "colour must be a hex colour such as #1a2b3c"}
+			},
+		})
+
+		rr := post(app, "7", map[string]string{"brand_colour": "red; background:url(x)"}, "logo.png")
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "Colour must be a hex colour such as #1a2b3c") {
+			t.Error("expected the colour error to be shown")
+		}
+		if strings.Contains(body, "--color-primary: red") {
+			t.Error("expected the refused colour not to reach the preview")
+		}
+	})
+}
+
+func TestOrganisationBrandingImages(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+	app.organisationService = &servicemocks.OrganisationServiceMock{
+		GetOrganisationFunc: func(ctx context.Context, id int64) (db.Organisation, error) {
+			if id != 7 {
+				return db.Organisation{}, repository.ErrNotFound
+			}
+			return db.Organisation{ID: id, LogoKey: pgtype.Text{String: "organisations/7/logo-a.png", Valid: true}}, nil
+		},
+	}
+	app.brandingService = &servicemocks.BrandingServiceMock{
+		BrandingImageURLFunc: func(ctx context.Context, key string) (string, error) {
+			return "https://files.example/" + key + "?X-Amz-Signature=x", nil
+		},
+	}
+	get := func(h http.HandlerFunc, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/organisations/"+id+"/logo", http.NoBody)
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		h(rr, req)
+		return rr
+	}
+
+	t.Run("redirects to a signed URL", func(t *testing.T) {
+		rr := get(app.organisationLogo, "7")
+
+		if rr.Code != http.StatusFound {
+			t.Fatalf("expected status %d, got %d", http.StatusFound, rr.Code)
+		}
+		if got := rr.Header().Get("Location"); got != "https://files.example/organisations/7/logo-a.png?X-Amz-Signature=x" {
+			t.Errorf("unexpected location %q", got)
+		}
+		if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("expected the redirect not to be cached, got %q", got)
+		}
+	})
+
+	t.Run("returns 404 without an image", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			h    http.HandlerFunc
+			id   string
+		}{
+			{"banner not set", app.organisationBanner, "7"},
+			{"unknown organisation", app.organisationLogo, "8"},
+			{"bad id", app.organisationLogo, "logo"},
+		} {
+			if rr := get(tc.h, tc.id); rr.Code != http.StatusNotFound {
+				t.Errorf("%s: expected status %d, got %d", tc.name, http.StatusNotFound, rr.Code)
+			}
+		}
+	})
+}
+
+func TestAdminWebhooks(t *testing.T) {
+	owner := db.User{ID: 2, Role: db.UserRoleOrganizer}
+	const orgID int64 = 7
+	endpoint := db.WebhookEndpoint{
+		ID:             3,
+		OrganisationID: orgID,
+		Url:            "https://crm.example/hooks",
+		EventTypes:     []string{"registration.created"},
+		CreatedAt:      pgtype.Timestamptz{Time: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC), Valid: true},
+	}
+
+	// The owner can manage organisation 7 and no other
+	newApp := func(webhooks *servicemocks.WebhookServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.organisationService = &servicemocks.OrganisationServiceMock{
+			CanManageMembersFunc: func(ctx context.Context, userID, organisationID int64) (bool, error) {
+				return userID == owner.ID && organisationID == orgID, nil
+			},
+			GetOrganisationFunc: func(ctx context.Context, id int64) (db.Organisation, error) {
+				return db.Organisation{ID: id, Name: "Peak Running Co"}, nil
+			},
+		}
+		webhooks.ListEndpointsFunc = func(ctx context.Context, organisationID int64) ([]db.WebhookEndpoint, error) {
+			return []db.WebhookEndpoint{endpoint}, nil
+		}
+		app.webhookService = webhooks
+		return app
+	}
+	serve := func(app *application, h http.HandlerFunc, method, target string, form url.Values, pathValues ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for i := 0; i < len(pathValues); i += 2 {
+			req.SetPathValue(pathValues[i], pathValues[i+1])
+		}
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, owner))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("lists the organisation's endpoints", func(t *testing.T) {
+		app := newApp(&servicemocks.WebhookServiceMock{})
+
+		rr := serve(app, app.adminWebhooksView, http.MethodGet, "/admin/organisations/7/webhooks", nil, "id", "7")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{`data-webhook-endpoint="https://crm.example/hooks"`, `href="/admin/organisations/7/webhooks/3"`, `action="/admin/organisations/7/webhooks/3/delete"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("returns 404 for another organisation", func(t *testing.T) {
+		app := newApp(&servicemocks.WebhookServiceMock{})
+
+		rr := serve(app, app.adminWebhooksView, http.MethodGet, "/admin/organisations/8/webhooks", nil, "id", "8")
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("shows the new endpoint's secret once", func(t *testing.T) {
+		var got service.WebhookEndpointInput
+		app := newApp(&servicemocks.WebhookServiceMock{
+			CreateEndpointFunc: func(ctx context.Context, organisationID int64, input service.WebhookEndpointInput) (service.CreatedWebhookEndpoint, error) {
+				got = input
+				return service.CreatedWebhookEndpoint{Endpoint: endpoint, Secret: "SIGNINGSECRET"}, nil
+			},
+		})
+		form := url.Values{"url": {"https://crm.example/hooks"}, "events": {"registration.created", "registration.cancelled"}}
+
+		rr := serve(app, app.adminCreateWebhookPost, http.MethodPost, "/admin/organisations/7/webhooks", form, "id", "7")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got.URL != "https://crm.example/hooks" || len(got.Events) != 2 {
+			t.Errorf("unexpected endpoint %+v", got)
+		}
+		if !strings.Contains(rr.Body.String(), "SIGNINGSECRET") {
+			t.Error("expected the secret to be shown")
+		}
+		if cc := rr.Header().Get("Cache-Control"); cc != "no-store" {
+			t.Errorf("expected the page not to be stored, got Cache-Control %q", cc)
+		}
+	})
+
+	t.Run("re-renders the form with the service's errors", func(t *testing.T) {
+		app := newApp(&servicemocks.WebhookServiceMock{
+			CreateEndpointFunc: func(ctx context.Context, organisationID int64, input service.WebhookEndpointInput) (service.CreatedWebhookEndpoint, error) {
+				return service.CreatedWebhookEndpoint{}, service.FieldErrors{"url": "enter a full http or https URL"}
+			},
+		})
+		form := url.Values{"url": {"crm.example"}, "events": {"registration.created"}}
+
+		rr := serve(app, app.adminCreateWebhookPost, http.MethodPost, "/admin/organisations/7/webhooks", form, "id", "7")
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "Enter a full http or https URL") || !strings.Contains(body, `value="crm.example"`) {
+			t.Error("expected the error with the submitted URL")
+		}
+	})
+
+	t.Run("deletes an endpoint", func(t *testing.T) {
+		app := newApp(&servicemocks.WebhookServiceMock{})
+
+		rr := serve(app, app.adminDeleteWebhookPost, http.MethodPost, "/admin/organisations/7/webhooks/3/delete", nil, "id", "7", "webhookID", "3")
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/organisations/7/webhooks" {
+			t.Fatalf("expected a redirect to the webhooks page, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		calls := app.webhookService.(*servicemocks.WebhookServiceMock).DeleteEndpointCalls()
+		if len(calls) != 1 || calls[0].OrganisationID != orgID || calls[0].ID != 3 {
+			t.Errorf("expected endpoint 3 of organisation 7 deleted, got %+v", calls)
+		}
+	})
+
+	t.Run("shows each delivery's attempts", func(t *testing.T) {
+		app := newApp(&servicemocks.WebhookServiceMock{
+			DeliveryLogFunc: func(ctx context.Context, organisationID, id int64) (service.WebhookDeliveryLog, error) {
+				return service.WebhookDeliveryLog{
+					Endpoint: endpoint,
+					Deliveries: []db.WebhookDelivery{{
+						ID:            11,
+						EventType:     "registration.created",
+						Status:        db.WebhookDeliveryStatusPending,
+						Attempts:      1,
+						NextAttemptAt: pgtype.Timestamptz{Time: time.Date(2026, 5, 1, 9, 1, 0, 0, time.UTC), Valid: true},
+					}},
+					Attempts: []db.WebhookDeliveryAttempt{{DeliveryID: 11, StatusCode: pgtype.Int4{Int32: 500, Valid: true}, DurationMs: 42}},
+				}, nil
+			},
+		})
+
+		rr := serve(app, app.adminWebhookLogView, http.MethodGet, "/admin/organisations/7/webhooks/3", nil, "id", "7", "webhookID", "3")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"Retrying at 2026-05-01 09:01:00", "HTTP 500", "42 ms"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("returns 404 for another organisation's endpoint", func(t *testing.T) {
+		app := newApp(&servicemocks.WebhookServiceMock{
+			DeliveryLogFunc: func(ctx context.Context, organisationID, id int64) (service.WebhookDeliveryLog, error) {
+				return service.WebhookDeliveryLog{}, repository.ErrNotFound
+			},
+		})
+
+		rr := serve(app, app.adminWebhookLogView, http.MethodGet, "/admin/organisations/7/webhooks/9", nil, "id", "7", "webhookID", "9")
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestPaymentsReport(t *testing.T) {
+	owner := db.User{ID: 2, Role: db.UserRoleOrganizer}
+	const orgID int64 = 7
+	const secret = "whsec_test"
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	// The repository keeps what the service records, as the upsert would,
+	// and reports all of it for organisation 7
+	var order []string
+	payments := map[string]db.UpsertProviderPaymentParams{}
+	var gotFrom, gotUntil time.Time
+	repo := &repositorymocks.PaymentRepositoryMock{
+		RecordProviderPaymentFunc: func(ctx context.Context, params db.UpsertProviderPaymentParams) (db.Payment, error) {
+			if params.RegistrationID > 100 {
+				return db.Payment{}, repository.ErrNotFound
+			}
+			prev, ok := payments[params.ProviderReference.String]
+			if !ok {
+				order = append(order, params.ProviderReference.String)
+			}
+			if ok && prev.RefundedUnits > params.RefundedUnits {
+				params.RefundedUnits, params.Status = prev.RefundedUnits, prev.Status
+			}
+			payments[params.ProviderReference.String] = params
+			return db.Payment{}, nil
+		},
+		ListForOrganisationFunc: func(ctx context.Context, organisationID int64, from, until time.Time) ([]db.ListOrganisationPaymentsRow, error) {
+			gotFrom, gotUntil = from, until
+			if organisationID != orgID {
+				return nil, nil
+			}
+			var rows []db.ListOrganisationPaymentsRow
+			for i, ref := range order {
+				p := payments[ref]
+				rows = append(rows, db.ListOrganisationPaymentsRow{
+					ID:                int64(i + 1),
+					RegistrationID:    p.RegistrationID,
+					AmountUnits:       p.AmountUnits,
+					Currency:          p.Currency,
+					Status:            p.Status,
+					ProviderReference: p.ProviderReference,
+					FeeUnits:          p.FeeUnits,
+					RefundedUnits:     p.RefundedUnits,
+					CreatedAt:         p.CreatedAt,
+					Email:             "jo@example.com",
+					FirstName:         "Jo",
+					LastName:          "Bloggs",
+					RaceName:          "10K",
+					EventName:         "Harbour Run",
+				})
+			}
+			return rows, nil
+		},
+	}
+
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+		GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+			return owner, nil
+		},
+	})
+	orgService := memberOrganisationService(orgID, nil)
+	orgService.CanManageMembersFunc = func(ctx context.Context, userID, organisationID int64) (bool, error) {
+		return organisationID == orgID, nil
+	}
+	orgService.GetOrganisationFunc = func(ctx context.Context, id int64) (db.Organisation, error) {
+		return db.Organisation{ID: id, Name: "Peak Running Co"}, nil
+	}
+	app.organisationService = orgService
+	app.paymentService = service.NewPaymentService(repo)
+	app.clock = fixedClock(now)
+	app.stripeWebhookSecret = secret
+	routes := app.routes()
+	cookie := signedIn(t, app, httptest.NewRequest(http.MethodGet, "/", http.NoBody), owner).Cookies()[0]
+
+	// deliver posts a fixture as Stripe would, signed with signer
+	deliver := func(t *testing.T, fixture, signer string) *httptest.ResponseRecorder {
+		t.Helper()
+		payload, err := os.ReadFile("testdata/stripe/" + fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/stripe", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(stripe.SignatureHeader, stripe.Sign(payload, signer, now))
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+		req.AddCookie(cookie)
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("rejects events with a bad signature", func(t *testing.T) {
+		if rr := deliver(t, "charge_succeeded_gbp.json", "whsec_other"); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+		if len(payments) != 0 {
+			t.Errorf("expected nothing recorded, got %v", payments)
+		}
+	})
+
+	t.Run("records charges from webhook events", func(t *testing.T) {
+		// The refund arrives twice, and a retried charge.succeeded after
+		// it, as Stripe may deliver them
+		for _, fixture := range []string{
+			"charge_succeeded_gbp.json",
+			"charge_succeeded_gbp_second.json",
+			"charge_refunded_gbp_partial.json",
+			"charge_succeeded_eur.json",
+			"charge_refunded_gbp_partial.json",
+			"charge_succeeded_gbp.json",
+			"charge_succeeded_unmatched.json",
+		} {
+			if rr := deliver(t, fixture, secret); rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d for %s, got %d", http.StatusOK, fixture, rr.Code)
+			}
+		}
+
+		if len(payments) != 3 {
+			t.Fatalf("expected 3 payments, got %d", len(payments))
+		}
+		refunded := payments["ch_3Q0gbp1"]
+		if refunded.Status != db.PaymentStatusPartiallyRefunded || refunded.RefundedUnits != 3250 || refunded.FeeUnits.Int32 != 120 || refunded.Currency != "GBP" {
+			t.Errorf("unexpected partially refunded payment: %+v", refunded)
+		}
+		if eur := payments["ch_3Q0eur1"]; eur.FeeUnits.Valid {
+			t.Errorf("expected no fee without an expanded balance transaction, got %+v", eur.FeeUnits)
+		}
+	})
+
+	t.Run("totals each currency in the report's footer", func(t *testing.T) {
+		rr := get("/admin/organisations/7/payments")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if want := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC); !gotFrom.Equal(want) || !gotUntil.Equal(want.AddDate(0, 1, 0)) {
+			t.Errorf("expected the current month, got %v to %v", gotFrom, gotUntil)
+		}
+		body := rr.Body.String()
+		footer := body[strings.Index(body, "<tfoot"):]
+		for _, want := range []string{
+			`<tr data-payment-totals="EUR">`,
+			`data-total="gross">€25.00<`, `data-total="fees">€0.00<`, `data-total="net">€25.00<`,
+			`<tr data-payment-totals="GBP">`,
+			`data-total="gross">£105.00<`, `data-total="fees">£2.00<`, `data-total="refunded">£32.50<`, `data-total="net">£70.50<`,
+		} {
+			if !strings.Contains(footer, want) {
+				t.Errorf("expected footer to contain %q", want)
+			}
+		}
+		if strings.Index(footer, `"EUR"`) > strings.Index(footer, `"GBP"`) {
+			t.Error("expected totals in currency order")
+		}
+	})
+
+	t.Run("exports the payments as CSV", func(t *testing.T) {
+		rr := get("/admin/organisations/7/payments/export?from=2025-10-01&to=2025-10-31")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if want := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC); !gotUntil.Equal(want) {
+			t.Errorf("expected the last day included, got until %v", gotUntil)
+		}
+		want := "date,reference,registration,entrant,email,event,race,status,currency,gross,fee,refunded,net\n" +
+			"2025-10-09,ch_3Q0gbp1,11,Jo Bloggs,jo@example.com,Harbour Run,10K,partially_refunded,GBP,65.00,1.20,32.50,31.30\n" +
+			"2025-10-10,ch_3Q0gbp2,12,Jo Bloggs,jo@example.com,Harbour Run,10K,succeeded,GBP,40.00,0.80,0.00,39.20\n" +
+			"2025-10-11,ch_3Q0eur1,13,Jo Bloggs,jo@example.com,Harbour Run,10K,succeeded,EUR,25.00,,0.00,25.00\n"
+		if got := strings.ReplaceAll(rr.Body.String(), "\r\n", "\n"); got != want {
+			t.Errorf("unexpected export:\n%s", got)
+		}
+	})
+
+	t.Run("returns 400 for a bad date", func(t *testing.T) {
+		if rr := get("/admin/organisations/7/payments?from=1%20October"); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("returns 404 for another organisation", func(t *testing.T) {
+		if rr := get("/admin/organisations/8/payments"); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("serves no webhook endpoint without a secret", func(t *testing.T) {
+		app.stripeWebhookSecret = ""
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhooks/stripe", strings.NewReader("{}")))
+		if rr.Code == http.StatusOK {
+			t.Errorf("expected the endpoint to be unserved, got %d", rr.Code)
+		}
+	})
+}
+
+func TestSignUpPost(t *testing.T) {
+	newFormRequest := func(form url.Values) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/auth/sign-up", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+	valid := url.Values{
+		"first_name": {"Jane"},
+		"last_name":  {"Doe"},
+		"email":      {"jane@example.com"},
+		"password":   {"password123"},
+	}
+
+	t.Run("signs up and redirects to sign in", func(t *testing.T) {
+		var captured service.SignUpInput
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			SignUpFunc: func(ctx context.Context, input service.SignUpInput) (db.User, error) {
+				captured = input
+				return db.User{ID: 1}, nil
+			},
+		}
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.signUpPost).ServeHTTP(rr, newFormRequest(valid))
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != "/auth/sign-in" {
+			t.Errorf("expected redirect to /auth/sign-in, got %q", loc)
+		}
+		if captured.FirstName != "Jane" || captured.Email != "jane@example.com" || captured.Password != "password123" {
+			t.Errorf("unexpected input passed to service: %+v", captured)
+		}
+	})
+
+	t.Run("re-renders with inline errors for missing fields", func(t *testing.T) {
+		called := false
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			SignUpFunc: func(ctx context.Context, input service.SignUpInput) (db.User, error) {
+				called = true
+				return db.User{}, nil
+			},
+		}
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.signUpPost).ServeHTTP(rr, newFormRequest(url.Values{"first_name": {"Jane"}, "email": {"jane@example.com"}}))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if called {
+			t.Error("expected service not to be called")
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"Last name is required", "Password is required", `value="jane@example.com"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected %q in response body", want)
+			}
+		}
+	})
+
+	t.Run("keeps what was typed but the password beside the errors", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			SignUpFunc: func(ctx context.Context, input service.SignUpInput) (db.User, error) {
+				return db.User{}, service.FieldErrors{"email": "invalid email address"}
+			},
+		}
+		form := url.Values{
+			"first_name": {"Jane"},
+			"last_name":  {"Doe"},
+			"email":      {"jane@example"},
+			"password":   {"correct-horse-battery"},
+		}
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.signUpPost).ServeHTTP(rr, newFormRequest(form))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{`value="jane@example"`, "Invalid email address", `value="Jane"`, `value="Doe"`, `aria-invalid="true"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected %q in response body", want)
+			}
+		}
+		if strings.Contains(body, "correct-horse-battery") {
+			t.Error("expected the password not to be shown again")
+		}
+	})
+
+	t.Run("re-renders with the service's field errors", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			SignUpFunc: func(ctx context.Context, input service.SignUpInput) (db.User, error) {
+				return db.User{}, service.FieldErrors{"password": "password must be at least 8 characters"}
+			},
+		}
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.signUpPost).ServeHTTP(rr, newFormRequest(valid))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Password must be at least 8 characters") {
+			t.Error("expected the password error in response body")
+		}
+	})
+
+	t.Run("re-renders with email error when the email is taken", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			SignUpFunc: func(ctx context.Context, input service.SignUpInput) (db.User, error) {
+				return db.User{}, service.ErrEmailExists
+			},
+		}
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.signUpPost).ServeHTTP(rr, newFormRequest(valid))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "An account with this email already exists") {
+			t.Error("expected the email error in response body")
+		}
+	})
+
+	t.Run("returns 500 on service error", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			SignUpFunc: func(ctx context.Context, input service.SignUpInput) (db.User, error) {
+				return db.User{}, errors.New("database error")
+			},
+		}
+
+		rr := httptest.NewRecorder()
+		withSession(app, app.signUpPost).ServeHTTP(rr, newFormRequest(valid))
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+}
+
+func TestProfilePost(t *testing.T) {
+	user := db.User{ID: 7, Email: "sam@example.com", FirstName: "Sam", LastName: "Lee"}
+	post := func(app *application, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/account/profile", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, user))
+		rr := httptest.NewRecorder()
+		withSession(app, app.profilePost).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("saves the details", func(t *testing.T) {
+		var gotID int64
+		var got service.ProfileInput
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+			UpdateProfileFunc: func(ctx context.Context, userID int64, input service.ProfileInput) (db.User, error) {
+				gotID, got = userID, input
+				return user, nil
+			},
+		})
+
+		rr := post(app, url.Values{"first_name": {"Samantha"}, "last_name": {"Lee"}, "date_of_birth": {"1990-04-02"}, "distance_unit": {"km"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/profile" {
+			t.Fatalf("expected a redirect back to the profile, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		want := service.ProfileInput{FirstName: "Samantha", LastName: "Lee", DateOfBirth: time.Date(1990, 4, 2, 0, 0, 0, 0, time.UTC), DistanceUnit: db.DistanceUnitKm}
+		if gotID != user.ID || got != want {
+			t.Errorf("expected %+v for user %d, got %+v for user %d", want, user.ID, got, gotID)
+		}
+	})
+
+	t.Run("shows what is wrong with the details", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+			UpdateProfileFunc: func(ctx context.Context, userID int64, input service.ProfileInput) (db.User, error) {
+				return db.User{}, service.FieldErrors{"date_of_birth": "date of birth must be in the past"}
+			},
+		})
+
+		rr := post(app, url.Values{"first_name": {"Sam"}, "last_name": {"Lee"}, "date_of_birth": {"2030-01-01"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"data-profile-form", "Date of birth must be in the past", `value="2030-01-01"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("rejects dates it cannot read", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+		rr := post(app, url.Values{"first_name": {"Sam"}, "last_name": {"Lee"}, "date_of_birth": {"2 April"}})
+
+		if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), "Date of birth must be a date") {
+			t.Errorf("expected the date to be refused, got %d", rr.Code)
+		}
+	})
+}
+
+func TestDeleteAccountPost(t *testing.T) {
+	// signIn starts a session for userID in the app's store and returns its
+	// token.
+	signIn := func(t *testing.T, app *application, userID int64) string {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		app.sessionManager.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			app.sessionManager.Put(r.Context(), "userID", userID)
+		})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+		for _, c := range rr.Result().Cookies() {
+			if c.Name == storedSessions(app).Cookie.Name {
+				return c.Value
+			}
+		}
+		t.Fatal("expected a session cookie")
+		return ""
+	}
+	sessionExists := func(t *testing.T, app *application, token string) bool {
+		t.Helper()
+		_, found, err := storedSessions(app).Store.Find(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+	post := func(app *application, token string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/account/delete", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: storedSessions(app).Cookie.Name, Value: token})
+		rr := httptest.NewRecorder()
+		withSession(app, app.deleteAccountPost).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("deletes the account and signs out every session", func(t *testing.T) {
+		var gotUserID int64
+		var gotPassword string
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID int64, password string) error {
+				gotUserID, gotPassword = userID, password
+				return nil
+			},
+		}
+		current := signIn(t, app, 7)
+		otherDevice := signIn(t, app, 7)
+		someoneElse := signIn(t, app, 8)
+
+		rr := post(app, current, url.Values{"password": {"correct_password"}})
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/" {
+			t.Fatalf("expected a redirect home, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if gotUserID != 7 || gotPassword != "correct_password" {
+			t.Errorf("expected user 7 with their password, got %d with %q", gotUserID, gotPassword)
+		}
+		if sessionExists(t, app, current) || sessionExists(t, app, otherDevice) {
+			t.Error("expected the user's sessions to be destroyed")
+		}
+		if !sessionExists(t, app, someoneElse) {
+			t.Error("expected other users' sessions to be kept")
+		}
+	})
+
+	t.Run("re-renders the form for a wrong password", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID int64, password string) error {
+				return service.ErrInvalidCredentials
+			},
+		}
+		current := signIn(t, app, 7)
+
+		rr := post(app, current, url.Values{"password": {"wrong_password"}})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "That password is not correct") {
+			t.Error("expected the password error to be shown")
+		}
+		if !sessionExists(t, app, current) {
+			t.Error("expected the session to be kept")
+		}
+	})
+
+	t.Run("requires a password", func(t *testing.T) {
+		called := false
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID int64, password string) error {
+				called = true
+				return nil
+			},
+		}
+
+		rr := post(app, signIn(t, app, 7), url.Values{})
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if called {
+			t.Error("expected the service not to be called without a password")
+		}
+	})
+
+	t.Run("returns 500 on service error", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID int64, password string) error {
+				return errors.New("database error")
+			},
+		}
+
+		if rr := post(app, signIn(t, app, 7), url.Values{"password": {"correct_password"}}); rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "redirects to sign in after verifying", want: http.StatusSeeOther},
+		{name: "redirects to sign in for an invalid token", err: service.ErrInvalidToken, want: http.StatusSeeOther},
+		{name: "returns 500 on service error", err: errors.New("database error"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotToken string
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			app.authService = &servicemocks.AuthServiceMock{
+				VerifyEmailTokenFunc: func(ctx context.Context, token string) error {
+					gotToken = token
+					return tt.err
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/verify?token=abc123", http.NoBody)
+			rr := httptest.NewRecorder()
+			withSession(app, app.verifyEmail).ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rr.Code)
+			}
+			if gotToken != "abc123" {
+				t.Errorf("expected token abc123, got %q", gotToken)
+			}
+			if tt.want == http.StatusSeeOther && rr.Header().Get("Location") != "/auth/sign-in" {
+				t.Errorf("expected redirect to /auth/sign-in, got %q", rr.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestSignInPostSessionLifetime(t *testing.T) {
+	signIn := func(t *testing.T, app *application, rememberMe bool) *http.Cookie {
+		t.Helper()
+		app.authService = &servicemocks.AuthServiceMock{
+			SignInFunc: func(ctx context.Context, input service.SignInInput) (service.AuthResult, error) {
+				if input.RememberMe != rememberMe {
+					t.Errorf("expected RememberMe %v, got %v", rememberMe, input.RememberMe)
+				}
+				return service.AuthResult{User: db.User{ID: 7}, RememberMe: input.RememberMe}, nil
+			},
+		}
+
+		form := url.Values{"email": {"jane@example.com"}, "password": {"password123"}}
+		if rememberMe {
+			form.Set("remember_me", "on")
+		}
+		req := httptest.NewRequest(http.MethodPost, "/auth/sign-in", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.signInPost).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		for _, c := range rr.Result().Cookies() {
+			if c.Name == storedSessions(app).Cookie.Name {
+				return c
+			}
+		}
+		t.Fatal("expected a session cookie")
+		return nil
+	}
+
+	// assertMaxAge allows for the seconds that pass while the request runs.
+	assertMaxAge := func(t *testing.T, c *http.Cookie, want time.Duration) {
+		t.Helper()
+		if got := time.Duration(c.MaxAge) * time.Second; got > want || got < want-5*time.Second {
+			t.Errorf("expected cookie Max-Age of about %v, got %v", want, got)
+		}
+	}
+
+	t.Run("keeps the default lifetime without remember me", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.sessionLifetime = 12 * time.Hour
+
+		assertMaxAge(t, signIn(t, app, false), 12*time.Hour)
+	})
+
+	t.Run("extends the lifetime with remember me", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.sessionLifetime = 12 * time.Hour
+
+		assertMaxAge(t, signIn(t, app, true), 30*24*time.Hour)
+	})
+}
+
+func TestSignInReturnsToNext(t *testing.T) {
+	signIn := func(t *testing.T, next string, err error) *httptest.ResponseRecorder {
+		t.Helper()
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.authService = &servicemocks.AuthServiceMock{
+			SignInFunc: func(ctx context.Context, input service.SignInInput) (service.AuthResult, error) {
+				return service.AuthResult{User: db.User{ID: 7}}, err
+			},
+		}
+
+		form := url.Values{"email": {"jane@example.com"}, "password": {"password123"}, "next": {next}}
+		req := httptest.NewRequest(http.MethodPost, "/auth/sign-in", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.signInPost).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		return rr
+	}
+
+	tests := []struct {
+		name         string
+		next         string
+		err          error
+		wantLocation string
+	}{
+		{name: "returns to a page of ours", next: "/account/registrations?race=3", wantLocation: "/account/registrations?race=3"},
+		{name: "goes home without a page", next: "", wantLocation: "/"},
+		{name: "goes home rather than to another site", next: "https://evil.com", wantLocation: "/"},
+		{name: "goes home rather than to a protocol-relative URL", next: "//evil.com", wantLocation: "/"},
+		{name: "goes home rather than through a backslash", next: "/\\evil.com", wantLocation: "/"},
+		{name: "keeps the page through a failed attempt", next: "/account/profile", err: service.ErrInvalidCredentials, wantLocation: "/auth/sign-in?next=%2Faccount%2Fprofile"},
+		{name: "drops an unsafe page on a failed attempt", next: "//evil.com", err: service.ErrInvalidCredentials, wantLocation: "/auth/sign-in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := signIn(t, tt.next, tt.err)
+
+			if got := rr.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("expected redirect to %q, got %q", tt.wantLocation, got)
+			}
+		})
+	}
+}
+
+func TestSignInRecordsDevice(t *testing.T) {
+	var input service.SignInInput
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+	app.authService = &servicemocks.AuthServiceMock{
+		SignInFunc: func(ctx context.Context, in service.SignInInput) (service.AuthResult, error) {
+			input = in
+			return service.AuthResult{}, service.ErrInvalidCredentials
+		},
+	}
+
+	form := url.Values{"email": {"jane@example.com"}, "password": {"password123"}}
+	req := httptest.NewRequest(http.MethodPost, "/auth/sign-in", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "Firefox/131.0")
+	req = req.WithContext(context.WithValue(req.Context(), contextKeyClientIP, "203.0.113.9"))
+
+	withSession(app, app.signInPost).ServeHTTP(httptest.NewRecorder(), req)
+
+	if input.IPAddress != "203.0.113.9" || input.UserAgent != "Firefox/131.0" {
+		t.Errorf("expected the device passed to sign-in, got %q and %q", input.IPAddress, input.UserAgent)
+	}
+}
+
+func TestSignInTooManySessions(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+	app.authService = &servicemocks.AuthServiceMock{
+		SignInFunc: func(ctx context.Context, input service.SignInInput) (service.AuthResult, error) {
+			return service.AuthResult{User: db.User{ID: 7}}, nil
+		},
+	}
+	app.sessionService = &servicemocks.SessionServiceMock{
+		StartSessionFunc: func(ctx context.Context, userID int64, params service.StartSessionParams) (db.UserSession, error) {
+			return db.UserSession{}, service.ErrTooManySessions
+		},
+	}
+
+	form := url.Values{"email": {"jane@example.com"}, "password": {"password123"}, "next": {"/account/profile"}}
+	req := httptest.NewRequest(http.MethodPost, "/auth/sign-in", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	var flash string
+	var signedIn bool
+	withSession(app, func(w http.ResponseWriter, r *http.Request) {
+		app.signInPost(w, r)
+		flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashError)
+		signedIn = app.isAuthenticated(r)
+	}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/auth/sign-in?next=%2Faccount%2Fprofile" {
+		t.Fatalf("expected a redirect back to sign in, got %d to %q", rr.Code, rr.Header().Get("Location"))
+	}
+	if !strings.Contains(flash, "too many devices") {
+		t.Errorf("expected a flash about too many devices, got %q", flash)
+	}
+	if signedIn {
+		t.Error("expected the sign-in to be refused")
+	}
+}
+
+func TestSignInUnverifiedEmail(t *testing.T) {
+	user := db.User{ID: 7, Email: "jane@example.com", FirstName: "Jane"}
+	newApp := func(verified bool) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+			GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+				return user, nil
+			},
+		})
+		app.authService = &servicemocks.AuthServiceMock{
+			SignInFunc: func(ctx context.Context, input service.SignInInput) (service.AuthResult, error) {
+				return service.AuthResult{User: user, EmailVerified: verified}, nil
+			},
+		}
+		return app
+	}
+	// signIn signs in and returns the account page it is then shown.
+	signIn := func(t *testing.T, app *application) (*httptest.ResponseRecorder, []*http.Cookie) {
+		t.Helper()
+		form := url.Values{"email": {"jane@example.com"}, "password": {"password123"}}
+		req := httptest.NewRequest(http.MethodPost, "/auth/sign-in", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		cookies := rr.Result().Cookies()
+
+		req = httptest.NewRequest(http.MethodGet, "/account/registrations", http.NoBody)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rr = httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+		return rr, cookies
+	}
+
+	t.Run("shows a banner until the email is verified", func(t *testing.T) {
+		app := newApp(false)
+		app.authService.(*servicemocks.AuthServiceMock).VerifyEmailTokenFunc = func(ctx context.Context, token string) error {
+			return nil
+		}
+
+		rr, cookies := signIn(t, app)
+		if !strings.Contains(rr.Body.String(), "data-unverified-banner") {
+			t.Error("expected the unverified email banner")
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/verify?token=abc123", http.NoBody)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rr = httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/" {
+			t.Fatalf("expected a redirect home, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/account/registrations", http.NoBody)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rr = httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+		if strings.Contains(rr.Body.String(), "data-unverified-banner") {
+			t.Error("expected the banner to go once the email is verified")
+		}
+	})
+
+	t.Run("shows no banner to verified users", func(t *testing.T) {
+		rr, _ := signIn(t, newApp(true))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if strings.Contains(rr.Body.String(), "data-unverified-banner") {
+			t.Error("expected no unverified email banner")
+		}
+	})
+}
+
+func TestSignInViewKeepsNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		wantNext bool
+	}{
+		{name: "keeps a page of ours", target: "/auth/sign-in?next=%2Faccount%2Fprofile", wantNext: true},
+		{name: "drops another site", target: "/auth/sign-in?next=%2F%2Fevil.com"},
+		{name: "has nothing to keep without next", target: "/auth/sign-in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			withSession(app, app.signInView).ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+			if got := strings.Contains(body, `name="next" value="/account/profile"`); got != tt.wantNext {
+				t.Errorf("expected the next field %v, got %v", tt.wantNext, got)
+			}
+			if strings.Contains(body, "evil.com") {
+				t.Error("expected the unsafe page to be dropped")
+			}
+		})
+	}
+}
+
+func TestRequireAuthRemembersPage(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		wantLocation string
+	}{
+		{name: "remembers the page asked for", method: http.MethodGet, target: "/account/profile", wantLocation: "/auth/sign-in?next=%2Faccount%2Fprofile"},
+		{name: "remembers the query", method: http.MethodGet, target: "/account/registrations?race=3", wantLocation: "/auth/sign-in?next=%2Faccount%2Fregistrations%3Frace%3D3"},
+		{name: "does not remember a form post", method: http.MethodPost, target: "/account/profile", wantLocation: "/auth/sign-in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			handler := app.sessionManager.LoadAndSave(app.requireAuth(next))
+			req := httptest.NewRequest(tt.method, tt.target, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusSeeOther {
+				t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+			}
+			if got := rr.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("expected redirect to %q, got %q", tt.wantLocation, got)
+			}
+		})
+	}
+}
+
+func TestRedirectIfAuthFollowsNext(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name         string
+		target       string
+		wantLocation string
+	}{
+		{name: "follows a page of ours", target: "/auth/sign-in?next=%2Faccount%2Fprofile", wantLocation: "/account/profile"},
+		{name: "goes home rather than to another site", target: "/auth/sign-in?next=%2F%2Fevil.com", wantLocation: "/"},
+		{name: "goes home without next", target: "/auth/sign-in", wantLocation: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			signedIn := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				app.sessionManager.Put(r.Context(), "userID", int64(7))
+				app.redirectIfAuth(next).ServeHTTP(w, r)
+			})
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			app.sessionManager.LoadAndSave(signedIn).ServeHTTP(rr, req)
+
+			if got := rr.Header().Get("Location"); rr.Code != http.StatusSeeOther || got != tt.wantLocation {
+				t.Errorf("expected redirect to %q, got %d %q", tt.wantLocation, rr.Code, got)
+			}
+		})
+	}
+}
+
+// ctxRecordingEventRepository records the context it is called with and, like
+// pgx, fails once that context is done.
+type ctxRecordingEventRepository struct {
+	repository.EventRepository
+	ctx context.Context
+}
+
+func (m *ctxRecordingEventRepository) ListWithStats(ctx context.Context, params db.ListEventsWithStatsParams) ([]db.ListEventsWithStatsRow, error) {
+	m.ctx = ctx
+	return nil, ctx.Err()
+}
+
+func (m *ctxRecordingEventRepository) GetBySlug(ctx context.Context, year int32, slug string) (db.Event, error) {
+	m.ctx = ctx
+	if err := ctx.Err(); err != nil {
+		return db.Event{}, err
+	}
+	return db.Event{Slug: slug, Year: year}, nil
+}
+
+func TestRequestContextPropagation(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		handler func(app *application) http.HandlerFunc
+	}{
+		{name: "home", target: "/", handler: func(app *application) http.HandlerFunc { return app.home }},
+		{name: "eventView", target: "/events/2026/lincoln-10k", handler: func(app *application) http.HandlerFunc { return app.eventView }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" passes a cancelled request context to the repository", func(t *testing.T) {
+			repo := &ctxRecordingEventRepository{}
+			var logs bytes.Buffer
+			app := newTestApplication(service.NewEventService(repo, nil, nil), &servicemocks.UserServiceMock{})
+			app.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody).WithContext(ctx)
+			req.SetPathValue("slug", "lincoln-10k")
+			req.SetPathValue("year", "2026")
+			rr := httptest.NewRecorder()
+
+			withSession(app, tt.handler(app)).ServeHTTP(rr, req)
+
+			if repo.ctx == nil {
+				t.Fatal("expected repository to be called")
+			}
+			if !errors.Is(repo.ctx.Err(), context.Canceled) {
+				t.Errorf("expected repository context to be cancelled, got %v", repo.ctx.Err())
+			}
+			if rr.Code == http.StatusInternalServerError || rr.Body.Len() != 0 {
+				t.Errorf("expected no response for a cancelled request, got %d with %d bytes", rr.Code, rr.Body.Len())
+			}
+			if strings.Contains(logs.String(), "level=ERROR") {
+				t.Errorf("expected cancellation not to be logged as an error, got:\n%s", logs.String())
+			}
+		})
+	}
+
+	t.Run("bounds repository calls with a deadline", func(t *testing.T) {
+		repo := &ctxRecordingEventRepository{}
+		app := newTestApplication(service.NewEventService(repo, nil, nil), &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/lincoln-10k", http.NoBody)
+		req.SetPathValue("slug", "lincoln-10k")
+		req.SetPathValue("year", "2026")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.eventView).ServeHTTP(rr, req)
+
+		deadline, ok := repo.ctx.Deadline()
+		if !ok {
+			t.Fatal("expected repository context to have a deadline")
+		}
+		if remaining := time.Until(deadline); remaining <= 0 || remaining > dbTimeout {
+			t.Errorf("expected deadline within %v, got %v", dbTimeout, remaining)
+		}
+	})
+
+	t.Run("still reports timeouts as server errors", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		app.serverError(rr, req, fmt.Errorf("failed to list events: %w", context.DeadlineExceeded))
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+
+	t.Run("reports database call timeouts as unavailable", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		err := fmt.Errorf("failed to list events: %w", repository.ErrTimeout)
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		rr := httptest.NewRecorder()
+		app.serverError(rr, req, err)
+
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+		}
+		if rr.Header().Get("Retry-After") == "" {
+			t.Error("expected a Retry-After header")
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/api/events", http.NoBody)
+		rr = httptest.NewRecorder()
+		app.apiError(rr, req, err)
+
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected API status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+		}
+		if got := decodeProblem(t, rr); got.Code != "unavailable" {
+			t.Errorf("expected error code unavailable, got %q", got.Code)
+		}
+	})
+}
+
+func TestRequireRole(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		user       *db.User
+		wantStatus int
+	}{
+		{name: "redirects anonymous users to sign in", user: nil, wantStatus: http.StatusSeeOther},
+		{name: "forbids entrants", user: &db.User{ID: 1, Role: db.UserRoleEntrant}, wantStatus: http.StatusForbidden},
+		{name: "allows organisers", user: &db.User{ID: 2, Role: db.UserRoleOrganizer}, wantStatus: http.StatusOK},
+		{name: "allows admins", user: &db.User{ID: 3, Role: db.UserRoleAdmin}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			handler := app.sessionManager.LoadAndSave(app.requireRole(db.UserRoleOrganizer, db.UserRoleAdmin)(next))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/events/new", http.NoBody)
+			if tt.user != nil {
+				req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, *tt.user))
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestLoadUser(t *testing.T) {
+	now := time.Date(2026, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		wantUser  bool
+	}{
+		{name: "loads the user before the absolute expiry", expiresAt: now.Add(time.Second), wantUser: true},
+		{name: "rejects a session at its absolute expiry", expiresAt: now},
+		{name: "rejects a session past its absolute expiry", expiresAt: now.Add(-time.Hour)},
+		{name: "rejects a session without an absolute expiry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lookedUp bool
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+				GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+					lookedUp = true
+					return db.User{ID: id}, nil
+				},
+			})
+			app.clock = fixedClock(now)
+
+			var gotUser, stillSignedIn bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, gotUser = getUserFromContext(r)
+				stillSignedIn = app.isAuthenticated(r)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			rr := httptest.NewRecorder()
+			app.sessionManager.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				app.sessionManager.Put(r.Context(), "userID", int64(7))
+				if !tt.expiresAt.IsZero() {
+					app.sessionManager.Put(r.Context(), sessionExpiresAtKey, tt.expiresAt.Unix())
+				}
+				app.loadUser(next).ServeHTTP(w, r)
+			})).ServeHTTP(rr, req)
+
+			if gotUser != tt.wantUser {
+				t.Errorf("expected user in context %v, got %v", tt.wantUser, gotUser)
+			}
+			if stillSignedIn != tt.wantUser {
+				t.Errorf("expected session signed in %v, got %v", tt.wantUser, stillSignedIn)
+			}
+			if !tt.wantUser && lookedUp {
+				t.Error("expected an expired session not to load the user")
+			}
+		})
+	}
+
+	for name, user := range map[string]db.User{
+		"deleted":     {ID: 7, DeletedAt: pgtype.Timestamptz{Time: now, Valid: true}},
+		"deactivated": {ID: 7, DeactivatedAt: pgtype.Timestamptz{Time: now, Valid: true}},
+	} {
+		t.Run("signs out a "+name+" user", func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+				GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+					return user, nil
+				},
+			})
+			app.clock = fixedClock(now)
+
+			var gotUser, stillSignedIn bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, gotUser = getUserFromContext(r)
+				stillSignedIn = app.isAuthenticated(r)
+			})
+
+			rr := httptest.NewRecorder()
+			app.sessionManager.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				app.sessionManager.Put(r.Context(), "userID", int64(7))
+				app.sessionManager.Put(r.Context(), sessionExpiresAtKey, now.Add(time.Hour).Unix())
+				app.loadUser(next).ServeHTTP(w, r)
+			})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+			if gotUser || stillSignedIn {
+				t.Errorf("expected the %s user to be signed out", name)
+			}
+		})
+	}
+
+	t.Run("signs out cookie sessions started before the user signed out everywhere", func(t *testing.T) {
+		user := db.User{ID: 7}
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+			GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+				return user, nil
+			},
+		})
+		cookies, err := session.NewCookieManager([]byte(strings.Repeat("k", session.KeyLength)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		app.sessionManager = cookies
+		app.clock = fixedClock(now)
+
+		var gotUser bool
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, gotUser = getUserFromContext(r)
+		})
+		rr := httptest.NewRecorder()
+		cookies.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookies.Put(r.Context(), "userID", int64(7))
+			cookies.Put(r.Context(), sessionExpiresAtKey, now.Add(time.Hour).Unix())
+			cookies.Put(r.Context(), sessionVersionKey, int32(0))
+		})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+		signedIn := rr.Result().Cookies()
+		visit := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			for _, c := range signedIn {
+				req.AddCookie(c)
+			}
+			rr := httptest.NewRecorder()
+			cookies.LoadAndSave(app.loadUser(next)).ServeHTTP(rr, req)
+			return rr
+		}
+
+		if visit(); !gotUser {
+			t.Fatal("expected the session to load the user")
+		}
+
+		user.SessionVersion = 1
+		rr = visit()
+
+		if gotUser {
+			t.Error("expected the session to be signed out")
+		}
+		if c := rr.Result().Cookies(); len(c) != 1 || c[0].MaxAge >= 0 {
+			t.Errorf("expected the session cookie to be deleted, got %v", c)
+		}
+	})
+}
+
+func TestImpersonation(t *testing.T) {
+	users := map[int64]db.User{
+		1: {ID: 1, Email: "ada@example.com", FirstName: "Ada", Role: db.UserRoleAdmin},
+		3: {ID: 3, Email: "jane@example.com", FirstName: "Jane", Role: db.UserRoleEntrant},
+		5: {ID: 5, Email: "grace@example.com", FirstName: "Grace", Role: db.UserRoleAdmin},
+	}
+
+	// newApp returns an application where user 1 is an admin who may act
+	// as user 3, and every audited request is recorded.
+	newApp := func() *application {
+		return newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+			GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+				user, ok := users[id]
+				if !ok {
+					return db.User{}, repository.ErrNotFound
+				}
+				return user, nil
+			},
+			StartImpersonationFunc: func(ctx context.Context, actorID, targetID int64) (db.User, error) {
+				target, ok := users[targetID]
+				if !ok {
+					return db.User{}, repository.ErrNotFound
+				}
+				return target, service.CanImpersonate(users[actorID], target)
+			},
+		})
+	}
+	serve := func(app *application, req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+		return rr
+	}
+	// impersonating returns a request carrying a session cookie for the
+	// admin acting as the target.
+	impersonating := func(t *testing.T, app *application, req *http.Request, adminID, targetID int64) *http.Request {
+		t.Helper()
+		ctx, err := storedSessions(app).Load(context.Background(), "")
+		if err != nil {
+			t.Fatalf("failed to load session: %v", err)
+		}
+		app.sessionManager.Put(ctx, "userID", adminID)
+		app.sessionManager.Put(ctx, sessionExpiresAtKey, time.Now().Add(time.Hour).Unix())
+		app.sessionManager.Put(ctx, sessionImpersonatedUserIDKey, targetID)
+		token, _, err := storedSessions(app).Commit(ctx)
+		if err != nil {
+			t.Fatalf("failed to commit session: %v", err)
+		}
+		req.AddCookie(&http.Cookie{Name: storedSessions(app).Cookie.Name, Value: token})
+		return req
+	}
+	// following returns a request carrying the session cookie rr set.
+	following := func(t *testing.T, rr *httptest.ResponseRecorder, req *http.Request) *http.Request {
+		t.Helper()
+		cookies := rr.Result().Cookies()
+		if len(cookies) == 0 {
+			t.Fatal("expected a session cookie")
+		}
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		return req
+	}
+
+	t.Run("starts acting as the user with a banner to stop", func(t *testing.T) {
+		app := newApp()
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodPost, "/admin/users/3/impersonate", http.NoBody), users[1]))
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/" {
+			t.Fatalf("expected a redirect to /, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if calls := app.userService.(*servicemocks.UserServiceMock).StartImpersonationCalls(); len(calls) != 1 || calls[0].ActorID != 1 || calls[0].TargetID != 3 {
+			t.Errorf("unexpected impersonations %+v", calls)
+		}
+
+		rr = serve(app, following(t, rr, httptest.NewRequest(http.MethodGet, "/account/registrations", http.NoBody)))
+
+		body := rr.Body.String()
+		for _, want := range []string{"data-impersonation-banner", "jane@example.com", `action="/admin/impersonation/stop"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		if calls := app.registrationService.(*servicemocks.RegistrationServiceMock).ListUserRegistrationsCalls(); len(calls) != 1 || calls[0].UserID != 3 {
+			t.Errorf("expected the user's registrations to be listed, got %+v", calls)
+		}
+	})
+
+	t.Run("refuses admins and the admin's own account", func(t *testing.T) {
+		app := newApp()
+
+		for _, path := range []string{"/admin/users/5/impersonate", "/admin/users/1/impersonate"} {
+			rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodPost, path, http.NoBody), users[1]))
+			if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/users" {
+				t.Errorf("%s: expected a redirect to /admin/users, got %d %q", path, rr.Code, rr.Header().Get("Location"))
+			}
+		}
+	})
+
+	t.Run("loads the user while keeping the admin", func(t *testing.T) {
+		app := newApp()
+
+		var user, admin db.User
+		var userID int64
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _ = getUserFromContext(r)
+			admin, _ = getRealUserFromContext(r)
+			userID = app.getUserID(r)
+		})
+		req := impersonating(t, app, httptest.NewRequest(http.MethodGet, "/", http.NoBody), 1, 3)
+		app.sessionManager.LoadAndSave(app.loadUser(next)).ServeHTTP(httptest.NewRecorder(), req)
+
+		if user.ID != 3 || userID != 3 {
+			t.Errorf("expected to act as user 3, got %d and %d", user.ID, userID)
+		}
+		if admin.ID != 1 {
+			t.Errorf("expected the real user to be the admin, got %d", admin.ID)
+		}
+	})
+
+	t.Run("ends impersonation of a user who can no longer be impersonated", func(t *testing.T) {
+		app := newApp()
+		users := maps.Clone(users)
+		users[3] = db.User{ID: 3, Role: db.UserRoleEntrant, DeactivatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}}
+		app.userService.(*servicemocks.UserServiceMock).GetUserFunc = func(ctx context.Context, id int64) (db.User, error) {
+			return users[id], nil
+		}
+
+		var user db.User
+		var stillImpersonating bool
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _ = getUserFromContext(r)
+			stillImpersonating = app.sessionManager.Exists(r.Context(), sessionImpersonatedUserIDKey)
+		})
+		req := impersonating(t, app, httptest.NewRequest(http.MethodGet, "/", http.NoBody), 1, 3)
+		app.sessionManager.LoadAndSave(app.loadUser(next)).ServeHTTP(httptest.NewRecorder(), req)
+
+		if user.ID != 1 || stillImpersonating {
+			t.Errorf("expected the admin back as themselves, got user %d, impersonating %v", user.ID, stillImpersonating)
+		}
+	})
+
+	t.Run("stopping restores the admin's identity", func(t *testing.T) {
+		app := newApp()
+
+		rr := serve(app, impersonating(t, app, httptest.NewRequest(http.MethodGet, "/admin/users", http.NoBody), 1, 3))
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("expected admin pages to be forbidden while impersonating, got %d", rr.Code)
+		}
+
+		rr = serve(app, impersonating(t, app, httptest.NewRequest(http.MethodPost, "/admin/impersonation/stop", http.NoBody), 1, 3))
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/users" {
+			t.Fatalf("expected a redirect to /admin/users, got %d %q", rr.Code, rr.Header().Get("Location"))
+		}
+		userService := app.userService.(*servicemocks.UserServiceMock)
+		if calls := userService.StopImpersonationCalls(); len(calls) != 1 || calls[0].ActorID != 1 || calls[0].TargetID != 3 {
+			t.Errorf("unexpected stops %+v", calls)
+		}
+		if calls := userService.RecordImpersonatedRequestCalls(); len(calls) != 0 {
+			t.Errorf("expected stopping to be audited as a stop only, got %+v", calls)
+		}
+
+		rr = serve(app, following(t, rr, httptest.NewRequest(http.MethodGet, "/admin/users", http.NoBody)))
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected the admin's pages back, got status %d", rr.Code)
+		}
+		if strings.Contains(rr.Body.String(), "data-impersonation-banner") {
+			t.Error("expected no impersonation banner")
+		}
+	})
+
+	t.Run("refuses to stop when not impersonating", func(t *testing.T) {
+		app := newApp()
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodPost, "/admin/impersonation/stop", http.NoBody), users[1]))
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("blocks sensitive actions and audits them", func(t *testing.T) {
+		wildcard := regexp.MustCompile(`\{[^}]+\}`)
+		for _, pattern := range impersonationBlocked {
+			method, path, _ := strings.Cut(pattern, " ")
+			path = wildcard.ReplaceAllString(path, "1")
+			app := newApp()
+
+			rr := serve(app, impersonating(t, app, httptest.NewRequest(method, path, http.NoBody), 1, 3))
+
+			if rr.Code != http.StatusForbidden {
+				t.Errorf("%s: expected status %d, got %d", pattern, http.StatusForbidden, rr.Code)
+			}
+			calls := app.userService.(*servicemocks.UserServiceMock).RecordImpersonatedRequestCalls()
+			if len(calls) != 1 || calls[0].ActorID != 1 || calls[0].TargetID != 3 || calls[0].Req != (service.ImpersonatedRequest{Method: method, Path: path, Blocked: true}) {
+				t.Errorf("%s: unexpected audit %+v", pattern, calls)
+			}
+		}
+	})
+
+	t.Run("audits other changes made as the user", func(t *testing.T) {
+		app := newApp()
+		form := url.Values{"first_name": {"Jane"}, "last_name": {"Runner"}}
+		req := httptest.NewRequest(http.MethodPost, "/account/profile", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		serve(app, impersonating(t, app, req, 1, 3))
+
+		userService := app.userService.(*servicemocks.UserServiceMock)
+		if calls := userService.RecordImpersonatedRequestCalls(); len(calls) != 1 || calls[0].Req != (service.ImpersonatedRequest{Method: http.MethodPost, Path: "/account/profile"}) {
+			t.Errorf("unexpected audit %+v", calls)
+		}
+		if calls := userService.UpdateProfileCalls(); len(calls) != 1 || calls[0].UserID != 3 {
+			t.Errorf("expected the user's profile to be updated, got %+v", calls)
+		}
+	})
+
+	t.Run("refuses changes that cannot be audited", func(t *testing.T) {
+		app := newApp()
+		userService := app.userService.(*servicemocks.UserServiceMock)
+		userService.RecordImpersonatedRequestFunc = func(ctx context.Context, actorID, targetID int64, req service.ImpersonatedRequest) error {
+			return errors.New("database unavailable")
+		}
+
+		rr := serve(app, impersonating(t, app, httptest.NewRequest(http.MethodPost, "/account/profile", http.NoBody), 1, 3))
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+		if calls := userService.UpdateProfileCalls(); len(calls) != 0 {
+			t.Errorf("expected no profile update, got %+v", calls)
+		}
+	})
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	mockEventSvc := &servicemocks.EventServiceMock{
+		FindEventBySlugFunc: func(ctx context.Context, slug string) (db.Event, error) {
+			return db.Event{}, repository.ErrNotFound
+		},
+	}
+	app := newTestApplication(mockEventSvc, &servicemocks.UserServiceMock{})
+	routes := app.routes()
+
+	for _, path := range []string{"/health", "/health", "/api/v1/events/missing"} {
+		routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	}
+
+	scrape := func() string {
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		return rr.Body.String()
+	}
+
+	body := scrape()
+	for _, want := range []string{
+		`firecrest_http_requests_total{route="GET /health",status="2xx"} 2`,
+		`firecrest_http_requests_total{route="GET /api/v1/events/{slug}",status="4xx"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q", want)
+		}
+	}
+
+	// Scraping again must not count the previous scrape.
+	if body := scrape(); strings.Contains(body, `route="GET /metrics"`) {
+		t.Error("expected metrics endpoint to be excluded from its own instrumentation")
+	}
+
+	t.Run("is not mounted when served on its own port", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.serveMetrics = false
+
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+		if strings.Contains(rr.Body.String(), "firecrest_http_requests_total") {
+			t.Error("expected metrics not to be served from the main router")
+		}
+	})
+}
+
+func TestRaceResults(t *testing.T) {
+	newApp := func(resultSvc *servicemocks.ResultServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				return db.Event{ID: 4, Name: "Lincoln 10k", Slug: slug, Year: year}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceFunc: func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+				if slug != "10k" {
+					return service.RaceAvailability{}, repository.ErrNotFound
+				}
+				return service.RaceAvailability{Race: db.Race{ID: 20, EventID: eventID, Name: "10K", Slug: slug}}, nil
+			},
+		}
+		app.resultService = resultSvc
+		return app
+	}
+	get := func(app *application, raceSlug, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/lincoln-10k/results/"+raceSlug+query, http.NoBody)
+		req.SetPathValue("slug", "lincoln-10k")
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("raceSlug", raceSlug)
+		rr := httptest.NewRecorder()
+		app.raceResults(rr, req)
+		return rr
+	}
+
+	t.Run("renders published results with the requested order and search", func(t *testing.T) {
+		var gotRace int64
+		var gotQuery service.ResultsQuery
+		app := newApp(&servicemocks.ResultServiceMock{
+			PublishedResultsFunc: func(ctx context.Context, raceID int64, query service.ResultsQuery) ([]db.ListRaceResultsRow, error) {
+				gotRace, gotQuery = raceID, query
+				return []db.ListRaceResultsRow{
+					{Position: 2, Name: "Jane Smith", Bib: "102", FinishSeconds: 2102, Category: "F", Published: true},
+				}, nil
+			},
+		})
+
+		rr := get(app, "10k", "?sort=name&order=desc&q=+smith+")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		want := service.ResultsQuery{Sort: service.SortByName, Desc: true, Search: "smith"}
+		if gotRace != 20 || gotQuery != want {
+			t.Errorf("expected race 20 with %+v, got race %d with %+v", want, gotRace, gotQuery)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			"Jane Smith",
+			"00:35:02",
+			`data-result-position="2"`,
+			`aria-sort="descending"`,
+			`href="/events/2026/lincoln-10k/results/10k?q=smith&amp;sort=name"`,
+			`href="/events/2026/lincoln-10k/results/10k?q=smith&amp;sort=time"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("defaults to finishing order for unknown sort columns", func(t *testing.T) {
+		var gotQuery service.ResultsQuery
+		app := newApp(&servicemocks.ResultServiceMock{
+			PublishedResultsFunc: func(ctx context.Context, raceID int64, query service.ResultsQuery) ([]db.ListRaceResultsRow, error) {
+				gotQuery = query
+				return []db.ListRaceResultsRow{{Position: 1, Name: "Ola", Published: true}}, nil
+			},
+		})
+
+		get(app, "10k", "?sort=bib;drop")
+
+		if gotQuery.Sort != service.SortByPosition || gotQuery.Desc {
+			t.Errorf("expected position ascending, got %+v", gotQuery)
+		}
+	})
+
+	t.Run("returns 404 for unpublished results", func(t *testing.T) {
+		app := newApp(&servicemocks.ResultServiceMock{
+			PublishedResultsFunc: func(ctx context.Context, raceID int64, query service.ResultsQuery) ([]db.ListRaceResultsRow, error) {
+				return nil, repository.ErrNotFound
+			},
+		})
+
+		if rr := get(app, "10k", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("returns 404 for an unknown race", func(t *testing.T) {
+		app := newApp(&servicemocks.ResultServiceMock{})
+
+		if rr := get(app, "marathon", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("returns 500 on service error", func(t *testing.T) {
+		app := newApp(&servicemocks.ResultServiceMock{
+			PublishedResultsFunc: func(ctx context.Context, raceID int64, query service.ResultsQuery) ([]db.ListRaceResultsRow, error) {
+				return nil, errors.New("database error")
+			},
+		})
+
+		if rr := get(app, "10k", ""); rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+}
+
+func TestRaceRoute(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{
+		GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+			return db.Event{ID: 4, Name: "Peak District Ultra", Slug: slug}, nil
+		},
+	}, &servicemocks.UserServiceMock{})
+	app.raceService = &servicemocks.RaceServiceMock{
+		GetRaceFunc: func(ctx context.Context, eventID int64, slug string) (service.RaceAvailability, error) {
+			if slug != "ultra-50k" {
+				return service.RaceAvailability{}, repository.ErrNotFound
+			}
+			return service.RaceAvailability{Race: db.Race{ID: 20, EventID: eventID, Slug: slug}}, nil
+		},
+		RouteGPXFunc: func(ctx context.Context, raceID int64) ([]byte, error) {
+			if raceID != 20 {
+				return nil, repository.ErrNotFound
+			}
+			return []byte("<gpx></gpx>"), nil
+		},
+	}
+	get := func(raceSlug string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/peak-ultra/races/"+raceSlug+"/route.gpx", http.NoBody)
+		req.SetPathValue("slug", "peak-ultra")
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("raceSlug", raceSlug)
+		rr := httptest.NewRecorder()
+		app.raceRoute(rr, req)
+		return rr
+	}
+
+	t.Run("downloads the route file", func(t *testing.T) {
+		rr := get("ultra-50k")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "application/gpx+xml" {
+			t.Errorf("expected a GPX content type, got %q", got)
+		}
+		if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename="peak-ultra-ultra-50k.gpx"` {
+			t.Errorf("unexpected content disposition %q", got)
+		}
+		if rr.Body.String() != "<gpx></gpx>" {
+			t.Errorf("unexpected body %q", rr.Body.String())
+		}
+	})
+
+	t.Run("returns 404 for unknown races", func(t *testing.T) {
+		if rr := get("10k"); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestEventPhoto(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{
+		GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+			return db.Event{ID: 4, Name: "Peak District Ultra", Slug: slug, Year: year}, nil
+		},
+	}, &servicemocks.UserServiceMock{})
+	app.photoService = &servicemocks.PhotoServiceMock{
+		GetPhotoFunc: func(ctx context.Context, eventID, photoID int64) (db.EventPhoto, error) {
+			if eventID != 4 || photoID != 30 {
+				return db.EventPhoto{}, repository.ErrNotFound
+			}
+			return db.EventPhoto{ID: photoID, EventID: eventID, WebKey: "events/4/photos/abc/web.jpg"}, nil
+		},
+		PhotoURLFunc: func(ctx context.Context, photo db.EventPhoto) (string, error) {
+			return "https://files.example/" + photo.WebKey + "?X-Amz-Signature=x", nil
+		},
+	}
+	get := func(photoID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/peak-ultra/photos/"+photoID, http.NoBody)
+		req.SetPathValue("slug", "peak-ultra")
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("photoID", photoID)
+		rr := httptest.NewRecorder()
+		app.eventPhoto(rr, req)
+		return rr
+	}
+
+	t.Run("redirects to a signed URL", func(t *testing.T) {
+		rr := get("30")
+
+		if rr.Code != http.StatusFound {
+			t.Fatalf("expected status %d, got %d", http.StatusFound, rr.Code)
+		}
+		if got := rr.Header().Get("Location"); got != "https://files.example/events/4/photos/abc/web.jpg?X-Amz-Signature=x" {
+			t.Errorf("unexpected location %q", got)
+		}
+		if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("expected the redirect not to be cached, got %q", got)
+		}
+	})
+
+	t.Run("returns 404 for unknown photos", func(t *testing.T) {
+		for _, id := range []string{"31", "photo"} {
+			if rr := get(id); rr.Code != http.StatusNotFound {
+				t.Errorf("expected status %d for %q, got %d", http.StatusNotFound, id, rr.Code)
+			}
+		}
+	})
+}
+
+func TestAdminRaceResults(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	race := db.Race{ID: 20, EventID: 4, Name: "10K", Slug: "10k"}
+
+	newApp := func(orgID int64, resultSvc *servicemocks.ResultServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				if id != race.EventID {
+					return db.Event{}, repository.ErrNotFound
+				}
+				return db.Event{ID: id, OrganisationID: 7, Name: "Lincoln 10k", Slug: "lincoln-10k", Year: 2026}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		app.raceService = &servicemocks.RaceServiceMock{
+			GetRaceByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+				if id != race.ID {
+					return db.Race{}, repository.ErrNotFound
+				}
+				return race, nil
+			},
+		}
+		app.organisationService = memberOrganisationService(orgID, map[int64]int64{race.EventID: 7})
+		app.resultService = resultSvc
+		return app
+	}
+	serve := func(app *application, h http.HandlerFunc, req *http.Request, id string) *httptest.ResponseRecorder {
+		req.SetPathValue("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, h).ServeHTTP(rr, req)
+		return rr
+	}
+	upload := func(app *application, id, filename, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		if filename != "" {
+			fw, err := mw.CreateFormFile("file", filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.WriteString(fw, content)
+		}
+		mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/races/"+id+"/results", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return serve(app, app.adminResultsPost, req, id)
+	}
+	staged := func(published bool) func(ctx context.Context, raceID int64) ([]db.ListRaceResultsRow, error) {
+		return func(ctx context.Context, raceID int64) ([]db.ListRaceResultsRow, error) {
+			return []db.ListRaceResultsRow{{Position: 1, Name: "Ola Nordmann", FinishSeconds: 2050, Published: published}}, nil
+		}
+	}
+
+	t.Run("renders staged results with a publish button", func(t *testing.T) {
+		app := newApp(7, &servicemocks.ResultServiceMock{ListResultsFunc: staged(false)})
+
+		rr := serve(app, app.adminResultsView, httptest.NewRequest(http.MethodGet, "/admin/races/20/results", http.NoBody), "20")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{`action="/admin/races/20/results"`, `enctype="multipart/form-data"`, `action="/admin/races/20/results/publish"`, "Ola Nordmann", "00:34:10"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("links published results to the public page", func(t *testing.T) {
+		app := newApp(7, &servicemocks.ResultServiceMock{ListResultsFunc: staged(true)})
+
+		rr := serve(app, app.adminResultsView, httptest.NewRequest(http.MethodGet, "/admin/races/20/results", http.NoBody), "20")
+
+		body := rr.Body.String()
+		if !strings.Contains(body, `href="/events/2026/lincoln-10k/results/10k"`) {
+			t.Error("expected a link to the public results page")
+		}
+		if strings.Contains(body, "data-publish-form") {
+			t.Error("expected no publish button for published results")
+		}
+	})
+
+	t.Run("uploads results and redirects with a flash", func(t *testing.T) {
+		var gotRace db.Race
+		var gotCSV string
+		app := newApp(7, &servicemocks.ResultServiceMock{
+			UploadResultsFunc: func(ctx context.Context, r db.Race, file io.Reader) (service.ResultsReport, error) {
+				gotRace = r
+				b, _ := io.ReadAll(file)
+				gotCSV = string(b)
+				return service.ResultsReport{Saved: 1}, nil
+			},
+		})
+
+		rr := upload(app, "20", "results.csv", "position,name,time\n1,Ola,00:34:10\n")
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != "/admin/races/20/results" {
+			t.Errorf("expected redirect to the results page, got %q", loc)
+		}
+		if gotRace.ID != race.ID || !strings.HasPrefix(gotCSV, "position,name,time\n") {
+			t.Errorf("expected the file to reach the service for race %d, got race %d and %q", race.ID, gotRace.ID, gotCSV)
+		}
+	})
+
+	t.Run("renders row errors when nothing was saved", func(t *testing.T) {
+		app := newApp(7, &servicemocks.ResultServiceMock{
+			UploadResultsFunc: func(ctx context.Context, r db.Race, file io.Reader) (service.ResultsReport, error) {
+				return service.ResultsReport{Errors: []service.ResultRowError{
+					{Line: 3, Message: `finish time "34:10" must be HH:MM:SS`},
+					{Line: 4, Message: "position 1 is also used on line 2"},
+				}}, nil
+			},
+		})
+
+		rr := upload(app, "20", "results.csv", "position,name,time\n")
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"Fix the 2 rows below", "finish time &#34;34:10&#34; must be HH:MM:SS", "position 1 is also used on line 2"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("shows file level errors inline", func(t *testing.T) {
+		app := newApp(7, &servicemocks.ResultServiceMock{
+			UploadResultsFunc: func(ctx context.Context, r db.Race, file io.Reader) (service.ResultsReport, error) {
+				return service.ResultsReport{}, fmt.Errorf("%w: the header row has no time column", service.ErrInvalidInput)
+			},
+		})
+
+		rr := upload(app, "20", "results.csv", "position,name\n")
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "the header row has no time column") {
+			t.Error("expected the file error in response body")
+		}
+	})
+
+	t.Run("asks for a file when none was chosen", func(t *testing.T) {
+		app := newApp(7, &servicemocks.ResultServiceMock{})
+
+		rr := upload(app, "20", "", "")
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Choose a CSV file to upload") {
+			t.Error("expected a prompt to choose a file")
+		}
+	})
+
+	t.Run("publishes results and redirects with a flash", func(t *testing.T) {
+		var published int64
+		app := newApp(7, &servicemocks.ResultServiceMock{
+			PublishResultsFunc: func(ctx context.Context, raceID int64) error {
+				published = raceID
+				return nil
+			},
+		})
+
+		rr := serve(app, app.adminPublishResultsPost, httptest.NewRequest(http.MethodPost, "/admin/races/20/results/publish", http.NoBody), "20")
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != "/admin/races/20/results" {
+			t.Errorf("expected redirect to the results page, got %q", loc)
+		}
+		if published != race.ID {
+			t.Errorf("expected race %d published, got %d", race.ID, published)
+		}
+	})
+
+	t.Run("redirects with an error when there is nothing to publish", func(t *testing.T) {
+		app := newApp(7, &servicemocks.ResultServiceMock{
+			PublishResultsFunc: func(ctx context.Context, raceID int64) error {
+				return service.ErrNoResults
+			},
+		})
+
+		rr := serve(app, app.adminPublishResultsPost, httptest.NewRequest(http.MethodPost, "/admin/races/20/results/publish", http.NoBody), "20")
+
+		if rr.Code != http.StatusSeeOther {
+			t.Errorf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+	})
+
+	t.Run("returns 404 for another organisation's race", func(t *testing.T) {
+		published := false
+		app := newApp(99, &servicemocks.ResultServiceMock{
+			PublishResultsFunc: func(ctx context.Context, raceID int64) error {
+				published = true
+				return nil
+			},
+		})
+
+		rr := serve(app, app.adminPublishResultsPost, httptest.NewRequest(http.MethodPost, "/admin/races/20/results/publish", http.NoBody), "20")
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if published {
+			t.Error("expected nothing to be published")
+		}
+	})
+}
+
+func TestAPITokenPages(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// asUser serves h to a session signed in as user 7.
+	asUser := func(app *application, h http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.sessionManager.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			app.sessionManager.Put(r.Context(), "userID", int64(7))
+			h(w, r)
+		})).ServeHTTP(rr, req)
+		return rr
+	}
+	postForm := func(path string, form url.Values) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+	listed := []db.ApiToken{{
+		ID:         3,
+		UserID:     7,
+		Label:      "Chip timing",
+		Scopes:     []string{service.ScopeReadEntrants},
+		CreatedAt:  pgtype.Timestamptz{Time: now.AddDate(0, -1, 0), Valid: true},
+		LastUsedAt: pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true},
+	}}
+
+	t.Run("lists the user's tokens without their secrets", func(t *testing.T) {
+		var gotUserID int64
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.clock = fixedClock(now)
+		app.tokenService = &servicemocks.TokenServiceMock{
+			ListAPITokensFunc: func(ctx context.Context, userID int64) ([]db.ApiToken, error) {
+				gotUserID = userID
+				return listed, nil
+			},
+		}
+
+		rr := asUser(app, app.apiTokensView, httptest.NewRequest(http.MethodGet, "/account/tokens", http.NoBody))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if gotUserID != 7 {
+			t.Errorf("expected user 7's tokens, got user %d's", gotUserID)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"Chip timing", "read:entrants", "Never expires", `action="/account/tokens/3/revoke"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+		if strings.Contains(body, "data-created-token") {
+			t.Error("expected no token to be shown")
+		}
+	})
+
+	t.Run("shows a created token once", func(t *testing.T) {
+		var gotParams service.CreateAPITokenParams
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.tokenService = &servicemocks.TokenServiceMock{
+			CreateAPITokenFunc: func(ctx context.Context, userID int64, params service.CreateAPITokenParams) (service.CreatedAPIToken, error) {
+				gotParams = params
+				return service.CreatedAPIToken{Plaintext: "PLAINTEXTTOKEN"}, nil
+			},
+			ListAPITokensFunc: func(ctx context.Context, userID int64) ([]db.ApiToken, error) {
+				return listed, nil
+			},
+		}
+
+		rr := asUser(app, app.createAPITokenPost, postForm("/account/tokens", url.Values{
+			"label":      {"Chip timing"},
+			"scopes":     {service.ScopeReadEvents, service.ScopeReadEntrants},
+			"expires_in": {"30"},
+		}))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if gotParams.Label != "Chip timing" || len(gotParams.Scopes) != 2 || gotParams.ExpiresIn != 30*24*time.Hour {
+			t.Errorf("unexpected params %+v", gotParams)
+		}
+		if !strings.Contains(rr.Body.String(), "PLAINTEXTTOKEN") {
+			t.Error("expected the new token to be shown")
+		}
+		if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("expected the page not to be stored, got Cache-Control %q", got)
+		}
+	})
+
+	t.Run("shows the form again when it is invalid", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.tokenService = &servicemocks.TokenServiceMock{
+			CreateAPITokenFunc: func(ctx context.Context, userID int64, params service.CreateAPITokenParams) (service.CreatedAPIToken, error) {
+				t.Error("expected no token to be created")
+				return service.CreatedAPIToken{}, nil
+			},
+		}
+
+		rr := asUser(app, app.createAPITokenPost, postForm("/account/tokens", url.Values{"label": {"Chip timing"}}))
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "Scopes is required") || !strings.Contains(body, `value="Chip timing"`) {
+			t.Errorf("expected the error beside the kept values, got:\n%s", body)
+		}
+	})
+
+	t.Run("revokes a token", func(t *testing.T) {
+		var gotUserID, gotID int64
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.tokenService = &servicemocks.TokenServiceMock{
+			RevokeAPITokenFunc: func(ctx context.Context, userID, id int64) error {
+				gotUserID, gotID = userID, id
+				return nil
+			},
+		}
+		req := postForm("/account/tokens/3/revoke", nil)
+		req.SetPathValue("id", "3")
+
+		var flash string
+		rr := asUser(app, func(w http.ResponseWriter, r *http.Request) {
+			app.revokeAPITokenPost(w, r)
+			flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashSuccess)
+		}, req)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/tokens" {
+			t.Fatalf("expected a redirect to the tokens page, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if gotUserID != 7 || gotID != 3 {
+			t.Errorf("expected user 7's token 3, got user %d's token %d", gotUserID, gotID)
+		}
+		if flash != "API token revoked" {
+			t.Errorf("unexpected flash %q", flash)
+		}
+	})
+
+	t.Run("returns 404 for tokens the user does not have", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.tokenService = &servicemocks.TokenServiceMock{
+			RevokeAPITokenFunc: func(ctx context.Context, userID, id int64) error {
+				return repository.ErrNotFound
+			},
+		}
+
+		for _, id := range []string{"3", "abc"} {
+			req := postForm("/account/tokens/"+id+"/revoke", nil)
+			req.SetPathValue("id", id)
+			if rr := asUser(app, app.revokeAPITokenPost, req); rr.Code != http.StatusNotFound {
+				t.Errorf("expected token %s to be not found, got %d", id, rr.Code)
+			}
+		}
+	})
+}
+
+func TestSessionPages(t *testing.T) {
+	user := db.User{ID: 7, Role: db.UserRoleEntrant}
+
+	// newApp returns an application whose user 7 is signed in on sessions
+	// 1 and 2, and which remembers sessions revoked through it.
+	newApp := func() *application {
+		revoked := map[int64]bool{}
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+			GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+				return user, nil
+			},
+		})
+		app.sessionService = &servicemocks.SessionServiceMock{
+			CheckSessionFunc: func(ctx context.Context, userID, sessionID int64) error {
+				if userID != user.ID || revoked[sessionID] {
+					return service.ErrSessionRevoked
+				}
+				return nil
+			},
+			ListSessionsFunc: func(ctx context.Context, userID int64) ([]db.UserSession, error) {
+				var sessions []db.UserSession
+				for _, id := range []int64{1, 2} {
+					if !revoked[id] {
+						sessions = append(sessions, db.UserSession{ID: id, UserID: userID, UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0", IpAddress: "203.0.113.9"})
+					}
+				}
+				return sessions, nil
+			},
+			RevokeSessionFunc: func(ctx context.Context, userID, sessionID int64) error {
+				if userID != user.ID || sessionID > 2 || revoked[sessionID] {
+					return repository.ErrNotFound
+				}
+				revoked[sessionID] = true
+				return nil
+			},
+			RevokeOtherSessionsFunc: func(ctx context.Context, userID, keepSessionID int64) (int64, error) {
+				var n int64
+				for _, id := range []int64{1, 2} {
+					if id != keepSessionID && !revoked[id] {
+						revoked[id] = true
+						n++
+					}
+				}
+				return n, nil
+			},
+		}
+		return app
+	}
+	// onSession returns a request carrying the cookie of user 7's session
+	// sessionID.
+	onSession := func(t *testing.T, app *application, req *http.Request, sessionID int64) *http.Request {
+		t.Helper()
+		ctx, err := storedSessions(app).Load(context.Background(), "")
+		if err != nil {
+			t.Fatalf("failed to load session: %v", err)
+		}
+		app.sessionManager.Put(ctx, "userID", user.ID)
+		app.sessionManager.Put(ctx, sessionExpiresAtKey, time.Now().Add(time.Hour).Unix())
+		app.sessionManager.Put(ctx, sessionIDKey, sessionID)
+		token, _, err := storedSessions(app).Commit(ctx)
+		if err != nil {
+			t.Fatalf("failed to commit session: %v", err)
+		}
+		req.AddCookie(&http.Cookie{Name: storedSessions(app).Cookie.Name, Value: token})
+		return req
+	}
+	serve := func(app *application, req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("lists the user's devices, marking this one", func(t *testing.T) {
+		app := newApp()
+
+		rr := serve(app, onSession(t, app, httptest.NewRequest(http.MethodGet, "/account/sessions", http.NoBody), 1))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"Firefox on Linux", "203.0.113.9", "data-current-session", `action="/account/sessions/2/revoke"`, "data-revoke-other-sessions"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q", want)
+			}
+		}
+		if strings.Contains(body, `action="/account/sessions/1/revoke"`) {
+			t.Error("expected this device to have no sign out button of its own")
+		}
+	})
+
+	t.Run("signs a device out on its next request", func(t *testing.T) {
+		app := newApp()
+
+		rr := serve(app, onSession(t, app, httptest.NewRequest(http.MethodPost, "/account/sessions/2/revoke", http.NoBody), 1))
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/sessions" {
+			t.Fatalf("expected a redirect to the sessions page, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+
+		rr = serve(app, onSession(t, app, httptest.NewRequest(http.MethodGet, "/account/sessions", http.NoBody), 2))
+		if rr.Code != http.StatusSeeOther || !strings.HasPrefix(rr.Header().Get("Location"), "/auth/sign-in") {
+			t.Errorf("expected the revoked device to be sent to sign in, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		rr = serve(app, onSession(t, app, httptest.NewRequest(http.MethodGet, "/account/sessions", http.NoBody), 1))
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected this device to stay signed in, got %d", rr.Code)
+		}
+	})
+
+	t.Run("signs out every other device", func(t *testing.T) {
+		app := newApp()
+
+		rr := serve(app, onSession(t, app, httptest.NewRequest(http.MethodPost, "/account/sessions/revoke-others", http.NoBody), 1))
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/sessions" {
+			t.Fatalf("expected a redirect to the sessions page, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+
+		rr = serve(app, onSession(t, app, httptest.NewRequest(http.MethodGet, "/account/sessions", http.NoBody), 1))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if body := rr.Body.String(); strings.Count(body, "data-session>") != 1 || strings.Contains(body, "data-revoke-other-sessions") {
+			t.Errorf("expected only this device to be left, got:\n%s", body)
+		}
+	})
+
+	t.Run("signs out, invalidating the session cookie", func(t *testing.T) {
+		app := newApp()
+		req := onSession(t, app, httptest.NewRequest(http.MethodPost, "/auth/sign-out", http.NoBody), 1)
+		cookie := req.Cookies()[0]
+
+		rr := serve(app, req)
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/" {
+			t.Fatalf("expected a redirect home, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		var replaced bool
+		for _, c := range rr.Result().Cookies() {
+			if c.Name == cookie.Name && c.Value != cookie.Value {
+				replaced = true
+			}
+		}
+		if !replaced {
+			t.Error("expected the session cookie to be replaced")
+		}
+
+		// The old cookie no longer signs anyone in
+		old := httptest.NewRequest(http.MethodGet, "/account/sessions", http.NoBody)
+		old.AddCookie(cookie)
+		rr = serve(app, old)
+		if rr.Code != http.StatusSeeOther || !strings.HasPrefix(rr.Header().Get("Location"), "/auth/sign-in") {
+			t.Errorf("expected the old cookie to be sent to sign in, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if calls := app.sessionService.(*servicemocks.SessionServiceMock).RevokeSessionCalls(); len(calls) != 1 || calls[0].SessionID != 1 {
+			t.Errorf("expected session 1's record revoked, got %+v", calls)
+		}
+	})
+
+	t.Run("signs out only by POST", func(t *testing.T) {
+		app := newApp()
+
+		rr := serve(app, onSession(t, app, httptest.NewRequest(http.MethodGet, "/auth/sign-out", http.NoBody), 1))
+		if rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, rr.Code)
+		}
+
+		req := onSession(t, app, httptest.NewRequest(http.MethodPost, "/auth/sign-out", http.NoBody), 1)
+		req.Header.Set("Sec-Fetch-Site", "cross-site")
+		if rr := serve(app, req); rr.Code != http.StatusForbidden {
+			t.Errorf("expected cross-site sign out to be refused, got %d", rr.Code)
+		}
+	})
+
+	t.Run("signs out every device, this one included", func(t *testing.T) {
+		app := newApp()
+		app.sessionService.(*servicemocks.SessionServiceMock).SignOutEverywhereFunc = func(ctx context.Context, userID int64) (int64, error) {
+			return 2, nil
+		}
+
+		other := onSession(t, app, httptest.NewRequest(http.MethodGet, "/account/sessions", http.NoBody), 2)
+		rr := serve(app, onSession(t, app, httptest.NewRequest(http.MethodPost, "/auth/sign-out-everywhere", http.NoBody), 1))
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/" {
+			t.Fatalf("expected a redirect home, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if calls := app.sessionService.(*servicemocks.SessionServiceMock).SignOutEverywhereCalls(); len(calls) != 1 || calls[0].UserID != user.ID {
+			t.Errorf("expected user 7 signed out everywhere, got %+v", calls)
+		}
+
+		rr = serve(app, other)
+		if rr.Code != http.StatusSeeOther || !strings.HasPrefix(rr.Header().Get("Location"), "/auth/sign-in") {
+			t.Errorf("expected the other device to be sent to sign in, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+	})
+
+	t.Run("offers sign out only to signed-in users", func(t *testing.T) {
+		app := newApp()
+
+		rr := serve(app, onSession(t, app, httptest.NewRequest(http.MethodGet, "/account/sessions", http.NoBody), 1))
+		if body := rr.Body.String(); !strings.Contains(body, "data-sign-out") || !strings.Contains(body, `action="/auth/sign-out"`) {
+			t.Error("expected the header to offer signing out")
+		}
+
+		rr = serve(app, httptest.NewRequest(http.MethodGet, "/auth/sign-in", http.NoBody))
+		if body := rr.Body.String(); strings.Contains(body, "data-sign-out") || !strings.Contains(body, `href="/auth/sign-in"`) {
+			t.Error("expected the header to offer signing in instead")
+		}
+	})
+
+	t.Run("tells a device signed out by a newer sign-in why", func(t *testing.T) {
+		app := newApp()
+		app.sessionService.(*servicemocks.SessionServiceMock).CheckSessionFunc = func(ctx context.Context, userID, sessionID int64) error {
+			if sessionID == 1 {
+				return service.ErrSessionEvicted
+			}
+			return nil
+		}
+
+		rr := serve(app, onSession(t, app, httptest.NewRequest(http.MethodGet, "/account/sessions", http.NoBody), 1))
+		if rr.Code != http.StatusSeeOther || !strings.HasPrefix(rr.Header().Get("Location"), "/auth/sign-in") {
+			t.Fatalf("expected the evicted device to be sent to sign in, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+
+		req := httptest.NewRequest(http.MethodGet, rr.Header().Get("Location"), http.NoBody)
+		for _, c := range rr.Result().Cookies() {
+			req.AddCookie(c)
+		}
+		rr = serve(app, req)
+		if body := rr.Body.String(); !strings.Contains(body, "signed in on another device") {
+			t.Errorf("expected the sign-in page to say why, got:\n%s", body)
+		}
+	})
+
+	t.Run("returns 404 for sessions the user does not have", func(t *testing.T) {
+		app := newApp()
+
+		rr := serve(app, onSession(t, app, httptest.NewRequest(http.MethodPost, "/account/sessions/9/revoke", http.NoBody), 1))
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+func TestDataExportPages(t *testing.T) {
+	user := db.User{ID: 7, Role: db.UserRoleEntrant}
+	newApp := func(exports *servicemocks.DataExportServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+			GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+				return user, nil
+			},
+		})
+		app.dataExportService = exports
+		return app
+	}
+	serve := func(app *application, req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("offers a copy to a user who has not asked for one", func(t *testing.T) {
+		app := newApp(&servicemocks.DataExportServiceMock{
+			LatestExportFunc: func(ctx context.Context, userID int64) (db.DataExport, error) {
+				return db.DataExport{}, repository.ErrNotFound
+			},
+		})
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, "/account/data-export", http.NoBody), user))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "data-data-export-form") {
+			t.Error("expected a form to ask for a copy")
+		}
+	})
+
+	t.Run("shows a copy being prepared without offering another", func(t *testing.T) {
+		app := newApp(&servicemocks.DataExportServiceMock{
+			LatestExportFunc: func(ctx context.Context, userID int64) (db.DataExport, error) {
+				return db.DataExport{ID: 1, UserID: userID, Status: db.DataExportStatusPending}, nil
+			},
+		})
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, "/account/data-export", http.NoBody), user))
+
+		body := rr.Body.String()
+		if !strings.Contains(body, `data-data-export-state="pending"`) {
+			t.Error("expected the copy shown as being prepared")
+		}
+		if strings.Contains(body, "data-data-export-form") {
+			t.Error("expected no form while a copy is being prepared")
+		}
+	})
+
+	t.Run("asks for a copy", func(t *testing.T) {
+		exports := &servicemocks.DataExportServiceMock{}
+		app := newApp(exports)
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodPost, "/account/data-export", http.NoBody), user))
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/data-export" {
+			t.Fatalf("expected a redirect to the data export page, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if calls := exports.RequestExportCalls(); len(calls) != 1 || calls[0].UserID != user.ID {
+			t.Errorf("expected a copy asked for user %d, got %+v", user.ID, calls)
+		}
+	})
+
+	t.Run("downloads the copy the link is for", func(t *testing.T) {
+		exports := &servicemocks.DataExportServiceMock{
+			OpenExportFunc: func(ctx context.Context, userID int64, tok string) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader("PK archive")), nil
+			},
+		}
+		app := newApp(exports)
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, "/account/data-export?token=abc", http.NoBody), user))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "application/zip" {
+			t.Errorf("expected a ZIP, got %q", got)
+		}
+		if got := rr.Header().Get("Content-Disposition"); !strings.HasPrefix(got, "attachment;") {
+			t.Errorf("expected the archive downloaded as a file, got %q", got)
+		}
+		if rr.Body.String() != "PK archive" {
+			t.Errorf("expected the archive sent, got %q", rr.Body.String())
+		}
+		if calls := exports.OpenExportCalls(); len(calls) != 1 || calls[0].UserID != user.ID || calls[0].Tok != "abc" {
+			t.Errorf("expected the token opened for user %d, got %+v", user.ID, calls)
+		}
+	})
+
+	t.Run("turns away a link that has been used", func(t *testing.T) {
+		app := newApp(&servicemocks.DataExportServiceMock{
+			OpenExportFunc: func(ctx context.Context, userID int64, tok string) (io.ReadCloser, error) {
+				return nil, service.ErrInvalidToken
+			},
+		})
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, "/account/data-export?token=abc", http.NoBody), user))
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/account/data-export" {
+			t.Errorf("expected a redirect to the data export page, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+	})
+
+	t.Run("sends a link followed while signed out to sign in first", func(t *testing.T) {
+		exports := &servicemocks.DataExportServiceMock{}
+		app := newApp(exports)
+
+		rr := serve(app, httptest.NewRequest(http.MethodGet, "/account/data-export?token=abc", http.NoBody))
+
+		if rr.Code != http.StatusSeeOther || !strings.HasPrefix(rr.Header().Get("Location"), "/auth/sign-in") {
+			t.Errorf("expected a redirect to sign in, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if len(exports.OpenExportCalls()) != 0 {
+			t.Error("expected the link left unused")
+		}
+	})
+}
+
+func TestAdminUsersPages(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	users := map[int64]db.User{
+		1: {ID: 1, Role: db.UserRoleAdmin},
+		2: {ID: 2, Role: db.UserRoleOrganizer},
+	}
+	rows := []db.ListUsersRow{
+		{
+			ID: 3, Email: "jane@example.com", FirstName: "Jane", LastName: "Runner", Role: db.UserRoleEntrant,
+			FailedLoginAttempts: pgtype.Int4{Int32: 5, Valid: true},
+			LockedUntil:         pgtype.Timestamptz{Time: now.Add(10 * time.Minute), Valid: true},
+		},
+		{
+			ID: 4, Email: "sam@example.com", FirstName: "Sam", LastName: "Walker", Role: db.UserRoleEntrant,
+			FailedLoginAttempts: pgtype.Int4{Int32: 0, Valid: true},
+			DeactivatedAt:       pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true},
+		},
+		{ID: 1, Email: "admin@example.com", FirstName: "Ada", LastName: "Admin", Role: db.UserRoleAdmin},
+	}
+
+	// newApp returns an application where user 1 is an admin, user 2 an
+	// organiser, and unlocked accounts are recorded in unlocked.
+	newApp := func(unlocked map[int64]int64) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+			GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+				return users[id], nil
+			},
+			ListUsersFunc: func(ctx context.Context, params service.ListUsersParams) (service.UserPage, error) {
+				if params.Role == "superuser" {
+					return service.UserPage{}, service.ErrInvalidInput
+				}
+				page := service.UserPage{Page: params.Page, PerPage: params.PerPage, Total: 120}
+				for _, row := range rows {
+					if strings.Contains(row.Email, params.Query) && (params.Role == "" || row.Role == params.Role) {
+						page.Users = append(page.Users, row)
+					}
+				}
+				return page, nil
+			},
+			UpdateUserRoleFunc: func(ctx context.Context, actorID, targetID int64, role db.UserRole) error {
+				switch {
+				case users[actorID].Role != db.UserRoleAdmin:
+					return service.ErrForbidden
+				case actorID == targetID:
+					return service.ErrOwnAccount
+				case role != db.UserRoleEntrant && role != db.UserRoleOrganizer && role != db.UserRoleAdmin:
+					return service.ErrInvalidInput
+				}
+				return nil
+			},
+			SetUserActiveFunc: func(ctx context.Context, actorID, targetID int64, active bool) error {
+				switch {
+				case users[actorID].Role != db.UserRoleAdmin:
+					return service.ErrForbidden
+				case actorID == targetID:
+					return service.ErrOwnAccount
+				case targetID != 3 && targetID != 4:
+					return repository.ErrNotFound
+				}
+				return nil
+			},
+		})
+		app.clock = fixedClock(now)
+		app.authService = &servicemocks.AuthServiceMock{
+			UnlockAccountFunc: func(ctx context.Context, adminUserID, targetUserID int64) error {
+				if users[adminUserID].Role != db.UserRoleAdmin {
+					return service.ErrForbidden
+				}
+				if targetUserID != 3 && targetUserID != 4 {
+					return repository.ErrNotFound
+				}
+				unlocked[targetUserID] = adminUserID
+				return nil
+			},
+		}
+		return app
+	}
+	serve := func(app *application, req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.routes().ServeHTTP(rr, req)
+		return rr
+	}
+	postForm := func(path string, form url.Values) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	t.Run("lists users with their lock status", func(t *testing.T) {
+		app := newApp(map[int64]int64{})
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, "/admin/users", http.NoBody), users[1]))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			`data-user="jane@example.com"`,
+			"Locked until 1 May 2026 09:10",
+			`data-user="sam@example.com"`,
+			`action="/admin/users/3/unlock"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		if strings.Contains(body, `action="/admin/users/4/unlock"`) {
+			t.Error("expected no unlock button for an account that is not locked")
+		}
+	})
+
+	t.Run("offers role and activation controls for other users only", func(t *testing.T) {
+		app := newApp(map[int64]int64{})
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, "/admin/users", http.NoBody), users[1]))
+
+		body := rr.Body.String()
+		for _, want := range []string{
+			`action="/admin/users/3/role"`,
+			`action="/admin/users/3/active"`,
+			`<option value="entrant" selected>Entrant</option>`,
+			"Deactivated",
+			"Reactivate",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		for _, unwanted := range []string{`action="/admin/users/1/role"`, `action="/admin/users/1/active"`} {
+			if strings.Contains(body, unwanted) {
+				t.Errorf("expected no %q for the admin's own account", unwanted)
+			}
+		}
+	})
+
+	t.Run("searches by email", func(t *testing.T) {
+		app := newApp(map[int64]int64{})
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, "/admin/users?q=sam", http.NoBody), users[1]))
+
+		body := rr.Body.String()
+		if !strings.Contains(body, `data-user="sam@example.com"`) || strings.Contains(body, `data-user="jane@example.com"`) {
+			t.Errorf("expected only sam@example.com to be listed, got %s", body)
+		}
+		if !strings.Contains(body, `value="sam"`) {
+			t.Error("expected the search to be kept in the form")
+		}
+	})
+
+	t.Run("filters by role and pages through the users", func(t *testing.T) {
+		app := newApp(map[int64]int64{})
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, "/admin/users?q=example&role=admin&page=2", http.NoBody), users[1]))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, `data-user="admin@example.com"`) || strings.Contains(body, `data-user="jane@example.com"`) {
+			t.Errorf("expected only admins to be listed, got %s", body)
+		}
+		for _, want := range []string{
+			`<option value="admin" selected>`,
+			"Page 2 of 3",
+			`href="/admin/users?q=example&amp;role=admin"`,
+			`href="/admin/users?page=3&amp;q=example&amp;role=admin"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+		calls := app.userService.(*servicemocks.UserServiceMock).ListUsersCalls()
+		if len(calls) != 1 || calls[0].Params.Page != 2 || calls[0].Params.PerPage != service.DefaultUsersPerPage {
+			t.Errorf("expected page 2 of %d users, got %+v", service.DefaultUsersPerPage, calls)
+		}
+	})
+
+	t.Run("says when no users match", func(t *testing.T) {
+		app := newApp(map[int64]int64{})
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, "/admin/users?q=nobody", http.NoBody), users[1]))
+
+		if !strings.Contains(rr.Body.String(), "data-users-empty") {
+			t.Error("expected the empty state")
+		}
+	})
+
+	t.Run("rejects bad pages and roles", func(t *testing.T) {
+		for _, target := range []string{"/admin/users?page=two", "/admin/users?role=superuser"} {
+			app := newApp(map[int64]int64{})
+
+			rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodGet, target, http.NoBody), users[1]))
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d for %s, got %d", http.StatusBadRequest, target, rr.Code)
+			}
+		}
+	})
+
+	t.Run("unlocks an account for an admin", func(t *testing.T) {
+		unlocked := map[int64]int64{}
+		app := newApp(unlocked)
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodPost, "/admin/users/3/unlock", http.NoBody), users[1]))
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected status %d, got %d", http.StatusSeeOther, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != "/admin/users" {
+			t.Errorf("expected redirect to /admin/users, got %q", loc)
+		}
+		if unlocked[3] != 1 {
+			t.Errorf("expected user 3 to be unlocked by the admin, got %v", unlocked)
+		}
+	})
+
+	t.Run("changes roles and deactivates accounts for an admin", func(t *testing.T) {
+		app := newApp(map[int64]int64{})
+		userService := app.userService.(*servicemocks.UserServiceMock)
+
+		for _, req := range []*http.Request{
+			postForm("/admin/users/3/role", url.Values{"role": {"organizer"}}),
+			postForm("/admin/users/3/active", url.Values{"active": {"false"}}),
+		} {
+			rr := serve(app, signedIn(t, app, req, users[1]))
+			if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/users" {
+				t.Errorf("%s: expected a redirect to /admin/users, got %d %q", req.URL.Path, rr.Code, rr.Header().Get("Location"))
+			}
+		}
+		if calls := userService.UpdateUserRoleCalls(); len(calls) != 1 || calls[0].ActorID != 1 || calls[0].TargetID != 3 || calls[0].Role != db.UserRoleOrganizer {
+			t.Errorf("unexpected role changes %+v", calls)
+		}
+		if calls := userService.SetUserActiveCalls(); len(calls) != 1 || calls[0].TargetID != 3 || calls[0].Active {
+			t.Errorf("unexpected activation changes %+v", calls)
+		}
+	})
+
+	t.Run("sends an admin changing themselves back with an error", func(t *testing.T) {
+		app := newApp(map[int64]int64{})
+
+		for _, req := range []*http.Request{
+			postForm("/admin/users/1/role", url.Values{"role": {"entrant"}}),
+			postForm("/admin/users/1/active", url.Values{"active": {"false"}}),
+		} {
+			rr := serve(app, signedIn(t, app, req, users[1]))
+			if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/admin/users" {
+				t.Errorf("%s: expected a redirect to /admin/users, got %d %q", req.URL.Path, rr.Code, rr.Header().Get("Location"))
+			}
+		}
+	})
+
+	t.Run("rejects bad role and activation values", func(t *testing.T) {
+		app := newApp(map[int64]int64{})
+
+		for _, req := range []*http.Request{
+			postForm("/admin/users/3/role", url.Values{"role": {"superuser"}}),
+			postForm("/admin/users/3/active", url.Values{"active": {"maybe"}}),
+		} {
+			rr := serve(app, signedIn(t, app, req, users[1]))
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", req.URL.Path, http.StatusBadRequest, rr.Code)
+			}
+		}
+	})
+
+	t.Run("returns 404 for an unknown user", func(t *testing.T) {
+		app := newApp(map[int64]int64{})
+
+		rr := serve(app, signedIn(t, app, httptest.NewRequest(http.MethodPost, "/admin/users/99/unlock", http.NoBody), users[1]))
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("forbids organisers", func(t *testing.T) {
+		unlocked := map[int64]int64{}
+		app := newApp(unlocked)
+
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/admin/users", http.NoBody),
+			httptest.NewRequest(http.MethodPost, "/admin/users/3/unlock", http.NoBody),
+			postForm("/admin/users/3/role", url.Values{"role": {"admin"}}),
+			postForm("/admin/users/3/active", url.Values{"active": {"false"}}),
+		} {
+			rr := serve(app, signedIn(t, app, req, users[2]))
+			if rr.Code != http.StatusForbidden {
+				t.Errorf("%s %s: expected status %d, got %d", req.Method, req.URL.Path, http.StatusForbidden, rr.Code)
+			}
+		}
+		if len(unlocked) != 0 {
+			t.Errorf("expected nothing unlocked, got %v", unlocked)
+		}
+	})
+}
+
+func TestContact(t *testing.T) {
+	event := db.Event{ID: 4, OrganisationID: 7, Name: "Harbour 10K", Slug: "harbour-10k", Year: 2026}
+
+	newApp := func(contactSvc *servicemocks.ContactServiceMock) *application {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			GetEventFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				if slug != event.Slug {
+					return db.Event{}, repository.ErrNotFound
+				}
+				return event, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+		contactSvc.FormStampFunc = func() (string, error) {
+			return "v1:contact-form:fresh", nil
+		}
+		app.contactService = contactSvc
+		return app
+	}
+	form := url.Values{
+		"name":    {"Ada Lovelace"},
+		"email":   {"ada@example.com"},
+		"message": {"Is there parking near the start?"},
+		"website": {""},
+		"stamp":   {"v1:contact-form:served"},
+	}
+	post := func(app *application, form url.Values) (*httptest.ResponseRecorder, string) {
+		req := httptest.NewRequest(http.MethodPost, "/events/2026/harbour-10k/contact/organiser", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("slug", "harbour-10k")
+		rr := httptest.NewRecorder()
+		var flash string
+		withSession(app, func(w http.ResponseWriter, r *http.Request) {
+			app.contactPost(w, r)
+			flash = app.sessionManager.GetString(r.Context(), "flash_"+FlashSuccess)
+		}).ServeHTTP(rr, req)
+		return rr, flash
+	}
+	with := func(key, value string) url.Values {
+		changed := maps.Clone(form)
+		changed.Set(key, value)
+		return changed
+	}
+
+	t.Run("shows the form with a stamp and a hidden honeypot", func(t *testing.T) {
+		app := newApp(&servicemocks.ContactServiceMock{})
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/harbour-10k/contact/organiser", http.NoBody)
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("slug", "harbour-10k")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.contactView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{`name="stamp" value="v1:contact-form:fresh"`, `name="website"`, `aria-hidden="true"`, `maxlength="5000"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("sends the enquiry and returns to the event", func(t *testing.T) {
+		var got service.EnquiryInput
+		app := newApp(&servicemocks.ContactServiceMock{
+			SendEnquiryFunc: func(ctx context.Context, e db.Event, input service.EnquiryInput) error {
+				got = input
+				return nil
+			},
+		})
+
+		rr, flash := post(app, form)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/events/2026/harbour-10k" {
+			t.Fatalf("expected a redirect to the event, got %d to %q", rr.Code, rr.Header().Get("Location"))
+		}
+		if flash == "" {
+			t.Error("expected a success flash")
+		}
+		if got.Name != "Ada Lovelace" || got.Stamp != "v1:contact-form:served" || got.Honeypot != "" {
+			t.Errorf("unexpected input %+v", got)
+		}
+	})
+
+	t.Run("answers a tripped honeypot as though it was sent", func(t *testing.T) {
+		var got service.EnquiryInput
+		app := newApp(&servicemocks.ContactServiceMock{
+			SendEnquiryFunc: func(ctx context.Context, e db.Event, input service.EnquiryInput) error {
+				got = input
+				return service.ErrSpam
+			},
+		})
+
+		rr, flash := post(app, with("website", "https://cheap-watches.example"))
+
+		if rr.Code != http.StatusSeeOther || flash == "" {
+			t.Errorf("expected the usual redirect and flash, got %d and %q", rr.Code, flash)
+		}
+		if got.Honeypot != "https://cheap-watches.example" {
+			t.Errorf("expected the honeypot passed on, got %q", got.Honeypot)
+		}
+	})
+
+	t.Run("asks again with a fresh stamp when sent too soon", func(t *testing.T) {
+		app := newApp(&servicemocks.ContactServiceMock{
+			SendEnquiryFunc: func(ctx context.Context, e db.Event, input service.EnquiryInput) error {
+				return service.ErrContactFormTiming
+			},
+		})
+
+		rr, _ := post(app, form)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"data-form-error", `value="v1:contact-form:fresh"`, "Is there parking near the start?"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("shows the service's field errors", func(t *testing.T) {
+		app := newApp(&servicemocks.ContactServiceMock{
+			SendEnquiryFunc: func(ctx context.Context, e db.Event, input service.EnquiryInput) error {
+				return service.FieldErrors{"message": "message must contain at most 3 links"}
+			},
+		})
+
+		rr, _ := post(app, with("message", "a.example b.example c.example d.example"))
+
+		if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), "Message must contain at most 3 links") {
+			t.Errorf("expected the links error, got %d", rr.Code)
+		}
+	})
+
+	t.Run("returns 404 for an unknown event", func(t *testing.T) {
+		app := newApp(&servicemocks.ContactServiceMock{})
+		req := httptest.NewRequest(http.MethodGet, "/events/2026/nope/contact/organiser", http.NoBody)
+		req.SetPathValue("year", "2026")
+		req.SetPathValue("slug", "nope")
+		rr := httptest.NewRecorder()
+
+		withSession(app, app.contactView).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("limits how often one client sends", func(t *testing.T) {
+		app := newApp(&servicemocks.ContactServiceMock{})
+		routes := app.routes()
+		send := func() int {
+			req := httptest.NewRequest(http.MethodPost, "/events/2026/harbour-10k/contact/organiser", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			routes.ServeHTTP(rr, req)
+			return rr.Code
+		}
+
+		for i := range contactLimit {
+			if code := send(); code != http.StatusSeeOther {
+				t.Fatalf("expected send %d through, got %d", i+1, code)
+			}
+		}
+		if code := send(); code != http.StatusTooManyRequests {
+			t.Errorf("expected status %d, got %d", http.StatusTooManyRequests, code)
+		}
+		if n := len(app.contactService.(*servicemocks.ContactServiceMock).SendEnquiryCalls()); n != contactLimit {
+			t.Errorf("expected %d enquiries sent, got %d", contactLimit, n)
+		}
+	})
+}
+
+func TestAdminEnquiries(t *testing.T) {
+	organiser := db.User{ID: 3, Role: db.UserRoleOrganizer}
+	app := newTestApplication(&servicemocks.EventServiceMock{
+		GetEventByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+			return db.Event{ID: id, OrganisationID: 7, Name: "Harbour 10K", Timezone: "Europe/London"}, nil
+		},
+	}, &servicemocks.UserServiceMock{})
+	app.organisationService = memberOrganisationService(7, map[int64]int64{4: 7, 5: 8})
+	app.contactService = &servicemocks.ContactServiceMock{
+		ListEnquiriesFunc: func(ctx context.Context, eventID int64) ([]db.EventEnquiry, error) {
+			return []db.EventEnquiry{{
+				ID:        1,
+				EventID:   eventID,
+				Name:      "Ada Lovelace",
+				Email:     "ada@example.com",
+				Message:   "Is there parking near the start?",
+				CreatedAt: pgtype.Timestamptz{Time: time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC), Valid: true},
+			}}, nil
+		},
+	}
+	serve := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/events/"+id+"/enquiries", http.NoBody)
+		req.SetPathValue("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyUser, organiser))
+		rr := httptest.NewRecorder()
+		withSession(app, app.adminEnquiriesView).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("lists the event's enquiries in its time zone", func(t *testing.T) {
+		rr := serve("4")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"Ada Lovelace", `href="mailto:ada@example.com"`, "Is there parking near the start?", "1 May 2026 10:30"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("returns 404 for another organisation's event", func(t *testing.T) {
+		if rr := serve("5"); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
 		}
 	})
 }
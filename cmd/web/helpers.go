@@ -1,62 +1,303 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
-	"runtime/debug"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/a-h/templ"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/repository"
+	"firecrest/internal/service"
+	"firecrest/ui/templates"
+	"firecrest/ui/viewmodels"
 )
 
+// dbTimeout bounds the database work done on behalf of a single request.
+const dbTimeout = 3 * time.Second
+
+// retryAfterSeconds is how long clients are asked to wait before retrying
+// a request the database was too slow to answer.
+const retryAfterSeconds = "5"
+
+// dbContext derives the context handlers pass to services, so database work
+// stops when the client goes away or after dbTimeout.
+func (app *application) dbContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), dbTimeout)
+}
+
+// clientGone reports whether err was caused by the client cancelling the
+// request. Nobody is left to read a response, so none is written.
+func (app *application) clientGone(r *http.Request, err error) bool {
+	if !errors.Is(err, context.Canceled) {
+		return false
+	}
+	app.logger.Debug("request cancelled by client", "method", r.Method, "uri", r.URL.RequestURI())
+	return true
+}
+
+// serverError logs err and shows the 500 page, which names the request so
+// the viewer can quote it. Only in development does the page include the
+// error, the request and, for panics, the stack. Database timeouts are
+// shown as 503 instead, and API requests get the problem apiError writes.
 func (app *application) serverError(w http.ResponseWriter, r *http.Request, err error) {
+	if app.clientGone(r, err) {
+		return
+	}
+	if isAPI(r) {
+		app.apiError(w, r, err)
+		return
+	}
+
 	var (
 		method = r.Method
 		uri    = r.URL.RequestURI()
-		trace  = string(debug.Stack())
 	)
 
-	app.logger.Error(err.Error(), "method", method, "uri", uri, "trace", trace)
+	if errors.Is(err, repository.ErrTimeout) {
+		app.logger.Warn(err.Error(), "request_id", getRequestID(r), "method", method, "uri", uri)
+		app.serviceUnavailable(w, r)
+		return
+	}
+
+	detail := viewmodels.ErrorDetail{RequestID: errorRequestID(r)}
+	trace := panicTrace(err)
+	app.logger.Error(err.Error(), "request_id", detail.RequestID, "method", method, "uri", uri, "trace", trace)
+	if app.debug {
+		detail.Error, detail.Method, detail.URI, detail.Trace = err.Error(), method, uri, trace
+	}
+	app.showError(w, r, http.StatusInternalServerError, "Something went wrong",
+		"Sorry, something went wrong on our end. Please try again.", detail)
+}
+
+// serviceErrorStatus returns the status answering a request whose service
+// call failed with err: the one the error itself carries, 503 when time ran
+// out, and 500 for errors nobody described to the client.
+func serviceErrorStatus(err error) int {
+	if isTimeout(err) {
+		return http.StatusServiceUnavailable
+	}
+	return apperr.StatusOf(err)
+}
+
+// isTimeout reports whether err means the database, or the request's own
+// deadline, ran out of time.
+func isTimeout(err error) bool {
+	return errors.Is(err, repository.ErrTimeout) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// handleServiceError answers a request whose service call failed with err,
+// with the status serviceErrorStatus gives it: the 404 page, the error page
+// for the client's mistakes, 503 when time ran out and serverError for the
+// rest. Handlers deal
+// with the errors they can explain better, such as showing field errors in
+// their form, before handing the remainder here.
+func (app *application) handleServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if isAPI(r) {
+		app.apiError(w, r, err)
+		return
+	}
+
+	switch status := serviceErrorStatus(err); status {
+	case http.StatusNotFound:
+		app.notFound(w, r)
+	case http.StatusServiceUnavailable:
+		app.logger.Warn(err.Error(), "request_id", getRequestID(r), "method", r.Method, "uri", r.URL.RequestURI())
+		app.serviceUnavailable(w, r)
+	case http.StatusInternalServerError:
+		app.serverError(w, r, err)
+	default:
+		app.clientError(w, r, status)
+	}
+}
+
+// errorRequestID returns the ID naming the request in the server log.
+// Requests outside the requestID middleware still need an ID the client
+// can quote and the log can be searched for, so they are given one.
+func errorRequestID(r *http.Request) string {
+	if id := getRequestID(r); id != "" {
+		return id
+	}
+	return rand.Text()
+}
+
+// serviceUnavailable tells the client the database is too slow to answer
+// right now and to try again shortly.
+func (app *application) serviceUnavailable(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Retry-After", retryAfterSeconds)
+	app.errorPage(w, r, http.StatusServiceUnavailable, "Service unavailable",
+		"We're taking longer than usual to respond. Please try again in a moment.")
+}
+
+// errorPage answers a failed request with status: a problem for API
+// requests, the error message alone for htmx to swap in, and otherwise the
+// error page in the site layout.
+func (app *application) errorPage(w http.ResponseWriter, r *http.Request, status int, title, message string) {
+	app.showError(w, r, status, title, message, viewmodels.ErrorDetail{})
+}
+
+// showError is errorPage with detail about the failed request added to the
+// page or message.
+func (app *application) showError(w http.ResponseWriter, r *http.Request, status int, title, message string, detail viewmodels.ErrorDetail) {
+	switch {
+	case isAPI(r):
+		app.writeProblem(w, r, newProblem(status, problemCode(status), message))
+	case isHTMX(r):
+		app.renderPartial(w, r, status, templates.ErrorMessage(status, title, message, detail))
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		app.render(r.Context(), w, status, templates.ErrorPage(status, title, message, detail))
+	}
+}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusInternalServerError)
-	//nolint:errcheck // Best effort write in error handler
-	w.Write([]byte(`
-		<!DOCTYPE html>
-		<html lang="en">
-		<head>
-			<meta charset="UTF-8">
-			<title>Server Error</title>
-			<style>
-				body { font-family: sans-serif; background: #f8f8f8; color: #333; padding: 2em; }
-				.container { max-width: 600px; margin: auto; background: #fff; border-radius: 8px; box-shadow: 0 2px 8px rgba(0,0,0,0.1); padding: 2em; }
-				h1 { color: #c00; }
-			</style>
-		</head>
-		<body>
-			<div class="container">
-				<h1>500 - Server Error</h1>
-				<p>Sorry, something went wrong on our end.</p>
-			</div>
-		</body>
-		</html>
-	`))
-}
-
-//nolint:unparam // status parameter kept for future flexibility with different HTTP status codes
 func (app *application) render(ctx context.Context, w http.ResponseWriter, status int, component templ.Component) {
+	// Render in full before writing, so a failure part way through leaves
+	// no half page behind
+	var buf bytes.Buffer
+	if err := component.Render(ctx, &buf); err != nil {
+		if !errors.Is(err, context.Canceled) {
+			app.logger.Error("failed to render component", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
 	w.WriteHeader(status)
+	//nolint:errcheck // The client going away is not worth reporting
+	buf.WriteTo(w)
+}
+
+// renderPartial renders component, a fragment of a page rather than a whole
+// page in the layout, for htmx to swap into the page the browser already
+// shows. Fragments do not start with a tag content sniffing recognises, so
+// the type is set here.
+func (app *application) renderPartial(w http.ResponseWriter, r *http.Request, status int, component templ.Component) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	app.render(r.Context(), w, status, component)
+}
+
+// isHTMX reports whether htmx sent the request to swap part of a page in
+// place. htmx restoring a page from history asks for all of it, so those
+// requests do not count.
+func isHTMX(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-History-Restore-Request") != "true"
+}
 
-	if err := component.Render(ctx, w); err != nil {
-		app.logger.Error("failed to render component", "error", err)
+// isAPI reports whether the request was made to the JSON API, whose errors
+// are problems rather than pages.
+func isAPI(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/")
+}
+
+// clientError shows the error page for a request the client got wrong.
+func (app *application) clientError(w http.ResponseWriter, r *http.Request, status int) {
+	message := "The request could not be completed."
+	switch status {
+	case http.StatusBadRequest:
+		message = "Something in the request wasn't right. Please go back and try again."
+	case http.StatusUnauthorized:
+		message = "Please sign in again and try once more."
+	case http.StatusForbidden:
+		message = "You don't have permission to do that."
+	case http.StatusConflict:
+		message = "That clashes with something that already exists."
+	case http.StatusUnprocessableEntity:
+		message = "Some of what you entered wasn't valid. Please go back and check it."
+	case http.StatusTooManyRequests:
+		message = "Too many attempts. Please wait a while and try again."
+	case http.StatusMethodNotAllowed:
+		message = "That isn't something this page can do."
+	case http.StatusRequestEntityTooLarge:
+		message = "What you sent is too large."
 	}
+	app.errorPage(w, r, status, http.StatusText(status), message)
 }
 
-func (app *application) clientError(w http.ResponseWriter, status int) {
-	http.Error(w, http.StatusText(status), status)
+// notFound shows the 404 page, which offers a search of the events.
+func (app *application) notFound(w http.ResponseWriter, r *http.Request) {
+	app.errorPage(w, r, http.StatusNotFound, "Page not found",
+		"The page you were looking for doesn't exist or has moved.")
 }
 
-func (app *application) notFound(w http.ResponseWriter) {
-	app.clientError(w, http.StatusNotFound)
+func (app *application) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		app.logger.Error("failed to encode JSON response", "error", err)
+	}
+}
+
+// apiUnauthorized writes a 401 problem challenging the client for a bearer
+// token. Codes other than "unauthorized" describe what was wrong with the
+// one presented.
+func (app *application) apiUnauthorized(w http.ResponseWriter, r *http.Request, code, detail string) {
+	challenge := "Bearer"
+	if code != "unauthorized" {
+		challenge += fmt.Sprintf(" error=%q", code)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	app.writeProblem(w, r, newProblem(http.StatusUnauthorized, code, detail))
+}
+
+// apiInsufficientScope writes a 403 problem for an API token that was not
+// granted scope.
+func (app *application) apiInsufficientScope(w http.ResponseWriter, r *http.Request, scope string) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer error=\"insufficient_scope\", scope=%q", scope))
+	app.writeProblem(w, r, newProblem(http.StatusForbidden, "insufficient_scope", "the API token needs the "+scope+" scope"))
+}
+
+// signInURL returns the sign-in page, which returns the user to next once
+// they have signed in. next must be a path safeRedirectPath accepts, or
+// empty to sign in from nowhere in particular.
+func signInURL(next string) string {
+	if next == "" {
+		return "/auth/sign-in"
+	}
+	return "/auth/sign-in?next=" + url.QueryEscape(next)
+}
+
+// redirectToSignIn sends a visitor who must sign in to the sign-in page,
+// asking it to return them to the page they wanted. Only GET requests are
+// returned to, as a redirect cannot repeat a form's submission.
+func (app *application) redirectToSignIn(w http.ResponseWriter, r *http.Request) {
+	app.addFlash(r, FlashError, "Please sign in to continue")
+	next := ""
+	if r.Method == http.MethodGet {
+		next, _ = safeRedirectPath(r.URL.RequestURI())
+	}
+	http.Redirect(w, r, signInURL(next), http.StatusSeeOther)
+}
+
+// safeRedirectPath returns target if it is a path on this site, and so safe
+// to redirect to when it comes from the request, as the page to return to
+// after signing in does. Anything else could send the user to another
+// site: absolute URLs, protocol-relative ones such as //evil.com, and
+// backslashes or control characters, which browsers may read as slashes or
+// drop, are all refused.
+func safeRedirectPath(target string) (string, bool) {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		return "", false
+	}
+	for _, c := range target {
+		if c == '\\' || c < 0x20 || c == 0x7f {
+			return "", false
+		}
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil {
+		return "", false
+	}
+	return target, true
 }
 
 // Flash message types
@@ -77,6 +318,16 @@ func (app *application) getFlash(r *http.Request, messageType string) string {
 	return app.sessionManager.PopString(r.Context(), "flash_"+messageType)
 }
 
+// hasFlashes reports whether any flash message is waiting to be shown.
+func (app *application) hasFlashes(r *http.Request) bool {
+	for _, messageType := range []string{FlashSuccess, FlashError, FlashInfo, FlashWarning} {
+		if app.sessionManager.Exists(r.Context(), "flash_"+messageType) {
+			return true
+		}
+	}
+	return false
+}
+
 // getAllFlashes retrieves all flash messages and returns them in a map.
 func (app *application) getAllFlashes(r *http.Request) map[string]string {
 	flashes := make(map[string]string)
@@ -97,12 +348,135 @@ func (app *application) getAllFlashes(r *http.Request) map[string]string {
 	return flashes
 }
 
+// sessionExpiresAtKey holds the absolute expiry, in Unix seconds, fixed when
+// the user signs in.
+const sessionExpiresAtKey = "expiresAt"
+
+// sessionIDKey holds the id of the user_sessions row recording the
+// signed-in session.
+const sessionIDKey = "sessionID"
+
+// sessionVersionKey holds the user's session version when they signed in.
+// Signing out everywhere moves the version on, ending sessions that hold an
+// older one, which is how cookie sessions are revoked.
+const sessionVersionKey = "sessionVersion"
+
+// sessionImpersonatedUserIDKey holds the id of the user a site admin is
+// acting as. The session's userID stays the admin's throughout.
+const sessionImpersonatedUserIDKey = "impersonatedUserID"
+
+// sessionEmailUnverifiedKey marks a session signed in within the
+// verification grace period, before the user verified their email.
+const sessionEmailUnverifiedKey = "emailUnverified"
+
+// sessionOrganisationIDKey holds the organisation admin pages are scoped to,
+// chosen with the header's switcher or by following a link into another
+// of the user's organisations.
+const sessionOrganisationIDKey = "organisationID"
+
+// startSession signs the user in to a freshly renewed session and records
+// the device it was started from. The session lasts sessionLifetime, or the
+// remember-me lifetime when asked for, from now regardless of later
+// activity.
+func (app *application) startSession(dbCtx context.Context, r *http.Request, userID int64, rememberMe bool) error {
+	ctx := r.Context()
+	if err := app.sessionManager.RenewToken(ctx); err != nil {
+		return err
+	}
+
+	lifetime := app.sessionLifetime
+	if rememberMe {
+		lifetime = app.rememberMeLifetime
+	}
+	expiresAt := app.clock.Now().Add(lifetime)
+
+	user, err := app.userService.GetUser(dbCtx, userID)
+	if err != nil {
+		return err
+	}
+	session, err := app.sessionService.StartSession(dbCtx, userID, service.StartSessionParams{
+		UserAgent: r.UserAgent(),
+		IPAddress: getClientIP(r),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	app.sessionManager.SetDeadline(ctx, expiresAt)
+	app.sessionManager.Put(ctx, sessionExpiresAtKey, expiresAt.Unix())
+	app.sessionManager.Put(ctx, sessionIDKey, session.ID)
+	app.sessionManager.Put(ctx, sessionVersionKey, user.SessionVersion)
+	app.sessionManager.Put(ctx, "userID", userID)
+	return nil
+}
+
+// impersonatedUser returns the user the signed-in admin is acting as, and
+// false when they act as no one. Impersonation ends, without error, once
+// the admin may no longer act as the user, as when the user is deleted.
+func (app *application) impersonatedUser(r *http.Request, admin db.User) (db.User, bool, error) {
+	id := app.sessionManager.GetInt64(r.Context(), sessionImpersonatedUserIDKey)
+	if id == 0 {
+		return db.User{}, false, nil
+	}
+
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+	user, err := app.userService.GetUser(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return db.User{}, false, err
+	}
+	if err != nil || service.CanImpersonate(admin, user) != nil {
+		app.sessionManager.Remove(r.Context(), sessionImpersonatedUserIDKey)
+		return db.User{}, false, nil
+	}
+	return user, true, nil
+}
+
+// getSessionID retrieves the id of the signed-in session's record.
+func (app *application) getSessionID(r *http.Request) int64 {
+	return app.sessionManager.GetInt64(r.Context(), sessionIDKey)
+}
+
+// destroyUserSessions signs the user out of every session they have,
+// including the current request's.
+func (app *application) destroyUserSessions(r *http.Request, userID int64) error {
+	err := app.sessionManager.Iterate(r.Context(), func(ctx context.Context) error {
+		if app.sessionManager.GetInt64(ctx, "userID") != userID {
+			return nil
+		}
+		return app.sessionManager.Destroy(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	return app.sessionManager.Destroy(r.Context())
+}
+
+// sessionExpired reports whether the signed-in session has passed its
+// absolute expiry. Sessions without one predate it and count as expired.
+func (app *application) sessionExpired(r *http.Request) bool {
+	expiresAt := app.sessionManager.GetInt64(r.Context(), sessionExpiresAtKey)
+	return !app.clock.Now().Before(time.Unix(expiresAt, 0))
+}
+
 // isAuthenticated returns true if the user is authenticated.
 func (app *application) isAuthenticated(r *http.Request) bool {
 	return app.sessionManager.Exists(r.Context(), "userID")
 }
 
-// getUserID retrieves the authenticated user's ID from the session.
+// getUserID retrieves the ID of the user the request acts as: the
+// authenticated user, or the user a site admin is impersonating.
 func (app *application) getUserID(r *http.Request) int64 {
+	if user, ok := getUserFromContext(r); ok {
+		return user.ID
+	}
+	return app.getRealUserID(r)
+}
+
+// getRealUserID retrieves the authenticated user's ID from the session,
+// which stays the admin's while they impersonate someone. The session's
+// own records, such as its devices, belong to this user.
+func (app *application) getRealUserID(r *http.Request) int64 {
 	return app.sessionManager.GetInt64(r.Context(), "userID")
 }
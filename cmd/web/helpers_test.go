@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"firecrest/internal/mocks/servicemocks"
+	"firecrest/internal/repository"
+	"firecrest/internal/service"
+)
+
+func TestErrorPages(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+	t.Run("offers a search on the not found page", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.notFound(rr, httptest.NewRequest(http.MethodGet, "/nowhere", http.NoBody))
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"<!doctype html>", `data-error-page="404"`, `action="/events"`, `name="q"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("keeps the error out of the server error page", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.serverError(rr, httptest.NewRequest(http.MethodGet, "/", http.NoBody), errors.New("pq: relation \"events\" does not exist"))
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, `data-error-page="500"`) {
+			t.Errorf("expected the error page, got %s", body)
+		}
+		if strings.Contains(body, "relation") || strings.Contains(body, "data-error-search") {
+			t.Errorf("expected no detail or search on the 500 page, got %s", body)
+		}
+	})
+
+	t.Run("shows client errors in the layout", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.clientError(rr, httptest.NewRequest(http.MethodGet, "/admin", http.NoBody), http.StatusForbidden)
+
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "<!doctype html>") || !strings.Contains(body, `data-error-page="403"`) {
+			t.Errorf("expected the 403 page in the layout, got %s", body)
+		}
+	})
+}
+
+func TestErrorPageNegotiation(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+	t.Run("answers API requests with a problem", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.notFound(rr, httptest.NewRequest(http.MethodGet, "/api/v1/nowhere", http.NoBody))
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "application/problem+json" {
+			t.Errorf("expected a problem content type, got %q", got)
+		}
+		if got := decodeProblem(t, rr); got.Code != "not_found" || got.Status != http.StatusNotFound {
+			t.Errorf("unexpected problem %+v", got)
+		}
+	})
+
+	t.Run("answers htmx requests with the message alone", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/nowhere", http.NoBody)
+		req.Header.Set("HX-Request", "true")
+		rr := httptest.NewRecorder()
+		app.notFound(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		body := rr.Body.String()
+		if strings.Contains(body, "<!doctype") {
+			t.Errorf("expected a fragment without the layout, got %s", body)
+		}
+		if !strings.Contains(body, `data-error="404"`) {
+			t.Errorf("expected the error message, got %s", body)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+			t.Errorf("expected an HTML content type, got %q", got)
+		}
+	})
+}
+
+func TestServerErrorDetail(t *testing.T) {
+	serve := func(debug bool, path string) string {
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.debug = debug
+		h := requestID(app.recoverPanic(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("<script>boom</script>")
+		})))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, http.NoBody))
+		if rr.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+		if id := rr.Header().Get("X-Request-ID"); !strings.Contains(rr.Body.String(), id) {
+			t.Errorf("expected the page to quote request %q", id)
+		}
+		return rr.Body.String()
+	}
+
+	t.Run("shows the error and stack in development", func(t *testing.T) {
+		body := serve(true, "/events/7/register")
+
+		for _, want := range []string{"data-error-debug", "POST /events/7/register", "panic: &lt;script&gt;boom&lt;/script&gt;", "data-error-trace"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected body to contain %q, got %s", want, body)
+			}
+		}
+		if strings.Contains(body, "<script>boom") {
+			t.Error("expected the error to be escaped")
+		}
+		// The stack is taken where the panic happened, not where it was reported
+		if !strings.Contains(body, "helpers_test.go") {
+			t.Errorf("expected the stack to include the panicking frame, got %s", body)
+		}
+	})
+
+	t.Run("keeps the detail out of production pages", func(t *testing.T) {
+		body := serve(false, "/events/7/register")
+
+		if !strings.Contains(body, "data-error-request-id") {
+			t.Error("expected the request ID to be shown")
+		}
+		for _, unwanted := range []string{"data-error-debug", "data-error-trace", "boom", "goroutine"} {
+			if strings.Contains(body, unwanted) {
+				t.Errorf("expected body not to contain %q", unwanted)
+			}
+		}
+	})
+}
+
+func TestServiceErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "missing resources", err: fmt.Errorf("failed to get event: %w", repository.ErrNotFound), want: http.StatusNotFound},
+		{name: "invalid input", err: service.ErrInvalidInput, want: http.StatusBadRequest},
+		{name: "invalid fields", err: service.FieldErrors{"name": "name is required"}, want: http.StatusUnprocessableEntity},
+		{name: "conflicts", err: repository.ErrConflict, want: http.StatusConflict},
+		{name: "wrong credentials", err: service.ErrInvalidCredentials, want: http.StatusUnauthorized},
+		{name: "locked accounts", err: service.ErrAccountLocked, want: http.StatusTooManyRequests},
+		{name: "forbidden actions", err: service.ErrForbidden, want: http.StatusForbidden},
+		{name: "database timeouts", err: fmt.Errorf("failed to list events: %w", repository.ErrTimeout), want: http.StatusServiceUnavailable},
+		{name: "request deadlines", err: context.DeadlineExceeded, want: http.StatusServiceUnavailable},
+		{name: "anything else", err: errors.New("connection refused"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serviceErrorStatus(tt.err); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleServiceError(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+	serve := func(target string, err error) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.handleServiceError(rr, httptest.NewRequest(http.MethodGet, target, http.NoBody), err)
+		return rr
+	}
+
+	t.Run("shows the not found page", func(t *testing.T) {
+		rr := serve("/events/2026/nowhere", repository.ErrNotFound)
+
+		if rr.Code != http.StatusNotFound || !strings.Contains(rr.Body.String(), `data-error-page="404"`) {
+			t.Errorf("expected the 404 page, got %d", rr.Code)
+		}
+	})
+
+	t.Run("asks the client to retry when time runs out", func(t *testing.T) {
+		rr := serve("/", context.DeadlineExceeded)
+
+		if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") == "" {
+			t.Errorf("expected 503 with Retry-After, got %d", rr.Code)
+		}
+	})
+
+	t.Run("shows client errors in the layout", func(t *testing.T) {
+		rr := serve("/admin/users/4/role", service.ErrForbidden)
+
+		if rr.Code != http.StatusForbidden || !strings.Contains(rr.Body.String(), `data-error-page="403"`) {
+			t.Errorf("expected the 403 page, got %d", rr.Code)
+		}
+	})
+
+	t.Run("keeps other errors off the page", func(t *testing.T) {
+		rr := serve("/", errors.New("pq: relation \"events\" does not exist"))
+
+		if rr.Code != http.StatusInternalServerError || strings.Contains(rr.Body.String(), "relation") {
+			t.Errorf("expected the bare 500 page, got %d", rr.Code)
+		}
+	})
+
+	t.Run("answers API requests with a problem", func(t *testing.T) {
+		rr := serve("/api/v1/events/nowhere", repository.ErrConflict)
+
+		if got := decodeProblem(t, rr); got.Status != http.StatusConflict || got.Code != "conflict" {
+			t.Errorf("unexpected problem %+v", got)
+		}
+	})
+}
+
+func TestSafeRedirectPath(t *testing.T) {
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{target: "/", want: true},
+		{target: "/events", want: true},
+		{target: "/account/registrations/4", want: true},
+		{target: "/admin/dashboard?organisation=2", want: true},
+		{target: "/events/2026/lincoln-10k#races", want: true},
+		{target: "/search?q=%2F%2Fevil.com", want: true},
+		{target: ""},
+		{target: "events"},
+		{target: "./events"},
+		{target: "http://evil.com"},
+		{target: "https://evil.com/account"},
+		{target: "HTTPS://evil.com"},
+		{target: "http:/evil.com"},
+		{target: "javascript:alert(1)"},
+		{target: "//evil.com"},
+		{target: "///evil.com"},
+		{target: "//evil.com/account"},
+		{target: "/\\evil.com"},
+		{target: "\\\\evil.com"},
+		{target: "\\/evil.com"},
+		{target: "/\\/evil.com"},
+		{target: "/\t/evil.com"},
+		{target: "/\n/evil.com"},
+		{target: "/\r\nSet-Cookie: a=b"},
+		{target: "/\x00"},
+		{target: "/\x7f"},
+		{target: " /events"},
+		{target: "/%zz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.target), func(t *testing.T) {
+			got, ok := safeRedirectPath(tt.target)
+			if ok != tt.want {
+				t.Fatalf("expected ok %v, got %v", tt.want, ok)
+			}
+			if ok && got != tt.target {
+				t.Errorf("expected %q unchanged, got %q", tt.target, got)
+			}
+			if !ok && got != "" {
+				t.Errorf("expected no path for a rejected target, got %q", got)
+			}
+		})
+	}
+}
@@ -2,28 +2,105 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
+	// Events name IANA time zones, so the binary carries its own copy of
+	// the zone database rather than relying on the host's
+	_ "time/tzdata"
 
 	"github.com/alexedwards/scs/pgxstore"
 	"github.com/alexedwards/scs/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 	"github.com/joho/godotenv"
 
 	"firecrest/db"
+	"firecrest/internal/bus"
+	"firecrest/internal/config"
+	"firecrest/internal/database"
+	"firecrest/internal/i18n"
+	"firecrest/internal/jobs"
+	"firecrest/internal/mail"
+	"firecrest/internal/metrics"
+	"firecrest/internal/migrate"
 	"firecrest/internal/repository"
+	"firecrest/internal/secret"
 	"firecrest/internal/service"
+	"firecrest/internal/session"
+	"firecrest/internal/storage"
+	"firecrest/internal/token"
+	"firecrest/internal/tracing"
+	"firecrest/internal/webhook"
 )
 
 type application struct {
-	logger         *slog.Logger
-	sessionManager *scs.SessionManager
-	eventService   service.EventService
-	userService    service.UserService
-	authService    service.AuthService
+	logger              *slog.Logger
+	sessionManager      SessionManager
+	eventService        service.EventService
+	userService         service.UserService
+	authService         service.AuthService
+	organisationService service.OrganisationService
+	raceService         service.RaceService
+	registrationCounter service.RegistrationCounter
+	registrationService service.RegistrationService
+	discountService     service.DiscountService
+	photoService        service.PhotoService
+	brandingService     service.BrandingService
+	resultService       service.ResultService
+	tokenService        service.TokenService
+	sessionService      service.SessionService
+	webhookService      service.WebhookService
+	contactService      service.ContactService
+	dataExportService   service.DataExportService
+	paymentService      service.PaymentService
+	metrics             *metrics.Metrics
+	clock               service.Clock
+	// translations holds the site's copy in each supported language; see
+	// localise.
+	translations *i18n.Bundle
+	// availability wakes the availability streams open on event pages.
+	availability *availabilityHub
+	// contactLimiter bounds how often one client may send the organisers'
+	// contact form.
+	contactLimiter *rateLimiter
+	// sessionLifetime is how long a sign-in lasts, and rememberMeLifetime
+	// how long one lasts with "remember me".
+	sessionLifetime    time.Duration
+	rememberMeLifetime time.Duration
+	// importMaxRows is the most entrants or results accepted in one
+	// upload, as enforced by the registration and result services.
+	importMaxRows int
+	// serveMetrics mounts the metrics endpoint on the main router. It is
+	// false when METRICS_ADDR gives metrics a listener of their own.
+	serveMetrics bool
+	// stripeWebhookSecret checks the signatures of Stripe's webhook events.
+	// Empty leaves the endpoint unserved.
+	stripeWebhookSecret string
+	// trustedProxies are the peers whose forwarding headers realIP believes.
+	trustedProxies []netip.Prefix
+	// publicBaseURL roots the links in the sitemap and robots.txt, without
+	// a trailing slash.
+	publicBaseURL string
+	// mockEvents, when set, supplies the home and event pages in place of
+	// the database. See events.
+	mockEvents *MockEventSource
+	// staticDir, when set, is the directory static files are read from in
+	// place of the embedded copies.
+	staticDir string
+	// media serves files from the local blob store at /media. It is nil
+	// when files are kept in S3, which serves them itself.
+	media http.Handler
+	// debug shows the error, request and stack on the 500 page. It is
+	// only set in development.
+	debug bool
 }
 
 func main() {
@@ -41,71 +118,330 @@ func run() error {
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{AddSource: true}))
 
-	// Get database configuration from environment variables
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "postgres")
-	dbName := getEnv("DB_NAME", "firecrest")
-	dbSSLMode := getEnv("DB_SSLMODE", "disable")
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
 
-	// Build connection string
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		dbUser, dbPassword, dbHost, dbPort, dbName, dbSSLMode)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		// Sends the spans still buffered
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("failed to flush traces", "error", err)
+		}
+	}()
+	var queryTracer pgx.QueryTracer
+	if cfg.Tracing.Enabled() {
+		queryTracer = tracing.QueryTracer()
+	}
 
-	dbpool, err := pgxpool.New(context.Background(), dsn)
+	dbpool, err := database.Connect(context.Background(), cfg.DB, logger, queryTracer)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer dbpool.Close()
 
-	queries := db.New(dbpool)
+	if cfg.DBAutoMigrate {
+		if err := migrate.Up(context.Background(), dbpool); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+	// Queries written for another schema fail in confusing ways, so refuse
+	// to start on one
+	switch err := migrate.Check(context.Background(), dbpool); {
+	case errors.Is(err, migrate.ErrPending):
+		return fmt.Errorf("%w; apply the migrations with `go run ./cmd/migrate up` or DB_AUTO_MIGRATE=true", err)
+	case err != nil:
+		return fmt.Errorf("failed to check database schema: %w", err)
+	}
 
-	// Initialize session manager
-	sessionManager := scs.New()
-	sessionManager.Store = pgxstore.New(dbpool)
-	sessionManager.Lifetime = 12 * time.Hour
-	sessionManager.Cookie.Name = "firecrest_session"
-	sessionManager.Cookie.HttpOnly = true
-	sessionManager.Cookie.SameSite = http.SameSiteLaxMode
-	sessionManager.Cookie.Secure = false // Set to true in production with HTTPS
+	// Transactions begin on the pool itself, so only calls made outside
+	// them are bounded and retried
+	queries := db.New(repository.NewTimeoutDB(dbpool, time.Duration(cfg.DBQueryTimeoutMS)*time.Millisecond))
+
+	appMetrics := metrics.New()
+	if err := appMetrics.Register(metrics.NewPoolCollector(dbpool)); err != nil {
+		return fmt.Errorf("failed to register pool metrics: %w", err)
+	}
+
+	// Initialize session manager. Cookie sessions need no database round
+	// trip to read; without a configured key (development only), they end
+	// when the server restarts.
+	cookie := scs.SessionCookie{
+		Name:     "firecrest_session",
+		Path:     "/",
+		HttpOnly: true,
+		Persist:  true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   false, // Set to true in production with HTTPS
+	}
+	var sessionManager SessionManager
+	switch cfg.Session.Backend {
+	case config.SessionBackendCookie:
+		sessionKey := cfg.Session.Key
+		if len(sessionKey) == 0 {
+			logger.Warn("SESSION_KEY not set, using a random key")
+			sessionKey = make([]byte, session.KeyLength)
+			rand.Read(sessionKey)
+		}
+		cookies, err := session.NewCookieManager(sessionKey)
+		if err != nil {
+			return fmt.Errorf("failed to create cookie session manager: %w", err)
+		}
+		cookies.Lifetime = cfg.Session.Lifetime
+		cookies.Cookie = cookie
+		sessionManager = cookies
+	default:
+		stored := scs.New()
+		stored.Store = pgxstore.New(dbpool)
+		stored.Lifetime = cfg.Session.Lifetime
+		stored.Cookie = cookie
+		sessionManager = stored
+	}
 
 	// Initialize repositories
-	eventRepo := repository.NewEventRepository(queries)
-	userRepo := repository.NewUserRepository(queries)
-	authRepo := repository.NewAuthRepository(queries)
+	eventRepo := repository.NewEventRepository(queries, dbpool)
+	userRepo := repository.NewUserRepository(queries, dbpool)
+	authRepo := repository.NewAuthRepository(queries, dbpool)
+	orgRepo := repository.NewOrganisationRepository(queries, dbpool)
+	raceRepo := repository.NewRaceRepository(queries, dbpool)
+	registrationRepo := repository.NewRegistrationRepository(queries, dbpool)
+	paymentRepo := repository.NewPaymentRepository(queries)
+	resultRepo := repository.NewResultRepository(queries, dbpool)
+	apiTokenRepo := repository.NewAPITokenRepository(queries)
+	discountRepo := repository.NewDiscountRepository(queries)
+	sessionRepo := repository.NewSessionRepository(queries, dbpool)
+	webhookRepo := repository.NewWebhookRepository(queries, dbpool)
+	enquiryRepo := repository.NewEnquiryRepository(queries)
+
+	// Initialize mailer. Without an SMTP relay, emails are logged instead.
+	var mailer mail.Mailer
+	if cfg.SMTP.Host == "" {
+		mailer = mail.NewConsoleMailer(logger)
+	} else {
+		queue := mail.NewQueue(mail.NewSMTPMailer(cfg.SMTP), logger, 2, 100)
+		defer queue.Close()
+		mailer = queue
+	}
+
+	// Initialize the token signer. Without a configured secret (development
+	// only), emailed links stop working when the server restarts.
+	tokenKey := []byte(cfg.TokenSecret)
+	if len(tokenKey) == 0 {
+		logger.Warn("TOKEN_SECRET not set, using a random key")
+		tokenKey = make([]byte, token.MinKeyLength)
+		rand.Read(tokenKey)
+	}
+	tokens, err := token.NewSigner(tokenKey)
+	if err != nil {
+		return fmt.Errorf("failed to create token signer: %w", err)
+	}
+
+	// Initialize the box questionnaire answers are encrypted with. Without a
+	// configured key (development only), answers stored before a restart
+	// can no longer be read.
+	answersKey := cfg.AnswersKey
+	if len(answersKey) == 0 {
+		logger.Warn("ANSWERS_KEY not set, using a random key")
+		answersKey = make([]byte, secret.KeyLength)
+		rand.Read(answersKey)
+	}
+	answersBox, err := secret.NewBox(answersKey)
+	if err != nil {
+		return fmt.Errorf("failed to create answers box: %w", err)
+	}
+
+	translations, err := i18n.New(logger)
+	if err != nil {
+		return fmt.Errorf("failed to load translations: %w", err)
+	}
+
+	// Initialize the blob store uploads are kept in. The local store serves
+	// its own files, so suits development and single-server deployments.
+	var store storage.BlobStore
+	var media http.Handler
+	switch cfg.Storage.Driver {
+	case config.StorageS3:
+		store, err = storage.NewS3Store(cfg.Storage.S3, &http.Client{Timeout: time.Minute})
+		if err != nil {
+			return fmt.Errorf("failed to create S3 store: %w", err)
+		}
+	default:
+		local, err := storage.NewLocalStore(cfg.Storage.Dir, "/media", tokenKey)
+		if err != nil {
+			return fmt.Errorf("failed to create local store: %w", err)
+		}
+		store, media = local, local
+	}
+	photoRepo := repository.NewPhotoRepository(queries, dbpool)
+	dataExportRepo := repository.NewDataExportRepository(queries)
+
+	// Changes to entries, events and races are published on the bus for
+	// the caches and webhooks that follow them
+	events := bus.New(logger)
 
 	// Initialize services
-	eventService := service.NewEventService(eventRepo)
+	eventService := service.NewEventService(eventRepo, raceRepo, events)
 	userService := service.NewUserService(userRepo)
-	authService := service.NewAuthService(authRepo, userRepo)
+	lockout := service.LockoutPolicy{
+		MaxAttempts:     cfg.AuthMaxAttempts,
+		LockoutDuration: time.Duration(cfg.AuthLockoutMinutes) * time.Minute,
+		Progressive:     cfg.AuthProgressiveDelays,
+	}
+	authService := service.NewAuthService(authRepo, userRepo, mailer, appMetrics, tokens, cfg.BaseURL, cfg.PasswordBcryptCost, lockout, time.Duration(cfg.AuthVerifyGraceHours)*time.Hour)
+	organisationService := service.NewOrganisationService(orgRepo, userRepo, eventRepo, mailer, tokens, cfg.BaseURL)
+	raceService := service.NewRaceService(raceRepo, registrationRepo, eventRepo, events)
+	registrationCounter := service.NewRegistrationCounter(registrationRepo, service.RegistrationCountTTL)
+	service.SubscribeRegistrationCounter(events, registrationCounter)
+	availability := newAvailabilityHub(availabilityHeartbeat)
+	availability.subscribe(events)
+	service.SubscribeWebhooks(events, registrationRepo, webhookRepo)
+	paymentService := service.NewPaymentService(paymentRepo)
+	discountService := service.NewDiscountService(discountRepo, raceRepo)
+	registrationService := service.NewRegistrationService(
+		registrationRepo,
+		orgRepo,
+		raceRepo,
+		paymentService,
+		discountService,
+		events,
+		mailer,
+		webhookRepo,
+		tokens,
+		answersBox,
+		cfg.BaseURL,
+		time.Duration(cfg.CancellationGraceHours)*time.Hour,
+		time.Duration(cfg.TransferCutoffHours)*time.Hour,
+		time.Duration(cfg.RegistrationEditLockHours)*time.Hour,
+		time.Duration(cfg.TeamFillHours)*time.Hour,
+		cfg.ImportMaxRows,
+		service.BibRange{From: cfg.BibReservedFrom, To: cfg.BibReservedTo},
+	)
+	photoService := service.NewPhotoService(photoRepo, store)
+	brandingService := service.NewBrandingService(orgRepo, store)
+	resultService := service.NewResultService(resultRepo, cfg.ImportMaxRows)
+	tokenService := service.NewTokenService(apiTokenRepo, userRepo)
+	sessionService := service.NewSessionService(sessionRepo, service.SessionLimit{
+		Max:    cfg.AuthMaxSessions,
+		Reject: cfg.AuthSessionPolicy == config.SessionPolicyReject,
+	})
+	webhookService := service.NewWebhookService(webhookRepo, webhook.NewHTTPSender(), answersBox)
+	contactService := service.NewContactService(enquiryRepo, orgRepo, mailer, tokens, cfg.BaseURL)
+	dataExportService := service.NewDataExportService(dataExportRepo, userRepo, registrationRepo, paymentRepo, sessionRepo, store, answersBox, tokens, mailer, cfg.BaseURL)
 
 	app := &application{
-		logger:         logger,
-		sessionManager: sessionManager,
-		eventService:   eventService,
-		userService:    userService,
-		authService:    authService,
+		logger:              logger,
+		sessionManager:      sessionManager,
+		eventService:        eventService,
+		userService:         userService,
+		authService:         authService,
+		organisationService: organisationService,
+		raceService:         raceService,
+		registrationCounter: registrationCounter,
+		registrationService: registrationService,
+		discountService:     discountService,
+		photoService:        photoService,
+		brandingService:     brandingService,
+		resultService:       resultService,
+		tokenService:        tokenService,
+		sessionService:      sessionService,
+		webhookService:      webhookService,
+		contactService:      contactService,
+		dataExportService:   dataExportService,
+		paymentService:      paymentService,
+		availability:        availability,
+		contactLimiter:      newRateLimiter(contactLimit, contactWindow, service.RealClock{}),
+		metrics:             appMetrics,
+		media:               media,
+		clock:               service.RealClock{},
+		translations:        translations,
+		sessionLifetime:     cfg.Session.Lifetime,
+		rememberMeLifetime:  cfg.Session.RememberLifetime,
+		importMaxRows:       cfg.ImportMaxRows,
+		serveMetrics:        cfg.MetricsAddr == "",
+		stripeWebhookSecret: cfg.StripeWebhookSecret,
+		trustedProxies:      cfg.TrustedProxies,
+		publicBaseURL:       strings.TrimRight(cfg.PublicBaseURL, "/"),
+		mockEvents:          mockEventSourceFor(cfg, time.Now()),
+		staticDir:           cfg.StaticDir,
+		debug:               cfg.IsDevelopment(),
+	}
+	if app.mockEvents != nil {
+		logger.Warn("USE_MOCK_DATA set, the home and event pages show fixture events")
+	}
+	if app.staticDir != "" {
+		logger.Warn("STATIC_DIR set, static files are read from disk", "dir", app.staticDir)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start background jobs. They are stopped before the mail queue and
+	// database pool close.
+	runner := jobs.NewRunner(logger)
+	runner.Register(jobs.ExpirePendingRegistrations(registrationRepo, service.RealClock{}, time.Duration(cfg.PendingRegistrationTTLHours)*time.Hour, logger))
+	runner.Register(jobs.UnlockAccounts(authRepo, logger))
+	runner.Register(jobs.PruneLoginEvents(authRepo, service.RealClock{}, logger))
+	runner.Register(jobs.SendRaceReminders(registrationService, logger))
+	runner.Register(jobs.SendRegistrationDigests(registrationService, logger))
+	runner.Register(jobs.ReleaseUnfilledTeams(registrationRepo, service.RealClock{}, logger))
+	runner.Register(jobs.DeliverWebhooks(webhookService, logger))
+	runner.Register(jobs.BuildDataExports(dataExportService, logger))
+	runner.Register(jobs.PruneDataExports(dataExportService, logger))
+	runner.Start(ctx)
+	defer runner.Stop()
+
+	if cfg.MetricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET "+metrics.Path, appMetrics.Handler())
+		metricsSrv := &http.Server{
+			Addr:         cfg.MetricsAddr,
+			Handler:      metricsMux,
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+		}
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("metrics server stopped", "error", err)
+			}
+		}()
+		fmt.Println("Serving metrics on " + cfg.MetricsAddr)
 	}
 
 	srv := &http.Server{
 		Addr:           ":8080",
 		Handler:        app.routes(),
-		ReadTimeout:    5 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		IdleTimeout:    120 * time.Second,
+		ReadTimeout:    cfg.Server.ReadTimeout,
+		WriteTimeout:   cfg.Server.WriteTimeout,
+		IdleTimeout:    cfg.Server.IdleTimeout,
 		MaxHeaderBytes: 1 << 20, // 1 MB
 	}
+	// Shutdown waits for requests to finish, which streams never do
+	srv.RegisterOnShutdown(availability.stop)
 
-	fmt.Println("Running server on :8080")
-	return srv.ListenAndServe()
-}
+	logger.Info("running server", "addr", srv.Addr, "env", cfg.Env, "bcrypt_cost", cfg.PasswordBcryptCost, "mock_data", cfg.MockDataEnabled(), "tracing", cfg.Tracing.Enabled())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
 
-// getEnv retrieves the value of an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+	logger.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
 	}
-	return value
+	events.Wait()
+	return nil
 }
@@ -2,11 +2,119 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
 	"net/http"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
 
 	"firecrest/db"
+	"firecrest/internal/i18n"
+	"firecrest/internal/service"
+	"firecrest/ui/viewmodels"
 )
 
+// recoverPanic turns a panicking handler into a 500 response. The connection
+// is closed afterwards as its state is unknown.
+func (app *application) recoverPanic(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				// http.ErrAbortHandler is how handlers deliberately abort a response
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				w.Header().Set("Connection", "close")
+				// The stack is taken here, while the panicking frame is
+				// still on it
+				app.serverError(w, r, &panicError{value: err, stack: debug.Stack()})
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// panicError is a panic recoverPanic caught, with the stack of the
+// goroutine at the point it panicked.
+type panicError struct {
+	value any
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// Unwrap returns the value panicked with, when it was an error.
+func (e *panicError) Unwrap() error {
+	err, _ := e.value.(error)
+	return err
+}
+
+// panicTrace returns the stack where err panicked, or "" if it was not a
+// panic.
+func panicTrace(err error) string {
+	var pe *panicError
+	if errors.As(err, &pe) {
+		return string(pe.stack)
+	}
+	return ""
+}
+
+// requestID tags each request with a random ID, sent back in the
+// X-Request-ID header and included in the request's log lines.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := rand.Text()
+		w.Header().Set("X-Request-ID", id)
+
+		ctx := context.WithValue(r.Context(), contextKeyRequestID, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// languageCookie remembers a language the visitor chose with ?lang=, for
+// a year from their last choice.
+const (
+	languageCookie    = "firecrest_lang"
+	languageCookieAge = 365 * 24 * time.Hour
+)
+
+// localise chooses the language of the request's pages and puts its
+// translator in the context. A supported ?lang= wins and is remembered in
+// a cookie; failing that the cookie, and failing that Accept-Language,
+// decides.
+func (app *application) localise(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale, ok := app.translations.Match(r.URL.Query().Get("lang"))
+		if ok {
+			http.SetCookie(w, &http.Cookie{
+				Name:     languageCookie,
+				Value:    locale,
+				Path:     "/",
+				MaxAge:   int(languageCookieAge.Seconds()),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		} else if c, err := r.Cookie(languageCookie); err == nil {
+			locale, ok = app.translations.Match(c.Value)
+		}
+		if !ok {
+			locale = app.translations.Negotiate(r.Header.Get("Accept-Language"))
+		}
+
+		w.Header().Set("Content-Language", locale)
+		w.Header().Add("Vary", "Accept-Language")
+
+		ctx := i18n.WithTranslator(r.Context(), app.translations.Translator(locale))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func commonHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -29,38 +137,145 @@ func commonHeaders(next http.Handler) http.Handler {
 	})
 }
 
+// crossOrigin lets other websites load what next serves, such as a script
+// they embed, which commonHeaders otherwise keeps to our own pages.
+func crossOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cross-Origin-Resource-Policy", "cross-origin")
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (app *application) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var (
-			ip     = r.RemoteAddr
+			ip     = getClientIP(r)
 			proto  = r.Proto
 			method = r.Method
 			uri    = r.URL.RequestURI()
+			id     = getRequestID(r)
 		)
 
-		app.logger.Info("request received", "ip", ip, "proto", proto, "method", method, "uri", uri)
+		app.logger.Info("request received", "request_id", id, "ip", ip, "proto", proto, "method", method, "uri", uri)
 
-		next.ServeHTTP(w, r)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(rec, r)
+
+		app.logger.Info("request completed", "request_id", id, "method", method, "uri", uri, "status", rec.status, "duration", time.Since(start))
 	})
 }
 
+// statusRecorder captures the status code written by downstream handlers.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(p []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(p)
+}
+
+// Unwrap exposes the underlying ResponseWriter to http.ResponseController.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
 // requireAuth ensures the user is authenticated.
 func (app *application) requireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !app.isAuthenticated(r) {
-			app.addFlash(r, FlashError, "Please sign in to continue")
-			http.Redirect(w, r, "/auth/sign-in", http.StatusSeeOther)
+			app.redirectToSignIn(w, r)
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
 
-// redirectIfAuth redirects authenticated users away from auth pages.
+// requireRole ensures the authenticated user has one of the given roles.
+// It must run after loadUser so the user is available in the context.
+func (app *application) requireRole(roles ...db.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := getUserFromContext(r)
+			if !ok {
+				app.redirectToSignIn(w, r)
+				return
+			}
+			if !slices.Contains(roles, user.Role) {
+				app.clientError(w, r, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// scopeOrganisation scopes admin pages to one of the organisations the user
+// works in, remembering it in the session, and gives the header a switcher
+// when they work in more than one.
+func (app *application) scopeOrganisation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := app.dbContext(r)
+		defer cancel()
+
+		user, _ := getUserFromContext(r)
+		orgs, err := app.managedOrganisations(ctx, user)
+		if err != nil {
+			if !app.clientGone(r, err) {
+				app.serverError(w, r, err)
+			}
+			return
+		}
+		if len(orgs) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		orgID, err := app.currentOrganisation(ctx, r, orgs)
+		if err != nil {
+			if !app.clientGone(r, err) {
+				app.serverError(w, r, err)
+			}
+			return
+		}
+		if app.sessionManager.GetInt64(r.Context(), sessionOrganisationIDKey) != orgID {
+			app.sessionManager.Put(r.Context(), sessionOrganisationIDKey, orgID)
+		}
+
+		if len(orgs) > 1 {
+			nav := viewmodels.NavFromContext(r.Context())
+			nav.Organisations = &viewmodels.OrganisationSwitcher{
+				CurrentID:     orgID,
+				Organisations: viewmodels.NewOrganisationOptions(orgs),
+			}
+			r = r.WithContext(viewmodels.WithNav(r.Context(), nav))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// redirectIfAuth redirects authenticated users away from auth pages, to
+// the page they were to be returned to after signing in, if any.
 func (app *application) redirectIfAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if app.isAuthenticated(r) {
-			http.Redirect(w, r, "/", http.StatusSeeOther)
+			target, ok := safeRedirectPath(r.URL.Query().Get("next"))
+			if !ok {
+				target = "/"
+			}
+			http.Redirect(w, r, target, http.StatusSeeOther)
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -71,32 +286,235 @@ func (app *application) redirectIfAuth(next http.Handler) http.Handler {
 func (app *application) loadUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if app.isAuthenticated(r) {
-			userID := app.getUserID(r)
-			user, err := app.userService.GetUser(r.Context(), userID)
-			if err != nil {
+			// Sessions past their absolute expiry must sign in again
+			if app.sessionExpired(r) {
+				if err := app.sessionManager.Destroy(r.Context()); err != nil {
+					app.logger.Error("failed to destroy session", "error", err)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID := app.getRealUserID(r)
+			dbCtx, cancel := app.dbContext(r)
+			// Sessions the user signed out from another device, and those
+			// started before sessions were recorded, are invalid too
+			err := app.sessionService.CheckSession(dbCtx, userID, app.getSessionID(r))
+			var user db.User
+			if err == nil {
+				user, err = app.userService.GetUser(dbCtx, userID)
+			}
+			cancel()
+			// A cancelled request says nothing about the session
+			if err != nil && app.clientGone(r, err) {
+				return
+			}
+			// Sessions of deleted or deactivated accounts that outlived
+			// the change are as invalid as those of unknown users, and so
+			// are those started before the user signed out everywhere
+			if err != nil || user.DeletedAt.Valid || user.DeactivatedAt.Valid ||
+				user.SessionVersion != app.sessionManager.GetInt32(r.Context(), sessionVersionKey) {
 				// Session is invalid, clear it
 				if err := app.sessionManager.Destroy(r.Context()); err != nil {
 					app.logger.Error("failed to destroy session", "error", err)
 				}
+				// The flash goes in the fresh session that replaces it
+				if errors.Is(err, service.ErrSessionEvicted) {
+					app.addFlash(r, FlashWarning, "You were signed out here because your account signed in on another device.")
+				}
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Add user to context
+			// Add user to context, and to the header pages render
 			ctx := context.WithValue(r.Context(), contextKeyUser, user)
-			r = r.WithContext(ctx)
+			nav := viewmodels.NewNavViewModel(user)
+			nav.EmailUnverified = app.sessionManager.GetBool(r.Context(), sessionEmailUnverifiedKey)
+
+			// A site admin impersonating someone acts as them, with the
+			// admin kept for what must know who is really there
+			target, impersonating, err := app.impersonatedUser(r, user)
+			if err != nil {
+				if !app.clientGone(r, err) {
+					app.serverError(w, r, err)
+				}
+				return
+			}
+			if impersonating {
+				ctx = context.WithValue(ctx, contextKeyRealUser, user)
+				ctx = context.WithValue(ctx, contextKeyUser, target)
+				nav = viewmodels.NewImpersonationNavViewModel(target, user)
+			}
+
+			r = r.WithContext(viewmodels.WithNav(ctx, nav))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// impersonationBlocked lists the routes a site admin may not use while
+// impersonating someone: those that spend or refund the user's money, that
+// change how the user signs in or whether their account exists, or that
+// send them a copy of everything held about them. The site has no password
+// change of its own to list; deleting an account is the one form that asks
+// for the password.
+var impersonationBlocked = []string{
+	"POST /events/{year}/{slug}/races/{raceSlug}/register",
+	"POST /account/registrations/{id}/cancel",
+	"POST /account/registrations/{id}/transfer",
+	"POST /account/tokens",
+	"POST /account/sessions/revoke-others",
+	"POST /account/sessions/{id}/revoke",
+	"POST /auth/sign-out-everywhere",
+	"POST /account/delete",
+	"POST /account/data-export",
+}
+
+// guardImpersonation audits every request that may change something while
+// a site admin impersonates someone, and refuses those to the routes in
+// impersonationBlocked. Requests whose audit fails are refused too, so
+// nothing is done as the user unrecorded. It must run after loadUser, in
+// the chain of the route so the pattern it matched is known.
+func (app *application) guardImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin, _ := getRealUserFromContext(r)
+		user, ok := getUserFromContext(r)
+		if !ok || user.ID == admin.ID || r.Pattern == stopImpersonatingPattern {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		blocked := slices.Contains(impersonationBlocked, r.Pattern)
+		ctx, cancel := app.dbContext(r)
+		err := app.userService.RecordImpersonatedRequest(ctx, admin.ID, user.ID, service.ImpersonatedRequest{
+			Method:  r.Method,
+			Path:    r.URL.Path,
+			Blocked: blocked,
+		})
+		cancel()
+		if err != nil {
+			if !app.clientGone(r, err) {
+				app.serverError(w, r, err)
+			}
+			return
+		}
+		if blocked {
+			app.clientError(w, r, http.StatusForbidden)
+			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+// authenticateAPI loads the user of the API token presented as a bearer
+// token into the request context. Requests without a token continue
+// anonymously; those with an unknown, expired or revoked token are refused.
+func (app *application) authenticateAPI(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scheme, plaintext, _ := strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			app.apiUnauthorized(w, r, "invalid_request", "use a bearer token")
+			return
+		}
+
+		dbCtx, cancel := app.dbContext(r)
+		token, user, err := app.tokenService.Authenticate(dbCtx, strings.TrimSpace(plaintext))
+		cancel()
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidAPIToken) {
+				app.apiUnauthorized(w, r, "invalid_token", "the API token is invalid, expired or revoked")
+				return
+			}
+			app.apiError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), contextKeyUser, user)
+		ctx = context.WithValue(ctx, contextKeyAPIToken, token)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireScope ensures the request presented an API token granted scope.
+// It must run after authenticateAPI.
+func (app *application) requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := getAPITokenFromContext(r)
+			if !ok {
+				app.apiUnauthorized(w, r, "unauthorized", "an API token is required")
+				return
+			}
+			if !service.APITokenHasScope(token, scope) {
+				app.apiInsufficientScope(w, r, scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// checkScope ensures any API token the request presented was granted
+// scope, letting anonymous requests continue. It must run after
+// authenticateAPI.
+func (app *application) checkScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token, ok := getAPITokenFromContext(r); ok && !service.APITokenHasScope(token, scope) {
+				app.apiInsufficientScope(w, r, scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // Context keys
 type contextKey string
 
-const contextKeyUser = contextKey("user")
+const (
+	contextKeyAPIToken  = contextKey("apiToken")
+	contextKeyUser      = contextKey("user")
+	contextKeyRealUser  = contextKey("realUser")
+	contextKeyRequestID = contextKey("requestID")
+	contextKeyClientIP  = contextKey("clientIP")
+)
+
+// getRequestID returns the ID requestID gave the request, or "" outside it.
+func getRequestID(r *http.Request) string {
+	id, _ := r.Context().Value(contextKeyRequestID).(string)
+	return id
+}
 
 // getUserFromContext retrieves the user from the request context.
 func getUserFromContext(r *http.Request) (db.User, bool) {
 	user, ok := r.Context().Value(contextKeyUser).(db.User)
 	return user, ok
 }
+
+// getRealUserFromContext retrieves the user actually signed in: the site
+// admin while they impersonate someone, otherwise the user in the context.
+func getRealUserFromContext(r *http.Request) (db.User, bool) {
+	if user, ok := r.Context().Value(contextKeyRealUser).(db.User); ok {
+		return user, true
+	}
+	return getUserFromContext(r)
+}
+
+// getAPITokenFromContext retrieves the API token authenticateAPI accepted
+// from the request context.
+func getAPITokenFromContext(r *http.Request) (db.ApiToken, bool) {
+	token, ok := r.Context().Value(contextKeyAPIToken).(db.ApiToken)
+	return token, ok
+}
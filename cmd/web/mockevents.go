@@ -1,75 +1,77 @@
-package templates
+package main
 
-import "time"
+import (
+	"context"
+	"time"
 
-// EventViewModel represents an event for display purposes
-type EventViewModel struct {
-	Slug        string
-	Name        string
-	Date        time.Time
-	Location    string
-	ImageURL    string
-	RaceType    string    // e.g., "Trail Run", "Road Race", "Ultra Marathon"
-	Distance    string    // e.g., "10K", "Half Marathon", "50K"
-	Description string
-	Races       []RaceViewModel
-	Photos      []string
-	MapURL      string
-	Organizer   string
-	Price       string
-	Capacity    int
-	Registered  int
-}
-
-// RaceViewModel represents a race within an event
-type RaceViewModel struct {
-	Name        string
-	Distance    string
-	Price       string
-	StartTime   string
-	Capacity    int
-	Registered  int
-	Description string
-}
+	"firecrest/internal/repository"
+	"firecrest/ui/viewmodels"
+)
 
-// FormattedDate returns the date in a human-readable format
-func (e EventViewModel) FormattedDate() string {
-	return e.Date.Format("2 January 2006")
+// MockEventSource serves fixture events, so the UI can be worked on without
+// a database. Registration counts climb a twentieth of each race's places a
+// minute from when the source was created until every race sells out, then
+// start again from the fixtures' counts, so listings pass through every
+// badge.
+type MockEventSource struct {
+	started time.Time
 }
 
-// FormattedDay returns just the day number
-func (e EventViewModel) FormattedDay() string {
-	return e.Date.Format("02")
+// NewMockEventSource creates a MockEventSource whose counts start climbing
+// at started.
+func NewMockEventSource(started time.Time) *MockEventSource {
+	return &MockEventSource{started: started}
 }
 
-// FormattedMonth returns the abbreviated month
-func (e EventViewModel) FormattedMonth() string {
-	return e.Date.Format("Jan")
-}
-
-// FormattedYear returns the year
-func (e EventViewModel) FormattedYear() string {
-	return e.Date.Format("2006")
+func (s *MockEventSource) HomeEvents(ctx context.Context, now time.Time) ([]viewmodels.EventViewModel, []any, error) {
+	events := mockEvents()
+	var parts []any
+	for i := range events {
+		events[i] = s.at(events[i], now)
+		parts = append(parts, events[i].Slug, events[i].Registered, events[i].Badges())
+	}
+	return events, parts, nil
 }
 
-// SpotsRemaining returns the number of spots left
-func (e EventViewModel) SpotsRemaining() int {
-	return e.Capacity - e.Registered
+func (s *MockEventSource) Event(ctx context.Context, year int32, slug string, now time.Time) (viewmodels.EventViewModel, []any, error) {
+	for _, e := range mockEvents() {
+		if e.Year == year && e.Slug == slug {
+			e = s.at(e, now)
+			return e, []any{e.Slug, e.Registered, e.Badges()}, nil
+		}
+	}
+	return viewmodels.EventViewModel{}, nil, repository.ErrNotFound
 }
 
-// RegistrationPercentage returns how full the event is
-func (e EventViewModel) RegistrationPercentage() int {
-	if e.Capacity == 0 {
-		return 0
+// at returns the fixture event as it stands at now, with its races'
+// registration counts faked and the event's totalled from them.
+func (s *MockEventSource) at(e viewmodels.EventViewModel, now time.Time) viewmodels.EventViewModel {
+	// Every race fills within 20 minutes, so the cycle is 21 long
+	phase := max(int(now.Sub(s.started)/time.Minute), 0) % 21
+	e.Now = now
+	e.Capacity, e.Registered = 0, 0
+	for i := range e.Races {
+		race := &e.Races[i]
+		race.EventSlug = e.Slug
+		race.EventYear = e.Year
+		race.Registered = min(race.Registered+phase*((race.Capacity+19)/20), race.Capacity)
+		race.State = viewmodels.RaceStateOpen
+		if race.Registered >= race.Capacity {
+			race.State = viewmodels.RaceStateSoldOut
+		}
+		e.Capacity += race.Capacity
+		e.Registered += race.Registered
 	}
-	return (e.Registered * 100) / e.Capacity
+	return e
 }
 
-// GetMockEvents returns sample events for UI mockup
-func GetMockEvents() []EventViewModel {
-	return []EventViewModel{
+// mockEvents returns the fixture events MockEventSource serves. Their
+// registration counts are where the counts start from.
+func mockEvents() []viewmodels.EventViewModel {
+	return []viewmodels.EventViewModel{
 		{
 			Slug:        "peak-district-ultra-2026",
+			Year:        2026,
 			Name:        "Peak District Ultra",
 			Date:        time.Date(2026, 4, 18, 8, 0, 0, 0, time.UTC),
 			Location:    "Castleton, Peak District",
@@ -87,13 +89,14 @@ func GetMockEvents() []EventViewModel {
 				"https://images.unsplash.com/photo-1469395446868-fb6a048d5ca3?w=800&h=600&fit=crop",
 				"https://images.unsplash.com/photo-1483728642387-6c3bdd6c93e5?w=800&h=600&fit=crop",
 			},
-			Races: []RaceViewModel{
+			Races: []viewmodels.RaceViewModel{
 				{Name: "Ultra 50K", Distance: "50K", Price: "£65", StartTime: "06:00", Capacity: 300, Registered: 245, Description: "The main event - a challenging 50K route through the heart of the Peak District."},
 				{Name: "Marathon", Distance: "42K", Price: "£55", StartTime: "07:00", Capacity: 200, Registered: 97, Description: "A full marathon distance covering the most scenic sections of the course."},
 			},
 		},
 		{
 			Slug:        "lake-district-trail-run",
+			Year:        2026,
 			Name:        "Lake District Trail Run",
 			Date:        time.Date(2026, 5, 9, 9, 0, 0, 0, time.UTC),
 			Location:    "Ambleside, Lake District",
@@ -110,13 +113,14 @@ func GetMockEvents() []EventViewModel {
 				"https://images.unsplash.com/photo-1571104508999-893933ded431?w=800&h=600&fit=crop",
 				"https://images.unsplash.com/photo-1501785888041-af3ef285b470?w=800&h=600&fit=crop",
 			},
-			Races: []RaceViewModel{
+			Races: []viewmodels.RaceViewModel{
 				{Name: "Half Marathon", Distance: "21K", Price: "£45", StartTime: "09:00", Capacity: 500, Registered: 389, Description: "The flagship half marathon with challenging ascents and incredible views."},
 				{Name: "10K Fun Run", Distance: "10K", Price: "£25", StartTime: "10:30", Capacity: 250, Registered: 123, Description: "A scenic 10K perfect for beginners and families."},
 			},
 		},
 		{
 			Slug:        "yorkshire-three-peaks",
+			Year:        2026,
 			Name:        "Yorkshire Three Peaks Challenge",
 			Date:        time.Date(2026, 6, 14, 7, 0, 0, 0, time.UTC),
 			Location:    "Horton-in-Ribblesdale, Yorkshire",
@@ -133,12 +137,13 @@ func GetMockEvents() []EventViewModel {
 				"https://images.unsplash.com/photo-1464822759023-fed622ff2c3b?w=800&h=600&fit=crop",
 				"https://images.unsplash.com/photo-1500534623283-312aade485b7?w=800&h=600&fit=crop",
 			},
-			Races: []RaceViewModel{
+			Races: []viewmodels.RaceViewModel{
 				{Name: "Three Peaks Challenge", Distance: "24 miles", Price: "£50", StartTime: "07:00", Capacity: 600, Registered: 598, Description: "The classic Three Peaks route with a 12-hour cutoff."},
 			},
 		},
 		{
 			Slug:        "cotswolds-spring-10k",
+			Year:        2026,
 			Name:        "Cotswolds Spring 10K",
 			Date:        time.Date(2026, 3, 22, 10, 0, 0, 0, time.UTC),
 			Location:    "Bourton-on-the-Water, Cotswolds",
@@ -154,13 +159,14 @@ func GetMockEvents() []EventViewModel {
 			Photos: []string{
 				"https://images.unsplash.com/photo-1508739773434-c26b3d09e071?w=800&h=600&fit=crop",
 			},
-			Races: []RaceViewModel{
+			Races: []viewmodels.RaceViewModel{
 				{Name: "10K Race", Distance: "10K", Price: "£28", StartTime: "10:00", Capacity: 300, Registered: 112, Description: "A fast and scenic 10K through the Cotswolds countryside."},
 				{Name: "5K Fun Run", Distance: "5K", Price: "£15", StartTime: "11:30", Capacity: 100, Registered: 44, Description: "A family-friendly 5K suitable for all abilities."},
 			},
 		},
 		{
 			Slug:        "snowdonia-marathon",
+			Year:        2026,
 			Name:        "Snowdonia Marathon",
 			Date:        time.Date(2026, 10, 24, 8, 0, 0, 0, time.UTC),
 			Location:    "Llanberis, Snowdonia",
@@ -177,13 +183,14 @@ func GetMockEvents() []EventViewModel {
 				"https://images.unsplash.com/photo-1506905925346-21bda4d32df4?w=800&h=600&fit=crop",
 				"https://images.unsplash.com/photo-1519904981063-b0cf448d479e?w=800&h=600&fit=crop",
 			},
-			Races: []RaceViewModel{
+			Races: []viewmodels.RaceViewModel{
 				{Name: "Full Marathon", Distance: "42.2K", Price: "£58", StartTime: "08:00", Capacity: 1500, Registered: 1123, Description: "The flagship Snowdonia Marathon with stunning mountain views."},
 				{Name: "Half Marathon", Distance: "21.1K", Price: "£38", StartTime: "09:30", Capacity: 500, Registered: 333, Description: "A challenging half marathon through the Snowdonia foothills."},
 			},
 		},
 		{
 			Slug:        "south-downs-way-50",
+			Year:        2026,
 			Name:        "South Downs Way 50",
 			Date:        time.Date(2026, 7, 11, 6, 0, 0, 0, time.UTC),
 			Location:    "Worthing, West Sussex",
@@ -199,20 +206,9 @@ func GetMockEvents() []EventViewModel {
 			Photos: []string{
 				"https://images.unsplash.com/photo-1551632811-561732d1e306?w=800&h=600&fit=crop",
 			},
-			Races: []RaceViewModel{
+			Races: []viewmodels.RaceViewModel{
 				{Name: "50 Mile Ultra", Distance: "50 miles", Price: "£95", StartTime: "06:00", Capacity: 350, Registered: 298, Description: "The full 50-mile route along the South Downs Way."},
 			},
 		},
 	}
 }
-
-// GetMockEvent returns a single mock event by slug
-func GetMockEvent(slug string) *EventViewModel {
-	events := GetMockEvents()
-	for _, e := range events {
-		if e.Slug == slug {
-			return &e
-		}
-	}
-	return nil
-}
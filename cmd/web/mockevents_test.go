@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"firecrest/internal/config"
+	"firecrest/internal/mocks/servicemocks"
+	"firecrest/internal/repository"
+	"firecrest/ui/viewmodels"
+)
+
+func TestMockEventSourceFor(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		wantMock bool
+	}{
+		{name: "development with mock data", cfg: config.Config{Env: config.EnvDevelopment, UseMockData: true}, wantMock: true},
+		{name: "development without mock data", cfg: config.Config{Env: config.EnvDevelopment}},
+		{name: "production with mock data", cfg: config.Config{Env: config.EnvProduction, UseMockData: true}},
+		{name: "production without mock data", cfg: config.Config{Env: config.EnvProduction}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+			app.mockEvents = mockEventSourceFor(tt.cfg, now)
+
+			_, isMock := app.events().(*MockEventSource)
+			if isMock != tt.wantMock {
+				t.Errorf("expected mock source %t, got %T", tt.wantMock, app.events())
+			}
+		})
+	}
+}
+
+func TestMockEventSource(t *testing.T) {
+	ctx := context.Background()
+	started := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	source := NewMockEventSource(started)
+
+	t.Run("counts climb over time and pass through every badge", func(t *testing.T) {
+		seen := map[viewmodels.Badge]bool{}
+		var last int
+		for minute := range 25 {
+			event, _, err := source.Event(ctx, 2026, "cotswolds-spring-10k", started.Add(time.Duration(minute)*time.Minute))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if minute == 1 && event.Registered <= last {
+				t.Errorf("expected registrations to climb, got %d then %d", last, event.Registered)
+			}
+			last = event.Registered
+			for _, badge := range event.Badges() {
+				seen[badge] = true
+			}
+		}
+		if !seen[viewmodels.BadgeAlmostFull] || !seen[viewmodels.BadgeSoldOut] {
+			t.Errorf("expected the event to become almost full and sell out, saw %v", seen)
+		}
+	})
+
+	t.Run("totals the event from its races", func(t *testing.T) {
+		events, _, err := source.HomeEvents(ctx, started.Add(3*time.Minute))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(events) == 0 {
+			t.Fatal("expected fixture events")
+		}
+		for _, e := range events {
+			var capacity, registered int
+			for _, race := range e.Races {
+				capacity += race.Capacity
+				registered += race.Registered
+			}
+			if e.Capacity != capacity || e.Registered != registered {
+				t.Errorf("%s: expected %d of %d registered, got %d of %d", e.Slug, registered, capacity, e.Registered, e.Capacity)
+			}
+		}
+		if !slices.ContainsFunc(events, func(e viewmodels.EventViewModel) bool { return e.Slug == "snowdonia-marathon" }) {
+			t.Error("expected the Snowdonia Marathon fixture")
+		}
+	})
+
+	t.Run("returns ErrNotFound for unknown events", func(t *testing.T) {
+		if _, _, err := source.Event(ctx, 2026, "no-such-event", started); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"firecrest/internal/apperr"
+	"firecrest/internal/service"
+)
+
+// problemContentType is the media type of RFC 9457 problem details.
+const problemContentType = "application/problem+json"
+
+// problem is an RFC 9457 problem details body, which every /api/v1 error
+// response uses. Code is an extension member carrying a short machine
+// readable reason, and RequestID one naming the request in the server log.
+type problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      string            `json:"code"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// newProblem returns a problem described only by its status, as RFC 9457
+// allows for the "about:blank" type.
+func newProblem(status int, code, detail string) problem {
+	return problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// writeProblem writes p as an application/problem+json response. The
+// request's path is used as the instance unless p names one.
+func (app *application) writeProblem(w http.ResponseWriter, r *http.Request, p problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		app.logger.Error("failed to encode problem response", "error", err)
+	}
+}
+
+// apiError writes the problem matching err, with the status
+// serviceErrorStatus gives it and the code and message the error carries
+// (see apperr). Invalid input is 422, listing the invalid fields of
+// service.FieldErrors, and 503 means the database timed out. Anything else
+// is logged and answered with a generic 500 that names the request, so the
+// message of err never reaches the client.
+func (app *application) apiError(w http.ResponseWriter, r *http.Request, err error) {
+	if app.clientGone(r, err) {
+		return
+	}
+
+	switch status := serviceErrorStatus(err); {
+	case errors.Is(err, service.ErrInvalidInput):
+		app.writeProblem(w, r, invalidInputProblem(err))
+	case status == http.StatusServiceUnavailable:
+		app.logger.Warn(err.Error(), "request_id", getRequestID(r), "method", r.Method, "uri", r.URL.RequestURI())
+		w.Header().Set("Retry-After", retryAfterSeconds)
+		app.writeProblem(w, r, newProblem(http.StatusServiceUnavailable, "unavailable", "the server is busy, try again shortly"))
+	case status == http.StatusInternalServerError:
+		id := errorRequestID(r)
+		app.logger.Error(err.Error(), "request_id", id, "method", r.Method, "uri", r.URL.RequestURI(), "trace", panicTrace(err))
+
+		p := newProblem(http.StatusInternalServerError, "internal_error", "the server encountered a problem")
+		p.RequestID = id
+		app.writeProblem(w, r, p)
+	default:
+		app.writeProblem(w, r, newProblem(status, errorCode(err), apperr.MessageOf(err)))
+	}
+}
+
+// invalidInputProblem describes a validation failure. The messages of
+// service.FieldErrors are listed by field; other validation errors carry
+// their message as the detail.
+func invalidInputProblem(err error) problem {
+	p := newProblem(http.StatusUnprocessableEntity, errorCode(err), err.Error())
+
+	var fieldErrs service.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		p.Detail = apperr.MessageOf(err)
+		p.Errors = fieldErrs
+	}
+	return p
+}
+
+// errorCode returns the code of the problem describing err: its apperr code
+// in the lower case problem codes use, such as "race_full".
+func errorCode(err error) string {
+	return strings.ToLower(string(apperr.CodeOf(err)))
+}
+
+// problemCode returns the code of a problem described only by its status,
+// such as "not_found" or "method_not_allowed".
+func problemCode(status int) string {
+	if status == http.StatusInternalServerError {
+		return "internal_error"
+	}
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/mocks/servicemocks"
+	"firecrest/internal/repository"
+	"firecrest/internal/service"
+)
+
+func TestAPIError(t *testing.T) {
+	serve := func(app *application, err error) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/peak-ultra", http.NoBody)
+		rr := httptest.NewRecorder()
+		requestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			app.apiError(w, r, err)
+		})).ServeHTTP(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid input", err: fmt.Errorf("%w: page must be 1 or greater", service.ErrInvalidInput), wantStatus: http.StatusUnprocessableEntity, wantCode: "invalid_input"},
+		{name: "not found", err: fmt.Errorf("failed to get event: %w", repository.ErrNotFound), wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "conflict", err: fmt.Errorf("failed to create event: %w", repository.ErrConflict), wantStatus: http.StatusConflict, wantCode: "conflict"},
+		{name: "timeout", err: fmt.Errorf("failed to list events: %w", repository.ErrTimeout), wantStatus: http.StatusServiceUnavailable, wantCode: "unavailable"},
+		{name: "a described error", err: fmt.Errorf("failed to register: %w", service.ErrRaceFull), wantStatus: http.StatusConflict, wantCode: "race_full"},
+		{name: "a missing event", err: fmt.Errorf("failed to load event: %w", service.ErrEventNotFound), wantStatus: http.StatusNotFound, wantCode: "event_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run("maps "+tt.name, func(t *testing.T) {
+			rr := serve(newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{}), tt.err)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			got := decodeProblem(t, rr)
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode {
+				t.Errorf("expected status %d and code %q, got %+v", tt.wantStatus, tt.wantCode, got)
+			}
+			if got.Type != "about:blank" || got.Title != http.StatusText(tt.wantStatus) {
+				t.Errorf("unexpected type or title: %+v", got)
+			}
+			if got.Instance != "/api/v1/events/peak-ultra" {
+				t.Errorf("expected the request path as instance, got %q", got.Instance)
+			}
+		})
+	}
+
+	t.Run("carries the code from the repository through the service", func(t *testing.T) {
+		// Two organisations use the slug, so the service cannot say which
+		// event was meant
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			ListBySlugFunc: func(ctx context.Context, slug string) ([]db.Event, error) {
+				return []db.Event{{ID: 2, OrganisationID: 1, Slug: slug}, {ID: 1, OrganisationID: 2, Slug: slug}}, nil
+			},
+		}
+		app := newTestApplication(service.NewEventService(eventRepo, &repositorymocks.RaceRepositoryMock{}, nil), &servicemocks.UserServiceMock{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/peak-ultra", http.NoBody)
+		req.SetPathValue("slug", "peak-ultra")
+		rr := httptest.NewRecorder()
+		app.apiEventDetail(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		got := decodeProblem(t, rr)
+		if got.Code != "event_not_found" || got.Detail != "event not found" {
+			t.Errorf("expected the service's code and message, got %+v", got)
+		}
+	})
+
+	t.Run("lists field errors", func(t *testing.T) {
+		err := service.FieldErrors{"page": "page must be an integer", "per_page": "per_page must be an integer"}
+
+		rr := serve(newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{}), err)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+		}
+		got := decodeProblem(t, rr)
+		if len(got.Errors) != 2 || got.Errors["page"] != "page must be an integer" || got.Errors["per_page"] != "per_page must be an integer" {
+			t.Errorf("unexpected field errors %v", got.Errors)
+		}
+	})
+
+	t.Run("hides unexpected errors behind the request ID", func(t *testing.T) {
+		var logs bytes.Buffer
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+		rr := serve(app, errors.New("pq: relation \"events\" does not exist"))
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+		body := rr.Body.String()
+		if strings.Contains(body, "relation") || strings.Contains(body, "pq:") {
+			t.Errorf("expected the error message to stay out of the body, got %s", body)
+		}
+		got := decodeProblem(t, rr)
+		if got.Code != "internal_error" {
+			t.Errorf("expected internal_error code, got %q", got.Code)
+		}
+		id := rr.Header().Get("X-Request-ID")
+		if id == "" || got.RequestID != id {
+			t.Errorf("expected request ID %q in the body, got %q", id, got.RequestID)
+		}
+		if !strings.Contains(logs.String(), "request_id="+id) {
+			t.Errorf("expected the log to carry request ID %q, got:\n%s", id, logs.String())
+		}
+	})
+
+	t.Run("names requests outside the request ID middleware", func(t *testing.T) {
+		var logs bytes.Buffer
+		app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+		app.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events", http.NoBody)
+		rr := httptest.NewRecorder()
+		app.apiError(rr, req, errors.New("boom"))
+
+		got := decodeProblem(t, rr)
+		if got.RequestID == "" || !strings.Contains(logs.String(), "request_id="+got.RequestID) {
+			t.Errorf("expected a generated request ID shared with the log, got %q", got.RequestID)
+		}
+	})
+}
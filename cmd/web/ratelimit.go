@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"firecrest/internal/cache"
+	"firecrest/internal/service"
+)
+
+// rateLimiter allows each key a number of requests per fixed window of
+// time, such as a client's address a few contact form submissions an hour.
+// Counts are kept in memory, so each server keeps its own and they are lost
+// when it restarts; it slows abuse rather than preventing it.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+	clock  service.Clock
+
+	// mu makes reading and bumping a key's count one step
+	mu      sync.Mutex
+	windows *cache.Cache[string, rateWindow]
+}
+
+// rateWindow is how many requests a key has made in its current window.
+type rateWindow struct {
+	count  int
+	resets time.Time
+}
+
+// maxRateLimitKeys bounds the keys a rateLimiter remembers. Past it the
+// least recently seen are forgotten, which only lets them start afresh.
+const maxRateLimitKeys = 10000
+
+// newRateLimiter creates a rateLimiter allowing limit requests per window
+// for each key.
+func newRateLimiter(limit int, window time.Duration, clock service.Clock) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+		clock:  clock,
+		windows: cache.New[string, rateWindow](cache.Options{
+			MaxEntries: maxRateLimitKeys,
+			Clock:      clock,
+		}),
+	}
+}
+
+// allow counts a request for key, reporting whether it is within the limit
+// and, when it is not, how long until the key's window resets.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	w, ok := l.windows.Get(key)
+	if !ok {
+		w = rateWindow{resets: now.Add(l.window)}
+	}
+	if w.count >= l.limit {
+		return false, w.resets.Sub(now)
+	}
+	w.count++
+	l.windows.Set(key, w, w.resets.Sub(now))
+	return true, 0
+}
+
+// limitByIP refuses requests from a client that has made more than the
+// limiter allows, answering 429 with a Retry-After header. Clients are told
+// apart by the address realIP found.
+func (app *application) limitByIP(l *rateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, retryAfter := l.allow(getClientIP(r)); !ok {
+				seconds := int(retryAfter.Round(time.Second) / time.Second)
+				w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+				app.clientError(w, r, http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
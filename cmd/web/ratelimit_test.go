@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"firecrest/internal/mocks/servicemocks"
+)
+
+// manualClock is a service.Clock moved on by hand.
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time { return c.now }
+
+func TestRateLimiter(t *testing.T) {
+	clock := &manualClock{now: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)}
+	limiter := newRateLimiter(2, time.Hour, clock)
+
+	for i := range 2 {
+		if ok, _ := limiter.allow("203.0.113.7"); !ok {
+			t.Fatalf("expected request %d allowed", i+1)
+		}
+	}
+	clock.now = clock.now.Add(20 * time.Minute)
+	ok, retryAfter := limiter.allow("203.0.113.7")
+	if ok || retryAfter != 40*time.Minute {
+		t.Errorf("expected the third request refused until the window resets, got %v, %v", ok, retryAfter)
+	}
+	if ok, _ := limiter.allow("198.51.100.1"); !ok {
+		t.Error("expected another key counted apart")
+	}
+
+	clock.now = clock.now.Add(40 * time.Minute)
+	if ok, _ := limiter.allow("203.0.113.7"); !ok {
+		t.Error("expected a request allowed once the window reset")
+	}
+}
+
+func TestLimitByIP(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+	limiter := newRateLimiter(1, time.Minute, &manualClock{now: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)})
+	h := app.realIP(app.limitByIP(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/events/2026/harbour-10k/contact", nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send("203.0.113.7:51234"); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected the first request through, got %d", rr.Code)
+	}
+	rr := send("203.0.113.7:51235")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("expected Retry-After 60, got %q", got)
+	}
+	if rr := send("198.51.100.1:40000"); rr.Code != http.StatusNoContent {
+		t.Errorf("expected another client through, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// realIP records the client's address in the request context, for logging
+// and anything else keyed by who is asking. Behind a trusted proxy the
+// connection's peer is the proxy, so the address comes from the headers it
+// sets. Anyone else could put anything in those headers, so they are only
+// read when the peer is one of app.trustedProxies.
+func (app *application) realIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r, app.trustedProxies)
+		ctx := context.WithValue(r.Context(), contextKeyClientIP, ip)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// getClientIP returns the address realIP found for the request's client, or
+// "" outside it.
+func getClientIP(r *http.Request) string {
+	ip, _ := r.Context().Value(contextKeyClientIP).(string)
+	return ip
+}
+
+// clientIP returns the address of the client that made r. Each proxy
+// appends the peer it heard from to X-Forwarded-For, so reading the list
+// from the right, the first hop that is not a trusted proxy is the client.
+// Entries to the left of it were supplied by the client and may be forged.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	peer, ok := parseHop(r.RemoteAddr)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !isTrusted(peer, trusted) {
+		return peer.String()
+	}
+
+	hops := forwardedFor(r.Header)
+	if len(hops) == 0 {
+		if ip, ok := parseHop(r.Header.Get("X-Real-IP")); ok {
+			return ip.String()
+		}
+		return peer.String()
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseHop(hops[i])
+		if !ok {
+			// A mangled entry means nothing further left can be relied
+			// on, so settle for the last hop known to be good
+			break
+		}
+		client = hop
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// forwardedFor returns the hops listed across all X-Forwarded-For headers,
+// nearest the client first.
+func forwardedFor(h http.Header) []string {
+	var hops []string
+	for _, value := range h.Values("X-Forwarded-For") {
+		for hop := range strings.SplitSeq(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// parseHop parses an address as found in RemoteAddr or a forwarding
+// header: bare, or with a port, and IPv6 optionally in brackets.
+func parseHop(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	// Dual-stack listeners report IPv4 peers as ::ffff:a.b.c.d
+	return addr.Unmap().WithZone(""), true
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"firecrest/internal/mocks/servicemocks"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		xRealIP    string
+		want       string
+	}{
+		{name: "uses the peer without a proxy", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "ignores headers from untrusted peers", remoteAddr: "203.0.113.7:51234", xff: []string{"198.51.100.1"}, xRealIP: "198.51.100.2", want: "203.0.113.7"},
+		{name: "reads the client from a trusted proxy", remoteAddr: "10.0.0.2:443", xff: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "takes the right-most untrusted hop", remoteAddr: "10.0.0.2:443", xff: []string{"192.0.2.66, 198.51.100.1, 10.0.0.3"}, want: "198.51.100.1"},
+		{name: "reads hops across several headers", remoteAddr: "10.0.0.2:443", xff: []string{"192.0.2.66", "198.51.100.1", "10.0.0.3"}, want: "198.51.100.1"},
+		{name: "uses the left-most hop when every hop is trusted", remoteAddr: "10.0.0.2:443", xff: []string{"10.1.1.1, 10.0.0.3"}, want: "10.1.1.1"},
+		{name: "stops at a malformed hop", remoteAddr: "10.0.0.2:443", xff: []string{"198.51.100.1, not-an-ip, 10.0.0.3"}, want: "10.0.0.3"},
+		{name: "falls back to X-Real-IP", remoteAddr: "10.0.0.2:443", xRealIP: "198.51.100.2", want: "198.51.100.2"},
+		{name: "prefers X-Forwarded-For to X-Real-IP", remoteAddr: "10.0.0.2:443", xff: []string{"198.51.100.1"}, xRealIP: "198.51.100.2", want: "198.51.100.1"},
+		{name: "uses the proxy when it names no client", remoteAddr: "10.0.0.2:443", want: "10.0.0.2"},
+		{name: "handles IPv6 peers", remoteAddr: "[2001:db8::1]:51234", xff: []string{"198.51.100.1"}, want: "2001:db8::1"},
+		{name: "handles IPv6 proxies and clients", remoteAddr: "[fd00::2]:443", xff: []string{"2001:db8::7, fd00::3"}, want: "2001:db8::7"},
+		{name: "handles bracketed hops with ports", remoteAddr: "10.0.0.2:443", xff: []string{"[2001:db8::7]:8443"}, want: "2001:db8::7"},
+		{name: "treats IPv4-mapped peers as IPv4", remoteAddr: "[::ffff:10.0.0.2]:443", xff: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "keeps an unparseable peer as it is", remoteAddr: "pipe", xff: []string{"198.51.100.1"}, want: "pipe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+
+			if got := clientIP(req, trusted); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	var logs bytes.Buffer
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+	app.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	app.trustedProxies = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	var got string
+	h := app.realIP(app.logRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = getClientIP(r)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.RemoteAddr = "10.0.0.2:443"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "198.51.100.1" {
+		t.Errorf("expected the client's address in the context, got %q", got)
+	}
+	if !strings.Contains(logs.String(), "ip=198.51.100.1") {
+		t.Errorf("expected the client's address to be logged, got %q", logs.String())
+	}
+}
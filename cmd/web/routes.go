@@ -2,49 +2,278 @@ package main
 
 import (
 	"net/http"
+	"slices"
 
+	"firecrest/db"
+	"firecrest/internal/metrics"
+	"firecrest/internal/service"
+	"firecrest/internal/tracing"
 	"firecrest/ui"
-
-	"github.com/justinas/alice"
 )
 
+// chain wraps h in the given middleware. The first middleware is the
+// outermost, so it sees the request first.
+func chain(h http.Handler, mws ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// routeGroup registers routes on a mux behind a shared middleware chain.
+type routeGroup struct {
+	mux *http.ServeMux
+	mws []func(http.Handler) http.Handler
+}
+
+// group returns a group whose routes also run mws, inside this group's chain.
+func (g routeGroup) group(mws ...func(http.Handler) http.Handler) routeGroup {
+	return routeGroup{mux: g.mux, mws: append(slices.Clone(g.mws), mws...)}
+}
+
+func (g routeGroup) handle(pattern string, h http.HandlerFunc) {
+	g.mux.Handle(pattern, chain(h, g.mws...))
+}
+
+// handleUnmatched serves mux, replacing its plain text answer to requests no
+// route matches. A path with no route gets the branded 404 page; a path
+// routed only under other methods gets a 405 naming them in Allow.
+func (app *application) handleUnmatched(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		// Ask the mux which it would have answered, keeping the Allow
+		// header it sets on a 405
+		rec := &headerRecorder{header: http.Header{}}
+		h.ServeHTTP(rec, r)
+		if rec.status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", rec.header.Get("Allow"))
+			app.clientError(w, r, http.StatusMethodNotAllowed)
+			return
+		}
+		app.notFound(w, r)
+	})
+}
+
+// headerRecorder keeps the headers and status a handler writes, discarding
+// the body.
+type headerRecorder struct {
+	header http.Header
+	status int
+}
+
+func (rec *headerRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *headerRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *headerRecorder) Write(p []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return len(p), nil
+}
+
 func (app *application) routes() http.Handler {
 	mux := http.NewServeMux()
 
-	fileServer := http.FileServer(http.FS(ui.Files))
-
-	mux.Handle("GET /static/", fileServer)
+	static := staticFiles(ui.Files)
+	if app.staticDir != "" {
+		static = diskStaticFiles(app.staticDir)
+	}
+	mux.Handle("GET /static/", static)
+	// Loaded by organisers' own websites
+	mux.Handle("GET /static/js/availability-widget.js", crossOrigin(static))
+	if app.media != nil {
+		// Uploads kept on local disk, reached through signed URLs
+		mux.Handle("GET /media/", http.StripPrefix("/media", app.media))
+	}
 	mux.HandleFunc("GET /health", app.health)
+	if app.serveMetrics {
+		mux.Handle("GET "+metrics.Path, app.metrics.Handler())
+	}
+	// Payment events (stateless, authenticated by signature)
+	if app.stripeWebhookSecret != "" {
+		mux.HandleFunc("POST /webhooks/stripe", app.stripeWebhook)
+	}
 
-	// Protects against CSRF by checking Sec-Fetch-Site header
-	// https://www.alexedwards.net/blog/preventing-csrf-in-go
-	cop := http.NewCrossOriginProtection()
-	cop.AddTrustedOrigin("http://localhost:8080")
+	// How full an event's races are, streamed to its page (stateless, no
+	// user)
+	mux.HandleFunc("GET /events/{year}/{slug}/availability/stream", app.eventAvailabilityStream)
+
+	// Crawler files (stateless, no user)
+	mux.HandleFunc("GET /robots.txt", app.robots)
+	mux.HandleFunc("GET /sitemap.xml", app.sitemap)
+	mux.HandleFunc("GET /sitemaps/{file}", app.sitemapFile)
+
+	// JSON API (stateless, authenticated by API token). The catalogue is
+	// open to anonymous clients too; entrant lists are not.
+	api := routeGroup{mux: mux}.group(app.authenticateAPI)
+	catalogue := api.group(app.checkScope(service.ScopeReadEvents))
+	catalogue.handle("GET /api/v1/events", app.apiListEvents)
+	catalogue.handle("GET /api/v1/events/{slug}", app.apiEventDetail)
+	catalogue.handle("GET /api/v1/events/{slug}/races/{raceSlug}", app.apiRaceDetail)
+	entrants := api.group(app.requireScope(service.ScopeReadEntrants))
+	entrants.handle("GET /api/v1/races/{id}/entrants", app.apiRaceEntrants)
+	// Read by the availability widget on organisers' own websites, which
+	// present no token
+	mux.HandleFunc("GET /api/v1/events/{slug}/availability", app.apiEventAvailability)
+	mux.HandleFunc("OPTIONS /api/v1/events/{slug}/availability", app.apiEventAvailabilityPreflight)
+
+	// Public pages
+	public := routeGroup{mux: mux}.group(app.loadUser, app.guardImpersonation)
+	public.handle("GET /{$}", app.home)
+	public.handle("GET /events", app.eventListing)
+	public.handle("GET /events/archive", app.eventArchive)
+	public.handle("GET /events/{year}/{slug}", app.eventView)
+	public.handle("GET /events/{year}/{slug}/results/{raceSlug}", app.raceResults)
+	public.handle("GET /events/{year}/{slug}/races/{raceSlug}/route.gpx", app.raceRoute)
+	public.handle("GET /events/{year}/{slug}/photos/{photoID}", app.eventPhoto)
+	// Not ".../contact", which would clash with the legacy results URLs
+	public.handle("GET /events/{year}/{slug}/contact/organiser", app.contactView)
+	public.group(app.limitByIP(app.contactLimiter)).handle("POST /events/{year}/{slug}/contact/organiser", app.contactPost)
+	// Links from before event pages were found by year as well as slug
+	public.handle("GET /events/{slug}", app.legacyEventView)
+	public.handle("GET /events/{slug}/results/{raceSlug}", app.legacyRaceResults)
+	// Emailed links may be opened whether or not signed in
+	public.handle("GET /auth/verify", app.verifyEmail)
+	public.handle("GET /transfers/accept", app.acceptTransfers)
+	public.handle("GET /email/unsubscribe", app.unsubscribe)
+	public.handle("GET /organisations/invitations/accept", app.acceptInvitations)
+	public.handle("GET /organisations/{id}/logo", app.organisationLogo)
+	public.handle("GET /organisations/{id}/banner", app.organisationBanner)
 
-	// Middleware chains
-	dynamic := alice.New(app.sessionManager.LoadAndSave, app.loadUser)
-	authRequired := dynamic.Append(app.requireAuth)
-	guestOnly := dynamic.Append(app.redirectIfAuth)
+	// Authentication pages (guests only)
+	guest := public.group(app.redirectIfAuth)
+	guest.handle("GET /auth/sign-in", app.signInView)
+	guest.handle("POST /auth/sign-in", app.signInPost)
+	guest.handle("GET /auth/sign-up", app.signUpView)
+	guest.handle("POST /auth/sign-up", app.signUpPost)
 
-	// Public routes
-	mux.Handle("GET /", dynamic.ThenFunc(app.home))
-	mux.Handle("GET /events/{slug}", dynamic.ThenFunc(app.eventView))
+	// Account pages (signed in only)
+	account := public.group(app.requireAuth)
+	account.handle("POST /auth/sign-out", app.signOut)
+	account.handle("POST /auth/sign-out-everywhere", app.signOutEverywhere)
+	account.handle("POST /events/{year}/{slug}/races/{raceSlug}/register", app.registerPost)
+	account.handle("GET /account/registrations", app.accountRegistrations)
+	account.handle("GET /account/profile", app.profileView)
+	account.handle("POST /account/profile", app.profilePost)
+	account.handle("POST /account/registrations/{id}/cancel", app.cancelRegistrationPost)
+	account.handle("POST /account/registrations/{id}/transfer", app.transferRegistrationPost)
+	account.handle("GET /account/registrations/{id}/edit", app.registrationDetailsView)
+	account.handle("POST /account/registrations/{id}/edit", app.registrationDetailsPost)
+	account.handle("GET /account/tokens", app.apiTokensView)
+	account.handle("POST /account/tokens", app.createAPITokenPost)
+	account.handle("POST /account/tokens/{id}/revoke", app.revokeAPITokenPost)
+	account.handle("GET /account/sessions", app.sessionsView)
+	account.handle("POST /account/sessions/revoke-others", app.revokeOtherSessionsPost)
+	account.handle("POST /account/sessions/{id}/revoke", app.revokeSessionPost)
+	account.handle("GET /account/delete", app.deleteAccountView)
+	account.handle("POST /account/delete", app.deleteAccountPost)
+	account.handle("GET /account/data-export", app.dataExportView)
+	account.handle("POST /account/data-export", app.dataExportPost)
 
-	// Authentication routes (guest only)
-	mux.Handle("GET /auth/sign-in", guestOnly.ThenFunc(app.signInView))
-	mux.Handle("POST /auth/sign-in", guestOnly.ThenFunc(app.signInPost))
-	mux.Handle("GET /auth/sign-up", guestOnly.ThenFunc(app.signUpView))
-	mux.Handle("POST /auth/sign-up", guestOnly.ThenFunc(app.signUpPost))
+	// Admin pages (organisers and admins only)
+	admin := account.group(app.requireRole(db.UserRoleOrganizer, db.UserRoleAdmin), app.scopeOrganisation)
+	admin.handle("GET /admin/dashboard", app.adminDashboard)
+	admin.handle("POST /admin/organisations/switch", app.adminSwitchOrganisationPost)
+	admin.handle("GET /admin/events/new", app.adminCreateView)
+	admin.handle("POST /admin/events", app.adminCreatePost)
+	admin.handle("GET /admin/events/{id}/duplicate", app.adminDuplicateView)
+	admin.handle("POST /admin/events/{id}/duplicate", app.adminDuplicatePost)
+	admin.handle("GET /admin/events/{id}/series", app.adminEventSeriesView)
+	admin.handle("POST /admin/events/{id}/series", app.adminEventSeriesPost)
+	admin.handle("POST /admin/events/{id}/publish", app.adminPublishEventPost)
+	admin.handle("POST /admin/events/{id}/archive", app.adminArchiveEventPost)
+	admin.handle("GET /admin/events/{id}/registrations/timeseries", app.adminRegistrationTimeseries)
+	admin.handle("GET /admin/events/{id}/discounts", app.adminDiscountCodesView)
+	admin.handle("POST /admin/events/{id}/discounts", app.adminCreateDiscountCodePost)
+	admin.handle("GET /admin/events/{id}/photos", app.adminPhotosView)
+	admin.handle("POST /admin/events/{id}/photos", app.adminUploadPhotoPost)
+	admin.handle("POST /admin/events/{id}/photos/{photoID}/delete", app.adminDeletePhotoPost)
+	admin.handle("POST /admin/events/{id}/photos/{photoID}/move", app.adminMovePhotoPost)
+	admin.handle("GET /admin/events/{id}/enquiries", app.adminEnquiriesView)
+	admin.handle("GET /admin/races/{id}/edit", app.adminEditRaceView)
+	admin.handle("POST /admin/races/{id}/edit", app.adminEditRacePost)
+	admin.handle("POST /admin/races/{id}/route", app.adminRaceRoutePost)
+	admin.handle("POST /admin/races/{id}/questions", app.adminRaceQuestionsPost)
+	admin.handle("POST /admin/races/{id}/min-age", app.adminRaceMinAgePost)
+	admin.handle("POST /admin/races/{id}/distance", app.adminRaceDistancePost)
+	admin.handle("POST /admin/races/{id}/waves", app.adminRaceWavesPost)
+	admin.handle("POST /admin/races/{id}/waves/{waveID}", app.adminRaceWavePost)
+	admin.handle("POST /admin/races/{id}/waves/{waveID}/delete", app.adminRaceWaveDeletePost)
+	admin.handle("POST /admin/races/{id}/prices", app.adminRacePriceTiersPost)
+	admin.handle("POST /admin/races/{id}/prices/{tierID}/delete", app.adminRacePriceTierDeletePost)
+	admin.handle("POST /admin/races/{id}/fields", app.adminRaceCustomFieldsPost)
+	admin.handle("POST /admin/races/{id}/fields/order", app.adminRaceCustomFieldOrderPost)
+	admin.handle("POST /admin/races/{id}/fields/{fieldID}", app.adminRaceCustomFieldPost)
+	admin.handle("POST /admin/races/{id}/fields/{fieldID}/delete", app.adminRaceCustomFieldDeletePost)
+	admin.handle("POST /admin/races/{id}/fields/{fieldID}/move", app.adminRaceCustomFieldMovePost)
+	admin.handle("GET /admin/races/{id}/entrants", app.adminEntrantsView)
+	admin.handle("GET /admin/races/{id}/entrants/import", app.adminImportEntrantsView)
+	admin.handle("POST /admin/races/{id}/entrants/import", app.adminImportEntrantsPost)
+	admin.handle("GET /admin/races/{id}/entrants/export", app.adminExportEntrants)
+	admin.handle("POST /admin/races/{id}/entrants/{registrationID}/bib", app.adminSetBibPost)
+	admin.handle("GET /admin/races/{id}/checkin", app.adminCheckInView)
+	admin.handle("POST /admin/races/{id}/checkin/{registrationID}", app.adminCheckInPost)
+	admin.handle("POST /admin/races/{id}/bibs", app.adminAssignBibsPost)
+	admin.handle("GET /admin/races/{id}/results", app.adminResultsView)
+	admin.handle("POST /admin/races/{id}/results", app.adminResultsPost)
+	admin.handle("POST /admin/races/{id}/results/publish", app.adminPublishResultsPost)
+	admin.handle("GET /admin/organisations/{id}/members", app.adminMembersView)
+	admin.handle("POST /admin/organisations/{id}/members", app.adminInviteMemberPost)
+	admin.handle("POST /admin/organisations/{id}/contact", app.adminContactEmailPost)
+	admin.handle("POST /admin/organisations/{id}/embedding", app.adminEmbeddingPost)
+	admin.handle("POST /admin/organisations/{id}/members/{userID}/remove", app.adminRemoveMemberPost)
+	admin.handle("POST /admin/organisations/{id}/notifications", app.adminNotificationsPost)
+	admin.handle("GET /admin/organisations/{id}/branding", app.adminBrandingView)
+	admin.handle("POST /admin/organisations/{id}/branding", app.adminBrandingPost)
+	admin.handle("GET /admin/organisations/{id}/payments", app.adminPaymentsView)
+	admin.handle("GET /admin/organisations/{id}/payments/export", app.adminExportPayments)
+	admin.handle("GET /admin/organisations/{id}/webhooks", app.adminWebhooksView)
+	admin.handle("POST /admin/organisations/{id}/webhooks", app.adminCreateWebhookPost)
+	admin.handle("GET /admin/organisations/{id}/webhooks/{webhookID}", app.adminWebhookLogView)
+	admin.handle("POST /admin/organisations/{id}/webhooks/{webhookID}/delete", app.adminDeleteWebhookPost)
 
-	// Sign out (authenticated only)
-	mux.Handle("POST /auth/sign-out", authRequired.ThenFunc(app.signOut))
+	// Site admin pages (admins only)
+	siteAdmin := account.group(app.requireRole(db.UserRoleAdmin))
+	siteAdmin.handle("GET /admin/users", app.adminUsersView)
+	siteAdmin.handle("POST /admin/users/{id}/unlock", app.adminUnlockUserPost)
+	siteAdmin.handle("POST /admin/users/{id}/role", app.adminUserRolePost)
+	siteAdmin.handle("POST /admin/users/{id}/active", app.adminUserActivePost)
+	siteAdmin.handle("POST /admin/users/{id}/impersonate", app.adminImpersonatePost)
+	// Impersonated users are never admins, so stopping is open to any account
+	account.handle(stopImpersonatingPattern, app.stopImpersonatingPost)
 
-	// Admin routes (temporary - should be removed in production)
-	mux.HandleFunc("GET /insert", app.adminCreatePost)
+	// Temporary admin routes - should be removed in production
 	mux.HandleFunc("GET /insert-user", app.adminCreateUser)
 
-	// Apply standard middleware + Cross-Origin Protection
-	standard := alice.New(app.logRequest, commonHeaders)
+	// Protects against CSRF by checking Sec-Fetch-Site header
+	// https://www.alexedwards.net/blog/preventing-csrf-in-go
+	cop := http.NewCrossOriginProtection()
+	cop.AddTrustedOrigin("http://localhost:8080")
 
-	return standard.Then(cop.Handler(mux))
+	// Middleware every request passes through once, outermost first. Tracing
+	// and metrics must see the request the mux matched so they can label by
+	// route pattern, so nothing between them and it may replace the request.
+	return chain(app.handleUnmatched(mux),
+		app.recoverPanic,
+		requestID,
+		app.realIP,
+		app.sessionManager.LoadAndSave,
+		app.localise,
+		tracing.Middleware,
+		app.metrics.Middleware,
+		app.logRequest,
+		commonHeaders,
+		compress(defaultCompressOptions()),
+		cop.Handler,
+	)
 }
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/mocks/servicemocks"
+	"firecrest/internal/service"
+	"firecrest/internal/tracing"
+)
+
+// signedIn returns a request carrying a session cookie for user.
+func signedIn(t *testing.T, app *application, req *http.Request, user db.User) *http.Request {
+	t.Helper()
+
+	ctx, err := storedSessions(app).Load(context.Background(), "")
+	if err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	app.sessionManager.Put(ctx, "userID", user.ID)
+	app.sessionManager.Put(ctx, sessionExpiresAtKey, time.Now().Add(time.Hour).Unix())
+	token, _, err := storedSessions(app).Commit(ctx)
+	if err != nil {
+		t.Fatalf("failed to commit session: %v", err)
+	}
+
+	req.AddCookie(&http.Cookie{Name: storedSessions(app).Cookie.Name, Value: token})
+	return req
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	mw := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	h := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}), mw("first"), mw("second"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if want := []string{"first", "second", "handler"}; !slices.Equal(order, want) {
+		t.Errorf("expected order %v, got %v", want, order)
+	}
+}
+
+func TestRoutesAdminAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *db.User
+		wantStatus int
+	}{
+		{name: "redirects anonymous users to sign in", wantStatus: http.StatusSeeOther},
+		{name: "forbids entrants", user: &db.User{ID: 1, Role: db.UserRoleEntrant}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+				GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+					return *tt.user, nil
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/dashboard", http.NoBody)
+			if tt.user != nil {
+				req = signedIn(t, app, req, *tt.user)
+			}
+			rr := httptest.NewRecorder()
+
+			app.routes().ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestRoutesCommonHeaders(t *testing.T) {
+	entrant := db.User{ID: 1, Role: db.UserRoleEntrant}
+
+	tests := []struct {
+		name string
+		path string
+		user *db.User
+	}{
+		{name: "static", path: "/static/main.css"},
+		{name: "api", path: "/api/v1/events"},
+		{name: "public", path: "/"},
+		{name: "auth", path: "/auth/sign-in"},
+		{name: "account", path: "/account/registrations", user: &entrant},
+		{name: "admin", path: "/admin/dashboard", user: &entrant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{
+				GetUserFunc: func(ctx context.Context, id int64) (db.User, error) {
+					return entrant, nil
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			if tt.user != nil {
+				req = signedIn(t, app, req, *tt.user)
+			}
+			rr := httptest.NewRecorder()
+
+			app.routes().ServeHTTP(rr, req)
+
+			for _, header := range []string{"X-Frame-Options", "X-Content-Type-Options", "Referrer-Policy"} {
+				if got := rr.Header().Values(header); len(got) != 1 {
+					t.Errorf("expected one %s header, got %q (status %d)", header, got, rr.Code)
+				}
+			}
+			if rr.Header().Get("X-Request-ID") == "" {
+				t.Error("expected an X-Request-ID header")
+			}
+		})
+	}
+}
+
+func TestRoutesUnmatched(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantAllow  string
+		wantBody   string
+	}{
+		{name: "serves the home page at the root", method: http.MethodGet, path: "/", wantStatus: http.StatusOK},
+		{name: "renders the branded 404 page for unknown paths", method: http.MethodGet, path: "/nonexistent", wantStatus: http.StatusNotFound, wantBody: `data-error-page="404"`},
+		{name: "does not serve the home page under unknown paths", method: http.MethodGet, path: "/evnts/foo", wantStatus: http.StatusNotFound, wantBody: `data-error-page="404"`},
+		{name: "rejects methods a path is not routed for", method: http.MethodPost, path: "/events/foo", wantStatus: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD", wantBody: `data-error-page="405"`},
+		{name: "answers unknown API paths with a problem", method: http.MethodGet, path: "/api/v1/nonexistent", wantStatus: http.StatusNotFound, wantBody: `"code":"not_found"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+			req := httptest.NewRequest(tt.method, tt.path, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			app.routes().ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if got := rr.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("expected Allow %q, got %q", tt.wantAllow, got)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("expected %q in response body", tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRoutesLocalise(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		acceptLanguage string
+		cookie         string
+		wantLang       string
+		wantHeading    string
+		wantCookie     string
+	}{
+		{name: "serves English by default", path: "/auth/sign-in", wantLang: "en-GB", wantHeading: "<h1>Sign In</h1>"},
+		{name: "negotiates Accept-Language", path: "/auth/sign-in", acceptLanguage: "cy-GB,cy;q=0.9,en;q=0.8", wantLang: "cy", wantHeading: "<h1>Mewngofnodi</h1>"},
+		{name: "falls back to English for unsupported languages", path: "/auth/sign-in", acceptLanguage: "fr-FR,fr", wantLang: "en-GB", wantHeading: "<h1>Sign In</h1>"},
+		{name: "switches language with ?lang= and remembers it", path: "/auth/sign-in?lang=cy", wantLang: "cy", wantHeading: "<h1>Mewngofnodi</h1>", wantCookie: "cy"},
+		{name: "prefers ?lang= to Accept-Language", path: "/auth/sign-in?lang=en-GB", acceptLanguage: "cy", wantLang: "en-GB", wantHeading: "<h1>Sign In</h1>", wantCookie: "en-GB"},
+		{name: "keeps the remembered language", path: "/auth/sign-in", acceptLanguage: "en-GB", cookie: "cy", wantLang: "cy", wantHeading: "<h1>Mewngofnodi</h1>"},
+		{name: "ignores unsupported ?lang= values", path: "/auth/sign-in?lang=xx", cookie: "cy", wantLang: "cy", wantHeading: "<h1>Mewngofnodi</h1>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: languageCookie, Value: tt.cookie})
+			}
+			rr := httptest.NewRecorder()
+
+			app.routes().ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+			}
+			body := rr.Body.String()
+			if want := `<html lang="` + tt.wantLang + `"`; !strings.Contains(body, want) {
+				t.Errorf("expected %q in response body", want)
+			}
+			if !strings.Contains(body, tt.wantHeading) {
+				t.Errorf("expected %q in response body", tt.wantHeading)
+			}
+			if got := rr.Header().Get("Content-Language"); got != tt.wantLang {
+				t.Errorf("expected Content-Language %q, got %q", tt.wantLang, got)
+			}
+			if !slices.Contains(rr.Header().Values("Vary"), "Accept-Language") {
+				t.Errorf("expected caches to keep a copy per language, got Vary %q", rr.Header().Values("Vary"))
+			}
+
+			var got string
+			for _, c := range rr.Result().Cookies() {
+				if c.Name == languageCookie {
+					got = c.Value
+				}
+			}
+			if got != tt.wantCookie {
+				t.Errorf("expected language cookie %q, got %q", tt.wantCookie, got)
+			}
+		})
+	}
+}
+
+func TestRoutesTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	queries := tracing.QueryTracer()
+	eventRepo := &repositorymocks.EventRepositoryMock{
+		CountSitemapFunc: func(ctx context.Context) (int64, error) {
+			// Stands in for pgx, which calls the tracer around each query
+			ctx = queries.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "-- name: CountSitemapEvents :one\nSELECT count(*) FROM events"})
+			queries.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+			return service.SitemapURLsPerFile + 1, nil
+		},
+	}
+	app := newTestApplication(service.NewEventService(eventRepo, &repositorymocks.RaceRepositoryMock{}, nil), &servicemocks.UserServiceMock{})
+
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sitemap.xml", http.NoBody))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	request, call, query := spans["GET /sitemap.xml"], spans["EventService.CountSitemapFiles"], spans["query CountSitemapEvents"]
+	if request == nil || call == nil || query == nil {
+		t.Fatalf("expected request, service and query spans, got %v", slices.Collect(maps.Keys(spans)))
+	}
+	if request.Parent().IsValid() {
+		t.Error("expected the request span to start the trace")
+	}
+	if call.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Error("expected the service span to be a child of the request span")
+	}
+	if query.Parent().SpanID() != call.SpanContext().SpanID() {
+		t.Error("expected the query span to be a child of the service span")
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+	h := app.recoverPanic(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	if got := rr.Header().Get("Connection"); got != "close" {
+		t.Errorf("expected Connection: close, got %q", got)
+	}
+}
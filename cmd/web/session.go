@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+
+	"firecrest/internal/session"
+)
+
+// SessionManager holds the signed-in user and other per-visitor state
+// between requests. SESSION_BACKEND chooses between an scs.SessionManager,
+// keeping sessions in Postgres behind a token cookie, and a
+// session.CookieManager, keeping them in the cookie itself so that reading
+// them costs no database round trip.
+type SessionManager interface {
+	LoadAndSave(next http.Handler) http.Handler
+
+	Put(ctx context.Context, key string, val any)
+	Exists(ctx context.Context, key string) bool
+	GetBool(ctx context.Context, key string) bool
+	GetInt32(ctx context.Context, key string) int32
+	GetInt64(ctx context.Context, key string) int64
+	GetString(ctx context.Context, key string) string
+	PopString(ctx context.Context, key string) string
+	Remove(ctx context.Context, key string)
+
+	RenewToken(ctx context.Context) error
+	SetDeadline(ctx context.Context, expire time.Time)
+	Destroy(ctx context.Context) error
+	// Iterate visits every stored session. Cookie sessions are not stored,
+	// so the cookie backend visits none.
+	Iterate(ctx context.Context, fn func(context.Context) error) error
+}
+
+var (
+	_ SessionManager = (*scs.SessionManager)(nil)
+	_ SessionManager = (*session.CookieManager)(nil)
+)
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"firecrest/ui/viewmodels"
+)
+
+// sitemapNamespace is the XML namespace of sitemaps and sitemap indexes.
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapChangeFreq tells crawlers how often event pages tend to change.
+const sitemapChangeFreq = "weekly"
+
+// sitemapURL is one page in a sitemap's urlset.
+type sitemapURL struct {
+	XMLName    xml.Name `xml:"url"`
+	Loc        string   `xml:"loc"`
+	LastMod    string   `xml:"lastmod,omitempty"`
+	ChangeFreq string   `xml:"changefreq"`
+}
+
+// sitemapRef is one sitemap file in a sitemap index.
+type sitemapRef struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+}
+
+// robots serves robots.txt, keeping crawlers out of pages that are only
+// useful signed in and pointing them at the sitemap.
+func (app *application) robots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	//nolint:errcheck // Nothing to do if the client has gone
+	fmt.Fprintf(w, "User-agent: *\nDisallow: /admin\nDisallow: /account\nDisallow: /auth\n\nSitemap: %s/sitemap.xml\n", app.publicBaseURL)
+}
+
+// sitemap serves the sitemap of event pages. Once there are too many for
+// one file it serves a sitemap index instead, linking to each file.
+func (app *application) sitemap(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	files, err := app.eventService.CountSitemapFiles(ctx)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	if files == 1 {
+		app.writeSitemapFile(ctx, w, r, 1)
+		return
+	}
+
+	app.writeSitemapXML(w, r, "sitemapindex", func(enc *xml.Encoder) error {
+		for file := 1; file <= files; file++ {
+			if err := enc.Encode(sitemapRef{Loc: app.sitemapFileURL(file)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// sitemapFile serves one of the files listed by the sitemap index.
+func (app *application) sitemapFile(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("file"), ".xml")
+	file, err := strconv.Atoi(name)
+	if !ok || err != nil || file < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	ctx, cancel := app.dbContext(r)
+	defer cancel()
+
+	files, err := app.eventService.CountSitemapFiles(ctx)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+	if file > files {
+		app.notFound(w, r)
+		return
+	}
+	app.writeSitemapFile(ctx, w, r, file)
+}
+
+// sitemapFileURL returns the public URL of a numbered sitemap file.
+func (app *application) sitemapFileURL(file int) string {
+	return fmt.Sprintf("%s/sitemaps/%d.xml", app.publicBaseURL, file)
+}
+
+// writeSitemapFile writes a urlset of the event pages in the numbered
+// sitemap file.
+func (app *application) writeSitemapFile(ctx context.Context, w http.ResponseWriter, r *http.Request, file int) {
+	events, err := app.eventService.ListSitemapEvents(ctx, file)
+	if err != nil {
+		app.handleServiceError(w, r, err)
+		return
+	}
+
+	app.writeSitemapXML(w, r, "urlset", func(enc *xml.Encoder) error {
+		for _, e := range events {
+			entry := sitemapURL{
+				Loc:        app.publicBaseURL + viewmodels.EventURL(e.Year, url.PathEscape(e.Slug)),
+				ChangeFreq: sitemapChangeFreq,
+			}
+			if e.UpdatedAt.Valid {
+				entry.LastMod = e.UpdatedAt.Time.UTC().Format(time.RFC3339)
+			}
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeSitemapXML streams an XML document whose root element, in the
+// sitemap namespace, holds the elements body encodes. The status is sent
+// before the body, so a failure part way through can only be logged.
+func (app *application) writeSitemapXML(w http.ResponseWriter, r *http.Request, root string, body func(enc *xml.Encoder) error) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+
+	enc := xml.NewEncoder(w)
+	start := xml.StartElement{Name: xml.Name{Space: sitemapNamespace, Local: root}}
+	err := func() error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		if err := body(enc); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(start.End()); err != nil {
+			return err
+		}
+		return enc.Flush()
+	}()
+	if err != nil && !app.clientGone(r, err) {
+		app.logger.Error("failed to write sitemap", "request_id", getRequestID(r), "uri", r.URL.RequestURI(), "error", err)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/servicemocks"
+)
+
+// sitemapURLSet mirrors the urlset element of the sitemap schema.
+type sitemapURLSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []struct {
+		Loc        string `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 loc"`
+		LastMod    string `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 lastmod"`
+		ChangeFreq string `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 changefreq"`
+	} `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 url"`
+}
+
+// sitemapIndex mirrors the sitemapindex element of the sitemap schema.
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []struct {
+		Loc string `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 loc"`
+	} `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemap"`
+}
+
+// serveRoutes sends a GET for path through the application's full router.
+func serveRoutes(app *application, path string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	return rr
+}
+
+// oneSitemapFile reports that every event fits in a single sitemap file.
+func oneSitemapFile(ctx context.Context) (int, error) {
+	return 1, nil
+}
+
+func TestSitemap(t *testing.T) {
+	updated := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+	t.Run("lists event pages with when they last changed", func(t *testing.T) {
+		events := &servicemocks.EventServiceMock{
+			CountSitemapFilesFunc: oneSitemapFile,
+			ListSitemapEventsFunc: func(ctx context.Context, file int) ([]db.ListSitemapEventsRow, error) {
+				return []db.ListSitemapEventsRow{
+					{Year: 2026, Slug: "lincoln-10k", UpdatedAt: pgtype.Timestamptz{Time: updated, Valid: true}},
+					{Year: 2025, Slug: "lincoln-10k", UpdatedAt: pgtype.Timestamptz{Time: updated, Valid: true}},
+				}, nil
+			},
+		}
+		app := newTestApplication(events, &servicemocks.UserServiceMock{})
+
+		rr := serveRoutes(app, "/sitemap.xml")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+			t.Errorf("expected an XML content type, got %q", ct)
+		}
+		if !strings.HasPrefix(rr.Body.String(), xml.Header) {
+			t.Errorf("expected an XML declaration, got %q", rr.Body.String())
+		}
+		var set sitemapURLSet
+		if err := xml.Unmarshal(rr.Body.Bytes(), &set); err != nil {
+			t.Fatalf("expected a urlset in the sitemap namespace: %v\n%s", err, rr.Body.String())
+		}
+		if calls := events.ListSitemapEventsCalls(); len(calls) != 1 || calls[0].File != 1 {
+			t.Errorf("expected one request for the first file of events, got %+v", calls)
+		}
+		if len(set.URLs) != 2 {
+			t.Fatalf("expected 2 URLs, got %d", len(set.URLs))
+		}
+		first := set.URLs[0]
+		if first.Loc != "https://firecrest.example/events/2026/lincoln-10k" {
+			t.Errorf("expected an absolute event URL, got %q", first.Loc)
+		}
+		if set.URLs[1].Loc != "https://firecrest.example/events/2025/lincoln-10k" {
+			t.Errorf("expected each edition to be listed, got %q", set.URLs[1].Loc)
+		}
+		if lastMod, err := time.Parse(time.RFC3339, first.LastMod); err != nil || !lastMod.Equal(updated) {
+			t.Errorf("expected lastmod %v, got %q", updated, first.LastMod)
+		}
+		if first.ChangeFreq != "weekly" {
+			t.Errorf("expected weekly changefreq, got %q", first.ChangeFreq)
+		}
+		for _, u := range set.URLs {
+			for _, private := range []string{"/admin", "/account", "/auth"} {
+				if strings.Contains(u.Loc, private) {
+					t.Errorf("expected no %s URLs, got %q", private, u.Loc)
+				}
+			}
+		}
+	})
+
+	t.Run("escapes event slugs", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			CountSitemapFilesFunc: oneSitemapFile,
+			ListSitemapEventsFunc: func(ctx context.Context, file int) ([]db.ListSitemapEventsRow, error) {
+				return []db.ListSitemapEventsRow{{Year: 2026, Slug: "fish&chips 5k"}}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+
+		var set sitemapURLSet
+		if err := xml.Unmarshal(serveRoutes(app, "/sitemap.xml").Body.Bytes(), &set); err != nil {
+			t.Fatalf("failed to parse sitemap: %v", err)
+		}
+		if len(set.URLs) != 1 || set.URLs[0].Loc != "https://firecrest.example/events/2026/fish&chips%205k" {
+			t.Errorf("expected the slug to be escaped, got %+v", set.URLs)
+		}
+	})
+
+	t.Run("serves an index once events need several files", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			CountSitemapFilesFunc: func(ctx context.Context) (int, error) {
+				return 3, nil
+			},
+			ListSitemapEventsFunc: func(ctx context.Context, file int) ([]db.ListSitemapEventsRow, error) {
+				t.Error("expected the index not to list events")
+				return nil, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+
+		rr := serveRoutes(app, "/sitemap.xml")
+
+		var index sitemapIndex
+		if err := xml.Unmarshal(rr.Body.Bytes(), &index); err != nil {
+			t.Fatalf("expected a sitemapindex in the sitemap namespace: %v\n%s", err, rr.Body.String())
+		}
+		if len(index.Sitemaps) != 3 {
+			t.Fatalf("expected 3 sitemap files, got %d", len(index.Sitemaps))
+		}
+		if index.Sitemaps[2].Loc != "https://firecrest.example/sitemaps/3.xml" {
+			t.Errorf("unexpected sitemap file URL %q", index.Sitemaps[2].Loc)
+		}
+	})
+
+	t.Run("serves the files an index lists", func(t *testing.T) {
+		var gotFile int
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			CountSitemapFilesFunc: func(ctx context.Context) (int, error) {
+				return 3, nil
+			},
+			ListSitemapEventsFunc: func(ctx context.Context, file int) ([]db.ListSitemapEventsRow, error) {
+				gotFile = file
+				return []db.ListSitemapEventsRow{{Slug: "lincoln-10k"}}, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+
+		rr := serveRoutes(app, "/sitemaps/2.xml")
+
+		var set sitemapURLSet
+		if err := xml.Unmarshal(rr.Body.Bytes(), &set); err != nil {
+			t.Fatalf("expected a urlset: %v\n%s", err, rr.Body.String())
+		}
+		if gotFile != 2 || len(set.URLs) != 1 {
+			t.Errorf("expected the second file's events, got file %d with %+v", gotFile, set.URLs)
+		}
+		if set.URLs[0].LastMod != "" {
+			t.Errorf("expected no lastmod for an event without one, got %q", set.URLs[0].LastMod)
+		}
+	})
+
+	t.Run("returns 404 for files past the last", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			CountSitemapFilesFunc: func(ctx context.Context) (int, error) {
+				return 3, nil
+			},
+		}, &servicemocks.UserServiceMock{})
+
+		for _, path := range []string{"/sitemaps/4.xml", "/sitemaps/0.xml", "/sitemaps/one.xml", "/sitemaps/2"} {
+			if rr := serveRoutes(app, path); rr.Code != http.StatusNotFound {
+				t.Errorf("expected %s to be not found, got %d", path, rr.Code)
+			}
+		}
+	})
+
+	t.Run("returns 500 when events cannot be listed", func(t *testing.T) {
+		app := newTestApplication(&servicemocks.EventServiceMock{
+			CountSitemapFilesFunc: func(ctx context.Context) (int, error) {
+				return 0, errors.New("database unavailable")
+			},
+		}, &servicemocks.UserServiceMock{})
+
+		if rr := serveRoutes(app, "/sitemap.xml"); rr.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+}
+
+func TestRobots(t *testing.T) {
+	app := newTestApplication(&servicemocks.EventServiceMock{}, &servicemocks.UserServiceMock{})
+
+	rr := serveRoutes(app, "/robots.txt")
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("expected a plain text content type, got %q", ct)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{
+		"User-agent: *",
+		"Disallow: /admin\n",
+		"Disallow: /account\n",
+		"Disallow: /auth\n",
+		"Sitemap: https://firecrest.example/sitemap.xml",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected robots.txt to contain %q, got:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+
+	"firecrest/ui"
+)
+
+// Cache policies for static files. Fingerprinted URLs name one version of a
+// file forever; plain URLs must be revalidated on every use.
+const (
+	immutableCacheControl  = "public, max-age=31536000, immutable"
+	revalidateCacheControl = "no-cache"
+)
+
+// staticFiles serves the embedded files under /static/. Files requested by
+// the fingerprinted name from ui.AssetPath are cached indefinitely; every
+// known file carries its content hash as an ETag so plain requests can be
+// answered with 304 Not Modified.
+func staticFiles(fsys fs.FS) http.Handler {
+	fileServer := http.FileServerFS(filesOnly{fsys})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asset, ok := ui.LookupAsset(strings.TrimPrefix(r.URL.Path, "/static/"))
+		if !ok {
+			w.Header().Set("Cache-Control", revalidateCacheControl)
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("ETag", `"`+asset.Hash+`"`)
+		if !asset.Fingerprinted {
+			w.Header().Set("Cache-Control", revalidateCacheControl)
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Cache-Control", immutableCacheControl)
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/static/" + asset.Name
+		r2.URL.RawPath = ""
+		fileServer.ServeHTTP(w, r2)
+	})
+}
+
+// diskStaticFiles serves the files in dir under /static/, reading them on
+// every request so edits show without a restart. Fingerprinted names are
+// resolved to the file they were built from, and nothing is cached, since
+// the fingerprints and ETags describe the embedded copies. It is for
+// development only.
+func diskStaticFiles(dir string) http.Handler {
+	fileServer := http.FileServerFS(filesOnly{os.DirFS(dir)})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/static/")
+		if asset, ok := ui.LookupAsset(name); ok {
+			name = asset.Name
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/" + name
+		r2.URL.RawPath = ""
+		fileServer.ServeHTTP(w, r2)
+	})
+}
+
+// filesOnly hides the directories in an fs.FS, so a file server answers
+// requests for them with 404 instead of listing their contents.
+type filesOnly struct {
+	fsys fs.FS
+}
+
+func (f filesOnly) Open(name string) (fs.File, error) {
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return file, nil
+}
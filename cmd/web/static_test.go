@@ -0,0 +1,178 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"firecrest/ui"
+)
+
+func TestStaticFiles(t *testing.T) {
+	h := staticFiles(ui.Files)
+	serve := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("caches fingerprinted assets forever", func(t *testing.T) {
+		path := ui.AssetPath("main.css")
+		if path == "/static/main.css" {
+			t.Fatal("expected main.css to be fingerprinted")
+		}
+
+		rr := serve(path, "")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Cache-Control"); got != "public, max-age=31536000, immutable" {
+			t.Errorf("expected immutable caching, got %q", got)
+		}
+		if !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/css") {
+			t.Errorf("expected a stylesheet, got %q", rr.Header().Get("Content-Type"))
+		}
+		if rr.Body.Len() == 0 {
+			t.Error("expected the stylesheet body")
+		}
+	})
+
+	t.Run("revalidates plain asset paths", func(t *testing.T) {
+		rr := serve("/static/main.css", "")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if got := rr.Header().Get("Cache-Control"); got != "no-cache" {
+			t.Errorf("expected no-cache, got %q", got)
+		}
+		etag := rr.Header().Get("ETag")
+		if etag == "" {
+			t.Fatal("expected an ETag")
+		}
+
+		rr = serve("/static/main.css", etag)
+
+		if rr.Code != http.StatusNotModified {
+			t.Fatalf("expected status %d, got %d", http.StatusNotModified, rr.Code)
+		}
+		if rr.Body.Len() != 0 {
+			t.Errorf("expected no body, got %d bytes", rr.Body.Len())
+		}
+	})
+
+	t.Run("answers a weakened ETag from compression with 304", func(t *testing.T) {
+		etag := serve("/static/main.css", "").Header().Get("ETag")
+
+		rr := serve("/static/main.css", "W/"+etag)
+
+		if rr.Code != http.StatusNotModified {
+			t.Errorf("expected status %d, got %d", http.StatusNotModified, rr.Code)
+		}
+	})
+
+	t.Run("returns 404 for unknown files", func(t *testing.T) {
+		rr := serve("/static/missing.css", "")
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("serves scripts from the embedded files", func(t *testing.T) {
+		rr := serve(ui.AssetPath("js/event-form.js"), "")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+		if !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/javascript") {
+			t.Errorf("expected a script, got %q", rr.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("does not list directories", func(t *testing.T) {
+		for _, path := range []string{"/static/", "/static/js/", "/static/js"} {
+			rr := serve(path, "")
+
+			if rr.Code != http.StatusNotFound {
+				t.Errorf("%s: expected status %d, got %d", path, http.StatusNotFound, rr.Code)
+			}
+			if strings.Contains(rr.Body.String(), "event-form.js") {
+				t.Errorf("%s: expected the directory not to be listed", path)
+			}
+		}
+	})
+}
+
+func TestDiskStaticFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "js"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.css"), []byte("body { color: red; }"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := diskStaticFiles(dir)
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		return rr
+	}
+
+	t.Run("serves the file on disk, uncached", func(t *testing.T) {
+		for _, path := range []string{"/static/main.css", ui.AssetPath("main.css")} {
+			rr := serve(path)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("%s: expected status %d, got %d", path, http.StatusOK, rr.Code)
+			}
+			if rr.Body.String() != "body { color: red; }" {
+				t.Errorf("%s: expected the file on disk, got %q", path, rr.Body.String())
+			}
+			if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("%s: expected no-store, got %q", path, got)
+			}
+			if rr.Header().Get("ETag") != "" {
+				t.Errorf("%s: expected no ETag", path)
+			}
+		}
+	})
+
+	t.Run("does not list directories", func(t *testing.T) {
+		for _, path := range []string{"/static/", "/static/js/"} {
+			if rr := serve(path); rr.Code != http.StatusNotFound {
+				t.Errorf("%s: expected status %d, got %d", path, http.StatusNotFound, rr.Code)
+			}
+		}
+	})
+}
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: `W/"abc"`, want: true},
+		{header: `"abc"`, want: true},
+		{header: `"other", W/"abc"`, want: true},
+		{header: "*", want: true},
+		{header: `W/"abcd"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := etagMatches(tt.header, `W/"abc"`); got != tt.want {
+				t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
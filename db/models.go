@@ -14,9 +14,12 @@ import (
 type AuditAction string
 
 const (
-	AuditActionCreated AuditAction = "created"
-	AuditActionUpdated AuditAction = "updated"
-	AuditActionDeleted AuditAction = "deleted"
+	AuditActionCreated              AuditAction = "created"
+	AuditActionUpdated              AuditAction = "updated"
+	AuditActionDeleted              AuditAction = "deleted"
+	AuditActionImpersonationStarted AuditAction = "impersonation_started"
+	AuditActionImpersonationStopped AuditAction = "impersonation_stopped"
+	AuditActionImpersonatedRequest  AuditAction = "impersonated_request"
 )
 
 func (e *AuditAction) Scan(src interface{}) error {
@@ -96,6 +99,478 @@ func (ns NullAuthProvider) Value() (driver.Value, error) {
 	return string(ns.AuthProvider), nil
 }
 
+type CustomFieldKind string
+
+const (
+	CustomFieldKindText     CustomFieldKind = "text"
+	CustomFieldKindSelect   CustomFieldKind = "select"
+	CustomFieldKindCheckbox CustomFieldKind = "checkbox"
+)
+
+func (e *CustomFieldKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CustomFieldKind(s)
+	case string:
+		*e = CustomFieldKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CustomFieldKind: %T", src)
+	}
+	return nil
+}
+
+type NullCustomFieldKind struct {
+	CustomFieldKind CustomFieldKind
+	Valid           bool // Valid is true if CustomFieldKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCustomFieldKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.CustomFieldKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CustomFieldKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCustomFieldKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CustomFieldKind), nil
+}
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPending DataExportStatus = "pending"
+	DataExportStatusReady   DataExportStatus = "ready"
+	DataExportStatusFailed  DataExportStatus = "failed"
+)
+
+func (e *DataExportStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DataExportStatus(s)
+	case string:
+		*e = DataExportStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DataExportStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDataExportStatus struct {
+	DataExportStatus DataExportStatus
+	Valid            bool // Valid is true if DataExportStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDataExportStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DataExportStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DataExportStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDataExportStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DataExportStatus), nil
+}
+
+type DistanceUnit string
+
+const (
+	DistanceUnitMiles DistanceUnit = "miles"
+	DistanceUnitKm    DistanceUnit = "km"
+)
+
+func (e *DistanceUnit) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DistanceUnit(s)
+	case string:
+		*e = DistanceUnit(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DistanceUnit: %T", src)
+	}
+	return nil
+}
+
+type NullDistanceUnit struct {
+	DistanceUnit DistanceUnit
+	Valid        bool // Valid is true if DistanceUnit is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDistanceUnit) Scan(value interface{}) error {
+	if value == nil {
+		ns.DistanceUnit, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DistanceUnit.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDistanceUnit) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DistanceUnit), nil
+}
+
+type EmailPreference string
+
+const (
+	EmailPreferenceMarketing     EmailPreference = "marketing"
+	EmailPreferenceTransactional EmailPreference = "transactional"
+)
+
+func (e *EmailPreference) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmailPreference(s)
+	case string:
+		*e = EmailPreference(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmailPreference: %T", src)
+	}
+	return nil
+}
+
+type NullEmailPreference struct {
+	EmailPreference EmailPreference
+	Valid           bool // Valid is true if EmailPreference is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmailPreference) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmailPreference, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmailPreference.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmailPreference) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmailPreference), nil
+}
+
+type EventStatus string
+
+const (
+	EventStatusDraft     EventStatus = "draft"
+	EventStatusPublished EventStatus = "published"
+	EventStatusArchived  EventStatus = "archived"
+)
+
+func (e *EventStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EventStatus(s)
+	case string:
+		*e = EventStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EventStatus: %T", src)
+	}
+	return nil
+}
+
+type NullEventStatus struct {
+	EventStatus EventStatus
+	Valid       bool // Valid is true if EventStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEventStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.EventStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EventStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEventStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EventStatus), nil
+}
+
+type LoginEventKind string
+
+const (
+	LoginEventKindSignedIn LoginEventKind = "signed_in"
+	LoginEventKindFailed   LoginEventKind = "failed"
+	LoginEventKindLocked   LoginEventKind = "locked"
+)
+
+func (e *LoginEventKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LoginEventKind(s)
+	case string:
+		*e = LoginEventKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LoginEventKind: %T", src)
+	}
+	return nil
+}
+
+type NullLoginEventKind struct {
+	LoginEventKind LoginEventKind
+	Valid          bool // Valid is true if LoginEventKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLoginEventKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.LoginEventKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LoginEventKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLoginEventKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LoginEventKind), nil
+}
+
+type NotificationPreference string
+
+const (
+	NotificationPreferenceImmediate   NotificationPreference = "immediate"
+	NotificationPreferenceDailyDigest NotificationPreference = "daily_digest"
+	NotificationPreferenceNone        NotificationPreference = "none"
+)
+
+func (e *NotificationPreference) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = NotificationPreference(s)
+	case string:
+		*e = NotificationPreference(s)
+	default:
+		return fmt.Errorf("unsupported scan type for NotificationPreference: %T", src)
+	}
+	return nil
+}
+
+type NullNotificationPreference struct {
+	NotificationPreference NotificationPreference
+	Valid                  bool // Valid is true if NotificationPreference is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullNotificationPreference) Scan(value interface{}) error {
+	if value == nil {
+		ns.NotificationPreference, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.NotificationPreference.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullNotificationPreference) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.NotificationPreference), nil
+}
+
+type OrganisationRole string
+
+const (
+	OrganisationRoleOwner OrganisationRole = "owner"
+	OrganisationRoleAdmin OrganisationRole = "admin"
+	OrganisationRoleStaff OrganisationRole = "staff"
+)
+
+func (e *OrganisationRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrganisationRole(s)
+	case string:
+		*e = OrganisationRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrganisationRole: %T", src)
+	}
+	return nil
+}
+
+type NullOrganisationRole struct {
+	OrganisationRole OrganisationRole
+	Valid            bool // Valid is true if OrganisationRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrganisationRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrganisationRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrganisationRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrganisationRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrganisationRole), nil
+}
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "pending"
+	PaymentStatusSucceeded         PaymentStatus = "succeeded"
+	PaymentStatusFailed            PaymentStatus = "failed"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+)
+
+func (e *PaymentStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PaymentStatus(s)
+	case string:
+		*e = PaymentStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PaymentStatus: %T", src)
+	}
+	return nil
+}
+
+type NullPaymentStatus struct {
+	PaymentStatus PaymentStatus
+	Valid         bool // Valid is true if PaymentStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPaymentStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.PaymentStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PaymentStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPaymentStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PaymentStatus), nil
+}
+
+type RegistrationSource string
+
+const (
+	RegistrationSourceOnline   RegistrationSource = "online"
+	RegistrationSourceImported RegistrationSource = "imported"
+)
+
+func (e *RegistrationSource) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RegistrationSource(s)
+	case string:
+		*e = RegistrationSource(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RegistrationSource: %T", src)
+	}
+	return nil
+}
+
+type NullRegistrationSource struct {
+	RegistrationSource RegistrationSource
+	Valid              bool // Valid is true if RegistrationSource is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRegistrationSource) Scan(value interface{}) error {
+	if value == nil {
+		ns.RegistrationSource, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RegistrationSource.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRegistrationSource) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RegistrationSource), nil
+}
+
+type RegistrationStatus string
+
+const (
+	RegistrationStatusPending   RegistrationStatus = "pending"
+	RegistrationStatusConfirmed RegistrationStatus = "confirmed"
+	RegistrationStatusCancelled RegistrationStatus = "cancelled"
+)
+
+func (e *RegistrationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RegistrationStatus(s)
+	case string:
+		*e = RegistrationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RegistrationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullRegistrationStatus struct {
+	RegistrationStatus RegistrationStatus
+	Valid              bool // Valid is true if RegistrationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRegistrationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.RegistrationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RegistrationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRegistrationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RegistrationStatus), nil
+}
+
 type UserRole string
 
 const (
@@ -139,6 +614,71 @@ func (ns NullUserRole) Value() (driver.Value, error) {
 	return string(ns.UserRole), nil
 }
 
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+func (e *WebhookDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatus(s)
+	case string:
+		*e = WebhookDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatus struct {
+	WebhookDeliveryStatus WebhookDeliveryStatus
+	Valid                 bool // Valid is true if WebhookDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatus), nil
+}
+
+type ApiToken struct {
+	ID         int64
+	UserID     int64
+	Label      string
+	TokenHash  []byte
+	Scopes     []string
+	LastUsedAt pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+}
+
+type AuditLog struct {
+	ID            int64
+	TableName     string
+	RecordID      int64
+	Action        AuditAction
+	UserID        pgtype.Int8
+	ChangedFields []byte
+	CreatedAt     pgtype.Timestamptz
+}
+
 type AuthCredential struct {
 	ID                  int64
 	UserID              int64
@@ -152,30 +692,139 @@ type AuthCredential struct {
 	DeletedAt           pgtype.Timestamptz
 }
 
+type DataExport struct {
+	ID           int64
+	UserID       int64
+	Status       DataExportStatus
+	LeaseUntil   pgtype.Timestamptz
+	StorageKey   pgtype.Text
+	TokenHash    []byte
+	ExpiresAt    pgtype.Timestamptz
+	DownloadedAt pgtype.Timestamptz
+	CompletedAt  pgtype.Timestamptz
+	CreatedAt    pgtype.Timestamptz
+}
+
+type DiscountCode struct {
+	ID             int64
+	EventID        int64
+	RaceID         pgtype.Int8
+	Code           string
+	PercentOff     pgtype.Int4
+	AmountOffUnits pgtype.Int4
+	MaxUses        pgtype.Int4
+	Uses           int32
+	ValidFrom      pgtype.Timestamptz
+	ValidUntil     pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+	DeletedAt      pgtype.Timestamptz
+}
+
+type EmailVerificationToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash []byte
+	ExpiresAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
 type Event struct {
 	ID             int64
 	OrganisationID int64
 	Name           string
 	Slug           string
+	Year           int32
+	Description    string
+	Location       string
+	ImageUrl       string
 	CreatedAt      pgtype.Timestamptz
 	UpdatedAt      pgtype.Timestamptz
 	DeletedAt      pgtype.Timestamptz
+	Status         EventStatus
+	Timezone       string
+	SeriesID       pgtype.Int8
 }
 
-type Organisation struct {
+type EventEnquiry struct {
 	ID        int64
+	EventID   int64
 	Name      string
+	Email     string
+	Message   string
 	CreatedAt pgtype.Timestamptz
-	UpdatedAt pgtype.Timestamptz
-	DeletedAt pgtype.Timestamptz
 }
 
-type OrganisationUser struct {
+type EventPhoto struct {
+	ID          int64
+	EventID     int64
+	Position    int32
+	OriginalKey string
+	WebKey      string
+	Width       int32
+	Height      int32
+	CreatedAt   pgtype.Timestamptz
+}
+
+type LoginEvent struct {
+	ID        int64
+	UserID    int64
+	Kind      LoginEventKind
+	IpAddress string
+	UserAgent string
+	Alerted   bool
+	CreatedAt pgtype.Timestamptz
+}
+
+type Organisation struct {
+	ID           int64
+	Name         string
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+	DeletedAt    pgtype.Timestamptz
+	ContactEmail pgtype.Text
+	BrandColour  pgtype.Text
+	LogoKey      pgtype.Text
+	BannerKey    pgtype.Text
+	EmbedPublic  bool
+	EmbedOrigins []string
+}
+
+type OrganisationInvitation struct {
 	ID             int64
 	OrganisationID int64
 	UserID         int64
+	Role           OrganisationRole
+	InvitedBy      pgtype.Int8
+	AcceptedAt     pgtype.Timestamptz
 	CreatedAt      pgtype.Timestamptz
-	DeletedAt      pgtype.Timestamptz
+}
+
+type OrganisationMember struct {
+	ID                     int64
+	OrganisationID         int64
+	UserID                 int64
+	CreatedAt              pgtype.Timestamptz
+	DeletedAt              pgtype.Timestamptz
+	Role                   OrganisationRole
+	NotificationPreference NotificationPreference
+	DigestSentFor          pgtype.Date
+	LastUsedAt             pgtype.Timestamptz
+}
+
+type Payment struct {
+	ID                int64
+	RegistrationID    int64
+	AmountUnits       int32
+	Currency          string
+	Status            PaymentStatus
+	ProviderReference pgtype.Text
+	RefundedUnits     int32
+	RefundedAt        pgtype.Timestamptz
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+	FeeUnits          pgtype.Int4
 }
 
 type Race struct {
@@ -185,12 +834,127 @@ type Race struct {
 	Slug                  string
 	RegistrationOpenDate  pgtype.Timestamptz
 	RegistrationCloseDate pgtype.Timestamptz
+	StartsAt              pgtype.Timestamptz
 	MaxCapacity           int32
 	PriceUnits            pgtype.Int4
 	Currency              pgtype.Text
 	CreatedAt             pgtype.Timestamptz
 	UpdatedAt             pgtype.Timestamptz
 	DeletedAt             pgtype.Timestamptz
+	MinAge                pgtype.Int4
+	DistanceMetres        pgtype.Int4
+	ElevationGainMetres   pgtype.Int4
+}
+
+type RaceCustomField struct {
+	ID        int64
+	RaceID    int64
+	Position  int32
+	Version   int32
+	CreatedAt pgtype.Timestamptz
+	DeletedAt pgtype.Timestamptz
+}
+
+type RaceCustomFieldVersion struct {
+	FieldID   int64
+	Version   int32
+	Label     string
+	Kind      CustomFieldKind
+	Options   []string
+	Required  bool
+	CreatedAt pgtype.Timestamptz
+}
+
+type RacePriceTier struct {
+	ID         int64
+	RaceID     int64
+	Name       string
+	PriceUnits int32
+	ValidFrom  pgtype.Timestamptz
+	ValidTo    pgtype.Timestamptz
+	Capacity   pgtype.Int4
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type RaceRequiredQuestion struct {
+	RaceID   int64
+	Question string
+}
+
+type RaceResult struct {
+	ID             int64
+	RaceID         int64
+	RegistrationID pgtype.Int8
+	Name           string
+	Bib            string
+	Position       int32
+	FinishSeconds  int32
+	Category       string
+	Published      bool
+	CreatedAt      pgtype.Timestamptz
+}
+
+type RaceRoute struct {
+	RaceID              int64
+	GpxGzip             []byte
+	PointCount          int32
+	DistanceMetres      int32
+	ElevationGainMetres int32
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
+}
+
+type RaceWave struct {
+	ID        int64
+	RaceID    int64
+	Name      string
+	StartsAt  pgtype.Timestamptz
+	Capacity  int32
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type Registration struct {
+	ID             int64
+	UserID         int64
+	RaceID         int64
+	Status         RegistrationStatus
+	Source         RegistrationSource
+	Bib            pgtype.Text
+	CancelledAt    pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+	DeletedAt      pgtype.Timestamptz
+	ReminderSentAt pgtype.Timestamptz
+	TeamID         pgtype.Int8
+	PriceUnits     pgtype.Int4
+	DiscountCodeID pgtype.Int8
+	DiscountUnits  int32
+	WaveID         pgtype.Int8
+	CheckedInAt    pgtype.Timestamptz
+	PriceTierID    pgtype.Int8
+}
+
+type RegistrationAnswer struct {
+	RegistrationID int64
+	AnswersSealed  []byte
+	CreatedAt      pgtype.Timestamptz
+}
+
+type RegistrationCustomAnswer struct {
+	RegistrationID int64
+	Answers        []byte
+	CreatedAt      pgtype.Timestamptz
+}
+
+type RegistrationTransfer struct {
+	ID             int64
+	RegistrationID int64
+	FromUserID     int64
+	ToUserID       int64
+	AcceptedAt     pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
 }
 
 type Session struct {
@@ -209,20 +973,81 @@ type SocialAccount struct {
 	DeletedAt      pgtype.Timestamptz
 }
 
+type Team struct {
+	ID            int64
+	RaceID        int64
+	CaptainUserID int64
+	Name          string
+	Size          int32
+	InviteCode    string
+	FillBy        pgtype.Timestamptz
+	ReleasedAt    pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+	DeletedAt     pgtype.Timestamptz
+}
+
 type User struct {
-	ID           int64
-	Email        string
-	FirstName    string
-	LastName     string
-	Phone        pgtype.Text
-	AddressLine1 pgtype.Text
-	AddressLine2 pgtype.Text
-	City         pgtype.Text
-	State        pgtype.Text
-	PostalCode   pgtype.Text
-	Country      pgtype.Text
-	Role         UserRole
-	CreatedAt    pgtype.Timestamptz
-	UpdatedAt    pgtype.Timestamptz
-	DeletedAt    pgtype.Timestamptz
+	ID              int64
+	Email           string
+	FirstName       string
+	LastName        string
+	Phone           pgtype.Text
+	AddressLine1    pgtype.Text
+	AddressLine2    pgtype.Text
+	City            pgtype.Text
+	State           pgtype.Text
+	PostalCode      pgtype.Text
+	Country         pgtype.Text
+	Role            UserRole
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+	DeletedAt       pgtype.Timestamptz
+	AnonymisedAt    pgtype.Timestamptz
+	EmailPreference EmailPreference
+	DeactivatedAt   pgtype.Timestamptz
+	DateOfBirth     pgtype.Date
+	DistanceUnit    DistanceUnit
+	SessionVersion  int32
+}
+
+type UserSession struct {
+	ID         int64
+	UserID     int64
+	UserAgent  string
+	IpAddress  string
+	LastSeenAt pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+	EvictedAt  pgtype.Timestamptz
+}
+
+type WebhookDelivery struct {
+	ID            int64
+	EndpointID    int64
+	EventType     string
+	Payload       []byte
+	Status        WebhookDeliveryStatus
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+}
+
+type WebhookDeliveryAttempt struct {
+	ID          int64
+	DeliveryID  int64
+	AttemptedAt pgtype.Timestamptz
+	StatusCode  pgtype.Int4
+	Error       string
+	DurationMs  int32
+}
+
+type WebhookEndpoint struct {
+	ID             int64
+	OrganisationID int64
+	Url            string
+	SecretSealed   []byte
+	EventTypes     []string
+	CreatedAt      pgtype.Timestamptz
 }
@@ -11,290 +11,3509 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createAuthCredentials = `-- name: CreateAuthCredentials :one
-
-INSERT INTO auth_credentials (
-    user_id,
-    password_hash
-) VALUES ($1, $2)
-RETURNING id, user_id, password_hash, email_verified_at, last_login_at, failed_login_attempts, locked_until, created_at, updated_at, deleted_at
+const acceptOrganisationInvitations = `-- name: AcceptOrganisationInvitations :execrows
+WITH accepted AS (
+  UPDATE organisation_invitations
+  SET accepted_at = NOW()
+  WHERE user_id = $1
+  AND accepted_at IS NULL
+  RETURNING organisation_id, user_id, role, created_at
+)
+INSERT INTO organisation_members (organisation_id, user_id, role)
+SELECT DISTINCT ON (organisation_id) organisation_id, user_id, role
+FROM accepted
+ORDER BY organisation_id, created_at DESC
+ON CONFLICT (organisation_id, user_id) DO UPDATE
+SET role = EXCLUDED.role,
+    created_at = NOW(),
+    deleted_at = NULL
+WHERE organisation_members.deleted_at IS NOT NULL
 `
 
-type CreateAuthCredentialsParams struct {
-	UserID       int64
-	PasswordHash string
+// Accepting makes the invitee a member of each organisation they were
+// invited to, in the role of the latest invitation. Current members keep
+// the role they have.
+func (q *Queries) AcceptOrganisationInvitations(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, acceptOrganisationInvitations, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-// Auth Credentials Queries
-func (q *Queries) CreateAuthCredentials(ctx context.Context, arg CreateAuthCredentialsParams) (AuthCredential, error) {
-	row := q.db.QueryRow(ctx, createAuthCredentials, arg.UserID, arg.PasswordHash)
-	var i AuthCredential
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.PasswordHash,
-		&i.EmailVerifiedAt,
-		&i.LastLoginAt,
-		&i.FailedLoginAttempts,
-		&i.LockedUntil,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-	)
-	return i, err
+const acceptRegistrationTransfers = `-- name: AcceptRegistrationTransfers :execrows
+UPDATE registration_transfers
+SET accepted_at = NOW()
+WHERE to_user_id = $1
+AND accepted_at IS NULL
+`
+
+func (q *Queries) AcceptRegistrationTransfers(ctx context.Context, toUserID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, acceptRegistrationTransfers, toUserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const createEvent = `-- name: CreateEvent :one
-INSERT INTO events (
-  organisation_id,
-  name,
-  slug)
+const addOrganisationMember = `-- name: AddOrganisationMember :one
+INSERT INTO organisation_members (organisation_id, user_id, role)
 VALUES ($1, $2, $3)
-RETURNING id, organisation_id, name, slug, created_at, updated_at, deleted_at
+ON CONFLICT (organisation_id, user_id) DO UPDATE
+SET role = EXCLUDED.role,
+    created_at = NOW(),
+    deleted_at = NULL
+WHERE organisation_members.deleted_at IS NOT NULL
+RETURNING id, organisation_id, user_id, created_at, deleted_at, role, notification_preference, digest_sent_for, last_used_at
 `
 
-type CreateEventParams struct {
+type AddOrganisationMemberParams struct {
 	OrganisationID int64
-	Name           string
-	Slug           string
+	UserID         int64
+	Role           OrganisationRole
 }
 
-func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event, error) {
-	row := q.db.QueryRow(ctx, createEvent, arg.OrganisationID, arg.Name, arg.Slug)
-	var i Event
+// Adding a removed member restores their membership with the new role; a
+// current member is left as they are and nothing is returned.
+func (q *Queries) AddOrganisationMember(ctx context.Context, arg AddOrganisationMemberParams) (OrganisationMember, error) {
+	row := q.db.QueryRow(ctx, addOrganisationMember, arg.OrganisationID, arg.UserID, arg.Role)
+	var i OrganisationMember
 	err := row.Scan(
 		&i.ID,
 		&i.OrganisationID,
-		&i.Name,
-		&i.Slug,
+		&i.UserID,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.Role,
+		&i.NotificationPreference,
+		&i.DigestSentFor,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
 
-const createOrganisation = `-- name: CreateOrganisation :one
-INSERT INTO organisations (
-  name)
-VALUES ($1)
-RETURNING id, name, created_at, updated_at, deleted_at
+const addRaceLockedQuestions = `-- name: AddRaceLockedQuestions :exec
+INSERT INTO race_locked_questions (race_id, question)
+SELECT $1, unnest($2::text[])
 `
 
-func (q *Queries) CreateOrganisation(ctx context.Context, name string) (Organisation, error) {
-	row := q.db.QueryRow(ctx, createOrganisation, name)
-	var i Organisation
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-	)
-	return i, err
+type AddRaceLockedQuestionsParams struct {
+	RaceID    int64
+	Questions []string
 }
 
-const createUser = `-- name: CreateUser :one
-INSERT INTO users (
-  email,
-  first_name,
-  last_name,
-  phone,
-  address_line1,
-  address_line2,
-  city,
-  state,
-  postal_code,
-  country,
-  role)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-RETURNING id, email, first_name, last_name, phone, address_line1, address_line2, city, state, postal_code, country, role, created_at, updated_at, deleted_at
+func (q *Queries) AddRaceLockedQuestions(ctx context.Context, arg AddRaceLockedQuestionsParams) error {
+	_, err := q.db.Exec(ctx, addRaceLockedQuestions, arg.RaceID, arg.Questions)
+	return err
+}
+
+const addRaceRequiredQuestions = `-- name: AddRaceRequiredQuestions :exec
+INSERT INTO race_required_questions (race_id, question)
+SELECT $1, unnest($2::text[])
 `
 
-type CreateUserParams struct {
-	Email        string
-	FirstName    string
-	LastName     string
-	Phone        pgtype.Text
-	AddressLine1 pgtype.Text
-	AddressLine2 pgtype.Text
-	City         pgtype.Text
-	State        pgtype.Text
-	PostalCode   pgtype.Text
-	Country      pgtype.Text
-	Role         UserRole
+type AddRaceRequiredQuestionsParams struct {
+	RaceID    int64
+	Questions []string
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, createUser,
-		arg.Email,
-		arg.FirstName,
-		arg.LastName,
-		arg.Phone,
-		arg.AddressLine1,
-		arg.AddressLine2,
-		arg.City,
-		arg.State,
-		arg.PostalCode,
-		arg.Country,
-		arg.Role,
-	)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.FirstName,
-		&i.LastName,
-		&i.Phone,
-		&i.AddressLine1,
-		&i.AddressLine2,
-		&i.City,
-		&i.State,
-		&i.PostalCode,
-		&i.Country,
-		&i.Role,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-	)
-	return i, err
+func (q *Queries) AddRaceRequiredQuestions(ctx context.Context, arg AddRaceRequiredQuestionsParams) error {
+	_, err := q.db.Exec(ctx, addRaceRequiredQuestions, arg.RaceID, arg.Questions)
+	return err
 }
 
-const deleteEvent = `-- name: DeleteEvent :exec
-UPDATE events
-SET deleted_at = NOW()
+const anonymiseUser = `-- name: AnonymiseUser :execrows
+UPDATE users
+SET email = 'deleted-' || id || '@anonymised.invalid',
+    first_name = '',
+    last_name = '',
+    phone = NULL,
+    address_line1 = NULL,
+    address_line2 = NULL,
+    city = NULL,
+    state = NULL,
+    postal_code = NULL,
+    country = NULL,
+    date_of_birth = NULL,
+    role = 'entrant',
+    anonymised_at = NOW(),
+    deleted_at = NOW()
 WHERE id = $1
+AND anonymised_at IS NULL
 `
 
-func (q *Queries) DeleteEvent(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteEvent, id)
-	return err
+// Anonymising keeps the user, and the registrations that point at it, but
+// removes everything that identifies them. The email becomes a tombstone on
+// the reserved .invalid domain, freeing the address for a new account.
+func (q *Queries) AnonymiseUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, anonymiseUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const deleteOrganisation = `-- name: DeleteOrganisation :exec
-UPDATE organisations
-SET deleted_at = NOW()
+const assignRegistrationBib = `-- name: AssignRegistrationBib :execrows
+UPDATE registrations
+SET bib = $2
 WHERE id = $1
+AND bib IS NULL
+AND status = 'confirmed'
+AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteOrganisation(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteOrganisation, id)
-	return err
+type AssignRegistrationBibParams struct {
+	ID  int64
+	Bib pgtype.Text
 }
 
-const deleteUser = `-- name: DeleteUser :exec
+// Only confirmed registrations without a bib are numbered, so assigning
+// again never renumbers anyone.
+func (q *Queries) AssignRegistrationBib(ctx context.Context, arg AssignRegistrationBibParams) (int64, error) {
+	result, err := q.db.Exec(ctx, assignRegistrationBib, arg.ID, arg.Bib)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const bumpUserSessionVersion = `-- name: BumpUserSessionVersion :exec
 UPDATE users
-SET deleted_at = NOW()
+SET session_version = session_version + 1
 WHERE id = $1
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
+// Ends every session the user has, cookie sessions included, which are
+// refused once their version is behind the user's.
+func (q *Queries) BumpUserSessionVersion(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, bumpUserSessionVersion, id)
 	return err
 }
 
-const getAuthCredentialsByEmail = `-- name: GetAuthCredentialsByEmail :one
-SELECT ac.id, ac.user_id, ac.password_hash, ac.email_verified_at, ac.last_login_at, ac.failed_login_attempts, ac.locked_until, ac.created_at, ac.updated_at, ac.deleted_at FROM auth_credentials ac
-INNER JOIN users u ON ac.user_id = u.id
-WHERE u.email = $1
-AND ac.deleted_at IS NULL
-AND u.deleted_at IS NULL
-LIMIT 1
+const cancelRegistration = `-- name: CancelRegistration :execrows
+UPDATE registrations
+SET status = 'cancelled',
+    cancelled_at = NOW()
+WHERE id = $1
+AND status <> 'cancelled'
 `
 
-func (q *Queries) GetAuthCredentialsByEmail(ctx context.Context, email string) (AuthCredential, error) {
-	row := q.db.QueryRow(ctx, getAuthCredentialsByEmail, email)
-	var i AuthCredential
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.PasswordHash,
-		&i.EmailVerifiedAt,
-		&i.LastLoginAt,
-		&i.FailedLoginAttempts,
-		&i.LockedUntil,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-	)
-	return i, err
+func (q *Queries) CancelRegistration(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelRegistration, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const getAuthCredentialsByUserID = `-- name: GetAuthCredentialsByUserID :one
-SELECT id, user_id, password_hash, email_verified_at, last_login_at, failed_login_attempts, locked_until, created_at, updated_at, deleted_at FROM auth_credentials
-WHERE user_id = $1
+const checkInRegistration = `-- name: CheckInRegistration :execrows
+UPDATE registrations
+SET checked_in_at = COALESCE(checked_in_at, $1)
+WHERE id = $2
+AND race_id = $3
+AND status = 'confirmed'
 AND deleted_at IS NULL
-LIMIT 1
 `
 
-func (q *Queries) GetAuthCredentialsByUserID(ctx context.Context, userID int64) (AuthCredential, error) {
-	row := q.db.QueryRow(ctx, getAuthCredentialsByUserID, userID)
-	var i AuthCredential
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.PasswordHash,
-		&i.EmailVerifiedAt,
-		&i.LastLoginAt,
-		&i.FailedLoginAttempts,
-		&i.LockedUntil,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-	)
-	return i, err
+type CheckInRegistrationParams struct {
+	CheckedInAt pgtype.Timestamptz
+	ID          int64
+	RaceID      int64
 }
 
-const getEvent = `-- name: GetEvent :one
-SELECT id, organisation_id, name, slug, created_at, updated_at, deleted_at from events
-WHERE slug = $1 LIMIT 1
+// Checking an entrant in again keeps the time they were first checked in.
+func (q *Queries) CheckInRegistration(ctx context.Context, arg CheckInRegistrationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, checkInRegistration, arg.CheckedInAt, arg.ID, arg.RaceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimDataExports = `-- name: ClaimDataExports :many
+WITH due AS (
+  SELECT id FROM data_exports
+  WHERE status = 'pending'
+  AND (lease_until IS NULL OR lease_until <= $1)
+  ORDER BY id
+  LIMIT $2
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE data_exports d
+SET lease_until = $3
+FROM due
+WHERE d.id = due.id
+RETURNING d.id, d.user_id, d.status, d.lease_until, d.storage_key, d.token_hash, d.expires_at, d.downloaded_at, d.completed_at, d.created_at
 `
 
-func (q *Queries) GetEvent(ctx context.Context, slug string) (Event, error) {
-	row := q.db.QueryRow(ctx, getEvent, slug)
-	var i Event
-	err := row.Scan(
-		&i.ID,
-		&i.OrganisationID,
-		&i.Name,
-		&i.Slug,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-	)
-	return i, err
+type ClaimDataExportsParams struct {
+	Now        pgtype.Timestamptz
+	MaxExports int32
+	LeaseUntil pgtype.Timestamptz
 }
 
-const getOrganisation = `-- name: GetOrganisation :one
-SELECT id, name, created_at, updated_at, deleted_at from organisations
-WHERE id = $1 LIMIT 1
+// Leases the pending requests nobody is building to the caller until
+// lease_until, oldest first, skipping any another server has locked.
+func (q *Queries) ClaimDataExports(ctx context.Context, arg ClaimDataExportsParams) ([]DataExport, error) {
+	rows, err := q.db.Query(ctx, claimDataExports, arg.Now, arg.MaxExports, arg.LeaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DataExport
+	for rows.Next() {
+		var i DataExport
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Status,
+			&i.LeaseUntil,
+			&i.StorageKey,
+			&i.TokenHash,
+			&i.ExpiresAt,
+			&i.DownloadedAt,
+			&i.CompletedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimRaceReminders = `-- name: ClaimRaceReminders :many
+UPDATE registrations reg
+SET reminder_sent_at = NOW()
+FROM races r, events e, users u
+WHERE r.id = reg.race_id
+AND e.id = r.event_id
+AND u.id = reg.user_id
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+AND reg.reminder_sent_at IS NULL
+AND r.starts_at > $1
+AND r.starts_at <= $2
+AND r.deleted_at IS NULL
+AND e.deleted_at IS NULL
+AND u.deleted_at IS NULL
+AND u.email_preference = 'marketing'
+RETURNING reg.id, reg.user_id, r.name AS race_name, r.starts_at,
+  e.name AS event_name, e.slug AS event_slug,
+  u.email, u.first_name
 `
 
-func (q *Queries) GetOrganisation(ctx context.Context, id int64) (Organisation, error) {
-	row := q.db.QueryRow(ctx, getOrganisation, id)
-	var i Organisation
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-	)
-	return i, err
+type ClaimRaceRemindersParams struct {
+	StartsAfter  pgtype.Timestamptz
+	StartsBefore pgtype.Timestamptz
 }
 
-const getUser = `-- name: GetUser :one
-SELECT id, email, first_name, last_name, phone, address_line1, address_line2, city, state, postal_code, country, role, created_at, updated_at, deleted_at from users
-WHERE id = $1 LIMIT 1
-`
+type ClaimRaceRemindersRow struct {
+	ID        int64
+	UserID    int64
+	RaceName  string
+	StartsAt  pgtype.Timestamptz
+	EventName string
+	EventSlug string
+	Email     string
+	FirstName string
+}
 
-func (q *Queries) GetUser(ctx context.Context, id int64) (User, error) {
-	row := q.db.QueryRow(ctx, getUser, id)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.FirstName,
+// Marks active registrations for races starting in the window as reminded
+// and returns them, skipping any already reminded and entrants who have
+// opted out. A registration is only ever claimed once, however many times
+// this runs.
+func (q *Queries) ClaimRaceReminders(ctx context.Context, arg ClaimRaceRemindersParams) ([]ClaimRaceRemindersRow, error) {
+	rows, err := q.db.Query(ctx, claimRaceReminders, arg.StartsAfter, arg.StartsBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimRaceRemindersRow
+	for rows.Next() {
+		var i ClaimRaceRemindersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RaceName,
+			&i.StartsAt,
+			&i.EventName,
+			&i.EventSlug,
+			&i.Email,
+			&i.FirstName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimRegistrationDigests = `-- name: ClaimRegistrationDigests :many
+UPDATE organisation_members om
+SET digest_sent_for = $1::date
+FROM organisations o, users u
+WHERE o.id = om.organisation_id
+AND u.id = om.user_id
+AND om.notification_preference = 'daily_digest'
+AND (om.digest_sent_for IS NULL OR om.digest_sent_for < $1::date)
+AND om.deleted_at IS NULL
+AND o.deleted_at IS NULL
+AND u.deleted_at IS NULL
+AND u.deactivated_at IS NULL
+RETURNING om.organisation_id, o.name AS organisation_name,
+  u.email, u.first_name
+`
+
+type ClaimRegistrationDigestsRow struct {
+	OrganisationID   int64
+	OrganisationName string
+	Email            string
+	FirstName        string
+}
+
+// Marks the digest for day as sent to every member who wants one and has
+// not had it, and returns them. A member is only ever claimed once for a
+// day, however many times this runs.
+func (q *Queries) ClaimRegistrationDigests(ctx context.Context, day pgtype.Date) ([]ClaimRegistrationDigestsRow, error) {
+	rows, err := q.db.Query(ctx, claimRegistrationDigests, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimRegistrationDigestsRow
+	for rows.Next() {
+		var i ClaimRegistrationDigestsRow
+		if err := rows.Scan(
+			&i.OrganisationID,
+			&i.OrganisationName,
+			&i.Email,
+			&i.FirstName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimWebhookDeliveries = `-- name: ClaimWebhookDeliveries :many
+WITH due AS (
+  SELECT id FROM webhook_deliveries
+  WHERE status = 'pending'
+  AND next_attempt_at <= $1
+  ORDER BY next_attempt_at, id
+  LIMIT $2
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_deliveries d
+SET next_attempt_at = $3
+FROM due, webhook_endpoints we
+WHERE d.id = due.id
+AND we.id = d.endpoint_id
+RETURNING d.id, d.event_type, d.payload, d.attempts, we.url, we.secret_sealed
+`
+
+type ClaimWebhookDeliveriesParams struct {
+	Now           pgtype.Timestamptz
+	MaxDeliveries int32
+	LeaseUntil    pgtype.Timestamptz
+}
+
+type ClaimWebhookDeliveriesRow struct {
+	ID           int64
+	EventType    string
+	Payload      []byte
+	Attempts     int32
+	Url          string
+	SecretSealed []byte
+}
+
+// Leases the pending deliveries that are due to the caller until
+// lease_until, oldest first, skipping any another server has locked, and
+// returns them with where to send them.
+func (q *Queries) ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimWebhookDeliveries, arg.Now, arg.MaxDeliveries, arg.LeaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.SecretSealed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clearDataExportStorageKey = `-- name: ClearDataExportStorageKey :exec
+UPDATE data_exports
+SET storage_key = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ClearDataExportStorageKey(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, clearDataExportStorageKey, id)
+	return err
+}
+
+const completeDataExport = `-- name: CompleteDataExport :execrows
+UPDATE data_exports
+SET status = 'ready', storage_key = $2, token_hash = $3, expires_at = $4,
+  completed_at = $5, lease_until = NULL
+WHERE id = $1
+AND status = 'pending'
+`
+
+type CompleteDataExportParams struct {
+	ID          int64
+	StorageKey  pgtype.Text
+	TokenHash   []byte
+	ExpiresAt   pgtype.Timestamptz
+	CompletedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CompleteDataExport(ctx context.Context, arg CompleteDataExportParams) (int64, error) {
+	result, err := q.db.Exec(ctx, completeDataExport,
+		arg.ID,
+		arg.StorageKey,
+		arg.TokenHash,
+		arg.ExpiresAt,
+		arg.CompletedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const consumeDataExport = `-- name: ConsumeDataExport :one
+UPDATE data_exports
+SET downloaded_at = $1
+WHERE token_hash = $2
+AND status = 'ready'
+AND downloaded_at IS NULL
+AND expires_at > $1
+RETURNING id, user_id, status, lease_until, storage_key, token_hash, expires_at, downloaded_at, completed_at, created_at
+`
+
+type ConsumeDataExportParams struct {
+	Now       pgtype.Timestamptz
+	TokenHash []byte
+}
+
+// Marks the export the token is for downloaded, provided it is ready, has
+// not been downloaded and has not expired.
+func (q *Queries) ConsumeDataExport(ctx context.Context, arg ConsumeDataExportParams) (DataExport, error) {
+	row := q.db.QueryRow(ctx, consumeDataExport, arg.Now, arg.TokenHash)
+	var i DataExport
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.LeaseUntil,
+		&i.StorageKey,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.DownloadedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const consumeEmailVerificationToken = `-- name: ConsumeEmailVerificationToken :one
+UPDATE email_verification_tokens
+SET used_at = NOW()
+WHERE token_hash = $1
+AND used_at IS NULL
+AND expires_at > NOW()
+RETURNING user_id
+`
+
+func (q *Queries) ConsumeEmailVerificationToken(ctx context.Context, tokenHash []byte) (int64, error) {
+	row := q.db.QueryRow(ctx, consumeEmailVerificationToken, tokenHash)
+	var user_id int64
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const countActiveUserSessions = `-- name: CountActiveUserSessions :one
+SELECT COUNT(*) FROM user_sessions
+WHERE user_id = $1
+AND revoked_at IS NULL
+AND expires_at > NOW()
+`
+
+func (q *Queries) CountActiveUserSessions(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveUserSessions, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countEventRegistrationsByPeriod = `-- name: CountEventRegistrationsByPeriod :many
+SELECT date_trunc($1::text,
+         LEAST(GREATEST(reg.created_at, $2::timestamptz), $3::timestamptz) AT TIME ZONE $4::text)::date AS period,
+       COUNT(*) AS registered
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+WHERE r.event_id = $5
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+GROUP BY period
+ORDER BY period
+`
+
+type CountEventRegistrationsByPeriodParams struct {
+	Granularity string
+	WindowFrom  pgtype.Timestamptz
+	WindowTo    pgtype.Timestamptz
+	Timezone    string
+	EventID     int64
+}
+
+type CountEventRegistrationsByPeriodRow struct {
+	Period     pgtype.Date
+	Registered int64
+}
+
+// Counts the event's registrations by the day or week, in the event's time
+// zone, they were made. Registrations outside the window count in its first
+// or last period, so the periods add up to the event's entrants.
+func (q *Queries) CountEventRegistrationsByPeriod(ctx context.Context, arg CountEventRegistrationsByPeriodParams) ([]CountEventRegistrationsByPeriodRow, error) {
+	rows, err := q.db.Query(ctx, countEventRegistrationsByPeriod,
+		arg.Granularity,
+		arg.WindowFrom,
+		arg.WindowTo,
+		arg.Timezone,
+		arg.EventID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountEventRegistrationsByPeriodRow
+	for rows.Next() {
+		var i CountEventRegistrationsByPeriodRow
+		if err := rows.Scan(&i.Period, &i.Registered); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countEvents = `-- name: CountEvents :one
+SELECT COUNT(*) from events
+WHERE deleted_at IS NULL
+AND status = 'published'
+`
+
+func (q *Queries) CountEvents(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countEvents)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRaceCustomFields = `-- name: CountRaceCustomFields :one
+SELECT COUNT(*) FROM race_custom_fields
+WHERE race_id = $1
+AND deleted_at IS NULL
+`
+
+func (q *Queries) CountRaceCustomFields(ctx context.Context, raceID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countRaceCustomFields, raceID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRegistrationsByEvent = `-- name: CountRegistrationsByEvent :many
+SELECT r.event_id, COUNT(*) AS registered
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+WHERE r.event_id = ANY($1::bigint[])
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+GROUP BY r.event_id
+`
+
+type CountRegistrationsByEventRow struct {
+	EventID    int64
+	Registered int64
+}
+
+func (q *Queries) CountRegistrationsByEvent(ctx context.Context, eventIds []int64) ([]CountRegistrationsByEventRow, error) {
+	rows, err := q.db.Query(ctx, countRegistrationsByEvent, eventIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRegistrationsByEventRow
+	for rows.Next() {
+		var i CountRegistrationsByEventRow
+		if err := rows.Scan(&i.EventID, &i.Registered); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countRegistrationsByRace = `-- name: CountRegistrationsByRace :one
+SELECT COUNT(*) FROM registrations
+WHERE race_id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL
+`
+
+func (q *Queries) CountRegistrationsByRace(ctx context.Context, raceID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countRegistrationsByRace, raceID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRegistrationsByRaceForEvent = `-- name: CountRegistrationsByRaceForEvent :many
+SELECT reg.race_id, COUNT(*) AS registered
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+WHERE r.event_id = $1
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+GROUP BY reg.race_id
+`
+
+type CountRegistrationsByRaceForEventRow struct {
+	RaceID     int64
+	Registered int64
+}
+
+func (q *Queries) CountRegistrationsByRaceForEvent(ctx context.Context, eventID int64) ([]CountRegistrationsByRaceForEventRow, error) {
+	rows, err := q.db.Query(ctx, countRegistrationsByRaceForEvent, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRegistrationsByRaceForEventRow
+	for rows.Next() {
+		var i CountRegistrationsByRaceForEventRow
+		if err := rows.Scan(&i.RaceID, &i.Registered); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countSitemapEvents = `-- name: CountSitemapEvents :one
+SELECT COUNT(*) from events e
+WHERE e.deleted_at IS NULL
+AND e.status = 'published'
+AND NOT EXISTS (
+  SELECT 1 FROM events later
+  WHERE later.series_id = e.series_id
+  AND later.year > e.year
+  AND later.status = 'published'
+  AND later.deleted_at IS NULL
+)
+`
+
+// Counts the published editions ListSitemapEvents lists.
+func (q *Queries) CountSitemapEvents(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countSitemapEvents)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTeamMembers = `-- name: CountTeamMembers :one
+SELECT COUNT(*) FROM registrations
+WHERE team_id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL
+`
+
+func (q *Queries) CountTeamMembers(ctx context.Context, teamID pgtype.Int8) (int64, error) {
+	row := q.db.QueryRow(ctx, countTeamMembers, teamID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUnfilledTeamPlaces = `-- name: CountUnfilledTeamPlaces :one
+SELECT COALESCE(SUM(GREATEST(t.size - (
+    SELECT COUNT(*) FROM registrations reg
+    WHERE reg.team_id = t.id
+    AND reg.status <> 'cancelled'
+    AND reg.deleted_at IS NULL
+  ), 0)), 0)::bigint AS unfilled
+FROM teams t
+WHERE t.race_id = $1
+AND t.released_at IS NULL
+AND t.deleted_at IS NULL
+`
+
+// Places teams in the race hold for members who have not yet joined.
+// Released teams hold none.
+func (q *Queries) CountUnfilledTeamPlaces(ctx context.Context, raceID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnfilledTeamPlaces, raceID)
+	var unfilled int64
+	err := row.Scan(&unfilled)
+	return unfilled, err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users u
+WHERE u.deleted_at IS NULL
+AND ($1::text = '' OR u.email ILIKE $1 OR u.first_name || ' ' || u.last_name ILIKE $1)
+AND ($2::user_role IS NULL OR u.role = $2)
+`
+
+type CountUsersParams struct {
+	Search string
+	Role   NullUserRole
+}
+
+// Counts the users ListUsers pages through.
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, arg.Search, arg.Role)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countWaveRegistrations = `-- name: CountWaveRegistrations :one
+SELECT COUNT(*) FROM registrations
+WHERE wave_id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL
+`
+
+func (q *Queries) CountWaveRegistrations(ctx context.Context, waveID pgtype.Int8) (int64, error) {
+	row := q.db.QueryRow(ctx, countWaveRegistrations, waveID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAPIToken = `-- name: CreateAPIToken :one
+INSERT INTO api_tokens (user_id, label, token_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, label, token_hash, scopes, last_used_at, expires_at, revoked_at, created_at
+`
+
+type CreateAPITokenParams struct {
+	UserID    int64
+	Label     string
+	TokenHash []byte
+	Scopes    []string
+	ExpiresAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error) {
+	row := q.db.QueryRow(ctx, createAPIToken,
+		arg.UserID,
+		arg.Label,
+		arg.TokenHash,
+		arg.Scopes,
+		arg.ExpiresAt,
+	)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Label,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.LastUsedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createAuditLogEntry = `-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log (table_name, record_id, action, user_id, changed_fields)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateAuditLogEntryParams struct {
+	TableName     string
+	RecordID      int64
+	Action        AuditAction
+	UserID        pgtype.Int8
+	ChangedFields []byte
+}
+
+func (q *Queries) CreateAuditLogEntry(ctx context.Context, arg CreateAuditLogEntryParams) error {
+	_, err := q.db.Exec(ctx, createAuditLogEntry,
+		arg.TableName,
+		arg.RecordID,
+		arg.Action,
+		arg.UserID,
+		arg.ChangedFields,
+	)
+	return err
+}
+
+const createAuthCredentials = `-- name: CreateAuthCredentials :one
+
+INSERT INTO auth_credentials (
+    user_id,
+    password_hash
+) VALUES ($1, $2)
+RETURNING id, user_id, password_hash, email_verified_at, last_login_at, failed_login_attempts, locked_until, created_at, updated_at, deleted_at
+`
+
+type CreateAuthCredentialsParams struct {
+	UserID       int64
+	PasswordHash string
+}
+
+// Auth Credentials Queries
+func (q *Queries) CreateAuthCredentials(ctx context.Context, arg CreateAuthCredentialsParams) (AuthCredential, error) {
+	row := q.db.QueryRow(ctx, createAuthCredentials, arg.UserID, arg.PasswordHash)
+	var i AuthCredential
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PasswordHash,
+		&i.EmailVerifiedAt,
+		&i.LastLoginAt,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const createDiscountCode = `-- name: CreateDiscountCode :one
+INSERT INTO discount_codes (event_id, race_id, code, percent_off, amount_off_units, max_uses, valid_from, valid_until)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, event_id, race_id, code, percent_off, amount_off_units, max_uses, uses, valid_from, valid_until, created_at, updated_at, deleted_at
+`
+
+type CreateDiscountCodeParams struct {
+	EventID        int64
+	RaceID         pgtype.Int8
+	Code           string
+	PercentOff     pgtype.Int4
+	AmountOffUnits pgtype.Int4
+	MaxUses        pgtype.Int4
+	ValidFrom      pgtype.Timestamptz
+	ValidUntil     pgtype.Timestamptz
+}
+
+func (q *Queries) CreateDiscountCode(ctx context.Context, arg CreateDiscountCodeParams) (DiscountCode, error) {
+	row := q.db.QueryRow(ctx, createDiscountCode,
+		arg.EventID,
+		arg.RaceID,
+		arg.Code,
+		arg.PercentOff,
+		arg.AmountOffUnits,
+		arg.MaxUses,
+		arg.ValidFrom,
+		arg.ValidUntil,
+	)
+	var i DiscountCode
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.RaceID,
+		&i.Code,
+		&i.PercentOff,
+		&i.AmountOffUnits,
+		&i.MaxUses,
+		&i.Uses,
+		&i.ValidFrom,
+		&i.ValidUntil,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const createEmailVerificationToken = `-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (
+    user_id,
+    token_hash,
+    expires_at
+) VALUES ($1, $2, $3)
+`
+
+type CreateEmailVerificationTokenParams struct {
+	UserID    int64
+	TokenHash []byte
+	ExpiresAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) error {
+	_, err := q.db.Exec(ctx, createEmailVerificationToken, arg.UserID, arg.TokenHash, arg.ExpiresAt)
+	return err
+}
+
+const createEvent = `-- name: CreateEvent :one
+INSERT INTO events (
+  organisation_id,
+  name,
+  slug,
+  year,
+  description,
+  location,
+  image_url,
+  timezone,
+  series_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, organisation_id, name, slug, year, description, location, image_url, created_at, updated_at, deleted_at, status, timezone, series_id
+`
+
+type CreateEventParams struct {
+	OrganisationID int64
+	Name           string
+	Slug           string
+	Year           int32
+	Description    string
+	Location       string
+	ImageUrl       string
+	Timezone       string
+	SeriesID       pgtype.Int8
+}
+
+func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event, error) {
+	row := q.db.QueryRow(ctx, createEvent,
+		arg.OrganisationID,
+		arg.Name,
+		arg.Slug,
+		arg.Year,
+		arg.Description,
+		arg.Location,
+		arg.ImageUrl,
+		arg.Timezone,
+		arg.SeriesID,
+	)
+	var i Event
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Name,
+		&i.Slug,
+		&i.Year,
+		&i.Description,
+		&i.Location,
+		&i.ImageUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Status,
+		&i.Timezone,
+		&i.SeriesID,
+	)
+	return i, err
+}
+
+const createEventEnquiry = `-- name: CreateEventEnquiry :one
+INSERT INTO event_enquiries (event_id, name, email, message)
+VALUES ($1, $2, $3, $4)
+RETURNING id, event_id, name, email, message, created_at
+`
+
+type CreateEventEnquiryParams struct {
+	EventID int64
+	Name    string
+	Email   string
+	Message string
+}
+
+func (q *Queries) CreateEventEnquiry(ctx context.Context, arg CreateEventEnquiryParams) (EventEnquiry, error) {
+	row := q.db.QueryRow(ctx, createEventEnquiry,
+		arg.EventID,
+		arg.Name,
+		arg.Email,
+		arg.Message,
+	)
+	var i EventEnquiry
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Name,
+		&i.Email,
+		&i.Message,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createEventPhoto = `-- name: CreateEventPhoto :one
+INSERT INTO event_photos (event_id, position, original_key, web_key, width, height)
+VALUES (
+  $1,
+  (SELECT COALESCE(MAX(position), 0) + 1 FROM event_photos WHERE event_id = $1),
+  $2, $3, $4, $5
+)
+RETURNING id, event_id, position, original_key, web_key, width, height, created_at
+`
+
+type CreateEventPhotoParams struct {
+	EventID     int64
+	OriginalKey string
+	WebKey      string
+	Width       int32
+	Height      int32
+}
+
+// Adds the photo after the event's others.
+func (q *Queries) CreateEventPhoto(ctx context.Context, arg CreateEventPhotoParams) (EventPhoto, error) {
+	row := q.db.QueryRow(ctx, createEventPhoto,
+		arg.EventID,
+		arg.OriginalKey,
+		arg.WebKey,
+		arg.Width,
+		arg.Height,
+	)
+	var i EventPhoto
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Position,
+		&i.OriginalKey,
+		&i.WebKey,
+		&i.Width,
+		&i.Height,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createImportedRegistration = `-- name: CreateImportedRegistration :one
+INSERT INTO registrations (user_id, race_id, status, source, bib)
+VALUES ($1, $2, 'confirmed', 'imported', $3)
+RETURNING id, user_id, race_id, status, source, bib, cancelled_at, created_at, updated_at, deleted_at, reminder_sent_at, team_id, price_units, discount_code_id, discount_units, wave_id, checked_in_at, price_tier_id
+`
+
+type CreateImportedRegistrationParams struct {
+	UserID int64
+	RaceID int64
+	Bib    pgtype.Text
+}
+
+func (q *Queries) CreateImportedRegistration(ctx context.Context, arg CreateImportedRegistrationParams) (Registration, error) {
+	row := q.db.QueryRow(ctx, createImportedRegistration, arg.UserID, arg.RaceID, arg.Bib)
+	var i Registration
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RaceID,
+		&i.Status,
+		&i.Source,
+		&i.Bib,
+		&i.CancelledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ReminderSentAt,
+		&i.TeamID,
+		&i.PriceUnits,
+		&i.DiscountCodeID,
+		&i.DiscountUnits,
+		&i.WaveID,
+		&i.CheckedInAt,
+		&i.PriceTierID,
+	)
+	return i, err
+}
+
+const createLoginEvent = `-- name: CreateLoginEvent :exec
+INSERT INTO login_events (user_id, kind, ip_address, user_agent, alerted, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateLoginEventParams struct {
+	UserID    int64
+	Kind      LoginEventKind
+	IpAddress string
+	UserAgent string
+	Alerted   bool
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateLoginEvent(ctx context.Context, arg CreateLoginEventParams) error {
+	_, err := q.db.Exec(ctx, createLoginEvent,
+		arg.UserID,
+		arg.Kind,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.Alerted,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createOrganisation = `-- name: CreateOrganisation :one
+INSERT INTO organisations (
+  name)
+VALUES ($1)
+RETURNING id, name, created_at, updated_at, deleted_at, contact_email, brand_colour, logo_key, banner_key, embed_public, embed_origins
+`
+
+func (q *Queries) CreateOrganisation(ctx context.Context, name string) (Organisation, error) {
+	row := q.db.QueryRow(ctx, createOrganisation, name)
+	var i Organisation
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ContactEmail,
+		&i.BrandColour,
+		&i.LogoKey,
+		&i.BannerKey,
+		&i.EmbedPublic,
+		&i.EmbedOrigins,
+	)
+	return i, err
+}
+
+const createOrganisationInvitation = `-- name: CreateOrganisationInvitation :one
+INSERT INTO organisation_invitations (organisation_id, user_id, role, invited_by)
+VALUES ($1, $2, $3, $4)
+RETURNING id, organisation_id, user_id, role, invited_by, accepted_at, created_at
+`
+
+type CreateOrganisationInvitationParams struct {
+	OrganisationID int64
+	UserID         int64
+	Role           OrganisationRole
+	InvitedBy      pgtype.Int8
+}
+
+func (q *Queries) CreateOrganisationInvitation(ctx context.Context, arg CreateOrganisationInvitationParams) (OrganisationInvitation, error) {
+	row := q.db.QueryRow(ctx, createOrganisationInvitation,
+		arg.OrganisationID,
+		arg.UserID,
+		arg.Role,
+		arg.InvitedBy,
+	)
+	var i OrganisationInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.UserID,
+		&i.Role,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRace = `-- name: CreateRace :one
+INSERT INTO races (
+  event_id,
+  name,
+  slug,
+  registration_open_date,
+  registration_close_date,
+  starts_at,
+  max_capacity,
+  price_units,
+  currency,
+  distance_metres,
+  elevation_gain_metres)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, event_id, name, slug, registration_open_date, registration_close_date, starts_at, max_capacity, price_units, currency, created_at, updated_at, deleted_at, min_age, distance_metres, elevation_gain_metres
+`
+
+type CreateRaceParams struct {
+	EventID               int64
+	Name                  string
+	Slug                  string
+	RegistrationOpenDate  pgtype.Timestamptz
+	RegistrationCloseDate pgtype.Timestamptz
+	StartsAt              pgtype.Timestamptz
+	MaxCapacity           int32
+	PriceUnits            pgtype.Int4
+	Currency              pgtype.Text
+	DistanceMetres        pgtype.Int4
+	ElevationGainMetres   pgtype.Int4
+}
+
+func (q *Queries) CreateRace(ctx context.Context, arg CreateRaceParams) (Race, error) {
+	row := q.db.QueryRow(ctx, createRace,
+		arg.EventID,
+		arg.Name,
+		arg.Slug,
+		arg.RegistrationOpenDate,
+		arg.RegistrationCloseDate,
+		arg.StartsAt,
+		arg.MaxCapacity,
+		arg.PriceUnits,
+		arg.Currency,
+		arg.DistanceMetres,
+		arg.ElevationGainMetres,
+	)
+	var i Race
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Name,
+		&i.Slug,
+		&i.RegistrationOpenDate,
+		&i.RegistrationCloseDate,
+		&i.StartsAt,
+		&i.MaxCapacity,
+		&i.PriceUnits,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.MinAge,
+		&i.DistanceMetres,
+		&i.ElevationGainMetres,
+	)
+	return i, err
+}
+
+const createRaceCustomField = `-- name: CreateRaceCustomField :one
+INSERT INTO race_custom_fields (race_id, position)
+VALUES (
+  $1,
+  (SELECT COALESCE(MAX(position), 0) + 1 FROM race_custom_fields WHERE race_id = $1)
+)
+RETURNING id, race_id, position, version, created_at, deleted_at
+`
+
+// Adds the field after the race's others, at its first version.
+func (q *Queries) CreateRaceCustomField(ctx context.Context, raceID int64) (RaceCustomField, error) {
+	row := q.db.QueryRow(ctx, createRaceCustomField, raceID)
+	var i RaceCustomField
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.Position,
+		&i.Version,
+		&i.CreatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const createRaceCustomFieldVersion = `-- name: CreateRaceCustomFieldVersion :one
+INSERT INTO race_custom_field_versions (field_id, version, label, kind, options, required)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING field_id, version, label, kind, options, required, created_at
+`
+
+type CreateRaceCustomFieldVersionParams struct {
+	FieldID  int64
+	Version  int32
+	Label    string
+	Kind     CustomFieldKind
+	Options  []string
+	Required bool
+}
+
+func (q *Queries) CreateRaceCustomFieldVersion(ctx context.Context, arg CreateRaceCustomFieldVersionParams) (RaceCustomFieldVersion, error) {
+	row := q.db.QueryRow(ctx, createRaceCustomFieldVersion,
+		arg.FieldID,
+		arg.Version,
+		arg.Label,
+		arg.Kind,
+		arg.Options,
+		arg.Required,
+	)
+	var i RaceCustomFieldVersion
+	err := row.Scan(
+		&i.FieldID,
+		&i.Version,
+		&i.Label,
+		&i.Kind,
+		&i.Options,
+		&i.Required,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRacePriceTier = `-- name: CreateRacePriceTier :one
+INSERT INTO race_price_tiers (race_id, name, price_units, valid_from, valid_to, capacity)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, race_id, name, price_units, valid_from, valid_to, capacity, created_at, updated_at
+`
+
+type CreateRacePriceTierParams struct {
+	RaceID     int64
+	Name       string
+	PriceUnits int32
+	ValidFrom  pgtype.Timestamptz
+	ValidTo    pgtype.Timestamptz
+	Capacity   pgtype.Int4
+}
+
+func (q *Queries) CreateRacePriceTier(ctx context.Context, arg CreateRacePriceTierParams) (RacePriceTier, error) {
+	row := q.db.QueryRow(ctx, createRacePriceTier,
+		arg.RaceID,
+		arg.Name,
+		arg.PriceUnits,
+		arg.ValidFrom,
+		arg.ValidTo,
+		arg.Capacity,
+	)
+	var i RacePriceTier
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.Name,
+		&i.PriceUnits,
+		&i.ValidFrom,
+		&i.ValidTo,
+		&i.Capacity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createRaceResult = `-- name: CreateRaceResult :exec
+INSERT INTO race_results (race_id, registration_id, name, bib, position, finish_seconds, category)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateRaceResultParams struct {
+	RaceID         int64
+	RegistrationID pgtype.Int8
+	Name           string
+	Bib            string
+	Position       int32
+	FinishSeconds  int32
+	Category       string
+}
+
+func (q *Queries) CreateRaceResult(ctx context.Context, arg CreateRaceResultParams) error {
+	_, err := q.db.Exec(ctx, createRaceResult,
+		arg.RaceID,
+		arg.RegistrationID,
+		arg.Name,
+		arg.Bib,
+		arg.Position,
+		arg.FinishSeconds,
+		arg.Category,
+	)
+	return err
+}
+
+const createRaceWave = `-- name: CreateRaceWave :one
+INSERT INTO race_waves (race_id, name, starts_at, capacity)
+VALUES ($1, $2, $3, $4)
+RETURNING id, race_id, name, starts_at, capacity, created_at, updated_at
+`
+
+type CreateRaceWaveParams struct {
+	RaceID   int64
+	Name     string
+	StartsAt pgtype.Timestamptz
+	Capacity int32
+}
+
+func (q *Queries) CreateRaceWave(ctx context.Context, arg CreateRaceWaveParams) (RaceWave, error) {
+	row := q.db.QueryRow(ctx, createRaceWave,
+		arg.RaceID,
+		arg.Name,
+		arg.StartsAt,
+		arg.Capacity,
+	)
+	var i RaceWave
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.Name,
+		&i.StartsAt,
+		&i.Capacity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createRegistration = `-- name: CreateRegistration :one
+INSERT INTO registrations (user_id, race_id, price_units, discount_code_id, discount_units, wave_id, price_tier_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, race_id, status, source, bib, cancelled_at, created_at, updated_at, deleted_at, reminder_sent_at, team_id, price_units, discount_code_id, discount_units, wave_id, checked_in_at, price_tier_id
+`
+
+type CreateRegistrationParams struct {
+	UserID         int64
+	RaceID         int64
+	PriceUnits     pgtype.Int4
+	DiscountCodeID pgtype.Int8
+	DiscountUnits  int32
+	WaveID         pgtype.Int8
+	PriceTierID    pgtype.Int8
+}
+
+// Online entries start pending until paid for, recording the fee charged
+// after any discount and the price tier it was charged at.
+func (q *Queries) CreateRegistration(ctx context.Context, arg CreateRegistrationParams) (Registration, error) {
+	row := q.db.QueryRow(ctx, createRegistration,
+		arg.UserID,
+		arg.RaceID,
+		arg.PriceUnits,
+		arg.DiscountCodeID,
+		arg.DiscountUnits,
+		arg.WaveID,
+		arg.PriceTierID,
+	)
+	var i Registration
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RaceID,
+		&i.Status,
+		&i.Source,
+		&i.Bib,
+		&i.CancelledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ReminderSentAt,
+		&i.TeamID,
+		&i.PriceUnits,
+		&i.DiscountCodeID,
+		&i.DiscountUnits,
+		&i.WaveID,
+		&i.CheckedInAt,
+		&i.PriceTierID,
+	)
+	return i, err
+}
+
+const createRegistrationAnswers = `-- name: CreateRegistrationAnswers :exec
+INSERT INTO registration_answers (registration_id, answers_sealed)
+VALUES ($1, $2)
+`
+
+type CreateRegistrationAnswersParams struct {
+	RegistrationID int64
+	AnswersSealed  []byte
+}
+
+func (q *Queries) CreateRegistrationAnswers(ctx context.Context, arg CreateRegistrationAnswersParams) error {
+	_, err := q.db.Exec(ctx, createRegistrationAnswers, arg.RegistrationID, arg.AnswersSealed)
+	return err
+}
+
+const createRegistrationCustomAnswers = `-- name: CreateRegistrationCustomAnswers :exec
+INSERT INTO registration_custom_answers (registration_id, answers)
+VALUES ($1, $2)
+`
+
+type CreateRegistrationCustomAnswersParams struct {
+	RegistrationID int64
+	Answers        []byte
+}
+
+func (q *Queries) CreateRegistrationCustomAnswers(ctx context.Context, arg CreateRegistrationCustomAnswersParams) error {
+	_, err := q.db.Exec(ctx, createRegistrationCustomAnswers, arg.RegistrationID, arg.Answers)
+	return err
+}
+
+const createRegistrationTransfer = `-- name: CreateRegistrationTransfer :one
+INSERT INTO registration_transfers (registration_id, from_user_id, to_user_id)
+VALUES ($1, $2, $3)
+RETURNING id, registration_id, from_user_id, to_user_id, accepted_at, created_at
+`
+
+type CreateRegistrationTransferParams struct {
+	RegistrationID int64
+	FromUserID     int64
+	ToUserID       int64
+}
+
+func (q *Queries) CreateRegistrationTransfer(ctx context.Context, arg CreateRegistrationTransferParams) (RegistrationTransfer, error) {
+	row := q.db.QueryRow(ctx, createRegistrationTransfer, arg.RegistrationID, arg.FromUserID, arg.ToUserID)
+	var i RegistrationTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.RegistrationID,
+		&i.FromUserID,
+		&i.ToUserID,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTeam = `-- name: CreateTeam :one
+INSERT INTO teams (race_id, captain_user_id, name, size, invite_code, fill_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, race_id, captain_user_id, name, size, invite_code, fill_by, released_at, created_at, updated_at, deleted_at
+`
+
+type CreateTeamParams struct {
+	RaceID        int64
+	CaptainUserID int64
+	Name          string
+	Size          int32
+	InviteCode    string
+	FillBy        pgtype.Timestamptz
+}
+
+func (q *Queries) CreateTeam(ctx context.Context, arg CreateTeamParams) (Team, error) {
+	row := q.db.QueryRow(ctx, createTeam,
+		arg.RaceID,
+		arg.CaptainUserID,
+		arg.Name,
+		arg.Size,
+		arg.InviteCode,
+		arg.FillBy,
+	)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.CaptainUserID,
+		&i.Name,
+		&i.Size,
+		&i.InviteCode,
+		&i.FillBy,
+		&i.ReleasedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const createTeamRegistration = `-- name: CreateTeamRegistration :one
+INSERT INTO registrations (user_id, race_id, team_id)
+VALUES ($1, $2, $3)
+RETURNING id, user_id, race_id, status, source, bib, cancelled_at, created_at, updated_at, deleted_at, reminder_sent_at, team_id, price_units, discount_code_id, discount_units, wave_id, checked_in_at, price_tier_id
+`
+
+type CreateTeamRegistrationParams struct {
+	UserID int64
+	RaceID int64
+	TeamID pgtype.Int8
+}
+
+// Team entries start pending until paid for, like other online entries.
+func (q *Queries) CreateTeamRegistration(ctx context.Context, arg CreateTeamRegistrationParams) (Registration, error) {
+	row := q.db.QueryRow(ctx, createTeamRegistration, arg.UserID, arg.RaceID, arg.TeamID)
+	var i Registration
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RaceID,
+		&i.Status,
+		&i.Source,
+		&i.Bib,
+		&i.CancelledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ReminderSentAt,
+		&i.TeamID,
+		&i.PriceUnits,
+		&i.DiscountCodeID,
+		&i.DiscountUnits,
+		&i.WaveID,
+		&i.CheckedInAt,
+		&i.PriceTierID,
+	)
+	return i, err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (
+  email,
+  first_name,
+  last_name,
+  phone,
+  address_line1,
+  address_line2,
+  city,
+  state,
+  postal_code,
+  country,
+  role,
+  date_of_birth)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, email, first_name, last_name, phone, address_line1, address_line2, city, state, postal_code, country, role, created_at, updated_at, deleted_at, anonymised_at, email_preference, deactivated_at, date_of_birth, distance_unit, session_version
+`
+
+type CreateUserParams struct {
+	Email        string
+	FirstName    string
+	LastName     string
+	Phone        pgtype.Text
+	AddressLine1 pgtype.Text
+	AddressLine2 pgtype.Text
+	City         pgtype.Text
+	State        pgtype.Text
+	PostalCode   pgtype.Text
+	Country      pgtype.Text
+	Role         UserRole
+	DateOfBirth  pgtype.Date
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, createUser,
+		arg.Email,
+		arg.FirstName,
+		arg.LastName,
+		arg.Phone,
+		arg.AddressLine1,
+		arg.AddressLine2,
+		arg.City,
+		arg.State,
+		arg.PostalCode,
+		arg.Country,
+		arg.Role,
+		arg.DateOfBirth,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FirstName,
+		&i.LastName,
+		&i.Phone,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.City,
+		&i.State,
+		&i.PostalCode,
+		&i.Country,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AnonymisedAt,
+		&i.EmailPreference,
+		&i.DeactivatedAt,
+		&i.DateOfBirth,
+		&i.DistanceUnit,
+		&i.SessionVersion,
+	)
+	return i, err
+}
+
+const createUserSession = `-- name: CreateUserSession :one
+INSERT INTO user_sessions (user_id, user_agent, ip_address, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, user_agent, ip_address, last_seen_at, expires_at, revoked_at, created_at, evicted_at
+`
+
+type CreateUserSessionParams struct {
+	UserID    int64
+	UserAgent string
+	IpAddress string
+	ExpiresAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateUserSession(ctx context.Context, arg CreateUserSessionParams) (UserSession, error) {
+	row := q.db.QueryRow(ctx, createUserSession,
+		arg.UserID,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.ExpiresAt,
+	)
+	var i UserSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.LastSeenAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+		&i.EvictedAt,
+	)
+	return i, err
+}
+
+const createWebhookDeliveryAttempt = `-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (delivery_id, attempted_at, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateWebhookDeliveryAttemptParams struct {
+	DeliveryID  int64
+	AttemptedAt pgtype.Timestamptz
+	StatusCode  pgtype.Int4
+	Error       string
+	DurationMs  int32
+}
+
+func (q *Queries) CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) error {
+	_, err := q.db.Exec(ctx, createWebhookDeliveryAttempt,
+		arg.DeliveryID,
+		arg.AttemptedAt,
+		arg.StatusCode,
+		arg.Error,
+		arg.DurationMs,
+	)
+	return err
+}
+
+const createWebhookEndpoint = `-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (organisation_id, url, secret_sealed, event_types)
+VALUES ($1, $2, $3, $4)
+RETURNING id, organisation_id, url, secret_sealed, event_types, created_at
+`
+
+type CreateWebhookEndpointParams struct {
+	OrganisationID int64
+	Url            string
+	SecretSealed   []byte
+	EventTypes     []string
+}
+
+func (q *Queries) CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error) {
+	row := q.db.QueryRow(ctx, createWebhookEndpoint,
+		arg.OrganisationID,
+		arg.Url,
+		arg.SecretSealed,
+		arg.EventTypes,
+	)
+	var i WebhookEndpoint
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Url,
+		&i.SecretSealed,
+		&i.EventTypes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deactivateUser = `-- name: DeactivateUser :one
+UPDATE users
+SET deactivated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+AND deactivated_at IS NULL
+RETURNING deactivated_at
+`
+
+func (q *Queries) DeactivateUser(ctx context.Context, id int64) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, deactivateUser, id)
+	var deactivated_at pgtype.Timestamptz
+	err := row.Scan(&deactivated_at)
+	return deactivated_at, err
+}
+
+const deleteEvent = `-- name: DeleteEvent :exec
+UPDATE events
+SET deleted_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) DeleteEvent(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteEvent, id)
+	return err
+}
+
+const deleteEventPhoto = `-- name: DeleteEventPhoto :exec
+DELETE FROM event_photos
+WHERE id = $1
+`
+
+func (q *Queries) DeleteEventPhoto(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteEventPhoto, id)
+	return err
+}
+
+const deleteLoginEventsBefore = `-- name: DeleteLoginEventsBefore :execrows
+DELETE FROM login_events
+WHERE created_at < $1
+`
+
+func (q *Queries) DeleteLoginEventsBefore(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteLoginEventsBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteOrganisation = `-- name: DeleteOrganisation :exec
+UPDATE organisations
+SET deleted_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) DeleteOrganisation(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteOrganisation, id)
+	return err
+}
+
+const deletePendingInvitationsForUser = `-- name: DeletePendingInvitationsForUser :exec
+DELETE FROM organisation_invitations
+WHERE user_id = $1
+AND accepted_at IS NULL
+`
+
+func (q *Queries) DeletePendingInvitationsForUser(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deletePendingInvitationsForUser, userID)
+	return err
+}
+
+const deleteRaceCustomField = `-- name: DeleteRaceCustomField :execrows
+UPDATE race_custom_fields
+SET deleted_at = NOW()
+WHERE id = $1
+AND race_id = $2
+AND deleted_at IS NULL
+`
+
+type DeleteRaceCustomFieldParams struct {
+	ID     int64
+	RaceID int64
+}
+
+func (q *Queries) DeleteRaceCustomField(ctx context.Context, arg DeleteRaceCustomFieldParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRaceCustomField, arg.ID, arg.RaceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRaceLockedQuestions = `-- name: DeleteRaceLockedQuestions :exec
+DELETE FROM race_locked_questions
+WHERE race_id = $1
+`
+
+func (q *Queries) DeleteRaceLockedQuestions(ctx context.Context, raceID int64) error {
+	_, err := q.db.Exec(ctx, deleteRaceLockedQuestions, raceID)
+	return err
+}
+
+const deleteRacePriceTier = `-- name: DeleteRacePriceTier :execrows
+DELETE FROM race_price_tiers
+WHERE id = $1
+AND race_id = $2
+`
+
+type DeleteRacePriceTierParams struct {
+	ID     int64
+	RaceID int64
+}
+
+func (q *Queries) DeleteRacePriceTier(ctx context.Context, arg DeleteRacePriceTierParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRacePriceTier, arg.ID, arg.RaceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRaceRequiredQuestions = `-- name: DeleteRaceRequiredQuestions :exec
+DELETE FROM race_required_questions
+WHERE race_id = $1
+`
+
+func (q *Queries) DeleteRaceRequiredQuestions(ctx context.Context, raceID int64) error {
+	_, err := q.db.Exec(ctx, deleteRaceRequiredQuestions, raceID)
+	return err
+}
+
+const deleteRaceResults = `-- name: DeleteRaceResults :exec
+DELETE FROM race_results
+WHERE race_id = $1
+`
+
+func (q *Queries) DeleteRaceResults(ctx context.Context, raceID int64) error {
+	_, err := q.db.Exec(ctx, deleteRaceResults, raceID)
+	return err
+}
+
+const deleteRaceWave = `-- name: DeleteRaceWave :execrows
+DELETE FROM race_waves
+WHERE id = $1
+AND race_id = $2
+`
+
+type DeleteRaceWaveParams struct {
+	ID     int64
+	RaceID int64
+}
+
+func (q *Queries) DeleteRaceWave(ctx context.Context, arg DeleteRaceWaveParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRaceWave, arg.ID, arg.RaceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRegistrationAnswers = `-- name: DeleteRegistrationAnswers :exec
+DELETE FROM registration_answers
+WHERE registration_id = $1
+`
+
+func (q *Queries) DeleteRegistrationAnswers(ctx context.Context, registrationID int64) error {
+	_, err := q.db.Exec(ctx, deleteRegistrationAnswers, registrationID)
+	return err
+}
+
+const deleteRegistrationCustomAnswers = `-- name: DeleteRegistrationCustomAnswers :exec
+DELETE FROM registration_custom_answers
+WHERE registration_id = $1
+`
+
+func (q *Queries) DeleteRegistrationCustomAnswers(ctx context.Context, registrationID int64) error {
+	_, err := q.db.Exec(ctx, deleteRegistrationCustomAnswers, registrationID)
+	return err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+UPDATE users
+SET deleted_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteUser, id)
+	return err
+}
+
+const deleteUserAPITokens = `-- name: DeleteUserAPITokens :exec
+DELETE FROM api_tokens
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserAPITokens(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserAPITokens, userID)
+	return err
+}
+
+const deleteUserCredentials = `-- name: DeleteUserCredentials :exec
+DELETE FROM auth_credentials
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserCredentials(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserCredentials, userID)
+	return err
+}
+
+const deleteUserLoginEvents = `-- name: DeleteUserLoginEvents :exec
+DELETE FROM login_events
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserLoginEvents(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserLoginEvents, userID)
+	return err
+}
+
+const deleteUserRegistrationAnswers = `-- name: DeleteUserRegistrationAnswers :exec
+DELETE FROM registration_answers
+WHERE registration_id IN (SELECT id FROM registrations WHERE user_id = $1)
+`
+
+func (q *Queries) DeleteUserRegistrationAnswers(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserRegistrationAnswers, userID)
+	return err
+}
+
+const deleteUserRegistrationCustomAnswers = `-- name: DeleteUserRegistrationCustomAnswers :exec
+DELETE FROM registration_custom_answers
+WHERE registration_id IN (SELECT id FROM registrations WHERE user_id = $1)
+`
+
+func (q *Queries) DeleteUserRegistrationCustomAnswers(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserRegistrationCustomAnswers, userID)
+	return err
+}
+
+const deleteUserSessions = `-- name: DeleteUserSessions :exec
+DELETE FROM user_sessions
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserSessions(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserSessions, userID)
+	return err
+}
+
+const deleteUserSocialAccounts = `-- name: DeleteUserSocialAccounts :exec
+DELETE FROM social_accounts
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserSocialAccounts(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserSocialAccounts, userID)
+	return err
+}
+
+const deleteUserVerificationTokens = `-- name: DeleteUserVerificationTokens :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserVerificationTokens(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteUserVerificationTokens, userID)
+	return err
+}
+
+const deleteWebhookEndpoint = `-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints
+WHERE id = $1
+AND organisation_id = $2
+`
+
+type DeleteWebhookEndpointParams struct {
+	ID             int64
+	OrganisationID int64
+}
+
+func (q *Queries) DeleteWebhookEndpoint(ctx context.Context, arg DeleteWebhookEndpointParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhookEndpoint, arg.ID, arg.OrganisationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enqueueWebhookDeliveries = `-- name: EnqueueWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries (endpoint_id, event_type, payload)
+SELECT we.id, $1::text, $2::json
+FROM webhook_endpoints we
+WHERE we.organisation_id = $3
+AND $1::text = ANY(we.event_types)
+`
+
+type EnqueueWebhookDeliveriesParams struct {
+	EventType      string
+	Payload        []byte
+	OrganisationID int64
+}
+
+// Queues the payload for every endpoint of the organisation subscribed to
+// the event type.
+func (q *Queries) EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, enqueueWebhookDeliveries, arg.EventType, arg.Payload, arg.OrganisationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const evictUserSessions = `-- name: EvictUserSessions :execrows
+UPDATE user_sessions
+SET revoked_at = NOW(),
+    evicted_at = NOW()
+WHERE id IN (
+    SELECT id FROM user_sessions
+    WHERE user_id = $1
+    AND revoked_at IS NULL
+    AND expires_at > NOW()
+    ORDER BY last_seen_at DESC, id DESC
+    OFFSET $2
+)
+`
+
+type EvictUserSessionsParams struct {
+	UserID int64
+	Keep   int64
+}
+
+// Revokes, as evicted, the user's sessions still in use but the keep most
+// recently active.
+func (q *Queries) EvictUserSessions(ctx context.Context, arg EvictUserSessionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, evictUserSessions, arg.UserID, arg.Keep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const expirePendingRegistrations = `-- name: ExpirePendingRegistrations :execrows
+UPDATE registrations
+SET status = 'cancelled',
+    cancelled_at = NOW()
+WHERE status = 'pending'
+AND created_at < $1
+AND deleted_at IS NULL
+`
+
+// Online entries are pending until paid for, holding a place in the race.
+func (q *Queries) ExpirePendingRegistrations(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, expirePendingRegistrations, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const expireUserDataExports = `-- name: ExpireUserDataExports :exec
+UPDATE data_exports
+SET status = CASE WHEN status = 'pending' THEN 'failed' ELSE status END,
+  token_hash = NULL,
+  expires_at = NOW(),
+  lease_until = NULL
+WHERE user_id = $1
+`
+
+// Ends the user's exports: pending ones fail, and ready ones expire so
+// their archives are removed.
+func (q *Queries) ExpireUserDataExports(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, expireUserDataExports, userID)
+	return err
+}
+
+const exportUserAuditEntries = `-- name: ExportUserAuditEntries :many
+SELECT id, table_name, record_id, action, user_id, changed_fields, created_at FROM audit_log
+WHERE user_id = $1::bigint
+AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ExportUserAuditEntriesParams struct {
+	UserID  int64
+	AfterID int64
+	MaxRows int32
+}
+
+// Pages through the audit entries for changes the user made.
+func (q *Queries) ExportUserAuditEntries(ctx context.Context, arg ExportUserAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, exportUserAuditEntries, arg.UserID, arg.AfterID, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TableName,
+			&i.RecordID,
+			&i.Action,
+			&i.UserID,
+			&i.ChangedFields,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserPayments = `-- name: ExportUserPayments :many
+SELECT p.id, p.registration_id, p.amount_units, p.currency, p.status,
+  p.provider_reference, p.refunded_units, p.refunded_at, p.created_at, p.updated_at,
+  p.fee_units
+FROM payments p
+INNER JOIN registrations reg ON reg.id = p.registration_id
+WHERE reg.user_id = $1
+AND p.id > $2
+ORDER BY p.id
+LIMIT $3
+`
+
+type ExportUserPaymentsParams struct {
+	UserID  int64
+	AfterID int64
+	MaxRows int32
+}
+
+func (q *Queries) ExportUserPayments(ctx context.Context, arg ExportUserPaymentsParams) ([]Payment, error) {
+	rows, err := q.db.Query(ctx, exportUserPayments, arg.UserID, arg.AfterID, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payment
+	for rows.Next() {
+		var i Payment
+		if err := rows.Scan(
+			&i.ID,
+			&i.RegistrationID,
+			&i.AmountUnits,
+			&i.Currency,
+			&i.Status,
+			&i.ProviderReference,
+			&i.RefundedUnits,
+			&i.RefundedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FeeUnits,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserRegistrationAnswers = `-- name: ExportUserRegistrationAnswers :many
+SELECT ra.registration_id, ra.answers_sealed, ra.created_at,
+  r.name AS race_name, e.name AS event_name, e.year AS event_year
+FROM registration_answers ra
+INNER JOIN registrations reg ON reg.id = ra.registration_id
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE reg.user_id = $1
+AND ra.registration_id > $2
+ORDER BY ra.registration_id
+LIMIT $3
+`
+
+type ExportUserRegistrationAnswersParams struct {
+	UserID  int64
+	AfterID int64
+	MaxRows int32
+}
+
+type ExportUserRegistrationAnswersRow struct {
+	RegistrationID int64
+	AnswersSealed  []byte
+	CreatedAt      pgtype.Timestamptz
+	RaceName       string
+	EventName      string
+	EventYear      int32
+}
+
+func (q *Queries) ExportUserRegistrationAnswers(ctx context.Context, arg ExportUserRegistrationAnswersParams) ([]ExportUserRegistrationAnswersRow, error) {
+	rows, err := q.db.Query(ctx, exportUserRegistrationAnswers, arg.UserID, arg.AfterID, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUserRegistrationAnswersRow
+	for rows.Next() {
+		var i ExportUserRegistrationAnswersRow
+		if err := rows.Scan(
+			&i.RegistrationID,
+			&i.AnswersSealed,
+			&i.CreatedAt,
+			&i.RaceName,
+			&i.EventName,
+			&i.EventYear,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserRegistrations = `-- name: ExportUserRegistrations :many
+SELECT reg.id, reg.status, reg.source, reg.bib, reg.price_units, reg.discount_units,
+  reg.cancelled_at, reg.checked_in_at, reg.created_at,
+  r.name AS race_name, e.name AS event_name, e.year AS event_year
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE reg.user_id = $1
+AND reg.id > $2
+ORDER BY reg.id
+LIMIT $3
+`
+
+type ExportUserRegistrationsParams struct {
+	UserID  int64
+	AfterID int64
+	MaxRows int32
+}
+
+type ExportUserRegistrationsRow struct {
+	ID            int64
+	Status        RegistrationStatus
+	Source        RegistrationSource
+	Bib           pgtype.Text
+	PriceUnits    pgtype.Int4
+	DiscountUnits int32
+	CancelledAt   pgtype.Timestamptz
+	CheckedInAt   pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+	RaceName      string
+	EventName     string
+	EventYear     int32
+}
+
+// Pages through the user's registrations by id, cancelled ones included,
+// for a copy of their data.
+func (q *Queries) ExportUserRegistrations(ctx context.Context, arg ExportUserRegistrationsParams) ([]ExportUserRegistrationsRow, error) {
+	rows, err := q.db.Query(ctx, exportUserRegistrations, arg.UserID, arg.AfterID, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUserRegistrationsRow
+	for rows.Next() {
+		var i ExportUserRegistrationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Source,
+			&i.Bib,
+			&i.PriceUnits,
+			&i.DiscountUnits,
+			&i.CancelledAt,
+			&i.CheckedInAt,
+			&i.CreatedAt,
+			&i.RaceName,
+			&i.EventName,
+			&i.EventYear,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserSessions = `-- name: ExportUserSessions :many
+SELECT id, user_id, user_agent, ip_address, last_seen_at, expires_at, revoked_at, created_at, evicted_at FROM user_sessions
+WHERE user_id = $1
+AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ExportUserSessionsParams struct {
+	UserID  int64
+	AfterID int64
+	MaxRows int32
+}
+
+// Pages through every session the user has signed in to, revoked and
+// expired ones included.
+func (q *Queries) ExportUserSessions(ctx context.Context, arg ExportUserSessionsParams) ([]UserSession, error) {
+	rows, err := q.db.Query(ctx, exportUserSessions, arg.UserID, arg.AfterID, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserSession
+	for rows.Next() {
+		var i UserSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.LastSeenAt,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+			&i.EvictedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const failDataExport = `-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', completed_at = $2, lease_until = NULL
+WHERE id = $1
+AND status = 'pending'
+`
+
+type FailDataExportParams struct {
+	ID          int64
+	CompletedAt pgtype.Timestamptz
+}
+
+func (q *Queries) FailDataExport(ctx context.Context, arg FailDataExportParams) error {
+	_, err := q.db.Exec(ctx, failDataExport, arg.ID, arg.CompletedAt)
+	return err
+}
+
+const getAPITokenByHash = `-- name: GetAPITokenByHash :one
+SELECT id, user_id, label, token_hash, scopes, last_used_at, expires_at, revoked_at, created_at from api_tokens
+WHERE token_hash = $1
+LIMIT 1
+`
+
+func (q *Queries) GetAPITokenByHash(ctx context.Context, tokenHash []byte) (ApiToken, error) {
+	row := q.db.QueryRow(ctx, getAPITokenByHash, tokenHash)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Label,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.LastUsedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getActiveRegistration = `-- name: GetActiveRegistration :one
+SELECT id, user_id, race_id, status, source, bib, cancelled_at, created_at, updated_at, deleted_at, reminder_sent_at, team_id, price_units, discount_code_id, discount_units, wave_id, checked_in_at, price_tier_id from registrations
+WHERE user_id = $1
+AND race_id = $2
+AND status <> 'cancelled'
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+type GetActiveRegistrationParams struct {
+	UserID int64
+	RaceID int64
+}
+
+func (q *Queries) GetActiveRegistration(ctx context.Context, arg GetActiveRegistrationParams) (Registration, error) {
+	row := q.db.QueryRow(ctx, getActiveRegistration, arg.UserID, arg.RaceID)
+	var i Registration
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RaceID,
+		&i.Status,
+		&i.Source,
+		&i.Bib,
+		&i.CancelledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ReminderSentAt,
+		&i.TeamID,
+		&i.PriceUnits,
+		&i.DiscountCodeID,
+		&i.DiscountUnits,
+		&i.WaveID,
+		&i.CheckedInAt,
+		&i.PriceTierID,
+	)
+	return i, err
+}
+
+const getAuthCredentialsByEmail = `-- name: GetAuthCredentialsByEmail :one
+SELECT ac.id, ac.user_id, ac.password_hash, ac.email_verified_at, ac.last_login_at, ac.failed_login_attempts, ac.locked_until, ac.created_at, ac.updated_at, ac.deleted_at FROM auth_credentials ac
+INNER JOIN users u ON ac.user_id = u.id
+WHERE u.email = $1
+AND ac.deleted_at IS NULL
+AND u.deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetAuthCredentialsByEmail(ctx context.Context, email string) (AuthCredential, error) {
+	row := q.db.QueryRow(ctx, getAuthCredentialsByEmail, email)
+	var i AuthCredential
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PasswordHash,
+		&i.EmailVerifiedAt,
+		&i.LastLoginAt,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getAuthCredentialsByUserID = `-- name: GetAuthCredentialsByUserID :one
+SELECT id, user_id, password_hash, email_verified_at, last_login_at, failed_login_attempts, locked_until, created_at, updated_at, deleted_at FROM auth_credentials
+WHERE user_id = $1
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetAuthCredentialsByUserID(ctx context.Context, userID int64) (AuthCredential, error) {
+	row := q.db.QueryRow(ctx, getAuthCredentialsByUserID, userID)
+	var i AuthCredential
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PasswordHash,
+		&i.EmailVerifiedAt,
+		&i.LastLoginAt,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getEntrantAgeCheck = `-- name: GetEntrantAgeCheck :one
+SELECT u.date_of_birth,
+  (r.starts_at AT TIME ZONE e.timezone)::date AS race_day
+FROM users u
+CROSS JOIN races r
+INNER JOIN events e ON e.id = r.event_id
+WHERE u.id = $1
+AND r.id = $2
+`
+
+type GetEntrantAgeCheckParams struct {
+	UserID int64
+	RaceID int64
+}
+
+type GetEntrantAgeCheckRow struct {
+	DateOfBirth pgtype.Date
+	RaceDay     pgtype.Date
+}
+
+// The entrant's date of birth and the race's date in its event's time
+// zone, to check the entrant is old enough to enter.
+func (q *Queries) GetEntrantAgeCheck(ctx context.Context, arg GetEntrantAgeCheckParams) (GetEntrantAgeCheckRow, error) {
+	row := q.db.QueryRow(ctx, getEntrantAgeCheck, arg.UserID, arg.RaceID)
+	var i GetEntrantAgeCheckRow
+	err := row.Scan(&i.DateOfBirth, &i.RaceDay)
+	return i, err
+}
+
+const getEvent = `-- name: GetEvent :one
+SELECT id, organisation_id, name, slug, year, description, location, image_url, created_at, updated_at, deleted_at, status, timezone, series_id from events
+WHERE year = $1
+AND slug = $2
+AND status = 'published'
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+type GetEventParams struct {
+	Year int32
+	Slug string
+}
+
+// Public pages only ever show published events; organisers find drafts
+// and archived events by id.
+func (q *Queries) GetEvent(ctx context.Context, arg GetEventParams) (Event, error) {
+	row := q.db.QueryRow(ctx, getEvent, arg.Year, arg.Slug)
+	var i Event
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Name,
+		&i.Slug,
+		&i.Year,
+		&i.Description,
+		&i.Location,
+		&i.ImageUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Status,
+		&i.Timezone,
+		&i.SeriesID,
+	)
+	return i, err
+}
+
+const getEventByID = `-- name: GetEventByID :one
+SELECT id, organisation_id, name, slug, year, description, location, image_url, created_at, updated_at, deleted_at, status, timezone, series_id from events
+WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetEventByID(ctx context.Context, id int64) (Event, error) {
+	row := q.db.QueryRow(ctx, getEventByID, id)
+	var i Event
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Name,
+		&i.Slug,
+		&i.Year,
+		&i.Description,
+		&i.Location,
+		&i.ImageUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Status,
+		&i.Timezone,
+		&i.SeriesID,
+	)
+	return i, err
+}
+
+const getEventPhoto = `-- name: GetEventPhoto :one
+SELECT id, event_id, position, original_key, web_key, width, height, created_at FROM event_photos
+WHERE id = $1
+`
+
+func (q *Queries) GetEventPhoto(ctx context.Context, id int64) (EventPhoto, error) {
+	row := q.db.QueryRow(ctx, getEventPhoto, id)
+	var i EventPhoto
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Position,
+		&i.OriginalKey,
+		&i.WebKey,
+		&i.Width,
+		&i.Height,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getEventStats = `-- name: GetEventStats :many
+WITH race_stats AS (
+  SELECT r.event_id,
+    r.max_capacity,
+    COALESCE(r.currency, 'GBP')::text AS currency,
+    COUNT(reg.id) FILTER (WHERE reg.status <> 'cancelled') AS registrations,
+    COALESCE(SUM(COALESCE(reg.price_units, r.price_units, 0)) FILTER (WHERE reg.status = 'confirmed' AND reg.source = 'online'), 0) AS revenue_units,
+    COUNT(reg.id) FILTER (WHERE reg.status <> 'cancelled' AND reg.created_at >= NOW() - INTERVAL '7 days') AS recent
+  FROM races r
+  LEFT JOIN registrations reg ON reg.race_id = r.id AND reg.deleted_at IS NULL
+  WHERE r.deleted_at IS NULL
+  GROUP BY r.id
+),
+revenue AS (
+  SELECT event_id, currency, SUM(revenue_units)::bigint AS units
+  FROM race_stats
+  GROUP BY event_id, currency
+)
+SELECT e.id, e.name, e.slug, e.year, e.status,
+  COALESCE(SUM(rs.registrations), 0)::bigint AS registrations,
+  COALESCE(SUM(rs.max_capacity), 0)::bigint AS capacity,
+  COALESCE(SUM(rs.recent), 0)::bigint AS recent_registrations,
+  COALESCE((SELECT ARRAY_AGG(rv.currency ORDER BY rv.currency) FROM revenue rv WHERE rv.event_id = e.id), '{}')::text[] AS revenue_currencies,
+  COALESCE((SELECT ARRAY_AGG(rv.units ORDER BY rv.currency) FROM revenue rv WHERE rv.event_id = e.id), '{}')::bigint[] AS revenue_units
+FROM events e
+LEFT JOIN race_stats rs ON rs.event_id = e.id
+WHERE e.organisation_id = $1
+AND e.deleted_at IS NULL
+GROUP BY e.id
+ORDER BY e.year DESC, e.name
+`
+
+type GetEventStatsRow struct {
+	ID                  int64
+	Name                string
+	Slug                string
+	Year                int32
+	Status              EventStatus
+	Registrations       int64
+	Capacity            int64
+	RecentRegistrations int64
+	RevenueCurrencies   []string
+	RevenueUnits        []int64
+}
+
+// Per-event dashboard statistics for an organisation. Revenue counts
+// confirmed online registrations at the fee they were charged, or the race
+// price for entries made before fees were recorded, and is grouped by
+// currency, so revenue_currencies[i] pairs with revenue_units[i].
+func (q *Queries) GetEventStats(ctx context.Context, organisationID int64) ([]GetEventStatsRow, error) {
+	rows, err := q.db.Query(ctx, getEventStats, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEventStatsRow
+	for rows.Next() {
+		var i GetEventStatsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Slug,
+			&i.Year,
+			&i.Status,
+			&i.Registrations,
+			&i.Capacity,
+			&i.RecentRegistrations,
+			&i.RevenueCurrencies,
+			&i.RevenueUnits,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLastUsedOrganisationID = `-- name: GetLastUsedOrganisationID :one
+SELECT om.organisation_id from organisation_members om
+INNER JOIN organisations o ON o.id = om.organisation_id
+WHERE om.user_id = $1
+AND om.last_used_at IS NOT NULL
+AND om.deleted_at IS NULL
+AND o.deleted_at IS NULL
+ORDER BY om.last_used_at DESC
+LIMIT 1
+`
+
+// The organisation the user most recently worked in, of those they are
+// still a member of.
+func (q *Queries) GetLastUsedOrganisationID(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getLastUsedOrganisationID, userID)
+	var organisation_id int64
+	err := row.Scan(&organisation_id)
+	return organisation_id, err
+}
+
+const getLatestDataExport = `-- name: GetLatestDataExport :one
+SELECT id, user_id, status, lease_until, storage_key, token_hash, expires_at, downloaded_at, completed_at, created_at FROM data_exports
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestDataExport(ctx context.Context, userID int64) (DataExport, error) {
+	row := q.db.QueryRow(ctx, getLatestDataExport, userID)
+	var i DataExport
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.LeaseUntil,
+		&i.StorageKey,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.DownloadedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOrganisation = `-- name: GetOrganisation :one
+SELECT id, name, created_at, updated_at, deleted_at, contact_email, brand_colour, logo_key, banner_key, embed_public, embed_origins from organisations
+WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetOrganisation(ctx context.Context, id int64) (Organisation, error) {
+	row := q.db.QueryRow(ctx, getOrganisation, id)
+	var i Organisation
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ContactEmail,
+		&i.BrandColour,
+		&i.LogoKey,
+		&i.BannerKey,
+		&i.EmbedPublic,
+		&i.EmbedOrigins,
+	)
+	return i, err
+}
+
+const getOrganisationMembership = `-- name: GetOrganisationMembership :one
+SELECT id, organisation_id, user_id, created_at, deleted_at, role, notification_preference, digest_sent_for, last_used_at from organisation_members
+WHERE user_id = $1
+AND organisation_id = $2
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+type GetOrganisationMembershipParams struct {
+	UserID         int64
+	OrganisationID int64
+}
+
+func (q *Queries) GetOrganisationMembership(ctx context.Context, arg GetOrganisationMembershipParams) (OrganisationMember, error) {
+	row := q.db.QueryRow(ctx, getOrganisationMembership, arg.UserID, arg.OrganisationID)
+	var i OrganisationMember
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.Role,
+		&i.NotificationPreference,
+		&i.DigestSentFor,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getRaceByID = `-- name: GetRaceByID :one
+SELECT id, event_id, name, slug, registration_open_date, registration_close_date, starts_at, max_capacity, price_units, currency, created_at, updated_at, deleted_at, min_age, distance_metres, elevation_gain_metres from races
+WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetRaceByID(ctx context.Context, id int64) (Race, error) {
+	row := q.db.QueryRow(ctx, getRaceByID, id)
+	var i Race
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Name,
+		&i.Slug,
+		&i.RegistrationOpenDate,
+		&i.RegistrationCloseDate,
+		&i.StartsAt,
+		&i.MaxCapacity,
+		&i.PriceUnits,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.MinAge,
+		&i.DistanceMetres,
+		&i.ElevationGainMetres,
+	)
+	return i, err
+}
+
+const getRaceBySlug = `-- name: GetRaceBySlug :one
+SELECT id, event_id, name, slug, registration_open_date, registration_close_date, starts_at, max_capacity, price_units, currency, created_at, updated_at, deleted_at, min_age, distance_metres, elevation_gain_metres from races
+WHERE event_id = $1
+AND slug = $2
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+type GetRaceBySlugParams struct {
+	EventID int64
+	Slug    string
+}
+
+func (q *Queries) GetRaceBySlug(ctx context.Context, arg GetRaceBySlugParams) (Race, error) {
+	row := q.db.QueryRow(ctx, getRaceBySlug, arg.EventID, arg.Slug)
+	var i Race
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Name,
+		&i.Slug,
+		&i.RegistrationOpenDate,
+		&i.RegistrationCloseDate,
+		&i.StartsAt,
+		&i.MaxCapacity,
+		&i.PriceUnits,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.MinAge,
+		&i.DistanceMetres,
+		&i.ElevationGainMetres,
+	)
+	return i, err
+}
+
+const getRaceEventStatus = `-- name: GetRaceEventStatus :one
+SELECT e.status from races r
+INNER JOIN events e ON e.id = r.event_id
+WHERE r.id = $1
+`
+
+func (q *Queries) GetRaceEventStatus(ctx context.Context, id int64) (EventStatus, error) {
+	row := q.db.QueryRow(ctx, getRaceEventStatus, id)
+	var status EventStatus
+	err := row.Scan(&status)
+	return status, err
+}
+
+const getRacePriceTierPlaces = `-- name: GetRacePriceTierPlaces :one
+SELECT t.capacity, COUNT(reg.id) AS taken
+FROM race_price_tiers t
+LEFT JOIN registrations reg ON reg.price_tier_id = t.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+WHERE t.id = $1
+AND t.race_id = $2
+GROUP BY t.id
+`
+
+type GetRacePriceTierPlacesParams struct {
+	ID     int64
+	RaceID int64
+}
+
+type GetRacePriceTierPlacesRow struct {
+	Capacity pgtype.Int4
+	Taken    int64
+}
+
+// The places a tier limited to its first entries has, and how many active
+// registrations were made at it. Run with the race locked.
+func (q *Queries) GetRacePriceTierPlaces(ctx context.Context, arg GetRacePriceTierPlacesParams) (GetRacePriceTierPlacesRow, error) {
+	row := q.db.QueryRow(ctx, getRacePriceTierPlaces, arg.ID, arg.RaceID)
+	var i GetRacePriceTierPlacesRow
+	err := row.Scan(&i.Capacity, &i.Taken)
+	return i, err
+}
+
+const getRaceRoute = `-- name: GetRaceRoute :one
+SELECT race_id, point_count, distance_metres, elevation_gain_metres, updated_at
+FROM race_routes
+WHERE race_id = $1
+`
+
+type GetRaceRouteRow struct {
+	RaceID              int64
+	PointCount          int32
+	DistanceMetres      int32
+	ElevationGainMetres int32
+	UpdatedAt           pgtype.Timestamptz
+}
+
+func (q *Queries) GetRaceRoute(ctx context.Context, raceID int64) (GetRaceRouteRow, error) {
+	row := q.db.QueryRow(ctx, getRaceRoute, raceID)
+	var i GetRaceRouteRow
+	err := row.Scan(
+		&i.RaceID,
+		&i.PointCount,
+		&i.DistanceMetres,
+		&i.ElevationGainMetres,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRaceRouteGPX = `-- name: GetRaceRouteGPX :one
+SELECT gpx_gzip FROM race_routes
+WHERE race_id = $1
+`
+
+func (q *Queries) GetRaceRouteGPX(ctx context.Context, raceID int64) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getRaceRouteGPX, raceID)
+	var gpx_gzip []byte
+	err := row.Scan(&gpx_gzip)
+	return gpx_gzip, err
+}
+
+const getRaceWave = `-- name: GetRaceWave :one
+SELECT id, race_id, name, starts_at, capacity, created_at, updated_at FROM race_waves
+WHERE id = $1
+AND race_id = $2
+`
+
+type GetRaceWaveParams struct {
+	ID     int64
+	RaceID int64
+}
+
+func (q *Queries) GetRaceWave(ctx context.Context, arg GetRaceWaveParams) (RaceWave, error) {
+	row := q.db.QueryRow(ctx, getRaceWave, arg.ID, arg.RaceID)
+	var i RaceWave
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.Name,
+		&i.StartsAt,
+		&i.Capacity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRegistrationCheckIn = `-- name: GetRegistrationCheckIn :one
+SELECT checked_in_at
+FROM registrations
+WHERE id = $1
+AND race_id = $2
+AND deleted_at IS NULL
+`
+
+type GetRegistrationCheckInParams struct {
+	ID     int64
+	RaceID int64
+}
+
+func (q *Queries) GetRegistrationCheckIn(ctx context.Context, arg GetRegistrationCheckInParams) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, getRegistrationCheckIn, arg.ID, arg.RaceID)
+	var checked_in_at pgtype.Timestamptz
+	err := row.Scan(&checked_in_at)
+	return checked_in_at, err
+}
+
+const getRegistrationDigestStats = `-- name: GetRegistrationDigestStats :many
+SELECT e.id AS event_id, e.name AS event_name, e.year,
+  COALESCE(r.currency, 'GBP')::text AS currency,
+  COUNT(reg.id) FILTER (WHERE reg.status <> 'cancelled') AS registrations,
+  COALESCE(SUM(COALESCE(reg.price_units, r.price_units, 0)) FILTER (WHERE reg.status = 'confirmed' AND reg.source = 'online'), 0)::bigint AS revenue_units
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE e.organisation_id = $1
+AND reg.created_at >= $2
+AND reg.created_at < $3
+AND reg.deleted_at IS NULL
+AND r.deleted_at IS NULL
+AND e.deleted_at IS NULL
+GROUP BY e.id, COALESCE(r.currency, 'GBP')
+ORDER BY e.name, e.id, currency
+`
+
+type GetRegistrationDigestStatsParams struct {
+	OrganisationID int64
+	CreatedAfter   pgtype.Timestamptz
+	CreatedBefore  pgtype.Timestamptz
+}
+
+type GetRegistrationDigestStatsRow struct {
+	EventID       int64
+	EventName     string
+	Year          int32
+	Currency      string
+	Registrations int64
+	RevenueUnits  int64
+}
+
+// Registrations made in [created_after, created_before) for each of the
+// organisation's events that had any, by currency. Revenue counts
+// confirmed online registrations as GetEventStats does.
+func (q *Queries) GetRegistrationDigestStats(ctx context.Context, arg GetRegistrationDigestStatsParams) ([]GetRegistrationDigestStatsRow, error) {
+	rows, err := q.db.Query(ctx, getRegistrationDigestStats, arg.OrganisationID, arg.CreatedAfter, arg.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRegistrationDigestStatsRow
+	for rows.Next() {
+		var i GetRegistrationDigestStatsRow
+		if err := rows.Scan(
+			&i.EventID,
+			&i.EventName,
+			&i.Year,
+			&i.Currency,
+			&i.Registrations,
+			&i.RevenueUnits,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRegistrationForCancellation = `-- name: GetRegistrationForCancellation :one
+SELECT reg.id, reg.user_id, reg.race_id, reg.status, r.event_id, r.registration_close_date, e.organisation_id
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL
+LIMIT 1
+`
+
+type GetRegistrationForCancellationRow struct {
+	ID                    int64
+	UserID                int64
+	RaceID                int64
+	Status                RegistrationStatus
+	EventID               int64
+	RegistrationCloseDate pgtype.Timestamptz
+	OrganisationID        int64
+}
+
+func (q *Queries) GetRegistrationForCancellation(ctx context.Context, id int64) (GetRegistrationForCancellationRow, error) {
+	row := q.db.QueryRow(ctx, getRegistrationForCancellation, id)
+	var i GetRegistrationForCancellationRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RaceID,
+		&i.Status,
+		&i.EventID,
+		&i.RegistrationCloseDate,
+		&i.OrganisationID,
+	)
+	return i, err
+}
+
+const getRegistrationForConfirmation = `-- name: GetRegistrationForConfirmation :one
+SELECT reg.id, reg.race_id, r.name AS race_name, r.starts_at,
+  e.name AS event_name, e.organisation_id,
+  u.email, u.first_name, u.last_name,
+  w.name AS wave_name, w.starts_at AS wave_starts_at
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+INNER JOIN users u ON u.id = reg.user_id
+LEFT JOIN race_waves w ON w.id = reg.wave_id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL
+LIMIT 1
+`
+
+type GetRegistrationForConfirmationRow struct {
+	ID             int64
+	RaceID         int64
+	RaceName       string
+	StartsAt       pgtype.Timestamptz
+	EventName      string
+	OrganisationID int64
+	Email          string
+	FirstName      string
+	LastName       string
+	WaveName       pgtype.Text
+	WaveStartsAt   pgtype.Timestamptz
+}
+
+func (q *Queries) GetRegistrationForConfirmation(ctx context.Context, id int64) (GetRegistrationForConfirmationRow, error) {
+	row := q.db.QueryRow(ctx, getRegistrationForConfirmation, id)
+	var i GetRegistrationForConfirmationRow
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.RaceName,
+		&i.StartsAt,
+		&i.EventName,
+		&i.OrganisationID,
+		&i.Email,
+		&i.FirstName,
+		&i.LastName,
+		&i.WaveName,
+		&i.WaveStartsAt,
+	)
+	return i, err
+}
+
+const getRegistrationForEdit = `-- name: GetRegistrationForEdit :one
+SELECT reg.id, reg.user_id, reg.race_id, reg.status,
+  r.name AS race_name, r.starts_at,
+  e.name AS event_name,
+  ra.answers_sealed
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+LEFT JOIN registration_answers ra ON ra.registration_id = reg.id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL
+LIMIT 1
+`
+
+type GetRegistrationForEditRow struct {
+	ID            int64
+	UserID        int64
+	RaceID        int64
+	Status        RegistrationStatus
+	RaceName      string
+	StartsAt      pgtype.Timestamptz
+	EventName     string
+	AnswersSealed []byte
+}
+
+func (q *Queries) GetRegistrationForEdit(ctx context.Context, id int64) (GetRegistrationForEditRow, error) {
+	row := q.db.QueryRow(ctx, getRegistrationForEdit, id)
+	var i GetRegistrationForEditRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RaceID,
+		&i.Status,
+		&i.RaceName,
+		&i.StartsAt,
+		&i.EventName,
+		&i.AnswersSealed,
+	)
+	return i, err
+}
+
+const getRegistrationForTransfer = `-- name: GetRegistrationForTransfer :one
+SELECT reg.id, reg.user_id, reg.race_id, reg.status,
+  r.name AS race_name, r.registration_close_date,
+  e.name AS event_name,
+  u.first_name AS owner_first_name, u.last_name AS owner_last_name, u.email AS owner_email
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+INNER JOIN users u ON u.id = reg.user_id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL
+LIMIT 1
+`
+
+type GetRegistrationForTransferRow struct {
+	ID                    int64
+	UserID                int64
+	RaceID                int64
+	Status                RegistrationStatus
+	RaceName              string
+	RegistrationCloseDate pgtype.Timestamptz
+	EventName             string
+	OwnerFirstName        string
+	OwnerLastName         string
+	OwnerEmail            string
+}
+
+func (q *Queries) GetRegistrationForTransfer(ctx context.Context, id int64) (GetRegistrationForTransferRow, error) {
+	row := q.db.QueryRow(ctx, getRegistrationForTransfer, id)
+	var i GetRegistrationForTransferRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RaceID,
+		&i.Status,
+		&i.RaceName,
+		&i.RegistrationCloseDate,
+		&i.EventName,
+		&i.OwnerFirstName,
+		&i.OwnerLastName,
+		&i.OwnerEmail,
+	)
+	return i, err
+}
+
+const getRegistrationForWebhook = `-- name: GetRegistrationForWebhook :one
+SELECT reg.id, reg.status, reg.bib, reg.created_at,
+  reg.race_id, r.name AS race_name, r.event_id, e.name AS event_name, e.organisation_id,
+  u.email, u.first_name, u.last_name
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+INNER JOIN users u ON u.id = reg.user_id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL
+`
+
+type GetRegistrationForWebhookRow struct {
+	ID             int64
+	Status         RegistrationStatus
+	Bib            pgtype.Text
+	CreatedAt      pgtype.Timestamptz
+	RaceID         int64
+	RaceName       string
+	EventID        int64
+	EventName      string
+	OrganisationID int64
+	Email          string
+	FirstName      string
+	LastName       string
+}
+
+func (q *Queries) GetRegistrationForWebhook(ctx context.Context, id int64) (GetRegistrationForWebhookRow, error) {
+	row := q.db.QueryRow(ctx, getRegistrationForWebhook, id)
+	var i GetRegistrationForWebhookRow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Bib,
+		&i.CreatedAt,
+		&i.RaceID,
+		&i.RaceName,
+		&i.EventID,
+		&i.EventName,
+		&i.OrganisationID,
+		&i.Email,
+		&i.FirstName,
+		&i.LastName,
+	)
+	return i, err
+}
+
+const getSettledPaymentByRegistration = `-- name: GetSettledPaymentByRegistration :one
+SELECT id, registration_id, amount_units, currency, status, provider_reference, refunded_units, refunded_at, created_at, updated_at, fee_units from payments
+WHERE registration_id = $1
+AND status = 'succeeded'
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetSettledPaymentByRegistration(ctx context.Context, registrationID int64) (Payment, error) {
+	row := q.db.QueryRow(ctx, getSettledPaymentByRegistration, registrationID)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.RegistrationID,
+		&i.AmountUnits,
+		&i.Currency,
+		&i.Status,
+		&i.ProviderReference,
+		&i.RefundedUnits,
+		&i.RefundedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FeeUnits,
+	)
+	return i, err
+}
+
+const getSignInHistory = `-- name: GetSignInHistory :one
+SELECT
+    COUNT(*) > 0 AS signed_in_before,
+    COALESCE(BOOL_OR(
+        ip_address = $1
+        AND user_agent = $2
+        AND created_at >= $3
+    ), FALSE)::boolean AS seen_device
+FROM login_events
+WHERE user_id = $4
+AND kind = 'signed_in'
+`
+
+type GetSignInHistoryParams struct {
+	IpAddress string
+	UserAgent string
+	Since     pgtype.Timestamptz
+	UserID    int64
+}
+
+type GetSignInHistoryRow struct {
+	SignedInBefore bool
+	SeenDevice     bool
+}
+
+// Reports whether the user has ever signed in, as far as the events kept
+// go back, and whether they have signed in from the device since the given
+// time.
+func (q *Queries) GetSignInHistory(ctx context.Context, arg GetSignInHistoryParams) (GetSignInHistoryRow, error) {
+	row := q.db.QueryRow(ctx, getSignInHistory,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.Since,
+		arg.UserID,
+	)
+	var i GetSignInHistoryRow
+	err := row.Scan(&i.SignedInBefore, &i.SeenDevice)
+	return i, err
+}
+
+const getTeamByInviteCode = `-- name: GetTeamByInviteCode :one
+SELECT id, race_id, captain_user_id, name, size, invite_code, fill_by, released_at, created_at, updated_at, deleted_at from teams
+WHERE invite_code = $1
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetTeamByInviteCode(ctx context.Context, inviteCode string) (Team, error) {
+	row := q.db.QueryRow(ctx, getTeamByInviteCode, inviteCode)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.CaptainUserID,
+		&i.Name,
+		&i.Size,
+		&i.InviteCode,
+		&i.FillBy,
+		&i.ReleasedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, email, first_name, last_name, phone, address_line1, address_line2, city, state, postal_code, country, role, created_at, updated_at, deleted_at, anonymised_at, email_preference, deactivated_at, date_of_birth, distance_unit, session_version from users
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRow(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FirstName,
+		&i.LastName,
+		&i.Phone,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.City,
+		&i.State,
+		&i.PostalCode,
+		&i.Country,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AnonymisedAt,
+		&i.EmailPreference,
+		&i.DeactivatedAt,
+		&i.DateOfBirth,
+		&i.DistanceUnit,
+		&i.SessionVersion,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, email, first_name, last_name, phone, address_line1, address_line2, city, state, postal_code, country, role, created_at, updated_at, deleted_at, anonymised_at, email_preference, deactivated_at, date_of_birth, distance_unit, session_version FROM users
+WHERE email = $1
+AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FirstName,
 		&i.LastName,
 		&i.Phone,
 		&i.AddressLine1,
@@ -307,134 +3526,3030 @@ func (q *Queries) GetUser(ctx context.Context, id int64) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AnonymisedAt,
+		&i.EmailPreference,
+		&i.DeactivatedAt,
+		&i.DateOfBirth,
+		&i.DistanceUnit,
+		&i.SessionVersion,
+	)
+	return i, err
+}
+
+const getUserSession = `-- name: GetUserSession :one
+SELECT id, user_id, user_agent, ip_address, last_seen_at, expires_at, revoked_at, created_at, evicted_at from user_sessions
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetUserSession(ctx context.Context, id int64) (UserSession, error) {
+	row := q.db.QueryRow(ctx, getUserSession, id)
+	var i UserSession
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.LastSeenAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+		&i.EvictedAt,
+	)
+	return i, err
+}
+
+const getWebhookEndpoint = `-- name: GetWebhookEndpoint :one
+SELECT id, organisation_id, url, secret_sealed, event_types, created_at FROM webhook_endpoints
+WHERE id = $1
+AND organisation_id = $2
+`
+
+type GetWebhookEndpointParams struct {
+	ID             int64
+	OrganisationID int64
+}
+
+func (q *Queries) GetWebhookEndpoint(ctx context.Context, arg GetWebhookEndpointParams) (WebhookEndpoint, error) {
+	row := q.db.QueryRow(ctx, getWebhookEndpoint, arg.ID, arg.OrganisationID)
+	var i WebhookEndpoint
+	err := row.Scan(
+		&i.ID,
+		&i.OrganisationID,
+		&i.Url,
+		&i.SecretSealed,
+		&i.EventTypes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const hasActiveRegistration = `-- name: HasActiveRegistration :one
+SELECT EXISTS (
+  SELECT 1 from registrations
+  WHERE user_id = $1
+  AND race_id = $2
+  AND status <> 'cancelled'
+  AND deleted_at IS NULL
+)
+`
+
+type HasActiveRegistrationParams struct {
+	UserID int64
+	RaceID int64
+}
+
+func (q *Queries) HasActiveRegistration(ctx context.Context, arg HasActiveRegistrationParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasActiveRegistration, arg.UserID, arg.RaceID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const incrementFailedLoginAttempts = `-- name: IncrementFailedLoginAttempts :exec
+UPDATE auth_credentials
+SET failed_login_attempts = failed_login_attempts + 1
+WHERE user_id = $1
+`
+
+func (q *Queries) IncrementFailedLoginAttempts(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, incrementFailedLoginAttempts, userID)
+	return err
+}
+
+const isAccountLocked = `-- name: IsAccountLocked :one
+SELECT
+    CASE
+        WHEN locked_until IS NULL THEN false
+        WHEN locked_until > NOW() THEN true
+        ELSE false
+    END as is_locked
+FROM auth_credentials
+WHERE user_id = $1
+`
+
+func (q *Queries) IsAccountLocked(ctx context.Context, userID int64) (bool, error) {
+	row := q.db.QueryRow(ctx, isAccountLocked, userID)
+	var is_locked bool
+	err := row.Scan(&is_locked)
+	return is_locked, err
+}
+
+const isEmailVerified = `-- name: IsEmailVerified :one
+SELECT EXISTS (
+  SELECT 1 FROM auth_credentials
+  WHERE user_id = $1
+  AND email_verified_at IS NOT NULL
+  AND deleted_at IS NULL
+)
+`
+
+func (q *Queries) IsEmailVerified(ctx context.Context, userID int64) (bool, error) {
+	row := q.db.QueryRow(ctx, isEmailVerified, userID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const isOrganisationMember = `-- name: IsOrganisationMember :one
+SELECT EXISTS (
+  SELECT 1 from organisation_members
+  WHERE organisation_id = $1
+  AND user_id = $2
+  AND deleted_at IS NULL
+)
+`
+
+type IsOrganisationMemberParams struct {
+	OrganisationID int64
+	UserID         int64
+}
+
+func (q *Queries) IsOrganisationMember(ctx context.Context, arg IsOrganisationMemberParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isOrganisationMember, arg.OrganisationID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listAPITokensForUser = `-- name: ListAPITokensForUser :many
+SELECT id, user_id, label, token_hash, scopes, last_used_at, expires_at, revoked_at, created_at from api_tokens
+WHERE user_id = $1
+AND revoked_at IS NULL
+ORDER BY created_at DESC, id DESC
+`
+
+// Lists the tokens the user has not revoked, newest first.
+func (q *Queries) ListAPITokensForUser(ctx context.Context, userID int64) ([]ApiToken, error) {
+	rows, err := q.db.Query(ctx, listAPITokensForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiToken
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Label,
+			&i.TokenHash,
+			&i.Scopes,
+			&i.LastUsedAt,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogForRecord = `-- name: ListAuditLogForRecord :many
+SELECT id, table_name, record_id, action, user_id, changed_fields, created_at FROM audit_log
+WHERE table_name = $1
+AND record_id = $2
+ORDER BY created_at DESC, id DESC
+`
+
+type ListAuditLogForRecordParams struct {
+	TableName string
+	RecordID  int64
+}
+
+func (q *Queries) ListAuditLogForRecord(ctx context.Context, arg ListAuditLogForRecordParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditLogForRecord, arg.TableName, arg.RecordID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TableName,
+			&i.RecordID,
+			&i.Action,
+			&i.UserID,
+			&i.ChangedFields,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDiscountCodesByCode = `-- name: ListDiscountCodesByCode :many
+SELECT id, event_id, race_id, code, percent_off, amount_off_units, max_uses, uses, valid_from, valid_until, created_at, updated_at, deleted_at from discount_codes
+WHERE LOWER(code) = LOWER($1::text)
+AND deleted_at IS NULL
+ORDER BY id
+`
+
+// Lists the codes matching code, ignoring case, in every event.
+func (q *Queries) ListDiscountCodesByCode(ctx context.Context, code string) ([]DiscountCode, error) {
+	rows, err := q.db.Query(ctx, listDiscountCodesByCode, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DiscountCode
+	for rows.Next() {
+		var i DiscountCode
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.RaceID,
+			&i.Code,
+			&i.PercentOff,
+			&i.AmountOffUnits,
+			&i.MaxUses,
+			&i.Uses,
+			&i.ValidFrom,
+			&i.ValidUntil,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDiscountCodesByEvent = `-- name: ListDiscountCodesByEvent :many
+SELECT id, event_id, race_id, code, percent_off, amount_off_units, max_uses, uses, valid_from, valid_until, created_at, updated_at, deleted_at from discount_codes
+WHERE event_id = $1
+AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) ListDiscountCodesByEvent(ctx context.Context, eventID int64) ([]DiscountCode, error) {
+	rows, err := q.db.Query(ctx, listDiscountCodesByEvent, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DiscountCode
+	for rows.Next() {
+		var i DiscountCode
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.RaceID,
+			&i.Code,
+			&i.PercentOff,
+			&i.AmountOffUnits,
+			&i.MaxUses,
+			&i.Uses,
+			&i.ValidFrom,
+			&i.ValidUntil,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDistinctYears = `-- name: ListDistinctYears :many
+SELECT DISTINCT year FROM events
+WHERE deleted_at IS NULL
+AND status = 'published'
+ORDER BY year DESC
+`
+
+func (q *Queries) ListDistinctYears(ctx context.Context) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listDistinctYears)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var year int32
+		if err := rows.Scan(&year); err != nil {
+			return nil, err
+		}
+		items = append(items, year)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventEnquiries = `-- name: ListEventEnquiries :many
+SELECT id, event_id, name, email, message, created_at FROM event_enquiries
+WHERE event_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+`
+
+type ListEventEnquiriesParams struct {
+	EventID int64
+	Limit   int32
+}
+
+func (q *Queries) ListEventEnquiries(ctx context.Context, arg ListEventEnquiriesParams) ([]EventEnquiry, error) {
+	rows, err := q.db.Query(ctx, listEventEnquiries, arg.EventID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EventEnquiry
+	for rows.Next() {
+		var i EventEnquiry
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Name,
+			&i.Email,
+			&i.Message,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventPhotos = `-- name: ListEventPhotos :many
+SELECT id, event_id, position, original_key, web_key, width, height, created_at FROM event_photos
+WHERE event_id = $1
+ORDER BY position
+`
+
+func (q *Queries) ListEventPhotos(ctx context.Context, eventID int64) ([]EventPhoto, error) {
+	rows, err := q.db.Query(ctx, listEventPhotos, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EventPhoto
+	for rows.Next() {
+		var i EventPhoto
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Position,
+			&i.OriginalKey,
+			&i.WebKey,
+			&i.Width,
+			&i.Height,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEvents = `-- name: ListEvents :many
+SELECT id, organisation_id, name, slug, year, description, location, image_url, created_at, updated_at, deleted_at, status, timezone, series_id from events
+WHERE status = 'published'
+ORDER BY name
+`
+
+func (q *Queries) ListEvents(ctx context.Context) ([]Event, error) {
+	rows, err := q.db.Query(ctx, listEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Name,
+			&i.Slug,
+			&i.Year,
+			&i.Description,
+			&i.Location,
+			&i.ImageUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Status,
+			&i.Timezone,
+			&i.SeriesID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsByYear = `-- name: ListEventsByYear :many
+SELECT e.id, e.organisation_id, e.name, e.slug, e.year, e.description, e.location, e.image_url, e.created_at, e.updated_at, e.deleted_at, e.status, e.timezone, e.series_id, COALESCE(p.past, false)::boolean AS past
+FROM events e
+LEFT JOIN LATERAL (
+  SELECT bool_and(COALESCE(r.registration_close_date < $1, false)) AS past
+  FROM races r
+  WHERE r.event_id = e.id
+  AND r.deleted_at IS NULL
+) p ON true
+WHERE e.year = $2
+AND e.deleted_at IS NULL
+AND e.status = 'published'
+AND ($3::boolean OR NOT COALESCE(p.past, false))
+ORDER BY e.name
+`
+
+type ListEventsByYearParams struct {
+	Now         pgtype.Timestamptz
+	Year        int32
+	IncludePast bool
+}
+
+type ListEventsByYearRow struct {
+	Event Event
+	Past  bool
+}
+
+// An event is past once registration has closed for all of its races.
+// Events without races, or with a race that never closes, are not past.
+func (q *Queries) ListEventsByYear(ctx context.Context, arg ListEventsByYearParams) ([]ListEventsByYearRow, error) {
+	rows, err := q.db.Query(ctx, listEventsByYear, arg.Now, arg.Year, arg.IncludePast)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventsByYearRow
+	for rows.Next() {
+		var i ListEventsByYearRow
+		if err := rows.Scan(
+			&i.Event.ID,
+			&i.Event.OrganisationID,
+			&i.Event.Name,
+			&i.Event.Slug,
+			&i.Event.Year,
+			&i.Event.Description,
+			&i.Event.Location,
+			&i.Event.ImageUrl,
+			&i.Event.CreatedAt,
+			&i.Event.UpdatedAt,
+			&i.Event.DeletedAt,
+			&i.Event.Status,
+			&i.Event.Timezone,
+			&i.Event.SeriesID,
+			&i.Past,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsInSeries = `-- name: ListEventsInSeries :many
+SELECT e.id, e.organisation_id, e.name, e.slug, e.year, e.description, e.location, e.image_url, e.created_at, e.updated_at, e.deleted_at, e.status, e.timezone, e.series_id,
+  COALESCE(res.names, '{}')::text[] AS result_race_names,
+  COALESCE(res.slugs, '{}')::text[] AS result_race_slugs
+FROM events e
+LEFT JOIN LATERAL (
+  SELECT ARRAY_AGG(r.name ORDER BY r.name) AS names,
+         ARRAY_AGG(r.slug ORDER BY r.name) AS slugs
+  FROM races r
+  WHERE r.event_id = e.id
+  AND r.deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM race_results rr WHERE rr.race_id = r.id AND rr.published)
+) res ON true
+WHERE e.series_id = $1
+AND e.status = 'published'
+AND e.deleted_at IS NULL
+ORDER BY e.year DESC
+`
+
+type ListEventsInSeriesRow struct {
+	Event           Event
+	ResultRaceNames []string
+	ResultRaceSlugs []string
+}
+
+// Lists a series' published editions, latest first, each with the names
+// and slugs of its races with published results, so
+// result_race_names[i] pairs with result_race_slugs[i].
+func (q *Queries) ListEventsInSeries(ctx context.Context, seriesID pgtype.Int8) ([]ListEventsInSeriesRow, error) {
+	rows, err := q.db.Query(ctx, listEventsInSeries, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventsInSeriesRow
+	for rows.Next() {
+		var i ListEventsInSeriesRow
+		if err := rows.Scan(
+			&i.Event.ID,
+			&i.Event.OrganisationID,
+			&i.Event.Name,
+			&i.Event.Slug,
+			&i.Event.Year,
+			&i.Event.Description,
+			&i.Event.Location,
+			&i.Event.ImageUrl,
+			&i.Event.CreatedAt,
+			&i.Event.UpdatedAt,
+			&i.Event.DeletedAt,
+			&i.Event.Status,
+			&i.Event.Timezone,
+			&i.Event.SeriesID,
+			&i.ResultRaceNames,
+			&i.ResultRaceSlugs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsPaginated = `-- name: ListEventsPaginated :many
+SELECT id, organisation_id, name, slug, year, description, location, image_url, created_at, updated_at, deleted_at, status, timezone, series_id from events
+WHERE deleted_at IS NULL
+AND status = 'published'
+ORDER BY year DESC, name
+LIMIT $1 OFFSET $2
+`
+
+type ListEventsPaginatedParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) ListEventsPaginated(ctx context.Context, arg ListEventsPaginatedParams) ([]Event, error) {
+	rows, err := q.db.Query(ctx, listEventsPaginated, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Name,
+			&i.Slug,
+			&i.Year,
+			&i.Description,
+			&i.Location,
+			&i.ImageUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Status,
+			&i.Timezone,
+			&i.SeriesID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsWithStats = `-- name: ListEventsWithStats :many
+SELECT e.id, e.organisation_id, e.name, e.slug, e.year, e.description, e.location, e.image_url, e.created_at, e.updated_at, e.deleted_at, e.status, e.timezone, e.series_id,
+       rs.race_count,
+       COALESCE(rs.total_capacity, 0)::bigint AS total_capacity,
+       rs.min_price_units::integer AS min_price_units,
+       rs.price_currency::text AS price_currency,
+       rs.registration_opens_at::timestamptz AS registration_opens_at,
+       rs.registration_closes_at::timestamptz AS registration_closes_at,
+       rs.races_updated_at::timestamptz AS races_updated_at,
+       rg.registered
+FROM events e
+LEFT JOIN LATERAL (
+  SELECT COUNT(*) AS race_count,
+         SUM(r.max_capacity) AS total_capacity,
+         MIN(COALESCE(tp.price_units, r.price_units, 0)) AS min_price_units,
+         (ARRAY_AGG(r.currency ORDER BY COALESCE(tp.price_units, r.price_units, 0), r.name))[1] AS price_currency,
+         MIN(r.registration_open_date) AS registration_opens_at,
+         CASE WHEN bool_and(r.registration_close_date IS NOT NULL)
+              THEN MAX(r.registration_close_date) END AS registration_closes_at,
+         MAX(r.updated_at) AS races_updated_at
+  FROM races r
+  -- A tier limited to its first entries takes precedence over one it
+  -- overlaps while it has places
+  LEFT JOIN LATERAL (
+    SELECT t.price_units
+    FROM race_price_tiers t
+    WHERE t.race_id = r.id
+    AND (t.valid_from IS NULL OR t.valid_from <= $1::timestamptz)
+    AND (t.valid_to IS NULL OR t.valid_to > $1::timestamptz)
+    AND (t.capacity IS NULL OR t.capacity > (
+      SELECT COUNT(*) FROM registrations reg
+      WHERE reg.price_tier_id = t.id
+      AND reg.status <> 'cancelled'
+      AND reg.deleted_at IS NULL
+    ))
+    ORDER BY t.capacity IS NULL, t.id
+    LIMIT 1
+  ) tp ON true
+  WHERE r.event_id = e.id
+  AND r.deleted_at IS NULL
+) rs ON true
+LEFT JOIN LATERAL (
+  SELECT COUNT(*) AS registered
+  FROM registrations reg
+  INNER JOIN races r ON r.id = reg.race_id
+  WHERE r.event_id = e.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+) rg ON true
+WHERE e.deleted_at IS NULL
+AND e.status = 'published'
+ORDER BY e.name
+LIMIT $2 OFFSET $3
+`
+
+type ListEventsWithStatsParams struct {
+	Now    pgtype.Timestamptz
+	Limit  int32
+	Offset int32
+}
+
+type ListEventsWithStatsRow struct {
+	Event                Event
+	RaceCount            int64
+	TotalCapacity        int64
+	MinPriceUnits        pgtype.Int4
+	PriceCurrency        pgtype.Text
+	RegistrationOpensAt  pgtype.Timestamptz
+	RegistrationClosesAt pgtype.Timestamptz
+	RacesUpdatedAt       pgtype.Timestamptz
+	Registered           int64
+}
+
+// Lists published events by name with totals across their live races and
+// their registration count, for listing cards, in one round trip. Events
+// without races have a race count and capacity of zero and no price or
+// registration dates. Races are priced at the tier current at @now, if
+// any, or else their own price; a race without a price is free, and the
+// cheapest race's currency is the price's. registration_closes_at is null
+// when any race takes entries without a close date.
+func (q *Queries) ListEventsWithStats(ctx context.Context, arg ListEventsWithStatsParams) ([]ListEventsWithStatsRow, error) {
+	rows, err := q.db.Query(ctx, listEventsWithStats, arg.Now, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventsWithStatsRow
+	for rows.Next() {
+		var i ListEventsWithStatsRow
+		if err := rows.Scan(
+			&i.Event.ID,
+			&i.Event.OrganisationID,
+			&i.Event.Name,
+			&i.Event.Slug,
+			&i.Event.Year,
+			&i.Event.Description,
+			&i.Event.Location,
+			&i.Event.ImageUrl,
+			&i.Event.CreatedAt,
+			&i.Event.UpdatedAt,
+			&i.Event.DeletedAt,
+			&i.Event.Status,
+			&i.Event.Timezone,
+			&i.Event.SeriesID,
+			&i.RaceCount,
+			&i.TotalCapacity,
+			&i.MinPriceUnits,
+			&i.PriceCurrency,
+			&i.RegistrationOpensAt,
+			&i.RegistrationClosesAt,
+			&i.RacesUpdatedAt,
+			&i.Registered,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImmediateNotificationRecipients = `-- name: ListImmediateNotificationRecipients :many
+SELECT u.email, u.first_name
+FROM organisation_members om
+INNER JOIN users u ON u.id = om.user_id
+WHERE om.organisation_id = $1
+AND om.notification_preference = 'immediate'
+AND om.deleted_at IS NULL
+AND u.deleted_at IS NULL
+AND u.deactivated_at IS NULL
+ORDER BY u.email
+`
+
+type ListImmediateNotificationRecipientsRow struct {
+	Email     string
+	FirstName string
+}
+
+// Members of the organisation who want an email for every registration.
+func (q *Queries) ListImmediateNotificationRecipients(ctx context.Context, organisationID int64) ([]ListImmediateNotificationRecipientsRow, error) {
+	rows, err := q.db.Query(ctx, listImmediateNotificationRecipients, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListImmediateNotificationRecipientsRow
+	for rows.Next() {
+		var i ListImmediateNotificationRecipientsRow
+		if err := rows.Scan(
+			&i.Email,
+			&i.FirstName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganisationEvents = `-- name: ListOrganisationEvents :many
+SELECT id, organisation_id, name, slug, year, description, location, image_url, created_at, updated_at, deleted_at, status, timezone, series_id from events
+WHERE organisation_id = $1
+AND deleted_at IS NULL
+ORDER BY name, year DESC
+`
+
+// Lists every event of the organisation, whatever its status, by name and
+// then latest year first.
+func (q *Queries) ListOrganisationEvents(ctx context.Context, organisationID int64) ([]Event, error) {
+	rows, err := q.db.Query(ctx, listOrganisationEvents, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Name,
+			&i.Slug,
+			&i.Year,
+			&i.Description,
+			&i.Location,
+			&i.ImageUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Status,
+			&i.Timezone,
+			&i.SeriesID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganisationMembers = `-- name: ListOrganisationMembers :many
+SELECT om.user_id, om.role, om.created_at,
+  u.email, u.first_name, u.last_name
+FROM organisation_members om
+INNER JOIN users u ON u.id = om.user_id
+WHERE om.organisation_id = $1
+AND om.deleted_at IS NULL
+AND u.deleted_at IS NULL
+ORDER BY om.role, u.last_name, u.first_name, u.email
+`
+
+type ListOrganisationMembersRow struct {
+	UserID    int64
+	Role      OrganisationRole
+	CreatedAt pgtype.Timestamptz
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+func (q *Queries) ListOrganisationMembers(ctx context.Context, organisationID int64) ([]ListOrganisationMembersRow, error) {
+	rows, err := q.db.Query(ctx, listOrganisationMembers, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrganisationMembersRow
+	for rows.Next() {
+		var i ListOrganisationMembersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Role,
+			&i.CreatedAt,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganisationPayments = `-- name: ListOrganisationPayments :many
+SELECT p.id, p.registration_id, p.amount_units, p.currency, p.status,
+  p.provider_reference, p.fee_units, p.refunded_units, p.created_at,
+  u.email, u.first_name, u.last_name, u.anonymised_at,
+  r.name AS race_name, e.name AS event_name
+FROM payments p
+INNER JOIN registrations reg ON reg.id = p.registration_id
+INNER JOIN users u ON u.id = reg.user_id
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE e.organisation_id = $1
+AND p.status IN ('succeeded', 'partially_refunded', 'refunded')
+AND p.created_at >= $2
+AND p.created_at < $3
+ORDER BY p.created_at, p.id
+`
+
+type ListOrganisationPaymentsParams struct {
+	OrganisationID int64
+	TakenFrom      pgtype.Timestamptz
+	TakenUntil     pgtype.Timestamptz
+}
+
+type ListOrganisationPaymentsRow struct {
+	ID                int64
+	RegistrationID    int64
+	AmountUnits       int32
+	Currency          string
+	Status            PaymentStatus
+	ProviderReference pgtype.Text
+	FeeUnits          pgtype.Int4
+	RefundedUnits     int32
+	CreatedAt         pgtype.Timestamptz
+	Email             string
+	FirstName         string
+	LastName          string
+	AnonymisedAt      pgtype.Timestamptz
+	RaceName          string
+	EventName         string
+}
+
+// Lists the payments taken for the organisation's events from
+// @taken_from up to @taken_until, oldest first, with who made them and for
+// which race, for reconciling against Stripe's payouts.
+func (q *Queries) ListOrganisationPayments(ctx context.Context, arg ListOrganisationPaymentsParams) ([]ListOrganisationPaymentsRow, error) {
+	rows, err := q.db.Query(ctx, listOrganisationPayments, arg.OrganisationID, arg.TakenFrom, arg.TakenUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrganisationPaymentsRow
+	for rows.Next() {
+		var i ListOrganisationPaymentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RegistrationID,
+			&i.AmountUnits,
+			&i.Currency,
+			&i.Status,
+			&i.ProviderReference,
+			&i.FeeUnits,
+			&i.RefundedUnits,
+			&i.CreatedAt,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+			&i.AnonymisedAt,
+			&i.RaceName,
+			&i.EventName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganisations = `-- name: ListOrganisations :many
+SELECT id, name, created_at, updated_at, deleted_at, contact_email, brand_colour, logo_key, banner_key, embed_public, embed_origins from organisations
+WHERE deleted_at IS NULL
+ORDER BY name
+`
+
+func (q *Queries) ListOrganisations(ctx context.Context) ([]Organisation, error) {
+	rows, err := q.db.Query(ctx, listOrganisations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Organisation
+	for rows.Next() {
+		var i Organisation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ContactEmail,
+			&i.BrandColour,
+			&i.LogoKey,
+			&i.BannerKey,
+			&i.EmbedPublic,
+			&i.EmbedOrigins,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganisationsForUser = `-- name: ListOrganisationsForUser :many
+SELECT o.id, o.name, o.created_at, o.updated_at, o.deleted_at, o.contact_email, o.brand_colour, o.logo_key, o.banner_key, o.embed_public, o.embed_origins from organisations o
+INNER JOIN organisation_members om ON om.organisation_id = o.id
+WHERE om.user_id = $1
+AND om.deleted_at IS NULL
+AND o.deleted_at IS NULL
+ORDER BY o.name
+`
+
+func (q *Queries) ListOrganisationsForUser(ctx context.Context, userID int64) ([]Organisation, error) {
+	rows, err := q.db.Query(ctx, listOrganisationsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Organisation
+	for rows.Next() {
+		var i Organisation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ContactEmail,
+			&i.BrandColour,
+			&i.LogoKey,
+			&i.BannerKey,
+			&i.EmbedPublic,
+			&i.EmbedOrigins,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingOrganisationInvitations = `-- name: ListPendingOrganisationInvitations :many
+SELECT i.id, i.user_id, i.role, i.created_at, u.email
+FROM organisation_invitations i
+INNER JOIN users u ON u.id = i.user_id
+WHERE i.organisation_id = $1
+AND i.accepted_at IS NULL
+ORDER BY i.created_at, i.id
+`
+
+type ListPendingOrganisationInvitationsRow struct {
+	ID        int64
+	UserID    int64
+	Role      OrganisationRole
+	CreatedAt pgtype.Timestamptz
+	Email     string
+}
+
+func (q *Queries) ListPendingOrganisationInvitations(ctx context.Context, organisationID int64) ([]ListPendingOrganisationInvitationsRow, error) {
+	rows, err := q.db.Query(ctx, listPendingOrganisationInvitations, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPendingOrganisationInvitationsRow
+	for rows.Next() {
+		var i ListPendingOrganisationInvitationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Role,
+			&i.CreatedAt,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPublishedEventsBySlug = `-- name: ListPublishedEventsBySlug :many
+SELECT id, organisation_id, name, slug, year, description, location, image_url, created_at, updated_at, deleted_at, status, timezone, series_id from events
+WHERE slug = $1
+AND status = 'published'
+AND deleted_at IS NULL
+ORDER BY year DESC
+`
+
+// Finds the editions an old slug-only event URL could mean, latest first.
+func (q *Queries) ListPublishedEventsBySlug(ctx context.Context, slug string) ([]Event, error) {
+	rows, err := q.db.Query(ctx, listPublishedEventsBySlug, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Name,
+			&i.Slug,
+			&i.Year,
+			&i.Description,
+			&i.Location,
+			&i.ImageUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Status,
+			&i.Timezone,
+			&i.SeriesID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceBibs = `-- name: ListRaceBibs :many
+SELECT id, status, bib from registrations
+WHERE race_id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL
+ORDER BY created_at, id
+`
+
+type ListRaceBibsRow struct {
+	ID     int64
+	Status RegistrationStatus
+	Bib    pgtype.Text
+}
+
+// Active registrations in the order bibs are handed out, with any bib they
+// already have.
+func (q *Queries) ListRaceBibs(ctx context.Context, raceID int64) ([]ListRaceBibsRow, error) {
+	rows, err := q.db.Query(ctx, listRaceBibs, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRaceBibsRow
+	for rows.Next() {
+		var i ListRaceBibsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Bib,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceCustomFieldVersions = `-- name: ListRaceCustomFieldVersions :many
+SELECT v.field_id, v.version, v.label, v.kind, v.options, v.required, v.created_at
+FROM race_custom_field_versions v
+INNER JOIN race_custom_fields f ON f.id = v.field_id
+WHERE f.race_id = $1
+ORDER BY v.field_id, v.version
+`
+
+// Every definition the race's custom fields have had, removed fields'
+// included, by field and then version.
+func (q *Queries) ListRaceCustomFieldVersions(ctx context.Context, raceID int64) ([]RaceCustomFieldVersion, error) {
+	rows, err := q.db.Query(ctx, listRaceCustomFieldVersions, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RaceCustomFieldVersion
+	for rows.Next() {
+		var i RaceCustomFieldVersion
+		if err := rows.Scan(
+			&i.FieldID,
+			&i.Version,
+			&i.Label,
+			&i.Kind,
+			&i.Options,
+			&i.Required,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceCustomFields = `-- name: ListRaceCustomFields :many
+SELECT v.field_id, v.version, v.label, v.kind, v.options, v.required, v.created_at
+FROM race_custom_fields f
+INNER JOIN race_custom_field_versions v ON v.field_id = f.id AND v.version = f.version
+WHERE f.race_id = $1
+AND f.deleted_at IS NULL
+ORDER BY f.position, f.id
+`
+
+// The current definitions of the race's custom fields that have not been
+// removed, in the order the entry form asks them.
+func (q *Queries) ListRaceCustomFields(ctx context.Context, raceID int64) ([]RaceCustomFieldVersion, error) {
+	rows, err := q.db.Query(ctx, listRaceCustomFields, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RaceCustomFieldVersion
+	for rows.Next() {
+		var i RaceCustomFieldVersion
+		if err := rows.Scan(
+			&i.FieldID,
+			&i.Version,
+			&i.Label,
+			&i.Kind,
+			&i.Options,
+			&i.Required,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceEntrants = `-- name: ListRaceEntrants :many
+SELECT reg.id, reg.status, reg.source, reg.bib, reg.created_at,
+  u.email, u.first_name, u.last_name, u.anonymised_at, u.date_of_birth,
+  t.name AS team_name,
+  (r.starts_at AT TIME ZONE e.timezone)::date AS race_day,
+  w.name AS wave_name, w.starts_at AS wave_starts_at,
+  reg.checked_in_at
+FROM registrations reg
+INNER JOIN users u ON u.id = reg.user_id
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+LEFT JOIN teams t ON t.id = reg.team_id
+LEFT JOIN race_waves w ON w.id = reg.wave_id
+WHERE reg.race_id = $1
+AND reg.deleted_at IS NULL
+ORDER BY reg.created_at, reg.id
+`
+
+type ListRaceEntrantsRow struct {
+	ID           int64
+	Status       RegistrationStatus
+	Source       RegistrationSource
+	Bib          pgtype.Text
+	CreatedAt    pgtype.Timestamptz
+	Email        string
+	FirstName    string
+	LastName     string
+	AnonymisedAt pgtype.Timestamptz
+	DateOfBirth  pgtype.Date
+	TeamName     pgtype.Text
+	RaceDay      pgtype.Date
+	WaveName     pgtype.Text
+	WaveStartsAt pgtype.Timestamptz
+	CheckedInAt  pgtype.Timestamptz
+}
+
+// Entrants show as registered, or as deleted once their account has been
+// anonymised.
+func (q *Queries) ListRaceEntrants(ctx context.Context, raceID int64) ([]ListRaceEntrantsRow, error) {
+	rows, err := q.db.Query(ctx, listRaceEntrants, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRaceEntrantsRow
+	for rows.Next() {
+		var i ListRaceEntrantsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Source,
+			&i.Bib,
+			&i.CreatedAt,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+			&i.AnonymisedAt,
+			&i.DateOfBirth,
+			&i.TeamName,
+			&i.RaceDay,
+			&i.WaveName,
+			&i.WaveStartsAt,
+			&i.CheckedInAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceLockedQuestions = `-- name: ListRaceLockedQuestions :many
+SELECT question FROM race_locked_questions
+WHERE race_id = $1
+ORDER BY question
+`
+
+func (q *Queries) ListRaceLockedQuestions(ctx context.Context, raceID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, listRaceLockedQuestions, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var question string
+		if err := rows.Scan(&question); err != nil {
+			return nil, err
+		}
+		items = append(items, question)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRacePriceTiers = `-- name: ListRacePriceTiers :many
+SELECT t.id, t.race_id, t.name, t.price_units, t.valid_from, t.valid_to, t.capacity, t.created_at, t.updated_at, COUNT(reg.id) AS taken
+FROM race_price_tiers t
+LEFT JOIN registrations reg ON reg.price_tier_id = t.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+WHERE t.race_id = ANY($1::bigint[])
+GROUP BY t.id
+ORDER BY t.race_id, t.valid_from NULLS FIRST, t.valid_to NULLS LAST, t.id
+`
+
+type ListRacePriceTiersRow struct {
+	RacePriceTier RacePriceTier
+	Taken         int64
+}
+
+// Price tiers of the races with the active registrations made at each, by
+// race and then earliest first.
+func (q *Queries) ListRacePriceTiers(ctx context.Context, raceIds []int64) ([]ListRacePriceTiersRow, error) {
+	rows, err := q.db.Query(ctx, listRacePriceTiers, raceIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRacePriceTiersRow
+	for rows.Next() {
+		var i ListRacePriceTiersRow
+		if err := rows.Scan(
+			&i.RacePriceTier.ID,
+			&i.RacePriceTier.RaceID,
+			&i.RacePriceTier.Name,
+			&i.RacePriceTier.PriceUnits,
+			&i.RacePriceTier.ValidFrom,
+			&i.RacePriceTier.ValidTo,
+			&i.RacePriceTier.Capacity,
+			&i.RacePriceTier.CreatedAt,
+			&i.RacePriceTier.UpdatedAt,
+			&i.Taken,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceRegistrationAnswers = `-- name: ListRaceRegistrationAnswers :many
+SELECT ra.registration_id, ra.answers_sealed
+FROM registration_answers ra
+INNER JOIN registrations reg ON reg.id = ra.registration_id
+WHERE reg.race_id = $1
+AND reg.deleted_at IS NULL
+ORDER BY ra.registration_id
+`
+
+type ListRaceRegistrationAnswersRow struct {
+	RegistrationID int64
+	AnswersSealed  []byte
+}
+
+func (q *Queries) ListRaceRegistrationAnswers(ctx context.Context, raceID int64) ([]ListRaceRegistrationAnswersRow, error) {
+	rows, err := q.db.Query(ctx, listRaceRegistrationAnswers, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRaceRegistrationAnswersRow
+	for rows.Next() {
+		var i ListRaceRegistrationAnswersRow
+		if err := rows.Scan(&i.RegistrationID, &i.AnswersSealed); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceRegistrationCustomAnswers = `-- name: ListRaceRegistrationCustomAnswers :many
+SELECT ca.registration_id, ca.answers
+FROM registration_custom_answers ca
+INNER JOIN registrations reg ON reg.id = ca.registration_id
+WHERE reg.race_id = $1
+AND reg.deleted_at IS NULL
+ORDER BY ca.registration_id
+`
+
+type ListRaceRegistrationCustomAnswersRow struct {
+	RegistrationID int64
+	Answers        []byte
+}
+
+func (q *Queries) ListRaceRegistrationCustomAnswers(ctx context.Context, raceID int64) ([]ListRaceRegistrationCustomAnswersRow, error) {
+	rows, err := q.db.Query(ctx, listRaceRegistrationCustomAnswers, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRaceRegistrationCustomAnswersRow
+	for rows.Next() {
+		var i ListRaceRegistrationCustomAnswersRow
+		if err := rows.Scan(&i.RegistrationID, &i.Answers); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceRequiredQuestions = `-- name: ListRaceRequiredQuestions :many
+SELECT question FROM race_required_questions
+WHERE race_id = $1
+ORDER BY question
+`
+
+func (q *Queries) ListRaceRequiredQuestions(ctx context.Context, raceID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, listRaceRequiredQuestions, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var question string
+		if err := rows.Scan(&question); err != nil {
+			return nil, err
+		}
+		items = append(items, question)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceResultEntrants = `-- name: ListRaceResultEntrants :many
+SELECT reg.id, u.date_of_birth,
+  (r.starts_at AT TIME ZONE e.timezone)::date AS race_day
+FROM registrations reg
+INNER JOIN users u ON u.id = reg.user_id
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE reg.race_id = $1
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+`
+
+type ListRaceResultEntrantsRow struct {
+	ID          int64
+	DateOfBirth pgtype.Date
+	RaceDay     pgtype.Date
+}
+
+// Each entrant's date of birth comes with the race's date in its event's
+// time zone, so results can be given an age category.
+func (q *Queries) ListRaceResultEntrants(ctx context.Context, raceID int64) ([]ListRaceResultEntrantsRow, error) {
+	rows, err := q.db.Query(ctx, listRaceResultEntrants, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRaceResultEntrantsRow
+	for rows.Next() {
+		var i ListRaceResultEntrantsRow
+		if err := rows.Scan(&i.ID, &i.DateOfBirth, &i.RaceDay); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceResults = `-- name: ListRaceResults :many
+SELECT rr.id, rr.registration_id, rr.position, rr.finish_seconds, rr.category, rr.published,
+  COALESCE(NULLIF(TRIM(u.first_name || ' ' || u.last_name), ''), rr.name)::text AS name,
+  COALESCE(NULLIF(rr.bib, ''), reg.bib, '')::text AS bib
+FROM race_results rr
+LEFT JOIN registrations reg ON reg.id = rr.registration_id
+LEFT JOIN users u ON u.id = reg.user_id
+WHERE rr.race_id = $1
+ORDER BY rr.position
+`
+
+type ListRaceResultsRow struct {
+	ID             int64
+	RegistrationID pgtype.Int8
+	Position       int32
+	FinishSeconds  int32
+	Category       string
+	Published      bool
+	Name           string
+	Bib            string
+}
+
+// Results name the runner as registered unless their account has no name,
+// as for runners invited by a transfer, and fall back to the uploaded bib.
+func (q *Queries) ListRaceResults(ctx context.Context, raceID int64) ([]ListRaceResultsRow, error) {
+	rows, err := q.db.Query(ctx, listRaceResults, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRaceResultsRow
+	for rows.Next() {
+		var i ListRaceResultsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RegistrationID,
+			&i.Position,
+			&i.FinishSeconds,
+			&i.Category,
+			&i.Published,
+			&i.Name,
+			&i.Bib,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceRoutesByEvent = `-- name: ListRaceRoutesByEvent :many
+SELECT rr.race_id, rr.point_count, rr.distance_metres, rr.elevation_gain_metres, rr.updated_at
+FROM race_routes rr
+INNER JOIN races r ON r.id = rr.race_id
+WHERE r.event_id = $1
+AND r.deleted_at IS NULL
+ORDER BY rr.race_id
+`
+
+type ListRaceRoutesByEventRow struct {
+	RaceID              int64
+	PointCount          int32
+	DistanceMetres      int32
+	ElevationGainMetres int32
+	UpdatedAt           pgtype.Timestamptz
+}
+
+func (q *Queries) ListRaceRoutesByEvent(ctx context.Context, eventID int64) ([]ListRaceRoutesByEventRow, error) {
+	rows, err := q.db.Query(ctx, listRaceRoutesByEvent, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRaceRoutesByEventRow
+	for rows.Next() {
+		var i ListRaceRoutesByEventRow
+		if err := rows.Scan(
+			&i.RaceID,
+			&i.PointCount,
+			&i.DistanceMetres,
+			&i.ElevationGainMetres,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceWaves = `-- name: ListRaceWaves :many
+SELECT w.id, w.race_id, w.name, w.starts_at, w.capacity, w.created_at, w.updated_at, COUNT(reg.id) AS taken
+FROM race_waves w
+LEFT JOIN registrations reg ON reg.wave_id = w.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+WHERE w.race_id = $1
+GROUP BY w.id
+ORDER BY w.starts_at, w.id
+`
+
+type ListRaceWavesRow struct {
+	RaceWave RaceWave
+	Taken    int64
+}
+
+// Waves of the race with the active registrations holding a place in each,
+// earliest start first.
+func (q *Queries) ListRaceWaves(ctx context.Context, raceID int64) ([]ListRaceWavesRow, error) {
+	rows, err := q.db.Query(ctx, listRaceWaves, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRaceWavesRow
+	for rows.Next() {
+		var i ListRaceWavesRow
+		if err := rows.Scan(
+			&i.RaceWave.ID,
+			&i.RaceWave.RaceID,
+			&i.RaceWave.Name,
+			&i.RaceWave.StartsAt,
+			&i.RaceWave.Capacity,
+			&i.RaceWave.CreatedAt,
+			&i.RaceWave.UpdatedAt,
+			&i.Taken,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRaceWavesByEvent = `-- name: ListRaceWavesByEvent :many
+SELECT w.id, w.race_id, w.name, w.starts_at, w.capacity, w.created_at, w.updated_at FROM race_waves w
+INNER JOIN races r ON r.id = w.race_id
+WHERE r.event_id = $1
+AND r.deleted_at IS NULL
+ORDER BY w.race_id, w.starts_at, w.id
+`
+
+func (q *Queries) ListRaceWavesByEvent(ctx context.Context, eventID int64) ([]RaceWave, error) {
+	rows, err := q.db.Query(ctx, listRaceWavesByEvent, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RaceWave
+	for rows.Next() {
+		var i RaceWave
+		if err := rows.Scan(
+			&i.ID,
+			&i.RaceID,
+			&i.Name,
+			&i.StartsAt,
+			&i.Capacity,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRacesByEvent = `-- name: ListRacesByEvent :many
+SELECT id, event_id, name, slug, registration_open_date, registration_close_date, starts_at, max_capacity, price_units, currency, created_at, updated_at, deleted_at, min_age, distance_metres, elevation_gain_metres from races
+WHERE event_id = $1
+AND deleted_at IS NULL
+ORDER BY name
+`
+
+func (q *Queries) ListRacesByEvent(ctx context.Context, eventID int64) ([]Race, error) {
+	rows, err := q.db.Query(ctx, listRacesByEvent, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Race
+	for rows.Next() {
+		var i Race
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Name,
+			&i.Slug,
+			&i.RegistrationOpenDate,
+			&i.RegistrationCloseDate,
+			&i.StartsAt,
+			&i.MaxCapacity,
+			&i.PriceUnits,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.MinAge,
+			&i.DistanceMetres,
+			&i.ElevationGainMetres,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRacesByEvents = `-- name: ListRacesByEvents :many
+SELECT id, event_id, name, slug, registration_open_date, registration_close_date, starts_at, max_capacity, price_units, currency, created_at, updated_at, deleted_at, min_age, distance_metres, elevation_gain_metres from races
+WHERE event_id = ANY($1::bigint[])
+AND deleted_at IS NULL
+ORDER BY event_id, name
+`
+
+func (q *Queries) ListRacesByEvents(ctx context.Context, eventIds []int64) ([]Race, error) {
+	rows, err := q.db.Query(ctx, listRacesByEvents, eventIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Race
+	for rows.Next() {
+		var i Race
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Name,
+			&i.Slug,
+			&i.RegistrationOpenDate,
+			&i.RegistrationCloseDate,
+			&i.StartsAt,
+			&i.MaxCapacity,
+			&i.PriceUnits,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.MinAge,
+			&i.DistanceMetres,
+			&i.ElevationGainMetres,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRegistrationsByUser = `-- name: ListRegistrationsByUser :many
+SELECT reg.id, reg.status, reg.bib, reg.created_at,
+  r.name AS race_name, r.starts_at, r.registration_close_date,
+  e.name AS event_name, e.slug AS event_slug, e.year AS event_year,
+  p.status AS payment_status,
+  EXISTS (
+    SELECT 1 from registration_transfers t
+    WHERE t.registration_id = reg.id
+    AND t.to_user_id = reg.user_id
+    AND t.accepted_at IS NULL
+  ) AS transfer_pending
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+LEFT JOIN LATERAL (
+  SELECT status from payments
+  WHERE registration_id = reg.id
+  ORDER BY created_at DESC
+  LIMIT 1
+) p ON true
+WHERE reg.user_id = $1
+AND reg.deleted_at IS NULL
+ORDER BY r.starts_at NULLS LAST, reg.id
+`
+
+type ListRegistrationsByUserRow struct {
+	ID                    int64
+	Status                RegistrationStatus
+	Bib                   pgtype.Text
+	CreatedAt             pgtype.Timestamptz
+	RaceName              string
+	StartsAt              pgtype.Timestamptz
+	RegistrationCloseDate pgtype.Timestamptz
+	EventName             string
+	EventSlug             string
+	EventYear             int32
+	PaymentStatus         NullPaymentStatus
+	TransferPending       bool
+}
+
+func (q *Queries) ListRegistrationsByUser(ctx context.Context, userID int64) ([]ListRegistrationsByUserRow, error) {
+	rows, err := q.db.Query(ctx, listRegistrationsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRegistrationsByUserRow
+	for rows.Next() {
+		var i ListRegistrationsByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Bib,
+			&i.CreatedAt,
+			&i.RaceName,
+			&i.StartsAt,
+			&i.RegistrationCloseDate,
+			&i.EventName,
+			&i.EventSlug,
+			&i.EventYear,
+			&i.PaymentStatus,
+			&i.TransferPending,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSitemapEvents = `-- name: ListSitemapEvents :many
+SELECT e.year, e.slug, e.updated_at from events e
+WHERE e.deleted_at IS NULL
+AND e.status = 'published'
+AND NOT EXISTS (
+  SELECT 1 FROM events later
+  WHERE later.series_id = e.series_id
+  AND later.year > e.year
+  AND later.status = 'published'
+  AND later.deleted_at IS NULL
+)
+ORDER BY e.year DESC, e.slug
+LIMIT $1 OFFSET $2
+`
+
+type ListSitemapEventsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type ListSitemapEventsRow struct {
+	Year      int32
+	Slug      string
+	UpdatedAt pgtype.Timestamptz
+}
+
+// Every published edition has its own page, but only the latest of a
+// series is listed; the earlier ones are linked from it.
+func (q *Queries) ListSitemapEvents(ctx context.Context, arg ListSitemapEventsParams) ([]ListSitemapEventsRow, error) {
+	rows, err := q.db.Query(ctx, listSitemapEvents, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSitemapEventsRow
+	for rows.Next() {
+		var i ListSitemapEventsRow
+		if err := rows.Scan(&i.Year, &i.Slug, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSpentDataExports = `-- name: ListSpentDataExports :many
+SELECT id, user_id, status, lease_until, storage_key, token_hash, expires_at, downloaded_at, completed_at, created_at FROM data_exports
+WHERE storage_key IS NOT NULL
+AND (expires_at <= $1 OR downloaded_at <= $2)
+ORDER BY id
+LIMIT $3
+`
+
+type ListSpentDataExportsParams struct {
+	Now              pgtype.Timestamptz
+	DownloadedBefore pgtype.Timestamptz
+	MaxExports       int32
+}
+
+// Lists exports whose archives are still stored but have expired or were
+// downloaded before downloaded_before.
+func (q *Queries) ListSpentDataExports(ctx context.Context, arg ListSpentDataExportsParams) ([]DataExport, error) {
+	rows, err := q.db.Query(ctx, listSpentDataExports, arg.Now, arg.DownloadedBefore, arg.MaxExports)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DataExport
+	for rows.Next() {
+		var i DataExport
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Status,
+			&i.LeaseUntil,
+			&i.StorageKey,
+			&i.TokenHash,
+			&i.ExpiresAt,
+			&i.DownloadedAt,
+			&i.CompletedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserSessionsForUser = `-- name: ListUserSessionsForUser :many
+SELECT id, user_id, user_agent, ip_address, last_seen_at, expires_at, revoked_at, created_at, evicted_at from user_sessions
+WHERE user_id = $1
+AND revoked_at IS NULL
+AND expires_at > NOW()
+ORDER BY last_seen_at DESC, id DESC
+`
+
+// Lists the user's sessions that have neither been revoked nor expired,
+// most recently active first.
+func (q *Queries) ListUserSessionsForUser(ctx context.Context, userID int64) ([]UserSession, error) {
+	rows, err := q.db.Query(ctx, listUserSessionsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserSession
+	for rows.Next() {
+		var i UserSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.LastSeenAt,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+			&i.EvictedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT u.id, u.email, u.first_name, u.last_name, u.role, u.deactivated_at, u.created_at,
+       ac.failed_login_attempts, ac.locked_until, ac.last_login_at
+FROM users u
+LEFT JOIN auth_credentials ac ON ac.user_id = u.id AND ac.deleted_at IS NULL
+WHERE u.deleted_at IS NULL
+AND ($1::text = '' OR u.email ILIKE $1 OR u.first_name || ' ' || u.last_name ILIKE $1)
+AND ($2::user_role IS NULL OR u.role = $2)
+ORDER BY u.created_at DESC, u.id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListUsersParams struct {
+	Search     string
+	Role       NullUserRole
+	MaxResults int32
+	Skip       int32
+}
+
+type ListUsersRow struct {
+	ID                  int64
+	Email               string
+	FirstName           string
+	LastName            string
+	Role                UserRole
+	DeactivatedAt       pgtype.Timestamptz
+	CreatedAt           pgtype.Timestamptz
+	FailedLoginAttempts pgtype.Int4
+	LockedUntil         pgtype.Timestamptz
+	LastLoginAt         pgtype.Timestamptz
+}
+
+// Lists users, newest first, with how their sign-ins are going, for the
+// admin users page. A non-empty search is an ILIKE pattern matched against
+// the email and the full name; a non-NULL role keeps users with that role.
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Search,
+		arg.Role,
+		arg.MaxResults,
+		arg.Skip,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersRow
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+			&i.Role,
+			&i.DeactivatedAt,
+			&i.CreatedAt,
+			&i.FailedLoginAttempts,
+			&i.LockedUntil,
+			&i.LastLoginAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWaveEntrants = `-- name: ListWaveEntrants :many
+SELECT reg.id, u.email, u.first_name
+FROM registrations reg
+INNER JOIN users u ON u.id = reg.user_id
+WHERE reg.wave_id = $1
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+AND u.anonymised_at IS NULL
+ORDER BY reg.id
+`
+
+type ListWaveEntrantsRow struct {
+	ID        int64
+	Email     string
+	FirstName string
+}
+
+// Entrants holding a place in the wave, to be told when it moves. Anonymised
+// entrants have no address left to write to.
+func (q *Queries) ListWaveEntrants(ctx context.Context, waveID pgtype.Int8) ([]ListWaveEntrantsRow, error) {
+	rows, err := q.db.Query(ctx, listWaveEntrants, waveID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWaveEntrantsRow
+	for rows.Next() {
+		var i ListWaveEntrantsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.FirstName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, endpoint_id, event_type, payload, status, attempts, next_attempt_at, created_at FROM webhook_deliveries
+WHERE endpoint_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+`
+
+type ListWebhookDeliveriesParams struct {
+	EndpointID int64
+	Limit      int32
+}
+
+// Lists the endpoint's deliveries, newest first.
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveries, arg.EndpointID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.EndpointID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveryAttempts = `-- name: ListWebhookDeliveryAttempts :many
+SELECT id, delivery_id, attempted_at, status_code, error, duration_ms FROM webhook_delivery_attempts
+WHERE delivery_id = ANY($1::bigint[])
+ORDER BY attempted_at, id
+`
+
+func (q *Queries) ListWebhookDeliveryAttempts(ctx context.Context, deliveryIds []int64) ([]WebhookDeliveryAttempt, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveryAttempts, deliveryIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDeliveryAttempt
+	for rows.Next() {
+		var i WebhookDeliveryAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeliveryID,
+			&i.AttemptedAt,
+			&i.StatusCode,
+			&i.Error,
+			&i.DurationMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookEndpoints = `-- name: ListWebhookEndpoints :many
+SELECT id, organisation_id, url, secret_sealed, event_types, created_at FROM webhook_endpoints
+WHERE organisation_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListWebhookEndpoints(ctx context.Context, organisationID int64) ([]WebhookEndpoint, error) {
+	rows, err := q.db.Query(ctx, listWebhookEndpoints, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookEndpoint
+	for rows.Next() {
+		var i WebhookEndpoint
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganisationID,
+			&i.Url,
+			&i.SecretSealed,
+			&i.EventTypes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAccount = `-- name: LockAccount :exec
+UPDATE auth_credentials
+SET locked_until = $2
+WHERE user_id = $1
+`
+
+type LockAccountParams struct {
+	UserID      int64
+	LockedUntil pgtype.Timestamptz
+}
+
+func (q *Queries) LockAccount(ctx context.Context, arg LockAccountParams) error {
+	_, err := q.db.Exec(ctx, lockAccount, arg.UserID, arg.LockedUntil)
+	return err
+}
+
+const lockRace = `-- name: LockRace :one
+SELECT max_capacity from races
+WHERE id = $1
+AND deleted_at IS NULL
+FOR UPDATE
+`
+
+// Locks the race row so concurrent writers cannot take it past capacity.
+func (q *Queries) LockRace(ctx context.Context, id int64) (int32, error) {
+	row := q.db.QueryRow(ctx, lockRace, id)
+	var max_capacity int32
+	err := row.Scan(&max_capacity)
+	return max_capacity, err
+}
+
+const lockTeam = `-- name: LockTeam :one
+SELECT id, race_id, captain_user_id, name, size, invite_code, fill_by, released_at, created_at, updated_at, deleted_at from teams
+WHERE id = $1
+AND released_at IS NULL
+AND deleted_at IS NULL
+FOR UPDATE
+`
+
+// Locks the team row so concurrent joins cannot overfill it.
+func (q *Queries) LockTeam(ctx context.Context, id int64) (Team, error) {
+	row := q.db.QueryRow(ctx, lockTeam, id)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.CaptainUserID,
+		&i.Name,
+		&i.Size,
+		&i.InviteCode,
+		&i.FillBy,
+		&i.ReleasedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, first_name, last_name, phone, address_line1, address_line2, city, state, postal_code, country, role, created_at, updated_at, deleted_at FROM users
-WHERE email = $1
+const lockUserSessions = `-- name: LockUserSessions :exec
+SELECT id FROM users
+WHERE id = $1
+FOR UPDATE
+`
+
+// Locks the user's row, so their sign-ins are counted against the session
+// limit one at a time.
+func (q *Queries) LockUserSessions(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, lockUserSessions, id)
+	return err
+}
+
+const loginAlertSentSince = `-- name: LoginAlertSentSince :one
+SELECT EXISTS (
+    SELECT 1 FROM login_events
+    WHERE user_id = $1
+    AND kind = $2
+    AND alerted
+    AND created_at > $3
+)
+`
+
+type LoginAlertSentSinceParams struct {
+	UserID    int64
+	Kind      LoginEventKind
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) LoginAlertSentSince(ctx context.Context, arg LoginAlertSentSinceParams) (bool, error) {
+	row := q.db.QueryRow(ctx, loginAlertSentSince, arg.UserID, arg.Kind, arg.CreatedAt)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const nextRaceCustomFieldVersion = `-- name: NextRaceCustomFieldVersion :one
+UPDATE race_custom_fields
+SET version = version + 1
+WHERE id = $1
+AND race_id = $2
+AND deleted_at IS NULL
+RETURNING version
+`
+
+type NextRaceCustomFieldVersionParams struct {
+	ID     int64
+	RaceID int64
+}
+
+// Moves a field on to its next version, which the caller then adds.
+func (q *Queries) NextRaceCustomFieldVersion(ctx context.Context, arg NextRaceCustomFieldVersionParams) (int32, error) {
+	row := q.db.QueryRow(ctx, nextRaceCustomFieldVersion, arg.ID, arg.RaceID)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
+
+const promoteEntrantToOrganizer = `-- name: PromoteEntrantToOrganizer :exec
+UPDATE users
+SET role = 'organizer'
+WHERE id = $1
+AND role = 'entrant'
+`
+
+func (q *Queries) PromoteEntrantToOrganizer(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, promoteEntrantToOrganizer, id)
+	return err
+}
+
+const publishRaceResults = `-- name: PublishRaceResults :execrows
+UPDATE race_results
+SET published = true
+WHERE race_id = $1
+AND NOT published
+`
+
+func (q *Queries) PublishRaceResults(ctx context.Context, raceID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, publishRaceResults, raceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reactivateUser = `-- name: ReactivateUser :execrows
+UPDATE users
+SET deactivated_at = NULL
+WHERE id = $1
+AND deleted_at IS NULL
+AND deactivated_at IS NOT NULL
+`
+
+func (q *Queries) ReactivateUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, reactivateUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordPaymentRefund = `-- name: RecordPaymentRefund :exec
+UPDATE payments
+SET status = $2,
+    refunded_units = $3,
+    refunded_at = NOW()
+WHERE id = $1
+`
+
+type RecordPaymentRefundParams struct {
+	ID            int64
+	Status        PaymentStatus
+	RefundedUnits int32
+}
+
+func (q *Queries) RecordPaymentRefund(ctx context.Context, arg RecordPaymentRefundParams) error {
+	_, err := q.db.Exec(ctx, recordPaymentRefund, arg.ID, arg.Status, arg.RefundedUnits)
+	return err
+}
+
+const releaseUnfilledTeams = `-- name: ReleaseUnfilledTeams :execrows
+UPDATE teams t
+SET released_at = NOW()
+WHERE t.fill_by <= $1
+AND t.released_at IS NULL
+AND t.deleted_at IS NULL
+AND t.size > (
+  SELECT COUNT(*) FROM registrations reg
+  WHERE reg.team_id = t.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+)
+`
+
+// Teams still short of members at their fill-by time give up the places
+// they were holding for the rest.
+func (q *Queries) ReleaseUnfilledTeams(ctx context.Context, fillBy pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseUnfilledTeams, fillBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const removeOrganisationMember = `-- name: RemoveOrganisationMember :execrows
+UPDATE organisation_members
+SET deleted_at = NOW()
+WHERE organisation_id = $1
+AND user_id = $2
+AND deleted_at IS NULL
+`
+
+type RemoveOrganisationMemberParams struct {
+	OrganisationID int64
+	UserID         int64
+}
+
+func (q *Queries) RemoveOrganisationMember(ctx context.Context, arg RemoveOrganisationMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeOrganisationMember, arg.OrganisationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const removeUserMemberships = `-- name: RemoveUserMemberships :exec
+UPDATE organisation_members
+SET deleted_at = NOW()
+WHERE user_id = $1
+AND deleted_at IS NULL
+`
+
+func (q *Queries) RemoveUserMemberships(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, removeUserMemberships, userID)
+	return err
+}
+
+const requestDataExport = `-- name: RequestDataExport :one
+INSERT INTO data_exports (user_id)
+VALUES ($1)
+ON CONFLICT (user_id) WHERE status = 'pending'
+DO UPDATE SET user_id = EXCLUDED.user_id
+RETURNING id, user_id, status, lease_until, storage_key, token_hash, expires_at, downloaded_at, completed_at, created_at
+`
+
+// Starts a copy of the user's data, or returns the request already
+// waiting to be built.
+func (q *Queries) RequestDataExport(ctx context.Context, userID int64) (DataExport, error) {
+	row := q.db.QueryRow(ctx, requestDataExport, userID)
+	var i DataExport
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.LeaseUntil,
+		&i.StorageKey,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.DownloadedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const revokeAPIToken = `-- name: RevokeAPIToken :execrows
+UPDATE api_tokens
+SET revoked_at = NOW()
+WHERE id = $1
+AND user_id = $2
+AND revoked_at IS NULL
+`
+
+type RevokeAPITokenParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) RevokeAPIToken(ctx context.Context, arg RevokeAPITokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeAPIToken, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeOtherUserSessions = `-- name: RevokeOtherUserSessions :execrows
+UPDATE user_sessions
+SET revoked_at = NOW()
+WHERE user_id = $1
+AND id <> $2
+AND revoked_at IS NULL
+`
+
+type RevokeOtherUserSessionsParams struct {
+	UserID int64
+	ID     int64
+}
+
+// Revokes every session of the user's but the one given.
+func (q *Queries) RevokeOtherUserSessions(ctx context.Context, arg RevokeOtherUserSessionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeOtherUserSessions, arg.UserID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeUserSession = `-- name: RevokeUserSession :execrows
+UPDATE user_sessions
+SET revoked_at = NOW()
+WHERE id = $1
+AND user_id = $2
+AND revoked_at IS NULL
+`
+
+type RevokeUserSessionParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) RevokeUserSession(ctx context.Context, arg RevokeUserSessionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeUserSession, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setEmailPreference = `-- name: SetEmailPreference :execrows
+UPDATE users
+SET email_preference = $2
+WHERE id = $1
+AND deleted_at IS NULL
+`
+
+type SetEmailPreferenceParams struct {
+	ID              int64
+	EmailPreference EmailPreference
+}
+
+func (q *Queries) SetEmailPreference(ctx context.Context, arg SetEmailPreferenceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setEmailPreference, arg.ID, arg.EmailPreference)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setEventPhotoPosition = `-- name: SetEventPhotoPosition :exec
+UPDATE event_photos
+SET position = $1
+WHERE id = $2
+AND event_id = $3
+`
+
+type SetEventPhotoPositionParams struct {
+	Position int32
+	ID       int64
+	EventID  int64
+}
+
+func (q *Queries) SetEventPhotoPosition(ctx context.Context, arg SetEventPhotoPositionParams) error {
+	_, err := q.db.Exec(ctx, setEventPhotoPosition, arg.Position, arg.ID, arg.EventID)
+	return err
+}
+
+const setEventSeries = `-- name: SetEventSeries :execrows
+UPDATE events
+SET series_id = $2
+WHERE id = $1
+AND deleted_at IS NULL
+`
+
+type SetEventSeriesParams struct {
+	ID       int64
+	SeriesID pgtype.Int8
+}
+
+func (q *Queries) SetEventSeries(ctx context.Context, arg SetEventSeriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setEventSeries, arg.ID, arg.SeriesID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setEventStatus = `-- name: SetEventStatus :execrows
+UPDATE events
+SET status = $2
+WHERE id = $1
+AND deleted_at IS NULL
+`
+
+type SetEventStatusParams struct {
+	ID     int64
+	Status EventStatus
+}
+
+func (q *Queries) SetEventStatus(ctx context.Context, arg SetEventStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setEventStatus, arg.ID, arg.Status)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setNotificationPreference = `-- name: SetNotificationPreference :execrows
+UPDATE organisation_members
+SET notification_preference = $3
+WHERE organisation_id = $1
+AND user_id = $2
+AND deleted_at IS NULL
+`
+
+type SetNotificationPreferenceParams struct {
+	OrganisationID         int64
+	UserID                 int64
+	NotificationPreference NotificationPreference
+}
+
+func (q *Queries) SetNotificationPreference(ctx context.Context, arg SetNotificationPreferenceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setNotificationPreference, arg.OrganisationID, arg.UserID, arg.NotificationPreference)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setOrganisationBranding = `-- name: SetOrganisationBranding :execrows
+UPDATE organisations
+SET brand_colour = $2,
+    logo_key = $3,
+    banner_key = $4
+WHERE id = $1
+AND deleted_at IS NULL
+`
+
+type SetOrganisationBrandingParams struct {
+	ID          int64
+	BrandColour pgtype.Text
+	LogoKey     pgtype.Text
+	BannerKey   pgtype.Text
+}
+
+func (q *Queries) SetOrganisationBranding(ctx context.Context, arg SetOrganisationBrandingParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrganisationBranding,
+		arg.ID,
+		arg.BrandColour,
+		arg.LogoKey,
+		arg.BannerKey,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setOrganisationContactEmail = `-- name: SetOrganisationContactEmail :execrows
+UPDATE organisations
+SET contact_email = $2
+WHERE id = $1
+AND deleted_at IS NULL
+`
+
+type SetOrganisationContactEmailParams struct {
+	ID           int64
+	ContactEmail pgtype.Text
+}
+
+func (q *Queries) SetOrganisationContactEmail(ctx context.Context, arg SetOrganisationContactEmailParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrganisationContactEmail, arg.ID, arg.ContactEmail)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setOrganisationEmbedding = `-- name: SetOrganisationEmbedding :execrows
+UPDATE organisations
+SET embed_public = $2,
+    embed_origins = $3
+WHERE id = $1
+AND deleted_at IS NULL
+`
+
+type SetOrganisationEmbeddingParams struct {
+	ID           int64
+	EmbedPublic  bool
+	EmbedOrigins []string
+}
+
+func (q *Queries) SetOrganisationEmbedding(ctx context.Context, arg SetOrganisationEmbeddingParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOrganisationEmbedding, arg.ID, arg.EmbedPublic, arg.EmbedOrigins)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setRaceCustomFieldPosition = `-- name: SetRaceCustomFieldPosition :exec
+UPDATE race_custom_fields
+SET position = $1
+WHERE id = $2
+AND race_id = $3
+`
+
+type SetRaceCustomFieldPositionParams struct {
+	Position int32
+	ID       int64
+	RaceID   int64
+}
+
+func (q *Queries) SetRaceCustomFieldPosition(ctx context.Context, arg SetRaceCustomFieldPositionParams) error {
+	_, err := q.db.Exec(ctx, setRaceCustomFieldPosition, arg.Position, arg.ID, arg.RaceID)
+	return err
+}
+
+const setRaceDistance = `-- name: SetRaceDistance :one
+UPDATE races
+SET distance_metres = $2,
+    elevation_gain_metres = $3,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING id, event_id, name, slug, registration_open_date, registration_close_date, starts_at, max_capacity, price_units, currency, created_at, updated_at, deleted_at, min_age, distance_metres, elevation_gain_metres
+`
+
+type SetRaceDistanceParams struct {
+	ID                  int64
+	DistanceMetres      pgtype.Int4
+	ElevationGainMetres pgtype.Int4
+}
+
+// A NULL distance or climb is unknown.
+func (q *Queries) SetRaceDistance(ctx context.Context, arg SetRaceDistanceParams) (Race, error) {
+	row := q.db.QueryRow(ctx, setRaceDistance, arg.ID, arg.DistanceMetres, arg.ElevationGainMetres)
+	var i Race
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Name,
+		&i.Slug,
+		&i.RegistrationOpenDate,
+		&i.RegistrationCloseDate,
+		&i.StartsAt,
+		&i.MaxCapacity,
+		&i.PriceUnits,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.MinAge,
+		&i.DistanceMetres,
+		&i.ElevationGainMetres,
+	)
+	return i, err
+}
+
+const setRaceMinAge = `-- name: SetRaceMinAge :one
+UPDATE races
+SET min_age = $2,
+    updated_at = NOW()
+WHERE id = $1
 AND deleted_at IS NULL
-LIMIT 1
+RETURNING id, event_id, name, slug, registration_open_date, registration_close_date, starts_at, max_capacity, price_units, currency, created_at, updated_at, deleted_at, min_age, distance_metres, elevation_gain_metres
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByEmail, email)
-	var i User
+type SetRaceMinAgeParams struct {
+	ID     int64
+	MinAge pgtype.Int4
+}
+
+// A NULL min_age lets entrants of any age enter.
+func (q *Queries) SetRaceMinAge(ctx context.Context, arg SetRaceMinAgeParams) (Race, error) {
+	row := q.db.QueryRow(ctx, setRaceMinAge, arg.ID, arg.MinAge)
+	var i Race
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.FirstName,
-		&i.LastName,
-		&i.Phone,
-		&i.AddressLine1,
-		&i.AddressLine2,
-		&i.City,
-		&i.State,
-		&i.PostalCode,
-		&i.Country,
-		&i.Role,
+		&i.EventID,
+		&i.Name,
+		&i.Slug,
+		&i.RegistrationOpenDate,
+		&i.RegistrationCloseDate,
+		&i.StartsAt,
+		&i.MaxCapacity,
+		&i.PriceUnits,
+		&i.Currency,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.MinAge,
+		&i.DistanceMetres,
+		&i.ElevationGainMetres,
 	)
 	return i, err
 }
 
-const incrementFailedLoginAttempts = `-- name: IncrementFailedLoginAttempts :exec
-UPDATE auth_credentials
-SET failed_login_attempts = failed_login_attempts + 1
-WHERE user_id = $1
+const setRegistrationBib = `-- name: SetRegistrationBib :execrows
+UPDATE registrations
+SET bib = $2
+WHERE id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL
 `
 
-func (q *Queries) IncrementFailedLoginAttempts(ctx context.Context, userID int64) error {
-	_, err := q.db.Exec(ctx, incrementFailedLoginAttempts, userID)
+type SetRegistrationBibParams struct {
+	ID  int64
+	Bib pgtype.Text
+}
+
+func (q *Queries) SetRegistrationBib(ctx context.Context, arg SetRegistrationBibParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setRegistrationBib, arg.ID, arg.Bib)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setUserRole = `-- name: SetUserRole :execrows
+UPDATE users
+SET role = $2
+WHERE id = $1
+AND deleted_at IS NULL
+`
+
+type SetUserRoleParams struct {
+	ID   int64
+	Role UserRole
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setUserRole, arg.ID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const startEventSeries = `-- name: StartEventSeries :exec
+UPDATE events
+SET series_id = id
+WHERE id = $1
+AND series_id IS NULL
+AND deleted_at IS NULL
+`
+
+// Makes the event the first edition of its own series, unless it is
+// already in one.
+func (q *Queries) StartEventSeries(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, startEventSeries, id)
 	return err
 }
 
-const isAccountLocked = `-- name: IsAccountLocked :one
-SELECT
-    CASE
-        WHEN locked_until IS NULL THEN false
-        WHEN locked_until > NOW() THEN true
-        ELSE false
-    END as is_locked
-FROM auth_credentials
-WHERE user_id = $1
+const touchAPIToken = `-- name: TouchAPIToken :exec
+UPDATE api_tokens
+SET last_used_at = NOW()
+WHERE id = $1
 `
 
-func (q *Queries) IsAccountLocked(ctx context.Context, userID int64) (bool, error) {
-	row := q.db.QueryRow(ctx, isAccountLocked, userID)
-	var is_locked bool
-	err := row.Scan(&is_locked)
-	return is_locked, err
+func (q *Queries) TouchAPIToken(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, touchAPIToken, id)
+	return err
 }
 
-const listEvents = `-- name: ListEvents :many
-SELECT id, organisation_id, name, slug, created_at, updated_at, deleted_at from events
-ORDER BY name
+const touchOrganisationMembership = `-- name: TouchOrganisationMembership :exec
+UPDATE organisation_members
+SET last_used_at = NOW()
+WHERE organisation_id = $1
+AND user_id = $2
+AND deleted_at IS NULL
 `
 
-func (q *Queries) ListEvents(ctx context.Context) ([]Event, error) {
-	rows, err := q.db.Query(ctx, listEvents)
+type TouchOrganisationMembershipParams struct {
+	OrganisationID int64
+	UserID         int64
+}
+
+func (q *Queries) TouchOrganisationMembership(ctx context.Context, arg TouchOrganisationMembershipParams) error {
+	_, err := q.db.Exec(ctx, touchOrganisationMembership, arg.OrganisationID, arg.UserID)
+	return err
+}
+
+const touchUserSession = `-- name: TouchUserSession :exec
+UPDATE user_sessions
+SET last_seen_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) TouchUserSession(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, touchUserSession, id)
+	return err
+}
+
+const transferRegistration = `-- name: TransferRegistration :execrows
+UPDATE registrations
+SET user_id = $1
+WHERE id = $2
+AND user_id = $3
+AND status <> 'cancelled'
+AND deleted_at IS NULL
+`
+
+type TransferRegistrationParams struct {
+	ToUserID   int64
+	ID         int64
+	FromUserID int64
+}
+
+func (q *Queries) TransferRegistration(ctx context.Context, arg TransferRegistrationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, transferRegistration, arg.ToUserID, arg.ID, arg.FromUserID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Event
-	for rows.Next() {
-		var i Event
-		if err := rows.Scan(
-			&i.ID,
-			&i.OrganisationID,
-			&i.Name,
-			&i.Slug,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.DeletedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected(), nil
+}
+
+const undoRegistrationCheckIn = `-- name: UndoRegistrationCheckIn :execrows
+UPDATE registrations
+SET checked_in_at = NULL
+WHERE id = $1
+AND race_id = $2
+AND deleted_at IS NULL
+AND checked_in_at > $3
+`
+
+type UndoRegistrationCheckInParams struct {
+	ID             int64
+	RaceID         int64
+	CheckedInAfter pgtype.Timestamptz
+}
+
+func (q *Queries) UndoRegistrationCheckIn(ctx context.Context, arg UndoRegistrationCheckInParams) (int64, error) {
+	result, err := q.db.Exec(ctx, undoRegistrationCheckIn, arg.ID, arg.RaceID, arg.CheckedInAfter)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const lockAccount = `-- name: LockAccount :exec
+const unlockAccount = `-- name: UnlockAccount :execrows
 UPDATE auth_credentials
-SET locked_until = $2
+SET locked_until = NULL,
+    failed_login_attempts = 0
 WHERE user_id = $1
+AND deleted_at IS NULL
 `
 
-type LockAccountParams struct {
-	UserID      int64
-	LockedUntil pgtype.Timestamptz
+func (q *Queries) UnlockAccount(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, unlockAccount, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-func (q *Queries) LockAccount(ctx context.Context, arg LockAccountParams) error {
-	_, err := q.db.Exec(ctx, lockAccount, arg.UserID, arg.LockedUntil)
-	return err
+const unlockExpiredAccounts = `-- name: UnlockExpiredAccounts :execrows
+UPDATE auth_credentials
+SET locked_until = NULL,
+    failed_login_attempts = 0
+WHERE locked_until <= NOW()
+`
+
+func (q *Queries) UnlockExpiredAccounts(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, unlockExpiredAccounts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const updateEvent = `-- name: UpdateEvent :exec
 UPDATE events
 SET name = $2,
-    slug = $3
+    slug = $3,
+    description = $4,
+    location = $5,
+    image_url = $6
 WHERE id = $1
-RETURNING id, organisation_id, name, slug, created_at, updated_at, deleted_at
+RETURNING id, organisation_id, name, slug, year, description, location, image_url, created_at, updated_at, deleted_at
 `
 
 type UpdateEventParams struct {
-	ID   int64
-	Name string
-	Slug string
+	ID          int64
+	Name        string
+	Slug        string
+	Description string
+	Location    string
+	ImageUrl    string
 }
 
 func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) error {
-	_, err := q.db.Exec(ctx, updateEvent, arg.ID, arg.Name, arg.Slug)
+	_, err := q.db.Exec(ctx, updateEvent,
+		arg.ID,
+		arg.Name,
+		arg.Slug,
+		arg.Description,
+		arg.Location,
+		arg.ImageUrl,
+	)
 	return err
 }
 
@@ -455,7 +6570,7 @@ const updateOrganisation = `-- name: UpdateOrganisation :exec
 UPDATE organisations
 SET name = $2
 WHERE id = $1
-RETURNING id, name, created_at, updated_at, deleted_at
+RETURNING id, name, created_at, updated_at, deleted_at, contact_email, brand_colour, logo_key, banner_key, embed_public, embed_origins
 `
 
 type UpdateOrganisationParams struct {
@@ -468,6 +6583,84 @@ func (q *Queries) UpdateOrganisation(ctx context.Context, arg UpdateOrganisation
 	return err
 }
 
+const updateRaceCapacity = `-- name: UpdateRaceCapacity :one
+UPDATE races
+SET max_capacity = $2,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING id, event_id, name, slug, registration_open_date, registration_close_date, starts_at, max_capacity, price_units, currency, created_at, updated_at, deleted_at, min_age, distance_metres, elevation_gain_metres
+`
+
+type UpdateRaceCapacityParams struct {
+	ID          int64
+	MaxCapacity int32
+}
+
+func (q *Queries) UpdateRaceCapacity(ctx context.Context, arg UpdateRaceCapacityParams) (Race, error) {
+	row := q.db.QueryRow(ctx, updateRaceCapacity, arg.ID, arg.MaxCapacity)
+	var i Race
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Name,
+		&i.Slug,
+		&i.RegistrationOpenDate,
+		&i.RegistrationCloseDate,
+		&i.StartsAt,
+		&i.MaxCapacity,
+		&i.PriceUnits,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.MinAge,
+		&i.DistanceMetres,
+		&i.ElevationGainMetres,
+	)
+	return i, err
+}
+
+const updateRaceWave = `-- name: UpdateRaceWave :one
+UPDATE race_waves
+SET name = $3,
+    starts_at = $4,
+    capacity = $5,
+    updated_at = NOW()
+WHERE id = $1
+AND race_id = $2
+RETURNING id, race_id, name, starts_at, capacity, created_at, updated_at
+`
+
+type UpdateRaceWaveParams struct {
+	ID       int64
+	RaceID   int64
+	Name     string
+	StartsAt pgtype.Timestamptz
+	Capacity int32
+}
+
+func (q *Queries) UpdateRaceWave(ctx context.Context, arg UpdateRaceWaveParams) (RaceWave, error) {
+	row := q.db.QueryRow(ctx, updateRaceWave,
+		arg.ID,
+		arg.RaceID,
+		arg.Name,
+		arg.StartsAt,
+		arg.Capacity,
+	)
+	var i RaceWave
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.Name,
+		&i.StartsAt,
+		&i.Capacity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :exec
 UPDATE users
 SET email = $2,
@@ -482,7 +6675,7 @@ SET email = $2,
     country = $11,
     role = $12
 WHERE id = $1
-RETURNING id, email, first_name, last_name, phone, address_line1, address_line2, city, state, postal_code, country, role, created_at, updated_at, deleted_at
+RETURNING id, email, first_name, last_name, phone, address_line1, address_line2, city, state, postal_code, country, role, created_at, updated_at, deleted_at, anonymised_at, email_preference, deactivated_at, date_of_birth, distance_unit, session_version
 `
 
 type UpdateUserParams struct {
@@ -518,6 +6711,212 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	return err
 }
 
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET first_name = $2,
+    last_name = $3,
+    date_of_birth = $4,
+    distance_unit = $5
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING id, email, first_name, last_name, phone, address_line1, address_line2, city, state, postal_code, country, role, created_at, updated_at, deleted_at, anonymised_at, email_preference, deactivated_at, date_of_birth, distance_unit, session_version
+`
+
+type UpdateUserProfileParams struct {
+	ID           int64
+	FirstName    string
+	LastName     string
+	DateOfBirth  pgtype.Date
+	DistanceUnit DistanceUnit
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserProfile,
+		arg.ID,
+		arg.FirstName,
+		arg.LastName,
+		arg.DateOfBirth,
+		arg.DistanceUnit,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FirstName,
+		&i.LastName,
+		&i.Phone,
+		&i.AddressLine1,
+		&i.AddressLine2,
+		&i.City,
+		&i.State,
+		&i.PostalCode,
+		&i.Country,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AnonymisedAt,
+		&i.EmailPreference,
+		&i.DeactivatedAt,
+		&i.DateOfBirth,
+		&i.DistanceUnit,
+		&i.SessionVersion,
+	)
+	return i, err
+}
+
+const updateWebhookDelivery = `-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = $2, attempts = $3, next_attempt_at = $4
+WHERE id = $1
+`
+
+type UpdateWebhookDeliveryParams struct {
+	ID            int64
+	Status        WebhookDeliveryStatus
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, updateWebhookDelivery,
+		arg.ID,
+		arg.Status,
+		arg.Attempts,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const upsertProviderPayment = `-- name: UpsertProviderPayment :one
+INSERT INTO payments (
+  registration_id, amount_units, currency, status, provider_reference,
+  fee_units, refunded_units, refunded_at, created_at
+) VALUES (
+  $1, $2, $3, $4, $5,
+  $6, $7,
+  CASE WHEN $7::int > 0 THEN NOW() END, $8
+)
+ON CONFLICT (provider_reference) WHERE provider_reference IS NOT NULL DO UPDATE
+SET status = CASE WHEN excluded.refunded_units >= payments.refunded_units
+                  THEN excluded.status ELSE payments.status END,
+    fee_units = COALESCE(excluded.fee_units, payments.fee_units),
+    refunded_units = GREATEST(payments.refunded_units, excluded.refunded_units),
+    refunded_at = CASE WHEN excluded.refunded_units > payments.refunded_units
+                       THEN NOW() ELSE payments.refunded_at END
+RETURNING id, registration_id, amount_units, currency, status, provider_reference, refunded_units, refunded_at, created_at, updated_at, fee_units
+`
+
+type UpsertProviderPaymentParams struct {
+	RegistrationID    int64
+	AmountUnits       int32
+	Currency          string
+	Status            PaymentStatus
+	ProviderReference pgtype.Text
+	FeeUnits          pgtype.Int4
+	RefundedUnits     int32
+	CreatedAt         pgtype.Timestamptz
+}
+
+// Records a charge reported by Stripe as a payment for its registration,
+// or brings the payment already recorded for the charge up to date.
+// Webhooks may be repeated or arrive out of order, so an older report
+// never undoes a refund or forgets a fee.
+func (q *Queries) UpsertProviderPayment(ctx context.Context, arg UpsertProviderPaymentParams) (Payment, error) {
+	row := q.db.QueryRow(ctx, upsertProviderPayment,
+		arg.RegistrationID,
+		arg.AmountUnits,
+		arg.Currency,
+		arg.Status,
+		arg.ProviderReference,
+		arg.FeeUnits,
+		arg.RefundedUnits,
+		arg.CreatedAt,
+	)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.RegistrationID,
+		&i.AmountUnits,
+		&i.Currency,
+		&i.Status,
+		&i.ProviderReference,
+		&i.RefundedUnits,
+		&i.RefundedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FeeUnits,
+	)
+	return i, err
+}
+
+const upsertRaceRoute = `-- name: UpsertRaceRoute :exec
+INSERT INTO race_routes (race_id, gpx_gzip, point_count, distance_metres, elevation_gain_metres)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (race_id) DO UPDATE
+SET gpx_gzip = EXCLUDED.gpx_gzip,
+    point_count = EXCLUDED.point_count,
+    distance_metres = EXCLUDED.distance_metres,
+    elevation_gain_metres = EXCLUDED.elevation_gain_metres,
+    updated_at = NOW()
+`
+
+type UpsertRaceRouteParams struct {
+	RaceID              int64
+	GpxGzip             []byte
+	PointCount          int32
+	DistanceMetres      int32
+	ElevationGainMetres int32
+}
+
+// Replaces any route the race already has.
+func (q *Queries) UpsertRaceRoute(ctx context.Context, arg UpsertRaceRouteParams) error {
+	_, err := q.db.Exec(ctx, upsertRaceRoute,
+		arg.RaceID,
+		arg.GpxGzip,
+		arg.PointCount,
+		arg.DistanceMetres,
+		arg.ElevationGainMetres,
+	)
+	return err
+}
+
+const upsertRegistrationAnswers = `-- name: UpsertRegistrationAnswers :exec
+INSERT INTO registration_answers (registration_id, answers_sealed)
+VALUES ($1, $2)
+ON CONFLICT (registration_id) DO UPDATE
+SET answers_sealed = EXCLUDED.answers_sealed
+`
+
+type UpsertRegistrationAnswersParams struct {
+	RegistrationID int64
+	AnswersSealed  []byte
+}
+
+func (q *Queries) UpsertRegistrationAnswers(ctx context.Context, arg UpsertRegistrationAnswersParams) error {
+	_, err := q.db.Exec(ctx, upsertRegistrationAnswers, arg.RegistrationID, arg.AnswersSealed)
+	return err
+}
+
+const useDiscountCode = `-- name: UseDiscountCode :execrows
+UPDATE discount_codes
+SET uses = uses + 1
+WHERE id = $1
+AND (max_uses IS NULL OR uses < max_uses)
+AND deleted_at IS NULL
+`
+
+// Counts an entry against the code unless it has no uses left. Entries
+// using the code at once queue on its row, so no more than max_uses of
+// them succeed.
+func (q *Queries) UseDiscountCode(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, useDiscountCode, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const verifyEmail = `-- name: VerifyEmail :exec
 UPDATE auth_credentials
 SET email_verified_at = NOW()
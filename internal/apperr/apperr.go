@@ -0,0 +1,93 @@
+// Package apperr gives errors a stable code clients can act on, the HTTP
+// status that suits them and a message safe to show.
+//
+// Packages declare their sentinel errors as *Error values and return them,
+// or wrap them with fmt.Errorf, as they would errors.New ones, so errors.Is
+// keeps matching them. Handlers read the code, status and message from the
+// first *Error in the chain rather than knowing every sentinel:
+//
+//	var ErrRaceFull = apperr.New(apperr.CodeRaceFull, http.StatusConflict, "race is full")
+//
+//	return fmt.Errorf("%w: %d places taken", ErrRaceFull, taken)
+//
+//	status := apperr.StatusOf(err) // 409
+package apperr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error is an error a client can be told about.
+type Error struct {
+	// Code names the error for programs, such as RACE_FULL. Codes are part
+	// of the API and do not change once published.
+	Code Code
+	// Status is the HTTP status a response about the error should have
+	Status int
+	// Message describes the error to people. It is shown to clients, so it
+	// must not carry anything they should not see.
+	Message string
+	// Err is the underlying error, if any. It is never shown to clients.
+	Err error
+}
+
+// New returns an Error with no underlying cause, for use as a sentinel.
+func New(code Code, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+// Wrap returns an Error describing err to clients. errors.Is and errors.As
+// still see err through it.
+func Wrap(err error, code Code, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message, Err: err}
+}
+
+// Error returns the message, followed by the cause's if there is one.
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// From returns the first *Error in err's chain.
+func From(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// CodeOf returns the code of the first *Error in err's chain, or
+// CodeInternal if there is none.
+func CodeOf(err error) Code {
+	if appErr, ok := From(err); ok {
+		return appErr.Code
+	}
+	return CodeInternal
+}
+
+// StatusOf returns the status of the first *Error in err's chain, or 500 if
+// there is none, as an error nobody described is the server's problem.
+func StatusOf(err error) int {
+	if appErr, ok := From(err); ok {
+		return appErr.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// MessageOf returns the message of the first *Error in err's chain, or a
+// generic one that gives nothing away if there is none.
+func MessageOf(err error) string {
+	if appErr, ok := From(err); ok {
+		return appErr.Message
+	}
+	return "the server encountered a problem"
+}
@@ -0,0 +1,55 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+var errRaceFull = New(CodeRaceFull, http.StatusConflict, "race is full")
+
+func TestError(t *testing.T) {
+	t.Run("describes wrapped sentinels", func(t *testing.T) {
+		err := fmt.Errorf("failed to register: %w", fmt.Errorf("%w: 200 places taken", errRaceFull))
+
+		if !errors.Is(err, errRaceFull) {
+			t.Errorf("expected %v to match the sentinel", err)
+		}
+		if CodeOf(err) != CodeRaceFull || StatusOf(err) != http.StatusConflict || MessageOf(err) != "race is full" {
+			t.Errorf("unexpected description %q, %d, %q", CodeOf(err), StatusOf(err), MessageOf(err))
+		}
+		if err.Error() != "failed to register: race is full: 200 places taken" {
+			t.Errorf("unexpected message %q", err.Error())
+		}
+	})
+
+	t.Run("keeps the cause it wraps", func(t *testing.T) {
+		cause := errors.New("resource not found")
+		err := fmt.Errorf("loading event: %w", Wrap(cause, CodeEventNotFound, http.StatusNotFound, "event not found"))
+
+		if !errors.Is(err, cause) {
+			t.Errorf("expected %v to match its cause", err)
+		}
+		if CodeOf(err) != CodeEventNotFound || MessageOf(err) != "event not found" {
+			t.Errorf("expected the outermost description, got %q, %q", CodeOf(err), MessageOf(err))
+		}
+		if err.Error() != "loading event: event not found: resource not found" {
+			t.Errorf("unexpected message %q", err.Error())
+		}
+	})
+
+	t.Run("treats undescribed errors as internal", func(t *testing.T) {
+		err := errors.New(`pq: relation "events" does not exist`)
+
+		if _, ok := From(err); ok {
+			t.Error("expected no *Error")
+		}
+		if CodeOf(err) != CodeInternal || StatusOf(err) != http.StatusInternalServerError {
+			t.Errorf("unexpected description %q, %d", CodeOf(err), StatusOf(err))
+		}
+		if MessageOf(err) == err.Error() {
+			t.Error("expected the message to stay hidden")
+		}
+	})
+}
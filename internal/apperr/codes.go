@@ -0,0 +1,80 @@
+package apperr
+
+// Code names an error for programs. Codes are upper snake case and, once
+// an API response has carried one, never change meaning.
+type Code string
+
+// Codes for errors of any kind of resource.
+const (
+	CodeInvalidInput Code = "INVALID_INPUT"
+	CodeNotFound     Code = "NOT_FOUND"
+	CodeConflict     Code = "CONFLICT"
+	CodeInUse        Code = "IN_USE"
+	CodeForbidden    Code = "FORBIDDEN"
+	CodeUnavailable  Code = "UNAVAILABLE"
+	CodeInternal     Code = "INTERNAL_ERROR"
+)
+
+// Codes for accounts and signing in.
+const (
+	CodeInvalidCredentials Code = "INVALID_CREDENTIALS"
+	CodeEmailNotVerified   Code = "EMAIL_NOT_VERIFIED"
+	CodeAccountLocked      Code = "ACCOUNT_LOCKED"
+	CodeAccountDisabled    Code = "ACCOUNT_DISABLED"
+	CodeEmailExists        Code = "EMAIL_EXISTS"
+	CodeInvalidToken       Code = "INVALID_TOKEN"
+	CodeInvalidAPIToken    Code = "INVALID_API_TOKEN"
+	CodeSessionRevoked     Code = "SESSION_REVOKED"
+	CodeSessionEvicted     Code = "SESSION_EVICTED"
+	CodeTooManySessions    Code = "TOO_MANY_SESSIONS"
+	CodeOwnAccount         Code = "OWN_ACCOUNT"
+	CodeCannotImpersonate  Code = "CANNOT_IMPERSONATE"
+)
+
+// Codes for organisations and their events.
+const (
+	CodeAlreadyMember        Code = "ALREADY_MEMBER"
+	CodeOwnerRemoval         Code = "OWNER_REMOVAL"
+	CodeEventNotFound        Code = "EVENT_NOT_FOUND"
+	CodeEventNotPublished    Code = "EVENT_NOT_PUBLISHED"
+	CodeSlugTaken            Code = "SLUG_TAKEN"
+	CodeEventURLTaken        Code = "EVENT_URL_TAKEN"
+	CodeEventHasNoRaces      Code = "EVENT_HAS_NO_RACES"
+	CodeNoRegistrationWindow Code = "NO_REGISTRATION_WINDOW"
+	CodeRaceNotFound         Code = "RACE_NOT_FOUND"
+	CodeWaveNotFound         Code = "WAVE_NOT_FOUND"
+	CodeWaveInUse            Code = "WAVE_IN_USE"
+	CodeTooManyCustomFields  Code = "TOO_MANY_CUSTOM_FIELDS"
+	CodeNoResults            Code = "NO_RESULTS"
+	CodeSpam                 Code = "SPAM"
+	CodeContactFormTiming    Code = "CONTACT_FORM_TIMING"
+)
+
+// Codes for entering races.
+const (
+	CodeRaceFull            Code = "RACE_FULL"
+	CodeRegistrationClosed  Code = "REGISTRATION_CLOSED"
+	CodeAlreadyRegistered   Code = "ALREADY_REGISTERED"
+	CodeAlreadyCancelled    Code = "ALREADY_CANCELLED"
+	CodeCancellationClosed  Code = "CANCELLATION_CLOSED"
+	CodeTransferClosed      Code = "TRANSFER_CLOSED"
+	CodeRecipientRegistered Code = "RECIPIENT_REGISTERED"
+	CodeEditClosed          Code = "EDIT_CLOSED"
+	CodeTooYoung            Code = "TOO_YOUNG"
+	CodePriceTierFull       Code = "PRICE_TIER_FULL"
+	CodeInvalidTeam         Code = "INVALID_TEAM"
+	CodeInvalidTeamCode     Code = "INVALID_TEAM_CODE"
+	CodeTeamClosed          Code = "TEAM_CLOSED"
+	CodeTeamFull            Code = "TEAM_FULL"
+	CodeInvalidBib          Code = "INVALID_BIB"
+	CodeCheckInFinal        Code = "CHECK_IN_FINAL"
+)
+
+// Codes for discount codes.
+const (
+	CodeDiscountNotFound    Code = "DISCOUNT_CODE_NOT_FOUND"
+	CodeDiscountWrongRace   Code = "DISCOUNT_CODE_WRONG_RACE"
+	CodeDiscountNotYetValid Code = "DISCOUNT_CODE_NOT_YET_VALID"
+	CodeDiscountExpired     Code = "DISCOUNT_CODE_EXPIRED"
+	CodeDiscountExhausted   Code = "DISCOUNT_CODE_EXHAUSTED"
+)
@@ -0,0 +1,137 @@
+// Package bus carries news of changes between parts of one process, so
+// caches and other listeners hear that something changed without the
+// service that changed it calling each of them.
+//
+// Messages are published on a Topic, which fixes the type of value they
+// carry. Subscribers are called on the publisher's goroutine, in the order
+// they subscribed, or on goroutines of their own. A subscriber that fails
+// or panics is logged and never reaches the publisher or the other
+// subscribers.
+package bus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// Topic is a kind of message, carrying values of type T.
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic returns the topic with the given name. Subscribers are found by
+// name, so each name must be used with one type only.
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+// Name returns the topic's name.
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+type subscriber struct {
+	name  string
+	async bool
+	fn    func(ctx context.Context, msg any) error
+}
+
+// Bus delivers messages to the subscribers of their topic. It is safe for
+// concurrent use. A nil *Bus takes no subscriptions and drops what is
+// published, for services built without one.
+type Bus struct {
+	logger      *slog.Logger
+	mu          sync.RWMutex
+	subscribers map[string][]subscriber
+	inFlight    sync.WaitGroup
+}
+
+// New creates a Bus that logs failing subscribers to logger.
+func New(logger *slog.Logger) *Bus {
+	return &Bus{logger: logger, subscribers: make(map[string][]subscriber)}
+}
+
+// Subscribe calls fn with each message published on topic, on the
+// publisher's goroutine before Publish returns. Synchronous subscribers are
+// called in the order they subscribed, so one may rely on those before it
+// having run. name identifies the subscriber in logs.
+func Subscribe[T any](b *Bus, topic Topic[T], name string, fn func(ctx context.Context, msg T) error) {
+	b.subscribe(topic.name, subscriber{name: name, fn: adapt(fn)})
+}
+
+// SubscribeAsync calls fn with each message published on topic on a
+// goroutine of its own, so a slow subscriber does not hold up the
+// publisher. Its context carries the publisher's values but is not
+// cancelled with it. Messages may reach it in any order.
+func SubscribeAsync[T any](b *Bus, topic Topic[T], name string, fn func(ctx context.Context, msg T) error) {
+	b.subscribe(topic.name, subscriber{name: name, async: true, fn: adapt(fn)})
+}
+
+func adapt[T any](fn func(ctx context.Context, msg T) error) func(ctx context.Context, msg any) error {
+	return func(ctx context.Context, msg any) error {
+		return fn(ctx, msg.(T))
+	}
+}
+
+func (b *Bus) subscribe(topic string, s subscriber) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[topic] = append(b.subscribers[topic], s)
+}
+
+// Publish delivers msg to the subscribers of topic: the synchronous ones in
+// turn, then the asynchronous ones are started. Services publish only once
+// a change has been committed.
+func Publish[T any](ctx context.Context, b *Bus, topic Topic[T], msg T) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	subscribers := b.subscribers[topic.name]
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		if !s.async {
+			b.deliver(ctx, topic.name, s, msg)
+		}
+	}
+	for _, s := range subscribers {
+		if !s.async {
+			continue
+		}
+		b.inFlight.Add(1)
+		go func() {
+			defer b.inFlight.Done()
+			b.deliver(context.WithoutCancel(ctx), topic.name, s, msg)
+		}()
+	}
+}
+
+// deliver calls the subscriber, logging rather than passing on any error or
+// panic.
+func (b *Bus) deliver(ctx context.Context, topic string, s subscriber, msg any) {
+	defer func() {
+		if p := recover(); p != nil {
+			b.logger.ErrorContext(ctx, "bus subscriber panicked", "topic", topic, "subscriber", s.name,
+				"error", fmt.Errorf("panic: %v\n%s", p, debug.Stack()))
+		}
+	}()
+	if err := s.fn(ctx, msg); err != nil {
+		b.logger.ErrorContext(ctx, "bus subscriber failed", "topic", topic, "subscriber", s.name, "error", err)
+	}
+}
+
+// Wait blocks until the asynchronous subscribers already started have
+// returned, so none is cut off at shutdown.
+func (b *Bus) Wait() {
+	if b == nil {
+		return
+	}
+	b.inFlight.Wait()
+}
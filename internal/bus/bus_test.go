@@ -0,0 +1,168 @@
+package bus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var testTopic = NewTopic[int]("test")
+
+// newTestBus returns a bus logging to the returned buffer.
+func newTestBus() (*Bus, *bytes.Buffer) {
+	var logs bytes.Buffer
+	return New(slog.New(slog.NewTextHandler(&logs, nil))), &logs
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("calls synchronous subscribers in the order they subscribed", func(t *testing.T) {
+		b, _ := newTestBus()
+		var calls []string
+		for _, name := range []string{"first", "second", "third"} {
+			Subscribe(b, testTopic, name, func(ctx context.Context, msg int) error {
+				calls = append(calls, name)
+				return nil
+			})
+		}
+
+		Publish(ctx, b, testTopic, 1)
+
+		if !slices.Equal(calls, []string{"first", "second", "third"}) {
+			t.Errorf("expected subscribers called in order, got %v", calls)
+		}
+	})
+
+	t.Run("has called synchronous subscribers by the time it returns", func(t *testing.T) {
+		b, _ := newTestBus()
+		var got []int
+		Subscribe(b, testTopic, "recorder", func(ctx context.Context, msg int) error {
+			got = append(got, msg)
+			return nil
+		})
+
+		for i := range 3 {
+			Publish(ctx, b, testTopic, i)
+		}
+
+		if !slices.Equal(got, []int{0, 1, 2}) {
+			t.Errorf("expected messages in the order published, got %v", got)
+		}
+	})
+
+	t.Run("delivers only to the topic's subscribers", func(t *testing.T) {
+		b, _ := newTestBus()
+		other := NewTopic[int]("other")
+		called := false
+		Subscribe(b, other, "other", func(ctx context.Context, msg int) error {
+			called = true
+			return nil
+		})
+
+		Publish(ctx, b, testTopic, 1)
+
+		if called {
+			t.Error("expected the other topic's subscriber not to be called")
+		}
+	})
+
+	t.Run("keeps a panicking subscriber from the others and the publisher", func(t *testing.T) {
+		b, logs := newTestBus()
+		var calls []string
+		Subscribe(b, testTopic, "before", func(ctx context.Context, msg int) error {
+			calls = append(calls, "before")
+			return nil
+		})
+		Subscribe(b, testTopic, "broken", func(ctx context.Context, msg int) error {
+			panic("boom")
+		})
+		Subscribe(b, testTopic, "after", func(ctx context.Context, msg int) error {
+			calls = append(calls, "after")
+			return nil
+		})
+
+		Publish(ctx, b, testTopic, 1)
+
+		if !slices.Equal(calls, []string{"before", "after"}) {
+			t.Errorf("expected the other subscribers called, got %v", calls)
+		}
+		if !strings.Contains(logs.String(), "bus subscriber panicked") || !strings.Contains(logs.String(), "subscriber=broken") {
+			t.Errorf("expected the panic logged, got %q", logs.String())
+		}
+	})
+
+	t.Run("logs a subscriber's error and carries on", func(t *testing.T) {
+		b, logs := newTestBus()
+		called := false
+		Subscribe(b, testTopic, "failing", func(ctx context.Context, msg int) error {
+			return errors.New("database down")
+		})
+		Subscribe(b, testTopic, "next", func(ctx context.Context, msg int) error {
+			called = true
+			return nil
+		})
+
+		Publish(ctx, b, testTopic, 1)
+
+		if !called {
+			t.Error("expected the next subscriber called")
+		}
+		if !strings.Contains(logs.String(), "database down") {
+			t.Errorf("expected the error logged, got %q", logs.String())
+		}
+	})
+
+	t.Run("runs asynchronous subscribers outside the publisher's cancellation", func(t *testing.T) {
+		b, logs := newTestBus()
+		var mu sync.Mutex
+		var got []int
+		var ctxErrs []error
+		SubscribeAsync(b, testTopic, "async", func(ctx context.Context, msg int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, msg)
+			ctxErrs = append(ctxErrs, ctx.Err())
+			return nil
+		})
+		SubscribeAsync(b, testTopic, "broken", func(ctx context.Context, msg int) error {
+			panic("boom")
+		})
+
+		ctx, cancel := context.WithCancel(ctx)
+		for i := range 3 {
+			Publish(ctx, b, testTopic, i)
+		}
+		cancel()
+		b.Wait()
+
+		slices.Sort(got)
+		if !slices.Equal(got, []int{0, 1, 2}) {
+			t.Errorf("expected every message delivered, got %v", got)
+		}
+		for _, err := range ctxErrs {
+			if err != nil {
+				t.Errorf("expected the subscriber's context not cancelled, got %v", err)
+			}
+		}
+		if strings.Count(logs.String(), "bus subscriber panicked") != 3 {
+			t.Errorf("expected each panic logged, got %q", logs.String())
+		}
+	})
+
+	t.Run("drops messages on a nil bus", func(t *testing.T) {
+		var b *Bus
+		Subscribe(b, testTopic, "ignored", func(ctx context.Context, msg int) error {
+			t.Error("expected no subscriber called")
+			return nil
+		})
+
+		Publish(ctx, b, testTopic, 1)
+		b.Wait()
+	})
+}
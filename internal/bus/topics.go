@@ -0,0 +1,35 @@
+package bus
+
+// RegistrationChange identifies a registration that was made or cancelled.
+type RegistrationChange struct {
+	RegistrationID int64
+	RaceID         int64
+	EventID        int64
+}
+
+// EventChange identifies an event that was changed.
+type EventChange struct {
+	EventID int64
+}
+
+// RaceChange identifies a race that was changed.
+type RaceChange struct {
+	RaceID  int64
+	EventID int64
+}
+
+// Topics the services publish on
+var (
+	// RegistrationCreated is published when someone is entered in a race,
+	// on their own or as a member of a team.
+	RegistrationCreated = NewTopic[RegistrationChange]("registration.created")
+	// RegistrationCancelled is published when an entry is cancelled.
+	RegistrationCancelled = NewTopic[RegistrationChange]("registration.cancelled")
+	// EventUpdated is published when an event's details change, or it is
+	// published or archived.
+	EventUpdated = NewTopic[EventChange]("event.updated")
+	// RaceUpdated is published when a race is created or its settings
+	// change, and when its entries change other than one at a time, as by
+	// an import.
+	RaceUpdated = NewTopic[RaceChange]("race.updated")
+)
@@ -0,0 +1,256 @@
+// Package cache keeps values in memory for a while, so reads of data that
+// changes rarely, such as registration counts or the years with events,
+// need not go to the database every time.
+//
+// A Cache is safe for concurrent use. Concurrent misses for the same key
+// wait for a single load rather than each loading the value themselves.
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLoadPanicked is returned to callers of GetOrLoad that waited on a
+// load that panicked.
+var ErrLoadPanicked = errors.New("cache: load panicked")
+
+// Clock tells a Cache the time, so tests can move it on by hand.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Options configure a Cache. The zero value keeps entries until they
+// expire, however many there are, with expired entries removed only as
+// they are read.
+type Options struct {
+	// TTL is how long values loaded by GetOrLoad are kept.
+	TTL time.Duration
+	// MaxEntries bounds how many entries are kept. Once it is reached the
+	// least recently used entry makes room for a new one. Zero is no bound.
+	MaxEntries int
+	// JanitorInterval, when positive, starts a goroutine that removes
+	// expired entries at that interval until the cache is closed.
+	JanitorInterval time.Duration
+	// Clock defaults to the system clock.
+	Clock Clock
+}
+
+// Cache holds values of type V by key, each until its time to live runs
+// out. Values are handed out as stored, so callers must not modify them.
+type Cache[K comparable, V any] struct {
+	ttl        time.Duration
+	maxEntries int
+	clock      Clock
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	// order holds the entries, most recently used first
+	order *list.List
+	// loads are the GetOrLoad calls in progress, which later callers for
+	// the same key wait on
+	loads map[K]*load[V]
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+type load[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// New creates a Cache, starting its janitor if opts asks for one. A cache
+// with a janitor should be closed once it is no longer needed.
+func New[K comparable, V any](opts Options) *Cache[K, V] {
+	c := &Cache[K, V]{
+		ttl:        opts.TTL,
+		maxEntries: opts.MaxEntries,
+		clock:      opts.Clock,
+		entries:    make(map[K]*list.Element),
+		order:      list.New(),
+		loads:      make(map[K]*load[V]),
+		stop:       make(chan struct{}),
+	}
+	if c.clock == nil {
+		c.clock = systemClock{}
+	}
+	if opts.JanitorInterval > 0 {
+		go c.janitor(opts.JanitorInterval)
+	}
+	return c
+}
+
+// Get returns the value kept for key, and false if there is none or it
+// has expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(key)
+}
+
+func (c *Cache[K, V]) get(key K) (V, bool) {
+	el, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	e := el.Value.(*entry[K, V])
+	if !c.clock.Now().Before(e.expires) {
+		c.remove(el)
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Set keeps value for key for ttl, replacing any value kept already.
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value, ttl)
+}
+
+func (c *Cache[K, V]) set(key K, value V, ttl time.Duration) {
+	expires := c.clock.Now().Add(ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// GetOrLoad returns the value kept for key, calling loader for it, and
+// keeping what it returns for the cache's TTL, if there is none. Callers
+// missing the same key while a load is in progress wait for its result
+// rather than loading again. Errors are returned to every waiting caller
+// but not kept, so the next call loads again.
+func (c *Cache[K, V]) GetOrLoad(key K, loader func() (V, error)) (V, error) {
+	c.mu.Lock()
+	if value, ok := c.get(key); ok {
+		c.mu.Unlock()
+		return value, nil
+	}
+	if l, ok := c.loads[key]; ok {
+		c.mu.Unlock()
+		<-l.done
+		return l.value, l.err
+	}
+	l := c.startLoad(key)
+	c.mu.Unlock()
+
+	// A load that panics fails the callers waiting on it rather than
+	// leaving them waiting forever
+	finished := false
+	defer func() {
+		if !finished {
+			l.err = ErrLoadPanicked
+			c.finishLoad(key, l)
+		}
+	}()
+	l.value, l.err = loader()
+	finished = true
+	c.finishLoad(key, l)
+	return l.value, l.err
+}
+
+func (c *Cache[K, V]) startLoad(key K) *load[V] {
+	l := &load[V]{done: make(chan struct{})}
+	c.loads[key] = l
+	return l
+}
+
+// finishLoad keeps a successful load's value unless the key was
+// invalidated while it ran, as the value may then be out of date, and
+// releases the callers waiting on it.
+func (c *Cache[K, V]) finishLoad(key K, l *load[V]) {
+	c.mu.Lock()
+	if c.loads[key] == l {
+		delete(c.loads, key)
+		if l.err == nil {
+			c.set(key, l.value, c.ttl)
+		}
+	}
+	c.mu.Unlock()
+	close(l.done)
+}
+
+// Invalidate discards the value kept for key, so the next read loads it
+// afresh. A load of key already in progress is not kept when it finishes.
+func (c *Cache[K, V]) Invalidate(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	delete(c.loads, key)
+}
+
+// Len returns how many entries are kept, counting any that have expired
+// but not yet been removed.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// DeleteExpired removes every entry that has expired.
+func (c *Cache[K, V]) DeleteExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if !now.Before(el.Value.(*entry[K, V]).expires) {
+			c.remove(el)
+		}
+		el = next
+	}
+}
+
+// Close stops the cache's janitor, if it has one. The cache may still be
+// used, though expired entries are then only removed as they are read.
+func (c *Cache[K, V]) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (c *Cache[K, V]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*entry[K, V]).key)
+}
+
+func (c *Cache[K, V]) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.DeleteExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock tests move on by hand.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, time.May, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+func TestExpiry(t *testing.T) {
+	clock := newClock()
+	c := New[string, int](Options{Clock: clock})
+
+	c.Set("a", 1, time.Minute)
+	clock.Add(time.Minute - time.Second)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected 1 before the TTL, got %d, %v", v, ok)
+	}
+
+	clock.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected the entry to expire at its TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected the expired entry to be removed, got %d entries", c.Len())
+	}
+
+	t.Run("replacing a value restarts its TTL", func(t *testing.T) {
+		c.Set("b", 1, time.Minute)
+		clock.Add(30 * time.Second)
+		c.Set("b", 2, time.Minute)
+		clock.Add(45 * time.Second)
+		if v, ok := c.Get("b"); !ok || v != 2 {
+			t.Errorf("expected 2, got %d, %v", v, ok)
+		}
+	})
+
+	t.Run("removes expired entries unread", func(t *testing.T) {
+		c := New[string, int](Options{Clock: clock})
+		c.Set("short", 1, time.Second)
+		c.Set("long", 2, time.Hour)
+		clock.Add(time.Minute)
+
+		c.DeleteExpired()
+
+		if c.Len() != 1 {
+			t.Errorf("expected only the long-lived entry left, got %d entries", c.Len())
+		}
+		if _, ok := c.Get("long"); !ok {
+			t.Error("expected the long-lived entry to be kept")
+		}
+	})
+}
+
+func TestGetOrLoad(t *testing.T) {
+	t.Run("keeps loaded values for the TTL", func(t *testing.T) {
+		clock := newClock()
+		c := New[string, int](Options{TTL: time.Minute, Clock: clock})
+		loads := 0
+		loader := func() (int, error) {
+			loads++
+			return loads, nil
+		}
+
+		for range 3 {
+			if v, err := c.GetOrLoad("a", loader); err != nil || v != 1 {
+				t.Fatalf("expected 1, got %d, %v", v, err)
+			}
+		}
+		clock.Add(time.Minute)
+		if v, _ := c.GetOrLoad("a", loader); v != 2 || loads != 2 {
+			t.Errorf("expected a reload after the TTL, got %d after %d loads", v, loads)
+		}
+	})
+
+	t.Run("does not keep errors", func(t *testing.T) {
+		c := New[string, int](Options{TTL: time.Minute})
+		failed := errors.New("database unavailable")
+
+		if _, err := c.GetOrLoad("a", func() (int, error) { return 0, failed }); !errors.Is(err, failed) {
+			t.Fatalf("expected the load's error, got %v", err)
+		}
+		if v, err := c.GetOrLoad("a", func() (int, error) { return 7, nil }); err != nil || v != 7 {
+			t.Errorf("expected the next call to load again, got %d, %v", v, err)
+		}
+	})
+
+	t.Run("loads once for concurrent misses", func(t *testing.T) {
+		c := New[string, int](Options{TTL: time.Minute})
+		var loads atomic.Int32
+		release := make(chan struct{})
+
+		const callers = 100
+		var started, done sync.WaitGroup
+		results := make([]int, callers)
+		started.Add(callers)
+		done.Add(callers)
+		for i := range callers {
+			go func() {
+				defer done.Done()
+				started.Done()
+				v, err := c.GetOrLoad("a", func() (int, error) {
+					loads.Add(1)
+					<-release
+					return 42, nil
+				})
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				results[i] = v
+			}()
+		}
+		started.Wait()
+		// Give the callers time to pile up on the load before it finishes
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		done.Wait()
+
+		if n := loads.Load(); n != 1 {
+			t.Errorf("expected one load, got %d", n)
+		}
+		for i, v := range results {
+			if v != 42 {
+				t.Fatalf("caller %d: expected 42, got %d", i, v)
+			}
+		}
+	})
+
+	t.Run("fails waiting callers when the load panics", func(t *testing.T) {
+		c := New[string, int](Options{TTL: time.Minute})
+		loading := make(chan struct{})
+		release := make(chan struct{})
+
+		go func() {
+			defer func() { _ = recover() }()
+			_, _ = c.GetOrLoad("a", func() (int, error) {
+				close(loading)
+				<-release
+				panic("boom")
+			})
+		}()
+		<-loading
+		waited := make(chan error)
+		go func() {
+			_, err := c.GetOrLoad("a", func() (int, error) { return 1, nil })
+			waited <- err
+		}()
+		// The second caller either waits on the panicking load or, if it
+		// arrives after, loads for itself
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+
+		err := <-waited
+		if err != nil && !errors.Is(err, ErrLoadPanicked) {
+			t.Errorf("expected ErrLoadPanicked, got %v", err)
+		}
+		if _, ok := c.Get("a"); ok != (err == nil) {
+			t.Error("expected only a value loaded without panicking to be kept")
+		}
+	})
+
+	t.Run("does not keep a load invalidated while in progress", func(t *testing.T) {
+		c := New[string, int](Options{TTL: time.Minute})
+		loading := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan int)
+
+		go func() {
+			v, _ := c.GetOrLoad("a", func() (int, error) {
+				close(loading)
+				<-release
+				return 1, nil
+			})
+			done <- v
+		}()
+		<-loading
+		c.Invalidate("a")
+		close(release)
+
+		if v := <-done; v != 1 {
+			t.Errorf("expected the caller to get its load, got %d", v)
+		}
+		if _, ok := c.Get("a"); ok {
+			t.Error("expected the out of date value not to be kept")
+		}
+	})
+}
+
+func TestInvalidate(t *testing.T) {
+	c := New[int, string](Options{})
+	c.Set(1, "one", time.Hour)
+	c.Set(2, "two", time.Hour)
+
+	c.Invalidate(1)
+	c.Invalidate(3)
+
+	if _, ok := c.Get(1); ok {
+		t.Error("expected 1 to be discarded")
+	}
+	if v, ok := c.Get(2); !ok || v != "two" {
+		t.Errorf("expected 2 to be kept, got %q, %v", v, ok)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	c := New[string, int](Options{MaxEntries: 3})
+	keys := func() []string {
+		var got []string
+		for el := c.order.Front(); el != nil; el = el.Next() {
+			got = append(got, el.Value.(*entry[string, int]).key)
+		}
+		return got
+	}
+
+	c.Set("a", 1, time.Hour)
+	c.Set("b", 2, time.Hour)
+	c.Set("c", 3, time.Hour)
+	// Reading a makes b the least recently used
+	c.Get("a")
+	c.Set("d", 4, time.Hour)
+
+	if got := keys(); !slices.Equal(got, []string{"d", "a", "c"}) {
+		t.Fatalf("expected b evicted, leaving d, a, c, got %v", got)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+
+	// Replacing c counts as using it, so a goes next
+	c.Set("c", 30, time.Hour)
+	c.Set("e", 5, time.Hour)
+	if got := keys(); !slices.Equal(got, []string{"e", "c", "d"}) {
+		t.Errorf("expected a evicted, leaving e, c, d, got %v", got)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	clock := newClock()
+	c := New[string, int](Options{Clock: clock, JanitorInterval: time.Millisecond})
+	defer c.Close()
+
+	c.Set("a", 1, time.Second)
+	clock.Add(time.Minute)
+
+	deadline := time.Now().Add(time.Second)
+	for c.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the janitor to remove the expired entry")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	c.Close()
+	c.Close()
+}
@@ -0,0 +1,640 @@
+// Package config loads application settings from environment variables.
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"firecrest/internal/mail"
+	"firecrest/internal/secret"
+	"firecrest/internal/session"
+	"firecrest/internal/storage"
+	"firecrest/internal/token"
+	"firecrest/internal/tracing"
+)
+
+// Environments recognised by APP_ENV.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Blob stores recognised by STORAGE_DRIVER.
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+// What AUTH_SESSION_POLICY does with a sign-in past AUTH_MAX_SESSIONS.
+const (
+	SessionPolicyEvict  = "evict"
+	SessionPolicyReject = "reject"
+)
+
+// Where SESSION_BACKEND keeps sessions.
+const (
+	SessionBackendPostgres = "postgres"
+	SessionBackendCookie   = "cookie"
+)
+
+// MinProductionBcryptCost is the lowest PASSWORD_BCRYPT_COST allowed in
+// production. Development may go as low as bcrypt.MinCost to keep sign-ups
+// fast.
+const MinProductionBcryptCost = 10
+
+// Config holds all application settings.
+type Config struct {
+	Env     string
+	BaseURL string
+	// PublicBaseURL roots the links search engines are given in the
+	// sitemap and robots.txt.
+	PublicBaseURL string
+	DB            DBConfig
+	// DBAutoMigrate applies pending schema migrations at startup. It is on
+	// by default in development only; production deployments run
+	// cmd/migrate before starting the server.
+	DBAutoMigrate bool
+	// DBQueryTimeoutMS bounds each database call, so a slow or unreachable
+	// database fails requests rather than holding them open.
+	DBQueryTimeoutMS int
+	// SMTP is the outgoing mail relay. An empty Host means email is logged
+	// to the console instead of sent.
+	SMTP mail.SMTPConfig
+
+	// Storage is where uploaded files, such as event photos, are kept.
+	Storage StorageConfig
+
+	// TokenSecret signs the tokens in emailed links. In development an
+	// empty secret means a random one is generated at startup.
+	TokenSecret string
+
+	// AnswersKey encrypts entrants' questionnaire answers, such as their
+	// medical conditions, before they are stored. ANSWERS_KEY gives it
+	// base64-encoded. In development an empty key means a random one is
+	// generated at startup.
+	AnswersKey []byte
+
+	// MetricsAddr, when set, serves /metrics on its own listener so it can
+	// be kept off the public port. Empty serves it alongside the app.
+	MetricsAddr string
+
+	// StripeWebhookSecret is the signing secret of the Stripe webhook
+	// endpoint that feeds the payments report. Empty leaves the endpoint
+	// unserved.
+	StripeWebhookSecret string
+
+	// Tracing sends OpenTelemetry traces of requests, service calls and
+	// queries to a collector. With no endpoint nothing is traced.
+	Tracing tracing.Config
+
+	// PasswordBcryptCost is the bcrypt cost passwords are hashed with.
+	PasswordBcryptCost int
+
+	// AuthMaxAttempts is how many failed sign-ins in a row lock an account,
+	// for AuthLockoutMinutes. With AuthProgressiveDelays set, the third
+	// and later failures are answered more slowly before the lock.
+	AuthMaxAttempts       int
+	AuthLockoutMinutes    int
+	AuthProgressiveDelays bool
+	// AuthVerifyGraceHours is how long after signing up users may sign in
+	// without verifying their email address. Until they verify they cannot
+	// enter races.
+	AuthVerifyGraceHours int
+	// AuthMaxSessions is how many sessions each account may have at once;
+	// zero allows any number. AuthSessionPolicy says what a sign-in past
+	// the limit does: SessionPolicyEvict signs out the account's least
+	// recently active session, SessionPolicyReject refuses the sign-in.
+	AuthMaxSessions   int
+	AuthSessionPolicy string
+
+	// Server bounds how long the HTTP server gives each connection.
+	Server ServerConfig
+
+	// Session sets how long sign-ins last.
+	Session SessionConfig
+
+	// CancellationGraceHours is how long after a race's registration closes
+	// entrants may still cancel.
+	CancellationGraceHours int
+
+	// TransferCutoffHours is how long before a race's registration closes
+	// entrants stop being able to transfer their place to someone else.
+	TransferCutoffHours int
+
+	// RegistrationEditLockHours is how long before a race starts entrants
+	// stop being able to change their registration details.
+	RegistrationEditLockHours int
+
+	// ImportMaxRows is the most entrants or results an organiser may
+	// upload in one file.
+	ImportMaxRows int
+
+	// PendingRegistrationTTLHours is how long an unpaid registration holds
+	// its place before it is cancelled.
+	PendingRegistrationTTLHours int
+
+	// TeamFillHours is how long a team has for its members to join before
+	// the places it holds for the rest are released.
+	TeamFillHours int
+
+	// BibReservedFrom and BibReservedTo bound the bib numbers left out when
+	// entrants are numbered, kept for organisers to hand out themselves.
+	// Both zero reserves none.
+	BibReservedFrom int
+	BibReservedTo   int
+
+	// TrustedProxies are the networks of the reverse proxies in front of
+	// the app. Only requests from them may name the client with
+	// X-Forwarded-For or X-Real-IP.
+	TrustedProxies []netip.Prefix
+
+	// UseMockData shows fixture events on the home and event pages instead
+	// of the database's. It is refused outside development.
+	UseMockData bool
+
+	// StaticDir, when set, serves static files from that directory on disk
+	// instead of the copies embedded in the binary, so edits show without
+	// rebuilding. It is refused outside development.
+	StaticDir string
+}
+
+// DBConfig holds the PostgreSQL connection settings.
+type DBConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+
+	// MaxConns and MinConns bound the connection pool. MinConns are kept
+	// open even when idle.
+	MaxConns int
+	MinConns int
+	// MaxConnLifetime is how long a connection is used before it is
+	// replaced, so connections move to a failed-over server in time.
+	MaxConnLifetime time.Duration
+	// ConnectTimeout bounds each attempt to reach the database.
+	ConnectTimeout time.Duration
+	// ConnectRetries is how many more times startup tries to reach the
+	// database after the first attempt fails, backing off between them.
+	ConnectRetries int
+}
+
+// ServerConfig holds the HTTP server's timeouts.
+type ServerConfig struct {
+	// ReadTimeout bounds reading a request, body included, and
+	// WriteTimeout the time from the end of its headers to the end of the
+	// response, so must be the longer.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// IdleTimeout is how long a kept-alive connection waits for its next
+	// request.
+	IdleTimeout time.Duration
+	// ShutdownTimeout bounds how long requests in flight, and traces still
+	// buffered, may take to finish once the server is asked to stop.
+	ShutdownTimeout time.Duration
+}
+
+// SessionConfig holds how long sign-ins last and where they are kept.
+type SessionConfig struct {
+	// Lifetime is how long a sign-in lasts, and RememberLifetime how long
+	// one lasts with "remember me".
+	Lifetime         time.Duration
+	RememberLifetime time.Duration
+	// Backend is SessionBackendPostgres, keeping sessions in the database,
+	// or SessionBackendCookie, keeping them in a signed, encrypted cookie.
+	Backend string
+	// Key seals the cookie backend's sessions. SESSION_KEY gives it
+	// base64-encoded. In development an empty key means a random one is
+	// generated at startup.
+	Key []byte
+}
+
+// StorageConfig selects the blob store uploads are kept in.
+type StorageConfig struct {
+	// Driver is StorageLocal or StorageS3.
+	Driver string
+	// Dir is the directory the local store keeps files under.
+	Dir string
+	// S3 is the bucket the S3 store keeps files in.
+	S3 storage.S3Config
+}
+
+// Load reads the configuration from the environment, applying defaults
+// suitable for local development, and validates it. The error lists every
+// problem found, both values that do not parse and settings that fail
+// Validate.
+func Load() (Config, error) {
+	cfg, err := Read()
+	if err := errors.Join(err, cfg.Validate()); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Read reads the configuration from the environment like Load, but without
+// validating it. Values that do not parse are reported in the error and
+// left at their defaults, so the rest of the configuration is still usable.
+func Read() (Config, error) {
+	var errs []error
+	getInt := func(key string, defaultValue int) int {
+		n, err := getEnvInt(key, defaultValue)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return n
+	}
+	getBool := func(key string, defaultValue bool) bool {
+		b, err := getEnvBool(key, defaultValue)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return b
+	}
+	getPrefixes := func(key string) []netip.Prefix {
+		prefixes, err := getEnvPrefixes(key)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return prefixes
+	}
+	getFloat := func(key string, defaultValue float64) float64 {
+		f, err := getEnvFloat(key, defaultValue)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return f
+	}
+	getDuration := func(key string, defaultValue time.Duration) time.Duration {
+		d, err := getEnvDuration(key, defaultValue)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return d
+	}
+	getKey := func(key string) []byte {
+		b, err := getEnvBase64(key)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return b
+	}
+
+	env := getEnv("APP_ENV", EnvDevelopment)
+	cfg := Config{
+		Env:           env,
+		BaseURL:       getEnv("BASE_URL", "http://localhost:8080"),
+		PublicBaseURL: getEnv("PUBLIC_BASE_URL", "http://localhost:8080"),
+		DB: DBConfig{
+			Host:     getEnv("DB_HOST", "localhost"),
+			Port:     getEnv("DB_PORT", "5432"),
+			User:     getEnv("DB_USER", "postgres"),
+			Password: getEnv("DB_PASSWORD", "postgres"),
+			Name:     getEnv("DB_NAME", "firecrest"),
+			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			MaxConns:        getInt("DB_MAX_CONNS", 10),
+			MinConns:        getInt("DB_MIN_CONNS", 0),
+			MaxConnLifetime: getDuration("DB_MAX_CONN_LIFETIME", time.Hour),
+			ConnectTimeout:  getDuration("DB_CONNECT_TIMEOUT", 5*time.Second),
+			ConnectRetries:  getInt("DB_CONNECT_RETRIES", 5),
+		},
+		DBAutoMigrate:    getBool("DB_AUTO_MIGRATE", env == EnvDevelopment),
+		DBQueryTimeoutMS: getInt("DB_QUERY_TIMEOUT_MS", 3000),
+		SMTP: mail.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     getInt("SMTP_PORT", 587),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     getEnv("SMTP_FROM", "Firecrest <no-reply@localhost>"),
+		},
+		Storage: StorageConfig{
+			Driver: getEnv("STORAGE_DRIVER", StorageLocal),
+			Dir:    getEnv("STORAGE_DIR", "uploads"),
+			S3: storage.S3Config{
+				Endpoint:        os.Getenv("S3_ENDPOINT"),
+				Region:          os.Getenv("S3_REGION"),
+				Bucket:          os.Getenv("S3_BUCKET"),
+				AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+				SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+				PathStyle:       getBool("S3_PATH_STYLE", false),
+			},
+		},
+		Server: ServerConfig{
+			ReadTimeout:     getDuration("SERVER_READ_TIMEOUT", 5*time.Second),
+			WriteTimeout:    getDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			IdleTimeout:     getDuration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+			ShutdownTimeout: getDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+		},
+		Session: SessionConfig{
+			Lifetime: getDuration("SESSION_LIFETIME", 12*time.Hour),
+			// SESSION_REMEMBER_LIFETIME_HRS, in hours, is still read when
+			// the duration is not given
+			RememberLifetime: getDuration("SESSION_REMEMBER_LIFETIME", time.Duration(getInt("SESSION_REMEMBER_LIFETIME_HRS", 30*24))*time.Hour),
+			Backend:          getEnv("SESSION_BACKEND", SessionBackendPostgres),
+			Key:              getKey("SESSION_KEY"),
+		},
+		Tracing: tracing.Config{
+			Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+			SampleRatio: getFloat("OTEL_TRACES_SAMPLER_ARG", 1),
+			ServiceName: getEnv("OTEL_SERVICE_NAME", "firecrest"),
+		},
+		TokenSecret:            os.Getenv("TOKEN_SECRET"),
+		AnswersKey:             getKey("ANSWERS_KEY"),
+		PasswordBcryptCost:     getInt("PASSWORD_BCRYPT_COST", 12),
+		AuthMaxAttempts:        getInt("AUTH_MAX_ATTEMPTS", 5),
+		AuthLockoutMinutes:     getInt("AUTH_LOCKOUT_MINUTES", 15),
+		AuthProgressiveDelays:  getBool("AUTH_PROGRESSIVE_DELAYS", false),
+		AuthVerifyGraceHours:   getInt("AUTH_VERIFY_GRACE_HOURS", 72),
+		AuthMaxSessions:        getInt("AUTH_MAX_SESSIONS", 0),
+		AuthSessionPolicy:      getEnv("AUTH_SESSION_POLICY", SessionPolicyEvict),
+		MetricsAddr:            os.Getenv("METRICS_ADDR"),
+		StripeWebhookSecret:    os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		CancellationGraceHours: getInt("CANCELLATION_GRACE_HOURS", 0),
+		TransferCutoffHours:    getInt("TRANSFER_CUTOFF_HOURS", 7*24),
+
+		RegistrationEditLockHours:   getInt("REGISTRATION_EDIT_LOCK_HOURS", 7*24),
+		ImportMaxRows:               getInt("IMPORT_MAX_ROWS", 10000),
+		PendingRegistrationTTLHours: getInt("PENDING_REGISTRATION_TTL_HOURS", 24),
+		TeamFillHours:               getInt("TEAM_FILL_HOURS", 72),
+		BibReservedFrom:             getInt("BIB_RESERVED_FROM", 0),
+		BibReservedTo:               getInt("BIB_RESERVED_TO", 0),
+		TrustedProxies:              getPrefixes("TRUSTED_PROXIES"),
+		UseMockData:                 getBool("USE_MOCK_DATA", false),
+		StaticDir:                   getEnv("STATIC_DIR", ""),
+	}
+
+	return cfg, errors.Join(errs...)
+}
+
+// Validate checks that the configuration is usable.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		errs = append(errs, fmt.Errorf("APP_ENV must be %q or %q, got %q", EnvDevelopment, EnvProduction, c.Env))
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("BASE_URL must be an absolute URL, got %q", c.BaseURL))
+	}
+	if u, err := url.Parse(c.PublicBaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("PUBLIC_BASE_URL must be an absolute URL, got %q", c.PublicBaseURL))
+	}
+	if c.DBQueryTimeoutMS < 1 {
+		errs = append(errs, fmt.Errorf("DB_QUERY_TIMEOUT_MS must be positive, got %d", c.DBQueryTimeoutMS))
+	}
+	if c.DB.MaxConns < 1 {
+		errs = append(errs, fmt.Errorf("DB_MAX_CONNS must be positive, got %d", c.DB.MaxConns))
+	}
+	if c.DB.MinConns < 0 || c.DB.MinConns > c.DB.MaxConns {
+		errs = append(errs, fmt.Errorf("DB_MIN_CONNS must be between 0 and DB_MAX_CONNS, got %d", c.DB.MinConns))
+	}
+	if c.DB.MaxConnLifetime <= 0 {
+		errs = append(errs, fmt.Errorf("DB_MAX_CONN_LIFETIME must be positive, got %s", c.DB.MaxConnLifetime))
+	}
+	if c.DB.ConnectTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("DB_CONNECT_TIMEOUT must be positive, got %s", c.DB.ConnectTimeout))
+	}
+	if c.DB.ConnectRetries < 0 {
+		errs = append(errs, fmt.Errorf("DB_CONNECT_RETRIES must not be negative, got %d", c.DB.ConnectRetries))
+	}
+	if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+		errs = append(errs, fmt.Errorf("SMTP_PORT must be between 1 and 65535, got %d", c.SMTP.Port))
+	}
+	if !c.IsDevelopment() && c.SMTP.Host == "" {
+		errs = append(errs, errors.New("SMTP_HOST is required in production"))
+	}
+	switch c.Storage.Driver {
+	case StorageLocal:
+	case StorageS3:
+		if c.Storage.S3.Endpoint == "" || c.Storage.S3.Region == "" || c.Storage.S3.Bucket == "" {
+			errs = append(errs, errors.New("S3_ENDPOINT, S3_REGION and S3_BUCKET are required for the s3 storage driver"))
+		}
+		if c.Storage.S3.AccessKeyID == "" || c.Storage.S3.SecretAccessKey == "" {
+			errs = append(errs, errors.New("S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for the s3 storage driver"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_DRIVER must be %q or %q, got %q", StorageLocal, StorageS3, c.Storage.Driver))
+	}
+	if c.Tracing.Enabled() {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT must be an absolute URL, got %q", c.Tracing.Endpoint))
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			errs = append(errs, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1, got %g", c.Tracing.SampleRatio))
+		}
+	}
+	if (c.TokenSecret != "" || !c.IsDevelopment()) && len(c.TokenSecret) < token.MinKeyLength {
+		errs = append(errs, fmt.Errorf("TOKEN_SECRET must be at least %d characters", token.MinKeyLength))
+	}
+	if (len(c.AnswersKey) != 0 || !c.IsDevelopment()) && len(c.AnswersKey) != secret.KeyLength {
+		errs = append(errs, fmt.Errorf("ANSWERS_KEY must be %d bytes, base64-encoded", secret.KeyLength))
+	}
+	if c.PasswordBcryptCost < bcrypt.MinCost || c.PasswordBcryptCost > bcrypt.MaxCost {
+		errs = append(errs, fmt.Errorf("PASSWORD_BCRYPT_COST must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.PasswordBcryptCost))
+	} else if !c.IsDevelopment() && c.PasswordBcryptCost < MinProductionBcryptCost {
+		errs = append(errs, fmt.Errorf("PASSWORD_BCRYPT_COST must be at least %d in production, got %d", MinProductionBcryptCost, c.PasswordBcryptCost))
+	}
+	if c.AuthMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("AUTH_MAX_ATTEMPTS must be positive, got %d", c.AuthMaxAttempts))
+	}
+	if c.AuthLockoutMinutes < 1 {
+		errs = append(errs, fmt.Errorf("AUTH_LOCKOUT_MINUTES must be positive, got %d", c.AuthLockoutMinutes))
+	}
+	if c.AuthVerifyGraceHours < 0 {
+		errs = append(errs, fmt.Errorf("AUTH_VERIFY_GRACE_HOURS must not be negative, got %d", c.AuthVerifyGraceHours))
+	}
+	if c.AuthMaxSessions < 0 {
+		errs = append(errs, fmt.Errorf("AUTH_MAX_SESSIONS must not be negative, got %d", c.AuthMaxSessions))
+	}
+	if c.AuthSessionPolicy != SessionPolicyEvict && c.AuthSessionPolicy != SessionPolicyReject {
+		errs = append(errs, fmt.Errorf("AUTH_SESSION_POLICY must be %q or %q, got %q", SessionPolicyEvict, SessionPolicyReject, c.AuthSessionPolicy))
+	}
+	if c.Server.ReadTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_READ_TIMEOUT must be positive, got %s", c.Server.ReadTimeout))
+	}
+	if c.Server.WriteTimeout <= c.Server.ReadTimeout {
+		errs = append(errs, fmt.Errorf("SERVER_WRITE_TIMEOUT must be longer than SERVER_READ_TIMEOUT, got %s and %s", c.Server.WriteTimeout, c.Server.ReadTimeout))
+	}
+	if c.Server.IdleTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_IDLE_TIMEOUT must be positive, got %s", c.Server.IdleTimeout))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_SHUTDOWN_TIMEOUT must be positive, got %s", c.Server.ShutdownTimeout))
+	}
+	if c.Session.Lifetime <= 0 {
+		errs = append(errs, fmt.Errorf("SESSION_LIFETIME must be positive, got %s", c.Session.Lifetime))
+	}
+	if c.Session.RememberLifetime <= 0 {
+		errs = append(errs, fmt.Errorf("SESSION_REMEMBER_LIFETIME must be positive, got %s", c.Session.RememberLifetime))
+	}
+	switch c.Session.Backend {
+	case SessionBackendPostgres:
+	case SessionBackendCookie:
+		if (len(c.Session.Key) != 0 || !c.IsDevelopment()) && len(c.Session.Key) != session.KeyLength {
+			errs = append(errs, fmt.Errorf("SESSION_KEY must be %d bytes, base64-encoded", session.KeyLength))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("SESSION_BACKEND must be %q or %q, got %q", SessionBackendPostgres, SessionBackendCookie, c.Session.Backend))
+	}
+	if c.CancellationGraceHours < 0 {
+		errs = append(errs, fmt.Errorf("CANCELLATION_GRACE_HOURS must not be negative, got %d", c.CancellationGraceHours))
+	}
+	if c.TransferCutoffHours < 0 {
+		errs = append(errs, fmt.Errorf("TRANSFER_CUTOFF_HOURS must not be negative, got %d", c.TransferCutoffHours))
+	}
+	if c.RegistrationEditLockHours < 0 {
+		errs = append(errs, fmt.Errorf("REGISTRATION_EDIT_LOCK_HOURS must not be negative, got %d", c.RegistrationEditLockHours))
+	}
+	if c.ImportMaxRows < 1 {
+		errs = append(errs, fmt.Errorf("IMPORT_MAX_ROWS must be positive, got %d", c.ImportMaxRows))
+	}
+	if c.PendingRegistrationTTLHours < 1 {
+		errs = append(errs, fmt.Errorf("PENDING_REGISTRATION_TTL_HOURS must be positive, got %d", c.PendingRegistrationTTLHours))
+	}
+	if c.TeamFillHours < 1 {
+		errs = append(errs, fmt.Errorf("TEAM_FILL_HOURS must be positive, got %d", c.TeamFillHours))
+	}
+	if (c.BibReservedFrom != 0 || c.BibReservedTo != 0) && (c.BibReservedFrom < 1 || c.BibReservedTo < c.BibReservedFrom) {
+		errs = append(errs, fmt.Errorf("BIB_RESERVED_FROM and BIB_RESERVED_TO must be a range of positive bibs, got %d to %d", c.BibReservedFrom, c.BibReservedTo))
+	}
+	if c.UseMockData && !c.IsDevelopment() {
+		errs = append(errs, errors.New("USE_MOCK_DATA is only allowed in development"))
+	}
+	if c.StaticDir != "" && !c.IsDevelopment() {
+		errs = append(errs, errors.New("STATIC_DIR is only allowed in development"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsDevelopment reports whether the application is running locally.
+func (c Config) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
+}
+
+// MockDataEnabled reports whether fixture events replace the database's.
+// Production never uses them, whatever USE_MOCK_DATA says.
+func (c Config) MockDataEnabled() bool {
+	return c.IsDevelopment() && c.UseMockData
+}
+
+// DSN returns the PostgreSQL connection string.
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		c.User, c.Password, c.Host, c.Port, c.Name, c.SSLMode)
+}
+
+// getEnv retrieves the value of an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvInt retrieves an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// getEnvBool retrieves a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// getEnvFloat retrieves a decimal environment variable or returns a default
+// value
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// getEnvDuration retrieves a duration environment variable, such as "5s"
+// or "1h", or returns a default value. A bare integer, as settings were
+// once given, is a number of seconds.
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// getEnvBase64 retrieves a base64-encoded environment variable, or nil if
+// it is not set
+func getEnvBase64(key string) ([]byte, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// getEnvPrefixes retrieves a comma-separated list of networks, such as
+// "10.0.0.0/8, fd00::/8". A bare address stands for that single host.
+func getEnvPrefixes(key string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for field := range strings.SplitSeq(os.Getenv(key), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(field); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,387 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("applies development defaults", func(t *testing.T) {
+		for _, key := range []string{"APP_ENV", "BASE_URL", "PUBLIC_BASE_URL", "SMTP_HOST", "SMTP_PORT", "TOKEN_SECRET", "ANSWERS_KEY", "CANCELLATION_GRACE_HOURS", "SESSION_LIFETIME", "SESSION_REMEMBER_LIFETIME", "SESSION_REMEMBER_LIFETIME_HRS", "SESSION_BACKEND", "SESSION_KEY", "SERVER_READ_TIMEOUT", "SERVER_WRITE_TIMEOUT", "SERVER_IDLE_TIMEOUT", "SERVER_SHUTDOWN_TIMEOUT", "IMPORT_MAX_ROWS", "DB_AUTO_MIGRATE", "TRANSFER_CUTOFF_HOURS", "REGISTRATION_EDIT_LOCK_HOURS", "PENDING_REGISTRATION_TTL_HOURS", "TRUSTED_PROXIES", "PASSWORD_BCRYPT_COST", "TEAM_FILL_HOURS", "DB_QUERY_TIMEOUT_MS", "DB_MAX_CONNS", "DB_MIN_CONNS", "DB_MAX_CONN_LIFETIME", "DB_CONNECT_TIMEOUT", "DB_CONNECT_RETRIES", "BIB_RESERVED_FROM", "BIB_RESERVED_TO", "USE_MOCK_DATA", "STATIC_DIR", "STORAGE_DRIVER", "STORAGE_DIR", "AUTH_MAX_ATTEMPTS", "AUTH_LOCKOUT_MINUTES", "AUTH_PROGRESSIVE_DELAYS", "AUTH_VERIFY_GRACE_HOURS", "AUTH_MAX_SESSIONS", "AUTH_SESSION_POLICY", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_TRACES_SAMPLER_ARG", "OTEL_SERVICE_NAME"} {
+			t.Setenv(key, "")
+		}
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cfg.IsDevelopment() {
+			t.Errorf("expected development environment, got %q", cfg.Env)
+		}
+		if cfg.BaseURL != "http://localhost:8080" {
+			t.Errorf("expected default base URL, got %q", cfg.BaseURL)
+		}
+		if cfg.PublicBaseURL != "http://localhost:8080" {
+			t.Errorf("expected default public base URL, got %q", cfg.PublicBaseURL)
+		}
+		if cfg.SMTP.Port != 587 {
+			t.Errorf("expected default SMTP port 587, got %d", cfg.SMTP.Port)
+		}
+		if cfg.ImportMaxRows != 10000 {
+			t.Errorf("expected default import limit of 10000 rows, got %d", cfg.ImportMaxRows)
+		}
+		if cfg.Session.Lifetime != 12*time.Hour || cfg.Session.RememberLifetime != 720*time.Hour {
+			t.Errorf("expected sessions of 12 hours, or 720 remembered, by default, got %+v", cfg.Session)
+		}
+		if cfg.Session.Backend != SessionBackendPostgres {
+			t.Errorf("expected sessions kept in postgres by default, got %q", cfg.Session.Backend)
+		}
+		if want := (ServerConfig{ReadTimeout: 5 * time.Second, WriteTimeout: 10 * time.Second, IdleTimeout: 2 * time.Minute, ShutdownTimeout: 30 * time.Second}); cfg.Server != want {
+			t.Errorf("unexpected server defaults: %+v", cfg.Server)
+		}
+		if !cfg.DBAutoMigrate {
+			t.Error("expected migrations to run at startup by default in development")
+		}
+		if cfg.DBQueryTimeoutMS != 3000 {
+			t.Errorf("expected database calls to time out after 3000ms by default, got %d", cfg.DBQueryTimeoutMS)
+		}
+		if cfg.DB.MaxConns != 10 || cfg.DB.MinConns != 0 {
+			t.Errorf("expected a pool of up to 10 connections by default, got %d to %d", cfg.DB.MinConns, cfg.DB.MaxConns)
+		}
+		if cfg.DB.MaxConnLifetime != time.Hour || cfg.DB.ConnectTimeout != 5*time.Second || cfg.DB.ConnectRetries != 5 {
+			t.Errorf("unexpected connection defaults: %+v", cfg.DB)
+		}
+		if cfg.TransferCutoffHours != 168 {
+			t.Errorf("expected default transfer cutoff of 168 hours, got %d", cfg.TransferCutoffHours)
+		}
+		if cfg.RegistrationEditLockHours != 168 {
+			t.Errorf("expected default edit lock of 168 hours, got %d", cfg.RegistrationEditLockHours)
+		}
+		if cfg.PendingRegistrationTTLHours != 24 {
+			t.Errorf("expected pending registrations to expire after 24 hours by default, got %d", cfg.PendingRegistrationTTLHours)
+		}
+		if len(cfg.TrustedProxies) != 0 {
+			t.Errorf("expected no trusted proxies by default, got %v", cfg.TrustedProxies)
+		}
+		if cfg.TeamFillHours != 72 {
+			t.Errorf("expected teams to have 72 hours to fill by default, got %d", cfg.TeamFillHours)
+		}
+		if cfg.BibReservedFrom != 0 || cfg.BibReservedTo != 0 {
+			t.Errorf("expected no reserved bibs by default, got %d to %d", cfg.BibReservedFrom, cfg.BibReservedTo)
+		}
+		if cfg.PasswordBcryptCost != 12 {
+			t.Errorf("expected default bcrypt cost of 12, got %d", cfg.PasswordBcryptCost)
+		}
+		if cfg.AuthMaxAttempts != 5 || cfg.AuthLockoutMinutes != 15 || cfg.AuthProgressiveDelays {
+			t.Errorf("expected a 15 minute lock after 5 attempts without delays by default, got %d, %d, %v", cfg.AuthMaxAttempts, cfg.AuthLockoutMinutes, cfg.AuthProgressiveDelays)
+		}
+		if cfg.AuthVerifyGraceHours != 72 {
+			t.Errorf("expected 72 hours to verify an email by default, got %d", cfg.AuthVerifyGraceHours)
+		}
+		if cfg.AuthMaxSessions != 0 || cfg.AuthSessionPolicy != SessionPolicyEvict {
+			t.Errorf("expected any number of sessions, evicting if limited, by default, got %d, %q", cfg.AuthMaxSessions, cfg.AuthSessionPolicy)
+		}
+		if cfg.MockDataEnabled() {
+			t.Error("expected the database's events to be shown by default")
+		}
+		if cfg.Storage.Driver != StorageLocal || cfg.Storage.Dir != "uploads" {
+			t.Errorf("expected uploads on disk by default, got %+v", cfg.Storage)
+		}
+		if cfg.Tracing.Enabled() || cfg.Tracing.SampleRatio != 1 || cfg.Tracing.ServiceName != "firecrest" {
+			t.Errorf("expected tracing off, keeping every trace once enabled, by default, got %+v", cfg.Tracing)
+		}
+	})
+
+	t.Run("allows a low bcrypt cost in development", func(t *testing.T) {
+		t.Setenv("APP_ENV", "development")
+		t.Setenv("TOKEN_SECRET", "")
+		t.Setenv("PASSWORD_BCRYPT_COST", "4")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.PasswordBcryptCost != 4 {
+			t.Errorf("expected bcrypt cost 4, got %d", cfg.PasswordBcryptCost)
+		}
+	})
+
+	t.Run("leaves migrations to cmd/migrate in production", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("DB_AUTO_MIGRATE", "")
+
+		cfg, err := Read()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.DBAutoMigrate {
+			t.Error("expected migrations not to run at startup by default in production")
+		}
+	})
+
+	t.Run("reads values from the environment", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("BASE_URL", "https://firecrest.example")
+		t.Setenv("PUBLIC_BASE_URL", "https://www.firecrest.example")
+		t.Setenv("SMTP_HOST", "smtp.example.com")
+		t.Setenv("SMTP_PORT", "2525")
+		t.Setenv("DB_HOST", "db")
+		t.Setenv("DB_MAX_CONNS", "20")
+		t.Setenv("DB_MIN_CONNS", "2")
+		t.Setenv("DB_MAX_CONN_LIFETIME", "30m")
+		t.Setenv("DB_CONNECT_TIMEOUT", "2s")
+		t.Setenv("DB_CONNECT_RETRIES", "10")
+		t.Setenv("SERVER_READ_TIMEOUT", "500ms")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "1m")
+		t.Setenv("SERVER_IDLE_TIMEOUT", "90s")
+		t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "45s")
+		t.Setenv("SESSION_LIFETIME", "8h")
+		t.Setenv("SESSION_REMEMBER_LIFETIME", "336h")
+		t.Setenv("SESSION_BACKEND", "cookie")
+		t.Setenv("SESSION_KEY", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("c", 32))))
+		t.Setenv("TOKEN_SECRET", strings.Repeat("s", 32))
+		t.Setenv("ANSWERS_KEY", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+		t.Setenv("CANCELLATION_GRACE_HOURS", "48")
+		t.Setenv("DB_AUTO_MIGRATE", "true")
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.7,fd00::/8")
+		t.Setenv("PASSWORD_BCRYPT_COST", "14")
+		t.Setenv("AUTH_MAX_ATTEMPTS", "10")
+		t.Setenv("AUTH_LOCKOUT_MINUTES", "60")
+		t.Setenv("AUTH_PROGRESSIVE_DELAYS", "true")
+		t.Setenv("AUTH_VERIFY_GRACE_HOURS", "0")
+		t.Setenv("AUTH_MAX_SESSIONS", "2")
+		t.Setenv("AUTH_SESSION_POLICY", "reject")
+		t.Setenv("STORAGE_DRIVER", "s3")
+		t.Setenv("S3_ENDPOINT", "http://minio:9000")
+		t.Setenv("S3_REGION", "eu-west-2")
+		t.Setenv("S3_BUCKET", "firecrest")
+		t.Setenv("S3_ACCESS_KEY_ID", "key-id")
+		t.Setenv("S3_SECRET_ACCESS_KEY", "secret")
+		t.Setenv("S3_PATH_STYLE", "true")
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+		t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+		t.Setenv("OTEL_SERVICE_NAME", "firecrest-web")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.PublicBaseURL != "https://www.firecrest.example" {
+			t.Errorf("expected PUBLIC_BASE_URL to be read, got %q", cfg.PublicBaseURL)
+		}
+		if cfg.SMTP.Host != "smtp.example.com" || cfg.SMTP.Port != 2525 {
+			t.Errorf("unexpected SMTP config: %+v", cfg.SMTP)
+		}
+		if string(cfg.AnswersKey) != strings.Repeat("k", 32) {
+			t.Errorf("expected ANSWERS_KEY to be decoded, got %q", cfg.AnswersKey)
+		}
+		if s3 := cfg.Storage.S3; cfg.Storage.Driver != StorageS3 || s3.Endpoint != "http://minio:9000" || s3.Bucket != "firecrest" || s3.SecretAccessKey != "secret" || !s3.PathStyle {
+			t.Errorf("unexpected storage config: %+v", cfg.Storage)
+		}
+		if tr := cfg.Tracing; !tr.Enabled() || tr.Endpoint != "http://collector:4318" || tr.SampleRatio != 0.25 || tr.ServiceName != "firecrest-web" {
+			t.Errorf("unexpected tracing config: %+v", tr)
+		}
+		if want := (ServerConfig{ReadTimeout: 500 * time.Millisecond, WriteTimeout: time.Minute, IdleTimeout: 90 * time.Second, ShutdownTimeout: 45 * time.Second}); cfg.Server != want {
+			t.Errorf("unexpected server config: %+v", cfg.Server)
+		}
+		if cfg.Session.Lifetime != 8*time.Hour || cfg.Session.RememberLifetime != 14*24*time.Hour || cfg.Session.Backend != SessionBackendCookie {
+			t.Errorf("unexpected session config: %+v", cfg.Session)
+		}
+		if string(cfg.Session.Key) != strings.Repeat("c", 32) {
+			t.Errorf("expected SESSION_KEY to be decoded, got %q", cfg.Session.Key)
+		}
+		if cfg.CancellationGraceHours != 48 {
+			t.Errorf("expected 48 grace hours, got %d", cfg.CancellationGraceHours)
+		}
+		if !cfg.DBAutoMigrate {
+			t.Error("expected DB_AUTO_MIGRATE to enable migrations at startup")
+		}
+		if cfg.AuthMaxAttempts != 10 || cfg.AuthLockoutMinutes != 60 || !cfg.AuthProgressiveDelays {
+			t.Errorf("unexpected lockout config: %d, %d, %v", cfg.AuthMaxAttempts, cfg.AuthLockoutMinutes, cfg.AuthProgressiveDelays)
+		}
+		if cfg.AuthMaxSessions != 2 || cfg.AuthSessionPolicy != SessionPolicyReject {
+			t.Errorf("unexpected session limit: %d, %q", cfg.AuthMaxSessions, cfg.AuthSessionPolicy)
+		}
+		if cfg.AuthVerifyGraceHours != 0 {
+			t.Errorf("expected no time to verify an email, got %d", cfg.AuthVerifyGraceHours)
+		}
+		if cfg.PasswordBcryptCost != 14 {
+			t.Errorf("expected bcrypt cost 14, got %d", cfg.PasswordBcryptCost)
+		}
+		if got := fmt.Sprint(cfg.TrustedProxies); got != "[10.0.0.0/8 192.168.1.7/32 fd00::/8]" {
+			t.Errorf("unexpected trusted proxies: %s", got)
+		}
+		if cfg.DB.MaxConns != 20 || cfg.DB.MinConns != 2 || cfg.DB.MaxConnLifetime != 30*time.Minute || cfg.DB.ConnectTimeout != 2*time.Second || cfg.DB.ConnectRetries != 10 {
+			t.Errorf("unexpected pool config: %+v", cfg.DB)
+		}
+		if !strings.Contains(cfg.DB.DSN(), "@db:5432/") {
+			t.Errorf("expected DSN to use DB_HOST, got %q", cfg.DB.DSN())
+		}
+	})
+
+	t.Run("reads bare integers as seconds", func(t *testing.T) {
+		t.Setenv("SERVER_READ_TIMEOUT", "3")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "20")
+		t.Setenv("SESSION_LIFETIME", "3600")
+		t.Setenv("DB_CONNECT_TIMEOUT", "2")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Server.ReadTimeout != 3*time.Second || cfg.Server.WriteTimeout != 20*time.Second {
+			t.Errorf("unexpected server config: %+v", cfg.Server)
+		}
+		if cfg.Session.Lifetime != time.Hour || cfg.DB.ConnectTimeout != 2*time.Second {
+			t.Errorf("expected one hour sessions and two second connects, got %s and %s", cfg.Session.Lifetime, cfg.DB.ConnectTimeout)
+		}
+	})
+
+	t.Run("reads the remember-me lifetime in hours when no duration is given", func(t *testing.T) {
+		t.Setenv("SESSION_REMEMBER_LIFETIME", "")
+		t.Setenv("SESSION_REMEMBER_LIFETIME_HRS", "48")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Session.RememberLifetime != 48*time.Hour {
+			t.Errorf("expected 48 hours, got %s", cfg.Session.RememberLifetime)
+		}
+
+		t.Setenv("SESSION_REMEMBER_LIFETIME", "24h")
+		if cfg, err = Load(); err != nil || cfg.Session.RememberLifetime != 24*time.Hour {
+			t.Errorf("expected SESSION_REMEMBER_LIFETIME to win, got %s (err %v)", cfg.Session.RememberLifetime, err)
+		}
+	})
+
+	t.Run("reports every problem at once", func(t *testing.T) {
+		t.Setenv("DB_MAX_CONNS", "many")
+		t.Setenv("SMTP_PORT", "70000")
+		t.Setenv("TOKEN_SECRET", "secret")
+
+		_, err := Load()
+
+		for _, want := range []string{"invalid DB_MAX_CONNS", "SMTP_PORT must be", "TOKEN_SECRET must be"} {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("expected an error mentioning %q, got %v", want, err)
+			}
+		}
+		if strings.Contains(err.Error(), "DB_MAX_CONNS must be positive") {
+			t.Errorf("expected the unparseable DB_MAX_CONNS to keep its default, got %v", err)
+		}
+	})
+
+	t.Run("reads a config that does not validate", func(t *testing.T) {
+		t.Setenv("DB_HOST", "db.internal")
+		t.Setenv("DB_MAX_CONNS", "many")
+		t.Setenv("APP_ENV", "staging")
+
+		cfg, err := Read()
+		if err == nil || !strings.Contains(err.Error(), "DB_MAX_CONNS") {
+			t.Errorf("expected a DB_MAX_CONNS error, got %v", err)
+		}
+		if cfg.DB.Host != "db.internal" || cfg.DB.MaxConns != 10 {
+			t.Errorf("expected the host read and the pool size defaulted, got %+v", cfg.DB)
+		}
+		if cfg.Validate() == nil {
+			t.Error("expected the unknown environment to fail validation")
+		}
+	})
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "rejects non-numeric ports", env: map[string]string{"SMTP_PORT": "smtp"}, want: "SMTP_PORT"},
+		{name: "rejects non-boolean auto migrate", env: map[string]string{"DB_AUTO_MIGRATE": "sometimes"}, want: "DB_AUTO_MIGRATE"},
+		{name: "rejects a zero query timeout", env: map[string]string{"DB_QUERY_TIMEOUT_MS": "0"}, want: "DB_QUERY_TIMEOUT_MS"},
+		{name: "rejects empty pools", env: map[string]string{"DB_MAX_CONNS": "0"}, want: "DB_MAX_CONNS"},
+		{name: "rejects more idle connections than the pool holds", env: map[string]string{"DB_MAX_CONNS": "4", "DB_MIN_CONNS": "5"}, want: "DB_MIN_CONNS"},
+		{name: "rejects malformed connection lifetimes", env: map[string]string{"DB_MAX_CONN_LIFETIME": "an hour"}, want: "DB_MAX_CONN_LIFETIME"},
+		{name: "rejects a zero connect timeout", env: map[string]string{"DB_CONNECT_TIMEOUT": "0s"}, want: "DB_CONNECT_TIMEOUT"},
+		{name: "rejects negative connect retries", env: map[string]string{"DB_CONNECT_RETRIES": "-1"}, want: "DB_CONNECT_RETRIES"},
+		{name: "rejects out of range ports", env: map[string]string{"SMTP_PORT": "70000"}, want: "SMTP_PORT"},
+		{name: "rejects unknown environments", env: map[string]string{"APP_ENV": "staging"}, want: "APP_ENV"},
+		{name: "rejects relative base URLs", env: map[string]string{"BASE_URL": "/events"}, want: "BASE_URL"},
+		{name: "rejects relative public base URLs", env: map[string]string{"PUBLIC_BASE_URL": "www.firecrest.example"}, want: "PUBLIC_BASE_URL"},
+		{name: "requires SMTP in production", env: map[string]string{"APP_ENV": "production", "SMTP_HOST": ""}, want: "SMTP_HOST"},
+		{name: "requires a token secret in production", env: map[string]string{"APP_ENV": "production", "SMTP_HOST": "smtp.example.com", "TOKEN_SECRET": ""}, want: "TOKEN_SECRET"},
+		{name: "rejects short token secrets", env: map[string]string{"TOKEN_SECRET": "secret"}, want: "TOKEN_SECRET"},
+		{name: "requires an answers key in production", env: map[string]string{"APP_ENV": "production", "SMTP_HOST": "smtp.example.com", "TOKEN_SECRET": strings.Repeat("s", 32), "ANSWERS_KEY": ""}, want: "ANSWERS_KEY"},
+		{name: "rejects answers keys that are not base64", env: map[string]string{"ANSWERS_KEY": "not base64!"}, want: "ANSWERS_KEY"},
+		{name: "rejects short answers keys", env: map[string]string{"ANSWERS_KEY": base64.StdEncoding.EncodeToString([]byte("short"))}, want: "ANSWERS_KEY"},
+		{name: "rejects non-positive remember-me lifetimes", env: map[string]string{"SESSION_REMEMBER_LIFETIME": "0s"}, want: "SESSION_REMEMBER_LIFETIME"},
+		{name: "rejects non-positive remember-me lifetimes in hours", env: map[string]string{"SESSION_REMEMBER_LIFETIME": "", "SESSION_REMEMBER_LIFETIME_HRS": "0"}, want: "SESSION_REMEMBER_LIFETIME"},
+		{name: "rejects unknown session backends", env: map[string]string{"SESSION_BACKEND": "redis"}, want: "SESSION_BACKEND"},
+		{name: "requires a session key for cookie sessions in production", env: map[string]string{"APP_ENV": "production", "SMTP_HOST": "smtp.example.com", "TOKEN_SECRET": strings.Repeat("s", 32), "SESSION_BACKEND": "cookie", "SESSION_KEY": ""}, want: "SESSION_KEY"},
+		{name: "rejects short session keys", env: map[string]string{"SESSION_BACKEND": "cookie", "SESSION_KEY": base64.StdEncoding.EncodeToString([]byte("short"))}, want: "SESSION_KEY"},
+		{name: "rejects malformed session lifetimes", env: map[string]string{"SESSION_LIFETIME": "12 hours"}, want: "SESSION_LIFETIME"},
+		{name: "rejects malformed server timeouts", env: map[string]string{"SERVER_READ_TIMEOUT": "5 seconds"}, want: "SERVER_READ_TIMEOUT"},
+		{name: "rejects fractional seconds without a unit", env: map[string]string{"SERVER_IDLE_TIMEOUT": "1.5"}, want: "SERVER_IDLE_TIMEOUT"},
+		{name: "rejects write timeouts no longer than read timeouts", env: map[string]string{"SERVER_READ_TIMEOUT": "10s", "SERVER_WRITE_TIMEOUT": "10s"}, want: "SERVER_WRITE_TIMEOUT"},
+		{name: "rejects a zero shutdown timeout", env: map[string]string{"SERVER_SHUTDOWN_TIMEOUT": "0"}, want: "SERVER_SHUTDOWN_TIMEOUT"},
+		{name: "rejects non-positive import limits", env: map[string]string{"IMPORT_MAX_ROWS": "0"}, want: "IMPORT_MAX_ROWS"},
+		{name: "rejects negative grace periods", env: map[string]string{"CANCELLATION_GRACE_HOURS": "-1"}, want: "CANCELLATION_GRACE_HOURS"},
+		{name: "rejects negative transfer cutoffs", env: map[string]string{"TRANSFER_CUTOFF_HOURS": "-1"}, want: "TRANSFER_CUTOFF_HOURS"},
+		{name: "rejects negative edit locks", env: map[string]string{"REGISTRATION_EDIT_LOCK_HOURS": "-1"}, want: "REGISTRATION_EDIT_LOCK_HOURS"},
+		{name: "rejects malformed trusted proxies", env: map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,proxy.internal"}, want: "TRUSTED_PROXIES"},
+		{name: "rejects lockouts without attempts", env: map[string]string{"AUTH_MAX_ATTEMPTS": "0"}, want: "AUTH_MAX_ATTEMPTS"},
+		{name: "rejects lockouts of no time", env: map[string]string{"AUTH_LOCKOUT_MINUTES": "0"}, want: "AUTH_LOCKOUT_MINUTES"},
+		{name: "rejects negative session limits", env: map[string]string{"AUTH_MAX_SESSIONS": "-1"}, want: "AUTH_MAX_SESSIONS"},
+		{name: "rejects unknown session policies", env: map[string]string{"AUTH_SESSION_POLICY": "oldest"}, want: "AUTH_SESSION_POLICY"},
+		{name: "rejects negative verification grace periods", env: map[string]string{"AUTH_VERIFY_GRACE_HOURS": "-1"}, want: "AUTH_VERIFY_GRACE_HOURS"},
+		{name: "rejects bcrypt costs bcrypt does not support", env: map[string]string{"PASSWORD_BCRYPT_COST": "3"}, want: "PASSWORD_BCRYPT_COST"},
+		{name: "rejects bcrypt costs above the maximum", env: map[string]string{"PASSWORD_BCRYPT_COST": "32"}, want: "PASSWORD_BCRYPT_COST"},
+		{name: "rejects low bcrypt costs in production", env: map[string]string{"APP_ENV": "production", "SMTP_HOST": "smtp.example.com", "TOKEN_SECRET": strings.Repeat("s", 32), "PASSWORD_BCRYPT_COST": "9"}, want: "PASSWORD_BCRYPT_COST"},
+		{name: "rejects non-positive pending registration lifetimes", env: map[string]string{"PENDING_REGISTRATION_TTL_HOURS": "0"}, want: "PENDING_REGISTRATION_TTL_HOURS"},
+		{name: "rejects non-positive team fill windows", env: map[string]string{"TEAM_FILL_HOURS": "0"}, want: "TEAM_FILL_HOURS"},
+		{name: "rejects reserved bib ranges that end before they start", env: map[string]string{"BIB_RESERVED_FROM": "100", "BIB_RESERVED_TO": "1"}, want: "BIB_RESERVED_FROM"},
+		{name: "rejects reserved bib ranges with no start", env: map[string]string{"BIB_RESERVED_TO": "99"}, want: "BIB_RESERVED_FROM"},
+		{name: "rejects unknown storage drivers", env: map[string]string{"STORAGE_DRIVER": "ftp"}, want: "STORAGE_DRIVER"},
+		{name: "requires a bucket for S3 storage", env: map[string]string{"STORAGE_DRIVER": "s3", "S3_ENDPOINT": "https://s3.amazonaws.com", "S3_REGION": "eu-west-2", "S3_BUCKET": ""}, want: "S3_BUCKET"},
+		{name: "requires credentials for S3 storage", env: map[string]string{"STORAGE_DRIVER": "s3", "S3_ENDPOINT": "https://s3.amazonaws.com", "S3_REGION": "eu-west-2", "S3_BUCKET": "firecrest", "S3_ACCESS_KEY_ID": ""}, want: "S3_ACCESS_KEY_ID"},
+		{name: "rejects relative tracing endpoints", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "collector:4318"}, want: "OTEL_EXPORTER_OTLP_ENDPOINT"},
+		{name: "rejects non-numeric sample ratios", env: map[string]string{"OTEL_TRACES_SAMPLER_ARG": "half"}, want: "OTEL_TRACES_SAMPLER_ARG"},
+		{name: "rejects sample ratios above one", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_TRACES_SAMPLER_ARG": "1.5"}, want: "OTEL_TRACES_SAMPLER_ARG"},
+		{name: "rejects mock data in production", env: map[string]string{"APP_ENV": "production", "SMTP_HOST": "smtp.example.com", "TOKEN_SECRET": strings.Repeat("s", 32), "USE_MOCK_DATA": "true"}, want: "USE_MOCK_DATA"},
+		{name: "rejects static files from disk in production", env: map[string]string{"APP_ENV": "production", "SMTP_HOST": "smtp.example.com", "TOKEN_SECRET": strings.Repeat("s", 32), "STATIC_DIR": "ui/static"}, want: "STATIC_DIR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error mentioning %s, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestMockDataEnabled(t *testing.T) {
+	tests := []struct {
+		env         string
+		useMockData bool
+		want        bool
+	}{
+		{env: EnvDevelopment, useMockData: true, want: true},
+		{env: EnvDevelopment, useMockData: false, want: false},
+		{env: EnvProduction, useMockData: true, want: false},
+		{env: EnvProduction, useMockData: false, want: false},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{Env: tt.env, UseMockData: tt.useMockData}
+		if got := cfg.MockDataEnabled(); got != tt.want {
+			t.Errorf("MockDataEnabled() in %s with USE_MOCK_DATA=%t = %t, want %t", tt.env, tt.useMockData, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package database opens the application's PostgreSQL connection pool.
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"firecrest/internal/config"
+)
+
+// Backoff between attempts to reach the database at startup: the first
+// retry waits firstBackoff and each one after twice as long as the last,
+// up to maxBackoff.
+const (
+	firstBackoff = 500 * time.Millisecond
+	maxBackoff   = 8 * time.Second
+)
+
+// Connect opens a connection pool tuned by cfg and waits for the database
+// to answer, so a server started alongside Postgres, as under
+// docker-compose, does not come up before it. Failed attempts are retried
+// up to cfg.ConnectRetries times with exponential backoff; once they run
+// out Connect returns the last error. A non-nil tracer sees every query the
+// pool's connections run.
+func Connect(ctx context.Context, cfg config.DBConfig, logger *slog.Logger, tracer pgx.QueryTracer) (*pgxpool.Pool, error) {
+	return connect(ctx, cfg, logger, tracer, nil, sleep)
+}
+
+// connect is Connect with the dialer and the wait between attempts
+// replaceable, for tests. A nil dial uses pgx's own.
+func connect(ctx context.Context, cfg config.DBConfig, logger *slog.Logger, tracer pgx.QueryTracer, dial pgconn.DialFunc, wait func(context.Context, time.Duration) error) (*pgxpool.Pool, error) {
+	poolCfg, err := poolConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	poolCfg.ConnConfig.Tracer = tracer
+	if dial != nil {
+		poolCfg.ConnConfig.DialFunc = dial
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database pool: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
+		err = pool.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			return pool, nil
+		}
+		if attempt == cfg.ConnectRetries {
+			break
+		}
+
+		delay := backoff(attempt)
+		logger.Warn("database not ready, retrying", "host", cfg.Host, "attempt", attempt+1, "retry_in", delay, "error", err)
+		if err := wait(ctx, delay); err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+	pool.Close()
+	return nil, fmt.Errorf("database at %s:%s unreachable after %d attempts: %w", cfg.Host, cfg.Port, cfg.ConnectRetries+1, err)
+}
+
+// poolConfig applies cfg's tuning to the pool's defaults.
+func poolConfig(cfg config.DBConfig) (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("invalid database config: %w", err)
+	}
+	poolCfg.MaxConns = int32(cfg.MaxConns)
+	poolCfg.MinConns = int32(cfg.MinConns)
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	return poolCfg, nil
+}
+
+// backoff returns how long to wait after the given failed attempt,
+// counting from zero.
+func backoff(attempt int) time.Duration {
+	delay := firstBackoff
+	for range attempt {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
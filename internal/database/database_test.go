@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"firecrest/internal/config"
+)
+
+var errRefused = errors.New("connection refused")
+
+func testConfig(retries int) config.DBConfig {
+	return config.DBConfig{
+		Host: "127.0.0.1", Port: "5432", User: "postgres", Password: "postgres", Name: "firecrest", SSLMode: "disable",
+		MaxConns: 4, MaxConnLifetime: time.Hour, ConnectTimeout: time.Second, ConnectRetries: retries,
+	}
+}
+
+// refusingDialer fails every connection, counting the attempts.
+func refusingDialer(dials *int) pgconn.DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		*dials++
+		return nil, errRefused
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	var got []time.Duration
+	for attempt := range 7 {
+		got = append(got, backoff(attempt))
+	}
+	want := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second, 8 * time.Second}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("backs off between attempts until the retries run out", func(t *testing.T) {
+		var dials int
+		var waits []time.Duration
+		wait := func(ctx context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		}
+
+		pool, err := connect(context.Background(), testConfig(4), logger, nil, refusingDialer(&dials), wait)
+
+		if pool != nil {
+			t.Error("expected no pool")
+		}
+		if !errors.Is(err, errRefused) || !strings.Contains(err.Error(), "127.0.0.1:5432 unreachable after 5 attempts") {
+			t.Errorf("expected the attempts and last error to be reported, got %v", err)
+		}
+		if dials != 5 {
+			t.Errorf("expected 5 dials, got %d", dials)
+		}
+		want := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second}
+		if !slices.Equal(waits, want) {
+			t.Errorf("expected waits of %v, got %v", want, waits)
+		}
+	})
+
+	t.Run("tries once without retries", func(t *testing.T) {
+		var dials int
+		wait := func(ctx context.Context, d time.Duration) error {
+			t.Error("expected no wait")
+			return nil
+		}
+
+		if _, err := connect(context.Background(), testConfig(0), logger, nil, refusingDialer(&dials), wait); err == nil {
+			t.Fatal("expected an error")
+		}
+		if dials != 1 {
+			t.Errorf("expected 1 dial, got %d", dials)
+		}
+	})
+
+	t.Run("stops waiting when the context ends", func(t *testing.T) {
+		var dials int
+		ctx, cancel := context.WithCancel(context.Background())
+		wait := func(ctx context.Context, d time.Duration) error {
+			cancel()
+			return sleep(ctx, d)
+		}
+
+		_, err := connect(ctx, testConfig(4), logger, nil, refusingDialer(&dials), wait)
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the context's error, got %v", err)
+		}
+		if dials != 1 {
+			t.Errorf("expected 1 dial, got %d", dials)
+		}
+	})
+}
+
+func TestPoolConfig(t *testing.T) {
+	cfg := testConfig(0)
+	cfg.MinConns = 2
+	cfg.MaxConnLifetime = 30 * time.Minute
+
+	poolCfg, err := poolConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if poolCfg.MaxConns != 4 || poolCfg.MinConns != 2 || poolCfg.MaxConnLifetime != 30*time.Minute {
+		t.Errorf("unexpected pool tuning: %d to %d connections, lifetime %s", poolCfg.MinConns, poolCfg.MaxConns, poolCfg.MaxConnLifetime)
+	}
+	if poolCfg.ConnConfig.ConnectTimeout != time.Second {
+		t.Errorf("expected a 1s connect timeout, got %s", poolCfg.ConnConfig.ConnectTimeout)
+	}
+}
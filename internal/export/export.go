@@ -0,0 +1,118 @@
+// Package export writes tables of text, a header row of column names and
+// then one row for each record, as CSV, JSON or Excel (XLSX) files.
+//
+// Rows are written as they come, so a table is never held in memory whole.
+// Every format is built from the same column names and rows, so a column
+// added by the caller appears in all of them.
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrUnknownFormat is returned for a format name that is not one of the
+// formats tables are exported in.
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Format is a file format tables are exported in, named as in the format
+// query parameter and the file extension.
+type Format string
+
+// Supported formats.
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+	XLSX Format = "xlsx"
+)
+
+// ParseFormat returns the format named s. An empty name means CSV, the
+// format exports were first offered in.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "":
+		return CSV, nil
+	case CSV, JSON, XLSX:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+}
+
+// ContentType returns the media type files in the format are served as.
+func (f Format) ContentType() string {
+	switch f {
+	case JSON:
+		return "application/json"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Filename returns name with the format's file extension.
+func (f Format) Filename(name string) string {
+	return name + "." + string(f)
+}
+
+// Exporter writes a table's rows to a file as they are given.
+type Exporter interface {
+	// WriteRow writes a row, with a value for each column in order.
+	WriteRow(values []string) error
+	// Close finishes the file. It does not close the writer the file was
+	// written to.
+	Close() error
+}
+
+// New starts a file in the format on w, writing the column names as its
+// header.
+func New(f Format, w io.Writer, columns []string) (Exporter, error) {
+	switch f {
+	case CSV:
+		return newCSV(w, columns)
+	case JSON:
+		return newJSON(w, columns)
+	case XLSX:
+		return newXLSX(w, columns)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, f)
+}
+
+// csvExporter writes comma-separated values.
+type csvExporter struct {
+	out *csv.Writer
+}
+
+func newCSV(w io.Writer, columns []string) (*csvExporter, error) {
+	e := &csvExporter{out: csv.NewWriter(w)}
+	if err := e.WriteRow(columns); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvExporter) WriteRow(values []string) error {
+	row := make([]string, len(values))
+	for i, v := range values {
+		row[i] = csvCell(v)
+	}
+	return e.out.Write(row)
+}
+
+func (e *csvExporter) Close() error {
+	e.out.Flush()
+	return e.out.Error()
+}
+
+// csvCell stops spreadsheets reading a value, which may have been typed by
+// anyone, as a formula. The other formats hold values as text, so need no
+// such care.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,185 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+var (
+	columns = []string{"bib", "name", "club"}
+	rows    = [][]string{
+		{"101", "Jane Runner", "Lincoln Harriers"},
+		{"", "=SUM(A1) Eve", ""},
+		{"7", "Ola <Nordmann> & co", "  spaced  "},
+	}
+)
+
+// write exports the fixture rows in the format.
+func write(t *testing.T, f Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	out, err := New(f, &buf, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, row := range rows {
+		if err := out.WriteRow(row); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	got, err := csv.NewReader(bytes.NewReader(write(t, CSV))).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+
+	if len(got) != len(rows)+1 || !slices.Equal(got[0], columns) {
+		t.Fatalf("expected a header and %d rows, got %q", len(rows), got)
+	}
+	if got[2][1] != "'=SUM(A1) Eve" {
+		t.Errorf("expected formulas to be escaped, got %q", got[2][1])
+	}
+	if !slices.Equal(got[3], rows[2]) {
+		t.Errorf("expected %q, got %q", rows[2], got[3])
+	}
+}
+
+func TestJSON(t *testing.T) {
+	body := write(t, JSON)
+	var got []map[string]string
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("failed to parse JSON: %v\n%s", err, body)
+	}
+
+	if len(got) != len(rows) {
+		t.Fatalf("expected %d objects, got %d", len(rows), len(got))
+	}
+	for i, row := range rows {
+		for j, column := range columns {
+			if got[i][column] != row[j] {
+				t.Errorf("row %d: expected %s %q, got %q", i, column, row[j], got[i][column])
+			}
+		}
+	}
+	if !bytes.HasPrefix(body, []byte(`[{"bib":"101","name":"Jane Runner","club":"Lincoln Harriers"}`)) {
+		t.Errorf("expected keys in column order, got %s", body)
+	}
+
+	t.Run("writes an empty array without rows", func(t *testing.T) {
+		var buf bytes.Buffer
+		out, err := New(JSON, &buf, columns)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := out.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := buf.String(); got != "[]\n" {
+			t.Errorf("expected an empty array, got %q", got)
+		}
+	})
+}
+
+// sheet is the part of a worksheet read back in tests.
+type sheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref   string `xml:"r,attr"`
+			Value string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSX(t *testing.T) {
+	body := write(t, XLSX)
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("failed to open workbook: %v", err)
+	}
+
+	parts := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+		var doc struct{}
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			t.Errorf("%s is not well-formed: %v", f.Name, err)
+		}
+		parts[f.Name] = b
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		if parts[name] == nil {
+			t.Errorf("expected a %s part", name)
+		}
+	}
+
+	var got sheet
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &got); err != nil {
+		t.Fatalf("failed to parse worksheet: %v", err)
+	}
+	if len(got.Rows) != len(rows)+1 {
+		t.Fatalf("expected a header and %d rows, got %d rows", len(rows), len(got.Rows))
+	}
+	header := got.Rows[0].Cells
+	if len(header) != 3 || header[0].Value != "bib" || header[2].Ref != "C1" {
+		t.Errorf("unexpected header %+v", header)
+	}
+	second := got.Rows[2].Cells
+	if len(second) != 1 || second[0].Ref != "B3" || second[0].Value != "=SUM(A1) Eve" {
+		t.Errorf("expected only the name, kept as text, in the second row, got %+v", second)
+	}
+	third := got.Rows[3].Cells
+	if len(third) != 3 || third[1].Value != "Ola <Nordmann> & co" || third[2].Value != "  spaced  " {
+		t.Errorf("expected values to round-trip, got %+v", third)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		want Format
+	}{
+		{name: "", want: CSV},
+		{name: "csv", want: CSV},
+		{name: "JSON", want: JSON},
+		{name: "xlsx", want: XLSX},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseFormat("xls"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
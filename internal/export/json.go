@@ -0,0 +1,73 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonExporter writes an array with an object for each row, keyed by
+// column name in column order.
+type jsonExporter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	columns []string
+	rows    int
+}
+
+func newJSON(w io.Writer, columns []string) (*jsonExporter, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonExporter{w: w, enc: enc, columns: columns}, nil
+}
+
+func (e *jsonExporter) WriteRow(values []string) error {
+	if len(values) != len(e.columns) {
+		return fmt.Errorf("row has %d values for %d columns", len(values), len(e.columns))
+	}
+	if e.rows > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.rows++
+	return e.enc.Encode(object{keys: e.columns, values: values})
+}
+
+func (e *jsonExporter) Close() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// object is a JSON object whose keys keep their order, which a map's would
+// not.
+type object struct {
+	keys, values []string
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,107 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// The parts of a workbook other than its one worksheet, which are the same
+// for every export. Office Open XML needs no more than these to open.
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxExporter writes an Excel workbook of one worksheet. Values are
+// written as inline strings, so Excel never reads them as numbers, dates
+// or formulas. The worksheet is the last part of the zip archive, so rows
+// are compressed into it as they come.
+type xlsxExporter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+func newXLSX(w io.Writer, columns []string) (*xlsxExporter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return nil, err
+	}
+
+	e := &xlsxExporter{zw: zw, sheet: sheet}
+	if err := e.WriteRow(columns); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *xlsxExporter) WriteRow(values []string) error {
+	e.rows++
+	row := strconv.Itoa(e.rows)
+	if _, err := fmt.Fprintf(e.sheet, `<row r="%s">`, row); err != nil {
+		return err
+	}
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(e.sheet, `<c r="%s%s" t="inlineStr"><is><t xml:space="preserve">`, columnName(i), row); err != nil {
+			return err
+		}
+		// Characters XML cannot hold are replaced, not refused
+		if err := xml.EscapeText(e.sheet, []byte(v)); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(e.sheet, `</t></is></c>`); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(e.sheet, `</row>`)
+	return err
+}
+
+func (e *xlsxExporter) Close() error {
+	if _, err := io.WriteString(e.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
+
+// columnName returns the spreadsheet name of the column at index i: A to Z,
+// then AA, AB and so on.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,197 @@
+// Package gpx reads the track points of a GPX file and works out the
+// distance and climb of the route they trace.
+//
+// Only track points (<trkpt>) are read; waypoints, routes and extensions
+// are skipped. Files are decoded as a stream, so a file with too many
+// points is refused as soon as the limit is passed.
+package gpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotGPX is returned for input that is not well-formed XML or whose
+	// root element is not <gpx>.
+	ErrNotGPX = errors.New("not a GPX file")
+	// ErrNoPoints is returned for a GPX file without any track points.
+	ErrNoPoints = errors.New("GPX file has no track points")
+	// ErrTooManyPoints is returned when a file has more track points than
+	// the caller allows.
+	ErrTooManyPoints = errors.New("GPX file has too many track points")
+)
+
+// earthRadius is the mean radius of the Earth in metres.
+const earthRadius = 6371008.8
+
+// Point is a track point. Ele is in metres and only meaningful when HasEle
+// is set.
+type Point struct {
+	Lat    float64
+	Lon    float64
+	Ele    float64
+	HasEle bool
+}
+
+// Track is the track points of a GPX file, in file order. Points from
+// several tracks and segments are joined into one line.
+type Track struct {
+	Points []Point
+}
+
+// Decode reads the track points from r, refusing files with more than
+// maxPoints of them. Errors match ErrNotGPX, ErrNoPoints or
+// ErrTooManyPoints, apart from those reading r.
+func Decode(r io.Reader, maxPoints int) (Track, error) {
+	src := &sourceReader{r: r}
+	dec := xml.NewDecoder(src)
+
+	var track Track
+	seenRoot := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Track{}, src.wrap(err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if !seenRoot {
+			if start.Name.Local != "gpx" {
+				return Track{}, fmt.Errorf("%w: root element is <%s>", ErrNotGPX, start.Name.Local)
+			}
+			seenRoot = true
+			continue
+		}
+		if start.Name.Local != "trkpt" {
+			continue
+		}
+
+		if len(track.Points) == maxPoints {
+			return Track{}, fmt.Errorf("%w: more than %d", ErrTooManyPoints, maxPoints)
+		}
+		p, err := decodePoint(dec, start)
+		if err != nil {
+			return Track{}, src.wrap(err)
+		}
+		track.Points = append(track.Points, p)
+	}
+
+	if !seenRoot {
+		return Track{}, fmt.Errorf("%w: no root element", ErrNotGPX)
+	}
+	if len(track.Points) == 0 {
+		return Track{}, ErrNoPoints
+	}
+	return track, nil
+}
+
+// trkpt is the part of a track point element Decode reads.
+type trkpt struct {
+	Lat string `xml:"lat,attr"`
+	Lon string `xml:"lon,attr"`
+	Ele string `xml:"ele"`
+}
+
+func decodePoint(dec *xml.Decoder, start xml.StartElement) (Point, error) {
+	var raw trkpt
+	if err := dec.DecodeElement(&raw, &start); err != nil {
+		return Point{}, err
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(raw.Lat), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return Point{}, fmt.Errorf("%w: track point latitude %q is invalid", ErrNotGPX, raw.Lat)
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(raw.Lon), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return Point{}, fmt.Errorf("%w: track point longitude %q is invalid", ErrNotGPX, raw.Lon)
+	}
+	p := Point{Lat: lat, Lon: lon}
+
+	if ele := strings.TrimSpace(raw.Ele); ele != "" {
+		p.Ele, err = strconv.ParseFloat(ele, 64)
+		if err != nil || math.IsNaN(p.Ele) || math.IsInf(p.Ele, 0) {
+			return Point{}, fmt.Errorf("%w: track point elevation %q is invalid", ErrNotGPX, raw.Ele)
+		}
+		p.HasEle = true
+	}
+	return p, nil
+}
+
+// sourceReader remembers the error reading the underlying reader, so it can
+// be told apart from the decoder's complaints about the XML.
+type sourceReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *sourceReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.err = err
+	}
+	return n, err
+}
+
+// wrap returns the read error behind err if there was one, and otherwise
+// err as ErrNotGPX unless it already is.
+func (s *sourceReader) wrap(err error) error {
+	if s.err != nil {
+		return s.err
+	}
+	if errors.Is(err, ErrNotGPX) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrNotGPX, err)
+}
+
+// Distance returns the length of the track in metres, following the
+// great circle between consecutive points.
+func (t Track) Distance() float64 {
+	var total float64
+	for i := 1; i < len(t.Points); i++ {
+		total += haversine(t.Points[i-1], t.Points[i])
+	}
+	return total
+}
+
+// ElevationGain returns the total climb of the track in metres: the sum of
+// every rise between consecutive points that both have an elevation.
+func (t Track) ElevationGain() float64 {
+	var gain float64
+	var prev *Point
+	for i := range t.Points {
+		p := &t.Points[i]
+		if !p.HasEle {
+			continue
+		}
+		if prev != nil && p.Ele > prev.Ele {
+			gain += p.Ele - prev.Ele
+		}
+		prev = p
+	}
+	return gain
+}
+
+// haversine returns the great-circle distance between a and b in metres.
+func haversine(a, b Point) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,86 @@
+package gpx
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecode(t *testing.T) {
+	f, err := os.Open("testdata/ridge.gpx")
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	track, err := Decode(f, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Waypoints and route points are skipped; both track segments are read
+	if len(track.Points) != 5 {
+		t.Fatalf("expected 5 track points, got %d", len(track.Points))
+	}
+	if p := track.Points[0]; p.Lat != 53.36 || p.Lon != -1.81 || !p.HasEle || p.Ele != 100 {
+		t.Errorf("unexpected first point %+v", p)
+	}
+
+	// Four steps of 0.001° of latitude, each about 111.2 m
+	if got := track.Distance(); math.Abs(got-444.78) > 0.5 {
+		t.Errorf("expected a distance of about 444.78 m, got %.2f", got)
+	}
+	// 100 → 110 → 105.5 → 120.5 → 118 climbs 10 and 15
+	if got := track.ElevationGain(); math.Abs(got-25) > 1e-9 {
+		t.Errorf("expected 25 m of climb, got %.2f", got)
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want error
+	}{
+		{name: "malformed XML", src: `<gpx><trk><trkpt lat="1" lon="1"></trk>`, want: ErrNotGPX},
+		{name: "other XML", src: `<kml><Placemark/></kml>`, want: ErrNotGPX},
+		{name: "CSV", src: "name,email\nJane,jane@example.com\n", want: ErrNotGPX},
+		{name: "empty input", src: "", want: ErrNotGPX},
+		{name: "invalid latitude", src: `<gpx><trk><trkseg><trkpt lat="91" lon="1"/></trkseg></trk></gpx>`, want: ErrNotGPX},
+		{name: "invalid elevation", src: `<gpx><trk><trkseg><trkpt lat="1" lon="1"><ele>high</ele></trkpt></trkseg></trk></gpx>`, want: ErrNotGPX},
+		{name: "no track points", src: `<gpx><wpt lat="1" lon="1"/></gpx>`, want: ErrNoPoints},
+		{name: "too many track points", src: `<gpx><trk><trkseg>` + strings.Repeat(`<trkpt lat="1" lon="1"/>`, 4) + `</trkseg></trk></gpx>`, want: ErrTooManyPoints},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(tt.src), 3); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestDecodeReturnsReadErrors(t *testing.T) {
+	readErr := errors.New("connection reset")
+
+	_, err := Decode(iotest.ErrReader(readErr), 10)
+	if !errors.Is(err, readErr) || errors.Is(err, ErrNotGPX) {
+		t.Errorf("expected the read error, got %v", err)
+	}
+}
+
+func TestElevationGainSkipsPointsWithoutElevation(t *testing.T) {
+	track := Track{Points: []Point{
+		{Ele: 100, HasEle: true},
+		{},
+		{Ele: 130, HasEle: true},
+	}}
+
+	if got := track.ElevationGain(); got != 30 {
+		t.Errorf("expected 30 m of climb, got %.2f", got)
+	}
+}
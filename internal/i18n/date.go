@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"strings"
+	"time"
+)
+
+// Layouts for dates shown on the site, as time.Format layouts. Date
+// replaces their month and weekday names with the locale's.
+const (
+	// DateLong is e.g. "2 January 2006"
+	DateLong = "2 January 2006"
+	// DateLongWeekday is e.g. "Mon 2 January 2006"
+	DateLongWeekday = "Mon 2 January 2006"
+	// MonthShort is e.g. "Jan"
+	MonthShort = "Jan"
+)
+
+// nameTokens are the layout elements Date names in the locale's language.
+// Longer elements come first, so "January" is not read as "Jan" and
+// "nuary".
+var nameTokens = []string{"January", "Monday", "Jan", "Mon"}
+
+// Date formats tm as time.Format does with layout, but with the month and
+// weekday names of the translator's locale.
+func (t *Translator) Date(tm time.Time, layout string) string {
+	var b strings.Builder
+	for layout != "" {
+		i, token := nextNameToken(layout)
+		if i > 0 {
+			b.WriteString(tm.Format(layout[:i]))
+		}
+		if token == "" {
+			break
+		}
+		b.WriteString(t.name(tm, token))
+		layout = layout[i+len(token):]
+	}
+	return b.String()
+}
+
+// nextNameToken returns the first of nameTokens in layout and where it
+// starts, or len(layout) and "" if there is none.
+func nextNameToken(layout string) (int, string) {
+	first, token := len(layout), ""
+	for _, tok := range nameTokens {
+		if i := strings.Index(layout, tok); i >= 0 && (i < first || i == first && len(tok) > len(token)) {
+			first, token = i, tok
+		}
+	}
+	return first, token
+}
+
+func (t *Translator) name(tm time.Time, token string) string {
+	c := t.catalogue
+	switch token {
+	case "January":
+		return c.Months[tm.Month()-1]
+	case "Jan":
+		return c.MonthsShort[tm.Month()-1]
+	case "Monday":
+		return c.Weekdays[tm.Weekday()]
+	default:
+		return c.WeekdaysShort[tm.Weekday()]
+	}
+}
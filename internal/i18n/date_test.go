@@ -0,0 +1,43 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTranslator_Date(t *testing.T) {
+	b := Must(New(nil))
+	// A Saturday
+	date := time.Date(2026, time.August, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		locale string
+		layout string
+		want   string
+	}{
+		{locale: EnglishGB, layout: DateLong, want: "1 August 2026"},
+		{locale: EnglishGB, layout: DateLongWeekday, want: "Sat 1 August 2026"},
+		{locale: EnglishGB, layout: MonthShort, want: "Aug"},
+		{locale: Welsh, layout: DateLong, want: "1 Awst 2026"},
+		{locale: Welsh, layout: DateLongWeekday, want: "Sad 1 Awst 2026"},
+		{locale: Welsh, layout: "Monday 2 January, 15:04", want: "Dydd Sadwrn 1 Awst, 09:30"},
+		{locale: Welsh, layout: "02/01/2006", want: "01/08/2026"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.layout, func(t *testing.T) {
+			if got := b.Translator(tt.locale).Date(date, tt.layout); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("names every month", func(t *testing.T) {
+		welsh := b.Translator(Welsh)
+		for month := time.January; month <= time.December; month++ {
+			d := time.Date(2026, month, 1, 0, 0, 0, 0, time.UTC)
+			if got, want := welsh.Date(d, "January"), b.catalogues[Welsh].Months[month-1]; got != want {
+				t.Errorf("expected %s for %s, got %s", want, month, got)
+			}
+		}
+	})
+}
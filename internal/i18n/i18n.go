@@ -0,0 +1,257 @@
+// Package i18n translates the site's copy and formats dates for the
+// visitor's language.
+//
+// Each supported locale has a catalogue in locales/, named by its tag,
+// holding its messages by key and the names of its months and weekdays.
+// English (en-GB) is complete; any other catalogue may leave messages out,
+// and those fall back to English one key at a time, so a partial
+// translation never leaves a page blank. The first time a message is
+// missing it is logged.
+//
+// A Translator for the request is chosen by the web app's middleware and
+// carried in the request's context, where templates find it with T, Tf
+// and Date.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Supported locales, by the tags used in lang attributes, ?lang= and the
+// catalogue file names.
+const (
+	EnglishGB = "en-GB"
+	Welsh     = "cy"
+)
+
+// Default is the locale used when a visitor asks for none we support, and
+// the one every other locale falls back to.
+const Default = EnglishGB
+
+// Locales lists the supported locales, Default first.
+var Locales = []string{EnglishGB, Welsh}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogue is one locale's file in locales/.
+type catalogue struct {
+	// Name is the language's name in itself, e.g. "Cymraeg", for the
+	// language toggle.
+	Name          string            `json:"name"`
+	Months        []string          `json:"months"`
+	MonthsShort   []string          `json:"monthsShort"`
+	Weekdays      []string          `json:"weekdays"`
+	WeekdaysShort []string          `json:"weekdaysShort"`
+	Messages      map[string]string `json:"messages"`
+}
+
+// Bundle holds the catalogues of every supported locale.
+type Bundle struct {
+	logger     *slog.Logger
+	catalogues map[string]*catalogue
+	matcher    language.Matcher
+	// reported holds the locale and key of each missing message already
+	// logged, so each is logged once
+	reported sync.Map
+}
+
+// New loads the catalogues embedded in the binary. Missing messages are
+// logged to logger; a nil logger logs nothing.
+func New(logger *slog.Logger) (*Bundle, error) {
+	return load(logger, locales)
+}
+
+// Must returns b, panicking if err is not nil. It suits bundles loaded once
+// at startup, whose catalogues are fixed when the binary is built.
+func Must(b *Bundle, err error) *Bundle {
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func load(logger *slog.Logger, fsys fs.FS) (*Bundle, error) {
+	b := &Bundle{logger: logger, catalogues: make(map[string]*catalogue, len(Locales))}
+	tags := make([]language.Tag, 0, len(Locales))
+	for _, locale := range Locales {
+		data, err := fs.ReadFile(fsys, "locales/"+locale+".json")
+		if err != nil {
+			return nil, fmt.Errorf("i18n: reading the %s catalogue: %w", locale, err)
+		}
+		var c catalogue
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("i18n: decoding the %s catalogue: %w", locale, err)
+		}
+		if len(c.Months) != 12 || len(c.MonthsShort) != 12 || len(c.Weekdays) != 7 || len(c.WeekdaysShort) != 7 {
+			return nil, fmt.Errorf("i18n: the %s catalogue needs 12 months and 7 weekdays", locale)
+		}
+		b.catalogues[locale] = &c
+		tags = append(tags, language.MustParse(locale))
+	}
+	b.matcher = language.NewMatcher(tags)
+	return b, nil
+}
+
+// Match returns the supported locale lang names, such as "cy" for "cy" or
+// "cy-GB", and whether there is one.
+func (b *Bundle) Match(lang string) (string, bool) {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return "", false
+	}
+	_, i, confidence := b.matcher.Match(tag)
+	if confidence < language.High {
+		return "", false
+	}
+	return Locales[i], true
+}
+
+// Negotiate returns the supported locale that best suits an
+// Accept-Language header, preferring languages in the order of their
+// weights, or Default if it names none of them.
+func (b *Bundle) Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, i, confidence := b.matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return Locales[i]
+}
+
+// Translator returns a Translator for locale, or for Default if locale is
+// not supported.
+func (b *Bundle) Translator(locale string) *Translator {
+	c, ok := b.catalogues[locale]
+	if !ok {
+		locale, c = Default, b.catalogues[Default]
+	}
+	return &Translator{bundle: b, locale: locale, catalogue: c}
+}
+
+// Language is a supported locale, named in its own language.
+type Language struct {
+	Locale string
+	Name   string
+}
+
+// reportMissing logs a message missing from locale's catalogue, the first
+// time it is asked for.
+func (b *Bundle) reportMissing(locale, key string) {
+	if b.logger == nil {
+		return
+	}
+	if _, seen := b.reported.LoadOrStore(locale+"\x00"+key, struct{}{}); seen {
+		return
+	}
+	if locale == Default {
+		b.logger.Error("unknown message", "locale", locale, "key", key)
+		return
+	}
+	b.logger.Warn("missing translation, falling back to "+Default, "locale", locale, "key", key)
+}
+
+// Translator translates messages into one locale.
+type Translator struct {
+	bundle    *Bundle
+	locale    string
+	catalogue *catalogue
+}
+
+// Locale returns the translator's locale, e.g. "cy".
+func (t *Translator) Locale() string {
+	return t.locale
+}
+
+// Alternatives returns the supported languages other than the
+// translator's, for the language toggle.
+func (t *Translator) Alternatives() []Language {
+	languages := make([]Language, 0, len(Locales)-1)
+	for _, locale := range Locales {
+		if locale != t.locale {
+			languages = append(languages, Language{Locale: locale, Name: t.bundle.catalogues[locale].Name})
+		}
+	}
+	return languages
+}
+
+// T returns the message with key in the translator's locale, falling back
+// to English if it has not been translated, and to the key itself if there
+// is no such message.
+func (t *Translator) T(key string) string {
+	if msg, ok := t.catalogue.Messages[key]; ok {
+		return msg
+	}
+	t.bundle.reportMissing(t.locale, key)
+	if t.locale != Default {
+		if msg, ok := t.bundle.catalogues[Default].Messages[key]; ok {
+			return msg
+		}
+		t.bundle.reportMissing(Default, key)
+	}
+	return key
+}
+
+// Tf returns the message with key, as T does, formatted with args as by
+// fmt.Sprintf.
+func (t *Translator) Tf(key string, args ...any) string {
+	return fmt.Sprintf(t.T(key), args...)
+}
+
+type contextKey struct{}
+
+// defaultBundle supplies the English translator for contexts without one,
+// such as templates rendered in tests.
+var defaultBundle = sync.OnceValue(func() *Bundle { return Must(New(nil)) })
+
+// WithTranslator returns a copy of ctx carrying t.
+func WithTranslator(ctx context.Context, t *Translator) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the translator ctx carries, or an English one.
+func FromContext(ctx context.Context) *Translator {
+	if t, ok := ctx.Value(contextKey{}).(*Translator); ok {
+		return t
+	}
+	return defaultBundle().Translator(Default)
+}
+
+// T translates key with the request's translator, for templates.
+func T(ctx context.Context, key string) string {
+	return FromContext(ctx).T(key)
+}
+
+// Tf translates and formats key with the request's translator, for
+// templates.
+func Tf(ctx context.Context, key string, args ...any) string {
+	return FromContext(ctx).Tf(key, args...)
+}
+
+// Lang returns the request's locale, for lang attributes.
+func Lang(ctx context.Context) string {
+	return FromContext(ctx).Locale()
+}
+
+// Alternatives returns the languages the request could switch to, for
+// templates.
+func Alternatives(ctx context.Context) []Language {
+	return FromContext(ctx).Alternatives()
+}
+
+// Date formats tm with the request's translator, for templates.
+func Date(ctx context.Context, tm time.Time, layout string) string {
+	return FromContext(ctx).Date(tm, layout)
+}
@@ -0,0 +1,189 @@
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testBundle loads a bundle from catalogues holding messages, logging to
+// the returned buffer.
+func testBundle(t *testing.T, messages map[string]string) (*Bundle, *bytes.Buffer) {
+	t.Helper()
+	const names = `"months": ["1","2","3","4","5","6","7","8","9","10","11","12"],
+"monthsShort": ["1","2","3","4","5","6","7","8","9","10","11","12"],
+"weekdays": ["1","2","3","4","5","6","7"],
+"weekdaysShort": ["1","2","3","4","5","6","7"]`
+	fsys := fstest.MapFS{}
+	for _, locale := range Locales {
+		fsys["locales/"+locale+".json"] = &fstest.MapFile{Data: []byte(`{"name": "` + locale + `", ` + names + `, "messages": ` + messages[locale] + `}`)}
+	}
+	var logs bytes.Buffer
+	b, err := load(slog.New(slog.NewTextHandler(&logs, nil)), fsys)
+	if err != nil {
+		t.Fatalf("failed to load catalogues: %v", err)
+	}
+	return b, &logs
+}
+
+func TestCatalogues(t *testing.T) {
+	b, err := New(nil)
+	if err != nil {
+		t.Fatalf("failed to load catalogues: %v", err)
+	}
+	english := b.catalogues[Default].Messages
+	for _, locale := range Locales[1:] {
+		for key, msg := range b.catalogues[locale].Messages {
+			want, ok := english[key]
+			if !ok {
+				t.Errorf("%s has %q, which English does not", locale, key)
+				continue
+			}
+			if strings.Count(msg, "%") != strings.Count(want, "%") {
+				t.Errorf("%s's %q takes different arguments from English", locale, key)
+			}
+		}
+	}
+}
+
+func TestTranslator_T(t *testing.T) {
+	b, logs := testBundle(t, map[string]string{
+		EnglishGB: `{"nav.events": "Events", "nav.calendar": "Calendar", "nav.sign_in": "Sign In", "event.spots_left": "%d spots left"}`,
+		Welsh:     `{"nav.events": "Digwyddiadau", "event.spots_left": "%d lle ar ôl"}`,
+	})
+	welsh := b.Translator(Welsh)
+
+	t.Run("translates messages", func(t *testing.T) {
+		if got := welsh.T("nav.events"); got != "Digwyddiadau" {
+			t.Errorf("expected Digwyddiadau, got %q", got)
+		}
+		if got := welsh.Tf("event.spots_left", 3); got != "3 lle ar ôl" {
+			t.Errorf("expected 3 lle ar ôl, got %q", got)
+		}
+	})
+
+	t.Run("falls back to English one message at a time", func(t *testing.T) {
+		if got := welsh.T("nav.calendar"); got != "Calendar" {
+			t.Errorf("expected Calendar, got %q", got)
+		}
+		if got := welsh.T("nav.events"); got != "Digwyddiadau" {
+			t.Errorf("expected the rest still translated, got %q", got)
+		}
+	})
+
+	t.Run("falls back to the key for unknown messages", func(t *testing.T) {
+		if got := welsh.T("nav.unknown"); got != "nav.unknown" {
+			t.Errorf("expected the key, got %q", got)
+		}
+	})
+
+	t.Run("serves unsupported locales in English", func(t *testing.T) {
+		tr := b.Translator("fr")
+		if tr.Locale() != Default || tr.T("nav.events") != "Events" {
+			t.Errorf("expected English, got %s: %q", tr.Locale(), tr.T("nav.events"))
+		}
+	})
+
+	t.Run("logs each missing message once", func(t *testing.T) {
+		logs.Reset()
+		welsh.T("nav.sign_in")
+		b.Translator(Welsh).T("nav.sign_in")
+
+		if n := strings.Count(logs.String(), "key=nav.sign_in"); n != 1 {
+			t.Errorf("expected the missing translation logged once, got %d times:\n%s", n, logs)
+		}
+		if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "locale=cy") {
+			t.Errorf("expected a warning naming the locale, got %s", logs)
+		}
+	})
+
+	t.Run("logs unknown messages as errors", func(t *testing.T) {
+		logs.Reset()
+		b.Translator(EnglishGB).T("nav.missing")
+
+		if !strings.Contains(logs.String(), "level=ERROR") || !strings.Contains(logs.String(), "key=nav.missing") {
+			t.Errorf("expected an error naming the key, got %s", logs)
+		}
+	})
+
+	t.Run("logs nothing for messages it has", func(t *testing.T) {
+		logs.Reset()
+		welsh.T("nav.events")
+		b.Translator(EnglishGB).T("nav.calendar")
+
+		if logs.Len() != 0 {
+			t.Errorf("expected nothing logged, got %s", logs)
+		}
+	})
+}
+
+func TestBundle_Negotiate(t *testing.T) {
+	b := Must(New(nil))
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: EnglishGB},
+		{header: "cy", want: Welsh},
+		{header: "cy-GB", want: Welsh},
+		{header: "en-GB,en;q=0.9", want: EnglishGB},
+		{header: "en-US,en;q=0.9", want: EnglishGB},
+		{header: "cy;q=0.8, en-GB;q=0.9", want: EnglishGB},
+		{header: "en-GB;q=0.5, cy-GB;q=0.9", want: Welsh},
+		{header: "fr-FR, cy;q=0.7", want: Welsh},
+		{header: "fr-FR, de;q=0.7", want: EnglishGB},
+		{header: "*", want: EnglishGB},
+		{header: "not a language;;q=x", want: EnglishGB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := b.Negotiate(tt.header); got != tt.want {
+				t.Errorf("Negotiate(%q) = %s, want %s", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundle_Match(t *testing.T) {
+	b := Must(New(nil))
+
+	tests := []struct {
+		lang string
+		want string
+		ok   bool
+	}{
+		{lang: "cy", want: Welsh, ok: true},
+		{lang: "CY", want: Welsh, ok: true},
+		{lang: "en-GB", want: EnglishGB, ok: true},
+		{lang: "en", want: EnglishGB, ok: true},
+		{lang: "fr"},
+		{lang: ""},
+		{lang: "<script>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			if got, ok := b.Match(tt.lang); got != tt.want || ok != tt.ok {
+				t.Errorf("Match(%q) = %s, %v, want %s, %v", tt.lang, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	b := Must(New(nil))
+
+	if got := Lang(context.Background()); got != Default {
+		t.Errorf("expected %s without a translator, got %s", Default, got)
+	}
+	ctx := WithTranslator(context.Background(), b.Translator(Welsh))
+	if got := T(ctx, "nav.events"); got != "Digwyddiadau" {
+		t.Errorf("expected the context's translator used, got %q", got)
+	}
+	if alt := Alternatives(ctx); len(alt) != 1 || alt[0] != (Language{Locale: EnglishGB, Name: "English"}) {
+		t.Errorf("expected English as the alternative, got %+v", alt)
+	}
+}
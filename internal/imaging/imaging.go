@@ -0,0 +1,62 @@
+// Package imaging resizes images for the web without leaving the standard
+// library.
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Fit scales img down, keeping its proportions, so that neither side is
+// longer than maxSide pixels, averaging the pixels each new one covers.
+// Images that already fit keep their size. The result is opaque:
+// transparent areas are laid over white, ready to be saved as a JPEG.
+func Fit(img image.Image, maxSide int) *image.RGBA {
+	src := flatten(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+
+	dw, dh := maxSide, maxSide
+	if w >= h {
+		dh = max(1, h*maxSide/w)
+	} else {
+		dw = max(1, w*maxSide/h)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := range dw {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+3]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
+
+// flatten copies img onto a white canvas whose bounds start at the origin.
+func flatten(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,53 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		w, h, maxSide int
+		wantW, wantH  int
+	}{
+		{name: "landscape", w: 4000, h: 3000, maxSide: 1600, wantW: 1600, wantH: 1200},
+		{name: "portrait", w: 3000, h: 4000, maxSide: 1600, wantW: 1200, wantH: 1600},
+		{name: "small images keep their size", w: 800, h: 600, maxSide: 1600, wantW: 800, wantH: 600},
+		{name: "thin strips keep a pixel", w: 5000, h: 2, maxSide: 100, wantW: 100, wantH: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Fit(image.NewRGBA(image.Rect(0, 0, tt.w, tt.h)), tt.maxSide).Bounds()
+			if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+				t.Errorf("expected %dx%d, got %dx%d", tt.wantW, tt.wantH, got.Dx(), got.Dy())
+			}
+		})
+	}
+}
+
+func TestFitAverages(t *testing.T) {
+	// Black and white columns average to grey
+	src := image.NewGray(image.Rect(0, 0, 4, 2))
+	for y := range 2 {
+		src.SetGray(0, y, color.Gray{Y: 0})
+		src.SetGray(1, y, color.Gray{Y: 255})
+		src.SetGray(2, y, color.Gray{Y: 0})
+		src.SetGray(3, y, color.Gray{Y: 255})
+	}
+
+	got := Fit(src, 2)
+	if c := got.RGBAAt(0, 0); c.R != 127 || c.A != 255 {
+		t.Errorf("expected opaque grey, got %v", c)
+	}
+}
+
+func TestFitFlattensTransparency(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(10, 10, 12, 12))
+
+	got := Fit(src, 10)
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("expected transparent pixels to become white, got %v", c)
+	}
+}
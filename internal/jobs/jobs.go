@@ -0,0 +1,250 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"firecrest/internal/service"
+	"firecrest/internal/webhook"
+)
+
+// Intervals of the built-in jobs
+const (
+	ExpirePendingInterval       = 5 * time.Minute
+	UnlockAccountsInterval      = time.Minute
+	RaceRemindersInterval       = time.Hour
+	ReleaseTeamsInterval        = 5 * time.Minute
+	RegistrationDigestsInterval = time.Hour
+	DeliverWebhooksInterval     = 15 * time.Second
+	PruneLoginEventsInterval    = time.Hour
+	BuildDataExportsInterval    = time.Minute
+	PruneDataExportsInterval    = 15 * time.Minute
+)
+
+// PendingExpirer cancels pending registrations created before a given time.
+type PendingExpirer interface {
+	ExpirePending(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AccountUnlocker clears account locks that have run out.
+type AccountUnlocker interface {
+	UnlockExpired(ctx context.Context) (int64, error)
+}
+
+// LoginEventPruner deletes the login events recorded before a given time.
+type LoginEventPruner interface {
+	DeleteLoginEventsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// RaceReminderSender emails entrants whose races are coming up.
+type RaceReminderSender interface {
+	SendRaceReminders(ctx context.Context) (int, error)
+}
+
+// RegistrationDigestSender emails organisers a summary of the previous
+// day's registrations.
+type RegistrationDigestSender interface {
+	SendRegistrationDigests(ctx context.Context) (int, error)
+}
+
+// WebhookDeliverer sends the webhook deliveries that are due.
+type WebhookDeliverer interface {
+	DeliverWebhooks(ctx context.Context) (int, error)
+}
+
+// DataExporter builds the copies of their data users have asked for, and
+// removes them once spent.
+type DataExporter interface {
+	BuildExports(ctx context.Context) (int, error)
+	PruneExports(ctx context.Context) (int, error)
+}
+
+// TeamReleaser releases the places held by teams not filled by a given time.
+type TeamReleaser interface {
+	ReleaseUnfilledTeams(ctx context.Context, now time.Time) (int64, error)
+}
+
+// ExpirePendingRegistrations returns a job that cancels registrations still
+// pending ttl after they were made, so unpaid entries stop holding places.
+func ExpirePendingRegistrations(registrations PendingExpirer, clock service.Clock, ttl time.Duration, logger *slog.Logger) Job {
+	return Job{
+		Name:     "expire-pending-registrations",
+		Interval: ExpirePendingInterval,
+		Run: func(ctx context.Context) error {
+			n, err := registrations.ExpirePending(ctx, clock.Now().Add(-ttl))
+			if err != nil {
+				return fmt.Errorf("failed to expire pending registrations: %w", err)
+			}
+			if n > 0 {
+				logger.Info("expired pending registrations", "count", n)
+			}
+			return nil
+		},
+	}
+}
+
+// UnlockAccounts returns a job that clears lapsed account locks. Logins
+// already ignore a lock once it has passed, but clearing it also resets the
+// failed attempts, giving the user a fresh set before the account locks
+// again.
+func UnlockAccounts(accounts AccountUnlocker, logger *slog.Logger) Job {
+	return Job{
+		Name:     "unlock-accounts",
+		Interval: UnlockAccountsInterval,
+		Run: func(ctx context.Context) error {
+			n, err := accounts.UnlockExpired(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to unlock accounts: %w", err)
+			}
+			if n > 0 {
+				logger.Info("unlocked accounts", "count", n)
+			}
+			return nil
+		},
+	}
+}
+
+// PruneLoginEvents returns a job that deletes login events older than
+// service.KnownDeviceWindow, by which time they no longer tell a device the
+// account has used from a new one.
+func PruneLoginEvents(events LoginEventPruner, clock service.Clock, logger *slog.Logger) Job {
+	return Job{
+		Name:     "prune-login-events",
+		Interval: PruneLoginEventsInterval,
+		Run: func(ctx context.Context) error {
+			n, err := events.DeleteLoginEventsBefore(ctx, clock.Now().Add(-service.KnownDeviceWindow))
+			if err != nil {
+				return fmt.Errorf("failed to prune login events: %w", err)
+			}
+			if n > 0 {
+				logger.Info("pruned login events", "count", n)
+			}
+			return nil
+		},
+	}
+}
+
+// SendRaceReminders returns a job that emails entrants whose races are
+// coming up. The sender remembers who it has reminded, so the job can run
+// as often as it likes without anyone hearing twice.
+func SendRaceReminders(reminders RaceReminderSender, logger *slog.Logger) Job {
+	return Job{
+		Name:     "send-race-reminders",
+		Interval: RaceRemindersInterval,
+		Run: func(ctx context.Context) error {
+			n, err := reminders.SendRaceReminders(ctx)
+			if n > 0 {
+				logger.Info("sent race reminders", "count", n)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to send race reminders: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// SendRegistrationDigests returns a job that emails organisers who asked for
+// it a digest of the previous day's registrations. The sender remembers the
+// day each organiser's last digest covered, so hourly runs send each digest
+// soon after midnight and never twice.
+func SendRegistrationDigests(digests RegistrationDigestSender, logger *slog.Logger) Job {
+	return Job{
+		Name:     "send-registration-digests",
+		Interval: RegistrationDigestsInterval,
+		Run: func(ctx context.Context) error {
+			n, err := digests.SendRegistrationDigests(ctx)
+			if n > 0 {
+				logger.Info("sent registration digests", "count", n)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to send registration digests: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// ReleaseUnfilledTeams returns a job that hands back to their races the
+// places held by teams whose members did not all join in time.
+func ReleaseUnfilledTeams(teams TeamReleaser, clock service.Clock, logger *slog.Logger) Job {
+	return Job{
+		Name:     "release-unfilled-teams",
+		Interval: ReleaseTeamsInterval,
+		Run: func(ctx context.Context) error {
+			n, err := teams.ReleaseUnfilledTeams(ctx, clock.Now())
+			if err != nil {
+				return fmt.Errorf("failed to release unfilled teams: %w", err)
+			}
+			if n > 0 {
+				logger.Info("released unfilled teams", "count", n)
+			}
+			return nil
+		},
+	}
+}
+
+// DeliverWebhooks returns a job that sends queued webhooks to organisers'
+// endpoints. A run may take far longer than the interval when endpoints
+// are slow, so it is given until every request of a batch could have
+// timed out, which is within the lease on the batch.
+func DeliverWebhooks(webhooks WebhookDeliverer, logger *slog.Logger) Job {
+	return Job{
+		Name:     "deliver-webhooks",
+		Interval: DeliverWebhooksInterval,
+		Timeout:  service.WebhookBatchSize * webhook.Timeout,
+		Run: func(ctx context.Context) error {
+			n, err := webhooks.DeliverWebhooks(ctx)
+			if n > 0 {
+				logger.Info("delivered webhooks", "count", n)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to deliver webhooks: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// BuildDataExports returns a job that builds the copies of their data users
+// have asked for and emails them the links. Copies claimed by a run that
+// stops early are built again once their lease runs out.
+func BuildDataExports(exports DataExporter, logger *slog.Logger) Job {
+	return Job{
+		Name:     "build-data-exports",
+		Interval: BuildDataExportsInterval,
+		Timeout:  service.DataExportBuildTimeout,
+		Run: func(ctx context.Context) error {
+			n, err := exports.BuildExports(ctx)
+			if n > 0 {
+				logger.Info("built data exports", "count", n)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to build data exports: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// PruneDataExports returns a job that removes copies of users' data from
+// the blob store once downloaded or expired, so none is kept for longer
+// than its owner needs it.
+func PruneDataExports(exports DataExporter, logger *slog.Logger) Job {
+	return Job{
+		Name:     "prune-data-exports",
+		Interval: PruneDataExportsInterval,
+		Run: func(ctx context.Context) error {
+			n, err := exports.PruneExports(ctx)
+			if n > 0 {
+				logger.Info("pruned data exports", "count", n)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to prune data exports: %w", err)
+			}
+			return nil
+		},
+	}
+}
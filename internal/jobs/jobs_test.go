@@ -0,0 +1,367 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"firecrest/internal/service"
+)
+
+// mockPendingExpirer implements PendingExpirer for testing.
+type mockPendingExpirer struct {
+	expirePendingFunc func(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (m *mockPendingExpirer) ExpirePending(ctx context.Context, before time.Time) (int64, error) {
+	return m.expirePendingFunc(ctx, before)
+}
+
+// mockAccountUnlocker implements AccountUnlocker for testing.
+type mockAccountUnlocker struct {
+	unlockExpiredFunc func(ctx context.Context) (int64, error)
+}
+
+func (m *mockAccountUnlocker) UnlockExpired(ctx context.Context) (int64, error) {
+	return m.unlockExpiredFunc(ctx)
+}
+
+// mockLoginEventPruner implements LoginEventPruner for testing.
+type mockLoginEventPruner struct {
+	deleteLoginEventsBeforeFunc func(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (m *mockLoginEventPruner) DeleteLoginEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.deleteLoginEventsBeforeFunc(ctx, before)
+}
+
+// mockRaceReminderSender implements RaceReminderSender for testing.
+type mockRaceReminderSender struct {
+	sendRaceRemindersFunc func(ctx context.Context) (int, error)
+}
+
+func (m *mockRaceReminderSender) SendRaceReminders(ctx context.Context) (int, error) {
+	return m.sendRaceRemindersFunc(ctx)
+}
+
+// mockRegistrationDigestSender implements RegistrationDigestSender for
+// testing.
+type mockRegistrationDigestSender struct {
+	sendRegistrationDigestsFunc func(ctx context.Context) (int, error)
+}
+
+func (m *mockRegistrationDigestSender) SendRegistrationDigests(ctx context.Context) (int, error) {
+	return m.sendRegistrationDigestsFunc(ctx)
+}
+
+// mockTeamReleaser implements TeamReleaser for testing.
+type mockTeamReleaser struct {
+	releaseUnfilledTeamsFunc func(ctx context.Context, now time.Time) (int64, error)
+}
+
+func (m *mockTeamReleaser) ReleaseUnfilledTeams(ctx context.Context, now time.Time) (int64, error) {
+	return m.releaseUnfilledTeamsFunc(ctx, now)
+}
+
+// mockWebhookDeliverer implements WebhookDeliverer for testing.
+type mockWebhookDeliverer struct {
+	deliverWebhooksFunc func(ctx context.Context) (int, error)
+}
+
+func (m *mockWebhookDeliverer) DeliverWebhooks(ctx context.Context) (int, error) {
+	return m.deliverWebhooksFunc(ctx)
+}
+
+// mockDataExporter implements DataExporter for testing.
+type mockDataExporter struct {
+	buildExportsFunc func(ctx context.Context) (int, error)
+	pruneExportsFunc func(ctx context.Context) (int, error)
+}
+
+func (m *mockDataExporter) BuildExports(ctx context.Context) (int, error) {
+	return m.buildExportsFunc(ctx)
+}
+
+func (m *mockDataExporter) PruneExports(ctx context.Context) (int, error) {
+	return m.pruneExportsFunc(ctx)
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func TestExpirePendingRegistrations(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	t.Run("expires registrations older than the TTL", func(t *testing.T) {
+		var cutoff time.Time
+		repo := &mockPendingExpirer{expirePendingFunc: func(ctx context.Context, before time.Time) (int64, error) {
+			cutoff = before
+			return 3, nil
+		}}
+
+		job := ExpirePendingRegistrations(repo, fixedClock(now), 24*time.Hour, discardLogger())
+		if err := job.Run(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := now.Add(-24 * time.Hour); !cutoff.Equal(want) {
+			t.Errorf("expected registrations made before %v to expire, got %v", want, cutoff)
+		}
+		if job.Interval != ExpirePendingInterval {
+			t.Errorf("expected the job to run every %v, got %v", ExpirePendingInterval, job.Interval)
+		}
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		repoErr := errors.New("database unavailable")
+		repo := &mockPendingExpirer{expirePendingFunc: func(ctx context.Context, before time.Time) (int64, error) {
+			return 0, repoErr
+		}}
+
+		job := ExpirePendingRegistrations(repo, fixedClock(now), time.Hour, discardLogger())
+		if err := job.Run(context.Background()); !errors.Is(err, repoErr) {
+			t.Errorf("expected the repository error, got %v", err)
+		}
+	})
+}
+
+func TestUnlockAccounts(t *testing.T) {
+	t.Run("unlocks expired accounts", func(t *testing.T) {
+		called := false
+		repo := &mockAccountUnlocker{unlockExpiredFunc: func(ctx context.Context) (int64, error) {
+			called = true
+			return 1, nil
+		}}
+
+		if err := UnlockAccounts(repo, discardLogger()).Run(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !called {
+			t.Error("expected expired locks to be cleared")
+		}
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		repoErr := errors.New("database unavailable")
+		repo := &mockAccountUnlocker{unlockExpiredFunc: func(ctx context.Context) (int64, error) {
+			return 0, repoErr
+		}}
+
+		if err := UnlockAccounts(repo, discardLogger()).Run(context.Background()); !errors.Is(err, repoErr) {
+			t.Errorf("expected the repository error, got %v", err)
+		}
+	})
+}
+
+func TestPruneLoginEvents(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	t.Run("deletes events from before the devices remembered", func(t *testing.T) {
+		var cutoff time.Time
+		repo := &mockLoginEventPruner{deleteLoginEventsBeforeFunc: func(ctx context.Context, before time.Time) (int64, error) {
+			cutoff = before
+			return 4, nil
+		}}
+
+		job := PruneLoginEvents(repo, fixedClock(now), discardLogger())
+		if err := job.Run(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := now.Add(-service.KnownDeviceWindow); !cutoff.Equal(want) {
+			t.Errorf("expected events before %v to be deleted, got %v", want, cutoff)
+		}
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		repoErr := errors.New("database unavailable")
+		repo := &mockLoginEventPruner{deleteLoginEventsBeforeFunc: func(ctx context.Context, before time.Time) (int64, error) {
+			return 0, repoErr
+		}}
+
+		if err := PruneLoginEvents(repo, fixedClock(now), discardLogger()).Run(context.Background()); !errors.Is(err, repoErr) {
+			t.Errorf("expected the repository error, got %v", err)
+		}
+	})
+}
+
+func TestSendRaceReminders(t *testing.T) {
+	t.Run("sends reminders every hour", func(t *testing.T) {
+		called := false
+		sender := &mockRaceReminderSender{sendRaceRemindersFunc: func(ctx context.Context) (int, error) {
+			called = true
+			return 2, nil
+		}}
+
+		job := SendRaceReminders(sender, discardLogger())
+		if err := job.Run(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !called {
+			t.Error("expected reminders to be sent")
+		}
+		if job.Interval != RaceRemindersInterval {
+			t.Errorf("expected the job to run every %v, got %v", RaceRemindersInterval, job.Interval)
+		}
+	})
+
+	t.Run("returns sender errors", func(t *testing.T) {
+		sendErr := errors.New("database unavailable")
+		sender := &mockRaceReminderSender{sendRaceRemindersFunc: func(ctx context.Context) (int, error) {
+			return 1, sendErr
+		}}
+
+		if err := SendRaceReminders(sender, discardLogger()).Run(context.Background()); !errors.Is(err, sendErr) {
+			t.Errorf("expected the sender error, got %v", err)
+		}
+	})
+}
+
+func TestSendRegistrationDigests(t *testing.T) {
+	t.Run("sends digests every hour", func(t *testing.T) {
+		called := false
+		sender := &mockRegistrationDigestSender{sendRegistrationDigestsFunc: func(ctx context.Context) (int, error) {
+			called = true
+			return 3, nil
+		}}
+
+		job := SendRegistrationDigests(sender, discardLogger())
+		if err := job.Run(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !called {
+			t.Error("expected digests to be sent")
+		}
+		if job.Interval != RegistrationDigestsInterval {
+			t.Errorf("expected the job to run every %v, got %v", RegistrationDigestsInterval, job.Interval)
+		}
+	})
+
+	t.Run("returns sender errors", func(t *testing.T) {
+		sendErr := errors.New("database unavailable")
+		sender := &mockRegistrationDigestSender{sendRegistrationDigestsFunc: func(ctx context.Context) (int, error) {
+			return 0, sendErr
+		}}
+
+		if err := SendRegistrationDigests(sender, discardLogger()).Run(context.Background()); !errors.Is(err, sendErr) {
+			t.Errorf("expected the sender error, got %v", err)
+		}
+	})
+}
+
+func TestReleaseUnfilledTeams(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	t.Run("releases teams unfilled by the current time", func(t *testing.T) {
+		var deadline time.Time
+		repo := &mockTeamReleaser{releaseUnfilledTeamsFunc: func(ctx context.Context, at time.Time) (int64, error) {
+			deadline = at
+			return 2, nil
+		}}
+
+		job := ReleaseUnfilledTeams(repo, fixedClock(now), discardLogger())
+		if err := job.Run(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !deadline.Equal(now) {
+			t.Errorf("expected teams due by %v to be released, got %v", now, deadline)
+		}
+		if job.Interval != ReleaseTeamsInterval {
+			t.Errorf("expected the job to run every %v, got %v", ReleaseTeamsInterval, job.Interval)
+		}
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		repoErr := errors.New("database unavailable")
+		repo := &mockTeamReleaser{releaseUnfilledTeamsFunc: func(ctx context.Context, at time.Time) (int64, error) {
+			return 0, repoErr
+		}}
+
+		job := ReleaseUnfilledTeams(repo, fixedClock(now), discardLogger())
+		if err := job.Run(context.Background()); !errors.Is(err, repoErr) {
+			t.Errorf("expected the repository error, got %v", err)
+		}
+	})
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	t.Run("delivers webhooks with time for a batch to time out", func(t *testing.T) {
+		called := false
+		deliverer := &mockWebhookDeliverer{deliverWebhooksFunc: func(ctx context.Context) (int, error) {
+			called = true
+			return 2, nil
+		}}
+
+		job := DeliverWebhooks(deliverer, discardLogger())
+		if err := job.Run(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !called {
+			t.Error("expected webhooks to be delivered")
+		}
+		if job.Interval != DeliverWebhooksInterval {
+			t.Errorf("expected the job to run every %v, got %v", DeliverWebhooksInterval, job.Interval)
+		}
+		if job.Timeout >= service.WebhookLease {
+			t.Errorf("expected runs to end within the lease of %v, got a timeout of %v", service.WebhookLease, job.Timeout)
+		}
+	})
+
+	t.Run("returns deliverer errors", func(t *testing.T) {
+		deliverErr := errors.New("database unavailable")
+		deliverer := &mockWebhookDeliverer{deliverWebhooksFunc: func(ctx context.Context) (int, error) {
+			return 0, deliverErr
+		}}
+
+		if err := DeliverWebhooks(deliverer, discardLogger()).Run(context.Background()); !errors.Is(err, deliverErr) {
+			t.Errorf("expected the deliverer error, got %v", err)
+		}
+	})
+}
+
+func TestBuildDataExports(t *testing.T) {
+	t.Run("builds exports within their lease", func(t *testing.T) {
+		called := false
+		exporter := &mockDataExporter{buildExportsFunc: func(ctx context.Context) (int, error) {
+			called = true
+			return 1, nil
+		}}
+
+		job := BuildDataExports(exporter, discardLogger())
+		if err := job.Run(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !called {
+			t.Error("expected exports to be built")
+		}
+		if job.Timeout >= service.DataExportLease {
+			t.Errorf("expected runs to end within the lease of %v, got a timeout of %v", service.DataExportLease, job.Timeout)
+		}
+	})
+
+	t.Run("returns builder errors", func(t *testing.T) {
+		buildErr := errors.New("storage unavailable")
+		exporter := &mockDataExporter{buildExportsFunc: func(ctx context.Context) (int, error) {
+			return 1, buildErr
+		}}
+
+		if err := BuildDataExports(exporter, discardLogger()).Run(context.Background()); !errors.Is(err, buildErr) {
+			t.Errorf("expected the builder error, got %v", err)
+		}
+	})
+}
+
+func TestPruneDataExports(t *testing.T) {
+	pruneErr := errors.New("storage unavailable")
+	exporter := &mockDataExporter{pruneExportsFunc: func(ctx context.Context) (int, error) {
+		return 0, pruneErr
+	}}
+
+	job := PruneDataExports(exporter, discardLogger())
+	if err := job.Run(context.Background()); !errors.Is(err, pruneErr) {
+		t.Errorf("expected the pruner error, got %v", err)
+	}
+	if job.Interval != PruneDataExportsInterval {
+		t.Errorf("expected the job to run every %v, got %v", PruneDataExportsInterval, job.Interval)
+	}
+}
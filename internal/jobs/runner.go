@@ -0,0 +1,170 @@
+// Package jobs runs periodic background work, such as expiring stale
+// registrations, alongside the web server.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Func does one run of a job. It should return promptly once ctx is done.
+type Func func(ctx context.Context) error
+
+// Job is work the Runner repeats on an interval.
+type Job struct {
+	// Name identifies the job in logs and must be unique within a Runner.
+	Name     string
+	Interval time.Duration
+	// Timeout bounds each run. Zero uses Interval.
+	Timeout time.Duration
+	Run     Func
+}
+
+// Runner runs registered jobs on their intervals until stopped. A job runs
+// first one interval after Start, and a tick that arrives while the previous
+// run is still going is skipped rather than queued, so runs of the same job
+// never overlap. Runs that fail or panic are logged and the job carries on.
+type Runner struct {
+	logger *slog.Logger
+	jobs   []Job
+
+	// newTicker returns a channel delivering a tick every interval and a
+	// function that stops it. Tests replace it to drive jobs by hand.
+	newTicker func(interval time.Duration) (<-chan time.Time, func())
+
+	mu      sync.Mutex
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewRunner creates a Runner that logs to logger.
+func NewRunner(logger *slog.Logger) *Runner {
+	return &Runner{
+		logger: logger,
+		newTicker: func(interval time.Duration) (<-chan time.Time, func()) {
+			t := time.NewTicker(interval)
+			return t.C, t.Stop
+		},
+	}
+}
+
+// Register adds a job to the runner. It panics if the job has no name or
+// function, its interval is not positive, its name is already taken, or the
+// runner has started.
+func (r *Runner) Register(job Job) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case r.started:
+		panic("jobs: Register called after Start")
+	case job.Name == "":
+		panic("jobs: job has no name")
+	case job.Run == nil:
+		panic("jobs: job " + job.Name + " has no function")
+	case job.Interval <= 0:
+		panic("jobs: job " + job.Name + " has a non-positive interval")
+	case job.Timeout < 0:
+		panic("jobs: job " + job.Name + " has a negative timeout")
+	}
+	for _, registered := range r.jobs {
+		if registered.Name == job.Name {
+			panic("jobs: job " + job.Name + " is registered twice")
+		}
+	}
+
+	if job.Timeout == 0 {
+		job.Timeout = job.Interval
+	}
+	r.jobs = append(r.jobs, job)
+}
+
+// Start begins running the registered jobs in the background. Cancelling
+// ctx has the same effect as Stop, except that it does not wait. Calling
+// Start again has no effect.
+func (r *Runner) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return
+	}
+	r.started = true
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.wg.Add(len(r.jobs))
+	for _, job := range r.jobs {
+		go r.loop(ctx, job)
+	}
+}
+
+// Stop cancels any runs in progress and waits for them to return.
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	cancel := r.cancel
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	r.wg.Wait()
+}
+
+// loop starts a run of job on every tick until ctx is done.
+func (r *Runner) loop(ctx context.Context, job Job) {
+	defer r.wg.Done()
+
+	ticks, stop := r.newTicker(job.Interval)
+	defer stop()
+
+	var running atomic.Bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+			if !running.CompareAndSwap(false, true) {
+				r.logger.Warn("job still running, skipping run", "job", job.Name)
+				continue
+			}
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+				defer running.Store(false)
+				r.run(ctx, job)
+			}()
+		}
+	}
+}
+
+// run runs job once, logging how it went.
+func (r *Runner) run(ctx context.Context, job Job) {
+	ctx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+
+	r.logger.Info("job started", "job", job.Name)
+	start := time.Now()
+	err := call(ctx, job.Run)
+	duration := time.Since(start)
+	if err != nil {
+		r.logger.Error("job failed", "job", job.Name, "duration", duration, "error", err)
+		return
+	}
+	r.logger.Info("job finished", "job", job.Name, "duration", duration)
+}
+
+// call runs fn, turning a panic into an error.
+func call(ctx context.Context, fn Func) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v\n%s", p, debug.Stack())
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,366 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeTicker hands out ticker channels the test sends ticks on by hand.
+type fakeTicker struct {
+	mu      sync.Mutex
+	ticks   map[time.Duration]chan time.Time
+	stopped atomic.Int32
+}
+
+// newTestRunner returns a runner whose tickers are driven by the returned
+// fakeTicker.
+func newTestRunner(logger *slog.Logger) (*Runner, *fakeTicker) {
+	ft := &fakeTicker{ticks: make(map[time.Duration]chan time.Time)}
+	r := NewRunner(logger)
+	r.newTicker = func(interval time.Duration) (<-chan time.Time, func()) {
+		return ft.channel(interval), func() { ft.stopped.Add(1) }
+	}
+	return r, ft
+}
+
+func (ft *fakeTicker) channel(interval time.Duration) chan time.Time {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ch, ok := ft.ticks[interval]
+	if !ok {
+		ch = make(chan time.Time)
+		ft.ticks[interval] = ch
+	}
+	return ch
+}
+
+// tick delivers a tick to the job running every interval, returning once
+// the job's loop has received it.
+func (ft *fakeTicker) tick(t *testing.T, interval time.Duration) {
+	t.Helper()
+	select {
+	case ft.channel(interval) <- time.Now():
+	case <-time.After(time.Second):
+		t.Fatalf("no job took the %v tick", interval)
+	}
+}
+
+// tickUntil ticks the job running every interval until it signals on ch.
+// A run clears its running flag just after it returns, so a tick straight
+// after one run may be skipped as an overlap.
+func (ft *fakeTicker) tickUntil(t *testing.T, interval time.Duration, ch <-chan struct{}, what string) {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for {
+		ft.tick(t, interval)
+		select {
+		case <-ch:
+			return
+		case <-deadline:
+			t.Fatalf("timed out waiting for %s", what)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// waitFor fails the test if ch is not closed or sent on within a second.
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to log to from several goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestRunner(t *testing.T) {
+	t.Run("runs a job on every tick", func(t *testing.T) {
+		r, ft := newTestRunner(discardLogger())
+		done := make(chan struct{})
+		r.Register(Job{Name: "count", Interval: time.Minute, Run: func(ctx context.Context) error {
+			done <- struct{}{}
+			return nil
+		}})
+		r.Start(context.Background())
+		defer r.Stop()
+
+		for i := range 3 {
+			ft.tickUntil(t, time.Minute, done, fmt.Sprintf("run %d", i+1))
+		}
+	})
+
+	t.Run("runs each job on its own interval", func(t *testing.T) {
+		r, ft := newTestRunner(discardLogger())
+		fast, slow := make(chan struct{}, 1), make(chan struct{}, 1)
+		r.Register(Job{Name: "fast", Interval: time.Minute, Run: func(ctx context.Context) error {
+			fast <- struct{}{}
+			return nil
+		}})
+		r.Register(Job{Name: "slow", Interval: time.Hour, Run: func(ctx context.Context) error {
+			slow <- struct{}{}
+			return nil
+		}})
+		r.Start(context.Background())
+		defer r.Stop()
+
+		ft.tick(t, time.Hour)
+		waitFor(t, slow, "the slow job")
+		select {
+		case <-fast:
+			t.Error("expected the fast job to wait for its own tick")
+		default:
+		}
+	})
+
+	t.Run("skips ticks while a run overruns its interval", func(t *testing.T) {
+		var logs syncBuffer
+		r, ft := newTestRunner(slog.New(slog.NewTextHandler(&logs, nil)))
+
+		var running, overlaps, runs atomic.Int32
+		started := make(chan struct{})
+		release := make(chan struct{})
+		r.Register(Job{Name: "slow", Interval: time.Minute, Timeout: time.Hour, Run: func(ctx context.Context) error {
+			if running.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			defer running.Add(-1)
+			runs.Add(1)
+			started <- struct{}{}
+			<-release
+			return nil
+		}})
+		r.Start(context.Background())
+		defer r.Stop()
+
+		ft.tick(t, time.Minute)
+		waitFor(t, started, "the first run")
+		// The loop takes each tick while the first run is blocked, so by
+		// the time the sends return it has decided to skip them
+		ft.tick(t, time.Minute)
+		ft.tick(t, time.Minute)
+		if got := runs.Load(); got != 1 {
+			t.Errorf("expected ticks during a run to be skipped, got %d runs", got)
+		}
+
+		release <- struct{}{}
+		ft.tickUntil(t, time.Minute, started, "the run after the overrun")
+		release <- struct{}{}
+
+		if got := overlaps.Load(); got != 0 {
+			t.Errorf("expected runs never to overlap, got %d overlaps", got)
+		}
+		if !strings.Contains(logs.String(), "job still running, skipping run") {
+			t.Errorf("expected skipped runs to be logged, got %q", logs.String())
+		}
+	})
+
+	t.Run("logs failures and keeps running", func(t *testing.T) {
+		var logs syncBuffer
+		r, ft := newTestRunner(slog.New(slog.NewTextHandler(&logs, nil)))
+		done := make(chan struct{})
+		r.Register(Job{Name: "broken", Interval: time.Minute, Run: func(ctx context.Context) error {
+			defer func() { done <- struct{}{} }()
+			return errors.New("database unavailable")
+		}})
+		r.Start(context.Background())
+		defer r.Stop()
+
+		ft.tick(t, time.Minute)
+		waitFor(t, done, "the failing run")
+		ft.tickUntil(t, time.Minute, done, "the run after a failure")
+
+		r.Stop()
+		out := logs.String()
+		if !strings.Contains(out, "job failed") || !strings.Contains(out, "database unavailable") || !strings.Contains(out, "job=broken") {
+			t.Errorf("expected the failure to be logged with the job name, got %q", out)
+		}
+	})
+
+	t.Run("recovers from panics", func(t *testing.T) {
+		var logs syncBuffer
+		r, ft := newTestRunner(slog.New(slog.NewTextHandler(&logs, nil)))
+		var runs atomic.Int32
+		r.Register(Job{Name: "panicky", Interval: time.Minute, Run: func(ctx context.Context) error {
+			runs.Add(1)
+			panic("nil map")
+		}})
+		r.Start(context.Background())
+
+		for runs.Load() < 2 {
+			ft.tick(t, time.Minute)
+		}
+		r.Stop()
+
+		if out := logs.String(); !strings.Contains(out, "panic: nil map") {
+			t.Errorf("expected the panic to be logged, got %q", out)
+		}
+	})
+
+	t.Run("bounds each run by its timeout", func(t *testing.T) {
+		r, ft := newTestRunner(discardLogger())
+		result := make(chan error, 1)
+		r.Register(Job{Name: "stuck", Interval: time.Minute, Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			result <- ctx.Err()
+			return ctx.Err()
+		}})
+		r.Start(context.Background())
+		defer r.Stop()
+
+		ft.tick(t, time.Minute)
+		select {
+		case err := <-result:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected the run's deadline to pass, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the run to be cancelled by its timeout")
+		}
+	})
+
+	t.Run("logs the start and finish of each run", func(t *testing.T) {
+		var logs syncBuffer
+		r, ft := newTestRunner(slog.New(slog.NewTextHandler(&logs, nil)))
+		done := make(chan struct{})
+		r.Register(Job{Name: "tidy", Interval: time.Minute, Run: func(ctx context.Context) error {
+			close(done)
+			return nil
+		}})
+		r.Start(context.Background())
+
+		ft.tick(t, time.Minute)
+		waitFor(t, done, "the run")
+		r.Stop()
+
+		out := logs.String()
+		for _, want := range []string{`msg="job started" job=tidy`, `msg="job finished" job=tidy duration=`} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected logs to contain %q, got %q", want, out)
+			}
+		}
+	})
+
+	t.Run("stop cancels runs in progress and waits for them", func(t *testing.T) {
+		r, ft := newTestRunner(discardLogger())
+		started := make(chan struct{})
+		var finished atomic.Bool
+		r.Register(Job{Name: "long", Interval: time.Minute, Timeout: time.Hour, Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			finished.Store(true)
+			return ctx.Err()
+		}})
+		r.Start(context.Background())
+
+		ft.tick(t, time.Minute)
+		waitFor(t, started, "the run")
+		r.Stop()
+
+		if !finished.Load() {
+			t.Error("expected Stop to wait for the run to return")
+		}
+		if got := ft.stopped.Load(); got != 1 {
+			t.Errorf("expected the ticker to be stopped, got %d stops", got)
+		}
+	})
+
+	t.Run("stops when the start context is cancelled", func(t *testing.T) {
+		r, ft := newTestRunner(discardLogger())
+		r.Register(Job{Name: "idle", Interval: time.Minute, Run: func(ctx context.Context) error { return nil }})
+		ctx, cancel := context.WithCancel(context.Background())
+		r.Start(ctx)
+
+		cancel()
+		r.Stop()
+		if got := ft.stopped.Load(); got != 1 {
+			t.Errorf("expected the ticker to be stopped, got %d stops", got)
+		}
+	})
+
+	t.Run("stop before start returns", func(t *testing.T) {
+		r, _ := newTestRunner(discardLogger())
+		r.Stop()
+	})
+}
+
+func TestRunnerRegister(t *testing.T) {
+	run := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name string
+		job  Job
+	}{
+		{name: "rejects jobs without a name", job: Job{Interval: time.Minute, Run: run}},
+		{name: "rejects jobs without a function", job: Job{Name: "empty", Interval: time.Minute}},
+		{name: "rejects non-positive intervals", job: Job{Name: "never", Run: run}},
+		{name: "rejects negative timeouts", job: Job{Name: "late", Interval: time.Minute, Timeout: -time.Second, Run: run}},
+		{name: "rejects duplicate names", job: Job{Name: "taken", Interval: time.Minute, Run: run}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner(discardLogger())
+			r.Register(Job{Name: "taken", Interval: time.Hour, Run: run})
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected Register to panic for %+v", tt.job)
+				}
+			}()
+			r.Register(tt.job)
+		})
+	}
+
+	t.Run("rejects jobs registered after start", func(t *testing.T) {
+		r := NewRunner(discardLogger())
+		r.Start(context.Background())
+		defer r.Stop()
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected Register to panic after Start")
+			}
+		}()
+		r.Register(Job{Name: "late", Interval: time.Minute, Run: run})
+	})
+
+	t.Run("defaults the timeout to the interval", func(t *testing.T) {
+		r := NewRunner(discardLogger())
+		r.Register(Job{Name: "tidy", Interval: time.Minute, Run: run})
+		if got := r.jobs[0].Timeout; got != time.Minute {
+			t.Errorf("expected a one minute timeout, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ConsoleMailer logs messages instead of sending them. It is intended for
+// local development, where the plain-text body includes any links needed to
+// complete a flow.
+type ConsoleMailer struct {
+	logger *slog.Logger
+}
+
+// NewConsoleMailer creates a ConsoleMailer that writes to logger.
+func NewConsoleMailer(logger *slog.Logger) *ConsoleMailer {
+	return &ConsoleMailer{logger: logger}
+}
+
+// Send logs the message.
+func (m *ConsoleMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.InfoContext(ctx, "email sent to console", "to", msg.To, "reply_to", msg.ReplyTo, "subject", msg.Subject, "text", msg.Text)
+	return nil
+}
@@ -0,0 +1,34 @@
+// Package mail sends transactional email such as account verification,
+// password reset and registration transfer messages.
+package mail
+
+import (
+	"context"
+	"strings"
+)
+
+// Message is an email with plain-text and HTML alternatives.
+type Message struct {
+	To string
+	// ReplyTo, when set, is the address replies go to in place of the
+	// sender's, such as the entrant who asked an organiser a question
+	ReplyTo string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers email messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// RedactAddress hides the local part of an email address so it can be
+// logged, keeping only the domain: "jane@example.com" becomes "***@example.com".
+func RedactAddress(addr string) string {
+	at := strings.LastIndex(addr, "@")
+	if at < 0 {
+		return "***"
+	}
+	return "***" + addr[at:]
+}
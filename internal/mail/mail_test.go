@@ -0,0 +1,548 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingMailer implements Mailer for testing.
+type recordingMailer struct {
+	mu      sync.Mutex
+	sent    []Message
+	err     error
+	release chan struct{}
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg Message) error {
+	if m.release != nil {
+		<-m.release
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return m.err
+}
+
+func TestRedactAddress(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "jane@example.com", want: "***@example.com"},
+		{input: "first.last+tag@mail.example.org", want: "***@mail.example.org"},
+		{input: "not-an-address", want: "***"},
+		{input: "", want: "***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := RedactAddress(tt.input); got != tt.want {
+				t.Errorf("RedactAddress(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerificationMessage(t *testing.T) {
+	msg, err := VerificationMessage("jane@example.com", VerificationData{
+		FirstName: "Jane",
+		VerifyURL: "https://firecrest.example/auth/verify?token=abc&x=1",
+		ExpiresIn: "24 hours",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if msg.To != "jane@example.com" {
+		t.Errorf("expected To jane@example.com, got %q", msg.To)
+	}
+	if msg.Subject == "" || strings.Contains(msg.Subject, "\n") {
+		t.Errorf("expected a single-line subject, got %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "Jane") || !strings.Contains(msg.Text, "https://firecrest.example/auth/verify?token=abc&x=1") {
+		t.Errorf("expected text body to contain name and link, got:\n%s", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "https://firecrest.example/auth/verify?token=abc&amp;x=1") {
+		t.Errorf("expected HTML body to contain escaped link, got:\n%s", msg.HTML)
+	}
+	if !strings.Contains(msg.HTML, "<html") {
+		t.Error("expected HTML body to be wrapped in the layout")
+	}
+}
+
+func TestPasswordResetMessage(t *testing.T) {
+	msg, err := PasswordResetMessage("jane@example.com", PasswordResetData{
+		FirstName: "<Jane>",
+		ResetURL:  "https://firecrest.example/auth/reset?token=abc",
+		ExpiresIn: "1 hour",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(msg.Text, "https://firecrest.example/auth/reset?token=abc") {
+		t.Errorf("expected text body to contain reset link, got:\n%s", msg.Text)
+	}
+	if strings.Contains(msg.HTML, "<Jane>") {
+		t.Error("expected HTML body to escape user-supplied names")
+	}
+}
+
+func TestRegistrationTransferMessage(t *testing.T) {
+	data := RegistrationTransferData{
+		SenderName: "Jane Runner",
+		RaceName:   "Ultra 50K",
+		EventName:  "Peak District Ultra",
+		AcceptURL:  "https://firecrest.example/transfers/accept?token=abc",
+		ExpiresIn:  "7 days",
+	}
+
+	msg, err := RegistrationTransferMessage("sam@example.com", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "Jane Runner has transferred their Ultra 50K place to you" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	if !strings.HasPrefix(msg.Text, "Hi,\n") {
+		t.Errorf("expected a greeting without a name, got:\n%s", msg.Text)
+	}
+	if !strings.Contains(msg.Text, "Peak District Ultra") || !strings.Contains(msg.Text, data.AcceptURL) {
+		t.Errorf("expected text body to contain event and link, got:\n%s", msg.Text)
+	}
+
+	data.FirstName = "Sam"
+	msg, err = RegistrationTransferMessage("sam@example.com", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(msg.Text, "Hi Sam,\n") {
+		t.Errorf("expected a greeting by name, got:\n%s", msg.Text)
+	}
+}
+
+func TestOrganisationInvitationMessage(t *testing.T) {
+	msg, err := OrganisationInvitationMessage("sam@example.com", OrganisationInvitationData{
+		InviterName:      "Jane Runner",
+		OrganisationName: "Peak Running Co",
+		Role:             "staff",
+		AcceptURL:        "https://firecrest.example/organisations/invitations/accept?token=abc",
+		ExpiresIn:        "7 days",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "Jane Runner has invited you to join Peak Running Co" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "as staff") || !strings.Contains(msg.Text, "https://firecrest.example/organisations/invitations/accept?token=abc") {
+		t.Errorf("expected text body to contain the role and link, got:\n%s", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "Accept the invitation") {
+		t.Errorf("expected HTML body to contain the accept button, got:\n%s", msg.HTML)
+	}
+}
+
+func TestRegistrationConfirmationMessage(t *testing.T) {
+	data := RegistrationConfirmationData{
+		FirstName: "Sam",
+		RaceName:  "Ultra 50K",
+		EventName: "Peak District Ultra",
+		Date:      "Saturday 14 March 2026 at 09:00",
+		EntryURL:  "https://firecrest.example/account/registrations#registration-7",
+	}
+
+	msg, err := RegistrationConfirmationMessage("sam@example.com", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "You're registered for Ultra 50K" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	for _, want := range []string{"Hi Sam,", "Peak District Ultra on Saturday 14 March 2026 at 09:00", data.EntryURL} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("expected text body to contain %q, got:\n%s", want, msg.Text)
+		}
+	}
+	if !strings.Contains(msg.HTML, `href="https://firecrest.example/account/registrations#registration-7"`) {
+		t.Errorf("expected HTML body to link to the entry, got:\n%s", msg.HTML)
+	}
+
+	data.Date = ""
+	msg, err = RegistrationConfirmationMessage("sam@example.com", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(msg.Text, "at Peak District Ultra.") {
+		t.Errorf("expected no date for an unscheduled race, got:\n%s", msg.Text)
+	}
+	if strings.Contains(msg.Text, "You start in") {
+		t.Errorf("expected no wave for a race without waves, got:\n%s", msg.Text)
+	}
+
+	data.Wave, data.WaveStart = "Wave B", "Saturday 14 March 2026 at 09:20"
+	msg, err = RegistrationConfirmationMessage("sam@example.com", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(msg.Text, "You start in Wave B, at Saturday 14 March 2026 at 09:20.") {
+		t.Errorf("expected the wave in the text body, got:\n%s", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "<strong>Wave B</strong>") {
+		t.Errorf("expected the wave in the HTML body, got:\n%s", msg.HTML)
+	}
+}
+
+func TestWaveStartChangedMessage(t *testing.T) {
+	msg, err := WaveStartChangedMessage("sam@example.com", WaveStartChangedData{
+		FirstName: "Sam",
+		RaceName:  "Ultra 50K",
+		EventName: "Peak District Ultra",
+		Wave:      "Wave <B>",
+		OldStart:  "Saturday 14 March 2026 at 09:20",
+		NewStart:  "Saturday 14 March 2026 at 09:40",
+		EntryURL:  "https://firecrest.example/account/registrations#registration-7",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "New start time for Ultra 50K" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "Wave <B> now starts on Saturday 14 March 2026 at 09:40, instead of Saturday 14 March 2026 at 09:20.") {
+		t.Errorf("expected text body to give both start times, got:\n%s", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "Wave &lt;B&gt;") {
+		t.Errorf("expected HTML body to escape the wave name, got:\n%s", msg.HTML)
+	}
+}
+
+func TestRaceReminderMessage(t *testing.T) {
+	msg, err := RaceReminderMessage("sam@example.com", RaceReminderData{
+		FirstName:      "Sam",
+		RaceName:       "Ultra 50K",
+		EventName:      "Peak <District> Ultra",
+		Date:           "Saturday 14 March 2026 at 09:00",
+		EntryURL:       "https://firecrest.example/account/registrations#registration-7",
+		UnsubscribeURL: "https://firecrest.example/email/unsubscribe?token=abc&x=1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "Ultra 50K is on Saturday 14 March 2026 at 09:00" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "https://firecrest.example/email/unsubscribe?token=abc&x=1") {
+		t.Errorf("expected text body to contain the unsubscribe link, got:\n%s", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "https://firecrest.example/email/unsubscribe?token=abc&amp;x=1") {
+		t.Errorf("expected HTML body to contain the escaped unsubscribe link, got:\n%s", msg.HTML)
+	}
+	if !strings.Contains(msg.HTML, "Peak &lt;District&gt; Ultra") {
+		t.Errorf("expected HTML body to escape the event name, got:\n%s", msg.HTML)
+	}
+}
+
+func TestNewRegistrationMessage(t *testing.T) {
+	msg, err := NewRegistrationMessage("ada@example.com", NewRegistrationData{
+		FirstName:   "Ada",
+		EntrantName: "Sam <Runner>",
+		RaceName:    "10K",
+		EventName:   "Riverside Run",
+		EntrantsURL: "https://firecrest.example/admin/races/3/entrants",
+		SettingsURL: "https://firecrest.example/admin/dashboard?organisation=1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "New entry for 10K: Sam <Runner>" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "https://firecrest.example/admin/races/3/entrants") {
+		t.Errorf("expected text body to link to the entrants, got:\n%s", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "Sam &lt;Runner&gt; has entered") {
+		t.Errorf("expected HTML body to escape the entrant's name, got:\n%s", msg.HTML)
+	}
+}
+
+func TestEventEnquiryMessage(t *testing.T) {
+	msg, err := EventEnquiryMessage("hello@riverside.example", EventEnquiryData{
+		EventName:    "Riverside Run",
+		SenderName:   "Sam Runner",
+		SenderEmail:  "sam@example.com",
+		Message:      "Is there parking <near> the start?",
+		EnquiriesURL: "https://firecrest.example/admin/events/4/enquiries",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "Question about Riverside Run from Sam Runner" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	if msg.ReplyTo != `"Sam Runner" <sam@example.com>` {
+		t.Errorf("expected replies to go to the sender, got %q", msg.ReplyTo)
+	}
+	if !strings.Contains(msg.Text, "Is there parking <near> the start?") {
+		t.Errorf("expected text body to carry the message, got:\n%s", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "Is there parking &lt;near&gt; the start?") {
+		t.Errorf("expected HTML body to escape the message, got:\n%s", msg.HTML)
+	}
+}
+
+func TestFailedSignInsMessage(t *testing.T) {
+	t.Run("warns of failed attempts", func(t *testing.T) {
+		msg, err := FailedSignInsMessage("jane@example.com", FailedSignInsData{
+			FirstName:   "Jane",
+			Attempts:    3,
+			SessionsURL: "https://firecrest.example/account/sessions",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if msg.Subject != "Failed attempts to sign in to your Firecrest account" {
+			t.Errorf("unexpected subject %q", msg.Subject)
+		}
+		if !strings.Contains(msg.Text, "3 failed attempts") || !strings.Contains(msg.Text, "https://firecrest.example/account/sessions") {
+			t.Errorf("expected text body to count the attempts and link to sessions, got:\n%s", msg.Text)
+		}
+	})
+
+	t.Run("says when the account is locked until", func(t *testing.T) {
+		msg, err := FailedSignInsMessage("jane@example.com", FailedSignInsData{
+			FirstName:   "Jane",
+			Attempts:    5,
+			LockedUntil: "10:15 on 1 May 2026",
+			SessionsURL: "https://firecrest.example/account/sessions",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if msg.Subject != "Your Firecrest account has been locked" {
+			t.Errorf("unexpected subject %q", msg.Subject)
+		}
+		if !strings.Contains(msg.HTML, "10:15 on 1 May 2026") {
+			t.Errorf("expected HTML body to say when the lock ends, got:\n%s", msg.HTML)
+		}
+	})
+}
+
+func TestNewSignInMessage(t *testing.T) {
+	msg, err := NewSignInMessage("jane@example.com", NewSignInData{
+		FirstName:   "Jane",
+		Time:        "09:30 on 1 May 2026",
+		Device:      "<script>Mozilla/5.0</script>",
+		IPAddress:   "203.0.113.9",
+		Location:    "Unknown",
+		SessionsURL: "https://firecrest.example/account/sessions",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"09:30 on 1 May 2026", "203.0.113.9", "Location: Unknown", "https://firecrest.example/account/sessions"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("expected text body to contain %q, got:\n%s", want, msg.Text)
+		}
+	}
+	if strings.Contains(msg.HTML, "<script>") {
+		t.Error("expected HTML body to escape the user agent")
+	}
+}
+
+func TestDataExportReadyMessage(t *testing.T) {
+	msg, err := DataExportReadyMessage("jane@example.com", DataExportReadyData{
+		FirstName:   "Jane",
+		DownloadURL: "https://firecrest.example/account/data-export?token=abc",
+		ExpiresIn:   "24 hours",
+		SessionsURL: "https://firecrest.example/account/sessions",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "Your data is ready to download" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	for _, want := range []string{"https://firecrest.example/account/data-export?token=abc", "works once", "24 hours", "https://firecrest.example/account/sessions"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("expected text body to contain %q, got:\n%s", want, msg.Text)
+		}
+	}
+	if !strings.Contains(msg.HTML, `href="https://firecrest.example/account/data-export?token=abc"`) {
+		t.Errorf("expected HTML body to link to the download, got:\n%s", msg.HTML)
+	}
+}
+
+func TestRegistrationDigestMessage(t *testing.T) {
+	msg, err := RegistrationDigestMessage("ada@example.com", RegistrationDigestData{
+		FirstName:        "Ada",
+		OrganisationName: "Riverside Harriers",
+		Date:             "Thursday 30 April 2026",
+		Events: []RegistrationDigestEvent{
+			{Name: "Riverside Run", Registrations: 3, Revenue: "£60.00"},
+			{Name: "Hill Climb", Registrations: 1},
+		},
+		Registrations: 4,
+		Revenue:       "£60.00",
+		SettingsURL:   "https://firecrest.example/admin/dashboard?organisation=1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "Riverside Harriers: 4 new entries on Thursday 30 April 2026" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	for _, line := range []string{"Riverside Run: 3 entries, £60.00", "Hill Climb: 1 entry", "Total: 4 entries, £60.00"} {
+		if !strings.Contains(msg.Text, line) {
+			t.Errorf("expected text body to contain %q, got:\n%s", line, msg.Text)
+		}
+	}
+	if !strings.Contains(msg.HTML, "Hill Climb") {
+		t.Errorf("expected HTML body to list every event, got:\n%s", msg.HTML)
+	}
+}
+
+func TestBuildMIME(t *testing.T) {
+	msg := Message{
+		To:      "jane@example.com",
+		ReplyTo: "Sam Runner <sam@example.com>",
+		Subject: "Vérifiez votre adresse",
+		Text:    "Hello Jane\n",
+		HTML:    "<p>Hello Jane</p>",
+	}
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	raw, err := buildMIME("Firecrest <no-reply@firecrest.example>", msg, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("expected subject %q, got %q (%v)", msg.Subject, subject, err)
+	}
+	if got := parsed.Header.Get("To"); got != msg.To {
+		t.Errorf("expected To %q, got %q", msg.To, got)
+	}
+	if got := parsed.Header.Get("Reply-To"); got != `"Sam Runner" <sam@example.com>` {
+		t.Errorf("expected Reply-To the sender, got %q", got)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q (%v)", mediaType, err)
+	}
+
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		b, _ := io.ReadAll(part)
+		// Quoted-printable encoding normalises line endings to CRLF.
+		bodies = append(bodies, strings.ReplaceAll(string(b), "\r\n", "\n"))
+	}
+
+	if len(bodies) != 2 || bodies[0] != msg.Text || bodies[1] != msg.HTML {
+		t.Errorf("expected text and HTML parts, got %q", bodies)
+	}
+}
+
+func TestBuildMIMERefusesHeadersInReplyTo(t *testing.T) {
+	msg := Message{To: "jane@example.com", ReplyTo: "sam@example.com\r\nBcc: everyone@example.com", Subject: "Hi"}
+
+	if _, err := buildMIME("no-reply@firecrest.example", msg, time.Now()); err == nil {
+		t.Error("expected an address with a line break to be refused")
+	}
+}
+
+func TestQueue(t *testing.T) {
+	t.Run("delivers queued messages before Close returns", func(t *testing.T) {
+		mailer := &recordingMailer{}
+		q := NewQueue(mailer, slog.New(slog.NewTextHandler(io.Discard, nil)), 2, 10)
+
+		for range 5 {
+			if err := q.Send(context.Background(), Message{To: "jane@example.com"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		q.Close()
+
+		if len(mailer.sent) != 5 {
+			t.Errorf("expected 5 messages delivered, got %d", len(mailer.sent))
+		}
+	})
+
+	t.Run("returns ErrQueueFull without blocking", func(t *testing.T) {
+		var logs bytes.Buffer
+		mailer := &recordingMailer{release: make(chan struct{})}
+		q := NewQueue(mailer, slog.New(slog.NewTextHandler(&logs, nil)), 1, 1)
+
+		// The first message occupies the worker, the second fills the buffer.
+		var err error
+		for range 3 {
+			if err = q.Send(context.Background(), Message{To: "jane@example.com"}); err != nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		close(mailer.release)
+		q.Close()
+
+		if !errors.Is(err, ErrQueueFull) {
+			t.Errorf("expected ErrQueueFull, got %v", err)
+		}
+		if strings.Contains(logs.String(), "jane@") {
+			t.Errorf("expected recipient to be redacted, got logs:\n%s", logs.String())
+		}
+	})
+
+	t.Run("logs delivery failures with the recipient redacted", func(t *testing.T) {
+		var logs bytes.Buffer
+		mailer := &recordingMailer{err: errors.New("connection refused")}
+		q := NewQueue(mailer, slog.New(slog.NewTextHandler(&logs, nil)), 1, 1)
+
+		if err := q.Send(context.Background(), Message{To: "jane@example.com", Subject: "Verify"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		q.Close()
+
+		out := logs.String()
+		if !strings.Contains(out, "failed to send email") || !strings.Contains(out, "***@example.com") {
+			t.Errorf("expected redacted failure log, got:\n%s", out)
+		}
+		if strings.Contains(out, "jane@") {
+			t.Errorf("expected full address to be omitted, got:\n%s", out)
+		}
+	})
+
+	t.Run("returns ErrQueueClosed after Close", func(t *testing.T) {
+		q := NewQueue(&recordingMailer{}, slog.New(slog.NewTextHandler(io.Discard, nil)), 1, 1)
+		q.Close()
+
+		if err := q.Send(context.Background(), Message{}); !errors.Is(err, ErrQueueClosed) {
+			t.Errorf("expected ErrQueueClosed, got %v", err)
+		}
+	})
+}
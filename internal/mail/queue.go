@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned when a message cannot be queued because every
+// slot in the queue is taken.
+var ErrQueueFull = errors.New("mail queue is full")
+
+// ErrQueueClosed is returned when a message is sent after Close.
+var ErrQueueClosed = errors.New("mail queue is closed")
+
+// SendTimeout bounds each delivery attempt made by a Queue worker.
+const SendTimeout = 30 * time.Second
+
+// Queue is a Mailer that hands messages to a fixed pool of workers so that
+// callers never wait on the underlying mailer. Delivery failures are logged
+// with the recipient redacted to its domain.
+type Queue struct {
+	mailer Mailer
+	logger *slog.Logger
+	jobs   chan Message
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewQueue starts workers goroutines delivering through mailer, buffering
+// up to size pending messages.
+func NewQueue(mailer Mailer, logger *slog.Logger, workers, size int) *Queue {
+	q := &Queue{
+		mailer: mailer,
+		logger: logger,
+		jobs:   make(chan Message, size),
+	}
+
+	q.wg.Add(workers)
+	for range workers {
+		go q.work()
+	}
+	return q
+}
+
+// Send queues msg for delivery without blocking. It returns ErrQueueFull if
+// the queue has no room.
+func (q *Queue) Send(ctx context.Context, msg Message) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.jobs <- msg:
+		return nil
+	default:
+		q.logger.Error("mail queue full, dropping message", "to", RedactAddress(msg.To), "subject", msg.Subject)
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting messages and waits for queued ones to be delivered.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.jobs)
+	q.mu.Unlock()
+
+	q.wg.Wait()
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for msg := range q.jobs {
+		// The request that queued the message has usually finished by now,
+		// so each delivery gets its own deadline.
+		ctx, cancel := context.WithTimeout(context.Background(), SendTimeout)
+		if err := q.mailer.Send(ctx, msg); err != nil {
+			q.logger.Error("failed to send email", "to", RedactAddress(msg.To), "subject", msg.Subject, "error", err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,189 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// ErrSTARTTLSUnsupported is returned when the SMTP server does not offer
+// STARTTLS; credentials and messages are never sent in the clear.
+var ErrSTARTTLSUnsupported = errors.New("smtp server does not support STARTTLS")
+
+// SMTPConfig holds the connection settings for an SMTP relay.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer sends messages through an SMTP relay, upgrading the connection
+// with STARTTLS before authenticating.
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPMailer creates an SMTPMailer for the given relay.
+func NewSMTPMailer(cfg SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg}
+}
+
+// Send delivers msg, honouring any deadline on ctx.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	body, err := buildMIME(m.cfg.From, msg, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build message: %w", err)
+	}
+
+	c, err := m.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Mail(m.cfg.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// buildMIME renders msg as a multipart/alternative message with quoted-printable parts.
+// Check connects to the relay and signs in as Send would, without sending
+// anything, to confirm the relay is reachable and accepts the credentials.
+func (m *SMTPMailer) Check(ctx context.Context) error {
+	c, err := m.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return c.Quit()
+}
+
+// open connects to the relay, upgrades the connection with STARTTLS and
+// authenticates, honouring any deadline on ctx.
+func (m *SMTPMailer) open(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start smtp session: %w", err)
+	}
+
+	if ok, _ := c.Extension("STARTTLS"); !ok {
+		c.Close()
+		return nil, ErrSTARTTLSUnsupported
+	}
+	if err := c.StartTLS(&tls.Config{ServerName: m.cfg.Host, MinVersion: tls.VersionTLS12}); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to start tls: %w", err)
+	}
+
+	if m.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func buildMIME(from string, msg Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	mw := multipart.NewWriter(&buf)
+
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "From: %s\r\n", from)
+	fmt.Fprintf(&header, "To: %s\r\n", msg.To)
+	if msg.ReplyTo != "" {
+		// Written as parsed, so a crafted address cannot add headers
+		replyTo, err := mail.ParseAddress(msg.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply-to address: %w", err)
+		}
+		fmt.Fprintf(&header, "Reply-To: %s\r\n", replyTo.String())
+	}
+	fmt.Fprintf(&header, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&header, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&header, "Message-ID: <%s@firecrest>\r\n", messageID())
+	fmt.Fprintf(&header, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&header, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain; charset=utf-8", body: msg.Text},
+		{contentType: "text/html; charset=utf-8", body: msg.HTML},
+	}
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(p.body)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return append(header.Bytes(), buf.Bytes()...), nil
+}
+
+func messageID() string {
+	b := make([]byte, 16)
+	//nolint:errcheck // crypto/rand.Read never returns an error
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,322 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"net/mail"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Template names; each has a .txt.tmpl and .html.tmpl file.
+const (
+	templateVerification             = "verification"
+	templatePasswordReset            = "password_reset"
+	templateRegistrationTransfer     = "registration_transfer"
+	templateOrganisationInvitation   = "organisation_invitation"
+	templateRegistrationConfirmation = "registration_confirmation"
+	templateRaceReminder             = "race_reminder"
+	templateWaveStartChanged         = "wave_start_changed"
+	templateNewRegistration          = "new_registration"
+	templateRegistrationDigest       = "registration_digest"
+	templateEventEnquiry             = "event_enquiry"
+	templateFailedSignIns            = "failed_sign_ins"
+	templateNewSignIn                = "new_sign_in"
+	templateDataExportReady          = "data_export_ready"
+)
+
+var (
+	textTemplates = map[string]*texttemplate.Template{
+		templateVerification:             mustParseText(templateVerification),
+		templatePasswordReset:            mustParseText(templatePasswordReset),
+		templateRegistrationTransfer:     mustParseText(templateRegistrationTransfer),
+		templateOrganisationInvitation:   mustParseText(templateOrganisationInvitation),
+		templateRegistrationConfirmation: mustParseText(templateRegistrationConfirmation),
+		templateRaceReminder:             mustParseText(templateRaceReminder),
+		templateWaveStartChanged:         mustParseText(templateWaveStartChanged),
+		templateNewRegistration:          mustParseText(templateNewRegistration),
+		templateRegistrationDigest:       mustParseText(templateRegistrationDigest),
+		templateEventEnquiry:             mustParseText(templateEventEnquiry),
+		templateFailedSignIns:            mustParseText(templateFailedSignIns),
+		templateNewSignIn:                mustParseText(templateNewSignIn),
+		templateDataExportReady:          mustParseText(templateDataExportReady),
+	}
+	htmlTemplates = map[string]*htmltemplate.Template{
+		templateVerification:             mustParseHTML(templateVerification),
+		templatePasswordReset:            mustParseHTML(templatePasswordReset),
+		templateRegistrationTransfer:     mustParseHTML(templateRegistrationTransfer),
+		templateOrganisationInvitation:   mustParseHTML(templateOrganisationInvitation),
+		templateRegistrationConfirmation: mustParseHTML(templateRegistrationConfirmation),
+		templateRaceReminder:             mustParseHTML(templateRaceReminder),
+		templateWaveStartChanged:         mustParseHTML(templateWaveStartChanged),
+		templateNewRegistration:          mustParseHTML(templateNewRegistration),
+		templateRegistrationDigest:       mustParseHTML(templateRegistrationDigest),
+		templateEventEnquiry:             mustParseHTML(templateEventEnquiry),
+		templateFailedSignIns:            mustParseHTML(templateFailedSignIns),
+		templateNewSignIn:                mustParseHTML(templateNewSignIn),
+		templateDataExportReady:          mustParseHTML(templateDataExportReady),
+	}
+)
+
+func mustParseText(name string) *texttemplate.Template {
+	return texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/"+name+".txt.tmpl"))
+}
+
+func mustParseHTML(name string) *htmltemplate.Template {
+	return htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html.tmpl", "templates/"+name+".html.tmpl"))
+}
+
+// VerificationData is the data rendered into the email verification message.
+type VerificationData struct {
+	FirstName string
+	VerifyURL string
+	ExpiresIn string
+}
+
+// PasswordResetData is the data rendered into the password reset message.
+type PasswordResetData struct {
+	FirstName string
+	ResetURL  string
+	ExpiresIn string
+}
+
+// RegistrationTransferData is the data rendered into the message telling a
+// recipient that a race place has been transferred to them. FirstName may be
+// empty for recipients who have not signed up yet.
+type RegistrationTransferData struct {
+	FirstName  string
+	SenderName string
+	RaceName   string
+	EventName  string
+	AcceptURL  string
+	ExpiresIn  string
+}
+
+// OrganisationInvitationData is the data rendered into the message inviting
+// someone to help run an organisation. FirstName may be empty for invitees
+// who have not signed up yet.
+type OrganisationInvitationData struct {
+	FirstName        string
+	InviterName      string
+	OrganisationName string
+	Role             string
+	AcceptURL        string
+	ExpiresIn        string
+}
+
+// RegistrationConfirmationData is the data rendered into the message
+// confirming an entrant's registration. Date is when the race starts, and may
+// be empty if it has not been scheduled yet. Wave and WaveStart are empty
+// unless the race starts in waves.
+type RegistrationConfirmationData struct {
+	FirstName string
+	RaceName  string
+	EventName string
+	Date      string
+	Wave      string
+	WaveStart string
+	EntryURL  string
+}
+
+// RaceReminderData is the data rendered into the message reminding an
+// entrant that their race is coming up.
+type RaceReminderData struct {
+	FirstName      string
+	RaceName       string
+	EventName      string
+	Date           string
+	EntryURL       string
+	UnsubscribeURL string
+}
+
+// WaveStartChangedData is the data rendered into the message telling an
+// entrant that their start wave has moved.
+type WaveStartChangedData struct {
+	FirstName string
+	RaceName  string
+	EventName string
+	Wave      string
+	OldStart  string
+	NewStart  string
+	EntryURL  string
+}
+
+// NewRegistrationData is the data rendered into the message telling an
+// organisation member about a new registration for one of its races.
+type NewRegistrationData struct {
+	FirstName   string
+	EntrantName string
+	RaceName    string
+	EventName   string
+	EntrantsURL string
+	SettingsURL string
+}
+
+// EventEnquiryData is the data rendered into the message relaying a
+// question sent through an event's contact form to its organisers.
+type EventEnquiryData struct {
+	EventName    string
+	SenderName   string
+	SenderEmail  string
+	Message      string
+	EnquiriesURL string
+}
+
+// RegistrationDigestData is the data rendered into the daily digest of an
+// organisation's new registrations. Revenue amounts are formatted, with
+// amounts in different currencies joined.
+type RegistrationDigestData struct {
+	FirstName        string
+	OrganisationName string
+	Date             string
+	Events           []RegistrationDigestEvent
+	Registrations    int
+	Revenue          string
+	SettingsURL      string
+}
+
+// RegistrationDigestEvent is one event's line in a registration digest.
+type RegistrationDigestEvent struct {
+	Name          string
+	Registrations int
+	Revenue       string
+}
+
+// FailedSignInsData is the data rendered into the message warning a user
+// of failed attempts to sign in to their account. LockedUntil is empty
+// unless the attempts locked the account.
+type FailedSignInsData struct {
+	FirstName   string
+	Attempts    int
+	LockedUntil string
+	SessionsURL string
+}
+
+// NewSignInData is the data rendered into the message telling a user their
+// account was signed in to from a device it has not been used on recently.
+type NewSignInData struct {
+	FirstName   string
+	Time        string
+	Device      string
+	IPAddress   string
+	Location    string
+	SessionsURL string
+}
+
+// DataExportReadyData is the data rendered into the message telling a user
+// the copy of their data they asked for is ready to download.
+type DataExportReadyData struct {
+	FirstName   string
+	DownloadURL string
+	ExpiresIn   string
+	SessionsURL string
+}
+
+// VerificationMessage builds the email asking a new user to verify their address.
+func VerificationMessage(to string, data VerificationData) (Message, error) {
+	return render(templateVerification, to, data)
+}
+
+// PasswordResetMessage builds the email containing a password reset link.
+func PasswordResetMessage(to string, data PasswordResetData) (Message, error) {
+	return render(templatePasswordReset, to, data)
+}
+
+// RegistrationTransferMessage builds the email asking the recipient of a
+// transferred registration to accept it.
+func RegistrationTransferMessage(to string, data RegistrationTransferData) (Message, error) {
+	return render(templateRegistrationTransfer, to, data)
+}
+
+// OrganisationInvitationMessage builds the email inviting someone to join an
+// organisation's team.
+func OrganisationInvitationMessage(to string, data OrganisationInvitationData) (Message, error) {
+	return render(templateOrganisationInvitation, to, data)
+}
+
+// RegistrationConfirmationMessage builds the email confirming an entrant's
+// registration, with a link to their entry.
+func RegistrationConfirmationMessage(to string, data RegistrationConfirmationData) (Message, error) {
+	return render(templateRegistrationConfirmation, to, data)
+}
+
+// RaceReminderMessage builds the email reminding an entrant that their race
+// is coming up, with a link to unsubscribe from reminders.
+func RaceReminderMessage(to string, data RaceReminderData) (Message, error) {
+	return render(templateRaceReminder, to, data)
+}
+
+// WaveStartChangedMessage builds the email telling an entrant their start
+// wave's start time has changed.
+func WaveStartChangedMessage(to string, data WaveStartChangedData) (Message, error) {
+	return render(templateWaveStartChanged, to, data)
+}
+
+// NewRegistrationMessage builds the email telling an organisation member
+// who asked to hear about every entry that someone has registered.
+func NewRegistrationMessage(to string, data NewRegistrationData) (Message, error) {
+	return render(templateNewRegistration, to, data)
+}
+
+// RegistrationDigestMessage builds the daily email summarising an
+// organisation's new registrations for a member who asked for it.
+func RegistrationDigestMessage(to string, data RegistrationDigestData) (Message, error) {
+	return render(templateRegistrationDigest, to, data)
+}
+
+// EventEnquiryMessage builds the email relaying a question about an event
+// to its organisers, replying to the sender.
+func EventEnquiryMessage(to string, data EventEnquiryData) (Message, error) {
+	msg, err := render(templateEventEnquiry, to, data)
+	if err != nil {
+		return Message{}, err
+	}
+	msg.ReplyTo = (&mail.Address{Name: data.SenderName, Address: data.SenderEmail}).String()
+	return msg, nil
+}
+
+// FailedSignInsMessage builds the email warning a user of failed attempts
+// to sign in to their account, or that the attempts locked it.
+func FailedSignInsMessage(to string, data FailedSignInsData) (Message, error) {
+	return render(templateFailedSignIns, to, data)
+}
+
+// NewSignInMessage builds the email telling a user their account was
+// signed in to from a new device.
+func NewSignInMessage(to string, data NewSignInData) (Message, error) {
+	return render(templateNewSignIn, to, data)
+}
+
+// DataExportReadyMessage builds the email with the link to download a
+// user's copy of their data.
+func DataExportReadyMessage(to string, data DataExportReadyData) (Message, error) {
+	return render(templateDataExportReady, to, data)
+}
+
+// render executes the named template pair. Each template defines a "subject"
+// and a "content" block; HTML content is wrapped in the shared layout.
+func render(name, to string, data any) (Message, error) {
+	text, html := textTemplates[name], htmlTemplates[name]
+
+	var subject, textBody, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.ExecuteTemplate(&textBody, "content", data); err != nil {
+		return Message{}, err
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(textBody.String()) + "\n",
+		HTML:    htmlBody.String(),
+	}, nil
+}
@@ -0,0 +1,30 @@
+// Package markdown renders organiser-written markdown, such as event
+// descriptions, as HTML that is safe to serve to the public.
+//
+// Raw HTML in the source is dropped rather than passed through, and links
+// and images with dangerous schemes such as javascript: lose their URL, so
+// the output cannot carry script however the source is written.
+package markdown
+
+import (
+	"context"
+	"io"
+
+	"github.com/a-h/templ"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// md must never be given html.WithUnsafe, which would let raw HTML through.
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.Linkify, extension.Strikethrough),
+	goldmark.WithRendererOptions(html.WithHardWraps()),
+)
+
+// Render returns a component that writes src as sanitised HTML.
+func Render(src string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		return md.Convert([]byte(src), w)
+	})
+}
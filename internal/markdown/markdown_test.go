@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, src string) string {
+	t.Helper()
+
+	var b strings.Builder
+	if err := Render(src).Render(context.Background(), &b); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	return b.String()
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{name: "renders emphasis", src: "A **tough** climb", want: []string{"<strong>tough</strong>"}},
+		{name: "renders lists", src: "- Water\n- Gels", want: []string{"<li>Water</li>", "<li>Gels</li>"}},
+		{name: "renders links", src: "[Route](https://example.com/route)", want: []string{`<a href="https://example.com/route">Route</a>`}},
+		{name: "links bare URLs", src: "See https://example.com", want: []string{`<a href="https://example.com">`}},
+		{name: "keeps single line breaks", src: "Start\nFinish", want: []string{"Start<br>"}},
+		{name: "escapes text", src: "5 < 10 & 10 > 5", want: []string{"5 &lt; 10 &amp; 10 &gt; 5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render(t, tt.src)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("expected output to contain %q, got %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderStripsScript(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "script block", src: "<script>alert(1)</script>"},
+		{name: "inline script", src: "Hello <script>alert(1)</script> there"},
+		{name: "event handler attribute", src: `<img src="x" onerror="alert(1)">`},
+		{name: "javascript link", src: "[click](javascript:alert(1))"},
+		{name: "javascript image", src: "![x](javascript:alert(1))"},
+		{name: "javascript autolink", src: "<javascript:alert(1)>"},
+		{name: "iframe", src: `<iframe src="https://example.com"></iframe>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.ToLower(render(t, tt.src))
+			for _, banned := range []string{"<script", `href="javascript:`, `src="javascript:`, "onerror=", "<iframe", `<img src="x"`} {
+				if strings.Contains(got, banned) {
+					t.Errorf("expected output without %q, got %q", banned, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package metrics exposes application metrics in the Prometheus text format.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is the route metrics are served on. Requests to it are not recorded.
+const Path = "/metrics"
+
+// Metrics holds the application's collectors and the registry they belong to.
+type Metrics struct {
+	registry       *prometheus.Registry
+	requests       *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	signInFailures prometheus.Counter
+	lockouts       prometheus.Counter
+}
+
+// New creates a Metrics with its own registry, including the standard Go
+// runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "firecrest_http_requests_total",
+			Help: "HTTP requests handled, by route pattern and status class.",
+		}, []string{"route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "firecrest_http_request_duration_seconds",
+			Help:    "Time taken to handle HTTP requests, by route pattern and status class.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "status"}),
+		signInFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "firecrest_auth_sign_in_failures_total",
+			Help: "Sign-in attempts rejected for invalid credentials.",
+		}),
+		lockouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "firecrest_auth_lockouts_total",
+			Help: "Accounts locked after too many failed sign-in attempts.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		m.signInFailures,
+		m.lockouts,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Register adds an additional collector, such as a PoolCollector.
+func (m *Metrics) Register(c prometheus.Collector) error {
+	return m.registry.Register(c)
+}
+
+// Handler serves the registered metrics for scraping.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// SignInFailed records a sign-in rejected for invalid credentials.
+func (m *Metrics) SignInFailed() {
+	m.signInFailures.Inc()
+}
+
+// AccountLocked records an account being locked out.
+func (m *Metrics) AccountLocked() {
+	m.lockouts.Inc()
+}
+
+// Middleware records the count and duration of each request, labelled by the
+// ServeMux pattern that matched it. It must wrap the ServeMux itself so the
+// pattern is set by the time the request completes.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == Path {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(rec, r)
+
+		// Unmatched requests share a label so arbitrary paths can't grow
+		// the number of series.
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		status := statusClass(rec.status)
+		m.requests.WithLabelValues(route, status).Inc()
+		m.duration.WithLabelValues(route, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusClass reduces a status code to its class, such as "2xx".
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// statusRecorder captures the status code written by downstream handlers.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying ResponseWriter to http.ResponseController.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
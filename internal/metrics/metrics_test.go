@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// scrape fetches the metrics endpoint and returns the exposition text.
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, Path, http.NoBody))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d from metrics handler, got %d", http.StatusOK, rr.Code)
+	}
+	body, _ := io.ReadAll(rr.Body)
+	return string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	m := New()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("slug") == "missing" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "ok")
+	})
+	mux.Handle("GET "+Path, m.Handler())
+	h := m.Middleware(mux)
+
+	for _, path := range []string{"/events/lincoln-10k", "/events/peak-ultra", "/events/missing", "/nowhere", Path} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	}
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`firecrest_http_requests_total{route="GET /events/{slug}",status="2xx"} 2`,
+		`firecrest_http_requests_total{route="GET /events/{slug}",status="4xx"} 1`,
+		`firecrest_http_requests_total{route="unmatched",status="4xx"} 1`,
+		`firecrest_http_request_duration_seconds_count{route="GET /events/{slug}",status="2xx"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q", want)
+		}
+	}
+	if strings.Contains(body, `route="GET /metrics"`) {
+		t.Error("expected metrics endpoint to be excluded from request metrics")
+	}
+}
+
+func TestAuthCounters(t *testing.T) {
+	m := New()
+	m.SignInFailed()
+	m.SignInFailed()
+	m.AccountLocked()
+
+	body := scrape(t, m)
+	if !strings.Contains(body, "firecrest_auth_sign_in_failures_total 2") {
+		t.Error("expected two sign-in failures")
+	}
+	if !strings.Contains(body, "firecrest_auth_lockouts_total 1") {
+		t.Error("expected one lockout")
+	}
+}
+
+func TestPoolCollector(t *testing.T) {
+	// The pool connects lazily, so no database is needed to read its stats.
+	pool, err := pgxpool.New(context.Background(), "postgres://postgres@127.0.0.1:1/firecrest?pool_max_conns=7")
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	m := New()
+	if err := m.Register(NewPoolCollector(pool)); err != nil {
+		t.Fatalf("failed to register pool collector: %v", err)
+	}
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		"firecrest_db_pool_acquired_conns 0",
+		"firecrest_db_pool_idle_conns 0",
+		"firecrest_db_pool_max_conns 7",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStater is the part of *pgxpool.Pool the PoolCollector reads.
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// PoolCollector reports database connection pool saturation, reading the
+// pool's statistics each time metrics are scraped.
+type PoolCollector struct {
+	pool     PoolStater
+	acquired *prometheus.Desc
+	idle     *prometheus.Desc
+	total    *prometheus.Desc
+	max      *prometheus.Desc
+}
+
+// NewPoolCollector creates a collector for the given pool.
+func NewPoolCollector(pool PoolStater) *PoolCollector {
+	return &PoolCollector{
+		pool:     pool,
+		acquired: prometheus.NewDesc("firecrest_db_pool_acquired_conns", "Connections currently in use.", nil, nil),
+		idle:     prometheus.NewDesc("firecrest_db_pool_idle_conns", "Connections open but not in use.", nil, nil),
+		total:    prometheus.NewDesc("firecrest_db_pool_total_conns", "Connections open, in use or idle.", nil, nil),
+		max:      prometheus.NewDesc("firecrest_db_pool_max_conns", "Largest number of connections the pool will open.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+}
+
+// Collect implements prometheus.Collector.
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+}
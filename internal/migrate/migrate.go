@@ -0,0 +1,292 @@
+// Package migrate applies the database schema from SQL files embedded in the
+// binary.
+//
+// Migrations live in migrations/ and are named
+//
+//	<version>_<description>.up.sql
+//
+// with versions numbered 1, 2, 3 and so on without gaps. A migration may
+// have a matching <version>_<description>.down.sql that undoes it; one
+// without cannot be rolled back. The versions applied are recorded in the
+// schema_migrations table, so Up only runs what a database has not seen.
+// sqlc reads the same directory as its schema and skips the down files.
+//
+// Up and Down hold a Postgres advisory lock while they run, so servers
+// started together migrate one at a time and the later ones find nothing
+// left to do.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var files embed.FS
+
+// lockID names the advisory lock taken while migrating. It is arbitrary,
+// but nothing else in the database may lock it.
+const lockID int64 = 8_135_270_442
+
+var (
+	// ErrTooNew is returned when the database's schema is at a version
+	// newer than any migration the binary carries, as when an older
+	// release is started after a newer one migrated the database.
+	ErrTooNew = errors.New("database schema is newer than this binary")
+
+	// ErrPending is returned by Check when migrations remain to be applied.
+	ErrPending = errors.New("database schema is behind this binary")
+
+	// ErrIrreversible is returned by Down when a migration to be rolled
+	// back has no down file.
+	ErrIrreversible = errors.New("migration cannot be rolled back")
+)
+
+// migration is one schema change, and the change undoing it if there is
+// one.
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// querier runs the single-row queries shared by pools and connections.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Up applies every migration newer than the database's current version, in
+// order and each in its own transaction. It does nothing when the database
+// is already up to date, and returns ErrTooNew when it is ahead.
+func Up(ctx context.Context, pool *pgxpool.Pool) error {
+	migrations, err := load(files)
+	if err != nil {
+		return err
+	}
+
+	return withLock(ctx, pool, func(conn *pgx.Conn) error {
+		if _, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT PRIMARY KEY,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`); err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current > len(migrations) {
+			return tooNew(current, len(migrations))
+		}
+
+		for _, m := range migrations[current:] {
+			if err := apply(ctx, conn, m.up, `INSERT INTO schema_migrations (version) VALUES ($1)`, m.version); err != nil {
+				return fmt.Errorf("migration %s: %w", m.name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down rolls back the n most recently applied migrations, newest first and
+// each in its own transaction. It changes nothing unless every one of them
+// has a down file.
+func Down(ctx context.Context, pool *pgxpool.Pool, n int) error {
+	if n < 1 {
+		return fmt.Errorf("cannot roll back %d migrations", n)
+	}
+	migrations, err := load(files)
+	if err != nil {
+		return err
+	}
+
+	return withLock(ctx, pool, func(conn *pgx.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current > len(migrations) {
+			return tooNew(current, len(migrations))
+		}
+		if n > current {
+			return fmt.Errorf("cannot roll back %d migrations from version %d", n, current)
+		}
+
+		undo := migrations[current-n : current]
+		slices.Reverse(undo)
+		for _, m := range undo {
+			if m.down == "" {
+				return fmt.Errorf("%w: %s has no down file", ErrIrreversible, m.name)
+			}
+		}
+		for _, m := range undo {
+			if err := apply(ctx, conn, m.down, `DELETE FROM schema_migrations WHERE version = $1`, m.version); err != nil {
+				return fmt.Errorf("rolling back migration %s: %w", m.name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Status reports the version the database's schema is at and the latest
+// version this binary carries. A database never migrated is at version 0.
+func Status(ctx context.Context, pool *pgxpool.Pool) (current, latest int, err error) {
+	migrations, err := load(files)
+	if err != nil {
+		return 0, 0, err
+	}
+	current, err = currentVersion(ctx, pool)
+	if err != nil {
+		return 0, 0, err
+	}
+	return current, len(migrations), nil
+}
+
+// Check returns ErrTooNew if the database's schema is newer than this
+// binary and ErrPending if migrations remain to be applied, both wrapped
+// with the versions found.
+func Check(ctx context.Context, pool *pgxpool.Pool) error {
+	current, latest, err := Status(ctx, pool)
+	switch {
+	case err != nil:
+		return err
+	case current > latest:
+		return tooNew(current, latest)
+	case current < latest:
+		return fmt.Errorf("%w: database is at version %d, this binary expects %d", ErrPending, current, latest)
+	}
+	return nil
+}
+
+func tooNew(current, latest int) error {
+	return fmt.Errorf("%w: database is at version %d, this binary knows up to %d", ErrTooNew, current, latest)
+}
+
+// currentVersion returns the latest version recorded in schema_migrations,
+// or 0 if there is no such table.
+func currentVersion(ctx context.Context, q querier) (int, error) {
+	var exists bool
+	if err := q.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to look for schema_migrations: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+	var current int
+	if err := q.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return current, nil
+}
+
+// withLock runs fn on a connection holding the migration lock, waiting for
+// the lock if another process has it.
+func withLock(ctx context.Context, pool *pgxpool.Pool, fn func(conn *pgx.Conn) error) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+	defer func() {
+		// The lock belongs to the session, so a connection that could not
+		// release it must not go back to the pool
+		ctx := context.WithoutCancel(ctx)
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, lockID); err != nil {
+			//nolint:errcheck // the connection is discarded either way
+			conn.Conn().Close(ctx)
+		}
+	}()
+
+	return fn(conn.Conn())
+}
+
+// apply runs sql and then record, given the migration's version, in one
+// transaction.
+func apply(ctx context.Context, conn *pgx.Conn, sql, record string, version int) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	// Without arguments pgx uses the simple protocol, which allows the
+	// several statements a migration file usually holds.
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, record, version); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// load reads the migrations in fsys, ordered by version. It rejects
+// misnamed files, gaps in the numbering and down files without an up file
+// of the same name.
+func load(fsys fs.FS) ([]migration, error) {
+	names, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migration
+	downs := make(map[string]string)
+	for _, name := range names {
+		base := path.Base(name)
+		prefix, _, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		stem, isUp := strings.CutSuffix(base, ".up.sql")
+		if !isUp {
+			stem, _ = strings.CutSuffix(base, ".down.sql")
+		}
+		if !ok || err != nil || version < 1 || stem == base {
+			return nil, fmt.Errorf("migration %s must be named <version>_<description>.up.sql or .down.sql", base)
+		}
+
+		sql, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if isUp {
+			migrations = append(migrations, migration{version: version, name: base, up: string(sql)})
+		} else {
+			downs[stem] = string(sql)
+		}
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int {
+		return cmp.Compare(a.version, b.version)
+	})
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("migration %s: expected version %d", m.name, i+1)
+		}
+		stem := strings.TrimSuffix(m.name, ".up.sql")
+		if down, ok := downs[stem]; ok {
+			migrations[i].down = down
+			delete(downs, stem)
+		}
+	}
+	for stem := range downs {
+		return nil, fmt.Errorf("migration %s.down.sql has no matching up file", stem)
+	}
+	return migrations, nil
+}
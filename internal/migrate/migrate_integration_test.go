@@ -0,0 +1,186 @@
+//go:build integration
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// testPool is connected to a Postgres container shared by every test in the
+// package. Each test starts from an empty database by calling resetSchema.
+var testPool *pgxpool.Pool
+
+func TestMain(m *testing.M) {
+	os.Exit(runIntegrationTests(m))
+}
+
+func runIntegrationTests(m *testing.M) int {
+	ctx := context.Background()
+
+	ctr, err := postgres.Run(ctx, "postgres:16-alpine",
+		postgres.WithDatabase("firecrest_test"),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("postgres"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start postgres: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := testcontainers.TerminateContainer(ctr); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to stop postgres: %v\n", err)
+		}
+	}()
+
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get connection string: %v\n", err)
+		return 1
+	}
+	testPool, err = pgxpool.New(ctx, dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to postgres: %v\n", err)
+		return 1
+	}
+	defer testPool.Close()
+
+	return m.Run()
+}
+
+// resetSchema drops everything the migrations created.
+func resetSchema(t *testing.T) {
+	t.Helper()
+	if _, err := testPool.Exec(context.Background(), `DROP SCHEMA public CASCADE; CREATE SCHEMA public`); err != nil {
+		t.Fatalf("failed to reset schema: %v", err)
+	}
+}
+
+// assertVersion fails the test unless the database is at version want.
+func assertVersion(t *testing.T, want int) {
+	t.Helper()
+	current, _, err := Status(context.Background(), testPool)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current != want {
+		t.Errorf("expected version %d, got %d", want, current)
+	}
+}
+
+func TestUp(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("migrates an empty database to the latest version", func(t *testing.T) {
+		resetSchema(t)
+		assertVersion(t, 0)
+
+		if err := Up(ctx, testPool); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, latest, _ := Status(ctx, testPool)
+		assertVersion(t, latest)
+		if err := Check(ctx, testPool); err != nil {
+			t.Errorf("expected the schema to check out, got %v", err)
+		}
+	})
+
+	t.Run("does nothing the second time", func(t *testing.T) {
+		resetSchema(t)
+		if err := Up(ctx, testPool); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var applied int
+		testPool.QueryRow(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&applied)
+
+		if err := Up(ctx, testPool); err != nil {
+			t.Fatalf("unexpected error on the second run: %v", err)
+		}
+		var again int
+		testPool.QueryRow(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&again)
+		if again != applied {
+			t.Errorf("expected %d recorded migrations, got %d", applied, again)
+		}
+	})
+
+	t.Run("migrates once when servers start together", func(t *testing.T) {
+		resetSchema(t)
+
+		errs := make([]error, 4)
+		var wg sync.WaitGroup
+		for i := range errs {
+			wg.Go(func() { errs[i] = Up(ctx, testPool) })
+		}
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, latest, _ := Status(ctx, testPool)
+		assertVersion(t, latest)
+	})
+
+	t.Run("refuses a database newer than the binary", func(t *testing.T) {
+		resetSchema(t)
+		if err := Up(ctx, testPool); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, latest, _ := Status(ctx, testPool)
+		if _, err := testPool.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, latest+1); err != nil {
+			t.Fatalf("failed to record a future migration: %v", err)
+		}
+
+		if err := Up(ctx, testPool); !errors.Is(err, ErrTooNew) {
+			t.Errorf("expected ErrTooNew from Up, got %v", err)
+		}
+		if err := Check(ctx, testPool); !errors.Is(err, ErrTooNew) {
+			t.Errorf("expected ErrTooNew from Check, got %v", err)
+		}
+	})
+}
+
+func TestDown(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rolls back the latest migration and applies it again", func(t *testing.T) {
+		resetSchema(t)
+		if err := Up(ctx, testPool); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, latest, _ := Status(ctx, testPool)
+
+		if err := Down(ctx, testPool, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertVersion(t, latest-1)
+		if err := Check(ctx, testPool); !errors.Is(err, ErrPending) {
+			t.Errorf("expected ErrPending, got %v", err)
+		}
+
+		if err := Up(ctx, testPool); err != nil {
+			t.Fatalf("unexpected error migrating up again: %v", err)
+		}
+		assertVersion(t, latest)
+	})
+
+	t.Run("refuses to roll back an irreversible migration", func(t *testing.T) {
+		resetSchema(t)
+		if err := Up(ctx, testPool); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, latest, _ := Status(ctx, testPool)
+
+		if err := Down(ctx, testPool, latest); !errors.Is(err, ErrIrreversible) {
+			t.Fatalf("expected ErrIrreversible, got %v", err)
+		}
+		assertVersion(t, latest)
+	})
+}
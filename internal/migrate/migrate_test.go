@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("orders migrations by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"migrations/000002_add_races.up.sql": {Data: []byte("CREATE TABLE races ();")},
+			"migrations/000001_init.up.sql":      {Data: []byte("CREATE TABLE events ();")},
+			"migrations/000010_last.up.sql":      {Data: []byte("SELECT 10;")},
+		}
+		for v := 3; v < 10; v++ {
+			fsys[fmt.Sprintf("migrations/%06d_step.up.sql", v)] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+		}
+
+		migrations, err := load(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(migrations) != 10 {
+			t.Fatalf("expected 10 migrations, got %d", len(migrations))
+		}
+		if migrations[0].name != "000001_init.up.sql" || migrations[0].up != "CREATE TABLE events ();" {
+			t.Errorf("expected init first, got %+v", migrations[0])
+		}
+		if migrations[9].version != 10 {
+			t.Errorf("expected version 10 last, got %d", migrations[9].version)
+		}
+	})
+
+	t.Run("pairs down files with their migrations", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"migrations/000001_init.up.sql":        {Data: []byte("CREATE TABLE events ();")},
+			"migrations/000002_add_races.up.sql":   {Data: []byte("CREATE TABLE races ();")},
+			"migrations/000002_add_races.down.sql": {Data: []byte("DROP TABLE races;")},
+		}
+
+		migrations, err := load(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if migrations[0].down != "" {
+			t.Errorf("expected init to be irreversible, got %q", migrations[0].down)
+		}
+		if migrations[1].down != "DROP TABLE races;" {
+			t.Errorf("expected add_races to have its down file, got %q", migrations[1].down)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		files []string
+	}{
+		{name: "rejects a gap in versions", files: []string{"000001_init.up.sql", "000003_skip.up.sql"}},
+		{name: "rejects a duplicate version", files: []string{"000001_init.up.sql", "000001_again.up.sql"}},
+		{name: "rejects a missing version prefix", files: []string{"init.up.sql"}},
+		{name: "rejects version zero", files: []string{"000000_init.up.sql"}},
+		{name: "rejects a file without the up suffix", files: []string{"000001_init.sql"}},
+		{name: "rejects a down file without an up file", files: []string{"000001_init.up.sql", "000002_races.down.sql"}},
+		{name: "rejects a down file named differently to its up file", files: []string{"000001_init.up.sql", "000001_start.down.sql"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, name := range tt.files {
+				fsys["migrations/"+name] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+			}
+			if _, err := load(fsys); err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+
+	t.Run("loads the embedded migrations", func(t *testing.T) {
+		migrations, err := load(files)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(migrations) == 0 {
+			t.Fatal("expected at least one embedded migration")
+		}
+		if latest := migrations[len(migrations)-1]; latest.down == "" {
+			t.Errorf("expected the latest migration, %s, to have a down file", latest.name)
+		}
+	})
+}
@@ -1,6 +1,9 @@
 CREATE TYPE user_role AS ENUM ('entrant', 'organizer', 'admin');
 CREATE TYPE auth_provider AS ENUM ('google', 'apple');
 CREATE TYPE audit_action AS ENUM ('created', 'updated', 'deleted');
+CREATE TYPE registration_status AS ENUM ('pending', 'confirmed', 'cancelled');
+CREATE TYPE registration_source AS ENUM ('online', 'imported');
+CREATE TYPE payment_status AS ENUM ('pending', 'succeeded', 'failed', 'refunded', 'partially_refunded');
 
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
@@ -60,11 +63,15 @@ CREATE TABLE events (
   id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
   organisation_id BIGINT NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
   name TEXT NOT NULL,
-  slug TEXT NOT NULL UNIQUE,
-  year INT NOT NULL CHECK (year >= 2025)
+  slug TEXT NOT NULL,
+  year INT NOT NULL CHECK (year >= 2025),
+  -- Markdown, rendered and sanitised when the event page is served
+  description TEXT NOT NULL DEFAULT '' CHECK (char_length(description) <= 10000),
+  location TEXT NOT NULL DEFAULT '' CHECK (char_length(location) <= 200),
+  image_url TEXT NOT NULL DEFAULT '',
   created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-  deleted_at TIMESTAMPTZ
+  deleted_at TIMESTAMPTZ,
 
   CONSTRAINT unique_year_slug UNIQUE(year, slug)
 );
@@ -84,6 +91,7 @@ CREATE TABLE races (
   slug TEXT NOT NULL,
   registration_open_date TIMESTAMPTZ,
   registration_close_date TIMESTAMPTZ,
+  starts_at TIMESTAMPTZ,
   max_capacity INT NOT NULL CHECK (max_capacity > 0),
   price_units INT CHECK (price_units >= 0),
   currency TEXT DEFAULT 'GBP',
@@ -103,6 +111,53 @@ CREATE TRIGGER update_races_updated_at
   EXECUTE FUNCTION update_updated_at_column();
 
 
+-- Registrations (an entrant's place in a race)
+CREATE TABLE registrations (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  race_id BIGINT NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  status registration_status NOT NULL DEFAULT 'pending',
+  -- Imported registrations were taken, and paid for, outside Firecrest
+  source registration_source NOT NULL DEFAULT 'online',
+  bib TEXT,
+  cancelled_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_registrations_user_id ON registrations(user_id);
+CREATE INDEX idx_registrations_race_id ON registrations(race_id);
+CREATE INDEX idx_registrations_deleted_at ON registrations(deleted_at) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER update_registrations_updated_at
+  BEFORE UPDATE ON registrations
+  FOR EACH ROW
+  EXECUTE FUNCTION update_updated_at_column();
+
+
+-- Payments (amounts in minor currency units)
+CREATE TABLE payments (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  registration_id BIGINT NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+  amount_units INT NOT NULL CHECK (amount_units >= 0),
+  currency TEXT NOT NULL DEFAULT 'GBP',
+  status payment_status NOT NULL DEFAULT 'pending',
+  provider_reference TEXT,
+  refunded_units INT NOT NULL DEFAULT 0 CHECK (refunded_units >= 0 AND refunded_units <= amount_units),
+  refunded_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_payments_registration_id ON payments(registration_id);
+
+CREATE TRIGGER update_payments_updated_at
+  BEFORE UPDATE ON payments
+  FOR EACH ROW
+  EXECUTE FUNCTION update_updated_at_column();
+
+
 -- Organisation Users (many-to-many relationship)
 CREATE TABLE organisation_users (
   id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
@@ -141,6 +196,19 @@ CREATE TRIGGER update_auth_credentials_updated_at
   EXECUTE FUNCTION update_updated_at_column();
 
 
+-- Email verification tokens (only a SHA-256 hash of the token is stored)
+CREATE TABLE email_verification_tokens (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
+
+
 CREATE TABLE social_accounts (
     id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
@@ -0,0 +1,14 @@
+-- Registration transfers (an entrant handing their place to another runner).
+-- The registration moves to the recipient straight away; accepted_at stays
+-- empty until they open the link emailed to them.
+CREATE TABLE registration_transfers (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  registration_id BIGINT NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+  from_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  to_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  accepted_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_registration_transfers_registration_id ON registration_transfers(registration_id);
+CREATE INDEX idx_registration_transfers_pending ON registration_transfers(to_user_id) WHERE accepted_at IS NULL;
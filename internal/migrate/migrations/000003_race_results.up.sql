@@ -0,0 +1,19 @@
+-- Race results. A result belongs to a registration, or names a runner who
+-- entered outside Firecrest. Uploads are staged unpublished so organisers
+-- can check them before they appear on the public results page.
+CREATE TABLE race_results (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  race_id BIGINT NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  registration_id BIGINT REFERENCES registrations(id) ON DELETE SET NULL,
+  name TEXT NOT NULL DEFAULT '',
+  bib TEXT NOT NULL DEFAULT '',
+  position INT NOT NULL CHECK (position > 0),
+  finish_seconds INT NOT NULL CHECK (finish_seconds >= 0),
+  category TEXT NOT NULL DEFAULT '',
+  published BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(race_id, position),
+  CHECK (registration_id IS NOT NULL OR name <> '')
+);
+
+CREATE INDEX idx_race_results_registration_id ON race_results(registration_id);
@@ -0,0 +1,28 @@
+-- Organisation members, with the part each plays. Every member manages the
+-- organisation's events, owners and admins also create them, and owners
+-- manage the membership.
+CREATE TYPE organisation_role AS ENUM ('owner', 'admin', 'staff');
+
+ALTER TABLE organisation_users RENAME TO organisation_members;
+ALTER INDEX idx_organisation_users_org_id RENAME TO idx_organisation_members_org_id;
+ALTER INDEX idx_organisation_users_user_id RENAME TO idx_organisation_members_user_id;
+ALTER INDEX idx_organisation_users_deleted_at RENAME TO idx_organisation_members_deleted_at;
+
+-- Members from before roles managed everything, so they become owners
+ALTER TABLE organisation_members ADD COLUMN role organisation_role NOT NULL DEFAULT 'owner';
+ALTER TABLE organisation_members ALTER COLUMN role SET DEFAULT 'staff';
+
+-- Invitations to join an organisation. The invitee's user exists from the
+-- start; they become a member when they open the link emailed to them.
+CREATE TABLE organisation_invitations (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  organisation_id BIGINT NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role organisation_role NOT NULL,
+  invited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  accepted_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_organisation_invitations_organisation_id ON organisation_invitations(organisation_id);
+CREATE INDEX idx_organisation_invitations_pending ON organisation_invitations(user_id) WHERE accepted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Deleted accounts are anonymised rather than removed, so that registrations
+-- keep their user for the organiser's records. The user's personal details
+-- are replaced and anonymised_at records when.
+ALTER TABLE users ADD COLUMN anonymised_at TIMESTAMPTZ;
@@ -0,0 +1,21 @@
+-- An entrant may hold one active registration per race. Cancelled entries
+-- are left out, so entrants can register again after cancelling.
+--
+-- Any second active registration made before this constraint is cancelled,
+-- keeping the earliest. Payments for the cancelled entries need refunding by
+-- hand.
+UPDATE registrations
+SET status = 'cancelled',
+    cancelled_at = NOW()
+WHERE id IN (
+  SELECT id FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, race_id ORDER BY created_at, id) AS n
+    FROM registrations
+    WHERE status <> 'cancelled'
+    AND deleted_at IS NULL
+  ) active
+  WHERE n > 1
+);
+
+CREATE UNIQUE INDEX idx_registrations_active_user_race ON registrations(user_id, race_id)
+WHERE status <> 'cancelled' AND deleted_at IS NULL;
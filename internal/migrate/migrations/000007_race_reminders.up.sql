@@ -0,0 +1,11 @@
+-- Which optional emails a user receives. Everyone gets transactional email
+-- about their account and the entries they make; 'marketing' adds emails
+-- they did not ask for, such as race reminders. Unsubscribing drops a user
+-- to 'transactional'.
+CREATE TYPE email_preference AS ENUM ('marketing', 'transactional');
+
+ALTER TABLE users ADD COLUMN email_preference email_preference NOT NULL DEFAULT 'marketing';
+
+-- Set when an entrant is emailed a reminder before their race, so each
+-- registration is reminded at most once.
+ALTER TABLE registrations ADD COLUMN reminder_sent_at TIMESTAMPTZ;
@@ -0,0 +1,29 @@
+-- Teams entered in a race together. Creating a team reserves size places,
+-- its captain's among them, and members fill the rest by joining with the
+-- invite code. Places still unfilled at fill_by are released back to the
+-- race; released_at records when.
+CREATE TABLE teams (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  race_id BIGINT NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  captain_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  size INT NOT NULL CHECK (size > 1),
+  invite_code TEXT NOT NULL UNIQUE,
+  fill_by TIMESTAMPTZ NOT NULL,
+  released_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_teams_race_id ON teams(race_id);
+CREATE INDEX idx_teams_unreleased ON teams(fill_by) WHERE released_at IS NULL AND deleted_at IS NULL;
+
+CREATE TRIGGER update_teams_updated_at
+  BEFORE UPDATE ON teams
+  FOR EACH ROW
+  EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE registrations ADD COLUMN team_id BIGINT REFERENCES teams(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_registrations_team_id ON registrations(team_id) WHERE team_id IS NOT NULL;
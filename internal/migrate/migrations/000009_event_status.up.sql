@@ -0,0 +1,10 @@
+-- Whether an event is shown to the public. Organisers set events up as
+-- drafts, publish them once their races are ready, and archive them to
+-- take them down again.
+CREATE TYPE event_status AS ENUM ('draft', 'published', 'archived');
+
+-- Events from before drafts were already public, so they stay published
+ALTER TABLE events ADD COLUMN status event_status NOT NULL DEFAULT 'published';
+ALTER TABLE events ALTER COLUMN status SET DEFAULT 'draft';
+
+CREATE INDEX idx_events_published ON events(year, slug) WHERE status = 'published' AND deleted_at IS NULL;
@@ -0,0 +1,17 @@
+-- Tokens users create to call the JSON API without a browser session. Only
+-- the SHA-256 of each token is kept; the token itself is shown once, when
+-- it is created. Scopes limit what a token can read, and a revoked or
+-- expired token is refused.
+CREATE TABLE api_tokens (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  label TEXT NOT NULL,
+  token_hash BYTEA NOT NULL UNIQUE,
+  scopes TEXT[] NOT NULL,
+  last_used_at TIMESTAMPTZ,
+  expires_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
@@ -0,0 +1,23 @@
+-- Bib numbers are unique within a race among its active registrations, so
+-- timing systems can rely on them. Cancelled entries are left out, letting
+-- their numbers be handed to someone else.
+--
+-- Blank bibs mean none. Where active registrations already share a bib the
+-- earliest keeps it and the rest are cleared, to be assigned again.
+UPDATE registrations SET bib = NULL WHERE bib = '';
+
+UPDATE registrations
+SET bib = NULL
+WHERE id IN (
+  SELECT id FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY race_id, bib ORDER BY created_at, id) AS n
+    FROM registrations
+    WHERE bib IS NOT NULL
+    AND status <> 'cancelled'
+    AND deleted_at IS NULL
+  ) active
+  WHERE n > 1
+);
+
+CREATE UNIQUE INDEX idx_registrations_race_bib ON registrations(race_id, bib)
+WHERE bib IS NOT NULL AND status <> 'cancelled' AND deleted_at IS NULL;
@@ -0,0 +1,37 @@
+-- Discount codes organisers offer on entries to an event, or to one race in
+-- it when race_id is set. A code takes either a percentage or a fixed amount,
+-- in the race's currency, off the entry fee. Codes are matched ignoring case
+-- and are unique within their event. uses counts the entries made with the
+-- code and can never pass max_uses; no max_uses means unlimited.
+CREATE TABLE discount_codes (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+  race_id BIGINT REFERENCES races(id) ON DELETE CASCADE,
+  code TEXT NOT NULL,
+  percent_off INT CHECK (percent_off BETWEEN 1 AND 100),
+  amount_off_units INT CHECK (amount_off_units > 0),
+  max_uses INT CHECK (max_uses > 0),
+  uses INT NOT NULL DEFAULT 0 CHECK (uses >= 0 AND (max_uses IS NULL OR uses <= max_uses)),
+  valid_from TIMESTAMPTZ,
+  valid_until TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ,
+  CHECK ((percent_off IS NULL) <> (amount_off_units IS NULL)),
+  CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_from < valid_until)
+);
+
+CREATE UNIQUE INDEX idx_discount_codes_event_code ON discount_codes(event_id, LOWER(code)) WHERE deleted_at IS NULL;
+CREATE INDEX idx_discount_codes_code ON discount_codes(LOWER(code)) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER update_discount_codes_updated_at
+  BEFORE UPDATE ON discount_codes
+  FOR EACH ROW
+  EXECUTE FUNCTION update_updated_at_column();
+
+-- What online entrants were charged, after any discount. Entries made
+-- before prices were recorded, and imported ones, have no price_units.
+ALTER TABLE registrations
+  ADD COLUMN price_units INT CHECK (price_units >= 0),
+  ADD COLUMN discount_code_id BIGINT REFERENCES discount_codes(id) ON DELETE SET NULL,
+  ADD COLUMN discount_units INT NOT NULL DEFAULT 0 CHECK (discount_units >= 0);
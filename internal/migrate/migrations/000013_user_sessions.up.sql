@@ -0,0 +1,17 @@
+-- Each time a user signs in, recorded alongside the browser session it
+-- starts so users can see where they are signed in and sign devices out.
+-- The session keeps the row's id; a revoked row signs that session out on
+-- its next request. Rows past expires_at outlived their session and are
+-- no longer shown.
+CREATE TABLE user_sessions (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  user_agent TEXT NOT NULL,
+  ip_address TEXT NOT NULL,
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
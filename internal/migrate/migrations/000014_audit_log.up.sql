@@ -0,0 +1,16 @@
+-- Changes made on someone else's behalf, such as an admin unlocking an
+-- account, recorded for support. changed_fields holds what changed, as
+-- {"field": {"old": ..., "new": ...}}. Entries outlive the admin who made
+-- them.
+CREATE TABLE audit_log (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  table_name TEXT NOT NULL,
+  record_id BIGINT NOT NULL,
+  action audit_action NOT NULL,
+  user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  changed_fields JSONB,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_table_record ON audit_log(table_name, record_id);
+CREATE INDEX idx_audit_log_user_id ON audit_log(user_id);
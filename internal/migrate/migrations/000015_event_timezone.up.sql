@@ -0,0 +1,5 @@
+-- The IANA time zone an event's organisers think in. Registration windows
+-- and start times are still stored as instants; the zone is what the
+-- organiser's local times are read in, and what dates are shifted in when
+-- an event is duplicated into another year.
+ALTER TABLE events ADD COLUMN timezone TEXT NOT NULL DEFAULT 'Europe/London';
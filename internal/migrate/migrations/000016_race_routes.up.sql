@@ -0,0 +1,13 @@
+-- The course of a race, uploaded by an organiser as a GPX file. gpx_gzip
+-- holds the file as uploaded, gzip-compressed. The distance and climb are
+-- worked out from its track points on upload, so event pages can show them
+-- without reading the file.
+CREATE TABLE race_routes (
+  race_id BIGINT PRIMARY KEY REFERENCES races(id) ON DELETE CASCADE,
+  gpx_gzip BYTEA NOT NULL,
+  point_count INTEGER NOT NULL,
+  distance_metres INTEGER NOT NULL,
+  elevation_gain_metres INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+-- Event slugs are chosen by each organisation, so two organisations may
+-- both run a half-marathon in the same year. Only one of them can be
+-- public at a time, as public pages are found by year and slug alone.
+ALTER TABLE events DROP CONSTRAINT unique_year_slug;
+ALTER TABLE events ADD CONSTRAINT unique_organisation_year_slug UNIQUE (organisation_id, year, slug);
+
+DROP INDEX idx_events_published;
+CREATE UNIQUE INDEX idx_events_published ON events(year, slug) WHERE status = 'published' AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Admins can deactivate an account, for example a departed organiser's,
+-- without anonymising it. A deactivated user cannot sign in, and their
+-- sessions and API tokens stop working, until they are reactivated.
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMPTZ;
@@ -0,0 +1,18 @@
+-- The questions a race requires entrants to answer before their entry is
+-- accepted, such as an emergency contact for ultras whose safety rules
+-- ask for one. A race with no rows asks nothing.
+CREATE TABLE race_required_questions (
+  race_id BIGINT NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  question TEXT NOT NULL,
+  PRIMARY KEY (race_id, question)
+);
+
+-- An entrant's answers to their race's questionnaire. They include medical
+-- details, so they are stored as a JSON object encrypted by the app and
+-- are removed when the place is transferred or the entrant's account is
+-- anonymised.
+CREATE TABLE registration_answers (
+  registration_id BIGINT PRIMARY KEY REFERENCES registrations(id) ON DELETE CASCADE,
+  answers_sealed BYTEA NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,16 @@
+-- Photos organisers upload to an event's gallery. The files live in the
+-- blob store: original_key is the image as uploaded and web_key the
+-- resized JPEG event pages show. Photos are shown in position order; the
+-- positions are unique per event but only checked at commit, so a reorder
+-- can swap them within a transaction.
+CREATE TABLE event_photos (
+  id BIGSERIAL PRIMARY KEY,
+  event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  original_key TEXT NOT NULL,
+  web_key TEXT NOT NULL,
+  width INTEGER NOT NULL,
+  height INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT event_photos_position_key UNIQUE (event_id, position) DEFERRABLE INITIALLY DEFERRED
+);
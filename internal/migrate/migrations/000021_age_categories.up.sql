@@ -0,0 +1,6 @@
+-- Entrants' dates of birth place them in an age category on race day, and
+-- races may set a minimum age on race day to enter. Dates of birth are
+-- personal data: they are never shown publicly and are cleared when an
+-- account is anonymised.
+ALTER TABLE users ADD COLUMN date_of_birth DATE;
+ALTER TABLE races ADD COLUMN min_age INT CHECK (min_age BETWEEN 1 AND 100);
@@ -0,0 +1,23 @@
+-- Big races start their entrants in waves, each with its own start time
+-- and number of places. Entrants choose a wave when they register or are
+-- put in the emptiest; a race without waves starts everyone together at
+-- its starts_at.
+CREATE TABLE race_waves (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  race_id BIGINT NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  starts_at TIMESTAMPTZ NOT NULL,
+  capacity INT NOT NULL CHECK (capacity > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (race_id, name)
+);
+
+CREATE TRIGGER update_race_waves_updated_at
+  BEFORE UPDATE ON race_waves
+  FOR EACH ROW
+  EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE registrations ADD COLUMN wave_id BIGINT REFERENCES race_waves(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_registrations_wave_id ON registrations(wave_id) WHERE wave_id IS NOT NULL;
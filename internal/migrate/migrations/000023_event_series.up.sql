@@ -0,0 +1,20 @@
+-- Editions of an annual event form a series, named by the id of its first
+-- edition, which carries its own id. Event pages link a series' earlier
+-- editions, and the archive and sitemap list only its latest.
+ALTER TABLE events ADD COLUMN series_id BIGINT REFERENCES events(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_events_series_id ON events(series_id) WHERE series_id IS NOT NULL;
+
+-- Editions already duplicated year to year share an organisation and slug
+UPDATE events e
+SET series_id = s.first_id
+FROM (
+  SELECT organisation_id, slug, MIN(id) AS first_id
+  FROM events
+  WHERE deleted_at IS NULL
+  GROUP BY organisation_id, slug
+  HAVING COUNT(*) > 1
+) s
+WHERE e.organisation_id = s.organisation_id
+AND e.slug = s.slug
+AND e.deleted_at IS NULL;
@@ -0,0 +1,9 @@
+-- Support staff may act as a user to see what they see. Starting and
+-- stopping, and every request that changes something in between, are
+-- audited against the impersonated user's row, with user_id the admin.
+-- changed_fields holds {"method": ..., "path": ..., "blocked": ...} for
+-- requests. New enum values cannot be used in the transaction adding them,
+-- and nothing here does.
+ALTER TYPE audit_action ADD VALUE 'impersonation_started';
+ALTER TYPE audit_action ADD VALUE 'impersonation_stopped';
+ALTER TYPE audit_action ADD VALUE 'impersonated_request';
@@ -0,0 +1,9 @@
+-- Each organisation member chooses how they hear about new registrations:
+-- an email for every one, a daily digest, or nothing. digest_sent_for is
+-- the day the member's last digest covered, so a digest is only ever sent
+-- once for a day however often the job runs.
+CREATE TYPE notification_preference AS ENUM ('immediate', 'daily_digest', 'none');
+
+ALTER TABLE organisation_members
+  ADD COLUMN notification_preference notification_preference NOT NULL DEFAULT 'none',
+  ADD COLUMN digest_sent_for DATE;
@@ -0,0 +1,4 @@
+-- When an entrant collected their bib on race day. Marshals check entrants
+-- in one at a time, and may clear a check-in made by mistake for a few
+-- minutes after.
+ALTER TABLE registrations ADD COLUMN checked_in_at TIMESTAMPTZ;
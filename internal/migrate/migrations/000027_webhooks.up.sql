@@ -0,0 +1,47 @@
+-- Endpoints organisations register to be told about their registrations,
+-- each subscribed to some event types. Requests are signed with the
+-- endpoint's secret, which is sealed like questionnaire answers and only
+-- shown when the endpoint is added.
+CREATE TABLE webhook_endpoints (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  organisation_id BIGINT NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  secret_sealed BYTEA NOT NULL,
+  event_types TEXT[] NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_endpoints_organisation_id ON webhook_endpoints(organisation_id);
+
+-- Each event queues a delivery to every endpoint subscribed to it, which a
+-- background job sends and retries with backoff until the endpoint accepts
+-- it or the retries run out. next_attempt_at also leases a delivery to the
+-- job sending it, so two servers never send it at once.
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'failed');
+
+CREATE TABLE webhook_deliveries (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  endpoint_id BIGINT NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+  event_type TEXT NOT NULL,
+  payload JSON NOT NULL,
+  status webhook_delivery_status NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_endpoint_id ON webhook_deliveries(endpoint_id, created_at);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+-- Every attempt at a delivery, for the delivery log. status_code is unset
+-- when no response came back.
+CREATE TABLE webhook_delivery_attempts (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+  attempted_at TIMESTAMPTZ NOT NULL,
+  status_code INT,
+  error TEXT NOT NULL DEFAULT '',
+  duration_ms INT NOT NULL
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts(delivery_id);
@@ -0,0 +1,29 @@
+-- Races step their prices up as race day approaches. Each tier prices
+-- entries made between valid_from and valid_to, or to the first capacity
+-- entries made at it, or both; outside every tier an entry costs the race's
+-- price_units. Open ends are NULL.
+CREATE TABLE race_price_tiers (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  race_id BIGINT NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  price_units INT NOT NULL CHECK (price_units >= 0),
+  valid_from TIMESTAMPTZ,
+  valid_to TIMESTAMPTZ,
+  capacity INT CHECK (capacity > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK (valid_to > valid_from),
+  CHECK (valid_to IS NOT NULL OR capacity IS NOT NULL)
+);
+
+CREATE INDEX idx_race_price_tiers_race_id ON race_price_tiers(race_id);
+
+CREATE TRIGGER update_race_price_tiers_updated_at
+  BEFORE UPDATE ON race_price_tiers
+  FOR EACH ROW
+  EXECUTE FUNCTION update_updated_at_column();
+
+-- The tier an entry was priced at, counted against the tier's capacity
+ALTER TABLE registrations ADD COLUMN price_tier_id BIGINT REFERENCES race_price_tiers(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_registrations_price_tier_id ON registrations(price_tier_id) WHERE price_tier_id IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Where questions sent through an event's contact form go. Without one,
+-- they go to the organisation's owners.
+ALTER TABLE organisations ADD COLUMN contact_email TEXT;
+
+-- Questions sent to an event's organisers through its contact form, kept
+-- so organisers can look back at them. The organisers' addresses are never
+-- shown to the sender.
+CREATE TABLE event_enquiries (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  email TEXT NOT NULL,
+  message TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_event_enquiries_event_id ON event_enquiries(event_id, created_at DESC);
@@ -0,0 +1,6 @@
+-- Organisations' own look on their event pages. The colour is checked
+-- here as well as by the service, as it is written into page styles.
+ALTER TABLE organisations
+  ADD COLUMN brand_colour TEXT CHECK (brand_colour ~ '^#[0-9a-f]{6}$'),
+  ADD COLUMN logo_key TEXT,
+  ADD COLUMN banner_key TEXT;
@@ -0,0 +1,9 @@
+-- Questions whose answers entrants may no longer change themselves once
+-- their entry is paid for, such as a club an affiliation discount was
+-- given for. Organisers can still see every answer; this only limits
+-- entrants' own edits.
+CREATE TABLE race_locked_questions (
+  race_id BIGINT NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  question TEXT NOT NULL,
+  PRIMARY KEY (race_id, question)
+);
@@ -0,0 +1,11 @@
+-- A race's distance and climb as the organiser gives them, in metres, for
+-- display and for the listing's distance filter. Either may be unknown.
+-- Entrants choose the unit distances are shown to them in, miles unless
+-- they say otherwise.
+ALTER TABLE races
+  ADD COLUMN distance_metres INT CHECK (distance_metres > 0 AND distance_metres < 5000000),
+  ADD COLUMN elevation_gain_metres INT CHECK (elevation_gain_metres > 0);
+
+CREATE TYPE distance_unit AS ENUM ('miles', 'km');
+
+ALTER TABLE users ADD COLUMN distance_unit distance_unit NOT NULL DEFAULT 'miles';
@@ -0,0 +1,9 @@
+-- Sessions signed out to make room for a newer sign-in, when accounts are
+-- limited to AUTH_MAX_SESSIONS, are marked so their next request can say
+-- why.
+ALTER TABLE user_sessions ADD COLUMN evicted_at TIMESTAMPTZ;
+
+-- Each sign-in counts the user's sessions still in use, and evicts the
+-- least recently active of them
+CREATE INDEX idx_user_sessions_user_active ON user_sessions (user_id, last_seen_at)
+WHERE revoked_at IS NULL;
@@ -0,0 +1,19 @@
+-- Sign-in attempts on each account, so its holder can be told about
+-- failed attempts, lockouts and sign-ins from devices not seen before.
+-- alerted marks the events an email was sent for, which limits how often
+-- each kind is sent. Events older than the 90 days a device is remembered
+-- for are pruned.
+CREATE TYPE login_event_kind AS ENUM ('signed_in', 'failed', 'locked');
+
+CREATE TABLE login_events (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind login_event_kind NOT NULL,
+  ip_address TEXT NOT NULL,
+  user_agent TEXT NOT NULL,
+  alerted BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_login_events_user_kind ON login_events (user_id, kind, created_at);
+CREATE INDEX idx_login_events_created_at ON login_events (created_at);
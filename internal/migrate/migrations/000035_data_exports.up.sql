@@ -0,0 +1,25 @@
+-- Copies of everything held about a user, asked for by them. A request is
+-- pending until a background job writes the archive to the blob store and
+-- emails a link to it; lease_until keeps two servers from building the
+-- same one. The link's token is stored hashed, and works once and until
+-- expires_at. Archives are removed from the store once downloaded or
+-- expired, clearing storage_key.
+CREATE TYPE data_export_status AS ENUM ('pending', 'ready', 'failed');
+
+CREATE TABLE data_exports (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status data_export_status NOT NULL DEFAULT 'pending',
+  lease_until TIMESTAMPTZ,
+  storage_key TEXT,
+  token_hash BYTEA UNIQUE,
+  expires_at TIMESTAMPTZ,
+  downloaded_at TIMESTAMPTZ,
+  completed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A user has at most one request waiting to be built
+CREATE UNIQUE INDEX idx_data_exports_pending_user ON data_exports (user_id) WHERE status = 'pending';
+CREATE INDEX idx_data_exports_user_id ON data_exports (user_id, created_at);
+CREATE INDEX idx_data_exports_storage_key ON data_exports (expires_at) WHERE storage_key IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE organisations
+  DROP COLUMN embed_origins,
+  DROP COLUMN embed_public;
@@ -0,0 +1,7 @@
+-- Which other websites may read the availability of an organisation's
+-- races from the API, for the widget organisers embed on their own sites.
+-- Listed origins are allowed by name; with none listed, embed_public lets
+-- any site read it.
+ALTER TABLE organisations
+  ADD COLUMN embed_public BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN embed_origins TEXT[] NOT NULL DEFAULT '{}';
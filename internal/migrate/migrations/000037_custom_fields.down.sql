@@ -0,0 +1,4 @@
+DROP TABLE registration_custom_answers;
+DROP TABLE race_custom_field_versions;
+DROP TABLE race_custom_fields;
+DROP TYPE custom_field_kind;
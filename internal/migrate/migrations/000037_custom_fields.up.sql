@@ -0,0 +1,40 @@
+-- Questions organisers add to a race's entry form beyond the fixed
+-- questionnaire, such as a t-shirt size, asked in position order. A
+-- field's definition is never changed in place: editing it adds a row to
+-- race_custom_field_versions and moves version on, and removing it only
+-- sets deleted_at, so answers still show against the label and options
+-- they were given for.
+CREATE TYPE custom_field_kind AS ENUM ('text', 'select', 'checkbox');
+
+CREATE TABLE race_custom_fields (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  race_id BIGINT NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  position INT NOT NULL,
+  version INT NOT NULL DEFAULT 1,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_race_custom_fields_race_id ON race_custom_fields (race_id, position) WHERE deleted_at IS NULL;
+
+CREATE TABLE race_custom_field_versions (
+  field_id BIGINT NOT NULL REFERENCES race_custom_fields(id) ON DELETE CASCADE,
+  version INT NOT NULL,
+  label TEXT NOT NULL,
+  kind custom_field_kind NOT NULL,
+  options TEXT[] NOT NULL DEFAULT '{}',
+  required BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (field_id, version)
+);
+
+-- An entrant's answers to their race's custom fields, as a JSON object
+-- keyed by field ID. Each holds the version of the field answered and the
+-- value given: a string for text and select fields, a boolean for
+-- checkboxes. They are removed, like the questionnaire's, when the place
+-- is transferred or the entrant's account is anonymised.
+CREATE TABLE registration_custom_answers (
+  registration_id BIGINT PRIMARY KEY REFERENCES registrations(id) ON DELETE CASCADE,
+  answers JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
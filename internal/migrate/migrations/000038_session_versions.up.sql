@@ -0,0 +1,6 @@
+-- Sessions carry the session_version their user had when they signed in,
+-- and are refused once it has moved on. Cookie sessions are held by the
+-- browser rather than the sessions table, so ending every session a user
+-- has (signing out everywhere, deactivating or deleting the account) moves
+-- it on rather than deleting them.
+ALTER TABLE users ADD COLUMN session_version INT NOT NULL DEFAULT 0;
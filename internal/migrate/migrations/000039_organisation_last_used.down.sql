@@ -0,0 +1 @@
+ALTER TABLE organisation_members DROP COLUMN last_used_at;
@@ -0,0 +1,4 @@
+-- When the member last chose the organisation in the admin pages'
+-- switcher, or opened one of its pages. A session with no organisation
+-- chosen yet starts in the one used most recently.
+ALTER TABLE organisation_members ADD COLUMN last_used_at TIMESTAMPTZ;
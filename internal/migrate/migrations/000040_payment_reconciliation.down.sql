@@ -0,0 +1,3 @@
+DROP INDEX idx_payments_created_at;
+DROP INDEX idx_payments_provider_reference;
+ALTER TABLE payments DROP COLUMN fee_units;
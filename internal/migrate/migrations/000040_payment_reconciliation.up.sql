@@ -0,0 +1,9 @@
+-- Payments are recorded from Stripe's webhooks, once per charge however
+-- often a webhook is repeated, with Stripe's fee when the webhook carries
+-- it (NULL when it does not). Organisers reconcile them by date.
+ALTER TABLE payments ADD COLUMN fee_units INT CHECK (fee_units >= 0);
+
+CREATE UNIQUE INDEX idx_payments_provider_reference ON payments(provider_reference)
+WHERE provider_reference IS NOT NULL;
+
+CREATE INDEX idx_payments_created_at ON payments(created_at);
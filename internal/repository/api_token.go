@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/api_token.go -pkg repositorymocks . APITokenRepository
+
+// APITokenRepository defines the interface for API token data access.
+type APITokenRepository interface {
+	// Create stores a token. It returns ErrConflict if a token with the
+	// same hash already exists.
+	Create(ctx context.Context, params db.CreateAPITokenParams) (db.ApiToken, error)
+	// ListForUser returns the user's unrevoked tokens, newest first.
+	ListForUser(ctx context.Context, userID int64) ([]db.ApiToken, error)
+	// GetByHash returns the token with the hash, revoked or expired ones
+	// included, or ErrNotFound if there is none.
+	GetByHash(ctx context.Context, tokenHash []byte) (db.ApiToken, error)
+	// Touch records that the token was just used.
+	Touch(ctx context.Context, id int64) error
+	// Revoke revokes the user's token. It returns ErrNotFound if the user
+	// has no such unrevoked token.
+	Revoke(ctx context.Context, userID, id int64) error
+}
+
+type apiTokenRepository struct {
+	queries *db.Queries
+}
+
+// NewAPITokenRepository creates a new APITokenRepository backed by the given queries.
+func NewAPITokenRepository(queries *db.Queries) APITokenRepository {
+	return &apiTokenRepository{queries: queries}
+}
+
+func (r *apiTokenRepository) Create(ctx context.Context, params db.CreateAPITokenParams) (db.ApiToken, error) {
+	token, err := r.queries.CreateAPIToken(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.ApiToken{}, ErrConflict
+		}
+		return db.ApiToken{}, err
+	}
+	return token, nil
+}
+
+func (r *apiTokenRepository) ListForUser(ctx context.Context, userID int64) ([]db.ApiToken, error) {
+	return r.queries.ListAPITokensForUser(ctx, userID)
+}
+
+func (r *apiTokenRepository) GetByHash(ctx context.Context, tokenHash []byte) (db.ApiToken, error) {
+	token, err := r.queries.GetAPITokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ApiToken{}, ErrNotFound
+		}
+		return db.ApiToken{}, err
+	}
+	return token, nil
+}
+
+func (r *apiTokenRepository) Touch(ctx context.Context, id int64) error {
+	return r.queries.TouchAPIToken(ctx, id)
+}
+
+func (r *apiTokenRepository) Revoke(ctx context.Context, userID, id int64) error {
+	n, err := r.queries.RevokeAPIToken(ctx, db.RevokeAPITokenParams{ID: id, UserID: userID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"firecrest/db"
+)
+
+func TestAPITokenRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("finds a token by its hash", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "timer@example.com")
+		repo := NewAPITokenRepository(queries)
+
+		created, err := repo.Create(ctx, db.CreateAPITokenParams{
+			UserID:    user.ID,
+			Label:     "Chip timing",
+			TokenHash: []byte("hash-1"),
+			Scopes:    []string{"read:entrants", "read:events"},
+		})
+		if err != nil {
+			t.Fatalf("failed to create token: %v", err)
+		}
+
+		token, err := repo.GetByHash(ctx, []byte("hash-1"))
+		if err != nil {
+			t.Fatalf("failed to get token: %v", err)
+		}
+		if token.ID != created.ID || token.UserID != user.ID || !slices.Equal(token.Scopes, []string{"read:entrants", "read:events"}) {
+			t.Errorf("unexpected token: %+v", token)
+		}
+		if token.LastUsedAt.Valid || token.ExpiresAt.Valid {
+			t.Errorf("expected an unused token that never expires, got %+v", token)
+		}
+
+		if err := repo.Touch(ctx, token.ID); err != nil {
+			t.Fatalf("failed to touch token: %v", err)
+		}
+		if token, _ = repo.GetByHash(ctx, []byte("hash-1")); !token.LastUsedAt.Valid {
+			t.Error("expected the token's use to be recorded")
+		}
+
+		if _, err := repo.GetByHash(ctx, []byte("hash-2")); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown hash, got %v", err)
+		}
+	})
+
+	t.Run("revokes only the user's own tokens", func(t *testing.T) {
+		queries := resetDB(t)
+		owner := createTestUser(t, queries, "timer@example.com")
+		other := createTestUser(t, queries, "other@example.com")
+		repo := NewAPITokenRepository(queries)
+
+		token, err := repo.Create(ctx, db.CreateAPITokenParams{
+			UserID:    owner.ID,
+			Label:     "Chip timing",
+			TokenHash: []byte("hash-1"),
+			Scopes:    []string{"read:events"},
+		})
+		if err != nil {
+			t.Fatalf("failed to create token: %v", err)
+		}
+
+		if err := repo.Revoke(ctx, other.ID, token.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound revoking someone else's token, got %v", err)
+		}
+		if err := repo.Revoke(ctx, owner.ID, token.ID); err != nil {
+			t.Fatalf("failed to revoke token: %v", err)
+		}
+		if err := repo.Revoke(ctx, owner.ID, token.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound revoking twice, got %v", err)
+		}
+
+		listed, err := repo.ListForUser(ctx, owner.ID)
+		if err != nil {
+			t.Fatalf("failed to list tokens: %v", err)
+		}
+		if len(listed) != 0 {
+			t.Errorf("expected revoked tokens not to be listed, got %+v", listed)
+		}
+		if revoked, _ := repo.GetByHash(ctx, []byte("hash-1")); !revoked.RevokedAt.Valid {
+			t.Error("expected the revoked token to be found with its revocation")
+		}
+	})
+
+	t.Run("returns ErrConflict for a duplicate hash", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "timer@example.com")
+		repo := NewAPITokenRepository(queries)
+		params := db.CreateAPITokenParams{UserID: user.ID, Label: "CI", TokenHash: []byte("hash-1"), Scopes: []string{"read:events"}}
+
+		if _, err := repo.Create(ctx, params); err != nil {
+			t.Fatalf("failed to create token: %v", err)
+		}
+		if _, err := repo.Create(ctx, params); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("removes tokens when the user is anonymised", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "timer@example.com")
+		repo := NewAPITokenRepository(queries)
+		if _, err := repo.Create(ctx, db.CreateAPITokenParams{UserID: user.ID, Label: "CI", TokenHash: []byte("hash-1"), Scopes: []string{"read:events"}}); err != nil {
+			t.Fatalf("failed to create token: %v", err)
+		}
+
+		if err := NewUserRepository(queries, testPool).Anonymise(ctx, user.ID); err != nil {
+			t.Fatalf("failed to anonymise user: %v", err)
+		}
+		if _, err := repo.GetByHash(ctx, []byte("hash-1")); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected the token to be gone, got %v", err)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -11,6 +12,8 @@ import (
 	"firecrest/db"
 )
 
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/auth.go -pkg repositorymocks . AuthRepository
+
 // AuthRepository defines the interface for authentication data access.
 type AuthRepository interface {
 	// User operations
@@ -28,18 +31,45 @@ type AuthRepository interface {
 	// Account locking
 	LockAccount(ctx context.Context, userID int64, lockUntil time.Time) error
 	IsAccountLocked(ctx context.Context, userID int64) (bool, error)
+	// UnlockExpired clears every lock that has run out, along with the
+	// failed attempts that caused it, and returns how many were cleared.
+	UnlockExpired(ctx context.Context) (int64, error)
+	// UnlockAccount clears the user's lock and failed attempts on behalf of
+	// adminUserID, recording the change in the audit log. It returns
+	// ErrNotFound if the user has no credentials.
+	UnlockAccount(ctx context.Context, userID, adminUserID int64) error
+
+	// Login events
+	// RecordLoginEvent records a sign-in attempt on the user's account.
+	RecordLoginEvent(ctx context.Context, params db.CreateLoginEventParams) error
+	// SignInHistory reports whether the user has signed in before and
+	// whether they have signed in from the device, an IP address and user
+	// agent, since since.
+	SignInHistory(ctx context.Context, userID int64, ipAddress, userAgent string, since time.Time) (db.GetSignInHistoryRow, error)
+	// LoginAlertSentSince reports whether the user was emailed about an
+	// event of kind after since.
+	LoginAlertSentSince(ctx context.Context, userID int64, kind db.LoginEventKind, since time.Time) (bool, error)
+	// DeleteLoginEventsBefore deletes the events recorded before before and
+	// returns how many were deleted.
+	DeleteLoginEventsBefore(ctx context.Context, before time.Time) (int64, error)
 
 	// Email verification
 	VerifyEmail(ctx context.Context, userID int64) error
+	CreateVerificationToken(ctx context.Context, userID int64, tokenHash []byte, expiresAt time.Time) error
+	// ConsumeVerificationToken marks an unexpired, unused token as used and
+	// returns its user ID, or ErrNotFound if no such token exists.
+	ConsumeVerificationToken(ctx context.Context, tokenHash []byte) (int64, error)
 }
 
 type authRepository struct {
 	queries *db.Queries
+	pool    TxBeginner
 }
 
-// NewAuthRepository creates a new AuthRepository backed by the given queries.
-func NewAuthRepository(queries *db.Queries) AuthRepository {
-	return &authRepository{queries: queries}
+// NewAuthRepository creates a new AuthRepository backed by the given
+// queries, using pool for writes that must be atomic.
+func NewAuthRepository(queries *db.Queries, pool TxBeginner) AuthRepository {
+	return &authRepository{queries: queries, pool: pool}
 }
 
 func (r *authRepository) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
@@ -54,10 +84,17 @@ func (r *authRepository) GetUserByEmail(ctx context.Context, email string) (db.U
 }
 
 func (r *authRepository) CreateCredentials(ctx context.Context, userID int64, passwordHash string) (db.AuthCredential, error) {
-	return r.queries.CreateAuthCredentials(ctx, db.CreateAuthCredentialsParams{
+	creds, err := r.queries.CreateAuthCredentials(ctx, db.CreateAuthCredentialsParams{
 		UserID:       userID,
 		PasswordHash: passwordHash,
 	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.AuthCredential{}, ErrConflict
+		}
+		return db.AuthCredential{}, err
+	}
+	return creds, nil
 }
 
 func (r *authRepository) GetCredentialsByEmail(ctx context.Context, email string) (db.AuthCredential, error) {
@@ -101,6 +138,108 @@ func (r *authRepository) IsAccountLocked(ctx context.Context, userID int64) (boo
 	return r.queries.IsAccountLocked(ctx, userID)
 }
 
+func (r *authRepository) UnlockExpired(ctx context.Context) (int64, error) {
+	return r.queries.UnlockExpiredAccounts(ctx)
+}
+
+// auditChange is one field's entry in an audit log's changed_fields.
+type auditChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+func (r *authRepository) UnlockAccount(ctx context.Context, userID, adminUserID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	creds, err := qtx.GetAuthCredentialsByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	n, err := qtx.UnlockAccount(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	var lockedUntil any
+	if creds.LockedUntil.Valid {
+		lockedUntil = creds.LockedUntil.Time
+	}
+	changed, err := json.Marshal(map[string]auditChange{
+		"failed_login_attempts": {Old: creds.FailedLoginAttempts, New: 0},
+		"locked_until":          {Old: lockedUntil, New: nil},
+	})
+	if err != nil {
+		return err
+	}
+	if err := qtx.CreateAuditLogEntry(ctx, db.CreateAuditLogEntryParams{
+		TableName:     "auth_credentials",
+		RecordID:      creds.ID,
+		Action:        db.AuditActionUpdated,
+		UserID:        pgtype.Int8{Int64: adminUserID, Valid: true},
+		ChangedFields: changed,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *authRepository) RecordLoginEvent(ctx context.Context, params db.CreateLoginEventParams) error {
+	return r.queries.CreateLoginEvent(ctx, params)
+}
+
+func (r *authRepository) SignInHistory(ctx context.Context, userID int64, ipAddress, userAgent string, since time.Time) (db.GetSignInHistoryRow, error) {
+	return r.queries.GetSignInHistory(ctx, db.GetSignInHistoryParams{
+		IpAddress: ipAddress,
+		UserAgent: userAgent,
+		Since:     pgtype.Timestamptz{Time: since, Valid: true},
+		UserID:    userID,
+	})
+}
+
+func (r *authRepository) LoginAlertSentSince(ctx context.Context, userID int64, kind db.LoginEventKind, since time.Time) (bool, error) {
+	return r.queries.LoginAlertSentSince(ctx, db.LoginAlertSentSinceParams{
+		UserID:    userID,
+		Kind:      kind,
+		CreatedAt: pgtype.Timestamptz{Time: since, Valid: true},
+	})
+}
+
+func (r *authRepository) DeleteLoginEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	return r.queries.DeleteLoginEventsBefore(ctx, pgtype.Timestamptz{Time: before, Valid: true})
+}
+
 func (r *authRepository) VerifyEmail(ctx context.Context, userID int64) error {
 	return r.queries.VerifyEmail(ctx, userID)
 }
+
+func (r *authRepository) CreateVerificationToken(ctx context.Context, userID int64, tokenHash []byte, expiresAt time.Time) error {
+	return r.queries.CreateEmailVerificationToken(ctx, db.CreateEmailVerificationTokenParams{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+}
+
+func (r *authRepository) ConsumeVerificationToken(ctx context.Context, tokenHash []byte) (int64, error) {
+	userID, err := r.queries.ConsumeEmailVerificationToken(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return userID, nil
+}
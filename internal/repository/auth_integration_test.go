@@ -0,0 +1,327 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestAuthRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("gets a user by email", func(t *testing.T) {
+		queries := resetDB(t)
+		created := createTestUser(t, queries, "jane@example.com")
+		repo := NewAuthRepository(queries, testPool)
+
+		user, err := repo.GetUserByEmail(ctx, "jane@example.com")
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if user.ID != created.ID {
+			t.Errorf("expected user %d, got %d", created.ID, user.ID)
+		}
+
+		if _, err := repo.GetUserByEmail(ctx, "missing@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("creates and gets credentials", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "jane@example.com")
+		repo := NewAuthRepository(queries, testPool)
+
+		if _, err := repo.CreateCredentials(ctx, user.ID, "hash"); err != nil {
+			t.Fatalf("failed to create credentials: %v", err)
+		}
+
+		byEmail, err := repo.GetCredentialsByEmail(ctx, "jane@example.com")
+		if err != nil {
+			t.Fatalf("failed to get credentials by email: %v", err)
+		}
+		if byEmail.UserID != user.ID || byEmail.PasswordHash != "hash" {
+			t.Errorf("unexpected credentials: %+v", byEmail)
+		}
+		if byEmail.EmailVerifiedAt.Valid {
+			t.Error("expected new credentials to be unverified")
+		}
+
+		byUser, err := repo.GetCredentialsByUserID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get credentials by user: %v", err)
+		}
+		if byUser.ID != byEmail.ID {
+			t.Errorf("expected credentials %d, got %d", byEmail.ID, byUser.ID)
+		}
+	})
+
+	t.Run("returns ErrNotFound for missing credentials", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "jane@example.com")
+		repo := NewAuthRepository(queries, testPool)
+
+		if _, err := repo.GetCredentialsByEmail(ctx, "jane@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetCredentialsByEmail: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetCredentialsByUserID(ctx, user.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetCredentialsByUserID: expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrConflict for a second set of credentials", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "jane@example.com")
+		repo := NewAuthRepository(queries, testPool)
+
+		if _, err := repo.CreateCredentials(ctx, user.ID, "hash"); err != nil {
+			t.Fatalf("failed to create credentials: %v", err)
+		}
+		if _, err := repo.CreateCredentials(ctx, user.ID, "other"); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("locks an account until the given time", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "jane@example.com")
+		repo := NewAuthRepository(queries, testPool)
+		if _, err := repo.CreateCredentials(ctx, user.ID, "hash"); err != nil {
+			t.Fatalf("failed to create credentials: %v", err)
+		}
+
+		if locked, err := repo.IsAccountLocked(ctx, user.ID); err != nil || locked {
+			t.Fatalf("expected a new account to be unlocked, got %v (err %v)", locked, err)
+		}
+		if err := repo.LockAccount(ctx, user.ID, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("failed to lock account: %v", err)
+		}
+		if locked, err := repo.IsAccountLocked(ctx, user.ID); err != nil || !locked {
+			t.Errorf("expected the account to be locked, got %v (err %v)", locked, err)
+		}
+		if err := repo.LockAccount(ctx, user.ID, time.Now().Add(-time.Minute)); err != nil {
+			t.Fatalf("failed to lock account: %v", err)
+		}
+		if locked, err := repo.IsAccountLocked(ctx, user.ID); err != nil || locked {
+			t.Errorf("expected an expired lock to be ignored, got %v (err %v)", locked, err)
+		}
+	})
+
+	t.Run("unlocks accounts whose lock has passed", func(t *testing.T) {
+		queries := resetDB(t)
+		lapsed := createTestUser(t, queries, "jane@example.com")
+		locked := createTestUser(t, queries, "sam@example.com")
+		repo := NewAuthRepository(queries, testPool)
+		for _, user := range []int64{lapsed.ID, locked.ID} {
+			if _, err := repo.CreateCredentials(ctx, user, "hash"); err != nil {
+				t.Fatalf("failed to create credentials: %v", err)
+			}
+			if err := repo.IncrementFailedAttempts(ctx, user); err != nil {
+				t.Fatalf("failed to record a failed attempt: %v", err)
+			}
+		}
+		if err := repo.LockAccount(ctx, lapsed.ID, time.Now().Add(-time.Minute)); err != nil {
+			t.Fatalf("failed to lock account: %v", err)
+		}
+		if err := repo.LockAccount(ctx, locked.ID, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("failed to lock account: %v", err)
+		}
+
+		n, err := repo.UnlockExpired(ctx)
+		if err != nil {
+			t.Fatalf("failed to unlock accounts: %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected one account unlocked, got %d", n)
+		}
+
+		creds, err := repo.GetCredentialsByUserID(ctx, lapsed.ID)
+		if err != nil {
+			t.Fatalf("failed to get credentials: %v", err)
+		}
+		if creds.LockedUntil.Valid || creds.FailedLoginAttempts != 0 {
+			t.Errorf("expected the lapsed lock and failed attempts to be cleared, got %+v", creds)
+		}
+		creds, err = repo.GetCredentialsByUserID(ctx, locked.ID)
+		if err != nil {
+			t.Fatalf("failed to get credentials: %v", err)
+		}
+		if !creds.LockedUntil.Valid || creds.FailedLoginAttempts != 1 {
+			t.Errorf("expected the current lock to be kept, got %+v", creds)
+		}
+	})
+
+	t.Run("unlocks an account on an admin's behalf", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "jane@example.com")
+		admin := createTestUser(t, queries, "admin@example.com")
+		repo := NewAuthRepository(queries, testPool)
+		creds, err := repo.CreateCredentials(ctx, user.ID, "hash")
+		if err != nil {
+			t.Fatalf("failed to create credentials: %v", err)
+		}
+		if err := repo.IncrementFailedAttempts(ctx, user.ID); err != nil {
+			t.Fatalf("failed to record a failed attempt: %v", err)
+		}
+		if err := repo.LockAccount(ctx, user.ID, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("failed to lock account: %v", err)
+		}
+
+		if err := repo.UnlockAccount(ctx, user.ID, admin.ID); err != nil {
+			t.Fatalf("failed to unlock account: %v", err)
+		}
+		if locked, err := repo.IsAccountLocked(ctx, user.ID); err != nil || locked {
+			t.Errorf("expected the account to be unlocked, got %v (err %v)", locked, err)
+		}
+
+		entries, err := queries.ListAuditLogForRecord(ctx, db.ListAuditLogForRecordParams{
+			TableName: "auth_credentials",
+			RecordID:  creds.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to list audit log: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected one audit entry, got %d", len(entries))
+		}
+		if entries[0].Action != db.AuditActionUpdated || entries[0].UserID.Int64 != admin.ID {
+			t.Errorf("expected an update by the admin, got %+v", entries[0])
+		}
+
+		if err := repo.UnlockAccount(ctx, admin.ID, admin.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound without credentials, got %v", err)
+		}
+	})
+
+	t.Run("consumes a verification token once", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "jane@example.com")
+		repo := NewAuthRepository(queries, testPool)
+
+		hash := []byte("token-hash")
+		if err := repo.CreateVerificationToken(ctx, user.ID, hash, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("failed to create token: %v", err)
+		}
+
+		userID, err := repo.ConsumeVerificationToken(ctx, hash)
+		if err != nil {
+			t.Fatalf("failed to consume token: %v", err)
+		}
+		if userID != user.ID {
+			t.Errorf("expected user %d, got %d", user.ID, userID)
+		}
+		if _, err := repo.ConsumeVerificationToken(ctx, hash); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a used token, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrNotFound for expired or unknown tokens", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "jane@example.com")
+		repo := NewAuthRepository(queries, testPool)
+
+		expired := []byte("expired-hash")
+		if err := repo.CreateVerificationToken(ctx, user.ID, expired, time.Now().Add(-time.Minute)); err != nil {
+			t.Fatalf("failed to create token: %v", err)
+		}
+
+		if _, err := repo.ConsumeVerificationToken(ctx, expired); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an expired token, got %v", err)
+		}
+		if _, err := repo.ConsumeVerificationToken(ctx, []byte("unknown")); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown token, got %v", err)
+		}
+	})
+
+	t.Run("marks the email verified", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "jane@example.com")
+		repo := NewAuthRepository(queries, testPool)
+		if _, err := repo.CreateCredentials(ctx, user.ID, "hash"); err != nil {
+			t.Fatalf("failed to create credentials: %v", err)
+		}
+
+		if err := repo.VerifyEmail(ctx, user.ID); err != nil {
+			t.Fatalf("failed to verify email: %v", err)
+		}
+		creds, err := repo.GetCredentialsByUserID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get credentials: %v", err)
+		}
+		if !creds.EmailVerifiedAt.Valid {
+			t.Error("expected the email to be verified")
+		}
+	})
+
+	t.Run("recalls sign-ins and alerts from login events", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "jane@example.com")
+		repo := NewAuthRepository(queries, testPool)
+		now := time.Now().Truncate(time.Second)
+		record := func(kind db.LoginEventKind, ip string, alerted bool, at time.Time) {
+			t.Helper()
+			if err := repo.RecordLoginEvent(ctx, db.CreateLoginEventParams{
+				UserID:    user.ID,
+				Kind:      kind,
+				IpAddress: ip,
+				UserAgent: "Firefox",
+				Alerted:   alerted,
+				CreatedAt: pgtype.Timestamptz{Time: at, Valid: true},
+			}); err != nil {
+				t.Fatalf("failed to record login event: %v", err)
+			}
+		}
+
+		history, err := repo.SignInHistory(ctx, user.ID, "203.0.113.9", "Firefox", now.Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("failed to get sign-in history: %v", err)
+		}
+		if history.SignedInBefore || history.SeenDevice {
+			t.Errorf("expected no history, got %+v", history)
+		}
+
+		record(db.LoginEventKindSignedIn, "203.0.113.9", false, now.Add(-2*time.Hour))
+		record(db.LoginEventKindFailed, "198.51.100.4", false, now)
+		record(db.LoginEventKindFailed, "198.51.100.4", true, now.Add(-30*time.Minute))
+
+		for _, tt := range []struct {
+			ip    string
+			since time.Duration
+			want  db.GetSignInHistoryRow
+		}{
+			{ip: "203.0.113.9", since: 3 * time.Hour, want: db.GetSignInHistoryRow{SignedInBefore: true, SeenDevice: true}},
+			{ip: "203.0.113.9", since: time.Hour, want: db.GetSignInHistoryRow{SignedInBefore: true}},
+			{ip: "198.51.100.4", since: 3 * time.Hour, want: db.GetSignInHistoryRow{SignedInBefore: true}},
+		} {
+			history, err := repo.SignInHistory(ctx, user.ID, tt.ip, "Firefox", now.Add(-tt.since))
+			if err != nil {
+				t.Fatalf("failed to get sign-in history: %v", err)
+			}
+			if history != tt.want {
+				t.Errorf("%s within %v: expected %+v, got %+v", tt.ip, tt.since, tt.want, history)
+			}
+		}
+
+		if sent, err := repo.LoginAlertSentSince(ctx, user.ID, db.LoginEventKindFailed, now.Add(-time.Hour)); err != nil || !sent {
+			t.Errorf("expected an alert within the hour, got %v, %v", sent, err)
+		}
+		if sent, err := repo.LoginAlertSentSince(ctx, user.ID, db.LoginEventKindLocked, now.Add(-time.Hour)); err != nil || sent {
+			t.Errorf("expected no lockout alert, got %v, %v", sent, err)
+		}
+
+		n, err := repo.DeleteLoginEventsBefore(ctx, now.Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("failed to prune login events: %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected the sign-in two hours ago pruned, got %d deleted", n)
+		}
+	})
+}
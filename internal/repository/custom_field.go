@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"firecrest/db"
+)
+
+func (r *raceRepository) ListCustomFields(ctx context.Context, raceID int64) ([]db.RaceCustomFieldVersion, error) {
+	return r.queries.ListRaceCustomFields(ctx, raceID)
+}
+
+func (r *raceRepository) ListCustomFieldVersions(ctx context.Context, raceID int64) ([]db.RaceCustomFieldVersion, error) {
+	return r.queries.ListRaceCustomFieldVersions(ctx, raceID)
+}
+
+func (r *raceRepository) CreateCustomField(ctx context.Context, raceID int64, def db.CreateRaceCustomFieldVersionParams, limit int) (db.RaceCustomFieldVersion, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return db.RaceCustomFieldVersion{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	// Counted under the race lock, so two organisers adding fields at once
+	// cannot take the race past the limit
+	qtx := r.queries.WithTx(tx)
+	if _, err := qtx.LockRace(ctx, raceID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.RaceCustomFieldVersion{}, ErrNotFound
+		}
+		return db.RaceCustomFieldVersion{}, err
+	}
+	n, err := qtx.CountRaceCustomFields(ctx, raceID)
+	if err != nil {
+		return db.RaceCustomFieldVersion{}, err
+	}
+	if n >= int64(limit) {
+		return db.RaceCustomFieldVersion{}, ErrCustomFieldLimit
+	}
+
+	field, err := qtx.CreateRaceCustomField(ctx, raceID)
+	if err != nil {
+		return db.RaceCustomFieldVersion{}, err
+	}
+	def.FieldID, def.Version = field.ID, field.Version
+	version, err := qtx.CreateRaceCustomFieldVersion(ctx, def)
+	if err != nil {
+		return db.RaceCustomFieldVersion{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.RaceCustomFieldVersion{}, err
+	}
+	return version, nil
+}
+
+func (r *raceRepository) UpdateCustomField(ctx context.Context, raceID int64, def db.CreateRaceCustomFieldVersionParams) (db.RaceCustomFieldVersion, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return db.RaceCustomFieldVersion{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	// Moving the field on locks its row, so two edits at once each get a
+	// version of their own
+	qtx := r.queries.WithTx(tx)
+	def.Version, err = qtx.NextRaceCustomFieldVersion(ctx, db.NextRaceCustomFieldVersionParams{ID: def.FieldID, RaceID: raceID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.RaceCustomFieldVersion{}, ErrNotFound
+		}
+		return db.RaceCustomFieldVersion{}, err
+	}
+	version, err := qtx.CreateRaceCustomFieldVersion(ctx, def)
+	if err != nil {
+		return db.RaceCustomFieldVersion{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.RaceCustomFieldVersion{}, err
+	}
+	return version, nil
+}
+
+func (r *raceRepository) DeleteCustomField(ctx context.Context, raceID, fieldID int64) error {
+	n, err := r.queries.DeleteRaceCustomField(ctx, db.DeleteRaceCustomFieldParams{ID: fieldID, RaceID: raceID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *raceRepository) SetCustomFieldOrder(ctx context.Context, raceID int64, fieldIDs []int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	for i, id := range fieldIDs {
+		if err := qtx.SetRaceCustomFieldPosition(ctx, db.SetRaceCustomFieldPositionParams{
+			Position: int32(i + 1),
+			ID:       id,
+			RaceID:   raceID,
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/data_export.go -pkg repositorymocks . DataExportRepository
+
+// DataExportRepository defines the interface for data access to users'
+// requests for a copy of their data.
+type DataExportRepository interface {
+	// Request records that the user wants a copy of their data, returning
+	// the request already waiting to be built if there is one.
+	Request(ctx context.Context, userID int64) (db.DataExport, error)
+	// GetLatest returns the user's most recent request, or ErrNotFound if
+	// they have made none.
+	GetLatest(ctx context.Context, userID int64) (db.DataExport, error)
+	// ClaimPending leases up to limit pending requests to the caller until
+	// leaseUntil, oldest first. A request whose lease runs out before it
+	// is completed or failed is claimed again.
+	ClaimPending(ctx context.Context, now, leaseUntil time.Time, limit int) ([]db.DataExport, error)
+	// Complete marks a pending request ready, with its archive stored under
+	// key and downloadable with the token hashed as tokenHash until
+	// expiresAt. It returns ErrNotFound if the request is no longer
+	// pending, such as when the user has since deleted their account.
+	Complete(ctx context.Context, id int64, key string, tokenHash []byte, expiresAt, now time.Time) error
+	// Fail marks a pending request as failed.
+	Fail(ctx context.Context, id int64, now time.Time) error
+	// Consume marks the export downloadable with the token hashed as
+	// tokenHash downloaded, and returns it, so each token works once. It
+	// returns ErrNotFound if no ready export has the token, or the export
+	// has been downloaded or has expired.
+	Consume(ctx context.Context, tokenHash []byte, now time.Time) (db.DataExport, error)
+	// ListSpent returns up to limit exports whose archives are still stored
+	// but have expired, or were downloaded before downloadedBefore.
+	ListSpent(ctx context.Context, now, downloadedBefore time.Time, limit int) ([]db.DataExport, error)
+	// ClearStorageKey records that the export's archive has been removed.
+	ClearStorageKey(ctx context.Context, id int64) error
+}
+
+type dataExportRepository struct {
+	queries *db.Queries
+}
+
+// NewDataExportRepository creates a new DataExportRepository backed by the given queries.
+func NewDataExportRepository(queries *db.Queries) DataExportRepository {
+	return &dataExportRepository{queries: queries}
+}
+
+func (r *dataExportRepository) Request(ctx context.Context, userID int64) (db.DataExport, error) {
+	return r.queries.RequestDataExport(ctx, userID)
+}
+
+func (r *dataExportRepository) GetLatest(ctx context.Context, userID int64) (db.DataExport, error) {
+	export, err := r.queries.GetLatestDataExport(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.DataExport{}, ErrNotFound
+		}
+		return db.DataExport{}, err
+	}
+	return export, nil
+}
+
+func (r *dataExportRepository) ClaimPending(ctx context.Context, now, leaseUntil time.Time, limit int) ([]db.DataExport, error) {
+	return r.queries.ClaimDataExports(ctx, db.ClaimDataExportsParams{
+		Now:        pgtype.Timestamptz{Time: now, Valid: true},
+		MaxExports: int32(limit),
+		LeaseUntil: pgtype.Timestamptz{Time: leaseUntil, Valid: true},
+	})
+}
+
+func (r *dataExportRepository) Complete(ctx context.Context, id int64, key string, tokenHash []byte, expiresAt, now time.Time) error {
+	n, err := r.queries.CompleteDataExport(ctx, db.CompleteDataExportParams{
+		ID:          id,
+		StorageKey:  pgtype.Text{String: key, Valid: true},
+		TokenHash:   tokenHash,
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		CompletedAt: pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *dataExportRepository) Fail(ctx context.Context, id int64, now time.Time) error {
+	return r.queries.FailDataExport(ctx, db.FailDataExportParams{
+		ID:          id,
+		CompletedAt: pgtype.Timestamptz{Time: now, Valid: true},
+	})
+}
+
+func (r *dataExportRepository) Consume(ctx context.Context, tokenHash []byte, now time.Time) (db.DataExport, error) {
+	export, err := r.queries.ConsumeDataExport(ctx, db.ConsumeDataExportParams{
+		Now:       pgtype.Timestamptz{Time: now, Valid: true},
+		TokenHash: tokenHash,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.DataExport{}, ErrNotFound
+		}
+		return db.DataExport{}, err
+	}
+	return export, nil
+}
+
+func (r *dataExportRepository) ListSpent(ctx context.Context, now, downloadedBefore time.Time, limit int) ([]db.DataExport, error) {
+	return r.queries.ListSpentDataExports(ctx, db.ListSpentDataExportsParams{
+		Now:              pgtype.Timestamptz{Time: now, Valid: true},
+		DownloadedBefore: pgtype.Timestamptz{Time: downloadedBefore, Valid: true},
+		MaxExports:       int32(limit),
+	})
+}
+
+func (r *dataExportRepository) ClearStorageKey(ctx context.Context, id int64) error {
+	return r.queries.ClearDataExportStorageKey(ctx, id)
+}
@@ -0,0 +1,86 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"firecrest/db"
+)
+
+func TestDataExportRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps one request pending and lets its token be used once", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "runner@example.com")
+		repo := NewDataExportRepository(queries)
+		now := time.Now()
+
+		if _, err := repo.GetLatest(ctx, user.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound before any request, got %v", err)
+		}
+		first, err := repo.Request(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to request export: %v", err)
+		}
+		again, err := repo.Request(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to request export again: %v", err)
+		}
+		if again.ID != first.ID {
+			t.Errorf("expected the pending request returned, got %d and %d", first.ID, again.ID)
+		}
+
+		claimed, err := repo.ClaimPending(ctx, now, now.Add(time.Minute), 5)
+		if err != nil {
+			t.Fatalf("failed to claim exports: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].ID != first.ID {
+			t.Fatalf("expected the request claimed, got %+v", claimed)
+		}
+		if claimed, err := repo.ClaimPending(ctx, now, now.Add(time.Minute), 5); err != nil || len(claimed) != 0 {
+			t.Errorf("expected a leased request left alone, got %+v, %v", claimed, err)
+		}
+
+		hash := []byte("0123456789abcdef0123456789abcdef")
+		if err := repo.Complete(ctx, first.ID, "exports/1/a.zip", hash, now.Add(time.Hour), now); err != nil {
+			t.Fatalf("failed to complete export: %v", err)
+		}
+		if err := repo.Complete(ctx, first.ID, "exports/1/b.zip", hash, now.Add(time.Hour), now); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound completing a ready export, got %v", err)
+		}
+
+		latest, err := repo.GetLatest(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get latest export: %v", err)
+		}
+		if latest.Status != db.DataExportStatusReady {
+			t.Errorf("expected the export ready, got %s", latest.Status)
+		}
+
+		if _, err := repo.Consume(ctx, hash, now); err != nil {
+			t.Fatalf("failed to consume export: %v", err)
+		}
+		if _, err := repo.Consume(ctx, hash, now); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound using a token twice, got %v", err)
+		}
+
+		spent, err := repo.ListSpent(ctx, now, now.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("failed to list spent exports: %v", err)
+		}
+		if len(spent) != 1 || spent[0].ID != first.ID {
+			t.Fatalf("expected the downloaded export spent, got %+v", spent)
+		}
+		if err := repo.ClearStorageKey(ctx, first.ID); err != nil {
+			t.Fatalf("failed to clear storage key: %v", err)
+		}
+		if spent, err := repo.ListSpent(ctx, now, now.Add(time.Minute), 10); err != nil || len(spent) != 0 {
+			t.Errorf("expected nothing left to prune, got %+v, %v", spent, err)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/discount.go -pkg repositorymocks . DiscountRepository
+
+// DiscountRepository defines the interface for discount code data access.
+type DiscountRepository interface {
+	// Create stores a discount code. It returns ErrConflict if the event
+	// already has a code that matches it ignoring case.
+	Create(ctx context.Context, params db.CreateDiscountCodeParams) (db.DiscountCode, error)
+	// ListByCode returns the codes matching code, ignoring case, in every
+	// event.
+	ListByCode(ctx context.Context, code string) ([]db.DiscountCode, error)
+	// ListByEvent returns the event's codes, newest first.
+	ListByEvent(ctx context.Context, eventID int64) ([]db.DiscountCode, error)
+}
+
+type discountRepository struct {
+	queries *db.Queries
+}
+
+// NewDiscountRepository creates a new DiscountRepository backed by the given queries.
+func NewDiscountRepository(queries *db.Queries) DiscountRepository {
+	return &discountRepository{queries: queries}
+}
+
+func (r *discountRepository) Create(ctx context.Context, params db.CreateDiscountCodeParams) (db.DiscountCode, error) {
+	code, err := r.queries.CreateDiscountCode(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.DiscountCode{}, ErrConflict
+		}
+		return db.DiscountCode{}, err
+	}
+	return code, nil
+}
+
+func (r *discountRepository) ListByCode(ctx context.Context, code string) ([]db.DiscountCode, error) {
+	return r.queries.ListDiscountCodesByCode(ctx, code)
+}
+
+func (r *discountRepository) ListByEvent(ctx context.Context, eventID int64) ([]db.DiscountCode, error) {
+	return r.queries.ListDiscountCodesByEvent(ctx, eventID)
+}
@@ -0,0 +1,59 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestDiscountRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("matches codes ignoring case and keeps them unique within an event", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		var events []db.Event
+		for _, slug := range []string{"spring-10k", "autumn-10k"} {
+			event, err := queries.CreateEvent(ctx, db.CreateEventParams{OrganisationID: org.ID, Name: slug, Slug: slug, Year: 2026})
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+			events = append(events, event)
+		}
+		repo := NewDiscountRepository(queries)
+
+		for _, event := range events {
+			if _, err := repo.Create(ctx, db.CreateDiscountCodeParams{
+				EventID:        event.ID,
+				Code:           "Early",
+				AmountOffUnits: pgtype.Int4{Int32: 500, Valid: true},
+			}); err != nil {
+				t.Fatalf("failed to create discount code: %v", err)
+			}
+		}
+		if _, err := repo.Create(ctx, db.CreateDiscountCodeParams{
+			EventID:    events[0].ID,
+			Code:       "EARLY",
+			PercentOff: pgtype.Int4{Int32: 10, Valid: true},
+		}); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict for a code the event has, got %v", err)
+		}
+
+		codes, err := repo.ListByCode(ctx, "eARLy")
+		if err != nil {
+			t.Fatalf("failed to list codes: %v", err)
+		}
+		if len(codes) != 2 || codes[0].EventID != events[0].ID || codes[1].EventID != events[1].ID {
+			t.Errorf("expected each event's code, got %+v", codes)
+		}
+		if codes, _ := repo.ListByCode(ctx, "LATE"); len(codes) != 0 {
+			t.Errorf("expected no codes, got %+v", codes)
+		}
+	})
+}
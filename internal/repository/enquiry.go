@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/enquiry.go -pkg repositorymocks . EnquiryRepository
+
+// EnquiryRepository defines the interface for access to the questions sent
+// to events' organisers through their contact forms.
+type EnquiryRepository interface {
+	// Create records an enquiry about an event.
+	Create(ctx context.Context, params db.CreateEventEnquiryParams) (db.EventEnquiry, error)
+	// ListByEvent returns the event's latest enquiries, newest first, up to
+	// limit.
+	ListByEvent(ctx context.Context, eventID int64, limit int32) ([]db.EventEnquiry, error)
+}
+
+type enquiryRepository struct {
+	queries *db.Queries
+}
+
+// NewEnquiryRepository creates a new EnquiryRepository backed by the given queries.
+func NewEnquiryRepository(queries *db.Queries) EnquiryRepository {
+	return &enquiryRepository{queries: queries}
+}
+
+func (r *enquiryRepository) Create(ctx context.Context, params db.CreateEventEnquiryParams) (db.EventEnquiry, error) {
+	return r.queries.CreateEventEnquiry(ctx, params)
+}
+
+func (r *enquiryRepository) ListByEvent(ctx context.Context, eventID int64, limit int32) ([]db.EventEnquiry, error) {
+	return r.queries.ListEventEnquiries(ctx, db.ListEventEnquiriesParams{EventID: eventID, Limit: limit})
+}
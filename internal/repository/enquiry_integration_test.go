@@ -0,0 +1,54 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestEnquiryRepository(t *testing.T) {
+	ctx := context.Background()
+	queries := resetDB(t)
+	org := createTestOrganisation(t, queries)
+	event, err := queries.CreateEvent(ctx, db.CreateEventParams{OrganisationID: org.ID, Name: "Lincoln 10k", Slug: "lincoln-10k", Year: 2026})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	repo := NewEnquiryRepository(queries)
+
+	for _, name := range []string{"Ada", "Grace", "Alan"} {
+		if _, err := repo.Create(ctx, db.CreateEventEnquiryParams{
+			EventID: event.ID,
+			Name:    name,
+			Email:   "runner@example.com",
+			Message: "Is there parking near the start?",
+		}); err != nil {
+			t.Fatalf("failed to create enquiry: %v", err)
+		}
+	}
+
+	enquiries, err := repo.ListByEvent(ctx, event.ID, 2)
+	if err != nil {
+		t.Fatalf("failed to list enquiries: %v", err)
+	}
+	if len(enquiries) != 2 || enquiries[0].Name != "Alan" || enquiries[1].Name != "Grace" {
+		t.Errorf("expected the latest two, newest first, got %+v", enquiries)
+	}
+
+	orgRepo := NewOrganisationRepository(queries, testPool)
+	if err := orgRepo.SetContactEmail(ctx, org.ID, pgtype.Text{String: "races@peak.example", Valid: true}); err != nil {
+		t.Fatalf("failed to set contact email: %v", err)
+	}
+	if got, _ := orgRepo.Get(ctx, org.ID); got.ContactEmail.String != "races@peak.example" {
+		t.Errorf("expected the contact email kept, got %+v", got.ContactEmail)
+	}
+	if err := orgRepo.SetContactEmail(ctx, org.ID+1, pgtype.Text{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another organisation, got %v", err)
+	}
+}
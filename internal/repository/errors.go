@@ -1,6 +1,82 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"firecrest/internal/apperr"
+)
 
 // ErrNotFound is returned when a requested resource does not exist.
-var ErrNotFound = errors.New("resource not found")
+var ErrNotFound = apperr.New(apperr.CodeNotFound, http.StatusNotFound, "resource not found")
+
+// ErrConflict is returned when a write violates a uniqueness constraint.
+var ErrConflict = apperr.New(apperr.CodeConflict, http.StatusConflict, "resource already exists")
+
+// ErrCapacityExceeded is returned when a write would take a race past its
+// maximum capacity.
+var ErrCapacityExceeded = apperr.New(apperr.CodeRaceFull, http.StatusConflict, "race capacity exceeded")
+
+// ErrAlreadyRegistered is returned when a write would give an entrant a
+// second active registration for the same race.
+var ErrAlreadyRegistered = apperr.New(apperr.CodeAlreadyRegistered, http.StatusConflict, "already registered for race")
+
+// ErrTeamFull is returned when a write would take a team past its size.
+var ErrTeamFull = apperr.New(apperr.CodeTeamFull, http.StatusConflict, "team is full")
+
+// ErrNotPublished is returned when a write would enter someone into a race
+// whose event is not published.
+var ErrNotPublished = apperr.New(apperr.CodeEventNotPublished, http.StatusConflict, "event not published")
+
+// ErrDiscountExhausted is returned when a write would use a discount code
+// more times than it allows.
+var ErrDiscountExhausted = apperr.New(apperr.CodeDiscountExhausted, http.StatusConflict, "discount code has no uses left")
+
+// ErrPriceTierFull is returned when a write would enter someone at a price
+// tier that has gone, or has no places left at its price.
+var ErrPriceTierFull = apperr.New(apperr.CodePriceTierFull, http.StatusConflict, "price tier has no places left")
+
+// ErrSessionLimit is returned when a write would give a user more sessions
+// than they are allowed at once.
+var ErrSessionLimit = apperr.New(apperr.CodeTooManySessions, http.StatusConflict, "session limit reached")
+
+// ErrCustomFieldLimit is returned when a write would give a race more
+// custom fields than it is allowed.
+var ErrCustomFieldLimit = apperr.New(apperr.CodeTooManyCustomFields, http.StatusConflict, "race has too many custom fields")
+
+// ErrInUse is returned when a delete would leave entries pointing at
+// nothing, such as removing a start wave entrants hold places in.
+var ErrInUse = apperr.New(apperr.CodeInUse, http.StatusConflict, "resource is in use")
+
+// ErrTimeout is returned when the database does not answer a call in time.
+var ErrTimeout = apperr.New(apperr.CodeUnavailable, http.StatusServiceUnavailable, "database call timed out")
+
+// pgUniqueViolation is the Postgres SQLSTATE for unique_violation.
+const pgUniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// pgForeignKeyViolation is the Postgres SQLSTATE for foreign_key_violation.
+const pgForeignKeyViolation = "23503"
+
+// isForeignKeyViolation reports whether err is a write naming a row, such
+// as a registration, that does not exist.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation
+}
+
+// bibIndex is the unique index keeping bibs distinct within a race.
+const bibIndex = "idx_registrations_race_bib"
+
+// isBibConflict reports whether err is a violation of bibIndex.
+func isBibConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == bibIndex
+}
@@ -3,34 +3,125 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"firecrest/db"
 )
 
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/event.go -pkg repositorymocks . EventRepository
+
 // EventRepository defines the interface for event data access.
 type EventRepository interface {
 	List(ctx context.Context) ([]db.Event, error)
-	GetBySlug(ctx context.Context, slug string) (db.Event, error)
+	ListPaginated(ctx context.Context, limit, offset int32) ([]db.Event, error)
+	// ListWithStats returns a page of published events by name, each with
+	// its live races' count, capacity, cheapest price and registration
+	// dates and its registration count, in a single query.
+	ListWithStats(ctx context.Context, params db.ListEventsWithStatsParams) ([]db.ListEventsWithStatsRow, error)
+	// ListByYear returns the year's events by name, noting which were past
+	// at now. Past events are left out unless includePast is set.
+	ListByYear(ctx context.Context, year int32, now time.Time, includePast bool) ([]db.ListEventsByYearRow, error)
+	// ListYears returns every year with an event, latest first.
+	ListYears(ctx context.Context) ([]int32, error)
+	Count(ctx context.Context) (int64, error)
+	// ListSitemap returns a page of published editions, each with when it
+	// last changed, latest year first and then by slug. Only the latest
+	// published edition of a series is listed.
+	ListSitemap(ctx context.Context, limit, offset int32) ([]db.ListSitemapEventsRow, error)
+	// CountSitemap counts the editions ListSitemap lists.
+	CountSitemap(ctx context.Context) (int64, error)
+	// GetBySlug returns the published edition in year with the slug.
+	GetBySlug(ctx context.Context, year int32, slug string) (db.Event, error)
+	// ListBySlug returns every published edition with the slug, whichever
+	// organisation runs it, latest year first.
+	ListBySlug(ctx context.Context, slug string) ([]db.Event, error)
+	GetByID(ctx context.Context, id int64) (db.Event, error)
+	// ListInSeries returns the series' published editions, latest year
+	// first, each with its races that have published results.
+	ListInSeries(ctx context.Context, seriesID int64) ([]db.ListEventsInSeriesRow, error)
+	// ListByOrganisation returns every event the organisation runs, by name
+	// and then latest year first.
+	ListByOrganisation(ctx context.Context, organisationID int64) ([]db.Event, error)
+	// JoinSeries adds an event to the series of another, starting one with
+	// the other event as its first edition if it is in none. It returns
+	// ErrNotFound if either event does not exist.
+	JoinSeries(ctx context.Context, eventID, seriesEventID int64) (db.Event, error)
+	// LeaveSeries takes an event out of its series, returning ErrNotFound
+	// if it does not exist.
+	LeaveSeries(ctx context.Context, eventID int64) error
 	Create(ctx context.Context, params db.CreateEventParams) (db.Event, error)
+	Update(ctx context.Context, params db.UpdateEventParams) error
+	// SetStatus moves an event to the given status, returning ErrNotFound
+	// if it does not exist and ErrConflict if publishing it would give two
+	// public events the same year and slug.
+	SetStatus(ctx context.Context, id int64, status db.EventStatus) error
+	// CreateWithRaces creates an event and its races together. An event
+	// created in a series starts it if the series has no first edition yet.
+	CreateWithRaces(ctx context.Context, event db.CreateEventParams, races []db.CreateRaceParams) (db.Event, error)
+	GetEventStats(ctx context.Context, organisationID int64) ([]db.GetEventStatsRow, error)
+	// CountRegistrationsByPeriod counts the event's registrations by the
+	// day or week they were made, in periods with any, oldest first.
+	CountRegistrationsByPeriod(ctx context.Context, params db.CountEventRegistrationsByPeriodParams) ([]db.CountEventRegistrationsByPeriodRow, error)
 }
 
 type eventRepository struct {
 	queries *db.Queries
+	pool    TxBeginner
 }
 
-// NewEventRepository creates a new EventRepository backed by the given queries.
-func NewEventRepository(queries *db.Queries) EventRepository {
-	return &eventRepository{queries: queries}
+// NewEventRepository creates a new EventRepository backed by the given
+// queries, using pool for writes that must be atomic.
+func NewEventRepository(queries *db.Queries, pool TxBeginner) EventRepository {
+	return &eventRepository{queries: queries, pool: pool}
 }
 
 func (r *eventRepository) List(ctx context.Context) ([]db.Event, error) {
 	return r.queries.ListEvents(ctx)
 }
 
-func (r *eventRepository) GetBySlug(ctx context.Context, slug string) (db.Event, error) {
-	event, err := r.queries.GetEvent(ctx, slug)
+func (r *eventRepository) ListPaginated(ctx context.Context, limit, offset int32) ([]db.Event, error) {
+	return r.queries.ListEventsPaginated(ctx, db.ListEventsPaginatedParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (r *eventRepository) ListWithStats(ctx context.Context, params db.ListEventsWithStatsParams) ([]db.ListEventsWithStatsRow, error) {
+	return r.queries.ListEventsWithStats(ctx, params)
+}
+
+func (r *eventRepository) ListByYear(ctx context.Context, year int32, now time.Time, includePast bool) ([]db.ListEventsByYearRow, error) {
+	return r.queries.ListEventsByYear(ctx, db.ListEventsByYearParams{
+		Now:         pgtype.Timestamptz{Time: now, Valid: true},
+		Year:        year,
+		IncludePast: includePast,
+	})
+}
+
+func (r *eventRepository) ListYears(ctx context.Context) ([]int32, error) {
+	return r.queries.ListDistinctYears(ctx)
+}
+
+func (r *eventRepository) Count(ctx context.Context) (int64, error) {
+	return r.queries.CountEvents(ctx)
+}
+
+func (r *eventRepository) ListSitemap(ctx context.Context, limit, offset int32) ([]db.ListSitemapEventsRow, error) {
+	return r.queries.ListSitemapEvents(ctx, db.ListSitemapEventsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (r *eventRepository) CountSitemap(ctx context.Context) (int64, error) {
+	return r.queries.CountSitemapEvents(ctx)
+}
+
+func (r *eventRepository) GetBySlug(ctx context.Context, year int32, slug string) (db.Event, error) {
+	event, err := r.queries.GetEvent(ctx, db.GetEventParams{Year: year, Slug: slug})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return db.Event{}, ErrNotFound
@@ -40,6 +131,156 @@ func (r *eventRepository) GetBySlug(ctx context.Context, slug string) (db.Event,
 	return event, nil
 }
 
+func (r *eventRepository) ListBySlug(ctx context.Context, slug string) ([]db.Event, error) {
+	return r.queries.ListPublishedEventsBySlug(ctx, slug)
+}
+
+func (r *eventRepository) GetByID(ctx context.Context, id int64) (db.Event, error) {
+	event, err := r.queries.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Event{}, ErrNotFound
+		}
+		return db.Event{}, err
+	}
+	return event, nil
+}
+
+func (r *eventRepository) ListInSeries(ctx context.Context, seriesID int64) ([]db.ListEventsInSeriesRow, error) {
+	return r.queries.ListEventsInSeries(ctx, pgtype.Int8{Int64: seriesID, Valid: true})
+}
+
+func (r *eventRepository) ListByOrganisation(ctx context.Context, organisationID int64) ([]db.Event, error) {
+	return r.queries.ListOrganisationEvents(ctx, organisationID)
+}
+
+// JoinSeries marks the other event as the first edition of a series if it
+// is in none, then moves the event into the other's series, in a single
+// transaction.
+func (r *eventRepository) JoinSeries(ctx context.Context, eventID, seriesEventID int64) (db.Event, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return db.Event{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	if err := qtx.StartEventSeries(ctx, seriesEventID); err != nil {
+		return db.Event{}, err
+	}
+	other, err := qtx.GetEventByID(ctx, seriesEventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Event{}, ErrNotFound
+		}
+		return db.Event{}, err
+	}
+	n, err := qtx.SetEventSeries(ctx, db.SetEventSeriesParams{ID: eventID, SeriesID: other.SeriesID})
+	if err != nil {
+		return db.Event{}, err
+	}
+	if n == 0 {
+		return db.Event{}, ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.Event{}, err
+	}
+	return other, nil
+}
+
+func (r *eventRepository) LeaveSeries(ctx context.Context, eventID int64) error {
+	n, err := r.queries.SetEventSeries(ctx, db.SetEventSeriesParams{ID: eventID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 func (r *eventRepository) Create(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
-	return r.queries.CreateEvent(ctx, params)
+	event, err := r.queries.CreateEvent(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.Event{}, ErrConflict
+		}
+		return db.Event{}, err
+	}
+	return event, nil
+}
+
+func (r *eventRepository) Update(ctx context.Context, params db.UpdateEventParams) error {
+	if err := r.queries.UpdateEvent(ctx, params); err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *eventRepository) SetStatus(ctx context.Context, id int64, status db.EventStatus) error {
+	n, err := r.queries.SetEventStatus(ctx, db.SetEventStatusParams{ID: id, Status: status})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateWithRaces creates an event and its races in a single transaction.
+// The EventID of each race is set to the new event's ID, and the event the
+// series is named after is marked as its first edition.
+func (r *eventRepository) CreateWithRaces(ctx context.Context, event db.CreateEventParams, races []db.CreateRaceParams) (db.Event, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return db.Event{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	created, err := qtx.CreateEvent(ctx, event)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.Event{}, ErrConflict
+		}
+		return db.Event{}, err
+	}
+	if event.SeriesID.Valid {
+		if err := qtx.StartEventSeries(ctx, event.SeriesID.Int64); err != nil {
+			return db.Event{}, err
+		}
+	}
+
+	for _, race := range races {
+		race.EventID = created.ID
+		if _, err := qtx.CreateRace(ctx, race); err != nil {
+			if isUniqueViolation(err) {
+				return db.Event{}, ErrConflict
+			}
+			return db.Event{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.Event{}, err
+	}
+	return created, nil
+}
+
+func (r *eventRepository) GetEventStats(ctx context.Context, organisationID int64) ([]db.GetEventStatsRow, error) {
+	return r.queries.GetEventStats(ctx, organisationID)
+}
+
+func (r *eventRepository) CountRegistrationsByPeriod(ctx context.Context, params db.CountEventRegistrationsByPeriodParams) ([]db.CountEventRegistrationsByPeriodRow, error) {
+	return r.queries.CountEventRegistrationsByPeriod(ctx, params)
 }
@@ -0,0 +1,835 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestEventRepository(t *testing.T) {
+	ctx := context.Background()
+
+	newEvent := func(orgID int64, slug string, year int32) db.CreateEventParams {
+		return db.CreateEventParams{
+			OrganisationID: orgID,
+			Name:           "Peak District Ultra",
+			Slug:           slug,
+			Year:           year,
+			Description:    "A **tough** route",
+			Location:       "Castleton",
+			ImageUrl:       "https://example.com/hero.jpg",
+			Timezone:       "Europe/London",
+		}
+	}
+	// createPublished creates an event and publishes it, so that public
+	// listings and lookups find it.
+	createPublished := func(repo EventRepository, params db.CreateEventParams) (db.Event, error) {
+		event, err := repo.Create(ctx, params)
+		if err != nil {
+			return db.Event{}, err
+		}
+		if err := repo.SetStatus(ctx, event.ID, db.EventStatusPublished); err != nil {
+			return db.Event{}, err
+		}
+		return repo.GetByID(ctx, event.ID)
+	}
+
+	t.Run("creates and gets an event", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		created, err := repo.Create(ctx, newEvent(org.ID, "peak-district-ultra", 2026))
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if created.ID == 0 || !created.CreatedAt.Valid {
+			t.Errorf("expected database-assigned ID and timestamps, got %+v", created)
+		}
+		if created.Status != db.EventStatusDraft {
+			t.Errorf("expected new events to be drafts, got %q", created.Status)
+		}
+
+		byID, err := repo.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("failed to get event by ID: %v", err)
+		}
+		if byID.Slug != "peak-district-ultra" || byID.Year != 2026 || byID.OrganisationID != org.ID {
+			t.Errorf("unexpected event: %+v", byID)
+		}
+		if byID.Description != "A **tough** route" || byID.Location != "Castleton" || byID.ImageUrl != "https://example.com/hero.jpg" || byID.Timezone != "Europe/London" {
+			t.Errorf("expected event details to round-trip, got %+v", byID)
+		}
+
+		if err := repo.SetStatus(ctx, created.ID, db.EventStatusPublished); err != nil {
+			t.Fatalf("failed to publish event: %v", err)
+		}
+		bySlug, err := repo.GetBySlug(ctx, 2026, "peak-district-ultra")
+		if err != nil {
+			t.Fatalf("failed to get event by slug: %v", err)
+		}
+		if bySlug.ID != created.ID {
+			t.Errorf("expected event %d, got %d", created.ID, bySlug.ID)
+		}
+	})
+
+	t.Run("shows only published events publicly", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		published, err := createPublished(repo, newEvent(org.ID, "published", 2026))
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		draft, err := repo.Create(ctx, newEvent(org.ID, "draft", 2026))
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		archived, err := createPublished(repo, newEvent(org.ID, "archived", 2027))
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if err := repo.SetStatus(ctx, archived.ID, db.EventStatusArchived); err != nil {
+			t.Fatalf("failed to archive event: %v", err)
+		}
+		// A newer draft of the slug stays hidden
+		if _, err := repo.Create(ctx, newEvent(org.ID, "published", 2027)); err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+
+		for _, e := range []db.Event{draft, archived} {
+			if _, err := repo.GetBySlug(ctx, e.Year, e.Slug); !errors.Is(err, ErrNotFound) {
+				t.Errorf("GetBySlug(%d, %q): expected ErrNotFound, got %v", e.Year, e.Slug, err)
+			}
+		}
+		if _, err := repo.GetBySlug(ctx, 2027, "published"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetBySlug(2027, published): expected ErrNotFound, got %v", err)
+		}
+		if event, err := repo.GetBySlug(ctx, 2026, "published"); err != nil || event.ID != published.ID {
+			t.Errorf("expected the published 2026 edition, got %+v (err %v)", event, err)
+		}
+		if editions, err := repo.ListBySlug(ctx, "published"); err != nil || len(editions) != 1 || editions[0].ID != published.ID {
+			t.Errorf("ListBySlug: expected only the published edition, got %+v (err %v)", editions, err)
+		}
+
+		listed, err := repo.List(ctx)
+		if err != nil || len(listed) != 1 || listed[0].ID != published.ID {
+			t.Errorf("List: expected only the published event, got %+v (err %v)", listed, err)
+		}
+		page, err := repo.ListPaginated(ctx, 10, 0)
+		if err != nil || len(page) != 1 || page[0].ID != published.ID {
+			t.Errorf("ListPaginated: expected only the published event, got %+v (err %v)", page, err)
+		}
+		if count, err := repo.Count(ctx); err != nil || count != 1 {
+			t.Errorf("Count: expected 1, got %d (err %v)", count, err)
+		}
+		byYear, err := repo.ListByYear(ctx, 2026, time.Now(), true)
+		if err != nil || len(byYear) != 1 || byYear[0].Event.ID != published.ID {
+			t.Errorf("ListByYear: expected only the published event, got %+v (err %v)", byYear, err)
+		}
+		if years, err := repo.ListYears(ctx); err != nil || !slices.Equal(years, []int32{2026}) {
+			t.Errorf("ListYears: expected only 2026, got %v (err %v)", years, err)
+		}
+		if rows, err := repo.ListSitemap(ctx, 10, 0); err != nil || len(rows) != 1 || rows[0].Slug != "published" {
+			t.Errorf("ListSitemap: expected only the published event, got %+v (err %v)", rows, err)
+		}
+
+		// Organisers still find every event by ID, with its status
+		for _, e := range []db.Event{draft, archived} {
+			got, err := repo.GetByID(ctx, e.ID)
+			if err != nil {
+				t.Errorf("GetByID(%d): unexpected error %v", e.ID, err)
+			}
+			if got.Status == db.EventStatusPublished {
+				t.Errorf("GetByID(%d): expected a %s event, got published", e.ID, got.Slug)
+			}
+		}
+		stats, err := repo.GetEventStats(ctx, org.ID)
+		if err != nil || len(stats) != 4 {
+			t.Fatalf("GetEventStats: expected all four events, got %+v (err %v)", stats, err)
+		}
+	})
+
+	t.Run("counts registrations by period in the event's time zone", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+		event, err := repo.CreateWithRaces(ctx, newEvent(org.ID, "summer-10k", 2026), []db.CreateRaceParams{{Name: "10K", Slug: "10k", MaxCapacity: 100}})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		race, err := queries.GetRaceBySlug(ctx, db.GetRaceBySlugParams{EventID: event.ID, Slug: "10k"})
+		if err != nil {
+			t.Fatalf("failed to get race: %v", err)
+		}
+		for i, reg := range []struct {
+			at     time.Time
+			status db.RegistrationStatus
+		}{
+			// Before the window, so counted in its first day
+			{time.Date(2026, time.June, 1, 9, 0, 0, 0, time.UTC), db.RegistrationStatusConfirmed},
+			{time.Date(2026, time.June, 29, 9, 0, 0, 0, time.UTC), db.RegistrationStatusConfirmed},
+			// 00:30 on 1 July in London
+			{time.Date(2026, time.June, 30, 23, 30, 0, 0, time.UTC), db.RegistrationStatusConfirmed},
+			{time.Date(2026, time.July, 1, 9, 0, 0, 0, time.UTC), db.RegistrationStatusCancelled},
+		} {
+			user := createTestUser(t, queries, fmt.Sprintf("runner%d@example.com", i))
+			if _, err := testPool.Exec(ctx,
+				"INSERT INTO registrations (user_id, race_id, status, created_at) VALUES ($1, $2, $3, $4)",
+				user.ID, race.ID, reg.status, reg.at,
+			); err != nil {
+				t.Fatalf("failed to create registration: %v", err)
+			}
+		}
+
+		rows, err := repo.CountRegistrationsByPeriod(ctx, db.CountEventRegistrationsByPeriodParams{
+			Granularity: "day",
+			WindowFrom:  pgtype.Timestamptz{Time: time.Date(2026, time.June, 28, 0, 0, 0, 0, time.UTC), Valid: true},
+			WindowTo:    pgtype.Timestamptz{Time: time.Date(2026, time.July, 31, 0, 0, 0, 0, time.UTC), Valid: true},
+			Timezone:    "Europe/London",
+			EventID:     event.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to count registrations: %v", err)
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, fmt.Sprintf("%s=%d", row.Period.Time.Format(time.DateOnly), row.Registered))
+		}
+		if want := []string{"2026-06-28=1", "2026-06-29=1", "2026-07-01=1"}; !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("returns ErrNotFound setting the status of a missing event", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewEventRepository(queries, testPool)
+
+		if err := repo.SetStatus(ctx, 999, db.EventStatusPublished); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("gets an edition by year and slug", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		for _, year := range []int32{2026, 2027, 2025} {
+			if _, err := createPublished(repo, newEvent(org.ID, "lakes-half", year)); err != nil {
+				t.Fatalf("failed to create %d edition: %v", year, err)
+			}
+		}
+
+		event, err := repo.GetBySlug(ctx, 2026, "lakes-half")
+		if err != nil {
+			t.Fatalf("failed to get event: %v", err)
+		}
+		if event.Year != 2026 {
+			t.Errorf("expected the 2026 edition, got %d", event.Year)
+		}
+
+		editions, err := repo.ListBySlug(ctx, "lakes-half")
+		if err != nil {
+			t.Fatalf("failed to list editions: %v", err)
+		}
+		var years []int32
+		for _, e := range editions {
+			years = append(years, e.Year)
+		}
+		if !slices.Equal(years, []int32{2027, 2026, 2025}) {
+			t.Errorf("expected the editions latest first, got %v", years)
+		}
+	})
+
+	t.Run("returns ErrNotFound for missing events", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewEventRepository(queries, testPool)
+
+		if _, err := repo.GetByID(ctx, 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetByID: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetBySlug(ctx, 2026, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetBySlug: expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrNotFound for deleted events by ID", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		event, err := repo.Create(ctx, newEvent(org.ID, "deleted-race", 2026))
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if err := queries.DeleteEvent(ctx, event.ID); err != nil {
+			t.Fatalf("failed to delete event: %v", err)
+		}
+
+		if _, err := repo.GetByID(ctx, event.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrConflict for a duplicate slug in the same year", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		if _, err := repo.Create(ctx, newEvent(org.ID, "lincoln-10k", 2026)); err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if _, err := repo.Create(ctx, newEvent(org.ID, "lincoln-10k", 2026)); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+		if _, err := repo.Create(ctx, newEvent(org.ID, "lincoln-10k", 2027)); err != nil {
+			t.Errorf("expected the slug to be free in another year, got %v", err)
+		}
+	})
+
+	t.Run("lets two organisations use the same slug in a year", func(t *testing.T) {
+		queries := resetDB(t)
+		first := createTestOrganisation(t, queries)
+		second, err := queries.CreateOrganisation(ctx, "Lincoln Harriers")
+		if err != nil {
+			t.Fatalf("failed to create organisation: %v", err)
+		}
+		repo := NewEventRepository(queries, testPool)
+
+		published, err := createPublished(repo, newEvent(first.ID, "half-marathon", 2026))
+		if err != nil {
+			t.Fatalf("failed to create the first organisation's event: %v", err)
+		}
+		other, err := repo.Create(ctx, newEvent(second.ID, "half-marathon", 2026))
+		if err != nil {
+			t.Fatalf("expected the second organisation to use the slug too, got %v", err)
+		}
+
+		// Both cannot be public, as they would share /events/2026/half-marathon
+		if err := repo.SetStatus(ctx, other.ID, db.EventStatusPublished); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict publishing the second, got %v", err)
+		}
+		if event, err := repo.GetBySlug(ctx, 2026, "half-marathon"); err != nil || event.ID != published.ID {
+			t.Errorf("expected the first organisation's event, got %+v (err %v)", event, err)
+		}
+
+		// Once the first is archived, the second may take its place
+		if err := repo.SetStatus(ctx, published.ID, db.EventStatusArchived); err != nil {
+			t.Fatalf("failed to archive event: %v", err)
+		}
+		if err := repo.SetStatus(ctx, other.ID, db.EventStatusPublished); err != nil {
+			t.Errorf("expected the second to publish, got %v", err)
+		}
+	})
+
+	t.Run("lists and counts events that are not deleted", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		var ids []int64
+		for _, p := range []struct {
+			slug string
+			year int32
+		}{{"a-race", 2026}, {"b-race", 2027}, {"c-race", 2026}} {
+			event, err := createPublished(repo, newEvent(org.ID, p.slug, p.year))
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+			ids = append(ids, event.ID)
+		}
+		if err := queries.DeleteEvent(ctx, ids[2]); err != nil {
+			t.Fatalf("failed to delete event: %v", err)
+		}
+
+		count, err := repo.Count(ctx)
+		if err != nil {
+			t.Fatalf("failed to count events: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("expected 2 events, got %d", count)
+		}
+
+		page, err := repo.ListPaginated(ctx, 1, 0)
+		if err != nil {
+			t.Fatalf("failed to list events: %v", err)
+		}
+		if len(page) != 1 || page[0].ID != ids[1] {
+			t.Errorf("expected the 2027 event first, got %+v", page)
+		}
+		page, err = repo.ListPaginated(ctx, 10, 1)
+		if err != nil {
+			t.Fatalf("failed to list events: %v", err)
+		}
+		if len(page) != 1 || page[0].ID != ids[0] {
+			t.Errorf("expected the remaining 2026 event, got %+v", page)
+		}
+	})
+
+	t.Run("lists every edition for the sitemap", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		var latest db.Event
+		for _, p := range []struct {
+			slug string
+			year int32
+		}{{"b-race", 2026}, {"a-race", 2026}, {"b-race", 2027}, {"c-race", 2026}} {
+			event, err := createPublished(repo, newEvent(org.ID, p.slug, p.year))
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+			if p.slug == "b-race" && p.year == 2027 {
+				latest = event
+			}
+			if p.slug == "c-race" {
+				if err := queries.DeleteEvent(ctx, event.ID); err != nil {
+					t.Fatalf("failed to delete event: %v", err)
+				}
+			}
+		}
+
+		count, err := repo.Count(ctx)
+		if err != nil || count != 3 {
+			t.Fatalf("expected 3 editions, got %d (err %v)", count, err)
+		}
+		rows, err := repo.ListSitemap(ctx, 10, 0)
+		if err != nil {
+			t.Fatalf("failed to list sitemap: %v", err)
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, fmt.Sprintf("%d/%s", row.Year, row.Slug))
+		}
+		if !slices.Equal(got, []string{"2027/b-race", "2026/a-race", "2026/b-race"}) {
+			t.Fatalf("expected the latest year first, then by slug, got %v", got)
+		}
+		if !rows[0].UpdatedAt.Time.Equal(latest.UpdatedAt.Time) {
+			t.Errorf("expected the 2027 edition's updated_at, got %v", rows[0].UpdatedAt.Time)
+		}
+		if rows, err := repo.ListSitemap(ctx, 10, 1); err != nil || len(rows) != 2 || rows[0].Slug != "a-race" {
+			t.Errorf("expected the offset to skip the 2027 edition, got %+v (err %v)", rows, err)
+		}
+	})
+
+	t.Run("links duplicated editions as a series", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		first, err := createPublished(repo, newEvent(org.ID, "peak-district-ultra", 2025))
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		series := pgtype.Int8{Int64: first.ID, Valid: true}
+		params := newEvent(org.ID, "peak-district-ultra", 2026)
+		params.SeriesID = series
+		second, err := repo.CreateWithRaces(ctx, params, []db.CreateRaceParams{{Name: "Ultra 50K", Slug: "ultra-50k", MaxCapacity: 300}})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if err := repo.SetStatus(ctx, second.ID, db.EventStatusPublished); err != nil {
+			t.Fatalf("failed to publish event: %v", err)
+		}
+
+		if first, err = repo.GetByID(ctx, first.ID); err != nil || first.SeriesID != series {
+			t.Fatalf("expected the first edition to start the series, got %+v (err %v)", first.SeriesID, err)
+		}
+
+		races, err := NewRaceRepository(queries, testPool).ListByEvent(ctx, second.ID)
+		if err != nil {
+			t.Fatalf("failed to list races: %v", err)
+		}
+		results := NewResultRepository(queries, testPool)
+		if err := results.Replace(ctx, races[0].ID, []db.CreateRaceResultParams{{Name: "Ola", Position: 1}}); err != nil {
+			t.Fatalf("failed to save results: %v", err)
+		}
+		if _, err := results.Publish(ctx, races[0].ID); err != nil {
+			t.Fatalf("failed to publish results: %v", err)
+		}
+
+		editions, err := repo.ListInSeries(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("failed to list series: %v", err)
+		}
+		if len(editions) != 2 || editions[0].Event.Year != 2026 || editions[1].Event.Year != 2025 {
+			t.Fatalf("expected 2026 then 2025, got %+v", editions)
+		}
+		if !slices.Equal(editions[0].ResultRaceSlugs, []string{"ultra-50k"}) || len(editions[1].ResultRaceSlugs) != 0 {
+			t.Errorf("expected only 2026's results, got %v and %v", editions[0].ResultRaceSlugs, editions[1].ResultRaceSlugs)
+		}
+
+		if count, err := repo.CountSitemap(ctx); err != nil || count != 1 {
+			t.Errorf("expected one sitemap entry for the series, got %d (err %v)", count, err)
+		}
+		if rows, err := repo.ListSitemap(ctx, 10, 0); err != nil || len(rows) != 1 || rows[0].Year != 2026 {
+			t.Errorf("expected only the 2026 edition in the sitemap, got %+v (err %v)", rows, err)
+		}
+
+		if err := repo.LeaveSeries(ctx, second.ID); err != nil {
+			t.Fatalf("failed to leave series: %v", err)
+		}
+		if count, err := repo.CountSitemap(ctx); err != nil || count != 2 {
+			t.Errorf("expected both editions once unlinked, got %d (err %v)", count, err)
+		}
+		if _, err := repo.JoinSeries(ctx, second.ID, first.ID); err != nil {
+			t.Fatalf("failed to join series: %v", err)
+		}
+		if second, err = repo.GetByID(ctx, second.ID); err != nil || second.SeriesID != series {
+			t.Errorf("expected the event to rejoin the series, got %+v (err %v)", second.SeriesID, err)
+		}
+		if _, err := repo.JoinSeries(ctx, second.ID, 999999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing event, got %v", err)
+		}
+	})
+
+	t.Run("lists a year's events, leaving out those that have closed", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+		now := time.Now()
+
+		// Closed has closed for every race, Open still has one race taking
+		// entries, and Unlimited has a race that never closes
+		closes := map[string][]pgtype.Timestamptz{
+			"closed":    {{Time: now.Add(-48 * time.Hour), Valid: true}, {Time: now.Add(-time.Hour), Valid: true}},
+			"open":      {{Time: now.Add(-time.Hour), Valid: true}, {Time: now.Add(time.Hour), Valid: true}},
+			"unlimited": {{Time: now.Add(-time.Hour), Valid: true}, {}},
+			"no-races":  nil,
+		}
+		ids := map[string]int64{}
+		for slug, raceCloses := range closes {
+			event, err := createPublished(repo, newEvent(org.ID, slug, 2026))
+			if err != nil {
+				t.Fatalf("failed to create event: %v", err)
+			}
+			ids[slug] = event.ID
+			for i, closeDate := range raceCloses {
+				if _, err := queries.CreateRace(ctx, db.CreateRaceParams{
+					EventID:               event.ID,
+					Name:                  "Race",
+					Slug:                  fmt.Sprintf("race-%d", i),
+					RegistrationCloseDate: closeDate,
+					MaxCapacity:           100,
+				}); err != nil {
+					t.Fatalf("failed to create race: %v", err)
+				}
+			}
+		}
+		if _, err := createPublished(repo, newEvent(org.ID, "next-year", 2027)); err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+
+		listed, err := repo.ListByYear(ctx, 2026, now, false)
+		if err != nil {
+			t.Fatalf("failed to list events: %v", err)
+		}
+		if len(listed) != 3 {
+			t.Fatalf("expected the three events still open, got %+v", listed)
+		}
+		for _, row := range listed {
+			if row.Event.ID == ids["closed"] || row.Past {
+				t.Errorf("expected the closed event to drop off the listing, got %+v", row)
+			}
+		}
+
+		all, err := repo.ListByYear(ctx, 2026, now, true)
+		if err != nil {
+			t.Fatalf("failed to list events: %v", err)
+		}
+		if len(all) != 4 {
+			t.Fatalf("expected every 2026 event, got %+v", all)
+		}
+		for _, row := range all {
+			if row.Past != (row.Event.ID == ids["closed"]) {
+				t.Errorf("expected only the closed event to be past, got %s past=%v", row.Event.Slug, row.Past)
+			}
+		}
+
+		years, err := repo.ListYears(ctx)
+		if err != nil {
+			t.Fatalf("failed to list years: %v", err)
+		}
+		if !slices.Equal(years, []int32{2027, 2026}) {
+			t.Errorf("expected 2027 then 2026, got %v", years)
+		}
+	})
+
+	t.Run("updates an event", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		event, err := repo.Create(ctx, newEvent(org.ID, "old-slug", 2026))
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if _, err := repo.Create(ctx, newEvent(org.ID, "taken-slug", 2026)); err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+
+		err = repo.Update(ctx, db.UpdateEventParams{
+			ID:          event.ID,
+			Name:        "Renamed",
+			Slug:        "new-slug",
+			Description: "New route",
+			Location:    "Edale",
+		})
+		if err != nil {
+			t.Fatalf("failed to update event: %v", err)
+		}
+		updated, err := repo.GetByID(ctx, event.ID)
+		if err != nil {
+			t.Fatalf("failed to get event: %v", err)
+		}
+		if updated.Name != "Renamed" || updated.Slug != "new-slug" || updated.Location != "Edale" || updated.ImageUrl != "" {
+			t.Errorf("unexpected event after update: %+v", updated)
+		}
+
+		err = repo.Update(ctx, db.UpdateEventParams{ID: event.ID, Name: "Renamed", Slug: "taken-slug"})
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("creates an event with its races", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		starts := pgtype.Timestamptz{Time: time.Date(2026, time.June, 6, 8, 0, 0, 0, time.UTC), Valid: true}
+		event, err := repo.CreateWithRaces(ctx, newEvent(org.ID, "peak-district-ultra", 2026), []db.CreateRaceParams{
+			{Name: "Ultra 50K", Slug: "ultra-50k", StartsAt: starts, MaxCapacity: 300},
+			{Name: "Marathon", Slug: "marathon", StartsAt: starts, MaxCapacity: 200},
+		})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+
+		races, err := NewRaceRepository(queries, testPool).ListByEvent(ctx, event.ID)
+		if err != nil {
+			t.Fatalf("failed to list races: %v", err)
+		}
+		if len(races) != 2 {
+			t.Fatalf("expected 2 races, got %d", len(races))
+		}
+		for _, race := range races {
+			if race.EventID != event.ID {
+				t.Errorf("expected race for event %d, got %d", event.ID, race.EventID)
+			}
+		}
+	})
+
+	t.Run("creates nothing when a race conflicts", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewEventRepository(queries, testPool)
+
+		_, err := repo.CreateWithRaces(ctx, newEvent(org.ID, "peak-district-ultra", 2026), []db.CreateRaceParams{
+			{Name: "Ultra 50K", Slug: "ultra", MaxCapacity: 300},
+			{Name: "Ultra 100K", Slug: "ultra", MaxCapacity: 100},
+		})
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("expected ErrConflict, got %v", err)
+		}
+
+		if count, err := repo.Count(ctx); err != nil || count != 0 {
+			t.Errorf("expected the event to be rolled back, got count %d (err %v)", count, err)
+		}
+	})
+}
+
+// seedListing publishes events named "Event 000" onwards, each with races
+// races and entrants confirmed registrations on its first race.
+func seedListing(tb testing.TB, queries *db.Queries, events, races, entrants int) {
+	tb.Helper()
+	ctx := context.Background()
+	org := createTestOrganisation(tb, queries)
+	repo := NewEventRepository(queries, testPool)
+
+	var users []db.User
+	for i := range entrants {
+		users = append(users, createTestUser(tb, queries, fmt.Sprintf("runner%d@example.com", i)))
+	}
+	for i := range events {
+		params := make([]db.CreateRaceParams, 0, races)
+		for j := range races {
+			params = append(params, db.CreateRaceParams{
+				Name:                  fmt.Sprintf("Race %d", j),
+				Slug:                  fmt.Sprintf("race-%d", j),
+				RegistrationCloseDate: pgtype.Timestamptz{Time: time.Date(2026, time.June, 1+j, 0, 0, 0, 0, time.UTC), Valid: true},
+				MaxCapacity:           100,
+				PriceUnits:            pgtype.Int4{Int32: int32(2500 + 500*j), Valid: true},
+			})
+		}
+		event, err := repo.CreateWithRaces(ctx, db.CreateEventParams{
+			OrganisationID: org.ID,
+			Name:           fmt.Sprintf("Event %03d", i),
+			Slug:           fmt.Sprintf("event-%03d", i),
+			Year:           2026,
+			Timezone:       "Europe/London",
+		}, params)
+		if err != nil {
+			tb.Fatalf("failed to create event: %v", err)
+		}
+		if err := repo.SetStatus(ctx, event.ID, db.EventStatusPublished); err != nil {
+			tb.Fatalf("failed to publish event: %v", err)
+		}
+		if races == 0 {
+			continue
+		}
+		first, err := queries.GetRaceBySlug(ctx, db.GetRaceBySlugParams{EventID: event.ID, Slug: "race-0"})
+		if err != nil {
+			tb.Fatalf("failed to get race: %v", err)
+		}
+		for _, user := range users {
+			if _, err := queries.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+				UserID: user.ID,
+				RaceID: first.ID,
+			}); err != nil {
+				tb.Fatalf("failed to create registration: %v", err)
+			}
+		}
+	}
+}
+
+func TestEventRepository_ListWithStats(t *testing.T) {
+	ctx := context.Background()
+	queries := resetDB(t)
+	seedListing(t, queries, 2, 3, 4)
+	org := createTestOrganisation(t, queries)
+	repo := NewEventRepository(queries, testPool)
+
+	// An event without races still appears, after the others by name
+	empty, err := repo.Create(ctx, db.CreateEventParams{
+		OrganisationID: org.ID,
+		Name:           "Fell Race",
+		Slug:           "fell-race",
+		Year:           2026,
+		Timezone:       "Europe/London",
+	})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	if err := repo.SetStatus(ctx, empty.ID, db.EventStatusPublished); err != nil {
+		t.Fatalf("failed to publish event: %v", err)
+	}
+	// Drafts are left out
+	if _, err := repo.Create(ctx, db.CreateEventParams{
+		OrganisationID: org.ID,
+		Name:           "Draft Race",
+		Slug:           "draft-race",
+		Year:           2026,
+		Timezone:       "Europe/London",
+	}); err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+
+	rows, err := repo.ListWithStats(ctx, db.ListEventsWithStatsParams{Limit: 20})
+	if err != nil {
+		t.Fatalf("failed to list events: %v", err)
+	}
+	var names []string
+	for _, row := range rows {
+		names = append(names, row.Event.Name)
+	}
+	if want := []string{"Event 000", "Event 001", "Fell Race"}; !slices.Equal(names, want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+
+	got := rows[0]
+	if got.RaceCount != 3 || got.TotalCapacity != 300 || got.Registered != 4 {
+		t.Errorf("expected 3 races, 300 places and 4 entrants, got %+v", got)
+	}
+	if got.MinPriceUnits.Int32 != 2500 || got.PriceCurrency.Valid {
+		t.Errorf("expected the cheapest race's price without a currency, got %+v and %+v", got.MinPriceUnits, got.PriceCurrency)
+	}
+	if want := time.Date(2026, time.June, 3, 0, 0, 0, 0, time.UTC); !got.RegistrationClosesAt.Time.Equal(want) {
+		t.Errorf("expected registration to close with the last race at %v, got %v", want, got.RegistrationClosesAt.Time)
+	}
+	if got.RegistrationOpensAt.Valid || !got.RacesUpdatedAt.Valid {
+		t.Errorf("expected no open date and a race update time, got %+v", got)
+	}
+
+	none := rows[2]
+	if none.RaceCount != 0 || none.TotalCapacity != 0 || none.Registered != 0 {
+		t.Errorf("expected zero totals without races, got %+v", none)
+	}
+	if none.MinPriceUnits.Valid || none.PriceCurrency.Valid || none.RegistrationClosesAt.Valid || none.RacesUpdatedAt.Valid {
+		t.Errorf("expected no price or dates without races, got %+v", none)
+	}
+
+	page, err := repo.ListWithStats(ctx, db.ListEventsWithStatsParams{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("failed to list events: %v", err)
+	}
+	if len(page) != 1 || page[0].Event.Name != "Event 001" {
+		t.Errorf("expected the second event alone, got %+v", page)
+	}
+}
+
+// BenchmarkEventListing compares building the listing's totals with queries
+// per event, with a query per table, and with ListWithStats alone, over 20
+// events of three races each.
+func BenchmarkEventListing(b *testing.B) {
+	ctx := context.Background()
+	queries := resetDB(b)
+	seedListing(b, queries, 20, 3, 10)
+	repo := NewEventRepository(queries, testPool)
+
+	b.Run("per event", func(b *testing.B) {
+		for b.Loop() {
+			events, err := queries.ListEventsPaginated(ctx, db.ListEventsPaginatedParams{Limit: 20})
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, e := range events {
+				if _, err := queries.ListRacesByEvent(ctx, e.ID); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := queries.CountRegistrationsByEvent(ctx, []int64{e.ID}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("per table", func(b *testing.B) {
+		for b.Loop() {
+			events, err := queries.ListEventsPaginated(ctx, db.ListEventsPaginatedParams{Limit: 20})
+			if err != nil {
+				b.Fatal(err)
+			}
+			ids := make([]int64, 0, len(events))
+			for _, e := range events {
+				ids = append(ids, e.ID)
+			}
+			if _, err := queries.ListRacesByEvents(ctx, ids); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := queries.CountRegistrationsByEvent(ctx, ids); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("joined", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := repo.ListWithStats(ctx, db.ListEventsWithStatsParams{Limit: 20}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,130 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"firecrest/db"
+	"firecrest/internal/migrate"
+)
+
+// testPool is connected to a Postgres container shared by every test in the
+// package. Tests run one at a time and call resetDB to start clean.
+var testPool *pgxpool.Pool
+
+func TestMain(m *testing.M) {
+	os.Exit(runIntegrationTests(m))
+}
+
+func runIntegrationTests(m *testing.M) int {
+	ctx := context.Background()
+
+	ctr, err := postgres.Run(ctx, "postgres:16-alpine",
+		postgres.WithDatabase("firecrest_test"),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("postgres"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start postgres: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := testcontainers.TerminateContainer(ctr); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to stop postgres: %v\n", err)
+		}
+	}()
+
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get connection string: %v\n", err)
+		return 1
+	}
+	testPool, err = pgxpool.New(ctx, dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to postgres: %v\n", err)
+		return 1
+	}
+	defer testPool.Close()
+
+	if err := migrate.Up(ctx, testPool); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to migrate: %v\n", err)
+		return 1
+	}
+
+	return m.Run()
+}
+
+func TestMigrateStatus(t *testing.T) {
+	current, latest, err := migrate.Status(context.Background(), testPool)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest == 0 || current != latest {
+		t.Errorf("expected the migrated database at the latest version, got %d of %d", current, latest)
+	}
+}
+
+// resetDB empties every table, keeping the schema, and returns queries
+// against the clean database.
+func resetDB(t testing.TB) *db.Queries {
+	t.Helper()
+	ctx := context.Background()
+
+	rows, err := testPool.Query(ctx, `SELECT tablename FROM pg_tables
+WHERE schemaname = 'public' AND tablename <> 'schema_migrations'`)
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	tables, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, pgx.Identifier{table}.Sanitize())
+	}
+	if _, err := testPool.Exec(ctx, "TRUNCATE "+strings.Join(names, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatalf("failed to truncate tables: %v", err)
+	}
+
+	return db.New(testPool)
+}
+
+// createTestUser inserts an entrant with the given email.
+func createTestUser(t testing.TB, queries *db.Queries, email string) db.User {
+	t.Helper()
+
+	user, err := NewUserRepository(queries, testPool).Create(context.Background(), db.CreateUserParams{
+		Email:     email,
+		FirstName: "Jane",
+		LastName:  "Runner",
+		Role:      db.UserRoleEntrant,
+	})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+// createTestOrganisation inserts an organisation to own test events.
+func createTestOrganisation(t testing.TB, queries *db.Queries) db.Organisation {
+	t.Helper()
+
+	org, err := queries.CreateOrganisation(context.Background(), "Peak Running Co")
+	if err != nil {
+		t.Fatalf("failed to create organisation: %v", err)
+	}
+	return org
+}
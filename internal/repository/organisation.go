@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/organisation.go -pkg repositorymocks . OrganisationRepository
+
+// OrganisationRepository defines the interface for organisation data access.
+type OrganisationRepository interface {
+	List(ctx context.Context) ([]db.Organisation, error)
+	ListForUser(ctx context.Context, userID int64) ([]db.Organisation, error)
+	// Get returns the organisation, or ErrNotFound if it does not exist.
+	Get(ctx context.Context, id int64) (db.Organisation, error)
+	IsMember(ctx context.Context, organisationID, userID int64) (bool, error)
+	// GetMembership returns the user's membership of the organisation, or
+	// ErrNotFound if they are not a member.
+	GetMembership(ctx context.Context, userID, organisationID int64) (db.OrganisationMember, error)
+	// ListMembers returns the organisation's members with their user
+	// details, owners first.
+	ListMembers(ctx context.Context, organisationID int64) ([]db.ListOrganisationMembersRow, error)
+	// AddMember makes the user a member of the organisation in role,
+	// restoring a membership that was removed. It returns ErrConflict if the
+	// user is already a member.
+	AddMember(ctx context.Context, organisationID, userID int64, role db.OrganisationRole) (db.OrganisationMember, error)
+	// RemoveMember ends the user's membership of the organisation. It
+	// returns ErrNotFound if they are not a member.
+	RemoveMember(ctx context.Context, organisationID, userID int64) error
+	// SetNotificationPreference sets how the member hears about new
+	// registrations. It returns ErrNotFound if they are not a member.
+	SetNotificationPreference(ctx context.Context, organisationID, userID int64, pref db.NotificationPreference) error
+	// TouchMembership records that the member is working in the
+	// organisation now. Users who are not members are left as they are.
+	TouchMembership(ctx context.Context, organisationID, userID int64) error
+	// LastUsedOrganisation returns the ID of the organisation the user
+	// most recently worked in, or ErrNotFound if they have worked in none
+	// they are still a member of.
+	LastUsedOrganisation(ctx context.Context, userID int64) (int64, error)
+	// SetContactEmail sets where enquiries about the organisation's events
+	// go, or clears it. It returns ErrNotFound if the organisation does not
+	// exist.
+	SetContactEmail(ctx context.Context, organisationID int64, email pgtype.Text) error
+	// SetEmbedding sets which other websites may read the availability of
+	// the organisation's races. It returns ErrNotFound if the organisation
+	// does not exist.
+	SetEmbedding(ctx context.Context, organisationID int64, public bool, origins []string) error
+	// SetBranding replaces the organisation's brand colour and the blob
+	// store keys of its logo and banner. It returns ErrNotFound if the
+	// organisation does not exist.
+	SetBranding(ctx context.Context, params db.SetOrganisationBrandingParams) error
+	// ListImmediateNotificationRecipients returns the members of the
+	// organisation who want an email for every new registration.
+	ListImmediateNotificationRecipients(ctx context.Context, organisationID int64) ([]db.ListImmediateNotificationRecipientsRow, error)
+	// ClaimRegistrationDigests marks the registration digest for day as
+	// sent to every member who wants one, and returns them to be emailed.
+	// Members already sent the digest for day, or a later one, are left
+	// out, so running it again for the same day returns nothing new.
+	ClaimRegistrationDigests(ctx context.Context, day time.Time) ([]db.ClaimRegistrationDigestsRow, error)
+	// Invite records an invitation to join the organisation for the user
+	// with the invitee's email, creating an entrant user if there is none,
+	// all in one transaction. It returns ErrConflict if the invitee is
+	// already a member.
+	Invite(ctx context.Context, params InviteParams) (Invitation, error)
+	// ListPendingInvitations returns the organisation's unaccepted
+	// invitations, oldest first.
+	ListPendingInvitations(ctx context.Context, organisationID int64) ([]db.ListPendingOrganisationInvitationsRow, error)
+	// AcceptInvitations makes the user a member of every organisation
+	// waiting on them and lets entrants among them into the admin pages, in
+	// one transaction. It returns how many memberships were added.
+	AcceptInvitations(ctx context.Context, userID int64) (int64, error)
+}
+
+// InviteParams identifies an organisation and the member being invited.
+type InviteParams struct {
+	OrganisationID int64
+	InviterID      int64
+	InviteeEmail   string
+	Role           db.OrganisationRole
+}
+
+// Invitation is the outcome of an invite.
+type Invitation struct {
+	Invitation db.OrganisationInvitation
+	Invitee    db.User
+	// NewInvitee reports whether the invitee's user was created by the
+	// invitation.
+	NewInvitee bool
+}
+
+type organisationRepository struct {
+	queries *db.Queries
+	pool    TxBeginner
+}
+
+// NewOrganisationRepository creates a new OrganisationRepository backed by
+// the given queries, using pool for writes that must be atomic.
+func NewOrganisationRepository(queries *db.Queries, pool TxBeginner) OrganisationRepository {
+	return &organisationRepository{queries: queries, pool: pool}
+}
+
+func (r *organisationRepository) List(ctx context.Context) ([]db.Organisation, error) {
+	return r.queries.ListOrganisations(ctx)
+}
+
+func (r *organisationRepository) ListForUser(ctx context.Context, userID int64) ([]db.Organisation, error) {
+	return r.queries.ListOrganisationsForUser(ctx, userID)
+}
+
+func (r *organisationRepository) Get(ctx context.Context, id int64) (db.Organisation, error) {
+	org, err := r.queries.GetOrganisation(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Organisation{}, ErrNotFound
+		}
+		return db.Organisation{}, err
+	}
+	return org, nil
+}
+
+func (r *organisationRepository) IsMember(ctx context.Context, organisationID, userID int64) (bool, error) {
+	return r.queries.IsOrganisationMember(ctx, db.IsOrganisationMemberParams{
+		OrganisationID: organisationID,
+		UserID:         userID,
+	})
+}
+
+func (r *organisationRepository) GetMembership(ctx context.Context, userID, organisationID int64) (db.OrganisationMember, error) {
+	member, err := r.queries.GetOrganisationMembership(ctx, db.GetOrganisationMembershipParams{
+		UserID:         userID,
+		OrganisationID: organisationID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.OrganisationMember{}, ErrNotFound
+		}
+		return db.OrganisationMember{}, err
+	}
+	return member, nil
+}
+
+func (r *organisationRepository) ListMembers(ctx context.Context, organisationID int64) ([]db.ListOrganisationMembersRow, error) {
+	return r.queries.ListOrganisationMembers(ctx, organisationID)
+}
+
+func (r *organisationRepository) AddMember(ctx context.Context, organisationID, userID int64, role db.OrganisationRole) (db.OrganisationMember, error) {
+	member, err := r.queries.AddOrganisationMember(ctx, db.AddOrganisationMemberParams{
+		OrganisationID: organisationID,
+		UserID:         userID,
+		Role:           role,
+	})
+	if err != nil {
+		// A current member leaves the upsert with nothing to return
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.OrganisationMember{}, ErrConflict
+		}
+		return db.OrganisationMember{}, err
+	}
+	return member, nil
+}
+
+func (r *organisationRepository) RemoveMember(ctx context.Context, organisationID, userID int64) error {
+	n, err := r.queries.RemoveOrganisationMember(ctx, db.RemoveOrganisationMemberParams{
+		OrganisationID: organisationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *organisationRepository) SetNotificationPreference(ctx context.Context, organisationID, userID int64, pref db.NotificationPreference) error {
+	n, err := r.queries.SetNotificationPreference(ctx, db.SetNotificationPreferenceParams{
+		OrganisationID:         organisationID,
+		UserID:                 userID,
+		NotificationPreference: pref,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *organisationRepository) TouchMembership(ctx context.Context, organisationID, userID int64) error {
+	return r.queries.TouchOrganisationMembership(ctx, db.TouchOrganisationMembershipParams{
+		OrganisationID: organisationID,
+		UserID:         userID,
+	})
+}
+
+func (r *organisationRepository) LastUsedOrganisation(ctx context.Context, userID int64) (int64, error) {
+	id, err := r.queries.GetLastUsedOrganisationID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *organisationRepository) SetContactEmail(ctx context.Context, organisationID int64, email pgtype.Text) error {
+	n, err := r.queries.SetOrganisationContactEmail(ctx, db.SetOrganisationContactEmailParams{ID: organisationID, ContactEmail: email})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *organisationRepository) SetEmbedding(ctx context.Context, organisationID int64, public bool, origins []string) error {
+	n, err := r.queries.SetOrganisationEmbedding(ctx, db.SetOrganisationEmbeddingParams{
+		ID:           organisationID,
+		EmbedPublic:  public,
+		EmbedOrigins: origins,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *organisationRepository) SetBranding(ctx context.Context, params db.SetOrganisationBrandingParams) error {
+	n, err := r.queries.SetOrganisationBranding(ctx, params)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *organisationRepository) ListImmediateNotificationRecipients(ctx context.Context, organisationID int64) ([]db.ListImmediateNotificationRecipientsRow, error) {
+	return r.queries.ListImmediateNotificationRecipients(ctx, organisationID)
+}
+
+func (r *organisationRepository) ClaimRegistrationDigests(ctx context.Context, day time.Time) ([]db.ClaimRegistrationDigestsRow, error) {
+	return r.queries.ClaimRegistrationDigests(ctx, pgtype.Date{Time: day, Valid: true})
+}
+
+func (r *organisationRepository) Invite(ctx context.Context, params InviteParams) (Invitation, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Invitation{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	var result Invitation
+	result.Invitee, err = qtx.GetUserByEmail(ctx, params.InviteeEmail)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// The invitee fills in their name when they sign up
+		result.Invitee, err = qtx.CreateUser(ctx, db.CreateUserParams{
+			Email: params.InviteeEmail,
+			Role:  db.UserRoleEntrant,
+		})
+		if err != nil {
+			return Invitation{}, err
+		}
+		result.NewInvitee = true
+	case err != nil:
+		return Invitation{}, err
+	default:
+		member, err := qtx.IsOrganisationMember(ctx, db.IsOrganisationMemberParams{
+			OrganisationID: params.OrganisationID,
+			UserID:         result.Invitee.ID,
+		})
+		if err != nil {
+			return Invitation{}, err
+		}
+		if member {
+			return Invitation{}, ErrConflict
+		}
+	}
+
+	result.Invitation, err = qtx.CreateOrganisationInvitation(ctx, db.CreateOrganisationInvitationParams{
+		OrganisationID: params.OrganisationID,
+		UserID:         result.Invitee.ID,
+		Role:           params.Role,
+		InvitedBy:      pgtype.Int8{Int64: params.InviterID, Valid: true},
+	})
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Invitation{}, err
+	}
+	return result, nil
+}
+
+func (r *organisationRepository) ListPendingInvitations(ctx context.Context, organisationID int64) ([]db.ListPendingOrganisationInvitationsRow, error) {
+	return r.queries.ListPendingOrganisationInvitations(ctx, organisationID)
+}
+
+func (r *organisationRepository) AcceptInvitations(ctx context.Context, userID int64) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	n, err := qtx.AcceptOrganisationInvitations(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	// Members reach their organisation through the admin pages, which
+	// entrants cannot open
+	if n > 0 {
+		if err := qtx.PromoteEntrantToOrganizer(ctx, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
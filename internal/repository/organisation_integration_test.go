@@ -0,0 +1,280 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestOrganisationRepository(t *testing.T) {
+	ctx := context.Background()
+
+	// setup creates an organisation owned by jane@example.com.
+	setup := func(t *testing.T) (*db.Queries, OrganisationRepository, db.Organisation, db.User) {
+		t.Helper()
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		owner := createTestUser(t, queries, "jane@example.com")
+		repo := NewOrganisationRepository(queries, testPool)
+		if _, err := repo.AddMember(ctx, org.ID, owner.ID, db.OrganisationRoleOwner); err != nil {
+			t.Fatalf("failed to add owner: %v", err)
+		}
+		return queries, repo, org, owner
+	}
+
+	t.Run("adds, lists and removes members", func(t *testing.T) {
+		queries, repo, org, owner := setup(t)
+		staff := createTestUser(t, queries, "sam@example.com")
+
+		if _, err := repo.AddMember(ctx, org.ID, staff.ID, db.OrganisationRoleStaff); err != nil {
+			t.Fatalf("failed to add member: %v", err)
+		}
+		if _, err := repo.AddMember(ctx, org.ID, staff.ID, db.OrganisationRoleAdmin); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict adding a member twice, got %v", err)
+		}
+
+		members, err := repo.ListMembers(ctx, org.ID)
+		if err != nil {
+			t.Fatalf("failed to list members: %v", err)
+		}
+		if len(members) != 2 || members[0].UserID != owner.ID || members[1].Role != db.OrganisationRoleStaff {
+			t.Errorf("expected the owner then staff, got %+v", members)
+		}
+
+		member, err := repo.GetMembership(ctx, staff.ID, org.ID)
+		if err != nil || member.Role != db.OrganisationRoleStaff {
+			t.Errorf("expected a staff membership, got %+v (err %v)", member, err)
+		}
+
+		if err := repo.RemoveMember(ctx, org.ID, staff.ID); err != nil {
+			t.Fatalf("failed to remove member: %v", err)
+		}
+		if _, err := repo.GetMembership(ctx, staff.ID, org.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound after removal, got %v", err)
+		}
+		if err := repo.RemoveMember(ctx, org.ID, staff.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound removing twice, got %v", err)
+		}
+
+		// A removed member can be added back
+		if _, err := repo.AddMember(ctx, org.ID, staff.ID, db.OrganisationRoleAdmin); err != nil {
+			t.Errorf("failed to add a removed member back: %v", err)
+		}
+	})
+
+	t.Run("keeps memberships to their organisation", func(t *testing.T) {
+		queries, repo, _, owner := setup(t)
+		other, err := queries.CreateOrganisation(ctx, "Lakes Events")
+		if err != nil {
+			t.Fatalf("failed to create organisation: %v", err)
+		}
+
+		if _, err := repo.GetMembership(ctx, owner.ID, other.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected no membership of another organisation, got %v", err)
+		}
+	})
+
+	t.Run("remembers the organisation last used", func(t *testing.T) {
+		queries, repo, org, owner := setup(t)
+		other, err := queries.CreateOrganisation(ctx, "Lakes Events")
+		if err != nil {
+			t.Fatalf("failed to create organisation: %v", err)
+		}
+		if _, err := repo.AddMember(ctx, other.ID, owner.ID, db.OrganisationRoleStaff); err != nil {
+			t.Fatalf("failed to add member: %v", err)
+		}
+
+		if _, err := repo.LastUsedOrganisation(ctx, owner.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound before any is used, got %v", err)
+		}
+
+		for _, id := range []int64{other.ID, org.ID} {
+			if err := repo.TouchMembership(ctx, id, owner.ID); err != nil {
+				t.Fatalf("failed to touch membership: %v", err)
+			}
+		}
+		if got, err := repo.LastUsedOrganisation(ctx, owner.ID); err != nil || got != org.ID {
+			t.Errorf("expected organisation %d, got %d (err %v)", org.ID, got, err)
+		}
+
+		// Leaving the organisation forgets it
+		if err := repo.RemoveMember(ctx, org.ID, owner.ID); err != nil {
+			t.Fatalf("failed to remove member: %v", err)
+		}
+		if got, err := repo.LastUsedOrganisation(ctx, owner.ID); err != nil || got != other.ID {
+			t.Errorf("expected organisation %d, got %d (err %v)", other.ID, got, err)
+		}
+	})
+
+	t.Run("invites a new email and accepts the invitation", func(t *testing.T) {
+		queries, repo, org, owner := setup(t)
+
+		invited, err := repo.Invite(ctx, InviteParams{
+			OrganisationID: org.ID,
+			InviterID:      owner.ID,
+			InviteeEmail:   "sam@example.com",
+			Role:           db.OrganisationRoleAdmin,
+		})
+		if err != nil {
+			t.Fatalf("failed to invite: %v", err)
+		}
+		if !invited.NewInvitee || invited.Invitee.Role != db.UserRoleEntrant {
+			t.Errorf("expected a new entrant to be invited, got %+v", invited)
+		}
+
+		pending, err := repo.ListPendingInvitations(ctx, org.ID)
+		if err != nil || len(pending) != 1 || pending[0].Email != "sam@example.com" {
+			t.Fatalf("expected one pending invitation, got %+v (err %v)", pending, err)
+		}
+
+		n, err := repo.AcceptInvitations(ctx, invited.Invitee.ID)
+		if err != nil || n != 1 {
+			t.Fatalf("expected one invitation accepted, got %d (err %v)", n, err)
+		}
+		member, err := repo.GetMembership(ctx, invited.Invitee.ID, org.ID)
+		if err != nil || member.Role != db.OrganisationRoleAdmin {
+			t.Errorf("expected an admin membership, got %+v (err %v)", member, err)
+		}
+		user, err := queries.GetUser(ctx, invited.Invitee.ID)
+		if err != nil || user.Role != db.UserRoleOrganizer {
+			t.Errorf("expected the invitee to become an organiser, got %q (err %v)", user.Role, err)
+		}
+		if pending, _ := repo.ListPendingInvitations(ctx, org.ID); len(pending) != 0 {
+			t.Errorf("expected no pending invitations, got %+v", pending)
+		}
+
+		// Accepting again is a no-op
+		if n, err := repo.AcceptInvitations(ctx, invited.Invitee.ID); err != nil || n != 0 {
+			t.Errorf("expected nothing to accept, got %d (err %v)", n, err)
+		}
+	})
+
+	t.Run("returns ErrConflict when inviting a member", func(t *testing.T) {
+		_, repo, org, owner := setup(t)
+
+		_, err := repo.Invite(ctx, InviteParams{
+			OrganisationID: org.ID,
+			InviterID:      owner.ID,
+			InviteeEmail:   owner.Email,
+			Role:           db.OrganisationRoleStaff,
+		})
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+		if pending, _ := repo.ListPendingInvitations(ctx, org.ID); len(pending) != 0 {
+			t.Errorf("expected no invitation to be recorded, got %+v", pending)
+		}
+	})
+	t.Run("routes registration notifications by preference", func(t *testing.T) {
+		queries, repo, org, owner := setup(t)
+		staff := createTestUser(t, queries, "sam@example.com")
+		if _, err := repo.AddMember(ctx, org.ID, staff.ID, db.OrganisationRoleStaff); err != nil {
+			t.Fatalf("failed to add member: %v", err)
+		}
+
+		if err := repo.SetNotificationPreference(ctx, org.ID, owner.ID, db.NotificationPreferenceImmediate); err != nil {
+			t.Fatalf("failed to set preference: %v", err)
+		}
+		if err := repo.SetNotificationPreference(ctx, org.ID, staff.ID, db.NotificationPreferenceDailyDigest); err != nil {
+			t.Fatalf("failed to set preference: %v", err)
+		}
+		if err := repo.SetNotificationPreference(ctx, org.ID+1, staff.ID, db.NotificationPreferenceNone); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a non-member, got %v", err)
+		}
+
+		recipients, err := repo.ListImmediateNotificationRecipients(ctx, org.ID)
+		if err != nil {
+			t.Fatalf("failed to list recipients: %v", err)
+		}
+		if len(recipients) != 1 || recipients[0].Email != owner.Email {
+			t.Errorf("expected only the owner notified immediately, got %+v", recipients)
+		}
+
+		day := time.Date(2026, time.April, 30, 0, 0, 0, 0, time.UTC)
+		claimed, err := repo.ClaimRegistrationDigests(ctx, day)
+		if err != nil {
+			t.Fatalf("failed to claim digests: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].Email != staff.Email || claimed[0].OrganisationID != org.ID {
+			t.Fatalf("expected the staff member's digest claimed, got %+v", claimed)
+		}
+		if again, err := repo.ClaimRegistrationDigests(ctx, day); err != nil || len(again) != 0 {
+			t.Errorf("expected nothing to claim twice for a day, got %+v (err %v)", again, err)
+		}
+		if next, err := repo.ClaimRegistrationDigests(ctx, day.AddDate(0, 0, 1)); err != nil || len(next) != 1 {
+			t.Errorf("expected the next day's digest claimed, got %+v (err %v)", next, err)
+		}
+	})
+
+	t.Run("sets and clears the organisation's branding", func(t *testing.T) {
+		_, repo, org, _ := setup(t)
+
+		params := db.SetOrganisationBrandingParams{
+			ID:          org.ID,
+			BrandColour: pgtype.Text{String: "#1a2b3c", Valid: true},
+			LogoKey:     pgtype.Text{String: "organisations/1/logo-a.png", Valid: true},
+		}
+		if err := repo.SetBranding(ctx, params); err != nil {
+			t.Fatalf("failed to set branding: %v", err)
+		}
+		got, err := repo.Get(ctx, org.ID)
+		if err != nil {
+			t.Fatalf("failed to get organisation: %v", err)
+		}
+		if got.BrandColour != params.BrandColour || got.LogoKey != params.LogoKey || got.BannerKey.Valid {
+			t.Errorf("unexpected branding %+v", got)
+		}
+
+		if err := repo.SetBranding(ctx, db.SetOrganisationBrandingParams{ID: org.ID}); err != nil {
+			t.Fatalf("failed to clear branding: %v", err)
+		}
+		if got, _ := repo.Get(ctx, org.ID); got.BrandColour.Valid || got.LogoKey.Valid {
+			t.Errorf("expected the branding cleared, got %+v", got)
+		}
+		if err := repo.SetBranding(ctx, db.SetOrganisationBrandingParams{ID: org.ID + 1}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown organisation, got %v", err)
+		}
+	})
+
+	t.Run("sets which websites may embed availability", func(t *testing.T) {
+		_, repo, org, _ := setup(t)
+
+		if got, _ := repo.Get(ctx, org.ID); got.EmbedPublic || len(got.EmbedOrigins) != 0 {
+			t.Errorf("expected no website allowed by default, got %+v", got)
+		}
+		origins := []string{"https://peak.example", "http://localhost:3000"}
+		if err := repo.SetEmbedding(ctx, org.ID, true, origins); err != nil {
+			t.Fatalf("failed to set embedding: %v", err)
+		}
+		got, err := repo.Get(ctx, org.ID)
+		if err != nil {
+			t.Fatalf("failed to get organisation: %v", err)
+		}
+		if !got.EmbedPublic || !slices.Equal(got.EmbedOrigins, origins) {
+			t.Errorf("unexpected embedding: public %v, origins %v", got.EmbedPublic, got.EmbedOrigins)
+		}
+		if err := repo.SetEmbedding(ctx, org.ID+1, false, []string{}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown organisation, got %v", err)
+		}
+	})
+
+	t.Run("refuses a brand colour that is not a hex colour", func(t *testing.T) {
+		_, repo, org, _ := setup(t)
+
+		err := repo.SetBranding(ctx, db.SetOrganisationBrandingParams{
+			ID:          org.ID,
+			BrandColour: pgtype.Text{String: "red; background:url(x)", Valid: true},
+		})
+		if err == nil {
+			t.Error("expected the check constraint to refuse the colour")
+		}
+	})
+}
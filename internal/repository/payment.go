@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/payment.go -pkg repositorymocks . PaymentRepository
+
+// PaymentRepository defines the interface for payment data access.
+type PaymentRepository interface {
+	// GetSettledByRegistration returns the successful payment for a
+	// registration, or ErrNotFound if it was never paid for.
+	GetSettledByRegistration(ctx context.Context, registrationID int64) (db.Payment, error)
+	RecordRefund(ctx context.Context, params db.RecordPaymentRefundParams) error
+	// ListForExport returns up to limit payments for the user's
+	// registrations with an id above afterID, in id order.
+	ListForExport(ctx context.Context, userID, afterID int64, limit int) ([]db.Payment, error)
+	// RecordProviderPayment records a charge reported by the payment
+	// provider, or brings the payment recorded for it up to date; see
+	// UpsertProviderPayment. It returns ErrNotFound if the registration
+	// does not exist.
+	RecordProviderPayment(ctx context.Context, params db.UpsertProviderPaymentParams) (db.Payment, error)
+	// ListForOrganisation returns the payments taken for the organisation's
+	// events from from up to until, oldest first.
+	ListForOrganisation(ctx context.Context, organisationID int64, from, until time.Time) ([]db.ListOrganisationPaymentsRow, error)
+}
+
+type paymentRepository struct {
+	queries *db.Queries
+}
+
+// NewPaymentRepository creates a new PaymentRepository backed by the given queries.
+func NewPaymentRepository(queries *db.Queries) PaymentRepository {
+	return &paymentRepository{queries: queries}
+}
+
+func (r *paymentRepository) GetSettledByRegistration(ctx context.Context, registrationID int64) (db.Payment, error) {
+	payment, err := r.queries.GetSettledPaymentByRegistration(ctx, registrationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Payment{}, ErrNotFound
+		}
+		return db.Payment{}, err
+	}
+	return payment, nil
+}
+
+func (r *paymentRepository) RecordRefund(ctx context.Context, params db.RecordPaymentRefundParams) error {
+	return r.queries.RecordPaymentRefund(ctx, params)
+}
+
+func (r *paymentRepository) ListForExport(ctx context.Context, userID, afterID int64, limit int) ([]db.Payment, error) {
+	return r.queries.ExportUserPayments(ctx, db.ExportUserPaymentsParams{UserID: userID, AfterID: afterID, MaxRows: int32(limit)})
+}
+
+func (r *paymentRepository) RecordProviderPayment(ctx context.Context, params db.UpsertProviderPaymentParams) (db.Payment, error) {
+	payment, err := r.queries.UpsertProviderPayment(ctx, params)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return db.Payment{}, ErrNotFound
+		}
+		return db.Payment{}, err
+	}
+	return payment, nil
+}
+
+func (r *paymentRepository) ListForOrganisation(ctx context.Context, organisationID int64, from, until time.Time) ([]db.ListOrganisationPaymentsRow, error) {
+	return r.queries.ListOrganisationPayments(ctx, db.ListOrganisationPaymentsParams{
+		OrganisationID: organisationID,
+		TakenFrom:      pgtype.Timestamptz{Time: from, Valid: true},
+		TakenUntil:     pgtype.Timestamptz{Time: until, Valid: true},
+	})
+}
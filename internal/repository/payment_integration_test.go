@@ -0,0 +1,133 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestPaymentRepository(t *testing.T) {
+	ctx := context.Background()
+	taken := time.Date(2025, 10, 9, 9, 0, 0, 0, time.UTC)
+
+	// setup creates a registration for jane@example.com in an event of a
+	// new organisation.
+	setup := func(t *testing.T) (*db.Queries, db.Organisation, db.Registration) {
+		t.Helper()
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+			OrganisationID: org.ID,
+			Name:           "Harbour Run",
+			Slug:           "harbour-run",
+			Year:           2025,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+			EventID:     event.ID,
+			Name:        "10K",
+			Slug:        "10k",
+			MaxCapacity: 300,
+		})
+		if err != nil {
+			t.Fatalf("failed to create race: %v", err)
+		}
+		reg, err := queries.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+			UserID: createTestUser(t, queries, "jane@example.com").ID,
+			RaceID: race.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create registration: %v", err)
+		}
+		return queries, org, reg
+	}
+	charge := func(registrationID int64, status db.PaymentStatus, refunded int32, fee pgtype.Int4) db.UpsertProviderPaymentParams {
+		return db.UpsertProviderPaymentParams{
+			RegistrationID:    registrationID,
+			AmountUnits:       6500,
+			Currency:          "GBP",
+			Status:            status,
+			ProviderReference: pgtype.Text{String: "ch_1", Valid: true},
+			FeeUnits:          fee,
+			RefundedUnits:     refunded,
+			CreatedAt:         pgtype.Timestamptz{Time: taken, Valid: true},
+		}
+	}
+	fee := pgtype.Int4{Int32: 120, Valid: true}
+
+	t.Run("records each charge once and never undoes a refund", func(t *testing.T) {
+		queries, org, reg := setup(t)
+		repo := NewPaymentRepository(queries)
+
+		for _, params := range []db.UpsertProviderPaymentParams{
+			charge(reg.ID, db.PaymentStatusSucceeded, 0, pgtype.Int4{}),
+			charge(reg.ID, db.PaymentStatusPartiallyRefunded, 3250, fee),
+			// A retried charge.succeeded arriving after the refund
+			charge(reg.ID, db.PaymentStatusSucceeded, 0, pgtype.Int4{}),
+		} {
+			if _, err := repo.RecordProviderPayment(ctx, params); err != nil {
+				t.Fatalf("failed to record charge: %v", err)
+			}
+		}
+
+		payments, err := repo.ListForOrganisation(ctx, org.ID, taken.AddDate(0, 0, -1), taken.AddDate(0, 0, 1))
+		if err != nil {
+			t.Fatalf("failed to list payments: %v", err)
+		}
+		if len(payments) != 1 {
+			t.Fatalf("expected one payment, got %d", len(payments))
+		}
+		p := payments[0]
+		if p.Status != db.PaymentStatusPartiallyRefunded || p.RefundedUnits != 3250 || p.FeeUnits != fee {
+			t.Errorf("expected the refund and fee kept, got %+v", p)
+		}
+		if p.Email != "jane@example.com" || p.RaceName != "10K" || p.EventName != "Harbour Run" {
+			t.Errorf("unexpected entrant or race: %+v", p)
+		}
+	})
+
+	t.Run("lists only the organisation's settled payments within the range", func(t *testing.T) {
+		queries, org, reg := setup(t)
+		repo := NewPaymentRepository(queries)
+		if _, err := repo.RecordProviderPayment(ctx, charge(reg.ID, db.PaymentStatusSucceeded, 0, fee)); err != nil {
+			t.Fatalf("failed to record charge: %v", err)
+		}
+		failed := charge(reg.ID, db.PaymentStatusFailed, 0, pgtype.Int4{})
+		failed.ProviderReference.String = "ch_2"
+		if _, err := repo.RecordProviderPayment(ctx, failed); err != nil {
+			t.Fatalf("failed to record charge: %v", err)
+		}
+		other, err := queries.CreateOrganisation(ctx, "Valley Striders")
+		if err != nil {
+			t.Fatalf("failed to create organisation: %v", err)
+		}
+
+		if got, _ := repo.ListForOrganisation(ctx, org.ID, taken, taken.Add(time.Second)); len(got) != 1 || got[0].ProviderReference.String != "ch_1" {
+			t.Errorf("expected only the settled payment, got %+v", got)
+		}
+		if got, _ := repo.ListForOrganisation(ctx, org.ID, taken.Add(time.Second), taken.AddDate(0, 0, 1)); len(got) != 0 {
+			t.Errorf("expected nothing after the payment, got %+v", got)
+		}
+		if got, _ := repo.ListForOrganisation(ctx, other.ID, taken, taken.AddDate(0, 0, 1)); len(got) != 0 {
+			t.Errorf("expected nothing for another organisation, got %+v", got)
+		}
+	})
+
+	t.Run("returns ErrNotFound for an unknown registration", func(t *testing.T) {
+		queries, _, reg := setup(t)
+
+		_, err := NewPaymentRepository(queries).RecordProviderPayment(ctx, charge(reg.ID+1, db.PaymentStatusSucceeded, 0, fee))
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/photo.go -pkg repositorymocks . PhotoRepository
+
+// PhotoRepository defines the interface for event photo data access. The
+// image files themselves live in the blob store; see internal/storage.
+type PhotoRepository interface {
+	// Create records a photo after the event's others. It returns
+	// ErrConflict if another photo took the same position at once.
+	Create(ctx context.Context, params db.CreateEventPhotoParams) (db.EventPhoto, error)
+	// Get returns the photo. It returns ErrNotFound if there is none.
+	Get(ctx context.Context, photoID int64) (db.EventPhoto, error)
+	// ListByEvent returns the event's photos in position order.
+	ListByEvent(ctx context.Context, eventID int64) ([]db.EventPhoto, error)
+	// Delete removes the photo's record.
+	Delete(ctx context.Context, photoID int64) error
+	// SetOrder numbers the event's photos from one in the order of
+	// photoIDs, which must be all of them.
+	SetOrder(ctx context.Context, eventID int64, photoIDs []int64) error
+}
+
+type photoRepository struct {
+	queries *db.Queries
+	pool    TxBeginner
+}
+
+// NewPhotoRepository creates a new PhotoRepository backed by the given
+// queries, beginning transactions on pool.
+func NewPhotoRepository(queries *db.Queries, pool TxBeginner) PhotoRepository {
+	return &photoRepository{queries: queries, pool: pool}
+}
+
+func (r *photoRepository) Create(ctx context.Context, params db.CreateEventPhotoParams) (db.EventPhoto, error) {
+	photo, err := r.queries.CreateEventPhoto(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.EventPhoto{}, ErrConflict
+		}
+		return db.EventPhoto{}, err
+	}
+	return photo, nil
+}
+
+func (r *photoRepository) Get(ctx context.Context, photoID int64) (db.EventPhoto, error) {
+	photo, err := r.queries.GetEventPhoto(ctx, photoID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.EventPhoto{}, ErrNotFound
+		}
+		return db.EventPhoto{}, err
+	}
+	return photo, nil
+}
+
+func (r *photoRepository) ListByEvent(ctx context.Context, eventID int64) ([]db.EventPhoto, error) {
+	return r.queries.ListEventPhotos(ctx, eventID)
+}
+
+func (r *photoRepository) Delete(ctx context.Context, photoID int64) error {
+	return r.queries.DeleteEventPhoto(ctx, photoID)
+}
+
+func (r *photoRepository) SetOrder(ctx context.Context, eventID int64, photoIDs []int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	// Positions are checked for repeats at commit, so photos can swap
+	// places here
+	qtx := r.queries.WithTx(tx)
+	for i, id := range photoIDs {
+		if err := qtx.SetEventPhotoPosition(ctx, db.SetEventPhotoPositionParams{
+			Position: int32(i + 1),
+			ID:       id,
+			EventID:  eventID,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	return nil
+}
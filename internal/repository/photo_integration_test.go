@@ -0,0 +1,71 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"firecrest/db"
+)
+
+func TestPhotoRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("adds photos at the end and reorders them", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		event, err := queries.CreateEvent(ctx, db.CreateEventParams{OrganisationID: org.ID, Name: "Lincoln 10k", Slug: "lincoln-10k", Year: 2026})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		repo := NewPhotoRepository(queries, testPool)
+
+		var ids []int64
+		for i := range 3 {
+			photo, err := repo.Create(ctx, db.CreateEventPhotoParams{
+				EventID:     event.ID,
+				OriginalKey: fmt.Sprintf("events/%d/photos/%d/original.jpg", event.ID, i),
+				WebKey:      fmt.Sprintf("events/%d/photos/%d/web.jpg", event.ID, i),
+				Width:       1600,
+				Height:      1200,
+			})
+			if err != nil {
+				t.Fatalf("failed to create photo: %v", err)
+			}
+			if photo.Position != int32(i+1) {
+				t.Errorf("expected position %d, got %d", i+1, photo.Position)
+			}
+			ids = append(ids, photo.ID)
+		}
+
+		// The first and last swap places, which only works if positions
+		// are checked at commit
+		if err := repo.SetOrder(ctx, event.ID, []int64{ids[2], ids[1], ids[0]}); err != nil {
+			t.Fatalf("failed to reorder photos: %v", err)
+		}
+		photos, err := repo.ListByEvent(ctx, event.ID)
+		if err != nil {
+			t.Fatalf("failed to list photos: %v", err)
+		}
+		if len(photos) != 3 || photos[0].ID != ids[2] || photos[2].ID != ids[0] {
+			t.Errorf("expected the photos reversed, got %+v", photos)
+		}
+
+		if err := repo.Delete(ctx, ids[1]); err != nil {
+			t.Fatalf("failed to delete photo: %v", err)
+		}
+		if _, err := repo.Get(ctx, ids[1]); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		photo, err := repo.Create(ctx, db.CreateEventPhotoParams{EventID: event.ID, OriginalKey: "o", WebKey: "w", Width: 1, Height: 1})
+		if err != nil {
+			t.Fatalf("failed to create photo: %v", err)
+		}
+		if photo.Position != 4 {
+			t.Errorf("expected the new photo after the last, got position %d", photo.Position)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"firecrest/db"
+)
+
+func (r *raceRepository) ListPriceTiers(ctx context.Context, raceIDs []int64) ([]db.ListRacePriceTiersRow, error) {
+	return r.queries.ListRacePriceTiers(ctx, raceIDs)
+}
+
+func (r *raceRepository) CreatePriceTier(ctx context.Context, params db.CreateRacePriceTierParams) (db.RacePriceTier, error) {
+	return r.queries.CreateRacePriceTier(ctx, params)
+}
+
+func (r *raceRepository) DeletePriceTier(ctx context.Context, raceID, tierID int64) error {
+	n, err := r.queries.DeleteRacePriceTier(ctx, db.DeleteRacePriceTierParams{ID: tierID, RaceID: raceID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// checkPriceTier returns ErrPriceTierFull unless the race still has the
+// tier and, for a tier limited to its first entries, a place left at it.
+// Callers should hold the race lock.
+func checkPriceTier(ctx context.Context, qtx *db.Queries, raceID, tierID int64) error {
+	places, err := qtx.GetRacePriceTierPlaces(ctx, db.GetRacePriceTierPlacesParams{ID: tierID, RaceID: raceID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPriceTierFull
+		}
+		return err
+	}
+	if places.Capacity.Valid && places.Taken >= int64(places.Capacity.Int32) {
+		return ErrPriceTierFull
+	}
+	return nil
+}
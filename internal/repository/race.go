@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/race.go -pkg repositorymocks . RaceRepository
+
+// RaceRepository defines the interface for race data access.
+type RaceRepository interface {
+	ListByEvent(ctx context.Context, eventID int64) ([]db.Race, error)
+	ListByEvents(ctx context.Context, eventIDs []int64) ([]db.Race, error)
+	GetBySlug(ctx context.Context, eventID int64, slug string) (db.Race, error)
+	GetByID(ctx context.Context, id int64) (db.Race, error)
+	Create(ctx context.Context, params db.CreateRaceParams) (db.Race, error)
+	// UpdateCapacity sets how many places the race has on behalf of
+	// userID, recording the change in the audit log. It returns
+	// ErrCapacityExceeded, with Taken set, if fewer places would remain
+	// than entries already hold, and changes nothing if the capacity is
+	// the same.
+	UpdateCapacity(ctx context.Context, raceID int64, capacity int32, userID int64) (CapacityChange, error)
+	// SaveRoute stores a race's route, replacing any it already has.
+	SaveRoute(ctx context.Context, params db.UpsertRaceRouteParams) error
+	// GetRoute returns the figures of a race's route without its file. It
+	// returns ErrNotFound if the race has no route.
+	GetRoute(ctx context.Context, raceID int64) (db.GetRaceRouteRow, error)
+	// GetRouteGPX returns a race's route file, gzip-compressed. It returns
+	// ErrNotFound if the race has no route.
+	GetRouteGPX(ctx context.Context, raceID int64) ([]byte, error)
+	ListRoutesByEvent(ctx context.Context, eventID int64) ([]db.ListRaceRoutesByEventRow, error)
+	// ListRequiredQuestions returns the questionnaire questions the race
+	// requires entrants to answer, in name order.
+	ListRequiredQuestions(ctx context.Context, raceID int64) ([]string, error)
+	// SetRequiredQuestions replaces the questions the race requires
+	// entrants to answer.
+	SetRequiredQuestions(ctx context.Context, raceID int64, questions []string) error
+	// ListLockedQuestions returns the questionnaire questions entrants may
+	// not change themselves once they have paid, in name order.
+	ListLockedQuestions(ctx context.Context, raceID int64) ([]string, error)
+	// SetLockedQuestions replaces the questions locked once entrants have
+	// paid.
+	SetLockedQuestions(ctx context.Context, raceID int64, questions []string) error
+	// SetMinAge sets the youngest entrants may be on race day; an invalid
+	// minAge lets any age enter. It returns ErrNotFound if the race does
+	// not exist or has been deleted.
+	SetMinAge(ctx context.Context, raceID int64, minAge pgtype.Int4) (db.Race, error)
+	// SetDistance sets the race's distance and climb in metres; invalid
+	// values leave them unknown. It returns ErrNotFound if the race does
+	// not exist or has been deleted.
+	SetDistance(ctx context.Context, params db.SetRaceDistanceParams) (db.Race, error)
+	// ListWaves returns the race's start waves, earliest first, with how
+	// many active registrations hold a place in each.
+	ListWaves(ctx context.Context, raceID int64) ([]db.ListRaceWavesRow, error)
+	// ListWavesByEvent returns the start waves of every race in the event,
+	// by race and then earliest first.
+	ListWavesByEvent(ctx context.Context, eventID int64) ([]db.RaceWave, error)
+	// GetWave returns one of the race's start waves, or ErrNotFound if it
+	// has no such wave.
+	GetWave(ctx context.Context, raceID, waveID int64) (db.RaceWave, error)
+	// CreateWave adds a start wave to a race. It returns ErrConflict if the
+	// race already has a wave with the name.
+	CreateWave(ctx context.Context, params db.CreateRaceWaveParams) (db.RaceWave, error)
+	// UpdateWave changes a start wave's name, start and capacity. It returns
+	// ErrNotFound if the race has no such wave, ErrConflict if another of
+	// its waves has the name and ErrCapacityExceeded, with Taken set, if
+	// fewer places would remain than entrants already hold.
+	UpdateWave(ctx context.Context, params db.UpdateRaceWaveParams) (WaveChange, error)
+	// DeleteWave removes a start wave from a race. It returns ErrNotFound if
+	// the race has no such wave and ErrInUse if entrants hold places in it.
+	DeleteWave(ctx context.Context, raceID, waveID int64) error
+	// ListPriceTiers returns the price tiers of the races, by race and then
+	// earliest first, with how many active registrations were made at each.
+	ListPriceTiers(ctx context.Context, raceIDs []int64) ([]db.ListRacePriceTiersRow, error)
+	CreatePriceTier(ctx context.Context, params db.CreateRacePriceTierParams) (db.RacePriceTier, error)
+	// DeletePriceTier removes a price tier from a race. Entries made at it
+	// keep the price they were charged. It returns ErrNotFound if the race
+	// has no such tier.
+	DeletePriceTier(ctx context.Context, raceID, tierID int64) error
+	// ListCustomFields returns the current definitions of the race's
+	// custom fields, in the order its entry form asks them. Removed fields
+	// are left out.
+	ListCustomFields(ctx context.Context, raceID int64) ([]db.RaceCustomFieldVersion, error)
+	// ListCustomFieldVersions returns every definition the race's custom
+	// fields have had, removed fields' included, by field and then version.
+	ListCustomFieldVersions(ctx context.Context, raceID int64) ([]db.RaceCustomFieldVersion, error)
+	// CreateCustomField adds a field to the end of the race's entry form,
+	// defined by def at version one; def's FieldID and Version are set
+	// here. It returns ErrNotFound if the race does not exist and
+	// ErrCustomFieldLimit if it already has limit fields.
+	CreateCustomField(ctx context.Context, raceID int64, def db.CreateRaceCustomFieldVersionParams, limit int) (db.RaceCustomFieldVersion, error)
+	// UpdateCustomField gives the field def.FieldID a new version defined
+	// by def, leaving earlier versions as they were; def's Version is set
+	// here. It returns ErrNotFound if the race has no such field.
+	UpdateCustomField(ctx context.Context, raceID int64, def db.CreateRaceCustomFieldVersionParams) (db.RaceCustomFieldVersion, error)
+	// DeleteCustomField removes a field from the race's entry form. Its
+	// versions are kept for the answers already given. It returns
+	// ErrNotFound if the race has no such field.
+	DeleteCustomField(ctx context.Context, raceID, fieldID int64) error
+	// SetCustomFieldOrder numbers the race's custom fields from one in the
+	// order of fieldIDs, which must be all of them.
+	SetCustomFieldOrder(ctx context.Context, raceID int64, fieldIDs []int64) error
+}
+
+// CapacityChange is the outcome of changing a race's capacity.
+type CapacityChange struct {
+	Race        db.Race
+	OldCapacity int32
+	// Taken is how many places entries and unfilled team places held when
+	// the race was locked
+	Taken int64
+}
+
+// WaveChange is the outcome of changing a start wave.
+type WaveChange struct {
+	Wave db.RaceWave
+	Old  db.RaceWave
+	// Taken is how many places in the wave active registrations held when
+	// the race was locked
+	Taken int64
+}
+
+type raceRepository struct {
+	queries *db.Queries
+	pool    TxBeginner
+}
+
+// NewRaceRepository creates a new RaceRepository backed by the given
+// queries, with pool used for changes made in a transaction.
+func NewRaceRepository(queries *db.Queries, pool TxBeginner) RaceRepository {
+	return &raceRepository{queries: queries, pool: pool}
+}
+
+func (r *raceRepository) ListByEvent(ctx context.Context, eventID int64) ([]db.Race, error) {
+	return r.queries.ListRacesByEvent(ctx, eventID)
+}
+
+func (r *raceRepository) ListByEvents(ctx context.Context, eventIDs []int64) ([]db.Race, error) {
+	return r.queries.ListRacesByEvents(ctx, eventIDs)
+}
+
+func (r *raceRepository) GetBySlug(ctx context.Context, eventID int64, slug string) (db.Race, error) {
+	race, err := r.queries.GetRaceBySlug(ctx, db.GetRaceBySlugParams{
+		EventID: eventID,
+		Slug:    slug,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Race{}, ErrNotFound
+		}
+		return db.Race{}, err
+	}
+	return race, nil
+}
+
+func (r *raceRepository) GetByID(ctx context.Context, id int64) (db.Race, error) {
+	race, err := r.queries.GetRaceByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Race{}, ErrNotFound
+		}
+		return db.Race{}, err
+	}
+	return race, nil
+}
+
+func (r *raceRepository) Create(ctx context.Context, params db.CreateRaceParams) (db.Race, error) {
+	race, err := r.queries.CreateRace(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.Race{}, ErrConflict
+		}
+		return db.Race{}, err
+	}
+	return race, nil
+}
+
+func (r *raceRepository) UpdateCapacity(ctx context.Context, raceID int64, capacity int32, userID int64) (CapacityChange, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return CapacityChange{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	// Locked like a registration, so no entry can slip in between the
+	// count and the update
+	qtx := r.queries.WithTx(tx)
+	old, err := qtx.LockRace(ctx, raceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CapacityChange{}, ErrNotFound
+		}
+		return CapacityChange{}, err
+	}
+	taken, err := placesTaken(ctx, qtx, raceID)
+	if err != nil {
+		return CapacityChange{}, err
+	}
+	change := CapacityChange{OldCapacity: old, Taken: taken}
+
+	if capacity == old {
+		change.Race, err = qtx.GetRaceByID(ctx, raceID)
+		if err != nil {
+			return CapacityChange{}, err
+		}
+		return change, nil
+	}
+	if int64(capacity) < taken {
+		return change, ErrCapacityExceeded
+	}
+
+	change.Race, err = qtx.UpdateRaceCapacity(ctx, db.UpdateRaceCapacityParams{ID: raceID, MaxCapacity: capacity})
+	if err != nil {
+		return CapacityChange{}, err
+	}
+	changed, err := json.Marshal(map[string]auditChange{
+		"max_capacity": {Old: old, New: capacity},
+	})
+	if err != nil {
+		return CapacityChange{}, err
+	}
+	if err := qtx.CreateAuditLogEntry(ctx, db.CreateAuditLogEntryParams{
+		TableName:     "races",
+		RecordID:      raceID,
+		Action:        db.AuditActionUpdated,
+		UserID:        pgtype.Int8{Int64: userID, Valid: true},
+		ChangedFields: changed,
+	}); err != nil {
+		return CapacityChange{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return CapacityChange{}, err
+	}
+	return change, nil
+}
+
+func (r *raceRepository) SaveRoute(ctx context.Context, params db.UpsertRaceRouteParams) error {
+	return r.queries.UpsertRaceRoute(ctx, params)
+}
+
+func (r *raceRepository) GetRoute(ctx context.Context, raceID int64) (db.GetRaceRouteRow, error) {
+	route, err := r.queries.GetRaceRoute(ctx, raceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetRaceRouteRow{}, ErrNotFound
+		}
+		return db.GetRaceRouteRow{}, err
+	}
+	return route, nil
+}
+
+func (r *raceRepository) GetRouteGPX(ctx context.Context, raceID int64) ([]byte, error) {
+	gpx, err := r.queries.GetRaceRouteGPX(ctx, raceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return gpx, nil
+}
+
+func (r *raceRepository) ListRoutesByEvent(ctx context.Context, eventID int64) ([]db.ListRaceRoutesByEventRow, error) {
+	return r.queries.ListRaceRoutesByEvent(ctx, eventID)
+}
+
+func (r *raceRepository) ListRequiredQuestions(ctx context.Context, raceID int64) ([]string, error) {
+	return r.queries.ListRaceRequiredQuestions(ctx, raceID)
+}
+
+func (r *raceRepository) SetRequiredQuestions(ctx context.Context, raceID int64, questions []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	if err := qtx.DeleteRaceRequiredQuestions(ctx, raceID); err != nil {
+		return err
+	}
+	if len(questions) > 0 {
+		if err := qtx.AddRaceRequiredQuestions(ctx, db.AddRaceRequiredQuestionsParams{
+			RaceID:    raceID,
+			Questions: questions,
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *raceRepository) ListLockedQuestions(ctx context.Context, raceID int64) ([]string, error) {
+	return r.queries.ListRaceLockedQuestions(ctx, raceID)
+}
+
+func (r *raceRepository) SetLockedQuestions(ctx context.Context, raceID int64, questions []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	if err := qtx.DeleteRaceLockedQuestions(ctx, raceID); err != nil {
+		return err
+	}
+	if len(questions) > 0 {
+		if err := qtx.AddRaceLockedQuestions(ctx, db.AddRaceLockedQuestionsParams{
+			RaceID:    raceID,
+			Questions: questions,
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *raceRepository) SetMinAge(ctx context.Context, raceID int64, minAge pgtype.Int4) (db.Race, error) {
+	race, err := r.queries.SetRaceMinAge(ctx, db.SetRaceMinAgeParams{ID: raceID, MinAge: minAge})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Race{}, ErrNotFound
+		}
+		return db.Race{}, err
+	}
+	return race, nil
+}
+
+func (r *raceRepository) SetDistance(ctx context.Context, params db.SetRaceDistanceParams) (db.Race, error) {
+	race, err := r.queries.SetRaceDistance(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Race{}, ErrNotFound
+		}
+		return db.Race{}, err
+	}
+	return race, nil
+}
@@ -0,0 +1,580 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestRaceRepository_UpdateCapacity(t *testing.T) {
+	ctx := context.Background()
+
+	// setup creates a race with three places, two of them taken, and the
+	// organiser changing it.
+	setup := func(t *testing.T) (*db.Queries, db.Race, db.User) {
+		t.Helper()
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+			OrganisationID: org.ID,
+			Name:           "Peak District Ultra",
+			Slug:           "peak-district-ultra",
+			Year:           2026,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+			EventID:     event.ID,
+			Name:        "Ultra 50K",
+			Slug:        "ultra-50k",
+			MaxCapacity: 3,
+		})
+		if err != nil {
+			t.Fatalf("failed to create race: %v", err)
+		}
+		for _, email := range []string{"jane@example.com", "sam@example.com"} {
+			entrant := createTestUser(t, queries, email)
+			if _, err := queries.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+				UserID: entrant.ID,
+				RaceID: race.ID,
+			}); err != nil {
+				t.Fatalf("failed to create registration: %v", err)
+			}
+		}
+		return queries, race, createTestUser(t, queries, "organiser@example.com")
+	}
+
+	auditEntries := func(t *testing.T, queries *db.Queries, raceID int64) []db.AuditLog {
+		t.Helper()
+		entries, err := queries.ListAuditLogForRecord(ctx, db.ListAuditLogForRecordParams{
+			TableName: "races",
+			RecordID:  raceID,
+		})
+		if err != nil {
+			t.Fatalf("failed to list audit log: %v", err)
+		}
+		return entries
+	}
+
+	t.Run("raises capacity and records the change", func(t *testing.T) {
+		queries, race, organiser := setup(t)
+		repo := NewRaceRepository(queries, testPool)
+
+		change, err := repo.UpdateCapacity(ctx, race.ID, 10, organiser.ID)
+		if err != nil {
+			t.Fatalf("failed to update capacity: %v", err)
+		}
+		if change.Race.MaxCapacity != 10 || change.OldCapacity != 3 || change.Taken != 2 {
+			t.Errorf("unexpected change %+v", change)
+		}
+
+		entries := auditEntries(t, queries, race.ID)
+		if len(entries) != 1 {
+			t.Fatalf("expected one audit entry, got %d", len(entries))
+		}
+		if entries[0].Action != db.AuditActionUpdated || entries[0].UserID.Int64 != organiser.ID {
+			t.Errorf("expected an update by the organiser, got %+v", entries[0])
+		}
+		var changed map[string]auditChange
+		if err := json.Unmarshal(entries[0].ChangedFields, &changed); err != nil {
+			t.Fatalf("failed to decode changed fields: %v", err)
+		}
+		if got := changed["max_capacity"]; got.Old != float64(3) || got.New != float64(10) {
+			t.Errorf("expected max_capacity 3 -> 10, got %+v", got)
+		}
+	})
+
+	t.Run("refuses to go below the places taken", func(t *testing.T) {
+		queries, race, organiser := setup(t)
+		repo := NewRaceRepository(queries, testPool)
+
+		change, err := repo.UpdateCapacity(ctx, race.ID, 1, organiser.ID)
+		if !errors.Is(err, ErrCapacityExceeded) {
+			t.Fatalf("expected ErrCapacityExceeded, got %v", err)
+		}
+		if change.Taken != 2 {
+			t.Errorf("expected 2 places taken, got %d", change.Taken)
+		}
+		if got, err := queries.GetRaceByID(ctx, race.ID); err != nil || got.MaxCapacity != 3 {
+			t.Errorf("expected capacity to stay 3, got %d (err %v)", got.MaxCapacity, err)
+		}
+		if entries := auditEntries(t, queries, race.ID); len(entries) != 0 {
+			t.Errorf("expected no audit entry, got %d", len(entries))
+		}
+	})
+
+	t.Run("changes nothing for the same capacity", func(t *testing.T) {
+		queries, race, organiser := setup(t)
+		repo := NewRaceRepository(queries, testPool)
+
+		change, err := repo.UpdateCapacity(ctx, race.ID, 3, organiser.ID)
+		if err != nil {
+			t.Fatalf("failed to update capacity: %v", err)
+		}
+		if change.Race.ID != race.ID || change.Race.UpdatedAt != race.UpdatedAt {
+			t.Errorf("expected the race untouched, got %+v", change.Race)
+		}
+		if entries := auditEntries(t, queries, race.ID); len(entries) != 0 {
+			t.Errorf("expected no audit entry, got %d", len(entries))
+		}
+	})
+
+	t.Run("reports missing races", func(t *testing.T) {
+		queries, _, organiser := setup(t)
+		repo := NewRaceRepository(queries, testPool)
+
+		if _, err := repo.UpdateCapacity(ctx, 999999, 10, organiser.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestRaceRepository_Routes(t *testing.T) {
+	ctx := context.Background()
+	queries := resetDB(t)
+	org := createTestOrganisation(t, queries)
+	event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+		OrganisationID: org.ID,
+		Name:           "Peak District Ultra",
+		Slug:           "peak-district-ultra",
+		Year:           2026,
+	})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+		EventID:     event.ID,
+		Name:        "Ultra 50K",
+		Slug:        "ultra-50k",
+		MaxCapacity: 300,
+	})
+	if err != nil {
+		t.Fatalf("failed to create race: %v", err)
+	}
+	repo := NewRaceRepository(queries, testPool)
+
+	if _, err := repo.GetRoute(ctx, race.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound before an upload, got %v", err)
+	}
+	if _, err := repo.GetRouteGPX(ctx, race.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound before an upload, got %v", err)
+	}
+
+	for _, params := range []db.UpsertRaceRouteParams{
+		{RaceID: race.ID, GpxGzip: []byte("first"), PointCount: 10, DistanceMetres: 1000, ElevationGainMetres: 10},
+		{RaceID: race.ID, GpxGzip: []byte("second"), PointCount: 900, DistanceMetres: 50120, ElevationGainMetres: 1840},
+	} {
+		if err := repo.SaveRoute(ctx, params); err != nil {
+			t.Fatalf("failed to save route: %v", err)
+		}
+	}
+
+	route, err := repo.GetRoute(ctx, race.ID)
+	if err != nil {
+		t.Fatalf("failed to get route: %v", err)
+	}
+	if route.PointCount != 900 || route.DistanceMetres != 50120 || route.ElevationGainMetres != 1840 {
+		t.Errorf("expected the second upload to replace the first, got %+v", route)
+	}
+	if gpx, err := repo.GetRouteGPX(ctx, race.ID); err != nil || string(gpx) != "second" {
+		t.Errorf("expected the second file, got %q (err %v)", gpx, err)
+	}
+
+	routes, err := repo.ListRoutesByEvent(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("failed to list routes: %v", err)
+	}
+	if len(routes) != 1 || routes[0].RaceID != race.ID || routes[0].DistanceMetres != 50120 {
+		t.Errorf("unexpected routes %+v", routes)
+	}
+}
+
+func TestRaceRepository_SetDistance(t *testing.T) {
+	ctx := context.Background()
+	queries := resetDB(t)
+	org := createTestOrganisation(t, queries)
+	event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+		OrganisationID: org.ID,
+		Name:           "Peak District Ultra",
+		Slug:           "peak-district-ultra",
+		Year:           2026,
+	})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+		EventID:        event.ID,
+		Name:           "Ultra 50K",
+		Slug:           "ultra-50k",
+		MaxCapacity:    300,
+		DistanceMetres: pgtype.Int4{Int32: 50000, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create race: %v", err)
+	}
+	if race.DistanceMetres.Int32 != 50000 || race.ElevationGainMetres.Valid {
+		t.Errorf("expected a 50 km race of unknown climb, got %+v", race)
+	}
+	repo := NewRaceRepository(queries, testPool)
+
+	updated, err := repo.SetDistance(ctx, db.SetRaceDistanceParams{
+		ID:                  race.ID,
+		DistanceMetres:      pgtype.Int4{Int32: 80467, Valid: true},
+		ElevationGainMetres: pgtype.Int4{Int32: 2400, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to set distance: %v", err)
+	}
+	if updated.DistanceMetres.Int32 != 80467 || updated.ElevationGainMetres.Int32 != 2400 {
+		t.Errorf("unexpected race %+v", updated)
+	}
+
+	if _, err := repo.SetDistance(ctx, db.SetRaceDistanceParams{
+		ID:             race.ID,
+		DistanceMetres: pgtype.Int4{Int32: 5_000_000, Valid: true},
+	}); err == nil {
+		t.Error("expected a distance of 5,000 km to be refused")
+	}
+	if _, err := repo.SetDistance(ctx, db.SetRaceDistanceParams{ID: 999}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRaceRepository_Waves(t *testing.T) {
+	ctx := context.Background()
+
+	// setup creates a race in a published event with two waves of two
+	// places, ten minutes apart.
+	setup := func(t *testing.T) (*db.Queries, db.Race, []db.RaceWave) {
+		t.Helper()
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+			OrganisationID: org.ID,
+			Name:           "Peak District Ultra",
+			Slug:           "peak-district-ultra",
+			Year:           2026,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if _, err := queries.SetEventStatus(ctx, db.SetEventStatusParams{ID: event.ID, Status: db.EventStatusPublished}); err != nil {
+			t.Fatalf("failed to publish event: %v", err)
+		}
+		race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+			EventID:     event.ID,
+			Name:        "Ultra 50K",
+			Slug:        "ultra-50k",
+			MaxCapacity: 300,
+		})
+		if err != nil {
+			t.Fatalf("failed to create race: %v", err)
+		}
+		repo := NewRaceRepository(queries, testPool)
+		start := time.Date(2026, 6, 6, 9, 0, 0, 0, time.UTC)
+		var waves []db.RaceWave
+		for i, name := range []string{"Wave A", "Wave B"} {
+			wave, err := repo.CreateWave(ctx, db.CreateRaceWaveParams{
+				RaceID:   race.ID,
+				Name:     name,
+				StartsAt: pgtype.Timestamptz{Time: start.Add(time.Duration(i) * 10 * time.Minute), Valid: true},
+				Capacity: 2,
+			})
+			if err != nil {
+				t.Fatalf("failed to create wave: %v", err)
+			}
+			waves = append(waves, wave)
+		}
+		return queries, race, waves
+	}
+
+	// enter registers a new entrant online, asking for the wave if given.
+	enter := func(t *testing.T, queries *db.Queries, raceID int64, email string, wave pgtype.Int8) (db.Registration, error) {
+		t.Helper()
+		entrant := createTestUser(t, queries, email)
+		return NewRegistrationRepository(queries, testPool).Create(ctx, db.CreateRegistrationParams{
+			UserID: entrant.ID,
+			RaceID: raceID,
+			WaveID: wave,
+		}, nil, nil)
+	}
+
+	t.Run("falls back to the next wave once the chosen one is full", func(t *testing.T) {
+		queries, race, waves := setup(t)
+		first := pgtype.Int8{Int64: waves[0].ID, Valid: true}
+
+		var got []int64
+		for i := range 4 {
+			reg, err := enter(t, queries, race.ID, fmt.Sprintf("runner%d@example.com", i), first)
+			if err != nil {
+				t.Fatalf("failed to register entrant %d: %v", i, err)
+			}
+			got = append(got, reg.WaveID.Int64)
+		}
+		if want := []int64{waves[0].ID, waves[0].ID, waves[1].ID, waves[1].ID}; !slices.Equal(got, want) {
+			t.Errorf("expected waves %v, got %v", want, got)
+		}
+		if _, err := enter(t, queries, race.ID, "late@example.com", pgtype.Int8{}); !errors.Is(err, ErrCapacityExceeded) {
+			t.Errorf("expected ErrCapacityExceeded once every wave is full, got %v", err)
+		}
+	})
+
+	t.Run("spreads automatic entries across waves", func(t *testing.T) {
+		queries, race, waves := setup(t)
+
+		for i := range 4 {
+			if _, err := enter(t, queries, race.ID, fmt.Sprintf("runner%d@example.com", i), pgtype.Int8{}); err != nil {
+				t.Fatalf("failed to register entrant %d: %v", i, err)
+			}
+		}
+		rows, err := NewRaceRepository(queries, testPool).ListWaves(ctx, race.ID)
+		if err != nil {
+			t.Fatalf("failed to list waves: %v", err)
+		}
+		if len(rows) != 2 || rows[0].RaceWave.ID != waves[0].ID || rows[0].Taken != 2 || rows[1].Taken != 2 {
+			t.Errorf("expected two entrants in each wave, got %+v", rows)
+		}
+	})
+
+	t.Run("refuses a wave from another race", func(t *testing.T) {
+		queries, race, _ := setup(t)
+
+		if _, err := enter(t, queries, race.ID, "sam@example.com", pgtype.Int8{Int64: 999999, Valid: true}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("updates waves no smaller than their entrants", func(t *testing.T) {
+		queries, race, waves := setup(t)
+		repo := NewRaceRepository(queries, testPool)
+		if _, err := enter(t, queries, race.ID, "sam@example.com", pgtype.Int8{Int64: waves[1].ID, Valid: true}); err != nil {
+			t.Fatalf("failed to register: %v", err)
+		}
+
+		params := db.UpdateRaceWaveParams{
+			ID:       waves[1].ID,
+			RaceID:   race.ID,
+			Name:     "Wave B",
+			StartsAt: pgtype.Timestamptz{Time: waves[1].StartsAt.Time.Add(5 * time.Minute), Valid: true},
+			Capacity: 0,
+		}
+		change, err := repo.UpdateWave(ctx, params)
+		if !errors.Is(err, ErrCapacityExceeded) || change.Taken != 1 {
+			t.Fatalf("expected ErrCapacityExceeded with 1 taken, got %+v (err %v)", change, err)
+		}
+
+		params.Capacity = 1
+		change, err = repo.UpdateWave(ctx, params)
+		if err != nil {
+			t.Fatalf("failed to update wave: %v", err)
+		}
+		if !change.Old.StartsAt.Time.Equal(waves[1].StartsAt.Time) || !change.Wave.StartsAt.Time.Equal(params.StartsAt.Time) {
+			t.Errorf("expected the start to move from %v to %v, got %+v", waves[1].StartsAt.Time, params.StartsAt.Time, change)
+		}
+
+		params.Name = "Wave A"
+		if _, err := repo.UpdateWave(ctx, params); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict for a name already used, got %v", err)
+		}
+	})
+
+	t.Run("deletes only waves nobody holds a place in", func(t *testing.T) {
+		queries, race, waves := setup(t)
+		repo := NewRaceRepository(queries, testPool)
+		reg, err := enter(t, queries, race.ID, "sam@example.com", pgtype.Int8{Int64: waves[0].ID, Valid: true})
+		if err != nil {
+			t.Fatalf("failed to register: %v", err)
+		}
+
+		if err := repo.DeleteWave(ctx, race.ID, waves[0].ID); !errors.Is(err, ErrInUse) {
+			t.Fatalf("expected ErrInUse, got %v", err)
+		}
+		entrants, err := NewRegistrationRepository(queries, testPool).ListByWave(ctx, waves[0].ID)
+		if err != nil || len(entrants) != 1 || entrants[0].Email != "sam@example.com" {
+			t.Fatalf("expected sam in the wave, got %+v (err %v)", entrants, err)
+		}
+
+		if _, err := queries.CancelRegistration(ctx, reg.ID); err != nil {
+			t.Fatalf("failed to cancel: %v", err)
+		}
+		if err := repo.DeleteWave(ctx, race.ID, waves[0].ID); err != nil {
+			t.Fatalf("failed to delete wave: %v", err)
+		}
+		if err := repo.DeleteWave(ctx, race.ID, waves[0].ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a deleted wave, got %v", err)
+		}
+	})
+}
+
+func TestRaceRepository_PriceTiers(t *testing.T) {
+	ctx := context.Background()
+	queries := resetDB(t)
+	org := createTestOrganisation(t, queries)
+	event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+		OrganisationID: org.ID,
+		Name:           "Peak District Ultra",
+		Slug:           "peak-district-ultra",
+		Year:           2026,
+	})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	if _, err := queries.SetEventStatus(ctx, db.SetEventStatusParams{ID: event.ID, Status: db.EventStatusPublished}); err != nil {
+		t.Fatalf("failed to publish event: %v", err)
+	}
+	race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+		EventID:     event.ID,
+		Name:        "Ultra 50K",
+		Slug:        "ultra-50k",
+		MaxCapacity: 300,
+	})
+	if err != nil {
+		t.Fatalf("failed to create race: %v", err)
+	}
+	repo := NewRaceRepository(queries, testPool)
+	tier, err := repo.CreatePriceTier(ctx, db.CreateRacePriceTierParams{
+		RaceID:     race.ID,
+		Name:       "First entry",
+		PriceUnits: 5500,
+		Capacity:   pgtype.Int4{Int32: 1, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create price tier: %v", err)
+	}
+
+	enter := func(email string) (db.Registration, error) {
+		entrant := createTestUser(t, queries, email)
+		return NewRegistrationRepository(queries, testPool).Create(ctx, db.CreateRegistrationParams{
+			UserID:      entrant.ID,
+			RaceID:      race.ID,
+			PriceUnits:  pgtype.Int4{Int32: tier.PriceUnits, Valid: true},
+			PriceTierID: pgtype.Int8{Int64: tier.ID, Valid: true},
+		}, nil, nil)
+	}
+
+	if _, err := enter("first@example.com"); err != nil {
+		t.Fatalf("failed to register at the tier: %v", err)
+	}
+	if _, err := enter("second@example.com"); !errors.Is(err, ErrPriceTierFull) {
+		t.Errorf("expected ErrPriceTierFull once the tier is taken, got %v", err)
+	}
+	rows, err := repo.ListPriceTiers(ctx, []int64{race.ID})
+	if err != nil {
+		t.Fatalf("failed to list price tiers: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Taken != 1 {
+		t.Errorf("expected one entry at the tier, got %+v", rows)
+	}
+
+	if err := repo.DeletePriceTier(ctx, race.ID+1, tier.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting from another race, got %v", err)
+	}
+	if err := repo.DeletePriceTier(ctx, race.ID, tier.ID); err != nil {
+		t.Fatalf("failed to delete price tier: %v", err)
+	}
+}
+
+func TestRaceRepository_CustomFields(t *testing.T) {
+	ctx := context.Background()
+	queries := resetDB(t)
+	org := createTestOrganisation(t, queries)
+	event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+		OrganisationID: org.ID,
+		Name:           "Peak District Ultra",
+		Slug:           "peak-district-ultra",
+		Year:           2026,
+	})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+		EventID:     event.ID,
+		Name:        "Ultra 50K",
+		Slug:        "ultra-50k",
+		MaxCapacity: 300,
+	})
+	if err != nil {
+		t.Fatalf("failed to create race: %v", err)
+	}
+	repo := NewRaceRepository(queries, testPool)
+
+	ferry, err := repo.CreateCustomField(ctx, race.ID, db.CreateRaceCustomFieldVersionParams{
+		Label:   "Ferry crossing",
+		Kind:    db.CustomFieldKindCheckbox,
+		Options: []string{},
+	}, 2)
+	if err != nil {
+		t.Fatalf("failed to create custom field: %v", err)
+	}
+	size, err := repo.CreateCustomField(ctx, race.ID, db.CreateRaceCustomFieldVersionParams{
+		Label:    "T-shirt size",
+		Kind:     db.CustomFieldKindSelect,
+		Options:  []string{"S", "M", "L"},
+		Required: true,
+	}, 2)
+	if err != nil {
+		t.Fatalf("failed to create custom field: %v", err)
+	}
+	if _, err := repo.CreateCustomField(ctx, race.ID, db.CreateRaceCustomFieldVersionParams{
+		Label:   "Club",
+		Kind:    db.CustomFieldKindText,
+		Options: []string{},
+	}, 2); !errors.Is(err, ErrCustomFieldLimit) {
+		t.Errorf("expected ErrCustomFieldLimit past the limit, got %v", err)
+	}
+
+	edited, err := repo.UpdateCustomField(ctx, race.ID, db.CreateRaceCustomFieldVersionParams{
+		FieldID: ferry.FieldID,
+		Label:   "Ferry",
+		Kind:    db.CustomFieldKindSelect,
+		Options: []string{"Outbound", "Return"},
+	})
+	if err != nil {
+		t.Fatalf("failed to update custom field: %v", err)
+	}
+	if edited.Version != 2 {
+		t.Errorf("expected the edit saved as version 2, got %d", edited.Version)
+	}
+	versions, err := repo.ListCustomFieldVersions(ctx, race.ID)
+	if err != nil {
+		t.Fatalf("failed to list custom field versions: %v", err)
+	}
+	if len(versions) != 3 || versions[0].Label != "Ferry crossing" || versions[1].Label != "Ferry" {
+		t.Errorf("expected both of the ferry field's versions kept, got %+v", versions)
+	}
+
+	if err := repo.SetCustomFieldOrder(ctx, race.ID, []int64{size.FieldID, ferry.FieldID}); err != nil {
+		t.Fatalf("failed to reorder custom fields: %v", err)
+	}
+	fields, err := repo.ListCustomFields(ctx, race.ID)
+	if err != nil {
+		t.Fatalf("failed to list custom fields: %v", err)
+	}
+	if len(fields) != 2 || fields[0].FieldID != size.FieldID || fields[1].Label != "Ferry" {
+		t.Errorf("expected the size field first and the ferry field's latest version, got %+v", fields)
+	}
+
+	if err := repo.DeleteCustomField(ctx, race.ID+1, size.FieldID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting from another race, got %v", err)
+	}
+	if err := repo.DeleteCustomField(ctx, race.ID, size.FieldID); err != nil {
+		t.Fatalf("failed to delete custom field: %v", err)
+	}
+	if fields, _ := repo.ListCustomFields(ctx, race.ID); len(fields) != 1 {
+		t.Errorf("expected the removed field left out, got %+v", fields)
+	}
+	if versions, _ := repo.ListCustomFieldVersions(ctx, race.ID); len(versions) != 3 {
+		t.Errorf("expected the removed field's versions kept, got %+v", versions)
+	}
+}
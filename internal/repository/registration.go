@@ -0,0 +1,950 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/registration.go -pkg repositorymocks . RegistrationRepository
+
+// RegistrationRepository defines the interface for registration data access.
+type RegistrationRepository interface {
+	// CountByRaceForEvent returns the number of active registrations for
+	// each race in the event, keyed by race ID. Races with no registrations
+	// are absent from the map.
+	CountByRaceForEvent(ctx context.Context, eventID int64) (map[int64]int, error)
+	// CountRegistrationsByEvent returns the number of active registrations
+	// for each of the given events, keyed by event ID, using a single query.
+	// Events with no registrations are absent from the map.
+	CountRegistrationsByEvent(ctx context.Context, eventIDs []int64) (map[int64]int, error)
+	// GetActive returns the user's registration for the race that has not
+	// been cancelled, or ErrNotFound if they have none.
+	GetActive(ctx context.Context, userID, raceID int64) (db.Registration, error)
+	// GetAgeCheck returns the user's date of birth and the race's date in
+	// its event's time zone, either of which may be unset. It returns
+	// ErrNotFound if the user or race does not exist.
+	GetAgeCheck(ctx context.Context, userID, raceID int64) (db.GetEntrantAgeCheckRow, error)
+	// IsEmailVerified reports whether the user has confirmed the email
+	// address they sign in with.
+	IsEmailVerified(ctx context.Context, userID int64) (bool, error)
+	// Create registers the user for the race online, pending payment, and
+	// counts the entry against any discount code it used. Races with start
+	// waves put the entry in the wave params.WaveID asks for, or the next
+	// with room once it is full, and otherwise in the emptiest. It returns
+	// ErrNotFound if the race or requested wave does not exist,
+	// ErrNotPublished if its event is not published, ErrCapacityExceeded if
+	// it or every wave is full,
+	// ErrAlreadyRegistered if the user already holds an active registration
+	// for it, ErrPriceTierFull if the price tier has gone or has no places
+	// left and ErrDiscountExhausted if the discount code has no uses left.
+	// Non-nil answersSealed, the entrant's encrypted questionnaire answers,
+	// and customAnswers, their answers to the race's custom fields as JSON,
+	// are stored with the registration.
+	Create(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error)
+	// GetForCancellation returns a registration together with the race and
+	// event details needed to decide whether it may be cancelled.
+	GetForCancellation(ctx context.Context, id int64) (db.GetRegistrationForCancellationRow, error)
+	// GetForConfirmation returns a registration together with the race,
+	// event and entrant details needed to confirm it by email.
+	GetForConfirmation(ctx context.Context, id int64) (db.GetRegistrationForConfirmationRow, error)
+	// GetForWebhook returns the registration as webhooks describe it, or
+	// ErrNotFound if it does not exist.
+	GetForWebhook(ctx context.Context, id int64) (db.GetRegistrationForWebhookRow, error)
+	// ListByUser returns the user's registrations with their race, event and
+	// latest payment status, soonest race first.
+	ListByUser(ctx context.Context, userID int64) ([]db.ListRegistrationsByUserRow, error)
+	// ListForExport returns up to limit of the user's registrations with
+	// an id above afterID, cancelled ones included, in id order.
+	ListForExport(ctx context.Context, userID, afterID int64, limit int) ([]db.ExportUserRegistrationsRow, error)
+	// ListAnswersForExport returns up to limit of the sealed questionnaire
+	// answers on the user's registrations with an id above afterID, in id
+	// order.
+	ListAnswersForExport(ctx context.Context, userID, afterID int64, limit int) ([]db.ExportUserRegistrationAnswersRow, error)
+	// ListByWave returns the entrants holding a place in the start wave,
+	// leaving out those anonymised.
+	ListByWave(ctx context.Context, waveID int64) ([]db.ListWaveEntrantsRow, error)
+	// ListByRace returns the race's registrations with their entrant,
+	// earliest first, including those cancelled.
+	ListByRace(ctx context.Context, raceID int64) ([]db.ListRaceEntrantsRow, error)
+	// ListAnswers returns the encrypted questionnaire answers of the race's
+	// registrations that have them.
+	ListAnswers(ctx context.Context, raceID int64) ([]db.ListRaceRegistrationAnswersRow, error)
+	// ListCustomAnswers returns the answers to custom fields of the race's
+	// registrations that have them, as JSON.
+	ListCustomAnswers(ctx context.Context, raceID int64) ([]db.ListRaceRegistrationCustomAnswersRow, error)
+	// ListBibs returns the race's active registrations with any bib they
+	// have, in the order bibs are handed out: earliest registered first.
+	ListBibs(ctx context.Context, raceID int64) ([]db.ListRaceBibsRow, error)
+	// AssignBibs gives each registration its bib in a single transaction
+	// and returns how many were numbered. Registrations that are not
+	// confirmed or already have a bib are left alone. If a bib is already
+	// taken in the race nothing is written and ErrConflict is returned.
+	AssignBibs(ctx context.Context, assignments []BibAssignment) (int64, error)
+	// SetBib gives the registration the bib, replacing any it had. It
+	// returns ErrNotFound if the registration does not exist or is
+	// cancelled, and ErrConflict if the bib is already taken in the race.
+	SetBib(ctx context.Context, id int64, bib string) error
+	// CheckIn records the race's confirmed registration as checked in at
+	// the time, keeping any earlier check-in. Each call updates the one
+	// row, so marshals checking in different entrants at once do not
+	// overwrite each other. It returns ErrNotFound if the race has no such
+	// confirmed registration.
+	CheckIn(ctx context.Context, raceID, id int64, at time.Time) error
+	// UndoCheckIn clears the check-in of the race's registration if it was
+	// made after the given time, and does nothing if it is not checked in.
+	// It returns ErrNotFound if the race has no such registration and
+	// ErrConflict if it was checked in earlier.
+	UndoCheckIn(ctx context.Context, raceID, id int64, after time.Time) error
+	// Cancel marks the registration cancelled. It returns ErrNotFound if the
+	// registration does not exist or is already cancelled.
+	Cancel(ctx context.Context, id int64) error
+	// ExpirePending cancels every pending registration created before the
+	// given time, freeing its place, and returns how many were cancelled.
+	ExpirePending(ctx context.Context, before time.Time) (int64, error)
+	// ClaimReminders marks the active registrations for races starting
+	// after from and no later than to as reminded, and returns them for
+	// their entrants to be emailed. Registrations already reminded are left
+	// out, as are entrants who only accept transactional email, so running
+	// it again for the same window returns nothing new.
+	ClaimReminders(ctx context.Context, from, to time.Time) ([]db.ClaimRaceRemindersRow, error)
+	// DigestStats returns, for each of the organisation's events with
+	// registrations made from from until to, how many there were and the
+	// revenue they brought in, one row per currency.
+	DigestStats(ctx context.Context, organisationID int64, from, to time.Time) ([]db.GetRegistrationDigestStatsRow, error)
+	// GetForTransfer returns a registration together with the race, event
+	// and owner details needed to transfer it.
+	GetForTransfer(ctx context.Context, id int64) (db.GetRegistrationForTransferRow, error)
+	// GetForEdit returns a registration together with its race, event and
+	// encrypted questionnaire answers, nil if it has none, for its entrant
+	// to edit.
+	GetForEdit(ctx context.Context, id int64) (db.GetRegistrationForEditRow, error)
+	// UpdateAnswers replaces the registration's encrypted questionnaire
+	// answers and records the edit in the audit log, in one transaction.
+	UpdateAnswers(ctx context.Context, params UpdateAnswersParams) error
+	// Transfer moves a registration from its owner to the entrant with the
+	// recipient's email, creating an entrant user if there is none, and
+	// records the transfer, all in one transaction. The owner's questionnaire
+	// answers are removed, as they are not the recipient's. It returns ErrNotFound if
+	// the registration is no longer the owner's or has been cancelled, and
+	// ErrAlreadyRegistered if the recipient already holds a place in the race.
+	Transfer(ctx context.Context, params TransferParams) (TransferredRegistration, error)
+	// AcceptTransfers marks every transfer waiting on the user accepted and
+	// returns how many there were.
+	AcceptTransfers(ctx context.Context, userID int64) (int64, error)
+	// ImportEntrants registers entrants for the race in a single
+	// transaction, creating entrant users for unknown email addresses. It
+	// reports for each entrant whether it was registered; entrants already
+	// registered for the race are skipped. If the race would end up over
+	// capacity nothing is written and ErrCapacityExceeded is returned, and
+	// if an entrant's bib is already taken in the race, ErrConflict.
+	ImportEntrants(ctx context.Context, raceID int64, entrants []ImportedEntrant) ([]bool, error)
+	// CreateTeam reserves places in the race for a team and registers its
+	// captain in one of them, pending payment. It returns ErrNotFound if the
+	// race does not exist, ErrNotPublished if its event is not published,
+	// ErrCapacityExceeded if the race has too few places left for the whole
+	// team and ErrAlreadyRegistered if the captain already holds an active
+	// registration for it.
+	CreateTeam(ctx context.Context, params CreateTeamParams) (CreatedTeam, error)
+	// GetTeamByInviteCode returns the team with the invite code, or
+	// ErrNotFound if there is none.
+	GetTeamByInviteCode(ctx context.Context, code string) (db.Team, error)
+	// JoinTeam registers the user in one of the places reserved for the
+	// team, pending payment. It returns ErrNotFound if the team does not
+	// exist or its places have been released, ErrNotPublished if the race's
+	// event is not published, ErrTeamFull if every place is taken and
+	// ErrAlreadyRegistered if the user already holds an active registration
+	// for the race.
+	JoinTeam(ctx context.Context, userID, teamID int64) (db.Registration, error)
+	// ReleaseUnfilledTeams releases the places held by teams that are still
+	// short of members at the given time, and returns how many teams there
+	// were. Members who have already joined keep their places.
+	ReleaseUnfilledTeams(ctx context.Context, now time.Time) (int64, error)
+}
+
+// ImportedEntrant is an entrant registered outside Firecrest.
+type ImportedEntrant struct {
+	Email     string
+	FirstName string
+	LastName  string
+	// Bib is the entrant's race number, if already allocated.
+	Bib string
+}
+
+// BibAssignment is a bib to give a registration.
+type BibAssignment struct {
+	RegistrationID int64
+	Bib            string
+}
+
+// TransferParams identifies a registration to transfer and its recipient.
+type TransferParams struct {
+	RegistrationID int64
+	RaceID         int64
+	FromUserID     int64
+	RecipientEmail string
+}
+
+// UpdateAnswersParams describes an entrant's edit of their questionnaire
+// answers.
+type UpdateAnswersParams struct {
+	RegistrationID int64
+	// UserID is who made the edit
+	UserID        int64
+	AnswersSealed []byte
+	// Changed names the questions whose answers changed. Only the names
+	// are audited, as the answers themselves are kept encrypted.
+	Changed []string
+}
+
+// TransferredRegistration is the outcome of a transfer.
+type TransferredRegistration struct {
+	Transfer  db.RegistrationTransfer
+	Recipient db.User
+	// NewRecipient reports whether the recipient's user was created by the
+	// transfer.
+	NewRecipient bool
+}
+
+// CreateTeamParams describes a team to enter in a race.
+type CreateTeamParams struct {
+	RaceID        int64
+	CaptainUserID int64
+	Name          string
+	// Size is the number of places reserved, the captain's included.
+	Size       int
+	InviteCode string
+	// FillBy is when places no member has taken are released.
+	FillBy time.Time
+}
+
+// CreatedTeam is a new team and its captain's registration.
+type CreatedTeam struct {
+	Team         db.Team
+	Registration db.Registration
+}
+
+type registrationRepository struct {
+	queries *db.Queries
+	pool    TxBeginner
+}
+
+// NewRegistrationRepository creates a new RegistrationRepository backed by the
+// given queries, using pool for writes that must be atomic.
+func NewRegistrationRepository(queries *db.Queries, pool TxBeginner) RegistrationRepository {
+	return &registrationRepository{queries: queries, pool: pool}
+}
+
+func (r *registrationRepository) CountByRaceForEvent(ctx context.Context, eventID int64) (map[int64]int, error) {
+	rows, err := r.queries.CountRegistrationsByRaceForEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int64]int, len(rows))
+	for _, row := range rows {
+		counts[row.RaceID] = int(row.Registered)
+	}
+	return counts, nil
+}
+
+func (r *registrationRepository) CountRegistrationsByEvent(ctx context.Context, eventIDs []int64) (map[int64]int, error) {
+	rows, err := r.queries.CountRegistrationsByEvent(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int64]int, len(rows))
+	for _, row := range rows {
+		counts[row.EventID] = int(row.Registered)
+	}
+	return counts, nil
+}
+
+func (r *registrationRepository) GetActive(ctx context.Context, userID, raceID int64) (db.Registration, error) {
+	reg, err := r.queries.GetActiveRegistration(ctx, db.GetActiveRegistrationParams{UserID: userID, RaceID: raceID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Registration{}, ErrNotFound
+		}
+		return db.Registration{}, err
+	}
+	return reg, nil
+}
+
+func (r *registrationRepository) GetAgeCheck(ctx context.Context, userID, raceID int64) (db.GetEntrantAgeCheckRow, error) {
+	check, err := r.queries.GetEntrantAgeCheck(ctx, db.GetEntrantAgeCheckParams{UserID: userID, RaceID: raceID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetEntrantAgeCheckRow{}, ErrNotFound
+		}
+		return db.GetEntrantAgeCheckRow{}, err
+	}
+	return check, nil
+}
+
+func (r *registrationRepository) IsEmailVerified(ctx context.Context, userID int64) (bool, error) {
+	return r.queries.IsEmailVerified(ctx, userID)
+}
+
+func (r *registrationRepository) Create(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return db.Registration{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	capacity, err := qtx.LockRace(ctx, params.RaceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Registration{}, ErrNotFound
+		}
+		return db.Registration{}, err
+	}
+	if err := checkPublished(ctx, qtx, params.RaceID); err != nil {
+		return db.Registration{}, err
+	}
+	registered, err := placesTaken(ctx, qtx, params.RaceID)
+	if err != nil {
+		return db.Registration{}, err
+	}
+	if registered >= int64(capacity) {
+		return db.Registration{}, ErrCapacityExceeded
+	}
+	// Waves fill under the same lock as the race, so two entries cannot
+	// both take a wave's last place
+	waves, err := qtx.ListRaceWaves(ctx, params.RaceID)
+	if err != nil {
+		return db.Registration{}, err
+	}
+	if len(waves) > 0 || params.WaveID.Valid {
+		waveID, err := chooseWave(waves, params.WaveID)
+		if err != nil {
+			return db.Registration{}, err
+		}
+		params.WaveID = pgtype.Int8{Int64: waveID, Valid: true}
+	}
+
+	// Checked under the race lock, so two entries cannot both take a
+	// tier's last place at its price
+	if params.PriceTierID.Valid {
+		if err := checkPriceTier(ctx, qtx, params.RaceID, params.PriceTierID.Int64); err != nil {
+			return db.Registration{}, err
+		}
+	}
+
+	// Used in the same transaction, so an entry turned away below hands
+	// the use back
+	if params.DiscountCodeID.Valid {
+		used, err := qtx.UseDiscountCode(ctx, params.DiscountCodeID.Int64)
+		if err != nil {
+			return db.Registration{}, err
+		}
+		if used == 0 {
+			return db.Registration{}, ErrDiscountExhausted
+		}
+	}
+
+	// The unique index on active registrations turns away a second entry
+	// however it arrives
+	reg, err := qtx.CreateRegistration(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.Registration{}, ErrAlreadyRegistered
+		}
+		return db.Registration{}, err
+	}
+	if answersSealed != nil {
+		if err := qtx.CreateRegistrationAnswers(ctx, db.CreateRegistrationAnswersParams{
+			RegistrationID: reg.ID,
+			AnswersSealed:  answersSealed,
+		}); err != nil {
+			return db.Registration{}, err
+		}
+	}
+	if customAnswers != nil {
+		if err := qtx.CreateRegistrationCustomAnswers(ctx, db.CreateRegistrationCustomAnswersParams{
+			RegistrationID: reg.ID,
+			Answers:        customAnswers,
+		}); err != nil {
+			return db.Registration{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.Registration{}, err
+	}
+	return reg, nil
+}
+
+// checkPublished returns ErrNotPublished unless the race's event is
+// published. Drafts and archived events take no entries, whatever their
+// registration dates say.
+func checkPublished(ctx context.Context, qtx *db.Queries, raceID int64) error {
+	status, err := qtx.GetRaceEventStatus(ctx, raceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if status != db.EventStatusPublished {
+		return ErrNotPublished
+	}
+	return nil
+}
+
+// placesTaken returns how many of the race's places are spoken for: those
+// held by active registrations and those teams are keeping for members yet
+// to join. Callers should hold the race lock.
+func placesTaken(ctx context.Context, qtx *db.Queries, raceID int64) (int64, error) {
+	registered, err := qtx.CountRegistrationsByRace(ctx, raceID)
+	if err != nil {
+		return 0, err
+	}
+	reserved, err := qtx.CountUnfilledTeamPlaces(ctx, raceID)
+	if err != nil {
+		return 0, err
+	}
+	return registered + reserved, nil
+}
+
+func (r *registrationRepository) GetForCancellation(ctx context.Context, id int64) (db.GetRegistrationForCancellationRow, error) {
+	row, err := r.queries.GetRegistrationForCancellation(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetRegistrationForCancellationRow{}, ErrNotFound
+		}
+		return db.GetRegistrationForCancellationRow{}, err
+	}
+	return row, nil
+}
+
+func (r *registrationRepository) GetForConfirmation(ctx context.Context, id int64) (db.GetRegistrationForConfirmationRow, error) {
+	row, err := r.queries.GetRegistrationForConfirmation(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetRegistrationForConfirmationRow{}, ErrNotFound
+		}
+		return db.GetRegistrationForConfirmationRow{}, err
+	}
+	return row, nil
+}
+
+func (r *registrationRepository) GetForWebhook(ctx context.Context, id int64) (db.GetRegistrationForWebhookRow, error) {
+	reg, err := r.queries.GetRegistrationForWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetRegistrationForWebhookRow{}, ErrNotFound
+		}
+		return db.GetRegistrationForWebhookRow{}, err
+	}
+	return reg, nil
+}
+
+func (r *registrationRepository) ListByUser(ctx context.Context, userID int64) ([]db.ListRegistrationsByUserRow, error) {
+	return r.queries.ListRegistrationsByUser(ctx, userID)
+}
+
+func (r *registrationRepository) ListForExport(ctx context.Context, userID, afterID int64, limit int) ([]db.ExportUserRegistrationsRow, error) {
+	return r.queries.ExportUserRegistrations(ctx, db.ExportUserRegistrationsParams{UserID: userID, AfterID: afterID, MaxRows: int32(limit)})
+}
+
+func (r *registrationRepository) ListAnswersForExport(ctx context.Context, userID, afterID int64, limit int) ([]db.ExportUserRegistrationAnswersRow, error) {
+	return r.queries.ExportUserRegistrationAnswers(ctx, db.ExportUserRegistrationAnswersParams{UserID: userID, AfterID: afterID, MaxRows: int32(limit)})
+}
+
+func (r *registrationRepository) ListByRace(ctx context.Context, raceID int64) ([]db.ListRaceEntrantsRow, error) {
+	return r.queries.ListRaceEntrants(ctx, raceID)
+}
+
+func (r *registrationRepository) ListByWave(ctx context.Context, waveID int64) ([]db.ListWaveEntrantsRow, error) {
+	return r.queries.ListWaveEntrants(ctx, pgtype.Int8{Int64: waveID, Valid: true})
+}
+
+func (r *registrationRepository) ListAnswers(ctx context.Context, raceID int64) ([]db.ListRaceRegistrationAnswersRow, error) {
+	return r.queries.ListRaceRegistrationAnswers(ctx, raceID)
+}
+
+func (r *registrationRepository) ListCustomAnswers(ctx context.Context, raceID int64) ([]db.ListRaceRegistrationCustomAnswersRow, error) {
+	return r.queries.ListRaceRegistrationCustomAnswers(ctx, raceID)
+}
+
+func (r *registrationRepository) ListBibs(ctx context.Context, raceID int64) ([]db.ListRaceBibsRow, error) {
+	return r.queries.ListRaceBibs(ctx, raceID)
+}
+
+func (r *registrationRepository) AssignBibs(ctx context.Context, assignments []BibAssignment) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	var assigned int64
+	for _, a := range assignments {
+		n, err := qtx.AssignRegistrationBib(ctx, db.AssignRegistrationBibParams{
+			ID:  a.RegistrationID,
+			Bib: pgtype.Text{String: a.Bib, Valid: true},
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				return 0, ErrConflict
+			}
+			return 0, err
+		}
+		assigned += n
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return assigned, nil
+}
+
+func (r *registrationRepository) SetBib(ctx context.Context, id int64, bib string) error {
+	n, err := r.queries.SetRegistrationBib(ctx, db.SetRegistrationBibParams{
+		ID:  id,
+		Bib: pgtype.Text{String: bib, Valid: true},
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *registrationRepository) CheckIn(ctx context.Context, raceID, id int64, at time.Time) error {
+	n, err := r.queries.CheckInRegistration(ctx, db.CheckInRegistrationParams{
+		CheckedInAt: pgtype.Timestamptz{Time: at, Valid: true},
+		ID:          id,
+		RaceID:      raceID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *registrationRepository) UndoCheckIn(ctx context.Context, raceID, id int64, after time.Time) error {
+	n, err := r.queries.UndoRegistrationCheckIn(ctx, db.UndoRegistrationCheckInParams{
+		ID:             id,
+		RaceID:         raceID,
+		CheckedInAfter: pgtype.Timestamptz{Time: after, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	// Nothing was cleared: find out whether there was nothing to undo or
+	// the check-in is too old to
+	checkedInAt, err := r.queries.GetRegistrationCheckIn(ctx, db.GetRegistrationCheckInParams{ID: id, RaceID: raceID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if checkedInAt.Valid {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *registrationRepository) Cancel(ctx context.Context, id int64) error {
+	n, err := r.queries.CancelRegistration(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *registrationRepository) ExpirePending(ctx context.Context, before time.Time) (int64, error) {
+	return r.queries.ExpirePendingRegistrations(ctx, pgtype.Timestamptz{Time: before, Valid: true})
+}
+
+func (r *registrationRepository) ClaimReminders(ctx context.Context, from, to time.Time) ([]db.ClaimRaceRemindersRow, error) {
+	return r.queries.ClaimRaceReminders(ctx, db.ClaimRaceRemindersParams{
+		StartsAfter:  pgtype.Timestamptz{Time: from, Valid: true},
+		StartsBefore: pgtype.Timestamptz{Time: to, Valid: true},
+	})
+}
+
+func (r *registrationRepository) DigestStats(ctx context.Context, organisationID int64, from, to time.Time) ([]db.GetRegistrationDigestStatsRow, error) {
+	return r.queries.GetRegistrationDigestStats(ctx, db.GetRegistrationDigestStatsParams{
+		OrganisationID: organisationID,
+		CreatedAfter:   pgtype.Timestamptz{Time: from, Valid: true},
+		CreatedBefore:  pgtype.Timestamptz{Time: to, Valid: true},
+	})
+}
+
+func (r *registrationRepository) GetForTransfer(ctx context.Context, id int64) (db.GetRegistrationForTransferRow, error) {
+	row, err := r.queries.GetRegistrationForTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetRegistrationForTransferRow{}, ErrNotFound
+		}
+		return db.GetRegistrationForTransferRow{}, err
+	}
+	return row, nil
+}
+
+func (r *registrationRepository) GetForEdit(ctx context.Context, id int64) (db.GetRegistrationForEditRow, error) {
+	row, err := r.queries.GetRegistrationForEdit(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetRegistrationForEditRow{}, ErrNotFound
+		}
+		return db.GetRegistrationForEditRow{}, err
+	}
+	return row, nil
+}
+
+func (r *registrationRepository) UpdateAnswers(ctx context.Context, params UpdateAnswersParams) error {
+	changed, err := json.Marshal(map[string][]string{"questions": params.Changed})
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	if err := qtx.UpsertRegistrationAnswers(ctx, db.UpsertRegistrationAnswersParams{
+		RegistrationID: params.RegistrationID,
+		AnswersSealed:  params.AnswersSealed,
+	}); err != nil {
+		return err
+	}
+	if err := qtx.CreateAuditLogEntry(ctx, db.CreateAuditLogEntryParams{
+		TableName:     "registration_answers",
+		RecordID:      params.RegistrationID,
+		Action:        db.AuditActionUpdated,
+		UserID:        pgtype.Int8{Int64: params.UserID, Valid: true},
+		ChangedFields: changed,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *registrationRepository) Transfer(ctx context.Context, params TransferParams) (TransferredRegistration, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return TransferredRegistration{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	// Locking the race serialises this with other writes that check who is
+	// registered for it.
+	qtx := r.queries.WithTx(tx)
+	if _, err := qtx.LockRace(ctx, params.RaceID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TransferredRegistration{}, ErrNotFound
+		}
+		return TransferredRegistration{}, err
+	}
+
+	var result TransferredRegistration
+	result.Recipient, err = qtx.GetUserByEmail(ctx, params.RecipientEmail)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// The recipient fills in their name when they sign up
+		result.Recipient, err = qtx.CreateUser(ctx, db.CreateUserParams{
+			Email: params.RecipientEmail,
+			Role:  db.UserRoleEntrant,
+		})
+		if err != nil {
+			return TransferredRegistration{}, err
+		}
+		result.NewRecipient = true
+	case err != nil:
+		return TransferredRegistration{}, err
+	default:
+		exists, err := qtx.HasActiveRegistration(ctx, db.HasActiveRegistrationParams{
+			UserID: result.Recipient.ID,
+			RaceID: params.RaceID,
+		})
+		if err != nil {
+			return TransferredRegistration{}, err
+		}
+		if exists {
+			return TransferredRegistration{}, ErrAlreadyRegistered
+		}
+	}
+
+	n, err := qtx.TransferRegistration(ctx, db.TransferRegistrationParams{
+		ToUserID:   result.Recipient.ID,
+		ID:         params.RegistrationID,
+		FromUserID: params.FromUserID,
+	})
+	if err != nil {
+		return TransferredRegistration{}, err
+	}
+	if n == 0 {
+		return TransferredRegistration{}, ErrNotFound
+	}
+	if err := qtx.DeleteRegistrationAnswers(ctx, params.RegistrationID); err != nil {
+		return TransferredRegistration{}, err
+	}
+	if err := qtx.DeleteRegistrationCustomAnswers(ctx, params.RegistrationID); err != nil {
+		return TransferredRegistration{}, err
+	}
+
+	result.Transfer, err = qtx.CreateRegistrationTransfer(ctx, db.CreateRegistrationTransferParams{
+		RegistrationID: params.RegistrationID,
+		FromUserID:     params.FromUserID,
+		ToUserID:       result.Recipient.ID,
+	})
+	if err != nil {
+		return TransferredRegistration{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return TransferredRegistration{}, err
+	}
+	return result, nil
+}
+
+func (r *registrationRepository) AcceptTransfers(ctx context.Context, userID int64) (int64, error) {
+	return r.queries.AcceptRegistrationTransfers(ctx, userID)
+}
+
+func (r *registrationRepository) ImportEntrants(ctx context.Context, raceID int64, entrants []ImportedEntrant) ([]bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	capacity, err := qtx.LockRace(ctx, raceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	registered, err := placesTaken(ctx, qtx, raceID)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]bool, len(entrants))
+	for i, entrant := range entrants {
+		user, err := qtx.GetUserByEmail(ctx, entrant.Email)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			user, err = qtx.CreateUser(ctx, db.CreateUserParams{
+				Email:     entrant.Email,
+				FirstName: entrant.FirstName,
+				LastName:  entrant.LastName,
+				Role:      db.UserRoleEntrant,
+			})
+			if err != nil {
+				return nil, err
+			}
+		case err != nil:
+			return nil, err
+		default:
+			exists, err := qtx.HasActiveRegistration(ctx, db.HasActiveRegistrationParams{
+				UserID: user.ID,
+				RaceID: raceID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+		}
+
+		if registered >= int64(capacity) {
+			return nil, ErrCapacityExceeded
+		}
+		if _, err := qtx.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+			UserID: user.ID,
+			RaceID: raceID,
+			Bib:    pgtype.Text{String: entrant.Bib, Valid: entrant.Bib != ""},
+		}); err != nil {
+			if isBibConflict(err) {
+				return nil, ErrConflict
+			}
+			return nil, err
+		}
+		registered++
+		created[i] = true
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *registrationRepository) CreateTeam(ctx context.Context, params CreateTeamParams) (CreatedTeam, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return CreatedTeam{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	capacity, err := qtx.LockRace(ctx, params.RaceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CreatedTeam{}, ErrNotFound
+		}
+		return CreatedTeam{}, err
+	}
+	if err := checkPublished(ctx, qtx, params.RaceID); err != nil {
+		return CreatedTeam{}, err
+	}
+	taken, err := placesTaken(ctx, qtx, params.RaceID)
+	if err != nil {
+		return CreatedTeam{}, err
+	}
+	if taken+int64(params.Size) > int64(capacity) {
+		return CreatedTeam{}, ErrCapacityExceeded
+	}
+
+	// Checked before the team is written so that a unique violation below
+	// can only mean a clashing invite code.
+	exists, err := qtx.HasActiveRegistration(ctx, db.HasActiveRegistrationParams{
+		UserID: params.CaptainUserID,
+		RaceID: params.RaceID,
+	})
+	if err != nil {
+		return CreatedTeam{}, err
+	}
+	if exists {
+		return CreatedTeam{}, ErrAlreadyRegistered
+	}
+
+	var result CreatedTeam
+	result.Team, err = qtx.CreateTeam(ctx, db.CreateTeamParams{
+		RaceID:        params.RaceID,
+		CaptainUserID: params.CaptainUserID,
+		Name:          params.Name,
+		Size:          int32(params.Size),
+		InviteCode:    params.InviteCode,
+		FillBy:        pgtype.Timestamptz{Time: params.FillBy, Valid: true},
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return CreatedTeam{}, ErrConflict
+		}
+		return CreatedTeam{}, err
+	}
+
+	result.Registration, err = qtx.CreateTeamRegistration(ctx, db.CreateTeamRegistrationParams{
+		UserID: params.CaptainUserID,
+		RaceID: params.RaceID,
+		TeamID: pgtype.Int8{Int64: result.Team.ID, Valid: true},
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return CreatedTeam{}, ErrAlreadyRegistered
+		}
+		return CreatedTeam{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return CreatedTeam{}, err
+	}
+	return result, nil
+}
+
+func (r *registrationRepository) GetTeamByInviteCode(ctx context.Context, code string) (db.Team, error) {
+	team, err := r.queries.GetTeamByInviteCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Team{}, ErrNotFound
+		}
+		return db.Team{}, err
+	}
+	return team, nil
+}
+
+func (r *registrationRepository) JoinTeam(ctx context.Context, userID, teamID int64) (db.Registration, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return db.Registration{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	// The member takes a place the team already holds, so the race's
+	// capacity needs no further check; locking the team stops two members
+	// taking its last place.
+	qtx := r.queries.WithTx(tx)
+	team, err := qtx.LockTeam(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Registration{}, ErrNotFound
+		}
+		return db.Registration{}, err
+	}
+	if err := checkPublished(ctx, qtx, team.RaceID); err != nil {
+		return db.Registration{}, err
+	}
+	members, err := qtx.CountTeamMembers(ctx, pgtype.Int8{Int64: team.ID, Valid: true})
+	if err != nil {
+		return db.Registration{}, err
+	}
+	if members >= int64(team.Size) {
+		return db.Registration{}, ErrTeamFull
+	}
+
+	reg, err := qtx.CreateTeamRegistration(ctx, db.CreateTeamRegistrationParams{
+		UserID: userID,
+		RaceID: team.RaceID,
+		TeamID: pgtype.Int8{Int64: team.ID, Valid: true},
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.Registration{}, ErrAlreadyRegistered
+		}
+		return db.Registration{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.Registration{}, err
+	}
+	return reg, nil
+}
+
+func (r *registrationRepository) ReleaseUnfilledTeams(ctx context.Context, now time.Time) (int64, error) {
+	return r.queries.ReleaseUnfilledTeams(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+}
@@ -0,0 +1,823 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestRegistrationRepository(t *testing.T) {
+	ctx := context.Background()
+
+	// setup creates a race in a published event with one registration held
+	// by jane@example.com.
+	setup := func(t *testing.T) (*db.Queries, db.User, db.Registration) {
+		t.Helper()
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+			OrganisationID: org.ID,
+			Name:           "Peak District Ultra",
+			Slug:           "peak-district-ultra",
+			Year:           2026,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if _, err := queries.SetEventStatus(ctx, db.SetEventStatusParams{ID: event.ID, Status: db.EventStatusPublished}); err != nil {
+			t.Fatalf("failed to publish event: %v", err)
+		}
+		race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+			EventID:     event.ID,
+			Name:        "Ultra 50K",
+			Slug:        "ultra-50k",
+			MaxCapacity: 300,
+		})
+		if err != nil {
+			t.Fatalf("failed to create race: %v", err)
+		}
+		owner := createTestUser(t, queries, "jane@example.com")
+		reg, err := queries.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+			UserID: owner.ID,
+			RaceID: race.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create registration: %v", err)
+		}
+		return queries, owner, reg
+	}
+
+	t.Run("transfers to an existing user pending acceptance", func(t *testing.T) {
+		queries, owner, reg := setup(t)
+		recipient := createTestUser(t, queries, "sam@example.com")
+		repo := NewRegistrationRepository(queries, testPool)
+
+		moved, err := repo.Transfer(ctx, TransferParams{
+			RegistrationID: reg.ID,
+			RaceID:         reg.RaceID,
+			FromUserID:     owner.ID,
+			RecipientEmail: "sam@example.com",
+		})
+		if err != nil {
+			t.Fatalf("failed to transfer: %v", err)
+		}
+		if moved.NewRecipient || moved.Recipient.ID != recipient.ID {
+			t.Errorf("expected the existing user to receive the place, got %+v", moved)
+		}
+		if moved.Transfer.FromUserID != owner.ID || moved.Transfer.ToUserID != recipient.ID || !moved.Transfer.CreatedAt.Valid {
+			t.Errorf("unexpected transfer record: %+v", moved.Transfer)
+		}
+
+		if regs, err := repo.ListByUser(ctx, owner.ID); err != nil || len(regs) != 0 {
+			t.Errorf("expected the owner to have no registrations, got %d (err %v)", len(regs), err)
+		}
+		regs, err := repo.ListByUser(ctx, recipient.ID)
+		if err != nil {
+			t.Fatalf("failed to list registrations: %v", err)
+		}
+		if len(regs) != 1 || !regs[0].TransferPending {
+			t.Fatalf("expected one registration pending acceptance, got %+v", regs)
+		}
+
+		if n, err := repo.AcceptTransfers(ctx, recipient.ID); err != nil || n != 1 {
+			t.Fatalf("expected 1 transfer accepted, got %d (err %v)", n, err)
+		}
+		regs, err = repo.ListByUser(ctx, recipient.ID)
+		if err != nil {
+			t.Fatalf("failed to list registrations: %v", err)
+		}
+		if len(regs) != 1 || regs[0].TransferPending {
+			t.Errorf("expected the registration to be accepted, got %+v", regs)
+		}
+	})
+
+	t.Run("creates a user for a new email", func(t *testing.T) {
+		queries, owner, reg := setup(t)
+		repo := NewRegistrationRepository(queries, testPool)
+
+		moved, err := repo.Transfer(ctx, TransferParams{
+			RegistrationID: reg.ID,
+			RaceID:         reg.RaceID,
+			FromUserID:     owner.ID,
+			RecipientEmail: "new@example.com",
+		})
+		if err != nil {
+			t.Fatalf("failed to transfer: %v", err)
+		}
+		if !moved.NewRecipient || moved.Recipient.Email != "new@example.com" || moved.Recipient.Role != db.UserRoleEntrant {
+			t.Errorf("expected a new entrant to be created, got %+v", moved)
+		}
+	})
+
+	t.Run("returns ErrAlreadyRegistered when the recipient holds a place", func(t *testing.T) {
+		queries, owner, reg := setup(t)
+		recipient := createTestUser(t, queries, "sam@example.com")
+		if _, err := queries.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+			UserID: recipient.ID,
+			RaceID: reg.RaceID,
+		}); err != nil {
+			t.Fatalf("failed to create registration: %v", err)
+		}
+		repo := NewRegistrationRepository(queries, testPool)
+
+		_, err := repo.Transfer(ctx, TransferParams{
+			RegistrationID: reg.ID,
+			RaceID:         reg.RaceID,
+			FromUserID:     owner.ID,
+			RecipientEmail: "sam@example.com",
+		})
+		if !errors.Is(err, ErrAlreadyRegistered) {
+			t.Fatalf("expected ErrAlreadyRegistered, got %v", err)
+		}
+		if regs, err := repo.ListByUser(ctx, owner.ID); err != nil || len(regs) != 1 {
+			t.Errorf("expected the owner to keep the place, got %d (err %v)", len(regs), err)
+		}
+	})
+
+	t.Run("returns ErrNotFound for a cancelled registration", func(t *testing.T) {
+		queries, owner, reg := setup(t)
+		repo := NewRegistrationRepository(queries, testPool)
+		if err := repo.Cancel(ctx, reg.ID); err != nil {
+			t.Fatalf("failed to cancel: %v", err)
+		}
+
+		_, err := repo.Transfer(ctx, TransferParams{
+			RegistrationID: reg.ID,
+			RaceID:         reg.RaceID,
+			FromUserID:     owner.ID,
+			RecipientEmail: "new@example.com",
+		})
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		if _, err := NewAuthRepository(queries, testPool).GetUserByEmail(ctx, "new@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected the recipient's user to be rolled back, got %v", err)
+		}
+	})
+
+	t.Run("replaces answers and audits which questions changed", func(t *testing.T) {
+		queries, owner, reg := setup(t)
+		repo := NewRegistrationRepository(queries, testPool)
+
+		row, err := repo.GetForEdit(ctx, reg.ID)
+		if err != nil {
+			t.Fatalf("failed to load registration: %v", err)
+		}
+		if row.UserID != owner.ID || row.AnswersSealed != nil {
+			t.Errorf("expected the owner's registration without answers, got %+v", row)
+		}
+
+		for _, sealed := range [][]byte{[]byte("first"), []byte("second")} {
+			if err := repo.UpdateAnswers(ctx, UpdateAnswersParams{
+				RegistrationID: reg.ID,
+				UserID:         owner.ID,
+				AnswersSealed:  sealed,
+				Changed:        []string{"club"},
+			}); err != nil {
+				t.Fatalf("failed to update answers: %v", err)
+			}
+		}
+
+		answers, err := repo.ListAnswers(ctx, reg.RaceID)
+		if err != nil {
+			t.Fatalf("failed to list answers: %v", err)
+		}
+		if len(answers) != 1 || string(answers[0].AnswersSealed) != "second" {
+			t.Errorf("expected the export to read the latest answers, got %+v", answers)
+		}
+
+		entries, err := queries.ListAuditLogForRecord(ctx, db.ListAuditLogForRecordParams{
+			TableName: "registration_answers",
+			RecordID:  reg.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to list audit log: %v", err)
+		}
+		if len(entries) != 2 || entries[0].UserID.Int64 != owner.ID {
+			t.Fatalf("expected two audit entries by the owner, got %+v", entries)
+		}
+		if got := string(entries[0].ChangedFields); !strings.Contains(got, `"club"`) || strings.Contains(got, "second") {
+			t.Errorf("expected only the question names audited, got %s", got)
+		}
+	})
+
+	t.Run("returns ErrNotFound editing a registration that does not exist", func(t *testing.T) {
+		queries, _, _ := setup(t)
+		if _, err := NewRegistrationRepository(queries, testPool).GetForEdit(ctx, 9999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("expires pending registrations made before the cutoff", func(t *testing.T) {
+		queries, owner, confirmed := setup(t)
+		stale := createTestUser(t, queries, "sam@example.com")
+		fresh := createTestUser(t, queries, "alex@example.com")
+		for _, pending := range []struct {
+			userID int64
+			age    time.Duration
+		}{{stale.ID, 2 * time.Hour}, {fresh.ID, time.Minute}} {
+			if _, err := testPool.Exec(ctx,
+				"INSERT INTO registrations (user_id, race_id, created_at) VALUES ($1, $2, $3)",
+				pending.userID, confirmed.RaceID, time.Now().Add(-pending.age),
+			); err != nil {
+				t.Fatalf("failed to create pending registration: %v", err)
+			}
+		}
+		// The confirmed registration is past the cutoff too, but not pending
+		if _, err := testPool.Exec(ctx, "UPDATE registrations SET created_at = NOW() - INTERVAL '1 day' WHERE id = $1", confirmed.ID); err != nil {
+			t.Fatalf("failed to backdate registration: %v", err)
+		}
+		repo := NewRegistrationRepository(queries, testPool)
+
+		n, err := repo.ExpirePending(ctx, time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("failed to expire registrations: %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected one registration expired, got %d", n)
+		}
+
+		for _, tt := range []struct {
+			userID int64
+			want   db.RegistrationStatus
+		}{
+			{owner.ID, db.RegistrationStatusConfirmed},
+			{stale.ID, db.RegistrationStatusCancelled},
+			{fresh.ID, db.RegistrationStatusPending},
+		} {
+			regs, err := repo.ListByUser(ctx, tt.userID)
+			if err != nil {
+				t.Fatalf("failed to list registrations: %v", err)
+			}
+			if len(regs) != 1 || regs[0].Status != tt.want {
+				t.Errorf("expected user %d to hold one %s registration, got %+v", tt.userID, tt.want, regs)
+			}
+		}
+	})
+
+	t.Run("loads the details to confirm a registration", func(t *testing.T) {
+		queries, owner, confirmed := setup(t)
+
+		row, err := NewRegistrationRepository(queries, testPool).GetForConfirmation(ctx, confirmed.ID)
+		if err != nil {
+			t.Fatalf("failed to load registration: %v", err)
+		}
+		if row.Email != owner.Email || row.RaceName != "Ultra 50K" || row.EventName != "Peak District Ultra" {
+			t.Errorf("unexpected confirmation details %+v", row)
+		}
+	})
+
+	t.Run("reports whether the entrant has verified their email", func(t *testing.T) {
+		queries, owner, _ := setup(t)
+		repo := NewRegistrationRepository(queries, testPool)
+		auth := NewAuthRepository(queries, testPool)
+		if _, err := auth.CreateCredentials(ctx, owner.ID, "hash"); err != nil {
+			t.Fatalf("failed to create credentials: %v", err)
+		}
+
+		if verified, err := repo.IsEmailVerified(ctx, owner.ID); err != nil || verified {
+			t.Errorf("expected an unverified email, got %v (err %v)", verified, err)
+		}
+		if err := auth.VerifyEmail(ctx, owner.ID); err != nil {
+			t.Fatalf("failed to verify email: %v", err)
+		}
+		if verified, err := repo.IsEmailVerified(ctx, owner.ID); err != nil || !verified {
+			t.Errorf("expected a verified email, got %v (err %v)", verified, err)
+		}
+	})
+
+	t.Run("summarises a day's registrations for the digest", func(t *testing.T) {
+		queries, _, imported := setup(t)
+		var orgID int64
+		if err := testPool.QueryRow(ctx,
+			"SELECT e.organisation_id FROM races r INNER JOIN events e ON e.id = r.event_id WHERE r.id = $1", imported.RaceID,
+		).Scan(&orgID); err != nil {
+			t.Fatalf("failed to find organisation: %v", err)
+		}
+		day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+		// Two online entries yesterday, one of them still pending, and one
+		// the day before, besides the imported entry made today
+		for i, entry := range []struct {
+			status  db.RegistrationStatus
+			created time.Time
+		}{
+			{db.RegistrationStatusConfirmed, day.Add(9 * time.Hour)},
+			{db.RegistrationStatusPending, day.Add(23 * time.Hour)},
+			{db.RegistrationStatusConfirmed, day.Add(-time.Hour)},
+		} {
+			user := createTestUser(t, queries, fmt.Sprintf("entrant%d@example.com", i))
+			if _, err := testPool.Exec(ctx,
+				"INSERT INTO registrations (user_id, race_id, status, source, price_units, created_at) VALUES ($1, $2, $3, 'online', 2500, $4)",
+				user.ID, imported.RaceID, entry.status, entry.created,
+			); err != nil {
+				t.Fatalf("failed to create registration: %v", err)
+			}
+		}
+
+		stats, err := NewRegistrationRepository(queries, testPool).DigestStats(ctx, orgID, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			t.Fatalf("failed to load stats: %v", err)
+		}
+		if len(stats) != 1 {
+			t.Fatalf("expected one event, got %+v", stats)
+		}
+		if got := stats[0]; got.EventName != "Peak District Ultra" || got.Currency != "GBP" || got.Registrations != 2 || got.RevenueUnits != 2500 {
+			t.Errorf("expected two entries and one paid, got %+v", got)
+		}
+	})
+
+	t.Run("claims each registration for a reminder once", func(t *testing.T) {
+		queries, owner, confirmed := setup(t)
+		if _, err := testPool.Exec(ctx, "UPDATE races SET starts_at = NOW() + INTERVAL '3 days' WHERE id = $1", confirmed.RaceID); err != nil {
+			t.Fatalf("failed to schedule race: %v", err)
+		}
+		// Sam has unsubscribed, and Alex has cancelled
+		sam := createTestUser(t, queries, "sam@example.com")
+		alex := createTestUser(t, queries, "alex@example.com")
+		userRepo := NewUserRepository(queries, testPool)
+		if err := userRepo.SetEmailPreference(ctx, sam.ID, db.EmailPreferenceTransactional); err != nil {
+			t.Fatalf("failed to unsubscribe: %v", err)
+		}
+		repo := NewRegistrationRepository(queries, testPool)
+		for _, userID := range []int64{sam.ID, alex.ID} {
+			if _, err := repo.Create(ctx, db.CreateRegistrationParams{UserID: userID, RaceID: confirmed.RaceID}, nil, nil); err != nil {
+				t.Fatalf("failed to register: %v", err)
+			}
+		}
+		cancelled, err := repo.GetActive(ctx, alex.ID, confirmed.RaceID)
+		if err != nil {
+			t.Fatalf("failed to load registration: %v", err)
+		}
+		if err := repo.Cancel(ctx, cancelled.ID); err != nil {
+			t.Fatalf("failed to cancel: %v", err)
+		}
+
+		now := time.Now()
+		claimed, err := repo.ClaimReminders(ctx, now, now.Add(7*24*time.Hour))
+		if err != nil {
+			t.Fatalf("failed to claim reminders: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].ID != confirmed.ID || claimed[0].Email != owner.Email {
+			t.Fatalf("expected only %s's registration to be claimed, got %+v", owner.Email, claimed)
+		}
+		if claimed[0].EventSlug != "peak-district-ultra" || !claimed[0].StartsAt.Valid {
+			t.Errorf("expected the event and start time, got %+v", claimed[0])
+		}
+
+		again, err := repo.ClaimReminders(ctx, now, now.Add(7*24*time.Hour))
+		if err != nil {
+			t.Fatalf("failed to claim reminders: %v", err)
+		}
+		if len(again) != 0 {
+			t.Errorf("expected nothing left to remind, got %+v", again)
+		}
+	})
+
+	t.Run("leaves races outside the window to be reminded later", func(t *testing.T) {
+		queries, _, confirmed := setup(t)
+		if _, err := testPool.Exec(ctx, "UPDATE races SET starts_at = NOW() + INTERVAL '8 days' WHERE id = $1", confirmed.RaceID); err != nil {
+			t.Fatalf("failed to schedule race: %v", err)
+		}
+		repo := NewRegistrationRepository(queries, testPool)
+
+		now := time.Now()
+		claimed, err := repo.ClaimReminders(ctx, now, now.Add(7*24*time.Hour))
+		if err != nil {
+			t.Fatalf("failed to claim reminders: %v", err)
+		}
+		if len(claimed) != 0 {
+			t.Errorf("expected no reminders a week early, got %+v", claimed)
+		}
+
+		claimed, err = repo.ClaimReminders(ctx, now, now.Add(9*24*time.Hour))
+		if err != nil {
+			t.Fatalf("failed to claim reminders: %v", err)
+		}
+		if len(claimed) != 1 {
+			t.Errorf("expected the registration to be claimed once in the window, got %+v", claimed)
+		}
+	})
+
+	t.Run("registers online once per race until cancelled", func(t *testing.T) {
+		queries, owner, confirmed := setup(t)
+		repo := NewRegistrationRepository(queries, testPool)
+
+		if _, err := repo.Create(ctx, db.CreateRegistrationParams{UserID: owner.ID, RaceID: confirmed.RaceID}, nil, nil); !errors.Is(err, ErrAlreadyRegistered) {
+			t.Fatalf("expected ErrAlreadyRegistered, got %v", err)
+		}
+		active, err := repo.GetActive(ctx, owner.ID, confirmed.RaceID)
+		if err != nil || active.ID != confirmed.ID {
+			t.Fatalf("expected the confirmed registration to be active, got %+v (err %v)", active, err)
+		}
+
+		if err := repo.Cancel(ctx, confirmed.ID); err != nil {
+			t.Fatalf("failed to cancel: %v", err)
+		}
+		if _, err := repo.GetActive(ctx, owner.ID, confirmed.RaceID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected no active registration after cancelling, got %v", err)
+		}
+		reg, err := repo.Create(ctx, db.CreateRegistrationParams{UserID: owner.ID, RaceID: confirmed.RaceID}, nil, nil)
+		if err != nil {
+			t.Fatalf("expected to register again after cancelling, got %v", err)
+		}
+		if reg.Status != db.RegistrationStatusPending || reg.Source != db.RegistrationSourceOnline {
+			t.Errorf("expected a pending online registration, got %+v", reg)
+		}
+	})
+
+	t.Run("returns ErrCapacityExceeded for a full race", func(t *testing.T) {
+		queries, _, confirmed := setup(t)
+		if _, err := testPool.Exec(ctx, "UPDATE races SET max_capacity = 1 WHERE id = $1", confirmed.RaceID); err != nil {
+			t.Fatalf("failed to shrink race: %v", err)
+		}
+		sam := createTestUser(t, queries, "sam@example.com")
+
+		if _, err := NewRegistrationRepository(queries, testPool).Create(ctx, db.CreateRegistrationParams{UserID: sam.ID, RaceID: confirmed.RaceID}, nil, nil); !errors.Is(err, ErrCapacityExceeded) {
+			t.Errorf("expected ErrCapacityExceeded, got %v", err)
+		}
+	})
+
+	t.Run("admits one of two simultaneous entries", func(t *testing.T) {
+		queries, _, confirmed := setup(t)
+		sam := createTestUser(t, queries, "sam@example.com")
+		repo := NewRegistrationRepository(queries, testPool)
+
+		errs := make(chan error, 2)
+		for range 2 {
+			go func() {
+				_, err := repo.Create(ctx, db.CreateRegistrationParams{UserID: sam.ID, RaceID: confirmed.RaceID}, nil, nil)
+				errs <- err
+			}()
+		}
+		var created, refused int
+		for range 2 {
+			switch err := <-errs; {
+			case err == nil:
+				created++
+			case errors.Is(err, ErrAlreadyRegistered):
+				refused++
+			default:
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if created != 1 || refused != 1 {
+			t.Errorf("expected one entry created and one refused, got %d and %d", created, refused)
+		}
+	})
+
+	t.Run("the unique index blocks a second active entry without the race lock", func(t *testing.T) {
+		queries, _, confirmed := setup(t)
+		sam := createTestUser(t, queries, "sam@example.com")
+		params := db.CreateRegistrationParams{UserID: sam.ID, RaceID: confirmed.RaceID}
+
+		// Two transactions insert at once; the second waits on the first's
+		// index entry and fails once it commits
+		first, err := testPool.Begin(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		//nolint:errcheck // Rollback after Commit is a no-op
+		defer first.Rollback(ctx)
+		if _, err := queries.WithTx(first).CreateRegistration(ctx, params); err != nil {
+			t.Fatalf("failed to create registration: %v", err)
+		}
+
+		second := make(chan error, 1)
+		go func() {
+			tx, err := testPool.Begin(ctx)
+			if err != nil {
+				second <- err
+				return
+			}
+			//nolint:errcheck // Rollback after Commit is a no-op
+			defer tx.Rollback(ctx)
+			if _, err := queries.WithTx(tx).CreateRegistration(ctx, params); err != nil {
+				second <- err
+				return
+			}
+			second <- tx.Commit(ctx)
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		if err := first.Commit(ctx); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		if err := <-second; !isUniqueViolation(err) {
+			t.Errorf("expected a unique violation, got %v", err)
+		}
+	})
+
+	t.Run("refuses entries while the event is not published", func(t *testing.T) {
+		queries, _, confirmed := setup(t)
+		captain := createTestUser(t, queries, "captain@example.com")
+		repo := NewRegistrationRepository(queries, testPool)
+		team, err := repo.CreateTeam(ctx, CreateTeamParams{
+			RaceID:        confirmed.RaceID,
+			CaptainUserID: captain.ID,
+			Name:          "Harriers A",
+			Size:          3,
+			InviteCode:    "HARRIERSA1",
+			FillBy:        time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("failed to create team: %v", err)
+		}
+
+		for _, status := range []db.EventStatus{db.EventStatusDraft, db.EventStatusArchived} {
+			if _, err := testPool.Exec(ctx, "UPDATE events SET status = $1 WHERE id = (SELECT event_id FROM races WHERE id = $2)", status, confirmed.RaceID); err != nil {
+				t.Fatalf("failed to set event status: %v", err)
+			}
+			sam := createTestUser(t, queries, "sam-"+string(status)+"@example.com")
+			if _, err := repo.Create(ctx, db.CreateRegistrationParams{UserID: sam.ID, RaceID: confirmed.RaceID}, nil, nil); !errors.Is(err, ErrNotPublished) {
+				t.Errorf("%s: expected ErrNotPublished, got %v", status, err)
+			}
+			if _, err := repo.CreateTeam(ctx, CreateTeamParams{
+				RaceID:        confirmed.RaceID,
+				CaptainUserID: sam.ID,
+				Name:          "Harriers B",
+				Size:          2,
+				InviteCode:    "HARRIERSB" + string(status[0]),
+				FillBy:        time.Now().Add(time.Hour),
+			}); !errors.Is(err, ErrNotPublished) {
+				t.Errorf("%s: expected ErrNotPublished creating a team, got %v", status, err)
+			}
+			if _, err := repo.JoinTeam(ctx, sam.ID, team.Team.ID); !errors.Is(err, ErrNotPublished) {
+				t.Errorf("%s: expected ErrNotPublished joining a team, got %v", status, err)
+			}
+		}
+	})
+
+	t.Run("holds a team's unfilled places against the race's capacity", func(t *testing.T) {
+		queries, owner, confirmed := setup(t)
+		// One place is taken by the setup registration, leaving four
+		if _, err := testPool.Exec(ctx, "UPDATE races SET max_capacity = 5 WHERE id = $1", confirmed.RaceID); err != nil {
+			t.Fatalf("failed to shrink race: %v", err)
+		}
+		captain := createTestUser(t, queries, "captain@example.com")
+		repo := NewRegistrationRepository(queries, testPool)
+		params := CreateTeamParams{
+			RaceID:        confirmed.RaceID,
+			CaptainUserID: captain.ID,
+			Name:          "Harriers A",
+			Size:          5,
+			InviteCode:    "HARRIERSA1",
+			FillBy:        time.Now().Add(time.Hour),
+		}
+
+		if _, err := repo.CreateTeam(ctx, params); !errors.Is(err, ErrCapacityExceeded) {
+			t.Fatalf("expected ErrCapacityExceeded for a team larger than the places left, got %v", err)
+		}
+		params.Size = 3
+		created, err := repo.CreateTeam(ctx, params)
+		if err != nil {
+			t.Fatalf("failed to create team: %v", err)
+		}
+		if !created.Registration.TeamID.Valid || created.Registration.TeamID.Int64 != created.Team.ID || created.Registration.UserID != captain.ID {
+			t.Errorf("expected the captain to be registered in the team, got %+v", created.Registration)
+		}
+
+		// 1 + 3 reserved leaves one place for individual entries
+		sam := createTestUser(t, queries, "sam@example.com")
+		if _, err := repo.Create(ctx, db.CreateRegistrationParams{UserID: sam.ID, RaceID: confirmed.RaceID}, nil, nil); err != nil {
+			t.Fatalf("expected the last free place to be taken, got %v", err)
+		}
+		kim := createTestUser(t, queries, "kim@example.com")
+		if _, err := repo.Create(ctx, db.CreateRegistrationParams{UserID: kim.ID, RaceID: confirmed.RaceID}, nil, nil); !errors.Is(err, ErrCapacityExceeded) {
+			t.Fatalf("expected places reserved for the team to be unavailable, got %v", err)
+		}
+		if _, err := repo.JoinTeam(ctx, owner.ID, created.Team.ID); !errors.Is(err, ErrAlreadyRegistered) {
+			t.Errorf("expected ErrAlreadyRegistered for a registered entrant, got %v", err)
+		}
+		if _, err := repo.JoinTeam(ctx, kim.ID, created.Team.ID); err != nil {
+			t.Fatalf("expected to join the team, got %v", err)
+		}
+		lee := createTestUser(t, queries, "lee@example.com")
+		if _, err := repo.JoinTeam(ctx, lee.ID, created.Team.ID); err != nil {
+			t.Fatalf("expected to join the team, got %v", err)
+		}
+		ash := createTestUser(t, queries, "ash@example.com")
+		if _, err := repo.JoinTeam(ctx, ash.ID, created.Team.ID); !errors.Is(err, ErrTeamFull) {
+			t.Errorf("expected ErrTeamFull, got %v", err)
+		}
+
+		entrants, err := repo.ListByRace(ctx, confirmed.RaceID)
+		if err != nil {
+			t.Fatalf("failed to list entrants: %v", err)
+		}
+		teamed := 0
+		for _, e := range entrants {
+			if e.TeamName.Valid && e.TeamName.String == "Harriers A" {
+				teamed++
+			}
+		}
+		if teamed != 3 {
+			t.Errorf("expected 3 entrants listed with the team name, got %d", teamed)
+		}
+	})
+
+	t.Run("releases unfilled places after the deadline", func(t *testing.T) {
+		queries, _, confirmed := setup(t)
+		if _, err := testPool.Exec(ctx, "UPDATE races SET max_capacity = 5 WHERE id = $1", confirmed.RaceID); err != nil {
+			t.Fatalf("failed to shrink race: %v", err)
+		}
+		captain := createTestUser(t, queries, "captain@example.com")
+		repo := NewRegistrationRepository(queries, testPool)
+		fillBy := time.Now().Add(time.Hour)
+		created, err := repo.CreateTeam(ctx, CreateTeamParams{
+			RaceID:        confirmed.RaceID,
+			CaptainUserID: captain.ID,
+			Name:          "Harriers A",
+			Size:          4,
+			InviteCode:    "HARRIERSA1",
+			FillBy:        fillBy,
+		})
+		if err != nil {
+			t.Fatalf("failed to create team: %v", err)
+		}
+
+		sam := createTestUser(t, queries, "sam@example.com")
+		if _, err := repo.Create(ctx, db.CreateRegistrationParams{UserID: sam.ID, RaceID: confirmed.RaceID}, nil, nil); !errors.Is(err, ErrCapacityExceeded) {
+			t.Fatalf("expected the race to be full while the team holds places, got %v", err)
+		}
+
+		if n, err := repo.ReleaseUnfilledTeams(ctx, fillBy.Add(-time.Minute)); err != nil || n != 0 {
+			t.Fatalf("expected no teams released before the deadline, got %d (err %v)", n, err)
+		}
+		if n, err := repo.ReleaseUnfilledTeams(ctx, fillBy); err != nil || n != 1 {
+			t.Fatalf("expected 1 team released, got %d (err %v)", n, err)
+		}
+
+		if _, err := repo.Create(ctx, db.CreateRegistrationParams{UserID: sam.ID, RaceID: confirmed.RaceID}, nil, nil); err != nil {
+			t.Errorf("expected released places to be free, got %v", err)
+		}
+		if _, err := repo.JoinTeam(ctx, createTestUser(t, queries, "kim@example.com").ID, created.Team.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound joining a released team, got %v", err)
+		}
+		if active, err := repo.GetActive(ctx, captain.ID, confirmed.RaceID); err != nil || active.TeamID.Int64 != created.Team.ID {
+			t.Errorf("expected the captain to keep their place, got %+v (err %v)", active, err)
+		}
+	})
+
+	t.Run("keeps bibs unique among a race's active entrants", func(t *testing.T) {
+		queries, _, confirmed := setup(t)
+		sam := createTestUser(t, queries, "sam@example.com")
+		second, err := queries.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+			UserID: sam.ID,
+			RaceID: confirmed.RaceID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create registration: %v", err)
+		}
+		repo := NewRegistrationRepository(queries, testPool)
+
+		n, err := repo.AssignBibs(ctx, []BibAssignment{{RegistrationID: confirmed.ID, Bib: "1"}})
+		if err != nil || n != 1 {
+			t.Fatalf("expected 1 bib assigned, got %d (err %v)", n, err)
+		}
+		if n, err := repo.AssignBibs(ctx, []BibAssignment{{RegistrationID: confirmed.ID, Bib: "5"}}); err != nil || n != 0 {
+			t.Errorf("expected an assigned bib to be left alone, got %d (err %v)", n, err)
+		}
+		if _, err := repo.AssignBibs(ctx, []BibAssignment{{RegistrationID: second.ID, Bib: "1"}}); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict assigning a taken bib, got %v", err)
+		}
+		if err := repo.SetBib(ctx, second.ID, "1"); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict setting a taken bib, got %v", err)
+		}
+
+		if err := repo.Cancel(ctx, confirmed.ID); err != nil {
+			t.Fatalf("failed to cancel: %v", err)
+		}
+		if err := repo.SetBib(ctx, second.ID, "1"); err != nil {
+			t.Errorf("expected a cancelled entry's bib to be free, got %v", err)
+		}
+		if err := repo.SetBib(ctx, confirmed.ID, "2"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a cancelled entry, got %v", err)
+		}
+	})
+
+	t.Run("checks entrants in one row at a time", func(t *testing.T) {
+		queries, _, confirmed := setup(t)
+		second, err := queries.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+			UserID: createTestUser(t, queries, "sam@example.com").ID,
+			RaceID: confirmed.RaceID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create registration: %v", err)
+		}
+		repo := NewRegistrationRepository(queries, testPool)
+		at := time.Now().Truncate(time.Microsecond)
+
+		// Two marshals checking in different entrants at once
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i, id := range []int64{confirmed.ID, second.ID} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = repo.CheckIn(ctx, confirmed.RaceID, id, at)
+			}()
+		}
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			t.Fatalf("failed to check in: %v", err)
+		}
+		if err := repo.CheckIn(ctx, confirmed.RaceID, confirmed.ID, at.Add(time.Minute)); err != nil {
+			t.Fatalf("failed to check in again: %v", err)
+		}
+		entrants, err := repo.ListByRace(ctx, confirmed.RaceID)
+		if err != nil {
+			t.Fatalf("failed to list entrants: %v", err)
+		}
+		for _, e := range entrants {
+			if !e.CheckedInAt.Time.Equal(at) {
+				t.Errorf("expected registration %d checked in at %v, got %+v", e.ID, at, e.CheckedInAt)
+			}
+		}
+
+		if err := repo.UndoCheckIn(ctx, confirmed.RaceID, second.ID, at); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict undoing an older check-in, got %v", err)
+		}
+		if err := repo.UndoCheckIn(ctx, confirmed.RaceID, second.ID, at.Add(-time.Minute)); err != nil {
+			t.Errorf("failed to undo check-in: %v", err)
+		}
+		if err := repo.UndoCheckIn(ctx, confirmed.RaceID, second.ID, at.Add(-time.Minute)); err != nil {
+			t.Errorf("expected undoing again to do nothing, got %v", err)
+		}
+		if err := repo.CheckIn(ctx, confirmed.RaceID+1, confirmed.ID, at); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound checking in through another race, got %v", err)
+		}
+		if err := repo.UndoCheckIn(ctx, confirmed.RaceID+1, confirmed.ID, at); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound undoing through another race, got %v", err)
+		}
+
+		if err := repo.Cancel(ctx, second.ID); err != nil {
+			t.Fatalf("failed to cancel: %v", err)
+		}
+		if err := repo.CheckIn(ctx, confirmed.RaceID, second.ID, at); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound checking in a cancelled entry, got %v", err)
+		}
+	})
+
+	t.Run("spends a discount code no more than its maximum uses", func(t *testing.T) {
+		queries, _, confirmed := setup(t)
+		race, err := queries.GetRaceByID(ctx, confirmed.RaceID)
+		if err != nil {
+			t.Fatalf("failed to load race: %v", err)
+		}
+		code, err := NewDiscountRepository(queries).Create(ctx, db.CreateDiscountCodeParams{
+			EventID:    race.EventID,
+			Code:       "TEN",
+			PercentOff: pgtype.Int4{Int32: 10, Valid: true},
+			MaxUses:    pgtype.Int4{Int32: 10, Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("failed to create discount code: %v", err)
+		}
+		var entrants []db.User
+		for i := range 11 {
+			entrants = append(entrants, createTestUser(t, queries, fmt.Sprintf("runner%d@example.com", i)))
+		}
+		repo := NewRegistrationRepository(queries, testPool)
+
+		errs := make(chan error, len(entrants))
+		for _, entrant := range entrants {
+			go func() {
+				_, err := repo.Create(ctx, db.CreateRegistrationParams{
+					UserID:         entrant.ID,
+					RaceID:         race.ID,
+					DiscountCodeID: pgtype.Int8{Int64: code.ID, Valid: true},
+					DiscountUnits:  100,
+				}, nil, nil)
+				errs <- err
+			}()
+		}
+		var created, refused int
+		for range entrants {
+			switch err := <-errs; {
+			case err == nil:
+				created++
+			case errors.Is(err, ErrDiscountExhausted):
+				refused++
+			default:
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if created != 10 || refused != 1 {
+			t.Errorf("expected 10 entries with the code and 1 refused, got %d and %d", created, refused)
+		}
+		codes, err := queries.ListDiscountCodesByEvent(ctx, race.EventID)
+		if err != nil || len(codes) != 1 || codes[0].Uses != 10 {
+			t.Errorf("expected the code used 10 times, got %+v (err %v)", codes, err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/result.go -pkg repositorymocks . ResultRepository
+
+// ResultRepository defines the interface for race result data access.
+type ResultRepository interface {
+	// ListByRace returns the race's results in finishing order, published
+	// or not.
+	ListByRace(ctx context.Context, raceID int64) ([]db.ListRaceResultsRow, error)
+	// ListEntrants returns the race's active registrations, which results
+	// may be recorded against, with each entrant's date of birth and the
+	// race's date.
+	ListEntrants(ctx context.Context, raceID int64) ([]db.ListRaceResultEntrantsRow, error)
+	// Replace swaps the race's results for the given ones in a single
+	// transaction. The new results are unpublished.
+	Replace(ctx context.Context, raceID int64, results []db.CreateRaceResultParams) error
+	// Publish makes the race's results public, returning how many were
+	// newly published.
+	Publish(ctx context.Context, raceID int64) (int64, error)
+}
+
+type resultRepository struct {
+	queries *db.Queries
+	pool    TxBeginner
+}
+
+// NewResultRepository creates a new ResultRepository backed by the given
+// queries, using pool for writes that must be atomic.
+func NewResultRepository(queries *db.Queries, pool TxBeginner) ResultRepository {
+	return &resultRepository{queries: queries, pool: pool}
+}
+
+func (r *resultRepository) ListByRace(ctx context.Context, raceID int64) ([]db.ListRaceResultsRow, error) {
+	return r.queries.ListRaceResults(ctx, raceID)
+}
+
+func (r *resultRepository) ListEntrants(ctx context.Context, raceID int64) ([]db.ListRaceResultEntrantsRow, error) {
+	return r.queries.ListRaceResultEntrants(ctx, raceID)
+}
+
+func (r *resultRepository) Replace(ctx context.Context, raceID int64, results []db.CreateRaceResultParams) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	if err := qtx.DeleteRaceResults(ctx, raceID); err != nil {
+		return err
+	}
+	for _, result := range results {
+		result.RaceID = raceID
+		if err := qtx.CreateRaceResult(ctx, result); err != nil {
+			if isUniqueViolation(err) {
+				return ErrConflict
+			}
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *resultRepository) Publish(ctx context.Context, raceID int64) (int64, error) {
+	return r.queries.PublishRaceResults(ctx, raceID)
+}
@@ -0,0 +1,149 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestResultRepository(t *testing.T) {
+	ctx := context.Background()
+
+	// setup creates a race with one registration held by jane@example.com
+	// with bib 101.
+	setup := func(t *testing.T) (*db.Queries, db.Race, db.Registration) {
+		t.Helper()
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+			OrganisationID: org.ID,
+			Name:           "Lincoln 10k",
+			Slug:           "lincoln-10k",
+			Year:           2026,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+			EventID:     event.ID,
+			Name:        "10K",
+			Slug:        "10k",
+			MaxCapacity: 300,
+		})
+		if err != nil {
+			t.Fatalf("failed to create race: %v", err)
+		}
+		user := createTestUser(t, queries, "jane@example.com")
+		reg, err := queries.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+			UserID: user.ID,
+			RaceID: race.ID,
+			Bib:    pgtype.Text{String: "101", Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("failed to create registration: %v", err)
+		}
+		return queries, race, reg
+	}
+
+	t.Run("replaces results and lists them in finishing order", func(t *testing.T) {
+		queries, race, reg := setup(t)
+		repo := NewResultRepository(queries, testPool)
+
+		if err := repo.Replace(ctx, race.ID, []db.CreateRaceResultParams{{Name: "Old Result", Position: 1}}); err != nil {
+			t.Fatalf("failed to save results: %v", err)
+		}
+		err := repo.Replace(ctx, race.ID, []db.CreateRaceResultParams{
+			{Name: "Ola Nordmann", Bib: "501", Position: 2, FinishSeconds: 2102, Category: "M40"},
+			{RegistrationID: pgtype.Int8{Int64: reg.ID, Valid: true}, Name: "ignored", Position: 1, FinishSeconds: 2050},
+		})
+		if err != nil {
+			t.Fatalf("failed to replace results: %v", err)
+		}
+
+		results, err := repo.ListByRace(ctx, race.ID)
+		if err != nil {
+			t.Fatalf("failed to list results: %v", err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results, got %+v", results)
+		}
+		if got := results[0]; got.Position != 1 || got.Name != "Jane Runner" || got.Bib != "101" || got.Published {
+			t.Errorf("expected the registered runner first, unpublished, got %+v", got)
+		}
+		if got := results[1]; got.Name != "Ola Nordmann" || got.Bib != "501" || got.Category != "M40" {
+			t.Errorf("unexpected second result: %+v", got)
+		}
+	})
+
+	t.Run("lists the race's active registrations", func(t *testing.T) {
+		queries, race, reg := setup(t)
+		dob := pgtype.Date{Time: time.Date(1980, time.May, 2, 0, 0, 0, 0, time.UTC), Valid: true}
+		if _, err := queries.UpdateUserProfile(ctx, db.UpdateUserProfileParams{ID: reg.UserID, FirstName: "Jane", LastName: "Runner", DateOfBirth: dob}); err != nil {
+			t.Fatalf("failed to set date of birth: %v", err)
+		}
+
+		entrants, err := NewResultRepository(queries, testPool).ListEntrants(ctx, race.ID)
+		if err != nil {
+			t.Fatalf("failed to list registrations: %v", err)
+		}
+		if len(entrants) != 1 || entrants[0].ID != reg.ID {
+			t.Fatalf("expected registration %d, got %+v", reg.ID, entrants)
+		}
+		// The race is not scheduled, so has no date yet
+		if got := entrants[0]; !got.DateOfBirth.Time.Equal(dob.Time) || got.RaceDay.Valid {
+			t.Errorf("expected the entrant's date of birth and no race day, got %+v", got)
+		}
+	})
+
+	t.Run("keeps the old results when a position is repeated", func(t *testing.T) {
+		queries, race, _ := setup(t)
+		repo := NewResultRepository(queries, testPool)
+		if err := repo.Replace(ctx, race.ID, []db.CreateRaceResultParams{{Name: "Ola", Position: 1}}); err != nil {
+			t.Fatalf("failed to save results: %v", err)
+		}
+
+		err := repo.Replace(ctx, race.ID, []db.CreateRaceResultParams{{Name: "Tom", Position: 1}, {Name: "Sam", Position: 1}})
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("expected ErrConflict, got %v", err)
+		}
+
+		results, err := repo.ListByRace(ctx, race.ID)
+		if err != nil {
+			t.Fatalf("failed to list results: %v", err)
+		}
+		if len(results) != 1 || results[0].Name != "Ola" {
+			t.Errorf("expected the upload to be rolled back, got %+v", results)
+		}
+	})
+
+	t.Run("publishes a race's results once", func(t *testing.T) {
+		queries, race, _ := setup(t)
+		repo := NewResultRepository(queries, testPool)
+		if err := repo.Replace(ctx, race.ID, []db.CreateRaceResultParams{{Name: "Ola", Position: 1}, {Name: "Tom", Position: 2}}); err != nil {
+			t.Fatalf("failed to save results: %v", err)
+		}
+
+		if n, err := repo.Publish(ctx, race.ID); err != nil || n != 2 {
+			t.Fatalf("expected 2 results published, got %d (err %v)", n, err)
+		}
+		if n, err := repo.Publish(ctx, race.ID); err != nil || n != 0 {
+			t.Errorf("expected nothing left to publish, got %d (err %v)", n, err)
+		}
+		results, err := repo.ListByRace(ctx, race.ID)
+		if err != nil {
+			t.Fatalf("failed to list results: %v", err)
+		}
+		for _, result := range results {
+			if !result.Published {
+				t.Errorf("expected result %d to be published", result.Position)
+			}
+		}
+	})
+}
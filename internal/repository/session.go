@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/session.go -pkg repositorymocks . SessionRepository
+
+// SessionRepository defines the interface for user session data access.
+type SessionRepository interface {
+	// Create records a session the user has signed in to.
+	Create(ctx context.Context, params db.CreateUserSessionParams) (db.UserSession, error)
+	// CreateWithin records a session the user has signed in to, keeping
+	// them to limit unrevoked, unexpired sessions. When they already have
+	// limit, evict revokes the least recently active to make room, marked
+	// as evicted; otherwise nothing is recorded and ErrSessionLimit is
+	// returned. The user's sign-ins are counted one at a time.
+	CreateWithin(ctx context.Context, params db.CreateUserSessionParams, limit int, evict bool) (db.UserSession, error)
+	// GetByID returns the session, revoked or expired ones included, or
+	// ErrNotFound if there is none.
+	GetByID(ctx context.Context, id int64) (db.UserSession, error)
+	// ListForUser returns the user's unrevoked, unexpired sessions, most
+	// recently active first.
+	ListForUser(ctx context.Context, userID int64) ([]db.UserSession, error)
+	// ListForExport returns up to limit of the user's sessions with an id
+	// above afterID, revoked and expired ones included, in id order.
+	ListForExport(ctx context.Context, userID, afterID int64, limit int) ([]db.UserSession, error)
+	// Touch records activity in the session.
+	Touch(ctx context.Context, id int64) error
+	// Revoke revokes the user's session. It returns ErrNotFound if the
+	// user has no such unrevoked session.
+	Revoke(ctx context.Context, userID, id int64) error
+	// RevokeOthers revokes every session of the user's except keepID,
+	// returning how many were revoked.
+	RevokeOthers(ctx context.Context, userID, keepID int64) (int64, error)
+	// RevokeAll revokes every session of the user's and moves their
+	// session version on, so sessions held only in cookies end too. It
+	// returns how many recorded sessions were revoked.
+	RevokeAll(ctx context.Context, userID int64) (int64, error)
+}
+
+type sessionRepository struct {
+	queries *db.Queries
+	pool    TxBeginner
+}
+
+// NewSessionRepository creates a new SessionRepository backed by the given queries.
+func NewSessionRepository(queries *db.Queries, pool TxBeginner) SessionRepository {
+	return &sessionRepository{queries: queries, pool: pool}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, params db.CreateUserSessionParams) (db.UserSession, error) {
+	return r.queries.CreateUserSession(ctx, params)
+}
+
+func (r *sessionRepository) CreateWithin(ctx context.Context, params db.CreateUserSessionParams, limit int, evict bool) (db.UserSession, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return db.UserSession{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	if err := qtx.LockUserSessions(ctx, params.UserID); err != nil {
+		return db.UserSession{}, err
+	}
+	if evict {
+		if _, err := qtx.EvictUserSessions(ctx, db.EvictUserSessionsParams{UserID: params.UserID, Keep: int64(limit - 1)}); err != nil {
+			return db.UserSession{}, err
+		}
+	} else {
+		active, err := qtx.CountActiveUserSessions(ctx, params.UserID)
+		if err != nil {
+			return db.UserSession{}, err
+		}
+		if active >= int64(limit) {
+			return db.UserSession{}, ErrSessionLimit
+		}
+	}
+	session, err := qtx.CreateUserSession(ctx, params)
+	if err != nil {
+		return db.UserSession{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return db.UserSession{}, err
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id int64) (db.UserSession, error) {
+	session, err := r.queries.GetUserSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.UserSession{}, ErrNotFound
+		}
+		return db.UserSession{}, err
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) ListForUser(ctx context.Context, userID int64) ([]db.UserSession, error) {
+	return r.queries.ListUserSessionsForUser(ctx, userID)
+}
+
+func (r *sessionRepository) ListForExport(ctx context.Context, userID, afterID int64, limit int) ([]db.UserSession, error) {
+	return r.queries.ExportUserSessions(ctx, db.ExportUserSessionsParams{UserID: userID, AfterID: afterID, MaxRows: int32(limit)})
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, id int64) error {
+	return r.queries.TouchUserSession(ctx, id)
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, userID, id int64) error {
+	n, err := r.queries.RevokeUserSession(ctx, db.RevokeUserSessionParams{ID: id, UserID: userID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sessionRepository) RevokeOthers(ctx context.Context, userID, keepID int64) (int64, error) {
+	return r.queries.RevokeOtherUserSessions(ctx, db.RevokeOtherUserSessionsParams{UserID: userID, ID: keepID})
+}
+
+func (r *sessionRepository) RevokeAll(ctx context.Context, userID int64) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	// No session has id 0, so none is kept
+	qtx := r.queries.WithTx(tx)
+	n, err := qtx.RevokeOtherUserSessions(ctx, db.RevokeOtherUserSessionsParams{UserID: userID, ID: 0})
+	if err != nil {
+		return 0, err
+	}
+	if err := qtx.BumpUserSessionVersion(ctx, userID); err != nil {
+		return 0, err
+	}
+	return n, tx.Commit(ctx)
+}
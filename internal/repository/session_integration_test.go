@@ -0,0 +1,222 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/pgxstore"
+	"github.com/alexedwards/scs/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/session"
+)
+
+func TestSessionRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists sessions still in use and revokes the others", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "runner@example.com")
+		other := createTestUser(t, queries, "other@example.com")
+		repo := NewSessionRepository(queries, testPool)
+
+		create := func(userID int64, expiresAt time.Time) db.UserSession {
+			t.Helper()
+			session, err := repo.Create(ctx, db.CreateUserSessionParams{
+				UserID:    userID,
+				UserAgent: "Mozilla/5.0",
+				IpAddress: "203.0.113.9",
+				ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+			})
+			if err != nil {
+				t.Fatalf("failed to create session: %v", err)
+			}
+			return session
+		}
+		current := create(user.ID, time.Now().Add(time.Hour))
+		laptop := create(user.ID, time.Now().Add(time.Hour))
+		phone := create(user.ID, time.Now().Add(time.Hour))
+		create(user.ID, time.Now().Add(-time.Hour))
+		otherUsers := create(other.ID, time.Now().Add(time.Hour))
+
+		if err := repo.Revoke(ctx, other.ID, laptop.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound revoking another user's session, got %v", err)
+		}
+		if err := repo.Revoke(ctx, user.ID, laptop.ID); err != nil {
+			t.Fatalf("failed to revoke session: %v", err)
+		}
+		if err := repo.Revoke(ctx, user.ID, laptop.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound revoking a session twice, got %v", err)
+		}
+
+		sessions, err := repo.ListForUser(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to list sessions: %v", err)
+		}
+		if len(sessions) != 2 {
+			t.Fatalf("expected the current and phone sessions, got %+v", sessions)
+		}
+
+		n, err := repo.RevokeOthers(ctx, user.ID, current.ID)
+		if err != nil {
+			t.Fatalf("failed to revoke other sessions: %v", err)
+		}
+		// The expired session is revoked too, though it was no longer listed
+		if n != 2 {
+			t.Errorf("expected 2 sessions revoked, got %d", n)
+		}
+		if sessions, _ = repo.ListForUser(ctx, user.ID); len(sessions) != 1 || sessions[0].ID != current.ID {
+			t.Errorf("expected only the current session, got %+v", sessions)
+		}
+		if session, _ := repo.GetByID(ctx, phone.ID); !session.RevokedAt.Valid {
+			t.Error("expected the phone session to be revoked")
+		}
+		if session, _ := repo.GetByID(ctx, otherUsers.ID); session.RevokedAt.Valid {
+			t.Error("expected other users' sessions to be kept")
+		}
+		if _, err := repo.GetByID(ctx, otherUsers.ID+100); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown session, got %v", err)
+		}
+	})
+	t.Run("keeps users within a session limit", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "runner@example.com")
+		repo := NewSessionRepository(queries, testPool)
+		params := db.CreateUserSessionParams{
+			UserID:    user.ID,
+			UserAgent: "Mozilla/5.0",
+			IpAddress: "203.0.113.9",
+			ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		}
+		signIn := func(evict bool) (db.UserSession, error) {
+			t.Helper()
+			return repo.CreateWithin(ctx, params, 2, evict)
+		}
+
+		first, err := signIn(true)
+		if err != nil {
+			t.Fatalf("failed to sign in: %v", err)
+		}
+		second, err := signIn(true)
+		if err != nil {
+			t.Fatalf("failed to sign in: %v", err)
+		}
+		// The first session is used after the second, so the second is
+		// the least recently active
+		if _, err := testPool.Exec(ctx, "UPDATE user_sessions SET last_seen_at = NOW() + INTERVAL '1 minute' WHERE id = $1", first.ID); err != nil {
+			t.Fatalf("failed to touch session: %v", err)
+		}
+
+		if _, err := signIn(false); !errors.Is(err, ErrSessionLimit) {
+			t.Errorf("expected ErrSessionLimit refusing a third session, got %v", err)
+		}
+		if sessions, _ := repo.ListForUser(ctx, user.ID); len(sessions) != 2 {
+			t.Errorf("expected the refused sign-in to leave two sessions, got %+v", sessions)
+		}
+
+		third, err := signIn(true)
+		if err != nil {
+			t.Fatalf("failed to sign in: %v", err)
+		}
+		evicted, _ := repo.GetByID(ctx, second.ID)
+		if !evicted.RevokedAt.Valid || !evicted.EvictedAt.Valid {
+			t.Errorf("expected the least recently active session evicted, got %+v", evicted)
+		}
+		sessions, err := repo.ListForUser(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to list sessions: %v", err)
+		}
+		if len(sessions) != 2 || sessions[0].ID != first.ID || sessions[1].ID != third.ID {
+			t.Errorf("expected the first and third sessions kept, got %+v", sessions)
+		}
+	})
+
+	t.Run("revokes every session and moves the version on", func(t *testing.T) {
+		queries := resetDB(t)
+		user := createTestUser(t, queries, "runner@example.com")
+		repo := NewSessionRepository(queries, testPool)
+		for range 2 {
+			if _, err := repo.Create(ctx, db.CreateUserSessionParams{
+				UserID:    user.ID,
+				UserAgent: "Mozilla/5.0",
+				IpAddress: "203.0.113.9",
+				ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+			}); err != nil {
+				t.Fatalf("failed to create session: %v", err)
+			}
+		}
+
+		n, err := repo.RevokeAll(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to revoke sessions: %v", err)
+		}
+		if n != 2 {
+			t.Errorf("expected 2 sessions revoked, got %d", n)
+		}
+		if sessions, _ := repo.ListForUser(ctx, user.ID); len(sessions) != 0 {
+			t.Errorf("expected no sessions left, got %+v", sessions)
+		}
+		got, err := queries.GetUser(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if got.SessionVersion != user.SessionVersion+1 {
+			t.Errorf("expected session version %d, got %d", user.SessionVersion+1, got.SessionVersion)
+		}
+	})
+}
+
+// BenchmarkSessionBackends compares reading a signed-in session from the
+// Postgres store with reading it from a cookie, as each request does.
+func BenchmarkSessionBackends(b *testing.B) {
+	ctx := context.Background()
+	resetDB(b)
+	values := map[string]any{
+		"userID":         int64(7),
+		"sessionID":      int64(42),
+		"expiresAt":      time.Now().Add(time.Hour).Unix(),
+		"sessionVersion": int32(0),
+	}
+
+	b.Run("postgres", func(b *testing.B) {
+		store := pgxstore.NewWithCleanupInterval(testPool, 0)
+		codec := scs.GobCodec{}
+		encoded, err := codec.Encode(time.Now().Add(time.Hour), values)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := store.CommitCtx(ctx, "benchmark-token", encoded, time.Now().Add(time.Hour)); err != nil {
+			b.Fatal(err)
+		}
+		for b.Loop() {
+			found, ok, err := store.FindCtx(ctx, "benchmark-token")
+			if err != nil || !ok {
+				b.Fatalf("expected the session, got %v", err)
+			}
+			if _, _, err := codec.Decode(found); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cookie", func(b *testing.B) {
+		m, err := session.NewCookieManager([]byte("0123456789abcdef0123456789abcdef"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		value, err := m.Encode(time.Now().Add(time.Hour), values)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for b.Loop() {
+			if _, _, err := m.Decode(value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"firecrest/db"
+)
+
+// DefaultQueryTimeout is how long a database call may take before it is
+// abandoned with ErrTimeout.
+const DefaultQueryTimeout = 3 * time.Second
+
+// timeoutDB bounds each call made through it and retries reads that fail
+// on a transient connection error.
+type timeoutDB struct {
+	conn    db.DBTX
+	timeout time.Duration
+}
+
+// NewTimeoutDB wraps conn so each call gives up after timeout, failing with
+// ErrTimeout. A read-only query that fails on a transient connection error
+// is tried once more within the same timeout; writes never are, as the
+// first attempt may have been applied. A timeout of zero or less means
+// DefaultQueryTimeout.
+//
+// Transactions bypass the wrapper: a failed statement aborts the
+// transaction, so there is nothing to retry.
+func NewTimeoutDB(conn db.DBTX, timeout time.Duration) db.DBTX {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	return &timeoutDB{conn: conn, timeout: timeout}
+}
+
+func (t *timeoutDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	tag, err := t.conn.Exec(ctx, sql, args...)
+	return tag, timeoutError(err)
+}
+
+func (t *timeoutDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+
+	rows, err := t.conn.Query(ctx, sql, args...)
+	if shouldRetry(ctx, sql, err) {
+		rows, err = t.conn.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		cancel()
+		return nil, timeoutError(err)
+	}
+	// The rows are read after Query returns, so the timeout lasts until
+	// they are closed
+	return &timeoutRows{Rows: rows, cancel: cancel}, nil
+}
+
+// QueryRow defers the query to Scan, which is where pgx reports its errors
+// and so where a failed read can be retried.
+func (t *timeoutDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &timeoutRow{db: t, ctx: ctx, sql: sql, args: args}
+}
+
+// timeoutRows ends the timeout of the query that produced them when closed.
+type timeoutRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+func (r *timeoutRows) Err() error {
+	return timeoutError(r.Rows.Err())
+}
+
+// timeoutRow runs its query when scanned.
+type timeoutRow struct {
+	db   *timeoutDB
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (r *timeoutRow) Scan(dest ...any) error {
+	ctx, cancel := context.WithTimeout(r.ctx, r.db.timeout)
+	defer cancel()
+
+	err := r.db.conn.QueryRow(ctx, r.sql, r.args...).Scan(dest...)
+	if shouldRetry(ctx, r.sql, err) {
+		err = r.db.conn.QueryRow(ctx, r.sql, r.args...).Scan(dest...)
+	}
+	return timeoutError(err)
+}
+
+// shouldRetry reports whether a call to sql that failed with err may be
+// tried again: it only read, failed on a transient connection error, and
+// still has time left.
+func shouldRetry(ctx context.Context, sql string, err error) bool {
+	if err == nil || ctx.Err() != nil || !isReadOnly(sql) {
+		return false
+	}
+	return pgconn.SafeToRetry(err) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// isReadOnly reports whether sql only reads. Only plain SELECTs that take no
+// row locks count; a WITH may hide a write, so it is treated as one.
+func isReadOnly(sql string) bool {
+	statement := strings.TrimSpace(sql)
+	// sqlc starts each query with a "-- name:" comment
+	for strings.HasPrefix(statement, "--") {
+		_, rest, _ := strings.Cut(statement, "\n")
+		statement = strings.TrimSpace(rest)
+	}
+	return strings.HasPrefix(strings.ToUpper(statement), "SELECT") && !rowLock.MatchString(statement)
+}
+
+// rowLock matches the locking clauses of a SELECT.
+var rowLock = regexp.MustCompile(`(?i)\bFOR\s+(NO\s+KEY\s+UPDATE|UPDATE|KEY\s+SHARE|SHARE)\b`)
+
+// timeoutError wraps err in ErrTimeout when the call ran out of time.
+func timeoutError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if pgconn.Timeout(err) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	readSQL  = "-- name: GetEventByID :one\nSELECT * from events\nWHERE id = $1 LIMIT 1"
+	writeSQL = "-- name: DeleteEvent :exec\nDELETE from events\nWHERE id = $1"
+)
+
+// errConnReset is how a connection dropped mid-call surfaces from pgx.
+var errConnReset = &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+// stubDBTX answers every call by running call, counting how many it gets.
+type stubDBTX struct {
+	calls int
+	call  func(ctx context.Context, attempt int) error
+}
+
+func (s *stubDBTX) run(ctx context.Context) error {
+	s.calls++
+	return s.call(ctx, s.calls)
+}
+
+func (s *stubDBTX) Exec(ctx context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, s.run(ctx)
+}
+
+func (s *stubDBTX) Query(ctx context.Context, _ string, _ ...any) (pgx.Rows, error) {
+	if err := s.run(ctx); err != nil {
+		return nil, err
+	}
+	return &stubRows{}, nil
+}
+
+func (s *stubDBTX) QueryRow(ctx context.Context, _ string, _ ...any) pgx.Row {
+	return stubRow{err: s.run(ctx)}
+}
+
+type stubRow struct{ err error }
+
+func (r stubRow) Scan(...any) error { return r.err }
+
+// stubRows is an empty result set.
+type stubRows struct {
+	pgx.Rows
+	closed bool
+}
+
+func (r *stubRows) Next() bool { return false }
+func (r *stubRows) Err() error { return nil }
+func (r *stubRows) Close()     { r.closed = true }
+
+// sleepPast blocks until the call's deadline passes or a minute is up.
+func sleepPast(ctx context.Context, _ int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Minute):
+		return nil
+	}
+}
+
+// failOnce fails the first call with a dropped connection.
+func failOnce(_ context.Context, attempt int) error {
+	if attempt == 1 {
+		return errConnReset
+	}
+	return nil
+}
+
+func TestTimeoutDB(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("gives up on slow calls with ErrTimeout", func(t *testing.T) {
+		calls := map[string]func(conn *stubDBTX) error{
+			"Exec": func(conn *stubDBTX) error {
+				_, err := NewTimeoutDB(conn, 10*time.Millisecond).Exec(ctx, writeSQL, 1)
+				return err
+			},
+			"Query": func(conn *stubDBTX) error {
+				_, err := NewTimeoutDB(conn, 10*time.Millisecond).Query(ctx, readSQL, 1)
+				return err
+			},
+			"QueryRow": func(conn *stubDBTX) error {
+				return NewTimeoutDB(conn, 10*time.Millisecond).QueryRow(ctx, readSQL, 1).Scan()
+			},
+		}
+		for name, call := range calls {
+			t.Run(name, func(t *testing.T) {
+				conn := &stubDBTX{call: sleepPast}
+				start := time.Now()
+				err := call(conn)
+				if !errors.Is(err, ErrTimeout) {
+					t.Fatalf("expected ErrTimeout, got %v", err)
+				}
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expected the cause to be kept, got %v", err)
+				}
+				if elapsed := time.Since(start); elapsed > time.Second {
+					t.Errorf("expected the call to be abandoned at its deadline, took %v", elapsed)
+				}
+				if conn.calls != 1 {
+					t.Errorf("expected a timed out call not to be retried, got %d calls", conn.calls)
+				}
+			})
+		}
+	})
+
+	t.Run("retries reads that fail on a dropped connection", func(t *testing.T) {
+		conn := &stubDBTX{call: failOnce}
+		if err := NewTimeoutDB(conn, time.Second).QueryRow(ctx, readSQL, 1).Scan(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if conn.calls != 2 {
+			t.Errorf("expected the read to be tried twice, got %d calls", conn.calls)
+		}
+
+		conn = &stubDBTX{call: failOnce}
+		rows, err := NewTimeoutDB(conn, time.Second).Query(ctx, readSQL, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows.Close()
+		if conn.calls != 2 {
+			t.Errorf("expected the query to be tried twice, got %d calls", conn.calls)
+		}
+	})
+
+	t.Run("retries a read only once", func(t *testing.T) {
+		conn := &stubDBTX{call: func(context.Context, int) error { return errConnReset }}
+		err := NewTimeoutDB(conn, time.Second).QueryRow(ctx, readSQL, 1).Scan()
+		if !errors.Is(err, syscall.ECONNRESET) {
+			t.Errorf("expected the connection error, got %v", err)
+		}
+		if conn.calls != 2 {
+			t.Errorf("expected two attempts, got %d", conn.calls)
+		}
+	})
+
+	t.Run("never retries writes", func(t *testing.T) {
+		conn := &stubDBTX{call: failOnce}
+		if _, err := NewTimeoutDB(conn, time.Second).Exec(ctx, writeSQL, 1); !errors.Is(err, syscall.ECONNRESET) {
+			t.Errorf("expected the connection error, got %v", err)
+		}
+		if conn.calls != 1 {
+			t.Errorf("expected Exec to be tried once, got %d calls", conn.calls)
+		}
+
+		// Writes returning rows go through QueryRow
+		conn = &stubDBTX{call: failOnce}
+		insert := "-- name: CreateEvent :one\nINSERT INTO events (name) VALUES ($1)\nRETURNING *"
+		if err := NewTimeoutDB(conn, time.Second).QueryRow(ctx, insert, "Run").Scan(); !errors.Is(err, syscall.ECONNRESET) {
+			t.Errorf("expected the connection error, got %v", err)
+		}
+		if conn.calls != 1 {
+			t.Errorf("expected the insert to be tried once, got %d calls", conn.calls)
+		}
+	})
+
+	t.Run("passes other errors through", func(t *testing.T) {
+		conn := &stubDBTX{call: func(context.Context, int) error { return pgx.ErrNoRows }}
+		err := NewTimeoutDB(conn, time.Second).QueryRow(ctx, readSQL, 1).Scan()
+		if !errors.Is(err, pgx.ErrNoRows) || errors.Is(err, ErrTimeout) {
+			t.Errorf("expected ErrNoRows, got %v", err)
+		}
+		if conn.calls != 1 {
+			t.Errorf("expected no retry, got %d calls", conn.calls)
+		}
+	})
+
+	t.Run("closing rows closes the underlying rows", func(t *testing.T) {
+		conn := &stubDBTX{call: func(context.Context, int) error { return nil }}
+		rows, err := NewTimeoutDB(conn, time.Second).Query(ctx, readSQL, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows.Close()
+		if !rows.(*timeoutRows).Rows.(*stubRows).closed {
+			t.Error("expected the underlying rows to be closed")
+		}
+	})
+}
+
+func TestIsReadOnly(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{readSQL, true},
+		{"select count(*) from races", true},
+		{writeSQL, false},
+		{"-- name: GetRaceForUpdate :one\nSELECT * from races\nWHERE id = $1\nFOR UPDATE;", false},
+		{"SELECT * from teams WHERE id = $1 FOR NO KEY UPDATE", false},
+		{"SELECT * from teams WHERE id = $1 for share", false},
+		{"WITH stats AS (SELECT 1) SELECT * FROM stats", false},
+		{"UPDATE races SET name = $1", false},
+	}
+	for _, tt := range tests {
+		if got := isReadOnly(tt.sql); got != tt.want {
+			t.Errorf("isReadOnly(%q) = %v, want %v", tt.sql, got, tt.want)
+		}
+	}
+}
@@ -2,26 +2,95 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"firecrest/db"
 )
 
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/user.go -pkg repositorymocks . UserRepository
+
 // UserRepository defines the interface for user data access.
 type UserRepository interface {
 	GetByID(ctx context.Context, id int64) (db.User, error)
 	Create(ctx context.Context, params db.CreateUserParams) (db.User, error)
+	// Anonymise replaces the user's personal details, removes their
+	// credentials, API tokens, linked accounts, memberships and pending
+	// invitations, ends their sessions and marks them anonymised, all in one
+	// transaction. Their registrations are kept, without their questionnaire
+	// answers. It returns ErrNotFound if the user does not exist or has
+	// already been anonymised. Copies of their data they have asked for
+	// expire, to be removed from the blob store.
+	Anonymise(ctx context.Context, id int64) error
+	// SetEmailPreference sets which optional emails the user receives. It
+	// returns ErrNotFound if the user does not exist or has been deleted.
+	SetEmailPreference(ctx context.Context, id int64, pref db.EmailPreference) error
+	// ListUsers returns up to limit of the users matching filter, newest
+	// first, skipping the first offset, with how their sign-ins are going.
+	ListUsers(ctx context.Context, filter UserFilter, limit, offset int32) ([]db.ListUsersRow, error)
+	// CountUsers returns how many users match filter.
+	CountUsers(ctx context.Context, filter UserFilter) (int64, error)
+	// UpdateRole sets the user's site role and records the change in the
+	// audit log as made by actorID. Setting the role they already have
+	// changes nothing. It returns ErrNotFound if the user does not exist or
+	// has been deleted.
+	UpdateRole(ctx context.Context, id int64, role db.UserRole, actorID int64) error
+	// SetActive deactivates or reactivates the user and records the change
+	// in the audit log as made by actorID. Deactivating also ends the
+	// user's sessions. Asking for the state the user is already in changes
+	// nothing. It returns ErrNotFound if the user does not exist or has
+	// been deleted.
+	SetActive(ctx context.Context, id int64, active bool, actorID int64) error
+	// UpdateProfile sets the user's name and date of birth. It returns
+	// ErrNotFound if the user does not exist or has been deleted.
+	UpdateProfile(ctx context.Context, params db.UpdateUserProfileParams) (db.User, error)
+	// AuditImpersonation records in the audit log, against the user, an
+	// impersonation action taken by actorID, with detail, if not nil, as
+	// its changed_fields.
+	AuditImpersonation(ctx context.Context, id, actorID int64, action db.AuditAction, detail any) error
+	// ListAuditEntriesForExport returns up to limit of the audit entries
+	// for changes the user made with an id above afterID, in id order.
+	ListAuditEntriesForExport(ctx context.Context, id, afterID int64, limit int) ([]db.AuditLog, error)
+}
+
+// UserFilter narrows the users listed to those matching all of its fields
+// that are set.
+type UserFilter struct {
+	// Query keeps users whose email or full name contains it, ignoring case
+	Query string
+	Role  db.UserRole
 }
 
+// pattern returns the ILIKE pattern matching the filter's query anywhere,
+// or an empty string to match every user.
+func (f UserFilter) pattern() string {
+	if f.Query == "" {
+		return ""
+	}
+	return "%" + likeEscaper.Replace(f.Query) + "%"
+}
+
+func (f UserFilter) role() db.NullUserRole {
+	return db.NullUserRole{UserRole: f.Role, Valid: f.Role != ""}
+}
+
+// likeEscaper escapes the characters LIKE treats specially, so that a
+// query matches only itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type userRepository struct {
 	queries *db.Queries
+	pool    TxBeginner
 }
 
-// NewUserRepository creates a new UserRepository backed by the given queries.
-func NewUserRepository(queries *db.Queries) UserRepository {
-	return &userRepository{queries: queries}
+// NewUserRepository creates a new UserRepository backed by the given
+// queries, using pool for writes that must be atomic.
+func NewUserRepository(queries *db.Queries, pool TxBeginner) UserRepository {
+	return &userRepository{queries: queries, pool: pool}
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id int64) (db.User, error) {
@@ -36,5 +105,220 @@ func (r *userRepository) GetByID(ctx context.Context, id int64) (db.User, error)
 }
 
 func (r *userRepository) Create(ctx context.Context, params db.CreateUserParams) (db.User, error) {
-	return r.queries.CreateUser(ctx, params)
+	user, err := r.queries.CreateUser(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.User{}, ErrConflict
+		}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+func (r *userRepository) Anonymise(ctx context.Context, id int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	n, err := qtx.AnonymiseUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	for _, remove := range []func(context.Context, int64) error{
+		qtx.DeleteUserCredentials,
+		qtx.DeleteUserVerificationTokens,
+		qtx.DeleteUserAPITokens,
+		qtx.DeleteUserSessions,
+		qtx.BumpUserSessionVersion,
+		qtx.DeleteUserLoginEvents,
+		qtx.ExpireUserDataExports,
+		qtx.DeleteUserRegistrationAnswers,
+		qtx.DeleteUserRegistrationCustomAnswers,
+		qtx.DeleteUserSocialAccounts,
+		qtx.RemoveUserMemberships,
+		qtx.DeletePendingInvitationsForUser,
+	} {
+		if err := remove(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *userRepository) SetEmailPreference(ctx context.Context, id int64, pref db.EmailPreference) error {
+	n, err := r.queries.SetEmailPreference(ctx, db.SetEmailPreferenceParams{ID: id, EmailPreference: pref})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) ListUsers(ctx context.Context, filter UserFilter, limit, offset int32) ([]db.ListUsersRow, error) {
+	return r.queries.ListUsers(ctx, db.ListUsersParams{
+		Search:     filter.pattern(),
+		Role:       filter.role(),
+		MaxResults: limit,
+		Skip:       offset,
+	})
+}
+
+func (r *userRepository) CountUsers(ctx context.Context, filter UserFilter) (int64, error) {
+	return r.queries.CountUsers(ctx, db.CountUsersParams{Search: filter.pattern(), Role: filter.role()})
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id int64, role db.UserRole, actorID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	user, err := getLiveUser(ctx, qtx, id)
+	if err != nil {
+		return err
+	}
+	if user.Role == role {
+		return nil
+	}
+	n, err := qtx.SetUserRole(ctx, db.SetUserRoleParams{ID: id, Role: role})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	if err := auditUserChange(ctx, qtx, id, actorID, "role", user.Role, role); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *userRepository) SetActive(ctx context.Context, id int64, active bool, actorID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	user, err := getLiveUser(ctx, qtx, id)
+	if err != nil {
+		return err
+	}
+	if active != user.DeactivatedAt.Valid {
+		return nil
+	}
+
+	if active {
+		n, err := qtx.ReactivateUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		err = auditUserChange(ctx, qtx, id, actorID, "deactivated_at", user.DeactivatedAt.Time, nil)
+		if err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	deactivatedAt, err := qtx.DeactivateUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := qtx.DeleteUserSessions(ctx, id); err != nil {
+		return err
+	}
+	if err := qtx.BumpUserSessionVersion(ctx, id); err != nil {
+		return err
+	}
+	if err := auditUserChange(ctx, qtx, id, actorID, "deactivated_at", nil, deactivatedAt.Time); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// getLiveUser returns the user unless they do not exist or have been
+// deleted, in which case it returns ErrNotFound.
+func getLiveUser(ctx context.Context, q *db.Queries, id int64) (db.User, error) {
+	user, err := q.GetUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrNotFound
+		}
+		return db.User{}, err
+	}
+	if user.DeletedAt.Valid {
+		return db.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+// auditUserChange records in the audit log that actorID changed one field
+// of the user from one value to another.
+func auditUserChange(ctx context.Context, q *db.Queries, id, actorID int64, field string, from, to any) error {
+	changed, err := json.Marshal(map[string]auditChange{field: {Old: from, New: to}})
+	if err != nil {
+		return err
+	}
+	return q.CreateAuditLogEntry(ctx, db.CreateAuditLogEntryParams{
+		TableName:     "users",
+		RecordID:      id,
+		Action:        db.AuditActionUpdated,
+		UserID:        pgtype.Int8{Int64: actorID, Valid: true},
+		ChangedFields: changed,
+	})
+}
+
+func (r *userRepository) UpdateProfile(ctx context.Context, params db.UpdateUserProfileParams) (db.User, error) {
+	user, err := r.queries.UpdateUserProfile(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrNotFound
+		}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+func (r *userRepository) AuditImpersonation(ctx context.Context, id, actorID int64, action db.AuditAction, detail any) error {
+	var changed []byte
+	if detail != nil {
+		var err error
+		if changed, err = json.Marshal(detail); err != nil {
+			return err
+		}
+	}
+	return r.queries.CreateAuditLogEntry(ctx, db.CreateAuditLogEntryParams{
+		TableName:     "users",
+		RecordID:      id,
+		Action:        action,
+		UserID:        pgtype.Int8{Int64: actorID, Valid: true},
+		ChangedFields: changed,
+	})
+}
+
+func (r *userRepository) ListAuditEntriesForExport(ctx context.Context, id, afterID int64, limit int) ([]db.AuditLog, error) {
+	return r.queries.ExportUserAuditEntries(ctx, db.ExportUserAuditEntriesParams{UserID: id, AfterID: afterID, MaxRows: int32(limit)})
 }
@@ -0,0 +1,435 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates and gets a user", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewUserRepository(queries, testPool)
+
+		created, err := repo.Create(ctx, db.CreateUserParams{
+			Email:     "jane@example.com",
+			FirstName: "Jane",
+			LastName:  "Runner",
+			City:      pgtype.Text{String: "Sheffield", Valid: true},
+			Role:      db.UserRoleOrganizer,
+		})
+		if err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+
+		user, err := repo.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if user.Email != "jane@example.com" || user.Role != db.UserRoleOrganizer || user.City.String != "Sheffield" {
+			t.Errorf("unexpected user: %+v", user)
+		}
+		if user.Phone.Valid {
+			t.Errorf("expected no phone number, got %q", user.Phone.String)
+		}
+	})
+
+	t.Run("returns ErrNotFound for a missing user", func(t *testing.T) {
+		queries := resetDB(t)
+
+		if _, err := NewUserRepository(queries, testPool).GetByID(ctx, 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrConflict for a duplicate email", func(t *testing.T) {
+		queries := resetDB(t)
+		createTestUser(t, queries, "jane@example.com")
+
+		_, err := NewUserRepository(queries, testPool).Create(ctx, db.CreateUserParams{
+			Email:     "jane@example.com",
+			FirstName: "Another",
+			LastName:  "Jane",
+			Role:      db.UserRoleEntrant,
+		})
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("anonymises a user and keeps their registrations", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewUserRepository(queries, testPool)
+		user := createTestUser(t, queries, "jane@example.com")
+		org := createTestOrganisation(t, queries)
+		if _, err := NewAuthRepository(queries, testPool).CreateCredentials(ctx, user.ID, "hash"); err != nil {
+			t.Fatalf("failed to create credentials: %v", err)
+		}
+		if _, err := queries.AddOrganisationMember(ctx, db.AddOrganisationMemberParams{
+			OrganisationID: org.ID,
+			UserID:         user.ID,
+			Role:           db.OrganisationRoleStaff,
+		}); err != nil {
+			t.Fatalf("failed to add member: %v", err)
+		}
+		event, err := queries.CreateEvent(ctx, db.CreateEventParams{
+			OrganisationID: org.ID,
+			Name:           "Peak District Ultra",
+			Slug:           "peak-district-ultra",
+			Year:           2026,
+		})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		race, err := queries.CreateRace(ctx, db.CreateRaceParams{
+			EventID:     event.ID,
+			Name:        "Ultra 50K",
+			Slug:        "ultra-50k",
+			MaxCapacity: 300,
+		})
+		if err != nil {
+			t.Fatalf("failed to create race: %v", err)
+		}
+		if _, err := queries.CreateImportedRegistration(ctx, db.CreateImportedRegistrationParams{
+			UserID: user.ID,
+			RaceID: race.ID,
+		}); err != nil {
+			t.Fatalf("failed to create registration: %v", err)
+		}
+
+		if _, err := repo.UpdateProfile(ctx, db.UpdateUserProfileParams{
+			ID:           user.ID,
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+			DateOfBirth:  pgtype.Date{Time: time.Date(1990, 4, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+			DistanceUnit: db.DistanceUnitMiles,
+		}); err != nil {
+			t.Fatalf("failed to set date of birth: %v", err)
+		}
+
+		if err := repo.Anonymise(ctx, user.ID); err != nil {
+			t.Fatalf("failed to anonymise user: %v", err)
+		}
+
+		anonymised, err := repo.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if !anonymised.DeletedAt.Valid || anonymised.Email == "jane@example.com" || anonymised.FirstName != "" || anonymised.City.Valid || anonymised.DateOfBirth.Valid {
+			t.Errorf("expected the user's details to be cleared, got %+v", anonymised)
+		}
+		if _, err := NewAuthRepository(queries, testPool).GetCredentialsByUserID(ctx, user.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected the credentials to be deleted, got %v", err)
+		}
+		if member, err := queries.IsOrganisationMember(ctx, db.IsOrganisationMemberParams{
+			OrganisationID: org.ID,
+			UserID:         user.ID,
+		}); err != nil || member {
+			t.Errorf("expected the membership to be removed, got %v (err %v)", member, err)
+		}
+
+		entrants, err := NewRegistrationRepository(queries, testPool).ListByRace(ctx, race.ID)
+		if err != nil {
+			t.Fatalf("failed to list entrants: %v", err)
+		}
+		if len(entrants) != 1 {
+			t.Fatalf("expected the registration to be kept, got %d", len(entrants))
+		}
+		got := entrants[0]
+		if !got.AnonymisedAt.Valid || got.FirstName != "" || got.LastName != "" || got.Email == "jane@example.com" {
+			t.Errorf("expected the entrant's details to be cleared, got %+v", got)
+		}
+
+		if err := repo.Anonymise(ctx, user.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound anonymising twice, got %v", err)
+		}
+		// The address is free to sign up with again
+		createTestUser(t, queries, "jane@example.com")
+	})
+
+	t.Run("updates a user's profile", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewUserRepository(queries, testPool)
+		user := createTestUser(t, queries, "jane@example.com")
+		dob := pgtype.Date{Time: time.Date(1990, 4, 2, 0, 0, 0, 0, time.UTC), Valid: true}
+
+		if user.DistanceUnit != db.DistanceUnitMiles {
+			t.Errorf("expected distances in miles by default, got %q", user.DistanceUnit)
+		}
+
+		updated, err := repo.UpdateProfile(ctx, db.UpdateUserProfileParams{ID: user.ID, FirstName: "Janet", LastName: "Runner", DateOfBirth: dob, DistanceUnit: db.DistanceUnitKm})
+		if err != nil {
+			t.Fatalf("failed to update profile: %v", err)
+		}
+		if updated.FirstName != "Janet" || updated.Email != user.Email || !updated.DateOfBirth.Time.Equal(dob.Time) || updated.DistanceUnit != db.DistanceUnitKm {
+			t.Errorf("unexpected user: %+v", updated)
+		}
+
+		if _, err := repo.UpdateProfile(ctx, db.UpdateUserProfileParams{ID: 999, FirstName: "Nobody", DistanceUnit: db.DistanceUnitMiles}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrNotFound anonymising a missing user", func(t *testing.T) {
+		queries := resetDB(t)
+
+		if err := NewUserRepository(queries, testPool).Anonymise(ctx, 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("sets which optional emails a user receives", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewUserRepository(queries, testPool)
+		user := createTestUser(t, queries, "jane@example.com")
+		if user.EmailPreference != db.EmailPreferenceMarketing {
+			t.Errorf("expected new users to receive every email, got %q", user.EmailPreference)
+		}
+
+		if err := repo.SetEmailPreference(ctx, user.ID, db.EmailPreferenceTransactional); err != nil {
+			t.Fatalf("failed to set preference: %v", err)
+		}
+		got, err := repo.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if got.EmailPreference != db.EmailPreferenceTransactional {
+			t.Errorf("expected transactional email only, got %q", got.EmailPreference)
+		}
+
+		if err := repo.Anonymise(ctx, user.ID); err != nil {
+			t.Fatalf("failed to anonymise: %v", err)
+		}
+		if err := repo.SetEmailPreference(ctx, user.ID, db.EmailPreferenceMarketing); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a deleted user, got %v", err)
+		}
+	})
+
+	// userAudit returns the single change recorded against the user.
+	userAudit := func(t *testing.T, queries *db.Queries, userID int64) (db.AuditLog, map[string]auditChange) {
+		t.Helper()
+		entries, err := queries.ListAuditLogForRecord(ctx, db.ListAuditLogForRecordParams{
+			TableName: "users",
+			RecordID:  userID,
+		})
+		if err != nil {
+			t.Fatalf("failed to list audit log: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected one audit entry, got %d", len(entries))
+		}
+		var changed map[string]auditChange
+		if err := json.Unmarshal(entries[0].ChangedFields, &changed); err != nil {
+			t.Fatalf("failed to decode changed fields: %v", err)
+		}
+		return entries[0], changed
+	}
+
+	t.Run("changes a user's role and records who did it", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewUserRepository(queries, testPool)
+		admin := createTestUser(t, queries, "admin@example.com")
+		user := createTestUser(t, queries, "jane@example.com")
+
+		if err := repo.UpdateRole(ctx, user.ID, db.UserRoleOrganizer, admin.ID); err != nil {
+			t.Fatalf("failed to update role: %v", err)
+		}
+		// The same role again changes nothing and records nothing
+		if err := repo.UpdateRole(ctx, user.ID, db.UserRoleOrganizer, admin.ID); err != nil {
+			t.Fatalf("failed to update role: %v", err)
+		}
+
+		got, err := repo.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if got.Role != db.UserRoleOrganizer {
+			t.Errorf("expected organizer, got %q", got.Role)
+		}
+		entry, changed := userAudit(t, queries, user.ID)
+		if entry.Action != db.AuditActionUpdated || entry.UserID.Int64 != admin.ID {
+			t.Errorf("expected an update by the admin, got %+v", entry)
+		}
+		if got := changed["role"]; got.Old != "entrant" || got.New != "organizer" {
+			t.Errorf("expected role entrant -> organizer, got %+v", got)
+		}
+
+		if err := repo.UpdateRole(ctx, 999, db.UserRoleAdmin, admin.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing user, got %v", err)
+		}
+	})
+
+	t.Run("lists users by search and role, newest first", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewUserRepository(queries, testPool)
+		create := func(email, first, last string, role db.UserRole) db.User {
+			t.Helper()
+			user, err := repo.Create(ctx, db.CreateUserParams{Email: email, FirstName: first, LastName: last, Role: role})
+			if err != nil {
+				t.Fatalf("failed to create user: %v", err)
+			}
+			return user
+		}
+		create("jane@example.com", "Jane", "Runner", db.UserRoleEntrant)
+		sam := create("sam@example.com", "Sam", "Runner", db.UserRoleOrganizer)
+		create("ada@example.com", "Ada", "Walker", db.UserRoleOrganizer)
+		newest := create("run_fast@example.com", "Rhiannon", "Jones", db.UserRoleOrganizer)
+		if _, err := NewAuthRepository(queries, testPool).CreateCredentials(ctx, sam.ID, "hash"); err != nil {
+			t.Fatalf("failed to create credentials: %v", err)
+		}
+		if err := queries.UpdateLastLogin(ctx, sam.ID); err != nil {
+			t.Fatalf("failed to record sign-in: %v", err)
+		}
+
+		ids := func(rows []db.ListUsersRow) []int64 {
+			var ids []int64
+			for _, row := range rows {
+				ids = append(ids, row.ID)
+			}
+			return ids
+		}
+
+		// "runner" matches names and "RUN" emails, ignoring case; the role
+		// leaves out Jane
+		filter := UserFilter{Query: "RUN", Role: db.UserRoleOrganizer}
+		rows, err := repo.ListUsers(ctx, filter, 10, 0)
+		if err != nil {
+			t.Fatalf("failed to list users: %v", err)
+		}
+		if got := ids(rows); !slices.Equal(got, []int64{newest.ID, sam.ID}) {
+			t.Errorf("expected the newest match first, got %v", got)
+		}
+		if !rows[1].LastLoginAt.Valid || rows[0].LastLoginAt.Valid {
+			t.Errorf("expected only Sam's last sign-in, got %+v", rows)
+		}
+		if n, err := repo.CountUsers(ctx, filter); err != nil || n != 2 {
+			t.Errorf("expected 2 users counted, got %d (err %v)", n, err)
+		}
+
+		rows, err = repo.ListUsers(ctx, filter, 1, 1)
+		if err != nil {
+			t.Fatalf("failed to list users: %v", err)
+		}
+		if got := ids(rows); !slices.Equal(got, []int64{sam.ID}) {
+			t.Errorf("expected the second page to hold Sam, got %v", got)
+		}
+
+		// Wildcards in the search match only themselves
+		rows, err = repo.ListUsers(ctx, UserFilter{Query: "n_f"}, 10, 0)
+		if err != nil {
+			t.Fatalf("failed to list users: %v", err)
+		}
+		if got := ids(rows); !slices.Equal(got, []int64{newest.ID}) {
+			t.Errorf("expected only run_fast@example.com, got %v", got)
+		}
+
+		rows, err = repo.ListUsers(ctx, UserFilter{Query: "%", Role: db.UserRoleAdmin}, 10, 0)
+		if err != nil {
+			t.Fatalf("failed to list users: %v", err)
+		}
+		if len(rows) != 0 {
+			t.Errorf("expected no users, got %v", ids(rows))
+		}
+		if n, err := repo.CountUsers(ctx, UserFilter{Role: db.UserRoleAdmin}); err != nil || n != 0 {
+			t.Errorf("expected no admins counted, got %d (err %v)", n, err)
+		}
+	})
+
+	t.Run("deactivates a user, ending their sessions, and reactivates them", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewUserRepository(queries, testPool)
+		admin := createTestUser(t, queries, "admin@example.com")
+		user := createTestUser(t, queries, "jane@example.com")
+		session, err := queries.CreateUserSession(ctx, db.CreateUserSessionParams{
+			UserID:    user.ID,
+			UserAgent: "Firefox",
+			IpAddress: "192.0.2.1",
+			ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+
+		if err := repo.SetActive(ctx, user.ID, false, admin.ID); err != nil {
+			t.Fatalf("failed to deactivate: %v", err)
+		}
+		got, err := repo.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get user: %v", err)
+		}
+		if !got.DeactivatedAt.Valid {
+			t.Fatal("expected the user to be deactivated")
+		}
+		if _, err := queries.GetUserSession(ctx, session.ID); err == nil {
+			t.Error("expected the user's session to be ended")
+		}
+		entry, changed := userAudit(t, queries, user.ID)
+		if entry.UserID.Int64 != admin.ID || changed["deactivated_at"].Old != nil || changed["deactivated_at"].New == nil {
+			t.Errorf("unexpected audit entry %+v with changes %+v", entry, changed)
+		}
+
+		if err := repo.SetActive(ctx, user.ID, true, admin.ID); err != nil {
+			t.Fatalf("failed to reactivate: %v", err)
+		}
+		if got, err := repo.GetByID(ctx, user.ID); err != nil || got.DeactivatedAt.Valid {
+			t.Errorf("expected the user to be active again, got %v (err %v)", got.DeactivatedAt, err)
+		}
+		// Reactivating an active user changes nothing
+		if err := repo.SetActive(ctx, user.ID, true, admin.ID); err != nil {
+			t.Fatalf("failed to reactivate: %v", err)
+		}
+		entries, err := queries.ListAuditLogForRecord(ctx, db.ListAuditLogForRecordParams{TableName: "users", RecordID: user.ID})
+		if err != nil || len(entries) != 2 {
+			t.Errorf("expected two audit entries, got %d (err %v)", len(entries), err)
+		}
+
+		if err := repo.SetActive(ctx, 999, false, admin.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing user, got %v", err)
+		}
+	})
+
+	t.Run("records impersonation against the user", func(t *testing.T) {
+		queries := resetDB(t)
+		repo := NewUserRepository(queries, testPool)
+		admin := createTestUser(t, queries, "admin@example.com")
+		user := createTestUser(t, queries, "jane@example.com")
+
+		if err := repo.AuditImpersonation(ctx, user.ID, admin.ID, db.AuditActionImpersonationStarted, nil); err != nil {
+			t.Fatalf("failed to audit start: %v", err)
+		}
+		detail := map[string]any{"method": "POST", "path": "/account/delete", "blocked": true}
+		if err := repo.AuditImpersonation(ctx, user.ID, admin.ID, db.AuditActionImpersonatedRequest, detail); err != nil {
+			t.Fatalf("failed to audit request: %v", err)
+		}
+
+		entries, err := queries.ListAuditLogForRecord(ctx, db.ListAuditLogForRecordParams{TableName: "users", RecordID: user.ID})
+		if err != nil || len(entries) != 2 {
+			t.Fatalf("expected two audit entries, got %d (err %v)", len(entries), err)
+		}
+		actions := map[db.AuditAction][]byte{}
+		for _, entry := range entries {
+			if entry.UserID.Int64 != admin.ID {
+				t.Errorf("expected the admin recorded, got %+v", entry.UserID)
+			}
+			actions[entry.Action] = entry.ChangedFields
+		}
+		if changed, ok := actions[db.AuditActionImpersonationStarted]; !ok || changed != nil {
+			t.Errorf("expected a start with no detail, got %+v", actions)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(actions[db.AuditActionImpersonatedRequest], &got); err != nil || got["path"] != "/account/delete" || got["blocked"] != true {
+			t.Errorf("expected the request's detail, got %s (err %v)", actions[db.AuditActionImpersonatedRequest], err)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func (r *raceRepository) ListWaves(ctx context.Context, raceID int64) ([]db.ListRaceWavesRow, error) {
+	return r.queries.ListRaceWaves(ctx, raceID)
+}
+
+func (r *raceRepository) ListWavesByEvent(ctx context.Context, eventID int64) ([]db.RaceWave, error) {
+	return r.queries.ListRaceWavesByEvent(ctx, eventID)
+}
+
+func (r *raceRepository) GetWave(ctx context.Context, raceID, waveID int64) (db.RaceWave, error) {
+	wave, err := r.queries.GetRaceWave(ctx, db.GetRaceWaveParams{ID: waveID, RaceID: raceID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.RaceWave{}, ErrNotFound
+		}
+		return db.RaceWave{}, err
+	}
+	return wave, nil
+}
+
+func (r *raceRepository) CreateWave(ctx context.Context, params db.CreateRaceWaveParams) (db.RaceWave, error) {
+	wave, err := r.queries.CreateRaceWave(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.RaceWave{}, ErrConflict
+		}
+		return db.RaceWave{}, err
+	}
+	return wave, nil
+}
+
+func (r *raceRepository) UpdateWave(ctx context.Context, params db.UpdateRaceWaveParams) (WaveChange, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return WaveChange{}, err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	// Locked like a registration, so no entry can slip into the wave
+	// between the count and the update
+	qtx := r.queries.WithTx(tx)
+	if _, err := qtx.LockRace(ctx, params.RaceID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return WaveChange{}, ErrNotFound
+		}
+		return WaveChange{}, err
+	}
+	old, err := qtx.GetRaceWave(ctx, db.GetRaceWaveParams{ID: params.ID, RaceID: params.RaceID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return WaveChange{}, ErrNotFound
+		}
+		return WaveChange{}, err
+	}
+	taken, err := qtx.CountWaveRegistrations(ctx, pgtype.Int8{Int64: params.ID, Valid: true})
+	if err != nil {
+		return WaveChange{}, err
+	}
+	change := WaveChange{Old: old, Taken: taken}
+	if int64(params.Capacity) < taken {
+		return change, ErrCapacityExceeded
+	}
+
+	change.Wave, err = qtx.UpdateRaceWave(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return WaveChange{}, ErrConflict
+		}
+		return WaveChange{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return WaveChange{}, err
+	}
+	return change, nil
+}
+
+func (r *raceRepository) DeleteWave(ctx context.Context, raceID, waveID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	if _, err := qtx.LockRace(ctx, raceID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	taken, err := qtx.CountWaveRegistrations(ctx, pgtype.Int8{Int64: waveID, Valid: true})
+	if err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrInUse
+	}
+	n, err := qtx.DeleteRaceWave(ctx, db.DeleteRaceWaveParams{ID: waveID, RaceID: raceID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+// chooseWave picks the wave a new entry starts in from the race's waves,
+// ordered earliest first. A preferred wave with room is used as is; a full
+// one falls back to the next later wave with room, then to the earlier
+// ones, latest first. Without a preference the entry goes in the emptiest
+// wave for its size, the earliest of any tied. It returns ErrNotFound if
+// the race has no preferred wave and ErrCapacityExceeded if every wave is
+// full. Callers should hold the race lock.
+func chooseWave(waves []db.ListRaceWavesRow, preferred pgtype.Int8) (int64, error) {
+	hasRoom := func(w db.ListRaceWavesRow) bool {
+		return w.Taken < int64(w.RaceWave.Capacity)
+	}
+
+	if preferred.Valid {
+		at := -1
+		for i, w := range waves {
+			if w.RaceWave.ID == preferred.Int64 {
+				at = i
+				break
+			}
+		}
+		if at < 0 {
+			return 0, ErrNotFound
+		}
+		for i := at; i < len(waves); i++ {
+			if hasRoom(waves[i]) {
+				return waves[i].RaceWave.ID, nil
+			}
+		}
+		for i := at - 1; i >= 0; i-- {
+			if hasRoom(waves[i]) {
+				return waves[i].RaceWave.ID, nil
+			}
+		}
+		return 0, ErrCapacityExceeded
+	}
+
+	best := -1
+	for i, w := range waves {
+		if !hasRoom(w) {
+			continue
+		}
+		// Compared as fractions of each wave's capacity, without dividing
+		if best < 0 || w.Taken*int64(waves[best].RaceWave.Capacity) < waves[best].Taken*int64(w.RaceWave.Capacity) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0, ErrCapacityExceeded
+	}
+	return waves[best].RaceWave.ID, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+// waves builds waves 1, 2, 3... in start order, each with the given places
+// taken out of capacity.
+func waves(capacity int32, taken ...int64) []db.ListRaceWavesRow {
+	rows := make([]db.ListRaceWavesRow, len(taken))
+	for i, n := range taken {
+		rows[i] = db.ListRaceWavesRow{RaceWave: db.RaceWave{ID: int64(i + 1), Capacity: capacity}, Taken: n}
+	}
+	return rows
+}
+
+func wave(id int64) pgtype.Int8 {
+	return pgtype.Int8{Int64: id, Valid: true}
+}
+
+func TestChooseWave(t *testing.T) {
+	tests := []struct {
+		name      string
+		waves     []db.ListRaceWavesRow
+		preferred pgtype.Int8
+		want      int64
+		wantErr   error
+	}{
+		{name: "uses the preferred wave with room", waves: waves(10, 9, 0, 0), preferred: wave(1), want: 1},
+		{name: "falls back to the next wave once the preferred is full", waves: waves(10, 0, 10, 0), preferred: wave(2), want: 3},
+		{name: "skips later waves that are full too", waves: waves(10, 0, 10, 10, 4), preferred: wave(2), want: 4},
+		{name: "falls back to the latest earlier wave when none later has room", waves: waves(10, 3, 5, 10, 10), preferred: wave(3), want: 2},
+		{name: "refuses when every wave is full", waves: waves(10, 10, 10), preferred: wave(1), wantErr: ErrCapacityExceeded},
+		{name: "refuses a wave the race does not have", waves: waves(10, 0), preferred: wave(7), wantErr: ErrNotFound},
+		{name: "refuses a wave in a race without waves", preferred: wave(1), wantErr: ErrNotFound},
+		{name: "puts automatic entries in the emptiest wave", waves: waves(10, 4, 2, 3), want: 2},
+		{name: "breaks ties with the earliest wave", waves: waves(10, 3, 2, 2), want: 2},
+		{name: "refuses automatic entries when every wave is full", waves: waves(5, 5, 5), wantErr: ErrCapacityExceeded},
+		{
+			name: "weighs emptiness by each wave's capacity",
+			waves: []db.ListRaceWavesRow{
+				{RaceWave: db.RaceWave{ID: 1, Capacity: 10}, Taken: 5},
+				{RaceWave: db.RaceWave{ID: 2, Capacity: 100}, Taken: 20},
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chooseWave(tt.waves, tt.preferred)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected wave %d, got %d", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("balances automatic entries across waves", func(t *testing.T) {
+		rows := waves(4, 0, 0, 0)
+		for range 9 {
+			id, err := chooseWave(rows, pgtype.Int8{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			rows[id-1].Taken++
+		}
+		for _, w := range rows {
+			if w.Taken != 3 {
+				t.Errorf("expected 3 entrants in each wave, got %d in wave %d", w.Taken, w.RaceWave.ID)
+			}
+		}
+	})
+}
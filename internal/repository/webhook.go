@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/repositorymocks/webhook.go -pkg repositorymocks . WebhookRepository
+
+// WebhookRepository defines the interface for webhook data access.
+type WebhookRepository interface {
+	// CreateEndpoint stores an organisation's endpoint.
+	CreateEndpoint(ctx context.Context, params db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error)
+	// ListEndpoints returns the organisation's endpoints, oldest first.
+	ListEndpoints(ctx context.Context, organisationID int64) ([]db.WebhookEndpoint, error)
+	// GetEndpoint returns one of the organisation's endpoints, or
+	// ErrNotFound if it has no such endpoint.
+	GetEndpoint(ctx context.Context, organisationID, id int64) (db.WebhookEndpoint, error)
+	// DeleteEndpoint removes one of the organisation's endpoints with its
+	// deliveries. It returns ErrNotFound if it has no such endpoint.
+	DeleteEndpoint(ctx context.Context, organisationID, id int64) error
+	// Enqueue queues the payload for delivery to each of the organisation's
+	// endpoints subscribed to the event type, returning how many it was
+	// queued for.
+	Enqueue(ctx context.Context, organisationID int64, eventType string, payload []byte) (int64, error)
+	// ClaimDue returns up to limit pending deliveries due by now, oldest
+	// first, leasing them until leaseUntil so no one else sends them
+	// meanwhile.
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]db.ClaimWebhookDeliveriesRow, error)
+	// RecordAttempt logs an attempt at a delivery and updates the delivery
+	// with its outcome, in a single transaction.
+	RecordAttempt(ctx context.Context, attempt WebhookAttempt) error
+	// ListDeliveries returns up to limit of the endpoint's deliveries,
+	// newest first.
+	ListDeliveries(ctx context.Context, endpointID int64, limit int) ([]db.WebhookDelivery, error)
+	// ListAttempts returns the attempts at the deliveries, oldest first.
+	ListAttempts(ctx context.Context, deliveryIDs []int64) ([]db.WebhookDeliveryAttempt, error)
+}
+
+// WebhookAttempt is an attempt at a delivery and where it leaves the
+// delivery.
+type WebhookAttempt struct {
+	DeliveryID  int64
+	AttemptedAt time.Time
+	// StatusCode is the status of the endpoint's response, or zero if none
+	// came back
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	// Status, Attempts and NextAttemptAt are the delivery's state after
+	// the attempt
+	Status        db.WebhookDeliveryStatus
+	Attempts      int32
+	NextAttemptAt time.Time
+}
+
+type webhookRepository struct {
+	queries *db.Queries
+	pool    TxBeginner
+}
+
+// NewWebhookRepository creates a new WebhookRepository backed by the given queries.
+func NewWebhookRepository(queries *db.Queries, pool TxBeginner) WebhookRepository {
+	return &webhookRepository{queries: queries, pool: pool}
+}
+
+func (r *webhookRepository) CreateEndpoint(ctx context.Context, params db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+	return r.queries.CreateWebhookEndpoint(ctx, params)
+}
+
+func (r *webhookRepository) ListEndpoints(ctx context.Context, organisationID int64) ([]db.WebhookEndpoint, error) {
+	return r.queries.ListWebhookEndpoints(ctx, organisationID)
+}
+
+func (r *webhookRepository) GetEndpoint(ctx context.Context, organisationID, id int64) (db.WebhookEndpoint, error) {
+	endpoint, err := r.queries.GetWebhookEndpoint(ctx, db.GetWebhookEndpointParams{ID: id, OrganisationID: organisationID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.WebhookEndpoint{}, ErrNotFound
+		}
+		return db.WebhookEndpoint{}, err
+	}
+	return endpoint, nil
+}
+
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, organisationID, id int64) error {
+	n, err := r.queries.DeleteWebhookEndpoint(ctx, db.DeleteWebhookEndpointParams{ID: id, OrganisationID: organisationID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) Enqueue(ctx context.Context, organisationID int64, eventType string, payload []byte) (int64, error) {
+	return r.queries.EnqueueWebhookDeliveries(ctx, db.EnqueueWebhookDeliveriesParams{
+		EventType:      eventType,
+		Payload:        payload,
+		OrganisationID: organisationID,
+	})
+}
+
+func (r *webhookRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]db.ClaimWebhookDeliveriesRow, error) {
+	return r.queries.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		Now:           pgtype.Timestamptz{Time: now, Valid: true},
+		MaxDeliveries: int32(limit),
+		LeaseUntil:    pgtype.Timestamptz{Time: leaseUntil, Valid: true},
+	})
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, attempt WebhookAttempt) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // Rollback after Commit is a no-op
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	if err := qtx.CreateWebhookDeliveryAttempt(ctx, db.CreateWebhookDeliveryAttemptParams{
+		DeliveryID:  attempt.DeliveryID,
+		AttemptedAt: pgtype.Timestamptz{Time: attempt.AttemptedAt, Valid: true},
+		StatusCode:  pgtype.Int4{Int32: int32(attempt.StatusCode), Valid: attempt.StatusCode != 0},
+		Error:       attempt.Error,
+		DurationMs:  int32(attempt.Duration.Milliseconds()),
+	}); err != nil {
+		return err
+	}
+	if err := qtx.UpdateWebhookDelivery(ctx, db.UpdateWebhookDeliveryParams{
+		ID:            attempt.DeliveryID,
+		Status:        attempt.Status,
+		Attempts:      attempt.Attempts,
+		NextAttemptAt: pgtype.Timestamptz{Time: attempt.NextAttemptAt, Valid: true},
+	}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, endpointID int64, limit int) ([]db.WebhookDelivery, error) {
+	return r.queries.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{EndpointID: endpointID, Limit: int32(limit)})
+}
+
+func (r *webhookRepository) ListAttempts(ctx context.Context, deliveryIDs []int64) ([]db.WebhookDeliveryAttempt, error) {
+	return r.queries.ListWebhookDeliveryAttempts(ctx, deliveryIDs)
+}
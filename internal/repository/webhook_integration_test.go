@@ -0,0 +1,121 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"firecrest/db"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queues events for subscribed endpoints and leases them to one sender", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		other, err := queries.CreateOrganisation(ctx, "Valley Striders")
+		if err != nil {
+			t.Fatalf("failed to create organisation: %v", err)
+		}
+		repo := NewWebhookRepository(queries, testPool)
+
+		create := func(organisationID int64, events ...string) db.WebhookEndpoint {
+			t.Helper()
+			endpoint, err := repo.CreateEndpoint(ctx, db.CreateWebhookEndpointParams{
+				OrganisationID: organisationID,
+				Url:            "https://crm.example/hooks",
+				SecretSealed:   []byte("sealed"),
+				EventTypes:     events,
+			})
+			if err != nil {
+				t.Fatalf("failed to create endpoint: %v", err)
+			}
+			return endpoint
+		}
+		created := create(org.ID, "registration.created")
+		create(org.ID, "registration.cancelled")
+		create(other.ID, "registration.created")
+
+		payload := []byte(`{"event":"registration.created","data":{"id":1}}`)
+		n, err := repo.Enqueue(ctx, org.ID, "registration.created", payload)
+		if err != nil {
+			t.Fatalf("failed to enqueue: %v", err)
+		}
+		if n != 1 {
+			t.Fatalf("expected one endpoint subscribed, got %d", n)
+		}
+
+		now := time.Now()
+		claimed, err := repo.ClaimDue(ctx, now, now.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("failed to claim: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].Url != created.Url || string(claimed[0].Payload) != string(payload) {
+			t.Fatalf("expected the delivery with its payload as queued, got %+v", claimed)
+		}
+		if again, _ := repo.ClaimDue(ctx, now, now.Add(time.Minute), 10); len(again) != 0 {
+			t.Errorf("expected a leased delivery not to be claimed again, got %+v", again)
+		}
+
+		retryAt := now.Add(2 * time.Minute)
+		if err := repo.RecordAttempt(ctx, WebhookAttempt{
+			DeliveryID:    claimed[0].ID,
+			AttemptedAt:   now,
+			StatusCode:    500,
+			Error:         "webhook: endpoint did not accept the request: 500 Internal Server Error",
+			Duration:      40 * time.Millisecond,
+			Status:        db.WebhookDeliveryStatusPending,
+			Attempts:      1,
+			NextAttemptAt: retryAt,
+		}); err != nil {
+			t.Fatalf("failed to record attempt: %v", err)
+		}
+		if due, _ := repo.ClaimDue(ctx, retryAt, retryAt.Add(time.Minute), 10); len(due) != 1 || due[0].Attempts != 1 {
+			t.Errorf("expected the delivery due again at its retry, got %+v", due)
+		}
+
+		deliveries, err := repo.ListDeliveries(ctx, created.ID, 10)
+		if err != nil {
+			t.Fatalf("failed to list deliveries: %v", err)
+		}
+		attempts, err := repo.ListAttempts(ctx, []int64{deliveries[0].ID})
+		if err != nil {
+			t.Fatalf("failed to list attempts: %v", err)
+		}
+		if len(attempts) != 1 || attempts[0].StatusCode.Int32 != 500 || attempts[0].DurationMs != 40 {
+			t.Errorf("unexpected attempts %+v", attempts)
+		}
+	})
+
+	t.Run("keeps endpoints to their organisation", func(t *testing.T) {
+		queries := resetDB(t)
+		org := createTestOrganisation(t, queries)
+		repo := NewWebhookRepository(queries, testPool)
+		endpoint, err := repo.CreateEndpoint(ctx, db.CreateWebhookEndpointParams{
+			OrganisationID: org.ID,
+			Url:            "https://crm.example/hooks",
+			SecretSealed:   []byte("sealed"),
+			EventTypes:     []string{"registration.created"},
+		})
+		if err != nil {
+			t.Fatalf("failed to create endpoint: %v", err)
+		}
+
+		if _, err := repo.GetEndpoint(ctx, org.ID+1, endpoint.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for another organisation, got %v", err)
+		}
+		if err := repo.DeleteEndpoint(ctx, org.ID+1, endpoint.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound deleting for another organisation, got %v", err)
+		}
+		if err := repo.DeleteEndpoint(ctx, org.ID, endpoint.ID); err != nil {
+			t.Fatalf("failed to delete endpoint: %v", err)
+		}
+		if endpoints, _ := repo.ListEndpoints(ctx, org.ID); len(endpoints) != 0 {
+			t.Errorf("expected no endpoints left, got %+v", endpoints)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// Package secret encrypts sensitive values, such as entrants' medical
+// answers, before they are stored.
+//
+// A sealed value has the form
+//
+//	<version byte> <12 byte nonce> <AES-256-GCM ciphertext and tag>
+//
+// The version byte lets the format or key change later while values sealed
+// before are still recognised.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// version is the current sealed format.
+const version byte = 1
+
+// KeyLength is the length of the key values are sealed with, in bytes.
+const KeyLength = 32
+
+var (
+	// ErrMalformed is returned for sealed values that are too short or in
+	// an unrecognised format.
+	ErrMalformed = errors.New("secret: malformed value")
+	// ErrDecrypt is returned when a sealed value was not sealed with the
+	// box's key or has been altered since.
+	ErrDecrypt = errors.New("secret: cannot decrypt value")
+)
+
+// Box seals and opens values with a secret key.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox creates a Box using key, which must be exactly KeyLength bytes.
+func NewBox(key []byte) (*Box, error) {
+	if len(key) != KeyLength {
+		return nil, fmt.Errorf("secret: key must be %d bytes, got %d", KeyLength, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("secret: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("secret: %w", err)
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext under a fresh random nonce.
+func (b *Box) Seal(plaintext []byte) []byte {
+	out := make([]byte, 1+b.aead.NonceSize(), 1+b.aead.NonceSize()+len(plaintext)+b.aead.Overhead())
+	out[0] = version
+	// crypto/rand.Read never fails
+	rand.Read(out[1:])
+	return b.aead.Seal(out, out[1:], plaintext, []byte{version})
+}
+
+// Open decrypts a value made by Seal, checking it has not been altered.
+func (b *Box) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < 1+b.aead.NonceSize()+b.aead.Overhead() || sealed[0] != version {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[1:1+b.aead.NonceSize()], sealed[1+b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, sealed[:1])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
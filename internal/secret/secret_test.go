@@ -0,0 +1,77 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestBox(t *testing.T, key []byte) *Box {
+	t.Helper()
+	b, err := NewBox(key)
+	if err != nil {
+		t.Fatalf("failed to create box: %v", err)
+	}
+	return b
+}
+
+func TestNewBox(t *testing.T) {
+	for _, key := range [][]byte{[]byte("too-short"), append(bytes.Clone(testKey), 'x')} {
+		if _, err := NewBox(key); err == nil {
+			t.Errorf("expected error for a %d byte key", len(key))
+		}
+	}
+}
+
+func TestSealAndOpen(t *testing.T) {
+	b := newTestBox(t, testKey)
+	plaintext := []byte(`{"medical_conditions":"asthma"}`)
+
+	sealed := b.Seal(plaintext)
+	if bytes.Contains(sealed, []byte("asthma")) {
+		t.Fatalf("expected the plaintext to be hidden, got %q", sealed)
+	}
+	if again := b.Seal(plaintext); bytes.Equal(sealed, again) {
+		t.Error("expected each seal to use a fresh nonce")
+	}
+
+	got, err := b.Open(sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, got)
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	b := newTestBox(t, testKey)
+	sealed := b.Seal([]byte("asthma"))
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 0xff
+	otherVersion := bytes.Clone(sealed)
+	otherVersion[0] = 2
+
+	tests := []struct {
+		name   string
+		box    *Box
+		sealed []byte
+		want   error
+	}{
+		{name: "altered values", box: b, sealed: tampered, want: ErrDecrypt},
+		{name: "another key", box: newTestBox(t, []byte("fedcba9876543210fedcba9876543210")), sealed: sealed, want: ErrDecrypt},
+		{name: "unknown versions", box: b, sealed: otherVersion, want: ErrMalformed},
+		{name: "short values", box: b, sealed: sealed[:10], want: ErrMalformed},
+		{name: "empty values", box: b, sealed: nil, want: ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.box.Open(tt.sealed); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+)
+
+// Age categories outside the veteran bands.
+const (
+	CategoryJunior = "U18"
+	CategorySenior = "Senior"
+)
+
+// MaxAge bounds the dates of birth accepted, in years before today.
+const MaxAge = 120
+
+// ErrTooYoung is returned when an entrant will be younger than their race's
+// minimum age on race day.
+var ErrTooYoung = apperr.New(apperr.CodeTooYoung, http.StatusUnprocessableEntity, "too young to enter this race")
+
+// Age returns how old someone born on dob is on day, in whole years. Only
+// the dates are read. Those born on 29 February turn a year older on 1
+// March in other years.
+func Age(dob, day time.Time) int {
+	years := day.Year() - dob.Year()
+	if day.Month() < dob.Month() || day.Month() == dob.Month() && day.Day() < dob.Day() {
+		years--
+	}
+	return years
+}
+
+// AgeCategory returns the category of an entrant born on dob in a race run
+// on raceDate. As in UK Athletics road running, the category is set by age
+// on race day: under 18s are U18, veterans are grouped in ten year bands
+// from 40 (V40, V50 and so on) and everyone else is Senior.
+func AgeCategory(dob, raceDate time.Time) string {
+	switch age := Age(dob, raceDate); {
+	case age < 18:
+		return CategoryJunior
+	case age < 40:
+		return CategorySenior
+	default:
+		return fmt.Sprintf("V%d", age/10*10)
+	}
+}
+
+// EntrantCategories returns the age category of each of a race's entrants
+// who gave a date of birth, keyed by registration ID. Entrants of races not
+// yet scheduled have no category.
+func EntrantCategories(entrants []db.ListRaceEntrantsRow) map[int64]string {
+	categories := make(map[int64]string, len(entrants))
+	for _, e := range entrants {
+		if e.DateOfBirth.Valid && e.RaceDay.Valid {
+			categories[e.ID] = AgeCategory(e.DateOfBirth.Time, e.RaceDay.Time)
+		}
+	}
+	return categories
+}
+
+// dateOfBirthProblem describes what is wrong with dob as a date of birth
+// given on now, or returns "" if nothing is.
+func dateOfBirthProblem(dob, now time.Time) string {
+	switch {
+	case dob.After(now):
+		return "date of birth must be in the past"
+	case Age(dob, now) > MaxAge:
+		return fmt.Sprintf("date of birth must be within the last %d years", MaxAge)
+	}
+	return ""
+}
+
+// dateOfBirth stores dob, or NULL for the zero time.
+func dateOfBirth(dob time.Time) pgtype.Date {
+	if dob.IsZero() {
+		return pgtype.Date{}
+	}
+	return pgtype.Date{Time: time.Date(dob.Year(), dob.Month(), dob.Day(), 0, 0, 0, 0, time.UTC), Valid: true}
+}
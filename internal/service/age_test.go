@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAgeCategory(t *testing.T) {
+	raceDate := date(2026, time.June, 6)
+
+	tests := []struct {
+		name string
+		dob  time.Time
+		want string
+	}{
+		{name: "the day before an 18th birthday", dob: date(2008, time.June, 7), want: CategoryJunior},
+		{name: "on an 18th birthday", dob: date(2008, time.June, 6), want: CategorySenior},
+		{name: "the day before a 40th birthday", dob: date(1986, time.June, 7), want: CategorySenior},
+		{name: "on a 40th birthday", dob: date(1986, time.June, 6), want: "V40"},
+		{name: "at 49", dob: date(1976, time.June, 7), want: "V40"},
+		{name: "on a 50th birthday", dob: date(1976, time.June, 6), want: "V50"},
+		{name: "at 71", dob: date(1955, time.January, 1), want: "V70"},
+		{name: "children", dob: date(2019, time.March, 3), want: CategoryJunior},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AgeCategory(tt.dob, raceDate); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAge(t *testing.T) {
+	leapling := date(2008, time.February, 29)
+
+	tests := []struct {
+		name string
+		day  time.Time
+		want int
+	}{
+		{name: "on 28 February of a common year", day: date(2026, time.February, 28), want: 17},
+		{name: "on 1 March of a common year", day: date(2026, time.March, 1), want: 18},
+		{name: "on the birthday in a leap year", day: date(2028, time.February, 29), want: 20},
+		{name: "on the day of birth", day: leapling, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Age(leapling, tt.day); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProfileInput_Validate(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	valid := ProfileInput{FirstName: "Sam", LastName: "Lee", DateOfBirth: date(1990, time.April, 2)}
+
+	tests := []struct {
+		name  string
+		input func(ProfileInput) ProfileInput
+		field string
+	}{
+		{name: "dates of birth in the future", input: func(p ProfileInput) ProfileInput { p.DateOfBirth = date(2026, time.May, 2); return p }, field: "date_of_birth"},
+		{name: "dates of birth over the maximum age", input: func(p ProfileInput) ProfileInput { p.DateOfBirth = date(1905, time.April, 30); return p }, field: "date_of_birth"},
+		{name: "blank first names", input: func(p ProfileInput) ProfileInput { p.FirstName = " "; return p }, field: "first_name"},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			err := tt.input(valid).Validate(now)
+
+			var fe FieldErrors
+			if !errors.As(err, &fe) || fe[tt.field] == "" {
+				t.Errorf("expected a %s field error, got %v", tt.field, err)
+			}
+		})
+	}
+
+	t.Run("accepts a missing date of birth", func(t *testing.T) {
+		p := valid
+		p.DateOfBirth = time.Time{}
+		if err := p.Validate(now); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
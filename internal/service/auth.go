@@ -2,32 +2,56 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
-	"regexp"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/crypto/bcrypt"
 
 	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/mail"
 	"firecrest/internal/repository"
+	"firecrest/internal/token"
 )
 
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/auth.go -pkg servicemocks . AuthService
+
 // Authentication errors
 var (
-	ErrInvalidCredentials = errors.New("invalid email or password")
-	ErrEmailNotVerified   = errors.New("email address not verified")
-	ErrAccountLocked      = errors.New("account is locked due to too many failed login attempts")
-	ErrEmailExists        = errors.New("email address already registered")
+	ErrInvalidCredentials = apperr.New(apperr.CodeInvalidCredentials, http.StatusUnauthorized, "invalid email or password")
+	ErrEmailNotVerified   = apperr.New(apperr.CodeEmailNotVerified, http.StatusForbidden, "email address not verified")
+	ErrAccountLocked      = apperr.New(apperr.CodeAccountLocked, http.StatusTooManyRequests, "account is locked due to too many failed login attempts")
+	ErrAccountDisabled    = apperr.New(apperr.CodeAccountDisabled, http.StatusForbidden, "account has been deactivated")
+	ErrEmailExists        = apperr.New(apperr.CodeEmailExists, http.StatusConflict, "email address already registered")
+	ErrInvalidToken       = apperr.New(apperr.CodeInvalidToken, http.StatusBadRequest, "invalid or expired token")
 )
 
-// Authentication constants
+// Authentication constants. MaxLoginAttempts and AccountLockoutDuration are
+// the defaults of the configurable LockoutPolicy.
 const (
-	BcryptCost             = 12
 	MaxLoginAttempts       = 5
 	AccountLockoutDuration = 15 * time.Minute
 	MinPasswordLength      = 8
+	VerificationTokenTTL   = 24 * time.Hour
+)
+
+// Security alerts. The account holder is warned after failedSignInAlert
+// failed attempts in a row and when the account is locked, at most once a
+// loginAlertInterval for each, and told of sign-ins from a device the
+// account has not signed in from for KnownDeviceWindow.
+const (
+	failedSignInAlert  = 3
+	loginAlertInterval = time.Hour
+	KnownDeviceWindow  = 90 * 24 * time.Hour
+	// unknownSignInLocation stands in for where a sign-in came from, as IP
+	// addresses are not looked up
+	unknownSignInLocation = "Unknown"
 )
 
 // Clock provides time-related operations for testing.
@@ -67,6 +91,19 @@ type AuthService interface {
 	SignUp(ctx context.Context, input SignUpInput) (db.User, error)
 	SignIn(ctx context.Context, input SignInInput) (AuthResult, error)
 	VerifyEmail(ctx context.Context, userID int64) error
+	VerifyEmailToken(ctx context.Context, token string) error
+	// DeleteAccount anonymises the user's account once password confirms it
+	// is theirs. Their registrations are kept for organisers without their
+	// details, and their email address is freed for a new account.
+	DeleteAccount(ctx context.Context, userID int64, password string) error
+	// Unsubscribe limits the user the token was issued to to transactional
+	// email, which stops race reminders. Unsubscribing twice is not an error.
+	Unsubscribe(ctx context.Context, token string) error
+	// UnlockAccount clears the target user's lockout and failed sign-in
+	// attempts, so they can sign in again straight away. Only site admins
+	// may unlock accounts; anyone else gets ErrForbidden. It returns
+	// repository.ErrNotFound if the target has no credentials.
+	UnlockAccount(ctx context.Context, adminUserID, targetUserID int64) error
 }
 
 // SignUpInput represents the input for user registration.
@@ -75,37 +112,30 @@ type SignUpInput struct {
 	Password  string
 	FirstName string
 	LastName  string
+	// DateOfBirth is optional at sign-up; zero leaves it unknown.
+	DateOfBirth time.Time
 }
 
-// Validate checks if the sign-up input is valid.
+// Validate checks if the sign-up input is valid, reporting every problem as
+// FieldErrors.
 func (i SignUpInput) Validate() error {
-	// Email validation
-	if i.Email == "" {
-		return fmt.Errorf("%w: email is required", ErrInvalidInput)
-	}
-	email := strings.TrimSpace(strings.ToLower(i.Email))
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(email) {
-		return fmt.Errorf("%w: invalid email format", ErrInvalidInput)
+	errs := FieldErrors{}
+	if msg := emailProblem(NormalizeEmail(i.Email)); msg != "" {
+		errs.Add("email", msg)
 	}
 
-	// Password validation
-	if i.Password == "" {
-		return fmt.Errorf("%w: password is required", ErrInvalidInput)
-	}
-	if len(i.Password) < MinPasswordLength {
-		return fmt.Errorf("%w: password must be at least %d characters", ErrInvalidInput, MinPasswordLength)
+	if msg := passwordProblem(i.Password, i.Email, i.FirstName, i.LastName); msg != "" {
+		errs.Add("password", msg)
 	}
 
-	// Name validation
 	if strings.TrimSpace(i.FirstName) == "" {
-		return fmt.Errorf("%w: first name is required", ErrInvalidInput)
+		errs.Add("first_name", "first name is required")
 	}
 	if strings.TrimSpace(i.LastName) == "" {
-		return fmt.Errorf("%w: last name is required", ErrInvalidInput)
+		errs.Add("last_name", "last name is required")
 	}
 
-	return nil
+	return errs.Err()
 }
 
 // SignInInput represents the input for user login.
@@ -113,11 +143,15 @@ type SignInInput struct {
 	Email      string
 	Password   string
 	RememberMe bool
+	// IPAddress and UserAgent describe the device signing in, so the
+	// account holder can be told of sign-ins from one not seen before
+	IPAddress string
+	UserAgent string
 }
 
 // Validate checks if the sign-in input is valid.
 func (i SignInInput) Validate() error {
-	if i.Email == "" {
+	if NormalizeEmail(i.Email) == "" {
 		return fmt.Errorf("%w: email is required", ErrInvalidInput)
 	}
 	if i.Password == "" {
@@ -130,33 +164,94 @@ func (i SignInInput) Validate() error {
 type AuthResult struct {
 	User       db.User
 	RememberMe bool
+	// EmailVerified is false for users signing in within the verification
+	// grace period who have not yet verified their email address.
+	EmailVerified bool
+}
+
+// AuthMetrics records sign-in outcomes for monitoring.
+type AuthMetrics interface {
+	SignInFailed()
+	AccountLocked()
 }
 
 type authService struct {
 	authRepo repository.AuthRepository
 	userRepo repository.UserRepository
 	clock    Clock
+	sleeper  Sleeper
 	hasher   PasswordHasher
+	mailer   mail.Mailer
+	metrics  AuthMetrics
+	tokens   *token.Signer
+	baseURL  string
+	// bcryptCost is the cost new password hashes are generated with
+	bcryptCost int
+	// lockout decides when failed sign-ins are slowed down or locked out
+	lockout LockoutPolicy
+	// verifyGrace is how long after signing up users may sign in without
+	// verifying their email address
+	verifyGrace time.Duration
 }
 
 // NewAuthService creates a new AuthService with the given repositories.
-func NewAuthService(authRepo repository.AuthRepository, userRepo repository.UserRepository) AuthService {
+// Verification emails are sent through mailer with links rooted at baseURL,
+// carrying tokens signed by tokens. Passwords are hashed with bcryptCost,
+// and failed sign-ins are handled by lockout. Users may sign in for
+// verifyGrace after signing up before verifying their email. metrics may be
+// nil.
+func NewAuthService(
+	authRepo repository.AuthRepository,
+	userRepo repository.UserRepository,
+	mailer mail.Mailer,
+	metrics AuthMetrics,
+	tokens *token.Signer,
+	baseURL string,
+	bcryptCost int,
+	lockout LockoutPolicy,
+	verifyGrace time.Duration,
+) AuthService {
 	return &authService{
-		authRepo: authRepo,
-		userRepo: userRepo,
-		clock:    RealClock{},
-		hasher:   BcryptHasher{},
+		authRepo:    authRepo,
+		userRepo:    userRepo,
+		clock:       RealClock{},
+		sleeper:     RealClock{},
+		hasher:      BcryptHasher{},
+		mailer:      mailer,
+		metrics:     metrics,
+		tokens:      tokens,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		bcryptCost:  bcryptCost,
+		lockout:     lockout,
+		verifyGrace: verifyGrace,
+	}
+}
+
+// hashPassword hashes a new password with the configured cost. Every path
+// that sets a password goes through it.
+func (s *authService) hashPassword(password string) (string, error) {
+	hash, err := s.hasher.GenerateFromPassword([]byte(password), s.bcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
+	return string(hash), nil
 }
 
 func (s *authService) SignUp(ctx context.Context, input SignUpInput) (db.User, error) {
+	ctx, span := startSpan(ctx, "AuthService.SignUp")
+	defer span.End()
+
 	// Validate input
 	if err := input.Validate(); err != nil {
 		return db.User{}, err
 	}
+	if !input.DateOfBirth.IsZero() {
+		if msg := dateOfBirthProblem(input.DateOfBirth, s.clock.Now()); msg != "" {
+			return db.User{}, FieldErrors{"date_of_birth": msg}
+		}
+	}
 
-	// Normalize email
-	email := strings.TrimSpace(strings.ToLower(input.Email))
+	email := NormalizeEmail(input.Email)
 
 	// Check if email already exists
 	_, err := s.authRepo.GetUserByEmail(ctx, email)
@@ -168,51 +263,93 @@ func (s *authService) SignUp(ctx context.Context, input SignUpInput) (db.User, e
 	}
 
 	// Hash password
-	passwordHash, err := s.hasher.GenerateFromPassword([]byte(input.Password), BcryptCost)
+	passwordHash, err := s.hashPassword(input.Password)
 	if err != nil {
-		return db.User{}, fmt.Errorf("failed to hash password: %w", err)
+		return db.User{}, err
 	}
 
 	// Create user
 	user, err := s.userRepo.Create(ctx, db.CreateUserParams{
-		Email:     email,
-		FirstName: strings.TrimSpace(input.FirstName),
-		LastName:  strings.TrimSpace(input.LastName),
-		Role:      db.UserRoleEntrant,
+		Email:       email,
+		FirstName:   strings.TrimSpace(input.FirstName),
+		LastName:    strings.TrimSpace(input.LastName),
+		Role:        db.UserRoleEntrant,
+		DateOfBirth: dateOfBirth(input.DateOfBirth),
 	})
 	if err != nil {
+		// Someone else signed up with the email since it was checked
+		if errors.Is(err, repository.ErrConflict) {
+			return db.User{}, ErrEmailExists
+		}
 		return db.User{}, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	// Create auth credentials
-	_, err = s.authRepo.CreateCredentials(ctx, user.ID, string(passwordHash))
+	_, err = s.authRepo.CreateCredentials(ctx, user.ID, passwordHash)
 	if err != nil {
 		// TODO: Consider implementing transaction rollback here
 		return db.User{}, fmt.Errorf("failed to create credentials: %w", err)
 	}
 
-	// TODO: Send verification email
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		return db.User{}, err
+	}
 
 	return user, nil
 }
 
+// sendVerificationEmail stores a new verification token for the user and
+// queues an email containing the link to redeem it.
+func (s *authService) sendVerificationEmail(ctx context.Context, user db.User) error {
+	tok, err := s.tokens.SignToken(token.PurposeEmailVerification, user.ID, VerificationTokenTTL)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	hash := sha256.Sum256([]byte(tok))
+
+	expiresAt := s.clock.Now().Add(VerificationTokenTTL)
+	if err := s.authRepo.CreateVerificationToken(ctx, user.ID, hash[:], expiresAt); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	msg, err := mail.VerificationMessage(user.Email, mail.VerificationData{
+		FirstName: user.FirstName,
+		VerifyURL: s.baseURL + "/auth/verify?token=" + url.QueryEscape(tok),
+		ExpiresIn: "24 hours",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build verification email: %w", err)
+	}
+
+	// Delivery happens in the background; the mailer logs any failure, and
+	// the user can still sign up again once the account is cleaned up.
+	_ = s.mailer.Send(ctx, msg)
+	return nil
+}
+
 func (s *authService) SignIn(ctx context.Context, input SignInInput) (AuthResult, error) {
+	ctx, span := startSpan(ctx, "AuthService.SignIn")
+	defer span.End()
+
 	// Validate input
 	if err := input.Validate(); err != nil {
 		return AuthResult{}, err
 	}
 
-	// Normalize email
-	email := strings.TrimSpace(strings.ToLower(input.Email))
+	email := NormalizeEmail(input.Email)
 
 	// Get user
 	user, err := s.authRepo.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
+			s.recordSignInFailure(false)
 			return AuthResult{}, ErrInvalidCredentials
 		}
 		return AuthResult{}, fmt.Errorf("failed to get user: %w", err)
 	}
+	if user.DeactivatedAt.Valid {
+		return AuthResult{}, ErrAccountDisabled
+	}
 
 	// Get credentials
 	creds, err := s.authRepo.GetCredentialsByUserID(ctx, user.ID)
@@ -241,19 +378,46 @@ func (s *authService) SignIn(ctx context.Context, input SignInInput) (AuthResult
 		}
 
 		// Lock account if max attempts reached
-		if creds.FailedLoginAttempts+1 >= MaxLoginAttempts {
-			lockUntil := s.clock.Now().Add(AccountLockoutDuration)
+		attempt := int(creds.FailedLoginAttempts) + 1
+		if s.lockout.Locks(attempt) {
+			lockUntil := s.clock.Now().Add(s.lockout.LockoutDuration)
 			if lockErr := s.authRepo.LockAccount(ctx, user.ID, lockUntil); lockErr != nil {
 				// Log error but continue
 			}
+			s.recordSignInFailure(true)
+			s.recordLoginEvent(ctx, user, db.LoginEventKindLocked, input, func() (mail.Message, error) {
+				return mail.FailedSignInsMessage(user.Email, mail.FailedSignInsData{
+					FirstName:   user.FirstName,
+					Attempts:    attempt,
+					LockedUntil: alertTime(lockUntil),
+					SessionsURL: s.baseURL + "/account/sessions",
+				})
+			})
 			return AuthResult{}, ErrAccountLocked
 		}
 
+		s.recordSignInFailure(false)
+		var alert func() (mail.Message, error)
+		if attempt == failedSignInAlert {
+			alert = func() (mail.Message, error) {
+				return mail.FailedSignInsMessage(user.Email, mail.FailedSignInsData{
+					FirstName:   user.FirstName,
+					Attempts:    attempt,
+					SessionsURL: s.baseURL + "/account/sessions",
+				})
+			}
+		}
+		s.recordLoginEvent(ctx, user, db.LoginEventKindFailed, input, alert)
+		if d := s.lockout.Delay(attempt); d > 0 {
+			s.sleeper.Sleep(ctx, d)
+		}
 		return AuthResult{}, ErrInvalidCredentials
 	}
 
-	// Check email verification
-	if !creds.EmailVerifiedAt.Valid {
+	// Unverified users are let in for a while after signing up, so a slow
+	// verification email doesn't lock them out
+	verified := creds.EmailVerifiedAt.Valid
+	if !verified && s.clock.Now().Sub(user.CreatedAt.Time) >= s.verifyGrace {
 		return AuthResult{}, ErrEmailNotVerified
 	}
 
@@ -261,13 +425,193 @@ func (s *authService) SignIn(ctx context.Context, input SignInInput) (AuthResult
 	if err := s.authRepo.UpdateLastLogin(ctx, user.ID); err != nil {
 		// Log error but don't fail the login
 	}
+	s.recordSignIn(ctx, user, input)
 
 	return AuthResult{
-		User:       user,
-		RememberMe: input.RememberMe,
+		User:          user,
+		RememberMe:    input.RememberMe,
+		EmailVerified: verified,
 	}, nil
 }
 
+// recordSignIn records a successful sign-in, telling the account holder if
+// it came from a device the account has not signed in from lately. The
+// first sign-in on record is not news to anyone.
+func (s *authService) recordSignIn(ctx context.Context, user db.User, input SignInInput) {
+	now := s.clock.Now()
+	history, err := s.authRepo.SignInHistory(ctx, user.ID, input.IPAddress, signInUserAgent(input), now.Add(-KnownDeviceWindow))
+	if err != nil || !history.SignedInBefore || history.SeenDevice {
+		s.storeLoginEvent(ctx, user, db.LoginEventKindSignedIn, input, false)
+		return
+	}
+
+	msg, err := mail.NewSignInMessage(user.Email, mail.NewSignInData{
+		FirstName:   user.FirstName,
+		Time:        alertTime(now),
+		Device:      signInUserAgent(input),
+		IPAddress:   input.IPAddress,
+		Location:    unknownSignInLocation,
+		SessionsURL: s.baseURL + "/account/sessions",
+	})
+	alerted := err == nil
+	if alerted {
+		// Delivery happens in the background; the mailer logs any failure
+		_ = s.mailer.Send(ctx, msg)
+	}
+	s.storeLoginEvent(ctx, user, db.LoginEventKindSignedIn, input, alerted)
+}
+
+// recordLoginEvent records an event of kind on the user's account. If
+// alert is non-nil, the email it builds is sent to the account holder,
+// unless they were sent one about the same kind of event within the last
+// loginAlertInterval. Failures are ignored, as alerts are best effort and
+// must not get in the way of signing in.
+func (s *authService) recordLoginEvent(ctx context.Context, user db.User, kind db.LoginEventKind, input SignInInput, alert func() (mail.Message, error)) {
+	var alerted bool
+	if alert != nil {
+		sent, err := s.authRepo.LoginAlertSentSince(ctx, user.ID, kind, s.clock.Now().Add(-loginAlertInterval))
+		if err == nil && !sent {
+			if msg, err := alert(); err == nil {
+				// Delivery happens in the background; the mailer logs any
+				// failure
+				_ = s.mailer.Send(ctx, msg)
+				alerted = true
+			}
+		}
+	}
+	s.storeLoginEvent(ctx, user, kind, input, alerted)
+}
+
+func (s *authService) storeLoginEvent(ctx context.Context, user db.User, kind db.LoginEventKind, input SignInInput, alerted bool) {
+	_ = s.authRepo.RecordLoginEvent(ctx, db.CreateLoginEventParams{
+		UserID:    user.ID,
+		Kind:      kind,
+		IpAddress: input.IPAddress,
+		UserAgent: signInUserAgent(input),
+		Alerted:   alerted,
+		CreatedAt: pgtype.Timestamptz{Time: s.clock.Now(), Valid: true},
+	})
+}
+
+// signInUserAgent returns the user agent the sign-in came from as it is
+// stored, cut short like a session's
+func signInUserAgent(input SignInInput) string {
+	return truncateUserAgent(strings.TrimSpace(input.UserAgent))
+}
+
+// alertTime writes t for a security alert. Accounts have no time zone of
+// their own, so it is written in UTC and says so.
+func alertTime(t time.Time) string {
+	return t.UTC().Format("15:04 MST on 2 January 2006")
+}
+
+// recordSignInFailure counts a sign-in rejected for invalid credentials and,
+// if it locked the account, the lockout.
+func (s *authService) recordSignInFailure(locked bool) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.SignInFailed()
+	if locked {
+		s.metrics.AccountLocked()
+	}
+}
+
 func (s *authService) VerifyEmail(ctx context.Context, userID int64) error {
+	ctx, span := startSpan(ctx, "AuthService.VerifyEmail")
+	defer span.End()
+
+	return s.authRepo.VerifyEmail(ctx, userID)
+}
+
+func (s *authService) VerifyEmailToken(ctx context.Context, tok string) error {
+	ctx, span := startSpan(ctx, "AuthService.VerifyEmailToken")
+	defer span.End()
+
+	// Reject forged, expired or misdirected tokens before touching the
+	// database; the stored hash then makes each token single-use.
+	userID, err := s.tokens.VerifyToken(token.PurposeEmailVerification, tok)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	hash := sha256.Sum256([]byte(tok))
+	ownerID, err := s.authRepo.ConsumeVerificationToken(ctx, hash[:])
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to consume verification token: %w", err)
+	}
+	if ownerID != userID {
+		return ErrInvalidToken
+	}
+
 	return s.authRepo.VerifyEmail(ctx, userID)
 }
+
+func (s *authService) DeleteAccount(ctx context.Context, userID int64, password string) error {
+	ctx, span := startSpan(ctx, "AuthService.DeleteAccount")
+	defer span.End()
+
+	if password == "" {
+		return FieldErrors{"password": "password is required"}
+	}
+
+	creds, err := s.authRepo.GetCredentialsByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidCredentials
+		}
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+	if err := s.hasher.CompareHashAndPassword([]byte(creds.PasswordHash), []byte(password)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.userRepo.Anonymise(ctx, userID); err != nil {
+		return fmt.Errorf("failed to anonymise user: %w", err)
+	}
+	return nil
+}
+
+func (s *authService) Unsubscribe(ctx context.Context, tok string) error {
+	ctx, span := startSpan(ctx, "AuthService.Unsubscribe")
+	defer span.End()
+
+	userID, err := s.tokens.VerifyToken(token.PurposeUnsubscribe, tok)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	if err := s.userRepo.SetEmailPreference(ctx, userID, db.EmailPreferenceTransactional); err != nil {
+		// The account has been deleted since, so there is nobody left to
+		// email
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to update email preference: %w", err)
+	}
+	return nil
+}
+
+func (s *authService) UnlockAccount(ctx context.Context, adminUserID, targetUserID int64) error {
+	ctx, span := startSpan(ctx, "AuthService.UnlockAccount")
+	defer span.End()
+
+	admin, err := s.userRepo.GetByID(ctx, adminUserID)
+	if err != nil {
+		return fmt.Errorf("failed to get admin: %w", err)
+	}
+	if admin.Role != db.UserRoleAdmin {
+		return ErrForbidden
+	}
+
+	if err := s.authRepo.UnlockAccount(ctx, targetUserID, adminUserID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to unlock account: %w", err)
+	}
+	return nil
+}
@@ -2,7 +2,13 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,94 +16,67 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"firecrest/db"
+	"firecrest/internal/mail"
+	"firecrest/internal/mocks/repositorymocks"
 	"firecrest/internal/repository"
+	"firecrest/internal/secret"
+	"firecrest/internal/token"
 )
 
-// mockAuthRepository implements repository.AuthRepository for testing.
-type mockAuthRepository struct {
-	getUserByEmailFunc          func(ctx context.Context, email string) (db.User, error)
-	getCredentialsByUserIDFunc  func(ctx context.Context, userID int64) (db.AuthCredential, error)
-	getCredentialsByEmailFunc   func(ctx context.Context, email string) (db.AuthCredential, error)
-	isAccountLockedFunc         func(ctx context.Context, userID int64) (bool, error)
-	incrementFailedAttemptsFunc func(ctx context.Context, userID int64) error
-	lockAccountFunc             func(ctx context.Context, userID int64, lockUntil time.Time) error
-	updateLastLoginFunc         func(ctx context.Context, userID int64) error
-	verifyEmailFunc             func(ctx context.Context, userID int64) error
-	createCredentialsFunc       func(ctx context.Context, userID int64, passwordHash string) (db.AuthCredential, error)
+// mockMailer implements mail.Mailer for testing, recording sent messages.
+type mockMailer struct {
+	sent []mail.Message
+	err  error
 }
 
-func (m *mockAuthRepository) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
-	if m.getUserByEmailFunc != nil {
-		return m.getUserByEmailFunc(ctx, email)
-	}
-	return db.User{}, nil
-}
-
-func (m *mockAuthRepository) GetCredentialsByUserID(ctx context.Context, userID int64) (db.AuthCredential, error) {
-	if m.getCredentialsByUserIDFunc != nil {
-		return m.getCredentialsByUserIDFunc(ctx, userID)
-	}
-	return db.AuthCredential{}, nil
-}
-
-func (m *mockAuthRepository) GetCredentialsByEmail(ctx context.Context, email string) (db.AuthCredential, error) {
-	if m.getCredentialsByEmailFunc != nil {
-		return m.getCredentialsByEmailFunc(ctx, email)
-	}
-	return db.AuthCredential{}, nil
+func (m *mockMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return m.err
 }
 
-func (m *mockAuthRepository) IsAccountLocked(ctx context.Context, userID int64) (bool, error) {
-	if m.isAccountLockedFunc != nil {
-		return m.isAccountLockedFunc(ctx, userID)
+// newTestSigner returns a token signer with a fixed test key.
+func newTestSigner(t *testing.T) *token.Signer {
+	t.Helper()
+	signer, err := token.NewSigner([]byte("test-key-test-key-test-key-test!"))
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
 	}
-	return false, nil
+	return signer
 }
 
-func (m *mockAuthRepository) IncrementFailedAttempts(ctx context.Context, userID int64) error {
-	if m.incrementFailedAttemptsFunc != nil {
-		return m.incrementFailedAttemptsFunc(ctx, userID)
+func newTestBox(t *testing.T) *secret.Box {
+	t.Helper()
+	box, err := secret.NewBox([]byte("test-key-test-key-test-key-test!"))
+	if err != nil {
+		t.Fatalf("failed to create box: %v", err)
 	}
-	return nil
+	return box
 }
 
-func (m *mockAuthRepository) LockAccount(ctx context.Context, userID int64, lockUntil time.Time) error {
-	if m.lockAccountFunc != nil {
-		return m.lockAccountFunc(ctx, userID, lockUntil)
-	}
-	return nil
+// mockAuthMetrics counts the sign-in outcomes recorded by the service.
+type mockAuthMetrics struct {
+	failures int
+	lockouts int
 }
 
-func (m *mockAuthRepository) UpdateLastLogin(ctx context.Context, userID int64) error {
-	if m.updateLastLoginFunc != nil {
-		return m.updateLastLoginFunc(ctx, userID)
-	}
-	return nil
-}
+func (m *mockAuthMetrics) SignInFailed()  { m.failures++ }
+func (m *mockAuthMetrics) AccountLocked() { m.lockouts++ }
 
-func (m *mockAuthRepository) VerifyEmail(ctx context.Context, userID int64) error {
-	if m.verifyEmailFunc != nil {
-		return m.verifyEmailFunc(ctx, userID)
-	}
-	return nil
-}
-
-func (m *mockAuthRepository) CreateCredentials(ctx context.Context, userID int64, passwordHash string) (db.AuthCredential, error) {
-	if m.createCredentialsFunc != nil {
-		return m.createCredentialsFunc(ctx, userID, passwordHash)
-	}
-	return db.AuthCredential{}, nil
-}
-
-// MockClock implements Clock for testing.
+// MockClock implements Clock and Sleeper for testing, recording sleeps
+// instead of waiting.
 type MockClock struct {
 	CurrentTime time.Time
+	Slept       []time.Duration
 }
 
 func (m *MockClock) Now() time.Time {
 	return m.CurrentTime
 }
 
+func (m *MockClock) Sleep(ctx context.Context, d time.Duration) {
+	m.Slept = append(m.Slept, d)
+}
+
 // MockHasher implements PasswordHasher for testing.
 type MockHasher struct {
 	CompareFunc  func(hashedPassword, password []byte) error
@@ -132,11 +111,11 @@ func TestAuthService_SignIn(t *testing.T) {
 			LastName:  "User",
 		}
 
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return expectedUser, nil
 			},
-			getCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
 				return db.AuthCredential{
 					UserID:       1,
 					PasswordHash: "hashed_password",
@@ -147,15 +126,15 @@ func TestAuthService_SignIn(t *testing.T) {
 					FailedLoginAttempts: 0,
 				}, nil
 			},
-			isAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+			IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
 				return false, nil
 			},
-			updateLastLoginFunc: func(ctx context.Context, userID int64) error {
+			UpdateLastLoginFunc: func(ctx context.Context, userID int64) error {
 				return nil
 			},
 		}
 
-		userRepo := &mockUserRepository{}
+		userRepo := &repositorymocks.UserRepositoryMock{}
 
 		hasher := &MockHasher{
 			CompareFunc: func(hashedPassword, password []byte) error {
@@ -170,6 +149,7 @@ func TestAuthService_SignIn(t *testing.T) {
 			authRepo: authRepo,
 			userRepo: userRepo,
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   hasher,
 		}
 
@@ -188,16 +168,19 @@ func TestAuthService_SignIn(t *testing.T) {
 		if !result.RememberMe {
 			t.Error("expected RememberMe to be true")
 		}
+		if !result.EmailVerified {
+			t.Error("expected EmailVerified to be true")
+		}
 	})
 
 	t.Run("normalizes email to lowercase and trimmed", func(t *testing.T) {
 		var capturedEmail string
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				capturedEmail = email
 				return db.User{ID: 1, Email: email}, nil
 			},
-			getCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
 				return db.AuthCredential{
 					UserID:       1,
 					PasswordHash: "hashed_password",
@@ -207,7 +190,7 @@ func TestAuthService_SignIn(t *testing.T) {
 					},
 				}, nil
 			},
-			isAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+			IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
 				return false, nil
 			},
 		}
@@ -220,8 +203,9 @@ func TestAuthService_SignIn(t *testing.T) {
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   hasher,
 		}
 
@@ -237,9 +221,10 @@ func TestAuthService_SignIn(t *testing.T) {
 
 	t.Run("returns ErrInvalidInput for empty email", func(t *testing.T) {
 		svc := &authService{
-			authRepo: &mockAuthRepository{},
-			userRepo: &mockUserRepository{},
+			authRepo: &repositorymocks.AuthRepositoryMock{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   &MockHasher{},
 		}
 
@@ -255,9 +240,10 @@ func TestAuthService_SignIn(t *testing.T) {
 
 	t.Run("returns ErrInvalidInput for empty password", func(t *testing.T) {
 		svc := &authService{
-			authRepo: &mockAuthRepository{},
-			userRepo: &mockUserRepository{},
+			authRepo: &repositorymocks.AuthRepositoryMock{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   &MockHasher{},
 		}
 
@@ -272,16 +258,17 @@ func TestAuthService_SignIn(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidCredentials for non-existent user", func(t *testing.T) {
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return db.User{}, repository.ErrNotFound
 			},
 		}
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   &MockHasher{},
 		}
 
@@ -296,21 +283,21 @@ func TestAuthService_SignIn(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidCredentials for wrong password", func(t *testing.T) {
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return db.User{ID: 1, Email: email}, nil
 			},
-			getCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
 				return db.AuthCredential{
 					UserID:              1,
 					PasswordHash:        "hashed_password",
 					FailedLoginAttempts: 0,
 				}, nil
 			},
-			isAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+			IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
 				return false, nil
 			},
-			incrementFailedAttemptsFunc: func(ctx context.Context, userID int64) error {
+			IncrementFailedAttemptsFunc: func(ctx context.Context, userID int64) error {
 				return nil
 			},
 		}
@@ -323,8 +310,9 @@ func TestAuthService_SignIn(t *testing.T) {
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   hasher,
 		}
 
@@ -340,21 +328,21 @@ func TestAuthService_SignIn(t *testing.T) {
 
 	t.Run("increments failed attempts on wrong password", func(t *testing.T) {
 		var incrementCalled bool
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return db.User{ID: 1}, nil
 			},
-			getCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
 				return db.AuthCredential{
 					UserID:              1,
 					PasswordHash:        "hashed_password",
 					FailedLoginAttempts: 2,
 				}, nil
 			},
-			isAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+			IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
 				return false, nil
 			},
-			incrementFailedAttemptsFunc: func(ctx context.Context, userID int64) error {
+			IncrementFailedAttemptsFunc: func(ctx context.Context, userID int64) error {
 				incrementCalled = true
 				return nil
 			},
@@ -366,11 +354,15 @@ func TestAuthService_SignIn(t *testing.T) {
 			},
 		}
 
+		metrics := &mockAuthMetrics{}
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   hasher,
+			mailer:   &mockMailer{},
+			metrics:  metrics,
 		}
 
 		_, _ = svc.SignIn(context.Background(), SignInInput{
@@ -381,6 +373,9 @@ func TestAuthService_SignIn(t *testing.T) {
 		if !incrementCalled {
 			t.Error("expected IncrementFailedAttempts to be called")
 		}
+		if metrics.failures != 1 || metrics.lockouts != 0 {
+			t.Errorf("expected 1 failure and no lockout recorded, got %d and %d", metrics.failures, metrics.lockouts)
+		}
 	})
 
 	t.Run("locks account on 5th failed attempt with correct lockUntil time", func(t *testing.T) {
@@ -388,24 +383,24 @@ func TestAuthService_SignIn(t *testing.T) {
 		expectedLockUntil := mockTime.Add(AccountLockoutDuration)
 		var capturedLockUntil time.Time
 
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return db.User{ID: 1}, nil
 			},
-			getCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
 				return db.AuthCredential{
 					UserID:              1,
 					PasswordHash:        "hashed_password",
 					FailedLoginAttempts: 4, // This will be the 5th attempt
 				}, nil
 			},
-			isAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+			IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
 				return false, nil
 			},
-			incrementFailedAttemptsFunc: func(ctx context.Context, userID int64) error {
+			IncrementFailedAttemptsFunc: func(ctx context.Context, userID int64) error {
 				return nil
 			},
-			lockAccountFunc: func(ctx context.Context, userID int64, lockUntil time.Time) error {
+			LockAccountFunc: func(ctx context.Context, userID int64, lockUntil time.Time) error {
 				capturedLockUntil = lockUntil
 				return nil
 			},
@@ -418,12 +413,16 @@ func TestAuthService_SignIn(t *testing.T) {
 		}
 
 		clock := &MockClock{CurrentTime: mockTime}
+		metrics := &mockAuthMetrics{}
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    clock,
+			lockout:  DefaultLockoutPolicy,
 			hasher:   hasher,
+			mailer:   &mockMailer{},
+			metrics:  metrics,
 		}
 
 		_, err := svc.SignIn(context.Background(), SignInInput{
@@ -438,29 +437,88 @@ func TestAuthService_SignIn(t *testing.T) {
 		if !capturedLockUntil.Equal(expectedLockUntil) {
 			t.Errorf("expected lock until %v, got %v", expectedLockUntil, capturedLockUntil)
 		}
+		if metrics.failures != 1 || metrics.lockouts != 1 {
+			t.Errorf("expected 1 failure and 1 lockout recorded, got %d and %d", metrics.failures, metrics.lockouts)
+		}
+	})
+
+	t.Run("follows the configured lockout policy", func(t *testing.T) {
+		policy := LockoutPolicy{MaxAttempts: 5, LockoutDuration: time.Hour, Progressive: true}
+		tests := []struct {
+			name      string
+			failed    int32
+			want      error
+			wantSleep []time.Duration
+		}{
+			{name: "second attempt", failed: 1, want: ErrInvalidCredentials},
+			{name: "third attempt", failed: 2, want: ErrInvalidCredentials, wantSleep: []time.Duration{100 * time.Millisecond}},
+			{name: "fourth attempt", failed: 3, want: ErrInvalidCredentials, wantSleep: []time.Duration{500 * time.Millisecond}},
+			{name: "fifth attempt", failed: 4, want: ErrAccountLocked},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				now := time.Date(2026, 1, 23, 12, 0, 0, 0, time.UTC)
+				var lockUntil time.Time
+				authRepo := &repositorymocks.AuthRepositoryMock{
+					GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+						return db.User{ID: 1}, nil
+					},
+					GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+						return db.AuthCredential{UserID: 1, PasswordHash: "hashed_password", FailedLoginAttempts: tt.failed}, nil
+					},
+					LockAccountFunc: func(ctx context.Context, userID int64, until time.Time) error {
+						lockUntil = until
+						return nil
+					},
+				}
+				clock := &MockClock{CurrentTime: now}
+				svc := &authService{
+					authRepo: authRepo,
+					userRepo: &repositorymocks.UserRepositoryMock{},
+					clock:    clock,
+					sleeper:  clock,
+					lockout:  policy,
+					hasher:   &MockHasher{},
+					mailer:   &mockMailer{},
+				}
+
+				_, err := svc.SignIn(context.Background(), SignInInput{Email: "test@example.com", Password: "wrong_password"})
+
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("expected %v, got %v", tt.want, err)
+				}
+				if !slices.Equal(clock.Slept, tt.wantSleep) {
+					t.Errorf("expected sleeps %v, got %v", tt.wantSleep, clock.Slept)
+				}
+				if tt.want == ErrAccountLocked && !lockUntil.Equal(now.Add(time.Hour)) {
+					t.Errorf("expected a lock for the policy's hour, until %v", lockUntil)
+				}
+			})
+		}
 	})
 
 	t.Run("returns ErrAccountLocked for locked account", func(t *testing.T) {
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return db.User{ID: 1}, nil
 			},
-			getCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
 				return db.AuthCredential{
 					UserID:              1,
 					PasswordHash:        "hashed_password",
 					FailedLoginAttempts: 5,
 				}, nil
 			},
-			isAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+			IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
 				return true, nil
 			},
 		}
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   &MockHasher{},
 		}
 
@@ -474,12 +532,61 @@ func TestAuthService_SignIn(t *testing.T) {
 		}
 	})
 
+	t.Run("returns ErrAccountDisabled for a deactivated account before checking the password", func(t *testing.T) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+				return db.User{
+					ID:            1,
+					DeactivatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+				}, nil
+			},
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+				return db.AuthCredential{
+					UserID:          1,
+					PasswordHash:    "hashed_password",
+					EmailVerifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+				}, nil
+			},
+		}
+
+		compared := false
+		hasher := &MockHasher{
+			CompareFunc: func(hashedPassword, password []byte) error {
+				compared = true
+				return nil
+			},
+		}
+
+		svc := &authService{
+			authRepo: authRepo,
+			userRepo: &repositorymocks.UserRepositoryMock{},
+			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
+			hasher:   hasher,
+		}
+
+		_, err := svc.SignIn(context.Background(), SignInInput{
+			Email:    "test@example.com",
+			Password: "wrong_password",
+		})
+
+		if !errors.Is(err, ErrAccountDisabled) {
+			t.Errorf("expected ErrAccountDisabled, got %v", err)
+		}
+		if compared {
+			t.Error("expected the password not to be checked")
+		}
+		if n := len(authRepo.IncrementFailedAttemptsCalls()); n != 0 {
+			t.Errorf("expected no failed attempt to be recorded, got %d", n)
+		}
+	})
+
 	t.Run("returns ErrEmailNotVerified for unverified email", func(t *testing.T) {
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return db.User{ID: 1}, nil
 			},
-			getCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
 				return db.AuthCredential{
 					UserID:       1,
 					PasswordHash: "hashed_password",
@@ -489,7 +596,7 @@ func TestAuthService_SignIn(t *testing.T) {
 					FailedLoginAttempts: 0,
 				}, nil
 			},
-			isAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+			IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
 				return false, nil
 			},
 		}
@@ -502,8 +609,9 @@ func TestAuthService_SignIn(t *testing.T) {
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   hasher,
 		}
 
@@ -517,13 +625,56 @@ func TestAuthService_SignIn(t *testing.T) {
 		}
 	})
 
+	t.Run("lets unverified users sign in during the grace period", func(t *testing.T) {
+		now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+		tests := []struct {
+			name       string
+			signedUpAt time.Time
+			wantErr    error
+		}{
+			{name: "just signed up", signedUpAt: now.Add(-time.Hour)},
+			{name: "a moment before the grace period ends", signedUpAt: now.Add(-72*time.Hour + time.Second)},
+			{name: "as the grace period ends", signedUpAt: now.Add(-72 * time.Hour), wantErr: ErrEmailNotVerified},
+			{name: "long after signing up", signedUpAt: now.Add(-30 * 24 * time.Hour), wantErr: ErrEmailNotVerified},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				authRepo := &repositorymocks.AuthRepositoryMock{
+					GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+						return db.User{ID: 1, CreatedAt: pgtype.Timestamptz{Time: tt.signedUpAt, Valid: true}}, nil
+					},
+					GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+						return db.AuthCredential{UserID: 1, PasswordHash: "hashed_password"}, nil
+					},
+				}
+				svc := &authService{
+					authRepo:    authRepo,
+					userRepo:    &repositorymocks.UserRepositoryMock{},
+					clock:       &MockClock{CurrentTime: now},
+					lockout:     DefaultLockoutPolicy,
+					hasher:      &MockHasher{CompareFunc: func(hashedPassword, password []byte) error { return nil }},
+					verifyGrace: 72 * time.Hour,
+				}
+
+				result, err := svc.SignIn(context.Background(), SignInInput{Email: "test@example.com", Password: "password"})
+
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if err == nil && (result.User.ID != 1 || result.EmailVerified) {
+					t.Errorf("expected an unverified sign-in for user 1, got %+v", result)
+				}
+			})
+		}
+	})
+
 	t.Run("calls UpdateLastLogin on successful sign in", func(t *testing.T) {
 		var updateLastLoginCalled bool
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return db.User{ID: 1}, nil
 			},
-			getCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
 				return db.AuthCredential{
 					UserID:       1,
 					PasswordHash: "hashed_password",
@@ -533,10 +684,10 @@ func TestAuthService_SignIn(t *testing.T) {
 					},
 				}, nil
 			},
-			isAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+			IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
 				return false, nil
 			},
-			updateLastLoginFunc: func(ctx context.Context, userID int64) error {
+			UpdateLastLoginFunc: func(ctx context.Context, userID int64) error {
 				updateLastLoginCalled = true
 				return nil
 			},
@@ -550,8 +701,9 @@ func TestAuthService_SignIn(t *testing.T) {
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   hasher,
 		}
 
@@ -566,16 +718,17 @@ func TestAuthService_SignIn(t *testing.T) {
 	})
 
 	t.Run("propagates repository errors from GetUserByEmail", func(t *testing.T) {
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return db.User{}, errors.New("database error")
 			},
 		}
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   &MockHasher{},
 		}
 
@@ -593,19 +746,20 @@ func TestAuthService_SignIn(t *testing.T) {
 	})
 
 	t.Run("propagates repository errors from GetCredentialsByUserID", func(t *testing.T) {
-		authRepo := &mockAuthRepository{
-			getUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
 				return db.User{ID: 1}, nil
 			},
-			getCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
 				return db.AuthCredential{}, errors.New("database error")
 			},
 		}
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
+			lockout:  DefaultLockoutPolicy,
 			hasher:   &MockHasher{},
 		}
 
@@ -623,17 +777,204 @@ func TestAuthService_SignIn(t *testing.T) {
 	})
 }
 
+// alertingAuthService returns an auth service whose user 1 has the
+// password "right", keeping credentials and login events in memory, and
+// the mailer and clock it alerts through.
+func alertingAuthService(now time.Time) (*authService, *mockMailer, *MockClock) {
+	clock := &MockClock{CurrentTime: now}
+	creds := db.AuthCredential{UserID: 1, PasswordHash: "right", EmailVerifiedAt: pgtype.Timestamptz{Time: now, Valid: true}}
+	var events []db.CreateLoginEventParams
+	authRepo := &repositorymocks.AuthRepositoryMock{
+		GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+			return db.User{ID: 1, Email: email, FirstName: "Jane"}, nil
+		},
+		GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			return creds, nil
+		},
+		IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+			return creds.LockedUntil.Valid && clock.Now().Before(creds.LockedUntil.Time), nil
+		},
+		IncrementFailedAttemptsFunc: func(ctx context.Context, userID int64) error {
+			creds.FailedLoginAttempts++
+			return nil
+		},
+		LockAccountFunc: func(ctx context.Context, userID int64, lockUntil time.Time) error {
+			creds.LockedUntil = pgtype.Timestamptz{Time: lockUntil, Valid: true}
+			return nil
+		},
+		UpdateLastLoginFunc: func(ctx context.Context, userID int64) error {
+			creds.FailedLoginAttempts = 0
+			creds.LockedUntil = pgtype.Timestamptz{}
+			return nil
+		},
+		RecordLoginEventFunc: func(ctx context.Context, params db.CreateLoginEventParams) error {
+			events = append(events, params)
+			return nil
+		},
+		SignInHistoryFunc: func(ctx context.Context, userID int64, ipAddress, userAgent string, since time.Time) (db.GetSignInHistoryRow, error) {
+			var history db.GetSignInHistoryRow
+			for _, e := range events {
+				if e.Kind != db.LoginEventKindSignedIn {
+					continue
+				}
+				history.SignedInBefore = true
+				if e.IpAddress == ipAddress && e.UserAgent == userAgent && !e.CreatedAt.Time.Before(since) {
+					history.SeenDevice = true
+				}
+			}
+			return history, nil
+		},
+		LoginAlertSentSinceFunc: func(ctx context.Context, userID int64, kind db.LoginEventKind, since time.Time) (bool, error) {
+			return slices.ContainsFunc(events, func(e db.CreateLoginEventParams) bool {
+				return e.Kind == kind && e.Alerted && e.CreatedAt.Time.After(since)
+			}), nil
+		},
+	}
+	mailer := &mockMailer{}
+	return &authService{
+		authRepo: authRepo,
+		userRepo: &repositorymocks.UserRepositoryMock{},
+		clock:    clock,
+		sleeper:  clock,
+		hasher: &MockHasher{CompareFunc: func(hashedPassword, password []byte) error {
+			if string(hashedPassword) != string(password) {
+				return bcrypt.ErrMismatchedHashAndPassword
+			}
+			return nil
+		}},
+		mailer:  mailer,
+		lockout: DefaultLockoutPolicy,
+		baseURL: "https://firecrest.example",
+	}, mailer, clock
+}
+
+func TestAuthService_SecurityAlerts(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+	laptop := SignInInput{Email: "jane@example.com", Password: "right", IPAddress: "203.0.113.9", UserAgent: "Firefox"}
+	phone := SignInInput{Email: "jane@example.com", Password: "right", IPAddress: "198.51.100.4", UserAgent: "Safari"}
+	signIn := func(t *testing.T, svc *authService, input SignInInput) error {
+		t.Helper()
+		_, err := svc.SignIn(context.Background(), input)
+		return err
+	}
+	subjects := func(mailer *mockMailer) []string {
+		var subjects []string
+		for _, msg := range mailer.sent {
+			subjects = append(subjects, msg.Subject)
+		}
+		return subjects
+	}
+
+	t.Run("tells of sign-ins from devices not seen for 90 days", func(t *testing.T) {
+		svc, mailer, clock := alertingAuthService(now)
+
+		steps := []struct {
+			name      string
+			after     time.Duration
+			input     SignInInput
+			wantAlert bool
+		}{
+			{name: "the first sign-in on record", input: laptop},
+			{name: "the same device again", after: time.Hour, input: laptop},
+			{name: "another device", after: time.Hour, input: phone, wantAlert: true},
+			{name: "the first device 89 days on", after: 89*24*time.Hour - time.Hour, input: laptop},
+			{name: "the second device 90 days on", after: 90*24*time.Hour - 89*24*time.Hour + time.Hour, input: phone},
+			{name: "the first device more than 90 days on", after: 91 * 24 * time.Hour, input: laptop, wantAlert: true},
+		}
+		for _, step := range steps {
+			clock.CurrentTime = clock.CurrentTime.Add(step.after)
+			sent := len(mailer.sent)
+
+			if err := signIn(t, svc, step.input); err != nil {
+				t.Fatalf("%s: unexpected error: %v", step.name, err)
+			}
+
+			if alerted := len(mailer.sent) > sent; alerted != step.wantAlert {
+				t.Errorf("%s: expected alert %v, got %v", step.name, step.wantAlert, alerted)
+			}
+		}
+
+		msg := mailer.sent[0]
+		for _, want := range []string{"198.51.100.4", "Safari", "Location: Unknown", "https://firecrest.example/account/sessions", "11:30 UTC on 1 May 2026"} {
+			if !strings.Contains(msg.Text, want) {
+				t.Errorf("expected the alert to contain %q, got:\n%s", want, msg.Text)
+			}
+		}
+	})
+
+	t.Run("warns of failed attempts at most once an hour", func(t *testing.T) {
+		svc, mailer, clock := alertingAuthService(now)
+		wrong := laptop
+		wrong.Password = "wrong"
+		failThrice := func() {
+			for range 3 {
+				if err := signIn(t, svc, wrong); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+				}
+			}
+			if err := signIn(t, svc, laptop); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		failThrice()
+		if got := len(mailer.sent); got != 1 {
+			t.Fatalf("expected one warning after the third failure, got %d", got)
+		}
+
+		clock.CurrentTime = clock.CurrentTime.Add(59 * time.Minute)
+		failThrice()
+		if got := len(mailer.sent); got != 1 {
+			t.Errorf("expected no second warning within the hour, got %v", subjects(mailer))
+		}
+
+		clock.CurrentTime = clock.CurrentTime.Add(time.Minute)
+		failThrice()
+		if got := len(mailer.sent); got != 2 {
+			t.Errorf("expected another warning an hour on, got %v", subjects(mailer))
+		}
+	})
+
+	t.Run("warns of a lockout apart from the failed attempts before it", func(t *testing.T) {
+		svc, mailer, clock := alertingAuthService(now)
+		wrong := laptop
+		wrong.Password = "wrong"
+
+		for range DefaultLockoutPolicy.MaxAttempts {
+			_ = signIn(t, svc, wrong)
+		}
+
+		want := []string{"Failed attempts to sign in to your Firecrest account", "Your Firecrest account has been locked"}
+		if got := subjects(mailer); !slices.Equal(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		if !strings.Contains(mailer.sent[1].Text, "locked until 09:45 UTC on 1 May 2026") {
+			t.Errorf("expected the lockout's end, got:\n%s", mailer.sent[1].Text)
+		}
+
+		// Locked again within the hour, after the lock ran out
+		clock.CurrentTime = clock.CurrentTime.Add(30 * time.Minute)
+		svc.authRepo.(*repositorymocks.AuthRepositoryMock).UpdateLastLoginFunc(context.Background(), 1)
+		for range DefaultLockoutPolicy.MaxAttempts {
+			_ = signIn(t, svc, wrong)
+		}
+		if got := len(mailer.sent); got != 2 {
+			t.Errorf("expected no more warnings within the hour, got %v", subjects(mailer))
+		}
+	})
+}
+
 func TestAuthService_VerifyEmail(t *testing.T) {
 	t.Run("succeeds for valid user ID", func(t *testing.T) {
-		authRepo := &mockAuthRepository{
-			verifyEmailFunc: func(ctx context.Context, userID int64) error {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			VerifyEmailFunc: func(ctx context.Context, userID int64) error {
 				return nil
 			},
 		}
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
 			hasher:   &MockHasher{},
 		}
@@ -646,15 +987,15 @@ func TestAuthService_VerifyEmail(t *testing.T) {
 	})
 
 	t.Run("propagates repository errors", func(t *testing.T) {
-		authRepo := &mockAuthRepository{
-			verifyEmailFunc: func(ctx context.Context, userID int64) error {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			VerifyEmailFunc: func(ctx context.Context, userID int64) error {
 				return errors.New("database error")
 			},
 		}
 
 		svc := &authService{
 			authRepo: authRepo,
-			userRepo: &mockUserRepository{},
+			userRepo: &repositorymocks.UserRepositoryMock{},
 			clock:    RealClock{},
 			hasher:   &MockHasher{},
 		}
@@ -666,3 +1007,573 @@ func TestAuthService_VerifyEmail(t *testing.T) {
 		}
 	})
 }
+
+func TestAuthService_SignUp(t *testing.T) {
+	input := SignUpInput{
+		Email:     "Jane@Example.com",
+		FirstName: "Jane",
+		LastName:  "Runner",
+		Password:  "granite-fell-sunrise",
+	}
+
+	t.Run("stores a token and emails the verification link", func(t *testing.T) {
+		now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		var storedHash []byte
+		var storedExpiry time.Time
+
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+				return db.User{}, repository.ErrNotFound
+			},
+			CreateVerificationTokenFunc: func(ctx context.Context, userID int64, tokenHash []byte, expiresAt time.Time) error {
+				if userID != 7 {
+					t.Errorf("expected token for user 7, got %d", userID)
+				}
+				storedHash = tokenHash
+				storedExpiry = expiresAt
+				return nil
+			},
+		}
+		userRepo := &repositorymocks.UserRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateUserParams) (db.User, error) {
+				return db.User{ID: 7, Email: params.Email, FirstName: params.FirstName}, nil
+			},
+		}
+		mailer := &mockMailer{}
+
+		svc := &authService{
+			authRepo: authRepo,
+			userRepo: userRepo,
+			clock:    &MockClock{CurrentTime: now},
+			hasher:   &MockHasher{},
+			mailer:   mailer,
+			tokens:   newTestSigner(t),
+			baseURL:  "https://firecrest.example",
+		}
+
+		if _, err := svc.SignUp(context.Background(), input); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(mailer.sent) != 1 {
+			t.Fatalf("expected 1 email, got %d", len(mailer.sent))
+		}
+		msg := mailer.sent[0]
+		if msg.To != "jane@example.com" {
+			t.Errorf("expected email to jane@example.com, got %q", msg.To)
+		}
+
+		const prefix = "https://firecrest.example/auth/verify?token="
+		start := strings.Index(msg.Text, prefix)
+		if start < 0 {
+			t.Fatalf("expected verification link in text body, got:\n%s", msg.Text)
+		}
+		link := strings.Fields(msg.Text[start:])[0]
+		u, err := url.Parse(link)
+		if err != nil {
+			t.Fatalf("failed to parse link %q: %v", link, err)
+		}
+		token := u.Query().Get("token")
+		hash := sha256.Sum256([]byte(token))
+		if string(storedHash) != string(hash[:]) {
+			t.Error("expected stored hash to match the emailed token")
+		}
+		if !storedExpiry.Equal(now.Add(VerificationTokenTTL)) {
+			t.Errorf("expected expiry %v, got %v", now.Add(VerificationTokenTTL), storedExpiry)
+		}
+	})
+
+	t.Run("returns error when the token cannot be stored", func(t *testing.T) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+				return db.User{}, repository.ErrNotFound
+			},
+			CreateVerificationTokenFunc: func(ctx context.Context, userID int64, tokenHash []byte, expiresAt time.Time) error {
+				return errors.New("database error")
+			},
+		}
+		mailer := &mockMailer{}
+
+		svc := &authService{
+			authRepo: authRepo,
+			userRepo: &repositorymocks.UserRepositoryMock{},
+			clock:    RealClock{},
+			hasher:   &MockHasher{},
+			mailer:   mailer,
+			tokens:   newTestSigner(t),
+		}
+
+		if _, err := svc.SignUp(context.Background(), input); err == nil {
+			t.Error("expected error, got nil")
+		}
+		if len(mailer.sent) != 0 {
+			t.Errorf("expected no email, got %d", len(mailer.sent))
+		}
+	})
+
+	t.Run("succeeds when the mailer cannot queue the email", func(t *testing.T) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+				return db.User{}, repository.ErrNotFound
+			},
+		}
+
+		svc := &authService{
+			authRepo: authRepo,
+			userRepo: &repositorymocks.UserRepositoryMock{},
+			clock:    RealClock{},
+			hasher:   &MockHasher{},
+			mailer:   &mockMailer{err: mail.ErrQueueFull},
+			tokens:   newTestSigner(t),
+		}
+
+		if _, err := svc.SignUp(context.Background(), input); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("hashes the password with the configured cost", func(t *testing.T) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+				return db.User{}, repository.ErrNotFound
+			},
+		}
+		var gotCost int
+		hasher := &MockHasher{
+			GenerateFunc: func(password []byte, cost int) ([]byte, error) {
+				gotCost = cost
+				return password, nil
+			},
+		}
+
+		svc := NewAuthService(authRepo, &repositorymocks.UserRepositoryMock{}, &mockMailer{}, nil, newTestSigner(t), "https://firecrest.example", 4, DefaultLockoutPolicy, 72*time.Hour).(*authService)
+		svc.hasher = hasher
+
+		if _, err := svc.SignUp(context.Background(), input); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotCost != 4 {
+			t.Errorf("expected bcrypt cost 4, got %d", gotCost)
+		}
+	})
+
+	t.Run("returns ErrEmailExists when the email is taken during sign up", func(t *testing.T) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+				return db.User{}, repository.ErrNotFound
+			},
+		}
+		userRepo := &repositorymocks.UserRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateUserParams) (db.User, error) {
+				return db.User{}, repository.ErrConflict
+			},
+		}
+
+		svc := &authService{
+			authRepo: authRepo,
+			userRepo: userRepo,
+			clock:    RealClock{},
+			hasher:   &MockHasher{},
+			mailer:   &mockMailer{},
+			tokens:   newTestSigner(t),
+		}
+
+		if _, err := svc.SignUp(context.Background(), input); !errors.Is(err, ErrEmailExists) {
+			t.Errorf("expected ErrEmailExists, got %v", err)
+		}
+	})
+
+	t.Run("normalises the email before validating it", func(t *testing.T) {
+		var looked, created string
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+				looked = email
+				return db.User{}, repository.ErrNotFound
+			},
+		}
+		userRepo := &repositorymocks.UserRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateUserParams) (db.User, error) {
+				created = params.Email
+				return db.User{ID: 7, Email: params.Email}, nil
+			},
+		}
+
+		svc := &authService{
+			authRepo: authRepo,
+			userRepo: userRepo,
+			clock:    RealClock{},
+			hasher:   &MockHasher{},
+			mailer:   &mockMailer{},
+			tokens:   newTestSigner(t),
+		}
+
+		padded := input
+		padded.Email = "  Jane+Ultra@Example.COM \t"
+		if _, err := svc.SignUp(context.Background(), padded); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if looked != "jane+ultra@example.com" || created != "jane+ultra@example.com" {
+			t.Errorf("expected the normalised email to be looked up and stored, got %q and %q", looked, created)
+		}
+	})
+	t.Run("reports every invalid field", func(t *testing.T) {
+		svc := &authService{authRepo: &repositorymocks.AuthRepositoryMock{}, userRepo: &repositorymocks.UserRepositoryMock{}, clock: RealClock{}, hasher: &MockHasher{}}
+
+		_, err := svc.SignUp(context.Background(), SignUpInput{Email: "not-an-email", Password: "short", LastName: "Doe"})
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("expected ErrInvalidInput, got %v", err)
+		}
+		var fields FieldErrors
+		if !errors.As(err, &fields) {
+			t.Fatalf("expected field errors, got %v", err)
+		}
+		want := FieldErrors{
+			"email":      "invalid email format",
+			"password":   fmt.Sprintf("password must be at least %d characters", MinPasswordLength),
+			"first_name": "first name is required",
+		}
+		if !maps.Equal(fields, want) {
+			t.Errorf("expected %v, got %v", want, fields)
+		}
+	})
+}
+
+func TestAuthService_VerifyEmailToken(t *testing.T) {
+	signer := newTestSigner(t)
+	sign := func(purpose token.Purpose, userID int64) string {
+		t.Helper()
+		tok, err := signer.SignToken(purpose, userID, VerificationTokenTTL)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return tok
+	}
+
+	t.Run("verifies the user owning the token", func(t *testing.T) {
+		tok := sign(token.PurposeEmailVerification, 7)
+		var verifiedID int64
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			ConsumeVerificationTokenFunc: func(ctx context.Context, tokenHash []byte) (int64, error) {
+				want := sha256.Sum256([]byte(tok))
+				if string(tokenHash) != string(want[:]) {
+					t.Error("expected token to be hashed before lookup")
+				}
+				return 7, nil
+			},
+			VerifyEmailFunc: func(ctx context.Context, userID int64) error {
+				verifiedID = userID
+				return nil
+			},
+		}
+
+		svc := &authService{authRepo: authRepo, tokens: signer}
+
+		if err := svc.VerifyEmailToken(context.Background(), tok); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if verifiedID != 7 {
+			t.Errorf("expected user 7 to be verified, got %d", verifiedID)
+		}
+	})
+
+	t.Run("returns ErrInvalidToken for used or unknown tokens", func(t *testing.T) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			ConsumeVerificationTokenFunc: func(ctx context.Context, tokenHash []byte) (int64, error) {
+				return 0, repository.ErrNotFound
+			},
+		}
+
+		svc := &authService{authRepo: authRepo, tokens: signer}
+
+		err := svc.VerifyEmailToken(context.Background(), sign(token.PurposeEmailVerification, 7))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrInvalidToken when the stored token belongs to another user", func(t *testing.T) {
+		authRepo := &repositorymocks.AuthRepositoryMock{
+			ConsumeVerificationTokenFunc: func(ctx context.Context, tokenHash []byte) (int64, error) {
+				return 8, nil
+			},
+			VerifyEmailFunc: func(ctx context.Context, userID int64) error {
+				t.Error("expected no user to be verified")
+				return nil
+			},
+		}
+
+		svc := &authService{authRepo: authRepo, tokens: signer}
+
+		err := svc.VerifyEmailToken(context.Background(), sign(token.PurposeEmailVerification, 7))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	for name, tok := range map[string]string{
+		"empty":         "",
+		"legacy format": "q1w2e3r4t5y6u7i8o9p0q1w2e3r4t5y6u7i8o9p0a1s",
+		"wrong purpose": sign(token.PurposePasswordReset, 7),
+		"tampered":      strings.Replace(sign(token.PurposeEmailVerification, 7), ":7:", ":8:", 1),
+	} {
+		t.Run("returns ErrInvalidToken without a lookup for "+name+" tokens", func(t *testing.T) {
+			authRepo := &repositorymocks.AuthRepositoryMock{
+				ConsumeVerificationTokenFunc: func(ctx context.Context, tokenHash []byte) (int64, error) {
+					t.Error("expected no database lookup")
+					return 7, nil
+				},
+			}
+
+			svc := &authService{authRepo: authRepo, tokens: signer}
+
+			err := svc.VerifyEmailToken(context.Background(), tok)
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_DeleteAccount(t *testing.T) {
+	hasher := &MockHasher{
+		CompareFunc: func(hashedPassword, password []byte) error {
+			if string(hashedPassword) == "hashed_password" && string(password) == "correct_password" {
+				return nil
+			}
+			return bcrypt.ErrMismatchedHashAndPassword
+		},
+	}
+	authRepo := &repositorymocks.AuthRepositoryMock{
+		GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+			return db.AuthCredential{UserID: userID, PasswordHash: "hashed_password"}, nil
+		},
+	}
+
+	t.Run("anonymises the user after checking their password", func(t *testing.T) {
+		var anonymised int64
+		userRepo := &repositorymocks.UserRepositoryMock{AnonymiseFunc: func(ctx context.Context, id int64) error {
+			anonymised = id
+			return nil
+		}}
+		svc := &authService{authRepo: authRepo, userRepo: userRepo, clock: RealClock{}, hasher: hasher}
+
+		if err := svc.DeleteAccount(context.Background(), 7, "correct_password"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if anonymised != 7 {
+			t.Errorf("expected user 7 to be anonymised, got %d", anonymised)
+		}
+	})
+
+	t.Run("keeps the account when the password is wrong", func(t *testing.T) {
+		userRepo := &repositorymocks.UserRepositoryMock{AnonymiseFunc: func(ctx context.Context, id int64) error {
+			t.Error("expected the account to be kept")
+			return nil
+		}}
+		svc := &authService{authRepo: authRepo, userRepo: userRepo, clock: RealClock{}, hasher: hasher}
+
+		if err := svc.DeleteAccount(context.Background(), 7, "wrong_password"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("requires a password", func(t *testing.T) {
+		svc := &authService{authRepo: authRepo, userRepo: &repositorymocks.UserRepositoryMock{}, clock: RealClock{}, hasher: hasher}
+
+		err := svc.DeleteAccount(context.Background(), 7, "")
+		var fields FieldErrors
+		if !errors.As(err, &fields) || fields["password"] == "" {
+			t.Errorf("expected a password field error, got %v", err)
+		}
+	})
+
+	t.Run("rejects accounts without a password", func(t *testing.T) {
+		noCreds := &repositorymocks.AuthRepositoryMock{
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+				return db.AuthCredential{}, repository.ErrNotFound
+			},
+		}
+		svc := &authService{authRepo: noCreds, userRepo: &repositorymocks.UserRepositoryMock{}, clock: RealClock{}, hasher: hasher}
+
+		if err := svc.DeleteAccount(context.Background(), 7, "correct_password"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		repoErr := errors.New("database unavailable")
+		userRepo := &repositorymocks.UserRepositoryMock{AnonymiseFunc: func(ctx context.Context, id int64) error {
+			return repoErr
+		}}
+		svc := &authService{authRepo: authRepo, userRepo: userRepo, clock: RealClock{}, hasher: hasher}
+
+		if err := svc.DeleteAccount(context.Background(), 7, "correct_password"); !errors.Is(err, repoErr) {
+			t.Errorf("expected the repository error, got %v", err)
+		}
+	})
+}
+
+func TestAuthService_Unsubscribe(t *testing.T) {
+	signer := newTestSigner(t)
+	sign := func(t *testing.T, purpose token.Purpose, userID int64) string {
+		t.Helper()
+		tok, err := signer.SignToken(purpose, userID, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return tok
+	}
+
+	t.Run("limits the token's user to transactional email", func(t *testing.T) {
+		var gotID int64
+		var gotPref db.EmailPreference
+		userRepo := &repositorymocks.UserRepositoryMock{SetEmailPreferenceFunc: func(ctx context.Context, id int64, pref db.EmailPreference) error {
+			gotID, gotPref = id, pref
+			return nil
+		}}
+		svc := &authService{userRepo: userRepo, tokens: signer, clock: RealClock{}}
+
+		if err := svc.Unsubscribe(context.Background(), sign(t, token.PurposeUnsubscribe, 7)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotID != 7 || gotPref != db.EmailPreferenceTransactional {
+			t.Errorf("expected user 7 to be set to transactional email, got user %d set to %q", gotID, gotPref)
+		}
+	})
+
+	t.Run("rejects tokens issued for another purpose", func(t *testing.T) {
+		userRepo := &repositorymocks.UserRepositoryMock{SetEmailPreferenceFunc: func(ctx context.Context, id int64, pref db.EmailPreference) error {
+			t.Error("expected the preference to be left alone")
+			return nil
+		}}
+		svc := &authService{userRepo: userRepo, tokens: signer, clock: RealClock{}}
+
+		if err := svc.Unsubscribe(context.Background(), sign(t, token.PurposeEmailVerification, 7)); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("succeeds for a deleted account", func(t *testing.T) {
+		userRepo := &repositorymocks.UserRepositoryMock{SetEmailPreferenceFunc: func(ctx context.Context, id int64, pref db.EmailPreference) error {
+			return repository.ErrNotFound
+		}}
+		svc := &authService{userRepo: userRepo, tokens: signer, clock: RealClock{}}
+
+		if err := svc.Unsubscribe(context.Background(), sign(t, token.PurposeUnsubscribe, 7)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		repoErr := errors.New("database unavailable")
+		userRepo := &repositorymocks.UserRepositoryMock{SetEmailPreferenceFunc: func(ctx context.Context, id int64, pref db.EmailPreference) error {
+			return repoErr
+		}}
+		svc := &authService{userRepo: userRepo, tokens: signer, clock: RealClock{}}
+
+		if err := svc.Unsubscribe(context.Background(), sign(t, token.PurposeUnsubscribe, 7)); !errors.Is(err, repoErr) {
+			t.Errorf("expected the repository error, got %v", err)
+		}
+	})
+}
+
+func TestAuthService_UnlockAccount(t *testing.T) {
+	users := map[int64]db.User{
+		1: {ID: 1, Email: "admin@example.com", Role: db.UserRoleAdmin},
+		2: {ID: 2, Email: "organiser@example.com", Role: db.UserRoleOrganizer},
+		3: {ID: 3, Email: "jane@example.com", Role: db.UserRoleEntrant},
+	}
+	userRepo := &repositorymocks.UserRepositoryMock{GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+		user, ok := users[id]
+		if !ok {
+			return db.User{}, repository.ErrNotFound
+		}
+		return user, nil
+	}}
+	hasher := &MockHasher{
+		CompareFunc: func(hashedPassword, password []byte) error {
+			if string(hashedPassword) == "hashed_password" && string(password) == "correct_password" {
+				return nil
+			}
+			return bcrypt.ErrMismatchedHashAndPassword
+		},
+	}
+
+	// lockedRepo holds Jane's credentials locked out, clearing them when
+	// unlocked
+	lockedRepo := func(unlockedBy *int64) *repositorymocks.AuthRepositoryMock {
+		creds := db.AuthCredential{
+			UserID:              3,
+			PasswordHash:        "hashed_password",
+			EmailVerifiedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+			FailedLoginAttempts: MaxLoginAttempts,
+			LockedUntil:         pgtype.Timestamptz{Time: time.Now().Add(AccountLockoutDuration), Valid: true},
+		}
+		return &repositorymocks.AuthRepositoryMock{
+			GetUserByEmailFunc: func(ctx context.Context, email string) (db.User, error) {
+				return users[3], nil
+			},
+			GetCredentialsByUserIDFunc: func(ctx context.Context, userID int64) (db.AuthCredential, error) {
+				return creds, nil
+			},
+			IsAccountLockedFunc: func(ctx context.Context, userID int64) (bool, error) {
+				return creds.LockedUntil.Valid, nil
+			},
+			UnlockAccountFunc: func(ctx context.Context, userID, adminUserID int64) error {
+				if userID != creds.UserID {
+					return repository.ErrNotFound
+				}
+				creds.FailedLoginAttempts = 0
+				creds.LockedUntil = pgtype.Timestamptz{}
+				*unlockedBy = adminUserID
+				return nil
+			},
+		}
+	}
+
+	t.Run("lets the user sign in straight after an admin unlocks them", func(t *testing.T) {
+		var unlockedBy int64
+		svc := &authService{authRepo: lockedRepo(&unlockedBy), userRepo: userRepo, clock: RealClock{}, hasher: hasher}
+		signIn := SignInInput{Email: "jane@example.com", Password: "correct_password"}
+
+		if _, err := svc.SignIn(context.Background(), signIn); !errors.Is(err, ErrAccountLocked) {
+			t.Fatalf("expected ErrAccountLocked before unlocking, got %v", err)
+		}
+		if err := svc.UnlockAccount(context.Background(), 1, 3); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if unlockedBy != 1 {
+			t.Errorf("expected the unlock to be recorded against the admin, got %d", unlockedBy)
+		}
+		result, err := svc.SignIn(context.Background(), signIn)
+		if err != nil {
+			t.Fatalf("expected sign-in to succeed after unlocking, got %v", err)
+		}
+		if result.User.ID != 3 {
+			t.Errorf("expected user 3 to sign in, got %d", result.User.ID)
+		}
+	})
+
+	t.Run("forbids anyone but an admin", func(t *testing.T) {
+		for _, callerID := range []int64{2, 3} {
+			var unlockedBy int64
+			svc := &authService{authRepo: lockedRepo(&unlockedBy), userRepo: userRepo, clock: RealClock{}, hasher: hasher}
+
+			if err := svc.UnlockAccount(context.Background(), callerID, 3); !errors.Is(err, ErrForbidden) {
+				t.Errorf("expected ErrForbidden for user %d, got %v", callerID, err)
+			}
+			if unlockedBy != 0 {
+				t.Errorf("expected user %d not to unlock the account", callerID)
+			}
+		}
+	})
+
+	t.Run("returns ErrNotFound for a user without credentials", func(t *testing.T) {
+		var unlockedBy int64
+		svc := &authService{authRepo: lockedRepo(&unlockedBy), userRepo: userRepo, clock: RealClock{}, hasher: hasher}
+
+		if err := svc.UnlockAccount(context.Background(), 1, 99); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
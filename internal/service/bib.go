@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/repository"
+)
+
+// ErrInvalidBib is returned for bib numbers below one.
+var ErrInvalidBib = apperr.New(apperr.CodeInvalidBib, http.StatusUnprocessableEntity, "bib must be a positive number")
+
+// BibRange is an inclusive range of bib numbers, such as those an
+// organiser keeps back for invited runners. The zero value is empty.
+type BibRange struct {
+	From int
+	To   int
+}
+
+// Contains reports whether bib falls within the range.
+func (r BibRange) Contains(bib int) bool {
+	return r.From > 0 && bib >= r.From && bib <= r.To
+}
+
+func (s *registrationService) AssignBibNumbers(ctx context.Context, raceID int64, startAt int) (int, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.AssignBibNumbers")
+	defer span.End()
+
+	if startAt < 1 {
+		return 0, ErrInvalidBib
+	}
+
+	registrations, err := s.registrationRepo.ListBibs(ctx, raceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list bibs: %w", err)
+	}
+
+	assignments := numberBibs(registrations, startAt, s.reservedBibs)
+	if len(assignments) == 0 {
+		return 0, nil
+	}
+	assigned, err := s.registrationRepo.AssignBibs(ctx, assignments)
+	if err != nil {
+		// A bib given out since the list was read
+		if errors.Is(err, repository.ErrConflict) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to assign bibs: %w", err)
+	}
+	return int(assigned), nil
+}
+
+// numberBibs picks bibs for the confirmed registrations without one, in the
+// order given, counting up from startAt past any bib already taken or
+// reserved.
+func numberBibs(registrations []db.ListRaceBibsRow, startAt int, reserved BibRange) []repository.BibAssignment {
+	taken := make(map[string]bool, len(registrations))
+	for _, reg := range registrations {
+		if reg.Bib.Valid {
+			taken[reg.Bib.String] = true
+		}
+	}
+
+	var assignments []repository.BibAssignment
+	next := startAt
+	for _, reg := range registrations {
+		if reg.Status != db.RegistrationStatusConfirmed || reg.Bib.Valid {
+			continue
+		}
+		for {
+			if reserved.Contains(next) {
+				next = reserved.To + 1
+				continue
+			}
+			if !taken[strconv.Itoa(next)] {
+				break
+			}
+			next++
+		}
+		assignments = append(assignments, repository.BibAssignment{
+			RegistrationID: reg.ID,
+			Bib:            strconv.Itoa(next),
+		})
+		next++
+	}
+	return assignments
+}
+
+func (s *registrationService) SetBib(ctx context.Context, registrationID int64, bib int) error {
+	ctx, span := startSpan(ctx, "RegistrationService.SetBib")
+	defer span.End()
+
+	if bib < 1 {
+		return ErrInvalidBib
+	}
+	err := s.registrationRepo.SetBib(ctx, registrationID, strconv.Itoa(bib))
+	if err != nil && !errors.Is(err, repository.ErrConflict) && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to set bib: %w", err)
+	}
+	return err
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestRegistrationService_AssignBibNumbers(t *testing.T) {
+	const raceID int64 = 20
+
+	confirmed := func(id int64, bib string) db.ListRaceBibsRow {
+		return db.ListRaceBibsRow{ID: id, Status: db.RegistrationStatusConfirmed, Bib: pgtype.Text{String: bib, Valid: bib != ""}}
+	}
+
+	newService := func(rows []db.ListRaceBibsRow, reserved BibRange) (*registrationService, *[]repository.BibAssignment) {
+		var assigned []repository.BibAssignment
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			ListBibsFunc: func(ctx context.Context, id int64) ([]db.ListRaceBibsRow, error) {
+				if id != raceID {
+					t.Errorf("expected race %d, got %d", raceID, id)
+				}
+				return rows, nil
+			},
+			AssignBibsFunc: func(ctx context.Context, assignments []repository.BibAssignment) (int64, error) {
+				assigned = assignments
+				return int64(len(assignments)), nil
+			},
+		}
+		svc := NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, &mockPaymentService{}, &mockDiscountService{}, nil, &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 0, 100, reserved).(*registrationService)
+		return svc, &assigned
+	}
+
+	t.Run("numbers confirmed entrants in the order they registered", func(t *testing.T) {
+		svc, assigned := newService([]db.ListRaceBibsRow{
+			confirmed(3, ""),
+			{ID: 4, Status: db.RegistrationStatusPending},
+			confirmed(1, ""),
+			confirmed(2, ""),
+		}, BibRange{})
+
+		n, err := svc.AssignBibNumbers(context.Background(), raceID, 100)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []repository.BibAssignment{
+			{RegistrationID: 3, Bib: "100"},
+			{RegistrationID: 1, Bib: "101"},
+			{RegistrationID: 2, Bib: "102"},
+		}
+		if n != 3 || !reflect.DeepEqual(*assigned, want) {
+			t.Errorf("expected %v, got %d: %v", want, n, *assigned)
+		}
+	})
+
+	t.Run("skips the reserved range", func(t *testing.T) {
+		svc, assigned := newService([]db.ListRaceBibsRow{
+			confirmed(1, ""),
+			confirmed(2, ""),
+			confirmed(3, ""),
+		}, BibRange{From: 2, To: 9})
+
+		if _, err := svc.AssignBibNumbers(context.Background(), raceID, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []repository.BibAssignment{
+			{RegistrationID: 1, Bib: "1"},
+			{RegistrationID: 2, Bib: "10"},
+			{RegistrationID: 3, Bib: "11"},
+		}
+		if !reflect.DeepEqual(*assigned, want) {
+			t.Errorf("expected %v, got %v", want, *assigned)
+		}
+	})
+
+	t.Run("starts after a reserved range it begins in", func(t *testing.T) {
+		svc, assigned := newService([]db.ListRaceBibsRow{confirmed(1, "")}, BibRange{From: 1, To: 99})
+
+		if _, err := svc.AssignBibNumbers(context.Background(), raceID, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := (*assigned)[0].Bib; got != "100" {
+			t.Errorf("expected bib 100, got %s", got)
+		}
+	})
+
+	t.Run("keeps existing bibs and fills the gaps between them", func(t *testing.T) {
+		svc, assigned := newService([]db.ListRaceBibsRow{
+			confirmed(1, "1"),
+			confirmed(2, "3"),
+			{ID: 3, Status: db.RegistrationStatusPending, Bib: pgtype.Text{String: "4", Valid: true}},
+			confirmed(4, "A7"),
+			confirmed(5, ""),
+			confirmed(6, ""),
+		}, BibRange{})
+
+		if _, err := svc.AssignBibNumbers(context.Background(), raceID, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []repository.BibAssignment{
+			{RegistrationID: 5, Bib: "2"},
+			{RegistrationID: 6, Bib: "5"},
+		}
+		if !reflect.DeepEqual(*assigned, want) {
+			t.Errorf("expected %v, got %v", want, *assigned)
+		}
+	})
+
+	t.Run("does nothing once everyone has a bib", func(t *testing.T) {
+		calls := 0
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			ListBibsFunc: func(ctx context.Context, id int64) ([]db.ListRaceBibsRow, error) {
+				return []db.ListRaceBibsRow{confirmed(1, "1"), confirmed(2, "2")}, nil
+			},
+			AssignBibsFunc: func(ctx context.Context, assignments []repository.BibAssignment) (int64, error) {
+				calls++
+				return 0, nil
+			},
+		}
+		svc := NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, &mockPaymentService{}, &mockDiscountService{}, nil, &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 0, 100, BibRange{})
+
+		n, err := svc.AssignBibNumbers(context.Background(), raceID, 1)
+		if err != nil || n != 0 {
+			t.Errorf("expected nothing assigned, got %d, %v", n, err)
+		}
+		if calls != 0 {
+			t.Errorf("expected no write, got %d", calls)
+		}
+	})
+
+	t.Run("rejects a start below one", func(t *testing.T) {
+		svc, _ := newService(nil, BibRange{})
+		if _, err := svc.AssignBibNumbers(context.Background(), raceID, 0); !errors.Is(err, ErrInvalidBib) {
+			t.Errorf("expected ErrInvalidBib, got %v", err)
+		}
+	})
+
+	t.Run("reports bibs taken meanwhile as a conflict", func(t *testing.T) {
+		svc, _ := newService([]db.ListRaceBibsRow{confirmed(1, "")}, BibRange{})
+		svc.registrationRepo.(*repositorymocks.RegistrationRepositoryMock).AssignBibsFunc = func(ctx context.Context, assignments []repository.BibAssignment) (int64, error) {
+			return 0, repository.ErrConflict
+		}
+		if _, err := svc.AssignBibNumbers(context.Background(), raceID, 1); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+}
+
+func TestRegistrationService_SetBib(t *testing.T) {
+	newService := func(repo *repositorymocks.RegistrationRepositoryMock) RegistrationService {
+		return NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, &mockPaymentService{}, &mockDiscountService{}, nil, &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 0, 100, BibRange{From: 1, To: 99})
+	}
+
+	t.Run("stores the bib, even in the reserved range", func(t *testing.T) {
+		var gotID int64
+		var gotBib string
+		svc := newService(&repositorymocks.RegistrationRepositoryMock{SetBibFunc: func(ctx context.Context, id int64, bib string) error {
+			gotID, gotBib = id, bib
+			return nil
+		}})
+
+		if err := svc.SetBib(context.Background(), 5, 7); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotID != 5 || gotBib != "7" {
+			t.Errorf("expected bib 7 on registration 5, got %q on %d", gotBib, gotID)
+		}
+	})
+
+	t.Run("rejects a bib that is taken", func(t *testing.T) {
+		svc := newService(&repositorymocks.RegistrationRepositoryMock{SetBibFunc: func(ctx context.Context, id int64, bib string) error {
+			return repository.ErrConflict
+		}})
+		if err := svc.SetBib(context.Background(), 5, 7); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("rejects bibs below one", func(t *testing.T) {
+		svc := newService(&repositorymocks.RegistrationRepositoryMock{SetBibFunc: func(ctx context.Context, id int64, bib string) error {
+			t.Error("expected nothing to be stored")
+			return nil
+		}})
+		if err := svc.SetBib(context.Background(), 5, -1); !errors.Is(err, ErrInvalidBib) {
+			t.Errorf("expected ErrInvalidBib, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/imaging"
+	"firecrest/internal/repository"
+	"firecrest/internal/storage"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/branding.go -pkg servicemocks . BrandingService
+
+// Sizes of the copies of branding images event pages show, as the longest
+// side in pixels. Banners are shown as wide as event photos.
+const (
+	LogoSize   = 400
+	BannerSize = PhotoWebSize
+)
+
+// brandColour matches the only form a brand colour may take. It is written
+// into page styles, so nothing else gets through.
+var brandColour = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// BrandingService defines the interface for organisations' branding of
+// their event pages.
+//
+// Organisations may give a colour their event pages use in place of the
+// site's, and a logo and banner image, kept in the blob store. Pages fall
+// back to the site's look for anything left unset.
+type BrandingService interface {
+	// SetBranding replaces the organisation's branding with input, keeping
+	// its logo and banner unless new ones are given or they are removed.
+	// It returns FieldErrors for a colour that is not #rrggbb and for
+	// images UploadPhoto would refuse, and repository.ErrNotFound if the
+	// organisation does not exist.
+	SetBranding(ctx context.Context, organisationID int64, input BrandingInput) (db.Organisation, error)
+	// BrandingImageURL returns a URL the logo or banner stored under key
+	// can be fetched from for the next PhotoURLTTL.
+	BrandingImageURL(ctx context.Context, key string) (string, error)
+}
+
+// BrandingInput is an organisation's branding as submitted.
+type BrandingInput struct {
+	// Colour is a hex colour such as #1a2b3c, or blank for the site's own
+	Colour string
+	// Logo and Banner are new images, or nil to keep the current ones
+	Logo   io.Reader
+	Banner io.Reader
+	// RemoveLogo and RemoveBanner drop the current images without
+	// replacing them
+	RemoveLogo   bool
+	RemoveBanner bool
+}
+
+// NormalizeColour returns the colour in the form it is stored in: trimmed
+// and lower case.
+func (i BrandingInput) NormalizeColour() string {
+	return strings.ToLower(strings.TrimSpace(i.Colour))
+}
+
+// Validate checks the colour, reporting it as FieldErrors. Images are
+// checked as they are read.
+func (i BrandingInput) Validate() error {
+	errs := FieldErrors{}
+	if c := i.NormalizeColour(); c != "" && !brandColour.MatchString(c) {
+		errs.Add("brand_colour", "colour must be a hex colour such as #1a2b3c")
+	}
+	return errs.Err()
+}
+
+type brandingService struct {
+	orgRepo repository.OrganisationRepository
+	store   storage.BlobStore
+}
+
+// NewBrandingService creates a new BrandingService keeping branding in
+// orgRepo and its images in store.
+func NewBrandingService(orgRepo repository.OrganisationRepository, store storage.BlobStore) BrandingService {
+	return &brandingService{orgRepo: orgRepo, store: store}
+}
+
+func (s *brandingService) SetBranding(ctx context.Context, organisationID int64, input BrandingInput) (db.Organisation, error) {
+	ctx, span := startSpan(ctx, "BrandingService.SetBranding")
+	defer span.End()
+
+	org, err := s.orgRepo.Get(ctx, organisationID)
+	if err != nil {
+		return db.Organisation{}, err
+	}
+
+	errs := FieldErrors{}
+	var verr FieldErrors
+	if err := input.Validate(); errors.As(err, &verr) {
+		for field, msg := range verr {
+			errs.Add(field, msg)
+		}
+	}
+	var logo, banner *bytes.Buffer
+	if input.Logo != nil {
+		if logo, err = readBrandingImage(input.Logo, "logo", LogoSize, png.Encode, errs); err != nil {
+			return db.Organisation{}, err
+		}
+	}
+	if input.Banner != nil {
+		if banner, err = readBrandingImage(input.Banner, "banner", BannerSize, encodeJPEG, errs); err != nil {
+			return db.Organisation{}, err
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return db.Organisation{}, err
+	}
+
+	colour := input.NormalizeColour()
+	params := db.SetOrganisationBrandingParams{
+		ID:          org.ID,
+		BrandColour: pgtype.Text{String: colour, Valid: colour != ""},
+		LogoKey:     org.LogoKey,
+		BannerKey:   org.BannerKey,
+	}
+	if input.RemoveLogo {
+		params.LogoKey = pgtype.Text{}
+	}
+	if input.RemoveBanner {
+		params.BannerKey = pgtype.Text{}
+	}
+
+	var added []string
+	if logo != nil {
+		key := fmt.Sprintf("organisations/%d/logo-%s.png", org.ID, rand.Text())
+		if err := s.store.Put(ctx, key, logo, "image/png"); err != nil {
+			return db.Organisation{}, fmt.Errorf("failed to store logo: %w", err)
+		}
+		added = append(added, key)
+		params.LogoKey = pgtype.Text{String: key, Valid: true}
+	}
+	if banner != nil {
+		key := fmt.Sprintf("organisations/%d/banner-%s.jpg", org.ID, rand.Text())
+		if err := s.store.Put(ctx, key, banner, "image/jpeg"); err != nil {
+			return db.Organisation{}, errors.Join(fmt.Errorf("failed to store banner: %w", err), deleteFiles(ctx, s.store, added...))
+		}
+		added = append(added, key)
+		params.BannerKey = pgtype.Text{String: key, Valid: true}
+	}
+
+	if err := s.orgRepo.SetBranding(ctx, params); err != nil {
+		return db.Organisation{}, errors.Join(err, deleteFiles(ctx, s.store, added...))
+	}
+
+	// Images no longer used are left behind if they cannot be deleted;
+	// nothing points at them, and the branding is saved
+	var replaced []string
+	for _, key := range []pgtype.Text{org.LogoKey, org.BannerKey} {
+		if key.Valid && key != params.LogoKey && key != params.BannerKey {
+			replaced = append(replaced, key.String)
+		}
+	}
+	_ = deleteFiles(ctx, s.store, replaced...)
+
+	org.BrandColour, org.LogoKey, org.BannerKey = params.BrandColour, params.LogoKey, params.BannerKey
+	return org, nil
+}
+
+// readBrandingImage reads the uploaded image and returns the copy pages
+// show, fitted within size and written with encode. Logos are kept as PNGs
+// for their transparency. Images that will not do are reported in errs
+// under field, returning a nil copy.
+func readBrandingImage(r io.Reader, field string, size int, encode func(io.Writer, image.Image) error, errs FieldErrors) (*bytes.Buffer, error) {
+	upload, err := readImage(r, field)
+	var imgErrs FieldErrors
+	if errors.As(err, &imgErrs) {
+		for f, msg := range imgErrs {
+			errs.Add(f, msg)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, imaging.Fit(upload.img, size)); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", field, err)
+	}
+	return &buf, nil
+}
+
+// encodeJPEG writes img as a JPEG of the quality photos' web copies have.
+func encodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: photoWebQuality})
+}
+
+func (s *brandingService) BrandingImageURL(ctx context.Context, key string) (string, error) {
+	return s.store.SignedURL(ctx, key, PhotoURLTTL)
+}
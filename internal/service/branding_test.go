@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestBrandingInput_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		colour string
+		valid  bool
+	}{
+		{name: "accepts a hex colour", colour: "#1a2b3c", valid: true},
+		{name: "accepts upper case", colour: "#1A2B3C", valid: true},
+		{name: "accepts surrounding space", colour: " #1a2b3c ", valid: true},
+		{name: "accepts no colour", colour: "", valid: true},
+		{name: "rejects a CSS injection", colour: "red; background:url(https://evil.example/x.png)"},
+		{name: "rejects a hex colour with more after it", colour: "#1a2b3c; color: red"},
+		{name: "rejects a hex colour over two lines", colour: "#1a2b3c\n#000000"},
+		{name: "rejects a colour name", colour: "red"},
+		{name: "rejects short hex", colour: "#abc"},
+		{name: "rejects hex without the hash", colour: "1a2b3c"},
+		{name: "rejects non-hex digits", colour: "#1a2b3g"},
+		{name: "rejects other colour functions", colour: "rgb(1, 2, 3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BrandingInput{Colour: tt.colour}.Validate()
+
+			var errs FieldErrors
+			if tt.valid && err != nil {
+				t.Errorf("expected %q to be valid, got %v", tt.colour, err)
+			}
+			if !tt.valid && (!errors.As(err, &errs) || errs["brand_colour"] == "") {
+				t.Errorf("expected a brand_colour error for %q, got %v", tt.colour, err)
+			}
+		})
+	}
+}
+
+func TestBrandingService_SetBranding(t *testing.T) {
+	ctx := context.Background()
+	org := db.Organisation{
+		ID:        3,
+		Name:      "Peak Running",
+		LogoKey:   pgtype.Text{String: "organisations/3/logo-old.png", Valid: true},
+		BannerKey: pgtype.Text{String: "organisations/3/banner-old.jpg", Valid: true},
+	}
+
+	newRepo := func(got *db.SetOrganisationBrandingParams) *repositorymocks.OrganisationRepositoryMock {
+		return &repositorymocks.OrganisationRepositoryMock{
+			GetFunc: func(ctx context.Context, id int64) (db.Organisation, error) {
+				if id != org.ID {
+					return db.Organisation{}, repository.ErrNotFound
+				}
+				return org, nil
+			},
+			SetBrandingFunc: func(ctx context.Context, params db.SetOrganisationBrandingParams) error {
+				*got = params
+				return nil
+			},
+		}
+	}
+
+	t.Run("stores a new logo fitted to size and deletes the old one", func(t *testing.T) {
+		store := newTestStore(t)
+		for _, key := range []string{org.LogoKey.String, org.BannerKey.String} {
+			if err := store.Put(ctx, key, strings.NewReader("old"), "image/png"); err != nil {
+				t.Fatalf("failed to store %s: %v", key, err)
+			}
+		}
+		var got db.SetOrganisationBrandingParams
+		svc := NewBrandingService(newRepo(&got), store)
+
+		saved, err := svc.SetBranding(ctx, org.ID, BrandingInput{Colour: "#1A2B3C", Logo: bytes.NewReader(testPNG(t, 1200, 600))})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.BrandColour.String != "#1a2b3c" || saved.BrandColour != got.BrandColour {
+			t.Errorf("expected the colour stored in lower case, got %+v", got.BrandColour)
+		}
+		if got.BannerKey != org.BannerKey {
+			t.Errorf("expected the banner kept, got %+v", got.BannerKey)
+		}
+		if !got.LogoKey.Valid || got.LogoKey == org.LogoKey || saved.LogoKey != got.LogoKey {
+			t.Fatalf("expected a new logo key, got %+v", got.LogoKey)
+		}
+
+		rc, err := store.Get(ctx, got.LogoKey.String)
+		if err != nil {
+			t.Fatalf("failed to read the new logo: %v", err)
+		}
+		defer rc.Close()
+		logo, err := png.DecodeConfig(rc)
+		if err != nil {
+			t.Fatalf("expected the logo stored as a PNG: %v", err)
+		}
+		if logo.Width != LogoSize || logo.Height != LogoSize/2 {
+			t.Errorf("expected a %dx%d logo, got %dx%d", LogoSize, LogoSize/2, logo.Width, logo.Height)
+		}
+		if _, err := store.Get(ctx, org.LogoKey.String); err == nil {
+			t.Error("expected the old logo to be deleted")
+		}
+		if rc, err := store.Get(ctx, org.BannerKey.String); err != nil {
+			t.Error("expected the banner to be kept")
+		} else {
+			rc.Close()
+		}
+	})
+
+	t.Run("removes images and the colour", func(t *testing.T) {
+		var got db.SetOrganisationBrandingParams
+		svc := NewBrandingService(newRepo(&got), newTestStore(t))
+
+		if _, err := svc.SetBranding(ctx, org.ID, BrandingInput{RemoveLogo: true, RemoveBanner: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.BrandColour.Valid || got.LogoKey.Valid || got.BannerKey.Valid {
+			t.Errorf("expected the branding cleared, got %+v", got)
+		}
+	})
+
+	t.Run("reports every problem without saving", func(t *testing.T) {
+		repo := newRepo(new(db.SetOrganisationBrandingParams))
+		svc := NewBrandingService(repo, newTestStore(t))
+
+		_, err := svc.SetBranding(ctx, org.ID, BrandingInput{
+			Colour: "red; background:url(x)",
+			Logo:   strings.NewReader("not an image"),
+			Banner: bytes.NewReader(testPNG(t, 10, 10)),
+		})
+
+		var errs FieldErrors
+		if !errors.As(err, &errs) || errs["brand_colour"] == "" || errs["logo"] != "logo must be a JPEG or PNG image" {
+			t.Fatalf("expected colour and logo errors, got %v", err)
+		}
+		if _, ok := errs["banner"]; ok {
+			t.Errorf("expected no error for a good banner, got %q", errs["banner"])
+		}
+		if len(repo.SetBrandingCalls()) != 0 {
+			t.Error("expected nothing to be saved")
+		}
+	})
+
+	t.Run("returns ErrNotFound for an unknown organisation", func(t *testing.T) {
+		svc := NewBrandingService(newRepo(new(db.SetOrganisationBrandingParams)), newTestStore(t))
+
+		if _, err := svc.SetBranding(ctx, 99, BrandingInput{}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
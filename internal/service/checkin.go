@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"firecrest/internal/apperr"
+	"firecrest/internal/repository"
+)
+
+// CheckInUndoWindow is how long after checking an entrant in a marshal may
+// undo it. Later, the check-in stands.
+const CheckInUndoWindow = 5 * time.Minute
+
+// ErrCheckInFinal is returned for undoing a check-in made longer ago than
+// CheckInUndoWindow.
+var ErrCheckInFinal = apperr.New(apperr.CodeCheckInFinal, http.StatusConflict, "check-in can no longer be undone")
+
+func (s *registrationService) CheckIn(ctx context.Context, raceID, registrationID int64) error {
+	ctx, span := startSpan(ctx, "RegistrationService.CheckIn")
+	defer span.End()
+
+	err := s.registrationRepo.CheckIn(ctx, raceID, registrationID, s.clock.Now())
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to check in: %w", err)
+	}
+	return err
+}
+
+func (s *registrationService) UndoCheckIn(ctx context.Context, raceID, registrationID int64) error {
+	ctx, span := startSpan(ctx, "RegistrationService.UndoCheckIn")
+	defer span.End()
+
+	err := s.registrationRepo.UndoCheckIn(ctx, raceID, registrationID, s.clock.Now().Add(-CheckInUndoWindow))
+	switch {
+	case errors.Is(err, repository.ErrConflict):
+		return ErrCheckInFinal
+	case err != nil && !errors.Is(err, repository.ErrNotFound):
+		return fmt.Errorf("failed to undo check-in: %w", err)
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestRegistrationService_CheckIn(t *testing.T) {
+	checkedInAt := time.Date(2026, 5, 2, 8, 30, 0, 0, time.UTC)
+
+	// newService backs the service with a single registration, 5 in race
+	// 20, whose check-in is kept as the queries keep it
+	newService := func(clock *MockClock) (*registrationService, *time.Time) {
+		var stored time.Time
+		registrations := &repositorymocks.RegistrationRepositoryMock{
+			CheckInFunc: func(ctx context.Context, raceID, id int64, at time.Time) error {
+				if raceID != 20 || id != 5 {
+					return repository.ErrNotFound
+				}
+				if stored.IsZero() {
+					stored = at
+				}
+				return nil
+			},
+			UndoCheckInFunc: func(ctx context.Context, raceID, id int64, after time.Time) error {
+				switch {
+				case raceID != 20 || id != 5:
+					return repository.ErrNotFound
+				case stored.IsZero():
+					return nil
+				case !stored.After(after):
+					return repository.ErrConflict
+				}
+				stored = time.Time{}
+				return nil
+			},
+		}
+		return &registrationService{registrationRepo: registrations, clock: clock}, &stored
+	}
+
+	t.Run("records the time and keeps it when checked in again", func(t *testing.T) {
+		clock := &MockClock{CurrentTime: checkedInAt}
+		svc, stored := newService(clock)
+
+		if err := svc.CheckIn(context.Background(), 20, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		clock.CurrentTime = checkedInAt.Add(time.Minute)
+		if err := svc.CheckIn(context.Background(), 20, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !stored.Equal(checkedInAt) {
+			t.Errorf("expected the first check-in kept, got %v", *stored)
+		}
+	})
+
+	t.Run("undoes a check-in within the window", func(t *testing.T) {
+		clock := &MockClock{CurrentTime: checkedInAt}
+		svc, stored := newService(clock)
+		if err := svc.CheckIn(context.Background(), 20, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		clock.CurrentTime = checkedInAt.Add(CheckInUndoWindow - time.Second)
+		if err := svc.UndoCheckIn(context.Background(), 20, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !stored.IsZero() {
+			t.Errorf("expected the check-in cleared, got %v", *stored)
+		}
+		if err := svc.UndoCheckIn(context.Background(), 20, 5); err != nil {
+			t.Errorf("expected undoing again to do nothing, got %v", err)
+		}
+	})
+
+	t.Run("refuses to undo once the window has passed", func(t *testing.T) {
+		clock := &MockClock{CurrentTime: checkedInAt}
+		svc, stored := newService(clock)
+		if err := svc.CheckIn(context.Background(), 20, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		clock.CurrentTime = checkedInAt.Add(CheckInUndoWindow)
+		if err := svc.UndoCheckIn(context.Background(), 20, 5); !errors.Is(err, ErrCheckInFinal) {
+			t.Errorf("expected ErrCheckInFinal, got %v", err)
+		}
+		if !stored.Equal(checkedInAt) {
+			t.Errorf("expected the check-in kept, got %v", *stored)
+		}
+	})
+
+	t.Run("reports registrations the race does not have", func(t *testing.T) {
+		svc, _ := newService(&MockClock{CurrentTime: checkedInAt})
+
+		if err := svc.CheckIn(context.Background(), 21, 5); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound checking in, got %v", err)
+		}
+		if err := svc.UndoCheckIn(context.Background(), 20, 6); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound undoing, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/mail"
+	"firecrest/internal/repository"
+	"firecrest/internal/token"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/contact.go -pkg servicemocks . ContactService
+
+const (
+	// MaxEnquiryNameLength bounds the name a sender gives, in characters.
+	MaxEnquiryNameLength = 100
+	// MaxEnquiryLength bounds an enquiry's message, in characters.
+	MaxEnquiryLength = 5000
+	// MaxEnquiryLinks is the most links a message may carry. Spam is
+	// mostly links; questions rarely need many.
+	MaxEnquiryLinks = 3
+	// ContactFormMinTime is how long a contact form must have been open
+	// before it is sent. People take longer than this to write a question;
+	// bots filling forms in often do not.
+	ContactFormMinTime = 3 * time.Second
+	// ContactFormTTL is how long a contact form can be sent after it was
+	// served.
+	ContactFormTTL = 24 * time.Hour
+	// EnquiryLogSize bounds how many of an event's enquiries are shown.
+	EnquiryLogSize = 100
+)
+
+// Contact form errors
+var (
+	// ErrSpam is returned for an enquiry that looks sent by a bot: its
+	// hidden honeypot field was filled in, or its form was not served
+	// here. Callers should answer as though it was sent, so bots learn
+	// nothing.
+	ErrSpam = apperr.New(apperr.CodeSpam, http.StatusUnprocessableEntity, "enquiry looks automated")
+	// ErrContactFormTiming is returned for a contact form sent too soon
+	// after it was served for a person to have filled it in, or after
+	// ContactFormTTL.
+	ErrContactFormTiming = apperr.New(apperr.CodeContactFormTiming, http.StatusUnprocessableEntity, "contact form sent too soon or too late")
+)
+
+// enquiryLink finds the links in a message, a whole link at a time.
+var enquiryLink = regexp.MustCompile(`(?i)(?:https?://|\bwww\.)\S*`)
+
+// ContactService defines the interface for contacting events' organisers.
+//
+// Senders never see the organisers' addresses: their questions are relayed
+// by email to the organisation's contact address, or to its owners if it
+// has none, with replies going straight back to the sender.
+type ContactService interface {
+	// FormStamp returns the value of the hidden field that dates a contact
+	// form served now.
+	FormStamp() (string, error)
+	// SendEnquiry records a question about the event and relays it to the
+	// event's organisers. It returns ErrSpam for an enquiry that looks
+	// automated, ErrContactFormTiming for a form sent too soon or too late,
+	// and FieldErrors for a name, email or message that will not do.
+	SendEnquiry(ctx context.Context, event db.Event, input EnquiryInput) error
+	// ListEnquiries returns the event's latest enquiries, newest first, up
+	// to EnquiryLogSize.
+	ListEnquiries(ctx context.Context, eventID int64) ([]db.EventEnquiry, error)
+	// SetContactEmail sets where enquiries about the organisation's events
+	// go. Blank sends them to its owners. It returns
+	// repository.ErrNotFound if the organisation does not exist.
+	SetContactEmail(ctx context.Context, organisationID int64, email string) error
+}
+
+// EnquiryInput is a question as sent through an event's contact form.
+type EnquiryInput struct {
+	Name    string
+	Email   string
+	Message string
+	// Honeypot is a field hidden from people, so only bots fill it in
+	Honeypot string
+	// Stamp is the FormStamp the form was served with
+	Stamp string
+}
+
+type contactService struct {
+	enquiryRepo repository.EnquiryRepository
+	orgRepo     repository.OrganisationRepository
+	mailer      mail.Mailer
+	tokens      *token.Signer
+	baseURL     string
+	clock       Clock
+}
+
+// NewContactService creates a new ContactService. Enquiries are sent
+// through mailer with links rooted at baseURL, and forms are dated with
+// stamps signed by tokens.
+func NewContactService(
+	enquiryRepo repository.EnquiryRepository,
+	orgRepo repository.OrganisationRepository,
+	mailer mail.Mailer,
+	tokens *token.Signer,
+	baseURL string,
+) ContactService {
+	return &contactService{
+		enquiryRepo: enquiryRepo,
+		orgRepo:     orgRepo,
+		mailer:      mailer,
+		tokens:      tokens,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		clock:       RealClock{},
+	}
+}
+
+func (s *contactService) FormStamp() (string, error) {
+	return s.tokens.SignTime(token.PurposeContactForm, s.clock.Now())
+}
+
+// Validate checks if the input is valid, reporting every problem as
+// FieldErrors.
+func (i EnquiryInput) Validate() error {
+	errs := FieldErrors{}
+	switch name := strings.TrimSpace(i.Name); {
+	case name == "":
+		errs.Add("name", "name is required")
+	case utf8.RuneCountInString(name) > MaxEnquiryNameLength:
+		errs.Add("name", fmt.Sprintf("name must be at most %d characters", MaxEnquiryNameLength))
+	}
+	if msg := emailProblem(NormalizeEmail(i.Email)); msg != "" {
+		errs.Add("email", msg)
+	}
+	switch message := strings.TrimSpace(i.Message); {
+	case message == "":
+		errs.Add("message", "message is required")
+	case utf8.RuneCountInString(message) > MaxEnquiryLength:
+		errs.Add("message", fmt.Sprintf("message must be at most %d characters", MaxEnquiryLength))
+	case len(enquiryLink.FindAllString(message, -1)) > MaxEnquiryLinks:
+		errs.Add("message", fmt.Sprintf("message must contain at most %d links", MaxEnquiryLinks))
+	}
+	return errs.Err()
+}
+
+func (s *contactService) SendEnquiry(ctx context.Context, event db.Event, input EnquiryInput) error {
+	ctx, span := startSpan(ctx, "ContactService.SendEnquiry")
+	defer span.End()
+
+	if input.Honeypot != "" {
+		return ErrSpam
+	}
+	served, err := s.tokens.VerifyTime(token.PurposeContactForm, input.Stamp)
+	if err != nil {
+		return ErrSpam
+	}
+	if open := s.clock.Now().Sub(served); open < ContactFormMinTime || open > ContactFormTTL {
+		return ErrContactFormTiming
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	recipients, err := s.recipients(ctx, event.OrganisationID)
+	if err != nil {
+		return err
+	}
+	enquiry, err := s.enquiryRepo.Create(ctx, db.CreateEventEnquiryParams{
+		EventID: event.ID,
+		// Names go into a header of the relayed email, so keep them to
+		// one line
+		Name:    strings.Join(strings.Fields(input.Name), " "),
+		Email:   NormalizeEmail(input.Email),
+		Message: strings.TrimSpace(input.Message),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record enquiry: %w", err)
+	}
+
+	data := mail.EventEnquiryData{
+		EventName:    event.Name,
+		SenderName:   enquiry.Name,
+		SenderEmail:  enquiry.Email,
+		Message:      enquiry.Message,
+		EnquiriesURL: s.baseURL + "/admin/events/" + strconv.FormatInt(event.ID, 10) + "/enquiries",
+	}
+	for _, to := range recipients {
+		msg, err := mail.EventEnquiryMessage(to, data)
+		if err != nil {
+			return fmt.Errorf("failed to build enquiry email: %w", err)
+		}
+		_ = s.mailer.Send(ctx, msg)
+	}
+	return nil
+}
+
+// recipients returns where enquiries about the organisation's events go:
+// its contact address or, without one, its owners.
+func (s *contactService) recipients(ctx context.Context, organisationID int64) ([]string, error) {
+	org, err := s.orgRepo.Get(ctx, organisationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organisation: %w", err)
+	}
+	if org.ContactEmail.String != "" {
+		return []string{org.ContactEmail.String}, nil
+	}
+
+	members, err := s.orgRepo.ListMembers(ctx, organisationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	var owners []string
+	for _, m := range members {
+		if m.Role == db.OrganisationRoleOwner {
+			owners = append(owners, m.Email)
+		}
+	}
+	return owners, nil
+}
+
+func (s *contactService) ListEnquiries(ctx context.Context, eventID int64) ([]db.EventEnquiry, error) {
+	return s.enquiryRepo.ListByEvent(ctx, eventID, EnquiryLogSize)
+}
+
+func (s *contactService) SetContactEmail(ctx context.Context, organisationID int64, email string) error {
+	email = NormalizeEmail(email)
+	if email != "" {
+		if msg := emailProblem(email); msg != "" {
+			return FieldErrors{"contact_email": msg}
+		}
+	}
+	return s.orgRepo.SetContactEmail(ctx, organisationID, pgtype.Text{String: email, Valid: email != ""})
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+)
+
+func TestContactService_SendEnquiry(t *testing.T) {
+	served := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	event := db.Event{ID: 12, OrganisationID: 7, Name: "Harbour 10K"}
+
+	// setup returns a service whose clock reads now, recording enquiries
+	// for an organisation with the contact address contact
+	setup := func(t *testing.T, contact string) (*contactService, *repositorymocks.EnquiryRepositoryMock, *mockMailer) {
+		enquiryRepo := &repositorymocks.EnquiryRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateEventEnquiryParams) (db.EventEnquiry, error) {
+				return db.EventEnquiry{ID: 1, EventID: params.EventID, Name: params.Name, Email: params.Email, Message: params.Message}, nil
+			},
+		}
+		orgRepo := &repositorymocks.OrganisationRepositoryMock{
+			GetFunc: func(ctx context.Context, id int64) (db.Organisation, error) {
+				return db.Organisation{ID: id, ContactEmail: pgtype.Text{String: contact, Valid: contact != ""}}, nil
+			},
+			ListMembersFunc: func(ctx context.Context, organisationID int64) ([]db.ListOrganisationMembersRow, error) {
+				return []db.ListOrganisationMembersRow{
+					{UserID: 1, Role: db.OrganisationRoleOwner, Email: "owner@example.com"},
+					{UserID: 2, Role: db.OrganisationRoleStaff, Email: "helper@example.com"},
+				}, nil
+			},
+		}
+		mailer := &mockMailer{}
+		svc := NewContactService(enquiryRepo, orgRepo, mailer, newTestSigner(t), "https://firecrest.example").(*contactService)
+		svc.clock = &MockClock{CurrentTime: served}
+		return svc, enquiryRepo, mailer
+	}
+	// input returns an enquiry sent open after its form was served
+	input := func(t *testing.T, svc *contactService, open time.Duration) EnquiryInput {
+		t.Helper()
+		stamp, err := svc.FormStamp()
+		if err != nil {
+			t.Fatalf("failed to stamp form: %v", err)
+		}
+		svc.clock = &MockClock{CurrentTime: served.Add(open)}
+		return EnquiryInput{
+			Name:    "Ada  Lovelace",
+			Email:   "Ada@Example.com",
+			Message: "Is there parking near the start?",
+			Stamp:   stamp,
+		}
+	}
+
+	t.Run("relays the enquiry to the contact address", func(t *testing.T) {
+		svc, enquiryRepo, mailer := setup(t, "races@harbour.example")
+
+		err := svc.SendEnquiry(context.Background(), event, input(t, svc, time.Minute))
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		calls := enquiryRepo.CreateCalls()
+		if len(calls) != 1 || calls[0].Params.EventID != 12 || calls[0].Params.Name != "Ada Lovelace" || calls[0].Params.Email != "ada@example.com" {
+			t.Fatalf("expected the enquiry recorded, got %+v", calls)
+		}
+		if len(mailer.sent) != 1 {
+			t.Fatalf("expected one email, got %d", len(mailer.sent))
+		}
+		msg := mailer.sent[0]
+		if msg.To != "races@harbour.example" || msg.ReplyTo != `"Ada Lovelace" <ada@example.com>` {
+			t.Errorf("expected the email to the contact address with replies to the sender, got to %q, reply-to %q", msg.To, msg.ReplyTo)
+		}
+		if !strings.Contains(msg.Text, "https://firecrest.example/admin/events/12/enquiries") {
+			t.Errorf("expected a link to the enquiries, got %q", msg.Text)
+		}
+	})
+
+	t.Run("falls back to the owners without a contact address", func(t *testing.T) {
+		svc, _, mailer := setup(t, "")
+
+		if err := svc.SendEnquiry(context.Background(), event, input(t, svc, time.Minute)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mailer.sent) != 1 || mailer.sent[0].To != "owner@example.com" {
+			t.Errorf("expected one email to the owner, got %+v", mailer.sent)
+		}
+	})
+
+	t.Run("treats a filled honeypot as spam", func(t *testing.T) {
+		svc, enquiryRepo, mailer := setup(t, "races@harbour.example")
+		in := input(t, svc, time.Minute)
+		in.Honeypot = "https://cheap-watches.example"
+
+		err := svc.SendEnquiry(context.Background(), event, in)
+
+		if !errors.Is(err, ErrSpam) {
+			t.Errorf("expected ErrSpam, got %v", err)
+		}
+		if len(enquiryRepo.CreateCalls()) != 0 || len(mailer.sent) != 0 {
+			t.Error("expected nothing recorded or sent")
+		}
+	})
+
+	t.Run("treats a form not served here as spam", func(t *testing.T) {
+		svc, _, mailer := setup(t, "races@harbour.example")
+		in := input(t, svc, time.Minute)
+		in.Stamp = "v1:contact-form:1777626000000:forged"
+
+		if err := svc.SendEnquiry(context.Background(), event, in); !errors.Is(err, ErrSpam) {
+			t.Errorf("expected ErrSpam, got %v", err)
+		}
+		if len(mailer.sent) != 0 {
+			t.Error("expected nothing sent")
+		}
+	})
+
+	t.Run("checks how long the form was open", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			open    time.Duration
+			wantErr error
+		}{
+			{name: "too soon", open: ContactFormMinTime - time.Millisecond, wantErr: ErrContactFormTiming},
+			{name: "just long enough", open: ContactFormMinTime},
+			{name: "on its last moment", open: ContactFormTTL},
+			{name: "too late", open: ContactFormTTL + time.Millisecond, wantErr: ErrContactFormTiming},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				svc, _, mailer := setup(t, "races@harbour.example")
+
+				err := svc.SendEnquiry(context.Background(), event, input(t, svc, tt.open))
+
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if sent := len(mailer.sent) == 1; sent != (tt.wantErr == nil) {
+					t.Errorf("expected sent %v, got %d emails", tt.wantErr == nil, len(mailer.sent))
+				}
+			})
+		}
+	})
+
+	t.Run("refuses an invalid enquiry", func(t *testing.T) {
+		svc, enquiryRepo, _ := setup(t, "races@harbour.example")
+		in := input(t, svc, time.Minute)
+		in.Email = "not-an-email"
+
+		err := svc.SendEnquiry(context.Background(), event, in)
+
+		var fieldErrs FieldErrors
+		if !errors.As(err, &fieldErrs) || fieldErrs["email"] == "" {
+			t.Fatalf("expected an email field error, got %v", err)
+		}
+		if len(enquiryRepo.CreateCalls()) != 0 {
+			t.Error("expected nothing recorded")
+		}
+	})
+}
+
+func TestEnquiryInput_Validate(t *testing.T) {
+	valid := EnquiryInput{Name: "Ada", Email: "ada@example.com", Message: "Is there parking?"}
+	links := func(n int) string {
+		return strings.Repeat("see https://example.com/page and ", n)
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(*EnquiryInput)
+		wantField string
+	}{
+		{name: "valid", modify: func(*EnquiryInput) {}},
+		{name: "blank name", modify: func(i *EnquiryInput) { i.Name = " " }, wantField: "name"},
+		{name: "name too long", modify: func(i *EnquiryInput) { i.Name = strings.Repeat("a", MaxEnquiryNameLength+1) }, wantField: "name"},
+		{name: "blank message", modify: func(i *EnquiryInput) { i.Message = "" }, wantField: "message"},
+		{name: "longest message", modify: func(i *EnquiryInput) { i.Message = strings.Repeat("é", MaxEnquiryLength) }},
+		{name: "message too long", modify: func(i *EnquiryInput) { i.Message = strings.Repeat("a", MaxEnquiryLength+1) }, wantField: "message"},
+		{name: "most links", modify: func(i *EnquiryInput) { i.Message = links(MaxEnquiryLinks) }},
+		{name: "too many links", modify: func(i *EnquiryInput) { i.Message = links(MaxEnquiryLinks + 1) }, wantField: "message"},
+		{name: "bare www links count", modify: func(i *EnquiryInput) { i.Message = links(MaxEnquiryLinks) + " www.example.com" }, wantField: "message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.modify(&input)
+
+			err := input.Validate()
+
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var fieldErrs FieldErrors
+			if !errors.As(err, &fieldErrs) || fieldErrs[tt.wantField] == "" {
+				t.Errorf("expected a %s field error, got %v", tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestContactService_SetContactEmail(t *testing.T) {
+	var got pgtype.Text
+	orgRepo := &repositorymocks.OrganisationRepositoryMock{
+		SetContactEmailFunc: func(ctx context.Context, organisationID int64, email pgtype.Text) error {
+			got = email
+			return nil
+		},
+	}
+	svc := NewContactService(&repositorymocks.EnquiryRepositoryMock{}, orgRepo, &mockMailer{}, newTestSigner(t), "")
+
+	if err := svc.SetContactEmail(context.Background(), 7, " Races@Harbour.example "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != (pgtype.Text{String: "races@harbour.example", Valid: true}) {
+		t.Errorf("expected the address normalised, got %+v", got)
+	}
+
+	if err := svc.SetContactEmail(context.Background(), 7, ""); err != nil || got.Valid {
+		t.Errorf("expected blank to clear the address, got %+v, %v", got, err)
+	}
+
+	var fieldErrs FieldErrors
+	if err := svc.SetContactEmail(context.Background(), 7, "races"); !errors.As(err, &fieldErrs) || fieldErrs["contact_email"] == "" {
+		t.Errorf("expected a contact_email field error, got %v", err)
+	}
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"firecrest/db"
+	"firecrest/internal/repository"
+)
+
+// MaxCustomFields is how many custom fields a race's entry form may ask.
+const MaxCustomFields = 20
+
+// Custom field limits, in characters and options.
+const (
+	MaxCustomFieldLabelLength  = 100
+	MaxCustomFieldOptions      = 30
+	MaxCustomFieldOptionLength = 50
+	MaxCustomAnswerLength      = 200
+)
+
+// CustomField is a question an organiser adds to a race's entry form, as
+// it was defined at one version. Editing a field gives it a new version;
+// answers keep the version they were given for.
+type CustomField struct {
+	ID      int64
+	Version int32
+	Label   string
+	Kind    db.CustomFieldKind
+	// Options are the choices of a select field, in the order shown
+	Options  []string
+	Required bool
+}
+
+// newCustomField converts a stored definition.
+func newCustomField(v db.RaceCustomFieldVersion) CustomField {
+	return CustomField{
+		ID:       v.FieldID,
+		Version:  v.Version,
+		Label:    v.Label,
+		Kind:     v.Kind,
+		Options:  v.Options,
+		Required: v.Required,
+	}
+}
+
+// FormName is the name the field's input has in entry forms.
+func (f CustomField) FormName() string {
+	return "custom_" + strconv.FormatInt(f.ID, 10)
+}
+
+// Answer checks value, as posted for the field, and returns it as the
+// field's answer. Checkboxes are ticked by any non-empty value. ok is false
+// for a blank answer to an optional field, which is not stored.
+func (f CustomField) Answer(value string) (answer CustomAnswer, ok bool, err error) {
+	value = strings.TrimSpace(value)
+	if f.Kind == db.CustomFieldKindCheckbox {
+		if f.Required && value == "" {
+			return CustomAnswer{}, false, fmt.Errorf("%s must be ticked", f.Label)
+		}
+		return CustomAnswer{Version: f.Version, Value: value != ""}, true, nil
+	}
+
+	switch {
+	case value == "" && f.Required:
+		return CustomAnswer{}, false, fmt.Errorf("%s is required", f.Label)
+	case value == "":
+		return CustomAnswer{}, false, nil
+	case f.Kind == db.CustomFieldKindSelect && !slices.Contains(f.Options, value):
+		return CustomAnswer{}, false, fmt.Errorf("%s must be one of the choices given", f.Label)
+	case utf8.RuneCountInString(value) > MaxCustomAnswerLength:
+		return CustomAnswer{}, false, fmt.Errorf("%s must be at most %d characters", f.Label, MaxCustomAnswerLength)
+	}
+	return CustomAnswer{Version: f.Version, Value: value}, true, nil
+}
+
+// CustomFieldInput is a custom field as an organiser defines it.
+type CustomFieldInput struct {
+	Label    string
+	Kind     string
+	Options  []string
+	Required bool
+}
+
+// Validate checks if the input is valid, reporting every problem as
+// FieldErrors. Select fields need at least two options, and only they may
+// have any.
+func (i CustomFieldInput) Validate() error {
+	errs := FieldErrors{}
+	switch label := strings.TrimSpace(i.Label); {
+	case label == "":
+		errs.Add("label", "label is required")
+	case utf8.RuneCountInString(label) > MaxCustomFieldLabelLength:
+		errs.Add("label", fmt.Sprintf("label must be at most %d characters", MaxCustomFieldLabelLength))
+	}
+
+	options := i.options()
+	switch kind := db.CustomFieldKind(i.Kind); kind {
+	case db.CustomFieldKindText, db.CustomFieldKindCheckbox:
+		if len(options) > 0 {
+			errs.Add("options", "only select fields have options")
+		}
+	case db.CustomFieldKindSelect:
+		switch {
+		case len(options) < 2:
+			errs.Add("options", "a select field needs at least 2 options")
+		case len(options) > MaxCustomFieldOptions:
+			errs.Add("options", fmt.Sprintf("a select field can have at most %d options", MaxCustomFieldOptions))
+		}
+	default:
+		errs.Add("kind", "type must be text, select or checkbox")
+	}
+	for _, o := range options {
+		if utf8.RuneCountInString(o) > MaxCustomFieldOptionLength {
+			errs.Add("options", fmt.Sprintf("options must be at most %d characters", MaxCustomFieldOptionLength))
+		}
+	}
+	return errs.Err()
+}
+
+// options returns the input's options trimmed, without blanks or repeats.
+func (i CustomFieldInput) options() []string {
+	var options []string
+	for _, o := range i.Options {
+		if o = strings.TrimSpace(o); o != "" && !slices.Contains(options, o) {
+			options = append(options, o)
+		}
+	}
+	return options
+}
+
+// params returns the stored form of the input's definition.
+func (i CustomFieldInput) params() db.CreateRaceCustomFieldVersionParams {
+	// Stored as an empty array rather than NULL for fields without options
+	options := i.options()
+	if options == nil {
+		options = []string{}
+	}
+	return db.CreateRaceCustomFieldVersionParams{
+		Label:    strings.TrimSpace(i.Label),
+		Kind:     db.CustomFieldKind(i.Kind),
+		Options:  options,
+		Required: i.Required,
+	}
+}
+
+// CustomAnswer is an entrant's answer to a custom field. Answers are
+// stored as JSON with the value typed: a string for text and select
+// fields, a bool for checkboxes.
+type CustomAnswer struct {
+	// Version is the version of the field the answer was given for
+	Version int32 `json:"version"`
+	Value   any   `json:"value"`
+}
+
+// Text returns the answer as organisers are shown it: the text given, or
+// "Yes" or "No" for a checkbox.
+func (a CustomAnswer) Text() string {
+	switch v := a.Value.(type) {
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case string:
+		return v
+	}
+	return ""
+}
+
+// CustomAnswers are an entrant's answers to their race's custom fields,
+// keyed by field ID.
+type CustomAnswers map[int64]CustomAnswer
+
+// CustomFieldVersions holds every definition a race's custom fields have
+// had, removed fields' included, so answers can be read against the
+// version they were given for.
+type CustomFieldVersions map[int64][]CustomField
+
+// Get returns the field's definition at version.
+func (v CustomFieldVersions) Get(fieldID int64, version int32) (CustomField, bool) {
+	i := slices.IndexFunc(v[fieldID], func(f CustomField) bool { return f.Version == version })
+	if i < 0 {
+		return CustomField{}, false
+	}
+	return v[fieldID][i], true
+}
+
+// Latest returns the field's definition at its newest version, which for a
+// removed field is the one it had when it was removed.
+func (v CustomFieldVersions) Latest(fieldID int64) (CustomField, bool) {
+	versions := v[fieldID]
+	if len(versions) == 0 {
+		return CustomField{}, false
+	}
+	return slices.MaxFunc(versions, func(a, b CustomField) int { return int(a.Version - b.Version) }), true
+}
+
+// checkCustomAnswers checks the values posted for fields, keyed by field
+// ID, adding their problems to errs keyed by each field's FormName. Values
+// for fields the race does not ask are ignored.
+func checkCustomAnswers(fields []CustomField, values map[int64]string, errs FieldErrors) CustomAnswers {
+	answers := CustomAnswers{}
+	for _, f := range fields {
+		answer, ok, err := f.Answer(values[f.ID])
+		if err != nil {
+			errs.Add(f.FormName(), err.Error())
+			continue
+		}
+		if ok {
+			answers[f.ID] = answer
+		}
+	}
+	return answers
+}
+
+// decodeCustomAnswers reads answers stored as JSON.
+func decodeCustomAnswers(raw []byte) (CustomAnswers, error) {
+	var answers CustomAnswers
+	if err := json.Unmarshal(raw, &answers); err != nil {
+		return nil, fmt.Errorf("failed to decode custom answers: %w", err)
+	}
+	return answers, nil
+}
+
+// listCustomFields returns the race's current custom fields in form order.
+func listCustomFields(ctx context.Context, raceRepo repository.RaceRepository, raceID int64) ([]CustomField, error) {
+	rows, err := raceRepo.ListCustomFields(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	fields := make([]CustomField, len(rows))
+	for i, row := range rows {
+		fields[i] = newCustomField(row)
+	}
+	return fields, nil
+}
+
+func (s *raceService) CustomFields(ctx context.Context, raceID int64) ([]CustomField, error) {
+	return listCustomFields(ctx, s.raceRepo, raceID)
+}
+
+func (s *raceService) CustomFieldVersions(ctx context.Context, raceID int64) (CustomFieldVersions, error) {
+	rows, err := s.raceRepo.ListCustomFieldVersions(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom field versions: %w", err)
+	}
+	versions := CustomFieldVersions{}
+	for _, row := range rows {
+		versions[row.FieldID] = append(versions[row.FieldID], newCustomField(row))
+	}
+	return versions, nil
+}
+
+func (s *raceService) CreateCustomField(ctx context.Context, raceID int64, input CustomFieldInput) (CustomField, error) {
+	if err := input.Validate(); err != nil {
+		return CustomField{}, err
+	}
+	created, err := s.raceRepo.CreateCustomField(ctx, raceID, input.params(), MaxCustomFields)
+	if err != nil {
+		if errors.Is(err, repository.ErrCustomFieldLimit) {
+			return CustomField{}, FieldErrors{"label": fmt.Sprintf("a race can ask at most %d custom fields", MaxCustomFields)}
+		}
+		return CustomField{}, err
+	}
+	return newCustomField(created), nil
+}
+
+func (s *raceService) UpdateCustomField(ctx context.Context, raceID, fieldID int64, input CustomFieldInput) (CustomField, error) {
+	if err := input.Validate(); err != nil {
+		return CustomField{}, err
+	}
+	params := input.params()
+	params.FieldID = fieldID
+	updated, err := s.raceRepo.UpdateCustomField(ctx, raceID, params)
+	if err != nil {
+		return CustomField{}, err
+	}
+	return newCustomField(updated), nil
+}
+
+func (s *raceService) DeleteCustomField(ctx context.Context, raceID, fieldID int64) error {
+	return s.raceRepo.DeleteCustomField(ctx, raceID, fieldID)
+}
+
+func (s *raceService) MoveCustomField(ctx context.Context, raceID, fieldID int64, offset int) error {
+	fields, err := s.CustomFields(ctx, raceID)
+	if err != nil {
+		return err
+	}
+	from := slices.IndexFunc(fields, func(f CustomField) bool { return f.ID == fieldID })
+	if from < 0 {
+		return repository.ErrNotFound
+	}
+	to := min(max(from+offset, 0), len(fields)-1)
+	if to == from {
+		return nil
+	}
+
+	ids := make([]int64, len(fields))
+	for i, f := range fields {
+		ids[i] = f.ID
+	}
+	ids = slices.Insert(slices.Delete(ids, from, from+1), to, fieldID)
+	return s.raceRepo.SetCustomFieldOrder(ctx, raceID, ids)
+}
+
+func (s *raceService) ReorderCustomFields(ctx context.Context, raceID int64, fieldIDs []int64) error {
+	fields, err := s.CustomFields(ctx, raceID)
+	if err != nil {
+		return err
+	}
+	current := make([]int64, len(fields))
+	for i, f := range fields {
+		current[i] = f.ID
+	}
+	sorted := slices.Sorted(slices.Values(fieldIDs))
+	slices.Sort(current)
+	if !slices.Equal(sorted, current) {
+		return fmt.Errorf("%w: the order must list each of the race's fields once", ErrInvalidInput)
+	}
+	return s.raceRepo.SetCustomFieldOrder(ctx, raceID, fieldIDs)
+}
+
+func (s *registrationService) ListRaceCustomAnswers(ctx context.Context, raceID int64) (map[int64]CustomAnswers, error) {
+	rows, err := s.registrationRepo.ListCustomAnswers(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom answers: %w", err)
+	}
+	answers := make(map[int64]CustomAnswers, len(rows))
+	for _, row := range rows {
+		a, err := decodeCustomAnswers(row.Answers)
+		if err != nil {
+			return nil, fmt.Errorf("registration %d: %w", row.RegistrationID, err)
+		}
+		answers[row.RegistrationID] = a
+	}
+	return answers, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestCustomFieldInput_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input CustomFieldInput
+		field string
+	}{
+		{name: "blank labels", input: CustomFieldInput{Label: " ", Kind: "text"}, field: "label"},
+		{name: "long labels", input: CustomFieldInput{Label: strings.Repeat("a", MaxCustomFieldLabelLength+1), Kind: "text"}, field: "label"},
+		{name: "unknown kinds", input: CustomFieldInput{Label: "Club", Kind: "radio"}, field: "kind"},
+		{name: "selects with one option", input: CustomFieldInput{Label: "T-shirt size", Kind: "select", Options: []string{"M", " M ", ""}}, field: "options"},
+		{name: "options on a checkbox", input: CustomFieldInput{Label: "Ferry crossing", Kind: "checkbox", Options: []string{"Yes", "No"}}, field: "options"},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			err := tt.input.Validate()
+
+			var fe FieldErrors
+			if !errors.As(err, &fe) || fe[tt.field] == "" {
+				t.Errorf("expected a %s field error, got %v", tt.field, err)
+			}
+		})
+	}
+
+	t.Run("accepts a select with its options", func(t *testing.T) {
+		input := CustomFieldInput{Label: "T-shirt size", Kind: "select", Options: []string{"S", "M", "L"}, Required: true}
+		if err := input.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestCustomField_Answer(t *testing.T) {
+	size := CustomField{ID: 5, Version: 2, Label: "T-shirt size", Kind: db.CustomFieldKindSelect, Options: []string{"S", "M", "L"}, Required: true}
+	ferry := CustomField{ID: 6, Version: 1, Label: "Ferry crossing", Kind: db.CustomFieldKindCheckbox}
+
+	t.Run("rejects a select answered outside its options", func(t *testing.T) {
+		if _, _, err := size.Answer("XXL"); err == nil || !strings.Contains(err.Error(), "one of the choices") {
+			t.Errorf("expected the answer rejected, got %v", err)
+		}
+	})
+
+	t.Run("rejects a required select left blank", func(t *testing.T) {
+		if _, _, err := size.Answer(" "); err == nil {
+			t.Error("expected the blank answer rejected")
+		}
+	})
+
+	t.Run("records the version answered", func(t *testing.T) {
+		answer, ok, err := size.Answer("M")
+		if err != nil || !ok {
+			t.Fatalf("expected the answer accepted, got %v", err)
+		}
+		if answer.Version != 2 || answer.Value != "M" {
+			t.Errorf("expected M at version 2, got %+v", answer)
+		}
+	})
+
+	t.Run("reads an unticked optional checkbox as no", func(t *testing.T) {
+		answer, ok, err := ferry.Answer("")
+		if err != nil || !ok {
+			t.Fatalf("expected the answer accepted, got %v", err)
+		}
+		if answer.Value != false || answer.Text() != "No" {
+			t.Errorf("expected false, got %+v", answer)
+		}
+	})
+}
+
+func TestRegistrationService_Register_customFields(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	race := db.Race{
+		ID:                    20,
+		EventID:               30,
+		Name:                  "10K",
+		RegistrationOpenDate:  pgtype.Timestamptz{Time: now.Add(-24 * time.Hour), Valid: true},
+		RegistrationCloseDate: pgtype.Timestamptz{Time: now.Add(24 * time.Hour), Valid: true},
+		MaxCapacity:           100,
+	}
+
+	newService := func(repo *repositorymocks.RegistrationRepositoryMock) *registrationService {
+		repo.IsEmailVerifiedFunc = func(ctx context.Context, userID int64) (bool, error) { return true, nil }
+		repo.GetActiveFunc = func(ctx context.Context, userID, raceID int64) (db.Registration, error) {
+			return db.Registration{}, repository.ErrNotFound
+		}
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListCustomFieldsFunc: func(ctx context.Context, raceID int64) ([]db.RaceCustomFieldVersion, error) {
+				return []db.RaceCustomFieldVersion{
+					{FieldID: 5, Version: 2, Label: "T-shirt size", Kind: db.CustomFieldKindSelect, Options: []string{"S", "M", "L"}, Required: true},
+					{FieldID: 6, Version: 1, Label: "Ferry crossing", Kind: db.CustomFieldKindCheckbox},
+				}, nil
+			},
+		}
+		svc := NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, raceRepo, &mockPaymentService{}, &mockDiscountService{}, nil, &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 0, 100, BibRange{}).(*registrationService)
+		svc.clock = &MockClock{CurrentTime: now}
+		return svc
+	}
+
+	t.Run("rejects a required select answered outside its options", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{}
+
+		_, err := newService(repo).Register(context.Background(), 7, race, 0, "", Answers{}, map[int64]string{5: "XXL"})
+
+		var fe FieldErrors
+		if !errors.As(err, &fe) || fe["custom_5"] == "" {
+			t.Fatalf("expected a custom_5 field error, got %v", err)
+		}
+		if len(repo.CreateCalls()) != 0 {
+			t.Error("expected no entry made")
+		}
+	})
+
+	t.Run("stores typed answers with the versions answered", func(t *testing.T) {
+		var stored []byte
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				stored = customAnswers
+				return db.Registration{ID: 100, UserID: params.UserID, RaceID: params.RaceID}, nil
+			},
+		}
+
+		if _, err := newService(repo).Register(context.Background(), 7, race, 0, "", Answers{}, map[int64]string{5: "M", 6: "1", 99: "ignored"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var got map[string]CustomAnswer
+		if err := json.Unmarshal(stored, &got); err != nil {
+			t.Fatalf("expected JSON answers, got %q: %v", stored, err)
+		}
+		if len(got) != 2 || got["5"] != (CustomAnswer{Version: 2, Value: "M"}) || got["6"] != (CustomAnswer{Version: 1, Value: true}) {
+			t.Errorf("expected the size and ferry answers, got %s", stored)
+		}
+	})
+}
+
+func TestRaceService_UpdateCustomField(t *testing.T) {
+	t.Run("saves the edit as a new version", func(t *testing.T) {
+		var got db.CreateRaceCustomFieldVersionParams
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			UpdateCustomFieldFunc: func(ctx context.Context, raceID int64, def db.CreateRaceCustomFieldVersionParams) (db.RaceCustomFieldVersion, error) {
+				got = def
+				return db.RaceCustomFieldVersion{FieldID: def.FieldID, Version: 2, Label: def.Label, Kind: def.Kind, Options: def.Options}, nil
+			},
+		}
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+
+		field, err := svc.UpdateCustomField(context.Background(), 20, 5, CustomFieldInput{Label: "Ferry", Kind: "select", Options: []string{"Outbound", "Return", "Both"}})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.FieldID != 5 || got.Kind != db.CustomFieldKindSelect || len(got.Options) != 3 {
+			t.Errorf("expected field 5's new definition saved, got %+v", got)
+		}
+		if field.Version != 2 {
+			t.Errorf("expected version 2, got %d", field.Version)
+		}
+	})
+
+	t.Run("shows old answers against the version they were given for", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListCustomFieldVersionsFunc: func(ctx context.Context, raceID int64) ([]db.RaceCustomFieldVersion, error) {
+				return []db.RaceCustomFieldVersion{
+					{FieldID: 5, Version: 1, Label: "Ferry crossing", Kind: db.CustomFieldKindCheckbox, Options: []string{}},
+					{FieldID: 5, Version: 2, Label: "Ferry", Kind: db.CustomFieldKindSelect, Options: []string{"Outbound", "Return", "Both"}},
+				}, nil
+			},
+		}
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+
+		versions, err := svc.CustomFieldVersions(context.Background(), 20)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		answers, err := decodeCustomAnswers([]byte(`{"5":{"version":1,"value":true}}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		answer := answers[5]
+		asked, ok := versions.Get(5, answer.Version)
+		if !ok || asked.Label != "Ferry crossing" || asked.Kind != db.CustomFieldKindCheckbox {
+			t.Errorf("expected the checkbox the answer was given for, got %+v", asked)
+		}
+		if answer.Text() != "Yes" {
+			t.Errorf("expected the old answer to read Yes, got %q", answer.Text())
+		}
+		if latest, _ := versions.Latest(5); latest.Label != "Ferry" || latest.Version != 2 {
+			t.Errorf("expected the edited field as latest, got %+v", latest)
+		}
+	})
+}
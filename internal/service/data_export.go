@@ -0,0 +1,451 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/export"
+	"firecrest/internal/mail"
+	"firecrest/internal/repository"
+	"firecrest/internal/secret"
+	"firecrest/internal/storage"
+	"firecrest/internal/token"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/data_export.go -pkg servicemocks . DataExportService
+
+const (
+	// DataExportTTL is how long the link to a copy of a user's data works.
+	DataExportTTL = 24 * time.Hour
+	// DataExportBatchSize bounds how many copies one run builds.
+	DataExportBatchSize = 5
+	// DataExportBuildTimeout bounds a run building a batch of copies.
+	DataExportBuildTimeout = 25 * time.Minute
+	// DataExportLease is how long a run has to build the copies it claims
+	// before another may claim them, which outlasts the run.
+	DataExportLease = DataExportBuildTimeout + 5*time.Minute
+	// dataExportPageSize is how many rows are read at a time while a copy
+	// is written, so a user with thousands is never held in memory whole.
+	dataExportPageSize = 500
+	// dataExportDownloadGrace is how long an archive is kept after its
+	// link is used, so the download in progress can finish.
+	dataExportDownloadGrace = time.Hour
+	// dataExportPruneBatch bounds how many archives one run removes.
+	dataExportPruneBatch = 100
+)
+
+// DataExportService defines the interface for giving users a copy of
+// everything held about them.
+//
+// A copy is asked for, built in the background by BuildExports, and
+// emailed to the user as a link that works once, to them, for
+// DataExportTTL. The archive is a ZIP of JSON files, each an array of
+// objects with text values: profile.json, registrations.json,
+// payments.json, sessions.json, audit-log.json for changes the user made
+// to others' records, and answers.json with their decrypted questionnaire
+// answers.
+type DataExportService interface {
+	// RequestExport asks for a copy of the user's data, returning the
+	// request already waiting to be built if there is one.
+	RequestExport(ctx context.Context, userID int64) (db.DataExport, error)
+	// LatestExport returns the user's most recent request, or
+	// repository.ErrNotFound if they have made none.
+	LatestExport(ctx context.Context, userID int64) (db.DataExport, error)
+	// BuildExports builds up to DataExportBatchSize pending copies, storing
+	// each archive and emailing its owner the link to it, and returns how
+	// many were built. A copy that cannot be built is marked failed.
+	BuildExports(ctx context.Context) (int, error)
+	// OpenExport opens the archive the token from the email links to, if
+	// the token was issued to the user, marking it downloaded so the link
+	// does not work again. It returns ErrInvalidToken if the token is
+	// forged, someone else's, used or expired.
+	OpenExport(ctx context.Context, userID int64, tok string) (io.ReadCloser, error)
+	// PruneExports removes archives that have expired or were downloaded
+	// over an hour ago from the blob store, returning how many.
+	PruneExports(ctx context.Context) (int, error)
+}
+
+type dataExportService struct {
+	exportRepo       repository.DataExportRepository
+	userRepo         repository.UserRepository
+	registrationRepo repository.RegistrationRepository
+	paymentRepo      repository.PaymentRepository
+	sessionRepo      repository.SessionRepository
+	store            storage.BlobStore
+	answersBox       *secret.Box
+	tokens           *token.Signer
+	mailer           mail.Mailer
+	baseURL          string
+	clock            Clock
+}
+
+// NewDataExportService creates a new DataExportService that reads a user's
+// data from the repositories, decrypting answers with answersBox, and keeps
+// archives in store. Links are emailed through mailer, rooted at baseURL
+// and carrying tokens signed by tokens.
+func NewDataExportService(
+	exportRepo repository.DataExportRepository,
+	userRepo repository.UserRepository,
+	registrationRepo repository.RegistrationRepository,
+	paymentRepo repository.PaymentRepository,
+	sessionRepo repository.SessionRepository,
+	store storage.BlobStore,
+	answersBox *secret.Box,
+	tokens *token.Signer,
+	mailer mail.Mailer,
+	baseURL string,
+) DataExportService {
+	return &dataExportService{
+		exportRepo:       exportRepo,
+		userRepo:         userRepo,
+		registrationRepo: registrationRepo,
+		paymentRepo:      paymentRepo,
+		sessionRepo:      sessionRepo,
+		store:            store,
+		answersBox:       answersBox,
+		tokens:           tokens,
+		mailer:           mailer,
+		baseURL:          strings.TrimRight(baseURL, "/"),
+		clock:            RealClock{},
+	}
+}
+
+func (s *dataExportService) RequestExport(ctx context.Context, userID int64) (db.DataExport, error) {
+	ctx, span := startSpan(ctx, "DataExportService.RequestExport")
+	defer span.End()
+
+	if userID <= 0 {
+		return db.DataExport{}, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+	return s.exportRepo.Request(ctx, userID)
+}
+
+func (s *dataExportService) LatestExport(ctx context.Context, userID int64) (db.DataExport, error) {
+	ctx, span := startSpan(ctx, "DataExportService.LatestExport")
+	defer span.End()
+
+	return s.exportRepo.GetLatest(ctx, userID)
+}
+
+func (s *dataExportService) BuildExports(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "DataExportService.BuildExports")
+	defer span.End()
+
+	now := s.clock.Now()
+	exports, err := s.exportRepo.ClaimPending(ctx, now, now.Add(DataExportLease), DataExportBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim data exports: %w", err)
+	}
+
+	built := 0
+	var errs []error
+	for _, e := range exports {
+		err := s.build(ctx, e)
+		switch {
+		case err == nil:
+			built++
+		case ctx.Err() != nil:
+			// Shutting down; the export is built again once its lease
+			// runs out
+			return built, errors.Join(append(errs, ctx.Err())...)
+		default:
+			errs = append(errs, fmt.Errorf("failed to build data export %d: %w", e.ID, err))
+			if err := s.exportRepo.Fail(ctx, e.ID, s.clock.Now()); err != nil {
+				errs = append(errs, fmt.Errorf("failed to mark data export %d failed: %w", e.ID, err))
+			}
+		}
+	}
+	return built, errors.Join(errs...)
+}
+
+// build writes the export's archive to the blob store, marks it ready and
+// emails its owner the link to it.
+func (s *dataExportService) build(ctx context.Context, e db.DataExport) error {
+	user, err := s.userRepo.GetByID(ctx, e.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// The archive is written as the store reads it. Closing the reader
+	// when the store gives up stops the writer part way.
+	key := fmt.Sprintf("exports/%d/%s.zip", user.ID, rand.Text())
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(s.writeArchive(ctx, pw, user))
+	}()
+	err = s.store.Put(ctx, key, pr, "application/zip")
+	pr.CloseWithError(errors.New("archive abandoned"))
+	if err != nil {
+		return fmt.Errorf("failed to store archive: %w", err)
+	}
+
+	tok, err := s.tokens.SignToken(token.PurposeDataExport, user.ID, DataExportTTL)
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to generate download token: %w", err), s.store.Delete(ctx, key))
+	}
+	hash := sha256.Sum256([]byte(tok))
+	now := s.clock.Now()
+	if err := s.exportRepo.Complete(ctx, e.ID, key, hash[:], now.Add(DataExportTTL), now); err != nil {
+		// No longer pending, as when the account was deleted while the
+		// archive was written, so nothing will ever remove it but us
+		return errors.Join(fmt.Errorf("failed to complete data export: %w", err), s.store.Delete(ctx, key))
+	}
+
+	msg, err := mail.DataExportReadyMessage(user.Email, mail.DataExportReadyData{
+		FirstName:   user.FirstName,
+		DownloadURL: s.baseURL + "/account/data-export?token=" + url.QueryEscape(tok),
+		ExpiresIn:   "24 hours",
+		SessionsURL: s.baseURL + "/account/sessions",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build data export email: %w", err)
+	}
+	// Delivery happens in the background and the mailer logs any failure
+	_ = s.mailer.Send(ctx, msg)
+	return nil
+}
+
+// writeArchive writes the ZIP of the user's data to w, a page of rows at a
+// time.
+func (s *dataExportService) writeArchive(ctx context.Context, w io.Writer, user db.User) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		columns []string
+		write   func(out export.Exporter) error
+	}{
+		{"profile.json", profileColumns, func(out export.Exporter) error {
+			return out.WriteRow(profileRow(user))
+		}},
+		{"registrations.json", registrationExportColumns, func(out export.Exporter) error {
+			return writePages(out, func(afterID int64) ([]db.ExportUserRegistrationsRow, error) {
+				return s.registrationRepo.ListForExport(ctx, user.ID, afterID, dataExportPageSize)
+			}, func(r db.ExportUserRegistrationsRow) int64 { return r.ID }, registrationExportRow)
+		}},
+		{"payments.json", paymentExportColumns, func(out export.Exporter) error {
+			return writePages(out, func(afterID int64) ([]db.Payment, error) {
+				return s.paymentRepo.ListForExport(ctx, user.ID, afterID, dataExportPageSize)
+			}, func(p db.Payment) int64 { return p.ID }, paymentExportRow)
+		}},
+		{"sessions.json", sessionExportColumns, func(out export.Exporter) error {
+			return writePages(out, func(afterID int64) ([]db.UserSession, error) {
+				return s.sessionRepo.ListForExport(ctx, user.ID, afterID, dataExportPageSize)
+			}, func(us db.UserSession) int64 { return us.ID }, sessionExportRow)
+		}},
+		{"audit-log.json", auditExportColumns, func(out export.Exporter) error {
+			return writePages(out, func(afterID int64) ([]db.AuditLog, error) {
+				return s.userRepo.ListAuditEntriesForExport(ctx, user.ID, afterID, dataExportPageSize)
+			}, func(a db.AuditLog) int64 { return a.ID }, auditExportRow)
+		}},
+		{"answers.json", answerExportColumns, func(out export.Exporter) error {
+			return writePages(out, func(afterID int64) ([]db.ExportUserRegistrationAnswersRow, error) {
+				return s.registrationRepo.ListAnswersForExport(ctx, user.ID, afterID, dataExportPageSize)
+			}, func(a db.ExportUserRegistrationAnswersRow) int64 { return a.RegistrationID }, s.answerExportRow)
+		}},
+	}
+
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: s.clock.Now()})
+		if err != nil {
+			return err
+		}
+		out, err := export.New(export.JSON, fw, f.columns)
+		if err != nil {
+			return err
+		}
+		if err := f.write(out); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writePages writes a row for each item list returns, asking for the page
+// after the last item's id until a page comes back short.
+func writePages[T any](out export.Exporter, list func(afterID int64) ([]T, error), id func(T) int64, row func(T) ([]string, error)) error {
+	var afterID int64
+	for {
+		page, err := list(afterID)
+		if err != nil {
+			return err
+		}
+		for _, item := range page {
+			values, err := row(item)
+			if err != nil {
+				return err
+			}
+			if err := out.WriteRow(values); err != nil {
+				return err
+			}
+		}
+		if len(page) < dataExportPageSize {
+			return nil
+		}
+		afterID = id(page[len(page)-1])
+	}
+}
+
+var profileColumns = []string{
+	"email", "first_name", "last_name", "date_of_birth", "phone",
+	"address_line_1", "address_line_2", "city", "state", "postal_code", "country",
+	"role", "email_preference", "distance_unit", "created_at", "deactivated_at",
+}
+
+func profileRow(u db.User) []string {
+	dob := ""
+	if u.DateOfBirth.Valid {
+		dob = u.DateOfBirth.Time.Format(time.DateOnly)
+	}
+	return []string{
+		u.Email, u.FirstName, u.LastName, dob, u.Phone.String,
+		u.AddressLine1.String, u.AddressLine2.String, u.City.String, u.State.String, u.PostalCode.String, u.Country.String,
+		string(u.Role), string(u.EmailPreference), string(u.DistanceUnit), exportTime(u.CreatedAt), exportTime(u.DeactivatedAt),
+	}
+}
+
+var registrationExportColumns = []string{
+	"id", "event", "year", "race", "status", "source", "bib", "registered_at", "cancelled_at", "checked_in_at",
+}
+
+func registrationExportRow(r db.ExportUserRegistrationsRow) ([]string, error) {
+	return []string{
+		strconv.FormatInt(r.ID, 10), r.EventName, strconv.Itoa(int(r.EventYear)), r.RaceName,
+		string(r.Status), string(r.Source), r.Bib.String,
+		exportTime(r.CreatedAt), exportTime(r.CancelledAt), exportTime(r.CheckedInAt),
+	}, nil
+}
+
+var paymentExportColumns = []string{
+	"id", "registration_id", "amount", "status", "reference", "refunded", "refunded_at", "created_at", "updated_at",
+}
+
+func paymentExportRow(p db.Payment) ([]string, error) {
+	refunded := ""
+	if p.RefundedUnits > 0 {
+		refunded = formatMoney(int64(p.RefundedUnits), p.Currency)
+	}
+	return []string{
+		strconv.FormatInt(p.ID, 10), strconv.FormatInt(p.RegistrationID, 10),
+		formatMoney(int64(p.AmountUnits), p.Currency), string(p.Status), p.ProviderReference.String,
+		refunded, exportTime(p.RefundedAt), exportTime(p.CreatedAt), exportTime(p.UpdatedAt),
+	}, nil
+}
+
+var sessionExportColumns = []string{
+	"id", "device", "ip_address", "signed_in_at", "last_seen_at", "expires_at", "revoked_at", "evicted_at",
+}
+
+func sessionExportRow(us db.UserSession) ([]string, error) {
+	return []string{
+		strconv.FormatInt(us.ID, 10), us.UserAgent, us.IpAddress, exportTime(us.CreatedAt),
+		exportTime(us.LastSeenAt), exportTime(us.ExpiresAt), exportTime(us.RevokedAt), exportTime(us.EvictedAt),
+	}, nil
+}
+
+var auditExportColumns = []string{"id", "table", "record_id", "action", "changes", "created_at"}
+
+func auditExportRow(a db.AuditLog) ([]string, error) {
+	return []string{
+		strconv.FormatInt(a.ID, 10), a.TableName, strconv.FormatInt(a.RecordID, 10),
+		string(a.Action), string(a.ChangedFields), exportTime(a.CreatedAt),
+	}, nil
+}
+
+var answerExportColumns = func() []string {
+	columns := []string{"registration_id", "event", "year", "race"}
+	for _, q := range Questions {
+		columns = append(columns, string(q))
+	}
+	return append(columns, "answered_at")
+}()
+
+func (s *dataExportService) answerExportRow(a db.ExportUserRegistrationAnswersRow) ([]string, error) {
+	answers, err := openAnswers(s.answersBox, a.AnswersSealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open answers to registration %d: %w", a.RegistrationID, err)
+	}
+	row := []string{strconv.FormatInt(a.RegistrationID, 10), a.EventName, strconv.Itoa(int(a.EventYear)), a.RaceName}
+	for _, q := range Questions {
+		row = append(row, answers.Get(q))
+	}
+	return append(row, exportTime(a.CreatedAt)), nil
+}
+
+// exportTime writes t in RFC 3339 in UTC, or nothing when it is not set.
+func exportTime(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+func (s *dataExportService) OpenExport(ctx context.Context, userID int64, tok string) (io.ReadCloser, error) {
+	ctx, span := startSpan(ctx, "DataExportService.OpenExport")
+	defer span.End()
+
+	// Checking the token is the user's before it is consumed stops anyone
+	// else who opens the link using it up
+	signedFor, err := s.tokens.VerifyToken(token.PurposeDataExport, tok)
+	if err != nil || signedFor != userID {
+		return nil, ErrInvalidToken
+	}
+
+	hash := sha256.Sum256([]byte(tok))
+	e, err := s.exportRepo.Consume(ctx, hash[:], s.clock.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to consume data export token: %w", err)
+	}
+	if e.UserID != userID || !e.StorageKey.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	archive, err := s.store.Get(ctx, e.StorageKey.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data export %d: %w", e.ID, err)
+	}
+	return archive, nil
+}
+
+func (s *dataExportService) PruneExports(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "DataExportService.PruneExports")
+	defer span.End()
+
+	now := s.clock.Now()
+	spent, err := s.exportRepo.ListSpent(ctx, now, now.Add(-dataExportDownloadGrace), dataExportPruneBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list spent data exports: %w", err)
+	}
+
+	pruned := 0
+	var errs []error
+	for _, e := range spent {
+		if err := s.store.Delete(ctx, e.StorageKey.String); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete data export %d: %w", e.ID, err))
+			continue
+		}
+		if err := s.exportRepo.ClearStorageKey(ctx, e.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to clear data export %d: %w", e.ID, err))
+			continue
+		}
+		pruned++
+	}
+	return pruned, errors.Join(errs...)
+}
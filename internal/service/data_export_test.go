@@ -0,0 +1,364 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+	"firecrest/internal/secret"
+	"firecrest/internal/storage"
+	"firecrest/internal/token"
+)
+
+// newTestDataExportService returns a DataExportService over the user's data
+// in the mocks, keeping archives in a store on disk. Completed exports are
+// remembered by token hash, so OpenExport works on what BuildExports built.
+func newTestDataExportService(t *testing.T, now time.Time) (*dataExportService, *repositorymocks.DataExportRepositoryMock, *mockMailer) {
+	t.Helper()
+	exports := map[string]*db.DataExport{}
+	exportRepo := &repositorymocks.DataExportRepositoryMock{
+		ClaimPendingFunc: func(ctx context.Context, now, leaseUntil time.Time, limit int) ([]db.DataExport, error) {
+			return []db.DataExport{{ID: 7, UserID: 42, Status: db.DataExportStatusPending}}, nil
+		},
+		CompleteFunc: func(ctx context.Context, id int64, key string, tokenHash []byte, expiresAt, now time.Time) error {
+			exports[string(tokenHash)] = &db.DataExport{
+				ID:         id,
+				UserID:     42,
+				Status:     db.DataExportStatusReady,
+				StorageKey: pgtype.Text{String: key, Valid: true},
+				ExpiresAt:  pgtype.Timestamptz{Time: expiresAt, Valid: true},
+			}
+			return nil
+		},
+		ConsumeFunc: func(ctx context.Context, tokenHash []byte, now time.Time) (db.DataExport, error) {
+			e, ok := exports[string(tokenHash)]
+			if !ok || e.DownloadedAt.Valid || !e.ExpiresAt.Time.After(now) {
+				return db.DataExport{}, repository.ErrNotFound
+			}
+			e.DownloadedAt = pgtype.Timestamptz{Time: now, Valid: true}
+			return *e, nil
+		},
+	}
+	mailer := &mockMailer{}
+	return &dataExportService{
+		exportRepo: exportRepo,
+		userRepo: &repositorymocks.UserRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+				return db.User{ID: id, Email: "jane@example.com", FirstName: "Jane", LastName: "Doe", Role: db.UserRoleEntrant}, nil
+			},
+		},
+		registrationRepo: &repositorymocks.RegistrationRepositoryMock{},
+		paymentRepo:      &repositorymocks.PaymentRepositoryMock{},
+		sessionRepo:      &repositorymocks.SessionRepositoryMock{},
+		store:            newTestStore(t),
+		answersBox:       newTestBox(t),
+		tokens:           newTestSigner(t),
+		mailer:           mailer,
+		baseURL:          "https://firecrest.example",
+		clock:            &MockClock{CurrentTime: now},
+	}, exportRepo, mailer
+}
+
+// readArchive opens the ZIP under key, returning its file names in order
+// and each file's rows.
+func readArchive(t *testing.T, store storage.BlobStore, key string) ([]string, map[string][]map[string]string) {
+	t.Helper()
+	blob, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer blob.Close()
+	data, err := io.ReadAll(blob)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid ZIP: %v", err)
+	}
+
+	var names []string
+	files := map[string][]map[string]string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		var rows []map[string]string
+		if err := json.NewDecoder(r).Decode(&rows); err != nil {
+			t.Fatalf("invalid JSON in %s: %v", f.Name, err)
+		}
+		r.Close()
+		files[f.Name] = rows
+	}
+	return names, files
+}
+
+// downloadToken returns the token in the link a data export email carries.
+func downloadToken(t *testing.T, body string) string {
+	t.Helper()
+	_, rest, ok := strings.Cut(body, "https://firecrest.example/account/data-export?")
+	if !ok {
+		t.Fatalf("expected a download link in:\n%s", body)
+	}
+	query, err := url.ParseQuery(strings.Fields(rest)[0])
+	if err != nil {
+		t.Fatalf("invalid download link: %v", err)
+	}
+	return query.Get("token")
+}
+
+func TestDataExportService_BuildExports(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	at := pgtype.Timestamptz{Time: now.Add(-48 * time.Hour), Valid: true}
+
+	t.Run("writes each kind of data to the archive and emails a link to it", func(t *testing.T) {
+		svc, exportRepo, mailer := newTestDataExportService(t, now)
+		// More registrations than fit in a page, to be read in two
+		var registrations []db.ExportUserRegistrationsRow
+		for id := int64(1); id <= dataExportPageSize+1; id++ {
+			registrations = append(registrations, db.ExportUserRegistrationsRow{
+				ID: id, Status: db.RegistrationStatusConfirmed, RaceName: "10K", EventName: "Riverside Run", EventYear: 2026, CreatedAt: at,
+			})
+		}
+		regRepo := &repositorymocks.RegistrationRepositoryMock{
+			ListForExportFunc: func(ctx context.Context, userID, afterID int64, limit int) ([]db.ExportUserRegistrationsRow, error) {
+				i, _ := slices.BinarySearchFunc(registrations, afterID+1, func(r db.ExportUserRegistrationsRow, id int64) int { return int(r.ID - id) })
+				return registrations[i:min(i+limit, len(registrations))], nil
+			},
+		}
+		sealed, err := sealAnswers(svc.answersBox, Answers{MedicalConditions: "Asthma", Club: "Riverside Harriers"})
+		if err != nil {
+			t.Fatalf("failed to seal answers: %v", err)
+		}
+		regRepo.ListAnswersForExportFunc = func(ctx context.Context, userID, afterID int64, limit int) ([]db.ExportUserRegistrationAnswersRow, error) {
+			if afterID > 0 {
+				return nil, nil
+			}
+			return []db.ExportUserRegistrationAnswersRow{{RegistrationID: 1, AnswersSealed: sealed, RaceName: "10K", EventName: "Riverside Run", EventYear: 2026, CreatedAt: at}}, nil
+		}
+		svc.registrationRepo = regRepo
+		svc.paymentRepo = &repositorymocks.PaymentRepositoryMock{
+			ListForExportFunc: func(ctx context.Context, userID, afterID int64, limit int) ([]db.Payment, error) {
+				return []db.Payment{{ID: 3, RegistrationID: 1, AmountUnits: 2500, Currency: "GBP", Status: db.PaymentStatusSucceeded, CreatedAt: at}}, nil
+			},
+		}
+		svc.sessionRepo = &repositorymocks.SessionRepositoryMock{
+			ListForExportFunc: func(ctx context.Context, userID, afterID int64, limit int) ([]db.UserSession, error) {
+				return []db.UserSession{{ID: 9, UserID: userID, UserAgent: "Firefox", IpAddress: "203.0.113.9", RevokedAt: at}}, nil
+			},
+		}
+		svc.userRepo.(*repositorymocks.UserRepositoryMock).ListAuditEntriesForExportFunc = func(ctx context.Context, id, afterID int64, limit int) ([]db.AuditLog, error) {
+			return []db.AuditLog{{ID: 5, TableName: "registrations", RecordID: 1, Action: db.AuditActionUpdated, ChangedFields: []byte(`{"answers":["club"]}`)}}, nil
+		}
+
+		built, err := svc.BuildExports(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if built != 1 {
+			t.Errorf("expected 1 export built, got %d", built)
+		}
+
+		calls := exportRepo.CompleteCalls()
+		if len(calls) != 1 {
+			t.Fatalf("expected the export completed once, got %d", len(calls))
+		}
+		if want := now.Add(DataExportTTL); !calls[0].ExpiresAt.Equal(want) {
+			t.Errorf("expected the link to expire at %v, got %v", want, calls[0].ExpiresAt)
+		}
+		names, files := readArchive(t, svc.store, calls[0].Key)
+		wantNames := []string{"profile.json", "registrations.json", "payments.json", "sessions.json", "audit-log.json", "answers.json"}
+		if !slices.Equal(names, wantNames) {
+			t.Errorf("expected files %v, got %v", wantNames, names)
+		}
+		if got := files["profile.json"]; len(got) != 1 || got[0]["email"] != "jane@example.com" || got[0]["last_name"] != "Doe" {
+			t.Errorf("unexpected profile %v", got)
+		}
+		if got := files["registrations.json"]; len(got) != dataExportPageSize+1 || got[dataExportPageSize]["id"] != "501" || got[0]["registered_at"] != "2026-04-29T09:00:00Z" {
+			t.Errorf("expected every registration across both pages, got %d", len(got))
+		}
+		if got := len(regRepo.ListForExportCalls()); got != 2 {
+			t.Errorf("expected registrations read in 2 pages, got %d", got)
+		}
+		if got := files["payments.json"]; len(got) != 1 || got[0]["amount"] != "£25.00" {
+			t.Errorf("unexpected payments %v", got)
+		}
+		if got := files["sessions.json"]; len(got) != 1 || got[0]["ip_address"] != "203.0.113.9" || got[0]["revoked_at"] == "" {
+			t.Errorf("unexpected sessions %v", got)
+		}
+		if got := files["audit-log.json"]; len(got) != 1 || got[0]["changes"] != `{"answers":["club"]}` {
+			t.Errorf("unexpected audit entries %v", got)
+		}
+		if got := files["answers.json"]; len(got) != 1 || got[0]["medical_conditions"] != "Asthma" || got[0]["club"] != "Riverside Harriers" {
+			t.Errorf("expected the answers decrypted, got %v", got)
+		}
+
+		if len(mailer.sent) != 1 || mailer.sent[0].To != "jane@example.com" {
+			t.Fatalf("expected the link emailed to the user, got %+v", mailer.sent)
+		}
+		tok := downloadToken(t, mailer.sent[0].Text)
+		if hash := sha256.Sum256([]byte(tok)); !bytes.Equal(hash[:], calls[0].TokenHash) {
+			t.Error("expected the emailed token's hash stored with the export")
+		}
+	})
+
+	t.Run("fails an export that cannot be built", func(t *testing.T) {
+		svc, exportRepo, mailer := newTestDataExportService(t, now)
+		other, err := secret.NewBox([]byte("another-key-another-key-another!"))
+		if err != nil {
+			t.Fatalf("failed to create box: %v", err)
+		}
+		sealed, err := sealAnswers(other, Answers{Club: "Riverside Harriers"})
+		if err != nil {
+			t.Fatalf("failed to seal answers: %v", err)
+		}
+		svc.registrationRepo = &repositorymocks.RegistrationRepositoryMock{
+			ListAnswersForExportFunc: func(ctx context.Context, userID, afterID int64, limit int) ([]db.ExportUserRegistrationAnswersRow, error) {
+				return []db.ExportUserRegistrationAnswersRow{{RegistrationID: 1, AnswersSealed: sealed}}, nil
+			},
+		}
+
+		built, err := svc.BuildExports(context.Background())
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if built != 0 {
+			t.Errorf("expected nothing built, got %d", built)
+		}
+		if calls := exportRepo.FailCalls(); len(calls) != 1 || calls[0].ID != 7 {
+			t.Errorf("expected the export marked failed, got %+v", calls)
+		}
+		if len(exportRepo.CompleteCalls()) != 0 || len(mailer.sent) != 0 {
+			t.Error("expected a failed export neither completed nor emailed")
+		}
+	})
+
+	t.Run("removes the archive of an export no longer wanted", func(t *testing.T) {
+		svc, exportRepo, mailer := newTestDataExportService(t, now)
+		exportRepo.CompleteFunc = func(ctx context.Context, id int64, key string, tokenHash []byte, expiresAt, now time.Time) error {
+			return repository.ErrNotFound
+		}
+
+		if _, err := svc.BuildExports(context.Background()); err == nil {
+			t.Fatal("expected an error")
+		}
+		key := exportRepo.CompleteCalls()[0].Key
+		if _, err := svc.store.Get(context.Background(), key); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("expected the archive removed, got %v", err)
+		}
+		if len(mailer.sent) != 0 {
+			t.Error("expected no email")
+		}
+	})
+}
+
+func TestDataExportService_OpenExport(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	// build returns a service with one export built for user 42, and the
+	// token emailed for it
+	build := func(t *testing.T) (*dataExportService, string) {
+		t.Helper()
+		svc, _, mailer := newTestDataExportService(t, now)
+		if _, err := svc.BuildExports(context.Background()); err != nil {
+			t.Fatalf("failed to build export: %v", err)
+		}
+		return svc, downloadToken(t, mailer.sent[0].Text)
+	}
+
+	t.Run("opens the archive once", func(t *testing.T) {
+		svc, tok := build(t)
+
+		archive, err := svc.OpenExport(context.Background(), 42, tok)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := io.ReadAll(archive)
+		archive.Close()
+		if err != nil {
+			t.Fatalf("failed to read archive: %v", err)
+		}
+		if _, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+			t.Errorf("expected a ZIP, got %v", err)
+		}
+
+		if _, err := svc.OpenExport(context.Background(), 42, tok); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected the link to work once, got %v", err)
+		}
+	})
+
+	t.Run("refuses someone else without using the link up", func(t *testing.T) {
+		svc, tok := build(t)
+
+		if _, err := svc.OpenExport(context.Background(), 43, tok); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+		archive, err := svc.OpenExport(context.Background(), 42, tok)
+		if err != nil {
+			t.Fatalf("expected the owner still able to download, got %v", err)
+		}
+		archive.Close()
+	})
+
+	t.Run("refuses expired and forged tokens", func(t *testing.T) {
+		svc, tok := build(t)
+
+		svc.clock = &MockClock{CurrentTime: now.Add(DataExportTTL)}
+		if _, err := svc.OpenExport(context.Background(), 42, tok); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected an expired link refused, got %v", err)
+		}
+
+		forged, err := newTestSigner(t).SignToken(token.PurposeUnsubscribe, 42, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		if _, err := svc.OpenExport(context.Background(), 42, forged); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected a token for another purpose refused, got %v", err)
+		}
+	})
+}
+
+func TestDataExportService_PruneExports(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	svc, exportRepo, _ := newTestDataExportService(t, now)
+	ctx := context.Background()
+	if err := svc.store.Put(ctx, "exports/42/a.zip", strings.NewReader("zip"), "application/zip"); err != nil {
+		t.Fatalf("failed to store archive: %v", err)
+	}
+	exportRepo.ListSpentFunc = func(ctx context.Context, now, downloadedBefore time.Time, limit int) ([]db.DataExport, error) {
+		return []db.DataExport{{ID: 7, StorageKey: pgtype.Text{String: "exports/42/a.zip", Valid: true}}}, nil
+	}
+
+	pruned, err := svc.PruneExports(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("expected 1 archive pruned, got %d", pruned)
+	}
+	if _, err := svc.store.Get(ctx, "exports/42/a.zip"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected the archive removed, got %v", err)
+	}
+	if calls := exportRepo.ClearStorageKeyCalls(); len(calls) != 1 || calls[0].ID != 7 {
+		t.Errorf("expected the export's storage key cleared, got %+v", calls)
+	}
+	if calls := exportRepo.ListSpentCalls(); calls[0].DownloadedBefore != now.Add(-dataExportDownloadGrace) {
+		t.Errorf("expected downloads kept for %v, got those before %v", dataExportDownloadGrace, calls[0].DownloadedBefore)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/repository"
+)
+
+// ErrEditClosed is returned for entrants changing their details once their
+// race is too close for organisers to take the change.
+var ErrEditClosed = apperr.New(apperr.CodeEditClosed, http.StatusConflict, "details can no longer be changed")
+
+// RegistrationDetails are the questionnaire answers of a registration as
+// its entrant may edit them.
+type RegistrationDetails struct {
+	RegistrationID int64
+	RaceName       string
+	EventName      string
+	Answers        Answers
+	// Questions are those the entrant is asked, in form order: the ones the
+	// race requires, their club, and any others they have answered.
+	Questions []Question
+	// Required are the questions that must keep an answer.
+	Required []Question
+	// Locked are the questions the entrant may no longer change, as the
+	// race locks them once the entry is paid for.
+	Locked []Question
+	// EditableUntil is when the entrant stops being able to make changes,
+	// or zero while the race has no date.
+	EditableUntil time.Time
+}
+
+// IsLocked reports whether the entrant may no longer change their answer
+// to q.
+func (d RegistrationDetails) IsLocked(q Question) bool {
+	return slices.Contains(d.Locked, q)
+}
+
+func (s *registrationService) RegistrationDetails(ctx context.Context, userID, registrationID int64) (RegistrationDetails, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.RegistrationDetails")
+	defer span.End()
+
+	return s.registrationDetails(ctx, userID, registrationID)
+}
+
+func (s *registrationService) UpdateRegistrationDetails(ctx context.Context, userID, registrationID int64, answers Answers) error {
+	ctx, span := startSpan(ctx, "RegistrationService.UpdateRegistrationDetails")
+	defer span.End()
+
+	details, err := s.registrationDetails(ctx, userID, registrationID)
+	if err != nil {
+		return err
+	}
+
+	// Questions the entrant was not asked keep their answers, so a form
+	// cannot clear them
+	updated := details.Answers
+	for _, q := range details.Questions {
+		updated.Set(q, answers.Get(q))
+	}
+
+	errs := FieldErrors{}
+	var verr FieldErrors
+	if err := updated.Validate(details.Required); errors.As(err, &verr) {
+		for field, msg := range verr {
+			errs.Add(field, msg)
+		}
+	}
+	var changed []string
+	for _, q := range Questions {
+		if updated.Get(q) == details.Answers.Get(q) {
+			continue
+		}
+		if details.IsLocked(q) {
+			errs.Add(string(q), q.label()+" can no longer be changed, as your entry is paid for")
+			continue
+		}
+		changed = append(changed, string(q))
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	sealed, err := sealAnswers(s.answersBox, updated)
+	if err != nil {
+		return err
+	}
+	if err := s.registrationRepo.UpdateAnswers(ctx, repository.UpdateAnswersParams{
+		RegistrationID: registrationID,
+		UserID:         userID,
+		AnswersSealed:  sealed,
+		Changed:        changed,
+	}); err != nil {
+		return fmt.Errorf("failed to update answers: %w", err)
+	}
+	return nil
+}
+
+// registrationDetails loads the user's registration for them to edit. Other
+// people's registrations, and cancelled ones, are reported as
+// repository.ErrNotFound so their IDs give nothing away.
+func (s *registrationService) registrationDetails(ctx context.Context, userID, registrationID int64) (RegistrationDetails, error) {
+	reg, err := s.registrationRepo.GetForEdit(ctx, registrationID)
+	if err != nil {
+		return RegistrationDetails{}, err
+	}
+	if reg.UserID != userID || reg.Status == db.RegistrationStatusCancelled {
+		return RegistrationDetails{}, repository.ErrNotFound
+	}
+
+	var startsAt time.Time
+	if reg.StartsAt.Valid {
+		startsAt = reg.StartsAt.Time
+	}
+	if s.editClosed(startsAt, s.clock.Now()) {
+		return RegistrationDetails{}, ErrEditClosed
+	}
+
+	details := RegistrationDetails{
+		RegistrationID: reg.ID,
+		RaceName:       reg.RaceName,
+		EventName:      reg.EventName,
+	}
+	if !startsAt.IsZero() {
+		details.EditableUntil = startsAt.Add(-s.editLock)
+	}
+	if reg.AnswersSealed != nil {
+		if details.Answers, err = openAnswers(s.answersBox, reg.AnswersSealed); err != nil {
+			return RegistrationDetails{}, fmt.Errorf("failed to open answers of registration %d: %w", reg.ID, err)
+		}
+	}
+	if details.Required, err = requiredQuestions(ctx, s.raceRepo, reg.RaceID); err != nil {
+		return RegistrationDetails{}, err
+	}
+	for _, q := range Questions {
+		if q == QuestionClub || slices.Contains(details.Required, q) || details.Answers.Get(q) != "" {
+			details.Questions = append(details.Questions, q)
+		}
+	}
+
+	_, err = s.payments.SettledPayment(ctx, reg.ID)
+	switch {
+	case err == nil:
+		if details.Locked, err = lockedQuestions(ctx, s.raceRepo, reg.RaceID); err != nil {
+			return RegistrationDetails{}, err
+		}
+	case !errors.Is(err, repository.ErrNotFound):
+		return RegistrationDetails{}, fmt.Errorf("failed to load payment: %w", err)
+	}
+	return details, nil
+}
+
+// editClosed reports whether entrants can no longer edit their details for
+// a race starting at startsAt. A zero startsAt never closes.
+func (s *registrationService) editClosed(startsAt, now time.Time) bool {
+	return !startsAt.IsZero() && !now.Before(startsAt.Add(-s.editLock))
+}
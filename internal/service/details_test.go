@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestRegistrationService_UpdateRegistrationDetails(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 6, 2, 12, 0, 0, 0, time.UTC)
+	box := newTestBox(t)
+	stored := Answers{EmergencyContactName: "Alex Hill", EmergencyContactPhone: "07700 900123", Club: "Dark Peak Fell Runners"}
+	sealed, err := sealAnswers(box, stored)
+	if err != nil {
+		t.Fatalf("failed to seal answers: %v", err)
+	}
+	reg := db.GetRegistrationForEditRow{
+		ID:            100,
+		UserID:        7,
+		RaceID:        20,
+		Status:        db.RegistrationStatusConfirmed,
+		RaceName:      "Ultra 50K",
+		StartsAt:      pgtype.Timestamptz{Time: now.Add(10 * 24 * time.Hour), Valid: true},
+		EventName:     "Peak District Ultra",
+		AnswersSealed: sealed,
+	}
+
+	type deps struct {
+		registrations *repositorymocks.RegistrationRepositoryMock
+		payments      *mockPaymentService
+		updated       *repository.UpdateAnswersParams
+	}
+	newService := func(reg db.GetRegistrationForEditRow, locked ...string) (*registrationService, *deps) {
+		d := &deps{payments: &mockPaymentService{}}
+		d.registrations = &repositorymocks.RegistrationRepositoryMock{
+			GetForEditFunc: func(ctx context.Context, id int64) (db.GetRegistrationForEditRow, error) {
+				if id != reg.ID {
+					return db.GetRegistrationForEditRow{}, repository.ErrNotFound
+				}
+				return reg, nil
+			},
+			UpdateAnswersFunc: func(ctx context.Context, params repository.UpdateAnswersParams) error {
+				d.updated = &params
+				return nil
+			},
+		}
+		races := &repositorymocks.RaceRepositoryMock{
+			ListRequiredQuestionsFunc: func(ctx context.Context, raceID int64) ([]string, error) {
+				return []string{"emergency_contact_name", "emergency_contact_phone"}, nil
+			},
+			ListLockedQuestionsFunc: func(ctx context.Context, raceID int64) ([]string, error) {
+				return locked, nil
+			},
+		}
+		return &registrationService{
+			registrationRepo: d.registrations,
+			raceRepo:         races,
+			payments:         d.payments,
+			answersBox:       box,
+			editLock:         7 * 24 * time.Hour,
+			clock:            &MockClock{CurrentTime: now},
+		}, d
+	}
+	paid := func(d *deps) {
+		d.payments.settledPaymentFunc = func(ctx context.Context, registrationID int64) (db.Payment, error) {
+			return db.Payment{ID: 1, RegistrationID: registrationID}, nil
+		}
+	}
+
+	t.Run("asks the required questions, the club and any answered", func(t *testing.T) {
+		svc, _ := newService(reg)
+
+		details, err := svc.RegistrationDetails(ctx, 7, reg.ID)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Question{QuestionEmergencyContactName, QuestionEmergencyContactPhone, QuestionClub}
+		if !slices.Equal(details.Questions, want) {
+			t.Errorf("expected questions %v, got %v", want, details.Questions)
+		}
+		if details.Answers != stored {
+			t.Errorf("expected the stored answers, got %+v", details.Answers)
+		}
+		if !details.EditableUntil.Equal(now.Add(3 * 24 * time.Hour)) {
+			t.Errorf("expected editing to close a week before the race, got %v", details.EditableUntil)
+		}
+	})
+
+	t.Run("stores the new answers and names the questions changed", func(t *testing.T) {
+		svc, d := newService(reg)
+		edit := stored
+		edit.Club = "Totley AC"
+		edit.EstimatedFinish = "5:30"
+
+		if err := svc.UpdateRegistrationDetails(ctx, 7, reg.ID, edit); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if d.updated == nil {
+			t.Fatal("expected the answers to be updated")
+		}
+		if d.updated.RegistrationID != reg.ID || d.updated.UserID != 7 {
+			t.Errorf("expected an edit of registration 100 by user 7, got %+v", d.updated)
+		}
+		if !slices.Equal(d.updated.Changed, []string{"club"}) {
+			t.Errorf("expected only the club changed, as the finish time is not asked, got %v", d.updated.Changed)
+		}
+		got, err := openAnswers(box, d.updated.AnswersSealed)
+		if err != nil {
+			t.Fatalf("failed to open answers: %v", err)
+		}
+		if got.Club != "Totley AC" || got.EmergencyContactName != "Alex Hill" || got.EstimatedFinish != "" {
+			t.Errorf("unexpected answers stored: %+v", got)
+		}
+	})
+
+	t.Run("saves nothing when nothing changed", func(t *testing.T) {
+		svc, d := newService(reg)
+
+		if err := svc.UpdateRegistrationDetails(ctx, 7, reg.ID, stored); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if d.updated != nil {
+			t.Errorf("expected no update, got %+v", d.updated)
+		}
+	})
+
+	t.Run("checks the answers", func(t *testing.T) {
+		svc, d := newService(reg)
+		edit := stored
+		edit.EmergencyContactName = ""
+
+		err := svc.UpdateRegistrationDetails(ctx, 7, reg.ID, edit)
+
+		var errs FieldErrors
+		if !errors.As(err, &errs) || errs["emergency_contact_name"] == "" {
+			t.Fatalf("expected an emergency_contact_name error, got %v", err)
+		}
+		if d.updated != nil {
+			t.Error("expected nothing to be saved")
+		}
+	})
+
+	t.Run("refuses changes to locked answers once paid", func(t *testing.T) {
+		svc, d := newService(reg, "club")
+		paid(d)
+		edit := stored
+		edit.Club = "Totley AC"
+		edit.EmergencyContactName = "Sam Hill"
+
+		details, err := svc.RegistrationDetails(ctx, 7, reg.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !details.IsLocked(QuestionClub) || details.IsLocked(QuestionEmergencyContactName) {
+			t.Errorf("expected only the club locked, got %v", details.Locked)
+		}
+
+		err = svc.UpdateRegistrationDetails(ctx, 7, reg.ID, edit)
+
+		var errs FieldErrors
+		if !errors.As(err, &errs) || errs["club"] == "" {
+			t.Fatalf("expected a club error, got %v", err)
+		}
+		if d.updated != nil {
+			t.Error("expected nothing to be saved")
+		}
+	})
+
+	t.Run("leaves locked answers editable until paid", func(t *testing.T) {
+		svc, d := newService(reg, "club")
+		edit := stored
+		edit.Club = "Totley AC"
+
+		if err := svc.UpdateRegistrationDetails(ctx, 7, reg.ID, edit); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if d.updated == nil {
+			t.Error("expected the club to be changed")
+		}
+	})
+
+	t.Run("closes the edit lock before the race", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			startsAt pgtype.Timestamptz
+			closed   bool
+		}{
+			{name: "open more than a week out", startsAt: pgtype.Timestamptz{Time: now.Add(7*24*time.Hour + time.Minute), Valid: true}},
+			{name: "closed exactly a week out", startsAt: pgtype.Timestamptz{Time: now.Add(7 * 24 * time.Hour), Valid: true}, closed: true},
+			{name: "closed after the race", startsAt: pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true}, closed: true},
+			{name: "open without a race date", startsAt: pgtype.Timestamptz{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				r := reg
+				r.StartsAt = tt.startsAt
+				svc, d := newService(r)
+				edit := stored
+				edit.Club = "Totley AC"
+
+				err := svc.UpdateRegistrationDetails(ctx, 7, reg.ID, edit)
+
+				if tt.closed {
+					if !errors.Is(err, ErrEditClosed) || d.updated != nil {
+						t.Errorf("expected ErrEditClosed and no update, got %v", err)
+					}
+				} else if err != nil || d.updated == nil {
+					t.Errorf("expected the edit to be saved, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("reports other entrants' registrations as not found", func(t *testing.T) {
+		svc, d := newService(reg)
+
+		if _, err := svc.RegistrationDetails(ctx, 8, reg.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if err := svc.UpdateRegistrationDetails(ctx, 8, reg.ID, stored); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if d.updated != nil {
+			t.Error("expected nothing to be saved")
+		}
+	})
+
+	t.Run("reports cancelled registrations as not found", func(t *testing.T) {
+		r := reg
+		r.Status = db.RegistrationStatusCancelled
+		svc, _ := newService(r)
+
+		if _, err := svc.RegistrationDetails(ctx, 7, reg.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
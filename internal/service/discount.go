@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/repository"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/discount.go -pkg servicemocks . DiscountService
+
+// Discount code lengths.
+const (
+	MinDiscountCodeLength = 3
+	MaxDiscountCodeLength = 32
+)
+
+// Discount code errors, each telling the entrant why the code they gave
+// was refused.
+var (
+	ErrDiscountCodeNotFound    = apperr.New(apperr.CodeDiscountNotFound, http.StatusUnprocessableEntity, "discount code not recognised")
+	ErrDiscountCodeWrongRace   = apperr.New(apperr.CodeDiscountWrongRace, http.StatusUnprocessableEntity, "discount code does not apply to this race")
+	ErrDiscountCodeNotYetValid = apperr.New(apperr.CodeDiscountNotYetValid, http.StatusUnprocessableEntity, "discount code is not valid yet")
+	ErrDiscountCodeExpired     = apperr.New(apperr.CodeDiscountExpired, http.StatusUnprocessableEntity, "discount code has expired")
+	ErrDiscountCodeExhausted   = apperr.New(apperr.CodeDiscountExhausted, http.StatusConflict, "discount code has been used up")
+)
+
+// DiscountService defines the interface for discount code business logic.
+//
+// Organisers create codes that take a percentage or a fixed amount off
+// entries to an event, or to one race in it. Codes are matched ignoring
+// case.
+type DiscountService interface {
+	// CreateCode creates a discount code, returning FieldErrors if the
+	// params are invalid or the event already has the code.
+	CreateCode(ctx context.Context, params CreateDiscountCodeParams) (db.DiscountCode, error)
+	// ValidateCode returns the discount the code gives on entries to the
+	// race. It returns ErrDiscountCodeNotFound for a code no event has,
+	// ErrDiscountCodeWrongRace for one that does not apply to the race,
+	// ErrDiscountCodeNotYetValid or ErrDiscountCodeExpired outside its
+	// validity window, and ErrDiscountCodeExhausted once it has been used
+	// as often as it allows. A valid code may still be used up by the time
+	// an entry is made with it.
+	ValidateCode(ctx context.Context, code string, raceID int64) (Discount, error)
+	// ListCodes returns the event's codes, newest first.
+	ListCodes(ctx context.Context, eventID int64) ([]db.DiscountCode, error)
+}
+
+// CreateDiscountCodeParams describes a discount code to create. Exactly one
+// of PercentOff and AmountOffUnits is set.
+type CreateDiscountCodeParams struct {
+	EventID int64
+	// RaceID limits the code to one race in the event. Zero means every
+	// race.
+	RaceID         int64
+	Code           string
+	PercentOff     int32
+	AmountOffUnits int32
+	// MaxUses is how many entries may be made with the code. Zero means
+	// unlimited.
+	MaxUses int32
+	// ValidFrom and ValidUntil bound when the code can be used. A zero
+	// time leaves that end open.
+	ValidFrom  time.Time
+	ValidUntil time.Time
+}
+
+// Discount is what a discount code takes off an entry.
+type Discount struct {
+	CodeID         int64
+	Code           string
+	PercentOff     int32
+	AmountOffUnits int32
+}
+
+// Apply returns how much the discount takes off an entry fee of
+// priceUnits. Percentages are rounded up to the penny, in the entrant's
+// favour, and no discount takes off more than the fee.
+func (d Discount) Apply(priceUnits int32) int32 {
+	if priceUnits <= 0 {
+		return 0
+	}
+	off := d.AmountOffUnits
+	if d.PercentOff > 0 {
+		off = int32((int64(priceUnits)*int64(d.PercentOff) + 99) / 100)
+	}
+	return min(off, priceUnits)
+}
+
+type discountService struct {
+	discountRepo repository.DiscountRepository
+	raceRepo     repository.RaceRepository
+	clock        Clock
+}
+
+// NewDiscountService creates a new DiscountService with the given repositories.
+func NewDiscountService(discountRepo repository.DiscountRepository, raceRepo repository.RaceRepository) DiscountService {
+	return &discountService{discountRepo: discountRepo, raceRepo: raceRepo, clock: RealClock{}}
+}
+
+func (s *discountService) CreateCode(ctx context.Context, params CreateDiscountCodeParams) (db.DiscountCode, error) {
+	if params.EventID <= 0 {
+		return db.DiscountCode{}, fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+
+	code := strings.TrimSpace(params.Code)
+	errs := FieldErrors{}
+	switch {
+	case code == "":
+		errs.Add("code", "code is required")
+	case len(code) < MinDiscountCodeLength || len(code) > MaxDiscountCodeLength:
+		errs.Add("code", fmt.Sprintf("code must be between %d and %d characters", MinDiscountCodeLength, MaxDiscountCodeLength))
+	case strings.IndexFunc(code, func(r rune) bool { return !isDiscountCodeRune(r) }) >= 0:
+		errs.Add("code", "code can only contain letters, numbers, hyphens and underscores")
+	}
+	switch {
+	case params.PercentOff == 0 && params.AmountOffUnits == 0:
+		errs.Add("percent_off", "enter a percentage or an amount off")
+	case params.PercentOff != 0 && params.AmountOffUnits != 0:
+		errs.Add("percent_off", "enter a percentage or an amount off, not both")
+	case params.PercentOff < 0 || params.PercentOff > 100:
+		errs.Add("percent_off", "percentage must be between 1 and 100")
+	case params.AmountOffUnits < 0:
+		errs.Add("amount_off", "amount off must be positive")
+	}
+	if params.MaxUses < 0 {
+		errs.Add("max_uses", "maximum uses cannot be negative")
+	}
+	if !params.ValidFrom.IsZero() && !params.ValidUntil.IsZero() && !params.ValidUntil.After(params.ValidFrom) {
+		errs.Add("valid_until", "code must stop being valid after it starts")
+	}
+	if params.RaceID > 0 {
+		race, err := s.raceRepo.GetByID(ctx, params.RaceID)
+		switch {
+		case errors.Is(err, repository.ErrNotFound) || (err == nil && race.EventID != params.EventID):
+			errs.Add("race_id", "choose a race in this event")
+		case err != nil:
+			return db.DiscountCode{}, fmt.Errorf("failed to load race: %w", err)
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return db.DiscountCode{}, err
+	}
+
+	created, err := s.discountRepo.Create(ctx, db.CreateDiscountCodeParams{
+		EventID:        params.EventID,
+		RaceID:         pgtype.Int8{Int64: params.RaceID, Valid: params.RaceID > 0},
+		Code:           code,
+		PercentOff:     pgtype.Int4{Int32: params.PercentOff, Valid: params.PercentOff > 0},
+		AmountOffUnits: pgtype.Int4{Int32: params.AmountOffUnits, Valid: params.AmountOffUnits > 0},
+		MaxUses:        pgtype.Int4{Int32: params.MaxUses, Valid: params.MaxUses > 0},
+		ValidFrom:      pgtype.Timestamptz{Time: params.ValidFrom, Valid: !params.ValidFrom.IsZero()},
+		ValidUntil:     pgtype.Timestamptz{Time: params.ValidUntil, Valid: !params.ValidUntil.IsZero()},
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return db.DiscountCode{}, FieldErrors{"code": "this event already has that code"}
+		}
+		return db.DiscountCode{}, fmt.Errorf("failed to create discount code: %w", err)
+	}
+	return created, nil
+}
+
+// isDiscountCodeRune reports whether r may appear in a discount code. Codes
+// are kept to characters that are easy to read out and type.
+func isDiscountCodeRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+}
+
+func (s *discountService) ValidateCode(ctx context.Context, code string, raceID int64) (Discount, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return Discount{}, ErrDiscountCodeNotFound
+	}
+
+	race, err := s.raceRepo.GetByID(ctx, raceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return Discount{}, err
+		}
+		return Discount{}, fmt.Errorf("failed to load race: %w", err)
+	}
+
+	codes, err := s.discountRepo.ListByCode(ctx, code)
+	if err != nil {
+		return Discount{}, fmt.Errorf("failed to look up discount code: %w", err)
+	}
+	if len(codes) == 0 {
+		return Discount{}, ErrDiscountCodeNotFound
+	}
+	// Each event has the code at most once, so at most one of them can
+	// apply to the race
+	i := slices.IndexFunc(codes, func(c db.DiscountCode) bool {
+		return c.EventID == race.EventID && (!c.RaceID.Valid || c.RaceID.Int64 == race.ID)
+	})
+	if i < 0 {
+		return Discount{}, ErrDiscountCodeWrongRace
+	}
+
+	c := codes[i]
+	now := s.clock.Now()
+	switch {
+	case c.ValidFrom.Valid && now.Before(c.ValidFrom.Time):
+		return Discount{}, ErrDiscountCodeNotYetValid
+	case c.ValidUntil.Valid && !now.Before(c.ValidUntil.Time):
+		return Discount{}, ErrDiscountCodeExpired
+	case c.MaxUses.Valid && c.Uses >= c.MaxUses.Int32:
+		return Discount{}, ErrDiscountCodeExhausted
+	}
+	return Discount{
+		CodeID:         c.ID,
+		Code:           c.Code,
+		PercentOff:     c.PercentOff.Int32,
+		AmountOffUnits: c.AmountOffUnits.Int32,
+	}, nil
+}
+
+func (s *discountService) ListCodes(ctx context.Context, eventID int64) ([]db.DiscountCode, error) {
+	codes, err := s.discountRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list discount codes: %w", err)
+	}
+	return codes, nil
+}
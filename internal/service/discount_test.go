@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+// mockDiscountService implements DiscountService for testing.
+type mockDiscountService struct {
+	createCodeFunc   func(ctx context.Context, params CreateDiscountCodeParams) (db.DiscountCode, error)
+	validateCodeFunc func(ctx context.Context, code string, raceID int64) (Discount, error)
+	listCodesFunc    func(ctx context.Context, eventID int64) ([]db.DiscountCode, error)
+}
+
+func (m *mockDiscountService) CreateCode(ctx context.Context, params CreateDiscountCodeParams) (db.DiscountCode, error) {
+	if m.createCodeFunc != nil {
+		return m.createCodeFunc(ctx, params)
+	}
+	return db.DiscountCode{}, nil
+}
+
+func (m *mockDiscountService) ValidateCode(ctx context.Context, code string, raceID int64) (Discount, error) {
+	if m.validateCodeFunc != nil {
+		return m.validateCodeFunc(ctx, code, raceID)
+	}
+	return Discount{}, ErrDiscountCodeNotFound
+}
+
+func (m *mockDiscountService) ListCodes(ctx context.Context, eventID int64) ([]db.DiscountCode, error) {
+	if m.listCodesFunc != nil {
+		return m.listCodesFunc(ctx, eventID)
+	}
+	return nil, nil
+}
+
+func TestDiscount_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		discount Discount
+		price    int32
+		want     int32
+	}{
+		{"takes a percentage off", Discount{PercentOff: 20}, 2500, 500},
+		{"rounds a fraction of a penny up", Discount{PercentOff: 15}, 1999, 300},
+		{"rounds a tiny fraction up", Discount{PercentOff: 1}, 1, 1},
+		{"takes the whole fee at 100%", Discount{PercentOff: 100}, 2999, 2999},
+		{"takes a fixed amount off", Discount{AmountOffUnits: 500}, 2500, 500},
+		{"takes no more than the fee", Discount{AmountOffUnits: 5000}, 2500, 2500},
+		{"takes nothing off a free race", Discount{PercentOff: 50}, 0, 0},
+		{"does not overflow on large fees", Discount{PercentOff: 99}, 2_000_000_000, 1_980_000_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.discount.Apply(tt.price); got != tt.want {
+				t.Errorf("expected %d off %d, got %d", tt.want, tt.price, got)
+			}
+		})
+	}
+}
+
+func TestDiscountService_CreateCode(t *testing.T) {
+	races := &repositorymocks.RaceRepositoryMock{
+		GetByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+			if id == 20 {
+				return db.Race{ID: 20, EventID: 30}, nil
+			}
+			if id == 21 {
+				return db.Race{ID: 21, EventID: 31}, nil
+			}
+			return db.Race{}, repository.ErrNotFound
+		},
+	}
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("stores a code for one race", func(t *testing.T) {
+		var stored db.CreateDiscountCodeParams
+		svc := NewDiscountService(&repositorymocks.DiscountRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateDiscountCodeParams) (db.DiscountCode, error) {
+				stored = params
+				return db.DiscountCode{ID: 5}, nil
+			},
+		}, races)
+
+		code, err := svc.CreateCode(context.Background(), CreateDiscountCodeParams{
+			EventID:    30,
+			RaceID:     20,
+			Code:       " Club-10 ",
+			PercentOff: 10,
+			MaxUses:    50,
+			ValidFrom:  from,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if code.ID != 5 {
+			t.Errorf("expected the stored code, got %+v", code)
+		}
+		want := db.CreateDiscountCodeParams{
+			EventID:    30,
+			RaceID:     pgtype.Int8{Int64: 20, Valid: true},
+			Code:       "Club-10",
+			PercentOff: pgtype.Int4{Int32: 10, Valid: true},
+			MaxUses:    pgtype.Int4{Int32: 50, Valid: true},
+			ValidFrom:  pgtype.Timestamptz{Time: from, Valid: true},
+		}
+		if stored != want {
+			t.Errorf("expected %+v, got %+v", want, stored)
+		}
+	})
+
+	t.Run("reports a code the event already has against the code", func(t *testing.T) {
+		svc := NewDiscountService(&repositorymocks.DiscountRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateDiscountCodeParams) (db.DiscountCode, error) {
+				return db.DiscountCode{}, repository.ErrConflict
+			},
+		}, races)
+
+		_, err := svc.CreateCode(context.Background(), CreateDiscountCodeParams{EventID: 30, Code: "EARLY", AmountOffUnits: 500})
+		var fieldErrs FieldErrors
+		if !errors.As(err, &fieldErrs) || fieldErrs["code"] == "" {
+			t.Errorf("expected an error on code, got %v", err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		params CreateDiscountCodeParams
+		field  string
+	}{
+		{"a blank code", CreateDiscountCodeParams{Code: "  ", PercentOff: 10}, "code"},
+		{"a short code", CreateDiscountCodeParams{Code: "AB", PercentOff: 10}, "code"},
+		{"a long code", CreateDiscountCodeParams{Code: strings.Repeat("A", MaxDiscountCodeLength+1), PercentOff: 10}, "code"},
+		{"spaces in a code", CreateDiscountCodeParams{Code: "RUN CLUB", PercentOff: 10}, "code"},
+		{"no discount", CreateDiscountCodeParams{Code: "EARLY"}, "percent_off"},
+		{"two discounts", CreateDiscountCodeParams{Code: "EARLY", PercentOff: 10, AmountOffUnits: 500}, "percent_off"},
+		{"a percentage over 100", CreateDiscountCodeParams{Code: "EARLY", PercentOff: 101}, "percent_off"},
+		{"a negative amount", CreateDiscountCodeParams{Code: "EARLY", AmountOffUnits: -5}, "amount_off"},
+		{"negative uses", CreateDiscountCodeParams{Code: "EARLY", PercentOff: 10, MaxUses: -1}, "max_uses"},
+		{"an end before the start", CreateDiscountCodeParams{Code: "EARLY", PercentOff: 10, ValidFrom: from, ValidUntil: from}, "valid_until"},
+		{"a race in another event", CreateDiscountCodeParams{Code: "EARLY", PercentOff: 10, RaceID: 21}, "race_id"},
+		{"a missing race", CreateDiscountCodeParams{Code: "EARLY", PercentOff: 10, RaceID: 99}, "race_id"},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			svc := NewDiscountService(&repositorymocks.DiscountRepositoryMock{
+				CreateFunc: func(ctx context.Context, params db.CreateDiscountCodeParams) (db.DiscountCode, error) {
+					t.Error("expected no code to be created")
+					return db.DiscountCode{}, nil
+				},
+			}, races)
+
+			params := tt.params
+			params.EventID = 30
+			_, err := svc.CreateCode(context.Background(), params)
+			var fieldErrs FieldErrors
+			if !errors.As(err, &fieldErrs) || fieldErrs[tt.field] == "" {
+				t.Errorf("expected an error on %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestDiscountService_ValidateCode(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	races := &repositorymocks.RaceRepositoryMock{
+		GetByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+			return db.Race{ID: id, EventID: 30}, nil
+		},
+	}
+	newService := func(codes ...db.DiscountCode) *discountService {
+		svc := NewDiscountService(&repositorymocks.DiscountRepositoryMock{
+			ListByCodeFunc: func(ctx context.Context, code string) ([]db.DiscountCode, error) {
+				return codes, nil
+			},
+		}, races).(*discountService)
+		svc.clock = &MockClock{CurrentTime: now}
+		return svc
+	}
+	eventCode := db.DiscountCode{ID: 5, EventID: 30, Code: "EARLY", PercentOff: pgtype.Int4{Int32: 10, Valid: true}}
+
+	t.Run("returns the discount the event's code gives", func(t *testing.T) {
+		other := db.DiscountCode{ID: 4, EventID: 31, Code: "early", AmountOffUnits: pgtype.Int4{Int32: 500, Valid: true}}
+
+		got, err := newService(other, eventCode).ValidateCode(context.Background(), " early ", 20)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := Discount{CodeID: 5, Code: "EARLY", PercentOff: 10}
+		if got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("accepts a code for the race", func(t *testing.T) {
+		code := eventCode
+		code.RaceID = pgtype.Int8{Int64: 20, Valid: true}
+		if _, err := newService(code).ValidateCode(context.Background(), "EARLY", 20); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("accepts a code with uses left", func(t *testing.T) {
+		code := eventCode
+		code.MaxUses = pgtype.Int4{Int32: 10, Valid: true}
+		code.Uses = 9
+		if _, err := newService(code).ValidateCode(context.Background(), "EARLY", 20); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	with := func(change func(*db.DiscountCode)) db.DiscountCode {
+		code := eventCode
+		change(&code)
+		return code
+	}
+	tests := []struct {
+		name  string
+		codes []db.DiscountCode
+		input string
+		want  error
+	}{
+		{"rejects a blank code", []db.DiscountCode{eventCode}, " ", ErrDiscountCodeNotFound},
+		{"rejects an unknown code", nil, "EARLY", ErrDiscountCodeNotFound},
+		{"rejects another event's code", []db.DiscountCode{with(func(c *db.DiscountCode) { c.EventID = 31 })}, "EARLY", ErrDiscountCodeWrongRace},
+		{"rejects another race's code", []db.DiscountCode{with(func(c *db.DiscountCode) { c.RaceID = pgtype.Int8{Int64: 21, Valid: true} })}, "EARLY", ErrDiscountCodeWrongRace},
+		{"rejects a code before it starts", []db.DiscountCode{with(func(c *db.DiscountCode) {
+			c.ValidFrom = pgtype.Timestamptz{Time: now.Add(time.Minute), Valid: true}
+		})}, "EARLY", ErrDiscountCodeNotYetValid},
+		{"rejects an expired code", []db.DiscountCode{with(func(c *db.DiscountCode) {
+			c.ValidUntil = pgtype.Timestamptz{Time: now, Valid: true}
+		})}, "EARLY", ErrDiscountCodeExpired},
+		{"rejects a used up code", []db.DiscountCode{with(func(c *db.DiscountCode) {
+			c.MaxUses = pgtype.Int4{Int32: 10, Valid: true}
+			c.Uses = 10
+		})}, "EARLY", ErrDiscountCodeExhausted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newService(tt.codes...).ValidateCode(context.Background(), tt.input, 20); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// MaxEmailLength is the longest email address that can be delivered to.
+const MaxEmailLength = 254
+
+// NormalizeEmail returns the form email addresses are stored and looked up
+// in, without surrounding whitespace and in lower case.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateEmail checks that email, already normalised, is a single bare
+// address such as "jane@example.com", with no display name.
+func ValidateEmail(email string) error {
+	if msg := emailProblem(email); msg != "" {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, msg)
+	}
+	return nil
+}
+
+// emailProblem returns why email is not a valid address, or "".
+func emailProblem(email string) string {
+	if email == "" {
+		return "email is required"
+	}
+	if len(email) > MaxEmailLength {
+		return fmt.Sprintf("email must be at most %d characters", MaxEmailLength)
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return "invalid email format"
+	}
+	// ParseAddress also accepts "Jane <jane@example.com>", and takes a
+	// trailing comment as the name
+	if addr.Name != "" || strings.ContainsAny(email, "<>") {
+		return "email must not include a display name"
+	}
+	// Addresses on the public internet have a dotted domain name rather
+	// than a bare host or an IP literal
+	domain := addr.Address[strings.LastIndex(addr.Address, "@")+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, "[") {
+		return "invalid email format"
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{name: "leaves normalised addresses alone", email: "jane@example.com", want: "jane@example.com"},
+		{name: "trims surrounding whitespace", email: " \tjane@example.com\n", want: "jane@example.com"},
+		{name: "lowercases mixed case", email: "Jane.Runner@Example.COM", want: "jane.runner@example.com"},
+		{name: "trims and lowercases together", email: " Foo@Bar.com ", want: "foo@bar.com"},
+		{name: "lowercases internationalised domains", email: "Jane@Bücher.DE", want: "jane@bücher.de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeEmail(tt.email); got != tt.want {
+				t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	// longest is exactly MaxEmailLength characters long
+	longest := strings.Repeat("a", 64) + "@" + strings.Repeat(strings.Repeat("b", 60)+".", 3) + "ex.com"
+
+	tests := []struct {
+		name  string
+		email string
+		// want is part of the expected error message, or "" for a valid address
+		want string
+	}{
+		{name: "accepts a simple address", email: "jane@example.com"},
+		{name: "accepts plus addressing", email: "user+tag@sub.example.co.uk"},
+		{name: "accepts dots and hyphens", email: "jane.runner@trail-club.example.org"},
+		{name: "accepts quoted local parts", email: `"jane runner"@example.com`},
+		{name: "accepts internationalised domains", email: "jane@bücher.de"},
+		{name: "accepts punycode domains", email: "jane@xn--bcher-kva.de"},
+		{name: "accepts internationalised local parts", email: "josé@example.com"},
+		{name: "accepts a padded mixed-case address once normalised", email: NormalizeEmail(" Foo@Bar.com ")},
+		{name: "accepts addresses at the length limit", email: longest},
+		{name: "requires an address", email: "", want: "email is required"},
+		{name: "rejects a missing at sign", email: "jane.example.com", want: "invalid email format"},
+		{name: "rejects two at signs", email: "jane@@example.com", want: "invalid email format"},
+		{name: "rejects unquoted spaces", email: "jane runner@example.com", want: "invalid email format"},
+		{name: "rejects double dots in the domain", email: "jane@example..com", want: "invalid email format"},
+		{name: "rejects a trailing dot in the local part", email: "jane.@example.com", want: "invalid email format"},
+		{name: "rejects undotted domains", email: "jane@localhost", want: "invalid email format"},
+		{name: "rejects IP literal domains", email: "jane@[192.0.2.1]", want: "invalid email format"},
+		{name: "rejects display names", email: "Jane Runner <jane@example.com>", want: "display name"},
+		{name: "rejects angle brackets alone", email: "<jane@example.com>", want: "display name"},
+		{name: "rejects trailing comments", email: "jane@example.com (Jane)", want: "display name"},
+		{name: "rejects address lists", email: "jane@example.com, sam@example.com", want: "invalid email format"},
+		{name: "rejects addresses over the length limit", email: "a" + longest, want: "at most 254 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEmail(tt.email)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("ValidateEmail(%q) = %v, want nil", tt.email, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ValidateEmail(%q) = %v, want ErrInvalidInput mentioning %q", tt.email, err, tt.want)
+			}
+		})
+	}
+}
@@ -4,19 +4,143 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/bus"
+	"firecrest/internal/cache"
 	"firecrest/internal/repository"
 )
 
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/event.go -pkg servicemocks . EventService
+
 // ErrInvalidInput is returned when input validation fails.
-var ErrInvalidInput = errors.New("invalid input")
+var ErrInvalidInput = apperr.New(apperr.CodeInvalidInput, http.StatusBadRequest, "invalid input")
+
+// ErrEventNotFound is returned when no event matches a lookup. It wraps
+// repository.ErrNotFound, so callers checking for that still see it.
+var ErrEventNotFound = apperr.Wrap(repository.ErrNotFound, apperr.CodeEventNotFound, http.StatusNotFound, "event not found")
+
+// notFoundAs returns notFound in place of repository.ErrNotFound, so the
+// client is told what was missing. Other errors are returned as they are.
+func notFoundAs(err, notFound error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound
+	}
+	return err
+}
+
+// ErrSlugTaken is returned when another of the organisation's events in
+// the same year, or a race within the same event, already uses the slug.
+var ErrSlugTaken = apperr.New(apperr.CodeSlugTaken, http.StatusConflict, "slug already taken")
+
+// ErrEventURLTaken is returned when publishing an event whose year and slug
+// another organisation's published event already has, as both would be
+// served from the same public URL.
+var ErrEventURLTaken = apperr.New(apperr.CodeEventURLTaken, http.StatusConflict, "event URL already taken")
+
+// ErrEventHasNoRaces is returned when publishing an event without races.
+var ErrEventHasNoRaces = apperr.New(apperr.CodeEventHasNoRaces, http.StatusConflict, "event has no races")
+
+// ErrNoRegistrationWindow is returned when publishing an event with a race
+// that has no registration window.
+var ErrNoRegistrationWindow = apperr.New(apperr.CodeNoRegistrationWindow, http.StatusConflict, "race has no registration window")
+
+// MinEventYear is the earliest year an event can be created for.
+const MinEventYear = 2025
+
+// MaxEventYearsAhead is how many years after the current one an event can
+// be created for.
+const MaxEventYearsAhead = 5
+
+// DefaultEventTimezone is the time zone events are run in unless their
+// organisers choose another.
+const DefaultEventTimezone = "Europe/London"
+
+// Limits on an event's descriptive fields, in characters.
+const (
+	MaxEventDescriptionLength = 10000
+	MaxEventLocationLength    = 200
+)
+
+// SitemapURLsPerFile is the most URLs the sitemaps protocol allows in one
+// file. Larger sites are split into several, listed by a sitemap index.
+const SitemapURLsPerFile = 50000
 
 // EventService defines the interface for event business logic.
 type EventService interface {
 	ListEvents(ctx context.Context) ([]db.Event, error)
-	GetEvent(ctx context.Context, slug string) (db.Event, error)
+	ListEventsPage(ctx context.Context, params ListEventsParams) (EventPage, error)
+	// ListEventsWithStats returns a page of published events by name for
+	// listing cards, each with its races' totals and registration count,
+	// priced at their races' current price tiers.
+	ListEventsWithStats(ctx context.Context, params ListEventsParams) ([]db.ListEventsWithStatsRow, error)
+	// ListEventsByYear returns the year's events by name. Past events, whose
+	// races have all closed for registration, are left out unless
+	// includePast is set.
+	ListEventsByYear(ctx context.Context, year int32, includePast bool) ([]db.Event, error)
+	// ListYears returns every year with an event, latest first. Events
+	// published on another instance may take EventYearsTTL to appear.
+	ListYears(ctx context.Context) ([]int32, error)
+	// ListArchive returns past events grouped by year, latest year first.
+	// Only the latest past edition of a series is listed, and years left
+	// without a past event are left out.
+	ListArchive(ctx context.Context) ([]EventYear, error)
+	// CountSitemapFiles returns how many sitemap files it takes to list
+	// the event pages, SitemapURLsPerFile to a file. It is never less
+	// than one, so an empty site still has a sitemap.
+	CountSitemapFiles(ctx context.Context) (int, error)
+	// ListSitemapEvents returns the event pages listed in the given
+	// sitemap file, numbered from 1, latest year first and then by slug.
+	// Earlier editions of a series are left to be found from the latest.
+	ListSitemapEvents(ctx context.Context, file int) ([]db.ListSitemapEventsRow, error)
+	// GetEvent returns the published edition in year of the event with the
+	// slug. Drafts and archived events are not found, returning
+	// ErrEventNotFound.
+	GetEvent(ctx context.Context, year int32, slug string) (db.Event, error)
+	// FindEventBySlug returns the latest published edition with the slug,
+	// for URLs from before events were found by year and slug. It returns
+	// ErrEventNotFound when no organisation, or more than one, has
+	// published an event with the slug.
+	FindEventBySlug(ctx context.Context, slug string) (db.Event, error)
+	// GetEventByID returns the event whatever its status, for organisers.
+	GetEventByID(ctx context.Context, id int64) (db.Event, error)
 	CreateEvent(ctx context.Context, input CreateEventInput) (db.Event, error)
+	UpdateEvent(ctx context.Context, id int64, input UpdateEventInput) error
+	DuplicateEvent(ctx context.Context, eventID int64, newYear int32) (db.Event, error)
+	// ListEventsInSeries returns the series' published editions, latest
+	// year first, each with its races that have published results.
+	ListEventsInSeries(ctx context.Context, seriesID int64) ([]db.ListEventsInSeriesRow, error)
+	// ListOrganisationEvents returns every event the organisation runs,
+	// whatever its status, by name and then latest year first.
+	ListOrganisationEvents(ctx context.Context, organisationID int64) ([]db.Event, error)
+	// SetEventSeries adds the event to the series of the event with ID
+	// seriesEventID, which must be another of the organisation's events.
+	// An ID of zero takes the event out of its series.
+	SetEventSeries(ctx context.Context, event db.Event, seriesEventID int64) error
+	// PublishEvent makes the event public and open to entries within its
+	// races' registration windows. It returns ErrEventHasNoRaces if the
+	// event has no races, ErrNoRegistrationWindow if one of them lacks
+	// registration open and close dates, or closes before it opens, and
+	// ErrEventURLTaken if another event is published at its URL.
+	PublishEvent(ctx context.Context, id int64) error
+	// ArchiveEvent takes the event down from the public site and stops it
+	// taking entries. Organisers still see it and may publish it again.
+	ArchiveEvent(ctx context.Context, id int64) error
+	GetEventStats(ctx context.Context, organisationID int64) ([]db.GetEventStatsRow, error)
+	// RegistrationTimeseries counts the event's registrations by day or
+	// week, in its time zone, across its registration window and a
+	// TimeseriesMargin either side. Periods without registrations are
+	// included, with a count of zero.
+	RegistrationTimeseries(ctx context.Context, event db.Event, granularity Granularity) ([]RegistrationBucket, error)
 }
 
 // CreateEventInput represents the input for creating an event.
@@ -24,53 +148,672 @@ type CreateEventInput struct {
 	OrganisationID int64
 	Name           string
 	Slug           string
+	Year           int32
+	// Description is markdown; it is sanitised when rendered.
+	Description string
+	Location    string
+	ImageURL    string
+	// Timezone is the IANA time zone the organisers' local times are read
+	// in. Empty means DefaultEventTimezone.
+	Timezone string
 }
 
-// Validate checks if the input is valid.
-func (i CreateEventInput) Validate() error {
+// Validate checks if the input is valid at now, reporting every problem as
+// FieldErrors.
+func (i CreateEventInput) Validate(now time.Time) error {
+	errs := FieldErrors{}
 	if i.Name == "" {
-		return fmt.Errorf("%w: name is required", ErrInvalidInput)
-	}
-	if i.Slug == "" {
-		return fmt.Errorf("%w: slug is required", ErrInvalidInput)
+		errs.Add("name", "name is required")
 	}
-	if len(i.Slug) > 100 {
-		return fmt.Errorf("%w: slug must be 100 characters or less", ErrInvalidInput)
+	if msg := slugProblem(i.Slug); msg != "" {
+		errs.Add("slug", msg)
 	}
 	if i.OrganisationID <= 0 {
-		return fmt.Errorf("%w: organisation_id must be positive", ErrInvalidInput)
+		errs.Add("organisation_id", "organisation_id must be positive")
+	}
+	if msg := yearProblem(i.Year, now); msg != "" {
+		errs.Add("year", msg)
+	}
+	if i.Timezone != "" {
+		if _, err := LoadEventLocation(i.Timezone); err != nil {
+			errs.Add("timezone", "timezone must be an IANA time zone such as "+DefaultEventTimezone)
+		}
+	}
+	validateEventDetails(errs, i.Description, i.Location, i.ImageURL)
+	return errs.Err()
+}
+
+// yearProblem describes why an event cannot be held in year, judged at now,
+// or returns "" if it can.
+func yearProblem(year int32, now time.Time) string {
+	if year < MinEventYear {
+		return fmt.Sprintf("year must be %d or later", MinEventYear)
+	}
+	if latest := int32(now.Year() + MaxEventYearsAhead); year > latest {
+		return fmt.Sprintf("year must be %d or earlier", latest)
+	}
+	return ""
+}
+
+// LoadEventLocation returns the time zone named by an event's timezone. An
+// empty name is DefaultEventTimezone. "Local" is refused, as it would
+// depend on where the server runs.
+func LoadEventLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultEventTimezone
+	}
+	if name == "Local" {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidInput, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidInput, name)
+	}
+	return loc, nil
+}
+
+// EventLocation returns the time zone the event's organisers think in,
+// falling back to DefaultEventTimezone if the stored one cannot be loaded.
+func EventLocation(event db.Event) *time.Location {
+	if loc, err := LoadEventLocation(event.Timezone); err == nil {
+		return loc
+	}
+	if loc, err := time.LoadLocation(DefaultEventTimezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// InLocation reads the date and clock of wall, ignoring its own location,
+// as a time in loc. A form's "23:59 on 1 June" thus closes at 23:59 in the
+// event's time zone, whether or not summer time is in force. Times that
+// fall in a gap when clocks go forward are moved past it.
+func InLocation(wall time.Time, loc *time.Location) time.Time {
+	year, month, day := wall.Date()
+	hour, minute, sec := wall.Clock()
+	return time.Date(year, month, day, hour, minute, sec, wall.Nanosecond(), loc)
+}
+
+// UpdateEventInput represents the editable fields of an existing event.
+type UpdateEventInput struct {
+	Name string
+	Slug string
+	// Description is markdown; it is sanitised when rendered.
+	Description string
+	Location    string
+	ImageURL    string
+}
+
+// Validate checks if the input is valid, reporting every problem as
+// FieldErrors.
+func (i UpdateEventInput) Validate() error {
+	errs := FieldErrors{}
+	if i.Name == "" {
+		errs.Add("name", "name is required")
+	}
+	if msg := slugProblem(i.Slug); msg != "" {
+		errs.Add("slug", msg)
+	}
+	validateEventDetails(errs, i.Description, i.Location, i.ImageURL)
+	return errs.Err()
+}
+
+// validateEventDetails checks the descriptive fields shared by event inputs,
+// recording problems in errs.
+func validateEventDetails(errs FieldErrors, description, location, imageURL string) {
+	if utf8.RuneCountInString(description) > MaxEventDescriptionLength {
+		errs.Add("description", fmt.Sprintf("description must be at most %d characters", MaxEventDescriptionLength))
+	}
+	if utf8.RuneCountInString(location) > MaxEventLocationLength {
+		errs.Add("location", fmt.Sprintf("location must be at most %d characters", MaxEventLocationLength))
+	}
+	if imageURL != "" && ValidateImageURL(imageURL) != nil {
+		errs.Add("image_url", "image URL must be an https:// address")
+	}
+}
+
+// ValidateImageURL checks that an event image is an absolute https URL.
+func ValidateImageURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: image URL must be an https:// address", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Pagination limits for event listings.
+const (
+	DefaultEventsPerPage = 20
+	MaxEventsPerPage     = 100
+)
+
+// ListEventsParams represents the pagination options for listing events.
+type ListEventsParams struct {
+	Page    int
+	PerPage int
+}
+
+// Validate checks if the pagination options are valid.
+func (p ListEventsParams) Validate() error {
+	if p.Page < 1 {
+		return fmt.Errorf("%w: page must be 1 or greater", ErrInvalidInput)
+	}
+	if p.PerPage < 1 || p.PerPage > MaxEventsPerPage {
+		return fmt.Errorf("%w: per_page must be between 1 and %d", ErrInvalidInput, MaxEventsPerPage)
 	}
 	return nil
 }
 
+// EventPage is a single page of events along with the total number of events.
+type EventPage struct {
+	Events  []db.Event
+	Page    int
+	PerPage int
+	Total   int64
+}
+
+// EventYear is a year's events in the archive.
+type EventYear struct {
+	Year   int32
+	Events []db.Event
+}
+
+// EventYearsTTL is how long the years with events are cached. Publishing or
+// archiving an event refreshes them at once on the instance that did it,
+// which hears of it on the bus; other instances catch up within the TTL.
+const EventYearsTTL = 5 * time.Minute
+
 type eventService struct {
 	eventRepo repository.EventRepository
+	raceRepo  repository.RaceRepository
+	events    *bus.Bus
+	clock     Clock
+	// years holds the years with published events, under the one key
+	years *cache.Cache[struct{}, []int32]
 }
 
-// NewEventService creates a new EventService with the given repository.
-func NewEventService(eventRepo repository.EventRepository) EventService {
-	return &eventService{eventRepo: eventRepo}
+// NewEventService creates a new EventService with the given repositories.
+// Changes to events are published on events, and the years with events
+// are cached until one is.
+func NewEventService(eventRepo repository.EventRepository, raceRepo repository.RaceRepository, events *bus.Bus) EventService {
+	s := &eventService{
+		eventRepo: eventRepo,
+		raceRepo:  raceRepo,
+		events:    events,
+		clock:     RealClock{},
+		years:     cache.New[struct{}, []int32](cache.Options{TTL: EventYearsTTL}),
+	}
+	bus.Subscribe(events, bus.EventUpdated, "event years", func(ctx context.Context, c bus.EventChange) error {
+		s.years.Invalidate(struct{}{})
+		return nil
+	})
+	return s
 }
 
 func (s *eventService) ListEvents(ctx context.Context) ([]db.Event, error) {
+	ctx, span := startSpan(ctx, "EventService.ListEvents")
+	defer span.End()
+
 	return s.eventRepo.List(ctx)
 }
 
-func (s *eventService) GetEvent(ctx context.Context, slug string) (db.Event, error) {
-	if slug == "" || len(slug) > 100 {
+func (s *eventService) ListEventsByYear(ctx context.Context, year int32, includePast bool) ([]db.Event, error) {
+	ctx, span := startSpan(ctx, "EventService.ListEventsByYear")
+	defer span.End()
+
+	if year <= 0 {
+		return nil, fmt.Errorf("%w: invalid year", ErrInvalidInput)
+	}
+
+	rows, err := s.eventRepo.ListByYear(ctx, year, s.clock.Now(), includePast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	events := make([]db.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, row.Event)
+	}
+	return events, nil
+}
+
+func (s *eventService) ListYears(ctx context.Context) ([]int32, error) {
+	ctx, span := startSpan(ctx, "EventService.ListYears")
+	defer span.End()
+
+	years, err := s.listYears(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(years), nil
+}
+
+// listYears returns the years with published events, latest first, from
+// the cache. The slice is shared, so must not be modified.
+func (s *eventService) listYears(ctx context.Context) ([]int32, error) {
+	return s.years.GetOrLoad(struct{}{}, func() ([]int32, error) {
+		return s.eventRepo.ListYears(ctx)
+	})
+}
+
+func (s *eventService) ListArchive(ctx context.Context) ([]EventYear, error) {
+	ctx, span := startSpan(ctx, "EventService.ListArchive")
+	defer span.End()
+
+	years, err := s.listYears(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list years: %w", err)
+	}
+
+	// One query a year; events have only been listed since MinEventYear.
+	// Years are latest first, so the first past edition of a series seen
+	// is the one listed.
+	now := s.clock.Now()
+	seen := make(map[int64]bool)
+	var archive []EventYear
+	for _, year := range years {
+		rows, err := s.eventRepo.ListByYear(ctx, year, now, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events for %d: %w", year, err)
+		}
+		var past []db.Event
+		for _, row := range rows {
+			if !row.Past {
+				continue
+			}
+			if series := row.Event.SeriesID; series.Valid {
+				if seen[series.Int64] {
+					continue
+				}
+				seen[series.Int64] = true
+			}
+			past = append(past, row.Event)
+		}
+		if len(past) > 0 {
+			archive = append(archive, EventYear{Year: year, Events: past})
+		}
+	}
+	return archive, nil
+}
+
+func (s *eventService) CountSitemapFiles(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "EventService.CountSitemapFiles")
+	defer span.End()
+
+	total, err := s.eventRepo.CountSitemap(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count events: %w", err)
+	}
+	return max(1, int((total+SitemapURLsPerFile-1)/SitemapURLsPerFile)), nil
+}
+
+func (s *eventService) ListSitemapEvents(ctx context.Context, file int) ([]db.ListSitemapEventsRow, error) {
+	ctx, span := startSpan(ctx, "EventService.ListSitemapEvents")
+	defer span.End()
+
+	if file < 1 {
+		return nil, fmt.Errorf("%w: sitemap files are numbered from 1", ErrInvalidInput)
+	}
+	offset := (file - 1) * SitemapURLsPerFile
+	if offset > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: sitemap file is too large", ErrInvalidInput)
+	}
+	return s.eventRepo.ListSitemap(ctx, SitemapURLsPerFile, int32(offset))
+}
+
+func (s *eventService) ListEventsPage(ctx context.Context, params ListEventsParams) (EventPage, error) {
+	ctx, span := startSpan(ctx, "EventService.ListEventsPage")
+	defer span.End()
+
+	if err := params.Validate(); err != nil {
+		return EventPage{}, err
+	}
+
+	offset := (params.Page - 1) * params.PerPage
+	if offset > math.MaxInt32 {
+		return EventPage{}, fmt.Errorf("%w: page is too large", ErrInvalidInput)
+	}
+
+	events, err := s.eventRepo.ListPaginated(ctx, int32(params.PerPage), int32(offset))
+	if err != nil {
+		return EventPage{}, err
+	}
+
+	total, err := s.eventRepo.Count(ctx)
+	if err != nil {
+		return EventPage{}, err
+	}
+
+	return EventPage{
+		Events:  events,
+		Page:    params.Page,
+		PerPage: params.PerPage,
+		Total:   total,
+	}, nil
+}
+
+func (s *eventService) ListEventsWithStats(ctx context.Context, params ListEventsParams) ([]db.ListEventsWithStatsRow, error) {
+	ctx, span := startSpan(ctx, "EventService.ListEventsWithStats")
+	defer span.End()
+
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	offset := (params.Page - 1) * params.PerPage
+	if offset > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: page is too large", ErrInvalidInput)
+	}
+
+	return s.eventRepo.ListWithStats(ctx, db.ListEventsWithStatsParams{
+		Now:    pgtype.Timestamptz{Time: s.clock.Now(), Valid: true},
+		Limit:  int32(params.PerPage),
+		Offset: int32(offset),
+	})
+}
+
+func (s *eventService) GetEvent(ctx context.Context, year int32, slug string) (db.Event, error) {
+	ctx, span := startSpan(ctx, "EventService.GetEvent")
+	defer span.End()
+
+	if slug == "" || len(slug) > MaxSlugLength {
+		return db.Event{}, fmt.Errorf("%w: invalid slug", ErrInvalidInput)
+	}
+	event, err := s.eventRepo.GetBySlug(ctx, year, slug)
+	return event, notFoundAs(err, ErrEventNotFound)
+}
+
+func (s *eventService) FindEventBySlug(ctx context.Context, slug string) (db.Event, error) {
+	ctx, span := startSpan(ctx, "EventService.FindEventBySlug")
+	defer span.End()
+
+	if slug == "" || len(slug) > MaxSlugLength {
 		return db.Event{}, fmt.Errorf("%w: invalid slug", ErrInvalidInput)
 	}
-	return s.eventRepo.GetBySlug(ctx, slug)
+	editions, err := s.eventRepo.ListBySlug(ctx, slug)
+	if err != nil {
+		return db.Event{}, err
+	}
+	if len(editions) == 0 {
+		return db.Event{}, ErrEventNotFound
+	}
+	// The slug alone cannot say which organisation's event was meant
+	for _, e := range editions[1:] {
+		if e.OrganisationID != editions[0].OrganisationID {
+			return db.Event{}, fmt.Errorf("%w: slug %q is used by more than one organisation", ErrEventNotFound, slug)
+		}
+	}
+	return editions[0], nil
 }
 
 func (s *eventService) CreateEvent(ctx context.Context, input CreateEventInput) (db.Event, error) {
-	if err := input.Validate(); err != nil {
+	ctx, span := startSpan(ctx, "EventService.CreateEvent")
+	defer span.End()
+
+	if err := input.Validate(s.clock.Now()); err != nil {
 		return db.Event{}, err
 	}
 
-	return s.eventRepo.Create(ctx, db.CreateEventParams{
+	timezone := input.Timezone
+	if timezone == "" {
+		timezone = DefaultEventTimezone
+	}
+	event, err := s.eventRepo.Create(ctx, db.CreateEventParams{
 		OrganisationID: input.OrganisationID,
 		Name:           input.Name,
 		Slug:           input.Slug,
+		Year:           input.Year,
+		Description:    input.Description,
+		Location:       input.Location,
+		ImageUrl:       input.ImageURL,
+		Timezone:       timezone,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return db.Event{}, ErrSlugTaken
+		}
+		return db.Event{}, err
+	}
+	return event, nil
+}
+
+func (s *eventService) UpdateEvent(ctx context.Context, id int64, input UpdateEventInput) error {
+	ctx, span := startSpan(ctx, "EventService.UpdateEvent")
+	defer span.End()
+
+	if id <= 0 {
+		return fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	err := s.eventRepo.Update(ctx, db.UpdateEventParams{
+		ID:          id,
+		Name:        input.Name,
+		Slug:        input.Slug,
+		Description: input.Description,
+		Location:    input.Location,
+		ImageUrl:    input.ImageURL,
 	})
+	if errors.Is(err, repository.ErrConflict) {
+		return ErrSlugTaken
+	}
+	if err != nil {
+		return err
+	}
+	bus.Publish(ctx, s.events, bus.EventUpdated, bus.EventChange{EventID: id})
+	return nil
+}
+
+func (s *eventService) GetEventByID(ctx context.Context, id int64) (db.Event, error) {
+	ctx, span := startSpan(ctx, "EventService.GetEventByID")
+	defer span.End()
+
+	if id <= 0 {
+		return db.Event{}, fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+	event, err := s.eventRepo.GetByID(ctx, id)
+	return event, notFoundAs(err, ErrEventNotFound)
+}
+
+// DuplicateEvent copies an event and its races into newYear, keeping the
+// slug and moving each race's dates forward by the difference in years.
+// Dates keep their local time in the event's time zone, so a race closing
+// at 23:59 in winter still does after the clocks change. Registrations are
+// not copied. The copy joins the source's series, which starts with the
+// source if it is in none yet. It returns repository.ErrConflict if the
+// organisation already has an event with the slug in newYear.
+func (s *eventService) DuplicateEvent(ctx context.Context, eventID int64, newYear int32) (db.Event, error) {
+	ctx, span := startSpan(ctx, "EventService.DuplicateEvent")
+	defer span.End()
+
+	if eventID <= 0 {
+		return db.Event{}, fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+	if msg := yearProblem(newYear, s.clock.Now()); msg != "" {
+		return db.Event{}, fmt.Errorf("%w: %s", ErrInvalidInput, msg)
+	}
+
+	source, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return db.Event{}, err
+	}
+	if newYear == source.Year {
+		return db.Event{}, repository.ErrConflict
+	}
+
+	races, err := s.raceRepo.ListByEvent(ctx, source.ID)
+	if err != nil {
+		return db.Event{}, fmt.Errorf("failed to list races: %w", err)
+	}
+
+	years := int(newYear - source.Year)
+	loc := EventLocation(source)
+	copies := make([]db.CreateRaceParams, 0, len(races))
+	for _, race := range races {
+		copies = append(copies, db.CreateRaceParams{
+			Name:                  race.Name,
+			Slug:                  race.Slug,
+			RegistrationOpenDate:  shiftTimestamptz(race.RegistrationOpenDate, years, loc),
+			RegistrationCloseDate: shiftTimestamptz(race.RegistrationCloseDate, years, loc),
+			StartsAt:              shiftTimestamptz(race.StartsAt, years, loc),
+			MaxCapacity:           race.MaxCapacity,
+			PriceUnits:            race.PriceUnits,
+			Currency:              race.Currency,
+			DistanceMetres:        race.DistanceMetres,
+			ElevationGainMetres:   race.ElevationGainMetres,
+		})
+	}
+
+	return s.eventRepo.CreateWithRaces(ctx, db.CreateEventParams{
+		OrganisationID: source.OrganisationID,
+		Name:           source.Name,
+		Slug:           source.Slug,
+		Year:           newYear,
+		Description:    source.Description,
+		Location:       source.Location,
+		ImageUrl:       source.ImageUrl,
+		Timezone:       loc.String(),
+		SeriesID:       seriesOf(source),
+	}, copies)
+}
+
+// seriesOf returns the series an edition following the event belongs to:
+// the event's own, or one named after the event if it is in none.
+func seriesOf(event db.Event) pgtype.Int8 {
+	if event.SeriesID.Valid {
+		return event.SeriesID
+	}
+	return pgtype.Int8{Int64: event.ID, Valid: true}
+}
+
+func (s *eventService) ListEventsInSeries(ctx context.Context, seriesID int64) ([]db.ListEventsInSeriesRow, error) {
+	ctx, span := startSpan(ctx, "EventService.ListEventsInSeries")
+	defer span.End()
+
+	if seriesID <= 0 {
+		return nil, fmt.Errorf("%w: invalid series id", ErrInvalidInput)
+	}
+	return s.eventRepo.ListInSeries(ctx, seriesID)
+}
+
+func (s *eventService) ListOrganisationEvents(ctx context.Context, organisationID int64) ([]db.Event, error) {
+	ctx, span := startSpan(ctx, "EventService.ListOrganisationEvents")
+	defer span.End()
+
+	if organisationID <= 0 {
+		return nil, fmt.Errorf("%w: invalid organisation id", ErrInvalidInput)
+	}
+	return s.eventRepo.ListByOrganisation(ctx, organisationID)
+}
+
+func (s *eventService) SetEventSeries(ctx context.Context, event db.Event, seriesEventID int64) error {
+	ctx, span := startSpan(ctx, "EventService.SetEventSeries")
+	defer span.End()
+
+	if seriesEventID == 0 {
+		return s.eventRepo.LeaveSeries(ctx, event.ID)
+	}
+	if seriesEventID < 0 || seriesEventID == event.ID {
+		return FieldErrors{"series_event_id": "choose another of your events"}
+	}
+
+	other, err := s.eventRepo.GetByID(ctx, seriesEventID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to get event: %w", err)
+	}
+	// Another organisation's events are not told apart from missing ones
+	if err != nil || other.OrganisationID != event.OrganisationID {
+		return FieldErrors{"series_event_id": "choose another of your events"}
+	}
+	if other.SeriesID.Valid && other.SeriesID == event.SeriesID {
+		return nil
+	}
+	_, err = s.eventRepo.JoinSeries(ctx, event.ID, other.ID)
+	return err
+}
+
+func (s *eventService) PublishEvent(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "EventService.PublishEvent")
+	defer span.End()
+
+	if id <= 0 {
+		return fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+
+	races, err := s.raceRepo.ListByEvent(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to list races: %w", err)
+	}
+	if len(races) == 0 {
+		return ErrEventHasNoRaces
+	}
+	for _, race := range races {
+		open, closes := race.RegistrationOpenDate, race.RegistrationCloseDate
+		if !open.Valid || !closes.Valid || !open.Time.Before(closes.Time) {
+			return fmt.Errorf("%w: %s", ErrNoRegistrationWindow, race.Name)
+		}
+	}
+
+	err = s.eventRepo.SetStatus(ctx, id, db.EventStatusPublished)
+	if errors.Is(err, repository.ErrConflict) {
+		return ErrEventURLTaken
+	}
+	if err != nil {
+		return err
+	}
+	bus.Publish(ctx, s.events, bus.EventUpdated, bus.EventChange{EventID: id})
+	return nil
+}
+
+func (s *eventService) ArchiveEvent(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "EventService.ArchiveEvent")
+	defer span.End()
+
+	if id <= 0 {
+		return fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+	if err := s.eventRepo.SetStatus(ctx, id, db.EventStatusArchived); err != nil {
+		return err
+	}
+	bus.Publish(ctx, s.events, bus.EventUpdated, bus.EventChange{EventID: id})
+	return nil
+}
+
+// shiftTimestamptz moves a nullable timestamp by the given number of years,
+// keeping its local time in loc.
+func shiftTimestamptz(ts pgtype.Timestamptz, years int, loc *time.Location) pgtype.Timestamptz {
+	if !ts.Valid {
+		return ts
+	}
+	ts.Time = shiftYears(ts.Time.In(loc), years).UTC()
+	return ts
+}
+
+// shiftYears moves t by the given number of years, keeping the month, day
+// and time of day. A 29 February that lands in a common year becomes
+// 28 February rather than rolling over into March as time.AddDate would.
+func shiftYears(t time.Time, years int) time.Time {
+	year, month, day := t.Date()
+	year += years
+	if last := daysIn(year, month); day > last {
+		day = last
+	}
+	hour, minute, sec := t.Clock()
+	return time.Date(year, month, day, hour, minute, sec, t.Nanosecond(), t.Location())
+}
+
+// daysIn returns the number of days in the month of the given year.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func (s *eventService) GetEventStats(ctx context.Context, organisationID int64) ([]db.GetEventStatsRow, error) {
+	ctx, span := startSpan(ctx, "EventService.GetEventStats")
+	defer span.End()
+
+	if organisationID <= 0 {
+		return nil, fmt.Errorf("%w: invalid organisation id", ErrInvalidInput)
+	}
+	return s.eventRepo.GetEventStats(ctx, organisationID)
 }
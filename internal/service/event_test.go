@@ -3,41 +3,21 @@ package service
 import (
 	"context"
 	"errors"
+	"log/slog"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/bus"
+	"firecrest/internal/mocks/repositorymocks"
 	"firecrest/internal/repository"
 )
 
-// mockEventRepository implements repository.EventRepository for testing.
-type mockEventRepository struct {
-	listFunc      func(ctx context.Context) ([]db.Event, error)
-	getBySlugFunc func(ctx context.Context, slug string) (db.Event, error)
-	createFunc    func(ctx context.Context, params db.CreateEventParams) (db.Event, error)
-}
-
-func (m *mockEventRepository) List(ctx context.Context) ([]db.Event, error) {
-	if m.listFunc != nil {
-		return m.listFunc(ctx)
-	}
-	return nil, nil
-}
-
-func (m *mockEventRepository) GetBySlug(ctx context.Context, slug string) (db.Event, error) {
-	if m.getBySlugFunc != nil {
-		return m.getBySlugFunc(ctx, slug)
-	}
-	return db.Event{}, nil
-}
-
-func (m *mockEventRepository) Create(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
-	if m.createFunc != nil {
-		return m.createFunc(ctx, params)
-	}
-	return db.Event{}, nil
-}
-
 func TestEventService_ListEvents(t *testing.T) {
 	t.Run("returns events from repository", func(t *testing.T) {
 		expected := []db.Event{
@@ -45,13 +25,13 @@ func TestEventService_ListEvents(t *testing.T) {
 			{ID: 2, Name: "Event 2", Slug: "event-2"},
 		}
 
-		repo := &mockEventRepository{
-			listFunc: func(ctx context.Context) ([]db.Event, error) {
+		repo := &repositorymocks.EventRepositoryMock{
+			ListFunc: func(ctx context.Context) ([]db.Event, error) {
 				return expected, nil
 			},
 		}
 
-		svc := NewEventService(repo)
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
 		events, err := svc.ListEvents(context.Background())
 
 		if err != nil {
@@ -63,13 +43,13 @@ func TestEventService_ListEvents(t *testing.T) {
 	})
 
 	t.Run("propagates repository errors", func(t *testing.T) {
-		repo := &mockEventRepository{
-			listFunc: func(ctx context.Context) ([]db.Event, error) {
+		repo := &repositorymocks.EventRepositoryMock{
+			ListFunc: func(ctx context.Context) ([]db.Event, error) {
 				return nil, errors.New("database error")
 			},
 		}
 
-		svc := NewEventService(repo)
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
 		_, err := svc.ListEvents(context.Background())
 
 		if err == nil {
@@ -78,21 +58,348 @@ func TestEventService_ListEvents(t *testing.T) {
 	})
 }
 
+func TestEventService_ListEventsPage(t *testing.T) {
+	t.Run("converts page to limit and offset", func(t *testing.T) {
+		var gotLimit, gotOffset int32
+		repo := &repositorymocks.EventRepositoryMock{
+			ListPaginatedFunc: func(ctx context.Context, limit, offset int32) ([]db.Event, error) {
+				gotLimit, gotOffset = limit, offset
+				return []db.Event{{ID: 21}}, nil
+			},
+			CountFunc: func(ctx context.Context) (int64, error) {
+				return 45, nil
+			},
+		}
+
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		page, err := svc.ListEventsPage(context.Background(), ListEventsParams{Page: 2, PerPage: 20})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotLimit != 20 || gotOffset != 20 {
+			t.Errorf("expected limit 20 offset 20, got limit %d offset %d", gotLimit, gotOffset)
+		}
+		if page.Total != 45 {
+			t.Errorf("expected total 45, got %d", page.Total)
+		}
+		if len(page.Events) != 1 {
+			t.Errorf("expected 1 event, got %d", len(page.Events))
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for invalid pagination", func(t *testing.T) {
+		cases := []ListEventsParams{
+			{Page: 0, PerPage: 20},
+			{Page: 1, PerPage: 0},
+			{Page: 1, PerPage: MaxEventsPerPage + 1},
+		}
+
+		svc := NewEventService(&repositorymocks.EventRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, nil)
+		for _, params := range cases {
+			if _, err := svc.ListEventsPage(context.Background(), params); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("params %+v: expected ErrInvalidInput, got %v", params, err)
+			}
+		}
+	})
+
+	t.Run("propagates repository errors", func(t *testing.T) {
+		repo := &repositorymocks.EventRepositoryMock{
+			CountFunc: func(ctx context.Context) (int64, error) {
+				return 0, errors.New("database error")
+			},
+		}
+
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		_, err := svc.ListEventsPage(context.Background(), ListEventsParams{Page: 1, PerPage: 20})
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestEventService_ListEventsWithStats(t *testing.T) {
+	t.Run("converts page to limit and offset", func(t *testing.T) {
+		repo := &repositorymocks.EventRepositoryMock{
+			ListWithStatsFunc: func(ctx context.Context, params db.ListEventsWithStatsParams) ([]db.ListEventsWithStatsRow, error) {
+				return []db.ListEventsWithStatsRow{{Event: db.Event{ID: 41}, RaceCount: 2}}, nil
+			},
+		}
+
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		rows, err := svc.ListEventsWithStats(context.Background(), ListEventsParams{Page: 3, PerPage: 20})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != 1 || rows[0].RaceCount != 2 {
+			t.Errorf("unexpected rows %+v", rows)
+		}
+		calls := repo.ListWithStatsCalls()
+		if len(calls) != 1 || calls[0].Params.Limit != 20 || calls[0].Params.Offset != 40 {
+			t.Errorf("expected limit 20 offset 40, got %+v", calls)
+		}
+	})
+
+	t.Run("prices races at the current time", func(t *testing.T) {
+		now := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+		repo := &repositorymocks.EventRepositoryMock{}
+		svc := &eventService{eventRepo: repo, raceRepo: &repositorymocks.RaceRepositoryMock{}, clock: &MockClock{CurrentTime: now}}
+
+		if _, err := svc.ListEventsWithStats(context.Background(), ListEventsParams{Page: 1, PerPage: 20}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if calls := repo.ListWithStatsCalls(); len(calls) != 1 || !calls[0].Params.Now.Time.Equal(now) {
+			t.Errorf("expected the prices at %v, got %+v", now, calls)
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for invalid pagination", func(t *testing.T) {
+		repo := &repositorymocks.EventRepositoryMock{}
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+
+		if _, err := svc.ListEventsWithStats(context.Background(), ListEventsParams{Page: 0, PerPage: 20}); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if len(repo.ListWithStatsCalls()) != 0 {
+			t.Error("expected no query for invalid pagination")
+		}
+	})
+}
+
+func TestEventService_ListEventsByYear(t *testing.T) {
+	t.Run("passes the year, current time and past flag to the repository", func(t *testing.T) {
+		now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+		var gotYear int32
+		var gotNow time.Time
+		var gotIncludePast bool
+		repo := &repositorymocks.EventRepositoryMock{
+			ListByYearFunc: func(ctx context.Context, year int32, now time.Time, includePast bool) ([]db.ListEventsByYearRow, error) {
+				gotYear, gotNow, gotIncludePast = year, now, includePast
+				return []db.ListEventsByYearRow{{Event: db.Event{ID: 1}}, {Event: db.Event{ID: 2}}}, nil
+			},
+		}
+
+		svc := &eventService{eventRepo: repo, raceRepo: &repositorymocks.RaceRepositoryMock{}, clock: &MockClock{CurrentTime: now}}
+		events, err := svc.ListEventsByYear(context.Background(), 2026, true)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotYear != 2026 || !gotNow.Equal(now) || !gotIncludePast {
+			t.Errorf("expected 2026 at %v including past, got %d at %v including past %v", now, gotYear, gotNow, gotIncludePast)
+		}
+		if len(events) != 2 {
+			t.Errorf("expected 2 events, got %d", len(events))
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for an invalid year", func(t *testing.T) {
+		svc := NewEventService(&repositorymocks.EventRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, nil)
+		if _, err := svc.ListEventsByYear(context.Background(), 0, false); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestEventService_ListArchive(t *testing.T) {
+	t.Run("groups past events by year, skipping years without any", func(t *testing.T) {
+		rows := map[int32][]db.ListEventsByYearRow{
+			2026: {
+				{Event: db.Event{ID: 1, Name: "Closed"}, Past: true},
+				{Event: db.Event{ID: 2, Name: "Open"}},
+			},
+			2025: {{Event: db.Event{ID: 3, Name: "Last Year"}, Past: true}},
+			2027: {{Event: db.Event{ID: 4, Name: "Next Year"}}},
+		}
+		repo := &repositorymocks.EventRepositoryMock{
+			ListYearsFunc: func(ctx context.Context) ([]int32, error) {
+				return []int32{2027, 2026, 2025}, nil
+			},
+			ListByYearFunc: func(ctx context.Context, year int32, now time.Time, includePast bool) ([]db.ListEventsByYearRow, error) {
+				if !includePast {
+					t.Error("expected past events to be included")
+				}
+				return rows[year], nil
+			},
+		}
+
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		archive, err := svc.ListArchive(context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(archive) != 2 {
+			t.Fatalf("expected 2 years, got %+v", archive)
+		}
+		if archive[0].Year != 2026 || len(archive[0].Events) != 1 || archive[0].Events[0].ID != 1 {
+			t.Errorf("expected only the closed 2026 event first, got %+v", archive[0])
+		}
+		if archive[1].Year != 2025 || len(archive[1].Events) != 1 {
+			t.Errorf("expected the 2025 event second, got %+v", archive[1])
+		}
+	})
+
+	t.Run("lists only the latest past edition of a series", func(t *testing.T) {
+		series := pgtype.Int8{Int64: 3, Valid: true}
+		rows := map[int32][]db.ListEventsByYearRow{
+			2027: {{Event: db.Event{ID: 5, Name: "Snowdonia Marathon", SeriesID: series}}},
+			2026: {
+				{Event: db.Event{ID: 4, Name: "Snowdonia Marathon", SeriesID: series}, Past: true},
+				{Event: db.Event{ID: 6, Name: "One Off"}, Past: true},
+			},
+			2025: {{Event: db.Event{ID: 3, Name: "Snowdonia Marathon", SeriesID: series}, Past: true}},
+		}
+		repo := &repositorymocks.EventRepositoryMock{
+			ListYearsFunc: func(ctx context.Context) ([]int32, error) {
+				return []int32{2027, 2026, 2025}, nil
+			},
+			ListByYearFunc: func(ctx context.Context, year int32, now time.Time, includePast bool) ([]db.ListEventsByYearRow, error) {
+				return rows[year], nil
+			},
+		}
+
+		archive, err := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil).ListArchive(context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(archive) != 1 || archive[0].Year != 2026 || len(archive[0].Events) != 2 || archive[0].Events[0].ID != 4 {
+			t.Errorf("expected only 2026's editions, got %+v", archive)
+		}
+	})
+
+	t.Run("propagates repository errors", func(t *testing.T) {
+		repo := &repositorymocks.EventRepositoryMock{
+			ListYearsFunc: func(ctx context.Context) ([]int32, error) {
+				return nil, errors.New("database error")
+			},
+		}
+
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		if _, err := svc.ListArchive(context.Background()); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestEventService_ListYears(t *testing.T) {
+	opens := pgtype.Timestamptz{Time: time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC), Valid: true}
+	closes := pgtype.Timestamptz{Time: time.Date(2026, time.May, 1, 23, 0, 0, 0, time.UTC), Valid: true}
+	years := []int32{2026}
+	repo := &repositorymocks.EventRepositoryMock{
+		ListYearsFunc: func(ctx context.Context) ([]int32, error) {
+			return slices.Clone(years), nil
+		},
+	}
+	raceRepo := &repositorymocks.RaceRepositoryMock{
+		ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.Race, error) {
+			return []db.Race{{ID: 10, Name: "10K", RegistrationOpenDate: opens, RegistrationCloseDate: closes}}, nil
+		},
+	}
+	svc := NewEventService(repo, raceRepo, bus.New(slog.New(slog.DiscardHandler)))
+	ctx := context.Background()
+
+	for range 3 {
+		if got, err := svc.ListYears(ctx); err != nil || !slices.Equal(got, []int32{2026}) {
+			t.Fatalf("expected [2026], got %v (err %v)", got, err)
+		}
+	}
+	if _, err := svc.ListArchive(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(repo.ListYearsCalls()); n != 1 {
+		t.Errorf("expected the years to be loaded once, got %d loads", n)
+	}
+
+	// Publishing an event in a new year shows it at once
+	years = []int32{2027, 2026}
+	if err := svc.PublishEvent(ctx, 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := svc.ListYears(ctx); !slices.Equal(got, years) {
+		t.Errorf("expected %v after publishing, got %v", years, got)
+	}
+}
+
+func TestEventService_CountSitemapFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		events int64
+		want   int
+	}{
+		{name: "an empty site still has one file", events: 0, want: 1},
+		{name: "a full file", events: SitemapURLsPerFile, want: 1},
+		{name: "one event over", events: SitemapURLsPerFile + 1, want: 2},
+		{name: "several files", events: 3*SitemapURLsPerFile - 1, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repositorymocks.EventRepositoryMock{
+				CountSitemapFunc: func(ctx context.Context) (int64, error) {
+					return tt.events, nil
+				},
+			}
+
+			files, err := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil).CountSitemapFiles(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if files != tt.want {
+				t.Errorf("expected %d files, got %d", tt.want, files)
+			}
+		})
+	}
+}
+
+func TestEventService_ListSitemapEvents(t *testing.T) {
+	t.Run("pages through events a file at a time", func(t *testing.T) {
+		var gotLimit, gotOffset int32
+		repo := &repositorymocks.EventRepositoryMock{
+			ListSitemapFunc: func(ctx context.Context, limit, offset int32) ([]db.ListSitemapEventsRow, error) {
+				gotLimit, gotOffset = limit, offset
+				return []db.ListSitemapEventsRow{{Slug: "lincoln-10k"}}, nil
+			},
+		}
+
+		events, err := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil).ListSitemapEvents(context.Background(), 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(events) != 1 {
+			t.Errorf("expected the repository's events, got %+v", events)
+		}
+		if gotLimit != SitemapURLsPerFile || gotOffset != 2*SitemapURLsPerFile {
+			t.Errorf("expected limit %d offset %d, got %d and %d", SitemapURLsPerFile, 2*SitemapURLsPerFile, gotLimit, gotOffset)
+		}
+	})
+
+	t.Run("rejects files before the first", func(t *testing.T) {
+		_, err := NewEventService(&repositorymocks.EventRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, nil).ListSitemapEvents(context.Background(), 0)
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
 func TestEventService_GetEvent(t *testing.T) {
 	t.Run("returns event for valid slug", func(t *testing.T) {
 		expected := db.Event{ID: 1, Name: "Test Event", Slug: "test-event"}
 
-		repo := &mockEventRepository{
-			getBySlugFunc: func(ctx context.Context, slug string) (db.Event, error) {
-				if slug == "test-event" {
+		repo := &repositorymocks.EventRepositoryMock{
+			GetBySlugFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
+				if year == 2026 && slug == "test-event" {
 					return expected, nil
 				}
 				return db.Event{}, repository.ErrNotFound
 			},
 		}
 
-		svc := NewEventService(repo)
-		event, err := svc.GetEvent(context.Background(), "test-event")
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		event, err := svc.GetEvent(context.Background(), 2026, "test-event")
 
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -103,10 +410,10 @@ func TestEventService_GetEvent(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for empty slug", func(t *testing.T) {
-		repo := &mockEventRepository{}
-		svc := NewEventService(repo)
+		repo := &repositorymocks.EventRepositoryMock{}
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
 
-		_, err := svc.GetEvent(context.Background(), "")
+		_, err := svc.GetEvent(context.Background(), 2026, "")
 
 		if !errors.Is(err, ErrInvalidInput) {
 			t.Errorf("expected ErrInvalidInput, got %v", err)
@@ -114,11 +421,11 @@ func TestEventService_GetEvent(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for slug exceeding 100 characters", func(t *testing.T) {
-		repo := &mockEventRepository{}
-		svc := NewEventService(repo)
+		repo := &repositorymocks.EventRepositoryMock{}
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
 		longSlug := strings.Repeat("a", 101)
 
-		_, err := svc.GetEvent(context.Background(), string(longSlug))
+		_, err := svc.GetEvent(context.Background(), 2026, string(longSlug))
 
 		if !errors.Is(err, ErrInvalidInput) {
 			t.Errorf("expected ErrInvalidInput, got %v", err)
@@ -126,18 +433,68 @@ func TestEventService_GetEvent(t *testing.T) {
 	})
 
 	t.Run("returns ErrNotFound for non-existent event", func(t *testing.T) {
-		repo := &mockEventRepository{
-			getBySlugFunc: func(ctx context.Context, slug string) (db.Event, error) {
+		repo := &repositorymocks.EventRepositoryMock{
+			GetBySlugFunc: func(ctx context.Context, year int32, slug string) (db.Event, error) {
 				return db.Event{}, repository.ErrNotFound
 			},
 		}
 
-		svc := NewEventService(repo)
-		_, err := svc.GetEvent(context.Background(), "non-existent")
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		_, err := svc.GetEvent(context.Background(), 2026, "non-existent")
 
 		if !errors.Is(err, repository.ErrNotFound) {
 			t.Errorf("expected ErrNotFound, got %v", err)
 		}
+		if !errors.Is(err, ErrEventNotFound) || apperr.CodeOf(err) != apperr.CodeEventNotFound {
+			t.Errorf("expected the error to say the event was missing, got %v", err)
+		}
+	})
+}
+
+func TestEventService_FindEventBySlug(t *testing.T) {
+	find := func(editions ...db.Event) (db.Event, error) {
+		repo := &repositorymocks.EventRepositoryMock{
+			ListBySlugFunc: func(ctx context.Context, slug string) ([]db.Event, error) {
+				return editions, nil
+			},
+		}
+		return NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil).FindEventBySlug(context.Background(), "half-marathon")
+	}
+
+	t.Run("returns the latest edition of one organisation's event", func(t *testing.T) {
+		event, err := find(
+			db.Event{ID: 2, OrganisationID: 1, Slug: "half-marathon", Year: 2027},
+			db.Event{ID: 1, OrganisationID: 1, Slug: "half-marathon", Year: 2026},
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if event.ID != 2 {
+			t.Errorf("expected the 2027 edition, got %+v", event)
+		}
+	})
+
+	t.Run("does not choose between organisations", func(t *testing.T) {
+		_, err := find(
+			db.Event{ID: 2, OrganisationID: 1, Slug: "half-marathon", Year: 2027},
+			db.Event{ID: 1, OrganisationID: 2, Slug: "half-marathon", Year: 2026},
+		)
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrNotFound without a published edition", func(t *testing.T) {
+		if _, err := find(); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for empty slug", func(t *testing.T) {
+		_, err := NewEventService(&repositorymocks.EventRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, nil).FindEventBySlug(context.Background(), "")
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
 	})
 }
 
@@ -145,17 +502,18 @@ func TestEventService_CreateEvent(t *testing.T) {
 	t.Run("creates event with valid input", func(t *testing.T) {
 		expected := db.Event{ID: 1, Name: "New Event", Slug: "new-event", OrganisationID: 1}
 
-		repo := &mockEventRepository{
-			createFunc: func(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
+		repo := &repositorymocks.EventRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
 				return expected, nil
 			},
 		}
 
-		svc := NewEventService(repo)
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
 		event, err := svc.CreateEvent(context.Background(), CreateEventInput{
 			OrganisationID: 1,
 			Name:           "New Event",
 			Slug:           "new-event",
+			Year:           2026,
 		})
 
 		if err != nil {
@@ -167,13 +525,14 @@ func TestEventService_CreateEvent(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for missing name", func(t *testing.T) {
-		repo := &mockEventRepository{}
-		svc := NewEventService(repo)
+		repo := &repositorymocks.EventRepositoryMock{}
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
 
 		_, err := svc.CreateEvent(context.Background(), CreateEventInput{
 			OrganisationID: 1,
 			Name:           "",
 			Slug:           "new-event",
+			Year:           2026,
 		})
 
 		if !errors.Is(err, ErrInvalidInput) {
@@ -182,13 +541,14 @@ func TestEventService_CreateEvent(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for missing slug", func(t *testing.T) {
-		repo := &mockEventRepository{}
-		svc := NewEventService(repo)
+		repo := &repositorymocks.EventRepositoryMock{}
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
 
 		_, err := svc.CreateEvent(context.Background(), CreateEventInput{
 			OrganisationID: 1,
 			Name:           "New Event",
 			Slug:           "",
+			Year:           2026,
 		})
 
 		if !errors.Is(err, ErrInvalidInput) {
@@ -197,13 +557,14 @@ func TestEventService_CreateEvent(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for invalid organisation_id", func(t *testing.T) {
-		repo := &mockEventRepository{}
-		svc := NewEventService(repo)
+		repo := &repositorymocks.EventRepositoryMock{}
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
 
 		_, err := svc.CreateEvent(context.Background(), CreateEventInput{
 			OrganisationID: 0,
 			Name:           "New Event",
 			Slug:           "new-event",
+			Year:           2026,
 		})
 
 		if !errors.Is(err, ErrInvalidInput) {
@@ -212,21 +573,749 @@ func TestEventService_CreateEvent(t *testing.T) {
 	})
 
 	t.Run("propagates repository errors", func(t *testing.T) {
-		repo := &mockEventRepository{
-			createFunc: func(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
+		repo := &repositorymocks.EventRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
 				return db.Event{}, errors.New("database error")
 			},
 		}
 
-		svc := NewEventService(repo)
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
 		_, err := svc.CreateEvent(context.Background(), CreateEventInput{
 			OrganisationID: 1,
 			Name:           "New Event",
 			Slug:           "new-event",
+			Year:           2026,
 		})
 
 		if err == nil {
 			t.Error("expected error, got nil")
 		}
 	})
+
+	t.Run("returns ErrInvalidInput for year before minimum", func(t *testing.T) {
+		repo := &repositorymocks.EventRepositoryMock{}
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+
+		_, err := svc.CreateEvent(context.Background(), CreateEventInput{
+			OrganisationID: 1,
+			Name:           "New Event",
+			Slug:           "new-event",
+			Year:           2024,
+		})
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrSlugTaken on repository conflict", func(t *testing.T) {
+		repo := &repositorymocks.EventRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
+				return db.Event{}, repository.ErrConflict
+			},
+		}
+
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		_, err := svc.CreateEvent(context.Background(), CreateEventInput{
+			OrganisationID: 1,
+			Name:           "New Event",
+			Slug:           "new-event",
+			Year:           2026,
+		})
+
+		if !errors.Is(err, ErrSlugTaken) {
+			t.Errorf("expected ErrSlugTaken, got %v", err)
+		}
+	})
+
+	t.Run("lets two organisations use the same slug in a year", func(t *testing.T) {
+		// The repository refuses a slug its organisation already uses that
+		// year, as the unique constraint does
+		type key struct {
+			org  int64
+			year int32
+			slug string
+		}
+		taken := map[key]bool{}
+		repo := &repositorymocks.EventRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
+				k := key{params.OrganisationID, params.Year, params.Slug}
+				if taken[k] {
+					return db.Event{}, repository.ErrConflict
+				}
+				taken[k] = true
+				return db.Event{OrganisationID: params.OrganisationID, Slug: params.Slug, Year: params.Year}, nil
+			},
+		}
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		create := func(org int64) error {
+			_, err := svc.CreateEvent(context.Background(), CreateEventInput{
+				OrganisationID: org,
+				Name:           "Half Marathon",
+				Slug:           "half-marathon",
+				Year:           2026,
+			})
+			return err
+		}
+
+		for _, org := range []int64{1, 2} {
+			if err := create(org); err != nil {
+				t.Fatalf("organisation %d: unexpected error: %v", org, err)
+			}
+		}
+		if err := create(2); !errors.Is(err, ErrSlugTaken) {
+			t.Errorf("expected ErrSlugTaken within one organisation, got %v", err)
+		}
+	})
+
+	t.Run("saves description, location and image", func(t *testing.T) {
+		var got db.CreateEventParams
+		repo := &repositorymocks.EventRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
+				got = params
+				return db.Event{ID: 1}, nil
+			},
+		}
+
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		_, err := svc.CreateEvent(context.Background(), CreateEventInput{
+			OrganisationID: 1,
+			Name:           "New Event",
+			Slug:           "new-event",
+			Year:           2026,
+			Description:    "A **hilly** route",
+			Location:       "Castleton",
+			ImageURL:       "https://example.com/hero.jpg",
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Description != "A **hilly** route" || got.Location != "Castleton" || got.ImageUrl != "https://example.com/hero.jpg" {
+			t.Errorf("expected details to be saved, got %+v", got)
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for years too far ahead", func(t *testing.T) {
+		svc := &eventService{
+			eventRepo: &repositorymocks.EventRepositoryMock{},
+			raceRepo:  &repositorymocks.RaceRepositoryMock{},
+			clock:     &MockClock{CurrentTime: time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)},
+		}
+		input := CreateEventInput{OrganisationID: 1, Name: "New Event", Slug: "new-event", Year: 2031}
+
+		if _, err := svc.CreateEvent(context.Background(), input); err != nil {
+			t.Fatalf("expected five years ahead to be allowed, got %v", err)
+		}
+		input.Year = 2032
+		_, err := svc.CreateEvent(context.Background(), input)
+		var fields FieldErrors
+		if !errors.As(err, &fields) || fields["year"] != "year must be 2031 or earlier" {
+			t.Errorf("expected a year field error, got %v", err)
+		}
+	})
+
+	t.Run("saves the time zone, defaulting to Europe/London", func(t *testing.T) {
+		var got db.CreateEventParams
+		repo := &repositorymocks.EventRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateEventParams) (db.Event, error) {
+				got = params
+				return db.Event{ID: 1}, nil
+			},
+		}
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		input := CreateEventInput{OrganisationID: 1, Name: "New Event", Slug: "new-event", Year: 2026}
+
+		if _, err := svc.CreateEvent(context.Background(), input); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Timezone != "Europe/London" {
+			t.Errorf("expected the default time zone, got %q", got.Timezone)
+		}
+
+		input.Timezone = "Europe/Dublin"
+		if _, err := svc.CreateEvent(context.Background(), input); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Timezone != "Europe/Dublin" {
+			t.Errorf("expected Europe/Dublin, got %q", got.Timezone)
+		}
+	})
+
+	t.Run("rejects unknown time zones", func(t *testing.T) {
+		svc := NewEventService(&repositorymocks.EventRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, nil)
+
+		for _, tz := range []string{"Europe/Lincoln", "Local", "BST"} {
+			_, err := svc.CreateEvent(context.Background(), CreateEventInput{OrganisationID: 1, Name: "New Event", Slug: "new-event", Year: 2026, Timezone: tz})
+			var fields FieldErrors
+			if !errors.As(err, &fields) || fields["timezone"] == "" {
+				t.Errorf("%s: expected a timezone field error, got %v", tz, err)
+			}
+		}
+	})
+}
+
+func TestValidateEventDetails(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		location    string
+		imageURL    string
+		wantErr     bool
+	}{
+		{name: "accepts empty details"},
+		{name: "accepts maximum description", description: strings.Repeat("é", MaxEventDescriptionLength)},
+		{name: "rejects long description", description: strings.Repeat("a", MaxEventDescriptionLength+1), wantErr: true},
+		{name: "accepts maximum location", location: strings.Repeat("a", MaxEventLocationLength)},
+		{name: "rejects long location", location: strings.Repeat("a", MaxEventLocationLength+1), wantErr: true},
+		{name: "accepts https image", imageURL: "https://example.com/hero.jpg?w=600"},
+		{name: "rejects http image", imageURL: "http://example.com/hero.jpg", wantErr: true},
+		{name: "rejects javascript image", imageURL: "javascript:alert(1)", wantErr: true},
+		{name: "rejects relative image", imageURL: "/static/hero.jpg", wantErr: true},
+		{name: "rejects image without host", imageURL: "https:///hero.jpg", wantErr: true},
+		{name: "rejects unparseable image", imageURL: "https://exa mple.com/%zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := FieldErrors{}
+			validateEventDetails(errs, tt.description, tt.location, tt.imageURL)
+			err := errs.Err()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Errorf("expected ErrInvalidInput, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEventService_UpdateEvent(t *testing.T) {
+	valid := UpdateEventInput{
+		Name:        "Peak District Ultra",
+		Slug:        "peak-district-ultra",
+		Description: "Now with a *new* route",
+		Location:    "Castleton",
+		ImageURL:    "https://example.com/hero.jpg",
+	}
+
+	t.Run("updates the event", func(t *testing.T) {
+		var got db.UpdateEventParams
+		repo := &repositorymocks.EventRepositoryMock{
+			UpdateFunc: func(ctx context.Context, params db.UpdateEventParams) error {
+				got = params
+				return nil
+			},
+		}
+
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		if err := svc.UpdateEvent(context.Background(), 7, valid); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := db.UpdateEventParams{
+			ID:          7,
+			Name:        valid.Name,
+			Slug:        valid.Slug,
+			Description: valid.Description,
+			Location:    valid.Location,
+			ImageUrl:    valid.ImageURL,
+		}
+		if got != want {
+			t.Errorf("expected params %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for an invalid image", func(t *testing.T) {
+		input := valid
+		input.ImageURL = "http://example.com/hero.jpg"
+
+		svc := NewEventService(&repositorymocks.EventRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, nil)
+		if err := svc.UpdateEvent(context.Background(), 7, input); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrSlugTaken on repository conflict", func(t *testing.T) {
+		repo := &repositorymocks.EventRepositoryMock{
+			UpdateFunc: func(ctx context.Context, params db.UpdateEventParams) error {
+				return repository.ErrConflict
+			},
+		}
+
+		svc := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil)
+		if err := svc.UpdateEvent(context.Background(), 7, valid); !errors.Is(err, ErrSlugTaken) {
+			t.Errorf("expected ErrSlugTaken, got %v", err)
+		}
+	})
+}
+
+func TestEventService_GetEventStats(t *testing.T) {
+	t.Run("returns stats for the organisation", func(t *testing.T) {
+		var gotID int64
+		repo := &repositorymocks.EventRepositoryMock{
+			GetEventStatsFunc: func(ctx context.Context, organisationID int64) ([]db.GetEventStatsRow, error) {
+				gotID = organisationID
+				return []db.GetEventStatsRow{{ID: 1, Name: "Lincoln 10k", Registrations: 12}}, nil
+			},
+		}
+
+		stats, err := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil).GetEventStats(context.Background(), 3)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotID != 3 {
+			t.Errorf("expected organisation 3, got %d", gotID)
+		}
+		if len(stats) != 1 || stats[0].Registrations != 12 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for invalid organisation id", func(t *testing.T) {
+		_, err := NewEventService(&repositorymocks.EventRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, nil).GetEventStats(context.Background(), 0)
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestEventService_DuplicateEvent(t *testing.T) {
+	ts := func(year int, month time.Month, day, hour int) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: time.Date(year, month, day, hour, 0, 0, 0, time.UTC), Valid: true}
+	}
+	source := db.Event{ID: 4, OrganisationID: 2, Name: "Leap Day Ultra", Slug: "leap-day-ultra", Year: 2028}
+
+	t.Run("copies event and races into the new year", func(t *testing.T) {
+		var gotEvent db.CreateEventParams
+		var gotRaces []db.CreateRaceParams
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return source, nil
+			},
+			CreateWithRacesFunc: func(ctx context.Context, event db.CreateEventParams, races []db.CreateRaceParams) (db.Event, error) {
+				gotEvent, gotRaces = event, races
+				return db.Event{ID: 5, Slug: event.Slug, Year: event.Year}, nil
+			},
+		}
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.Race, error) {
+				return []db.Race{
+					{
+						ID:                    10,
+						EventID:               4,
+						Name:                  "50K",
+						Slug:                  "50k",
+						RegistrationOpenDate:  ts(2027, time.October, 1, 9),
+						RegistrationCloseDate: ts(2028, time.February, 22, 23),
+						StartsAt:              ts(2028, time.February, 29, 7),
+						MaxCapacity:           300,
+						PriceUnits:            pgtype.Int4{Int32: 6500, Valid: true},
+						Currency:              pgtype.Text{String: "GBP", Valid: true},
+					},
+					{ID: 11, EventID: 4, Name: "Fun Run", Slug: "fun-run", MaxCapacity: 100},
+				}, nil
+			},
+		}
+
+		event, err := NewEventService(eventRepo, raceRepo, nil).DuplicateEvent(context.Background(), 4, 2029)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if event.ID != 5 || event.Year != 2029 {
+			t.Errorf("unexpected event returned: %+v", event)
+		}
+		// The source had no series, so starts one named after itself
+		series := pgtype.Int8{Int64: 4, Valid: true}
+		if gotEvent != (db.CreateEventParams{OrganisationID: 2, Name: "Leap Day Ultra", Slug: "leap-day-ultra", Year: 2029, Timezone: "Europe/London", SeriesID: series}) {
+			t.Errorf("unexpected event params: %+v", gotEvent)
+		}
+		if len(gotRaces) != 2 {
+			t.Fatalf("expected 2 races, got %d", len(gotRaces))
+		}
+
+		race := gotRaces[0]
+		if race.Slug != "50k" || race.MaxCapacity != 300 || race.PriceUnits.Int32 != 6500 || race.Currency.String != "GBP" {
+			t.Errorf("unexpected race params: %+v", race)
+		}
+		if want := ts(2028, time.October, 1, 9); race.RegistrationOpenDate != want {
+			t.Errorf("expected open date %v, got %v", want.Time, race.RegistrationOpenDate.Time)
+		}
+		if want := ts(2029, time.February, 22, 23); race.RegistrationCloseDate != want {
+			t.Errorf("expected close date %v, got %v", want.Time, race.RegistrationCloseDate.Time)
+		}
+		if want := ts(2029, time.February, 28, 7); race.StartsAt != want {
+			t.Errorf("expected leap day start to move to %v, got %v", want.Time, race.StartsAt.Time)
+		}
+		if gotRaces[1].RegistrationOpenDate.Valid || gotRaces[1].StartsAt.Valid {
+			t.Errorf("expected unset dates to stay unset, got %+v", gotRaces[1])
+		}
+	})
+
+	t.Run("keeps local times across the clock change", func(t *testing.T) {
+		london, err := time.LoadLocation("Europe/London")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var gotRaces []db.CreateRaceParams
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: 4, Name: "Spring 10K", Slug: "spring-10k", Year: 2026, Timezone: "Europe/London"}, nil
+			},
+			CreateWithRacesFunc: func(ctx context.Context, event db.CreateEventParams, races []db.CreateRaceParams) (db.Event, error) {
+				gotRaces = races
+				return db.Event{ID: 5}, nil
+			},
+		}
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.Race, error) {
+				return []db.Race{{
+					ID: 10, EventID: 4, Name: "10K", Slug: "10k",
+					// 23:00 GMT on 28 March 2026, the day before the clocks
+					// go forward; in 2027 they go forward on 28 March
+					RegistrationCloseDate: ts(2026, time.March, 28, 23),
+					// 10:00 BST on 29 March 2026
+					StartsAt: pgtype.Timestamptz{Time: time.Date(2026, time.March, 29, 10, 0, 0, 0, london), Valid: true},
+				}}, nil
+			},
+		}
+
+		if _, err := NewEventService(eventRepo, raceRepo, nil).DuplicateEvent(context.Background(), 4, 2027); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(gotRaces) != 1 {
+			t.Fatalf("expected 1 race, got %d", len(gotRaces))
+		}
+		if want := time.Date(2027, time.March, 28, 22, 0, 0, 0, time.UTC); !gotRaces[0].RegistrationCloseDate.Time.Equal(want) {
+			t.Errorf("expected 23:00 BST (%v), got %v", want, gotRaces[0].RegistrationCloseDate.Time)
+		}
+		if want := time.Date(2027, time.March, 29, 9, 0, 0, 0, time.UTC); !gotRaces[0].StartsAt.Time.Equal(want) {
+			t.Errorf("expected 10:00 BST (%v), got %v", want, gotRaces[0].StartsAt.Time)
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for years too far ahead", func(t *testing.T) {
+		svc := &eventService{
+			eventRepo: &repositorymocks.EventRepositoryMock{},
+			raceRepo:  &repositorymocks.RaceRepositoryMock{},
+			clock:     &MockClock{CurrentTime: time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)},
+		}
+
+		if _, err := svc.DuplicateEvent(context.Background(), 4, 2032); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrConflict when the year already exists", func(t *testing.T) {
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return source, nil
+			},
+			CreateWithRacesFunc: func(ctx context.Context, event db.CreateEventParams, races []db.CreateRaceParams) (db.Event, error) {
+				return db.Event{}, repository.ErrConflict
+			},
+		}
+
+		_, err := NewEventService(eventRepo, &repositorymocks.RaceRepositoryMock{}, nil).DuplicateEvent(context.Background(), 4, 2029)
+
+		if !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("keeps the source's series", func(t *testing.T) {
+		series := pgtype.Int8{Int64: 1, Valid: true}
+		var gotEvent db.CreateEventParams
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				e := source
+				e.SeriesID = series
+				return e, nil
+			},
+			CreateWithRacesFunc: func(ctx context.Context, event db.CreateEventParams, races []db.CreateRaceParams) (db.Event, error) {
+				gotEvent = event
+				return db.Event{ID: 5}, nil
+			},
+		}
+
+		if _, err := NewEventService(eventRepo, &repositorymocks.RaceRepositoryMock{}, nil).DuplicateEvent(context.Background(), 4, 2029); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotEvent.SeriesID != series {
+			t.Errorf("expected series %v, got %v", series, gotEvent.SeriesID)
+		}
+	})
+
+	t.Run("returns ErrConflict for the source event's own year", func(t *testing.T) {
+		called := false
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return source, nil
+			},
+			CreateWithRacesFunc: func(ctx context.Context, event db.CreateEventParams, races []db.CreateRaceParams) (db.Event, error) {
+				called = true
+				return db.Event{}, nil
+			},
+		}
+
+		_, err := NewEventService(eventRepo, &repositorymocks.RaceRepositoryMock{}, nil).DuplicateEvent(context.Background(), 4, 2028)
+
+		if !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+		if called {
+			t.Error("expected repository not to be called")
+		}
+	})
+
+	t.Run("returns ErrNotFound for missing event", func(t *testing.T) {
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{}, repository.ErrNotFound
+			},
+		}
+
+		_, err := NewEventService(eventRepo, &repositorymocks.RaceRepositoryMock{}, nil).DuplicateEvent(context.Background(), 99, 2029)
+
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for year before minimum", func(t *testing.T) {
+		_, err := NewEventService(&repositorymocks.EventRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, nil).DuplicateEvent(context.Background(), 4, 2020)
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestEventService_SetEventSeries(t *testing.T) {
+	event := db.Event{ID: 4, OrganisationID: 2, Name: "Snowdonia Marathon", Year: 2026}
+
+	t.Run("joins the series of another of the organisation's events", func(t *testing.T) {
+		var gotEvent, gotSeriesEvent int64
+		repo := &repositorymocks.EventRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, OrganisationID: 2, Year: 2025}, nil
+			},
+			JoinSeriesFunc: func(ctx context.Context, eventID, seriesEventID int64) (db.Event, error) {
+				gotEvent, gotSeriesEvent = eventID, seriesEventID
+				return db.Event{}, nil
+			},
+		}
+
+		if err := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil).SetEventSeries(context.Background(), event, 3); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotEvent != 4 || gotSeriesEvent != 3 {
+			t.Errorf("expected event 4 to join event 3's series, got %d and %d", gotEvent, gotSeriesEvent)
+		}
+	})
+
+	t.Run("leaves the series for zero", func(t *testing.T) {
+		var left int64
+		repo := &repositorymocks.EventRepositoryMock{
+			LeaveSeriesFunc: func(ctx context.Context, eventID int64) error {
+				left = eventID
+				return nil
+			},
+		}
+
+		if err := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil).SetEventSeries(context.Background(), event, 0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if left != 4 {
+			t.Errorf("expected event 4 to leave its series, got %d", left)
+		}
+	})
+
+	for name, other := range map[string]func(ctx context.Context, id int64) (db.Event, error){
+		"another organisation's events": func(ctx context.Context, id int64) (db.Event, error) {
+			return db.Event{ID: id, OrganisationID: 9}, nil
+		},
+		"missing events": func(ctx context.Context, id int64) (db.Event, error) {
+			return db.Event{}, repository.ErrNotFound
+		},
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			repo := &repositorymocks.EventRepositoryMock{GetByIDFunc: other}
+
+			err := NewEventService(repo, &repositorymocks.RaceRepositoryMock{}, nil).SetEventSeries(context.Background(), event, 3)
+
+			var fe FieldErrors
+			if !errors.As(err, &fe) || fe["series_event_id"] == "" {
+				t.Errorf("expected a series_event_id field error, got %v", err)
+			}
+			if len(repo.JoinSeriesCalls()) != 0 {
+				t.Error("expected the event to stay where it was")
+			}
+		})
+	}
+
+	t.Run("rejects the event itself", func(t *testing.T) {
+		err := NewEventService(&repositorymocks.EventRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, nil).SetEventSeries(context.Background(), event, 4)
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestEventService_PublishEvent(t *testing.T) {
+	opens := pgtype.Timestamptz{Time: time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC), Valid: true}
+	closes := pgtype.Timestamptz{Time: time.Date(2026, time.May, 1, 23, 0, 0, 0, time.UTC), Valid: true}
+	ready := db.Race{ID: 10, EventID: 4, Name: "10K", RegistrationOpenDate: opens, RegistrationCloseDate: closes}
+
+	newService := func(races []db.Race, status *db.EventStatus) EventService {
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			SetStatusFunc: func(ctx context.Context, id int64, s db.EventStatus) error {
+				*status = s
+				return nil
+			},
+		}
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.Race, error) {
+				return races, nil
+			},
+		}
+		return NewEventService(eventRepo, raceRepo, nil)
+	}
+
+	t.Run("publishes an event whose races take entries", func(t *testing.T) {
+		var status db.EventStatus
+		if err := newService([]db.Race{ready}, &status).PublishEvent(context.Background(), 4); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if status != db.EventStatusPublished {
+			t.Errorf("expected the event to be published, got %q", status)
+		}
+	})
+
+	t.Run("reports another event published at the same URL", func(t *testing.T) {
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			SetStatusFunc: func(ctx context.Context, id int64, s db.EventStatus) error {
+				return repository.ErrConflict
+			},
+		}
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.Race, error) {
+				return []db.Race{ready}, nil
+			},
+		}
+		err := NewEventService(eventRepo, raceRepo, nil).PublishEvent(context.Background(), 4)
+		if !errors.Is(err, ErrEventURLTaken) {
+			t.Errorf("expected ErrEventURLTaken, got %v", err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		races   func() []db.Race
+		wantErr error
+	}{
+		{
+			name:    "rejects events without races",
+			races:   func() []db.Race { return nil },
+			wantErr: ErrEventHasNoRaces,
+		},
+		{
+			name: "rejects races without an opening date",
+			races: func() []db.Race {
+				race := ready
+				race.RegistrationOpenDate = pgtype.Timestamptz{}
+				return []db.Race{race}
+			},
+			wantErr: ErrNoRegistrationWindow,
+		},
+		{
+			name: "rejects races without a closing date",
+			races: func() []db.Race {
+				race := ready
+				race.RegistrationCloseDate = pgtype.Timestamptz{}
+				return []db.Race{ready, race}
+			},
+			wantErr: ErrNoRegistrationWindow,
+		},
+		{
+			name: "rejects races that close before they open",
+			races: func() []db.Race {
+				race := ready
+				race.RegistrationOpenDate, race.RegistrationCloseDate = closes, opens
+				return []db.Race{race}
+			},
+			wantErr: ErrNoRegistrationWindow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status db.EventStatus
+			err := newService(tt.races(), &status).PublishEvent(context.Background(), 4)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if status != "" {
+				t.Errorf("expected the status to be left alone, got %q", status)
+			}
+		})
+	}
+}
+
+func TestEventService_ArchiveEvent(t *testing.T) {
+	var gotID int64
+	var status db.EventStatus
+	svc := NewEventService(&repositorymocks.EventRepositoryMock{
+		SetStatusFunc: func(ctx context.Context, id int64, s db.EventStatus) error {
+			gotID, status = id, s
+			return nil
+		},
+	}, &repositorymocks.RaceRepositoryMock{}, nil)
+
+	if err := svc.ArchiveEvent(context.Background(), 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotID != 4 || status != db.EventStatusArchived {
+		t.Errorf("expected event 4 archived, got event %d %q", gotID, status)
+	}
+
+	if err := svc.ArchiveEvent(context.Background(), 0); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an invalid id, got %v", err)
+	}
+}
+
+func TestShiftYears(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		in    time.Time
+		years int
+		want  time.Time
+	}{
+		{name: "keeps month and day", in: time.Date(2026, time.June, 14, 9, 30, 0, 0, time.UTC), years: 1, want: time.Date(2027, time.June, 14, 9, 30, 0, 0, time.UTC)},
+		{name: "clamps leap day into a common year", in: time.Date(2028, time.February, 29, 8, 0, 0, 0, time.UTC), years: 1, want: time.Date(2029, time.February, 28, 8, 0, 0, 0, time.UTC)},
+		{name: "keeps leap day into a leap year", in: time.Date(2028, time.February, 29, 8, 0, 0, 0, time.UTC), years: 4, want: time.Date(2032, time.February, 29, 8, 0, 0, 0, time.UTC)},
+		{name: "moves backwards", in: time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC), years: -1, want: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "keeps local wall clock time", in: time.Date(2026, time.March, 29, 10, 0, 0, 0, london), years: 1, want: time.Date(2027, time.March, 29, 10, 0, 0, 0, london)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shiftYears(tt.in, tt.years); !got.Equal(tt.want) {
+				t.Errorf("shiftYears(%v, %d) = %v, want %v", tt.in, tt.years, got, tt.want)
+			}
+		})
+	}
 }
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"firecrest/db"
+	"firecrest/internal/bus"
+	"firecrest/internal/repository"
+)
+
+// MaxBibLength is the longest race number an imported entrant may have.
+const MaxBibLength = 20
+
+// ImportRowStatus is the outcome of one row of an entrant import.
+type ImportRowStatus string
+
+// Import row outcomes
+const (
+	ImportRowCreated          ImportRowStatus = "created"
+	ImportRowSkippedDuplicate ImportRowStatus = "skipped-duplicate"
+	ImportRowError            ImportRowStatus = "error"
+)
+
+// ImportRow reports what happened to one row of an entrant import.
+type ImportRow struct {
+	// Line is the line of the file the row starts on, counting the header.
+	Line    int
+	Email   string
+	Status  ImportRowStatus
+	Message string
+}
+
+// ImportReport describes the outcome of an entrant import. An import is all
+// or nothing: when any row has an error nothing is imported, and Rows holds
+// only the rows that need fixing.
+type ImportReport struct {
+	Imported bool
+	Rows     []ImportRow
+}
+
+// Count returns the number of rows with the given status.
+func (r ImportReport) Count(status ImportRowStatus) int {
+	n := 0
+	for _, row := range r.Rows {
+		if row.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// importColumns maps normalised header names to the fields they hold.
+var importColumns = map[string]string{
+	"email":        "email",
+	"emailaddress": "email",
+	"firstname":    "first_name",
+	"forename":     "first_name",
+	"lastname":     "last_name",
+	"surname":      "last_name",
+	"bib":          "bib",
+	"bibnumber":    "bib",
+}
+
+// importRecord is a row of an import that passed validation.
+type importRecord struct {
+	line    int
+	entrant repository.ImportedEntrant
+	// duplicateOf is the line of an earlier row with the same email
+	// address, or zero.
+	duplicateOf int
+}
+
+func (s *registrationService) ImportEntrants(ctx context.Context, race db.Race, file io.Reader) (ImportReport, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.ImportEntrants")
+	defer span.End()
+
+	records, report, err := s.readImport(file)
+	if err != nil || len(report.Rows) > 0 {
+		return report, err
+	}
+
+	var entrants []repository.ImportedEntrant
+	for _, rec := range records {
+		if rec.duplicateOf == 0 {
+			entrants = append(entrants, rec.entrant)
+		}
+	}
+
+	created, err := s.registrationRepo.ImportEntrants(ctx, race.ID, entrants)
+	if err != nil {
+		if errors.Is(err, repository.ErrCapacityExceeded) {
+			return ImportReport{}, fmt.Errorf("%w: %s has room for %d entrants", ErrRaceFull, race.Name, race.MaxCapacity)
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return ImportReport{}, fmt.Errorf("%w: a bib in the file is already taken in %s", ErrInvalidInput, race.Name)
+		}
+		return ImportReport{}, fmt.Errorf("failed to import entrants: %w", err)
+	}
+	bus.Publish(ctx, s.events, bus.RaceUpdated, bus.RaceChange{RaceID: race.ID, EventID: race.EventID})
+
+	report = ImportReport{Imported: true, Rows: make([]ImportRow, 0, len(records))}
+	next := 0
+	for _, rec := range records {
+		row := ImportRow{Line: rec.line, Email: rec.entrant.Email}
+		switch {
+		case rec.duplicateOf != 0:
+			row.Status = ImportRowSkippedDuplicate
+			row.Message = "same email address as line " + strconv.Itoa(rec.duplicateOf)
+		case created[next]:
+			row.Status = ImportRowCreated
+			next++
+		default:
+			row.Status = ImportRowSkippedDuplicate
+			row.Message = "already registered for this race"
+			next++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	return report, nil
+}
+
+// readImport parses and validates an import file. It returns every valid
+// row, or a report of the rows with errors.
+func (s *registrationService) readImport(file io.Reader) ([]importRecord, ImportReport, error) {
+	r := newCSVReader(file)
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ImportReport{}, fmt.Errorf("%w: the file is empty", ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, ImportReport{}, fmt.Errorf("%w: could not read the header row: %v", ErrInvalidInput, err)
+	}
+	columns, err := importHeader(header)
+	if err != nil {
+		return nil, ImportReport{}, err
+	}
+
+	var (
+		records []importRecord
+		report  ImportReport
+		emails  = make(map[string]int)
+		bibs    = make(map[string]int)
+		rows    int
+	)
+	for {
+		fields, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, ImportReport{}, fmt.Errorf("failed to read import: %w", err)
+		}
+		if err == nil && blankRecord(fields) {
+			continue
+		}
+		if rows++; rows > s.importMaxRows {
+			return nil, ImportReport{}, fmt.Errorf("%w: the file has more than %d entrants", ErrInvalidInput, s.importMaxRows)
+		}
+		if parseErr != nil {
+			report.Rows = append(report.Rows, ImportRow{Line: parseErr.StartLine, Status: ImportRowError, Message: parseErr.Err.Error()})
+			continue
+		}
+
+		line, _ := r.FieldPos(0)
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+		rec := importRecord{line: line, entrant: repository.ImportedEntrant{
+			Email:     NormalizeEmail(field("email")),
+			FirstName: field("first_name"),
+			LastName:  field("last_name"),
+			Bib:       field("bib"),
+		}}
+
+		if first, ok := emails[rec.entrant.Email]; ok {
+			rec.duplicateOf = first
+			records = append(records, rec)
+			continue
+		}
+		msg := validateImportedEntrant(rec.entrant)
+		if first, ok := bibs[rec.entrant.Bib]; ok && msg == "" {
+			msg = "bib " + rec.entrant.Bib + " is also used on line " + strconv.Itoa(first)
+		}
+		if msg != "" {
+			report.Rows = append(report.Rows, ImportRow{Line: line, Email: rec.entrant.Email, Status: ImportRowError, Message: msg})
+			continue
+		}
+
+		emails[rec.entrant.Email] = line
+		if rec.entrant.Bib != "" {
+			bibs[rec.entrant.Bib] = line
+		}
+		records = append(records, rec)
+	}
+
+	if rows == 0 {
+		return nil, ImportReport{}, fmt.Errorf("%w: the file has no entrants", ErrInvalidInput)
+	}
+	return records, report, nil
+}
+
+// newCSVReader reads a spreadsheet export, which may have ragged rows.
+func newCSVReader(file io.Reader) *csv.Reader {
+	// Spreadsheet software often starts CSV exports with a byte order mark
+	br := bufio.NewReader(file)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\ufeff" {
+		br.Discard(3) //nolint:errcheck // the bytes were just peeked
+	}
+
+	r := csv.NewReader(br)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	return r
+}
+
+// headerKey normalises a header name so "First Name", "first_name" and
+// "first-name" are all "firstname".
+func headerKey(name string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// importHeader maps each field to its column index.
+func importHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if field, ok := importColumns[headerKey(name)]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+
+	for _, required := range []string{"email", "first_name", "last_name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: the header row has no %s column", ErrInvalidInput, strings.ReplaceAll(required, "_", " "))
+		}
+	}
+	return columns, nil
+}
+
+// blankRecord reports whether every field is empty, as in the trailing rows
+// spreadsheets export.
+func blankRecord(fields []string) bool {
+	for _, f := range fields {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// validateImportedEntrant returns why an entrant cannot be imported, or ""
+func validateImportedEntrant(e repository.ImportedEntrant) string {
+	if msg := emailProblem(e.Email); msg != "" {
+		return msg
+	}
+	switch {
+	case e.FirstName == "":
+		return "first name is required"
+	case e.LastName == "":
+		return "last name is required"
+	case len(e.Bib) > MaxBibLength:
+		return fmt.Sprintf("bib must be at most %d characters", MaxBibLength)
+	}
+	return ""
+}
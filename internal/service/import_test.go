@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestRegistrationService_ImportEntrants(t *testing.T) {
+	race := db.Race{ID: 20, EventID: 30, Name: "10K", MaxCapacity: 100}
+
+	newService := func(repo *repositorymocks.RegistrationRepositoryMock, maxRows int) (*registrationService, *recordingCounter) {
+		counter := &recordingCounter{}
+		svc := NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, &mockPaymentService{}, &mockDiscountService{}, counterBus(counter), &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "", 0, 0, 0, 0, maxRows, BibRange{}).(*registrationService)
+		return svc, counter
+	}
+
+	t.Run("imports the fixture file", func(t *testing.T) {
+		file, err := os.Open("testdata/entrants.csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		var got []repository.ImportedEntrant
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			ImportEntrantsFunc: func(ctx context.Context, raceID int64, entrants []repository.ImportedEntrant) ([]bool, error) {
+				if raceID != race.ID {
+					t.Errorf("expected race %d, got %d", race.ID, raceID)
+				}
+				got = entrants
+				// tom@example.com is already registered
+				return []bool{true, false, true}, nil
+			},
+		}
+		svc, counter := newService(repo, 100)
+
+		report, err := svc.ImportEntrants(context.Background(), race, file)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []repository.ImportedEntrant{
+			{Email: "jane@example.com", FirstName: "Jane", LastName: "Smith, MBE", Bib: "101"},
+			{Email: "tom@example.com", FirstName: "Tom", LastName: "Jones", Bib: "102"},
+			{Email: "priya@example.com", FirstName: "Priya", LastName: `Patel, "PP"`},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %d entrants, got %d: %+v", len(want), len(got), got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("entrant %d = %+v, want %+v", i, got[i], want[i])
+			}
+		}
+
+		if !report.Imported {
+			t.Error("expected the import to be committed")
+		}
+		wantRows := []ImportRow{
+			{Line: 2, Email: "jane@example.com", Status: ImportRowCreated},
+			{Line: 3, Email: "tom@example.com", Status: ImportRowSkippedDuplicate, Message: "already registered for this race"},
+			{Line: 4, Email: "jane@example.com", Status: ImportRowSkippedDuplicate, Message: "same email address as line 2"},
+			{Line: 5, Email: "priya@example.com", Status: ImportRowCreated},
+		}
+		if len(report.Rows) != len(wantRows) {
+			t.Fatalf("expected %d report rows, got %+v", len(wantRows), report.Rows)
+		}
+		for i := range wantRows {
+			if report.Rows[i] != wantRows[i] {
+				t.Errorf("row %d = %+v, want %+v", i, report.Rows[i], wantRows[i])
+			}
+		}
+		if report.Count(ImportRowCreated) != 2 || report.Count(ImportRowSkippedDuplicate) != 2 {
+			t.Errorf("unexpected counts in %+v", report.Rows)
+		}
+		if len(counter.invalidated) != 1 || counter.invalidated[0] != race.EventID {
+			t.Errorf("expected event %d counts to be invalidated, got %v", race.EventID, counter.invalidated)
+		}
+	})
+
+	t.Run("reports invalid rows and imports nothing", func(t *testing.T) {
+		csv := "email,first_name,last_name,bib\n" +
+			"jane@example.com,Jane,Smith,7\n" +
+			"not-an-email,Tom,Jones,\n" +
+			"priya@example.com,Priya,,\n" +
+			"sam@example.com,Sam,Lee,7\n" +
+			"\"ola@example.com,Ola,\"Nordmann\",\n"
+
+		called := false
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			ImportEntrantsFunc: func(ctx context.Context, raceID int64, entrants []repository.ImportedEntrant) ([]bool, error) {
+				called = true
+				return nil, nil
+			},
+		}
+		svc, _ := newService(repo, 100)
+
+		report, err := svc.ImportEntrants(context.Background(), race, strings.NewReader(csv))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if called {
+			t.Error("expected nothing to be written")
+		}
+		if report.Imported {
+			t.Error("expected the import not to be committed")
+		}
+
+		wantLines := map[int]string{
+			3: "invalid email format",
+			4: "last name is required",
+			5: "bib 7 is also used on line 2",
+		}
+		for _, row := range report.Rows {
+			if row.Status != ImportRowError {
+				t.Errorf("expected only error rows, got %+v", row)
+			}
+			if msg, ok := wantLines[row.Line]; ok && row.Message != msg {
+				t.Errorf("line %d message = %q, want %q", row.Line, row.Message, msg)
+			}
+		}
+		if len(report.Rows) != 4 {
+			t.Errorf("expected 4 error rows, got %+v", report.Rows)
+		}
+		if last := report.Rows[len(report.Rows)-1]; last.Line != 6 {
+			t.Errorf("expected malformed quoting to be reported on line 6, got %+v", last)
+		}
+	})
+
+	t.Run("rejects files over the row limit", func(t *testing.T) {
+		csv := "email,first name,last name\n" +
+			"a@example.com,A,One\n" +
+			"b@example.com,B,Two\n" +
+			"c@example.com,C,Three\n"
+		svc, _ := newService(&repositorymocks.RegistrationRepositoryMock{}, 2)
+
+		_, err := svc.ImportEntrants(context.Background(), race, strings.NewReader(csv))
+		if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "more than 2") {
+			t.Errorf("expected row limit error, got %v", err)
+		}
+	})
+
+	t.Run("rejects files missing a required column", func(t *testing.T) {
+		svc, _ := newService(&repositorymocks.RegistrationRepositoryMock{}, 100)
+
+		_, err := svc.ImportEntrants(context.Background(), race, strings.NewReader("email,name\njane@example.com,Jane Smith\n"))
+		if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "first name") {
+			t.Errorf("expected missing column error, got %v", err)
+		}
+	})
+
+	t.Run("rejects files without entrants", func(t *testing.T) {
+		svc, _ := newService(&repositorymocks.RegistrationRepositoryMock{}, 100)
+
+		for _, csv := range []string{"", "email,first name,last name\n"} {
+			if _, err := svc.ImportEntrants(context.Background(), race, strings.NewReader(csv)); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("ImportEntrants(%q) = %v, want ErrInvalidInput", csv, err)
+			}
+		}
+	})
+
+	t.Run("reports imports that would exceed capacity", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			ImportEntrantsFunc: func(ctx context.Context, raceID int64, entrants []repository.ImportedEntrant) ([]bool, error) {
+				return nil, repository.ErrCapacityExceeded
+			},
+		}
+		svc, counter := newService(repo, 100)
+
+		_, err := svc.ImportEntrants(context.Background(), race, strings.NewReader("email,first name,last name\njane@example.com,Jane,Smith\n"))
+		if !errors.Is(err, ErrRaceFull) {
+			t.Errorf("expected ErrRaceFull, got %v", err)
+		}
+		if len(counter.invalidated) != 0 {
+			t.Error("expected counts not to be invalidated when nothing was imported")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// progressiveDelays holds back the responses to the first failed sign-ins
+// in a row, by attempt number counting from one.
+var progressiveDelays = []time.Duration{0, 0, 100 * time.Millisecond, 500 * time.Millisecond}
+
+// LockoutPolicy decides how an account answers repeated failed sign-ins:
+// locked for LockoutDuration once MaxAttempts have failed in a row and,
+// with Progressive set, slowed down before then.
+type LockoutPolicy struct {
+	MaxAttempts     int
+	LockoutDuration time.Duration
+	// Progressive delays the third and fourth failed attempts by 100ms and
+	// 500ms, and any later ones short of the lockout by 500ms, so guessing
+	// slows down before it locks a user out of their own account.
+	Progressive bool
+}
+
+// DefaultLockoutPolicy locks an account for AccountLockoutDuration after
+// MaxLoginAttempts failed sign-ins, without delays.
+var DefaultLockoutPolicy = LockoutPolicy{
+	MaxAttempts:     MaxLoginAttempts,
+	LockoutDuration: AccountLockoutDuration,
+}
+
+// Locks reports whether the failed attempt, counting from one, locks the
+// account.
+func (p LockoutPolicy) Locks(attempt int) bool {
+	return attempt >= p.MaxAttempts
+}
+
+// Delay returns how long to hold back the response to the failed attempt,
+// counting from one. Attempts that lock the account are not delayed; the
+// lock does their work.
+func (p LockoutPolicy) Delay(attempt int) time.Duration {
+	if !p.Progressive || attempt < 1 || p.Locks(attempt) {
+		return 0
+	}
+	return progressiveDelays[min(attempt, len(progressiveDelays))-1]
+}
+
+// Sleeper waits, so tests can check delays without waiting for them.
+type Sleeper interface {
+	// Sleep waits for d, or until ctx is done.
+	Sleep(ctx context.Context, d time.Duration)
+}
+
+// Sleep waits for d using a timer, or until ctx is done.
+func (RealClock) Sleep(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
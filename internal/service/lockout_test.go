@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDefaultLockoutPolicy(t *testing.T) {
+	p := DefaultLockoutPolicy
+	if p.MaxAttempts != 5 || p.LockoutDuration != 15*time.Minute || p.Progressive {
+		t.Errorf("expected a 15 minute lock after 5 attempts without delays, got %+v", p)
+	}
+	for attempt := 1; attempt <= 6; attempt++ {
+		if d := p.Delay(attempt); d != 0 {
+			t.Errorf("expected attempt %d not to be delayed, got %v", attempt, d)
+		}
+		if got, want := p.Locks(attempt), attempt >= 5; got != want {
+			t.Errorf("expected attempt %d to lock: %v, got %v", attempt, want, got)
+		}
+	}
+}
+
+func TestLockoutPolicy_Delay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy LockoutPolicy
+		want   []time.Duration
+	}{
+		{
+			name:   "slows the third and fourth attempts",
+			policy: LockoutPolicy{MaxAttempts: 5, LockoutDuration: time.Minute, Progressive: true},
+			want:   []time.Duration{0, 0, 100 * time.Millisecond, 500 * time.Millisecond, 0},
+		},
+		{
+			name:   "keeps the longest delay until a later lock",
+			policy: LockoutPolicy{MaxAttempts: 7, LockoutDuration: time.Minute, Progressive: true},
+			want:   []time.Duration{0, 0, 100 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond, 0},
+		},
+		{
+			name:   "leaves delays to the lock when it comes first",
+			policy: LockoutPolicy{MaxAttempts: 3, LockoutDuration: time.Minute, Progressive: true},
+			want:   []time.Duration{0, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.policy.Delay(i + 1); got != want {
+					t.Errorf("attempt %d: expected %v, got %v", i+1, want, got)
+				}
+			}
+		})
+	}
+}
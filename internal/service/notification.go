@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/mail"
+)
+
+// notifyOrganisers emails the members of the organisation running the race
+// who asked to hear about every registration.
+func (s *registrationService) notifyOrganisers(ctx context.Context, reg db.GetRegistrationForConfirmationRow) error {
+	recipients, err := s.orgRepo.ListImmediateNotificationRecipients(ctx, reg.OrganisationID)
+	if err != nil {
+		return fmt.Errorf("failed to list organisers to notify: %w", err)
+	}
+
+	entrant := strings.TrimSpace(reg.FirstName + " " + reg.LastName)
+	if entrant == "" {
+		entrant = reg.Email
+	}
+	for _, recipient := range recipients {
+		msg, err := mail.NewRegistrationMessage(recipient.Email, mail.NewRegistrationData{
+			FirstName:   recipient.FirstName,
+			EntrantName: entrant,
+			RaceName:    reg.RaceName,
+			EventName:   reg.EventName,
+			EntrantsURL: fmt.Sprintf("%s/admin/races/%d/entrants", s.baseURL, reg.RaceID),
+			SettingsURL: s.notificationSettingsURL(reg.OrganisationID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build new registration email: %w", err)
+		}
+		_ = s.mailer.Send(ctx, msg)
+	}
+	return nil
+}
+
+func (s *registrationService) SendRegistrationDigests(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.SendRegistrationDigests")
+	defer span.End()
+
+	now := s.clock.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := today.AddDate(0, 0, -1)
+
+	// Claiming marks the digest for day sent before anything is sent, as
+	// race reminders do, so a repeated run emails no one twice
+	members, err := s.orgRepo.ClaimRegistrationDigests(ctx, day)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim registration digests: %w", err)
+	}
+
+	stats := make(map[int64][]db.GetRegistrationDigestStatsRow)
+	sent := 0
+	var errs []error
+	for _, member := range members {
+		rows, ok := stats[member.OrganisationID]
+		if !ok {
+			rows, err = s.registrationRepo.DigestStats(ctx, member.OrganisationID, day, today)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to load digest for organisation %d: %w", member.OrganisationID, err))
+				continue
+			}
+			stats[member.OrganisationID] = rows
+		}
+		// A day without registrations has nothing to report
+		if len(rows) == 0 {
+			continue
+		}
+
+		data := registrationDigest(day, rows)
+		data.FirstName = member.FirstName
+		data.OrganisationName = member.OrganisationName
+		data.SettingsURL = s.notificationSettingsURL(member.OrganisationID)
+		msg, err := mail.RegistrationDigestMessage(member.Email, data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to build registration digest for organisation %d: %w", member.OrganisationID, err))
+			continue
+		}
+		_ = s.mailer.Send(ctx, msg)
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// notificationSettingsURL links to the page where members choose how they
+// hear about the organisation's registrations.
+func (s *registrationService) notificationSettingsURL(organisationID int64) string {
+	return fmt.Sprintf("%s/admin/dashboard?organisation=%d", s.baseURL, organisationID)
+}
+
+// registrationDigest summarises the registrations made on day for the
+// digest email, from stats with a row for each event and currency. Events
+// are listed in the order of their first row, and revenue in each currency
+// is totalled separately. The recipient and links are left for the caller.
+func registrationDigest(day time.Time, stats []db.GetRegistrationDigestStatsRow) mail.RegistrationDigestData {
+	data := mail.RegistrationDigestData{Date: day.Format("Monday 2 January 2006")}
+
+	var events []int64
+	counts := make(map[int64]int)
+	names := make(map[int64]string)
+	revenue := make(map[int64]map[string]int64)
+	totals := make(map[string]int64)
+	for _, row := range stats {
+		if _, ok := names[row.EventID]; !ok {
+			events = append(events, row.EventID)
+			names[row.EventID] = row.EventName + " " + strconv.Itoa(int(row.Year))
+			revenue[row.EventID] = make(map[string]int64)
+		}
+		counts[row.EventID] += int(row.Registrations)
+		data.Registrations += int(row.Registrations)
+		if row.RevenueUnits > 0 {
+			revenue[row.EventID][row.Currency] += row.RevenueUnits
+			totals[row.Currency] += row.RevenueUnits
+		}
+	}
+
+	for _, id := range events {
+		data.Events = append(data.Events, mail.RegistrationDigestEvent{
+			Name:          names[id],
+			Registrations: counts[id],
+			Revenue:       formatRevenue(revenue[id]),
+		})
+	}
+	data.Revenue = formatRevenue(totals)
+	return data
+}
+
+// formatRevenue formats amounts in minor units by currency, such as
+// "£120.00 + €40.00", in currency order, or "" for none.
+func formatRevenue(amounts map[string]int64) string {
+	parts := make([]string, 0, len(amounts))
+	for _, currency := range slices.Sorted(maps.Keys(amounts)) {
+		parts = append(parts, formatMoney(amounts[currency], currency))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// formatMoney formats an amount in minor units for emails as the admin
+// pages do: with the currency's symbol where it has a common one, and
+// otherwise its code after the amount.
+func formatMoney(units int64, currency string) string {
+	decimals := 2
+	switch currency {
+	case "ISK", "JPY", "KRW":
+		decimals = 0
+	case "BHD", "JOD", "KWD", "OMR", "TND":
+		decimals = 3
+	}
+	scale := int64(1)
+	for range decimals {
+		scale *= 10
+	}
+
+	whole := strconv.FormatInt(units/scale, 10)
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	amount := whole
+	if decimals > 0 {
+		amount += fmt.Sprintf(".%0*d", decimals, units%scale)
+	}
+
+	switch currency {
+	case "GBP":
+		return "£" + amount
+	case "EUR":
+		return "€" + amount
+	case "USD":
+		return "$" + amount
+	}
+	return amount + " " + currency
+}
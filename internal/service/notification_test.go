@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+)
+
+func TestRegistrationService_NotifyOrganisers(t *testing.T) {
+	confirmation := db.GetRegistrationForConfirmationRow{
+		ID:             100,
+		RaceID:         3,
+		RaceName:       "10K",
+		EventName:      "Riverside Run",
+		OrganisationID: 1,
+		Email:          "sam@example.com",
+		FirstName:      "Sam",
+		LastName:       "Runner",
+	}
+	registrations := &repositorymocks.RegistrationRepositoryMock{
+		GetForConfirmationFunc: func(ctx context.Context, id int64) (db.GetRegistrationForConfirmationRow, error) {
+			return confirmation, nil
+		},
+	}
+
+	t.Run("emails members who want every registration", func(t *testing.T) {
+		var listed int64
+		orgs := &repositorymocks.OrganisationRepositoryMock{
+			ListImmediateNotificationRecipientsFunc: func(ctx context.Context, organisationID int64) ([]db.ListImmediateNotificationRecipientsRow, error) {
+				listed = organisationID
+				return []db.ListImmediateNotificationRecipientsRow{{Email: "ada@example.com", FirstName: "Ada"}}, nil
+			},
+		}
+		mailer := &mockMailer{}
+		svc := &registrationService{registrationRepo: registrations, orgRepo: orgs, mailer: mailer, webhookRepo: &repositorymocks.WebhookRepositoryMock{}, baseURL: "https://firecrest.example", clock: RealClock{}}
+
+		if err := svc.sendConfirmationEmail(context.Background(), confirmation.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if listed != confirmation.OrganisationID {
+			t.Errorf("expected the members of organisation %d, got %d", confirmation.OrganisationID, listed)
+		}
+		if len(mailer.sent) != 2 {
+			t.Fatalf("expected the confirmation and one notification, got %d emails", len(mailer.sent))
+		}
+		msg := mailer.sent[1]
+		if msg.To != "ada@example.com" || msg.Subject != "New entry for 10K: Sam Runner" {
+			t.Errorf("unexpected email to %q: %q", msg.To, msg.Subject)
+		}
+		if !strings.Contains(msg.Text, "https://firecrest.example/admin/races/3/entrants") {
+			t.Errorf("expected a link to the race's entrants, got:\n%s", msg.Text)
+		}
+	})
+
+	t.Run("sends only the confirmation when no one wants every registration", func(t *testing.T) {
+		mailer := &mockMailer{}
+		svc := &registrationService{registrationRepo: registrations, orgRepo: &repositorymocks.OrganisationRepositoryMock{}, mailer: mailer, webhookRepo: &repositorymocks.WebhookRepositoryMock{}, clock: RealClock{}}
+
+		if err := svc.sendConfirmationEmail(context.Background(), confirmation.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mailer.sent) != 1 || mailer.sent[0].To != "sam@example.com" {
+			t.Errorf("expected only the entrant's confirmation, got %d emails", len(mailer.sent))
+		}
+	})
+}
+
+func TestRegistrationService_SendRegistrationDigests(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 30, 0, 0, time.UTC)
+	yesterday := time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)
+	stats := []db.GetRegistrationDigestStatsRow{
+		{EventID: 10, EventName: "Riverside Run", Year: 2026, Currency: "GBP", Registrations: 3, RevenueUnits: 6000},
+	}
+
+	newService := func(orgs *repositorymocks.OrganisationRepositoryMock, registrations *repositorymocks.RegistrationRepositoryMock, mailer *mockMailer) *registrationService {
+		return &registrationService{
+			registrationRepo: registrations,
+			orgRepo:          orgs,
+			mailer:           mailer,
+			baseURL:          "https://firecrest.example",
+			clock:            &MockClock{CurrentTime: now},
+		}
+	}
+
+	t.Run("emails each claimed member a digest of the previous day", func(t *testing.T) {
+		var claimedDay, from, to time.Time
+		loads := 0
+		orgs := &repositorymocks.OrganisationRepositoryMock{
+			ClaimRegistrationDigestsFunc: func(ctx context.Context, day time.Time) ([]db.ClaimRegistrationDigestsRow, error) {
+				claimedDay = day
+				return []db.ClaimRegistrationDigestsRow{
+					{OrganisationID: 1, OrganisationName: "Riverside Harriers", Email: "ada@example.com", FirstName: "Ada"},
+					{OrganisationID: 1, OrganisationName: "Riverside Harriers", Email: "bo@example.com", FirstName: "Bo"},
+				}, nil
+			},
+		}
+		registrations := &repositorymocks.RegistrationRepositoryMock{
+			DigestStatsFunc: func(ctx context.Context, organisationID int64, start, end time.Time) ([]db.GetRegistrationDigestStatsRow, error) {
+				loads++
+				from, to = start, end
+				return stats, nil
+			},
+		}
+		mailer := &mockMailer{}
+
+		sent, err := newService(orgs, registrations, mailer).SendRegistrationDigests(context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sent != 2 || len(mailer.sent) != 2 {
+			t.Fatalf("expected two digests, got %d reported and %d sent", sent, len(mailer.sent))
+		}
+		if !claimedDay.Equal(yesterday) {
+			t.Errorf("expected digests claimed for %v, got %v", yesterday, claimedDay)
+		}
+		if !from.Equal(yesterday) || !to.Equal(yesterday.AddDate(0, 0, 1)) {
+			t.Errorf("expected registrations made on %v, got %v to %v", yesterday, from, to)
+		}
+		if loads != 1 {
+			t.Errorf("expected the organisation's stats loaded once, got %d loads", loads)
+		}
+		if msg := mailer.sent[1]; msg.To != "bo@example.com" || msg.Subject != "Riverside Harriers: 3 new entries on Thursday 30 April 2026" {
+			t.Errorf("unexpected email to %q: %q", msg.To, msg.Subject)
+		}
+	})
+
+	t.Run("skips organisations without registrations", func(t *testing.T) {
+		orgs := &repositorymocks.OrganisationRepositoryMock{
+			ClaimRegistrationDigestsFunc: func(ctx context.Context, day time.Time) ([]db.ClaimRegistrationDigestsRow, error) {
+				return []db.ClaimRegistrationDigestsRow{{OrganisationID: 2, Email: "ada@example.com"}}, nil
+			},
+		}
+		mailer := &mockMailer{}
+
+		sent, err := newService(orgs, &repositorymocks.RegistrationRepositoryMock{}, mailer).SendRegistrationDigests(context.Background())
+
+		if err != nil || sent != 0 || len(mailer.sent) != 0 {
+			t.Errorf("expected nothing sent, got %d reported, %d sent, %v", sent, len(mailer.sent), err)
+		}
+	})
+
+	t.Run("sends nothing once the day's digests have been claimed", func(t *testing.T) {
+		claimed := false
+		orgs := &repositorymocks.OrganisationRepositoryMock{
+			ClaimRegistrationDigestsFunc: func(ctx context.Context, day time.Time) ([]db.ClaimRegistrationDigestsRow, error) {
+				if claimed {
+					return nil, nil
+				}
+				claimed = true
+				return []db.ClaimRegistrationDigestsRow{{OrganisationID: 1, Email: "ada@example.com"}}, nil
+			},
+		}
+		registrations := &repositorymocks.RegistrationRepositoryMock{
+			DigestStatsFunc: func(ctx context.Context, organisationID int64, from, to time.Time) ([]db.GetRegistrationDigestStatsRow, error) {
+				return stats, nil
+			},
+		}
+		mailer := &mockMailer{}
+		svc := newService(orgs, registrations, mailer)
+
+		for range 2 {
+			if _, err := svc.SendRegistrationDigests(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if len(mailer.sent) != 1 {
+			t.Errorf("expected one digest across both runs, got %d", len(mailer.sent))
+		}
+	})
+
+	t.Run("carries on past an organisation that fails to load", func(t *testing.T) {
+		loadErr := errors.New("database unavailable")
+		orgs := &repositorymocks.OrganisationRepositoryMock{
+			ClaimRegistrationDigestsFunc: func(ctx context.Context, day time.Time) ([]db.ClaimRegistrationDigestsRow, error) {
+				return []db.ClaimRegistrationDigestsRow{
+					{OrganisationID: 1, Email: "ada@example.com"},
+					{OrganisationID: 2, Email: "bo@example.com"},
+				}, nil
+			},
+		}
+		registrations := &repositorymocks.RegistrationRepositoryMock{
+			DigestStatsFunc: func(ctx context.Context, organisationID int64, from, to time.Time) ([]db.GetRegistrationDigestStatsRow, error) {
+				if organisationID == 1 {
+					return nil, loadErr
+				}
+				return stats, nil
+			},
+		}
+		mailer := &mockMailer{}
+
+		sent, err := newService(orgs, registrations, mailer).SendRegistrationDigests(context.Background())
+
+		if !errors.Is(err, loadErr) {
+			t.Errorf("expected the load error, got %v", err)
+		}
+		if sent != 1 || len(mailer.sent) != 1 || mailer.sent[0].To != "bo@example.com" {
+			t.Errorf("expected the other organisation's digest sent, got %d", len(mailer.sent))
+		}
+	})
+}
+
+func TestRegistrationDigest(t *testing.T) {
+	day := time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)
+	got := registrationDigest(day, []db.GetRegistrationDigestStatsRow{
+		{EventID: 10, EventName: "Riverside Run", Year: 2026, Currency: "EUR", Registrations: 1, RevenueUnits: 4000},
+		{EventID: 10, EventName: "Riverside Run", Year: 2026, Currency: "GBP", Registrations: 2, RevenueUnits: 123450},
+		{EventID: 11, EventName: "Hill Climb", Year: 2026, Currency: "GBP", Registrations: 4},
+		{EventID: 12, EventName: "Fjord Dash", Year: 2026, Currency: "NOK", Registrations: 1, RevenueUnits: 25000},
+	})
+
+	if got.Date != "Thursday 30 April 2026" {
+		t.Errorf("unexpected date %q", got.Date)
+	}
+	if got.Registrations != 8 {
+		t.Errorf("expected 8 registrations in all, got %d", got.Registrations)
+	}
+	if got.Revenue != "€40.00 + £1,234.50 + 250.00 NOK" {
+		t.Errorf("unexpected total revenue %q", got.Revenue)
+	}
+	if len(got.Events) != 3 {
+		t.Fatalf("expected three events, got %+v", got.Events)
+	}
+	if e := got.Events[0]; e.Name != "Riverside Run 2026" || e.Registrations != 3 || e.Revenue != "€40.00 + £1,234.50" {
+		t.Errorf("expected currencies combined per event, got %+v", e)
+	}
+	if e := got.Events[1]; e.Name != "Hill Climb 2026" || e.Registrations != 4 || e.Revenue != "" {
+		t.Errorf("expected an event without revenue, got %+v", e)
+	}
+}
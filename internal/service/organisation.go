@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/mail"
+	"firecrest/internal/repository"
+	"firecrest/internal/token"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/organisation.go -pkg servicemocks . OrganisationService
+
+// InvitationTokenTTL is how long an invitee has to accept an invitation to
+// an organisation through the emailed link.
+const InvitationTokenTTL = 7 * 24 * time.Hour
+
+// Organisation errors
+var (
+	ErrAlreadyMember = apperr.New(apperr.CodeAlreadyMember, http.StatusConflict, "already a member of this organisation")
+	ErrOwnerRemoval  = apperr.New(apperr.CodeOwnerRemoval, http.StatusConflict, "organisation owners cannot be removed")
+)
+
+// OrganisationService defines the interface for organisation business logic.
+//
+// Any member of an organisation can manage its existing events and races.
+// Owners and admins can also create events and hold the read:medical
+// permission to see entrants' medical answers, and owners manage the
+// members. Site admins can do everything in every organisation.
+type OrganisationService interface {
+	ListOrganisations(ctx context.Context) ([]db.Organisation, error)
+	ListOrganisationsForUser(ctx context.Context, userID int64) ([]db.Organisation, error)
+	GetOrganisation(ctx context.Context, id int64) (db.Organisation, error)
+	// CanManageEvent reports whether the user can change the event and its
+	// races. It returns repository.ErrNotFound if the event does not exist.
+	CanManageEvent(ctx context.Context, userID, eventID int64) (bool, error)
+	// CanCreateEvents reports whether the user can create events for the
+	// organisation.
+	CanCreateEvents(ctx context.Context, userID, organisationID int64) (bool, error)
+	// CanReadMedical reports whether the user holds the read:medical
+	// permission for the event, letting them see the medical answers its
+	// entrants gave. It returns repository.ErrNotFound if the event does
+	// not exist.
+	CanReadMedical(ctx context.Context, userID, eventID int64) (bool, error)
+	// CanManageMembers reports whether the user can invite and remove the
+	// organisation's members.
+	CanManageMembers(ctx context.Context, userID, organisationID int64) (bool, error)
+	ListMembers(ctx context.Context, organisationID int64) ([]db.ListOrganisationMembersRow, error)
+	ListPendingInvitations(ctx context.Context, organisationID int64) ([]db.ListPendingOrganisationInvitationsRow, error)
+	// InviteMember invites the person with email to join the organisation
+	// in role, creating an account for them if they have none, and emails
+	// them a link to accept. Only admins and staff can be invited.
+	InviteMember(ctx context.Context, inviterID, organisationID int64, email string, role db.OrganisationRole) (Invite, error)
+	// RemoveMember ends the user's membership of the organisation. Owners
+	// cannot be removed.
+	RemoveMember(ctx context.Context, removerID, organisationID, userID int64) error
+	// AcceptInvitations accepts every invitation waiting on the user the
+	// token was issued to.
+	AcceptInvitations(ctx context.Context, token string) error
+	// GetMembership returns the user's membership of the organisation. It
+	// returns repository.ErrNotFound if they are not a member.
+	GetMembership(ctx context.Context, userID, organisationID int64) (db.OrganisationMember, error)
+	// SetNotificationPreference sets how the member hears about new
+	// registrations for the organisation's events: an email for each, a
+	// daily digest, or nothing. It returns repository.ErrNotFound if the
+	// user is not a member.
+	SetNotificationPreference(ctx context.Context, userID, organisationID int64, pref db.NotificationPreference) error
+	// UseOrganisation records that the user is working in the
+	// organisation, so their next session starts in it.
+	UseOrganisation(ctx context.Context, userID, organisationID int64) error
+	// LastUsedOrganisation returns the ID of the organisation the user
+	// most recently worked in. It returns repository.ErrNotFound if they
+	// have worked in none they are still a member of; site admins working
+	// in organisations they do not belong to are not recorded.
+	LastUsedOrganisation(ctx context.Context, userID int64) (int64, error)
+	// SetEmbedding sets which other websites may read the availability of
+	// the organisation's races, for a widget on their pages: the origins
+	// listed, each a scheme and host such as https://club.example, or any
+	// site when public and none are listed. It returns
+	// repository.ErrNotFound if the organisation does not exist.
+	SetEmbedding(ctx context.Context, organisationID int64, public bool, origins []string) error
+}
+
+// MaxEmbedOrigins bounds how many websites an organisation may list as
+// allowed to embed its availability.
+const MaxEmbedOrigins = 20
+
+// Invite describes an invitation sent to join an organisation.
+type Invite struct {
+	Email string
+	Role  db.OrganisationRole
+	// NewUser reports whether an account was created for the invitee.
+	NewUser bool
+}
+
+type organisationService struct {
+	orgRepo   repository.OrganisationRepository
+	userRepo  repository.UserRepository
+	eventRepo repository.EventRepository
+	mailer    mail.Mailer
+	tokens    *token.Signer
+	baseURL   string
+}
+
+// NewOrganisationService creates a new OrganisationService with the given
+// repositories. Invitations are sent through mailer with links rooted at
+// baseURL, carrying tokens signed by tokens.
+func NewOrganisationService(
+	orgRepo repository.OrganisationRepository,
+	userRepo repository.UserRepository,
+	eventRepo repository.EventRepository,
+	mailer mail.Mailer,
+	tokens *token.Signer,
+	baseURL string,
+) OrganisationService {
+	return &organisationService{
+		orgRepo:   orgRepo,
+		userRepo:  userRepo,
+		eventRepo: eventRepo,
+		mailer:    mailer,
+		tokens:    tokens,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (s *organisationService) ListOrganisations(ctx context.Context) ([]db.Organisation, error) {
+	return s.orgRepo.List(ctx)
+}
+
+func (s *organisationService) ListOrganisationsForUser(ctx context.Context, userID int64) ([]db.Organisation, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+	return s.orgRepo.ListForUser(ctx, userID)
+}
+
+func (s *organisationService) GetOrganisation(ctx context.Context, id int64) (db.Organisation, error) {
+	return s.orgRepo.Get(ctx, id)
+}
+
+func (s *organisationService) CanManageEvent(ctx context.Context, userID, eventID int64) (bool, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return false, err
+	}
+	_, ok, err := s.roleIn(ctx, userID, event.OrganisationID)
+	return ok, err
+}
+
+func (s *organisationService) CanCreateEvents(ctx context.Context, userID, organisationID int64) (bool, error) {
+	role, ok, err := s.roleIn(ctx, userID, organisationID)
+	if err != nil || !ok {
+		return false, err
+	}
+	return role == db.OrganisationRoleOwner || role == db.OrganisationRoleAdmin, nil
+}
+
+func (s *organisationService) CanReadMedical(ctx context.Context, userID, eventID int64) (bool, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return false, err
+	}
+	role, ok, err := s.roleIn(ctx, userID, event.OrganisationID)
+	if err != nil || !ok {
+		return false, err
+	}
+	return role == db.OrganisationRoleOwner || role == db.OrganisationRoleAdmin, nil
+}
+
+func (s *organisationService) CanManageMembers(ctx context.Context, userID, organisationID int64) (bool, error) {
+	role, ok, err := s.roleIn(ctx, userID, organisationID)
+	if err != nil || !ok {
+		return false, err
+	}
+	return role == db.OrganisationRoleOwner, nil
+}
+
+// roleIn returns the user's role in the organisation, treating site admins
+// as owners of every organisation. ok is false if the user has no role,
+// including when the user does not exist.
+func (s *organisationService) roleIn(ctx context.Context, userID, organisationID int64) (db.OrganisationRole, bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	if user.Role == db.UserRoleAdmin {
+		return db.OrganisationRoleOwner, true, nil
+	}
+
+	member, err := s.orgRepo.GetMembership(ctx, userID, organisationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return member.Role, true, nil
+}
+
+func (s *organisationService) ListMembers(ctx context.Context, organisationID int64) ([]db.ListOrganisationMembersRow, error) {
+	return s.orgRepo.ListMembers(ctx, organisationID)
+}
+
+func (s *organisationService) ListPendingInvitations(ctx context.Context, organisationID int64) ([]db.ListPendingOrganisationInvitationsRow, error) {
+	return s.orgRepo.ListPendingInvitations(ctx, organisationID)
+}
+
+func (s *organisationService) InviteMember(ctx context.Context, inviterID, organisationID int64, email string, role db.OrganisationRole) (Invite, error) {
+	// Organisations have their owners from the start; ownership is not
+	// handed out by invitation
+	if role != db.OrganisationRoleAdmin && role != db.OrganisationRoleStaff {
+		return Invite{}, fmt.Errorf("%w: role must be admin or staff", ErrInvalidInput)
+	}
+	email = NormalizeEmail(email)
+	if err := ValidateEmail(email); err != nil {
+		return Invite{}, err
+	}
+
+	allowed, err := s.CanManageMembers(ctx, inviterID, organisationID)
+	if err != nil {
+		return Invite{}, err
+	}
+	if !allowed {
+		return Invite{}, ErrForbidden
+	}
+
+	org, err := s.orgRepo.Get(ctx, organisationID)
+	if err != nil {
+		return Invite{}, err
+	}
+	inviter, err := s.userRepo.GetByID(ctx, inviterID)
+	if err != nil {
+		return Invite{}, err
+	}
+
+	invited, err := s.orgRepo.Invite(ctx, repository.InviteParams{
+		OrganisationID: organisationID,
+		InviterID:      inviterID,
+		InviteeEmail:   email,
+		Role:           role,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return Invite{}, ErrAlreadyMember
+		}
+		return Invite{}, fmt.Errorf("failed to invite member: %w", err)
+	}
+
+	result := Invite{
+		Email:   invited.Invitee.Email,
+		Role:    role,
+		NewUser: invited.NewInvitee,
+	}
+	if err := s.sendInvitationEmail(ctx, org, inviter, invited.Invitee, role); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// sendInvitationEmail queues an email inviting the invitee to join the
+// organisation, with a link to accept.
+func (s *organisationService) sendInvitationEmail(ctx context.Context, org db.Organisation, inviter, invitee db.User, role db.OrganisationRole) error {
+	tok, err := s.tokens.SignToken(token.PurposeOrganisationInvitation, invitee.ID, InvitationTokenTTL)
+	if err != nil {
+		return fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	roleName := "staff"
+	if role == db.OrganisationRoleAdmin {
+		roleName = "an admin"
+	}
+	msg, err := mail.OrganisationInvitationMessage(invitee.Email, mail.OrganisationInvitationData{
+		FirstName:        invitee.FirstName,
+		InviterName:      strings.TrimSpace(inviter.FirstName + " " + inviter.LastName),
+		OrganisationName: org.Name,
+		Role:             roleName,
+		AcceptURL:        s.baseURL + "/organisations/invitations/accept?token=" + url.QueryEscape(tok),
+		ExpiresIn:        "7 days",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build invitation email: %w", err)
+	}
+
+	// Delivery happens in the background and the mailer logs any failure;
+	// the invitation is recorded either way.
+	_ = s.mailer.Send(ctx, msg)
+	return nil
+}
+
+func (s *organisationService) RemoveMember(ctx context.Context, removerID, organisationID, userID int64) error {
+	allowed, err := s.CanManageMembers(ctx, removerID, organisationID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+
+	member, err := s.orgRepo.GetMembership(ctx, userID, organisationID)
+	if err != nil {
+		return err
+	}
+	// Every organisation keeps its owners, so it is never left without
+	// someone to manage its members
+	if member.Role == db.OrganisationRoleOwner {
+		return ErrOwnerRemoval
+	}
+	return s.orgRepo.RemoveMember(ctx, organisationID, userID)
+}
+
+func (s *organisationService) AcceptInvitations(ctx context.Context, tok string) error {
+	userID, err := s.tokens.VerifyToken(token.PurposeOrganisationInvitation, tok)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	// Accepting is idempotent, so a link opened twice still succeeds
+	if _, err := s.orgRepo.AcceptInvitations(ctx, userID); err != nil {
+		return fmt.Errorf("failed to accept invitations: %w", err)
+	}
+	return nil
+}
+
+func (s *organisationService) GetMembership(ctx context.Context, userID, organisationID int64) (db.OrganisationMember, error) {
+	return s.orgRepo.GetMembership(ctx, userID, organisationID)
+}
+
+func (s *organisationService) SetNotificationPreference(ctx context.Context, userID, organisationID int64, pref db.NotificationPreference) error {
+	switch pref {
+	case db.NotificationPreferenceImmediate, db.NotificationPreferenceDailyDigest, db.NotificationPreferenceNone:
+	default:
+		return fmt.Errorf("%w: unknown notification preference %q", ErrInvalidInput, pref)
+	}
+	return s.orgRepo.SetNotificationPreference(ctx, organisationID, userID, pref)
+}
+
+func (s *organisationService) UseOrganisation(ctx context.Context, userID, organisationID int64) error {
+	return s.orgRepo.TouchMembership(ctx, organisationID, userID)
+}
+
+func (s *organisationService) LastUsedOrganisation(ctx context.Context, userID int64) (int64, error) {
+	return s.orgRepo.LastUsedOrganisation(ctx, userID)
+}
+
+func (s *organisationService) SetEmbedding(ctx context.Context, organisationID int64, public bool, origins []string) error {
+	var allowed []string
+	for _, raw := range origins {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		origin, ok := parseOrigin(raw)
+		if !ok {
+			return FieldErrors{"embed_origins": fmt.Sprintf("%q is not a website address such as https://club.example", raw)}
+		}
+		if !slices.Contains(allowed, origin) {
+			allowed = append(allowed, origin)
+		}
+	}
+	if len(allowed) > MaxEmbedOrigins {
+		return FieldErrors{"embed_origins": fmt.Sprintf("list at most %d websites", MaxEmbedOrigins)}
+	}
+	if allowed == nil {
+		allowed = []string{}
+	}
+	return s.orgRepo.SetEmbedding(ctx, organisationID, public, allowed)
+}
+
+// parseOrigin reads a website's origin, as browsers send it in the Origin
+// header: an http or https scheme and a host with any port, in lower case.
+// A trailing slash is forgiven, as people copy addresses with one.
+func parseOrigin(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", false
+	}
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", false
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), true
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+	"firecrest/internal/token"
+)
+
+func TestOrganisationService_CanManageEvent(t *testing.T) {
+	const (
+		orgA int64 = 7
+		orgB int64 = 8
+	)
+	users := map[int64]db.User{
+		1: {ID: 1, Role: db.UserRoleAdmin},
+		2: {ID: 2, Role: db.UserRoleOrganizer},
+		3: {ID: 3, Role: db.UserRoleOrganizer},
+		4: {ID: 4, Role: db.UserRoleOrganizer},
+	}
+	// Users 2, 3 and 4 are org A's owner, admin and staff
+	memberships := map[int64]db.OrganisationMember{
+		2: {UserID: 2, OrganisationID: orgA, Role: db.OrganisationRoleOwner},
+		3: {UserID: 3, OrganisationID: orgA, Role: db.OrganisationRoleAdmin},
+		4: {UserID: 4, OrganisationID: orgA, Role: db.OrganisationRoleStaff},
+	}
+	events := map[int64]db.Event{
+		10: {ID: 10, OrganisationID: orgA},
+		20: {ID: 20, OrganisationID: orgB},
+	}
+
+	svc := NewOrganisationService(
+		&repositorymocks.OrganisationRepositoryMock{
+			GetMembershipFunc: func(ctx context.Context, userID, organisationID int64) (db.OrganisationMember, error) {
+				m, ok := memberships[userID]
+				if !ok || m.OrganisationID != organisationID {
+					return db.OrganisationMember{}, repository.ErrNotFound
+				}
+				return m, nil
+			},
+		},
+		&repositorymocks.UserRepositoryMock{GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+			u, ok := users[id]
+			if !ok {
+				return db.User{}, repository.ErrNotFound
+			}
+			return u, nil
+		}},
+		&repositorymocks.EventRepositoryMock{GetByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+			e, ok := events[id]
+			if !ok {
+				return db.Event{}, repository.ErrNotFound
+			}
+			return e, nil
+		}},
+		&mockMailer{},
+		newTestSigner(t),
+		"https://firecrest.example",
+	)
+
+	tests := []struct {
+		name    string
+		userID  int64
+		eventID int64
+		want    bool
+	}{
+		{name: "lets staff manage their organisation's events", userID: 4, eventID: 10, want: true},
+		{name: "lets owners manage their organisation's events", userID: 2, eventID: 10, want: true},
+		{name: "stops staff of org A editing an event of org B", userID: 4, eventID: 20, want: false},
+		{name: "stops owners of org A editing an event of org B", userID: 2, eventID: 20, want: false},
+		{name: "lets site admins manage any event", userID: 1, eventID: 20, want: true},
+		{name: "stops unknown users", userID: 99, eventID: 10, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.CanManageEvent(context.Background(), tt.userID, tt.eventID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("returns ErrNotFound for an unknown event", func(t *testing.T) {
+		if _, err := svc.CanManageEvent(context.Background(), 1, 99); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("lets only owners and admins create events", func(t *testing.T) {
+		want := map[int64]bool{2: true, 3: true, 4: false}
+		for userID, allowed := range want {
+			got, err := svc.CanCreateEvents(context.Background(), userID, orgA)
+			if err != nil || got != allowed {
+				t.Errorf("user %d: expected %v, got %v (err %v)", userID, allowed, got, err)
+			}
+		}
+		if got, _ := svc.CanCreateEvents(context.Background(), 3, orgB); got {
+			t.Error("expected org A's admin not to create events for org B")
+		}
+	})
+
+	t.Run("gives read:medical to owners, admins and site admins but not staff", func(t *testing.T) {
+		want := map[int64]bool{1: true, 2: true, 3: true, 4: false}
+		for userID, allowed := range want {
+			got, err := svc.CanReadMedical(context.Background(), userID, 10)
+			if err != nil || got != allowed {
+				t.Errorf("user %d: expected %v, got %v (err %v)", userID, allowed, got, err)
+			}
+		}
+		if got, _ := svc.CanReadMedical(context.Background(), 2, 20); got {
+			t.Error("expected org A's owner not to read org B's medical answers")
+		}
+		if _, err := svc.CanReadMedical(context.Background(), 1, 99); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown event, got %v", err)
+		}
+	})
+
+	t.Run("lets only owners manage members", func(t *testing.T) {
+		want := map[int64]bool{1: true, 2: true, 3: false, 4: false}
+		for userID, allowed := range want {
+			got, err := svc.CanManageMembers(context.Background(), userID, orgA)
+			if err != nil || got != allowed {
+				t.Errorf("user %d: expected %v, got %v (err %v)", userID, allowed, got, err)
+			}
+		}
+	})
+}
+
+func TestOrganisationService_InviteMember(t *testing.T) {
+	const (
+		orgID   int64 = 7
+		ownerID int64 = 2
+		staffID int64 = 4
+		baseURL       = "https://firecrest.example"
+	)
+
+	type deps struct {
+		mailer  *mockMailer
+		invites []repository.InviteParams
+	}
+
+	newService := func(t *testing.T) (*organisationService, *deps) {
+		d := &deps{mailer: &mockMailer{}}
+		repo := &repositorymocks.OrganisationRepositoryMock{
+			GetFunc: func(ctx context.Context, id int64) (db.Organisation, error) {
+				return db.Organisation{ID: id, Name: "Peak Running Co"}, nil
+			},
+			GetMembershipFunc: func(ctx context.Context, userID, organisationID int64) (db.OrganisationMember, error) {
+				switch userID {
+				case ownerID:
+					return db.OrganisationMember{Role: db.OrganisationRoleOwner}, nil
+				case staffID:
+					return db.OrganisationMember{Role: db.OrganisationRoleStaff}, nil
+				}
+				return db.OrganisationMember{}, repository.ErrNotFound
+			},
+			InviteFunc: func(ctx context.Context, params repository.InviteParams) (repository.Invitation, error) {
+				d.invites = append(d.invites, params)
+				if params.InviteeEmail == "alex@example.com" {
+					return repository.Invitation{}, repository.ErrConflict
+				}
+				return repository.Invitation{
+					Invitee:    db.User{ID: 9, Email: params.InviteeEmail},
+					NewInvitee: true,
+				}, nil
+			},
+		}
+		users := &repositorymocks.UserRepositoryMock{GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+			return db.User{ID: id, FirstName: "Jane", LastName: "Runner", Role: db.UserRoleOrganizer}, nil
+		}}
+		svc := NewOrganisationService(repo, users, &repositorymocks.EventRepositoryMock{}, d.mailer, newTestSigner(t), baseURL).(*organisationService)
+		return svc, d
+	}
+
+	t.Run("invites a new member with a link to accept", func(t *testing.T) {
+		svc, d := newService(t)
+
+		invite, err := svc.InviteMember(context.Background(), ownerID, orgID, " Sam@Example.com ", db.OrganisationRoleStaff)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !invite.NewUser || invite.Email != "sam@example.com" {
+			t.Errorf("unexpected invite: %+v", invite)
+		}
+		want := repository.InviteParams{OrganisationID: orgID, InviterID: ownerID, InviteeEmail: "sam@example.com", Role: db.OrganisationRoleStaff}
+		if len(d.invites) != 1 || d.invites[0] != want {
+			t.Errorf("expected invite %+v, got %+v", want, d.invites)
+		}
+		if len(d.mailer.sent) != 1 {
+			t.Fatalf("expected 1 email, got %d", len(d.mailer.sent))
+		}
+
+		text := d.mailer.sent[0].Text
+		if !strings.Contains(text, "Jane Runner") || !strings.Contains(text, "Peak Running Co") {
+			t.Errorf("expected the inviter and organisation in:\n%s", text)
+		}
+		prefix := baseURL + "/organisations/invitations/accept?token="
+		start := strings.Index(text, prefix)
+		if start < 0 {
+			t.Fatalf("expected an accept link in:\n%s", text)
+		}
+		tok, err := url.QueryUnescape(strings.TrimPrefix(strings.Fields(text[start:])[0], prefix))
+		if err != nil {
+			t.Fatalf("failed to unescape token: %v", err)
+		}
+		userID, err := svc.tokens.VerifyToken(token.PurposeOrganisationInvitation, tok)
+		if err != nil || userID != 9 {
+			t.Errorf("expected an invitation token for user 9, got %d (err %v)", userID, err)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		inviterID int64
+		email     string
+		role      db.OrganisationRole
+		want      error
+	}{
+		{name: "rejects invitations from staff", inviterID: staffID, email: "sam@example.com", role: db.OrganisationRoleStaff, want: ErrForbidden},
+		{name: "rejects invitations from non-members", inviterID: 99, email: "sam@example.com", role: db.OrganisationRoleStaff, want: ErrForbidden},
+		{name: "rejects inviting owners", inviterID: ownerID, email: "sam@example.com", role: db.OrganisationRoleOwner, want: ErrInvalidInput},
+		{name: "rejects an invalid email", inviterID: ownerID, email: "not-an-email", role: db.OrganisationRoleAdmin, want: ErrInvalidInput},
+		{name: "rejects existing members", inviterID: ownerID, email: "alex@example.com", role: db.OrganisationRoleAdmin, want: ErrAlreadyMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, d := newService(t)
+
+			_, err := svc.InviteMember(context.Background(), tt.inviterID, orgID, tt.email, tt.role)
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if len(d.mailer.sent) != 0 {
+				t.Error("expected no email to be sent")
+			}
+		})
+	}
+}
+
+func TestOrganisationService_RemoveMember(t *testing.T) {
+	const (
+		orgID   int64 = 7
+		ownerID int64 = 2
+		staffID int64 = 4
+	)
+	roles := map[int64]db.OrganisationRole{ownerID: db.OrganisationRoleOwner, 3: db.OrganisationRoleOwner, staffID: db.OrganisationRoleStaff}
+
+	var removed []int64
+	svc := NewOrganisationService(
+		&repositorymocks.OrganisationRepositoryMock{
+			GetMembershipFunc: func(ctx context.Context, userID, organisationID int64) (db.OrganisationMember, error) {
+				role, ok := roles[userID]
+				if !ok {
+					return db.OrganisationMember{}, repository.ErrNotFound
+				}
+				return db.OrganisationMember{UserID: userID, Role: role}, nil
+			},
+			RemoveMemberFunc: func(ctx context.Context, organisationID, userID int64) error {
+				removed = append(removed, userID)
+				return nil
+			},
+		},
+		&repositorymocks.UserRepositoryMock{GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+			return db.User{ID: id, Role: db.UserRoleOrganizer}, nil
+		}},
+		&repositorymocks.EventRepositoryMock{}, &mockMailer{}, newTestSigner(t), "",
+	)
+
+	tests := []struct {
+		name      string
+		removerID int64
+		userID    int64
+		want      error
+	}{
+		{name: "removes staff", removerID: ownerID, userID: staffID},
+		{name: "keeps owners", removerID: ownerID, userID: 3, want: ErrOwnerRemoval},
+		{name: "rejects removals by staff", removerID: staffID, userID: staffID, want: ErrForbidden},
+		{name: "reports non-members", removerID: ownerID, userID: 99, want: repository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed = nil
+
+			err := svc.RemoveMember(context.Background(), tt.removerID, orgID, tt.userID)
+
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if wantRemoved := tt.want == nil; (len(removed) == 1) != wantRemoved {
+				t.Errorf("expected removal %v, got %v", wantRemoved, removed)
+			}
+		})
+	}
+}
+
+func TestOrganisationService_AcceptInvitations(t *testing.T) {
+	signer := newTestSigner(t)
+	var accepted []int64
+	svc := &organisationService{
+		orgRepo: &repositorymocks.OrganisationRepositoryMock{
+			AcceptInvitationsFunc: func(ctx context.Context, userID int64) (int64, error) {
+				accepted = append(accepted, userID)
+				return 1, nil
+			},
+		},
+		tokens: signer,
+	}
+
+	t.Run("accepts the invitations waiting on the token's user", func(t *testing.T) {
+		tok, err := signer.SignToken(token.PurposeOrganisationInvitation, 9, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+
+		if err := svc.AcceptInvitations(context.Background(), tok); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(accepted) != 1 || accepted[0] != 9 {
+			t.Errorf("expected invitations for user 9 to be accepted, got %v", accepted)
+		}
+	})
+
+	t.Run("rejects tokens issued for another purpose", func(t *testing.T) {
+		accepted = nil
+		tok, err := signer.SignToken(token.PurposeRegistrationTransfer, 9, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+
+		if err := svc.AcceptInvitations(context.Background(), tok); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+		if len(accepted) != 0 {
+			t.Errorf("expected nothing to be accepted, got %v", accepted)
+		}
+	})
+}
+
+func TestOrganisationService_SetNotificationPreference(t *testing.T) {
+	var got db.NotificationPreference
+	repo := &repositorymocks.OrganisationRepositoryMock{
+		SetNotificationPreferenceFunc: func(ctx context.Context, organisationID, userID int64, pref db.NotificationPreference) error {
+			if organisationID != 1 || userID != 7 {
+				return repository.ErrNotFound
+			}
+			got = pref
+			return nil
+		},
+	}
+	svc := &organisationService{orgRepo: repo}
+
+	if err := svc.SetNotificationPreference(context.Background(), 7, 1, db.NotificationPreferenceDailyDigest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != db.NotificationPreferenceDailyDigest {
+		t.Errorf("expected the daily digest, got %q", got)
+	}
+
+	if err := svc.SetNotificationPreference(context.Background(), 7, 1, "weekly"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an unknown preference, got %v", err)
+	}
+	if err := svc.SetNotificationPreference(context.Background(), 8, 1, db.NotificationPreferenceNone); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a non-member, got %v", err)
+	}
+}
+
+func TestOrganisationService_SetEmbedding(t *testing.T) {
+	var gotPublic bool
+	var gotOrigins []string
+	repo := &repositorymocks.OrganisationRepositoryMock{
+		SetEmbeddingFunc: func(ctx context.Context, organisationID int64, public bool, origins []string) error {
+			gotPublic, gotOrigins = public, origins
+			return nil
+		},
+	}
+	svc := &organisationService{orgRepo: repo}
+
+	t.Run("keeps each website's origin once", func(t *testing.T) {
+		err := svc.SetEmbedding(context.Background(), 1, true, []string{" https://Club.Example/ ", "", "http://localhost:3000", "https://club.example"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !gotPublic || !slices.Equal(gotOrigins, []string{"https://club.example", "http://localhost:3000"}) {
+			t.Errorf("unexpected settings: public %v, origins %v", gotPublic, gotOrigins)
+		}
+	})
+
+	t.Run("stores an empty list rather than none", func(t *testing.T) {
+		if err := svc.SetEmbedding(context.Background(), 1, false, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotOrigins == nil || len(gotOrigins) != 0 {
+			t.Errorf("expected an empty list, got %#v", gotOrigins)
+		}
+	})
+
+	t.Run("refuses anything but a website's origin", func(t *testing.T) {
+		for _, origin := range []string{"club.example", "ftp://club.example", "https://club.example/widget", "https://user@club.example", "https://club.example?x=1"} {
+			err := svc.SetEmbedding(context.Background(), 1, false, []string{origin})
+			var fieldErrs FieldErrors
+			if !errors.As(err, &fieldErrs) || fieldErrs["embed_origins"] == "" {
+				t.Errorf("expected a field error for %q, got %v", origin, err)
+			}
+		}
+	})
+
+	t.Run("limits how many websites are listed", func(t *testing.T) {
+		var origins []string
+		for i := range MaxEmbedOrigins + 1 {
+			origins = append(origins, fmt.Sprintf("https://site%d.example", i))
+		}
+		var fieldErrs FieldErrors
+		if err := svc.SetEmbedding(context.Background(), 1, false, origins); !errors.As(err, &fieldErrs) {
+			t.Errorf("expected a field error, got %v", err)
+		}
+	})
+}
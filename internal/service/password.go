@@ -0,0 +1,70 @@
+package service
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// MaxPasswordBytes is the longest password bcrypt hashes in full. Longer
+// passwords are refused rather than silently truncated.
+const MaxPasswordBytes = 72
+
+// commonPasswordList holds the most common leaked passwords, one lowercase
+// password per line.
+//
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords is the set of commonPasswordList, built on first use.
+var commonPasswords = sync.OnceValue(func() map[string]struct{} {
+	set := make(map[string]struct{})
+	for line := range strings.Lines(commonPasswordList) {
+		if pw := strings.TrimSpace(line); pw != "" {
+			set[strings.ToLower(pw)] = struct{}{}
+		}
+	}
+	return set
+})
+
+// passwordProblem describes what is wrong with a new password for the user
+// with the given email and name, or returns "" if it is acceptable. Length is
+// counted in characters for the minimum and in bytes for the maximum, since
+// that is the limit bcrypt imposes.
+func passwordProblem(password, email, firstName, lastName string) string {
+	if password == "" {
+		return "password is required"
+	}
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return fmt.Sprintf("password must be at least %d characters", MinPasswordLength)
+	}
+	if len(password) > MaxPasswordBytes {
+		return fmt.Sprintf("password must be at most %d bytes; accented letters and symbols count as more than one", MaxPasswordBytes)
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := commonPasswords()[lower]; ok {
+		return "password is too common, choose one that is harder to guess"
+	}
+	if matchesPersonalDetail(lower, email, firstName, lastName) {
+		return "password must not be your name or email address"
+	}
+	return ""
+}
+
+// matchesPersonalDetail reports whether the lowercased password is the
+// local part of email, or the first, last or full name.
+func matchesPersonalDetail(password, email, firstName, lastName string) bool {
+	first := strings.ToLower(strings.TrimSpace(firstName))
+	last := strings.ToLower(strings.TrimSpace(lastName))
+	local, _, _ := strings.Cut(NormalizeEmail(email), "@")
+
+	for _, detail := range []string{local, first, last, first + last, first + " " + last} {
+		if strings.TrimSpace(detail) != "" && password == detail {
+			return true
+		}
+	}
+	return false
+}
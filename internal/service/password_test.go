@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSignUpInput_ValidatePassword(t *testing.T) {
+	// "é" is two bytes, so these sit either side of MaxPasswordBytes
+	atLimit := strings.Repeat("é", MaxPasswordBytes/2)
+	overLimit := atLimit + "x"
+
+	tests := []struct {
+		name     string
+		password string
+		// want is part of the expected password message, or "" for an
+		// acceptable password
+		want string
+	}{
+		{name: "requires a password", password: "", want: "password is required"},
+		{name: "rejects short passwords", password: "tr41l", want: "at least 8 characters"},
+		{name: "counts characters rather than bytes for the minimum", password: "ééééééé", want: "at least 8 characters"},
+		{name: "rejects common passwords", password: "password", want: "too common"},
+		{name: "rejects common passwords with digits", password: "password123", want: "too common"},
+		{name: "matches the common list case-insensitively", password: "QwErTy123", want: "too common"},
+		{name: "rejects the email local part", password: "jane.runner", want: "your name or email address"},
+		{name: "rejects the first name", password: "Jacqueline", want: "your name or email address"},
+		{name: "rejects the full name", password: "jacqueline runner", want: "your name or email address"},
+		{name: "rejects the full name without a space", password: "JacquelineRunner", want: "your name or email address"},
+		{name: "accepts multi-byte passwords at the byte limit", password: atLimit},
+		{name: "rejects passwords past the byte limit", password: overLimit, want: "at most 72 bytes"},
+		{name: "rejects long ASCII passwords", password: strings.Repeat("a", MaxPasswordBytes+1), want: "at most 72 bytes"},
+		{name: "accepts a passphrase", password: "granite fell at sunrise"},
+		{name: "accepts a passphrase containing the name", password: "jacqueline runs the fells"},
+		{name: "accepts mixed characters", password: "Tr41l-r0ute!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := SignUpInput{
+				Email:     "Jane.Runner@example.com",
+				Password:  tt.password,
+				FirstName: "Jacqueline",
+				LastName:  "Runner",
+			}
+
+			err := input.Validate()
+			var fields FieldErrors
+			if err != nil && !errors.As(err, &fields) {
+				t.Fatalf("expected field errors, got %v", err)
+			}
+			got := fields["password"]
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("expected %q to be accepted, got %q", tt.password, got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("expected a message containing %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
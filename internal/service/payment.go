@@ -0,0 +1,210 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/repository"
+)
+
+// FullRefundNotice is how long before a race's registration close date an
+// entrant must cancel to receive a full refund. Later cancellations receive
+// PartialRefundPercent of the amount paid.
+const (
+	FullRefundNotice     = 30 * 24 * time.Hour
+	PartialRefundPercent = 50
+)
+
+// RefundAmount returns how much of amountUnits is refunded when a
+// registration is cancelled at now for a race whose registration closes at
+// closeDate: all of it more than FullRefundNotice out, otherwise
+// PartialRefundPercent rounded down. A zero closeDate means no close date has
+// been set and always earns a full refund.
+func RefundAmount(amountUnits int32, now, closeDate time.Time) int32 {
+	if closeDate.IsZero() || closeDate.Sub(now) > FullRefundNotice {
+		return amountUnits
+	}
+	return amountUnits * PartialRefundPercent / 100
+}
+
+// ProviderCharge is a charge as the payment provider reports it. Amounts
+// are in the currency's minor units.
+type ProviderCharge struct {
+	Reference      string
+	RegistrationID int64
+	AmountUnits    int64
+	Currency       string
+	// FeeUnits is what the provider kept, or nil if it did not say.
+	FeeUnits      *int64
+	RefundedUnits int64
+	Failed        bool
+	CreatedAt     time.Time
+}
+
+// Validate checks that the charge can be recorded.
+func (c ProviderCharge) Validate() error {
+	switch {
+	case c.Reference == "":
+		return fmt.Errorf("%w: charge has no reference", ErrInvalidInput)
+	case len(c.Currency) != 3:
+		return fmt.Errorf("%w: currency must be a three letter code", ErrInvalidInput)
+	case c.AmountUnits < 0 || c.AmountUnits > math.MaxInt32:
+		return fmt.Errorf("%w: amount out of range", ErrInvalidInput)
+	case c.RefundedUnits < 0 || c.RefundedUnits > c.AmountUnits:
+		return fmt.Errorf("%w: refunded amount out of range", ErrInvalidInput)
+	case c.FeeUnits != nil && (*c.FeeUnits < 0 || *c.FeeUnits > math.MaxInt32):
+		return fmt.Errorf("%w: fee out of range", ErrInvalidInput)
+	}
+	return nil
+}
+
+// status is the payment status the charge amounts to.
+func (c ProviderCharge) status() db.PaymentStatus {
+	switch {
+	case c.Failed:
+		return db.PaymentStatusFailed
+	case c.RefundedUnits > 0 && c.RefundedUnits == c.AmountUnits:
+		return db.PaymentStatusRefunded
+	case c.RefundedUnits > 0:
+		return db.PaymentStatusPartiallyRefunded
+	}
+	return db.PaymentStatusSucceeded
+}
+
+// PaymentReport is the payments an organisation took over a period, with
+// their totals.
+type PaymentReport struct {
+	Payments []db.ListOrganisationPaymentsRow
+	// Totals has one entry per currency, in currency order.
+	Totals []PaymentTotals
+}
+
+// PaymentTotals sums payments in one currency, in its minor units. Net is
+// what the organisation keeps: gross less fees and refunds. Payments whose
+// fee is unknown count towards Net without one.
+type PaymentTotals struct {
+	Currency string
+	Gross    int64
+	Fees     int64
+	Refunded int64
+	Net      int64
+}
+
+// PaymentNet returns what the organisation keeps of a payment.
+func PaymentNet(p db.ListOrganisationPaymentsRow) int64 {
+	return int64(p.AmountUnits) - int64(p.FeeUnits.Int32) - int64(p.RefundedUnits)
+}
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/payment.go -pkg servicemocks . PaymentService
+
+// PaymentService defines the interface for payment business logic.
+type PaymentService interface {
+	// SettledPayment returns the successful payment for a registration, or
+	// repository.ErrNotFound if the registration was never paid for.
+	SettledPayment(ctx context.Context, registrationID int64) (db.Payment, error)
+	// Refund returns amountUnits of the payment to the entrant.
+	Refund(ctx context.Context, payment db.Payment, amountUnits int32) error
+	// RecordCharge records a charge reported by the payment provider. The
+	// provider may report a charge more than once, and out of order, so
+	// recording it again only ever adds what is new, such as a refund.
+	RecordCharge(ctx context.Context, charge ProviderCharge) (db.Payment, error)
+	// Report returns the payments taken for the organisation's events from
+	// from up to until.
+	Report(ctx context.Context, organisationID int64, from, until time.Time) (PaymentReport, error)
+}
+
+type paymentService struct {
+	paymentRepo repository.PaymentRepository
+}
+
+// NewPaymentService creates a new PaymentService with the given repository.
+func NewPaymentService(paymentRepo repository.PaymentRepository) PaymentService {
+	return &paymentService{paymentRepo: paymentRepo}
+}
+
+func (s *paymentService) SettledPayment(ctx context.Context, registrationID int64) (db.Payment, error) {
+	return s.paymentRepo.GetSettledByRegistration(ctx, registrationID)
+}
+
+func (s *paymentService) Refund(ctx context.Context, payment db.Payment, amountUnits int32) error {
+	if amountUnits <= 0 || amountUnits > payment.AmountUnits-payment.RefundedUnits {
+		return fmt.Errorf("%w: refund amount out of range", ErrInvalidInput)
+	}
+
+	refunded := payment.RefundedUnits + amountUnits
+	status := db.PaymentStatusPartiallyRefunded
+	if refunded == payment.AmountUnits {
+		status = db.PaymentStatusRefunded
+	}
+
+	if err := s.paymentRepo.RecordRefund(ctx, db.RecordPaymentRefundParams{
+		ID:            payment.ID,
+		Status:        status,
+		RefundedUnits: refunded,
+	}); err != nil {
+		return fmt.Errorf("failed to record refund: %w", err)
+	}
+	return nil
+}
+
+func (s *paymentService) RecordCharge(ctx context.Context, charge ProviderCharge) (db.Payment, error) {
+	if err := charge.Validate(); err != nil {
+		return db.Payment{}, err
+	}
+
+	var fee pgtype.Int4
+	if charge.FeeUnits != nil {
+		fee = pgtype.Int4{Int32: int32(*charge.FeeUnits), Valid: true}
+	}
+	payment, err := s.paymentRepo.RecordProviderPayment(ctx, db.UpsertProviderPaymentParams{
+		RegistrationID:    charge.RegistrationID,
+		AmountUnits:       int32(charge.AmountUnits),
+		Currency:          strings.ToUpper(charge.Currency),
+		Status:            charge.status(),
+		ProviderReference: pgtype.Text{String: charge.Reference, Valid: true},
+		FeeUnits:          fee,
+		RefundedUnits:     int32(charge.RefundedUnits),
+		CreatedAt:         pgtype.Timestamptz{Time: charge.CreatedAt, Valid: true},
+	})
+	if err != nil {
+		return db.Payment{}, fmt.Errorf("failed to record charge %s: %w", charge.Reference, err)
+	}
+	return payment, nil
+}
+
+func (s *paymentService) Report(ctx context.Context, organisationID int64, from, until time.Time) (PaymentReport, error) {
+	if !until.After(from) {
+		return PaymentReport{}, fmt.Errorf("%w: the report must end after it starts", ErrInvalidInput)
+	}
+	payments, err := s.paymentRepo.ListForOrganisation(ctx, organisationID, from, until)
+	if err != nil {
+		return PaymentReport{}, err
+	}
+
+	report := PaymentReport{Payments: payments}
+	totals := map[string]*PaymentTotals{}
+	for _, p := range payments {
+		t, ok := totals[p.Currency]
+		if !ok {
+			t = &PaymentTotals{Currency: p.Currency}
+			totals[p.Currency] = t
+		}
+		t.Gross += int64(p.AmountUnits)
+		t.Fees += int64(p.FeeUnits.Int32)
+		t.Refunded += int64(p.RefundedUnits)
+		t.Net += PaymentNet(p)
+	}
+	for _, t := range totals {
+		report.Totals = append(report.Totals, *t)
+	}
+	slices.SortFunc(report.Totals, func(a, b PaymentTotals) int { return cmp.Compare(a.Currency, b.Currency) })
+	return report, nil
+}
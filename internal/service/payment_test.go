@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+)
+
+func TestRefundAmount(t *testing.T) {
+	closeDate := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		amount int32
+		now    time.Time
+		close  time.Time
+		want   int32
+	}{
+		{name: "full refund more than 30 days out", amount: 6500, now: closeDate.Add(-31 * 24 * time.Hour), close: closeDate, want: 6500},
+		{name: "full refund one second beyond 30 days", amount: 6500, now: closeDate.Add(-FullRefundNotice - time.Second), close: closeDate, want: 6500},
+		{name: "half refund exactly 30 days out", amount: 6500, now: closeDate.Add(-FullRefundNotice), close: closeDate, want: 3250},
+		{name: "half refund within 30 days", amount: 6500, now: closeDate.Add(-24 * time.Hour), close: closeDate, want: 3250},
+		{name: "half refund after close", amount: 6500, now: closeDate.Add(time.Hour), close: closeDate, want: 3250},
+		{name: "rounds partial refunds down", amount: 6499, now: closeDate, close: closeDate, want: 3249},
+		{name: "full refund without a close date", amount: 6500, now: closeDate, close: time.Time{}, want: 6500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RefundAmount(tt.amount, tt.now, tt.close); got != tt.want {
+				t.Errorf("RefundAmount(%d) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentService_Refund(t *testing.T) {
+	t.Run("marks a full refund as refunded", func(t *testing.T) {
+		var got db.RecordPaymentRefundParams
+		repo := &repositorymocks.PaymentRepositoryMock{
+			RecordRefundFunc: func(ctx context.Context, params db.RecordPaymentRefundParams) error {
+				got = params
+				return nil
+			},
+		}
+
+		svc := NewPaymentService(repo)
+		err := svc.Refund(context.Background(), db.Payment{ID: 4, AmountUnits: 6500}, 6500)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Status != db.PaymentStatusRefunded || got.RefundedUnits != 6500 {
+			t.Errorf("unexpected refund params: %+v", got)
+		}
+	})
+
+	t.Run("marks a partial refund as partially refunded", func(t *testing.T) {
+		var got db.RecordPaymentRefundParams
+		repo := &repositorymocks.PaymentRepositoryMock{
+			RecordRefundFunc: func(ctx context.Context, params db.RecordPaymentRefundParams) error {
+				got = params
+				return nil
+			},
+		}
+
+		svc := NewPaymentService(repo)
+		err := svc.Refund(context.Background(), db.Payment{ID: 4, AmountUnits: 6500}, 3250)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Status != db.PaymentStatusPartiallyRefunded || got.RefundedUnits != 3250 {
+			t.Errorf("unexpected refund params: %+v", got)
+		}
+	})
+
+	t.Run("rejects refunds larger than the amount paid", func(t *testing.T) {
+		svc := NewPaymentService(&repositorymocks.PaymentRepositoryMock{})
+
+		err := svc.Refund(context.Background(), db.Payment{AmountUnits: 6500}, 6501)
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestPaymentService_RecordCharge(t *testing.T) {
+	fee := int64(120)
+	charge := ProviderCharge{
+		Reference:      "ch_1",
+		RegistrationID: 11,
+		AmountUnits:    6500,
+		Currency:       "gbp",
+		FeeUnits:       &fee,
+		CreatedAt:      time.Date(2025, 10, 9, 9, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name     string
+		refunded int64
+		failed   bool
+		want     db.PaymentStatus
+	}{
+		{name: "records a paid charge as succeeded", want: db.PaymentStatusSucceeded},
+		{name: "records a partial refund", refunded: 3250, want: db.PaymentStatusPartiallyRefunded},
+		{name: "records a full refund", refunded: 6500, want: db.PaymentStatusRefunded},
+		{name: "records a failed charge", failed: true, want: db.PaymentStatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got db.UpsertProviderPaymentParams
+			repo := &repositorymocks.PaymentRepositoryMock{
+				RecordProviderPaymentFunc: func(ctx context.Context, params db.UpsertProviderPaymentParams) (db.Payment, error) {
+					got = params
+					return db.Payment{}, nil
+				},
+			}
+			c := charge
+			c.RefundedUnits, c.Failed = tt.refunded, tt.failed
+
+			if _, err := NewPaymentService(repo).RecordCharge(context.Background(), c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Status != tt.want || got.RefundedUnits != int32(tt.refunded) {
+				t.Errorf("expected %s with %d refunded, got %+v", tt.want, tt.refunded, got)
+			}
+			if got.Currency != "GBP" || got.FeeUnits.Int32 != 120 || got.ProviderReference.String != "ch_1" {
+				t.Errorf("unexpected params: %+v", got)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name   string
+		change func(c *ProviderCharge)
+	}{
+		{name: "without a reference", change: func(c *ProviderCharge) { c.Reference = "" }},
+		{name: "with a bad currency", change: func(c *ProviderCharge) { c.Currency = "pounds" }},
+		{name: "refunding more than was paid", change: func(c *ProviderCharge) { c.RefundedUnits = 6501 }},
+		{name: "too large to store", change: func(c *ProviderCharge) { c.AmountUnits = 1 << 31 }},
+		{name: "with a negative fee", change: func(c *ProviderCharge) { n := int64(-1); c.FeeUnits = &n }},
+	}
+	for _, tt := range invalid {
+		t.Run("rejects a charge "+tt.name, func(t *testing.T) {
+			c := charge
+			tt.change(&c)
+
+			_, err := NewPaymentService(&repositorymocks.PaymentRepositoryMock{}).RecordCharge(context.Background(), c)
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPaymentService_Report(t *testing.T) {
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	row := func(currency string, amount, fee, refunded int32, feeKnown bool) db.ListOrganisationPaymentsRow {
+		return db.ListOrganisationPaymentsRow{
+			Currency:      currency,
+			AmountUnits:   amount,
+			FeeUnits:      pgtype.Int4{Int32: fee, Valid: feeKnown},
+			RefundedUnits: refunded,
+		}
+	}
+	repo := &repositorymocks.PaymentRepositoryMock{
+		ListForOrganisationFunc: func(ctx context.Context, organisationID int64, from, until time.Time) ([]db.ListOrganisationPaymentsRow, error) {
+			return []db.ListOrganisationPaymentsRow{
+				row("GBP", 6500, 120, 3250, true),
+				row("EUR", 2500, 0, 0, false),
+				row("GBP", 4000, 80, 0, true),
+			}, nil
+		},
+	}
+
+	report, err := NewPaymentService(repo).Report(context.Background(), 7, from, from.AddDate(0, 1, 0))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []PaymentTotals{
+		{Currency: "EUR", Gross: 2500, Net: 2500},
+		{Currency: "GBP", Gross: 10500, Fees: 200, Refunded: 3250, Net: 7050},
+	}
+	if !slices.Equal(report.Totals, want) {
+		t.Errorf("expected totals %+v, got %+v", want, report.Totals)
+	}
+
+	t.Run("rejects a range that ends before it starts", func(t *testing.T) {
+		_, err := NewPaymentService(repo).Report(context.Background(), 7, from, from)
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
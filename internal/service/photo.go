@@ -0,0 +1,230 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // registers PNG with image.Decode
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/imaging"
+	"firecrest/internal/repository"
+	"firecrest/internal/storage"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/photo.go -pkg servicemocks . PhotoService
+
+// Photo upload limits and the size of the copies event pages show.
+const (
+	MaxPhotoBytes = 10 << 20
+	// MaxPhotoPixels bounds an image's width times height, so a small
+	// file cannot decode to more memory than the server has
+	MaxPhotoPixels = 50_000_000
+	// PhotoWebSize is the longest side of a photo's web copy, in pixels
+	PhotoWebSize = 1600
+	// photoWebQuality is the JPEG quality web copies are saved at
+	photoWebQuality = 82
+)
+
+// photoTypes maps the content types photos may have, as sniffed from
+// their first bytes, to the extension their originals are stored with.
+var photoTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// PhotoService defines the interface for event photo business logic.
+//
+// Organisers upload photos to an event's gallery. The original is kept in
+// the blob store alongside a resized JPEG copy, which is what event pages
+// show.
+type PhotoService interface {
+	// UploadPhoto adds the image read from r to the end of the event's
+	// gallery. Files larger than MaxPhotoBytes or MaxPhotoPixels, that are
+	// not JPEG or PNG, or that cannot be decoded are refused with
+	// FieldErrors for "photo".
+	UploadPhoto(ctx context.Context, eventID int64, r io.Reader) (db.EventPhoto, error)
+	// ListPhotos returns the event's photos in gallery order.
+	ListPhotos(ctx context.Context, eventID int64) ([]db.EventPhoto, error)
+	// GetPhoto returns the event's photo. It returns repository.ErrNotFound
+	// if the event has no such photo.
+	GetPhoto(ctx context.Context, eventID, photoID int64) (db.EventPhoto, error)
+	// DeletePhoto removes the event's photo and its files. It returns
+	// repository.ErrNotFound if the event has no such photo.
+	DeletePhoto(ctx context.Context, eventID, photoID int64) error
+	// MovePhoto moves the event's photo offset places along the gallery,
+	// earlier for a negative offset, stopping at either end. It returns
+	// repository.ErrNotFound if the event has no such photo.
+	MovePhoto(ctx context.Context, eventID, photoID int64, offset int) error
+	// PhotoURL returns a URL the photo's web copy can be fetched from for
+	// the next PhotoURLTTL.
+	PhotoURL(ctx context.Context, photo db.EventPhoto) (string, error)
+}
+
+// PhotoURLTTL is how long the URLs PhotoURL returns work for.
+const PhotoURLTTL = time.Hour
+
+type photoService struct {
+	photoRepo repository.PhotoRepository
+	store     storage.BlobStore
+}
+
+// NewPhotoService creates a new PhotoService keeping records in photoRepo
+// and files in store.
+func NewPhotoService(photoRepo repository.PhotoRepository, store storage.BlobStore) PhotoService {
+	return &photoService{photoRepo: photoRepo, store: store}
+}
+
+func (s *photoService) UploadPhoto(ctx context.Context, eventID int64, r io.Reader) (db.EventPhoto, error) {
+	if eventID < 1 {
+		return db.EventPhoto{}, fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+
+	upload, err := readImage(r, "photo")
+	if err != nil {
+		return db.EventPhoto{}, err
+	}
+
+	web := imaging.Fit(upload.img, PhotoWebSize)
+	var webJPEG bytes.Buffer
+	if err := jpeg.Encode(&webJPEG, web, &jpeg.Options{Quality: photoWebQuality}); err != nil {
+		return db.EventPhoto{}, fmt.Errorf("failed to encode web copy: %w", err)
+	}
+
+	dir := fmt.Sprintf("events/%d/photos/%s/", eventID, rand.Text())
+	params := db.CreateEventPhotoParams{
+		EventID:     eventID,
+		OriginalKey: dir + "original" + upload.ext,
+		WebKey:      dir + "web.jpg",
+		Width:       int32(web.Bounds().Dx()),
+		Height:      int32(web.Bounds().Dy()),
+	}
+	if err := s.store.Put(ctx, params.OriginalKey, bytes.NewReader(upload.raw), upload.contentType); err != nil {
+		return db.EventPhoto{}, fmt.Errorf("failed to store photo: %w", err)
+	}
+	if err := s.store.Put(ctx, params.WebKey, &webJPEG, "image/jpeg"); err != nil {
+		return db.EventPhoto{}, errors.Join(fmt.Errorf("failed to store web copy: %w", err), deleteFiles(ctx, s.store, params.OriginalKey))
+	}
+
+	photo, err := s.photoRepo.Create(ctx, params)
+	if err != nil {
+		return db.EventPhoto{}, errors.Join(err, deleteFiles(ctx, s.store, params.OriginalKey, params.WebKey))
+	}
+	return photo, nil
+}
+
+func (s *photoService) ListPhotos(ctx context.Context, eventID int64) ([]db.EventPhoto, error) {
+	return s.photoRepo.ListByEvent(ctx, eventID)
+}
+
+func (s *photoService) GetPhoto(ctx context.Context, eventID, photoID int64) (db.EventPhoto, error) {
+	photo, err := s.photoRepo.Get(ctx, photoID)
+	if err != nil {
+		return db.EventPhoto{}, err
+	}
+	// Another event's photo is as good as missing
+	if photo.EventID != eventID {
+		return db.EventPhoto{}, repository.ErrNotFound
+	}
+	return photo, nil
+}
+
+func (s *photoService) DeletePhoto(ctx context.Context, eventID, photoID int64) error {
+	photo, err := s.GetPhoto(ctx, eventID, photoID)
+	if err != nil {
+		return err
+	}
+	// The files go first, so a failure leaves the photo listed to delete
+	// again rather than files nothing points at
+	if err := deleteFiles(ctx, s.store, photo.OriginalKey, photo.WebKey); err != nil {
+		return err
+	}
+	return s.photoRepo.Delete(ctx, photo.ID)
+}
+
+func (s *photoService) MovePhoto(ctx context.Context, eventID, photoID int64, offset int) error {
+	photos, err := s.photoRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	from := slices.IndexFunc(photos, func(p db.EventPhoto) bool { return p.ID == photoID })
+	if from < 0 {
+		return repository.ErrNotFound
+	}
+	to := min(max(from+offset, 0), len(photos)-1)
+	if to == from {
+		return nil
+	}
+
+	moved := photos[from]
+	photos = slices.Insert(slices.Delete(photos, from, from+1), to, moved)
+	ids := make([]int64, len(photos))
+	for i, p := range photos {
+		ids[i] = p.ID
+	}
+	return s.photoRepo.SetOrder(ctx, eventID, ids)
+}
+
+func (s *photoService) PhotoURL(ctx context.Context, photo db.EventPhoto) (string, error) {
+	return s.store.SignedURL(ctx, photo.WebKey, PhotoURLTTL)
+}
+
+// uploadedImage is an image read by readImage.
+type uploadedImage struct {
+	raw         []byte
+	contentType string
+	// ext is the extension the file is stored with
+	ext string
+	img image.Image
+}
+
+// readImage reads and decodes an uploaded JPEG or PNG image. Files larger
+// than MaxPhotoBytes or MaxPhotoPixels, of another type, or that cannot be
+// decoded are refused with FieldErrors for field.
+func readImage(r io.Reader, field string) (uploadedImage, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, MaxPhotoBytes+1))
+	if err != nil {
+		return uploadedImage{}, err
+	}
+	if len(raw) > MaxPhotoBytes {
+		return uploadedImage{}, FieldErrors{field: fmt.Sprintf("%s must be at most %d MB", field, MaxPhotoBytes>>20)}
+	}
+	contentType := http.DetectContentType(raw)
+	ext, ok := photoTypes[contentType]
+	if !ok {
+		return uploadedImage{}, FieldErrors{field: field + " must be a JPEG or PNG image"}
+	}
+
+	// The size is read from the header before the image is decoded
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return uploadedImage{}, FieldErrors{field: field + " could not be read as an image"}
+	}
+	if cfg.Width*cfg.Height > MaxPhotoPixels {
+		return uploadedImage{}, FieldErrors{field: fmt.Sprintf("%s must be at most %d megapixels", field, MaxPhotoPixels/1_000_000)}
+	}
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return uploadedImage{}, FieldErrors{field: field + " could not be read as an image"}
+	}
+	return uploadedImage{raw: raw, contentType: contentType, ext: ext, img: img}, nil
+}
+
+// deleteFiles removes the files under keys from store.
+func deleteFiles(ctx context.Context, store storage.BlobStore, keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
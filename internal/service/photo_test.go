@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+	"firecrest/internal/storage"
+)
+
+func newTestStore(t *testing.T) *storage.LocalStore {
+	t.Helper()
+	store, err := storage.NewLocalStore(t.TempDir(), "/media", []byte("signing-key"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	return store
+}
+
+// testPNG returns a w by h PNG filled with one colour.
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPhotoService_UploadPhoto(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps the original and a resized web copy", func(t *testing.T) {
+		store := newTestStore(t)
+		photoRepo := &repositorymocks.PhotoRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateEventPhotoParams) (db.EventPhoto, error) {
+				return db.EventPhoto{ID: 1, EventID: params.EventID, OriginalKey: params.OriginalKey, WebKey: params.WebKey, Width: params.Width, Height: params.Height}, nil
+			},
+		}
+		svc := NewPhotoService(photoRepo, store)
+		original := testPNG(t, 3200, 2400)
+
+		photo, err := svc.UploadPhoto(ctx, 4, bytes.NewReader(original))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !strings.HasPrefix(photo.OriginalKey, "events/4/photos/") || !strings.HasSuffix(photo.OriginalKey, "/original.png") {
+			t.Errorf("unexpected original key %q", photo.OriginalKey)
+		}
+		if photo.Width != PhotoWebSize || photo.Height != 1200 {
+			t.Errorf("expected a %dx1200 web copy, got %dx%d", PhotoWebSize, photo.Width, photo.Height)
+		}
+		if got := readStored(t, store, photo.OriginalKey); !bytes.Equal(got, original) {
+			t.Error("expected the original to be stored as uploaded")
+		}
+		web, err := jpeg.Decode(bytes.NewReader(readStored(t, store, photo.WebKey)))
+		if err != nil {
+			t.Fatalf("expected the web copy to be a JPEG: %v", err)
+		}
+		if b := web.Bounds(); b.Dx() != PhotoWebSize || b.Dy() != 1200 {
+			t.Errorf("expected the web copy to be %dx1200, got %v", PhotoWebSize, b)
+		}
+	})
+
+	t.Run("removes the files when the photo cannot be recorded", func(t *testing.T) {
+		store := newTestStore(t)
+		var keys []string
+		photoRepo := &repositorymocks.PhotoRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateEventPhotoParams) (db.EventPhoto, error) {
+				keys = []string{params.OriginalKey, params.WebKey}
+				return db.EventPhoto{}, repository.ErrConflict
+			},
+		}
+		svc := NewPhotoService(photoRepo, store)
+
+		if _, err := svc.UploadPhoto(ctx, 4, bytes.NewReader(testPNG(t, 10, 10))); !errors.Is(err, repository.ErrConflict) {
+			t.Fatalf("expected ErrConflict, got %v", err)
+		}
+		for _, key := range keys {
+			if _, err := store.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+				t.Errorf("expected %s to be removed, got %v", key, err)
+			}
+		}
+	})
+
+	corrupt, err := os.ReadFile("testdata/corrupt.jpg")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var gif bytes.Buffer
+	gif.WriteString("GIF89a")
+	gif.Write(make([]byte, 32))
+
+	tests := []struct {
+		name string
+		file []byte
+		want string
+	}{
+		{name: "refuses corrupt images", file: corrupt, want: "photo could not be read as an image"},
+		{name: "refuses other file types", file: gif.Bytes(), want: "photo must be a JPEG or PNG image"},
+		{name: "refuses text", file: []byte("name,email\n"), want: "photo must be a JPEG or PNG image"},
+		{name: "refuses large files", file: append(testPNG(t, 1, 1), make([]byte, MaxPhotoBytes)...), want: "photo must be at most 10 MB"},
+		{name: "refuses images with too many pixels", file: pngHeader(t, 10000, 10000), want: "photo must be at most 50 megapixels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			photoRepo := &repositorymocks.PhotoRepositoryMock{}
+			svc := NewPhotoService(photoRepo, newTestStore(t))
+
+			_, err := svc.UploadPhoto(ctx, 4, bytes.NewReader(tt.file))
+
+			var fieldErrs FieldErrors
+			if !errors.As(err, &fieldErrs) {
+				t.Fatalf("expected FieldErrors, got %v", err)
+			}
+			if got := fieldErrs["photo"]; got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if len(photoRepo.CreateCalls()) != 0 {
+				t.Error("expected no photo to be recorded")
+			}
+		})
+	}
+}
+
+// pngHeader returns the start of a w by h PNG, enough for its size to be
+// read but not its pixels.
+func pngHeader(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewPaletted(image.Rect(0, 0, w, h), color.Palette{color.Black})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()[:64]
+}
+
+func readStored(t *testing.T, store storage.BlobStore, key string) []byte {
+	t.Helper()
+	rc, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", key, err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", key, err)
+	}
+	return b
+}
+
+func TestPhotoService_DeletePhoto(t *testing.T) {
+	ctx := context.Background()
+	photo := db.EventPhoto{ID: 9, EventID: 4, OriginalKey: "events/4/photos/a/original.jpg", WebKey: "events/4/photos/a/web.jpg"}
+
+	t.Run("removes the files and the record", func(t *testing.T) {
+		store := newTestStore(t)
+		for _, key := range []string{photo.OriginalKey, photo.WebKey} {
+			if err := store.Put(ctx, key, strings.NewReader("jpeg"), "image/jpeg"); err != nil {
+				t.Fatalf("failed to store %s: %v", key, err)
+			}
+		}
+		photoRepo := &repositorymocks.PhotoRepositoryMock{
+			GetFunc: func(ctx context.Context, photoID int64) (db.EventPhoto, error) {
+				return photo, nil
+			},
+		}
+		svc := NewPhotoService(photoRepo, store)
+
+		if err := svc.DeletePhoto(ctx, 4, 9); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if calls := photoRepo.DeleteCalls(); len(calls) != 1 || calls[0].PhotoID != 9 {
+			t.Errorf("expected photo 9 to be deleted, got %+v", calls)
+		}
+		if _, err := store.Get(ctx, photo.WebKey); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("expected the web copy to be removed, got %v", err)
+		}
+	})
+
+	t.Run("treats another event's photo as missing", func(t *testing.T) {
+		photoRepo := &repositorymocks.PhotoRepositoryMock{
+			GetFunc: func(ctx context.Context, photoID int64) (db.EventPhoto, error) {
+				return photo, nil
+			},
+		}
+		svc := NewPhotoService(photoRepo, newTestStore(t))
+
+		if err := svc.DeletePhoto(ctx, 5, 9); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if len(photoRepo.DeleteCalls()) != 0 {
+			t.Error("expected nothing to be deleted")
+		}
+	})
+}
+
+func TestPhotoService_MovePhoto(t *testing.T) {
+	photos := []db.EventPhoto{{ID: 1, EventID: 4}, {ID: 2, EventID: 4}, {ID: 3, EventID: 4}}
+
+	tests := []struct {
+		name    string
+		photoID int64
+		offset  int
+		want    []int64
+	}{
+		{name: "moves a photo earlier", photoID: 3, offset: -1, want: []int64{1, 3, 2}},
+		{name: "moves a photo later", photoID: 1, offset: 1, want: []int64{2, 1, 3}},
+		{name: "stops at the end", photoID: 2, offset: 5, want: []int64{1, 3, 2}},
+		{name: "leaves the first photo first", photoID: 1, offset: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			photoRepo := &repositorymocks.PhotoRepositoryMock{
+				ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.EventPhoto, error) {
+					return slices.Clone(photos), nil
+				},
+			}
+			svc := NewPhotoService(photoRepo, newTestStore(t))
+
+			if err := svc.MovePhoto(context.Background(), 4, tt.photoID, tt.offset); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			calls := photoRepo.SetOrderCalls()
+			if tt.want == nil {
+				if len(calls) != 0 {
+					t.Errorf("expected the order to be left alone, got %+v", calls)
+				}
+				return
+			}
+			if len(calls) != 1 || !slices.Equal(calls[0].PhotoIDs, tt.want) {
+				t.Errorf("expected order %v, got %+v", tt.want, calls)
+			}
+		})
+	}
+
+	t.Run("returns ErrNotFound for a photo the event does not have", func(t *testing.T) {
+		photoRepo := &repositorymocks.PhotoRepositoryMock{
+			ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.EventPhoto, error) {
+				return slices.Clone(photos), nil
+			},
+		}
+		svc := NewPhotoService(photoRepo, newTestStore(t))
+
+		if err := svc.MovePhoto(context.Background(), 4, 99, 1); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
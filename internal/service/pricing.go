@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/repository"
+)
+
+// MaxPriceTierNameLength is the longest price tier name accepted, in
+// characters.
+const MaxPriceTierNameLength = 50
+
+// maxPriceAttempts bounds how many times an entry is priced again when the
+// tier it was priced at fills before it is made.
+const maxPriceAttempts = 3
+
+// PriceTierInput is a price tier as an organiser enters it.
+type PriceTierInput struct {
+	Name       string
+	PriceUnits int32
+	// ValidFrom and ValidTo bound when the tier prices entries, as the
+	// organiser's local times in the event's time zone; only their date
+	// and clock are read. Zero leaves that end open.
+	ValidFrom time.Time
+	ValidTo   time.Time
+	// Capacity limits the tier to its first entries. Zero means any number.
+	Capacity int
+}
+
+// Validate checks if the input is valid, reporting every problem as
+// FieldErrors.
+func (i PriceTierInput) Validate() error {
+	errs := FieldErrors{}
+	switch name := strings.TrimSpace(i.Name); {
+	case name == "":
+		errs.Add("name", "name is required")
+	case utf8.RuneCountInString(name) > MaxPriceTierNameLength:
+		errs.Add("name", fmt.Sprintf("name must be at most %d characters", MaxPriceTierNameLength))
+	}
+	if i.PriceUnits < 0 {
+		errs.Add("price", "price must not be negative")
+	}
+	if i.Capacity < 0 {
+		errs.Add("capacity", "entries must be at least 1, or blank")
+	}
+	switch {
+	case i.ValidTo.IsZero() && i.Capacity == 0:
+		errs.Add("valid_to", "a tier must end on a date, after a number of entries, or both")
+	case !i.ValidTo.IsZero() && !i.ValidFrom.IsZero() && !i.ValidTo.After(i.ValidFrom):
+		errs.Add("valid_to", "valid to must be after valid from")
+	}
+	return errs.Err()
+}
+
+// PriceTier is one of a race's price tiers with how many active entries
+// were made at it.
+type PriceTier struct {
+	Tier  db.RacePriceTier
+	Taken int
+}
+
+// capped reports whether the tier is limited to its first entries.
+func (t PriceTier) capped() bool {
+	return t.Tier.Capacity.Valid
+}
+
+// appliesAt reports whether the tier prices entries made at at: it has
+// begun but not yet ended, and has places left if it is capped. A tier
+// ends at the instant of its valid_to.
+func (t PriceTier) appliesAt(at time.Time) bool {
+	if t.Tier.ValidFrom.Valid && at.Before(t.Tier.ValidFrom.Time) {
+		return false
+	}
+	if t.Tier.ValidTo.Valid && !at.Before(t.Tier.ValidTo.Time) {
+		return false
+	}
+	return !t.capped() || t.Taken < int(t.Tier.Capacity.Int32)
+}
+
+// overlaps reports whether the tier's dates share any instant with
+// other's, open ends reaching forever.
+func (t PriceTier) overlaps(other db.RacePriceTier) bool {
+	before := func(from, to pgtype.Timestamptz) bool {
+		return !from.Valid || !to.Valid || from.Time.Before(to.Time)
+	}
+	return before(t.Tier.ValidFrom, other.ValidTo) && before(other.ValidFrom, t.Tier.ValidTo)
+}
+
+// Price is what an entry to a race costs at a moment.
+type Price struct {
+	Units int32
+	// Tier is the tier the price comes from, or nil for the race's own
+	// price
+	Tier *db.RacePriceTier
+	// PlacesLeft is how many more entries a capped tier takes at the price
+	PlacesLeft int
+	// Next is the price that follows, if it differs: from Until or, when
+	// Until is zero, once the tier's places are gone
+	Next  *Price
+	Until time.Time
+}
+
+// ResolvePrice returns what an entry to race costs at now given its price
+// tiers, earliest first, and what the price becomes next. A capped tier
+// takes precedence over an uncapped one it overlaps while it has places;
+// outside every tier the race's own price applies.
+func ResolvePrice(race db.Race, tiers []PriceTier, now time.Time) Price {
+	price := priceAt(race, tiers, now, 0)
+
+	// The price next changes when its tier ends or another begins
+	var until time.Time
+	if price.Tier != nil && price.Tier.ValidTo.Valid {
+		until = price.Tier.ValidTo.Time
+	}
+	for _, t := range tiers {
+		if from := t.Tier.ValidFrom; from.Valid && from.Time.After(now) && (until.IsZero() || from.Time.Before(until)) {
+			until = from.Time
+		}
+	}
+
+	var next Price
+	switch {
+	case !until.IsZero():
+		next = priceAt(race, tiers, until, 0)
+	case price.Tier != nil && price.PlacesLeft > 0:
+		next = priceAt(race, tiers, now, price.Tier.ID)
+	default:
+		return price
+	}
+	if next.Units != price.Units {
+		price.Next, price.Until = &next, until
+	}
+	return price
+}
+
+// priceAt returns the price at at, leaving out the tier with the ID skip.
+func priceAt(race db.Race, tiers []PriceTier, at time.Time, skip int64) Price {
+	var found *PriceTier
+	for i, t := range tiers {
+		if t.Tier.ID == skip || !t.appliesAt(at) {
+			continue
+		}
+		if found == nil || (t.capped() && !found.capped()) {
+			found = &tiers[i]
+		}
+	}
+	if found == nil {
+		return Price{Units: race.PriceUnits.Int32}
+	}
+
+	tier := found.Tier
+	price := Price{Units: tier.PriceUnits, Tier: &tier}
+	if found.capped() {
+		price.PlacesLeft = int(tier.Capacity.Int32) - found.Taken
+	}
+	return price
+}
+
+// listPriceTiers returns the price tiers of the races by race, earliest
+// first.
+func listPriceTiers(ctx context.Context, raceRepo repository.RaceRepository, raceIDs []int64) (map[int64][]PriceTier, error) {
+	rows, err := raceRepo.ListPriceTiers(ctx, raceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price tiers: %w", err)
+	}
+	byRace := make(map[int64][]PriceTier, len(raceIDs))
+	for _, row := range rows {
+		byRace[row.RacePriceTier.RaceID] = append(byRace[row.RacePriceTier.RaceID], PriceTier{
+			Tier:  row.RacePriceTier,
+			Taken: int(row.Taken),
+		})
+	}
+	return byRace, nil
+}
+
+// currentPrice returns what an entry to race costs at now.
+func currentPrice(ctx context.Context, raceRepo repository.RaceRepository, race db.Race, now time.Time) (Price, error) {
+	tiers, err := listPriceTiers(ctx, raceRepo, []int64{race.ID})
+	if err != nil {
+		return Price{}, err
+	}
+	return ResolvePrice(race, tiers[race.ID], now), nil
+}
+
+func (s *raceService) ListPriceTiers(ctx context.Context, raceID int64) ([]PriceTier, error) {
+	tiers, err := listPriceTiers(ctx, s.raceRepo, []int64{raceID})
+	if err != nil {
+		return nil, err
+	}
+	return tiers[raceID], nil
+}
+
+func (s *raceService) CreatePriceTier(ctx context.Context, event db.Event, raceID int64, input PriceTierInput) (db.RacePriceTier, error) {
+	if err := input.Validate(); err != nil {
+		return db.RacePriceTier{}, err
+	}
+	loc := EventLocation(event)
+	tier := PriceTier{Tier: db.RacePriceTier{
+		RaceID:     raceID,
+		Name:       strings.TrimSpace(input.Name),
+		PriceUnits: input.PriceUnits,
+		ValidFrom:  localTimestamptz(input.ValidFrom, loc),
+		ValidTo:    localTimestamptz(input.ValidTo, loc),
+		Capacity:   pgtype.Int4{Int32: int32(input.Capacity), Valid: input.Capacity > 0},
+	}}
+
+	// Only a capped tier may overlap an uncapped one, so one price always
+	// wins at any instant
+	existing, err := s.ListPriceTiers(ctx, raceID)
+	if err != nil {
+		return db.RacePriceTier{}, err
+	}
+	for _, other := range existing {
+		if other.capped() == tier.capped() && tier.overlaps(other.Tier) {
+			return db.RacePriceTier{}, FieldErrors{"valid_from": fmt.Sprintf("dates overlap the %s tier", other.Tier.Name)}
+		}
+	}
+
+	created, err := s.raceRepo.CreatePriceTier(ctx, db.CreateRacePriceTierParams{
+		RaceID:     raceID,
+		Name:       tier.Tier.Name,
+		PriceUnits: tier.Tier.PriceUnits,
+		ValidFrom:  tier.Tier.ValidFrom,
+		ValidTo:    tier.Tier.ValidTo,
+		Capacity:   tier.Tier.Capacity,
+	})
+	if err != nil {
+		return db.RacePriceTier{}, fmt.Errorf("failed to create price tier: %w", err)
+	}
+	return created, nil
+}
+
+func (s *raceService) DeletePriceTier(ctx context.Context, raceID, tierID int64) error {
+	return s.raceRepo.DeletePriceTier(ctx, raceID, tierID)
+}
+
+func (s *raceService) CurrentPrice(ctx context.Context, raceID int64, now time.Time) (Price, error) {
+	race, err := s.GetRaceByID(ctx, raceID)
+	if err != nil {
+		return Price{}, err
+	}
+	return currentPrice(ctx, s.raceRepo, race, now)
+}
+
+func (s *raceService) CurrentPrices(ctx context.Context, races []db.Race, now time.Time) (map[int64]Price, error) {
+	ids := make([]int64, len(races))
+	for i, race := range races {
+		ids[i] = race.ID
+	}
+	tiers, err := listPriceTiers(ctx, s.raceRepo, ids)
+	if err != nil {
+		return nil, err
+	}
+	prices := make(map[int64]Price, len(races))
+	for _, race := range races {
+		prices[race.ID] = ResolvePrice(race, tiers[race.ID], now)
+	}
+	return prices, nil
+}
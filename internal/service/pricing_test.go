@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestResolvePrice(t *testing.T) {
+	race := db.Race{ID: 20, PriceUnits: pgtype.Int4{Int32: 7500, Valid: true}}
+	at := func(t time.Time) pgtype.Timestamptz { return pgtype.Timestamptz{Time: t, Valid: true} }
+	march := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	earlyBird := PriceTier{Tier: db.RacePriceTier{ID: 1, Name: "Early bird", PriceUnits: 6500, ValidTo: at(march)}}
+	firstHundred := PriceTier{Tier: db.RacePriceTier{ID: 2, Name: "First 100", PriceUnits: 5500, Capacity: pgtype.Int4{Int32: 100, Valid: true}}}
+
+	tests := []struct {
+		name      string
+		tiers     []PriceTier
+		now       time.Time
+		wantUnits int32
+		wantTier  int64
+		wantNext  int32
+		wantUntil time.Time
+	}{
+		{name: "race price without tiers", now: march, wantUnits: 7500},
+		{name: "just before the tier ends", tiers: []PriceTier{earlyBird}, now: march.Add(-time.Nanosecond), wantUnits: 6500, wantTier: 1, wantNext: 7500, wantUntil: march},
+		{name: "at the instant the tier ends", tiers: []PriceTier{earlyBird}, now: march, wantUnits: 7500},
+		{
+			name:      "before a tier begins",
+			tiers:     []PriceTier{{Tier: db.RacePriceTier{ID: 3, Name: "Late", PriceUnits: 9000, ValidFrom: at(march)}}},
+			now:       march.Add(-time.Hour),
+			wantUnits: 7500, wantNext: 9000, wantUntil: march,
+		},
+		{
+			name:      "capped tier over an uncapped one",
+			tiers:     []PriceTier{earlyBird, firstHundred},
+			now:       march.Add(-time.Hour),
+			wantUnits: 5500, wantTier: 2, wantNext: 6500,
+		},
+		{
+			name:      "uncapped tier once the capped one is full",
+			tiers:     []PriceTier{earlyBird, {Tier: firstHundred.Tier, Taken: 100}},
+			now:       march.Add(-time.Hour),
+			wantUnits: 6500, wantTier: 1, wantNext: 7500, wantUntil: march,
+		},
+		{name: "capped tier until its places are gone", tiers: []PriceTier{{Tier: firstHundred.Tier, Taken: 88}}, now: march, wantUnits: 5500, wantTier: 2, wantNext: 7500},
+		{
+			name:      "no next price when it stays the same",
+			tiers:     []PriceTier{{Tier: db.RacePriceTier{ID: 4, Name: "Launch", PriceUnits: 7500, ValidTo: at(march)}}},
+			now:       march.Add(-time.Hour),
+			wantUnits: 7500, wantTier: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := ResolvePrice(race, tt.tiers, tt.now)
+
+			if price.Units != tt.wantUnits {
+				t.Errorf("Units = %d, want %d", price.Units, tt.wantUnits)
+			}
+			var gotTier int64
+			if price.Tier != nil {
+				gotTier = price.Tier.ID
+			}
+			if gotTier != tt.wantTier {
+				t.Errorf("Tier = %d, want %d", gotTier, tt.wantTier)
+			}
+			var gotNext int32
+			if price.Next != nil {
+				gotNext = price.Next.Units
+			}
+			if gotNext != tt.wantNext || !price.Until.Equal(tt.wantUntil) {
+				t.Errorf("next = %d from %v, want %d from %v", gotNext, price.Until, tt.wantNext, tt.wantUntil)
+			}
+		})
+	}
+
+	t.Run("counts the capped tier's places left", func(t *testing.T) {
+		price := ResolvePrice(race, []PriceTier{{Tier: firstHundred.Tier, Taken: 88}}, march)
+		if price.PlacesLeft != 12 {
+			t.Errorf("PlacesLeft = %d, want 12", price.PlacesLeft)
+		}
+	})
+}
+
+func TestRaceService_CreatePriceTier(t *testing.T) {
+	event := db.Event{ID: 30, Timezone: "Europe/London"}
+	march := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	existing := db.ListRacePriceTiersRow{RacePriceTier: db.RacePriceTier{
+		ID:         1,
+		RaceID:     20,
+		Name:       "Early bird",
+		PriceUnits: 6500,
+		ValidTo:    pgtype.Timestamptz{Time: march, Valid: true},
+	}}
+
+	newService := func(created *db.CreateRacePriceTierParams) RaceService {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListPriceTiersFunc: func(ctx context.Context, raceIDs []int64) ([]db.ListRacePriceTiersRow, error) {
+				return []db.ListRacePriceTiersRow{existing}, nil
+			},
+			CreatePriceTierFunc: func(ctx context.Context, params db.CreateRacePriceTierParams) (db.RacePriceTier, error) {
+				*created = params
+				return db.RacePriceTier{ID: 2, RaceID: params.RaceID, Name: params.Name}, nil
+			},
+		}
+		return NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+	}
+
+	t.Run("creates a tier after the others in the event's time zone", func(t *testing.T) {
+		var created db.CreateRacePriceTierParams
+		_, err := newService(&created).CreatePriceTier(context.Background(), event, 20, PriceTierInput{
+			Name:       " Standard ",
+			PriceUnits: 7000,
+			ValidFrom:  time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+			ValidTo:    time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC),
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 1 May is in summer time in London
+		wantTo := time.Date(2026, time.April, 30, 23, 0, 0, 0, time.UTC)
+		if created.Name != "Standard" || !created.ValidFrom.Time.Equal(march) || !created.ValidTo.Time.Equal(wantTo) || created.Capacity.Valid {
+			t.Errorf("unexpected tier %+v", created)
+		}
+	})
+
+	t.Run("refuses a tier overlapping another", func(t *testing.T) {
+		var created db.CreateRacePriceTierParams
+		_, err := newService(&created).CreatePriceTier(context.Background(), event, 20, PriceTierInput{
+			Name:       "Winter",
+			PriceUnits: 6000,
+			ValidTo:    time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+		})
+
+		var errs FieldErrors
+		if !errors.As(err, &errs) || errs["valid_from"] != "dates overlap the Early bird tier" {
+			t.Errorf("expected the overlap reported, got %v", err)
+		}
+	})
+
+	t.Run("lets a capped tier overlap an uncapped one", func(t *testing.T) {
+		var created db.CreateRacePriceTierParams
+		_, err := newService(&created).CreatePriceTier(context.Background(), event, 20, PriceTierInput{
+			Name:       "First 100",
+			PriceUnits: 5500,
+			Capacity:   100,
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !created.Capacity.Valid || created.Capacity.Int32 != 100 || created.ValidTo.Valid {
+			t.Errorf("unexpected tier %+v", created)
+		}
+	})
+
+	t.Run("requires an end", func(t *testing.T) {
+		var created db.CreateRacePriceTierParams
+		_, err := newService(&created).CreatePriceTier(context.Background(), event, 20, PriceTierInput{Name: "Forever", PriceUnits: 5000})
+
+		var errs FieldErrors
+		if !errors.As(err, &errs) || errs["valid_to"] == "" {
+			t.Errorf("expected valid_to reported, got %v", err)
+		}
+	})
+}
+
+func TestRegistrationService_RegisterPricing(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	race := db.Race{
+		ID:          20,
+		EventID:     30,
+		Name:        "10K",
+		MaxCapacity: 100,
+		PriceUnits:  pgtype.Int4{Int32: 7500, Valid: true},
+	}
+	earlyBird := db.RacePriceTier{ID: 5, RaceID: race.ID, Name: "First 100", PriceUnits: 5500, Capacity: pgtype.Int4{Int32: 100, Valid: true}}
+
+	newService := func(repo *repositorymocks.RegistrationRepositoryMock, raceRepo *repositorymocks.RaceRepositoryMock) *registrationService {
+		if repo.IsEmailVerifiedFunc == nil {
+			repo.IsEmailVerifiedFunc = func(ctx context.Context, userID int64) (bool, error) {
+				return true, nil
+			}
+		}
+		repo.GetActiveFunc = func(ctx context.Context, userID, raceID int64) (db.Registration, error) {
+			return db.Registration{}, repository.ErrNotFound
+		}
+		svc := NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, raceRepo, &mockPaymentService{}, &mockDiscountService{}, nil, &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 0, 100, BibRange{}).(*registrationService)
+		svc.clock = &MockClock{CurrentTime: now}
+		return svc
+	}
+
+	t.Run("charges the current tier's price", func(t *testing.T) {
+		var got db.CreateRegistrationParams
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				got = params
+				return db.Registration{ID: 100}, nil
+			},
+		}
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListPriceTiersFunc: func(ctx context.Context, raceIDs []int64) ([]db.ListRacePriceTiersRow, error) {
+				return []db.ListRacePriceTiersRow{{RacePriceTier: earlyBird, Taken: 40}}, nil
+			},
+		}
+
+		if _, err := newService(repo, raceRepo).Register(context.Background(), 7, race, 0, "", Answers{}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.PriceUnits.Int32 != 5500 || got.PriceTierID.Int64 != earlyBird.ID {
+			t.Errorf("expected the entry priced at the tier, got %+v", got)
+		}
+	})
+
+	t.Run("charges the next price when the tier fills mid-checkout", func(t *testing.T) {
+		// The last early-bird place goes to another entrant between pricing
+		// this entry and making it
+		taken := int64(99)
+		var got []db.CreateRegistrationParams
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				got = append(got, params)
+				if params.PriceTierID.Valid {
+					taken = 100
+					return db.Registration{}, repository.ErrPriceTierFull
+				}
+				return db.Registration{ID: 100, PriceUnits: params.PriceUnits}, nil
+			},
+		}
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListPriceTiersFunc: func(ctx context.Context, raceIDs []int64) ([]db.ListRacePriceTiersRow, error) {
+				return []db.ListRacePriceTiersRow{{RacePriceTier: earlyBird, Taken: taken}}, nil
+			},
+		}
+
+		reg, err := newService(repo, raceRepo).Register(context.Background(), 7, race, 0, "", Answers{}, nil)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0].PriceUnits.Int32 != 5500 {
+			t.Fatalf("expected the entry tried at the tier first, got %+v", got)
+		}
+		if reg.PriceUnits.Int32 != 7500 || got[1].PriceTierID.Valid {
+			t.Errorf("expected the entry made at the race's price, got %+v", got[1])
+		}
+	})
+
+	t.Run("gives up on a tier that keeps filling", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				return db.Registration{}, repository.ErrPriceTierFull
+			},
+		}
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListPriceTiersFunc: func(ctx context.Context, raceIDs []int64) ([]db.ListRacePriceTiersRow, error) {
+				return []db.ListRacePriceTiersRow{{RacePriceTier: earlyBird}}, nil
+			},
+		}
+
+		_, err := newService(repo, raceRepo).Register(context.Background(), 7, race, 0, "", Answers{}, nil)
+
+		if !errors.Is(err, repository.ErrPriceTierFull) {
+			t.Errorf("expected ErrPriceTierFull, got %v", err)
+		}
+		if n := len(repo.CreateCalls()); n != maxPriceAttempts {
+			t.Errorf("expected %d attempts, got %d", maxPriceAttempts, n)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"firecrest/internal/repository"
+	"firecrest/internal/secret"
+)
+
+// Question is one of the questions a race can require its entrants to
+// answer when they register.
+type Question string
+
+// Questionnaire questions. Answers to the medical ones are only shown to
+// organisers who can read medical details; see
+// OrganisationService.CanReadMedical.
+const (
+	QuestionEmergencyContactName  Question = "emergency_contact_name"
+	QuestionEmergencyContactPhone Question = "emergency_contact_phone"
+	QuestionMedicalConditions     Question = "medical_conditions"
+	QuestionClub                  Question = "club"
+	QuestionEstimatedFinish       Question = "estimated_finish"
+)
+
+// Questions lists every question, in the order forms ask them.
+var Questions = []Question{
+	QuestionEmergencyContactName,
+	QuestionEmergencyContactPhone,
+	QuestionMedicalConditions,
+	QuestionClub,
+	QuestionEstimatedFinish,
+}
+
+// Medical reports whether answers to q are medical details, which only
+// some organisers may read.
+func (q Question) Medical() bool {
+	switch q {
+	case QuestionEmergencyContactName, QuestionEmergencyContactPhone, QuestionMedicalConditions:
+		return true
+	}
+	return false
+}
+
+// Answer length limits, in characters.
+const (
+	MaxAnswerLength            = 100
+	MaxMedicalConditionsLength = 2000
+)
+
+var (
+	phonePattern  = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,24}$`)
+	finishPattern = regexp.MustCompile(`^[0-9]{1,3}:[0-5][0-9](:[0-5][0-9])?$`)
+)
+
+// Answers are an entrant's answers to their race's questionnaire. They are
+// stored encrypted, as they include medical details.
+type Answers struct {
+	EmergencyContactName  string `json:"emergency_contact_name,omitempty"`
+	EmergencyContactPhone string `json:"emergency_contact_phone,omitempty"`
+	MedicalConditions     string `json:"medical_conditions,omitempty"`
+	Club                  string `json:"club,omitempty"`
+	// EstimatedFinish is the finish time the entrant expects, in hours and
+	// minutes such as "4:30", optionally with seconds.
+	EstimatedFinish string `json:"estimated_finish,omitempty"`
+}
+
+// Get returns the answer to q.
+func (a Answers) Get(q Question) string {
+	switch q {
+	case QuestionEmergencyContactName:
+		return a.EmergencyContactName
+	case QuestionEmergencyContactPhone:
+		return a.EmergencyContactPhone
+	case QuestionMedicalConditions:
+		return a.MedicalConditions
+	case QuestionClub:
+		return a.Club
+	case QuestionEstimatedFinish:
+		return a.EstimatedFinish
+	}
+	return ""
+}
+
+// Set gives answer as the answer to q.
+func (a *Answers) Set(q Question, answer string) {
+	switch q {
+	case QuestionEmergencyContactName:
+		a.EmergencyContactName = answer
+	case QuestionEmergencyContactPhone:
+		a.EmergencyContactPhone = answer
+	case QuestionMedicalConditions:
+		a.MedicalConditions = answer
+	case QuestionClub:
+		a.Club = answer
+	case QuestionEstimatedFinish:
+		a.EstimatedFinish = answer
+	}
+}
+
+// IsZero reports whether no question has been answered.
+func (a Answers) IsZero() bool {
+	return a == Answers{}
+}
+
+// Validate checks the answers given and that every question in required
+// has one, returning FieldErrors keyed by question.
+func (a Answers) Validate(required []Question) error {
+	errs := FieldErrors{}
+	for _, q := range required {
+		if strings.TrimSpace(a.Get(q)) == "" {
+			errs.Add(string(q), q.label()+" is required")
+		}
+	}
+
+	for _, q := range Questions {
+		answer := a.Get(q)
+		if answer == "" {
+			continue
+		}
+		limit := MaxAnswerLength
+		if q == QuestionMedicalConditions {
+			limit = MaxMedicalConditionsLength
+		}
+		if utf8.RuneCountInString(answer) > limit {
+			errs.Add(string(q), fmt.Sprintf("%s must be at most %d characters", q.label(), limit))
+		}
+	}
+	if a.EmergencyContactPhone != "" && !phonePattern.MatchString(a.EmergencyContactPhone) {
+		errs.Add(string(QuestionEmergencyContactPhone), "emergency contact phone must be a phone number")
+	}
+	if a.EstimatedFinish != "" && !finishPattern.MatchString(a.EstimatedFinish) {
+		errs.Add(string(QuestionEstimatedFinish), "estimated finish time must be hours and minutes, such as 4:30")
+	}
+	return errs.Err()
+}
+
+// label names the question in messages.
+func (q Question) label() string {
+	if q == QuestionEstimatedFinish {
+		return "estimated finish time"
+	}
+	return strings.ReplaceAll(string(q), "_", " ")
+}
+
+// parseQuestions checks that every name is a question, returning them in
+// the order forms ask them without repeats.
+func parseQuestions(names []string) ([]Question, error) {
+	want := make(map[Question]bool, len(names))
+	for _, name := range names {
+		q := Question(name)
+		if !slices.Contains(Questions, q) {
+			return nil, fmt.Errorf("%w: unknown question %q", ErrInvalidInput, name)
+		}
+		want[q] = true
+	}
+
+	var questions []Question
+	for _, q := range Questions {
+		if want[q] {
+			questions = append(questions, q)
+		}
+	}
+	return questions, nil
+}
+
+// requiredQuestions returns the questions the race requires entrants to
+// answer.
+func requiredQuestions(ctx context.Context, raceRepo repository.RaceRepository, raceID int64) ([]Question, error) {
+	names, err := raceRepo.ListRequiredQuestions(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list required questions: %w", err)
+	}
+	return parseQuestions(names)
+}
+
+// lockedQuestions returns the questions the race locks once an entry is
+// paid for.
+func lockedQuestions(ctx context.Context, raceRepo repository.RaceRepository, raceID int64) ([]Question, error) {
+	names, err := raceRepo.ListLockedQuestions(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locked questions: %w", err)
+	}
+	return parseQuestions(names)
+}
+
+// sealAnswers encrypts the answers for storage with box.
+func sealAnswers(box *secret.Box, a Answers) ([]byte, error) {
+	plaintext, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode answers: %w", err)
+	}
+	return box.Seal(plaintext), nil
+}
+
+// openAnswers decrypts answers sealed by sealAnswers.
+func openAnswers(box *secret.Box, sealed []byte) (Answers, error) {
+	plaintext, err := box.Open(sealed)
+	if err != nil {
+		return Answers{}, err
+	}
+	var a Answers
+	if err := json.Unmarshal(plaintext, &a); err != nil {
+		return Answers{}, fmt.Errorf("failed to decode answers: %w", err)
+	}
+	return a, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAnswers_Validate(t *testing.T) {
+	complete := Answers{
+		EmergencyContactName:  "Alex Hill",
+		EmergencyContactPhone: "+44 7700 900123",
+		MedicalConditions:     "None",
+		Club:                  "Dark Peak Fell Runners",
+		EstimatedFinish:       "4:30",
+	}
+
+	t.Run("accepts complete answers", func(t *testing.T) {
+		if err := complete.Validate(Questions); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("accepts unanswered optional questions", func(t *testing.T) {
+		if err := (Answers{}).Validate(nil); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		answers  Answers
+		required []Question
+		field    string
+		want     string
+	}{
+		{name: "requires required answers", answers: Answers{}, required: []Question{QuestionMedicalConditions}, field: "medical_conditions", want: "medical conditions is required"},
+		{name: "treats blank answers as missing", answers: Answers{EmergencyContactName: "  "}, required: []Question{QuestionEmergencyContactName}, field: "emergency_contact_name", want: "emergency contact name is required"},
+		{name: "rejects phone numbers with letters", answers: Answers{EmergencyContactPhone: "call my mum"}, field: "emergency_contact_phone", want: "emergency contact phone must be a phone number"},
+		{name: "rejects finish times without minutes", answers: Answers{EstimatedFinish: "4 hours"}, field: "estimated_finish", want: "estimated finish time must be hours and minutes, such as 4:30"},
+		{name: "rejects long clubs", answers: Answers{Club: strings.Repeat("x", MaxAnswerLength+1)}, field: "club", want: "club must be at most 100 characters"},
+		{name: "rejects long medical conditions", answers: Answers{MedicalConditions: strings.Repeat("x", MaxMedicalConditionsLength+1)}, field: "medical_conditions", want: "medical conditions must be at most 2000 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fieldErrs FieldErrors
+			if err := tt.answers.Validate(tt.required); !errors.As(err, &fieldErrs) {
+				t.Fatalf("expected FieldErrors, got %v", err)
+			}
+			if fieldErrs[tt.field] != tt.want {
+				t.Errorf("expected %q for %s, got %v", tt.want, tt.field, fieldErrs)
+			}
+		})
+	}
+}
+
+func TestParseQuestions(t *testing.T) {
+	got, err := parseQuestions([]string{"club", "emergency_contact_name", "club"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != QuestionEmergencyContactName || got[1] != QuestionClub {
+		t.Errorf("expected the questions in form order without repeats, got %v", got)
+	}
+
+	if _, err := parseQuestions([]string{"blood_type"}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an unknown question, got %v", err)
+	}
+}
+
+func TestSealAnswers(t *testing.T) {
+	box := newTestBox(t)
+	answers := Answers{MedicalConditions: "Type 1 diabetes", EstimatedFinish: "11:45:00"}
+
+	sealed, err := sealAnswers(box, answers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(sealed), "diabetes") {
+		t.Fatalf("expected the answers to be encrypted, got %q", sealed)
+	}
+
+	got, err := openAnswers(box, sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != answers {
+		t.Errorf("expected %+v, got %+v", answers, got)
+	}
+}
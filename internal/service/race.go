@@ -0,0 +1,551 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/bus"
+	"firecrest/internal/gpx"
+	"firecrest/internal/repository"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/race.go -pkg servicemocks . RaceService
+
+// ErrRaceNotFound is returned when no race matches a lookup. It wraps
+// repository.ErrNotFound, so callers checking for that still see it.
+var ErrRaceNotFound = apperr.Wrap(repository.ErrNotFound, apperr.CodeRaceNotFound, http.StatusNotFound, "race not found")
+
+// RaceService defines the interface for race business logic.
+type RaceService interface {
+	ListRaces(ctx context.Context, eventID int64) ([]RaceAvailability, error)
+	ListRacesByEvents(ctx context.Context, eventIDs []int64) (map[int64][]db.Race, error)
+	// GetRace and GetRaceByID return ErrRaceNotFound for races that do
+	// not exist.
+	GetRace(ctx context.Context, eventID int64, slug string) (RaceAvailability, error)
+	GetRaceByID(ctx context.Context, id int64) (db.Race, error)
+	CreateRace(ctx context.Context, input CreateRaceInput) (db.Race, error)
+	UpdateRaceCapacity(ctx context.Context, raceID int64, capacity int32, userID int64) (db.Race, error)
+	// UploadRoute stores the GPX file read from r as raceID's route,
+	// replacing any it had. Files larger than MaxRouteBytes, that are not
+	// GPX, or that have no or more than MaxRoutePoints track points are
+	// refused with FieldErrors for "route".
+	UploadRoute(ctx context.Context, raceID int64, r io.Reader) (RouteStats, error)
+	// GetRoute returns the figures of raceID's route. It returns
+	// repository.ErrNotFound if the race has no route.
+	GetRoute(ctx context.Context, raceID int64) (RouteStats, error)
+	// RouteGPX returns raceID's route file as it was uploaded. It returns
+	// repository.ErrNotFound if the race has no route.
+	RouteGPX(ctx context.Context, raceID int64) ([]byte, error)
+	// RequiredQuestions returns the questions raceID requires entrants to
+	// answer when they register, in the order forms ask them.
+	RequiredQuestions(ctx context.Context, raceID int64) ([]Question, error)
+	// SetRequiredQuestions replaces the questions raceID requires entrants
+	// to answer. Names that are not questions are refused with
+	// ErrInvalidInput.
+	SetRequiredQuestions(ctx context.Context, raceID int64, names []string) error
+	// LockedQuestions returns the questions whose answers entrants to
+	// raceID may no longer change themselves once their entry is paid
+	// for, in the order forms ask them.
+	LockedQuestions(ctx context.Context, raceID int64) ([]Question, error)
+	// SetLockedQuestions replaces the questions locked once entries to
+	// raceID are paid for. Names that are not questions are refused with
+	// ErrInvalidInput.
+	SetLockedQuestions(ctx context.Context, raceID int64, names []string) error
+	// CustomFields returns the fields raceID's organisers have added to its
+	// entry form, in the order it asks them, at their current versions.
+	CustomFields(ctx context.Context, raceID int64) ([]CustomField, error)
+	// CustomFieldVersions returns every definition raceID's custom fields
+	// have had, removed fields' included.
+	CustomFieldVersions(ctx context.Context, raceID int64) (CustomFieldVersions, error)
+	// CreateCustomField adds a field to the end of raceID's entry form.
+	// Invalid fields, and fields past MaxCustomFields, are refused with
+	// FieldErrors.
+	CreateCustomField(ctx context.Context, raceID int64, input CustomFieldInput) (CustomField, error)
+	// UpdateCustomField gives one of raceID's fields a new version, checked
+	// as CreateCustomField does. Answers already given keep the version
+	// they were given for. It returns repository.ErrNotFound if the race
+	// has no such field.
+	UpdateCustomField(ctx context.Context, raceID, fieldID int64, input CustomFieldInput) (CustomField, error)
+	// DeleteCustomField removes one of raceID's fields from its entry form,
+	// keeping the answers already given. It returns repository.ErrNotFound
+	// if the race has no such field.
+	DeleteCustomField(ctx context.Context, raceID, fieldID int64) error
+	// MoveCustomField moves one of raceID's fields offset places along its
+	// entry form, stopping at either end. It returns repository.ErrNotFound
+	// if the race has no such field.
+	MoveCustomField(ctx context.Context, raceID, fieldID int64, offset int) error
+	// ReorderCustomFields puts raceID's fields in the order of fieldIDs,
+	// which must list each of them once or is refused with
+	// ErrInvalidInput.
+	ReorderCustomFields(ctx context.Context, raceID int64, fieldIDs []int64) error
+	// SetMinAge sets the youngest entrants to raceID may be on race day,
+	// from 1 to MaxMinAge; zero lets any age enter. Other ages are refused
+	// with FieldErrors for "min_age".
+	SetMinAge(ctx context.Context, raceID int64, minAge int) (db.Race, error)
+	// SetDistance sets raceID's distance and climb in metres; zero leaves
+	// either unknown. Distances from MaxDistanceMetres up and negative
+	// figures are refused with FieldErrors for "distance" and
+	// "elevation_gain".
+	SetDistance(ctx context.Context, raceID int64, distanceMetres, elevationGainMetres int) (db.Race, error)
+	// ListPriceTiers returns raceID's price tiers, earliest first.
+	ListPriceTiers(ctx context.Context, raceID int64) ([]PriceTier, error)
+	// CreatePriceTier adds a price tier to raceID, reading its dates in
+	// the event's time zone. Invalid tiers, and tiers whose dates overlap
+	// another's unless exactly one of the two is capped, are refused with
+	// FieldErrors.
+	CreatePriceTier(ctx context.Context, event db.Event, raceID int64, input PriceTierInput) (db.RacePriceTier, error)
+	// DeletePriceTier removes one of raceID's price tiers. It returns
+	// repository.ErrNotFound if the race has no such tier.
+	DeletePriceTier(ctx context.Context, raceID, tierID int64) error
+	// CurrentPrice returns what an entry to raceID costs at now, and what
+	// the price becomes next. Entries are charged the price current when
+	// they are made, not the one shown when the page was drawn.
+	CurrentPrice(ctx context.Context, raceID int64, now time.Time) (Price, error)
+	// CurrentPrices returns what entries to each of the races cost at now,
+	// by race ID.
+	CurrentPrices(ctx context.Context, races []db.Race, now time.Time) (map[int64]Price, error)
+}
+
+// MaxMinAge is the highest minimum age a race may set.
+const MaxMinAge = 100
+
+// MaxDistanceMetres bounds a race's distance: 5,000 km and over is taken
+// for a slip of the keyboard.
+const MaxDistanceMetres = 5_000_000
+
+// Route upload limits
+const (
+	MaxRouteBytes  = 5 << 20
+	MaxRoutePoints = 100_000
+)
+
+// CreateRaceInput represents the input for creating a race within an event.
+type CreateRaceInput struct {
+	EventID     int64
+	Name        string
+	Slug        string
+	MaxCapacity int32
+	// PriceUnits is the entry fee in minor currency units outside the
+	// race's price tiers; zero means free.
+	PriceUnits int32
+	Currency   string
+	// RegistrationOpens, RegistrationCloses and StartsAt are the organiser's
+	// local times in the event's time zone; only their date and clock are
+	// read. Zero leaves them unset.
+	RegistrationOpens  time.Time
+	RegistrationCloses time.Time
+	StartsAt           time.Time
+	// DistanceMetres and ElevationGainMetres are the race's length and
+	// climb. Zero leaves them unknown.
+	DistanceMetres      int
+	ElevationGainMetres int
+}
+
+// Validate checks if the input is valid.
+func (i CreateRaceInput) Validate() error {
+	if i.EventID <= 0 {
+		return fmt.Errorf("%w: event_id must be positive", ErrInvalidInput)
+	}
+	if i.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if err := validateSlug(i.Slug); err != nil {
+		return err
+	}
+	if i.MaxCapacity <= 0 {
+		return fmt.Errorf("%w: max_capacity must be positive", ErrInvalidInput)
+	}
+	if i.PriceUnits < 0 {
+		return fmt.Errorf("%w: price must not be negative", ErrInvalidInput)
+	}
+	if !i.RegistrationOpens.IsZero() && !i.RegistrationCloses.IsZero() && !i.RegistrationCloses.After(i.RegistrationOpens) {
+		return fmt.Errorf("%w: registration must close after it opens", ErrInvalidInput)
+	}
+	return distanceProblems(i.DistanceMetres, i.ElevationGainMetres).Err()
+}
+
+// distanceProblems checks a race's distance and climb in metres, where zero
+// is unknown.
+func distanceProblems(distanceMetres, elevationGainMetres int) FieldErrors {
+	errs := FieldErrors{}
+	if distanceMetres < 0 || distanceMetres >= MaxDistanceMetres {
+		errs.Add("distance", fmt.Sprintf("distance must be positive and under %d km", MaxDistanceMetres/1000))
+	}
+	if elevationGainMetres < 0 {
+		errs.Add("elevation_gain", "climb must be positive")
+	}
+	return errs
+}
+
+// optionalInt4 stores n, with zero as NULL.
+func optionalInt4(n int) pgtype.Int4 {
+	return pgtype.Int4{Int32: int32(n), Valid: n != 0}
+}
+
+// RaceAvailability pairs a race with its current number of active
+// registrations. Route is set by ListRaces for races with a route, and
+// Waves lists the start waves of races that have them, earliest first.
+type RaceAvailability struct {
+	Race       db.Race
+	Registered int
+	Route      *RouteStats
+	Waves      []db.RaceWave
+}
+
+// RouteStats are the figures worked out from a race's route when it was
+// uploaded.
+type RouteStats struct {
+	Points              int
+	DistanceMetres      int
+	ElevationGainMetres int
+}
+
+// SpotsRemaining returns the number of places left in the race, never less than zero.
+func (a RaceAvailability) SpotsRemaining() int {
+	return max(int(a.Race.MaxCapacity)-a.Registered, 0)
+}
+
+type raceService struct {
+	raceRepo         repository.RaceRepository
+	registrationRepo repository.RegistrationRepository
+	eventRepo        repository.EventRepository
+	events           *bus.Bus
+}
+
+// NewRaceService creates a new RaceService with the given repositories.
+// New races' local times are read in their event's time zone, loaded
+// through eventRepo. Changes to races are published on events.
+func NewRaceService(raceRepo repository.RaceRepository, registrationRepo repository.RegistrationRepository, eventRepo repository.EventRepository, events *bus.Bus) RaceService {
+	return &raceService{
+		raceRepo:         raceRepo,
+		registrationRepo: registrationRepo,
+		eventRepo:        eventRepo,
+		events:           events,
+	}
+}
+
+func (s *raceService) ListRaces(ctx context.Context, eventID int64) ([]RaceAvailability, error) {
+	races, err := s.raceRepo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.registrationRepo.CountByRaceForEvent(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count registrations: %w", err)
+	}
+
+	routes, err := s.raceRepo.ListRoutesByEvent(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routes: %w", err)
+	}
+	byRace := make(map[int64]*RouteStats, len(routes))
+	for _, route := range routes {
+		byRace[route.RaceID] = &RouteStats{
+			Points:              int(route.PointCount),
+			DistanceMetres:      int(route.DistanceMetres),
+			ElevationGainMetres: int(route.ElevationGainMetres),
+		}
+	}
+
+	waves, err := s.raceRepo.ListWavesByEvent(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list waves: %w", err)
+	}
+	wavesByRace := make(map[int64][]db.RaceWave)
+	for _, wave := range waves {
+		wavesByRace[wave.RaceID] = append(wavesByRace[wave.RaceID], wave)
+	}
+
+	availability := make([]RaceAvailability, 0, len(races))
+	for _, race := range races {
+		availability = append(availability, RaceAvailability{
+			Race:       race,
+			Registered: counts[race.ID],
+			Route:      byRace[race.ID],
+			Waves:      wavesByRace[race.ID],
+		})
+	}
+	return availability, nil
+}
+
+func (s *raceService) ListRacesByEvents(ctx context.Context, eventIDs []int64) (map[int64][]db.Race, error) {
+	races, err := s.raceRepo.ListByEvents(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byEvent := make(map[int64][]db.Race, len(eventIDs))
+	for _, race := range races {
+		byEvent[race.EventID] = append(byEvent[race.EventID], race)
+	}
+	return byEvent, nil
+}
+
+func (s *raceService) GetRace(ctx context.Context, eventID int64, slug string) (RaceAvailability, error) {
+	if slug == "" || len(slug) > MaxSlugLength {
+		return RaceAvailability{}, fmt.Errorf("%w: invalid slug", ErrInvalidInput)
+	}
+
+	race, err := s.raceRepo.GetBySlug(ctx, eventID, slug)
+	if err != nil {
+		return RaceAvailability{}, notFoundAs(err, ErrRaceNotFound)
+	}
+
+	counts, err := s.registrationRepo.CountByRaceForEvent(ctx, eventID)
+	if err != nil {
+		return RaceAvailability{}, fmt.Errorf("failed to count registrations: %w", err)
+	}
+
+	return RaceAvailability{
+		Race:       race,
+		Registered: counts[race.ID],
+	}, nil
+}
+
+func (s *raceService) GetRaceByID(ctx context.Context, id int64) (db.Race, error) {
+	if id < 1 {
+		return db.Race{}, fmt.Errorf("%w: invalid race id", ErrInvalidInput)
+	}
+	race, err := s.raceRepo.GetByID(ctx, id)
+	return race, notFoundAs(err, ErrRaceNotFound)
+}
+
+func (s *raceService) CreateRace(ctx context.Context, input CreateRaceInput) (db.Race, error) {
+	if err := input.Validate(); err != nil {
+		return db.Race{}, err
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, input.EventID)
+	if err != nil {
+		return db.Race{}, err
+	}
+	loc := EventLocation(event)
+
+	currency := pgtype.Text{String: input.Currency, Valid: input.Currency != ""}
+	race, err := s.raceRepo.Create(ctx, db.CreateRaceParams{
+		EventID:               input.EventID,
+		Name:                  input.Name,
+		Slug:                  input.Slug,
+		RegistrationOpenDate:  localTimestamptz(input.RegistrationOpens, loc),
+		RegistrationCloseDate: localTimestamptz(input.RegistrationCloses, loc),
+		StartsAt:              localTimestamptz(input.StartsAt, loc),
+		MaxCapacity:           input.MaxCapacity,
+		PriceUnits:            pgtype.Int4{Int32: input.PriceUnits, Valid: true},
+		Currency:              currency,
+		DistanceMetres:        optionalInt4(input.DistanceMetres),
+		ElevationGainMetres:   optionalInt4(input.ElevationGainMetres),
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return db.Race{}, ErrSlugTaken
+		}
+		return db.Race{}, err
+	}
+	bus.Publish(ctx, s.events, bus.RaceUpdated, bus.RaceChange{RaceID: race.ID, EventID: race.EventID})
+	return race, nil
+}
+
+// UpdateRaceCapacity sets how many places raceID has, on behalf of userID.
+// Capacity cannot drop below the places entries already hold; asking for
+// less returns FieldErrors naming that floor. Setting the capacity the race
+// already has changes nothing.
+func (s *raceService) UpdateRaceCapacity(ctx context.Context, raceID int64, capacity int32, userID int64) (db.Race, error) {
+	if raceID < 1 {
+		return db.Race{}, fmt.Errorf("%w: invalid race id", ErrInvalidInput)
+	}
+	if capacity <= 0 {
+		return db.Race{}, FieldErrors{"max_capacity": "capacity must be at least 1"}
+	}
+
+	change, err := s.raceRepo.UpdateCapacity(ctx, raceID, capacity, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrCapacityExceeded) {
+			return db.Race{}, FieldErrors{"max_capacity": fmt.Sprintf("capacity cannot be less than the %d places already taken", change.Taken)}
+		}
+		return db.Race{}, err
+	}
+	bus.Publish(ctx, s.events, bus.RaceUpdated, bus.RaceChange{RaceID: raceID, EventID: change.Race.EventID})
+	return change.Race, nil
+}
+
+func (s *raceService) UploadRoute(ctx context.Context, raceID int64, r io.Reader) (RouteStats, error) {
+	if raceID < 1 {
+		return RouteStats{}, fmt.Errorf("%w: invalid race id", ErrInvalidInput)
+	}
+
+	// The whole file is kept, so it is read into memory once and decoded
+	// from there
+	raw, err := io.ReadAll(io.LimitReader(r, MaxRouteBytes+1))
+	if err != nil {
+		return RouteStats{}, err
+	}
+	if len(raw) > MaxRouteBytes {
+		return RouteStats{}, FieldErrors{"route": fmt.Sprintf("route file must be at most %d MB", MaxRouteBytes>>20)}
+	}
+
+	track, err := gpx.Decode(bytes.NewReader(raw), MaxRoutePoints)
+	if err != nil {
+		switch {
+		case errors.Is(err, gpx.ErrNotGPX):
+			return RouteStats{}, FieldErrors{"route": "route must be a GPX file"}
+		case errors.Is(err, gpx.ErrNoPoints):
+			return RouteStats{}, FieldErrors{"route": "route file has no track points"}
+		case errors.Is(err, gpx.ErrTooManyPoints):
+			return RouteStats{}, FieldErrors{"route": fmt.Sprintf("route file must have at most %d track points", MaxRoutePoints)}
+		}
+		return RouteStats{}, err
+	}
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(raw); err != nil {
+		return RouteStats{}, fmt.Errorf("failed to compress route: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return RouteStats{}, fmt.Errorf("failed to compress route: %w", err)
+	}
+
+	stats := RouteStats{
+		Points:              len(track.Points),
+		DistanceMetres:      int(math.Round(track.Distance())),
+		ElevationGainMetres: int(math.Round(track.ElevationGain())),
+	}
+	if err := s.raceRepo.SaveRoute(ctx, db.UpsertRaceRouteParams{
+		RaceID:              raceID,
+		GpxGzip:             compressed.Bytes(),
+		PointCount:          int32(stats.Points),
+		DistanceMetres:      int32(stats.DistanceMetres),
+		ElevationGainMetres: int32(stats.ElevationGainMetres),
+	}); err != nil {
+		return RouteStats{}, err
+	}
+	return stats, nil
+}
+
+func (s *raceService) GetRoute(ctx context.Context, raceID int64) (RouteStats, error) {
+	if raceID < 1 {
+		return RouteStats{}, fmt.Errorf("%w: invalid race id", ErrInvalidInput)
+	}
+	route, err := s.raceRepo.GetRoute(ctx, raceID)
+	if err != nil {
+		return RouteStats{}, err
+	}
+	return RouteStats{
+		Points:              int(route.PointCount),
+		DistanceMetres:      int(route.DistanceMetres),
+		ElevationGainMetres: int(route.ElevationGainMetres),
+	}, nil
+}
+
+func (s *raceService) RouteGPX(ctx context.Context, raceID int64) ([]byte, error) {
+	if raceID < 1 {
+		return nil, fmt.Errorf("%w: invalid race id", ErrInvalidInput)
+	}
+	compressed, err := s.raceRepo.GetRouteGPX(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress route: %w", err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress route: %w", err)
+	}
+	return raw, nil
+}
+
+// localTimestamptz stores the local time wall, read in loc, as an instant.
+// A zero wall is stored as NULL.
+func localTimestamptz(wall time.Time, loc *time.Location) pgtype.Timestamptz {
+	if wall.IsZero() {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: InLocation(wall, loc).UTC(), Valid: true}
+}
+
+func (s *raceService) RequiredQuestions(ctx context.Context, raceID int64) ([]Question, error) {
+	return requiredQuestions(ctx, s.raceRepo, raceID)
+}
+
+func (s *raceService) SetRequiredQuestions(ctx context.Context, raceID int64, names []string) error {
+	questions, err := parseQuestions(names)
+	if err != nil {
+		return err
+	}
+	stored := make([]string, len(questions))
+	for i, q := range questions {
+		stored[i] = string(q)
+	}
+	if err := s.raceRepo.SetRequiredQuestions(ctx, raceID, stored); err != nil {
+		return fmt.Errorf("failed to set required questions: %w", err)
+	}
+	return nil
+}
+
+func (s *raceService) LockedQuestions(ctx context.Context, raceID int64) ([]Question, error) {
+	return lockedQuestions(ctx, s.raceRepo, raceID)
+}
+
+func (s *raceService) SetLockedQuestions(ctx context.Context, raceID int64, names []string) error {
+	questions, err := parseQuestions(names)
+	if err != nil {
+		return err
+	}
+	stored := make([]string, len(questions))
+	for i, q := range questions {
+		stored[i] = string(q)
+	}
+	if err := s.raceRepo.SetLockedQuestions(ctx, raceID, stored); err != nil {
+		return fmt.Errorf("failed to set locked questions: %w", err)
+	}
+	return nil
+}
+
+func (s *raceService) SetMinAge(ctx context.Context, raceID int64, minAge int) (db.Race, error) {
+	if minAge < 0 || minAge > MaxMinAge {
+		return db.Race{}, FieldErrors{"min_age": fmt.Sprintf("minimum age must be between 1 and %d, or blank", MaxMinAge)}
+	}
+	race, err := s.raceRepo.SetMinAge(ctx, raceID, pgtype.Int4{Int32: int32(minAge), Valid: minAge > 0})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return db.Race{}, err
+		}
+		return db.Race{}, fmt.Errorf("failed to set minimum age: %w", err)
+	}
+	bus.Publish(ctx, s.events, bus.RaceUpdated, bus.RaceChange{RaceID: raceID, EventID: race.EventID})
+	return race, nil
+}
+
+func (s *raceService) SetDistance(ctx context.Context, raceID int64, distanceMetres, elevationGainMetres int) (db.Race, error) {
+	if err := distanceProblems(distanceMetres, elevationGainMetres).Err(); err != nil {
+		return db.Race{}, err
+	}
+	race, err := s.raceRepo.SetDistance(ctx, db.SetRaceDistanceParams{
+		ID:                  raceID,
+		DistanceMetres:      optionalInt4(distanceMetres),
+		ElevationGainMetres: optionalInt4(elevationGainMetres),
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return db.Race{}, err
+		}
+		return db.Race{}, fmt.Errorf("failed to set distance: %w", err)
+	}
+	bus.Publish(ctx, s.events, bus.RaceUpdated, bus.RaceChange{RaceID: raceID, EventID: race.EventID})
+	return race, nil
+}
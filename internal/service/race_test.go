@@ -0,0 +1,550 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestRaceService_ListRaces(t *testing.T) {
+	t.Run("pairs races with registration counts", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.Race, error) {
+				return []db.Race{
+					{ID: 1, EventID: eventID, Name: "Ultra 50K", MaxCapacity: 300},
+					{ID: 2, EventID: eventID, Name: "Marathon", MaxCapacity: 200},
+				}, nil
+			},
+		}
+		registrationRepo := &repositorymocks.RegistrationRepositoryMock{
+			CountByRaceForEventFunc: func(ctx context.Context, eventID int64) (map[int64]int, error) {
+				return map[int64]int{1: 245}, nil
+			},
+		}
+
+		svc := NewRaceService(raceRepo, registrationRepo, &repositorymocks.EventRepositoryMock{}, nil)
+		races, err := svc.ListRaces(context.Background(), 10)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(races) != 2 {
+			t.Fatalf("expected 2 races, got %d", len(races))
+		}
+		if races[0].Registered != 245 || races[0].SpotsRemaining() != 55 {
+			t.Errorf("expected 245 registered and 55 remaining, got %d and %d", races[0].Registered, races[0].SpotsRemaining())
+		}
+		if races[1].Registered != 0 || races[1].SpotsRemaining() != 200 {
+			t.Errorf("expected race without registrations to have full capacity, got %d remaining", races[1].SpotsRemaining())
+		}
+	})
+
+	t.Run("attaches the routes races have", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.Race, error) {
+				return []db.Race{{ID: 1, EventID: eventID}, {ID: 2, EventID: eventID}}, nil
+			},
+			ListRoutesByEventFunc: func(ctx context.Context, eventID int64) ([]db.ListRaceRoutesByEventRow, error) {
+				return []db.ListRaceRoutesByEventRow{{RaceID: 2, PointCount: 900, DistanceMetres: 50120, ElevationGainMetres: 1840}}, nil
+			},
+		}
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		races, err := svc.ListRaces(context.Background(), 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if races[0].Route != nil {
+			t.Errorf("expected no route for race 1, got %+v", races[0].Route)
+		}
+		if got := races[1].Route; got == nil || *got != (RouteStats{Points: 900, DistanceMetres: 50120, ElevationGainMetres: 1840}) {
+			t.Errorf("unexpected route for race 2: %+v", got)
+		}
+	})
+
+	t.Run("propagates registration count errors", func(t *testing.T) {
+		registrationRepo := &repositorymocks.RegistrationRepositoryMock{
+			CountByRaceForEventFunc: func(ctx context.Context, eventID int64) (map[int64]int, error) {
+				return nil, errors.New("database error")
+			},
+		}
+
+		svc := NewRaceService(&repositorymocks.RaceRepositoryMock{}, registrationRepo, &repositorymocks.EventRepositoryMock{}, nil)
+		_, err := svc.ListRaces(context.Background(), 10)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestRaceService_ListRacesByEvents(t *testing.T) {
+	t.Run("groups races by event", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListByEventsFunc: func(ctx context.Context, eventIDs []int64) ([]db.Race, error) {
+				return []db.Race{
+					{ID: 1, EventID: 10},
+					{ID: 2, EventID: 10},
+					{ID: 3, EventID: 20},
+				}, nil
+			},
+		}
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		byEvent, err := svc.ListRacesByEvents(context.Background(), []int64{10, 20, 30})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(byEvent[10]) != 2 || len(byEvent[20]) != 1 || len(byEvent[30]) != 0 {
+			t.Errorf("unexpected grouping: %v", byEvent)
+		}
+	})
+}
+
+func TestRaceService_GetRace(t *testing.T) {
+	t.Run("returns ErrInvalidInput for empty slug", func(t *testing.T) {
+		svc := NewRaceService(&repositorymocks.RaceRepositoryMock{}, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+
+		_, err := svc.GetRace(context.Background(), 1, "")
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrNotFound for non-existent race", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			GetBySlugFunc: func(ctx context.Context, eventID int64, slug string) (db.Race, error) {
+				return db.Race{}, repository.ErrNotFound
+			},
+		}
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		_, err := svc.GetRace(context.Background(), 1, "missing")
+
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestRaceService_CreateRace(t *testing.T) {
+	valid := CreateRaceInput{EventID: 1, Name: "Half Marathon", Slug: "half-marathon", MaxCapacity: 500, PriceUnits: 3500, Currency: "GBP"}
+
+	t.Run("creates race with priced entry", func(t *testing.T) {
+		var captured db.CreateRaceParams
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRaceParams) (db.Race, error) {
+				captured = params
+				return db.Race{ID: 9, EventID: params.EventID, Slug: params.Slug}, nil
+			},
+		}
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		race, err := svc.CreateRace(context.Background(), valid)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if race.ID != 9 {
+			t.Errorf("expected race 9, got %d", race.ID)
+		}
+		if captured.PriceUnits.Int32 != 3500 || captured.Currency.String != "GBP" {
+			t.Errorf("unexpected params passed to repository: %+v", captured)
+		}
+	})
+
+	t.Run("rejects malformed slug", func(t *testing.T) {
+		called := false
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRaceParams) (db.Race, error) {
+				called = true
+				return db.Race{}, nil
+			},
+		}
+		input := valid
+		input.Slug = "Half Marathon"
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		_, err := svc.CreateRace(context.Background(), input)
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if called {
+			t.Error("expected repository not to be called")
+		}
+	})
+
+	t.Run("returns ErrSlugTaken on repository conflict", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRaceParams) (db.Race, error) {
+				return db.Race{}, repository.ErrConflict
+			},
+		}
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		_, err := svc.CreateRace(context.Background(), valid)
+
+		if !errors.Is(err, ErrSlugTaken) {
+			t.Errorf("expected ErrSlugTaken, got %v", err)
+		}
+	})
+
+	t.Run("reads local times in the event's time zone", func(t *testing.T) {
+		var captured db.CreateRaceParams
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRaceParams) (db.Race, error) {
+				captured = params
+				return db.Race{ID: 9}, nil
+			},
+		}
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.Event, error) {
+				return db.Event{ID: id, Timezone: "Europe/London"}, nil
+			},
+		}
+		// The clocks go forward at 01:00 UTC on 29 March 2026
+		input := valid
+		input.RegistrationOpens = time.Date(2026, time.March, 28, 9, 0, 0, 0, time.UTC)
+		input.RegistrationCloses = time.Date(2026, time.June, 1, 23, 59, 0, 0, time.UTC)
+		input.StartsAt = time.Date(2026, time.March, 29, 9, 0, 0, 0, time.UTC)
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, eventRepo, nil)
+		if _, err := svc.CreateRace(context.Background(), input); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if want := time.Date(2026, time.March, 28, 9, 0, 0, 0, time.UTC); !captured.RegistrationOpenDate.Time.Equal(want) {
+			t.Errorf("expected 09:00 GMT to open at %v, got %v", want, captured.RegistrationOpenDate.Time)
+		}
+		if want := time.Date(2026, time.June, 1, 22, 59, 0, 0, time.UTC); !captured.RegistrationCloseDate.Time.Equal(want) {
+			t.Errorf("expected 23:59 BST to close at %v, got %v", want, captured.RegistrationCloseDate.Time)
+		}
+		if want := time.Date(2026, time.March, 29, 8, 0, 0, 0, time.UTC); !captured.StartsAt.Time.Equal(want) {
+			t.Errorf("expected 09:00 BST to start at %v, got %v", want, captured.StartsAt.Time)
+		}
+	})
+
+	t.Run("leaves unset times unset", func(t *testing.T) {
+		var captured db.CreateRaceParams
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRaceParams) (db.Race, error) {
+				captured = params
+				return db.Race{ID: 9}, nil
+			},
+		}
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		if _, err := svc.CreateRace(context.Background(), valid); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if captured.RegistrationOpenDate.Valid || captured.RegistrationCloseDate.Valid || captured.StartsAt.Valid {
+			t.Errorf("expected no dates, got %+v", captured)
+		}
+	})
+
+	t.Run("rejects a window that closes before it opens", func(t *testing.T) {
+		input := valid
+		input.RegistrationOpens = time.Date(2026, time.June, 1, 9, 0, 0, 0, time.UTC)
+		input.RegistrationCloses = time.Date(2026, time.May, 1, 9, 0, 0, 0, time.UTC)
+
+		svc := NewRaceService(&repositorymocks.RaceRepositoryMock{}, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		if _, err := svc.CreateRace(context.Background(), input); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestRaceAvailability_SpotsRemaining(t *testing.T) {
+	t.Run("never goes below zero when oversubscribed", func(t *testing.T) {
+		a := RaceAvailability{Race: db.Race{MaxCapacity: 100}, Registered: 103}
+
+		if got := a.SpotsRemaining(); got != 0 {
+			t.Errorf("expected 0 spots remaining, got %d", got)
+		}
+	})
+}
+
+func TestRaceService_UpdateRaceCapacity(t *testing.T) {
+	t.Run("refuses to go below the places already taken", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			UpdateCapacityFunc: func(ctx context.Context, raceID int64, capacity int32, userID int64) (repository.CapacityChange, error) {
+				return repository.CapacityChange{OldCapacity: 100, Taken: 42}, repository.ErrCapacityExceeded
+			},
+		}
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		_, err := svc.UpdateRaceCapacity(context.Background(), 7, 40, 3)
+
+		var fieldErrs FieldErrors
+		if !errors.As(err, &fieldErrs) {
+			t.Fatalf("expected FieldErrors, got %v", err)
+		}
+		if got := fieldErrs["max_capacity"]; got != "capacity cannot be less than the 42 places already taken" {
+			t.Errorf("unexpected message %q", got)
+		}
+	})
+
+	t.Run("passes the new capacity and who changed it to the repository", func(t *testing.T) {
+		var gotRace, gotUser int64
+		var gotCapacity int32
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			UpdateCapacityFunc: func(ctx context.Context, raceID int64, capacity int32, userID int64) (repository.CapacityChange, error) {
+				gotRace, gotCapacity, gotUser = raceID, capacity, userID
+				return repository.CapacityChange{Race: db.Race{ID: raceID, MaxCapacity: capacity}, OldCapacity: 100, Taken: 42}, nil
+			},
+		}
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		race, err := svc.UpdateRaceCapacity(context.Background(), 7, 150, 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotRace != 7 || gotCapacity != 150 || gotUser != 3 {
+			t.Errorf("unexpected repository call: race %d, capacity %d, user %d", gotRace, gotCapacity, gotUser)
+		}
+		if race.MaxCapacity != 150 {
+			t.Errorf("expected the updated race, got %+v", race)
+		}
+	})
+
+	t.Run("rejects a capacity below one without calling the repository", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			UpdateCapacityFunc: func(ctx context.Context, raceID int64, capacity int32, userID int64) (repository.CapacityChange, error) {
+				t.Fatal("expected the repository not to be called")
+				return repository.CapacityChange{}, nil
+			},
+		}
+
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+		if _, err := svc.UpdateRaceCapacity(context.Background(), 7, 0, 3); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestRaceService_UploadRoute(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/route.gpx")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	t.Run("stores the file compressed with its distance and climb", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{}
+		svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+
+		stats, err := svc.UploadRoute(context.Background(), 7, bytes.NewReader(fixture))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Ten legs of 0.001° north and 0.0005° east, about 116 m each
+		if math.Abs(float64(stats.DistanceMetres)-1160) > 2 {
+			t.Errorf("expected a distance of about 1160 m, got %d", stats.DistanceMetres)
+		}
+		if stats.ElevationGainMetres != 51 || stats.Points != 11 {
+			t.Errorf("expected 51 m of climb over 11 points, got %+v", stats)
+		}
+
+		calls := raceRepo.SaveRouteCalls()
+		if len(calls) != 1 {
+			t.Fatalf("expected the route to be saved once, got %d", len(calls))
+		}
+		saved := calls[0].Params
+		if saved.RaceID != 7 || saved.DistanceMetres != int32(stats.DistanceMetres) || saved.ElevationGainMetres != 51 || saved.PointCount != 11 {
+			t.Errorf("unexpected saved route %+v", saved)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(saved.GpxGzip))
+		if err != nil {
+			t.Fatalf("expected a gzip-compressed file: %v", err)
+		}
+		if stored, err := io.ReadAll(zr); err != nil || !bytes.Equal(stored, fixture) {
+			t.Errorf("expected the file to be stored as uploaded (err %v)", err)
+		}
+	})
+
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "refuses files over the size limit", file: strings.Repeat(" ", MaxRouteBytes+1), want: "route file must be at most 5 MB"},
+		{name: "refuses malformed XML", file: "<gpx><trk>", want: "route must be a GPX file"},
+		{name: "refuses other files", file: "name,email\nJane,jane@example.com\n", want: "route must be a GPX file"},
+		{name: "refuses files without track points", file: `<gpx><wpt lat="53.35" lon="-1.8"/></gpx>`, want: "route file has no track points"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raceRepo := &repositorymocks.RaceRepositoryMock{}
+			svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+
+			_, err := svc.UploadRoute(context.Background(), 7, strings.NewReader(tt.file))
+
+			var fieldErrs FieldErrors
+			if !errors.As(err, &fieldErrs) {
+				t.Fatalf("expected FieldErrors, got %v", err)
+			}
+			if got := fieldErrs["route"]; got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if n := len(raceRepo.SaveRouteCalls()); n != 0 {
+				t.Errorf("expected nothing saved, got %d saves", n)
+			}
+		})
+	}
+}
+
+func TestRaceService_RouteGPX(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write([]byte("<gpx></gpx>")); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	raceRepo := &repositorymocks.RaceRepositoryMock{
+		GetRouteGPXFunc: func(ctx context.Context, raceID int64) ([]byte, error) {
+			if raceID != 7 {
+				return nil, repository.ErrNotFound
+			}
+			return compressed.Bytes(), nil
+		},
+	}
+	svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+
+	got, err := svc.RouteGPX(context.Background(), 7)
+	if err != nil || string(got) != "<gpx></gpx>" {
+		t.Errorf("expected the decompressed file, got %q (err %v)", got, err)
+	}
+	if _, err := svc.RouteGPX(context.Background(), 8); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a race without a route, got %v", err)
+	}
+}
+
+func TestRaceService_SetRequiredQuestions(t *testing.T) {
+	var stored []string
+	raceRepo := &repositorymocks.RaceRepositoryMock{
+		SetRequiredQuestionsFunc: func(ctx context.Context, raceID int64, questions []string) error {
+			stored = questions
+			return nil
+		},
+	}
+	svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+
+	if err := svc.SetRequiredQuestions(context.Background(), 7, []string{"medical_conditions", "emergency_contact_name"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(stored, ",") != "emergency_contact_name,medical_conditions" {
+		t.Errorf("expected the questions in form order, got %v", stored)
+	}
+
+	stored = nil
+	if err := svc.SetRequiredQuestions(context.Background(), 7, []string{"shoe_size"}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+	if stored != nil {
+		t.Errorf("expected nothing stored, got %v", stored)
+	}
+}
+
+func TestRaceService_SetLockedQuestions(t *testing.T) {
+	var stored []string
+	raceRepo := &repositorymocks.RaceRepositoryMock{
+		SetLockedQuestionsFunc: func(ctx context.Context, raceID int64, questions []string) error {
+			stored = questions
+			return nil
+		},
+	}
+	svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+
+	if err := svc.SetLockedQuestions(context.Background(), 7, []string{"estimated_finish", "club", "club"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(stored, ",") != "club,estimated_finish" {
+		t.Errorf("expected the questions in form order, got %v", stored)
+	}
+
+	stored = nil
+	if err := svc.SetLockedQuestions(context.Background(), 7, []string{"shoe_size"}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+	if stored != nil {
+		t.Errorf("expected nothing stored, got %v", stored)
+	}
+}
+
+func TestRaceService_SetDistance(t *testing.T) {
+	var stored *db.SetRaceDistanceParams
+	raceRepo := &repositorymocks.RaceRepositoryMock{
+		SetDistanceFunc: func(ctx context.Context, params db.SetRaceDistanceParams) (db.Race, error) {
+			stored = &params
+			return db.Race{ID: params.ID, DistanceMetres: params.DistanceMetres, ElevationGainMetres: params.ElevationGainMetres}, nil
+		},
+	}
+	svc := NewRaceService(raceRepo, &repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.EventRepositoryMock{}, nil)
+
+	t.Run("stores the distance and climb", func(t *testing.T) {
+		stored = nil
+		if _, err := svc.SetDistance(context.Background(), 7, 42195, 1250); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stored == nil || stored.ID != 7 || stored.DistanceMetres.Int32 != 42195 || stored.ElevationGainMetres.Int32 != 1250 {
+			t.Errorf("unexpected params passed to repository: %+v", stored)
+		}
+	})
+
+	t.Run("clears figures given as zero", func(t *testing.T) {
+		stored = nil
+		if _, err := svc.SetDistance(context.Background(), 7, 0, 0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stored == nil || stored.DistanceMetres.Valid || stored.ElevationGainMetres.Valid {
+			t.Errorf("expected both figures cleared, got %+v", stored)
+		}
+	})
+
+	tests := []struct {
+		name            string
+		distance, climb int
+		field           string
+	}{
+		{name: "negative distance", distance: -1, field: "distance"},
+		{name: "distance of 5,000 km", distance: MaxDistanceMetres, field: "distance"},
+		{name: "negative climb", distance: 10000, climb: -5, field: "elevation_gain"},
+	}
+	for _, tt := range tests {
+		t.Run("refuses a "+tt.name, func(t *testing.T) {
+			stored = nil
+			_, err := svc.SetDistance(context.Background(), 7, tt.distance, tt.climb)
+
+			var errs FieldErrors
+			if !errors.As(err, &errs) || errs[tt.field] == "" {
+				t.Errorf("expected a %s error, got %v", tt.field, err)
+			}
+			if stored != nil {
+				t.Errorf("expected nothing stored, got %+v", stored)
+			}
+		})
+	}
+
+	t.Run("is checked when a race is created", func(t *testing.T) {
+		input := CreateRaceInput{EventID: 1, Name: "Ultra", Slug: "ultra", MaxCapacity: 100, DistanceMetres: MaxDistanceMetres + 1}
+		if _, err := svc.CreateRace(context.Background(), input); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
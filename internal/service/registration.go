@@ -0,0 +1,939 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/bus"
+	"firecrest/internal/cache"
+	"firecrest/internal/mail"
+	"firecrest/internal/repository"
+	"firecrest/internal/secret"
+	"firecrest/internal/token"
+	"firecrest/internal/webhook"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/registration.go -pkg servicemocks . RegistrationCounter RegistrationService
+
+// RegistrationCountTTL is how long cached registration counts are served
+// before they are reloaded from the database.
+const RegistrationCountTTL = 30 * time.Second
+
+// TransferTokenTTL is how long the recipient of a transferred registration
+// has to accept it through the emailed link.
+const TransferTokenTTL = 7 * 24 * time.Hour
+
+// RaceReminderLead is how long before a race its entrants are reminded.
+const RaceReminderLead = 7 * 24 * time.Hour
+
+// UnsubscribeTokenTTL is how long the unsubscribe link in a reminder works.
+// It outlives the race so a reminder read late can still be acted on.
+const UnsubscribeTokenTTL = 90 * 24 * time.Hour
+
+// Team sizes an entrant may reserve places for, the captain included.
+const (
+	MinTeamSize = 2
+	MaxTeamSize = 20
+)
+
+// RegistrationCounter reports the number of active registrations per event.
+type RegistrationCounter interface {
+	// CountByEvents returns active registration counts keyed by event ID.
+	// Events with no registrations are absent from the map.
+	CountByEvents(ctx context.Context, eventIDs []int64) (map[int64]int, error)
+	// CountByRace returns active registration counts of the event's races
+	// keyed by race ID. Races with no registrations are absent from the map,
+	// which may be shared with other callers and must not be changed.
+	CountByRace(ctx context.Context, eventID int64) (map[int64]int, error)
+	// Invalidate discards any cached count for the event so the next read
+	// reflects a new or cancelled registration.
+	Invalidate(eventID int64)
+}
+
+// registrationCountEntries bounds how many events' counts are cached.
+const registrationCountEntries = 10000
+
+type cachedRegistrationCounter struct {
+	repo   repository.RegistrationRepository
+	ttl    time.Duration
+	counts *cache.Cache[int64, int]
+	// raceCounts holds each event's counts by race, keyed by event
+	raceCounts *cache.Cache[int64, map[int64]int]
+}
+
+// NewRegistrationCounter creates a RegistrationCounter that caches counts in
+// memory for ttl, loading any missing events with a single repository query.
+func NewRegistrationCounter(repo repository.RegistrationRepository, ttl time.Duration) RegistrationCounter {
+	return newRegistrationCounter(repo, ttl, RealClock{})
+}
+
+func newRegistrationCounter(repo repository.RegistrationRepository, ttl time.Duration, clock Clock) *cachedRegistrationCounter {
+	return &cachedRegistrationCounter{
+		repo: repo,
+		ttl:  ttl,
+		counts: cache.New[int64, int](cache.Options{
+			MaxEntries:      registrationCountEntries,
+			JanitorInterval: ttl,
+			Clock:           clock,
+		}),
+		raceCounts: cache.New[int64, map[int64]int](cache.Options{
+			MaxEntries:      registrationCountEntries,
+			JanitorInterval: ttl,
+			Clock:           clock,
+		}),
+	}
+}
+
+func (c *cachedRegistrationCounter) CountByEvents(ctx context.Context, eventIDs []int64) (map[int64]int, error) {
+	counts := make(map[int64]int, len(eventIDs))
+
+	var missing []int64
+	for _, id := range eventIDs {
+		count, ok := c.counts.Get(id)
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		if count > 0 {
+			counts[id] = count
+		}
+	}
+
+	if len(missing) == 0 {
+		return counts, nil
+	}
+
+	loaded, err := c.repo.CountRegistrationsByEvent(ctx, missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count registrations: %w", err)
+	}
+
+	for _, id := range missing {
+		count := loaded[id]
+		c.counts.Set(id, count, c.ttl)
+		if count > 0 {
+			counts[id] = count
+		}
+	}
+
+	return counts, nil
+}
+
+func (c *cachedRegistrationCounter) CountByRace(ctx context.Context, eventID int64) (map[int64]int, error) {
+	if counts, ok := c.raceCounts.Get(eventID); ok {
+		return counts, nil
+	}
+
+	counts, err := c.repo.CountByRaceForEvent(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count registrations: %w", err)
+	}
+	c.raceCounts.Set(eventID, counts, c.ttl)
+	return counts, nil
+}
+
+// SubscribeRegistrationCounter discards counter's counts for an event as
+// soon as an entry to it is made or cancelled, or one of its races changes,
+// so the next read reflects it.
+func SubscribeRegistrationCounter(b *bus.Bus, counter RegistrationCounter) {
+	invalidateRegistration := func(ctx context.Context, c bus.RegistrationChange) error {
+		counter.Invalidate(c.EventID)
+		return nil
+	}
+	bus.Subscribe(b, bus.RegistrationCreated, "registration counter", invalidateRegistration)
+	bus.Subscribe(b, bus.RegistrationCancelled, "registration counter", invalidateRegistration)
+	bus.Subscribe(b, bus.RaceUpdated, "registration counter", func(ctx context.Context, c bus.RaceChange) error {
+		counter.Invalidate(c.EventID)
+		return nil
+	})
+}
+
+func (c *cachedRegistrationCounter) Invalidate(eventID int64) {
+	c.counts.Invalidate(eventID)
+	c.raceCounts.Invalidate(eventID)
+}
+
+// Registration errors
+var (
+	ErrForbidden           = apperr.New(apperr.CodeForbidden, http.StatusForbidden, "not permitted")
+	ErrAlreadyCancelled    = apperr.New(apperr.CodeAlreadyCancelled, http.StatusConflict, "registration is already cancelled")
+	ErrCancellationClosed  = apperr.New(apperr.CodeCancellationClosed, http.StatusConflict, "cancellation deadline has passed")
+	ErrRaceFull            = apperr.New(apperr.CodeRaceFull, http.StatusConflict, "race is full")
+	ErrTransferClosed      = apperr.New(apperr.CodeTransferClosed, http.StatusConflict, "transfer deadline has passed")
+	ErrRecipientRegistered = apperr.New(apperr.CodeRecipientRegistered, http.StatusConflict, "recipient is already registered for this race")
+	ErrAlreadyRegistered   = apperr.New(apperr.CodeAlreadyRegistered, http.StatusConflict, "already registered for this race")
+	ErrRegistrationClosed  = apperr.New(apperr.CodeRegistrationClosed, http.StatusConflict, "registration is not open")
+	ErrInvalidTeam         = apperr.New(apperr.CodeInvalidTeam, http.StatusUnprocessableEntity, "team needs a name and a valid size")
+	ErrInvalidTeamCode     = apperr.New(apperr.CodeInvalidTeamCode, http.StatusUnprocessableEntity, "invalid team invite code")
+	ErrTeamClosed          = apperr.New(apperr.CodeTeamClosed, http.StatusConflict, "team is no longer taking members")
+	ErrTeamFull            = apperr.New(apperr.CodeTeamFull, http.StatusConflict, "team is full")
+	ErrWaveNotFound        = apperr.New(apperr.CodeWaveNotFound, http.StatusUnprocessableEntity, "race has no such start wave")
+)
+
+// RegistrationService defines the interface for registration business logic.
+type RegistrationService interface {
+	// Register enters the user in the race online, pending payment. It
+	// returns ErrAlreadyRegistered, with the registration they hold when it
+	// is known, if they already have a place in the race;
+	// ErrRegistrationClosed outside its registration window or while its
+	// event is not published; and ErrRaceFull once every place is taken. Entrants who cancelled may register again.
+	// The entry is charged the race's price when it is made, from the tier
+	// current then; an entry beaten to a tier's last place pays the price
+	// that follows.
+	// A non-empty discountCode is checked with DiscountService.ValidateCode,
+	// returning its errors, and the entry is priced with its discount; the
+	// code is used up by the entry even if it is later cancelled.
+	// The entrant is emailed a confirmation whatever their email
+	// preference, as it concerns an entry they have just made.
+	// answers must answer every question the race requires and are checked
+	// with Answers.Validate, returning its FieldErrors; any given are
+	// stored encrypted with the entry. custom holds the values given for
+	// the race's custom fields, keyed by field ID; they are checked with
+	// CustomField.Answer, returning FieldErrors keyed by each field's
+	// FormName, and stored as CustomAnswers.
+	// Races with a minimum age need the entrant's date of birth, asked for
+	// with FieldErrors for "date_of_birth" until they give one, and return
+	// ErrTooYoung if they will be under it on race day.
+	// Races with start waves put the entrant in the wave waveID, or the
+	// next with room once it is full, and in the emptiest for a zero
+	// waveID; a wave the race does not have returns ErrWaveNotFound and
+	// ErrRaceFull is returned once every wave is full.
+	// Users who have not verified their email address are refused with
+	// ErrEmailNotVerified.
+	Register(ctx context.Context, userID int64, race db.Race, waveID int64, discountCode string, answers Answers, custom map[int64]string) (db.Registration, error)
+	// SendRaceReminders emails entrants whose race starts within
+	// RaceReminderLead and returns how many were sent. Each registration is
+	// reminded at most once, and entrants who only accept transactional
+	// email are skipped.
+	SendRaceReminders(ctx context.Context) (int, error)
+	// SendRegistrationDigests emails each organisation member who asked for
+	// a daily digest a summary of the registrations made for the
+	// organisation's events the previous day, in UTC, and returns how many
+	// were sent. Each member gets at most one digest a day, and none for a
+	// day without registrations.
+	SendRegistrationDigests(ctx context.Context) (int, error)
+	// CancelRegistration cancels a registration on behalf of userID, who must
+	// either own it or belong to the organisation running the race.
+	CancelRegistration(ctx context.Context, userID, registrationID int64) (Cancellation, error)
+	// ListUserRegistrations returns the user's registrations, noting which
+	// they may still cancel, transfer or edit themselves.
+	ListUserRegistrations(ctx context.Context, userID int64) ([]UserRegistration, error)
+	// ListRaceEntrants returns everyone registered for the race, for its
+	// organisers.
+	ListRaceEntrants(ctx context.Context, raceID int64) ([]db.ListRaceEntrantsRow, error)
+	// ListRaceAnswers returns the questionnaire answers of the race's
+	// entrants, keyed by registration ID, for its organisers. Callers must
+	// leave out the medical answers for organisers who cannot read them.
+	ListRaceAnswers(ctx context.Context, raceID int64) (map[int64]Answers, error)
+	// ListRaceCustomAnswers returns the answers to custom fields of the
+	// race's registrations, keyed by registration ID.
+	ListRaceCustomAnswers(ctx context.Context, raceID int64) (map[int64]CustomAnswers, error)
+	// TransferRegistration hands the owner's registration to the entrant
+	// with recipientEmail, creating an account for them if needed, and
+	// emails them a link to accept it. Transfers close the configured cutoff
+	// before the race's registration close date.
+	TransferRegistration(ctx context.Context, ownerUserID, registrationID int64, recipientEmail string) (Transfer, error)
+	// RegistrationDetails returns the questionnaire answers of the user's
+	// registration for them to edit. It returns repository.ErrNotFound for
+	// registrations that are not the user's or are cancelled, and
+	// ErrEditClosed from the configured lock time before the race starts.
+	RegistrationDetails(ctx context.Context, userID, registrationID int64) (RegistrationDetails, error)
+	// UpdateRegistrationDetails replaces the user's answers to the questions
+	// RegistrationDetails asks, returning its errors. The answers are
+	// checked with Answers.Validate, and changes to locked answers are
+	// refused, both with FieldErrors. Edits are recorded in the audit log
+	// by the questions they change, and organisers' exports show the new
+	// answers.
+	UpdateRegistrationDetails(ctx context.Context, userID, registrationID int64, answers Answers) error
+	// AcceptTransfers accepts every transfer waiting on the user the token
+	// was issued to.
+	AcceptTransfers(ctx context.Context, token string) error
+	// ImportEntrants registers the entrants listed in a CSV file for the
+	// race as confirmed, paid outside Firecrest. The file has a header row
+	// naming email, first name, last name and optional bib columns.
+	ImportEntrants(ctx context.Context, race db.Race, file io.Reader) (ImportReport, error)
+	// CreateTeam enters a team of size in the race with the captain in its
+	// first place, reserving the rest for members who join with the returned
+	// team's invite code. It returns ErrInvalidTeam for a blank name or a
+	// size outside MinTeamSize..MaxTeamSize, ErrRegistrationClosed outside
+	// the race's registration window or while its event is not published,
+	// and ErrRaceFull if too few places are
+	// left for the whole team. Places still unfilled after the configured
+	// fill window, or when registration closes if sooner, are released.
+	// Like Register, it returns ErrEmailNotVerified for a captain who has
+	// not verified their email address.
+	CreateTeam(ctx context.Context, captainUserID, raceID int64, name string, size int) (db.Team, error)
+	// JoinTeam enters the user in the race in one of the places reserved
+	// for the team with the invite code. It returns ErrInvalidTeamCode if no
+	// team has the code, ErrTeamClosed once the team's places have been
+	// released, registration has closed or the event is no longer
+	// published, and ErrTeamFull when every place
+	// is taken. Like Register, it returns ErrEmailNotVerified for a user
+	// who has not verified their email address.
+	JoinTeam(ctx context.Context, userID int64, code string) (db.Registration, error)
+	// AssignBibNumbers numbers the race's confirmed registrations that have
+	// no bib yet, earliest registered first, counting up from startAt and
+	// skipping bibs already taken and the configured reserved range. It
+	// returns how many were numbered. Entrants keep the bibs they have, so
+	// assigning again only numbers new entrants, filling any gaps left by
+	// cancellations.
+	AssignBibNumbers(ctx context.Context, raceID int64, startAt int) (int, error)
+	// SetBib gives the registration a bib of the organiser's choosing, which
+	// may be in the reserved range. It returns repository.ErrConflict if
+	// another entrant in the race has the bib and repository.ErrNotFound if
+	// the registration is cancelled.
+	SetBib(ctx context.Context, registrationID int64, bib int) error
+	// CheckIn marks the race's confirmed registration checked in on race
+	// day, keeping the time of any earlier check-in. It returns
+	// repository.ErrNotFound if the race has no such confirmed
+	// registration.
+	CheckIn(ctx context.Context, raceID, registrationID int64) error
+	// UndoCheckIn clears a check-in made by mistake, which is only allowed
+	// within CheckInUndoWindow of it. It returns ErrCheckInFinal once the
+	// window has passed and repository.ErrNotFound if the race has no such
+	// registration. Undoing a registration that is not checked in does
+	// nothing.
+	UndoCheckIn(ctx context.Context, raceID, registrationID int64) error
+	// ListWaves returns the race's start waves, earliest first, with how
+	// many entrants hold a place in each.
+	ListWaves(ctx context.Context, raceID int64) ([]db.ListRaceWavesRow, error)
+	// CreateWave adds a start wave to the race, reading its start in the
+	// event's time zone. Invalid input and names the race already uses
+	// are refused with FieldErrors.
+	CreateWave(ctx context.Context, event db.Event, raceID int64, input WaveInput) (db.RaceWave, error)
+	// UpdateWave changes one of the race's start waves, returning
+	// repository.ErrNotFound if it has no such wave. A capacity below the
+	// places entrants already hold is refused with FieldErrors, like
+	// invalid input. Moving the wave's start emails its entrants the new
+	// time.
+	UpdateWave(ctx context.Context, event db.Event, race db.Race, waveID int64, input WaveInput) (db.RaceWave, error)
+	// DeleteWave removes one of the race's start waves. It returns
+	// ErrWaveInUse while entrants hold places in it and
+	// repository.ErrNotFound if the race has no such wave.
+	DeleteWave(ctx context.Context, raceID, waveID int64) error
+}
+
+// UserRegistration is one of a user's registrations as shown on their account.
+type UserRegistration struct {
+	db.ListRegistrationsByUserRow
+	CanCancel   bool
+	CanTransfer bool
+	CanEdit     bool
+}
+
+// Cancellation describes the outcome of a cancelled registration.
+type Cancellation struct {
+	RegistrationID int64
+	RefundUnits    int32
+	Currency       string
+}
+
+// Transfer describes a registration handed to another entrant.
+type Transfer struct {
+	RegistrationID int64
+	RecipientEmail string
+	// Invited reports whether the recipient had no account and one was
+	// created for them.
+	Invited bool
+}
+
+type registrationService struct {
+	registrationRepo repository.RegistrationRepository
+	orgRepo          repository.OrganisationRepository
+	raceRepo         repository.RaceRepository
+	payments         PaymentService
+	discounts        DiscountService
+	events           *bus.Bus
+	mailer           mail.Mailer
+	webhookRepo      repository.WebhookRepository
+	tokens           *token.Signer
+	answersBox       *secret.Box
+	baseURL          string
+	gracePeriod      time.Duration
+	transferCutoff   time.Duration
+	editLock         time.Duration
+	teamFillWindow   time.Duration
+	importMaxRows    int
+	reservedBibs     BibRange
+	clock            Clock
+}
+
+// NewRegistrationService creates a new RegistrationService. Entrants may
+// cancel until gracePeriod after their race's registration close date and
+// transfer until transferCutoff before it, and edit their details until
+// editLock before the race starts. Teams have teamFillWindow to fill
+// their places, imports are limited to importMaxRows entrants, and bibs in
+// reservedBibs are left out when numbering entrants. Confirmation, reminder and transfer emails are sent
+// through mailer with links rooted at baseURL, carrying tokens signed by
+// tokens, and transfers are queued for organisers' webhooks through
+// webhookRepo. Entries made and cancelled are published on events. Entries made with a discount code are priced through discounts,
+// and questionnaire answers are encrypted with answersBox.
+func NewRegistrationService(
+	registrationRepo repository.RegistrationRepository,
+	orgRepo repository.OrganisationRepository,
+	raceRepo repository.RaceRepository,
+	payments PaymentService,
+	discounts DiscountService,
+	events *bus.Bus,
+	mailer mail.Mailer,
+	webhookRepo repository.WebhookRepository,
+	tokens *token.Signer,
+	answersBox *secret.Box,
+	baseURL string,
+	gracePeriod time.Duration,
+	transferCutoff time.Duration,
+	editLock time.Duration,
+	teamFillWindow time.Duration,
+	importMaxRows int,
+	reservedBibs BibRange,
+) RegistrationService {
+	return &registrationService{
+		registrationRepo: registrationRepo,
+		orgRepo:          orgRepo,
+		raceRepo:         raceRepo,
+		payments:         payments,
+		discounts:        discounts,
+		events:           events,
+		mailer:           mailer,
+		webhookRepo:      webhookRepo,
+		tokens:           tokens,
+		answersBox:       answersBox,
+		baseURL:          strings.TrimRight(baseURL, "/"),
+		gracePeriod:      gracePeriod,
+		transferCutoff:   transferCutoff,
+		editLock:         editLock,
+		teamFillWindow:   teamFillWindow,
+		importMaxRows:    importMaxRows,
+		reservedBibs:     reservedBibs,
+		clock:            RealClock{},
+	}
+}
+
+func (s *registrationService) Register(ctx context.Context, userID int64, race db.Race, waveID int64, discountCode string, answers Answers, custom map[int64]string) (db.Registration, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.Register")
+	defer span.End()
+
+	if !registrationOpen(race, s.clock.Now()) {
+		return db.Registration{}, ErrRegistrationClosed
+	}
+	if err := s.checkEmailVerified(ctx, userID); err != nil {
+		return db.Registration{}, err
+	}
+
+	// Checked up front for a clear answer; the database enforces it too,
+	// for two entries racing each other
+	existing, err := s.registrationRepo.GetActive(ctx, userID, race.ID)
+	switch {
+	case err == nil:
+		return existing, ErrAlreadyRegistered
+	case !errors.Is(err, repository.ErrNotFound):
+		return db.Registration{}, fmt.Errorf("failed to check registrations: %w", err)
+	}
+
+	params := db.CreateRegistrationParams{UserID: userID, RaceID: race.ID}
+	if waveID != 0 {
+		params.WaveID = pgtype.Int8{Int64: waveID, Valid: true}
+	}
+	var discount *Discount
+	if strings.TrimSpace(discountCode) != "" {
+		d, err := s.discounts.ValidateCode(ctx, discountCode, race.ID)
+		if err != nil {
+			return db.Registration{}, err
+		}
+		discount = &d
+		params.DiscountCodeID = pgtype.Int8{Int64: d.CodeID, Valid: true}
+	}
+
+	errs := FieldErrors{}
+	if race.MinAge.Valid {
+		if err := s.checkAge(ctx, userID, race, errs); err != nil {
+			return db.Registration{}, err
+		}
+	}
+	required, err := requiredQuestions(ctx, s.raceRepo, race.ID)
+	if err != nil {
+		return db.Registration{}, err
+	}
+	var answerErrs FieldErrors
+	if err := answers.Validate(required); errors.As(err, &answerErrs) {
+		for field, msg := range answerErrs {
+			errs.Add(field, msg)
+		}
+	}
+	fields, err := listCustomFields(ctx, s.raceRepo, race.ID)
+	if err != nil {
+		return db.Registration{}, err
+	}
+	customAnswers := checkCustomAnswers(fields, custom, errs)
+	if err := errs.Err(); err != nil {
+		return db.Registration{}, err
+	}
+	var sealed, customJSON []byte
+	if !answers.IsZero() {
+		if sealed, err = sealAnswers(s.answersBox, answers); err != nil {
+			return db.Registration{}, err
+		}
+	}
+	if len(customAnswers) > 0 {
+		if customJSON, err = json.Marshal(customAnswers); err != nil {
+			return db.Registration{}, fmt.Errorf("failed to encode custom answers: %w", err)
+		}
+	}
+
+	// Priced here rather than from the page, so the entrant pays what the
+	// tier current now and their code actually allow. An entry beaten to a
+	// tier's last place is priced again.
+	var reg db.Registration
+	for attempt := 1; ; attempt++ {
+		if err := s.price(ctx, race, discount, &params); err != nil {
+			return db.Registration{}, err
+		}
+		reg, err = s.registrationRepo.Create(ctx, params, sealed, customJSON)
+		if !errors.Is(err, repository.ErrPriceTierFull) || attempt == maxPriceAttempts {
+			break
+		}
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDiscountExhausted):
+			return db.Registration{}, ErrDiscountCodeExhausted
+		case errors.Is(err, repository.ErrAlreadyRegistered):
+			return db.Registration{}, ErrAlreadyRegistered
+		case errors.Is(err, repository.ErrCapacityExceeded):
+			return db.Registration{}, ErrRaceFull
+		case errors.Is(err, repository.ErrNotPublished):
+			return db.Registration{}, ErrRegistrationClosed
+		case errors.Is(err, repository.ErrNotFound) && params.WaveID.Valid:
+			// The race was loaded moments ago, so it is the wave that has
+			// gone, or was never the race's
+			return db.Registration{}, ErrWaveNotFound
+		case errors.Is(err, repository.ErrNotFound):
+			return db.Registration{}, err
+		}
+		return db.Registration{}, fmt.Errorf("failed to create registration: %w", err)
+	}
+	bus.Publish(ctx, s.events, bus.RegistrationCreated, bus.RegistrationChange{RegistrationID: reg.ID, RaceID: race.ID, EventID: race.EventID})
+
+	if err := s.sendConfirmationEmail(ctx, reg.ID); err != nil {
+		return reg, err
+	}
+	return reg, nil
+}
+
+// price sets the fee params charges for an entry to race made now, at the
+// current price tier less any discount.
+func (s *registrationService) price(ctx context.Context, race db.Race, discount *Discount, params *db.CreateRegistrationParams) error {
+	price, err := currentPrice(ctx, s.raceRepo, race, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	params.PriceTierID = pgtype.Int8{}
+	if price.Tier != nil {
+		params.PriceTierID = pgtype.Int8{Int64: price.Tier.ID, Valid: true}
+	}
+	params.DiscountUnits = 0
+	if discount != nil {
+		params.DiscountUnits = discount.Apply(price.Units)
+	}
+	// Races without a price, and outside every tier, record none
+	params.PriceUnits = pgtype.Int4{Int32: price.Units - params.DiscountUnits, Valid: race.PriceUnits.Valid || price.Tier != nil}
+	return nil
+}
+
+// checkAge returns ErrTooYoung if the user will be under the race's minimum
+// age on race day, or today for a race not yet scheduled. Users who have not
+// given a date of birth are asked for it through errs.
+func (s *registrationService) checkAge(ctx context.Context, userID int64, race db.Race, errs FieldErrors) error {
+	check, err := s.registrationRepo.GetAgeCheck(ctx, userID, race.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to check age: %w", err)
+	}
+	if !check.DateOfBirth.Valid {
+		errs.Add("date_of_birth", "date of birth is required to enter this race")
+		return nil
+	}
+
+	day := s.clock.Now()
+	if check.RaceDay.Valid {
+		day = check.RaceDay.Time
+	}
+	if Age(check.DateOfBirth.Time, day) < int(race.MinAge.Int32) {
+		return fmt.Errorf("%w: entrants must be %d or over on race day", ErrTooYoung, race.MinAge.Int32)
+	}
+	return nil
+}
+
+// checkEmailVerified returns ErrEmailNotVerified if the user has not
+// verified their email address. Unverified users may still sign in during
+// the verification grace period, so entries are refused here instead.
+func (s *registrationService) checkEmailVerified(ctx context.Context, userID int64) error {
+	verified, err := s.registrationRepo.IsEmailVerified(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check email verification: %w", err)
+	}
+	if !verified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// registrationOpen reports whether now falls within the race's registration
+// window.
+func registrationOpen(race db.Race, now time.Time) bool {
+	if race.RegistrationOpenDate.Valid && now.Before(race.RegistrationOpenDate.Time) {
+		return false
+	}
+	return !race.RegistrationCloseDate.Valid || now.Before(race.RegistrationCloseDate.Time)
+}
+
+// sendConfirmationEmail queues an email confirming the registration to its
+// entrant, with a link to their entry, and tells the organisers who asked
+// to hear about every registration.
+func (s *registrationService) sendConfirmationEmail(ctx context.Context, registrationID int64) error {
+	reg, err := s.registrationRepo.GetForConfirmation(ctx, registrationID)
+	if err != nil {
+		return fmt.Errorf("failed to load registration for confirmation: %w", err)
+	}
+
+	msg, err := mail.RegistrationConfirmationMessage(reg.Email, mail.RegistrationConfirmationData{
+		FirstName: reg.FirstName,
+		RaceName:  reg.RaceName,
+		EventName: reg.EventName,
+		Date:      formatRaceDate(reg.StartsAt),
+		Wave:      reg.WaveName.String,
+		WaveStart: formatRaceDate(reg.WaveStartsAt),
+		EntryURL:  s.entryURL(reg.ID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build confirmation email: %w", err)
+	}
+
+	// Delivery happens in the background and the mailer logs any failure;
+	// the entrant is registered either way.
+	_ = s.mailer.Send(ctx, msg)
+	return s.notifyOrganisers(ctx, reg)
+}
+
+func (s *registrationService) SendRaceReminders(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.SendRaceReminders")
+	defer span.End()
+
+	now := s.clock.Now()
+	// Claiming marks the registrations reminded before anything is sent, so
+	// an overlapping or repeated run cannot email anyone twice. A reminder
+	// that then fails to build is lost rather than retried.
+	regs, err := s.registrationRepo.ClaimReminders(ctx, now, now.Add(RaceReminderLead))
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim race reminders: %w", err)
+	}
+
+	sent := 0
+	var errs []error
+	for _, reg := range regs {
+		if err := s.sendReminderEmail(ctx, reg); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// sendReminderEmail queues an email reminding the entrant about their race,
+// with links to their entry and to unsubscribe from reminders.
+func (s *registrationService) sendReminderEmail(ctx context.Context, reg db.ClaimRaceRemindersRow) error {
+	tok, err := s.tokens.SignToken(token.PurposeUnsubscribe, reg.UserID, UnsubscribeTokenTTL)
+	if err != nil {
+		return fmt.Errorf("failed to generate unsubscribe token: %w", err)
+	}
+
+	msg, err := mail.RaceReminderMessage(reg.Email, mail.RaceReminderData{
+		FirstName:      reg.FirstName,
+		RaceName:       reg.RaceName,
+		EventName:      reg.EventName,
+		Date:           formatRaceDate(reg.StartsAt),
+		EntryURL:       s.entryURL(reg.ID),
+		UnsubscribeURL: s.baseURL + "/email/unsubscribe?token=" + url.QueryEscape(tok),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build reminder email for registration %d: %w", reg.ID, err)
+	}
+
+	_ = s.mailer.Send(ctx, msg)
+	return nil
+}
+
+// entryURL links to the registration on the entrant's account page.
+func (s *registrationService) entryURL(registrationID int64) string {
+	return fmt.Sprintf("%s/account/registrations#registration-%d", s.baseURL, registrationID)
+}
+
+// formatRaceDate formats a race's start for emails, or returns "" for a race
+// not yet scheduled.
+func formatRaceDate(startsAt pgtype.Timestamptz) string {
+	if !startsAt.Valid {
+		return ""
+	}
+	return startsAt.Time.Format("Monday 2 January 2006 at 15:04")
+}
+
+func (s *registrationService) CancelRegistration(ctx context.Context, userID, registrationID int64) (Cancellation, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.CancelRegistration")
+	defer span.End()
+
+	reg, err := s.registrationRepo.GetForCancellation(ctx, registrationID)
+	if err != nil {
+		return Cancellation{}, err
+	}
+
+	// Organisers may cancel any registration in their races at any time;
+	// entrants may only cancel their own, and only before the deadline.
+	isOwner := reg.UserID == userID
+	if !isOwner {
+		member, err := s.orgRepo.IsMember(ctx, reg.OrganisationID, userID)
+		if err != nil {
+			return Cancellation{}, fmt.Errorf("failed to check organisation membership: %w", err)
+		}
+		if !member {
+			return Cancellation{}, ErrForbidden
+		}
+	}
+
+	if reg.Status == db.RegistrationStatusCancelled {
+		return Cancellation{}, ErrAlreadyCancelled
+	}
+
+	now := s.clock.Now()
+	var closeDate time.Time
+	if reg.RegistrationCloseDate.Valid {
+		closeDate = reg.RegistrationCloseDate.Time
+	}
+	if isOwner && s.cancellationClosed(closeDate, now) {
+		return Cancellation{}, ErrCancellationClosed
+	}
+
+	if err := s.registrationRepo.Cancel(ctx, reg.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return Cancellation{}, ErrAlreadyCancelled
+		}
+		return Cancellation{}, fmt.Errorf("failed to cancel registration: %w", err)
+	}
+	bus.Publish(ctx, s.events, bus.RegistrationCancelled, bus.RegistrationChange{RegistrationID: reg.ID, RaceID: reg.RaceID, EventID: reg.EventID})
+
+	result := Cancellation{RegistrationID: reg.ID}
+
+	payment, err := s.payments.SettledPayment(ctx, reg.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to load payment: %w", err)
+	}
+
+	amount := RefundAmount(payment.AmountUnits, now, closeDate)
+	if amount > 0 {
+		if err := s.payments.Refund(ctx, payment, amount); err != nil {
+			return result, err
+		}
+	}
+
+	result.RefundUnits = amount
+	result.Currency = payment.Currency
+	return result, nil
+}
+
+func (s *registrationService) ListUserRegistrations(ctx context.Context, userID int64) ([]UserRegistration, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.ListUserRegistrations")
+	defer span.End()
+
+	if userID <= 0 {
+		return nil, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+
+	rows, err := s.registrationRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list registrations: %w", err)
+	}
+
+	now := s.clock.Now()
+	regs := make([]UserRegistration, 0, len(rows))
+	for _, row := range rows {
+		var closeDate time.Time
+		if row.RegistrationCloseDate.Valid {
+			closeDate = row.RegistrationCloseDate.Time
+		}
+		var startsAt time.Time
+		if row.StartsAt.Valid {
+			startsAt = row.StartsAt.Time
+		}
+		// A place waiting on the user to accept its transfer is not yet
+		// theirs to give up
+		active := row.Status != db.RegistrationStatusCancelled && !row.TransferPending
+		regs = append(regs, UserRegistration{
+			ListRegistrationsByUserRow: row,
+			CanCancel:                  active && !s.cancellationClosed(closeDate, now),
+			CanTransfer:                active && !s.transferClosed(closeDate, now),
+			CanEdit:                    active && !s.editClosed(startsAt, now),
+		})
+	}
+	return regs, nil
+}
+
+func (s *registrationService) ListRaceEntrants(ctx context.Context, raceID int64) ([]db.ListRaceEntrantsRow, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.ListRaceEntrants")
+	defer span.End()
+
+	return s.registrationRepo.ListByRace(ctx, raceID)
+}
+
+func (s *registrationService) ListRaceAnswers(ctx context.Context, raceID int64) (map[int64]Answers, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.ListRaceAnswers")
+	defer span.End()
+
+	rows, err := s.registrationRepo.ListAnswers(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list answers: %w", err)
+	}
+
+	answers := make(map[int64]Answers, len(rows))
+	for _, row := range rows {
+		a, err := openAnswers(s.answersBox, row.AnswersSealed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open answers of registration %d: %w", row.RegistrationID, err)
+		}
+		answers[row.RegistrationID] = a
+	}
+	return answers, nil
+}
+
+func (s *registrationService) TransferRegistration(ctx context.Context, ownerUserID, registrationID int64, recipientEmail string) (Transfer, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.TransferRegistration")
+	defer span.End()
+
+	email := NormalizeEmail(recipientEmail)
+	if email == "" {
+		return Transfer{}, fmt.Errorf("%w: recipient email is required", ErrInvalidInput)
+	}
+	if err := ValidateEmail(email); err != nil {
+		return Transfer{}, err
+	}
+
+	reg, err := s.registrationRepo.GetForTransfer(ctx, registrationID)
+	if err != nil {
+		return Transfer{}, err
+	}
+	// Only entrants transfer their places; organisers cancel instead
+	if reg.UserID != ownerUserID {
+		return Transfer{}, ErrForbidden
+	}
+	if reg.Status == db.RegistrationStatusCancelled {
+		return Transfer{}, ErrAlreadyCancelled
+	}
+	if email == NormalizeEmail(reg.OwnerEmail) {
+		return Transfer{}, fmt.Errorf("%w: you cannot transfer a place to yourself", ErrInvalidInput)
+	}
+
+	var closeDate time.Time
+	if reg.RegistrationCloseDate.Valid {
+		closeDate = reg.RegistrationCloseDate.Time
+	}
+	if s.transferClosed(closeDate, s.clock.Now()) {
+		return Transfer{}, ErrTransferClosed
+	}
+
+	moved, err := s.registrationRepo.Transfer(ctx, repository.TransferParams{
+		RegistrationID: reg.ID,
+		RaceID:         reg.RaceID,
+		FromUserID:     ownerUserID,
+		RecipientEmail: email,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAlreadyRegistered):
+			return Transfer{}, ErrRecipientRegistered
+		case errors.Is(err, repository.ErrNotFound):
+			// Cancelled or transferred since it was loaded
+			return Transfer{}, ErrAlreadyCancelled
+		}
+		return Transfer{}, fmt.Errorf("failed to transfer registration: %w", err)
+	}
+
+	result := Transfer{
+		RegistrationID: reg.ID,
+		RecipientEmail: moved.Recipient.Email,
+		Invited:        moved.NewRecipient,
+	}
+	previous := &webhookEntrant{Email: reg.OwnerEmail, FirstName: reg.OwnerFirstName, LastName: reg.OwnerLastName}
+	return result, errors.Join(
+		s.sendTransferEmail(ctx, reg, moved.Recipient),
+		s.queueWebhook(ctx, webhook.EventRegistrationTransferred, reg.ID, previous),
+	)
+}
+
+// sendTransferEmail queues an email telling the recipient about the place
+// transferred to them, with a link to accept it.
+func (s *registrationService) sendTransferEmail(ctx context.Context, reg db.GetRegistrationForTransferRow, recipient db.User) error {
+	tok, err := s.tokens.SignToken(token.PurposeRegistrationTransfer, recipient.ID, TransferTokenTTL)
+	if err != nil {
+		return fmt.Errorf("failed to generate transfer token: %w", err)
+	}
+
+	msg, err := mail.RegistrationTransferMessage(recipient.Email, mail.RegistrationTransferData{
+		FirstName:  recipient.FirstName,
+		SenderName: strings.TrimSpace(reg.OwnerFirstName + " " + reg.OwnerLastName),
+		RaceName:   reg.RaceName,
+		EventName:  reg.EventName,
+		AcceptURL:  s.baseURL + "/transfers/accept?token=" + url.QueryEscape(tok),
+		ExpiresIn:  "7 days",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build transfer email: %w", err)
+	}
+
+	// Delivery happens in the background and the mailer logs any failure;
+	// the place has already moved either way.
+	_ = s.mailer.Send(ctx, msg)
+	return nil
+}
+
+func (s *registrationService) AcceptTransfers(ctx context.Context, tok string) error {
+	ctx, span := startSpan(ctx, "RegistrationService.AcceptTransfers")
+	defer span.End()
+
+	userID, err := s.tokens.VerifyToken(token.PurposeRegistrationTransfer, tok)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	// Accepting is idempotent, so a link opened twice still succeeds
+	if _, err := s.registrationRepo.AcceptTransfers(ctx, userID); err != nil {
+		return fmt.Errorf("failed to accept transfers: %w", err)
+	}
+	return nil
+}
+
+// transferClosed reports whether entrants can no longer transfer a
+// registration for a race closing at closeDate. A zero closeDate never closes.
+func (s *registrationService) transferClosed(closeDate, now time.Time) bool {
+	return !closeDate.IsZero() && !now.Before(closeDate.Add(-s.transferCutoff))
+}
+
+// cancellationClosed reports whether entrants can no longer cancel a
+// registration for a race closing at closeDate. A zero closeDate never closes.
+func (s *registrationService) cancellationClosed(closeDate, now time.Time) bool {
+	return !closeDate.IsZero() && now.After(closeDate.Add(s.gracePeriod))
+}
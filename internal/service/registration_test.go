@@ -0,0 +1,1447 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/bus"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+	"firecrest/internal/secret"
+	"firecrest/internal/token"
+)
+
+// mockPaymentService implements PaymentService for testing.
+type mockPaymentService struct {
+	settledPaymentFunc func(ctx context.Context, registrationID int64) (db.Payment, error)
+	refundFunc         func(ctx context.Context, payment db.Payment, amountUnits int32) error
+}
+
+func (m *mockPaymentService) SettledPayment(ctx context.Context, registrationID int64) (db.Payment, error) {
+	if m.settledPaymentFunc != nil {
+		return m.settledPaymentFunc(ctx, registrationID)
+	}
+	return db.Payment{}, repository.ErrNotFound
+}
+
+func (m *mockPaymentService) Refund(ctx context.Context, payment db.Payment, amountUnits int32) error {
+	if m.refundFunc != nil {
+		return m.refundFunc(ctx, payment, amountUnits)
+	}
+	return nil
+}
+
+func (m *mockPaymentService) RecordCharge(ctx context.Context, charge ProviderCharge) (db.Payment, error) {
+	return db.Payment{}, nil
+}
+
+func (m *mockPaymentService) Report(ctx context.Context, organisationID int64, from, until time.Time) (PaymentReport, error) {
+	return PaymentReport{}, nil
+}
+
+// recordingCounter implements RegistrationCounter and records invalidations.
+type recordingCounter struct {
+	invalidated []int64
+}
+
+func (c *recordingCounter) CountByEvents(ctx context.Context, eventIDs []int64) (map[int64]int, error) {
+	return map[int64]int{}, nil
+}
+
+func (c *recordingCounter) CountByRace(ctx context.Context, eventID int64) (map[int64]int, error) {
+	return map[int64]int{}, nil
+}
+
+func (c *recordingCounter) Invalidate(eventID int64) {
+	c.invalidated = append(c.invalidated, eventID)
+}
+
+// counterBus returns a bus with counter subscribed to it, as it is in the
+// running application.
+func counterBus(counter RegistrationCounter) *bus.Bus {
+	b := bus.New(slog.New(slog.DiscardHandler))
+	SubscribeRegistrationCounter(b, counter)
+	return b
+}
+
+func TestSubscribeRegistrationCounter(t *testing.T) {
+	counter := &recordingCounter{}
+	b := counterBus(counter)
+	ctx := context.Background()
+
+	bus.Publish(ctx, b, bus.RegistrationCreated, bus.RegistrationChange{RegistrationID: 1, RaceID: 2, EventID: 10})
+	bus.Publish(ctx, b, bus.RegistrationCancelled, bus.RegistrationChange{RegistrationID: 1, RaceID: 2, EventID: 11})
+	bus.Publish(ctx, b, bus.RaceUpdated, bus.RaceChange{RaceID: 2, EventID: 12})
+	bus.Publish(ctx, b, bus.EventUpdated, bus.EventChange{EventID: 13})
+
+	if !slices.Equal(counter.invalidated, []int64{10, 11, 12}) {
+		t.Errorf("expected events 10, 11 and 12 invalidated, got %v", counter.invalidated)
+	}
+}
+
+func newTestRegistrationCounter(repo *repositorymocks.RegistrationRepositoryMock, clock Clock) *cachedRegistrationCounter {
+	return newRegistrationCounter(repo, RegistrationCountTTL, clock)
+}
+
+func TestRegistrationCounter_CountByEvents(t *testing.T) {
+	t.Run("loads a page of events with a single query", func(t *testing.T) {
+		queries := 0
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CountRegistrationsByEventFunc: func(ctx context.Context, eventIDs []int64) (map[int64]int, error) {
+				queries++
+				return map[int64]int{1: 342, 2: 10}, nil
+			},
+		}
+
+		eventIDs := make([]int64, 20)
+		for i := range eventIDs {
+			eventIDs[i] = int64(i + 1)
+		}
+
+		counter := newTestRegistrationCounter(repo, &MockClock{CurrentTime: time.Now()})
+		counts, err := counter.CountByEvents(context.Background(), eventIDs)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if queries != 1 {
+			t.Errorf("expected 1 query, got %d", queries)
+		}
+		if counts[1] != 342 || counts[2] != 10 || counts[3] != 0 {
+			t.Errorf("unexpected counts: %v", counts)
+		}
+	})
+
+	t.Run("serves cached counts until the TTL expires", func(t *testing.T) {
+		queries := 0
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CountRegistrationsByEventFunc: func(ctx context.Context, eventIDs []int64) (map[int64]int, error) {
+				queries++
+				return map[int64]int{1: queries}, nil
+			},
+		}
+
+		clock := &MockClock{CurrentTime: time.Now()}
+		counter := newTestRegistrationCounter(repo, clock)
+		ctx := context.Background()
+
+		if _, err := counter.CountByEvents(ctx, []int64{1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		clock.CurrentTime = clock.CurrentTime.Add(RegistrationCountTTL - time.Second)
+		counts, _ := counter.CountByEvents(ctx, []int64{1})
+		if queries != 1 || counts[1] != 1 {
+			t.Errorf("expected cached count from 1 query, got %d queries and count %d", queries, counts[1])
+		}
+
+		clock.CurrentTime = clock.CurrentTime.Add(2 * time.Second)
+		counts, _ = counter.CountByEvents(ctx, []int64{1})
+		if queries != 2 || counts[1] != 2 {
+			t.Errorf("expected reload after expiry, got %d queries and count %d", queries, counts[1])
+		}
+	})
+
+	t.Run("only queries events missing from the cache", func(t *testing.T) {
+		var requested [][]int64
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CountRegistrationsByEventFunc: func(ctx context.Context, eventIDs []int64) (map[int64]int, error) {
+				requested = append(requested, eventIDs)
+				return map[int64]int{}, nil
+			},
+		}
+
+		counter := newTestRegistrationCounter(repo, &MockClock{CurrentTime: time.Now()})
+		ctx := context.Background()
+
+		_, _ = counter.CountByEvents(ctx, []int64{1, 2})
+		_, _ = counter.CountByEvents(ctx, []int64{1, 2, 3})
+
+		if len(requested) != 2 {
+			t.Fatalf("expected 2 queries, got %d", len(requested))
+		}
+		if len(requested[1]) != 1 || requested[1][0] != 3 {
+			t.Errorf("expected second query for event 3 only, got %v", requested[1])
+		}
+	})
+
+	t.Run("reloads an event after it is invalidated", func(t *testing.T) {
+		queries := 0
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CountRegistrationsByEventFunc: func(ctx context.Context, eventIDs []int64) (map[int64]int, error) {
+				queries++
+				return map[int64]int{}, nil
+			},
+		}
+
+		counter := newTestRegistrationCounter(repo, &MockClock{CurrentTime: time.Now()})
+		ctx := context.Background()
+
+		_, _ = counter.CountByEvents(ctx, []int64{1})
+		counter.Invalidate(1)
+		_, _ = counter.CountByEvents(ctx, []int64{1})
+
+		if queries != 2 {
+			t.Errorf("expected 2 queries, got %d", queries)
+		}
+	})
+
+	t.Run("propagates repository errors without caching", func(t *testing.T) {
+		queries := 0
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CountRegistrationsByEventFunc: func(ctx context.Context, eventIDs []int64) (map[int64]int, error) {
+				queries++
+				return nil, errors.New("database error")
+			},
+		}
+
+		counter := newTestRegistrationCounter(repo, &MockClock{CurrentTime: time.Now()})
+		ctx := context.Background()
+
+		if _, err := counter.CountByEvents(ctx, []int64{1}); err == nil {
+			t.Error("expected error, got nil")
+		}
+		_, _ = counter.CountByEvents(ctx, []int64{1})
+		if queries != 2 {
+			t.Errorf("expected failed lookups not to be cached, got %d queries", queries)
+		}
+	})
+}
+
+func TestRegistrationCounter_CountByRace(t *testing.T) {
+	t.Run("serves an event's race counts from the cache until it is invalidated", func(t *testing.T) {
+		var queried []int64
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CountByRaceForEventFunc: func(ctx context.Context, eventID int64) (map[int64]int, error) {
+				queried = append(queried, eventID)
+				return map[int64]int{10: 40, 11: len(queried)}, nil
+			},
+		}
+
+		counter := newTestRegistrationCounter(repo, &MockClock{CurrentTime: time.Now()})
+		ctx := context.Background()
+
+		counts, err := counter.CountByRace(ctx, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if counts[10] != 40 || counts[11] != 1 {
+			t.Errorf("unexpected counts: %v", counts)
+		}
+		_, _ = counter.CountByRace(ctx, 1)
+		_, _ = counter.CountByRace(ctx, 2)
+		if !slices.Equal(queried, []int64{1, 2}) {
+			t.Errorf("expected one query per event, got %v", queried)
+		}
+
+		counter.Invalidate(1)
+		counts, _ = counter.CountByRace(ctx, 1)
+		if counts[11] != 3 {
+			t.Errorf("expected fresh counts after invalidation, got %v", counts)
+		}
+	})
+
+	t.Run("reloads once the TTL expires", func(t *testing.T) {
+		queries := 0
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CountByRaceForEventFunc: func(ctx context.Context, eventID int64) (map[int64]int, error) {
+				queries++
+				return map[int64]int{}, nil
+			},
+		}
+
+		clock := &MockClock{CurrentTime: time.Now()}
+		counter := newTestRegistrationCounter(repo, clock)
+		ctx := context.Background()
+
+		_, _ = counter.CountByRace(ctx, 1)
+		clock.CurrentTime = clock.CurrentTime.Add(RegistrationCountTTL + time.Second)
+		_, _ = counter.CountByRace(ctx, 1)
+
+		if queries != 2 {
+			t.Errorf("expected 2 queries, got %d", queries)
+		}
+	})
+}
+
+func TestRegistrationService_Register(t *testing.T) {
+	const userID int64 = 7
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	race := db.Race{
+		ID:                    20,
+		EventID:               30,
+		Name:                  "10K",
+		RegistrationOpenDate:  pgtype.Timestamptz{Time: now.Add(-24 * time.Hour), Valid: true},
+		RegistrationCloseDate: pgtype.Timestamptz{Time: now.Add(24 * time.Hour), Valid: true},
+		MaxCapacity:           100,
+	}
+
+	newService := func(repo *repositorymocks.RegistrationRepositoryMock, counter *recordingCounter) *registrationService {
+		if repo.IsEmailVerifiedFunc == nil {
+			repo.IsEmailVerifiedFunc = func(ctx context.Context, userID int64) (bool, error) {
+				return true, nil
+			}
+		}
+		if repo.GetActiveFunc == nil {
+			repo.GetActiveFunc = func(ctx context.Context, userID, raceID int64) (db.Registration, error) {
+				return db.Registration{}, repository.ErrNotFound
+			}
+		}
+		svc := NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, &mockPaymentService{}, &mockDiscountService{}, counterBus(counter), &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 0, 100, BibRange{}).(*registrationService)
+		svc.clock = &MockClock{CurrentTime: now}
+		return svc
+	}
+
+	t.Run("registers the user pending payment", func(t *testing.T) {
+		var gotUser, gotRace int64
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				gotUser, gotRace = params.UserID, params.RaceID
+				return db.Registration{ID: 100, UserID: params.UserID, RaceID: params.RaceID, Status: db.RegistrationStatusPending}, nil
+			},
+		}
+		counter := &recordingCounter{}
+
+		reg, err := newService(repo, counter).Register(context.Background(), userID, race, 0, "", Answers{}, nil)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if reg.ID != 100 || gotUser != userID || gotRace != race.ID {
+			t.Errorf("expected user %d registered for race %d, got %+v", userID, race.ID, reg)
+		}
+		if len(counter.invalidated) != 1 || counter.invalidated[0] != race.EventID {
+			t.Errorf("expected event %d counts to be invalidated, got %v", race.EventID, counter.invalidated)
+		}
+	})
+
+	t.Run("emails the entrant a confirmation", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				return db.Registration{ID: 100, UserID: params.UserID, RaceID: params.RaceID}, nil
+			},
+			GetForConfirmationFunc: func(ctx context.Context, id int64) (db.GetRegistrationForConfirmationRow, error) {
+				return db.GetRegistrationForConfirmationRow{
+					ID:        id,
+					RaceName:  "10K",
+					StartsAt:  pgtype.Timestamptz{Time: time.Date(2026, 6, 6, 9, 30, 0, 0, time.UTC), Valid: true},
+					EventName: "Riverside Run",
+					Email:     "sam@example.com",
+					FirstName: "Sam",
+				}, nil
+			},
+		}
+		mailer := &mockMailer{}
+		svc := newService(repo, &recordingCounter{})
+		svc.mailer = mailer
+
+		if _, err := svc.Register(context.Background(), userID, race, 0, "", Answers{}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mailer.sent) != 1 {
+			t.Fatalf("expected one email, got %d", len(mailer.sent))
+		}
+		msg := mailer.sent[0]
+		if msg.To != "sam@example.com" || msg.Subject != "You're registered for 10K" {
+			t.Errorf("unexpected email to %q: %q", msg.To, msg.Subject)
+		}
+		for _, want := range []string{"Riverside Run on Saturday 6 June 2026 at 09:30", "https://firecrest.example/account/registrations#registration-100"} {
+			if !strings.Contains(msg.Text, want) {
+				t.Errorf("expected the email to contain %q, got:\n%s", want, msg.Text)
+			}
+		}
+	})
+
+	t.Run("enters the chosen wave and names it in the confirmation", func(t *testing.T) {
+		var gotWave pgtype.Int8
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				gotWave = params.WaveID
+				return db.Registration{ID: 100, UserID: params.UserID, RaceID: params.RaceID, WaveID: params.WaveID}, nil
+			},
+			GetForConfirmationFunc: func(ctx context.Context, id int64) (db.GetRegistrationForConfirmationRow, error) {
+				return db.GetRegistrationForConfirmationRow{
+					ID:           id,
+					RaceName:     "10K",
+					EventName:    "Riverside Run",
+					Email:        "sam@example.com",
+					FirstName:    "Sam",
+					WaveName:     pgtype.Text{String: "Wave B", Valid: true},
+					WaveStartsAt: pgtype.Timestamptz{Time: time.Date(2026, 6, 6, 9, 50, 0, 0, time.UTC), Valid: true},
+				}, nil
+			},
+		}
+		mailer := &mockMailer{}
+		svc := newService(repo, &recordingCounter{})
+		svc.mailer = mailer
+
+		if _, err := svc.Register(context.Background(), userID, race, 6, "", Answers{}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !gotWave.Valid || gotWave.Int64 != 6 {
+			t.Errorf("expected wave 6, got %+v", gotWave)
+		}
+		if len(mailer.sent) != 1 || !strings.Contains(mailer.sent[0].Text, "You start in Wave B") {
+			t.Errorf("expected the confirmation to name the wave, got %+v", mailer.sent)
+		}
+	})
+
+	t.Run("reports a wave the race does not have", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				return db.Registration{}, repository.ErrNotFound
+			},
+		}
+
+		if _, err := newService(repo, &recordingCounter{}).Register(context.Background(), userID, race, 99, "", Answers{}, nil); !errors.Is(err, ErrWaveNotFound) {
+			t.Errorf("expected ErrWaveNotFound, got %v", err)
+		}
+	})
+
+	t.Run("refuses users who have not verified their email", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			IsEmailVerifiedFunc: func(ctx context.Context, id int64) (bool, error) {
+				return false, nil
+			},
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				t.Error("expected no registration to be created")
+				return db.Registration{}, nil
+			},
+		}
+
+		if _, err := newService(repo, &recordingCounter{}).Register(context.Background(), userID, race, 0, "", Answers{}, nil); !errors.Is(err, ErrEmailNotVerified) {
+			t.Errorf("expected ErrEmailNotVerified, got %v", err)
+		}
+	})
+
+	t.Run("returns the registration when the confirmation cannot be sent", func(t *testing.T) {
+		loadErr := errors.New("database unavailable")
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				return db.Registration{ID: 100}, nil
+			},
+			GetForConfirmationFunc: func(ctx context.Context, id int64) (db.GetRegistrationForConfirmationRow, error) {
+				return db.GetRegistrationForConfirmationRow{}, loadErr
+			},
+		}
+
+		reg, err := newService(repo, &recordingCounter{}).Register(context.Background(), userID, race, 0, "", Answers{}, nil)
+
+		if !errors.Is(err, loadErr) {
+			t.Errorf("expected the repository error, got %v", err)
+		}
+		if reg.ID != 100 {
+			t.Errorf("expected the registration made, got %+v", reg)
+		}
+	})
+
+	t.Run("sends no confirmation for an existing registration", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			GetActiveFunc: func(ctx context.Context, userID, raceID int64) (db.Registration, error) {
+				return db.Registration{ID: 55}, nil
+			},
+		}
+		mailer := &mockMailer{}
+		svc := newService(repo, &recordingCounter{})
+		svc.mailer = mailer
+
+		_, _ = svc.Register(context.Background(), userID, race, 0, "", Answers{}, nil)
+		if len(mailer.sent) != 0 {
+			t.Errorf("expected no email, got %d", len(mailer.sent))
+		}
+	})
+
+	t.Run("returns the registration the user already holds", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			GetActiveFunc: func(ctx context.Context, userID, raceID int64) (db.Registration, error) {
+				return db.Registration{ID: 55, UserID: userID, RaceID: raceID, Status: db.RegistrationStatusConfirmed}, nil
+			},
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				t.Error("expected no second registration to be created")
+				return db.Registration{}, nil
+			},
+		}
+
+		reg, err := newService(repo, &recordingCounter{}).Register(context.Background(), userID, race, 0, "", Answers{}, nil)
+
+		if !errors.Is(err, ErrAlreadyRegistered) {
+			t.Fatalf("expected ErrAlreadyRegistered, got %v", err)
+		}
+		if reg.ID != 55 {
+			t.Errorf("expected the existing registration, got %+v", reg)
+		}
+	})
+
+	t.Run("refuses entries missing a required answer", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				t.Error("expected no registration to be created")
+				return db.Registration{}, nil
+			},
+		}
+		svc := newService(repo, &recordingCounter{})
+		svc.raceRepo = &repositorymocks.RaceRepositoryMock{
+			ListRequiredQuestionsFunc: func(ctx context.Context, raceID int64) ([]string, error) {
+				return []string{"emergency_contact_name", "emergency_contact_phone"}, nil
+			},
+		}
+
+		_, err := svc.Register(context.Background(), userID, race, 0, "", Answers{EmergencyContactName: "Alex Hill"}, nil)
+
+		var fieldErrs FieldErrors
+		if !errors.As(err, &fieldErrs) {
+			t.Fatalf("expected FieldErrors, got %v", err)
+		}
+		if len(fieldErrs) != 1 || fieldErrs["emergency_contact_phone"] != "emergency contact phone is required" {
+			t.Errorf("unexpected field errors %v", fieldErrs)
+		}
+	})
+
+	t.Run("stores the answers encrypted", func(t *testing.T) {
+		var sealed []byte
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				sealed = answersSealed
+				return db.Registration{ID: 100}, nil
+			},
+		}
+		svc := newService(repo, &recordingCounter{})
+		svc.raceRepo = &repositorymocks.RaceRepositoryMock{
+			ListRequiredQuestionsFunc: func(ctx context.Context, raceID int64) ([]string, error) {
+				return []string{"medical_conditions"}, nil
+			},
+		}
+		answers := Answers{MedicalConditions: "Asthma, carries an inhaler", Club: "Dark Peak Fell Runners"}
+
+		if _, err := svc.Register(context.Background(), userID, race, 0, "", answers, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Contains(string(sealed), "Asthma") {
+			t.Fatalf("expected the answers to be encrypted, got %q", sealed)
+		}
+		got, err := openAnswers(svc.answersBox, sealed)
+		if err != nil {
+			t.Fatalf("failed to open answers: %v", err)
+		}
+		if got != answers {
+			t.Errorf("expected %+v, got %+v", answers, got)
+		}
+	})
+
+	t.Run("stores nothing for entries without answers", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				if answersSealed != nil {
+					t.Errorf("expected no answers, got %q", answersSealed)
+				}
+				return db.Registration{ID: 100}, nil
+			},
+		}
+
+		if _, err := newService(repo, &recordingCounter{}).Register(context.Background(), userID, race, 0, "", Answers{}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("reports an entry that lost a race to the unique index as already registered", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				return db.Registration{}, repository.ErrAlreadyRegistered
+			},
+		}
+
+		if _, err := newService(repo, &recordingCounter{}).Register(context.Background(), userID, race, 0, "", Answers{}, nil); !errors.Is(err, ErrAlreadyRegistered) {
+			t.Errorf("expected ErrAlreadyRegistered, got %v", err)
+		}
+	})
+
+	t.Run("prices the entry with its discount code", func(t *testing.T) {
+		var stored db.CreateRegistrationParams
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				stored = params
+				return db.Registration{ID: 100}, nil
+			},
+		}
+		svc := newService(repo, &recordingCounter{})
+		svc.discounts = &mockDiscountService{
+			validateCodeFunc: func(ctx context.Context, code string, raceID int64) (Discount, error) {
+				if code != "early" || raceID != race.ID {
+					t.Errorf("expected code early for race %d, got %q for %d", race.ID, code, raceID)
+				}
+				return Discount{CodeID: 5, PercentOff: 15}, nil
+			},
+		}
+		priced := race
+		priced.PriceUnits = pgtype.Int4{Int32: 1999, Valid: true}
+
+		if _, err := svc.Register(context.Background(), userID, priced, 0, "early", Answers{}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := db.CreateRegistrationParams{
+			UserID:         userID,
+			RaceID:         race.ID,
+			PriceUnits:     pgtype.Int4{Int32: 1699, Valid: true},
+			DiscountCodeID: pgtype.Int8{Int64: 5, Valid: true},
+			DiscountUnits:  300,
+		}
+		if stored != want {
+			t.Errorf("expected %+v, got %+v", want, stored)
+		}
+	})
+
+	t.Run("records the full price without a discount code", func(t *testing.T) {
+		var stored db.CreateRegistrationParams
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				stored = params
+				return db.Registration{ID: 100}, nil
+			},
+		}
+		priced := race
+		priced.PriceUnits = pgtype.Int4{Int32: 1999, Valid: true}
+
+		if _, err := newService(repo, &recordingCounter{}).Register(context.Background(), userID, priced, 0, "  ", Answers{}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stored.PriceUnits != priced.PriceUnits || stored.DiscountCodeID.Valid || stored.DiscountUnits != 0 {
+			t.Errorf("expected the full price and no discount, got %+v", stored)
+		}
+	})
+
+	t.Run("returns a refused discount code without making an entry", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				t.Error("expected no registration to be created")
+				return db.Registration{}, nil
+			},
+		}
+		svc := newService(repo, &recordingCounter{})
+		svc.discounts = &mockDiscountService{
+			validateCodeFunc: func(ctx context.Context, code string, raceID int64) (Discount, error) {
+				return Discount{}, ErrDiscountCodeExpired
+			},
+		}
+
+		if _, err := svc.Register(context.Background(), userID, race, 0, "EARLY", Answers{}, nil); !errors.Is(err, ErrDiscountCodeExpired) {
+			t.Errorf("expected ErrDiscountCodeExpired, got %v", err)
+		}
+	})
+
+	t.Run("reports a code used up while entering as exhausted", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+				return db.Registration{}, repository.ErrDiscountExhausted
+			},
+		}
+		svc := newService(repo, &recordingCounter{})
+		svc.discounts = &mockDiscountService{
+			validateCodeFunc: func(ctx context.Context, code string, raceID int64) (Discount, error) {
+				return Discount{CodeID: 5, AmountOffUnits: 500}, nil
+			},
+		}
+
+		if _, err := svc.Register(context.Background(), userID, race, 0, "EARLY", Answers{}, nil); !errors.Is(err, ErrDiscountCodeExhausted) {
+			t.Errorf("expected ErrDiscountCodeExhausted, got %v", err)
+		}
+	})
+
+	t.Run("checks the minimum age on race day", func(t *testing.T) {
+		adultsOnly := race
+		adultsOnly.MinAge = pgtype.Int4{Int32: 18, Valid: true}
+		raceDay := pgtype.Date{Time: time.Date(2026, 6, 6, 0, 0, 0, 0, time.UTC), Valid: true}
+
+		tests := []struct {
+			name string
+			dob  time.Time
+			want error
+		}{
+			{name: "refuses entrants turning 18 the day after", dob: time.Date(2008, 6, 7, 0, 0, 0, 0, time.UTC), want: ErrTooYoung},
+			{name: "accepts entrants turning 18 on race day", dob: time.Date(2008, 6, 6, 0, 0, 0, 0, time.UTC)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := &repositorymocks.RegistrationRepositoryMock{
+					GetAgeCheckFunc: func(ctx context.Context, gotUser, raceID int64) (db.GetEntrantAgeCheckRow, error) {
+						return db.GetEntrantAgeCheckRow{DateOfBirth: pgtype.Date{Time: tt.dob, Valid: true}, RaceDay: raceDay}, nil
+					},
+					CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+						return db.Registration{ID: 100}, nil
+					},
+				}
+
+				_, err := newService(repo, &recordingCounter{}).Register(context.Background(), userID, adultsOnly, 0, "", Answers{}, nil)
+
+				if !errors.Is(err, tt.want) {
+					t.Errorf("expected %v, got %v", tt.want, err)
+				}
+				if created := len(repo.CreateCalls()) == 1; created != (tt.want == nil) {
+					t.Errorf("expected an entry to be made only when accepted, got %d", len(repo.CreateCalls()))
+				}
+			})
+		}
+	})
+
+	t.Run("asks for a date of birth alongside missing answers", func(t *testing.T) {
+		adultsOnly := race
+		adultsOnly.MinAge = pgtype.Int4{Int32: 18, Valid: true}
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			GetAgeCheckFunc: func(ctx context.Context, gotUser, raceID int64) (db.GetEntrantAgeCheckRow, error) {
+				return db.GetEntrantAgeCheckRow{}, nil
+			},
+		}
+		svc := newService(repo, &recordingCounter{})
+		svc.raceRepo = &repositorymocks.RaceRepositoryMock{
+			ListRequiredQuestionsFunc: func(ctx context.Context, raceID int64) ([]string, error) {
+				return []string{"club"}, nil
+			},
+		}
+
+		_, err := svc.Register(context.Background(), userID, adultsOnly, 0, "", Answers{}, nil)
+
+		var fieldErrs FieldErrors
+		if !errors.As(err, &fieldErrs) {
+			t.Fatalf("expected FieldErrors, got %v", err)
+		}
+		want := FieldErrors{"date_of_birth": "date of birth is required to enter this race", "club": "club is required"}
+		if !maps.Equal(fieldErrs, want) {
+			t.Errorf("expected %v, got %v", want, fieldErrs)
+		}
+		if len(repo.CreateCalls()) != 0 {
+			t.Error("expected no registration to be created")
+		}
+	})
+
+	tests := []struct {
+		name   string
+		race   func(db.Race) db.Race
+		create error
+		want   error
+	}{
+		{
+			name: "rejects entries before registration opens",
+			race: func(r db.Race) db.Race {
+				r.RegistrationOpenDate.Time = now.Add(time.Hour)
+				return r
+			},
+			want: ErrRegistrationClosed,
+		},
+		{
+			name: "rejects entries once registration closes",
+			race: func(r db.Race) db.Race {
+				r.RegistrationCloseDate.Time = now
+				return r
+			},
+			want: ErrRegistrationClosed,
+		},
+		{name: "rejects entries to a full race", create: repository.ErrCapacityExceeded, want: ErrRaceFull},
+		{name: "rejects entries while the event is not published", create: repository.ErrNotPublished, want: ErrRegistrationClosed},
+		{name: "returns ErrNotFound for a deleted race", create: repository.ErrNotFound, want: repository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := race
+			if tt.race != nil {
+				r = tt.race(r)
+			}
+			repo := &repositorymocks.RegistrationRepositoryMock{
+				CreateFunc: func(ctx context.Context, params db.CreateRegistrationParams, answersSealed, customAnswers []byte) (db.Registration, error) {
+					return db.Registration{}, tt.create
+				},
+			}
+
+			if _, err := newService(repo, &recordingCounter{}).Register(context.Background(), userID, r, 0, "", Answers{}, nil); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationService_SendRaceReminders(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	signer := newTestSigner(t)
+	reminder := db.ClaimRaceRemindersRow{
+		ID:        100,
+		UserID:    7,
+		RaceName:  "10K",
+		StartsAt:  pgtype.Timestamptz{Time: now.Add(6 * 24 * time.Hour), Valid: true},
+		EventName: "Riverside Run",
+		EventSlug: "riverside-run",
+		Email:     "sam@example.com",
+		FirstName: "Sam",
+	}
+
+	newService := func(repo *repositorymocks.RegistrationRepositoryMock, mailer *mockMailer) *registrationService {
+		return &registrationService{
+			registrationRepo: repo,
+			mailer:           mailer,
+			tokens:           signer,
+			baseURL:          "https://firecrest.example",
+			clock:            &MockClock{CurrentTime: now},
+		}
+	}
+
+	t.Run("emails the entrants of races starting within a week", func(t *testing.T) {
+		var from, to time.Time
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			ClaimRemindersFunc: func(ctx context.Context, start, end time.Time) ([]db.ClaimRaceRemindersRow, error) {
+				from, to = start, end
+				return []db.ClaimRaceRemindersRow{reminder}, nil
+			},
+		}
+		mailer := &mockMailer{}
+
+		sent, err := newService(repo, mailer).SendRaceReminders(context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sent != 1 || len(mailer.sent) != 1 {
+			t.Fatalf("expected one reminder, got %d reported and %d sent", sent, len(mailer.sent))
+		}
+		if !from.Equal(now) || !to.Equal(now.Add(7*24*time.Hour)) {
+			t.Errorf("expected races starting in the next 7 days, got %v to %v", from, to)
+		}
+		msg := mailer.sent[0]
+		if msg.To != "sam@example.com" || msg.Subject != "10K is on Thursday 7 May 2026 at 12:00" {
+			t.Errorf("unexpected email to %q: %q", msg.To, msg.Subject)
+		}
+	})
+
+	t.Run("links to an unsubscribe page for the entrant", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			ClaimRemindersFunc: func(ctx context.Context, from, to time.Time) ([]db.ClaimRaceRemindersRow, error) {
+				return []db.ClaimRaceRemindersRow{reminder}, nil
+			},
+		}
+		mailer := &mockMailer{}
+
+		if _, err := newService(repo, mailer).SendRaceReminders(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, query, ok := strings.Cut(mailer.sent[0].Text, "https://firecrest.example/email/unsubscribe?")
+		if !ok {
+			t.Fatalf("expected an unsubscribe link, got:\n%s", mailer.sent[0].Text)
+		}
+		values, err := url.ParseQuery(strings.TrimSpace(query))
+		if err != nil {
+			t.Fatalf("failed to parse the link: %v", err)
+		}
+		userID, err := signer.VerifyToken(token.PurposeUnsubscribe, values.Get("token"))
+		if err != nil || userID != reminder.UserID {
+			t.Errorf("expected a token for user %d, got %d (%v)", reminder.UserID, userID, err)
+		}
+	})
+
+	t.Run("sends nothing once every registration has been reminded", func(t *testing.T) {
+		claimed := false
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			ClaimRemindersFunc: func(ctx context.Context, from, to time.Time) ([]db.ClaimRaceRemindersRow, error) {
+				if claimed {
+					return nil, nil
+				}
+				claimed = true
+				return []db.ClaimRaceRemindersRow{reminder}, nil
+			},
+		}
+		mailer := &mockMailer{}
+		svc := newService(repo, mailer)
+
+		for range 2 {
+			if _, err := svc.SendRaceReminders(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if len(mailer.sent) != 1 {
+			t.Errorf("expected one reminder across both runs, got %d", len(mailer.sent))
+		}
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		repoErr := errors.New("database unavailable")
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			ClaimRemindersFunc: func(ctx context.Context, from, to time.Time) ([]db.ClaimRaceRemindersRow, error) {
+				return nil, repoErr
+			},
+		}
+
+		if _, err := newService(repo, &mockMailer{}).SendRaceReminders(context.Background()); !errors.Is(err, repoErr) {
+			t.Errorf("expected the repository error, got %v", err)
+		}
+	})
+}
+
+func TestRegistrationService_CancelRegistration(t *testing.T) {
+	const (
+		ownerID     int64 = 7
+		organiserID int64 = 8
+		strangerID  int64 = 9
+		grace             = 48 * time.Hour
+	)
+	closeDate := time.Date(2026, 6, 1, 23, 59, 0, 0, time.UTC)
+
+	registration := func() db.GetRegistrationForCancellationRow {
+		return db.GetRegistrationForCancellationRow{
+			ID:                    100,
+			UserID:                ownerID,
+			RaceID:                20,
+			Status:                db.RegistrationStatusConfirmed,
+			EventID:               30,
+			RegistrationCloseDate: pgtype.Timestamptz{Time: closeDate, Valid: true},
+			OrganisationID:        40,
+		}
+	}
+
+	type deps struct {
+		registrations *repositorymocks.RegistrationRepositoryMock
+		orgs          *repositorymocks.OrganisationRepositoryMock
+		payments      *mockPaymentService
+		counter       *recordingCounter
+		cancelled     []int64
+		refunds       []int32
+	}
+
+	newService := func(now time.Time, reg db.GetRegistrationForCancellationRow) (*registrationService, *deps) {
+		d := &deps{counter: &recordingCounter{}}
+		d.registrations = &repositorymocks.RegistrationRepositoryMock{
+			GetForCancellationFunc: func(ctx context.Context, id int64) (db.GetRegistrationForCancellationRow, error) {
+				if id != reg.ID {
+					return db.GetRegistrationForCancellationRow{}, repository.ErrNotFound
+				}
+				return reg, nil
+			},
+			CancelFunc: func(ctx context.Context, id int64) error {
+				d.cancelled = append(d.cancelled, id)
+				return nil
+			},
+		}
+		d.orgs = &repositorymocks.OrganisationRepositoryMock{
+			IsMemberFunc: func(ctx context.Context, organisationID, userID int64) (bool, error) {
+				return organisationID == reg.OrganisationID && userID == organiserID, nil
+			},
+		}
+		d.payments = &mockPaymentService{
+			settledPaymentFunc: func(ctx context.Context, registrationID int64) (db.Payment, error) {
+				return db.Payment{ID: 1, RegistrationID: registrationID, AmountUnits: 6500, Currency: "GBP"}, nil
+			},
+			refundFunc: func(ctx context.Context, payment db.Payment, amountUnits int32) error {
+				d.refunds = append(d.refunds, amountUnits)
+				return nil
+			},
+		}
+
+		svc := NewRegistrationService(d.registrations, d.orgs, &repositorymocks.RaceRepositoryMock{}, d.payments, &mockDiscountService{}, counterBus(d.counter), &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", grace, 0, 0, 0, 100, BibRange{}).(*registrationService)
+		svc.clock = &MockClock{CurrentTime: now}
+		return svc, d
+	}
+
+	t.Run("owner cancelling well ahead receives a full refund", func(t *testing.T) {
+		svc, d := newService(closeDate.Add(-60*24*time.Hour), registration())
+
+		result, err := svc.CancelRegistration(context.Background(), ownerID, 100)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(d.cancelled) != 1 || d.cancelled[0] != 100 {
+			t.Errorf("expected registration 100 to be cancelled, got %v", d.cancelled)
+		}
+		if result.RefundUnits != 6500 || len(d.refunds) != 1 || d.refunds[0] != 6500 {
+			t.Errorf("expected full refund of 6500, got result %d and refunds %v", result.RefundUnits, d.refunds)
+		}
+		if len(d.counter.invalidated) != 1 || d.counter.invalidated[0] != 30 {
+			t.Errorf("expected event 30 counts to be invalidated, got %v", d.counter.invalidated)
+		}
+	})
+
+	t.Run("owner cancelling within 30 days receives half", func(t *testing.T) {
+		svc, _ := newService(closeDate.Add(-10*24*time.Hour), registration())
+
+		result, err := svc.CancelRegistration(context.Background(), ownerID, 100)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.RefundUnits != 3250 {
+			t.Errorf("expected refund of 3250, got %d", result.RefundUnits)
+		}
+	})
+
+	t.Run("owner may cancel during the grace period", func(t *testing.T) {
+		svc, d := newService(closeDate.Add(grace-time.Minute), registration())
+
+		if _, err := svc.CancelRegistration(context.Background(), ownerID, 100); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(d.cancelled) != 1 {
+			t.Error("expected registration to be cancelled")
+		}
+	})
+
+	t.Run("owner may cancel exactly at the deadline", func(t *testing.T) {
+		svc, _ := newService(closeDate.Add(grace), registration())
+
+		if _, err := svc.CancelRegistration(context.Background(), ownerID, 100); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("owner cannot cancel after the deadline", func(t *testing.T) {
+		svc, d := newService(closeDate.Add(grace+time.Second), registration())
+
+		_, err := svc.CancelRegistration(context.Background(), ownerID, 100)
+
+		if !errors.Is(err, ErrCancellationClosed) {
+			t.Errorf("expected ErrCancellationClosed, got %v", err)
+		}
+		if len(d.cancelled) != 0 || len(d.refunds) != 0 {
+			t.Error("expected no cancellation or refund")
+		}
+	})
+
+	t.Run("organiser may cancel after the deadline", func(t *testing.T) {
+		svc, d := newService(closeDate.Add(grace+24*time.Hour), registration())
+
+		if _, err := svc.CancelRegistration(context.Background(), organiserID, 100); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(d.cancelled) != 1 {
+			t.Error("expected registration to be cancelled")
+		}
+	})
+
+	t.Run("other users are forbidden", func(t *testing.T) {
+		svc, d := newService(closeDate.Add(-60*24*time.Hour), registration())
+
+		_, err := svc.CancelRegistration(context.Background(), strangerID, 100)
+
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden, got %v", err)
+		}
+		if len(d.cancelled) != 0 {
+			t.Error("expected no cancellation")
+		}
+	})
+
+	t.Run("returns ErrAlreadyCancelled for a cancelled registration", func(t *testing.T) {
+		reg := registration()
+		reg.Status = db.RegistrationStatusCancelled
+		svc, _ := newService(closeDate.Add(-60*24*time.Hour), reg)
+
+		_, err := svc.CancelRegistration(context.Background(), ownerID, 100)
+
+		if !errors.Is(err, ErrAlreadyCancelled) {
+			t.Errorf("expected ErrAlreadyCancelled, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrAlreadyCancelled when a concurrent cancel wins", func(t *testing.T) {
+		svc, d := newService(closeDate.Add(-60*24*time.Hour), registration())
+		d.registrations.CancelFunc = func(ctx context.Context, id int64) error {
+			return repository.ErrNotFound
+		}
+
+		_, err := svc.CancelRegistration(context.Background(), ownerID, 100)
+
+		if !errors.Is(err, ErrAlreadyCancelled) {
+			t.Errorf("expected ErrAlreadyCancelled, got %v", err)
+		}
+		if len(d.refunds) != 0 {
+			t.Error("expected no refund")
+		}
+	})
+
+	t.Run("skips the refund when no payment exists", func(t *testing.T) {
+		svc, d := newService(closeDate.Add(-60*24*time.Hour), registration())
+		d.payments.settledPaymentFunc = func(ctx context.Context, registrationID int64) (db.Payment, error) {
+			return db.Payment{}, repository.ErrNotFound
+		}
+
+		result, err := svc.CancelRegistration(context.Background(), ownerID, 100)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.RefundUnits != 0 || len(d.refunds) != 0 {
+			t.Errorf("expected no refund, got %d", result.RefundUnits)
+		}
+	})
+
+	t.Run("returns ErrNotFound for an unknown registration", func(t *testing.T) {
+		svc, _ := newService(closeDate, registration())
+
+		_, err := svc.CancelRegistration(context.Background(), ownerID, 999)
+
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestRegistrationService_ListUserRegistrations(t *testing.T) {
+	now := time.Date(2026, 6, 2, 12, 0, 0, 0, time.UTC)
+	at := func(t time.Time) pgtype.Timestamptz { return pgtype.Timestamptz{Time: t, Valid: true} }
+
+	rows := []db.ListRegistrationsByUserRow{
+		{ID: 1, Status: db.RegistrationStatusConfirmed, RegistrationCloseDate: at(now.Add(24 * time.Hour))},
+		{ID: 2, Status: db.RegistrationStatusConfirmed, RegistrationCloseDate: at(now.Add(-12 * time.Hour))},
+		{ID: 3, Status: db.RegistrationStatusConfirmed, RegistrationCloseDate: at(now.Add(-72 * time.Hour))},
+		{ID: 4, Status: db.RegistrationStatusCancelled, RegistrationCloseDate: at(now.Add(24 * time.Hour))},
+		{ID: 5, Status: db.RegistrationStatusPending},
+	}
+
+	t.Run("marks registrations still inside the cancellation window", func(t *testing.T) {
+		var gotUserID int64
+		svc := &registrationService{
+			registrationRepo: &repositorymocks.RegistrationRepositoryMock{
+				ListByUserFunc: func(ctx context.Context, userID int64) ([]db.ListRegistrationsByUserRow, error) {
+					gotUserID = userID
+					return rows, nil
+				},
+			},
+			gracePeriod: 48 * time.Hour,
+			clock:       &MockClock{CurrentTime: now},
+		}
+
+		regs, err := svc.ListUserRegistrations(context.Background(), 7)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotUserID != 7 {
+			t.Errorf("expected registrations for user 7, got %d", gotUserID)
+		}
+		want := map[int64]bool{1: true, 2: true, 3: false, 4: false, 5: true}
+		for _, reg := range regs {
+			if reg.CanCancel != want[reg.ID] {
+				t.Errorf("registration %d: expected CanCancel %v, got %v", reg.ID, want[reg.ID], reg.CanCancel)
+			}
+		}
+	})
+
+	t.Run("marks registrations still inside the transfer window", func(t *testing.T) {
+		pending := db.ListRegistrationsByUserRow{ID: 6, Status: db.RegistrationStatusConfirmed, TransferPending: true}
+		svc := &registrationService{
+			registrationRepo: &repositorymocks.RegistrationRepositoryMock{
+				ListByUserFunc: func(ctx context.Context, userID int64) ([]db.ListRegistrationsByUserRow, error) {
+					return append(rows, pending), nil
+				},
+			},
+			transferCutoff: 12 * time.Hour,
+			clock:          &MockClock{CurrentTime: now},
+		}
+
+		regs, err := svc.ListUserRegistrations(context.Background(), 7)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[int64]bool{1: true, 2: false, 3: false, 4: false, 5: true, 6: false}
+		for _, reg := range regs {
+			if reg.CanTransfer != want[reg.ID] {
+				t.Errorf("registration %d: expected CanTransfer %v, got %v", reg.ID, want[reg.ID], reg.CanTransfer)
+			}
+			if reg.ID == 6 && reg.CanCancel {
+				t.Error("expected a registration awaiting acceptance not to be cancellable")
+			}
+		}
+	})
+
+	t.Run("marks registrations whose race is outside the edit lock", func(t *testing.T) {
+		starting := []db.ListRegistrationsByUserRow{
+			{ID: 1, Status: db.RegistrationStatusConfirmed, StartsAt: at(now.Add(10 * 24 * time.Hour))},
+			{ID: 2, Status: db.RegistrationStatusConfirmed, StartsAt: at(now.Add(3 * 24 * time.Hour))},
+			{ID: 3, Status: db.RegistrationStatusCancelled, StartsAt: at(now.Add(10 * 24 * time.Hour))},
+			{ID: 4, Status: db.RegistrationStatusPending},
+		}
+		svc := &registrationService{
+			registrationRepo: &repositorymocks.RegistrationRepositoryMock{
+				ListByUserFunc: func(ctx context.Context, userID int64) ([]db.ListRegistrationsByUserRow, error) {
+					return starting, nil
+				},
+			},
+			editLock: 7 * 24 * time.Hour,
+			clock:    &MockClock{CurrentTime: now},
+		}
+
+		regs, err := svc.ListUserRegistrations(context.Background(), 7)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[int64]bool{1: true, 2: false, 3: false, 4: true}
+		for _, reg := range regs {
+			if reg.CanEdit != want[reg.ID] {
+				t.Errorf("registration %d: expected CanEdit %v, got %v", reg.ID, want[reg.ID], reg.CanEdit)
+			}
+		}
+	})
+
+	t.Run("returns ErrInvalidInput for invalid user id", func(t *testing.T) {
+		svc := &registrationService{registrationRepo: &repositorymocks.RegistrationRepositoryMock{}, clock: RealClock{}}
+
+		_, err := svc.ListUserRegistrations(context.Background(), 0)
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestRegistrationService_TransferRegistration(t *testing.T) {
+	const (
+		ownerID  int64 = 7
+		cutoff         = 7 * 24 * time.Hour
+		baseURL        = "https://firecrest.example"
+		newEmail       = "sam@example.com"
+	)
+	closeDate := time.Date(2026, 6, 1, 23, 59, 0, 0, time.UTC)
+
+	registration := db.GetRegistrationForTransferRow{
+		ID:                    100,
+		UserID:                ownerID,
+		RaceID:                20,
+		Status:                db.RegistrationStatusConfirmed,
+		RaceName:              "Ultra 50K",
+		RegistrationCloseDate: pgtype.Timestamptz{Time: closeDate, Valid: true},
+		EventName:             "Peak District Ultra",
+		OwnerFirstName:        "Jane",
+		OwnerLastName:         "Runner",
+		OwnerEmail:            "jane@example.com",
+	}
+	existing := db.User{ID: 8, Email: "alex@example.com", FirstName: "Alex"}
+
+	type deps struct {
+		mailer    *mockMailer
+		transfers []repository.TransferParams
+	}
+
+	newService := func(t *testing.T, now time.Time) (*registrationService, *deps) {
+		d := &deps{mailer: &mockMailer{}}
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			GetForTransferFunc: func(ctx context.Context, id int64) (db.GetRegistrationForTransferRow, error) {
+				if id != registration.ID {
+					return db.GetRegistrationForTransferRow{}, repository.ErrNotFound
+				}
+				return registration, nil
+			},
+			TransferFunc: func(ctx context.Context, params repository.TransferParams) (repository.TransferredRegistration, error) {
+				d.transfers = append(d.transfers, params)
+				if params.RecipientEmail == existing.Email {
+					return repository.TransferredRegistration{Recipient: existing}, nil
+				}
+				return repository.TransferredRegistration{
+					Recipient:    db.User{ID: 9, Email: params.RecipientEmail},
+					NewRecipient: true,
+				}, nil
+			},
+		}
+		svc := NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, &repositorymocks.RaceRepositoryMock{}, &mockPaymentService{}, &mockDiscountService{}, nil,
+			d.mailer, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), baseURL, 0, cutoff, 0, 0, 100, BibRange{}).(*registrationService)
+		svc.clock = &MockClock{CurrentTime: now}
+		return svc, d
+	}
+
+	t.Run("transfers to an existing user", func(t *testing.T) {
+		svc, d := newService(t, closeDate.Add(-30*24*time.Hour))
+
+		transfer, err := svc.TransferRegistration(context.Background(), ownerID, 100, " Alex@Example.com ")
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if transfer.Invited || transfer.RecipientEmail != existing.Email {
+			t.Errorf("unexpected transfer: %+v", transfer)
+		}
+		want := repository.TransferParams{RegistrationID: 100, RaceID: 20, FromUserID: ownerID, RecipientEmail: existing.Email}
+		if len(d.transfers) != 1 || d.transfers[0] != want {
+			t.Errorf("expected transfer %+v, got %+v", want, d.transfers)
+		}
+		if len(d.mailer.sent) != 1 {
+			t.Fatalf("expected 1 email, got %d", len(d.mailer.sent))
+		}
+		msg := d.mailer.sent[0]
+		if msg.To != existing.Email || !strings.Contains(msg.Text, "Hi Alex") || !strings.Contains(msg.Text, "Jane Runner") {
+			t.Errorf("unexpected email to %s:\n%s", msg.To, msg.Text)
+		}
+	})
+
+	t.Run("invites a brand-new email with a link to accept", func(t *testing.T) {
+		svc, d := newService(t, closeDate.Add(-30*24*time.Hour))
+
+		transfer, err := svc.TransferRegistration(context.Background(), ownerID, 100, newEmail)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !transfer.Invited {
+			t.Error("expected the recipient to be invited")
+		}
+		if len(d.mailer.sent) != 1 || d.mailer.sent[0].To != newEmail {
+			t.Fatalf("expected an email to %s, got %+v", newEmail, d.mailer.sent)
+		}
+
+		prefix := baseURL + "/transfers/accept?token="
+		text := d.mailer.sent[0].Text
+		start := strings.Index(text, prefix)
+		if start < 0 {
+			t.Fatalf("expected an accept link in:\n%s", text)
+		}
+		link := strings.Fields(text[start:])[0]
+		tok, err := url.QueryUnescape(strings.TrimPrefix(link, prefix))
+		if err != nil {
+			t.Fatalf("failed to unescape token: %v", err)
+		}
+		userID, err := svc.tokens.VerifyToken(token.PurposeRegistrationTransfer, tok)
+		if err != nil || userID != 9 {
+			t.Errorf("expected a transfer token for user 9, got %d (err %v)", userID, err)
+		}
+	})
+
+	t.Run("rejects a transfer after the cutoff", func(t *testing.T) {
+		svc, d := newService(t, closeDate.Add(-cutoff))
+
+		_, err := svc.TransferRegistration(context.Background(), ownerID, 100, newEmail)
+
+		if !errors.Is(err, ErrTransferClosed) {
+			t.Errorf("expected ErrTransferClosed, got %v", err)
+		}
+		if len(d.transfers) != 0 || len(d.mailer.sent) != 0 {
+			t.Error("expected nothing to be transferred or sent")
+		}
+	})
+
+	tests := []struct {
+		name    string
+		userID  int64
+		email   string
+		moveErr error
+		want    error
+	}{
+		{name: "rejects someone else's registration", userID: 8, email: newEmail, want: ErrForbidden},
+		{name: "rejects an invalid email", userID: ownerID, email: "not-an-email", want: ErrInvalidInput},
+		{name: "rejects transferring to yourself", userID: ownerID, email: "JANE@example.com", want: ErrInvalidInput},
+		{name: "rejects a recipient already registered", userID: ownerID, email: newEmail, moveErr: repository.ErrAlreadyRegistered, want: ErrRecipientRegistered},
+		{name: "reports a registration cancelled meanwhile", userID: ownerID, email: newEmail, moveErr: repository.ErrNotFound, want: ErrAlreadyCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, d := newService(t, closeDate.Add(-30*24*time.Hour))
+			if tt.moveErr != nil {
+				svc.registrationRepo.(*repositorymocks.RegistrationRepositoryMock).TransferFunc = func(ctx context.Context, params repository.TransferParams) (repository.TransferredRegistration, error) {
+					return repository.TransferredRegistration{}, tt.moveErr
+				}
+			}
+
+			_, err := svc.TransferRegistration(context.Background(), tt.userID, 100, tt.email)
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if len(d.mailer.sent) != 0 {
+				t.Error("expected no email to be sent")
+			}
+		})
+	}
+}
+
+func TestRegistrationService_AcceptTransfers(t *testing.T) {
+	signer := newTestSigner(t)
+	var accepted []int64
+	svc := &registrationService{
+		registrationRepo: &repositorymocks.RegistrationRepositoryMock{
+			AcceptTransfersFunc: func(ctx context.Context, userID int64) (int64, error) {
+				accepted = append(accepted, userID)
+				return 1, nil
+			},
+		},
+		tokens: signer,
+		clock:  RealClock{},
+	}
+
+	t.Run("accepts the transfers waiting on the token's user", func(t *testing.T) {
+		tok, err := signer.SignToken(token.PurposeRegistrationTransfer, 9, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+
+		if err := svc.AcceptTransfers(context.Background(), tok); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(accepted) != 1 || accepted[0] != 9 {
+			t.Errorf("expected transfers for user 9 to be accepted, got %v", accepted)
+		}
+	})
+
+	t.Run("rejects tokens issued for another purpose", func(t *testing.T) {
+		accepted = nil
+		tok, err := signer.SignToken(token.PurposeEmailVerification, 9, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+
+		if err := svc.AcceptTransfers(context.Background(), tok); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+		if len(accepted) != 0 {
+			t.Errorf("expected nothing to be accepted, got %v", accepted)
+		}
+	})
+}
+
+func TestRegistrationService_ListRaceAnswers(t *testing.T) {
+	box := newTestBox(t)
+	answers := Answers{EmergencyContactName: "Alex Hill", EmergencyContactPhone: "07700 900123"}
+	sealed, err := sealAnswers(box, answers)
+	if err != nil {
+		t.Fatalf("failed to seal answers: %v", err)
+	}
+
+	newService := func(rows []db.ListRaceRegistrationAnswersRow) *registrationService {
+		return &registrationService{
+			registrationRepo: &repositorymocks.RegistrationRepositoryMock{
+				ListAnswersFunc: func(ctx context.Context, raceID int64) ([]db.ListRaceRegistrationAnswersRow, error) {
+					return rows, nil
+				},
+			},
+			answersBox: box,
+		}
+	}
+
+	t.Run("decrypts answers by registration", func(t *testing.T) {
+		got, err := newService([]db.ListRaceRegistrationAnswersRow{{RegistrationID: 100, AnswersSealed: sealed}}).ListRaceAnswers(context.Background(), 20)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 || got[100] != answers {
+			t.Errorf("expected registration 100's answers, got %+v", got)
+		}
+	})
+
+	t.Run("fails on answers sealed with another key", func(t *testing.T) {
+		other, err := secret.NewBox([]byte("another-key-another-key-another!"))
+		if err != nil {
+			t.Fatalf("failed to create box: %v", err)
+		}
+		foreign, err := sealAnswers(other, answers)
+		if err != nil {
+			t.Fatalf("failed to seal answers: %v", err)
+		}
+
+		if _, err := newService([]db.ListRaceRegistrationAnswersRow{{RegistrationID: 100, AnswersSealed: foreign}}).ListRaceAnswers(context.Background(), 20); !errors.Is(err, secret.ErrDecrypt) {
+			t.Errorf("expected secret.ErrDecrypt, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/repository"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/result.go -pkg servicemocks . ResultService
+
+// Result field limits
+const (
+	MaxResultNameLength = 200
+	MaxCategoryLength   = 50
+)
+
+// ErrNoResults is returned when publishing a race that has no results.
+var ErrNoResults = apperr.New(apperr.CodeNoResults, http.StatusConflict, "race has no results")
+
+// ResultService defines the interface for race result business logic.
+type ResultService interface {
+	// UploadResults replaces the race's results with those listed in a CSV
+	// file. The upload is all or nothing, and the new results are staged
+	// unpublished. Results recorded against a registration without a
+	// category take the entrant's AgeCategory, if they gave a date of birth.
+	UploadResults(ctx context.Context, race db.Race, file io.Reader) (ResultsReport, error)
+	// ListResults returns the race's results in finishing order, published
+	// or not.
+	ListResults(ctx context.Context, raceID int64) ([]db.ListRaceResultsRow, error)
+	// PublishResults makes the race's results public.
+	PublishResults(ctx context.Context, raceID int64) error
+	// PublishedResults returns the race's published results matching the
+	// query, or repository.ErrNotFound if none have been published.
+	PublishedResults(ctx context.Context, raceID int64, query ResultsQuery) ([]db.ListRaceResultsRow, error)
+}
+
+// ResultSort is a column results can be sorted by.
+type ResultSort string
+
+// Result sort columns
+const (
+	SortByPosition ResultSort = "position"
+	SortByName     ResultSort = "name"
+	SortByTime     ResultSort = "time"
+	SortByCategory ResultSort = "category"
+)
+
+// ParseResultSort returns the sort column named s, defaulting to position.
+func ParseResultSort(s string) ResultSort {
+	switch sort := ResultSort(s); sort {
+	case SortByName, SortByTime, SortByCategory:
+		return sort
+	default:
+		return SortByPosition
+	}
+}
+
+// ResultsQuery filters and orders published results.
+type ResultsQuery struct {
+	Sort ResultSort
+	Desc bool
+	// Search keeps only runners whose name contains it, ignoring case.
+	Search string
+}
+
+// ResultRowError is a row of a results upload that could not be saved.
+type ResultRowError struct {
+	// Line is the line of the file the row starts on, counting the header.
+	Line    int
+	Message string
+}
+
+// ResultsReport describes the outcome of a results upload. When any row has
+// an error nothing is saved, and Errors lists the rows that need fixing.
+type ResultsReport struct {
+	Saved  int
+	Errors []ResultRowError
+}
+
+// resultColumns maps normalised header names to the fields they hold.
+var resultColumns = map[string]string{
+	"position":       "position",
+	"pos":            "position",
+	"place":          "position",
+	"time":           "time",
+	"finishtime":     "time",
+	"name":           "name",
+	"runner":         "name",
+	"bib":            "bib",
+	"bibnumber":      "bib",
+	"category":       "category",
+	"cat":            "category",
+	"registration":   "registration",
+	"registrationid": "registration",
+}
+
+type resultService struct {
+	resultRepo repository.ResultRepository
+	maxRows    int
+}
+
+// NewResultService creates a new ResultService. Uploads may list at most
+// maxRows results.
+func NewResultService(resultRepo repository.ResultRepository, maxRows int) ResultService {
+	return &resultService{
+		resultRepo: resultRepo,
+		maxRows:    maxRows,
+	}
+}
+
+func (s *resultService) UploadResults(ctx context.Context, race db.Race, file io.Reader) (ResultsReport, error) {
+	results, report, err := s.readResults(ctx, race.ID, file)
+	if err != nil || len(report.Errors) > 0 {
+		return report, err
+	}
+
+	if err := s.resultRepo.Replace(ctx, race.ID, results); err != nil {
+		return ResultsReport{}, fmt.Errorf("failed to save results: %w", err)
+	}
+	return ResultsReport{Saved: len(results)}, nil
+}
+
+// readResults parses and validates a results file a row at a time. It
+// returns every result, or a report of the rows with errors.
+func (s *resultService) readResults(ctx context.Context, raceID int64, file io.Reader) ([]db.CreateRaceResultParams, ResultsReport, error) {
+	r := newCSVReader(file)
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ResultsReport{}, fmt.Errorf("%w: the file is empty", ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, ResultsReport{}, fmt.Errorf("%w: could not read the header row: %v", ErrInvalidInput, err)
+	}
+	columns, err := resultsHeader(header)
+	if err != nil {
+		return nil, ResultsReport{}, err
+	}
+
+	var (
+		entered    = make(map[int64]bool)
+		categories = make(map[int64]string)
+	)
+	if _, ok := columns["registration"]; ok {
+		entrants, err := s.resultRepo.ListEntrants(ctx, raceID)
+		if err != nil {
+			return nil, ResultsReport{}, fmt.Errorf("failed to list registrations: %w", err)
+		}
+		for _, e := range entrants {
+			entered[e.ID] = true
+			if e.DateOfBirth.Valid && e.RaceDay.Valid {
+				categories[e.ID] = AgeCategory(e.DateOfBirth.Time, e.RaceDay.Time)
+			}
+		}
+	}
+
+	var (
+		results       []db.CreateRaceResultParams
+		report        ResultsReport
+		positions     = make(map[int32]int)
+		registrations = make(map[int64]int)
+		rows          int
+	)
+	for {
+		fields, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, ResultsReport{}, fmt.Errorf("failed to read results: %w", err)
+		}
+		if err == nil && blankRecord(fields) {
+			continue
+		}
+		if rows++; rows > s.maxRows {
+			return nil, ResultsReport{}, fmt.Errorf("%w: the file has more than %d results", ErrInvalidInput, s.maxRows)
+		}
+		if parseErr != nil {
+			report.Errors = append(report.Errors, ResultRowError{Line: parseErr.StartLine, Message: parseErr.Err.Error()})
+			continue
+		}
+
+		line, _ := r.FieldPos(0)
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+
+		result, msg := parseResult(field, entered)
+		if msg == "" {
+			if first, ok := positions[result.Position]; ok {
+				msg = fmt.Sprintf("position %d is also used on line %d", result.Position, first)
+			} else if first, ok := registrations[result.RegistrationID.Int64]; ok && result.RegistrationID.Valid {
+				msg = fmt.Sprintf("registration %d is also used on line %d", result.RegistrationID.Int64, first)
+			}
+		}
+		if msg != "" {
+			report.Errors = append(report.Errors, ResultRowError{Line: line, Message: msg})
+			continue
+		}
+
+		positions[result.Position] = line
+		if result.RegistrationID.Valid {
+			registrations[result.RegistrationID.Int64] = line
+			// Entrants who gave a date of birth are placed in their age
+			// category unless the file names one
+			if result.Category == "" {
+				result.Category = categories[result.RegistrationID.Int64]
+			}
+		}
+		results = append(results, result)
+	}
+
+	if rows == 0 {
+		return nil, ResultsReport{}, fmt.Errorf("%w: the file has no results", ErrInvalidInput)
+	}
+	return results, report, nil
+}
+
+// resultsHeader maps each field to its column index.
+func resultsHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if field, ok := resultColumns[headerKey(name)]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+
+	for _, required := range []string{"position", "time"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: the header row has no %s column", ErrInvalidInput, required)
+		}
+	}
+	_, hasName := columns["name"]
+	_, hasRegistration := columns["registration"]
+	if !hasName && !hasRegistration {
+		return nil, fmt.Errorf("%w: the header row has no name or registration column", ErrInvalidInput)
+	}
+	return columns, nil
+}
+
+// parseResult builds a result from a row's fields, returning why the row is
+// invalid, or "". Registrations must be in entered.
+func parseResult(field func(string) string, entered map[int64]bool) (db.CreateRaceResultParams, string) {
+	result := db.CreateRaceResultParams{
+		Name:     field("name"),
+		Bib:      field("bib"),
+		Category: field("category"),
+	}
+
+	position, err := strconv.ParseInt(field("position"), 10, 32)
+	if err != nil || position < 1 {
+		return result, fmt.Sprintf("position %q must be a whole number above zero", field("position"))
+	}
+	result.Position = int32(position)
+
+	finish, err := parseFinishTime(field("time"))
+	if err != nil {
+		return result, err.Error()
+	}
+	result.FinishSeconds = int32(finish / time.Second)
+
+	if reg := field("registration"); reg != "" {
+		id, err := strconv.ParseInt(reg, 10, 64)
+		if err != nil || !entered[id] {
+			return result, fmt.Sprintf("registration %q is not entered in this race", reg)
+		}
+		result.RegistrationID = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	switch {
+	case !result.RegistrationID.Valid && result.Name == "":
+		return result, "name is required for a runner without a registration"
+	case len(result.Name) > MaxResultNameLength:
+		return result, fmt.Sprintf("name must be at most %d characters", MaxResultNameLength)
+	case len(result.Bib) > MaxBibLength:
+		return result, fmt.Sprintf("bib must be at most %d characters", MaxBibLength)
+	case len(result.Category) > MaxCategoryLength:
+		return result, fmt.Sprintf("category must be at most %d characters", MaxCategoryLength)
+	}
+	return result, ""
+}
+
+// parseFinishTime parses a finish time written as HH:MM:SS. Hours may run
+// past 24 for ultras and may be written with a single digit.
+func parseFinishTime(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("finish time %q must be HH:MM:SS", s)
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || len(parts[1]) != 2 || len(parts[2]) != 2 {
+		return 0, invalid
+	}
+	var values [3]int
+	for i, part := range parts {
+		if part == "" || strings.TrimLeft(part, "0123456789") != "" {
+			return 0, invalid
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, invalid
+		}
+		values[i] = n
+	}
+	hours, minutes, seconds := values[0], values[1], values[2]
+	if minutes > 59 || seconds > 59 || hours > 999 {
+		return 0, invalid
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second, nil
+}
+
+func (s *resultService) ListResults(ctx context.Context, raceID int64) ([]db.ListRaceResultsRow, error) {
+	return s.resultRepo.ListByRace(ctx, raceID)
+}
+
+func (s *resultService) PublishResults(ctx context.Context, raceID int64) error {
+	results, err := s.resultRepo.ListByRace(ctx, raceID)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return ErrNoResults
+	}
+
+	if _, err := s.resultRepo.Publish(ctx, raceID); err != nil {
+		return fmt.Errorf("failed to publish results: %w", err)
+	}
+	return nil
+}
+
+func (s *resultService) PublishedResults(ctx context.Context, raceID int64, query ResultsQuery) ([]db.ListRaceResultsRow, error) {
+	results, err := s.resultRepo.ListByRace(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	// Results are published a race at a time, so one unpublished result
+	// means the whole upload is still being checked
+	if len(results) == 0 || !results[0].Published {
+		return nil, repository.ErrNotFound
+	}
+
+	if search := strings.ToLower(strings.TrimSpace(query.Search)); search != "" {
+		results = slices.DeleteFunc(results, func(r db.ListRaceResultsRow) bool {
+			return !strings.Contains(strings.ToLower(r.Name), search)
+		})
+	}
+
+	slices.SortStableFunc(results, func(a, b db.ListRaceResultsRow) int {
+		var c int
+		switch query.Sort {
+		case SortByName:
+			c = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		case SortByTime:
+			c = cmp.Compare(a.FinishSeconds, b.FinishSeconds)
+		case SortByCategory:
+			c = strings.Compare(strings.ToLower(a.Category), strings.ToLower(b.Category))
+		default:
+			c = cmp.Compare(a.Position, b.Position)
+		}
+		if query.Desc {
+			c = -c
+		}
+		// Ties keep finishing order whichever way the column runs
+		return cmp.Or(c, cmp.Compare(a.Position, b.Position))
+	})
+	return results, nil
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestParseFinishTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{in: "00:41:07", want: 41*time.Minute + 7*time.Second, ok: true},
+		{in: "3:05:00", want: 3*time.Hour + 5*time.Minute, ok: true},
+		{in: "27:59:59", want: 27*time.Hour + 59*time.Minute + 59*time.Second, ok: true},
+		{in: "", ok: false},
+		{in: "41:07", ok: false},
+		{in: "1:2:3", ok: false},
+		{in: "01:60:00", ok: false},
+		{in: "01:00:60", ok: false},
+		{in: "-1:00:00", ok: false},
+		{in: "01:00:00.5", ok: false},
+		{in: "aa:bb:cc", ok: false},
+		{in: "01:00:00:00", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseFinishTime(tt.in)
+			if tt.ok {
+				if err != nil || got != tt.want {
+					t.Errorf("parseFinishTime(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+				}
+				return
+			}
+			if err == nil {
+				t.Errorf("parseFinishTime(%q) = %v, want an error", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestResultService_UploadResults(t *testing.T) {
+	race := db.Race{ID: 20, EventID: 30, Name: "10K"}
+
+	t.Run("replaces the race's results", func(t *testing.T) {
+		csv := "Position,Registration,Name,Bib,Time,Category\n" +
+			"1,,Ola Nordmann,501,00:34:10,M40\n" +
+			"2,7,,,00:35:02,F\n" +
+			"3,,\"Smith, Jane\",,1:02:03,\n" +
+			"4,8,,,1:10:00,\n"
+
+		var got []db.CreateRaceResultParams
+		raceDay := pgtype.Date{Time: time.Date(2026, time.June, 7, 0, 0, 0, 0, time.UTC), Valid: true}
+		repo := &repositorymocks.ResultRepositoryMock{
+			ListEntrantsFunc: func(ctx context.Context, raceID int64) ([]db.ListRaceResultEntrantsRow, error) {
+				return []db.ListRaceResultEntrantsRow{
+					{ID: 7, DateOfBirth: pgtype.Date{Time: time.Date(1990, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true}, RaceDay: raceDay},
+					{ID: 8, DateOfBirth: pgtype.Date{Time: time.Date(1976, time.June, 7, 0, 0, 0, 0, time.UTC), Valid: true}, RaceDay: raceDay},
+				}, nil
+			},
+			ReplaceFunc: func(ctx context.Context, raceID int64, results []db.CreateRaceResultParams) error {
+				if raceID != race.ID {
+					t.Errorf("expected race %d, got %d", race.ID, raceID)
+				}
+				got = results
+				return nil
+			},
+		}
+		svc := NewResultService(repo, 100)
+
+		report, err := svc.UploadResults(context.Background(), race, strings.NewReader(csv))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Saved != 4 || len(report.Errors) != 0 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+
+		want := []db.CreateRaceResultParams{
+			{Name: "Ola Nordmann", Bib: "501", Position: 1, FinishSeconds: 34*60 + 10, Category: "M40"},
+			{RegistrationID: pgtype.Int8{Int64: 7, Valid: true}, Position: 2, FinishSeconds: 35*60 + 2, Category: "F"},
+			{Name: "Smith, Jane", Position: 3, FinishSeconds: 3723},
+			// Turned 50 on race day, with no category in the file
+			{RegistrationID: pgtype.Int8{Int64: 8, Valid: true}, Position: 4, FinishSeconds: 4200, Category: "V50"},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %d results, got %+v", len(want), got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("result %d = %+v, want %+v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("reports invalid rows and saves nothing", func(t *testing.T) {
+		csv := "position,registration,name,time\n" +
+			"1,,Ola,00:34:10\n" +
+			"2,,Tom,34:10\n" +
+			"3,,Sam,00:61:00\n" +
+			"1,,Priya,00:36:00\n" +
+			"0,,Lee,00:37:00\n" +
+			"5,99,,00:38:00\n" +
+			"6,,,00:39:00\n" +
+			"7,7,,00:40:00\n" +
+			"8,7,,00:41:00\n"
+
+		called := false
+		repo := &repositorymocks.ResultRepositoryMock{
+			ListEntrantsFunc: func(ctx context.Context, raceID int64) ([]db.ListRaceResultEntrantsRow, error) {
+				return []db.ListRaceResultEntrantsRow{{ID: 7}}, nil
+			},
+			ReplaceFunc: func(ctx context.Context, raceID int64, results []db.CreateRaceResultParams) error {
+				called = true
+				return nil
+			},
+		}
+		svc := NewResultService(repo, 100)
+
+		report, err := svc.UploadResults(context.Background(), race, strings.NewReader(csv))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if called {
+			t.Error("expected nothing to be written")
+		}
+		if report.Saved != 0 {
+			t.Errorf("expected nothing saved, got %d", report.Saved)
+		}
+
+		want := []ResultRowError{
+			{Line: 3, Message: `finish time "34:10" must be HH:MM:SS`},
+			{Line: 4, Message: `finish time "00:61:00" must be HH:MM:SS`},
+			{Line: 5, Message: "position 1 is also used on line 2"},
+			{Line: 6, Message: `position "0" must be a whole number above zero`},
+			{Line: 7, Message: `registration "99" is not entered in this race`},
+			{Line: 8, Message: "name is required for a runner without a registration"},
+			{Line: 10, Message: "registration 7 is also used on line 9"},
+		}
+		if len(report.Errors) != len(want) {
+			t.Fatalf("expected %d errors, got %+v", len(want), report.Errors)
+		}
+		for i := range want {
+			if report.Errors[i] != want[i] {
+				t.Errorf("error %d = %+v, want %+v", i, report.Errors[i], want[i])
+			}
+		}
+	})
+
+	t.Run("rejects files missing a column or results", func(t *testing.T) {
+		svc := NewResultService(&repositorymocks.ResultRepositoryMock{}, 100)
+
+		for csv, want := range map[string]string{
+			"name,time\nOla,00:34:10\n":     "position",
+			"position,name\n1,Ola\n":        "time",
+			"position,time\n1,00:34:10\n":   "name or registration",
+			"place,runner,finish time\n":    "no results",
+			"position,bib,time\n1,5,1:00\n": "name or registration",
+		} {
+			_, err := svc.UploadResults(context.Background(), race, strings.NewReader(csv))
+			if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), want) {
+				t.Errorf("UploadResults(%q) = %v, want an error mentioning %q", csv, err, want)
+			}
+		}
+	})
+
+	t.Run("rejects files over the row limit", func(t *testing.T) {
+		csv := "position,name,time\n1,A,00:30:00\n2,B,00:31:00\n3,C,00:32:00\n"
+		svc := NewResultService(&repositorymocks.ResultRepositoryMock{}, 2)
+
+		_, err := svc.UploadResults(context.Background(), race, strings.NewReader(csv))
+		if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "more than 2") {
+			t.Errorf("expected row limit error, got %v", err)
+		}
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		repo := &repositorymocks.ResultRepositoryMock{
+			ReplaceFunc: func(ctx context.Context, raceID int64, results []db.CreateRaceResultParams) error {
+				return errors.New("database error")
+			},
+		}
+		svc := NewResultService(repo, 100)
+
+		_, err := svc.UploadResults(context.Background(), race, strings.NewReader("position,name,time\n1,Ola,00:34:10\n"))
+		if err == nil || errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected a server error, got %v", err)
+		}
+	})
+}
+
+func TestResultService_PublishResults(t *testing.T) {
+	t.Run("publishes staged results", func(t *testing.T) {
+		published := false
+		repo := &repositorymocks.ResultRepositoryMock{
+			ListByRaceFunc: func(ctx context.Context, raceID int64) ([]db.ListRaceResultsRow, error) {
+				return []db.ListRaceResultsRow{{ID: 1, Position: 1}}, nil
+			},
+			PublishFunc: func(ctx context.Context, raceID int64) (int64, error) {
+				published = raceID == 20
+				return 1, nil
+			},
+		}
+
+		if err := NewResultService(repo, 100).PublishResults(context.Background(), 20); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !published {
+			t.Error("expected race 20 to be published")
+		}
+	})
+
+	t.Run("returns ErrNoResults for a race without results", func(t *testing.T) {
+		err := NewResultService(&repositorymocks.ResultRepositoryMock{}, 100).PublishResults(context.Background(), 20)
+		if !errors.Is(err, ErrNoResults) {
+			t.Errorf("expected ErrNoResults, got %v", err)
+		}
+	})
+}
+
+func TestResultService_PublishedResults(t *testing.T) {
+	results := []db.ListRaceResultsRow{
+		{ID: 1, Position: 1, Name: "Ola Nordmann", FinishSeconds: 2050, Category: "M40", Published: true},
+		{ID: 2, Position: 2, Name: "jane smith", FinishSeconds: 2102, Category: "F", Published: true},
+		{ID: 3, Position: 3, Name: "Tom Jones", FinishSeconds: 2102, Category: "M40", Published: true},
+	}
+	newService := func(rows []db.ListRaceResultsRow) ResultService {
+		return NewResultService(&repositorymocks.ResultRepositoryMock{
+			ListByRaceFunc: func(ctx context.Context, raceID int64) ([]db.ListRaceResultsRow, error) {
+				// Callers may reorder the slice they are given
+				return append([]db.ListRaceResultsRow(nil), rows...), nil
+			},
+		}, 100)
+	}
+	positions := func(rows []db.ListRaceResultsRow) []int32 {
+		var got []int32
+		for _, row := range rows {
+			got = append(got, row.Position)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name  string
+		query ResultsQuery
+		want  []int32
+	}{
+		{name: "finishing order by default", want: []int32{1, 2, 3}},
+		{name: "position descending", query: ResultsQuery{Sort: SortByPosition, Desc: true}, want: []int32{3, 2, 1}},
+		{name: "name ignoring case", query: ResultsQuery{Sort: SortByName}, want: []int32{2, 1, 3}},
+		{name: "time with ties in finishing order", query: ResultsQuery{Sort: SortByTime, Desc: true}, want: []int32{2, 3, 1}},
+		{name: "category", query: ResultsQuery{Sort: SortByCategory}, want: []int32{2, 1, 3}},
+		{name: "search by name", query: ResultsQuery{Search: "  JONES "}, want: []int32{3}},
+		{name: "search without matches", query: ResultsQuery{Search: "nobody"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newService(results).PublishedResults(context.Background(), 20, tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotPositions := positions(got); !slices.Equal(gotPositions, tt.want) {
+				t.Errorf("positions = %v, want %v", gotPositions, tt.want)
+			}
+		})
+	}
+
+	t.Run("returns ErrNotFound for unpublished results", func(t *testing.T) {
+		staged := []db.ListRaceResultsRow{{ID: 1, Position: 1, Name: "Ola"}}
+		if _, err := newService(staged).PublishedResults(context.Background(), 20, ResultsQuery{}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrNotFound for a race without results", func(t *testing.T) {
+		if _, err := newService(nil).PublishedResults(context.Background(), 20, ResultsQuery{}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
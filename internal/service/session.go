@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/repository"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/session.go -pkg servicemocks . SessionService
+
+// MaxSessionUserAgentLength bounds the user agent kept for a session.
+// Browsers send far less; anything longer is cut short.
+const MaxSessionUserAgentLength = 512
+
+// sessionTouchInterval is how long a session's last activity may go
+// unrecorded, so busy sessions are not written to on every request.
+const sessionTouchInterval = time.Minute
+
+// ErrSessionRevoked is returned when a session has been revoked or has
+// expired, or was never recorded for the user.
+var ErrSessionRevoked = apperr.New(apperr.CodeSessionRevoked, http.StatusUnauthorized, "session revoked or unknown")
+
+// ErrSessionEvicted is returned when a session was signed out to make room
+// for a newer sign-in to the same account.
+var ErrSessionEvicted = apperr.New(apperr.CodeSessionEvicted, http.StatusUnauthorized, "session signed out by a newer sign-in")
+
+// ErrTooManySessions is returned when signing in would give an account more
+// sessions than it may have at once, under a limit that rejects sign-ins.
+var ErrTooManySessions = apperr.New(apperr.CodeTooManySessions, http.StatusConflict, "too many sessions")
+
+// SessionService defines the interface for user session business logic.
+//
+// Each sign-in is recorded with the device it came from, so users can see
+// where they are signed in and sign other devices out. A revoked session
+// stops working on its next request. Accounts may be limited to a number
+// of sessions at once.
+type SessionService interface {
+	// StartSession records a session the user has just signed in to. Past
+	// the session limit it signs out the user's least recently active
+	// session to make room or, when the limit rejects sign-ins, returns
+	// ErrTooManySessions.
+	StartSession(ctx context.Context, userID int64, params StartSessionParams) (db.UserSession, error)
+	// CheckSession returns ErrSessionRevoked unless the user's session may
+	// still be used, recording activity in it when it may. A session
+	// signed out to make room for another returns ErrSessionEvicted.
+	CheckSession(ctx context.Context, userID, sessionID int64) error
+	// ListSessions returns the user's unrevoked, unexpired sessions, most
+	// recently active first.
+	ListSessions(ctx context.Context, userID int64) ([]db.UserSession, error)
+	// RevokeSession signs the user's session out. It returns
+	// repository.ErrNotFound if the user has no such unrevoked session.
+	RevokeSession(ctx context.Context, userID, sessionID int64) error
+	// RevokeOtherSessions signs out every session of the user's except
+	// keepSessionID, returning how many were signed out.
+	RevokeOtherSessions(ctx context.Context, userID, keepSessionID int64) (int64, error)
+	// SignOutEverywhere signs out every session of the user's, the one
+	// asking included, returning how many were signed out.
+	SignOutEverywhere(ctx context.Context, userID int64) (int64, error)
+}
+
+// StartSessionParams describes the device a session was started from.
+type StartSessionParams struct {
+	UserAgent string
+	IPAddress string
+	// ExpiresAt is when the session ends, however active it is.
+	ExpiresAt time.Time
+}
+
+// SessionLimit bounds how many sessions each account may have at once. A
+// zero Max allows any number.
+type SessionLimit struct {
+	Max int
+	// Reject refuses a sign-in past Max, rather than signing out the
+	// least recently active session to make room for it.
+	Reject bool
+}
+
+type sessionService struct {
+	sessionRepo repository.SessionRepository
+	limit       SessionLimit
+	clock       Clock
+}
+
+// NewSessionService creates a new SessionService with the given repository,
+// keeping accounts within limit.
+func NewSessionService(sessionRepo repository.SessionRepository, limit SessionLimit) SessionService {
+	return &sessionService{sessionRepo: sessionRepo, limit: limit, clock: RealClock{}}
+}
+
+func (s *sessionService) StartSession(ctx context.Context, userID int64, params StartSessionParams) (db.UserSession, error) {
+	if userID <= 0 {
+		return db.UserSession{}, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+	if !params.ExpiresAt.After(s.clock.Now()) {
+		return db.UserSession{}, fmt.Errorf("%w: session must expire in the future", ErrInvalidInput)
+	}
+
+	create := db.CreateUserSessionParams{
+		UserID:    userID,
+		UserAgent: truncateUserAgent(strings.TrimSpace(params.UserAgent)),
+		IpAddress: params.IPAddress,
+		ExpiresAt: pgtype.Timestamptz{Time: params.ExpiresAt, Valid: true},
+	}
+	var session db.UserSession
+	var err error
+	if s.limit.Max > 0 {
+		session, err = s.sessionRepo.CreateWithin(ctx, create, s.limit.Max, !s.limit.Reject)
+	} else {
+		session, err = s.sessionRepo.Create(ctx, create)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionLimit) {
+			return db.UserSession{}, ErrTooManySessions
+		}
+		return db.UserSession{}, fmt.Errorf("failed to record session: %w", err)
+	}
+	return session, nil
+}
+
+// truncateUserAgent cuts ua to MaxSessionUserAgentLength bytes without
+// splitting a character.
+func truncateUserAgent(ua string) string {
+	if len(ua) <= MaxSessionUserAgentLength {
+		return ua
+	}
+	ua = ua[:MaxSessionUserAgentLength]
+	for !utf8.ValidString(ua) {
+		ua = ua[:len(ua)-1]
+	}
+	return ua
+}
+
+func (s *sessionService) CheckSession(ctx context.Context, userID, sessionID int64) error {
+	// Sessions started before sessions were recorded have no id, and
+	// must sign in again to be listed
+	if sessionID <= 0 {
+		return ErrSessionRevoked
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSessionRevoked
+		}
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	now := s.clock.Now()
+	if session.UserID != userID {
+		return ErrSessionRevoked
+	}
+	if session.EvictedAt.Valid {
+		return ErrSessionEvicted
+	}
+	if session.RevokedAt.Valid || !now.Before(session.ExpiresAt.Time) {
+		return ErrSessionRevoked
+	}
+
+	if now.Sub(session.LastSeenAt.Time) >= sessionTouchInterval {
+		if err := s.sessionRepo.Touch(ctx, session.ID); err != nil {
+			return fmt.Errorf("failed to record session activity: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *sessionService) ListSessions(ctx context.Context, userID int64) ([]db.UserSession, error) {
+	return s.sessionRepo.ListForUser(ctx, userID)
+}
+
+func (s *sessionService) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	return s.sessionRepo.Revoke(ctx, userID, sessionID)
+}
+
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userID, keepSessionID int64) (int64, error) {
+	n, err := s.sessionRepo.RevokeOthers(ctx, userID, keepSessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return n, nil
+}
+
+func (s *sessionService) SignOutEverywhere(ctx context.Context, userID int64) (int64, error) {
+	if userID <= 0 {
+		return 0, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+	n, err := s.sessionRepo.RevokeAll(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestSessionService_StartSession(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("records the device the session was started from", func(t *testing.T) {
+		var stored db.CreateUserSessionParams
+		svc := &sessionService{
+			sessionRepo: &repositorymocks.SessionRepositoryMock{
+				CreateFunc: func(ctx context.Context, params db.CreateUserSessionParams) (db.UserSession, error) {
+					stored = params
+					return db.UserSession{ID: 9, UserID: params.UserID}, nil
+				},
+			},
+			clock: &MockClock{CurrentTime: now},
+		}
+
+		session, err := svc.StartSession(context.Background(), 3, StartSessionParams{
+			UserAgent: " Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4) Firefox/125.0 ",
+			IPAddress: "203.0.113.9",
+			ExpiresAt: now.Add(12 * time.Hour),
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if session.ID != 9 {
+			t.Errorf("expected session 9, got %d", session.ID)
+		}
+		want := db.CreateUserSessionParams{
+			UserID:    3,
+			UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4) Firefox/125.0",
+			IpAddress: "203.0.113.9",
+			ExpiresAt: pgtype.Timestamptz{Time: now.Add(12 * time.Hour), Valid: true},
+		}
+		if stored != want {
+			t.Errorf("expected %+v, got %+v", want, stored)
+		}
+	})
+
+	t.Run("cuts long user agents short", func(t *testing.T) {
+		var stored db.CreateUserSessionParams
+		svc := &sessionService{
+			sessionRepo: &repositorymocks.SessionRepositoryMock{
+				CreateFunc: func(ctx context.Context, params db.CreateUserSessionParams) (db.UserSession, error) {
+					stored = params
+					return db.UserSession{}, nil
+				},
+			},
+			clock: &MockClock{CurrentTime: now},
+		}
+
+		_, err := svc.StartSession(context.Background(), 3, StartSessionParams{
+			UserAgent: "a" + strings.Repeat("é", MaxSessionUserAgentLength),
+			ExpiresAt: now.Add(time.Hour),
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(stored.UserAgent) != MaxSessionUserAgentLength-1 || !strings.HasSuffix(stored.UserAgent, "é") {
+			t.Errorf("expected the user agent cut at a character boundary, got %d bytes", len(stored.UserAgent))
+		}
+	})
+
+	t.Run("rejects a session that has already expired", func(t *testing.T) {
+		svc := &sessionService{sessionRepo: &repositorymocks.SessionRepositoryMock{}, clock: &MockClock{CurrentTime: now}}
+
+		_, err := svc.StartSession(context.Background(), 3, StartSessionParams{ExpiresAt: now})
+
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestSessionService_SessionLimit(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// newService keeps sessions in memory, signing out the least recently
+	// active past the limit as the repository does
+	newService := func(limit SessionLimit) *sessionService {
+		var sessions []db.UserSession
+		return &sessionService{
+			sessionRepo: &repositorymocks.SessionRepositoryMock{
+				CreateWithinFunc: func(ctx context.Context, params db.CreateUserSessionParams, max int, evict bool) (db.UserSession, error) {
+					var active []int
+					for i, s := range sessions {
+						if s.UserID == params.UserID && !s.RevokedAt.Valid {
+							active = append(active, i)
+						}
+					}
+					if len(active) >= max {
+						if !evict {
+							return db.UserSession{}, repository.ErrSessionLimit
+						}
+						for _, i := range active[:len(active)-max+1] {
+							sessions[i].RevokedAt = pgtype.Timestamptz{Time: now, Valid: true}
+							sessions[i].EvictedAt = pgtype.Timestamptz{Time: now, Valid: true}
+						}
+					}
+					session := db.UserSession{
+						ID:        int64(len(sessions) + 1),
+						UserID:    params.UserID,
+						ExpiresAt: params.ExpiresAt,
+					}
+					sessions = append(sessions, session)
+					return session, nil
+				},
+				GetByIDFunc: func(ctx context.Context, id int64) (db.UserSession, error) {
+					return sessions[id-1], nil
+				},
+			},
+			limit: limit,
+			clock: &MockClock{CurrentTime: now},
+		}
+	}
+	signIn := func(svc *sessionService) (db.UserSession, error) {
+		return svc.StartSession(context.Background(), 3, StartSessionParams{ExpiresAt: now.Add(time.Hour)})
+	}
+
+	t.Run("signs the oldest session out to make room", func(t *testing.T) {
+		svc := newService(SessionLimit{Max: 2})
+
+		var started []db.UserSession
+		for range 3 {
+			session, err := signIn(svc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			started = append(started, session)
+		}
+
+		if err := svc.CheckSession(context.Background(), 3, started[0].ID); !errors.Is(err, ErrSessionEvicted) {
+			t.Errorf("expected the first session evicted, got %v", err)
+		}
+		for _, session := range started[1:] {
+			if err := svc.CheckSession(context.Background(), 3, session.ID); err != nil {
+				t.Errorf("expected session %d to still work, got %v", session.ID, err)
+			}
+		}
+	})
+
+	t.Run("refuses the newest sign-in when the limit rejects", func(t *testing.T) {
+		svc := newService(SessionLimit{Max: 2, Reject: true})
+
+		var started []db.UserSession
+		for range 2 {
+			session, err := signIn(svc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			started = append(started, session)
+		}
+		if _, err := signIn(svc); !errors.Is(err, ErrTooManySessions) {
+			t.Fatalf("expected ErrTooManySessions, got %v", err)
+		}
+
+		for _, session := range started {
+			if err := svc.CheckSession(context.Background(), 3, session.ID); err != nil {
+				t.Errorf("expected session %d to still work, got %v", session.ID, err)
+			}
+		}
+	})
+
+	t.Run("allows any number without a limit", func(t *testing.T) {
+		repo := &repositorymocks.SessionRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateUserSessionParams) (db.UserSession, error) {
+				return db.UserSession{ID: 1}, nil
+			},
+		}
+		svc := &sessionService{sessionRepo: repo, clock: &MockClock{CurrentTime: now}}
+
+		if _, err := signIn(svc); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.CreateWithinCalls()) != 0 {
+			t.Error("expected no limit to be applied")
+		}
+	})
+}
+
+func TestSessionService_CheckSession(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	active := db.UserSession{
+		ID:         9,
+		UserID:     3,
+		LastSeenAt: pgtype.Timestamptz{Time: now.Add(-10 * time.Second), Valid: true},
+		ExpiresAt:  pgtype.Timestamptz{Time: now.Add(time.Hour), Valid: true},
+	}
+
+	newService := func(session db.UserSession, touched *bool) *sessionService {
+		return &sessionService{
+			sessionRepo: &repositorymocks.SessionRepositoryMock{
+				GetByIDFunc: func(ctx context.Context, id int64) (db.UserSession, error) {
+					if id != session.ID {
+						return db.UserSession{}, repository.ErrNotFound
+					}
+					return session, nil
+				},
+				TouchFunc: func(ctx context.Context, id int64) error {
+					*touched = true
+					return nil
+				},
+			},
+			clock: &MockClock{CurrentTime: now},
+		}
+	}
+
+	t.Run("accepts an active session without recording recent activity again", func(t *testing.T) {
+		var touched bool
+		svc := newService(active, &touched)
+
+		if err := svc.CheckSession(context.Background(), 3, 9); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if touched {
+			t.Error("expected activity seconds ago not to be recorded again")
+		}
+	})
+
+	t.Run("records activity after a quiet spell", func(t *testing.T) {
+		var touched bool
+		session := active
+		session.LastSeenAt.Time = now.Add(-sessionTouchInterval)
+		svc := newService(session, &touched)
+
+		if err := svc.CheckSession(context.Background(), 3, 9); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !touched {
+			t.Error("expected the activity to be recorded")
+		}
+	})
+
+	revoked := active
+	revoked.RevokedAt = pgtype.Timestamptz{Time: now.Add(-time.Minute), Valid: true}
+	expired := active
+	expired.ExpiresAt.Time = now
+
+	tests := []struct {
+		name      string
+		session   db.UserSession
+		userID    int64
+		sessionID int64
+	}{
+		{name: "a revoked session", session: revoked, userID: 3, sessionID: 9},
+		{name: "an expired session", session: expired, userID: 3, sessionID: 9},
+		{name: "another user's session", session: active, userID: 4, sessionID: 9},
+		{name: "an unknown session", session: active, userID: 3, sessionID: 10},
+		{name: "a session started before sessions were recorded", session: active, userID: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			var touched bool
+			svc := newService(tt.session, &touched)
+
+			if err := svc.CheckSession(context.Background(), tt.userID, tt.sessionID); !errors.Is(err, ErrSessionRevoked) {
+				t.Errorf("expected ErrSessionRevoked, got %v", err)
+			}
+			if touched {
+				t.Error("expected no activity to be recorded")
+			}
+		})
+	}
+}
+
+func TestSessionService_SignOutEverywhere(t *testing.T) {
+	t.Run("revokes every session", func(t *testing.T) {
+		repo := &repositorymocks.SessionRepositoryMock{
+			RevokeAllFunc: func(ctx context.Context, userID int64) (int64, error) {
+				return 3, nil
+			},
+		}
+		svc := &sessionService{sessionRepo: repo, clock: RealClock{}}
+
+		n, err := svc.SignOutEverywhere(context.Background(), 7)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 3 {
+			t.Errorf("expected 3 sessions signed out, got %d", n)
+		}
+		calls := repo.RevokeAllCalls()
+		if len(calls) != 1 || calls[0].UserID != 7 {
+			t.Errorf("expected all of user 7's sessions revoked, got %+v", calls)
+		}
+	})
+
+	t.Run("rejects invalid users", func(t *testing.T) {
+		svc := &sessionService{sessionRepo: &repositorymocks.SessionRepositoryMock{}, clock: RealClock{}}
+
+		if _, err := svc.SignOutEverywhere(context.Background(), 0); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
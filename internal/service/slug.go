@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxSlugLength is the longest slug accepted for events and races.
+const MaxSlugLength = 100
+
+// reservedSlugs collide with top-level routes and cannot be used as slugs.
+var reservedSlugs = map[string]bool{
+	"static":  true,
+	"auth":    true,
+	"admin":   true,
+	"api":     true,
+	"healthz": true,
+	// Shadowed by the past events page under /events/
+	"archive": true,
+}
+
+// slugReplacements transliterates characters that Unicode decomposition
+// does not reduce to a plain ASCII letter. Apostrophes are dropped so that
+// "Runner's Half" becomes "runners-half" rather than "runner-s-half".
+var slugReplacements = map[rune]string{
+	'ß':  "ss",
+	'æ':  "ae",
+	'œ':  "oe",
+	'ø':  "o",
+	'ł':  "l",
+	'đ':  "d",
+	'ð':  "d",
+	'þ':  "th",
+	'&':  " and ",
+	'\'': "",
+	'’':  "",
+}
+
+// Slugify converts s into a URL-safe slug: lowercase ASCII letters and
+// digits separated by single hyphens. Accented letters are transliterated
+// to their base letter and any other characters become separators.
+func Slugify(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	pendingHyphen := false
+	write := func(r rune) {
+		if pendingHyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingHyphen = false
+		b.WriteRune(r)
+	}
+
+	for _, r := range norm.NFKD.String(strings.ToLower(s)) {
+		if repl, ok := slugReplacements[r]; ok {
+			for _, rr := range repl {
+				if rr == ' ' {
+					pendingHyphen = true
+					continue
+				}
+				write(rr)
+			}
+			continue
+		}
+
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Drop combining marks left over from decomposition.
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			write(r)
+		default:
+			pendingHyphen = true
+		}
+	}
+
+	return b.String()
+}
+
+// NormalizeSlug turns s into the closest valid slug, for suggesting a
+// correction when validateSlug rejects user input. Over-long slugs are
+// trimmed at a hyphen where possible and reserved words are given a suffix.
+// It returns "" when s contains nothing usable.
+func NormalizeSlug(s string) string {
+	slug := Slugify(s)
+	if len(slug) > MaxSlugLength {
+		slug = slug[:MaxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimSuffix(slug, "-")
+	}
+	if reservedSlugs[slug] {
+		slug += "-event"
+	}
+	return slug
+}
+
+// validateSlug checks that s is a slug Slugify could have produced: lowercase
+// ASCII letters and digits separated by single hyphens, no longer than
+// MaxSlugLength and not a reserved route name.
+func validateSlug(s string) error {
+	if msg := slugProblem(s); msg != "" {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, msg)
+	}
+	return nil
+}
+
+// slugProblem returns why s is not a valid slug, or "".
+func slugProblem(s string) string {
+	if s == "" {
+		return "slug is required"
+	}
+	if len(s) > MaxSlugLength {
+		return fmt.Sprintf("slug must be %d characters or less", MaxSlugLength)
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '-':
+			if i == 0 || i == len(s)-1 || s[i-1] == '-' {
+				return "slug must not start, end or repeat hyphens"
+			}
+		default:
+			return "slug may only contain lowercase letters, digits and hyphens"
+		}
+	}
+	if reservedSlugs[s] {
+		return fmt.Sprintf("slug %q is reserved", s)
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "lowercases and hyphenates words", input: "Lincoln 10k", want: "lincoln-10k"},
+		{name: "trims surrounding whitespace", input: "  Peak District Ultra  ", want: "peak-district-ultra"},
+		{name: "collapses repeated separators", input: "Lakes -- Trail   Run", want: "lakes-trail-run"},
+		{name: "collapses repeated hyphens", input: "half---marathon", want: "half-marathon"},
+		{name: "strips leading and trailing hyphens", input: "-fell-race-", want: "fell-race"},
+		{name: "transliterates accented letters", input: "Café Crème Ténèbres", want: "cafe-creme-tenebres"},
+		{name: "transliterates welsh circumflex", input: "Ras Yr Wyddfa Pontrhydfendigaid Ŵ", want: "ras-yr-wyddfa-pontrhydfendigaid-w"},
+		{name: "transliterates non-decomposing letters", input: "Straße Ærø Łódź", want: "strasse-aero-lodz"},
+		{name: "replaces ampersand with and", input: "Swim & Run", want: "swim-and-run"},
+		{name: "drops apostrophes", input: "Runner's Half", want: "runners-half"},
+		{name: "drops emoji and symbols", input: "🏃 Fun Run! 🎉", want: "fun-run"},
+		{name: "drops non-latin scripts", input: "東京 Marathon", want: "marathon"},
+		{name: "replaces path separators", input: "10k/5k", want: "10k-5k"},
+		{name: "returns empty for punctuation only", input: "!!!", want: ""},
+		{name: "returns empty for empty input", input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.input); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSlug(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "accepts lowercase words", input: "lincoln-10k"},
+		{name: "accepts single character", input: "a"},
+		{name: "accepts maximum length", input: strings.Repeat("a", 100)},
+		{name: "accepts reserved word as prefix", input: "admin-cup"},
+		{name: "rejects empty", input: "", wantErr: true},
+		{name: "rejects over maximum length", input: strings.Repeat("a", 101), wantErr: true},
+		{name: "rejects uppercase", input: "Lincoln-10k", wantErr: true},
+		{name: "rejects spaces", input: "lincoln 10k", wantErr: true},
+		{name: "rejects slash", input: "10k/5k", wantErr: true},
+		{name: "rejects path traversal", input: "../../etc", wantErr: true},
+		{name: "rejects encoded traversal", input: "..%2fetc", wantErr: true},
+		{name: "rejects leading hyphen", input: "-fell-race", wantErr: true},
+		{name: "rejects trailing hyphen", input: "fell-race-", wantErr: true},
+		{name: "rejects double hyphen", input: "fell--race", wantErr: true},
+		{name: "rejects accented letters", input: "café-run", wantErr: true},
+		{name: "rejects non-latin scripts", input: "東京", wantErr: true},
+		{name: "rejects emoji", input: "fun-run-🎉", wantErr: true},
+		{name: "rejects static", input: "static", wantErr: true},
+		{name: "rejects auth", input: "auth", wantErr: true},
+		{name: "rejects admin", input: "admin", wantErr: true},
+		{name: "rejects api", input: "api", wantErr: true},
+		{name: "rejects healthz", input: "healthz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSlug(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Errorf("validateSlug(%q) = %v, want ErrInvalidInput", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("validateSlug(%q) = %v, want nil", tt.input, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeSlug(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "leaves valid slug unchanged", input: "lincoln-10k", want: "lincoln-10k"},
+		{name: "lowercases and hyphenates", input: "Lincoln 10K", want: "lincoln-10k"},
+		{name: "transliterates unicode", input: "Café Crème", want: "cafe-creme"},
+		{name: "drops emoji", input: "🏃 Fun Run 🎉", want: "fun-run"},
+		{name: "neutralises path traversal", input: "../../etc", want: "etc"},
+		{name: "suffixes reserved words", input: "Admin", want: "admin-event"},
+		{name: "truncates at a hyphen", input: strings.Repeat("a", 95) + " marathon", want: strings.Repeat("a", 95)},
+		{name: "returns empty when nothing usable", input: "🎉", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeSlug(tt.input)
+			if got != tt.want {
+				t.Errorf("NormalizeSlug(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if got != "" {
+				if err := validateSlug(got); err != nil {
+					t.Errorf("NormalizeSlug(%q) = %q, which fails validation: %v", tt.input, got, err)
+				}
+			}
+		})
+	}
+}
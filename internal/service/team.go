@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+
+	"firecrest/db"
+	"firecrest/internal/bus"
+	"firecrest/internal/repository"
+)
+
+// teamInviteCodeLength is the number of characters in a team invite code.
+// Codes are drawn from the base32 alphabet, so ten give 50 bits.
+const teamInviteCodeLength = 10
+
+func (s *registrationService) CreateTeam(ctx context.Context, captainUserID, raceID int64, name string, size int) (db.Team, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.CreateTeam")
+	defer span.End()
+
+	name = strings.TrimSpace(name)
+	if name == "" || size < MinTeamSize || size > MaxTeamSize {
+		return db.Team{}, ErrInvalidTeam
+	}
+
+	race, err := s.raceRepo.GetByID(ctx, raceID)
+	if err != nil {
+		return db.Team{}, err
+	}
+	now := s.clock.Now()
+	if !registrationOpen(race, now) {
+		return db.Team{}, ErrRegistrationClosed
+	}
+	if err := s.checkEmailVerified(ctx, captainUserID); err != nil {
+		return db.Team{}, err
+	}
+
+	// Members cannot join once registration closes, so there is no point
+	// holding places for them any longer
+	fillBy := now.Add(s.teamFillWindow)
+	if race.RegistrationCloseDate.Valid && race.RegistrationCloseDate.Time.Before(fillBy) {
+		fillBy = race.RegistrationCloseDate.Time
+	}
+
+	created, err := s.registrationRepo.CreateTeam(ctx, repository.CreateTeamParams{
+		RaceID:        race.ID,
+		CaptainUserID: captainUserID,
+		Name:          name,
+		Size:          size,
+		InviteCode:    newTeamInviteCode(),
+		FillBy:        fillBy,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAlreadyRegistered):
+			return db.Team{}, ErrAlreadyRegistered
+		case errors.Is(err, repository.ErrCapacityExceeded):
+			return db.Team{}, ErrRaceFull
+		case errors.Is(err, repository.ErrNotPublished):
+			return db.Team{}, ErrRegistrationClosed
+		case errors.Is(err, repository.ErrNotFound):
+			return db.Team{}, err
+		}
+		return db.Team{}, fmt.Errorf("failed to create team: %w", err)
+	}
+	bus.Publish(ctx, s.events, bus.RegistrationCreated, bus.RegistrationChange{RegistrationID: created.Registration.ID, RaceID: race.ID, EventID: race.EventID})
+
+	if err := s.sendConfirmationEmail(ctx, created.Registration.ID); err != nil {
+		return created.Team, err
+	}
+	return created.Team, nil
+}
+
+func (s *registrationService) JoinTeam(ctx context.Context, userID int64, code string) (db.Registration, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.JoinTeam")
+	defer span.End()
+
+	team, err := s.registrationRepo.GetTeamByInviteCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return db.Registration{}, ErrInvalidTeamCode
+		}
+		return db.Registration{}, fmt.Errorf("failed to load team: %w", err)
+	}
+
+	// The release job runs periodically, so the deadline is checked here
+	// rather than relying on it having caught up
+	now := s.clock.Now()
+	if team.ReleasedAt.Valid || !now.Before(team.FillBy.Time) {
+		return db.Registration{}, ErrTeamClosed
+	}
+
+	race, err := s.raceRepo.GetByID(ctx, team.RaceID)
+	if err != nil {
+		return db.Registration{}, err
+	}
+	if !registrationOpen(race, now) {
+		return db.Registration{}, ErrTeamClosed
+	}
+	if err := s.checkEmailVerified(ctx, userID); err != nil {
+		return db.Registration{}, err
+	}
+
+	reg, err := s.registrationRepo.JoinTeam(ctx, userID, team.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAlreadyRegistered):
+			return db.Registration{}, ErrAlreadyRegistered
+		case errors.Is(err, repository.ErrTeamFull):
+			return db.Registration{}, ErrTeamFull
+		case errors.Is(err, repository.ErrNotFound), errors.Is(err, repository.ErrNotPublished):
+			return db.Registration{}, ErrTeamClosed
+		}
+		return db.Registration{}, fmt.Errorf("failed to join team: %w", err)
+	}
+	bus.Publish(ctx, s.events, bus.RegistrationCreated, bus.RegistrationChange{RegistrationID: reg.ID, RaceID: race.ID, EventID: race.EventID})
+
+	if err := s.sendConfirmationEmail(ctx, reg.ID); err != nil {
+		return reg, err
+	}
+	return reg, nil
+}
+
+// newTeamInviteCode returns a random code for members to join a team with.
+// It is upper case, so codes typed in lower case are normalised to match.
+func newTeamInviteCode() string {
+	return rand.Text()[:teamInviteCodeLength]
+}
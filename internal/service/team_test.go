@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestRegistrationService_CreateTeam(t *testing.T) {
+	const captainID int64 = 7
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	race := db.Race{
+		ID:                    20,
+		EventID:               30,
+		Name:                  "Relay",
+		RegistrationOpenDate:  pgtype.Timestamptz{Time: now.Add(-24 * time.Hour), Valid: true},
+		RegistrationCloseDate: pgtype.Timestamptz{Time: now.Add(30 * 24 * time.Hour), Valid: true},
+		MaxCapacity:           100,
+	}
+
+	newService := func(repo *repositorymocks.RegistrationRepositoryMock, race db.Race, counter *recordingCounter) *registrationService {
+		if repo.IsEmailVerifiedFunc == nil {
+			repo.IsEmailVerifiedFunc = func(ctx context.Context, userID int64) (bool, error) {
+				return true, nil
+			}
+		}
+		races := &repositorymocks.RaceRepositoryMock{GetByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+			return race, nil
+		}}
+		svc := NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, races, &mockPaymentService{}, &mockDiscountService{}, counterBus(counter), &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 72*time.Hour, 100, BibRange{}).(*registrationService)
+		svc.clock = &MockClock{CurrentTime: now}
+		return svc
+	}
+
+	t.Run("reserves places for the whole team", func(t *testing.T) {
+		var got repository.CreateTeamParams
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateTeamFunc: func(ctx context.Context, params repository.CreateTeamParams) (repository.CreatedTeam, error) {
+				got = params
+				return repository.CreatedTeam{
+					Team:         db.Team{ID: 5, RaceID: params.RaceID, Name: params.Name, Size: int32(params.Size), InviteCode: params.InviteCode},
+					Registration: db.Registration{ID: 100, UserID: params.CaptainUserID, RaceID: params.RaceID},
+				}, nil
+			},
+		}
+		counter := &recordingCounter{}
+
+		team, err := newService(repo, race, counter).CreateTeam(context.Background(), captainID, race.ID, "  Harriers A ", 4)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if team.ID != 5 {
+			t.Errorf("expected the created team, got %+v", team)
+		}
+		if got.RaceID != race.ID || got.CaptainUserID != captainID || got.Name != "Harriers A" || got.Size != 4 {
+			t.Errorf("unexpected team params: %+v", got)
+		}
+		if len(got.InviteCode) != teamInviteCodeLength {
+			t.Errorf("expected a %d character invite code, got %q", teamInviteCodeLength, got.InviteCode)
+		}
+		if want := now.Add(72 * time.Hour); !got.FillBy.Equal(want) {
+			t.Errorf("expected the team to fill by %v, got %v", want, got.FillBy)
+		}
+		if len(counter.invalidated) != 1 || counter.invalidated[0] != race.EventID {
+			t.Errorf("expected event %d counts to be invalidated, got %v", race.EventID, counter.invalidated)
+		}
+	})
+
+	t.Run("releases places no later than registration closes", func(t *testing.T) {
+		closing := race
+		closing.RegistrationCloseDate = pgtype.Timestamptz{Time: now.Add(6 * time.Hour), Valid: true}
+		var got repository.CreateTeamParams
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateTeamFunc: func(ctx context.Context, params repository.CreateTeamParams) (repository.CreatedTeam, error) {
+				got = params
+				return repository.CreatedTeam{}, nil
+			},
+		}
+
+		if _, err := newService(repo, closing, &recordingCounter{}).CreateTeam(context.Background(), captainID, race.ID, "Harriers A", 4); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got.FillBy.Equal(closing.RegistrationCloseDate.Time) {
+			t.Errorf("expected the team to fill by registration close, got %v", got.FillBy)
+		}
+	})
+
+	t.Run("rejects invalid teams", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			teamName string
+			size     int
+		}{
+			{name: "blank name", teamName: "  ", size: 4},
+			{name: "too small", teamName: "Solo", size: MinTeamSize - 1},
+			{name: "too large", teamName: "Everyone", size: MaxTeamSize + 1},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := &repositorymocks.RegistrationRepositoryMock{
+					CreateTeamFunc: func(ctx context.Context, params repository.CreateTeamParams) (repository.CreatedTeam, error) {
+						t.Fatal("expected no team to be created")
+						return repository.CreatedTeam{}, nil
+					},
+				}
+
+				_, err := newService(repo, race, &recordingCounter{}).CreateTeam(context.Background(), captainID, race.ID, tt.teamName, tt.size)
+				if !errors.Is(err, ErrInvalidTeam) {
+					t.Errorf("expected ErrInvalidTeam, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("rejects teams outside the registration window", func(t *testing.T) {
+		closed := race
+		closed.RegistrationCloseDate = pgtype.Timestamptz{Time: now, Valid: true}
+
+		_, err := newService(&repositorymocks.RegistrationRepositoryMock{}, closed, &recordingCounter{}).CreateTeam(context.Background(), captainID, race.ID, "Harriers A", 4)
+		if !errors.Is(err, ErrRegistrationClosed) {
+			t.Errorf("expected ErrRegistrationClosed, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrRaceFull when the race cannot fit the team", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateTeamFunc: func(ctx context.Context, params repository.CreateTeamParams) (repository.CreatedTeam, error) {
+				return repository.CreatedTeam{}, repository.ErrCapacityExceeded
+			},
+		}
+		counter := &recordingCounter{}
+
+		_, err := newService(repo, race, counter).CreateTeam(context.Background(), captainID, race.ID, "Harriers A", 4)
+		if !errors.Is(err, ErrRaceFull) {
+			t.Errorf("expected ErrRaceFull, got %v", err)
+		}
+		if len(counter.invalidated) != 0 {
+			t.Errorf("expected no counts to be invalidated, got %v", counter.invalidated)
+		}
+	})
+
+	t.Run("returns ErrAlreadyRegistered when the captain holds a place", func(t *testing.T) {
+		repo := &repositorymocks.RegistrationRepositoryMock{
+			CreateTeamFunc: func(ctx context.Context, params repository.CreateTeamParams) (repository.CreatedTeam, error) {
+				return repository.CreatedTeam{}, repository.ErrAlreadyRegistered
+			},
+		}
+
+		_, err := newService(repo, race, &recordingCounter{}).CreateTeam(context.Background(), captainID, race.ID, "Harriers A", 4)
+		if !errors.Is(err, ErrAlreadyRegistered) {
+			t.Errorf("expected ErrAlreadyRegistered, got %v", err)
+		}
+	})
+}
+
+func TestRegistrationService_JoinTeam(t *testing.T) {
+	const userID int64 = 8
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	race := db.Race{
+		ID:                    20,
+		EventID:               30,
+		RegistrationCloseDate: pgtype.Timestamptz{Time: now.Add(30 * 24 * time.Hour), Valid: true},
+		MaxCapacity:           100,
+	}
+	team := db.Team{
+		ID:         5,
+		RaceID:     race.ID,
+		Name:       "Harriers A",
+		Size:       4,
+		InviteCode: "ABCDEFGHIJ",
+		FillBy:     pgtype.Timestamptz{Time: now.Add(time.Hour), Valid: true},
+	}
+
+	newService := func(repo *repositorymocks.RegistrationRepositoryMock, clock *MockClock) *registrationService {
+		if repo.IsEmailVerifiedFunc == nil {
+			repo.IsEmailVerifiedFunc = func(ctx context.Context, userID int64) (bool, error) {
+				return true, nil
+			}
+		}
+		races := &repositorymocks.RaceRepositoryMock{GetByIDFunc: func(ctx context.Context, id int64) (db.Race, error) {
+			return race, nil
+		}}
+		svc := NewRegistrationService(repo, &repositorymocks.OrganisationRepositoryMock{}, races, &mockPaymentService{}, &mockDiscountService{}, nil, &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 72*time.Hour, 100, BibRange{}).(*registrationService)
+		svc.clock = clock
+		return svc
+	}
+	teamRepo := func(join func(ctx context.Context, userID, teamID int64) (db.Registration, error)) *repositorymocks.RegistrationRepositoryMock {
+		return &repositorymocks.RegistrationRepositoryMock{
+			GetTeamByInviteCodeFunc: func(ctx context.Context, code string) (db.Team, error) {
+				if code != team.InviteCode {
+					return db.Team{}, repository.ErrNotFound
+				}
+				return team, nil
+			},
+			JoinTeamFunc: join,
+		}
+	}
+
+	t.Run("joins the team with its invite code", func(t *testing.T) {
+		var gotUser, gotTeam int64
+		repo := teamRepo(func(ctx context.Context, userID, teamID int64) (db.Registration, error) {
+			gotUser, gotTeam = userID, teamID
+			return db.Registration{ID: 101, UserID: userID, RaceID: race.ID, TeamID: pgtype.Int8{Int64: teamID, Valid: true}}, nil
+		})
+
+		reg, err := newService(repo, &MockClock{CurrentTime: now}).JoinTeam(context.Background(), userID, " abcdefghij ")
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if reg.ID != 101 || gotUser != userID || gotTeam != team.ID {
+			t.Errorf("expected user %d to join team %d, got %+v", userID, team.ID, reg)
+		}
+	})
+
+	t.Run("returns ErrInvalidTeamCode for an unknown code", func(t *testing.T) {
+		_, err := newService(teamRepo(nil), &MockClock{CurrentTime: now}).JoinTeam(context.Background(), userID, "NOPE")
+		if !errors.Is(err, ErrInvalidTeamCode) {
+			t.Errorf("expected ErrInvalidTeamCode, got %v", err)
+		}
+	})
+
+	t.Run("refuses users who have not verified their email", func(t *testing.T) {
+		repo := teamRepo(func(ctx context.Context, userID, teamID int64) (db.Registration, error) {
+			t.Error("expected the team not to be joined")
+			return db.Registration{}, nil
+		})
+		repo.IsEmailVerifiedFunc = func(ctx context.Context, id int64) (bool, error) {
+			return false, nil
+		}
+
+		_, err := newService(repo, &MockClock{CurrentTime: now}).JoinTeam(context.Background(), userID, team.InviteCode)
+		if !errors.Is(err, ErrEmailNotVerified) {
+			t.Errorf("expected ErrEmailNotVerified, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrTeamFull once every place is taken", func(t *testing.T) {
+		repo := teamRepo(func(ctx context.Context, userID, teamID int64) (db.Registration, error) {
+			return db.Registration{}, repository.ErrTeamFull
+		})
+
+		_, err := newService(repo, &MockClock{CurrentTime: now}).JoinTeam(context.Background(), userID, team.InviteCode)
+		if !errors.Is(err, ErrTeamFull) {
+			t.Errorf("expected ErrTeamFull, got %v", err)
+		}
+	})
+
+	t.Run("closes the team at its fill-by time", func(t *testing.T) {
+		repo := teamRepo(func(ctx context.Context, userID, teamID int64) (db.Registration, error) {
+			t.Fatal("expected no join after the deadline")
+			return db.Registration{}, nil
+		})
+		clock := &MockClock{CurrentTime: now}
+		svc := newService(repo, clock)
+
+		clock.CurrentTime = team.FillBy.Time
+		_, err := svc.JoinTeam(context.Background(), userID, team.InviteCode)
+		if !errors.Is(err, ErrTeamClosed) {
+			t.Errorf("expected ErrTeamClosed, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrTeamClosed once the places are released", func(t *testing.T) {
+		repo := teamRepo(func(ctx context.Context, userID, teamID int64) (db.Registration, error) {
+			return db.Registration{}, repository.ErrNotFound
+		})
+
+		_, err := newService(repo, &MockClock{CurrentTime: now}).JoinTeam(context.Background(), userID, team.InviteCode)
+		if !errors.Is(err, ErrTeamClosed) {
+			t.Errorf("expected ErrTeamClosed, got %v", err)
+		}
+	})
+}
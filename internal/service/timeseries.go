@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+// Granularity is the length of the periods a registration timeseries is
+// counted in.
+type Granularity string
+
+// Timeseries granularities. Weeks start on Monday.
+const (
+	GranularityDay  Granularity = "day"
+	GranularityWeek Granularity = "week"
+)
+
+// TimeseriesMargin extends a timeseries either side of the event's
+// registration window, so the chart shows it opening and closing.
+const TimeseriesMargin = 7 * 24 * time.Hour
+
+// MaxTimeseriesBuckets bounds a timeseries, so a window open for years
+// cannot be counted in days.
+const MaxTimeseriesBuckets = 800
+
+// RegistrationBucket counts the registrations made in one period of a
+// timeseries.
+type RegistrationBucket struct {
+	// Date is the first day of the period, at midnight in the event's
+	// time zone.
+	Date  time.Time
+	Count int64
+	// Cumulative counts the registrations made up to the end of the period.
+	Cumulative int64
+}
+
+func (s *eventService) RegistrationTimeseries(ctx context.Context, event db.Event, granularity Granularity) ([]RegistrationBucket, error) {
+	ctx, span := startSpan(ctx, "EventService.RegistrationTimeseries")
+	defer span.End()
+
+	if granularity != GranularityDay && granularity != GranularityWeek {
+		return nil, fmt.Errorf("%w: granularity must be %q or %q", ErrInvalidInput, GranularityDay, GranularityWeek)
+	}
+
+	races, err := s.raceRepo.ListByEvent(ctx, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list races: %w", err)
+	}
+	from, to := registrationWindow(event, races, s.clock.Now())
+
+	loc := EventLocation(event)
+	first, last := periodStart(from.In(loc), granularity), periodStart(to.In(loc), granularity)
+	if n := bucketCount(first, last, granularity); n > MaxTimeseriesBuckets {
+		return nil, fmt.Errorf("%w: the registration window is too long to count by %s", ErrInvalidInput, granularity)
+	}
+
+	rows, err := s.eventRepo.CountRegistrationsByPeriod(ctx, db.CountEventRegistrationsByPeriodParams{
+		Granularity: string(granularity),
+		WindowFrom:  pgtype.Timestamptz{Time: from, Valid: true},
+		WindowTo:    pgtype.Timestamptz{Time: to, Valid: true},
+		Timezone:    loc.String(),
+		EventID:     event.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count registrations: %w", err)
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Period.Time.Format(time.DateOnly)] = row.Registered
+	}
+
+	// Periods without registrations are filled in, so the chart's points
+	// are evenly spaced
+	var buckets []RegistrationBucket
+	var total int64
+	for day := first; !day.After(last); day = nextPeriod(day, granularity) {
+		count := counts[day.Format(time.DateOnly)]
+		total += count
+		buckets = append(buckets, RegistrationBucket{Date: day, Count: count, Cumulative: total})
+	}
+	return buckets, nil
+}
+
+// registrationWindow returns the span a timeseries of the event's
+// registrations covers: from a margin before its races first open to a
+// margin after they last close, and no later than now. Without open dates
+// it starts when the event was created, and without close dates it runs
+// until now.
+func registrationWindow(event db.Event, races []db.Race, now time.Time) (from, to time.Time) {
+	var opens, closes time.Time
+	for _, race := range races {
+		if o := race.RegistrationOpenDate; o.Valid && (opens.IsZero() || o.Time.Before(opens)) {
+			opens = o.Time
+		}
+		if c := race.RegistrationCloseDate; c.Valid && c.Time.After(closes) {
+			closes = c.Time
+		}
+	}
+
+	from = event.CreatedAt.Time
+	if !opens.IsZero() {
+		from = opens.Add(-TimeseriesMargin)
+	}
+	to = now
+	if !closes.IsZero() && closes.Add(TimeseriesMargin).Before(now) {
+		to = closes.Add(TimeseriesMargin)
+	}
+	if to.Before(from) {
+		to = from
+	}
+	return from, to
+}
+
+// periodStart returns midnight on the first day of the period t falls in,
+// in t's location.
+func periodStart(t time.Time, granularity Granularity) time.Time {
+	year, month, day := t.Date()
+	if granularity == GranularityWeek {
+		// Go's weeks start on Sunday
+		day -= (int(t.Weekday()) + 6) % 7
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// nextPeriod returns the start of the period after the one starting at t.
+// Days are counted on the calendar, so clocks changing do not shift them.
+func nextPeriod(t time.Time, granularity Granularity) time.Time {
+	if granularity == GranularityWeek {
+		return t.AddDate(0, 0, 7)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// bucketCount returns how many periods run from first to last inclusive.
+func bucketCount(first, last time.Time, granularity Granularity) int {
+	days := int(last.Sub(first).Round(24*time.Hour) / (24 * time.Hour))
+	if granularity == GranularityWeek {
+		return days/7 + 1
+	}
+	return days + 1
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+)
+
+func TestEventService_RegistrationTimeseries(t *testing.T) {
+	at := func(year int, month time.Month, day int) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: time.Date(year, month, day, 9, 0, 0, 0, time.UTC), Valid: true}
+	}
+	date := func(year int, month time.Month, day int) pgtype.Date {
+		return pgtype.Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), Valid: true}
+	}
+	event := db.Event{ID: 4, Timezone: "Europe/London", CreatedAt: at(2025, time.December, 1)}
+
+	newService := func(races []db.Race, rows []db.CountEventRegistrationsByPeriodRow, now time.Time) (*eventService, *repositorymocks.EventRepositoryMock) {
+		eventRepo := &repositorymocks.EventRepositoryMock{
+			CountRegistrationsByPeriodFunc: func(ctx context.Context, params db.CountEventRegistrationsByPeriodParams) ([]db.CountEventRegistrationsByPeriodRow, error) {
+				return rows, nil
+			},
+		}
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			ListByEventFunc: func(ctx context.Context, eventID int64) ([]db.Race, error) {
+				return races, nil
+			},
+		}
+		return &eventService{eventRepo: eventRepo, raceRepo: raceRepo, clock: &MockClock{CurrentTime: now}}, eventRepo
+	}
+	format := func(buckets []RegistrationBucket) []string {
+		var out []string
+		for _, b := range buckets {
+			out = append(out, fmt.Sprintf("%s %d %d", b.Date.Format(time.DateOnly), b.Count, b.Cumulative))
+		}
+		return out
+	}
+
+	t.Run("fills days without registrations across a month boundary", func(t *testing.T) {
+		races := []db.Race{
+			{RegistrationOpenDate: at(2026, time.February, 4), RegistrationCloseDate: at(2026, time.March, 1)},
+			{RegistrationOpenDate: at(2026, time.February, 6), RegistrationCloseDate: at(2026, time.February, 20)},
+		}
+		rows := []db.CountEventRegistrationsByPeriodRow{
+			{Period: date(2026, time.January, 30), Registered: 2},
+			{Period: date(2026, time.February, 2), Registered: 3},
+		}
+		// Registration is still open, so the series stops today
+		svc, repo := newService(races, rows, time.Date(2026, time.February, 3, 12, 0, 0, 0, time.UTC))
+
+		buckets, err := svc.RegistrationTimeseries(context.Background(), event, GranularityDay)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{
+			"2026-01-28 0 0",
+			"2026-01-29 0 0",
+			"2026-01-30 2 2",
+			"2026-01-31 0 2",
+			"2026-02-01 0 2",
+			"2026-02-02 3 5",
+			"2026-02-03 0 5",
+		}
+		if got := format(buckets); !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+
+		calls := repo.CountRegistrationsByPeriodCalls()
+		if len(calls) != 1 {
+			t.Fatalf("expected one count, got %d", len(calls))
+		}
+		params := calls[0].Params
+		if params.Granularity != "day" || params.Timezone != "Europe/London" || params.EventID != 4 {
+			t.Errorf("unexpected params %+v", params)
+		}
+		if !params.WindowFrom.Time.Equal(races[0].RegistrationOpenDate.Time.Add(-TimeseriesMargin)) {
+			t.Errorf("expected the window to open a margin before the first race, got %v", params.WindowFrom.Time)
+		}
+	})
+
+	t.Run("counts weeks from Monday", func(t *testing.T) {
+		// Thursday 1 January to Wednesday 14 January, with the margin
+		races := []db.Race{{RegistrationOpenDate: at(2026, time.January, 8), RegistrationCloseDate: at(2026, time.January, 7)}}
+		rows := []db.CountEventRegistrationsByPeriodRow{{Period: date(2026, time.January, 5), Registered: 4}}
+		svc, _ := newService(races, rows, time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC))
+
+		buckets, err := svc.RegistrationTimeseries(context.Background(), event, GranularityWeek)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"2025-12-29 0 0", "2026-01-05 4 4", "2026-01-12 0 4"}
+		if got := format(buckets); !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+		for _, b := range buckets {
+			if b.Date.Weekday() != time.Monday || b.Date.Location().String() != "Europe/London" {
+				t.Errorf("expected weeks to start at midnight on Monday in London, got %v", b.Date)
+			}
+		}
+	})
+
+	t.Run("rejects unknown granularities", func(t *testing.T) {
+		svc, _ := newService(nil, nil, time.Now())
+
+		if _, err := svc.RegistrationTimeseries(context.Background(), event, "month"); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("refuses to count a years-long window by day", func(t *testing.T) {
+		svc, _ := newService(nil, nil, time.Date(2029, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+		if _, err := svc.RegistrationTimeseries(context.Background(), event, GranularityDay); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if _, err := svc.RegistrationTimeseries(context.Background(), event, GranularityWeek); err != nil {
+			t.Errorf("expected weeks to be counted, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/repository"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/token.go -pkg servicemocks . TokenService
+
+// API token scopes, each letting a token read one part of the JSON API.
+const (
+	ScopeReadEvents   = "read:events"
+	ScopeReadEntrants = "read:entrants"
+)
+
+// APITokenScopes lists the scopes a token can be granted, in the order
+// forms offer them.
+var APITokenScopes = []string{ScopeReadEvents, ScopeReadEntrants}
+
+// MaxAPITokenLabelLength bounds the label users give a token.
+const MaxAPITokenLabelLength = 100
+
+// ErrInvalidAPIToken is returned when a presented API token is unknown,
+// expired or revoked, or its user has been deleted or deactivated.
+var ErrInvalidAPIToken = apperr.New(apperr.CodeInvalidAPIToken, http.StatusUnauthorized, "invalid, expired or revoked API token")
+
+// TokenService defines the interface for API token business logic.
+//
+// Tokens let users call the JSON API without a browser session. Only a
+// hash of each token is stored, so the token itself is only ever seen by
+// whoever created it.
+type TokenService interface {
+	// CreateAPIToken creates a token for the user, returning it with the
+	// plaintext to show them once.
+	CreateAPIToken(ctx context.Context, userID int64, params CreateAPITokenParams) (CreatedAPIToken, error)
+	// RevokeAPIToken stops the user's token working. It returns
+	// repository.ErrNotFound if the user has no such unrevoked token.
+	RevokeAPIToken(ctx context.Context, userID, id int64) error
+	// ListAPITokens returns the user's unrevoked tokens, newest first.
+	ListAPITokens(ctx context.Context, userID int64) ([]db.ApiToken, error)
+	// Authenticate returns the token with the given plaintext and its
+	// user, recording that it was used. It returns ErrInvalidAPIToken if
+	// the token cannot be used.
+	Authenticate(ctx context.Context, plaintext string) (db.ApiToken, db.User, error)
+}
+
+// CreateAPITokenParams describes a token to create.
+type CreateAPITokenParams struct {
+	Label  string
+	Scopes []string
+	// ExpiresIn is how long the token works for. Zero means it never
+	// expires.
+	ExpiresIn time.Duration
+}
+
+// CreatedAPIToken is a newly created token.
+type CreatedAPIToken struct {
+	Token db.ApiToken
+	// Plaintext is the token to present. It is not stored, so cannot be
+	// shown again.
+	Plaintext string
+}
+
+type tokenService struct {
+	tokenRepo repository.APITokenRepository
+	userRepo  repository.UserRepository
+	clock     Clock
+}
+
+// NewTokenService creates a new TokenService with the given repositories.
+func NewTokenService(tokenRepo repository.APITokenRepository, userRepo repository.UserRepository) TokenService {
+	return &tokenService{tokenRepo: tokenRepo, userRepo: userRepo, clock: RealClock{}}
+}
+
+func (s *tokenService) CreateAPIToken(ctx context.Context, userID int64, params CreateAPITokenParams) (CreatedAPIToken, error) {
+	if userID <= 0 {
+		return CreatedAPIToken{}, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+
+	label := strings.TrimSpace(params.Label)
+	errs := FieldErrors{}
+	switch {
+	case label == "":
+		errs.Add("label", "label is required")
+	case len(label) > MaxAPITokenLabelLength:
+		errs.Add("label", fmt.Sprintf("label must be at most %d characters", MaxAPITokenLabelLength))
+	}
+	if len(params.Scopes) == 0 {
+		errs.Add("scopes", "choose at least one scope")
+	}
+	for _, scope := range params.Scopes {
+		if !slices.Contains(APITokenScopes, scope) {
+			errs.Add("scopes", fmt.Sprintf("unknown scope %q", scope))
+		}
+	}
+	if params.ExpiresIn < 0 {
+		errs.Add("expires_in", "expiry must be in the future")
+	}
+	if err := errs.Err(); err != nil {
+		return CreatedAPIToken{}, err
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if params.ExpiresIn > 0 {
+		expiresAt = pgtype.Timestamptz{Time: s.clock.Now().Add(params.ExpiresIn), Valid: true}
+	}
+
+	plaintext := rand.Text()
+	token, err := s.tokenRepo.Create(ctx, db.CreateAPITokenParams{
+		UserID:    userID,
+		Label:     label,
+		TokenHash: hashAPIToken(plaintext),
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(params.Scopes))),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return CreatedAPIToken{}, fmt.Errorf("failed to create API token: %w", err)
+	}
+	return CreatedAPIToken{Token: token, Plaintext: plaintext}, nil
+}
+
+func (s *tokenService) RevokeAPIToken(ctx context.Context, userID, id int64) error {
+	return s.tokenRepo.Revoke(ctx, userID, id)
+}
+
+func (s *tokenService) ListAPITokens(ctx context.Context, userID int64) ([]db.ApiToken, error) {
+	return s.tokenRepo.ListForUser(ctx, userID)
+}
+
+func (s *tokenService) Authenticate(ctx context.Context, plaintext string) (db.ApiToken, db.User, error) {
+	if plaintext == "" {
+		return db.ApiToken{}, db.User{}, ErrInvalidAPIToken
+	}
+
+	token, err := s.tokenRepo.GetByHash(ctx, hashAPIToken(plaintext))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return db.ApiToken{}, db.User{}, ErrInvalidAPIToken
+		}
+		return db.ApiToken{}, db.User{}, fmt.Errorf("failed to load API token: %w", err)
+	}
+	if token.RevokedAt.Valid || APITokenExpired(token, s.clock.Now()) {
+		return db.ApiToken{}, db.User{}, ErrInvalidAPIToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return db.ApiToken{}, db.User{}, ErrInvalidAPIToken
+		}
+		return db.ApiToken{}, db.User{}, fmt.Errorf("failed to load API token user: %w", err)
+	}
+	if user.DeletedAt.Valid || user.DeactivatedAt.Valid {
+		return db.ApiToken{}, db.User{}, ErrInvalidAPIToken
+	}
+
+	if err := s.tokenRepo.Touch(ctx, token.ID); err != nil {
+		return db.ApiToken{}, db.User{}, fmt.Errorf("failed to record API token use: %w", err)
+	}
+	return token, user, nil
+}
+
+// APITokenHasScope reports whether the token was granted scope.
+func APITokenHasScope(token db.ApiToken, scope string) bool {
+	return slices.Contains(token.Scopes, scope)
+}
+
+// APITokenExpired reports whether the token has expired by now.
+func APITokenExpired(token db.ApiToken, now time.Time) bool {
+	return token.ExpiresAt.Valid && !now.Before(token.ExpiresAt.Time)
+}
+
+// hashAPIToken returns the hash a token is stored and looked up by.
+// Tokens are long and random, so a fast unsalted hash is enough.
+func hashAPIToken(plaintext string) []byte {
+	sum := sha256.Sum256([]byte(plaintext))
+	return sum[:]
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestTokenService_CreateAPIToken(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("stores a hash of the token it returns", func(t *testing.T) {
+		var stored db.CreateAPITokenParams
+		svc := &tokenService{
+			tokenRepo: &repositorymocks.APITokenRepositoryMock{
+				CreateFunc: func(ctx context.Context, params db.CreateAPITokenParams) (db.ApiToken, error) {
+					stored = params
+					return db.ApiToken{ID: 7, UserID: params.UserID, Label: params.Label, Scopes: params.Scopes}, nil
+				},
+			},
+			clock: &MockClock{CurrentTime: now},
+		}
+
+		created, err := svc.CreateAPIToken(context.Background(), 3, CreateAPITokenParams{
+			Label:     "  Chip timing  ",
+			Scopes:    []string{ScopeReadEvents, ScopeReadEntrants, ScopeReadEvents},
+			ExpiresIn: 30 * 24 * time.Hour,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if created.Plaintext == "" {
+			t.Fatal("expected the plaintext token to be returned")
+		}
+		if !bytes.Equal(stored.TokenHash, hashAPIToken(created.Plaintext)) {
+			t.Error("expected the hash of the returned token to be stored")
+		}
+		if bytes.Contains(stored.TokenHash, []byte(created.Plaintext)) {
+			t.Error("expected the plaintext token not to be stored")
+		}
+		if stored.UserID != 3 || stored.Label != "Chip timing" {
+			t.Errorf("expected a trimmed label for user 3, got %+v", stored)
+		}
+		if !slices.Equal(stored.Scopes, []string{ScopeReadEntrants, ScopeReadEvents}) {
+			t.Errorf("expected each scope once, got %v", stored.Scopes)
+		}
+		if !stored.ExpiresAt.Valid || !stored.ExpiresAt.Time.Equal(now.Add(30*24*time.Hour)) {
+			t.Errorf("expected expiry in 30 days, got %+v", stored.ExpiresAt)
+		}
+		if created.Token.ID != 7 {
+			t.Errorf("expected the stored token, got %+v", created.Token)
+		}
+	})
+
+	t.Run("creates tokens that never expire", func(t *testing.T) {
+		var stored db.CreateAPITokenParams
+		svc := &tokenService{
+			tokenRepo: &repositorymocks.APITokenRepositoryMock{
+				CreateFunc: func(ctx context.Context, params db.CreateAPITokenParams) (db.ApiToken, error) {
+					stored = params
+					return db.ApiToken{}, nil
+				},
+			},
+			clock: &MockClock{CurrentTime: now},
+		}
+
+		if _, err := svc.CreateAPIToken(context.Background(), 3, CreateAPITokenParams{Label: "CI", Scopes: []string{ScopeReadEvents}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stored.ExpiresAt.Valid {
+			t.Errorf("expected no expiry, got %v", stored.ExpiresAt.Time)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		params CreateAPITokenParams
+		field  string
+	}{
+		{"blank label", CreateAPITokenParams{Label: "  ", Scopes: []string{ScopeReadEvents}}, "label"},
+		{"no scopes", CreateAPITokenParams{Label: "CI"}, "scopes"},
+		{"unknown scope", CreateAPITokenParams{Label: "CI", Scopes: []string{"write:events"}}, "scopes"},
+		{"past expiry", CreateAPITokenParams{Label: "CI", Scopes: []string{ScopeReadEvents}, ExpiresIn: -time.Hour}, "expires_in"},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			svc := &tokenService{
+				tokenRepo: &repositorymocks.APITokenRepositoryMock{
+					CreateFunc: func(ctx context.Context, params db.CreateAPITokenParams) (db.ApiToken, error) {
+						t.Error("expected no token to be created")
+						return db.ApiToken{}, nil
+					},
+				},
+				clock: &MockClock{CurrentTime: now},
+			}
+
+			_, err := svc.CreateAPIToken(context.Background(), 3, tt.params)
+			var fieldErrs FieldErrors
+			if !errors.As(err, &fieldErrs) || fieldErrs[tt.field] == "" {
+				t.Errorf("expected an error on %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestTokenService_Authenticate(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	user := db.User{ID: 3, Email: "timer@example.com"}
+
+	newService := func(token db.ApiToken, touched *bool) *tokenService {
+		return &tokenService{
+			tokenRepo: &repositorymocks.APITokenRepositoryMock{
+				GetByHashFunc: func(ctx context.Context, tokenHash []byte) (db.ApiToken, error) {
+					if !bytes.Equal(tokenHash, hashAPIToken("secret")) {
+						return db.ApiToken{}, repository.ErrNotFound
+					}
+					return token, nil
+				},
+				TouchFunc: func(ctx context.Context, id int64) error {
+					*touched = true
+					return nil
+				},
+			},
+			userRepo: &repositorymocks.UserRepositoryMock{
+				GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+					if id == user.ID {
+						return user, nil
+					}
+					return db.User{}, repository.ErrNotFound
+				},
+			},
+			clock: &MockClock{CurrentTime: now},
+		}
+	}
+
+	t.Run("returns the token and its user", func(t *testing.T) {
+		var touched bool
+		svc := newService(db.ApiToken{ID: 7, UserID: 3, Scopes: []string{ScopeReadEvents}}, &touched)
+
+		token, got, err := svc.Authenticate(context.Background(), "secret")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if token.ID != 7 || got.ID != user.ID {
+			t.Errorf("expected token 7 for user 3, got token %d for user %d", token.ID, got.ID)
+		}
+		if !touched {
+			t.Error("expected the token's use to be recorded")
+		}
+	})
+
+	tests := []struct {
+		name      string
+		token     db.ApiToken
+		plaintext string
+	}{
+		{"unknown tokens", db.ApiToken{ID: 7, UserID: 3}, "guess"},
+		{"empty tokens", db.ApiToken{ID: 7, UserID: 3}, ""},
+		{"revoked tokens", db.ApiToken{ID: 7, UserID: 3, RevokedAt: pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true}}, "secret"},
+		{"expired tokens", db.ApiToken{ID: 7, UserID: 3, ExpiresAt: pgtype.Timestamptz{Time: now, Valid: true}}, "secret"},
+		{"tokens of deleted users", db.ApiToken{ID: 7, UserID: 4}, "secret"},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			var touched bool
+			svc := newService(tt.token, &touched)
+
+			_, _, err := svc.Authenticate(context.Background(), tt.plaintext)
+			if !errors.Is(err, ErrInvalidAPIToken) {
+				t.Errorf("expected ErrInvalidAPIToken, got %v", err)
+			}
+			if touched {
+				t.Error("expected an unusable token's use not to be recorded")
+			}
+		})
+	}
+}
+
+func TestAPITokenHasScope(t *testing.T) {
+	token := db.ApiToken{Scopes: []string{ScopeReadEvents}}
+
+	if !APITokenHasScope(token, ScopeReadEvents) {
+		t.Error("expected the token to have read:events")
+	}
+	if APITokenHasScope(token, ScopeReadEntrants) {
+		t.Error("expected the token not to have read:entrants")
+	}
+}
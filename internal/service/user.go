@@ -1,18 +1,130 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
 
 	"firecrest/db"
+	"firecrest/internal/apperr"
 	"firecrest/internal/repository"
 )
 
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/user.go -pkg servicemocks . UserService
+
 // UserService defines the interface for user business logic.
 type UserService interface {
 	GetUser(ctx context.Context, id int64) (db.User, error)
 	CreateUser(ctx context.Context, input CreateUserInput) (db.User, error)
+	// ListUsers returns a page of the users matching the params, newest
+	// first, with how their sign-ins are going. Params that are out of
+	// range give ErrInvalidInput.
+	ListUsers(ctx context.Context, params ListUsersParams) (UserPage, error)
+	// UpdateUserRole sets the target user's site role. Only site admins may
+	// change roles; anyone else gets ErrForbidden. Admins cannot change
+	// their own role, so there is always an admin left, and get
+	// ErrOwnAccount. It returns repository.ErrNotFound if the target does
+	// not exist.
+	UpdateUserRole(ctx context.Context, actorID, targetID int64, role db.UserRole) error
+	// SetUserActive deactivates or reactivates the target user. A
+	// deactivated user cannot sign in and their sessions and API tokens
+	// stop working. The same rules as UpdateUserRole apply: only site
+	// admins may do it, and not to themselves.
+	SetUserActive(ctx context.Context, actorID, targetID int64, active bool) error
+	// UpdateProfile sets the user's name, date of birth and the unit they
+	// see distances in, returning the updated user. Problems with the input are reported as FieldErrors.
+	UpdateProfile(ctx context.Context, userID int64, input ProfileInput) (db.User, error)
+	// StartImpersonation lets the actor act as the target user, recording
+	// it in the audit log, and returns the target. CanImpersonate says who
+	// may impersonate whom. It returns repository.ErrNotFound if the target
+	// does not exist.
+	StartImpersonation(ctx context.Context, actorID, targetID int64) (db.User, error)
+	// StopImpersonation records in the audit log that the actor stopped
+	// acting as the target user.
+	StopImpersonation(ctx context.Context, actorID, targetID int64) error
+	// RecordImpersonatedRequest records in the audit log a request the
+	// actor made as the target user that would change something, whether
+	// or not it was let through.
+	RecordImpersonatedRequest(ctx context.Context, actorID, targetID int64, req ImpersonatedRequest) error
+}
+
+// Pagination limits for the admin users page.
+const (
+	DefaultUsersPerPage = 50
+	MaxUsersPerPage     = 200
+)
+
+// ListUsersParams picks a page of users. Query, if not empty, keeps users
+// whose email or name contains it, ignoring case; Role, if set, keeps users
+// with that role.
+type ListUsersParams struct {
+	Query   string
+	Role    db.UserRole
+	Page    int
+	PerPage int
+}
+
+// Validate checks if the filters and pagination options are valid.
+func (p ListUsersParams) Validate() error {
+	switch p.Role {
+	case "", db.UserRoleEntrant, db.UserRoleOrganizer, db.UserRoleAdmin:
+	default:
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidInput, p.Role)
+	}
+	if p.Page < 1 {
+		return fmt.Errorf("%w: page must be 1 or greater", ErrInvalidInput)
+	}
+	if p.PerPage < 1 || p.PerPage > MaxUsersPerPage {
+		return fmt.Errorf("%w: per_page must be between 1 and %d", ErrInvalidInput, MaxUsersPerPage)
+	}
+	return nil
+}
+
+// UserPage is a single page of users along with the total number matching.
+type UserPage struct {
+	Users   []db.ListUsersRow
+	Page    int
+	PerPage int
+	Total   int64
+}
+
+// ErrOwnAccount is returned when an admin tries to change their own role or
+// deactivate themselves.
+var ErrOwnAccount = apperr.New(apperr.CodeOwnAccount, http.StatusForbidden, "admins cannot change their own role or deactivate themselves")
+
+// ErrCannotImpersonate is returned when asked to impersonate another admin
+// or an account that has been deleted or deactivated.
+var ErrCannotImpersonate = apperr.New(apperr.CodeCannotImpersonate, http.StatusForbidden, "this account cannot be impersonated")
+
+// ImpersonatedRequest is a request that would change something, made by an
+// admin acting as another user.
+type ImpersonatedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Blocked is set when the request was refused as too sensitive to make
+	// on someone else's behalf
+	Blocked bool `json:"blocked"`
+}
+
+// CanImpersonate returns nil if the actor may act as the target. Only site
+// admins may, and not as themselves or another admin, or as an account
+// that has been deleted or deactivated. It returns ErrForbidden,
+// ErrOwnAccount or ErrCannotImpersonate otherwise.
+func CanImpersonate(actor, target db.User) error {
+	switch {
+	case actor.Role != db.UserRoleAdmin:
+		return ErrForbidden
+	case actor.ID == target.ID:
+		return ErrOwnAccount
+	case target.Role == db.UserRoleAdmin, target.DeletedAt.Valid, target.DeactivatedAt.Valid:
+		return ErrCannotImpersonate
+	}
+	return nil
 }
 
 // CreateUserInput represents the input for creating a user.
@@ -25,8 +137,8 @@ type CreateUserInput struct {
 
 // Validate checks if the input is valid.
 func (i CreateUserInput) Validate() error {
-	if i.Email == "" {
-		return fmt.Errorf("%w: email is required", ErrInvalidInput)
+	if err := ValidateEmail(NormalizeEmail(i.Email)); err != nil {
+		return err
 	}
 	if i.FirstName == "" {
 		return fmt.Errorf("%w: first_name is required", ErrInvalidInput)
@@ -37,13 +149,48 @@ func (i CreateUserInput) Validate() error {
 	return nil
 }
 
+// ProfileInput is the part of a user's account they edit themselves.
+type ProfileInput struct {
+	FirstName string
+	LastName  string
+	// DateOfBirth is read as a date; zero leaves it unknown.
+	DateOfBirth time.Time
+	// DistanceUnit is the unit the user sees race distances in. Empty is
+	// the default, miles.
+	DistanceUnit db.DistanceUnit
+}
+
+// Validate checks the input as given on now, reporting every problem as
+// FieldErrors.
+func (i ProfileInput) Validate(now time.Time) error {
+	errs := FieldErrors{}
+	if strings.TrimSpace(i.FirstName) == "" {
+		errs.Add("first_name", "first name is required")
+	}
+	if strings.TrimSpace(i.LastName) == "" {
+		errs.Add("last_name", "last name is required")
+	}
+	if !i.DateOfBirth.IsZero() {
+		if msg := dateOfBirthProblem(i.DateOfBirth, now); msg != "" {
+			errs.Add("date_of_birth", msg)
+		}
+	}
+	switch i.DistanceUnit {
+	case "", db.DistanceUnitMiles, db.DistanceUnitKm:
+	default:
+		errs.Add("distance_unit", "distance unit must be miles or km")
+	}
+	return errs.Err()
+}
+
 type userService struct {
 	userRepo repository.UserRepository
+	clock    Clock
 }
 
 // NewUserService creates a new UserService with the given repository.
 func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+	return &userService{userRepo: userRepo, clock: RealClock{}}
 }
 
 func (s *userService) GetUser(ctx context.Context, id int64) (db.User, error) {
@@ -65,9 +212,142 @@ func (s *userService) CreateUser(ctx context.Context, input CreateUserInput) (db
 	}
 
 	return s.userRepo.Create(ctx, db.CreateUserParams{
-		Email:     input.Email,
+		Email:     NormalizeEmail(input.Email),
 		FirstName: input.FirstName,
 		LastName:  input.LastName,
 		Role:      role,
 	})
 }
+
+func (s *userService) ListUsers(ctx context.Context, params ListUsersParams) (UserPage, error) {
+	if err := params.Validate(); err != nil {
+		return UserPage{}, err
+	}
+	offset := (params.Page - 1) * params.PerPage
+	if offset > math.MaxInt32 {
+		return UserPage{}, fmt.Errorf("%w: page is too large", ErrInvalidInput)
+	}
+
+	filter := repository.UserFilter{Query: strings.TrimSpace(params.Query), Role: params.Role}
+	users, err := s.userRepo.ListUsers(ctx, filter, int32(params.PerPage), int32(offset))
+	if err != nil {
+		return UserPage{}, err
+	}
+	total, err := s.userRepo.CountUsers(ctx, filter)
+	if err != nil {
+		return UserPage{}, err
+	}
+
+	return UserPage{
+		Users:   users,
+		Page:    params.Page,
+		PerPage: params.PerPage,
+		Total:   total,
+	}, nil
+}
+
+func (s *userService) UpdateUserRole(ctx context.Context, actorID, targetID int64, role db.UserRole) error {
+	switch role {
+	case db.UserRoleEntrant, db.UserRoleOrganizer, db.UserRoleAdmin:
+	default:
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidInput, role)
+	}
+	if err := s.checkCanManage(ctx, actorID, targetID); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, targetID, role, actorID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) SetUserActive(ctx context.Context, actorID, targetID int64, active bool) error {
+	if err := s.checkCanManage(ctx, actorID, targetID); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SetActive(ctx, targetID, active, actorID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to set user active: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, userID int64, input ProfileInput) (db.User, error) {
+	if err := input.Validate(s.clock.Now()); err != nil {
+		return db.User{}, err
+	}
+
+	user, err := s.userRepo.UpdateProfile(ctx, db.UpdateUserProfileParams{
+		ID:           userID,
+		FirstName:    strings.TrimSpace(input.FirstName),
+		LastName:     strings.TrimSpace(input.LastName),
+		DateOfBirth:  dateOfBirth(input.DateOfBirth),
+		DistanceUnit: cmp.Or(input.DistanceUnit, db.DistanceUnitMiles),
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return db.User{}, err
+		}
+		return db.User{}, fmt.Errorf("failed to update profile: %w", err)
+	}
+	return user, nil
+}
+
+func (s *userService) StartImpersonation(ctx context.Context, actorID, targetID int64) (db.User, error) {
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return db.User{}, fmt.Errorf("failed to get admin: %w", err)
+	}
+	target, err := s.userRepo.GetByID(ctx, targetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return db.User{}, err
+		}
+		return db.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := CanImpersonate(actor, target); err != nil {
+		return db.User{}, err
+	}
+
+	if err := s.userRepo.AuditImpersonation(ctx, targetID, actorID, db.AuditActionImpersonationStarted, nil); err != nil {
+		return db.User{}, fmt.Errorf("failed to audit impersonation: %w", err)
+	}
+	return target, nil
+}
+
+func (s *userService) StopImpersonation(ctx context.Context, actorID, targetID int64) error {
+	if err := s.userRepo.AuditImpersonation(ctx, targetID, actorID, db.AuditActionImpersonationStopped, nil); err != nil {
+		return fmt.Errorf("failed to audit impersonation: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) RecordImpersonatedRequest(ctx context.Context, actorID, targetID int64, req ImpersonatedRequest) error {
+	if err := s.userRepo.AuditImpersonation(ctx, targetID, actorID, db.AuditActionImpersonatedRequest, req); err != nil {
+		return fmt.Errorf("failed to audit impersonated request: %w", err)
+	}
+	return nil
+}
+
+// checkCanManage returns ErrForbidden unless the actor is a site admin, and
+// ErrOwnAccount if they are trying to manage themselves.
+func (s *userService) checkCanManage(ctx context.Context, actorID, targetID int64) error {
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return fmt.Errorf("failed to get admin: %w", err)
+	}
+	if actor.Role != db.UserRoleAdmin {
+		return ErrForbidden
+	}
+	if actorID == targetID {
+		return ErrOwnAccount
+	}
+	return nil
+}
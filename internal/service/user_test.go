@@ -5,36 +5,19 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/jackc/pgx/v5/pgtype"
+
 	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
 	"firecrest/internal/repository"
 )
 
-// mockUserRepository implements repository.UserRepository for testing.
-type mockUserRepository struct {
-	getByIDFunc func(ctx context.Context, id int64) (db.User, error)
-	createFunc  func(ctx context.Context, params db.CreateUserParams) (db.User, error)
-}
-
-func (m *mockUserRepository) GetByID(ctx context.Context, id int64) (db.User, error) {
-	if m.getByIDFunc != nil {
-		return m.getByIDFunc(ctx, id)
-	}
-	return db.User{}, nil
-}
-
-func (m *mockUserRepository) Create(ctx context.Context, params db.CreateUserParams) (db.User, error) {
-	if m.createFunc != nil {
-		return m.createFunc(ctx, params)
-	}
-	return db.User{}, nil
-}
-
 func TestUserService_GetUser(t *testing.T) {
 	t.Run("returns user for valid id", func(t *testing.T) {
 		expected := db.User{ID: 1, Email: "test@example.com", FirstName: "Test", LastName: "User"}
 
-		repo := &mockUserRepository{
-			getByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+		repo := &repositorymocks.UserRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
 				if id == 1 {
 					return expected, nil
 				}
@@ -54,7 +37,7 @@ func TestUserService_GetUser(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for invalid id", func(t *testing.T) {
-		repo := &mockUserRepository{}
+		repo := &repositorymocks.UserRepositoryMock{}
 		svc := NewUserService(repo)
 
 		_, err := svc.GetUser(context.Background(), 0)
@@ -65,7 +48,7 @@ func TestUserService_GetUser(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for negative id", func(t *testing.T) {
-		repo := &mockUserRepository{}
+		repo := &repositorymocks.UserRepositoryMock{}
 		svc := NewUserService(repo)
 
 		_, err := svc.GetUser(context.Background(), -1)
@@ -76,8 +59,8 @@ func TestUserService_GetUser(t *testing.T) {
 	})
 
 	t.Run("returns ErrNotFound for non-existent user", func(t *testing.T) {
-		repo := &mockUserRepository{
-			getByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+		repo := &repositorymocks.UserRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
 				return db.User{}, repository.ErrNotFound
 			},
 		}
@@ -95,8 +78,8 @@ func TestUserService_CreateUser(t *testing.T) {
 	t.Run("creates user with valid input", func(t *testing.T) {
 		expected := db.User{ID: 1, Email: "test@example.com", FirstName: "Test", LastName: "User", Role: "entrant"}
 
-		repo := &mockUserRepository{
-			createFunc: func(ctx context.Context, params db.CreateUserParams) (db.User, error) {
+		repo := &repositorymocks.UserRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateUserParams) (db.User, error) {
 				return expected, nil
 			},
 		}
@@ -118,7 +101,7 @@ func TestUserService_CreateUser(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for missing email", func(t *testing.T) {
-		repo := &mockUserRepository{}
+		repo := &repositorymocks.UserRepositoryMock{}
 		svc := NewUserService(repo)
 
 		_, err := svc.CreateUser(context.Background(), CreateUserInput{
@@ -134,7 +117,7 @@ func TestUserService_CreateUser(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for missing first_name", func(t *testing.T) {
-		repo := &mockUserRepository{}
+		repo := &repositorymocks.UserRepositoryMock{}
 		svc := NewUserService(repo)
 
 		_, err := svc.CreateUser(context.Background(), CreateUserInput{
@@ -150,7 +133,7 @@ func TestUserService_CreateUser(t *testing.T) {
 	})
 
 	t.Run("returns ErrInvalidInput for missing last_name", func(t *testing.T) {
-		repo := &mockUserRepository{}
+		repo := &repositorymocks.UserRepositoryMock{}
 		svc := NewUserService(repo)
 
 		_, err := svc.CreateUser(context.Background(), CreateUserInput{
@@ -166,7 +149,7 @@ func TestUserService_CreateUser(t *testing.T) {
 	})
 
 	t.Run("returns error for missing role", func(t *testing.T) {
-		repo := &mockUserRepository{}
+		repo := &repositorymocks.UserRepositoryMock{}
 		svc := NewUserService(repo)
 
 		_, err := svc.CreateUser(context.Background(), CreateUserInput{
@@ -182,8 +165,8 @@ func TestUserService_CreateUser(t *testing.T) {
 	})
 
 	t.Run("propagates repository errors", func(t *testing.T) {
-		repo := &mockUserRepository{
-			createFunc: func(ctx context.Context, params db.CreateUserParams) (db.User, error) {
+		repo := &repositorymocks.UserRepositoryMock{
+			CreateFunc: func(ctx context.Context, params db.CreateUserParams) (db.User, error) {
 				return db.User{}, errors.New("database error")
 			},
 		}
@@ -201,3 +184,249 @@ func TestUserService_CreateUser(t *testing.T) {
 		}
 	})
 }
+
+func TestUserService_ListUsers(t *testing.T) {
+	t.Run("filters by role and search together", func(t *testing.T) {
+		repo := &repositorymocks.UserRepositoryMock{
+			ListUsersFunc: func(ctx context.Context, filter repository.UserFilter, limit, offset int32) ([]db.ListUsersRow, error) {
+				return []db.ListUsersRow{{ID: 1, Email: "jane@example.com", Role: db.UserRoleOrganizer}}, nil
+			},
+			CountUsersFunc: func(ctx context.Context, filter repository.UserFilter) (int64, error) {
+				return 21, nil
+			},
+		}
+		svc := NewUserService(repo)
+
+		page, err := svc.ListUsers(context.Background(), ListUsersParams{Query: "  jane  ", Role: db.UserRoleOrganizer, Page: 2, PerPage: 20})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Users) != 1 || page.Total != 21 || page.Page != 2 || page.PerPage != 20 {
+			t.Errorf("expected page 2 of 21 users, got %+v", page)
+		}
+		want := repository.UserFilter{Query: "jane", Role: db.UserRoleOrganizer}
+		calls := repo.ListUsersCalls()
+		if len(calls) != 1 || calls[0].Filter != want || calls[0].Limit != 20 || calls[0].Offset != 20 {
+			t.Errorf("expected %+v with limit 20 offset 20, got %+v", want, calls)
+		}
+		if counts := repo.CountUsersCalls(); len(counts) != 1 || counts[0].Filter != want {
+			t.Errorf("expected the same filter counted, got %+v", counts)
+		}
+	})
+
+	t.Run("returns an empty page when nothing matches", func(t *testing.T) {
+		svc := NewUserService(&repositorymocks.UserRepositoryMock{})
+
+		page, err := svc.ListUsers(context.Background(), ListUsersParams{Query: "nobody", Page: 1, PerPage: DefaultUsersPerPage})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Users) != 0 || page.Total != 0 {
+			t.Errorf("expected no users, got %+v", page)
+		}
+	})
+
+	t.Run("rejects invalid params", func(t *testing.T) {
+		for _, params := range []ListUsersParams{
+			{Page: 0, PerPage: 20},
+			{Page: 1, PerPage: 0},
+			{Page: 1, PerPage: MaxUsersPerPage + 1},
+			{Page: 1, PerPage: 20, Role: "superuser"},
+		} {
+			repo := &repositorymocks.UserRepositoryMock{}
+			svc := NewUserService(repo)
+
+			if _, err := svc.ListUsers(context.Background(), params); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput for %+v, got %v", params, err)
+			}
+			if len(repo.ListUsersCalls()) != 0 {
+				t.Errorf("expected no query for %+v", params)
+			}
+		}
+	})
+}
+
+func TestUserService_ManageUsers(t *testing.T) {
+	users := map[int64]db.User{
+		1: {ID: 1, Email: "admin@example.com", Role: db.UserRoleAdmin},
+		2: {ID: 2, Email: "organiser@example.com", Role: db.UserRoleOrganizer},
+		3: {ID: 3, Email: "jane@example.com", Role: db.UserRoleEntrant},
+	}
+	newRepo := func() *repositorymocks.UserRepositoryMock {
+		return &repositorymocks.UserRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+				user, ok := users[id]
+				if !ok {
+					return db.User{}, repository.ErrNotFound
+				}
+				return user, nil
+			},
+		}
+	}
+
+	t.Run("lets an admin change another user's role", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewUserService(repo)
+
+		if err := svc.UpdateUserRole(context.Background(), 1, 3, db.UserRoleOrganizer); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		calls := repo.UpdateRoleCalls()
+		if len(calls) != 1 || calls[0].ID != 3 || calls[0].Role != db.UserRoleOrganizer || calls[0].ActorID != 1 {
+			t.Errorf("unexpected role updates %+v", calls)
+		}
+	})
+
+	t.Run("stops an admin demoting or deactivating themselves", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewUserService(repo)
+
+		if err := svc.UpdateUserRole(context.Background(), 1, 1, db.UserRoleEntrant); !errors.Is(err, ErrOwnAccount) {
+			t.Errorf("expected ErrOwnAccount demoting, got %v", err)
+		}
+		if err := svc.SetUserActive(context.Background(), 1, 1, false); !errors.Is(err, ErrOwnAccount) {
+			t.Errorf("expected ErrOwnAccount deactivating, got %v", err)
+		}
+		if len(repo.UpdateRoleCalls()) != 0 || len(repo.SetActiveCalls()) != 0 {
+			t.Error("expected the admin's account to be left alone")
+		}
+	})
+
+	t.Run("forbids anyone but an admin", func(t *testing.T) {
+		for _, actorID := range []int64{2, 3} {
+			repo := newRepo()
+			svc := NewUserService(repo)
+
+			if err := svc.UpdateUserRole(context.Background(), actorID, 3, db.UserRoleAdmin); !errors.Is(err, ErrForbidden) {
+				t.Errorf("expected ErrForbidden changing roles for user %d, got %v", actorID, err)
+			}
+			if err := svc.SetUserActive(context.Background(), actorID, 1, false); !errors.Is(err, ErrForbidden) {
+				t.Errorf("expected ErrForbidden deactivating for user %d, got %v", actorID, err)
+			}
+			if len(repo.UpdateRoleCalls()) != 0 || len(repo.SetActiveCalls()) != 0 {
+				t.Errorf("expected user %d to change nothing", actorID)
+			}
+		}
+	})
+
+	t.Run("rejects unknown roles", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewUserService(repo)
+
+		if err := svc.UpdateUserRole(context.Background(), 1, 3, "superuser"); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if len(repo.UpdateRoleCalls()) != 0 {
+			t.Error("expected no role update")
+		}
+	})
+
+	t.Run("deactivates and reactivates another user", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewUserService(repo)
+
+		for _, active := range []bool{false, true} {
+			if err := svc.SetUserActive(context.Background(), 1, 3, active); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		calls := repo.SetActiveCalls()
+		if len(calls) != 2 || calls[0].Active || !calls[1].Active || calls[0].ActorID != 1 || calls[1].ID != 3 {
+			t.Errorf("unexpected changes %+v", calls)
+		}
+	})
+
+	t.Run("reports missing users", func(t *testing.T) {
+		repo := newRepo()
+		repo.SetActiveFunc = func(ctx context.Context, id int64, active bool, actorID int64) error {
+			return repository.ErrNotFound
+		}
+		svc := NewUserService(repo)
+
+		if err := svc.SetUserActive(context.Background(), 1, 99, false); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestUserService_Impersonation(t *testing.T) {
+	users := map[int64]db.User{
+		1: {ID: 1, Email: "admin@example.com", Role: db.UserRoleAdmin},
+		2: {ID: 2, Email: "organiser@example.com", Role: db.UserRoleOrganizer},
+		3: {ID: 3, Email: "jane@example.com", Role: db.UserRoleEntrant},
+		4: {ID: 4, Email: "grace@example.com", Role: db.UserRoleAdmin},
+		5: {ID: 5, Email: "sam@example.com", Role: db.UserRoleEntrant, DeactivatedAt: pgtype.Timestamptz{Valid: true}},
+		6: {ID: 6, Email: "deleted@example.com", Role: db.UserRoleEntrant, DeletedAt: pgtype.Timestamptz{Valid: true}},
+	}
+	newRepo := func() *repositorymocks.UserRepositoryMock {
+		return &repositorymocks.UserRepositoryMock{
+			GetByIDFunc: func(ctx context.Context, id int64) (db.User, error) {
+				user, ok := users[id]
+				if !ok {
+					return db.User{}, repository.ErrNotFound
+				}
+				return user, nil
+			},
+		}
+	}
+
+	t.Run("lets an admin act as an ordinary user, audited", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewUserService(repo)
+
+		target, err := svc.StartImpersonation(context.Background(), 1, 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if target.ID != 3 {
+			t.Errorf("expected user 3, got %d", target.ID)
+		}
+		calls := repo.AuditImpersonationCalls()
+		if len(calls) != 1 || calls[0].ID != 3 || calls[0].ActorID != 1 || calls[0].Action != db.AuditActionImpersonationStarted {
+			t.Errorf("unexpected audit %+v", calls)
+		}
+	})
+
+	t.Run("refuses who may not be impersonated, or by whom", func(t *testing.T) {
+		tests := []struct {
+			name              string
+			actorID, targetID int64
+			want              error
+		}{
+			{name: "an organiser", actorID: 2, targetID: 3, want: ErrForbidden},
+			{name: "the admin themselves", actorID: 1, targetID: 1, want: ErrOwnAccount},
+			{name: "another admin", actorID: 1, targetID: 4, want: ErrCannotImpersonate},
+			{name: "a deactivated account", actorID: 1, targetID: 5, want: ErrCannotImpersonate},
+			{name: "a deleted account", actorID: 1, targetID: 6, want: ErrCannotImpersonate},
+			{name: "a missing account", actorID: 1, targetID: 99, want: repository.ErrNotFound},
+		}
+		for _, tt := range tests {
+			repo := newRepo()
+			svc := NewUserService(repo)
+
+			if _, err := svc.StartImpersonation(context.Background(), tt.actorID, tt.targetID); !errors.Is(err, tt.want) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+			}
+			if len(repo.AuditImpersonationCalls()) != 0 {
+				t.Errorf("%s: expected nothing audited", tt.name)
+			}
+		}
+	})
+
+	t.Run("audits stopping and requests made as the user", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewUserService(repo)
+		req := ImpersonatedRequest{Method: "POST", Path: "/account/delete", Blocked: true}
+
+		if err := svc.RecordImpersonatedRequest(context.Background(), 1, 3, req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := svc.StopImpersonation(context.Background(), 1, 3); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		calls := repo.AuditImpersonationCalls()
+		if len(calls) != 2 || calls[0].Action != db.AuditActionImpersonatedRequest || calls[0].Detail != req || calls[1].Action != db.AuditActionImpersonationStopped {
+			t.Errorf("unexpected audits %+v", calls)
+		}
+	})
+}
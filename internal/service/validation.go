@@ -0,0 +1,61 @@
+package service
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"firecrest/internal/apperr"
+)
+
+// errInvalidFields describes FieldErrors to clients. Unlike ErrInvalidInput
+// the request was understood, so it is 422 rather than 400.
+var errInvalidFields = apperr.New(apperr.CodeInvalidInput, http.StatusUnprocessableEntity, "the request has invalid fields")
+
+// FieldErrors reports invalid input field by field, keyed by the name of the
+// form field it came from. Messages are written like other validation
+// errors, such as "name is required". It matches ErrInvalidInput, so
+// callers that only need to know the input was rejected can keep using
+// errors.Is, while forms use errors.As to show each message in place.
+type FieldErrors map[string]string
+
+// Error lists the messages in field order.
+func (e FieldErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, field := range slices.Sorted(maps.Keys(e)) {
+		msgs = append(msgs, e[field])
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+// Unwrap makes FieldErrors match ErrInvalidInput.
+func (e FieldErrors) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// As finds errInvalidFields ahead of the ErrInvalidInput e unwraps to, so
+// apperr reports FieldErrors as 422.
+func (e FieldErrors) As(target any) bool {
+	if t, ok := target.(**apperr.Error); ok {
+		*t = errInvalidFields
+		return true
+	}
+	return false
+}
+
+// Add records msg against field, keeping any message already there so the
+// first problem found is the one reported.
+func (e FieldErrors) Add(field, msg string) {
+	if _, ok := e[field]; !ok {
+		e[field] = msg
+	}
+}
+
+// Err returns e as an error, or nil if no field has a message.
+func (e FieldErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"firecrest/internal/apperr"
+)
+
+func TestFieldErrors(t *testing.T) {
+	t.Run("matches ErrInvalidInput", func(t *testing.T) {
+		err := fmt.Errorf("creating event: %w", FieldErrors{"name": "name is required"})
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected %v to match ErrInvalidInput", err)
+		}
+		var fields FieldErrors
+		if !errors.As(err, &fields) || fields["name"] != "name is required" {
+			t.Errorf("expected the field errors to be recoverable, got %v", fields)
+		}
+	})
+
+	t.Run("is invalid input the request was understood", func(t *testing.T) {
+		err := fmt.Errorf("creating event: %w", FieldErrors{"name": "name is required"})
+		if apperr.CodeOf(err) != apperr.CodeInvalidInput || apperr.StatusOf(err) != http.StatusUnprocessableEntity {
+			t.Errorf("expected INVALID_INPUT and 422, got %s and %d", apperr.CodeOf(err), apperr.StatusOf(err))
+		}
+		if plain := fmt.Errorf("%w: invalid slug", ErrInvalidInput); apperr.StatusOf(plain) != http.StatusBadRequest {
+			t.Errorf("expected other invalid input to stay 400, got %d", apperr.StatusOf(plain))
+		}
+	})
+
+	t.Run("lists messages in field order", func(t *testing.T) {
+		err := FieldErrors{"year": "year is required", "name": "name is required"}
+		if got, want := err.Error(), "invalid input: name is required; year is required"; got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("keeps the first message for a field", func(t *testing.T) {
+		errs := FieldErrors{}
+		errs.Add("slug", "slug is required")
+		errs.Add("slug", "slug is taken")
+		if errs["slug"] != "slug is required" {
+			t.Errorf("expected the first message to be kept, got %q", errs["slug"])
+		}
+	})
+
+	t.Run("is nil when empty", func(t *testing.T) {
+		if err := (FieldErrors{}).Err(); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+}
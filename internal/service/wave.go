@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"firecrest/db"
+	"firecrest/internal/apperr"
+	"firecrest/internal/mail"
+	"firecrest/internal/repository"
+)
+
+// MaxWaveNameLength is the longest start wave name accepted, in characters.
+const MaxWaveNameLength = 50
+
+// ErrWaveInUse is returned when deleting a start wave entrants still hold
+// places in.
+var ErrWaveInUse = apperr.New(apperr.CodeWaveInUse, http.StatusConflict, "entrants hold places in this wave")
+
+// WaveInput is a start wave as an organiser enters it.
+type WaveInput struct {
+	Name string
+	// StartsAt is the organiser's local time in the event's time zone;
+	// only its date and clock are read.
+	StartsAt time.Time
+	Capacity int
+}
+
+// Validate checks if the input is valid, reporting every problem as
+// FieldErrors.
+func (i WaveInput) Validate() error {
+	errs := FieldErrors{}
+	switch name := strings.TrimSpace(i.Name); {
+	case name == "":
+		errs.Add("name", "name is required")
+	case utf8.RuneCountInString(name) > MaxWaveNameLength:
+		errs.Add("name", fmt.Sprintf("name must be at most %d characters", MaxWaveNameLength))
+	}
+	if i.StartsAt.IsZero() {
+		errs.Add("starts_at", "start time is required")
+	}
+	if i.Capacity < 1 {
+		errs.Add("capacity", "capacity must be at least 1")
+	}
+	return errs.Err()
+}
+
+func (s *registrationService) ListWaves(ctx context.Context, raceID int64) ([]db.ListRaceWavesRow, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.ListWaves")
+	defer span.End()
+
+	return s.raceRepo.ListWaves(ctx, raceID)
+}
+
+func (s *registrationService) CreateWave(ctx context.Context, event db.Event, raceID int64, input WaveInput) (db.RaceWave, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.CreateWave")
+	defer span.End()
+
+	if err := input.Validate(); err != nil {
+		return db.RaceWave{}, err
+	}
+	name := strings.TrimSpace(input.Name)
+	wave, err := s.raceRepo.CreateWave(ctx, db.CreateRaceWaveParams{
+		RaceID:   raceID,
+		Name:     name,
+		StartsAt: localTimestamptz(input.StartsAt, EventLocation(event)),
+		Capacity: int32(input.Capacity),
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return db.RaceWave{}, FieldErrors{"name": fmt.Sprintf("the race already has a wave called %s", name)}
+		}
+		return db.RaceWave{}, err
+	}
+	return wave, nil
+}
+
+func (s *registrationService) UpdateWave(ctx context.Context, event db.Event, race db.Race, waveID int64, input WaveInput) (db.RaceWave, error) {
+	ctx, span := startSpan(ctx, "RegistrationService.UpdateWave")
+	defer span.End()
+
+	if err := input.Validate(); err != nil {
+		return db.RaceWave{}, err
+	}
+	name := strings.TrimSpace(input.Name)
+	change, err := s.raceRepo.UpdateWave(ctx, db.UpdateRaceWaveParams{
+		ID:       waveID,
+		RaceID:   race.ID,
+		Name:     name,
+		StartsAt: localTimestamptz(input.StartsAt, EventLocation(event)),
+		Capacity: int32(input.Capacity),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrConflict):
+			return db.RaceWave{}, FieldErrors{"name": fmt.Sprintf("the race already has a wave called %s", name)}
+		case errors.Is(err, repository.ErrCapacityExceeded):
+			return db.RaceWave{}, FieldErrors{"capacity": fmt.Sprintf("capacity cannot be less than the %d places already taken", change.Taken)}
+		}
+		return db.RaceWave{}, err
+	}
+
+	if !change.Wave.StartsAt.Time.Equal(change.Old.StartsAt.Time) {
+		if err := s.sendWaveStartChangedEmails(ctx, event, race, change); err != nil {
+			return change.Wave, err
+		}
+	}
+	return change.Wave, nil
+}
+
+// sendWaveStartChangedEmails queues an email to each of the wave's entrants
+// telling them it has moved.
+func (s *registrationService) sendWaveStartChangedEmails(ctx context.Context, event db.Event, race db.Race, change repository.WaveChange) error {
+	entrants, err := s.registrationRepo.ListByWave(ctx, change.Wave.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list wave entrants: %w", err)
+	}
+
+	var errs []error
+	for _, entrant := range entrants {
+		msg, err := mail.WaveStartChangedMessage(entrant.Email, mail.WaveStartChangedData{
+			FirstName: entrant.FirstName,
+			RaceName:  race.Name,
+			EventName: event.Name,
+			Wave:      change.Wave.Name,
+			OldStart:  formatRaceDate(change.Old.StartsAt),
+			NewStart:  formatRaceDate(change.Wave.StartsAt),
+			EntryURL:  s.entryURL(entrant.ID),
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to build wave email for registration %d: %w", entrant.ID, err))
+			continue
+		}
+		_ = s.mailer.Send(ctx, msg)
+	}
+	return errors.Join(errs...)
+}
+
+func (s *registrationService) DeleteWave(ctx context.Context, raceID, waveID int64) error {
+	ctx, span := startSpan(ctx, "RegistrationService.DeleteWave")
+	defer span.End()
+
+	if err := s.raceRepo.DeleteWave(ctx, raceID, waveID); err != nil {
+		if errors.Is(err, repository.ErrInUse) {
+			return ErrWaveInUse
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+)
+
+func TestWaveInput_Validate(t *testing.T) {
+	valid := WaveInput{Name: "Wave A", StartsAt: time.Date(2026, 6, 6, 9, 0, 0, 0, time.UTC), Capacity: 50}
+
+	tests := []struct {
+		name  string
+		input func(WaveInput) WaveInput
+		field string
+	}{
+		{name: "blank names", input: func(w WaveInput) WaveInput { w.Name = "  "; return w }, field: "name"},
+		{name: "long names", input: func(w WaveInput) WaveInput { w.Name = strings.Repeat("a", MaxWaveNameLength+1); return w }, field: "name"},
+		{name: "missing start times", input: func(w WaveInput) WaveInput { w.StartsAt = time.Time{}; return w }, field: "starts_at"},
+		{name: "empty waves", input: func(w WaveInput) WaveInput { w.Capacity = 0; return w }, field: "capacity"},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			err := tt.input(valid).Validate()
+
+			var fe FieldErrors
+			if !errors.As(err, &fe) || fe[tt.field] == "" {
+				t.Errorf("expected a %s field error, got %v", tt.field, err)
+			}
+		})
+	}
+
+	t.Run("accepts a complete wave", func(t *testing.T) {
+		if err := valid.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestRegistrationService_UpdateWave(t *testing.T) {
+	event := db.Event{ID: 30, Name: "Riverside Run", Timezone: "Europe/London"}
+	race := db.Race{ID: 20, EventID: 30, Name: "10K"}
+	start := pgtype.Timestamptz{Time: time.Date(2026, 6, 6, 8, 0, 0, 0, time.UTC), Valid: true}
+	input := WaveInput{Name: "Wave A", StartsAt: time.Date(2026, 6, 6, 9, 15, 0, 0, time.UTC), Capacity: 50}
+
+	newService := func(raceRepo *repositorymocks.RaceRepositoryMock, mailer *mockMailer) *registrationService {
+		regRepo := &repositorymocks.RegistrationRepositoryMock{
+			ListByWaveFunc: func(ctx context.Context, waveID int64) ([]db.ListWaveEntrantsRow, error) {
+				return []db.ListWaveEntrantsRow{
+					{ID: 100, Email: "sam@example.com", FirstName: "Sam"},
+					{ID: 101, Email: "alex@example.com", FirstName: "Alex"},
+				}, nil
+			},
+		}
+		return NewRegistrationService(regRepo, &repositorymocks.OrganisationRepositoryMock{}, raceRepo, &mockPaymentService{}, &mockDiscountService{}, nil, mailer, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 0, 100, BibRange{}).(*registrationService)
+	}
+	updating := func(got *db.UpdateRaceWaveParams) *repositorymocks.RaceRepositoryMock {
+		return &repositorymocks.RaceRepositoryMock{
+			UpdateWaveFunc: func(ctx context.Context, params db.UpdateRaceWaveParams) (repository.WaveChange, error) {
+				*got = params
+				old := db.RaceWave{ID: params.ID, RaceID: params.RaceID, Name: "Wave A", StartsAt: start, Capacity: 50}
+				wave := old
+				wave.Name, wave.StartsAt, wave.Capacity = params.Name, params.StartsAt, params.Capacity
+				return repository.WaveChange{Wave: wave, Old: old, Taken: 2}, nil
+			},
+		}
+	}
+
+	t.Run("emails the wave's entrants when its start moves", func(t *testing.T) {
+		var got db.UpdateRaceWaveParams
+		mailer := &mockMailer{}
+
+		if _, err := newService(updating(&got), mailer).UpdateWave(context.Background(), event, race, 5, input); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := time.Date(2026, 6, 6, 8, 15, 0, 0, time.UTC); !got.StartsAt.Time.Equal(want) {
+			t.Errorf("expected the start read in the event's time zone, %v, got %v", want, got.StartsAt.Time)
+		}
+		if len(mailer.sent) != 2 {
+			t.Fatalf("expected two emails, got %d", len(mailer.sent))
+		}
+		if msg := mailer.sent[0]; msg.To != "sam@example.com" || !strings.Contains(msg.Text, "at 08:15") {
+			t.Errorf("expected Sam to be told the new start, got %q:\n%s", msg.To, msg.Text)
+		}
+	})
+
+	t.Run("sends nothing when only the name or capacity changes", func(t *testing.T) {
+		var got db.UpdateRaceWaveParams
+		mailer := &mockMailer{}
+		unmoved := input
+		unmoved.StartsAt = time.Date(2026, 6, 6, 9, 0, 0, 0, time.UTC)
+		unmoved.Capacity = 80
+
+		if _, err := newService(updating(&got), mailer).UpdateWave(context.Background(), event, race, 5, unmoved); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mailer.sent) != 0 {
+			t.Errorf("expected no emails, got %d", len(mailer.sent))
+		}
+	})
+
+	t.Run("refuses a capacity below the places taken", func(t *testing.T) {
+		raceRepo := &repositorymocks.RaceRepositoryMock{
+			UpdateWaveFunc: func(ctx context.Context, params db.UpdateRaceWaveParams) (repository.WaveChange, error) {
+				return repository.WaveChange{Taken: 60}, repository.ErrCapacityExceeded
+			},
+		}
+
+		_, err := newService(raceRepo, &mockMailer{}).UpdateWave(context.Background(), event, race, 5, input)
+
+		var fe FieldErrors
+		if !errors.As(err, &fe) || !strings.Contains(fe["capacity"], "60") {
+			t.Errorf("expected a capacity error naming the places taken, got %v", err)
+		}
+	})
+}
+
+func TestRegistrationService_DeleteWave(t *testing.T) {
+	svc := NewRegistrationService(&repositorymocks.RegistrationRepositoryMock{}, &repositorymocks.OrganisationRepositoryMock{}, &repositorymocks.RaceRepositoryMock{
+		DeleteWaveFunc: func(ctx context.Context, raceID, waveID int64) error {
+			return repository.ErrInUse
+		},
+	}, &mockPaymentService{}, &mockDiscountService{}, nil, &mockMailer{}, &repositorymocks.WebhookRepositoryMock{}, newTestSigner(t), newTestBox(t), "https://firecrest.example", 0, 0, 0, 0, 100, BibRange{})
+
+	if err := svc.DeleteWave(context.Background(), 20, 5); !errors.Is(err, ErrWaveInUse) {
+		t.Errorf("expected ErrWaveInUse, got %v", err)
+	}
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/bus"
+	"firecrest/internal/repository"
+	"firecrest/internal/secret"
+	"firecrest/internal/webhook"
+)
+
+//go:generate go tool moq -rm -stub -out ../mocks/servicemocks/webhook.go -pkg servicemocks . WebhookService
+
+const (
+	// WebhookMaxRetries is how many times a failed delivery is retried
+	// before it is given up on.
+	WebhookMaxRetries = 5
+	// WebhookRetryDelay is how long after its first failed attempt a
+	// delivery is retried. The delay doubles after each further failure.
+	WebhookRetryDelay = time.Minute
+	// WebhookBatchSize bounds how many deliveries one run sends.
+	WebhookBatchSize = 10
+	// WebhookLogSize bounds how many of an endpoint's deliveries its log
+	// shows.
+	WebhookLogSize = 50
+	// MaxWebhookURLLength bounds the URLs endpoints are given.
+	MaxWebhookURLLength = 2000
+)
+
+// WebhookLease is how long a run has to send the deliveries it claims
+// before they may be claimed again. It outlasts a batch of requests that
+// all time out.
+const WebhookLease = WebhookBatchSize*webhook.Timeout + time.Minute
+
+// WebhookService defines the interface for webhook business logic.
+//
+// Organisations register endpoints to be told about their registrations.
+// Events are queued as they happen and delivered in the background, so a
+// slow or failing endpoint never holds up entrants.
+type WebhookService interface {
+	// ListEndpoints returns the organisation's endpoints, oldest first.
+	ListEndpoints(ctx context.Context, organisationID int64) ([]db.WebhookEndpoint, error)
+	// CreateEndpoint adds an endpoint to the organisation, returning it
+	// with the secret its requests are signed with, to show once.
+	CreateEndpoint(ctx context.Context, organisationID int64, input WebhookEndpointInput) (CreatedWebhookEndpoint, error)
+	// DeleteEndpoint removes one of the organisation's endpoints. It
+	// returns repository.ErrNotFound if the organisation has no such
+	// endpoint.
+	DeleteEndpoint(ctx context.Context, organisationID, id int64) error
+	// DeliveryLog returns one of the organisation's endpoints with its
+	// latest deliveries. It returns repository.ErrNotFound if the
+	// organisation has no such endpoint.
+	DeliveryLog(ctx context.Context, organisationID, id int64) (WebhookDeliveryLog, error)
+	// DeliverWebhooks sends the deliveries that are due, returning how
+	// many were accepted.
+	DeliverWebhooks(ctx context.Context) (int, error)
+}
+
+// WebhookEndpointInput describes an endpoint to create.
+type WebhookEndpointInput struct {
+	URL    string
+	Events []string
+}
+
+// CreatedWebhookEndpoint is a newly created endpoint.
+type CreatedWebhookEndpoint struct {
+	Endpoint db.WebhookEndpoint
+	// Secret signs the endpoint's requests. It is stored encrypted and
+	// only shown when the endpoint is created.
+	Secret string
+}
+
+// WebhookDeliveryLog is an endpoint with its latest deliveries, newest
+// first, and the attempts at them, oldest first.
+type WebhookDeliveryLog struct {
+	Endpoint   db.WebhookEndpoint
+	Deliveries []db.WebhookDelivery
+	Attempts   []db.WebhookDeliveryAttempt
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	sender      webhook.Sender
+	box         *secret.Box
+	clock       Clock
+}
+
+// NewWebhookService creates a new WebhookService. Deliveries are sent
+// through sender, and endpoints' secrets are encrypted with box.
+func NewWebhookService(webhookRepo repository.WebhookRepository, sender webhook.Sender, box *secret.Box) WebhookService {
+	return &webhookService{webhookRepo: webhookRepo, sender: sender, box: box, clock: RealClock{}}
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context, organisationID int64) ([]db.WebhookEndpoint, error) {
+	return s.webhookRepo.ListEndpoints(ctx, organisationID)
+}
+
+func (s *webhookService) CreateEndpoint(ctx context.Context, organisationID int64, input WebhookEndpointInput) (CreatedWebhookEndpoint, error) {
+	ctx, span := startSpan(ctx, "WebhookService.CreateEndpoint")
+	defer span.End()
+
+	endpointURL := strings.TrimSpace(input.URL)
+	errs := FieldErrors{}
+	if u, err := url.Parse(endpointURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		errs.Add("url", "enter a full http or https URL")
+	} else if len(endpointURL) > MaxWebhookURLLength {
+		errs.Add("url", fmt.Sprintf("URL must be at most %d characters", MaxWebhookURLLength))
+	}
+	if len(input.Events) == 0 {
+		errs.Add("events", "choose at least one event")
+	}
+	for _, event := range input.Events {
+		if !slices.Contains(webhook.Events, event) {
+			errs.Add("events", fmt.Sprintf("unknown event %q", event))
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return CreatedWebhookEndpoint{}, err
+	}
+
+	key := rand.Text()
+	endpoint, err := s.webhookRepo.CreateEndpoint(ctx, db.CreateWebhookEndpointParams{
+		OrganisationID: organisationID,
+		Url:            endpointURL,
+		SecretSealed:   s.box.Seal([]byte(key)),
+		EventTypes:     slices.Compact(slices.Sorted(slices.Values(input.Events))),
+	})
+	if err != nil {
+		return CreatedWebhookEndpoint{}, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return CreatedWebhookEndpoint{Endpoint: endpoint, Secret: key}, nil
+}
+
+func (s *webhookService) DeleteEndpoint(ctx context.Context, organisationID, id int64) error {
+	return s.webhookRepo.DeleteEndpoint(ctx, organisationID, id)
+}
+
+func (s *webhookService) DeliveryLog(ctx context.Context, organisationID, id int64) (WebhookDeliveryLog, error) {
+	ctx, span := startSpan(ctx, "WebhookService.DeliveryLog")
+	defer span.End()
+
+	endpoint, err := s.webhookRepo.GetEndpoint(ctx, organisationID, id)
+	if err != nil {
+		return WebhookDeliveryLog{}, err
+	}
+	deliveries, err := s.webhookRepo.ListDeliveries(ctx, endpoint.ID, WebhookLogSize)
+	if err != nil {
+		return WebhookDeliveryLog{}, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	ids := make([]int64, len(deliveries))
+	for i, d := range deliveries {
+		ids[i] = d.ID
+	}
+	attempts, err := s.webhookRepo.ListAttempts(ctx, ids)
+	if err != nil {
+		return WebhookDeliveryLog{}, fmt.Errorf("failed to list webhook delivery attempts: %w", err)
+	}
+
+	return WebhookDeliveryLog{Endpoint: endpoint, Deliveries: deliveries, Attempts: attempts}, nil
+}
+
+func (s *webhookService) DeliverWebhooks(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "WebhookService.DeliverWebhooks")
+	defer span.End()
+
+	now := s.clock.Now()
+	deliveries, err := s.webhookRepo.ClaimDue(ctx, now, now.Add(WebhookLease), WebhookBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	delivered := 0
+	var errs []error
+	for _, d := range deliveries {
+		attempt := s.attempt(ctx, d)
+		if err := s.webhookRepo.RecordAttempt(ctx, attempt); err != nil {
+			// The delivery is sent again once its lease runs out
+			errs = append(errs, fmt.Errorf("failed to record attempt at webhook delivery %d: %w", d.ID, err))
+			continue
+		}
+		if attempt.Status == db.WebhookDeliveryStatusDelivered {
+			delivered++
+		}
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// attempt sends the delivery once, returning the attempt with the state it
+// leaves the delivery in: delivered, pending a retry, or failed once its
+// retries are used up.
+func (s *webhookService) attempt(ctx context.Context, d db.ClaimWebhookDeliveriesRow) repository.WebhookAttempt {
+	start := s.clock.Now()
+	attempt := repository.WebhookAttempt{
+		DeliveryID:  d.ID,
+		AttemptedAt: start,
+		Attempts:    d.Attempts + 1,
+	}
+
+	key, err := s.box.Open(d.SecretSealed)
+	if err == nil {
+		attempt.StatusCode, err = s.sender.Send(ctx, webhook.Request{
+			URL:        d.Url,
+			Secret:     key,
+			DeliveryID: d.ID,
+			Event:      d.EventType,
+			Body:       d.Payload,
+		})
+	}
+	end := s.clock.Now()
+	attempt.Duration = end.Sub(start)
+	attempt.NextAttemptAt = end
+
+	switch {
+	case err == nil:
+		attempt.Status = db.WebhookDeliveryStatusDelivered
+	case attempt.Attempts > WebhookMaxRetries:
+		attempt.Status = db.WebhookDeliveryStatusFailed
+		attempt.Error = err.Error()
+	default:
+		attempt.Status = db.WebhookDeliveryStatusPending
+		attempt.Error = err.Error()
+		attempt.NextAttemptAt = end.Add(WebhookBackoff(attempt.Attempts))
+	}
+	return attempt
+}
+
+// WebhookBackoff returns how long to wait before retrying a delivery that
+// has failed attempts times: WebhookRetryDelay, doubling with each failure.
+func WebhookBackoff(attempts int32) time.Duration {
+	return WebhookRetryDelay << (attempts - 1)
+}
+
+// webhookRegistration is the data of registration events.
+type webhookRegistration struct {
+	ID        int64          `json:"id"`
+	Status    string         `json:"status"`
+	Bib       string         `json:"bib,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	Race      webhookRef     `json:"race"`
+	Event     webhookRef     `json:"event"`
+	Entrant   webhookEntrant `json:"entrant"`
+	// PreviousEntrant is who a transferred registration came from
+	PreviousEntrant *webhookEntrant `json:"previous_entrant,omitempty"`
+}
+
+type webhookRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type webhookEntrant struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// SubscribeWebhooks queues deliveries to organisers' webhooks for each
+// entry made or cancelled, as it is published on b. They are queued off the
+// publisher's goroutine, so a slow database holds up no entrant, and one
+// that fails to queue is logged.
+func SubscribeWebhooks(b *bus.Bus, registrationRepo repository.RegistrationRepository, webhookRepo repository.WebhookRepository) {
+	q := webhookQueue{registrationRepo: registrationRepo, webhookRepo: webhookRepo, clock: RealClock{}}
+	bus.SubscribeAsync(b, bus.RegistrationCreated, "webhooks", func(ctx context.Context, c bus.RegistrationChange) error {
+		return q.queue(ctx, webhook.EventRegistrationCreated, c.RegistrationID, nil)
+	})
+	bus.SubscribeAsync(b, bus.RegistrationCancelled, "webhooks", func(ctx context.Context, c bus.RegistrationChange) error {
+		return q.queue(ctx, webhook.EventRegistrationCancelled, c.RegistrationID, nil)
+	})
+}
+
+// webhookQueue builds registration events and queues them for delivery.
+type webhookQueue struct {
+	registrationRepo repository.RegistrationRepository
+	webhookRepo      repository.WebhookRepository
+	clock            Clock
+}
+
+// queueWebhook queues the event for the registration's organisers, as
+// webhookQueue.queue does.
+func (s *registrationService) queueWebhook(ctx context.Context, event string, registrationID int64, previous *webhookEntrant) error {
+	q := webhookQueue{registrationRepo: s.registrationRepo, webhookRepo: s.webhookRepo, clock: s.clock}
+	return q.queue(ctx, event, registrationID, previous)
+}
+
+// queue queues the event for the endpoints of the organisation running the
+// registration's race. previous names who a transferred registration came
+// from, and is nil for other events.
+func (q webhookQueue) queue(ctx context.Context, event string, registrationID int64, previous *webhookEntrant) error {
+	reg, err := q.registrationRepo.GetForWebhook(ctx, registrationID)
+	if err != nil {
+		return fmt.Errorf("failed to load registration for webhooks: %w", err)
+	}
+
+	body, err := json.Marshal(webhook.Payload{
+		Event:     event,
+		CreatedAt: q.clock.Now().UTC(),
+		Data: webhookRegistration{
+			ID:        reg.ID,
+			Status:    string(reg.Status),
+			Bib:       reg.Bib.String,
+			CreatedAt: reg.CreatedAt.Time.UTC(),
+			Race:      webhookRef{ID: reg.RaceID, Name: reg.RaceName},
+			Event:     webhookRef{ID: reg.EventID, Name: reg.EventName},
+			Entrant: webhookEntrant{
+				Email:     reg.Email,
+				FirstName: reg.FirstName,
+				LastName:  reg.LastName,
+			},
+			PreviousEntrant: previous,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build webhook payload: %w", err)
+	}
+	if _, err := q.webhookRepo.Enqueue(ctx, reg.OrganisationID, event, body); err != nil {
+		return fmt.Errorf("failed to queue webhooks: %w", err)
+	}
+	return nil
+}
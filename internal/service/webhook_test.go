@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+	"firecrest/internal/bus"
+	"firecrest/internal/mocks/repositorymocks"
+	"firecrest/internal/repository"
+	"firecrest/internal/webhook"
+)
+
+func TestWebhookService_CreateEndpoint(t *testing.T) {
+	t.Run("stores the endpoint with its secret sealed", func(t *testing.T) {
+		var got db.CreateWebhookEndpointParams
+		repo := &repositorymocks.WebhookRepositoryMock{
+			CreateEndpointFunc: func(ctx context.Context, params db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+				got = params
+				return db.WebhookEndpoint{ID: 3, OrganisationID: params.OrganisationID}, nil
+			},
+		}
+		box := newTestBox(t)
+		svc := NewWebhookService(repo, webhook.NewHTTPSender(), box)
+
+		created, err := svc.CreateEndpoint(context.Background(), 7, WebhookEndpointInput{
+			URL:    " https://crm.example/hooks ",
+			Events: []string{webhook.EventRegistrationCancelled, webhook.EventRegistrationCreated, webhook.EventRegistrationCancelled},
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.OrganisationID != 7 || got.Url != "https://crm.example/hooks" {
+			t.Errorf("unexpected endpoint %+v", got)
+		}
+		if len(got.EventTypes) != 2 || got.EventTypes[0] != webhook.EventRegistrationCancelled {
+			t.Errorf("expected the events sorted without repeats, got %v", got.EventTypes)
+		}
+		if created.Secret == "" || created.Endpoint.ID != 3 {
+			t.Fatalf("expected the endpoint and its secret, got %+v", created)
+		}
+		if opened, err := box.Open(got.SecretSealed); err != nil || string(opened) != created.Secret {
+			t.Errorf("expected the secret sealed with the box, got %q, %v", opened, err)
+		}
+	})
+
+	t.Run("rejects invalid endpoints", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input WebhookEndpointInput
+			field string
+		}{
+			{name: "relative URL", input: WebhookEndpointInput{URL: "/hooks", Events: webhook.Events}, field: "url"},
+			{name: "other scheme", input: WebhookEndpointInput{URL: "ftp://crm.example/hooks", Events: webhook.Events}, field: "url"},
+			{name: "no events", input: WebhookEndpointInput{URL: "https://crm.example/hooks"}, field: "events"},
+			{name: "unknown event", input: WebhookEndpointInput{URL: "https://crm.example/hooks", Events: []string{"race.created"}}, field: "events"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := &repositorymocks.WebhookRepositoryMock{}
+				svc := NewWebhookService(repo, webhook.NewHTTPSender(), newTestBox(t))
+
+				_, err := svc.CreateEndpoint(context.Background(), 7, tt.input)
+
+				var errs FieldErrors
+				if !errors.As(err, &errs) || errs[tt.field] == "" {
+					t.Fatalf("expected an error for %s, got %v", tt.field, err)
+				}
+				if len(repo.CreateEndpointCalls()) != 0 {
+					t.Error("expected nothing stored")
+				}
+			})
+		}
+	})
+}
+
+// webhookReceiver is an endpoint answering with each status in turn and
+// recording the requests it gets.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := rc.statuses[0]
+	if len(rc.statuses) > 1 {
+		rc.statuses = rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestWebhookService_DeliverWebhooks(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	payload := []byte(`{"event":"registration.created","data":{"id":100}}`)
+	const key = "endpoint-secret"
+
+	// setup returns a service claiming one delivery, that has already been
+	// attempted attempts times, for an endpoint at srv
+	setup := func(t *testing.T, srv *httptest.Server, attempts int32) (*webhookService, *repositorymocks.WebhookRepositoryMock) {
+		box := newTestBox(t)
+		repo := &repositorymocks.WebhookRepositoryMock{
+			ClaimDueFunc: func(ctx context.Context, at, leaseUntil time.Time, limit int) ([]db.ClaimWebhookDeliveriesRow, error) {
+				return []db.ClaimWebhookDeliveriesRow{{
+					ID:           11,
+					EventType:    webhook.EventRegistrationCreated,
+					Payload:      payload,
+					Attempts:     attempts,
+					Url:          srv.URL,
+					SecretSealed: box.Seal([]byte(key)),
+				}}, nil
+			},
+		}
+		svc := NewWebhookService(repo, webhook.NewHTTPSender(), box).(*webhookService)
+		svc.clock = &MockClock{CurrentTime: now}
+		return svc, repo
+	}
+
+	t.Run("signs the payload with the endpoint's secret", func(t *testing.T) {
+		receiver := &webhookReceiver{statuses: []int{http.StatusOK}}
+		srv := httptest.NewServer(receiver)
+		defer srv.Close()
+		svc, _ := setup(t, srv, 0)
+
+		if _, err := svc.DeliverWebhooks(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(receiver.requests) != 1 {
+			t.Fatalf("expected one request, got %d", len(receiver.requests))
+		}
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(receiver.bodies[0])
+		want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if got := receiver.requests[0].Header.Get(webhook.SignatureHeader); got != want {
+			t.Errorf("expected signature %s, got %s", want, got)
+		}
+		if string(receiver.bodies[0]) != string(payload) {
+			t.Errorf("expected the payload as queued, got %s", receiver.bodies[0])
+		}
+	})
+
+	t.Run("marks accepted deliveries delivered", func(t *testing.T) {
+		receiver := &webhookReceiver{statuses: []int{http.StatusAccepted}}
+		srv := httptest.NewServer(receiver)
+		defer srv.Close()
+		svc, repo := setup(t, srv, 0)
+
+		delivered, err := svc.DeliverWebhooks(context.Background())
+
+		if err != nil || delivered != 1 {
+			t.Fatalf("expected one delivered, got %d, %v", delivered, err)
+		}
+		calls := repo.RecordAttemptCalls()
+		if len(calls) != 1 {
+			t.Fatalf("expected one attempt recorded, got %d", len(calls))
+		}
+		a := calls[0].Attempt
+		if a.Status != db.WebhookDeliveryStatusDelivered || a.StatusCode != http.StatusAccepted || a.Attempts != 1 || a.Error != "" {
+			t.Errorf("unexpected attempt %+v", a)
+		}
+
+		// Nothing more is claimed, so the next run sends nothing
+		repo.ClaimDueFunc = nil
+		if _, err := svc.DeliverWebhooks(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(receiver.requests) != 1 {
+			t.Errorf("expected no retry, got %d requests", len(receiver.requests))
+		}
+	})
+
+	t.Run("retries failures with backoff", func(t *testing.T) {
+		receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+		srv := httptest.NewServer(receiver)
+		defer srv.Close()
+		svc, repo := setup(t, srv, 2)
+
+		delivered, err := svc.DeliverWebhooks(context.Background())
+
+		if err != nil || delivered != 0 {
+			t.Fatalf("expected nothing delivered and no error, got %d, %v", delivered, err)
+		}
+		a := repo.RecordAttemptCalls()[0].Attempt
+		if a.Status != db.WebhookDeliveryStatusPending || a.StatusCode != http.StatusInternalServerError || a.Attempts != 3 {
+			t.Errorf("expected the third attempt left pending, got %+v", a)
+		}
+		if want := now.Add(4 * time.Minute); !a.NextAttemptAt.Equal(want) {
+			t.Errorf("expected a retry at %v, got %v", want, a.NextAttemptAt)
+		}
+		if a.Error == "" {
+			t.Error("expected the failure recorded")
+		}
+	})
+
+	t.Run("gives up once the retries are used", func(t *testing.T) {
+		receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+		srv := httptest.NewServer(receiver)
+		defer srv.Close()
+		svc, repo := setup(t, srv, WebhookMaxRetries)
+
+		if _, err := svc.DeliverWebhooks(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if a := repo.RecordAttemptCalls()[0].Attempt; a.Status != db.WebhookDeliveryStatusFailed || a.Attempts != WebhookMaxRetries+1 {
+			t.Errorf("expected the delivery failed, got %+v", a)
+		}
+	})
+
+	t.Run("leases what it claims for longer than a run can take", func(t *testing.T) {
+		var leased time.Duration
+		repo := &repositorymocks.WebhookRepositoryMock{
+			ClaimDueFunc: func(ctx context.Context, at, leaseUntil time.Time, limit int) ([]db.ClaimWebhookDeliveriesRow, error) {
+				leased = leaseUntil.Sub(at)
+				return nil, nil
+			},
+		}
+		svc := NewWebhookService(repo, webhook.NewHTTPSender(), newTestBox(t))
+
+		if _, err := svc.DeliverWebhooks(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if leased <= WebhookBatchSize*webhook.Timeout {
+			t.Errorf("expected a lease beyond a batch of timeouts, got %v", leased)
+		}
+	})
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	for attempts, want := range map[int32]time.Duration{1: time.Minute, 2: 2 * time.Minute, 5: 16 * time.Minute} {
+		if got := WebhookBackoff(attempts); got != want {
+			t.Errorf("WebhookBackoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestRegistrationService_QueueWebhook(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	registrations := &repositorymocks.RegistrationRepositoryMock{
+		GetForWebhookFunc: func(ctx context.Context, id int64) (db.GetRegistrationForWebhookRow, error) {
+			return db.GetRegistrationForWebhookRow{
+				ID:             id,
+				Status:         db.RegistrationStatusConfirmed,
+				Bib:            pgtype.Text{String: "101", Valid: true},
+				CreatedAt:      pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true},
+				RaceID:         3,
+				RaceName:       "10K",
+				EventID:        10,
+				EventName:      "Riverside Run",
+				OrganisationID: 7,
+				Email:          "sam@example.com",
+				FirstName:      "Sam",
+				LastName:       "Runner",
+			}, nil
+		},
+	}
+	var organisationID int64
+	var event string
+	var body []byte
+	webhooks := &repositorymocks.WebhookRepositoryMock{
+		EnqueueFunc: func(ctx context.Context, orgID int64, eventType string, payload []byte) (int64, error) {
+			organisationID, event, body = orgID, eventType, payload
+			return 1, nil
+		},
+	}
+	svc := &registrationService{registrationRepo: registrations, webhookRepo: webhooks, clock: &MockClock{CurrentTime: now}}
+
+	previous := &webhookEntrant{Email: "ada@example.com", FirstName: "Ada"}
+	if err := svc.queueWebhook(context.Background(), webhook.EventRegistrationTransferred, 100, previous); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if organisationID != 7 || event != webhook.EventRegistrationTransferred {
+		t.Errorf("expected the event queued for organisation 7, got %q for %d", event, organisationID)
+	}
+	var got struct {
+		Event     string    `json:"event"`
+		CreatedAt time.Time `json:"created_at"`
+		Data      struct {
+			ID      int64  `json:"id"`
+			Bib     string `json:"bib"`
+			Race    struct{ Name string }
+			Entrant struct {
+				Email string `json:"email"`
+			} `json:"entrant"`
+			PreviousEntrant struct {
+				Email string `json:"email"`
+			} `json:"previous_entrant"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("failed to parse payload: %v\n%s", err, body)
+	}
+	if got.Event != webhook.EventRegistrationTransferred || !got.CreatedAt.Equal(now) {
+		t.Errorf("unexpected envelope %s", body)
+	}
+	d := got.Data
+	if d.ID != 100 || d.Bib != "101" || d.Race.Name != "10K" || d.Entrant.Email != "sam@example.com" || d.PreviousEntrant.Email != "ada@example.com" {
+		t.Errorf("unexpected data %s", body)
+	}
+
+	t.Run("reports registrations that have gone", func(t *testing.T) {
+		registrations := &repositorymocks.RegistrationRepositoryMock{
+			GetForWebhookFunc: func(ctx context.Context, id int64) (db.GetRegistrationForWebhookRow, error) {
+				return db.GetRegistrationForWebhookRow{}, repository.ErrNotFound
+			},
+		}
+		svc := &registrationService{registrationRepo: registrations, webhookRepo: &repositorymocks.WebhookRepositoryMock{}, clock: &MockClock{CurrentTime: now}}
+
+		if err := svc.queueWebhook(context.Background(), webhook.EventRegistrationCreated, 100, nil); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestSubscribeWebhooks(t *testing.T) {
+	registrations := &repositorymocks.RegistrationRepositoryMock{
+		GetForWebhookFunc: func(ctx context.Context, id int64) (db.GetRegistrationForWebhookRow, error) {
+			return db.GetRegistrationForWebhookRow{ID: id, OrganisationID: 7}, nil
+		},
+	}
+	var mu sync.Mutex
+	queued := map[string]int64{}
+	webhooks := &repositorymocks.WebhookRepositoryMock{
+		EnqueueFunc: func(ctx context.Context, orgID int64, eventType string, payload []byte) (int64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			queued[eventType] = orgID
+			return 1, nil
+		},
+	}
+	b := bus.New(slog.New(slog.DiscardHandler))
+	SubscribeWebhooks(b, registrations, webhooks)
+	ctx := context.Background()
+
+	bus.Publish(ctx, b, bus.RegistrationCreated, bus.RegistrationChange{RegistrationID: 100, RaceID: 3, EventID: 10})
+	bus.Publish(ctx, b, bus.RegistrationCancelled, bus.RegistrationChange{RegistrationID: 101, RaceID: 3, EventID: 10})
+	bus.Publish(ctx, b, bus.RaceUpdated, bus.RaceChange{RaceID: 3, EventID: 10})
+	b.Wait()
+
+	want := map[string]int64{webhook.EventRegistrationCreated: 7, webhook.EventRegistrationCancelled: 7}
+	if !maps.Equal(queued, want) {
+		t.Errorf("expected %v queued, got %v", want, queued)
+	}
+}
@@ -0,0 +1,382 @@
+// Package session keeps sessions in the browser's cookie instead of a
+// server-side store, so that reading who is signed in costs no database
+// round trip.
+//
+// A session cookie has the form
+//
+//	<sealed payload>.<signature>
+//
+// both unpadded base64url. The payload is the session's deadline and
+// values, gob-encoded as scs encodes them for its stores, sealed with
+// AES-256-GCM by a secret.Box. The signature is an HMAC-SHA256 of the
+// sealed payload, checked before anything is decrypted. The encryption and
+// signing keys are derived from one key with HKDF.
+//
+// A cookie cannot be taken back once issued, so it is valid until its
+// deadline. Signing a user out everywhere relies on the values it carries
+// being checked against the database where it matters: the application
+// compares the session version the cookie holds with the user's.
+package session
+
+import (
+	"context"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+
+	"firecrest/internal/secret"
+)
+
+// KeyLength is the length of the key sessions are sealed and signed with,
+// in bytes.
+const KeyLength = 32
+
+// MaxCookieLength is the longest cookie a session may need. Browsers keep
+// cookies of up to 4096 bytes, name and attributes included.
+const MaxCookieLength = 3800
+
+var (
+	// ErrInvalid is returned for cookies that are malformed, were not
+	// issued with the manager's key, or have been altered since.
+	ErrInvalid = errors.New("session: invalid cookie")
+	// ErrTooLarge is returned when a session holds more than fits in a
+	// cookie.
+	ErrTooLarge = errors.New("session: too large for a cookie")
+)
+
+// CookieManager keeps each request's session in a signed, encrypted cookie.
+// Its methods match those of scs.SessionManager the application uses, so
+// either can hold its sessions. A request's session belongs to the
+// goroutine serving it: nothing is shared between requests, so nothing is
+// locked.
+type CookieManager struct {
+	// Lifetime is how long a new session lasts, unless its deadline is set.
+	Lifetime time.Duration
+	// Cookie configures the session cookie; the same settings as scs's.
+	Cookie scs.SessionCookie
+	// ErrorFunc handles a session that cannot be saved. By default the
+	// error is logged and a 500 sent.
+	ErrorFunc func(http.ResponseWriter, *http.Request, error)
+
+	box     *secret.Box
+	signKey []byte
+	codec   scs.GobCodec
+	now     func() time.Time
+}
+
+// NewCookieManager creates a CookieManager sealing sessions with key, which
+// must be exactly KeyLength bytes, with scs's default lifetime and cookie
+// settings.
+func NewCookieManager(key []byte) (*CookieManager, error) {
+	if len(key) != KeyLength {
+		return nil, fmt.Errorf("session: key must be %d bytes, got %d", KeyLength, len(key))
+	}
+	sealKey, err := hkdf.Key(sha256.New, key, nil, "firecrest session encryption", secret.KeyLength)
+	if err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+	signKey, err := hkdf.Key(sha256.New, key, nil, "firecrest session signature", sha256.Size)
+	if err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+	box, err := secret.NewBox(sealKey)
+	if err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+
+	defaults := scs.New()
+	return &CookieManager{
+		Lifetime:  defaults.Lifetime,
+		Cookie:    defaults.Cookie,
+		ErrorFunc: defaultErrorFunc,
+		box:       box,
+		signKey:   signKey,
+		now:       time.Now,
+	}, nil
+}
+
+// status is what has happened to a session during its request.
+type status int
+
+const (
+	unmodified status = iota
+	modified
+	destroyed
+)
+
+// data is a request's session.
+type data struct {
+	deadline time.Time
+	values   map[string]any
+	status   status
+}
+
+type contextKey struct{}
+
+// LoadAndSave loads the request's session from its cookie and sends the
+// session back in the response once the handler changes it. A missing,
+// invalid or expired cookie gives a new, empty session.
+func (m *CookieManager) LoadAndSave(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Cookie")
+
+		sd := m.load(r)
+		sr := r.WithContext(context.WithValue(r.Context(), contextKey{}, sd))
+		sw := &responseWriter{ResponseWriter: w, request: sr, manager: m}
+
+		next.ServeHTTP(sw, sr)
+
+		if !sw.written {
+			m.commit(w, sr)
+		}
+	})
+}
+
+// load reads the request's session, or starts a new one.
+func (m *CookieManager) load(r *http.Request) *data {
+	if c, err := r.Cookie(m.Cookie.Name); err == nil {
+		if deadline, values, err := m.Decode(c.Value); err == nil && m.now().Before(deadline) {
+			return &data{deadline: deadline, values: values}
+		}
+	}
+	return &data{deadline: m.now().Add(m.Lifetime), values: map[string]any{}}
+}
+
+// Encode seals and signs a session as a cookie value.
+func (m *CookieManager) Encode(deadline time.Time, values map[string]any) (string, error) {
+	b, err := m.codec.Encode(deadline, values)
+	if err != nil {
+		return "", fmt.Errorf("session: %w", err)
+	}
+	sealed := m.box.Seal(b)
+	value := base64.RawURLEncoding.EncodeToString(sealed) + "." + base64.RawURLEncoding.EncodeToString(m.sign(sealed))
+	if len(value) > MaxCookieLength {
+		return "", ErrTooLarge
+	}
+	return value, nil
+}
+
+// Decode checks a cookie value's signature and returns the session it
+// holds. It returns ErrInvalid if the value was not issued by Encode with
+// the manager's key, or has been altered since.
+func (m *CookieManager) Decode(value string) (time.Time, map[string]any, error) {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return time.Time{}, nil, ErrInvalid
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return time.Time{}, nil, ErrInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, m.sign(sealed)) {
+		return time.Time{}, nil, ErrInvalid
+	}
+	b, err := m.box.Open(sealed)
+	if err != nil {
+		return time.Time{}, nil, ErrInvalid
+	}
+	deadline, values, err := m.codec.Decode(b)
+	if err != nil {
+		return time.Time{}, nil, ErrInvalid
+	}
+	return deadline, values, nil
+}
+
+func (m *CookieManager) sign(sealed []byte) []byte {
+	mac := hmac.New(sha256.New, m.signKey)
+	mac.Write(sealed)
+	return mac.Sum(nil)
+}
+
+// commit sends a changed session's cookie, or expires a destroyed one's.
+func (m *CookieManager) commit(w http.ResponseWriter, r *http.Request) {
+	sd := m.data(r.Context())
+	switch {
+	case sd.status == unmodified:
+		return
+	case len(sd.values) == 0 && sd.status == destroyed:
+		m.writeCookie(w, "", time.Time{})
+	default:
+		value, err := m.Encode(sd.deadline, sd.values)
+		if err != nil {
+			m.ErrorFunc(w, r, err)
+			return
+		}
+		m.writeCookie(w, value, sd.deadline)
+	}
+}
+
+// writeCookie sets the session cookie, expiring it when expiry is zero.
+func (m *CookieManager) writeCookie(w http.ResponseWriter, value string, expiry time.Time) {
+	cookie := &http.Cookie{
+		Name:        m.Cookie.Name,
+		Value:       value,
+		Domain:      m.Cookie.Domain,
+		Path:        m.Cookie.Path,
+		HttpOnly:    m.Cookie.HttpOnly,
+		SameSite:    m.Cookie.SameSite,
+		Secure:      m.Cookie.Secure,
+		Partitioned: m.Cookie.Partitioned,
+	}
+	switch {
+	case expiry.IsZero():
+		cookie.Expires = time.Unix(1, 0)
+		cookie.MaxAge = -1
+	case m.Cookie.Persist:
+		// Rounded up to the second, as scs does
+		cookie.Expires = time.Unix(expiry.Unix()+1, 0)
+		cookie.MaxAge = int(expiry.Sub(m.now()).Seconds() + 1)
+	}
+	w.Header().Add("Set-Cookie", cookie.String())
+	w.Header().Add("Cache-Control", `no-cache="Set-Cookie"`)
+}
+
+// data returns the session LoadAndSave put in ctx. Like scs, it panics
+// outside the middleware, where there is no session to use.
+func (m *CookieManager) data(ctx context.Context) *data {
+	sd, ok := ctx.Value(contextKey{}).(*data)
+	if !ok {
+		panic("session: no session data in context")
+	}
+	return sd
+}
+
+// Put adds key and val to the session, replacing any value key had.
+func (m *CookieManager) Put(ctx context.Context, key string, val any) {
+	sd := m.data(ctx)
+	sd.values[key] = val
+	sd.status = modified
+}
+
+// Exists reports whether key is in the session.
+func (m *CookieManager) Exists(ctx context.Context, key string) bool {
+	_, ok := m.data(ctx).values[key]
+	return ok
+}
+
+// GetBool returns the bool value for key, or false if it is missing or not
+// a bool.
+func (m *CookieManager) GetBool(ctx context.Context, key string) bool {
+	v, _ := m.data(ctx).values[key].(bool)
+	return v
+}
+
+// GetInt32 returns the int32 value for key, or zero if it is missing or not
+// an int32.
+func (m *CookieManager) GetInt32(ctx context.Context, key string) int32 {
+	v, _ := m.data(ctx).values[key].(int32)
+	return v
+}
+
+// GetInt64 returns the int64 value for key, or zero if it is missing or not
+// an int64.
+func (m *CookieManager) GetInt64(ctx context.Context, key string) int64 {
+	v, _ := m.data(ctx).values[key].(int64)
+	return v
+}
+
+// GetString returns the string value for key, or "" if it is missing or
+// not a string.
+func (m *CookieManager) GetString(ctx context.Context, key string) string {
+	v, _ := m.data(ctx).values[key].(string)
+	return v
+}
+
+// PopString returns the string value for key, as GetString does, and
+// removes it from the session.
+func (m *CookieManager) PopString(ctx context.Context, key string) string {
+	sd := m.data(ctx)
+	v, ok := sd.values[key]
+	if !ok {
+		return ""
+	}
+	delete(sd.values, key)
+	sd.status = modified
+	s, _ := v.(string)
+	return s
+}
+
+// Remove deletes key from the session.
+func (m *CookieManager) Remove(ctx context.Context, key string) {
+	sd := m.data(ctx)
+	if _, ok := sd.values[key]; !ok {
+		return
+	}
+	delete(sd.values, key)
+	sd.status = modified
+}
+
+// RenewToken sends the session in a freshly sealed cookie. There is no
+// server-side token to replace: a cookie planted before sign-in cannot be
+// used to read the session after it, as the session is only ever in the
+// cookie the response sends.
+func (m *CookieManager) RenewToken(ctx context.Context) error {
+	m.data(ctx).status = modified
+	return nil
+}
+
+// SetDeadline sets when the session expires.
+func (m *CookieManager) SetDeadline(ctx context.Context, expire time.Time) {
+	sd := m.data(ctx)
+	sd.deadline = expire
+	sd.status = modified
+}
+
+// Destroy empties the session and expires its cookie. Values put in it
+// afterwards start a new session.
+func (m *CookieManager) Destroy(ctx context.Context) error {
+	sd := m.data(ctx)
+	sd.values = map[string]any{}
+	sd.deadline = m.now().Add(m.Lifetime)
+	sd.status = destroyed
+	return nil
+}
+
+// Iterate does nothing: the manager keeps no sessions to iterate over, as
+// each is held only by the browser it was sent to.
+func (m *CookieManager) Iterate(ctx context.Context, fn func(context.Context) error) error {
+	return nil
+}
+
+// responseWriter sends the session cookie before the response's headers.
+type responseWriter struct {
+	http.ResponseWriter
+	request *http.Request
+	manager *CookieManager
+	written bool
+}
+
+func (sw *responseWriter) Write(b []byte) (int, error) {
+	if !sw.written {
+		sw.manager.commit(sw.ResponseWriter, sw.request)
+		sw.written = true
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *responseWriter) WriteHeader(code int) {
+	if !sw.written {
+		sw.manager.commit(sw.ResponseWriter, sw.request)
+		sw.written = true
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *responseWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+func defaultErrorFunc(w http.ResponseWriter, r *http.Request, err error) {
+	log.Output(2, err.Error())
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,262 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/alexedwards/scs/v2/memstore"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestManager(t testing.TB, key []byte) *CookieManager {
+	t.Helper()
+	m, err := NewCookieManager(key)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	return m
+}
+
+// serve runs h behind the manager's middleware, sending cookies with the
+// request, and returns the response.
+func serve(m *CookieManager, cookies []*http.Cookie, h http.HandlerFunc) *http.Response {
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rr := httptest.NewRecorder()
+	m.LoadAndSave(h).ServeHTTP(rr, req)
+	return rr.Result()
+}
+
+// signIn returns the cookies of a session holding user ID 7.
+func signIn(t *testing.T, m *CookieManager) []*http.Cookie {
+	t.Helper()
+	cookies := serve(m, nil, func(w http.ResponseWriter, r *http.Request) {
+		m.Put(r.Context(), "userID", int64(7))
+		m.Put(r.Context(), "sessionVersion", int32(2))
+	}).Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected a session cookie, got %v", cookies)
+	}
+	return cookies
+}
+
+func TestNewCookieManager(t *testing.T) {
+	for _, key := range [][]byte{[]byte("too-short"), append([]byte(string(testKey)), 'x')} {
+		if _, err := NewCookieManager(key); err == nil {
+			t.Errorf("expected error for a %d byte key", len(key))
+		}
+	}
+}
+
+func TestCookieManager_LoadAndSave(t *testing.T) {
+	t.Run("reads back what was put in the session", func(t *testing.T) {
+		m := newTestManager(t, testKey)
+		cookies := signIn(t, m)
+
+		if strings.Contains(cookies[0].Value, "userID") {
+			t.Errorf("expected the session to be encrypted, got %q", cookies[0].Value)
+		}
+		var userID int64
+		var version int32
+		serve(m, cookies, func(w http.ResponseWriter, r *http.Request) {
+			userID = m.GetInt64(r.Context(), "userID")
+			version = m.GetInt32(r.Context(), "sessionVersion")
+		})
+		if userID != 7 || version != 2 {
+			t.Errorf("expected user 7 at version 2, got %d at %d", userID, version)
+		}
+	})
+
+	t.Run("sends no cookie for a session left unchanged", func(t *testing.T) {
+		m := newTestManager(t, testKey)
+		cookies := signIn(t, m)
+
+		res := serve(m, cookies, func(w http.ResponseWriter, r *http.Request) {
+			m.GetInt64(r.Context(), "userID")
+			w.Write([]byte("ok"))
+		})
+		if len(res.Cookies()) != 0 {
+			t.Errorf("expected no cookie, got %v", res.Cookies())
+		}
+	})
+
+	t.Run("sends the cookie before a response written early", func(t *testing.T) {
+		m := newTestManager(t, testKey)
+
+		res := serve(m, nil, func(w http.ResponseWriter, r *http.Request) {
+			m.Put(r.Context(), "flash_success", "Saved")
+			w.WriteHeader(http.StatusSeeOther)
+		})
+		if len(res.Cookies()) != 1 {
+			t.Fatalf("expected a session cookie, got %v", res.Cookies())
+		}
+		if got := res.Header.Get("Cache-Control"); got != `no-cache="Set-Cookie"` {
+			t.Errorf("expected the cookie kept out of caches, got %q", got)
+		}
+	})
+
+	t.Run("sets the cookie's lifetime from the deadline", func(t *testing.T) {
+		m := newTestManager(t, testKey)
+		m.Lifetime = 12 * time.Hour
+
+		res := serve(m, nil, func(w http.ResponseWriter, r *http.Request) {
+			m.Put(r.Context(), "userID", int64(7))
+		})
+		if got := time.Duration(res.Cookies()[0].MaxAge) * time.Second; got > 12*time.Hour+time.Second || got < 12*time.Hour-5*time.Second {
+			t.Errorf("expected a Max-Age of about 12 hours, got %v", got)
+		}
+	})
+
+	t.Run("deletes the cookie of a destroyed session", func(t *testing.T) {
+		m := newTestManager(t, testKey)
+		cookies := signIn(t, m)
+
+		res := serve(m, cookies, func(w http.ResponseWriter, r *http.Request) {
+			m.Destroy(r.Context())
+		})
+		if c := res.Cookies(); len(c) != 1 || c[0].MaxAge >= 0 || c[0].Value != "" {
+			t.Errorf("expected the cookie to be deleted, got %v", c)
+		}
+	})
+
+	t.Run("starts a new session after an expired one", func(t *testing.T) {
+		m := newTestManager(t, testKey)
+		cookies := signIn(t, m)
+		m.now = func() time.Time { return time.Now().Add(m.Lifetime + time.Minute) }
+
+		var signedIn bool
+		serve(m, cookies, func(w http.ResponseWriter, r *http.Request) {
+			signedIn = m.Exists(r.Context(), "userID")
+		})
+		if signedIn {
+			t.Error("expected the expired session to be empty")
+		}
+	})
+
+	t.Run("reports sessions too large for a cookie", func(t *testing.T) {
+		m := newTestManager(t, testKey)
+		var got error
+		m.ErrorFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			got = err
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		res := serve(m, nil, func(w http.ResponseWriter, r *http.Request) {
+			m.Put(r.Context(), "note", rand.Text()+strings.Repeat(rand.Text(), MaxCookieLength/26))
+		})
+		if !errors.Is(got, ErrTooLarge) || len(res.Cookies()) != 0 {
+			t.Errorf("expected ErrTooLarge and no cookie, got %v and %v", got, res.Cookies())
+		}
+	})
+}
+
+func TestCookieManager_Decode(t *testing.T) {
+	m := newTestManager(t, testKey)
+	value, err := m.Encode(time.Now().Add(time.Hour), map[string]any{"userID": int64(7)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload, signature, _ := strings.Cut(value, ".")
+	forged, err := newTestManager(t, []byte("fedcba9876543210fedcba9876543210")).Encode(time.Now().Add(time.Hour), map[string]any{"userID": int64(1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// flip changes the first character of s to another valid one
+	flip := func(s string) string {
+		if s[0] == 'A' {
+			return "B" + s[1:]
+		}
+		return "A" + s[1:]
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "altered payloads", value: flip(payload) + "." + signature},
+		{name: "altered signatures", value: payload + "." + flip(signature)},
+		{name: "payloads without a signature", value: payload},
+		{name: "another key's cookies", value: forged},
+		{name: "malformed values", value: "not!base64.at-all!"},
+		{name: "empty values", value: ""},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			if _, _, err := m.Decode(tt.value); !errors.Is(err, ErrInvalid) {
+				t.Errorf("expected ErrInvalid, got %v", err)
+			}
+		})
+	}
+
+	t.Run("gives a tampered cookie an empty session", func(t *testing.T) {
+		var userID int64
+		serve(m, []*http.Cookie{{Name: m.Cookie.Name, Value: flip(payload) + "." + signature}}, func(w http.ResponseWriter, r *http.Request) {
+			userID = m.GetInt64(r.Context(), "userID")
+		})
+		if userID != 0 {
+			t.Errorf("expected no user, got %d", userID)
+		}
+	})
+}
+
+// BenchmarkSessionRead compares reading a signed-in session from a cookie
+// with reading it from an scs store, here one in memory; see
+// BenchmarkSessionBackends in the repository package for Postgres.
+func BenchmarkSessionRead(b *testing.B) {
+	values := func(ctx context.Context, put func(context.Context, string, any)) {
+		put(ctx, "userID", int64(7))
+		put(ctx, "sessionID", int64(42))
+		put(ctx, "expiresAt", time.Now().Add(time.Hour).Unix())
+		put(ctx, "sessionVersion", int32(0))
+	}
+	bench := func(b *testing.B, h http.Handler, cookie *http.Cookie) {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.AddCookie(cookie)
+		for b.Loop() {
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		}
+	}
+
+	b.Run("cookie", func(b *testing.B) {
+		m := newTestManager(b, testKey)
+		session := map[string]any{}
+		values(context.Background(), func(ctx context.Context, key string, val any) { session[key] = val })
+		value, err := m.Encode(time.Now().Add(time.Hour), session)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		bench(b, m.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.GetInt64(r.Context(), "userID")
+		})), &http.Cookie{Name: m.Cookie.Name, Value: value})
+	})
+
+	b.Run("store", func(b *testing.B) {
+		m := scs.New()
+		store := memstore.NewWithCleanupInterval(0)
+		m.Store = store
+		ctx, err := m.Load(context.Background(), "")
+		if err != nil {
+			b.Fatal(err)
+		}
+		values(ctx, m.Put)
+		token, _, err := m.Commit(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		bench(b, m.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.GetInt64(r.Context(), "userID")
+		})), &http.Cookie{Name: m.Cookie.Name, Value: token})
+	})
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStore keeps blobs as files under a directory, for development. Its
+// signed URLs point back at the application, which serves them through
+// the store as an http.Handler.
+type LocalStore struct {
+	root *os.Root
+	// baseURL is where the handler is mounted, such as "/media"
+	baseURL string
+	key     []byte
+	now     func() time.Time
+}
+
+// NewLocalStore creates a LocalStore keeping blobs under dir, which is
+// created if need be. Signed URLs start with baseURL, where the store must
+// be mounted with http.StripPrefix, and are signed with signingKey.
+func NewLocalStore(dir, baseURL string, signingKey []byte) (*LocalStore, error) {
+	if len(signingKey) == 0 {
+		return nil, errors.New("storage: signing key is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+	return &LocalStore{
+		root:    root,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		key:     signingKey,
+		now:     time.Now,
+	}, nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if err := s.root.MkdirAll(path.Dir(key), 0o750); err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+
+	// Written aside and renamed into place, so readers never see part of a
+	// blob
+	tmp := key + ".tmp-" + rand.Text()
+	f, err := s.root.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = s.root.Rename(tmp, key)
+	}
+	if err != nil {
+		//nolint:errcheck // the write already failed
+		s.root.Remove(tmp)
+		return fmt.Errorf("storage: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	f, err := s.root.Open(key)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+	return f, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if err := s.root.Remove(key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(s.now().Add(ttl).Unix(), 10)
+	q := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return s.baseURL + "/" + key + "?" + q.Encode(), nil
+}
+
+// sign returns the signature of a URL for key that expires at the given
+// Unix time.
+func (s *LocalStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(key + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ServeHTTP serves the blob named by the request's path, once the prefix
+// the store is mounted under has been stripped, if the URL was signed by
+// SignedURL and has not expired.
+func (s *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	expires := r.URL.Query().Get("expires")
+	signature := r.URL.Query().Get("signature")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || checkKey(key) != nil || !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	remaining := time.Unix(unix, 0).Sub(s.now())
+	if remaining <= 0 {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	f, err := s.root.Open(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(remaining.Seconds())))
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStore(t *testing.T) *LocalStore {
+	t.Helper()
+	s, err := NewLocalStore(t.TempDir(), "/media", []byte("signing-key"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	return s
+}
+
+func readBlob(t *testing.T, s BlobStore, key string) string {
+	t.Helper()
+	rc, err := s.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(b)
+}
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores, replaces and deletes blobs", func(t *testing.T) {
+		s := newTestLocalStore(t)
+
+		if err := s.Put(ctx, "events/4/photo.jpg", strings.NewReader("first"), "image/jpeg"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.Put(ctx, "events/4/photo.jpg", strings.NewReader("second"), "image/jpeg"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := readBlob(t, s, "events/4/photo.jpg"); got != "second" {
+			t.Errorf("expected the replacement, got %q", got)
+		}
+
+		if err := s.Delete(ctx, "events/4/photo.jpg"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := s.Get(ctx, "events/4/photo.jpg"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if err := s.Delete(ctx, "events/4/photo.jpg"); err != nil {
+			t.Errorf("expected deleting a missing blob to succeed, got %v", err)
+		}
+	})
+
+	t.Run("refuses keys outside the store", func(t *testing.T) {
+		s := newTestLocalStore(t)
+		for _, key := range []string{"", "/etc/passwd", "../secret", "events/../../secret", "events//photo.jpg", `events\photo.jpg`} {
+			if err := s.Put(ctx, key, strings.NewReader("x"), "text/plain"); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("expected ErrInvalidKey for %q, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("serves signed URLs until they expire", func(t *testing.T) {
+		s := newTestLocalStore(t)
+		now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+		s.now = func() time.Time { return now }
+		if err := s.Put(ctx, "events/4/web.jpg", strings.NewReader("jpeg bytes"), "image/jpeg"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		signed, err := s.SignedURL(ctx, "events/4/web.jpg", time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(signed, "/media/events/4/web.jpg?") {
+			t.Fatalf("expected a URL under the mount point, got %q", signed)
+		}
+		handler := http.StripPrefix("/media", s)
+		get := func(target string) *httptest.ResponseRecorder {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, http.NoBody))
+			return rr
+		}
+
+		rr := get(signed)
+		if rr.Code != http.StatusOK || rr.Body.String() != "jpeg bytes" {
+			t.Fatalf("expected the blob, got %d %q", rr.Code, rr.Body.String())
+		}
+		if got := rr.Header().Get("Content-Type"); got != "image/jpeg" {
+			t.Errorf("expected an image content type, got %q", got)
+		}
+
+		if rr := get(strings.Replace(signed, "web.jpg", "original.jpg", 1)); rr.Code != http.StatusForbidden {
+			t.Errorf("expected another key's signature to be refused, got %d", rr.Code)
+		}
+		if rr := get("/media/events/4/web.jpg"); rr.Code != http.StatusForbidden {
+			t.Errorf("expected unsigned requests to be refused, got %d", rr.Code)
+		}
+
+		now = now.Add(time.Hour)
+		if rr := get(signed); rr.Code != http.StatusForbidden {
+			t.Errorf("expected an expired URL to be refused, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+// Package storage keeps uploaded files, such as event photos, in a blob
+// store: a directory on disk in development, or an S3-compatible bucket.
+//
+// Blobs are named by slash-separated keys such as
+// "events/4/photos/3f9c…/web.jpg". Clients never fetch them through the
+// store's credentials; SignedURL hands out a link that works for a while.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for a key with no blob.
+	ErrNotFound = errors.New("storage: blob not found")
+	// ErrInvalidKey is returned for keys that are empty, absolute, or
+	// climb out of the store with "..".
+	ErrInvalidKey = errors.New("storage: invalid key")
+)
+
+// BlobStore stores blobs under keys.
+type BlobStore interface {
+	// Put stores the blob read from r under key, replacing any there.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get opens the blob under key. It returns ErrNotFound if there is
+	// none.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob under key. Deleting a blob that does not
+	// exist is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL the blob under key can be fetched from,
+	// without credentials, until ttl has passed.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// checkKey returns ErrInvalidKey unless key is a relative, slash-separated
+// path that stays inside the store.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.ContainsAny(key, "\\\x00") {
+		return ErrInvalidKey
+	}
+	for part := range strings.SplitSeq(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
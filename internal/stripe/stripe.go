@@ -0,0 +1,149 @@
+// Package stripe reads the webhook events Stripe sends about payments.
+//
+// Stripe signs each event with the endpoint's signing secret: the
+// Stripe-Signature header holds "t=" and the Unix time it was sent, then
+// one or more "v1=" and the hex HMAC-SHA256 of the time, a dot and the
+// body. Verify checks that before an event is trusted.
+package stripe
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the header Stripe signs events in.
+const SignatureHeader = "Stripe-Signature"
+
+// Tolerance is how far from now an event's signed time may be before it is
+// rejected, so a captured request cannot be replayed later.
+const Tolerance = 5 * time.Minute
+
+// Event types the payments report is fed from.
+const (
+	EventChargeSucceeded = "charge.succeeded"
+	EventChargeRefunded  = "charge.refunded"
+	EventChargeFailed    = "charge.failed"
+)
+
+// ErrSignature is returned for an event whose signature is missing, does
+// not match the body or is too old.
+var ErrSignature = errors.New("stripe: invalid signature")
+
+// Sign returns a Stripe-Signature header value for payload sent at t, as
+// Stripe would send it.
+func Sign(payload []byte, secret string, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(payload, secret, ts))
+}
+
+// Verify checks that header is a signature of payload with secret, made
+// within Tolerance of now.
+func Verify(payload []byte, header, secret string, now time.Time) error {
+	var ts string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+	sent, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrSignature
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > Tolerance || age < -Tolerance {
+		return fmt.Errorf("%w: sent %s ago", ErrSignature, age.Round(time.Second))
+	}
+
+	want := mac(payload, secret, ts)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, want) {
+			return nil
+		}
+	}
+	return ErrSignature
+}
+
+func mac(payload []byte, secret, ts string) []byte {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(ts))
+	m.Write([]byte("."))
+	m.Write(payload)
+	return m.Sum(nil)
+}
+
+// Event is a webhook event. Data.Object is the object it is about, such as
+// a Charge for the charge events.
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// ParseEvent decodes an event from a webhook body.
+func ParseEvent(payload []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return Event{}, fmt.Errorf("stripe: decoding event: %w", err)
+	}
+	if e.Type == "" {
+		return Event{}, errors.New("stripe: event has no type")
+	}
+	return e, nil
+}
+
+// Charge is the part of a charge the payments report uses. Amounts are in
+// the currency's minor units, such as pence.
+type Charge struct {
+	ID             string            `json:"id"`
+	Amount         int64             `json:"amount"`
+	AmountRefunded int64             `json:"amount_refunded"`
+	Currency       string            `json:"currency"`
+	Created        int64             `json:"created"`
+	Metadata       map[string]string `json:"metadata"`
+	// BalanceTransaction is the ID of the charge's balance transaction, or
+	// the transaction itself when the endpoint expands it.
+	BalanceTransaction json.RawMessage `json:"balance_transaction"`
+}
+
+// Charge decodes the charge an event is about.
+func (e Event) Charge() (Charge, error) {
+	var c Charge
+	if err := json.Unmarshal(e.Data.Object, &c); err != nil {
+		return Charge{}, fmt.Errorf("stripe: decoding charge: %w", err)
+	}
+	if c.ID == "" {
+		return Charge{}, errors.New("stripe: charge has no id")
+	}
+	return c, nil
+}
+
+// Fee returns what Stripe kept of the charge, which is only known when the
+// balance transaction was expanded into the event.
+func (c Charge) Fee() (int64, bool) {
+	if !bytes.HasPrefix(bytes.TrimSpace(c.BalanceTransaction), []byte("{")) {
+		return 0, false
+	}
+	var tx struct {
+		Fee *int64 `json:"fee"`
+	}
+	if err := json.Unmarshal(c.BalanceTransaction, &tx); err != nil || tx.Fee == nil {
+		return 0, false
+	}
+	return *tx.Fee, true
+}
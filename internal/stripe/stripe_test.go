@@ -0,0 +1,73 @@
+package stripe
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"charge.succeeded"}`)
+	secret := "whsec_test"
+	sent := time.Unix(1760000000, 0)
+	header := Sign(payload, secret, sent)
+
+	if err := Verify(payload, header, secret, sent.Add(time.Minute)); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+	// Stripe sends a signature for each active secret while one is rolled
+	if err := Verify(payload, header+",v1=00ff", secret, sent); err != nil {
+		t.Errorf("expected any matching signature to do, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+		header  string
+		secret  string
+		now     time.Time
+	}{
+		{name: "altered bodies", payload: []byte(`{"id":"evt_2"}`), header: header, secret: secret, now: sent},
+		{name: "other secrets", payload: payload, header: header, secret: "whsec_other", now: sent},
+		{name: "old events", payload: payload, header: header, secret: secret, now: sent.Add(Tolerance + time.Second)},
+		{name: "missing signatures", payload: payload, header: "t=1760000000", secret: secret, now: sent},
+		{name: "missing times", payload: payload, header: header[len("t=1760000000,"):], secret: secret, now: sent},
+		{name: "empty headers", payload: payload, header: "", secret: secret, now: sent},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			if err := Verify(tt.payload, tt.header, tt.secret, tt.now); !errors.Is(err, ErrSignature) {
+				t.Errorf("expected ErrSignature, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCharge_Fee(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		fee     int64
+		ok      bool
+	}{
+		{name: "expanded", payload: `{"data":{"object":{"id":"ch_1","balance_transaction":{"id":"txn_1","fee":120}}}}`, fee: 120, ok: true},
+		{name: "an ID", payload: `{"data":{"object":{"id":"ch_1","balance_transaction":"txn_1"}}}`},
+		{name: "missing", payload: `{"data":{"object":{"id":"ch_1"}}}`},
+		{name: "null", payload: `{"data":{"object":{"id":"ch_1","balance_transaction":null}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := ParseEvent([]byte(`{"type":"charge.succeeded",` + tt.payload[1:]))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			c, err := e.Charge()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fee, ok := c.Fee(); fee != tt.fee || ok != tt.ok {
+				t.Errorf("expected %d, %v, got %d, %v", tt.fee, tt.ok, fee, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package token
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestSigner(t *testing.T, now time.Time) *Signer {
+	t.Helper()
+	s, err := NewSigner(testKey)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestNewSigner(t *testing.T) {
+	if _, err := NewSigner([]byte("too-short")); err == nil {
+		t.Error("expected error for short key")
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	issued := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestSigner(t, issued)
+
+	tok, err := s.SignToken(PurposeEmailVerification, 42, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(tok, "v1:email-verification:42:") {
+		t.Errorf("unexpected token format: %q", tok)
+	}
+
+	userID, err := s.VerifyToken(PurposeEmailVerification, tok)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if userID != 42 {
+		t.Errorf("expected user 42, got %d", userID)
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	issued := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tok, err := newTestSigner(t, issued).SignToken(PurposeEmailVerification, 42, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	parts := strings.Split(tok, ":")
+	replace := func(i int, v string) string {
+		p := append([]string(nil), parts...)
+		p[i] = v
+		return strings.Join(p, ":")
+	}
+
+	otherKey, _ := NewSigner([]byte("fedcba9876543210fedcba9876543210"))
+	otherKey.now = func() time.Time { return issued }
+	foreign, _ := otherKey.SignToken(PurposeEmailVerification, 42, time.Hour)
+
+	tests := []struct {
+		name    string
+		token   string
+		purpose Purpose
+		now     time.Time
+		wantErr error
+	}{
+		{name: "accepts just before expiry", token: tok, now: issued.Add(time.Hour - time.Second)},
+		{name: "rejects at expiry", token: tok, now: issued.Add(time.Hour), wantErr: ErrExpired},
+		{name: "rejects after expiry", token: tok, now: issued.Add(2 * time.Hour), wantErr: ErrExpired},
+		{name: "rejects wrong purpose", token: tok, purpose: PurposePasswordReset, now: issued, wantErr: ErrWrongPurpose},
+		{name: "rejects tampered user id", token: replace(2, "43"), now: issued, wantErr: ErrInvalidSignature},
+		{name: "rejects tampered expiry", token: replace(3, "9999999999"), now: issued, wantErr: ErrInvalidSignature},
+		{name: "rejects tampered purpose", token: replace(1, string(PurposePasswordReset)), purpose: PurposePasswordReset, now: issued, wantErr: ErrInvalidSignature},
+		{name: "rejects tampered signature", token: replace(4, base64.RawURLEncoding.EncodeToString(make([]byte, 32))), now: issued, wantErr: ErrInvalidSignature},
+		{name: "rejects token signed with another key", token: foreign, now: issued, wantErr: ErrInvalidSignature},
+		{name: "rejects unknown version", token: replace(0, "v2"), now: issued, wantErr: ErrMalformed},
+		{name: "rejects undecodable signature", token: replace(4, "not base64!"), now: issued, wantErr: ErrMalformed},
+		{name: "rejects extra fields", token: tok + ":extra", now: issued, wantErr: ErrMalformed},
+		{name: "rejects legacy dotted format", token: "42.1772366400.c2lnbmF0dXJl", now: issued, wantErr: ErrMalformed},
+		{name: "rejects legacy random token", token: "q1w2e3r4t5y6u7i8o9p0q1w2e3r4t5y6u7i8o9p0a1s", now: issued, wantErr: ErrMalformed},
+		{name: "rejects empty token", token: "", now: issued, wantErr: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purpose := tt.purpose
+			if purpose == "" {
+				purpose = PurposeEmailVerification
+			}
+
+			userID, err := newTestSigner(t, tt.now).VerifyToken(purpose, tt.token)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if userID != 42 {
+				t.Errorf("expected user 42, got %d", userID)
+			}
+		})
+	}
+}
+
+func TestSignTokenRejectsInvalidInput(t *testing.T) {
+	s := newTestSigner(t, time.Now())
+
+	if _, err := s.SignToken("bad:purpose", 1, time.Hour); err == nil {
+		t.Error("expected error for purpose containing a separator")
+	}
+	if _, err := s.SignToken(PurposeEmailVerification, 1, 0); err == nil {
+		t.Error("expected error for non-positive ttl")
+	}
+}
+
+func TestSignAndVerifyTime(t *testing.T) {
+	s := newTestSigner(t, time.Now())
+	served := time.Date(2026, time.March, 1, 12, 0, 0, 250*int(time.Millisecond), time.UTC)
+
+	stamp, err := s.SignTime(PurposeContactForm, served)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := s.VerifyTime(PurposeContactForm, stamp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Equal(served) {
+		t.Errorf("expected %v, got %v", served, got)
+	}
+
+	parts := strings.Split(stamp, ":")
+	tests := []struct {
+		name    string
+		purpose Purpose
+		stamp   string
+		wantErr error
+	}{
+		{name: "changed time", purpose: PurposeContactForm, stamp: strings.Join([]string{parts[0], parts[1], "1", parts[3]}, ":"), wantErr: ErrInvalidSignature},
+		{name: "wrong purpose", purpose: PurposeUnsubscribe, stamp: stamp, wantErr: ErrWrongPurpose},
+		{name: "token in place of a stamp", purpose: PurposeContactForm, stamp: "v1:contact-form:1:2:sig", wantErr: ErrMalformed},
+		{name: "empty", purpose: PurposeContactForm, stamp: "", wantErr: ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.VerifyTime(tt.purpose, tt.stamp); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
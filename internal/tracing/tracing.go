@@ -0,0 +1,161 @@
+// Package tracing records OpenTelemetry traces of requests, the service
+// calls they make and the database queries those run, and sends them to an
+// OTLP collector.
+//
+// Until Setup installs a tracer provider the global one is OpenTelemetry's
+// no-op, so spans started anywhere in the application cost next to nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation names the spans this package starts.
+const instrumentation = "firecrest/internal/tracing"
+
+// Config selects where traces are sent and how many are kept.
+type Config struct {
+	// Endpoint is the base URL of the OTLP/HTTP collector, such as
+	// "http://localhost:4318". Traces are sent to its /v1/traces unless the
+	// URL names another path. Empty turns tracing off.
+	Endpoint string
+	// SampleRatio is the fraction of traces started here that are kept,
+	// from 0 to 1. Requests carrying a sampled parent are always kept.
+	SampleRatio float64
+	// ServiceName identifies the application in the collector.
+	ServiceName string
+}
+
+// Enabled reports whether traces are sent anywhere.
+func (c Config) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+// Setup installs a global tracer provider exporting to cfg.Endpoint and
+// returns a function that flushes the spans still buffered and stops it.
+// When tracing is off the no-op provider is left in place and the function
+// does nothing.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	if !cfg.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracing endpoint: %w", err)
+	}
+	if strings.Trim(endpoint.Path, "/") == "" {
+		endpoint = endpoint.JoinPath("v1", "traces")
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// QueryTracer returns a pgx tracer recording each query as a child of the
+// span in its context, named after the sqlc query that ran it. Queries made
+// outside a recorded span are not traced.
+func QueryTracer() pgx.QueryTracer {
+	return otelpgx.NewTracer(
+		otelpgx.WithTrimSQLInSpanName(),
+		otelpgx.WithSpanNameFunc(queryName),
+		otelpgx.WithDisableConnectionDetailsInAttributes(),
+	)
+}
+
+// queryName returns the name sqlc gives sql in its leading "-- name:"
+// comment, or its first word for statements written by hand.
+func queryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		if name, _, ok := strings.Cut(rest, " "); ok {
+			return name
+		}
+	}
+	op, _, _ := strings.Cut(sql, " ")
+	return strings.ToUpper(op)
+}
+
+// Middleware records a span for each request, named and labelled by the
+// ServeMux pattern that matched it and the status answered. Like
+// metrics.Middleware, nothing between it and the ServeMux may replace the
+// request, or the pattern is not seen. A traceparent header from a caller
+// continues its trace.
+func Middleware(next http.Handler) http.Handler {
+	tracer := otel.Tracer(instrumentation)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(r.Method)),
+		)
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		r = r.WithContext(ctx)
+		next.ServeHTTP(rec, r)
+
+		if r.Pattern != "" {
+			route := r.Pattern
+			if _, path, ok := strings.Cut(route, " "); ok {
+				route = path
+			}
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	})
+}
+
+// statusRecorder captures the status code written by downstream handlers.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying ResponseWriter to http.ResponseController.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordSpans installs a global tracer provider keeping every span in
+// memory until the test ends.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	return recorder
+}
+
+// attr returns the value of the span's attribute key, or an empty value.
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events/{year}/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	mux.HandleFunc("POST /admin/events", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+
+	serve := func(t *testing.T, method, path string) sdktrace.ReadOnlySpan {
+		t.Helper()
+		recorder := recordSpans(t)
+		Middleware(mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, http.NoBody))
+
+		spans := recorder.Ended()
+		if len(spans) != 1 {
+			t.Fatalf("expected one span, got %d", len(spans))
+		}
+		return spans[0]
+	}
+
+	t.Run("names the span by route pattern and records the status", func(t *testing.T) {
+		span := serve(t, http.MethodGet, "/events/2026/lincoln-10k")
+
+		if span.Name() != "GET /events/{year}/{slug}" {
+			t.Errorf("expected the span to be named by route, got %q", span.Name())
+		}
+		if got := attr(span, "http.route").AsString(); got != "/events/{year}/{slug}" {
+			t.Errorf("expected the route attribute, got %q", got)
+		}
+		if got := attr(span, "http.response.status_code").AsInt64(); got != http.StatusTeapot {
+			t.Errorf("expected status %d, got %d", http.StatusTeapot, got)
+		}
+		if span.Status().Code == codes.Error {
+			t.Error("expected client errors not to mark the span failed")
+		}
+	})
+
+	t.Run("marks server errors", func(t *testing.T) {
+		span := serve(t, http.MethodPost, "/admin/events")
+
+		if span.Status().Code != codes.Error {
+			t.Errorf("expected the span to be marked failed, got %v", span.Status())
+		}
+	})
+
+	t.Run("names unmatched requests by method alone", func(t *testing.T) {
+		span := serve(t, http.MethodGet, "/nowhere/12345")
+
+		if span.Name() != "GET" || attr(span, "http.route").Type() != attribute.INVALID {
+			t.Errorf("expected no route on the span, got %q with %v", span.Name(), span.Attributes())
+		}
+	})
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{sql: "-- name: GetEvent :one\nSELECT id FROM events WHERE id = $1", want: "GetEvent"},
+		{sql: "\n-- name: ListRaceEntrants :many\nSELECT 1", want: "ListRaceEntrants"},
+		{sql: "select pg_advisory_lock($1)", want: "SELECT"},
+		{sql: "begin", want: "BEGIN"},
+	}
+	for _, tt := range tests {
+		if got := queryName(tt.sql); got != tt.want {
+			t.Errorf("queryName(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	before := otel.GetTracerProvider()
+
+	shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected error shutting down: %v", err)
+	}
+	if otel.GetTracerProvider() != before {
+		t.Error("expected the no-op provider to be left in place when tracing is off")
+	}
+}
@@ -0,0 +1,116 @@
+// Package webhook notifies organisers' own systems, such as their CRMs, of
+// events like new registrations over HTTP.
+//
+// Each notification is a JSON POST signed with the endpoint's secret: the
+// X-Firecrest-Signature header holds "sha256=" and the hex HMAC-SHA256 of
+// the body, so a receiver can check the request came from us unaltered.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every request.
+const (
+	SignatureHeader = "X-Firecrest-Signature"
+	EventHeader     = "X-Firecrest-Event"
+	DeliveryHeader  = "X-Firecrest-Delivery"
+)
+
+// Event types endpoints can subscribe to.
+const (
+	EventRegistrationCreated     = "registration.created"
+	EventRegistrationCancelled   = "registration.cancelled"
+	EventRegistrationTransferred = "registration.transferred"
+)
+
+// Events lists the event types, in the order forms offer them.
+var Events = []string{EventRegistrationCreated, EventRegistrationCancelled, EventRegistrationTransferred}
+
+// Timeout bounds each request, from connecting to reading the response.
+const Timeout = 10 * time.Second
+
+// ErrStatus is returned when an endpoint answers with a status other than
+// 2xx.
+var ErrStatus = errors.New("webhook: endpoint did not accept the request")
+
+// Payload is the body of every request.
+type Payload struct {
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Request is a delivery to send to an endpoint.
+type Request struct {
+	URL        string
+	Secret     []byte
+	DeliveryID int64
+	Event      string
+	Body       []byte
+}
+
+// Sender sends requests to endpoints. Send returns the status code of the
+// response, or zero if none came back, and an error unless it was 2xx.
+type Sender interface {
+	Send(ctx context.Context, req Request) (int, error)
+}
+
+// HTTPSender sends requests over HTTP.
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender creates an HTTPSender. Redirects are not followed, so an
+// endpoint that has moved fails until its URL is updated.
+func NewHTTPSender() *HTTPSender {
+	return &HTTPSender{client: &http.Client{
+		Timeout: Timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, req Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "Firecrest-Webhooks/1.0")
+	httpReq.Header.Set(SignatureHeader, Sign(req.Secret, req.Body))
+	httpReq.Header.Set(EventHeader, req.Event)
+	httpReq.Header.Set(DeliveryHeader, strconv.FormatInt(req.DeliveryID, 10))
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Reading a little of the body lets the connection be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w: %s", ErrStatus, resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value for body: "sha256=" and the hex
+// HMAC-SHA256 of body keyed with secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
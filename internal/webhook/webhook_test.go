@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// From RFC 4231, test case 2
+	got := Sign([]byte("Jefe"), []byte("what do ya want for nothing?"))
+	want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestHTTPSender(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"event":"registration.created"}`)
+
+	t.Run("posts the signed body", func(t *testing.T) {
+		var got *http.Request
+		var gotBody []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			gotBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		status, err := NewHTTPSender().Send(context.Background(), Request{
+			URL:        srv.URL,
+			Secret:     secret,
+			DeliveryID: 42,
+			Event:      EventRegistrationCreated,
+			Body:       body,
+		})
+
+		if err != nil || status != http.StatusNoContent {
+			t.Fatalf("expected 204 and no error, got %d, %v", status, err)
+		}
+		if got.Method != http.MethodPost || string(gotBody) != string(body) {
+			t.Errorf("expected the body posted, got %s %s", got.Method, gotBody)
+		}
+		// Check the signature as a receiver would
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(gotBody)
+		if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); got.Header.Get(SignatureHeader) != want {
+			t.Errorf("expected signature %s, got %s", want, got.Header.Get(SignatureHeader))
+		}
+		if got.Header.Get(EventHeader) != EventRegistrationCreated || got.Header.Get(DeliveryHeader) != "42" {
+			t.Errorf("unexpected headers %v", got.Header)
+		}
+		if ct := got.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected a JSON body, got %q", ct)
+		}
+	})
+
+	t.Run("fails on statuses other than 2xx", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		status, err := NewHTTPSender().Send(context.Background(), Request{URL: srv.URL, Secret: secret, Body: body})
+
+		if status != http.StatusInternalServerError || !errors.Is(err, ErrStatus) {
+			t.Errorf("expected 500 and ErrStatus, got %d, %v", status, err)
+		}
+	})
+
+	t.Run("does not follow redirects", func(t *testing.T) {
+		followed := false
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/moved" {
+				followed = true
+				return
+			}
+			http.Redirect(w, r, "/moved", http.StatusTemporaryRedirect)
+		}))
+		defer srv.Close()
+
+		status, err := NewHTTPSender().Send(context.Background(), Request{URL: srv.URL, Secret: secret, Body: body})
+
+		if status != http.StatusTemporaryRedirect || !errors.Is(err, ErrStatus) || followed {
+			t.Errorf("expected the redirect to fail the request, got %d, %v", status, err)
+		}
+	})
+
+	t.Run("reports no status when the endpoint is unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+
+		status, err := NewHTTPSender().Send(context.Background(), Request{URL: srv.URL, Secret: secret, Body: body})
+
+		if status != 0 || err == nil {
+			t.Errorf("expected no status and an error, got %d, %v", status, err)
+		}
+	})
+}
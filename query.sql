@@ -1,36 +1,1250 @@
+-- Public pages only ever show published events; organisers find drafts
+-- and archived events by id.
 -- name: GetEvent :one
 SELECT * from events
-WHERE slug = $1 LIMIT 1;
+WHERE year = $1
+AND slug = $2
+AND status = 'published'
+AND deleted_at IS NULL
+LIMIT 1;
+
+-- Finds the editions an old slug-only event URL could mean, latest first.
+-- name: ListPublishedEventsBySlug :many
+SELECT * from events
+WHERE slug = $1
+AND status = 'published'
+AND deleted_at IS NULL
+ORDER BY year DESC;
+
+-- name: GetEventByID :one
+SELECT * from events
+WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1;
 
 -- name: ListEvents :many
 SELECT * from events
+WHERE status = 'published'
 ORDER BY name;
 
+-- Lists published events by name with totals across their live races and
+-- their registration count, for listing cards, in one round trip. Events
+-- without races have a race count and capacity of zero and no price or
+-- registration dates. Races are priced at the tier current at @now, if
+-- any, or else their own price; a race without a price is free, and the
+-- cheapest race's currency is the price's. registration_closes_at is null
+-- when any race takes entries without a close date.
+-- name: ListEventsWithStats :many
+SELECT sqlc.embed(e),
+       rs.race_count,
+       COALESCE(rs.total_capacity, 0)::bigint AS total_capacity,
+       rs.min_price_units::integer AS min_price_units,
+       rs.price_currency::text AS price_currency,
+       rs.registration_opens_at::timestamptz AS registration_opens_at,
+       rs.registration_closes_at::timestamptz AS registration_closes_at,
+       rs.races_updated_at::timestamptz AS races_updated_at,
+       rg.registered
+FROM events e
+LEFT JOIN LATERAL (
+  SELECT COUNT(*) AS race_count,
+         SUM(r.max_capacity) AS total_capacity,
+         MIN(COALESCE(tp.price_units, r.price_units, 0)) AS min_price_units,
+         (ARRAY_AGG(r.currency ORDER BY COALESCE(tp.price_units, r.price_units, 0), r.name))[1] AS price_currency,
+         MIN(r.registration_open_date) AS registration_opens_at,
+         CASE WHEN bool_and(r.registration_close_date IS NOT NULL)
+              THEN MAX(r.registration_close_date) END AS registration_closes_at,
+         MAX(r.updated_at) AS races_updated_at
+  FROM races r
+  -- A tier limited to its first entries takes precedence over one it
+  -- overlaps while it has places
+  LEFT JOIN LATERAL (
+    SELECT t.price_units
+    FROM race_price_tiers t
+    WHERE t.race_id = r.id
+    AND (t.valid_from IS NULL OR t.valid_from <= @now::timestamptz)
+    AND (t.valid_to IS NULL OR t.valid_to > @now::timestamptz)
+    AND (t.capacity IS NULL OR t.capacity > (
+      SELECT COUNT(*) FROM registrations reg
+      WHERE reg.price_tier_id = t.id
+      AND reg.status <> 'cancelled'
+      AND reg.deleted_at IS NULL
+    ))
+    ORDER BY t.capacity IS NULL, t.id
+    LIMIT 1
+  ) tp ON true
+  WHERE r.event_id = e.id
+  AND r.deleted_at IS NULL
+) rs ON true
+LEFT JOIN LATERAL (
+  SELECT COUNT(*) AS registered
+  FROM registrations reg
+  INNER JOIN races r ON r.id = reg.race_id
+  WHERE r.event_id = e.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+) rg ON true
+WHERE e.deleted_at IS NULL
+AND e.status = 'published'
+ORDER BY e.name
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListEventsPaginated :many
+SELECT * from events
+WHERE deleted_at IS NULL
+AND status = 'published'
+ORDER BY year DESC, name
+LIMIT $1 OFFSET $2;
+
+-- An event is past once registration has closed for all of its races.
+-- Events without races, or with a race that never closes, are not past.
+-- name: ListEventsByYear :many
+SELECT sqlc.embed(e), COALESCE(p.past, false)::boolean AS past
+FROM events e
+LEFT JOIN LATERAL (
+  SELECT bool_and(COALESCE(r.registration_close_date < @now, false)) AS past
+  FROM races r
+  WHERE r.event_id = e.id
+  AND r.deleted_at IS NULL
+) p ON true
+WHERE e.year = @year
+AND e.deleted_at IS NULL
+AND e.status = 'published'
+AND (@include_past::boolean OR NOT COALESCE(p.past, false))
+ORDER BY e.name;
+
+-- name: ListDistinctYears :many
+SELECT DISTINCT year FROM events
+WHERE deleted_at IS NULL
+AND status = 'published'
+ORDER BY year DESC;
+
+-- name: CountEvents :one
+SELECT COUNT(*) from events
+WHERE deleted_at IS NULL
+AND status = 'published';
+
+-- Counts the published editions ListSitemapEvents lists.
+-- name: CountSitemapEvents :one
+SELECT COUNT(*) from events e
+WHERE e.deleted_at IS NULL
+AND e.status = 'published'
+AND NOT EXISTS (
+  SELECT 1 FROM events later
+  WHERE later.series_id = e.series_id
+  AND later.year > e.year
+  AND later.status = 'published'
+  AND later.deleted_at IS NULL
+);
+
+-- Every published edition has its own page, but only the latest of a
+-- series is listed; the earlier ones are linked from it.
+-- name: ListSitemapEvents :many
+SELECT e.year, e.slug, e.updated_at from events e
+WHERE e.deleted_at IS NULL
+AND e.status = 'published'
+AND NOT EXISTS (
+  SELECT 1 FROM events later
+  WHERE later.series_id = e.series_id
+  AND later.year > e.year
+  AND later.status = 'published'
+  AND later.deleted_at IS NULL
+)
+ORDER BY e.year DESC, e.slug
+LIMIT $1 OFFSET $2;
+
+-- Lists a series' published editions, latest first, each with the names
+-- and slugs of its races with published results, so
+-- result_race_names[i] pairs with result_race_slugs[i].
+-- name: ListEventsInSeries :many
+SELECT sqlc.embed(e),
+  COALESCE(res.names, '{}')::text[] AS result_race_names,
+  COALESCE(res.slugs, '{}')::text[] AS result_race_slugs
+FROM events e
+LEFT JOIN LATERAL (
+  SELECT ARRAY_AGG(r.name ORDER BY r.name) AS names,
+         ARRAY_AGG(r.slug ORDER BY r.name) AS slugs
+  FROM races r
+  WHERE r.event_id = e.id
+  AND r.deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM race_results rr WHERE rr.race_id = r.id AND rr.published)
+) res ON true
+WHERE e.series_id = $1
+AND e.status = 'published'
+AND e.deleted_at IS NULL
+ORDER BY e.year DESC;
+
+-- Lists every event of the organisation, whatever its status, by name and
+-- then latest year first.
+-- name: ListOrganisationEvents :many
+SELECT * from events
+WHERE organisation_id = $1
+AND deleted_at IS NULL
+ORDER BY name, year DESC;
+
+-- Makes the event the first edition of its own series, unless it is
+-- already in one.
+-- name: StartEventSeries :exec
+UPDATE events
+SET series_id = id
+WHERE id = $1
+AND series_id IS NULL
+AND deleted_at IS NULL;
+
+-- name: SetEventSeries :execrows
+UPDATE events
+SET series_id = $2
+WHERE id = $1
+AND deleted_at IS NULL;
+
 
 -- name: CreateEvent :one
 INSERT INTO events (
   organisation_id,
   name,
-  slug)
-VALUES ($1, $2, $3)
+  slug,
+  year,
+  description,
+  location,
+  image_url,
+  timezone,
+  series_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING *;
 
 -- name: UpdateEvent :exec
 UPDATE events
 SET name = $2,
-    slug = $3
+    slug = $3,
+    description = $4,
+    location = $5,
+    image_url = $6
 WHERE id = $1
 RETURNING *;
 
+-- name: SetEventStatus :execrows
+UPDATE events
+SET status = $2
+WHERE id = $1
+AND deleted_at IS NULL;
+
 -- name: DeleteEvent :exec
 UPDATE events
 SET deleted_at = NOW()
 WHERE id = $1;
 
 
+-- name: ListRacesByEvent :many
+SELECT * from races
+WHERE event_id = $1
+AND deleted_at IS NULL
+ORDER BY name;
+
+-- name: ListRacesByEvents :many
+SELECT * from races
+WHERE event_id = ANY(@event_ids::bigint[])
+AND deleted_at IS NULL
+ORDER BY event_id, name;
+
+-- name: GetRaceBySlug :one
+SELECT * from races
+WHERE event_id = $1
+AND slug = $2
+AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetRaceByID :one
+SELECT * from races
+WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1;
+
+-- Locks the race row so concurrent writers cannot take it past capacity.
+-- name: LockRace :one
+SELECT max_capacity from races
+WHERE id = $1
+AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: GetRaceEventStatus :one
+SELECT e.status from races r
+INNER JOIN events e ON e.id = r.event_id
+WHERE r.id = $1;
+
+-- name: CreateRace :one
+INSERT INTO races (
+  event_id,
+  name,
+  slug,
+  registration_open_date,
+  registration_close_date,
+  starts_at,
+  max_capacity,
+  price_units,
+  currency,
+  distance_metres,
+  elevation_gain_metres)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: UpdateRaceCapacity :one
+UPDATE races
+SET max_capacity = $2,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING *;
+
+-- A NULL min_age lets entrants of any age enter.
+-- name: SetRaceMinAge :one
+UPDATE races
+SET min_age = $2,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING *;
+
+-- A NULL distance or climb is unknown.
+-- name: SetRaceDistance :one
+UPDATE races
+SET distance_metres = $2,
+    elevation_gain_metres = $3,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING *;
+
+-- Replaces any route the race already has.
+-- name: UpsertRaceRoute :exec
+INSERT INTO race_routes (race_id, gpx_gzip, point_count, distance_metres, elevation_gain_metres)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (race_id) DO UPDATE
+SET gpx_gzip = EXCLUDED.gpx_gzip,
+    point_count = EXCLUDED.point_count,
+    distance_metres = EXCLUDED.distance_metres,
+    elevation_gain_metres = EXCLUDED.elevation_gain_metres,
+    updated_at = NOW();
+
+-- name: GetRaceRoute :one
+SELECT race_id, point_count, distance_metres, elevation_gain_metres, updated_at
+FROM race_routes
+WHERE race_id = $1;
+
+-- name: GetRaceRouteGPX :one
+SELECT gpx_gzip FROM race_routes
+WHERE race_id = $1;
+
+-- name: ListRaceRoutesByEvent :many
+SELECT rr.race_id, rr.point_count, rr.distance_metres, rr.elevation_gain_metres, rr.updated_at
+FROM race_routes rr
+INNER JOIN races r ON r.id = rr.race_id
+WHERE r.event_id = $1
+AND r.deleted_at IS NULL
+ORDER BY rr.race_id;
+
+-- Waves of the race with the active registrations holding a place in each,
+-- earliest start first.
+-- name: ListRaceWaves :many
+SELECT sqlc.embed(w), COUNT(reg.id) AS taken
+FROM race_waves w
+LEFT JOIN registrations reg ON reg.wave_id = w.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+WHERE w.race_id = $1
+GROUP BY w.id
+ORDER BY w.starts_at, w.id;
+
+-- name: ListRaceWavesByEvent :many
+SELECT w.* FROM race_waves w
+INNER JOIN races r ON r.id = w.race_id
+WHERE r.event_id = $1
+AND r.deleted_at IS NULL
+ORDER BY w.race_id, w.starts_at, w.id;
+
+-- name: GetRaceWave :one
+SELECT * FROM race_waves
+WHERE id = $1
+AND race_id = $2;
+
+-- name: CreateRaceWave :one
+INSERT INTO race_waves (race_id, name, starts_at, capacity)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: UpdateRaceWave :one
+UPDATE race_waves
+SET name = $3,
+    starts_at = $4,
+    capacity = $5,
+    updated_at = NOW()
+WHERE id = $1
+AND race_id = $2
+RETURNING *;
+
+-- name: CountWaveRegistrations :one
+SELECT COUNT(*) FROM registrations
+WHERE wave_id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL;
+
+-- name: DeleteRaceWave :execrows
+DELETE FROM race_waves
+WHERE id = $1
+AND race_id = $2;
+
+-- Entrants holding a place in the wave, to be told when it moves. Anonymised
+-- entrants have no address left to write to.
+-- name: ListWaveEntrants :many
+SELECT reg.id, u.email, u.first_name
+FROM registrations reg
+INNER JOIN users u ON u.id = reg.user_id
+WHERE reg.wave_id = $1
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+AND u.anonymised_at IS NULL
+ORDER BY reg.id;
+
+-- Price tiers of the races with the active registrations made at each, by
+-- race and then earliest first.
+-- name: ListRacePriceTiers :many
+SELECT sqlc.embed(t), COUNT(reg.id) AS taken
+FROM race_price_tiers t
+LEFT JOIN registrations reg ON reg.price_tier_id = t.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+WHERE t.race_id = ANY(@race_ids::bigint[])
+GROUP BY t.id
+ORDER BY t.race_id, t.valid_from NULLS FIRST, t.valid_to NULLS LAST, t.id;
+
+-- name: CreateRacePriceTier :one
+INSERT INTO race_price_tiers (race_id, name, price_units, valid_from, valid_to, capacity)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: DeleteRacePriceTier :execrows
+DELETE FROM race_price_tiers
+WHERE id = $1
+AND race_id = $2;
+
+-- The places a tier limited to its first entries has, and how many active
+-- registrations were made at it. Run with the race locked.
+-- name: GetRacePriceTierPlaces :one
+SELECT t.capacity, COUNT(reg.id) AS taken
+FROM race_price_tiers t
+LEFT JOIN registrations reg ON reg.price_tier_id = t.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+WHERE t.id = $1
+AND t.race_id = $2
+GROUP BY t.id;
+
+-- The current definitions of the race's custom fields that have not been
+-- removed, in the order the entry form asks them.
+-- name: ListRaceCustomFields :many
+SELECT v.*
+FROM race_custom_fields f
+INNER JOIN race_custom_field_versions v ON v.field_id = f.id AND v.version = f.version
+WHERE f.race_id = $1
+AND f.deleted_at IS NULL
+ORDER BY f.position, f.id;
+
+-- Every definition the race's custom fields have had, removed fields'
+-- included, by field and then version.
+-- name: ListRaceCustomFieldVersions :many
+SELECT v.*
+FROM race_custom_field_versions v
+INNER JOIN race_custom_fields f ON f.id = v.field_id
+WHERE f.race_id = $1
+ORDER BY v.field_id, v.version;
+
+-- name: CountRaceCustomFields :one
+SELECT COUNT(*) FROM race_custom_fields
+WHERE race_id = $1
+AND deleted_at IS NULL;
+
+-- Adds the field after the race's others, at its first version.
+-- name: CreateRaceCustomField :one
+INSERT INTO race_custom_fields (race_id, position)
+VALUES (
+  @race_id,
+  (SELECT COALESCE(MAX(position), 0) + 1 FROM race_custom_fields WHERE race_id = @race_id)
+)
+RETURNING *;
+
+-- name: CreateRaceCustomFieldVersion :one
+INSERT INTO race_custom_field_versions (field_id, version, label, kind, options, required)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- Moves a field on to its next version, which the caller then adds.
+-- name: NextRaceCustomFieldVersion :one
+UPDATE race_custom_fields
+SET version = version + 1
+WHERE id = $1
+AND race_id = $2
+AND deleted_at IS NULL
+RETURNING version;
+
+-- name: DeleteRaceCustomField :execrows
+UPDATE race_custom_fields
+SET deleted_at = NOW()
+WHERE id = $1
+AND race_id = $2
+AND deleted_at IS NULL;
+
+-- name: SetRaceCustomFieldPosition :exec
+UPDATE race_custom_fields
+SET position = @position
+WHERE id = @id
+AND race_id = @race_id;
+
+-- Adds the photo after the event's others.
+-- name: CreateEventPhoto :one
+INSERT INTO event_photos (event_id, position, original_key, web_key, width, height)
+VALUES (
+  @event_id,
+  (SELECT COALESCE(MAX(position), 0) + 1 FROM event_photos WHERE event_id = @event_id),
+  @original_key, @web_key, @width, @height
+)
+RETURNING *;
+
+-- name: GetEventPhoto :one
+SELECT * FROM event_photos
+WHERE id = $1;
+
+-- name: ListEventPhotos :many
+SELECT * FROM event_photos
+WHERE event_id = $1
+ORDER BY position;
+
+-- name: DeleteEventPhoto :exec
+DELETE FROM event_photos
+WHERE id = $1;
+
+-- name: SetEventPhotoPosition :exec
+UPDATE event_photos
+SET position = @position
+WHERE id = @id
+AND event_id = @event_id;
+
+-- name: ListRaceRequiredQuestions :many
+SELECT question FROM race_required_questions
+WHERE race_id = $1
+ORDER BY question;
+
+-- name: DeleteRaceRequiredQuestions :exec
+DELETE FROM race_required_questions
+WHERE race_id = $1;
+
+-- name: AddRaceRequiredQuestions :exec
+INSERT INTO race_required_questions (race_id, question)
+SELECT @race_id, unnest(@questions::text[]);
+
+-- name: ListRaceLockedQuestions :many
+SELECT question FROM race_locked_questions
+WHERE race_id = $1
+ORDER BY question;
+
+-- name: DeleteRaceLockedQuestions :exec
+DELETE FROM race_locked_questions
+WHERE race_id = $1;
+
+-- name: AddRaceLockedQuestions :exec
+INSERT INTO race_locked_questions (race_id, question)
+SELECT @race_id, unnest(@questions::text[]);
+
+
+-- Counts the event's registrations by the day or week, in the event's time
+-- zone, they were made. Registrations outside the window count in its first
+-- or last period, so the periods add up to the event's entrants.
+-- name: CountEventRegistrationsByPeriod :many
+SELECT date_trunc(@granularity::text,
+         LEAST(GREATEST(reg.created_at, @window_from::timestamptz), @window_to::timestamptz) AT TIME ZONE @timezone::text)::date AS period,
+       COUNT(*) AS registered
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+WHERE r.event_id = @event_id
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+GROUP BY period
+ORDER BY period;
+
+-- name: CountRegistrationsByEvent :many
+SELECT r.event_id, COUNT(*) AS registered
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+WHERE r.event_id = ANY(@event_ids::bigint[])
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+GROUP BY r.event_id;
+
+-- name: CountRegistrationsByRace :one
+SELECT COUNT(*) FROM registrations
+WHERE race_id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL;
+
+-- name: CountRegistrationsByRaceForEvent :many
+SELECT reg.race_id, COUNT(*) AS registered
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+WHERE r.event_id = $1
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+GROUP BY reg.race_id;
+
+
+-- Per-event dashboard statistics for an organisation. Revenue counts
+-- confirmed online registrations at the fee they were charged, or the race
+-- price for entries made before fees were recorded, and is grouped by
+-- currency, so revenue_currencies[i] pairs with revenue_units[i].
+-- name: GetEventStats :many
+WITH race_stats AS (
+  SELECT r.event_id,
+    r.max_capacity,
+    COALESCE(r.currency, 'GBP')::text AS currency,
+    COUNT(reg.id) FILTER (WHERE reg.status <> 'cancelled') AS registrations,
+    COALESCE(SUM(COALESCE(reg.price_units, r.price_units, 0)) FILTER (WHERE reg.status = 'confirmed' AND reg.source = 'online'), 0) AS revenue_units,
+    COUNT(reg.id) FILTER (WHERE reg.status <> 'cancelled' AND reg.created_at >= NOW() - INTERVAL '7 days') AS recent
+  FROM races r
+  LEFT JOIN registrations reg ON reg.race_id = r.id AND reg.deleted_at IS NULL
+  WHERE r.deleted_at IS NULL
+  GROUP BY r.id
+),
+revenue AS (
+  SELECT event_id, currency, SUM(revenue_units)::bigint AS units
+  FROM race_stats
+  GROUP BY event_id, currency
+)
+SELECT e.id, e.name, e.slug, e.year, e.status,
+  COALESCE(SUM(rs.registrations), 0)::bigint AS registrations,
+  COALESCE(SUM(rs.max_capacity), 0)::bigint AS capacity,
+  COALESCE(SUM(rs.recent), 0)::bigint AS recent_registrations,
+  COALESCE((SELECT ARRAY_AGG(rv.currency ORDER BY rv.currency) FROM revenue rv WHERE rv.event_id = e.id), '{}')::text[] AS revenue_currencies,
+  COALESCE((SELECT ARRAY_AGG(rv.units ORDER BY rv.currency) FROM revenue rv WHERE rv.event_id = e.id), '{}')::bigint[] AS revenue_units
+FROM events e
+LEFT JOIN race_stats rs ON rs.event_id = e.id
+WHERE e.organisation_id = $1
+AND e.deleted_at IS NULL
+GROUP BY e.id
+ORDER BY e.year DESC, e.name;
+
+
+-- name: GetRegistrationForCancellation :one
+SELECT reg.id, reg.user_id, reg.race_id, reg.status, r.event_id, r.registration_close_date, e.organisation_id
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL
+LIMIT 1;
+
+-- name: HasActiveRegistration :one
+SELECT EXISTS (
+  SELECT 1 from registrations
+  WHERE user_id = $1
+  AND race_id = $2
+  AND status <> 'cancelled'
+  AND deleted_at IS NULL
+);
+
+-- name: GetActiveRegistration :one
+SELECT * from registrations
+WHERE user_id = $1
+AND race_id = $2
+AND status <> 'cancelled'
+AND deleted_at IS NULL
+LIMIT 1;
+
+-- Online entries start pending until paid for, recording the fee charged
+-- after any discount and the price tier it was charged at.
+-- name: CreateRegistration :one
+INSERT INTO registrations (user_id, race_id, price_units, discount_code_id, discount_units, wave_id, price_tier_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: CreateImportedRegistration :one
+INSERT INTO registrations (user_id, race_id, status, source, bib)
+VALUES ($1, $2, 'confirmed', 'imported', $3)
+RETURNING *;
+
+-- name: ListRegistrationsByUser :many
+SELECT reg.id, reg.status, reg.bib, reg.created_at,
+  r.name AS race_name, r.starts_at, r.registration_close_date,
+  e.name AS event_name, e.slug AS event_slug, e.year AS event_year,
+  p.status AS payment_status,
+  EXISTS (
+    SELECT 1 from registration_transfers t
+    WHERE t.registration_id = reg.id
+    AND t.to_user_id = reg.user_id
+    AND t.accepted_at IS NULL
+  ) AS transfer_pending
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+LEFT JOIN LATERAL (
+  SELECT status from payments
+  WHERE registration_id = reg.id
+  ORDER BY created_at DESC
+  LIMIT 1
+) p ON true
+WHERE reg.user_id = $1
+AND reg.deleted_at IS NULL
+ORDER BY r.starts_at NULLS LAST, reg.id;
+
+-- Entrants show as registered, or as deleted once their account has been
+-- anonymised.
+-- name: ListRaceEntrants :many
+SELECT reg.id, reg.status, reg.source, reg.bib, reg.created_at,
+  u.email, u.first_name, u.last_name, u.anonymised_at, u.date_of_birth,
+  t.name AS team_name,
+  (r.starts_at AT TIME ZONE e.timezone)::date AS race_day,
+  w.name AS wave_name, w.starts_at AS wave_starts_at,
+  reg.checked_in_at
+FROM registrations reg
+INNER JOIN users u ON u.id = reg.user_id
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+LEFT JOIN teams t ON t.id = reg.team_id
+LEFT JOIN race_waves w ON w.id = reg.wave_id
+WHERE reg.race_id = $1
+AND reg.deleted_at IS NULL
+ORDER BY reg.created_at, reg.id;
+
+-- name: CreateRegistrationAnswers :exec
+INSERT INTO registration_answers (registration_id, answers_sealed)
+VALUES ($1, $2);
+
+-- Entrants' edits replace their answers, or give the first ones for
+-- entries made before their race asked any.
+-- name: UpsertRegistrationAnswers :exec
+INSERT INTO registration_answers (registration_id, answers_sealed)
+VALUES ($1, $2)
+ON CONFLICT (registration_id) DO UPDATE
+SET answers_sealed = EXCLUDED.answers_sealed;
+
+-- name: DeleteRegistrationAnswers :exec
+DELETE FROM registration_answers
+WHERE registration_id = $1;
+
+-- name: CreateRegistrationCustomAnswers :exec
+INSERT INTO registration_custom_answers (registration_id, answers)
+VALUES ($1, $2);
+
+-- name: DeleteRegistrationCustomAnswers :exec
+DELETE FROM registration_custom_answers
+WHERE registration_id = $1;
+
+-- name: ListRaceRegistrationCustomAnswers :many
+SELECT ca.registration_id, ca.answers
+FROM registration_custom_answers ca
+INNER JOIN registrations reg ON reg.id = ca.registration_id
+WHERE reg.race_id = $1
+AND reg.deleted_at IS NULL
+ORDER BY ca.registration_id;
+
+-- name: ListRaceRegistrationAnswers :many
+SELECT ra.registration_id, ra.answers_sealed
+FROM registration_answers ra
+INNER JOIN registrations reg ON reg.id = ra.registration_id
+WHERE reg.race_id = $1
+AND reg.deleted_at IS NULL
+ORDER BY ra.registration_id;
+
+-- Active registrations in the order bibs are handed out, with any bib they
+-- already have.
+-- name: ListRaceBibs :many
+SELECT id, status, bib from registrations
+WHERE race_id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL
+ORDER BY created_at, id;
+
+-- Only confirmed registrations without a bib are numbered, so assigning
+-- again never renumbers anyone.
+-- name: AssignRegistrationBib :execrows
+UPDATE registrations
+SET bib = $2
+WHERE id = $1
+AND bib IS NULL
+AND status = 'confirmed'
+AND deleted_at IS NULL;
+
+-- name: SetRegistrationBib :execrows
+UPDATE registrations
+SET bib = $2
+WHERE id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL;
+
+-- Checking an entrant in again keeps the time they were first checked in.
+-- name: CheckInRegistration :execrows
+UPDATE registrations
+SET checked_in_at = COALESCE(checked_in_at, @checked_in_at)
+WHERE id = @id
+AND race_id = @race_id
+AND status = 'confirmed'
+AND deleted_at IS NULL;
+
+-- name: UndoRegistrationCheckIn :execrows
+UPDATE registrations
+SET checked_in_at = NULL
+WHERE id = @id
+AND race_id = @race_id
+AND deleted_at IS NULL
+AND checked_in_at > @checked_in_after;
+
+-- name: GetRegistrationCheckIn :one
+SELECT checked_in_at
+FROM registrations
+WHERE id = $1
+AND race_id = $2
+AND deleted_at IS NULL;
+
+-- Places teams in the race hold for members who have not yet joined.
+-- Released teams hold none.
+-- name: CountUnfilledTeamPlaces :one
+SELECT COALESCE(SUM(GREATEST(t.size - (
+    SELECT COUNT(*) FROM registrations reg
+    WHERE reg.team_id = t.id
+    AND reg.status <> 'cancelled'
+    AND reg.deleted_at IS NULL
+  ), 0)), 0)::bigint AS unfilled
+FROM teams t
+WHERE t.race_id = $1
+AND t.released_at IS NULL
+AND t.deleted_at IS NULL;
+
+-- name: CreateTeam :one
+INSERT INTO teams (race_id, captain_user_id, name, size, invite_code, fill_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- Team entries start pending until paid for, like other online entries.
+-- name: CreateTeamRegistration :one
+INSERT INTO registrations (user_id, race_id, team_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetTeamByInviteCode :one
+SELECT * FROM teams
+WHERE invite_code = $1
+AND deleted_at IS NULL
+LIMIT 1;
+
+-- Locks the team row so concurrent joins cannot overfill it.
+-- name: LockTeam :one
+SELECT * FROM teams
+WHERE id = $1
+AND released_at IS NULL
+AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: CountTeamMembers :one
+SELECT COUNT(*) FROM registrations
+WHERE team_id = $1
+AND status <> 'cancelled'
+AND deleted_at IS NULL;
+
+-- Teams still short of members at their fill-by time give up the places
+-- they were holding for the rest.
+-- name: ReleaseUnfilledTeams :execrows
+UPDATE teams t
+SET released_at = NOW()
+WHERE t.fill_by <= $1
+AND t.released_at IS NULL
+AND t.deleted_at IS NULL
+AND t.size > (
+  SELECT COUNT(*) FROM registrations reg
+  WHERE reg.team_id = t.id
+  AND reg.status <> 'cancelled'
+  AND reg.deleted_at IS NULL
+);
+
+-- name: CancelRegistration :execrows
+UPDATE registrations
+SET status = 'cancelled',
+    cancelled_at = NOW()
+WHERE id = $1
+AND status <> 'cancelled';
+
+-- Online entries are pending until paid for, holding a place in the race.
+-- name: ExpirePendingRegistrations :execrows
+UPDATE registrations
+SET status = 'cancelled',
+    cancelled_at = NOW()
+WHERE status = 'pending'
+AND created_at < $1
+AND deleted_at IS NULL;
+
+-- name: GetRegistrationForConfirmation :one
+SELECT reg.id, reg.race_id, r.name AS race_name, r.starts_at,
+  e.name AS event_name, e.organisation_id,
+  u.email, u.first_name, u.last_name,
+  w.name AS wave_name, w.starts_at AS wave_starts_at
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+INNER JOIN users u ON u.id = reg.user_id
+LEFT JOIN race_waves w ON w.id = reg.wave_id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetRegistrationForWebhook :one
+SELECT reg.id, reg.status, reg.bib, reg.created_at,
+  reg.race_id, r.name AS race_name, r.event_id, e.name AS event_name, e.organisation_id,
+  u.email, u.first_name, u.last_name
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+INNER JOIN users u ON u.id = reg.user_id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL;
+
+-- Marks active registrations for races starting in the window as reminded
+-- and returns them, skipping any already reminded and entrants who have
+-- opted out. A registration is only ever claimed once, however many times
+-- this runs.
+-- name: ClaimRaceReminders :many
+UPDATE registrations reg
+SET reminder_sent_at = NOW()
+FROM races r, events e, users u
+WHERE r.id = reg.race_id
+AND e.id = r.event_id
+AND u.id = reg.user_id
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL
+AND reg.reminder_sent_at IS NULL
+AND r.starts_at > @starts_after
+AND r.starts_at <= @starts_before
+AND r.deleted_at IS NULL
+AND e.deleted_at IS NULL
+AND u.deleted_at IS NULL
+AND u.email_preference = 'marketing'
+RETURNING reg.id, reg.user_id, r.name AS race_name, r.starts_at,
+  e.name AS event_name, e.slug AS event_slug,
+  u.email, u.first_name;
+
+-- name: GetRegistrationForTransfer :one
+SELECT reg.id, reg.user_id, reg.race_id, reg.status,
+  r.name AS race_name, r.registration_close_date,
+  e.name AS event_name,
+  u.first_name AS owner_first_name, u.last_name AS owner_last_name, u.email AS owner_email
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+INNER JOIN users u ON u.id = reg.user_id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetRegistrationForEdit :one
+SELECT reg.id, reg.user_id, reg.race_id, reg.status,
+  r.name AS race_name, r.starts_at,
+  e.name AS event_name,
+  ra.answers_sealed
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+LEFT JOIN registration_answers ra ON ra.registration_id = reg.id
+WHERE reg.id = $1
+AND reg.deleted_at IS NULL
+LIMIT 1;
+
+-- name: TransferRegistration :execrows
+UPDATE registrations
+SET user_id = @to_user_id
+WHERE id = @id
+AND user_id = @from_user_id
+AND status <> 'cancelled'
+AND deleted_at IS NULL;
+
+-- name: CreateRegistrationTransfer :one
+INSERT INTO registration_transfers (registration_id, from_user_id, to_user_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: AcceptRegistrationTransfers :execrows
+UPDATE registration_transfers
+SET accepted_at = NOW()
+WHERE to_user_id = $1
+AND accepted_at IS NULL;
+
+
+-- Each entrant's date of birth comes with the race's date in its event's
+-- time zone, so results can be given an age category.
+-- name: ListRaceResultEntrants :many
+SELECT reg.id, u.date_of_birth,
+  (r.starts_at AT TIME ZONE e.timezone)::date AS race_day
+FROM registrations reg
+INNER JOIN users u ON u.id = reg.user_id
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE reg.race_id = $1
+AND reg.status <> 'cancelled'
+AND reg.deleted_at IS NULL;
+
+-- name: DeleteRaceResults :exec
+DELETE FROM race_results
+WHERE race_id = $1;
+
+-- name: CreateRaceResult :exec
+INSERT INTO race_results (race_id, registration_id, name, bib, position, finish_seconds, category)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- Results name the runner as registered unless their account has no name,
+-- as for runners invited by a transfer, and fall back to the uploaded bib.
+-- name: ListRaceResults :many
+SELECT rr.id, rr.registration_id, rr.position, rr.finish_seconds, rr.category, rr.published,
+  COALESCE(NULLIF(TRIM(u.first_name || ' ' || u.last_name), ''), rr.name)::text AS name,
+  COALESCE(NULLIF(rr.bib, ''), reg.bib, '')::text AS bib
+FROM race_results rr
+LEFT JOIN registrations reg ON reg.id = rr.registration_id
+LEFT JOIN users u ON u.id = reg.user_id
+WHERE rr.race_id = $1
+ORDER BY rr.position;
+
+-- name: PublishRaceResults :execrows
+UPDATE race_results
+SET published = true
+WHERE race_id = $1
+AND NOT published;
+
+
+-- name: GetSettledPaymentByRegistration :one
+SELECT * from payments
+WHERE registration_id = $1
+AND status = 'succeeded'
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: RecordPaymentRefund :exec
+UPDATE payments
+SET status = $2,
+    refunded_units = $3,
+    refunded_at = NOW()
+WHERE id = $1;
+
+-- Records a charge reported by Stripe as a payment for its registration,
+-- or brings the payment already recorded for the charge up to date.
+-- Webhooks may be repeated or arrive out of order, so an older report
+-- never undoes a refund or forgets a fee.
+-- name: UpsertProviderPayment :one
+INSERT INTO payments (
+  registration_id, amount_units, currency, status, provider_reference,
+  fee_units, refunded_units, refunded_at, created_at
+) VALUES (
+  @registration_id, @amount_units, @currency, @status, @provider_reference,
+  sqlc.narg(fee_units), @refunded_units,
+  CASE WHEN @refunded_units::int > 0 THEN NOW() END, @created_at
+)
+ON CONFLICT (provider_reference) WHERE provider_reference IS NOT NULL DO UPDATE
+SET status = CASE WHEN excluded.refunded_units >= payments.refunded_units
+                  THEN excluded.status ELSE payments.status END,
+    fee_units = COALESCE(excluded.fee_units, payments.fee_units),
+    refunded_units = GREATEST(payments.refunded_units, excluded.refunded_units),
+    refunded_at = CASE WHEN excluded.refunded_units > payments.refunded_units
+                       THEN NOW() ELSE payments.refunded_at END
+RETURNING *;
+
+-- Lists the payments taken for the organisation's events from
+-- @taken_from up to @taken_until, oldest first, with who made them and for
+-- which race, for reconciling against Stripe's payouts.
+-- name: ListOrganisationPayments :many
+SELECT p.id, p.registration_id, p.amount_units, p.currency, p.status,
+  p.provider_reference, p.fee_units, p.refunded_units, p.created_at,
+  u.email, u.first_name, u.last_name, u.anonymised_at,
+  r.name AS race_name, e.name AS event_name
+FROM payments p
+INNER JOIN registrations reg ON reg.id = p.registration_id
+INNER JOIN users u ON u.id = reg.user_id
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE e.organisation_id = @organisation_id
+AND p.status IN ('succeeded', 'partially_refunded', 'refunded')
+AND p.created_at >= @taken_from
+AND p.created_at < @taken_until
+ORDER BY p.created_at, p.id;
+
+
 -- name: GetOrganisation :one
 SELECT * from organisations
-WHERE id = $1 LIMIT 1;
+WHERE id = $1
+AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: IsOrganisationMember :one
+SELECT EXISTS (
+  SELECT 1 from organisation_members
+  WHERE organisation_id = $1
+  AND user_id = $2
+  AND deleted_at IS NULL
+);
+
+-- name: ListOrganisations :many
+SELECT * from organisations
+WHERE deleted_at IS NULL
+ORDER BY name;
+
+-- name: ListOrganisationsForUser :many
+SELECT o.* from organisations o
+INNER JOIN organisation_members om ON om.organisation_id = o.id
+WHERE om.user_id = $1
+AND om.deleted_at IS NULL
+AND o.deleted_at IS NULL
+ORDER BY o.name;
+
+-- name: GetOrganisationMembership :one
+SELECT * from organisation_members
+WHERE user_id = $1
+AND organisation_id = $2
+AND deleted_at IS NULL
+LIMIT 1;
+
+-- Adding a removed member restores their membership with the new role; a
+-- current member is left as they are and nothing is returned.
+-- name: AddOrganisationMember :one
+INSERT INTO organisation_members (organisation_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (organisation_id, user_id) DO UPDATE
+SET role = EXCLUDED.role,
+    created_at = NOW(),
+    deleted_at = NULL
+WHERE organisation_members.deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: RemoveOrganisationMember :execrows
+UPDATE organisation_members
+SET deleted_at = NOW()
+WHERE organisation_id = $1
+AND user_id = $2
+AND deleted_at IS NULL;
+
+-- name: ListOrganisationMembers :many
+SELECT om.user_id, om.role, om.created_at,
+  u.email, u.first_name, u.last_name
+FROM organisation_members om
+INNER JOIN users u ON u.id = om.user_id
+WHERE om.organisation_id = $1
+AND om.deleted_at IS NULL
+AND u.deleted_at IS NULL
+ORDER BY om.role, u.last_name, u.first_name, u.email;
+
+-- name: TouchOrganisationMembership :exec
+UPDATE organisation_members
+SET last_used_at = NOW()
+WHERE organisation_id = $1
+AND user_id = $2
+AND deleted_at IS NULL;
+
+-- The organisation the user most recently worked in, of those they are
+-- still a member of.
+-- name: GetLastUsedOrganisationID :one
+SELECT om.organisation_id from organisation_members om
+INNER JOIN organisations o ON o.id = om.organisation_id
+WHERE om.user_id = $1
+AND om.last_used_at IS NOT NULL
+AND om.deleted_at IS NULL
+AND o.deleted_at IS NULL
+ORDER BY om.last_used_at DESC
+LIMIT 1;
+
+-- name: SetNotificationPreference :execrows
+UPDATE organisation_members
+SET notification_preference = $3
+WHERE organisation_id = $1
+AND user_id = $2
+AND deleted_at IS NULL;
+
+-- Members of the organisation who want an email for every registration.
+-- name: ListImmediateNotificationRecipients :many
+SELECT u.email, u.first_name
+FROM organisation_members om
+INNER JOIN users u ON u.id = om.user_id
+WHERE om.organisation_id = $1
+AND om.notification_preference = 'immediate'
+AND om.deleted_at IS NULL
+AND u.deleted_at IS NULL
+AND u.deactivated_at IS NULL
+ORDER BY u.email;
+
+-- Marks the digest for day as sent to every member who wants one and has
+-- not had it, and returns them. A member is only ever claimed once for a
+-- day, however many times this runs.
+-- name: ClaimRegistrationDigests :many
+UPDATE organisation_members om
+SET digest_sent_for = @day::date
+FROM organisations o, users u
+WHERE o.id = om.organisation_id
+AND u.id = om.user_id
+AND om.notification_preference = 'daily_digest'
+AND (om.digest_sent_for IS NULL OR om.digest_sent_for < @day::date)
+AND om.deleted_at IS NULL
+AND o.deleted_at IS NULL
+AND u.deleted_at IS NULL
+AND u.deactivated_at IS NULL
+RETURNING om.organisation_id, o.name AS organisation_name,
+  u.email, u.first_name;
+
+-- Registrations made in [created_after, created_before) for each of the
+-- organisation's events that had any, by currency. Revenue counts
+-- confirmed online registrations as GetEventStats does.
+-- name: GetRegistrationDigestStats :many
+SELECT e.id AS event_id, e.name AS event_name, e.year,
+  COALESCE(r.currency, 'GBP')::text AS currency,
+  COUNT(reg.id) FILTER (WHERE reg.status <> 'cancelled') AS registrations,
+  COALESCE(SUM(COALESCE(reg.price_units, r.price_units, 0)) FILTER (WHERE reg.status = 'confirmed' AND reg.source = 'online'), 0)::bigint AS revenue_units
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE e.organisation_id = @organisation_id
+AND reg.created_at >= @created_after
+AND reg.created_at < @created_before
+AND reg.deleted_at IS NULL
+AND r.deleted_at IS NULL
+AND e.deleted_at IS NULL
+GROUP BY e.id, COALESCE(r.currency, 'GBP')
+ORDER BY e.name, e.id, currency;
+
+-- name: CreateOrganisationInvitation :one
+INSERT INTO organisation_invitations (organisation_id, user_id, role, invited_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListPendingOrganisationInvitations :many
+SELECT i.id, i.user_id, i.role, i.created_at, u.email
+FROM organisation_invitations i
+INNER JOIN users u ON u.id = i.user_id
+WHERE i.organisation_id = $1
+AND i.accepted_at IS NULL
+ORDER BY i.created_at, i.id;
+
+-- Accepting makes the invitee a member of each organisation they were
+-- invited to, in the role of the latest invitation. Current members keep
+-- the role they have.
+-- name: AcceptOrganisationInvitations :execrows
+WITH accepted AS (
+  UPDATE organisation_invitations
+  SET accepted_at = NOW()
+  WHERE user_id = $1
+  AND accepted_at IS NULL
+  RETURNING organisation_id, user_id, role, created_at
+)
+INSERT INTO organisation_members (organisation_id, user_id, role)
+SELECT DISTINCT ON (organisation_id) organisation_id, user_id, role
+FROM accepted
+ORDER BY organisation_id, created_at DESC
+ON CONFLICT (organisation_id, user_id) DO UPDATE
+SET role = EXCLUDED.role,
+    created_at = NOW(),
+    deleted_at = NULL
+WHERE organisation_members.deleted_at IS NOT NULL;
+
+-- name: PromoteEntrantToOrganizer :exec
+UPDATE users
+SET role = 'organizer'
+WHERE id = $1
+AND role = 'entrant';
 
 -- name: CreateOrganisation :one
 INSERT INTO organisations (
@@ -45,6 +1259,27 @@ WHERE id = $1
 RETURNING *;
 
 
+-- name: SetOrganisationContactEmail :execrows
+UPDATE organisations
+SET contact_email = $2
+WHERE id = $1
+AND deleted_at IS NULL;
+
+-- name: SetOrganisationEmbedding :execrows
+UPDATE organisations
+SET embed_public = $2,
+    embed_origins = $3
+WHERE id = $1
+AND deleted_at IS NULL;
+
+-- name: SetOrganisationBranding :execrows
+UPDATE organisations
+SET brand_colour = $2,
+    logo_key = $3,
+    banner_key = $4
+WHERE id = $1
+AND deleted_at IS NULL;
+
 -- name: DeleteOrganisation :exec
 UPDATE organisations
 SET deleted_at = NOW()
@@ -67,8 +1302,9 @@ INSERT INTO users (
   state,
   postal_code,
   country,
-  role)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+  role,
+  date_of_birth)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
 -- name: UpdateUser :exec
@@ -93,6 +1329,129 @@ SET deleted_at = NOW()
 WHERE id = $1;
 
 
+-- Anonymising keeps the user, and the registrations that point at it, but
+-- removes everything that identifies them. The email becomes a tombstone on
+-- the reserved .invalid domain, freeing the address for a new account.
+-- name: AnonymiseUser :execrows
+UPDATE users
+SET email = 'deleted-' || id || '@anonymised.invalid',
+    first_name = '',
+    last_name = '',
+    phone = NULL,
+    address_line1 = NULL,
+    address_line2 = NULL,
+    city = NULL,
+    state = NULL,
+    postal_code = NULL,
+    country = NULL,
+    date_of_birth = NULL,
+    role = 'entrant',
+    anonymised_at = NOW(),
+    deleted_at = NOW()
+WHERE id = $1
+AND anonymised_at IS NULL;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET first_name = $2,
+    last_name = $3,
+    date_of_birth = $4,
+    distance_unit = $5
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING *;
+
+-- The entrant's date of birth and the race's date in its event's time
+-- zone, to check the entrant is old enough to enter.
+-- name: GetEntrantAgeCheck :one
+SELECT u.date_of_birth,
+  (r.starts_at AT TIME ZONE e.timezone)::date AS race_day
+FROM users u
+CROSS JOIN races r
+INNER JOIN events e ON e.id = r.event_id
+WHERE u.id = @user_id
+AND r.id = @race_id;
+
+-- name: IsEmailVerified :one
+SELECT EXISTS (
+  SELECT 1 FROM auth_credentials
+  WHERE user_id = $1
+  AND email_verified_at IS NOT NULL
+  AND deleted_at IS NULL
+);
+
+-- name: SetEmailPreference :execrows
+UPDATE users
+SET email_preference = $2
+WHERE id = $1
+AND deleted_at IS NULL;
+
+-- name: SetUserRole :execrows
+UPDATE users
+SET role = $2
+WHERE id = $1
+AND deleted_at IS NULL;
+
+-- name: DeactivateUser :one
+UPDATE users
+SET deactivated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+AND deactivated_at IS NULL
+RETURNING deactivated_at;
+
+-- name: ReactivateUser :execrows
+UPDATE users
+SET deactivated_at = NULL
+WHERE id = $1
+AND deleted_at IS NULL
+AND deactivated_at IS NOT NULL;
+
+-- Lists users, newest first, with how their sign-ins are going, for the
+-- admin users page. A non-empty search is an ILIKE pattern matched against
+-- the email and the full name; a non-NULL role keeps users with that role.
+-- name: ListUsers :many
+SELECT u.id, u.email, u.first_name, u.last_name, u.role, u.deactivated_at, u.created_at,
+       ac.failed_login_attempts, ac.locked_until, ac.last_login_at
+FROM users u
+LEFT JOIN auth_credentials ac ON ac.user_id = u.id AND ac.deleted_at IS NULL
+WHERE u.deleted_at IS NULL
+AND (@search::text = '' OR u.email ILIKE @search OR u.first_name || ' ' || u.last_name ILIKE @search)
+AND (sqlc.narg(role)::user_role IS NULL OR u.role = sqlc.narg(role))
+ORDER BY u.created_at DESC, u.id DESC
+LIMIT @max_results OFFSET @skip;
+
+-- Counts the users ListUsers pages through.
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users u
+WHERE u.deleted_at IS NULL
+AND (@search::text = '' OR u.email ILIKE @search OR u.first_name || ' ' || u.last_name ILIKE @search)
+AND (sqlc.narg(role)::user_role IS NULL OR u.role = sqlc.narg(role));
+
+-- name: DeleteUserCredentials :exec
+DELETE FROM auth_credentials
+WHERE user_id = $1;
+
+-- name: DeleteUserVerificationTokens :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1;
+
+-- name: DeleteUserSocialAccounts :exec
+DELETE FROM social_accounts
+WHERE user_id = $1;
+
+-- name: RemoveUserMemberships :exec
+UPDATE organisation_members
+SET deleted_at = NOW()
+WHERE user_id = $1
+AND deleted_at IS NULL;
+
+-- name: DeletePendingInvitationsForUser :exec
+DELETE FROM organisation_invitations
+WHERE user_id = $1
+AND accepted_at IS NULL;
+
+
 -- Auth Credentials Queries
 
 -- name: CreateAuthCredentials :one
@@ -139,6 +1498,19 @@ UPDATE auth_credentials
 SET locked_until = $2
 WHERE user_id = $1;
 
+-- name: UnlockExpiredAccounts :execrows
+UPDATE auth_credentials
+SET locked_until = NULL,
+    failed_login_attempts = 0
+WHERE locked_until <= NOW();
+
+-- name: UnlockAccount :execrows
+UPDATE auth_credentials
+SET locked_until = NULL,
+    failed_login_attempts = 0
+WHERE user_id = $1
+AND deleted_at IS NULL;
+
 -- name: IsAccountLocked :one
 SELECT
     CASE
@@ -153,3 +1525,440 @@ WHERE user_id = $1;
 UPDATE auth_credentials
 SET email_verified_at = NOW()
 WHERE user_id = $1;
+
+-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (
+    user_id,
+    token_hash,
+    expires_at
+) VALUES ($1, $2, $3);
+
+-- name: ConsumeEmailVerificationToken :one
+UPDATE email_verification_tokens
+SET used_at = NOW()
+WHERE token_hash = $1
+AND used_at IS NULL
+AND expires_at > NOW()
+RETURNING user_id;
+
+
+-- Login Event Queries
+
+-- name: CreateLoginEvent :exec
+INSERT INTO login_events (user_id, kind, ip_address, user_agent, alerted, created_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- Reports whether the user has ever signed in, as far as the events kept
+-- go back, and whether they have signed in from the device since the given
+-- time.
+-- name: GetSignInHistory :one
+SELECT
+    COUNT(*) > 0 AS signed_in_before,
+    COALESCE(BOOL_OR(
+        ip_address = sqlc.arg('ip_address')
+        AND user_agent = sqlc.arg('user_agent')
+        AND created_at >= sqlc.arg('since')
+    ), FALSE)::boolean AS seen_device
+FROM login_events
+WHERE user_id = sqlc.arg('user_id')
+AND kind = 'signed_in';
+
+-- name: LoginAlertSentSince :one
+SELECT EXISTS (
+    SELECT 1 FROM login_events
+    WHERE user_id = $1
+    AND kind = $2
+    AND alerted
+    AND created_at > $3
+);
+
+-- name: DeleteLoginEventsBefore :execrows
+DELETE FROM login_events
+WHERE created_at < $1;
+
+-- name: DeleteUserLoginEvents :exec
+DELETE FROM login_events
+WHERE user_id = $1;
+
+
+-- API Token Queries
+
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (user_id, label, token_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- Lists the tokens the user has not revoked, newest first.
+-- name: ListAPITokensForUser :many
+SELECT * FROM api_tokens
+WHERE user_id = $1
+AND revoked_at IS NULL
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetAPITokenByHash :one
+SELECT * FROM api_tokens
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: TouchAPIToken :exec
+UPDATE api_tokens
+SET last_used_at = NOW()
+WHERE id = $1;
+
+-- name: RevokeAPIToken :execrows
+UPDATE api_tokens
+SET revoked_at = NOW()
+WHERE id = $1
+AND user_id = $2
+AND revoked_at IS NULL;
+
+-- name: DeleteUserAPITokens :exec
+DELETE FROM api_tokens
+WHERE user_id = $1;
+
+
+-- User Session Queries
+
+-- name: CreateUserSession :one
+INSERT INTO user_sessions (user_id, user_agent, ip_address, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetUserSession :one
+SELECT * FROM user_sessions
+WHERE id = $1
+LIMIT 1;
+
+-- Lists the user's sessions that have neither been revoked nor expired,
+-- most recently active first.
+-- name: ListUserSessionsForUser :many
+SELECT * FROM user_sessions
+WHERE user_id = $1
+AND revoked_at IS NULL
+AND expires_at > NOW()
+ORDER BY last_seen_at DESC, id DESC;
+
+-- name: TouchUserSession :exec
+UPDATE user_sessions
+SET last_seen_at = NOW()
+WHERE id = $1;
+
+-- name: RevokeUserSession :execrows
+UPDATE user_sessions
+SET revoked_at = NOW()
+WHERE id = $1
+AND user_id = $2
+AND revoked_at IS NULL;
+
+-- Revokes every session of the user's but the one given.
+-- name: RevokeOtherUserSessions :execrows
+UPDATE user_sessions
+SET revoked_at = NOW()
+WHERE user_id = $1
+AND id <> $2
+AND revoked_at IS NULL;
+
+-- Locks the user's row, so their sign-ins are counted against the session
+-- limit one at a time.
+-- name: LockUserSessions :exec
+SELECT id FROM users
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CountActiveUserSessions :one
+SELECT COUNT(*) FROM user_sessions
+WHERE user_id = $1
+AND revoked_at IS NULL
+AND expires_at > NOW();
+
+-- Revokes, as evicted, the user's sessions still in use but the keep most
+-- recently active.
+-- name: EvictUserSessions :execrows
+UPDATE user_sessions
+SET revoked_at = NOW(),
+    evicted_at = NOW()
+WHERE id IN (
+    SELECT id FROM user_sessions
+    WHERE user_id = $1
+    AND revoked_at IS NULL
+    AND expires_at > NOW()
+    ORDER BY last_seen_at DESC, id DESC
+    OFFSET sqlc.arg('keep')
+);
+
+-- name: DeleteUserSessions :exec
+DELETE FROM user_sessions
+WHERE user_id = $1;
+
+-- Ends every session the user has, cookie sessions included, which are
+-- refused once their version is behind the user's.
+-- name: BumpUserSessionVersion :exec
+UPDATE users
+SET session_version = session_version + 1
+WHERE id = $1;
+
+-- name: DeleteUserRegistrationAnswers :exec
+DELETE FROM registration_answers
+WHERE registration_id IN (SELECT id FROM registrations WHERE user_id = $1);
+
+-- name: DeleteUserRegistrationCustomAnswers :exec
+DELETE FROM registration_custom_answers
+WHERE registration_id IN (SELECT id FROM registrations WHERE user_id = $1);
+
+
+-- name: CreateDiscountCode :one
+INSERT INTO discount_codes (event_id, race_id, code, percent_off, amount_off_units, max_uses, valid_from, valid_until)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- Lists the codes matching code, ignoring case, in every event.
+-- name: ListDiscountCodesByCode :many
+SELECT * FROM discount_codes
+WHERE LOWER(code) = LOWER(@code::text)
+AND deleted_at IS NULL
+ORDER BY id;
+
+-- name: ListDiscountCodesByEvent :many
+SELECT * FROM discount_codes
+WHERE event_id = $1
+AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC;
+
+-- Counts an entry against the code unless it has no uses left. Entries
+-- using the code at once queue on its row, so no more than max_uses of
+-- them succeed.
+-- name: UseDiscountCode :execrows
+UPDATE discount_codes
+SET uses = uses + 1
+WHERE id = $1
+AND (max_uses IS NULL OR uses < max_uses)
+AND deleted_at IS NULL;
+
+
+-- Audit Log Queries
+
+-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log (table_name, record_id, action, user_id, changed_fields)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListAuditLogForRecord :many
+SELECT * FROM audit_log
+WHERE table_name = $1
+AND record_id = $2
+ORDER BY created_at DESC, id DESC;
+
+-- Webhook Queries
+
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (organisation_id, url, secret_sealed, event_types)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListWebhookEndpoints :many
+SELECT * FROM webhook_endpoints
+WHERE organisation_id = $1
+ORDER BY created_at, id;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoints
+WHERE id = $1
+AND organisation_id = $2;
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints
+WHERE id = $1
+AND organisation_id = $2;
+
+-- Queues the payload for every endpoint of the organisation subscribed to
+-- the event type.
+-- name: EnqueueWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries (endpoint_id, event_type, payload)
+SELECT we.id, @event_type::text, @payload::json
+FROM webhook_endpoints we
+WHERE we.organisation_id = @organisation_id
+AND @event_type::text = ANY(we.event_types);
+
+-- Leases the pending deliveries that are due to the caller until
+-- lease_until, oldest first, skipping any another server has locked, and
+-- returns them with where to send them.
+-- name: ClaimWebhookDeliveries :many
+WITH due AS (
+  SELECT id FROM webhook_deliveries
+  WHERE status = 'pending'
+  AND next_attempt_at <= @now
+  ORDER BY next_attempt_at, id
+  LIMIT @max_deliveries
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_deliveries d
+SET next_attempt_at = @lease_until
+FROM due, webhook_endpoints we
+WHERE d.id = due.id
+AND we.id = d.endpoint_id
+RETURNING d.id, d.event_type, d.payload, d.attempts, we.url, we.secret_sealed;
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (delivery_id, attempted_at, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = $2, attempts = $3, next_attempt_at = $4
+WHERE id = $1;
+
+-- Lists the endpoint's deliveries, newest first.
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE endpoint_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT * FROM webhook_delivery_attempts
+WHERE delivery_id = ANY(@delivery_ids::bigint[])
+ORDER BY attempted_at, id;
+
+-- name: CreateEventEnquiry :one
+INSERT INTO event_enquiries (event_id, name, email, message)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListEventEnquiries :many
+SELECT * FROM event_enquiries
+WHERE event_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+-- Starts a copy of the user's data, or returns the request already
+-- waiting to be built.
+-- name: RequestDataExport :one
+INSERT INTO data_exports (user_id)
+VALUES ($1)
+ON CONFLICT (user_id) WHERE status = 'pending'
+DO UPDATE SET user_id = EXCLUDED.user_id
+RETURNING *;
+
+-- name: GetLatestDataExport :one
+SELECT * FROM data_exports
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- Leases the pending requests nobody is building to the caller until
+-- lease_until, oldest first, skipping any another server has locked.
+-- name: ClaimDataExports :many
+WITH due AS (
+  SELECT id FROM data_exports
+  WHERE status = 'pending'
+  AND (lease_until IS NULL OR lease_until <= @now)
+  ORDER BY id
+  LIMIT @max_exports
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE data_exports d
+SET lease_until = @lease_until
+FROM due
+WHERE d.id = due.id
+RETURNING d.*;
+
+-- name: CompleteDataExport :execrows
+UPDATE data_exports
+SET status = 'ready', storage_key = $2, token_hash = $3, expires_at = $4,
+  completed_at = $5, lease_until = NULL
+WHERE id = $1
+AND status = 'pending';
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', completed_at = $2, lease_until = NULL
+WHERE id = $1
+AND status = 'pending';
+
+-- Marks the export the token is for downloaded, provided it is ready, has
+-- not been downloaded and has not expired.
+-- name: ConsumeDataExport :one
+UPDATE data_exports
+SET downloaded_at = @now
+WHERE token_hash = @token_hash
+AND status = 'ready'
+AND downloaded_at IS NULL
+AND expires_at > @now
+RETURNING *;
+
+-- Lists exports whose archives are still stored but have expired or were
+-- downloaded before downloaded_before.
+-- name: ListSpentDataExports :many
+SELECT * FROM data_exports
+WHERE storage_key IS NOT NULL
+AND (expires_at <= @now OR downloaded_at <= @downloaded_before)
+ORDER BY id
+LIMIT @max_exports;
+
+-- name: ClearDataExportStorageKey :exec
+UPDATE data_exports
+SET storage_key = NULL
+WHERE id = $1;
+
+-- Ends the user's exports: pending ones fail, and ready ones expire so
+-- their archives are removed.
+-- name: ExpireUserDataExports :exec
+UPDATE data_exports
+SET status = CASE WHEN status = 'pending' THEN 'failed' ELSE status END,
+  token_hash = NULL,
+  expires_at = NOW(),
+  lease_until = NULL
+WHERE user_id = $1;
+
+-- Pages through the user's registrations by id, cancelled ones included,
+-- for a copy of their data.
+-- name: ExportUserRegistrations :many
+SELECT reg.id, reg.status, reg.source, reg.bib, reg.price_units, reg.discount_units,
+  reg.cancelled_at, reg.checked_in_at, reg.created_at,
+  r.name AS race_name, e.name AS event_name, e.year AS event_year
+FROM registrations reg
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE reg.user_id = @user_id
+AND reg.id > @after_id
+ORDER BY reg.id
+LIMIT @max_rows;
+
+-- name: ExportUserRegistrationAnswers :many
+SELECT ra.registration_id, ra.answers_sealed, ra.created_at,
+  r.name AS race_name, e.name AS event_name, e.year AS event_year
+FROM registration_answers ra
+INNER JOIN registrations reg ON reg.id = ra.registration_id
+INNER JOIN races r ON r.id = reg.race_id
+INNER JOIN events e ON e.id = r.event_id
+WHERE reg.user_id = @user_id
+AND ra.registration_id > @after_id
+ORDER BY ra.registration_id
+LIMIT @max_rows;
+
+-- name: ExportUserPayments :many
+SELECT p.id, p.registration_id, p.amount_units, p.currency, p.status,
+  p.provider_reference, p.refunded_units, p.refunded_at, p.created_at, p.updated_at,
+  p.fee_units
+FROM payments p
+INNER JOIN registrations reg ON reg.id = p.registration_id
+WHERE reg.user_id = @user_id
+AND p.id > @after_id
+ORDER BY p.id
+LIMIT @max_rows;
+
+-- Pages through every session the user has signed in to, revoked and
+-- expired ones included.
+-- name: ExportUserSessions :many
+SELECT * FROM user_sessions
+WHERE user_id = @user_id
+AND id > @after_id
+ORDER BY id
+LIMIT @max_rows;
+
+-- Pages through the audit entries for changes the user made.
+-- name: ExportUserAuditEntries :many
+SELECT * FROM audit_log
+WHERE user_id = @user_id::bigint
+AND id > @after_id
+ORDER BY id
+LIMIT @max_rows;
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// staticPrefix is the URL path the static files are served under.
+const staticPrefix = "/static/"
+
+// Asset describes an embedded static file.
+type Asset struct {
+	// Name is the file's path below static/, such as "js/event-form.js".
+	Name string
+	// Hash is a hex digest of the file's content.
+	Hash string
+	// Fingerprinted reports whether the file was requested by its
+	// fingerprinted name, whose content can never change.
+	Fingerprinted bool
+}
+
+// manifest maps each static file to its fingerprinted name and back.
+type manifest struct {
+	hashes       map[string]string // name -> content hash
+	originals    map[string]string // fingerprinted name -> name
+	fingerprints map[string]string // name -> fingerprinted name
+	version      string
+}
+
+var assets = mustBuildManifest(Files)
+
+// AssetPath returns the URL of the named static file with its content hash
+// in the filename, so "main.css" becomes "/static/main.1a2b3c4d5e.css".
+// Unknown names are returned unfingerprinted.
+func AssetPath(name string) string {
+	if fingerprinted, ok := assets.fingerprints[name]; ok {
+		return staticPrefix + fingerprinted
+	}
+	return staticPrefix + name
+}
+
+// AssetsVersion identifies the current set of static files, changing
+// whenever any of them does. Pages that link to fingerprinted assets include
+// it in their validators so a cached page never points at a removed file.
+func AssetsVersion() string {
+	return assets.version
+}
+
+// LookupAsset resolves a path below static/, fingerprinted or not, to the
+// embedded file it names.
+func LookupAsset(name string) (Asset, bool) {
+	if original, ok := assets.originals[name]; ok {
+		return Asset{Name: original, Hash: assets.hashes[original], Fingerprinted: true}, true
+	}
+	if hash, ok := assets.hashes[name]; ok {
+		return Asset{Name: name, Hash: hash}, true
+	}
+	return Asset{}, false
+}
+
+func mustBuildManifest(fsys fs.FS) manifest {
+	m, err := buildManifest(fsys)
+	if err != nil {
+		panic("ui: failed to fingerprint static files: " + err.Error())
+	}
+	return m
+}
+
+// buildManifest hashes every file under static/ in fsys.
+func buildManifest(fsys fs.FS) (manifest, error) {
+	m := manifest{
+		hashes:       make(map[string]string),
+		originals:    make(map[string]string),
+		fingerprints: make(map[string]string),
+	}
+
+	err := fs.WalkDir(fsys, "static", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:5])
+		name := strings.TrimPrefix(p, "static/")
+		fingerprinted := fingerprint(name, hash)
+
+		m.hashes[name] = hash
+		m.originals[fingerprinted] = name
+		m.fingerprints[name] = fingerprinted
+		return nil
+	})
+	if err != nil {
+		return manifest{}, err
+	}
+
+	h := sha256.New()
+	for _, name := range slices.Sorted(maps.Keys(m.hashes)) {
+		h.Write([]byte(name + "\x00" + m.hashes[name] + "\x00"))
+	}
+	m.version = hex.EncodeToString(h.Sum(nil)[:5])
+	return m, nil
+}
+
+// fingerprint inserts hash before the file extension of name.
+func fingerprint(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
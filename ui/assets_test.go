@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestBuildManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/main.css":         {Data: []byte("body{}")},
+		"static/js/event-form.js": {Data: []byte("console.log(1)")},
+	}
+
+	m, err := buildManifest(fsys)
+	if err != nil {
+		t.Fatalf("failed to build manifest: %v", err)
+	}
+
+	fingerprinted := m.fingerprints["js/event-form.js"]
+	hash := m.hashes["js/event-form.js"]
+	if want := "js/event-form." + hash + ".js"; fingerprinted != want {
+		t.Errorf("expected %q, got %q", want, fingerprinted)
+	}
+	if m.originals[fingerprinted] != "js/event-form.js" {
+		t.Errorf("expected %q to map back to its original name", fingerprinted)
+	}
+
+	fsys["static/main.css"] = &fstest.MapFile{Data: []byte("body{color:red}")}
+	changed, err := buildManifest(fsys)
+	if err != nil {
+		t.Fatalf("failed to build manifest: %v", err)
+	}
+	if changed.fingerprints["main.css"] == m.fingerprints["main.css"] {
+		t.Error("expected a new fingerprint when the content changes")
+	}
+	if changed.version == m.version {
+		t.Error("expected a new version when a file changes")
+	}
+}
+
+func TestLookupAsset(t *testing.T) {
+	path := AssetPath("main.css")
+	name := path[len(staticPrefix):]
+
+	asset, ok := LookupAsset(name)
+	if !ok || asset.Name != "main.css" || !asset.Fingerprinted {
+		t.Errorf("expected fingerprinted main.css, got %+v (ok %v)", asset, ok)
+	}
+
+	asset, ok = LookupAsset("main.css")
+	if !ok || asset.Fingerprinted {
+		t.Errorf("expected plain main.css, got %+v (ok %v)", asset, ok)
+	}
+
+	if _, ok := LookupAsset("missing.css"); ok {
+		t.Error("expected an unknown asset not to be found")
+	}
+	if got := AssetPath("missing.css"); got != "/static/missing.css" {
+		t.Errorf("expected an unknown asset to keep its name, got %q", got)
+	}
+}
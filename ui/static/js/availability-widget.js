@@ -0,0 +1,53 @@
+// Shows how many places are left in an event's races on an organiser's own
+// website. Each element with data-firecrest-availability="EVENT-SLUG" is
+// filled with a list of the event's races, read from the availability API
+// of the site this script was loaded from. The organisation must allow the
+// website to read it, on its members page.
+(function () {
+  const script = document.currentScript;
+  if (!script) return;
+  const site = new URL(script.src).origin;
+
+  const label = (race) => {
+    switch (race.state) {
+      case "not-yet-open":
+        return "Entries not yet open";
+      case "sold-out":
+        return "Sold out";
+      case "closed":
+        return "Entries closed";
+      default: {
+        const left = Math.max(race.capacity - race.registered, 0);
+        return left === 1 ? "1 place left" : `${left} places left`;
+      }
+    }
+  };
+
+  const show = async (el) => {
+    const slug = el.dataset.firecrestAvailability;
+    const res = await fetch(`${site}/api/v1/events/${encodeURIComponent(slug)}/availability`);
+    if (!res.ok) return;
+    const { data } = await res.json();
+
+    const list = document.createElement("ul");
+    list.className = "firecrest-availability";
+    for (const race of data) {
+      const item = document.createElement("li");
+      item.dataset.state = race.state;
+      item.textContent = `${race.name}: ${label(race)}`;
+      list.append(item);
+    }
+    el.replaceChildren(list);
+  };
+
+  const start = () => {
+    for (const el of document.querySelectorAll("[data-firecrest-availability]")) {
+      show(el).catch(() => {});
+    }
+  };
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", start);
+  } else {
+    start();
+  }
+})();
@@ -0,0 +1,40 @@
+// Keeps the colour field and picker in step and shows the colour on the
+// preview as it is chosen. The server checks the colour and picks the text
+// colour the same way in viewmodels.BrandingViewModel, so this is purely a
+// convenience.
+(function () {
+  const form = document.querySelector("[data-branding-form]");
+  const preview = document.querySelector("[data-branding-preview]");
+  if (!form || !preview) return;
+
+  const colour = form.querySelector("[data-colour]");
+  const picker = form.querySelector("[data-colour-picker]");
+  const hex = /^#[0-9a-f]{6}$/;
+
+  // Black on light colours and white on dark ones, by relative luminance
+  const foreground = (value) => {
+    const lum = [0.2126, 0.7152, 0.0722].reduce(
+      (sum, weight, i) => sum + (weight * parseInt(value.slice(1 + 2 * i, 3 + 2 * i), 16)) / 255,
+      0,
+    );
+    return lum > 0.5 ? "#000000" : "#ffffff";
+  };
+
+  const update = () => {
+    const value = colour.value.trim().toLowerCase();
+    if (!hex.test(value)) {
+      preview.style.removeProperty("--color-primary");
+      preview.style.removeProperty("--color-primary-foreground");
+      return;
+    }
+    picker.value = value;
+    preview.style.setProperty("--color-primary", value);
+    preview.style.setProperty("--color-primary-foreground", foreground(value));
+  };
+
+  colour.addEventListener("input", update);
+  picker.addEventListener("input", () => {
+    colour.value = picker.value;
+    update();
+  });
+})();
@@ -0,0 +1,48 @@
+// Lets organisers drag a race's custom fields into a new order. Dropping a
+// field posts every field's ID, in the order shown, to the race's field
+// order form, which saves it and reloads the page. The move up and down
+// buttons do the same one place at a time without JavaScript.
+(function () {
+  const list = document.querySelector("[data-custom-fields]");
+  const form = document.querySelector("[data-custom-field-order-form]");
+  if (!list || !form) return;
+
+  let dragged = null;
+  let before = "";
+
+  const order = () => [...list.querySelectorAll("[data-custom-field]")].map((item) => item.dataset.customField);
+
+  list.addEventListener("dragstart", (e) => {
+    dragged = e.target.closest("[data-custom-field]");
+    if (!dragged) return;
+    before = order().join();
+    e.dataTransfer.effectAllowed = "move";
+    dragged.classList.add("opacity-50");
+  });
+
+  list.addEventListener("dragover", (e) => {
+    const over = e.target.closest("[data-custom-field]");
+    if (!dragged || !over || over === dragged) return;
+    e.preventDefault();
+    const { top, height } = over.getBoundingClientRect();
+    over.parentNode.insertBefore(dragged, e.clientY < top + height / 2 ? over : over.nextSibling);
+  });
+
+  list.addEventListener("dragend", () => {
+    if (!dragged) return;
+    dragged.classList.remove("opacity-50");
+    dragged = null;
+    const ids = order();
+    if (ids.join() === before) return;
+
+    const inputs = ids.map((id) => {
+      const input = document.createElement("input");
+      input.type = "hidden";
+      input.name = "field_id";
+      input.value = id;
+      return input;
+    });
+    form.replaceChildren(...inputs);
+    form.submit();
+  });
+})();
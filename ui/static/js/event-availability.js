@@ -0,0 +1,52 @@
+// Keeps an event page's capacity figures current while it is open, from
+// the availability stream named by data-availability-stream. Each element
+// with data-availability shows one figure, written with the printf-style
+// format in its data-format so it stays in the page's language; race cards
+// are found by data-race.
+(function () {
+  const root = document.querySelector("[data-availability-stream]");
+  if (!root || !window.EventSource) return;
+
+  const format = (fmt, ...values) => {
+    let i = 0;
+    return fmt.replace(/%[d%]/g, (m) => (m === "%%" ? "%" : String(values[i++])));
+  };
+  const write = (el, ...values) => {
+    if (el && el.dataset.format) el.textContent = format(el.dataset.format, ...values);
+  };
+
+  const show = ({ capacity, registered, races }) => {
+    const percent = capacity > 0 ? Math.floor((registered * 100) / capacity) : 0;
+    for (const el of root.querySelectorAll("[data-availability]")) {
+      switch (el.dataset.availability) {
+        case "registered":
+          write(el, registered, capacity);
+          break;
+        case "remaining":
+          write(el, Math.max(capacity - registered, 0));
+          break;
+        case "percent":
+          write(el, percent);
+          break;
+        case "bar":
+          el.style.width = `${percent}%`;
+          break;
+      }
+    }
+
+    for (const race of races) {
+      const card = root.querySelector(`[data-race="${CSS.escape(race.slug)}"]`);
+      if (!card) continue;
+      card.dataset.raceState = race.state;
+      write(card.querySelector("[data-race-spots]"), race.registered, race.capacity);
+      // A race that fills while the page is open stops taking entries; one
+      // that reopens is offered again when the page is reloaded
+      const register = card.querySelector("[data-race-register]");
+      if (register && race.state !== "open") register.disabled = true;
+    }
+  };
+
+  // EventSource reconnects by itself, as after the server restarts
+  const source = new EventSource(root.dataset.availabilityStream);
+  source.addEventListener("availability", (e) => show(JSON.parse(e.data)));
+})();
@@ -0,0 +1,52 @@
+// Keeps the slug field and URL preview in step with the event name and year
+// until the organiser edits the slug by hand, and counts the characters in the
+// description. The server applies the same rules via service.Slugify and
+// the event input limits, so this is purely a convenience.
+(function () {
+  const form = document.querySelector("[data-event-form]");
+  if (!form) return;
+
+  const name = form.querySelector("[data-slug-from]");
+  const slug = form.querySelector("[data-slug-into]");
+  const year = form.querySelector("[data-slug-year]");
+  const preview = form.querySelector("[data-slug-preview]");
+  let touched = slug.value !== "";
+
+  const slugify = (value) =>
+    value
+      .toLowerCase()
+      .normalize("NFKD")
+      .replace(/[\u0300-\u036f]/g, "")
+      .replace(/['’]/g, "")
+      .replace(/&/g, " and ")
+      .replace(/[^a-z0-9]+/g, "-")
+      .replace(/^-+|-+$/g, "");
+
+  const update = () => {
+    preview.textContent =
+      "/events/" + (year.value || "…") + "/" + (slug.value || slugify(name.value) || "…");
+  };
+
+  name.addEventListener("input", () => {
+    if (!touched) slug.value = slugify(name.value);
+    update();
+  });
+
+  year.addEventListener("input", update);
+
+  slug.addEventListener("input", () => {
+    touched = slug.value !== "";
+    update();
+  });
+
+  form.querySelectorAll("[data-char-count-from]").forEach((field) => {
+    const count = form.querySelector(`[data-char-count="${field.dataset.charCountFrom}"]`);
+    if (!count) return;
+    // Count code points, as the server does, rather than UTF-16 units
+    const updateCount = () => {
+      count.textContent = [...field.value].length;
+    };
+    field.addEventListener("input", updateCount);
+    updateCount();
+  });
+})();
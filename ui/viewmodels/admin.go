@@ -0,0 +1,841 @@
+package viewmodels
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+// OrganisationOption represents an organisation in a select input
+type OrganisationOption struct {
+	ID   int64
+	Name string
+}
+
+// EventFormViewModel holds the submitted values and validation errors for the admin event form
+type EventFormViewModel struct {
+	OrganisationID int64
+	Name           string
+	Year           string
+	Slug           string
+	Description    string
+	Location       string
+	ImageURL       string
+	Timezone       string
+	Organisations  []OrganisationOption
+	Errors         map[string]string
+}
+
+// DescriptionLength returns the number of characters in the description
+func (f EventFormViewModel) DescriptionLength() string {
+	return strconv.Itoa(utf8.RuneCountInString(f.Description))
+}
+
+// PreviewURL returns the public URL the event will be served from
+func (f EventFormViewModel) PreviewURL() string {
+	year, slug := f.Year, f.Slug
+	if year == "" {
+		year = "…"
+	}
+	if slug == "" {
+		slug = "…"
+	}
+	return "/events/" + year + "/" + slug
+}
+
+// IsSelected reports whether the given organisation is the current selection
+func (f EventFormViewModel) IsSelected(id int64) bool {
+	return f.OrganisationID == id
+}
+
+// Error returns the validation error for a field, if any
+func (f EventFormViewModel) Error(field string) string {
+	return f.Errors[field]
+}
+
+// Value returns the option value for an organisation
+func (o OrganisationOption) Value() string {
+	return strconv.FormatInt(o.ID, 10)
+}
+
+// NewOrganisationOptions converts organisations into select options
+func NewOrganisationOptions(orgs []db.Organisation) []OrganisationOption {
+	options := make([]OrganisationOption, 0, len(orgs))
+	for _, org := range orgs {
+		options = append(options, OrganisationOption{ID: org.ID, Name: org.Name})
+	}
+	return options
+}
+
+// DuplicateEventViewModel holds the confirmation form for copying an event into another year
+type DuplicateEventViewModel struct {
+	EventID int64
+	Name    string
+	Year    int32
+	NewYear string
+	Errors  map[string]string
+}
+
+// NewDuplicateEventViewModel prepares the form, defaulting to the following year
+func NewDuplicateEventViewModel(event db.Event) DuplicateEventViewModel {
+	return DuplicateEventViewModel{
+		EventID: event.ID,
+		Name:    event.Name,
+		Year:    event.Year,
+		NewYear: strconv.Itoa(int(event.Year) + 1),
+		Errors:  make(map[string]string),
+	}
+}
+
+// ActionURL returns the URL the form posts to
+func (f DuplicateEventViewModel) ActionURL() string {
+	return "/admin/events/" + strconv.FormatInt(f.EventID, 10) + "/duplicate"
+}
+
+// Error returns the validation error for a field, if any
+func (f DuplicateEventViewModel) Error(field string) string {
+	return f.Errors[field]
+}
+
+// EventSeriesViewModel holds the form for linking an event to the other
+// editions of its series
+type EventSeriesViewModel struct {
+	EventID int64
+	Name    string
+	Year    int32
+	// Editions are the other events in the event's series, by name and then
+	// latest year first
+	Editions []EventOption
+	// Options are the organisation's events the event could join the
+	// series of
+	Options       []EventOption
+	SeriesEventID string
+	Errors        map[string]string
+}
+
+// EventOption represents an event in a list or select input
+type EventOption struct {
+	ID   int64
+	Name string
+	Year int32
+}
+
+// Value returns the option value for an event
+func (o EventOption) Value() string {
+	return strconv.FormatInt(o.ID, 10)
+}
+
+// Label names the event's edition, e.g. "Lincoln 10K 2026"
+func (o EventOption) Label() string {
+	return o.Name + " " + strconv.Itoa(int(o.Year))
+}
+
+// NewEventSeriesViewModel prepares the form from the event and every event
+// its organisation runs.
+func NewEventSeriesViewModel(event db.Event, events []db.Event) EventSeriesViewModel {
+	vm := EventSeriesViewModel{
+		EventID: event.ID,
+		Name:    event.Name,
+		Year:    event.Year,
+		Errors:  make(map[string]string),
+	}
+	for _, e := range events {
+		if e.ID == event.ID {
+			continue
+		}
+		option := EventOption{ID: e.ID, Name: e.Name, Year: e.Year}
+		if event.SeriesID.Valid && e.SeriesID == event.SeriesID {
+			vm.Editions = append(vm.Editions, option)
+		} else {
+			vm.Options = append(vm.Options, option)
+		}
+	}
+	return vm
+}
+
+// InSeries reports whether the event is linked to any other
+func (f EventSeriesViewModel) InSeries() bool {
+	return len(f.Editions) > 0
+}
+
+// ActionURL returns the URL the form posts to
+func (f EventSeriesViewModel) ActionURL() string {
+	return "/admin/events/" + strconv.FormatInt(f.EventID, 10) + "/series"
+}
+
+// Error returns the validation error for a field, if any
+func (f EventSeriesViewModel) Error(field string) string {
+	return f.Errors[field]
+}
+
+// EditRaceViewModel holds the form for changing a race's capacity
+type EditRaceViewModel struct {
+	RaceID    int64
+	RaceName  string
+	EventName string
+	Capacity  int32
+	// Registered is how many active entries the race has
+	Registered  int
+	NewCapacity string
+	// ConfirmReduction is set when the organiser must confirm lowering the
+	// capacity to NewCapacity before it is saved
+	ConfirmReduction bool
+	Errors           map[string]string
+	// Route is set when the race has a route
+	Route *RouteViewModel
+	// MaxRouteMB is the largest route file accepted
+	MaxRouteMB int
+	// RequiredQuestions are the questionnaire questions the race requires
+	// entrants to answer
+	RequiredQuestions []string
+	// LockedQuestions are the questions entrants may no longer change
+	// themselves once their entry is paid for
+	LockedQuestions []string
+	// MinAge is the youngest entrants may be on race day, blank if any age
+	// may enter
+	MinAge string
+	// Distance is the race's length typed in DistanceUnit, blank if unknown,
+	// and ElevationGain its climb in metres, blank if unknown
+	Distance      string
+	DistanceUnit  db.DistanceUnit
+	ElevationGain string
+	// Waves are the race's start waves, earliest first, and NewWave the
+	// form adding another
+	Waves   []WaveForm
+	NewWave WaveForm
+	// Price is the race's own price, charged outside every price tier
+	Price string
+	// PriceTiers are the race's price tiers, earliest first, and
+	// NewPriceTier the form adding another
+	PriceTiers   []PriceTierRow
+	NewPriceTier PriceTierForm
+	// CustomFields are the organisers' own questions on the entry form, in
+	// the order asked, and NewCustomField the form adding another
+	CustomFields    []CustomFieldForm
+	NewCustomField  CustomFieldForm
+	MaxCustomFields int
+
+	length Distance
+}
+
+// NewEditRaceViewModel prepares the form, filled in with the race's capacity
+func NewEditRaceViewModel(race db.Race, event db.Event, registered int) EditRaceViewModel {
+	vm := EditRaceViewModel{
+		RaceID:       race.ID,
+		RaceName:     race.Name,
+		EventName:    event.Name,
+		Capacity:     race.MaxCapacity,
+		Registered:   registered,
+		NewCapacity:  strconv.Itoa(int(race.MaxCapacity)),
+		MinAge:       minAgeLabel(race.MinAge),
+		Errors:       make(map[string]string),
+		NewWave:      WaveForm{RaceID: race.ID},
+		Price:        priceLabel(raceFee(race), Money.Format),
+		NewPriceTier: PriceTierForm{RaceID: race.ID},
+		NewCustomField: CustomFieldForm{
+			RaceID: race.ID,
+			Kind:   string(db.CustomFieldKindText),
+		},
+	}
+	if race.DistanceMetres.Valid {
+		vm.length = Distance(race.DistanceMetres.Int32)
+	}
+	if race.ElevationGainMetres.Valid {
+		vm.ElevationGain = strconv.Itoa(int(race.ElevationGainMetres.Int32))
+	}
+	vm.SetDistanceUnit(db.DistanceUnitMiles)
+	return vm
+}
+
+// SetDistanceUnit fills in the race's distance in unit, the organiser's
+// preference
+func (f *EditRaceViewModel) SetDistanceUnit(unit db.DistanceUnit) {
+	f.DistanceUnit = unit
+	f.Distance = ""
+	if f.length == 0 {
+		return
+	}
+	if unit == db.DistanceUnitKm {
+		f.Distance = formatTenths(float64(f.length) / 1000)
+	} else {
+		f.Distance = formatTenths(float64(f.length) / metresPerMile)
+	}
+}
+
+// PriceTierRow is one of a race's price tiers as its edit page lists it
+type PriceTierRow struct {
+	RaceID int64
+	ID     int64
+	Name   string
+	Price  string
+	// From and To are when the tier starts and stops pricing entries in the
+	// event's time zone, blank when open
+	From string
+	To   string
+	// Places says how many of a capped tier's entries are taken, e.g.
+	// "12 of 100", blank when the tier takes any number
+	Places string
+}
+
+// NewPriceTierRow prepares a price tier of race for listing, taken of its
+// entries made, showing its dates in loc
+func NewPriceTierRow(race db.Race, tier db.RacePriceTier, taken int, loc *time.Location) PriceTierRow {
+	row := PriceTierRow{
+		RaceID: race.ID,
+		ID:     tier.ID,
+		Name:   tier.Name,
+		Price:  priceLabel(Money{Units: int64(tier.PriceUnits), Currency: RaceCurrency(race)}, Money.Format),
+	}
+	if tier.ValidFrom.Valid {
+		row.From = tier.ValidFrom.Time.In(loc).Format(priceTierTimeLayout)
+	}
+	if tier.ValidTo.Valid {
+		row.To = tier.ValidTo.Time.In(loc).Format(priceTierTimeLayout)
+	}
+	if tier.Capacity.Valid {
+		row.Places = fmt.Sprintf("%d of %d", taken, tier.Capacity.Int32)
+	}
+	return row
+}
+
+// priceTierTimeLayout shows when a price tier starts or stops
+const priceTierTimeLayout = "2 Jan 2006 15:04"
+
+// DeleteURL returns the URL that deletes the tier
+func (t PriceTierRow) DeleteURL() string {
+	return PriceTiersURL(t.RaceID) + "/" + strconv.FormatInt(t.ID, 10) + "/delete"
+}
+
+// PriceTierForm is the form adding a price tier to a race. ValidFrom and
+// ValidTo are in the event's time zone, in DiscountTimeLayout.
+type PriceTierForm struct {
+	RaceID    int64
+	Name      string
+	Price     string
+	ValidFrom string
+	ValidTo   string
+	Capacity  string
+	Errors    map[string]string
+}
+
+// PriceTiersURL returns the URL a race's price tiers are added at
+func PriceTiersURL(raceID int64) string {
+	return "/admin/races/" + strconv.FormatInt(raceID, 10) + "/prices"
+}
+
+// ActionURL returns the URL the form posts to
+func (f PriceTierForm) ActionURL() string {
+	return PriceTiersURL(f.RaceID)
+}
+
+// Error returns the validation error for a field, if any
+func (f PriceTierForm) Error(field string) string {
+	return f.Errors[field]
+}
+
+// WaveForm is the form changing one of a race's start waves, or adding one
+// when ID is zero
+type WaveForm struct {
+	RaceID int64
+	ID     int64
+	Name   string
+	// StartsAt is the wave's start in the event's time zone, in
+	// DiscountTimeLayout
+	StartsAt string
+	Capacity string
+	// Taken is how many entrants hold a place in the wave
+	Taken  int64
+	Errors map[string]string
+}
+
+// NewWaveForms prepares a form for each of the race's start waves, showing
+// their starts in loc
+func NewWaveForms(raceID int64, waves []db.ListRaceWavesRow, loc *time.Location) []WaveForm {
+	forms := make([]WaveForm, len(waves))
+	for i, w := range waves {
+		forms[i] = WaveForm{
+			RaceID:   raceID,
+			ID:       w.RaceWave.ID,
+			Name:     w.RaceWave.Name,
+			StartsAt: w.RaceWave.StartsAt.Time.In(loc).Format(DiscountTimeLayout),
+			Capacity: strconv.Itoa(int(w.RaceWave.Capacity)),
+			Taken:    w.Taken,
+		}
+	}
+	return forms
+}
+
+// ActionURL returns the URL the form posts to
+func (w WaveForm) ActionURL() string {
+	url := "/admin/races/" + strconv.FormatInt(w.RaceID, 10) + "/waves"
+	if w.ID != 0 {
+		url += "/" + strconv.FormatInt(w.ID, 10)
+	}
+	return url
+}
+
+// DeleteURL returns the URL that deletes the wave
+func (w WaveForm) DeleteURL() string {
+	return w.ActionURL() + "/delete"
+}
+
+// FieldID returns a page-unique id for one of the form's inputs
+func (w WaveForm) FieldID(name string) string {
+	if w.ID == 0 {
+		return "wave-new-" + name
+	}
+	return "wave-" + strconv.FormatInt(w.ID, 10) + "-" + name
+}
+
+// Error returns the validation error for a field, if any
+func (w WaveForm) Error(field string) string {
+	return w.Errors[field]
+}
+
+// CustomFieldKindOption is a kind of custom field organisers can choose
+type CustomFieldKindOption struct {
+	Value db.CustomFieldKind
+	Label string
+}
+
+// CustomFieldKindOptions lists the kinds of custom field, the default first
+var CustomFieldKindOptions = []CustomFieldKindOption{
+	{Value: db.CustomFieldKindText, Label: "Text"},
+	{Value: db.CustomFieldKindSelect, Label: "Dropdown"},
+	{Value: db.CustomFieldKindCheckbox, Label: "Tick box"},
+}
+
+// CustomFieldForm is the form changing one of a race's custom fields, or
+// adding one when ID is zero
+type CustomFieldForm struct {
+	RaceID int64
+	ID     int64
+	Label  string
+	Kind   string
+	// Options are a select field's choices, one per line
+	Options  string
+	Required bool
+	// First and Last are set on the fields that cannot move further up or
+	// down the form
+	First  bool
+	Last   bool
+	Errors map[string]string
+}
+
+// ActionURL returns the URL the form posts to
+func (f CustomFieldForm) ActionURL() string {
+	url := "/admin/races/" + strconv.FormatInt(f.RaceID, 10) + "/fields"
+	if f.ID != 0 {
+		url += "/" + strconv.FormatInt(f.ID, 10)
+	}
+	return url
+}
+
+// DeleteURL returns the URL that removes the field
+func (f CustomFieldForm) DeleteURL() string {
+	return f.ActionURL() + "/delete"
+}
+
+// MoveURL returns the URL that moves the field up or down the form
+func (f CustomFieldForm) MoveURL() string {
+	return f.ActionURL() + "/move"
+}
+
+// FieldID returns a page-unique id for one of the form's inputs
+func (f CustomFieldForm) FieldID(name string) string {
+	if f.ID == 0 {
+		return "custom-field-new-" + name
+	}
+	return "custom-field-" + strconv.FormatInt(f.ID, 10) + "-" + name
+}
+
+// Error returns the validation error for a field, if any
+func (f CustomFieldForm) Error(field string) string {
+	return f.Errors[field]
+}
+
+// CustomFieldOrderURL returns the URL the custom fields' new order is
+// posted to
+func (f EditRaceViewModel) CustomFieldOrderURL() string {
+	return "/admin/races/" + strconv.FormatInt(f.RaceID, 10) + "/fields/order"
+}
+
+// CanAddCustomField reports whether the race has room for another custom
+// field
+func (f EditRaceViewModel) CanAddCustomField() bool {
+	return len(f.CustomFields) < f.MaxCustomFields
+}
+
+// minAgeLabel shows a race's minimum age, or "" if it has none
+func minAgeLabel(minAge pgtype.Int4) string {
+	if !minAge.Valid {
+		return ""
+	}
+	return strconv.Itoa(int(minAge.Int32))
+}
+
+// EditRaceURL returns the URL of a race's edit form
+func EditRaceURL(raceID int64) string {
+	return "/admin/races/" + strconv.FormatInt(raceID, 10) + "/edit"
+}
+
+// ActionURL returns the URL the form posts to
+func (f EditRaceViewModel) ActionURL() string {
+	return EditRaceURL(f.RaceID)
+}
+
+// RouteActionURL returns the URL the route upload form posts to
+func (f EditRaceViewModel) RouteActionURL() string {
+	return "/admin/races/" + strconv.FormatInt(f.RaceID, 10) + "/route"
+}
+
+// MinAgeActionURL returns the URL the minimum age form posts to
+func (f EditRaceViewModel) MinAgeActionURL() string {
+	return "/admin/races/" + strconv.FormatInt(f.RaceID, 10) + "/min-age"
+}
+
+// DistanceActionURL returns the URL the distance form posts to
+func (f EditRaceViewModel) DistanceActionURL() string {
+	return "/admin/races/" + strconv.FormatInt(f.RaceID, 10) + "/distance"
+}
+
+// QuestionsActionURL returns the URL the questionnaire form posts to
+func (f EditRaceViewModel) QuestionsActionURL() string {
+	return "/admin/races/" + strconv.FormatInt(f.RaceID, 10) + "/questions"
+}
+
+// Requires reports whether the race requires entrants to answer the question
+func (f EditRaceViewModel) Requires(question string) bool {
+	return slices.Contains(f.RequiredQuestions, question)
+}
+
+// Locks reports whether entrants may no longer change their answer to the
+// question once their entry is paid for
+func (f EditRaceViewModel) Locks(question string) bool {
+	return slices.Contains(f.LockedQuestions, question)
+}
+
+// EntrantsURL returns the URL of the race's entrant list
+func (f EditRaceViewModel) EntrantsURL() string {
+	return EntrantsURL(f.RaceID)
+}
+
+// Error returns the validation error for a field, if any
+func (f EditRaceViewModel) Error(field string) string {
+	return f.Errors[field]
+}
+
+// ImportEntrantsViewModel holds the entrant import form and the report of the last upload
+type ImportEntrantsViewModel struct {
+	RaceID    int64
+	RaceName  string
+	EventName string
+	MaxRows   int
+	Error     string
+	Report    *ImportReportViewModel
+}
+
+// ImportReportViewModel summarises the outcome of an entrant import
+type ImportReportViewModel struct {
+	Imported bool
+	Created  int
+	Skipped  int
+	Failed   int
+	Rows     []ImportRowViewModel
+}
+
+// ImportRowViewModel is the outcome of one row of an entrant import
+type ImportRowViewModel struct {
+	Line    int
+	Email   string
+	Status  string
+	Message string
+}
+
+// NewImportEntrantsViewModel prepares the import form for a race
+func NewImportEntrantsViewModel(race db.Race, event db.Event, maxRows int) ImportEntrantsViewModel {
+	return ImportEntrantsViewModel{
+		RaceID:    race.ID,
+		RaceName:  race.Name,
+		EventName: event.Name,
+		MaxRows:   maxRows,
+	}
+}
+
+// ActionURL returns the URL the form posts to
+func (f ImportEntrantsViewModel) ActionURL() string {
+	return "/admin/races/" + strconv.FormatInt(f.RaceID, 10) + "/entrants/import"
+}
+
+// EntrantsURL returns the URL of the race's entrant list
+func (f ImportEntrantsViewModel) EntrantsURL() string {
+	return "/admin/races/" + strconv.FormatInt(f.RaceID, 10) + "/entrants"
+}
+
+// StatusLabel returns a human-readable outcome for the row
+func (r ImportRowViewModel) StatusLabel() string {
+	switch r.Status {
+	case "created":
+		return "Created"
+	case "skipped-duplicate":
+		return "Skipped (duplicate)"
+	default:
+		return "Error"
+	}
+}
+
+// DeletedUserName stands in for the name of an entrant who has deleted their
+// account
+const DeletedUserName = "Deleted user"
+
+// EntrantsViewModel lists a race's entrants for its organisers
+type EntrantsViewModel struct {
+	RaceID    int64
+	RaceName  string
+	EventName string
+	Entrants  []EntrantViewModel
+}
+
+// EntrantViewModel is one registration in a race's entrant list
+type EntrantViewModel struct {
+	ID    int64
+	Name  string
+	Email string
+	Bib   string
+	// Team is the name of the team the entrant entered with, if any
+	Team string
+	// Wave and WaveStart name the start wave the entrant is in, if any,
+	// and when it starts
+	Wave      string
+	WaveStart string
+	// Category is the entrant's age category on race day, if known
+	Category string
+	Status   db.RegistrationStatus
+	Imported bool
+	// Deleted reports whether the entrant has since deleted their account,
+	// leaving the registration without their details
+	Deleted bool
+	// CheckedInAt is when the entrant was checked in on race day, if they
+	// have been
+	CheckedInAt string
+}
+
+// NewEntrantsViewModel prepares the entrant list for a race. categories
+// holds the age categories of the entrants, keyed by registration ID.
+func NewEntrantsViewModel(race db.Race, event db.Event, rows []db.ListRaceEntrantsRow, categories map[int64]string) EntrantsViewModel {
+	vm := EntrantsViewModel{
+		RaceID:    race.ID,
+		RaceName:  race.Name,
+		EventName: event.Name,
+		Entrants:  make([]EntrantViewModel, 0, len(rows)),
+	}
+	for _, row := range rows {
+		entrant := EntrantViewModel{
+			ID:       row.ID,
+			Bib:      row.Bib.String,
+			Team:     row.TeamName.String,
+			Category: categories[row.ID],
+			Status:   row.Status,
+			Imported: row.Source == db.RegistrationSourceImported,
+			Deleted:  row.AnonymisedAt.Valid,
+			Wave:     row.WaveName.String,
+		}
+		if row.WaveStartsAt.Valid {
+			entrant.WaveStart = row.WaveStartsAt.Time.Format("2006-01-02 15:04")
+		}
+		if row.CheckedInAt.Valid {
+			entrant.CheckedInAt = row.CheckedInAt.Time.Format("2006-01-02 15:04")
+		}
+		entrant.Name = entrantName(row)
+		if !entrant.Deleted {
+			entrant.Email = row.Email
+		}
+		vm.Entrants = append(vm.Entrants, entrant)
+	}
+	return vm
+}
+
+// entrantName returns the name an entrant is listed under: their full
+// name, or their email if they gave none, or DeletedUserName once their
+// account has been deleted.
+func entrantName(row db.ListRaceEntrantsRow) string {
+	return listedName(row.FirstName, row.LastName, row.Email, row.AnonymisedAt.Valid)
+}
+
+// listedName is entrantName for the parts of a name.
+func listedName(firstName, lastName, email string, anonymised bool) string {
+	if anonymised {
+		return DeletedUserName
+	}
+	if name := strings.TrimSpace(firstName + " " + lastName); name != "" {
+		return name
+	}
+	return email
+}
+
+// EntrantsURL returns the URL of a race's entrant list
+func EntrantsURL(raceID int64) string {
+	return "/admin/races/" + strconv.FormatInt(raceID, 10) + "/entrants"
+}
+
+// ImportURL returns the URL of the race's entrant import form
+func (vm EntrantsViewModel) ImportURL() string {
+	return EntrantsURL(vm.RaceID) + "/import"
+}
+
+// ExportURL returns the URL the race's entrant list downloads from in the
+// format, "csv", "json" or "xlsx"
+func (vm EntrantsViewModel) ExportURL(format string) string {
+	return EntrantsURL(vm.RaceID) + "/export?format=" + format
+}
+
+// CheckInURL returns the URL of the race's check-in page
+func (vm EntrantsViewModel) CheckInURL() string {
+	return CheckInURL(vm.RaceID)
+}
+
+// EditURL returns the URL of the race's edit form
+func (vm EntrantsViewModel) EditURL() string {
+	return EditRaceURL(vm.RaceID)
+}
+
+// AssignBibsURL returns the URL the race's entrants are numbered through
+func (vm EntrantsViewModel) AssignBibsURL() string {
+	return "/admin/races/" + strconv.FormatInt(vm.RaceID, 10) + "/bibs"
+}
+
+// SetBibURL returns the URL the entrant's bib is changed through
+func (vm EntrantsViewModel) SetBibURL(e EntrantViewModel) string {
+	return EntrantsURL(vm.RaceID) + "/" + strconv.FormatInt(e.ID, 10) + "/bib"
+}
+
+// StatusLabel returns the registration status for display
+func (e EntrantViewModel) StatusLabel() string {
+	return registrationStatusLabel(e.Status)
+}
+
+// CanSetBib reports whether the entrant may be given a bib; cancelled
+// entrants give theirs up
+func (e EntrantViewModel) CanSetBib() bool {
+	return e.Status != db.RegistrationStatusCancelled
+}
+
+// CheckInViewModel is the race-day check-in page, where marshals find
+// confirmed entrants by name or bib and check them in
+type CheckInViewModel struct {
+	RaceID    int64
+	RaceName  string
+	EventName string
+	// Query is the search the entrants were filtered by
+	Query string
+	// CheckedIn and Total count the race's confirmed entrants, whether or
+	// not they match the search
+	CheckedIn int
+	Total     int
+	Entrants  []CheckInEntrantViewModel
+}
+
+// CheckInEntrantViewModel is one confirmed entrant on the check-in page
+type CheckInEntrantViewModel struct {
+	ID          int64
+	Name        string
+	Bib         string
+	Team        string
+	CheckedIn   bool
+	CheckedInAt string
+	// CanUndo reports whether the check-in is recent enough to be undone
+	CanUndo bool
+}
+
+// NewCheckInViewModel prepares the check-in page for a race, listing the
+// confirmed entrants whose name or bib matches query. Check-ins made after
+// undoAfter may still be undone.
+func NewCheckInViewModel(race db.Race, event db.Event, rows []db.ListRaceEntrantsRow, query string, undoAfter time.Time) CheckInViewModel {
+	vm := CheckInViewModel{
+		RaceID:    race.ID,
+		RaceName:  race.Name,
+		EventName: event.Name,
+		Query:     strings.TrimSpace(query),
+		Entrants:  []CheckInEntrantViewModel{},
+	}
+	for _, row := range rows {
+		if row.Status != db.RegistrationStatusConfirmed {
+			continue
+		}
+		vm.Total++
+		if row.CheckedInAt.Valid {
+			vm.CheckedIn++
+		}
+
+		entrant := CheckInEntrantViewModel{
+			ID:        row.ID,
+			Name:      entrantName(row),
+			Bib:       row.Bib.String,
+			Team:      row.TeamName.String,
+			CheckedIn: row.CheckedInAt.Valid,
+		}
+		if !matchesCheckInSearch(entrant, vm.Query) {
+			continue
+		}
+		if entrant.CheckedIn {
+			entrant.CheckedInAt = row.CheckedInAt.Time.Format("15:04")
+			entrant.CanUndo = row.CheckedInAt.Time.After(undoAfter)
+		}
+		vm.Entrants = append(vm.Entrants, entrant)
+	}
+	slices.SortStableFunc(vm.Entrants, func(a, b CheckInEntrantViewModel) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return vm
+}
+
+// matchesCheckInSearch reports whether the entrant's bib starts with query,
+// or their name contains it, ignoring case. Every entrant matches an empty
+// query.
+func matchesCheckInSearch(e CheckInEntrantViewModel, query string) bool {
+	if query == "" {
+		return true
+	}
+	if e.Bib != "" && strings.HasPrefix(e.Bib, query) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(e.Name), strings.ToLower(query))
+}
+
+// CheckInURL returns the URL of a race's check-in page
+func CheckInURL(raceID int64) string {
+	return "/admin/races/" + strconv.FormatInt(raceID, 10) + "/checkin"
+}
+
+// URL returns the URL of the check-in page, keeping the search
+func (vm CheckInViewModel) URL() string {
+	if vm.Query == "" {
+		return CheckInURL(vm.RaceID)
+	}
+	return CheckInURL(vm.RaceID) + "?q=" + url.QueryEscape(vm.Query)
+}
+
+// EntrantsURL returns the URL of the race's entrant list
+func (vm CheckInViewModel) EntrantsURL() string {
+	return EntrantsURL(vm.RaceID)
+}
+
+// CheckInEntrantURL returns the URL the entrant is checked in, or their
+// check-in undone, through
+func (vm CheckInViewModel) CheckInEntrantURL(e CheckInEntrantViewModel) string {
+	return CheckInURL(vm.RaceID) + "/" + strconv.FormatInt(e.ID, 10)
+}
+
+// Progress returns the count of entrants checked in, such as "12 of 40
+// checked in"
+func (vm CheckInViewModel) Progress() string {
+	return strconv.Itoa(vm.CheckedIn) + " of " + strconv.Itoa(vm.Total) + " checked in"
+}
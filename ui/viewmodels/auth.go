@@ -0,0 +1,67 @@
+package viewmodels
+
+import (
+	"cmp"
+	"time"
+
+	"firecrest/db"
+)
+
+// SignUpFormViewModel holds the submitted values and validation errors for
+// the sign-up form. The password is never shown again.
+type SignUpFormViewModel struct {
+	FirstName   string
+	LastName    string
+	Email       string
+	DateOfBirth string
+	// DistanceUnit is the unit the user reads race distances in
+	DistanceUnit db.DistanceUnit
+	Errors       map[string]string
+}
+
+// Error returns the validation error for a field, if any
+func (f SignUpFormViewModel) Error(field string) string {
+	return f.Errors[field]
+}
+
+// DeleteAccountViewModel holds the account deletion confirmation form
+type DeleteAccountViewModel struct {
+	Errors map[string]string
+}
+
+// Error returns the validation error for a field, if any
+func (f DeleteAccountViewModel) Error(field string) string {
+	return f.Errors[field]
+}
+
+// ProfileViewModel holds the form a user edits their own details with
+type ProfileViewModel struct {
+	FirstName string
+	LastName  string
+	Email     string
+	// DateOfBirth is written as a date input's value, YYYY-MM-DD
+	DateOfBirth string
+	// DistanceUnit is the unit the user reads race distances in
+	DistanceUnit db.DistanceUnit
+	Errors       map[string]string
+}
+
+// NewProfileViewModel fills in the form with the user's details
+func NewProfileViewModel(user db.User) ProfileViewModel {
+	vm := ProfileViewModel{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		// Users saved before the unit existed read miles
+		DistanceUnit: cmp.Or(user.DistanceUnit, db.DistanceUnitMiles),
+	}
+	if user.DateOfBirth.Valid {
+		vm.DateOfBirth = user.DateOfBirth.Time.Format(time.DateOnly)
+	}
+	return vm
+}
+
+// Error returns the validation error for a field, if any
+func (f ProfileViewModel) Error(field string) string {
+	return f.Errors[field]
+}
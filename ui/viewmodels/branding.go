@@ -0,0 +1,126 @@
+package viewmodels
+
+import (
+	"regexp"
+	"strconv"
+
+	"firecrest/db"
+)
+
+// brandColour matches the colours branding may set. They are checked when
+// saved too, but as the colour is written into a style attribute, anything
+// else is ignored here rather than trusted.
+var brandColour = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// BrandingViewModel holds an organisation's look for its event pages. Each
+// part is empty when unset, leaving the site's own.
+type BrandingViewModel struct {
+	Colour    string
+	LogoURL   string
+	BannerURL string
+}
+
+// NewBrandingViewModel builds the branding of the organisation's pages
+func NewBrandingViewModel(org db.Organisation) BrandingViewModel {
+	b := BrandingViewModel{}
+	if brandColour.MatchString(org.BrandColour.String) {
+		b.Colour = org.BrandColour.String
+	}
+	if org.LogoKey.Valid {
+		b.LogoURL = OrganisationLogoURL(org.ID)
+	}
+	if org.BannerKey.Valid {
+		b.BannerURL = OrganisationBannerURL(org.ID)
+	}
+	return b
+}
+
+// Style returns the style attribute that gives the page the brand colour,
+// with text on it in black or white, whichever reads better. It is empty
+// without a colour.
+func (b BrandingViewModel) Style() string {
+	if !brandColour.MatchString(b.Colour) {
+		return ""
+	}
+	return "--color-primary: " + b.Colour + "; --color-primary-foreground: " + foregroundFor(b.Colour) + ";"
+}
+
+// foregroundFor returns black for light colours and white for dark ones,
+// judged by their relative luminance
+func foregroundFor(colour string) string {
+	var lum float64
+	for i, weight := range []float64{0.2126, 0.7152, 0.0722} {
+		c, _ := strconv.ParseUint(colour[1+2*i:3+2*i], 16, 8)
+		lum += weight * float64(c) / 255
+	}
+	if lum > 0.5 {
+		return "#000000"
+	}
+	return "#ffffff"
+}
+
+// OrganisationLogoURL returns the public URL of the organisation's logo
+func OrganisationLogoURL(organisationID int64) string {
+	return "/organisations/" + strconv.FormatInt(organisationID, 10) + "/logo"
+}
+
+// OrganisationBannerURL returns the public URL of the organisation's banner
+func OrganisationBannerURL(organisationID int64) string {
+	return "/organisations/" + strconv.FormatInt(organisationID, 10) + "/banner"
+}
+
+// BrandingFormViewModel holds the organisation's branding settings, with a
+// preview of how event pages will look
+type BrandingFormViewModel struct {
+	OrganisationID   int64
+	OrganisationName string
+	Colour           string
+	Branding         BrandingViewModel
+	MaxImageMB       int
+	Errors           map[string]string
+}
+
+// NewBrandingFormViewModel fills the form with the organisation's branding.
+// maxBytes is the size limit on each uploaded image.
+func NewBrandingFormViewModel(org db.Organisation, maxBytes int64) BrandingFormViewModel {
+	return BrandingFormViewModel{
+		OrganisationID:   org.ID,
+		OrganisationName: org.Name,
+		Colour:           org.BrandColour.String,
+		Branding:         NewBrandingViewModel(org),
+		MaxImageMB:       int(maxBytes >> 20),
+	}
+}
+
+// BrandingURL returns the admin URL of the organisation's branding settings
+func BrandingURL(organisationID int64) string {
+	return "/admin/organisations/" + strconv.FormatInt(organisationID, 10) + "/branding"
+}
+
+// ActionURL returns the URL the form posts to
+func (vm BrandingFormViewModel) ActionURL() string {
+	return BrandingURL(vm.OrganisationID)
+}
+
+// PickerColour returns the colour the colour picker starts at: the brand
+// colour, or the site's own green
+func (vm BrandingFormViewModel) PickerColour() string {
+	if vm.Branding.Colour != "" {
+		return vm.Branding.Colour
+	}
+	return DefaultBrandColour
+}
+
+// DefaultBrandColour is the site's primary colour, as a hex colour
+const DefaultBrandColour = "#16a34a"
+
+// Error returns the validation error for a field, if any
+func (vm BrandingFormViewModel) Error(field string) string {
+	return vm.Errors[field]
+}
+
+// BrandingURL returns the URL of the branding settings for the selected
+// organisation
+func (d DashboardViewModel) BrandingURL() string {
+	return BrandingURL(d.OrganisationID)
+}
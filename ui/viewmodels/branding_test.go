@@ -0,0 +1,43 @@
+package viewmodels
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestBrandingViewModel_Style(t *testing.T) {
+	tests := []struct {
+		name   string
+		colour string
+		want   string
+	}{
+		{name: "white on a dark colour", colour: "#1a2b3c", want: "--color-primary: #1a2b3c; --color-primary-foreground: #ffffff;"},
+		{name: "black on a light colour", colour: "#ffd700", want: "--color-primary: #ffd700; --color-primary-foreground: #000000;"},
+		{name: "nothing without a colour", colour: "", want: ""},
+		{name: "nothing for a CSS injection", colour: "red; background:url(https://evil.example/x.png)", want: ""},
+		{name: "nothing for upper case", colour: "#1A2B3C", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBrandingViewModel(db.Organisation{ID: 7, BrandColour: pgtype.Text{String: tt.colour, Valid: true}})
+			if got := b.Style(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewBrandingViewModel_Images(t *testing.T) {
+	b := NewBrandingViewModel(db.Organisation{ID: 7, LogoKey: pgtype.Text{String: "organisations/7/logo-a.png", Valid: true}})
+
+	if b.LogoURL != "/organisations/7/logo" {
+		t.Errorf("expected the logo URL, got %q", b.LogoURL)
+	}
+	if b.BannerURL != "" || b.Colour != "" {
+		t.Errorf("expected no banner or colour, got %+v", b)
+	}
+}
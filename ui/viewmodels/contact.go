@@ -0,0 +1,95 @@
+package viewmodels
+
+import (
+	"strconv"
+	"time"
+
+	"firecrest/db"
+)
+
+// ContactViewModel holds the form that asks an event's organisers a
+// question. Stamp dates the form, and is served afresh each time it is
+// shown.
+type ContactViewModel struct {
+	EventName string
+	EventYear int32
+	EventSlug string
+	Name      string
+	Email     string
+	Message   string
+	Stamp     string
+	// MaxLength is the longest message accepted, in characters
+	MaxLength int
+	Errors    map[string]string
+}
+
+// NewContactViewModel prepares an empty contact form for the event
+func NewContactViewModel(event db.Event, stamp string, maxLength int) ContactViewModel {
+	return ContactViewModel{
+		EventName: event.Name,
+		EventYear: event.Year,
+		EventSlug: event.Slug,
+		Stamp:     stamp,
+		MaxLength: maxLength,
+	}
+}
+
+// ContactURL returns the URL of the form asking an event's organisers a
+// question
+func ContactURL(year int32, slug string) string {
+	return EventURL(year, slug) + "/contact/organiser"
+}
+
+// ActionURL returns the URL the form posts to
+func (vm ContactViewModel) ActionURL() string {
+	return ContactURL(vm.EventYear, vm.EventSlug)
+}
+
+// EventURL returns the public page of the event asked about
+func (vm ContactViewModel) EventURL() string {
+	return EventURL(vm.EventYear, vm.EventSlug)
+}
+
+// Error returns the validation error for a field, if any
+func (vm ContactViewModel) Error(field string) string {
+	return vm.Errors[field]
+}
+
+// EnquiryViewModel is a question asked through an event's contact form
+type EnquiryViewModel struct {
+	Name    string
+	Email   string
+	Message string
+	SentAt  string
+}
+
+// EnquiriesViewModel holds the questions asked about an event, newest first
+type EnquiriesViewModel struct {
+	EventID   int64
+	EventName string
+	Enquiries []EnquiryViewModel
+}
+
+// NewEnquiriesViewModel prepares the enquiries page for an event, showing
+// when each was sent in loc
+func NewEnquiriesViewModel(event db.Event, enquiries []db.EventEnquiry, loc *time.Location) EnquiriesViewModel {
+	vm := EnquiriesViewModel{
+		EventID:   event.ID,
+		EventName: event.Name,
+		Enquiries: make([]EnquiryViewModel, 0, len(enquiries)),
+	}
+	for _, e := range enquiries {
+		vm.Enquiries = append(vm.Enquiries, EnquiryViewModel{
+			Name:    e.Name,
+			Email:   e.Email,
+			Message: e.Message,
+			SentAt:  e.CreatedAt.Time.In(loc).Format("2 Jan 2006 15:04"),
+		})
+	}
+	return vm
+}
+
+// EnquiriesURL returns the admin URL of an event's enquiries
+func EnquiriesURL(eventID int64) string {
+	return "/admin/events/" + strconv.FormatInt(eventID, 10) + "/enquiries"
+}
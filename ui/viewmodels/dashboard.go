@@ -0,0 +1,147 @@
+package viewmodels
+
+import (
+	"strconv"
+
+	"firecrest/db"
+)
+
+// DashboardViewModel holds the organiser dashboard for one organisation
+type DashboardViewModel struct {
+	OrganisationID int64
+	Organisations  []OrganisationOption
+	Events         []EventStatsViewModel
+	// CanManageMembers reports whether the viewer can invite and remove
+	// the organisation's members
+	CanManageMembers bool
+	// Notifications is how the viewer hears about new registrations, or
+	// empty when they are not a member of the organisation
+	Notifications db.NotificationPreference
+}
+
+// EventStatsViewModel summarises registrations and revenue for one event
+type EventStatsViewModel struct {
+	ID                  int64
+	Name                string
+	Slug                string
+	Year                int32
+	Status              db.EventStatus
+	Registrations       int
+	Capacity            int
+	RecentRegistrations int
+	Revenue             []Money
+}
+
+// NewDashboardViewModel builds the dashboard from per-event statistics
+func NewDashboardViewModel(organisationID int64, orgs []db.Organisation, stats []db.GetEventStatsRow) DashboardViewModel {
+	vm := DashboardViewModel{
+		OrganisationID: organisationID,
+		Organisations:  NewOrganisationOptions(orgs),
+		Events:         make([]EventStatsViewModel, 0, len(stats)),
+	}
+
+	for _, row := range stats {
+		event := EventStatsViewModel{
+			ID:                  row.ID,
+			Name:                row.Name,
+			Slug:                row.Slug,
+			Year:                row.Year,
+			Status:              row.Status,
+			Registrations:       int(row.Registrations),
+			Capacity:            int(row.Capacity),
+			RecentRegistrations: int(row.RecentRegistrations),
+		}
+		for i, currency := range row.RevenueCurrencies {
+			if i >= len(row.RevenueUnits) {
+				break
+			}
+			event.Revenue = append(event.Revenue, Money{Units: row.RevenueUnits[i], Currency: currency})
+		}
+		vm.Events = append(vm.Events, event)
+	}
+	return vm
+}
+
+// DashboardURL returns the admin dashboard URL showing the organisation's
+// events
+func DashboardURL(organisationID int64) string {
+	return "/admin/dashboard?organisation=" + strconv.FormatInt(organisationID, 10)
+}
+
+// Utilisation returns the percentage of capacity taken, rounded down
+func (e EventStatsViewModel) Utilisation() int {
+	if e.Capacity == 0 {
+		return 0
+	}
+	return (e.Registrations * 100) / e.Capacity
+}
+
+// EventURL returns the public URL for the event
+func (e EventStatsViewModel) EventURL() string {
+	return EventURL(e.Year, e.Slug)
+}
+
+// DuplicateURL returns the admin URL for copying the event into another year
+func (e EventStatsViewModel) DuplicateURL() string {
+	return "/admin/events/" + strconv.FormatInt(e.ID, 10) + "/duplicate"
+}
+
+// DiscountCodesURL returns the admin URL for the event's discount codes
+func (e EventStatsViewModel) DiscountCodesURL() string {
+	return DiscountCodesURL(e.ID)
+}
+
+// SeriesURL returns the admin URL for linking the event to its other
+// editions
+func (e EventStatsViewModel) SeriesURL() string {
+	return "/admin/events/" + strconv.FormatInt(e.ID, 10) + "/series"
+}
+
+// PhotosURL returns the admin URL for the event's photo gallery
+func (e EventStatsViewModel) PhotosURL() string {
+	return EventPhotosURL(e.ID)
+}
+
+// EnquiriesURL returns the admin URL for the questions asked about the event
+func (e EventStatsViewModel) EnquiriesURL() string {
+	return EnquiriesURL(e.ID)
+}
+
+// StatusLabel returns the event's status for display
+func (e EventStatsViewModel) StatusLabel() string {
+	switch e.Status {
+	case db.EventStatusPublished:
+		return "Published"
+	case db.EventStatusArchived:
+		return "Archived"
+	default:
+		return "Draft"
+	}
+}
+
+// StatusVariant returns the badge variant for the event's status
+func (e EventStatsViewModel) StatusVariant() string {
+	switch e.Status {
+	case db.EventStatusPublished:
+		return "success"
+	case db.EventStatusArchived:
+		return "outline"
+	default:
+		return "secondary"
+	}
+}
+
+// CanPublish reports whether the event is not yet public
+func (e EventStatsViewModel) CanPublish() bool {
+	return e.Status != db.EventStatusPublished
+}
+
+// PublishURL returns the admin URL for publishing the event
+func (e EventStatsViewModel) PublishURL() string {
+	return "/admin/events/" + strconv.FormatInt(e.ID, 10) + "/publish"
+}
+
+// ArchiveURL returns the admin URL for taking the event down
+func (e EventStatsViewModel) ArchiveURL() string {
+	return "/admin/events/" + strconv.FormatInt(e.ID, 10) + "/archive"
+}
@@ -0,0 +1,51 @@
+package viewmodels
+
+import (
+	"testing"
+
+	"firecrest/db"
+)
+
+func TestNewDashboardViewModel(t *testing.T) {
+	stats := []db.GetEventStatsRow{
+		{
+			Name:                "Peak District Ultra",
+			Slug:                "peak-district-ultra",
+			Status:              db.EventStatusPublished,
+			Registrations:       150,
+			Capacity:            200,
+			RecentRegistrations: 12,
+			RevenueCurrencies:   []string{"EUR", "GBP"},
+			RevenueUnits:        []int64{45000, 650000},
+		},
+		{Name: "No Races Yet", Slug: "no-races-yet"},
+	}
+
+	vm := NewDashboardViewModel(7, []db.Organisation{{ID: 7, Name: "Peak Running Co"}}, stats)
+
+	if vm.OrganisationID != 7 || len(vm.Organisations) != 1 {
+		t.Fatalf("expected organisation 7 to be selected, got %+v", vm)
+	}
+	if len(vm.Events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(vm.Events))
+	}
+
+	ultra := vm.Events[0]
+	if ultra.Utilisation() != 75 {
+		t.Errorf("expected 75%% utilisation, got %d", ultra.Utilisation())
+	}
+	if ultra.StatusLabel() != "Published" || ultra.CanPublish() {
+		t.Errorf("expected a published event, got %q", ultra.StatusLabel())
+	}
+	if len(ultra.Revenue) != 2 || ultra.Revenue[0] != (Money{Units: 45000, Currency: "EUR"}) || ultra.Revenue[1] != (Money{Units: 650000, Currency: "GBP"}) {
+		t.Errorf("expected revenue kept separate per currency, got %+v", ultra.Revenue)
+	}
+
+	empty := vm.Events[1]
+	if empty.Registrations != 0 || empty.Capacity != 0 || empty.Utilisation() != 0 || len(empty.Revenue) != 0 {
+		t.Errorf("expected zeros for event without races, got %+v", empty)
+	}
+	if empty.StatusLabel() != "Draft" || !empty.CanPublish() {
+		t.Errorf("expected an event without a status to show as a draft, got %q", empty.StatusLabel())
+	}
+}
@@ -0,0 +1,74 @@
+package viewmodels
+
+import (
+	"time"
+
+	"firecrest/db"
+)
+
+// DataExportState is where the user's latest request for a copy of their
+// data has got to
+type DataExportState string
+
+const (
+	DataExportNone       DataExportState = ""
+	DataExportPending    DataExportState = "pending"
+	DataExportReady      DataExportState = "ready"
+	DataExportDownloaded DataExportState = "downloaded"
+	DataExportExpired    DataExportState = "expired"
+	DataExportFailed     DataExportState = "failed"
+)
+
+// DataExportViewModel holds the page the user asks for a copy of their data
+// on
+type DataExportViewModel struct {
+	State       DataExportState
+	RequestedAt time.Time
+	// At is when the copy was downloaded, or when its link expires or
+	// expired
+	At time.Time
+}
+
+// NewDataExportViewModel builds the page from the user's latest request, a
+// zero one if they have made none
+func NewDataExportViewModel(e db.DataExport, now time.Time) DataExportViewModel {
+	vm := DataExportViewModel{RequestedAt: e.CreatedAt.Time, At: e.ExpiresAt.Time}
+	switch {
+	case e.ID == 0:
+		vm.State = DataExportNone
+	case e.Status == db.DataExportStatusPending:
+		vm.State = DataExportPending
+	case e.Status == db.DataExportStatusFailed:
+		vm.State = DataExportFailed
+	case e.DownloadedAt.Valid:
+		vm.State, vm.At = DataExportDownloaded, e.DownloadedAt.Time
+	case !e.ExpiresAt.Time.After(now):
+		vm.State = DataExportExpired
+	default:
+		vm.State = DataExportReady
+	}
+	return vm
+}
+
+// Message describes the latest request, or nothing if there is none
+func (vm DataExportViewModel) Message() string {
+	switch vm.State {
+	case DataExportPending:
+		return "We're preparing the copy you asked for on " + vm.RequestedAt.Format("2 January 2006 at 15:04") + ", and will email you a link to it when it's ready."
+	case DataExportReady:
+		return "Your copy is ready. We've emailed you a link to it, which works once until " + vm.At.Format("2 January 2006 at 15:04") + "."
+	case DataExportDownloaded:
+		return "You downloaded your copy on " + vm.At.Format("2 January 2006 at 15:04") + "."
+	case DataExportExpired:
+		return "The link to your last copy has expired."
+	case DataExportFailed:
+		return "We couldn't prepare the copy you asked for. Please try again."
+	}
+	return ""
+}
+
+// CanRequest reports whether the user may ask for another copy, which they
+// may unless one is being prepared
+func (vm DataExportViewModel) CanRequest() bool {
+	return vm.State != DataExportPending
+}
@@ -0,0 +1,38 @@
+package viewmodels
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+func TestNewDataExportViewModel(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	at := func(t time.Time) pgtype.Timestamptz { return pgtype.Timestamptz{Time: t, Valid: true} }
+
+	tests := []struct {
+		name   string
+		export db.DataExport
+		want   DataExportState
+	}{
+		{name: "none", want: DataExportNone},
+		{name: "pending", export: db.DataExport{ID: 1, Status: db.DataExportStatusPending}, want: DataExportPending},
+		{name: "failed", export: db.DataExport{ID: 1, Status: db.DataExportStatusFailed}, want: DataExportFailed},
+		{name: "ready", export: db.DataExport{ID: 1, Status: db.DataExportStatusReady, ExpiresAt: at(now.Add(time.Hour))}, want: DataExportReady},
+		{name: "expired", export: db.DataExport{ID: 1, Status: db.DataExportStatusReady, ExpiresAt: at(now)}, want: DataExportExpired},
+		{name: "downloaded", export: db.DataExport{ID: 1, Status: db.DataExportStatusReady, ExpiresAt: at(now.Add(time.Hour)), DownloadedAt: at(now)}, want: DataExportDownloaded},
+	}
+
+	for _, tt := range tests {
+		vm := NewDataExportViewModel(tt.export, now)
+		if vm.State != tt.want {
+			t.Errorf("%s: expected state %q, got %q", tt.name, tt.want, vm.State)
+		}
+		if vm.CanRequest() == (tt.want == DataExportPending) {
+			t.Errorf("%s: expected another copy to be requestable unless one is pending", tt.name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package viewmodels
+
+import (
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"firecrest/db"
+)
+
+// DiscountTimeLayout is the format of the discount form's datetime-local
+// inputs
+const DiscountTimeLayout = "2006-01-02T15:04"
+
+// RaceOption represents a race in a select input
+type RaceOption struct {
+	ID   int64
+	Name string
+}
+
+// Value returns the option value for a race
+func (o RaceOption) Value() string {
+	return strconv.FormatInt(o.ID, 10)
+}
+
+// DiscountCodeViewModel represents one of an event's discount codes
+type DiscountCodeViewModel struct {
+	Code     string
+	Discount string
+	Races    string
+	Uses     string
+	Validity string
+}
+
+// DiscountCodesViewModel holds an event's discount codes and the form to
+// create one
+type DiscountCodesViewModel struct {
+	EventID   int64
+	EventName string
+	Codes     []DiscountCodeViewModel
+	Races     []RaceOption
+	// The create form's submitted values
+	Code       string
+	RaceID     string
+	PercentOff string
+	AmountOff  string
+	MaxUses    string
+	ValidFrom  string
+	ValidUntil string
+	Errors     map[string]string
+
+	currencies map[int64]string
+}
+
+// NewDiscountCodesViewModel prepares the discount codes page for an event
+func NewDiscountCodesViewModel(event db.Event, races []db.Race, codes []db.DiscountCode) DiscountCodesViewModel {
+	vm := DiscountCodesViewModel{
+		EventID:    event.ID,
+		EventName:  event.Name,
+		Codes:      make([]DiscountCodeViewModel, 0, len(codes)),
+		Races:      make([]RaceOption, 0, len(races)),
+		currencies: make(map[int64]string, len(races)),
+	}
+	names := make(map[int64]string, len(races))
+	for _, race := range races {
+		vm.Races = append(vm.Races, RaceOption{ID: race.ID, Name: race.Name})
+		vm.currencies[race.ID] = raceFee(race).Currency
+		names[race.ID] = race.Name
+	}
+
+	for _, code := range codes {
+		c := DiscountCodeViewModel{
+			Code:     code.Code,
+			Races:    "All races",
+			Uses:     strconv.Itoa(int(code.Uses)),
+			Validity: validityLabel(code.ValidFrom, code.ValidUntil),
+		}
+		if code.RaceID.Valid {
+			c.Races = names[code.RaceID.Int64]
+		}
+		if code.MaxUses.Valid {
+			c.Uses += " of " + strconv.Itoa(int(code.MaxUses.Int32))
+		}
+		if code.PercentOff.Valid {
+			c.Discount = strconv.Itoa(int(code.PercentOff.Int32)) + "% off"
+		} else {
+			amount := Money{Units: int64(code.AmountOffUnits.Int32), Currency: vm.Currency(code.RaceID.Int64)}
+			c.Discount = amount.Format() + " off"
+		}
+		vm.Codes = append(vm.Codes, c)
+	}
+	return vm
+}
+
+// Currency returns the currency amounts off are given in for a code limited
+// to the race, or for a code for every race when raceID is zero. Races in an
+// event are assumed to share the currency of the first.
+func (vm DiscountCodesViewModel) Currency(raceID int64) string {
+	if currency, ok := vm.currencies[raceID]; ok {
+		return currency
+	}
+	if len(vm.Races) > 0 {
+		return vm.currencies[vm.Races[0].ID]
+	}
+	return defaultCurrency
+}
+
+// ActionURL returns the URL the create form posts to
+func (vm DiscountCodesViewModel) ActionURL() string {
+	return DiscountCodesURL(vm.EventID)
+}
+
+// Error returns the validation error for a field, if any
+func (vm DiscountCodesViewModel) Error(field string) string {
+	return vm.Errors[field]
+}
+
+// validityLabel describes when a code can be used
+func validityLabel(from, until pgtype.Timestamptz) string {
+	const layout = "2 January 2006 15:04"
+	switch {
+	case from.Valid && until.Valid:
+		return from.Time.Format(layout) + " to " + until.Time.Format(layout)
+	case from.Valid:
+		return "From " + from.Time.Format(layout)
+	case until.Valid:
+		return "Until " + until.Time.Format(layout)
+	default:
+		return "Any time"
+	}
+}
+
+// DiscountCodesURL returns the URL of an event's discount codes page
+func DiscountCodesURL(eventID int64) string {
+	return "/admin/events/" + strconv.FormatInt(eventID, 10) + "/discounts"
+}
@@ -0,0 +1,140 @@
+package viewmodels
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"firecrest/db"
+)
+
+// metresPerMile is the length of an international mile
+const metresPerMile = 1609.344
+
+// Distance is a race's length in metres, shown in the unit the reader
+// prefers
+type Distance int
+
+// FormatKm returns the distance in kilometres to a tenth, e.g. "42.2 km",
+// leaving off a tenth of zero, as in "50 km"
+func (d Distance) FormatKm() string {
+	return formatTenths(float64(d)/1000) + " km"
+}
+
+// FormatMiles returns the distance in miles to a tenth, e.g. "26.2 miles",
+// leaving off a tenth of zero, as in "50 miles"
+func (d Distance) FormatMiles() string {
+	miles := formatTenths(float64(d) / metresPerMile)
+	if miles == "1" {
+		return "1 mile"
+	}
+	return miles + " miles"
+}
+
+// Format returns the distance in unit, the reader's preference: kilometres
+// for km and otherwise miles, the default for a UK audience
+func (d Distance) Format(unit db.DistanceUnit) string {
+	if unit == db.DistanceUnitKm {
+		return d.FormatKm()
+	}
+	return d.FormatMiles()
+}
+
+// formatTenths writes n rounded to a tenth, without a trailing ".0"
+func formatTenths(n float64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(math.Round(n*10)/10, 'f', 1, 64), ".0")
+}
+
+// ParseDistance reads a distance typed in unit, such as "26.2" miles or
+// "50" km, to the nearest metre. It rejects anything but a positive number.
+func ParseDistance(text string, unit db.DistanceUnit) (Distance, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || !(n > 0) || math.IsInf(n, 0) {
+		return 0, errors.New("invalid distance " + strconv.Quote(text))
+	}
+	if unit == db.DistanceUnitKm {
+		return Distance(math.Round(n * 1000)), nil
+	}
+	return Distance(math.Round(n * metresPerMile)), nil
+}
+
+// DistanceUnitOption is a unit offered for distances to be typed or shown in
+type DistanceUnitOption struct {
+	Value db.DistanceUnit
+	Label string
+}
+
+// DistanceUnitOptions lists the units, the default first
+var DistanceUnitOptions = []DistanceUnitOption{
+	{Value: db.DistanceUnitMiles, Label: "Miles"},
+	{Value: db.DistanceUnitKm, Label: "Kilometres"},
+}
+
+// DistanceBucket is a band of race distances the event listing filters by
+type DistanceBucket string
+
+const (
+	Bucket5K       DistanceBucket = "5k"
+	Bucket10K      DistanceBucket = "10k"
+	BucketHalf     DistanceBucket = "half"
+	BucketMarathon DistanceBucket = "marathon"
+	BucketUltra    DistanceBucket = "ultra"
+)
+
+// distanceBands bound each bucket in metres, both ends included. Courses
+// are rarely measured to the metre, so each band allows some way either
+// side of the standard distance, and anything longer than a marathon's
+// band is an ultra. Distances between bands, such as 15 km, are in none.
+var distanceBands = []struct {
+	bucket   DistanceBucket
+	label    string
+	min, max Distance
+}{
+	{Bucket5K, "5K", 4_500, 5_500},
+	{Bucket10K, "10K", 9_000, 11_000},
+	{BucketHalf, "Half marathon", 20_000, 22_500},
+	{BucketMarathon, "Marathon", 41_000, 43_500},
+	{BucketUltra, "Ultra", 43_501, math.MaxInt},
+}
+
+// DistanceBuckets lists the buckets, shortest first
+var DistanceBuckets = func() []DistanceBucket {
+	buckets := make([]DistanceBucket, len(distanceBands))
+	for i, band := range distanceBands {
+		buckets[i] = band.bucket
+	}
+	return buckets
+}()
+
+// BucketOf returns the bucket the distance falls in, and false if it falls
+// in none
+func BucketOf(d Distance) (DistanceBucket, bool) {
+	for _, band := range distanceBands {
+		if d >= band.min && d <= band.max {
+			return band.bucket, true
+		}
+	}
+	return "", false
+}
+
+// ParseDistanceBucket reads a bucket from a query string, reporting false
+// for anything but one of DistanceBuckets
+func ParseDistanceBucket(s string) (DistanceBucket, bool) {
+	for _, band := range distanceBands {
+		if string(band.bucket) == s {
+			return band.bucket, true
+		}
+	}
+	return "", false
+}
+
+// Label returns the bucket's name for display, e.g. "Half marathon"
+func (b DistanceBucket) Label() string {
+	for _, band := range distanceBands {
+		if band.bucket == b {
+			return band.label
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,196 @@
+package viewmodels
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"firecrest/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDistanceFormat(t *testing.T) {
+	tests := []struct {
+		metres    Distance
+		wantKm    string
+		wantMiles string
+	}{
+		{metres: 5000, wantKm: "5 km", wantMiles: "3.1 miles"},
+		{metres: 10000, wantKm: "10 km", wantMiles: "6.2 miles"},
+		{metres: 21097, wantKm: "21.1 km", wantMiles: "13.1 miles"},
+		{metres: 42195, wantKm: "42.2 km", wantMiles: "26.2 miles"},
+		{metres: 1609, wantKm: "1.6 km", wantMiles: "1 mile"},
+		{metres: 80467, wantKm: "80.5 km", wantMiles: "50 miles"},
+		{metres: 160934, wantKm: "160.9 km", wantMiles: "100 miles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantKm, func(t *testing.T) {
+			if got := tt.metres.FormatKm(); got != tt.wantKm {
+				t.Errorf("Distance(%d).FormatKm() = %q, want %q", tt.metres, got, tt.wantKm)
+			}
+			if got := tt.metres.FormatMiles(); got != tt.wantMiles {
+				t.Errorf("Distance(%d).FormatMiles() = %q, want %q", tt.metres, got, tt.wantMiles)
+			}
+			if got := tt.metres.Format(db.DistanceUnitKm); got != tt.wantKm {
+				t.Errorf("Distance(%d).Format(km) = %q, want %q", tt.metres, got, tt.wantKm)
+			}
+			if got := tt.metres.Format(""); got != tt.wantMiles {
+				t.Errorf("Distance(%d).Format(\"\") = %q, want miles %q", tt.metres, got, tt.wantMiles)
+			}
+		})
+	}
+}
+
+func TestParseDistance(t *testing.T) {
+	t.Run("round-trips what was typed", func(t *testing.T) {
+		tests := []struct {
+			text   string
+			unit   db.DistanceUnit
+			metres Distance
+		}{
+			{text: "26.2", unit: db.DistanceUnitMiles, metres: 42165},
+			{text: "13.1", unit: db.DistanceUnitMiles, metres: 21082},
+			{text: "50", unit: db.DistanceUnitMiles, metres: 80467},
+			{text: "3.1", unit: db.DistanceUnitMiles, metres: 4989},
+			{text: "42.2", unit: db.DistanceUnitKm, metres: 42200},
+			{text: "5", unit: db.DistanceUnitKm, metres: 5000},
+			{text: " 10.5 ", unit: db.DistanceUnitKm, metres: 10500},
+		}
+		for _, tt := range tests {
+			got, err := ParseDistance(tt.text, tt.unit)
+			if err != nil {
+				t.Fatalf("ParseDistance(%q, %s) failed: %v", tt.text, tt.unit, err)
+			}
+			if got != tt.metres {
+				t.Errorf("ParseDistance(%q, %s) = %d, want %d", tt.text, tt.unit, got, tt.metres)
+			}
+			want := strings.TrimSpace(tt.text) + " km"
+			if tt.unit == db.DistanceUnitMiles {
+				want = strings.TrimSpace(tt.text) + " miles"
+			}
+			if back := got.Format(tt.unit); back != want {
+				t.Errorf("Distance(%d).Format(%s) = %q, want %q", got, tt.unit, back, want)
+			}
+		}
+	})
+
+	t.Run("rejects anything but a positive number", func(t *testing.T) {
+		for _, text := range []string{"", "0", "-5", "ten", "5km", "Inf", "NaN"} {
+			if got, err := ParseDistance(text, db.DistanceUnitKm); err == nil {
+				t.Errorf("ParseDistance(%q) = %d, want an error", text, got)
+			}
+		}
+	})
+}
+
+func TestBucketOf(t *testing.T) {
+	tests := []struct {
+		metres Distance
+		want   DistanceBucket
+	}{
+		{metres: 4499},
+		{metres: 4500, want: Bucket5K},
+		{metres: 5000, want: Bucket5K},
+		{metres: 5500, want: Bucket5K},
+		{metres: 5501},
+		{metres: 8999},
+		{metres: 9000, want: Bucket10K},
+		{metres: 11000, want: Bucket10K},
+		{metres: 11001},
+		{metres: 15000},
+		{metres: 19999},
+		{metres: 20000, want: BucketHalf},
+		{metres: 21097, want: BucketHalf},
+		{metres: 22500, want: BucketHalf},
+		{metres: 22501},
+		{metres: 40999},
+		{metres: 41000, want: BucketMarathon},
+		{metres: 42195, want: BucketMarathon},
+		{metres: 43500, want: BucketMarathon},
+		{metres: 43501, want: BucketUltra},
+		{metres: 160934, want: BucketUltra},
+	}
+
+	for _, tt := range tests {
+		got, ok := BucketOf(tt.metres)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("BucketOf(%d) = %q, %v, want %q", tt.metres, got, ok, tt.want)
+		}
+	}
+}
+
+func TestParseDistanceBucket(t *testing.T) {
+	for _, b := range DistanceBuckets {
+		if got, ok := ParseDistanceBucket(string(b)); !ok || got != b {
+			t.Errorf("ParseDistanceBucket(%q) = %q, %v", b, got, ok)
+		}
+	}
+	if _, ok := ParseDistanceBucket("50k"); ok {
+		t.Error("expected an unknown bucket to be refused")
+	}
+}
+
+func TestEventViewModelShowDistancesIn(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	race := func(metres int32) db.Race {
+		return db.Race{MaxCapacity: 100, DistanceMetres: pgtype.Int4{Int32: metres, Valid: metres > 0}}
+	}
+	events := NewEventListViewModels(
+		[]db.Event{{ID: 1, Slug: "a"}, {ID: 2, Slug: "b"}, {ID: 3, Slug: "c"}},
+		map[int64][]db.Race{1: {race(42195), race(5000)}, 2: {race(10000), race(0)}, 3: {race(0)}},
+		nil, nil, now,
+	)
+
+	for i := range events {
+		events[i].ShowDistancesIn(db.DistanceUnitKm)
+	}
+
+	for i, want := range []string{"5 km to 42.2 km", "10 km", ""} {
+		if events[i].Distance != want {
+			t.Errorf("event %d: expected distance %q, got %q", i+1, want, events[i].Distance)
+		}
+	}
+	if !events[0].HasDistance(BucketMarathon) || !events[0].HasDistance(Bucket5K) || events[0].HasDistance(Bucket10K) {
+		t.Errorf("expected the first event in the 5K and marathon buckets only, got lengths %v", events[0].Lengths)
+	}
+
+	listing := NewEventListingViewModel(2026, []int32{2026}, false, Bucket10K, events)
+	if len(listing.Events) != 1 || listing.Events[0].Slug != "b" {
+		t.Errorf("expected only the second event listed as a 10K, got %+v", listing.Events)
+	}
+	if !slices.ContainsFunc(listing.Distances, func(tab DistanceTab) bool {
+		return tab.Active && tab.URL == "/events?distance=10k&year=2026"
+	}) {
+		t.Errorf("expected the 10K tab active, got %+v", listing.Distances)
+	}
+	if listing.Years[0].URL != "/events?distance=10k&year=2026" {
+		t.Errorf("expected the year tab to keep the distance, got %q", listing.Years[0].URL)
+	}
+}
+
+func TestRaceViewModelShowDistancesIn(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	known := NewRaceViewModel(db.Race{
+		MaxCapacity:         100,
+		DistanceMetres:      pgtype.Int4{Int32: 80467, Valid: true},
+		ElevationGainMetres: pgtype.Int4{Int32: 2350, Valid: true},
+	}, 0, now)
+	unknown := NewRaceViewModel(db.Race{MaxCapacity: 100}, 0, now)
+	unknown.Distance = "Fun run"
+	event := EventViewModel{Races: []RaceViewModel{known, unknown}}
+
+	event.ShowDistancesIn(db.DistanceUnitMiles)
+
+	if got := event.Races[0].Distance; got != "50 miles" {
+		t.Errorf("expected the race shown as 50 miles, got %q", got)
+	}
+	if got := event.Races[0].Climb; got != "2,350 m climb" {
+		t.Errorf("expected a climb of 2,350 m, got %q", got)
+	}
+	if got := event.Races[1].Distance; got != "Fun run" {
+		t.Errorf("expected a race without a length to keep its label, got %q", got)
+	}
+}
@@ -0,0 +1,18 @@
+package viewmodels
+
+// ErrorDetail describes a failed request on the 500 page. RequestID lets
+// the viewer quote the request to support; the rest is only filled in
+// during development, where it saves a trip to the logs.
+type ErrorDetail struct {
+	RequestID string
+	Error     string
+	Method    string
+	URI       string
+	// Trace is the stack where a panic happened, if the error was one.
+	Trace string
+}
+
+// Debug reports whether the detail includes the error itself.
+func (d ErrorDetail) Debug() bool {
+	return d.Error != ""
+}
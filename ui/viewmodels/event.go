@@ -1,17 +1,26 @@
 package viewmodels
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/i18n"
+)
 
 // EventViewModel represents an event for display purposes
 type EventViewModel struct {
 	Slug        string
+	Year        int32
 	Name        string
 	Date        time.Time
 	Location    string
 	ImageURL    string
-	RaceType    string    // e.g., "Trail Run", "Road Race", "Ultra Marathon"
-	Distance    string    // e.g., "10K", "Half Marathon", "50K"
-	Description string
+	RaceType    string // e.g., "Trail Run", "Road Race", "Ultra Marathon"
+	Distance    string // e.g., "10K", "Half Marathon", "50K"
+	Description string // markdown
 	Races       []RaceViewModel
 	Photos      []string
 	MapURL      string
@@ -19,32 +28,452 @@ type EventViewModel struct {
 	Price       string
 	Capacity    int
 	Registered  int
+	// RegistrationClosesAt is the latest close date across the event's
+	// races, or zero when any race takes entries without one
+	RegistrationClosesAt time.Time
+	// Now is the instant the view model was built at, against which
+	// time-dependent badges are judged
+	Now time.Time
+	// PreviousEditions lists the earlier published editions of the event's
+	// series, latest first
+	PreviousEditions []EditionViewModel
+	// Branding is the organisation's look for the page
+	Branding BrandingViewModel
+	// Lengths lists the lengths of the races whose organiser gave one
+	Lengths []Distance
+	// TimeZone is the event's, in which StructuredData writes its dates
+	TimeZone *time.Location
+	// SiteURL roots the links in StructuredData, e.g.
+	// "https://firecrest.run"
+	SiteURL string
+}
+
+// EditionViewModel is an earlier edition of an event, linked from the page
+// of a later one
+type EditionViewModel struct {
+	Year    int32
+	Name    string
+	URL     string
+	Results []ResultLink
+}
+
+// ResultLink links to a race's published results
+type ResultLink struct {
+	RaceName string
+	URL      string
+}
+
+// NewEditionViewModels builds the previous editions of an event in year
+// from its series' editions, given latest first. Editions from year onwards
+// are left out.
+func NewEditionViewModels(editions []db.ListEventsInSeriesRow, year int32) []EditionViewModel {
+	var vms []EditionViewModel
+	for _, e := range editions {
+		if e.Event.Year >= year {
+			continue
+		}
+		url := EventURL(e.Event.Year, e.Event.Slug)
+		vm := EditionViewModel{Year: e.Event.Year, Name: e.Event.Name, URL: url}
+		for i, slug := range e.ResultRaceSlugs {
+			vm.Results = append(vm.Results, ResultLink{RaceName: e.ResultRaceNames[i], URL: url + "/results/" + slug})
+		}
+		vms = append(vms, vm)
+	}
+	return vms
 }
 
 // RaceViewModel represents a race within an event
 type RaceViewModel struct {
+	Slug        string
+	EventSlug   string
+	EventYear   int32
 	Name        string
 	Distance    string
 	Price       string
 	StartTime   string
+	StartsAt    time.Time
 	Capacity    int
 	Registered  int
 	Description string
+	State       RaceState
+	OpensAt     time.Time
+	ClosesAt    time.Time
+	// Route is set for races whose organiser uploaded a route
+	Route *RouteViewModel
+	// Waves lists the race's start waves, earliest first, for entrants to
+	// choose from
+	Waves []WaveOption
+	// PriceNote says when the price changes and to what, e.g. "until 1
+	// March, then £75", and is empty while it stays as it is
+	PriceNote string
+	// Length is the race's distance, or zero when the organiser has not
+	// given one. Distance shows it in the reader's unit once
+	// EventViewModel.ShowDistancesIn is called.
+	Length Distance
+	// Climb is the race's elevation gain, e.g. "1,250 m climb", and is
+	// empty when the organiser has not given one
+	Climb string
+
+	fee Money
+}
+
+// RouteViewModel is what the race card shows of a race's route
+type RouteViewModel struct {
+	DistanceMetres      int
+	ElevationGainMetres int
+}
+
+// DistanceLabel returns the route's length in kilometres, e.g. "42.2 km"
+func (r RouteViewModel) DistanceLabel() string {
+	return strconv.FormatFloat(float64(r.DistanceMetres)/1000, 'f', 1, 64) + " km"
+}
+
+// ClimbLabel returns the route's elevation gain, e.g. "1,250 m climb"
+func (r RouteViewModel) ClimbLabel() string {
+	return groupThousands(int64(r.ElevationGainMetres)) + " m climb"
+}
+
+// WaveOption is a start wave an entrant may ask for
+type WaveOption struct {
+	ID        int64
+	Name      string
+	StartTime string
+}
+
+// Value returns the wave's form value.
+func (w WaveOption) Value() string {
+	return strconv.FormatInt(w.ID, 10)
+}
+
+// SetWaves lists the race's start waves, given earliest first, and shows
+// the first wave's start as the race's.
+func (vm *RaceViewModel) SetWaves(waves []db.RaceWave) {
+	vm.Waves = make([]WaveOption, len(waves))
+	for i, w := range waves {
+		vm.Waves[i] = WaveOption{ID: w.ID, Name: w.Name, StartTime: w.StartsAt.Time.Format("15:04")}
+	}
+	if len(waves) > 0 {
+		vm.StartsAt = waves[0].StartsAt.Time
+		vm.StartTime = vm.Waves[0].StartTime
+	}
+}
+
+// RaceState is where a race is in its registration window
+type RaceState int
+
+// Registration states, in the order a race normally moves through them
+const (
+	RaceStateOpen RaceState = iota
+	RaceStateNotYetOpen
+	RaceStateSoldOut
+	RaceStateClosed
+)
+
+// NewRaceViewModel builds a race view model, deriving its registration state
+// at now from the registration window and the number already registered.
+// Registration opens at the open instant and closes at the close instant.
+func NewRaceViewModel(race db.Race, registered int, now time.Time) RaceViewModel {
+	vm := RaceViewModel{
+		Slug:       race.Slug,
+		Name:       race.Name,
+		Capacity:   int(race.MaxCapacity),
+		Registered: registered,
+		State:      raceState(race, registered, now),
+	}
+	if race.RegistrationOpenDate.Valid {
+		vm.OpensAt = race.RegistrationOpenDate.Time
+	}
+	if race.RegistrationCloseDate.Valid {
+		vm.ClosesAt = race.RegistrationCloseDate.Time
+	}
+	if race.StartsAt.Valid {
+		vm.StartsAt = race.StartsAt.Time
+		vm.StartTime = race.StartsAt.Time.Format("15:04")
+	}
+	if race.DistanceMetres.Valid {
+		vm.Length = Distance(race.DistanceMetres.Int32)
+	}
+	if race.ElevationGainMetres.Valid {
+		vm.Climb = groupThousands(int64(race.ElevationGainMetres.Int32)) + " m climb"
+	}
+
+	vm.fee = raceFee(race)
+	vm.Price = priceLabel(vm.fee, Money.Format)
+	return vm
+}
+
+// SetPrice shows units as what an entry costs now, in place of the race's
+// own price. A non-nil next is what it costs from until or, when until is
+// zero, once placesLeft more entries have been made at the price.
+func (vm *RaceViewModel) SetPrice(units int32, next *int32, until time.Time, placesLeft int) {
+	vm.fee.Units = int64(units)
+	vm.Price = priceLabel(vm.fee, Money.Format)
+	vm.PriceNote = ""
+	if next == nil {
+		return
+	}
+	then := priceLabel(Money{Units: int64(*next), Currency: vm.fee.Currency}, Money.Format)
+	switch {
+	case !until.IsZero():
+		vm.PriceNote = fmt.Sprintf("until %s, then %s", until.Format("2 January"), then)
+	case placesLeft == 1:
+		vm.PriceNote = "for the next entry, then " + then
+	default:
+		vm.PriceNote = fmt.Sprintf("for the next %d entries, then %s", placesLeft, then)
+	}
+}
+
+func raceState(race db.Race, registered int, now time.Time) RaceState {
+	switch {
+	case race.RegistrationOpenDate.Valid && now.Before(race.RegistrationOpenDate.Time):
+		return RaceStateNotYetOpen
+	case race.RegistrationCloseDate.Valid && !now.Before(race.RegistrationCloseDate.Time):
+		return RaceStateClosed
+	case registered >= int(race.MaxCapacity):
+		return RaceStateSoldOut
+	default:
+		return RaceStateOpen
+	}
+}
+
+// EventURL returns the public URL of an event's edition in year. Slugs are
+// only unique within an organisation and year, so the year is part of it.
+func EventURL(year int32, slug string) string {
+	return "/events/" + strconv.Itoa(int(year)) + "/" + slug
+}
+
+// RegisterURL returns the endpoint that enters the signed-in user in the race
+func (r RaceViewModel) RegisterURL() string {
+	return EventURL(r.EventYear, r.EventSlug) + "/races/" + r.Slug + "/register"
+}
+
+// RouteURL returns where the race's GPX route file is downloaded from
+func (r RaceViewModel) RouteURL() string {
+	return EventURL(r.EventYear, r.EventSlug) + "/races/" + r.Slug + "/route.gpx"
+}
+
+// CanRegister reports whether entries are currently being taken
+func (r RaceViewModel) CanRegister() bool {
+	return r.State == RaceStateOpen
+}
+
+// ActionLabel returns the text for the race's registration button
+func (r RaceViewModel) ActionLabel() string {
+	switch r.State {
+	case RaceStateNotYetOpen:
+		return "Opens on " + r.OpensAt.Format("2 January 2006")
+	case RaceStateSoldOut:
+		return "Sold out"
+	case RaceStateClosed:
+		return "Closed"
+	default:
+		return "Register"
+	}
+}
+
+// StateName returns a stable name for the state, for use in markup
+func (r RaceViewModel) StateName() string {
+	switch r.State {
+	case RaceStateNotYetOpen:
+		return "not-yet-open"
+	case RaceStateSoldOut:
+		return "sold-out"
+	case RaceStateClosed:
+		return "closed"
+	default:
+		return "open"
+	}
+}
+
+// NewEventViewModel builds an EventViewModel from a database event
+func NewEventViewModel(e db.Event) EventViewModel {
+	return EventViewModel{
+		Slug:        e.Slug,
+		Year:        e.Year,
+		Name:        e.Name,
+		Location:    e.Location,
+		ImageURL:    e.ImageUrl,
+		Description: e.Description,
+	}
+}
+
+// NewEventDetailViewModel builds the event page view model at now from the
+// event and its races. The event's date is that of its earliest race and its
+// price the cheapest entry.
+func NewEventDetailViewModel(e db.Event, races []RaceViewModel, now time.Time) EventViewModel {
+	vm := NewEventViewModel(e)
+	vm.Races = races
+	vm.Now = now
+
+	closes := make([]time.Time, 0, len(races))
+	var cheapest *Money
+	for i := range vm.Races {
+		vm.Races[i].EventSlug = e.Slug
+		vm.Races[i].EventYear = e.Year
+	}
+	for _, race := range races {
+		if race.Length > 0 {
+			vm.Lengths = append(vm.Lengths, race.Length)
+		}
+		closes = append(closes, race.ClosesAt)
+		vm.Capacity += race.Capacity
+		vm.Registered += race.Registered
+		if !race.StartsAt.IsZero() && (vm.Date.IsZero() || race.StartsAt.Before(vm.Date)) {
+			vm.Date = race.StartsAt
+		}
+		if cheapest == nil || race.fee.Units < cheapest.Units {
+			cheapest = &race.fee
+		}
+	}
+
+	if cheapest != nil {
+		vm.Price = priceLabel(*cheapest, Money.Format)
+	}
+	vm.RegistrationClosesAt = latestClose(closes)
+	return vm
+}
+
+// NewEventListViewModels builds listing view models for events at now,
+// totalling capacity across each event's races alongside its registration
+// count and pricing each event at its cheapest entry. prices holds what
+// entries cost now by race ID; races missing from it cost their own price.
+func NewEventListViewModels(events []db.Event, races map[int64][]db.Race, prices map[int64]int32, registered map[int64]int, now time.Time) []EventViewModel {
+	vms := make([]EventViewModel, 0, len(events))
+	for _, e := range events {
+		vm := NewEventViewModel(e)
+		vm.Now = now
+		closes := make([]time.Time, 0, len(races[e.ID]))
+		var cheapest *Money
+		for _, race := range races[e.ID] {
+			vm.Capacity += int(race.MaxCapacity)
+			if race.DistanceMetres.Valid {
+				vm.Lengths = append(vm.Lengths, Distance(race.DistanceMetres.Int32))
+			}
+			closes = append(closes, race.RegistrationCloseDate.Time)
+			fee := raceFee(race)
+			if units, ok := prices[race.ID]; ok {
+				fee.Units = int64(units)
+			}
+			if cheapest == nil || fee.Units < cheapest.Units {
+				cheapest = &fee
+			}
+		}
+		if cheapest != nil {
+			vm.Price = priceLabel(*cheapest, Money.FormatCompact)
+		}
+		vm.Registered = registered[e.ID]
+		vm.RegistrationClosesAt = latestClose(closes)
+		vms = append(vms, vm)
+	}
+	return vms
+}
+
+// NewEventStatsViewModels builds listing view models at now from events
+// already totalled across their races by the database, as
+// NewEventListViewModels does from the races themselves.
+func NewEventStatsViewModels(rows []db.ListEventsWithStatsRow, now time.Time) []EventViewModel {
+	vms := make([]EventViewModel, 0, len(rows))
+	for _, row := range rows {
+		vm := NewEventViewModel(row.Event)
+		vm.Now = now
+		vm.Capacity = int(row.TotalCapacity)
+		vm.Registered = int(row.Registered)
+		if row.RaceCount > 0 {
+			currency := defaultCurrency
+			if row.PriceCurrency.Valid && row.PriceCurrency.String != "" {
+				currency = row.PriceCurrency.String
+			}
+			vm.Price = priceLabel(Money{Units: int64(row.MinPriceUnits.Int32), Currency: currency}, Money.FormatCompact)
+		}
+		if row.RegistrationClosesAt.Valid {
+			vm.RegistrationClosesAt = row.RegistrationClosesAt.Time
+		}
+		vms = append(vms, vm)
+	}
+	return vms
+}
+
+// ShowDistancesIn writes the race lengths in unit, the reader's preference.
+// The event's distance spans its shortest race to its longest.
+func (e *EventViewModel) ShowDistancesIn(unit db.DistanceUnit) {
+	for i, race := range e.Races {
+		if race.Length > 0 {
+			e.Races[i].Distance = race.Length.Format(unit)
+		}
+	}
+	if len(e.Lengths) == 0 {
+		return
+	}
+	shortest, longest := slices.Min(e.Lengths), slices.Max(e.Lengths)
+	e.Distance = shortest.Format(unit)
+	if longest != shortest {
+		e.Distance += " to " + longest.Format(unit)
+	}
+}
+
+// HasDistance reports whether any of the event's races is in bucket
+func (e EventViewModel) HasDistance(bucket DistanceBucket) bool {
+	return slices.ContainsFunc(e.Lengths, func(d Distance) bool {
+		b, ok := BucketOf(d)
+		return ok && b == bucket
+	})
 }
 
-// FormattedDate returns the date in a human-readable format
-func (e EventViewModel) FormattedDate() string {
-	return e.Date.Format("2 January 2006")
+// latestClose returns the latest of the races' close dates. A zero date is a
+// race without one, which keeps the event open indefinitely, so the result
+// is zero.
+func latestClose(closes []time.Time) time.Time {
+	var latest time.Time
+	for _, closesAt := range closes {
+		if closesAt.IsZero() {
+			return time.Time{}
+		}
+		if closesAt.After(latest) {
+			latest = closesAt
+		}
+	}
+	return latest
+}
+
+// FormattedDate returns the date in a human-readable format, in t's
+// language
+func (e EventViewModel) FormattedDate(t *i18n.Translator) string {
+	if e.Date.IsZero() {
+		return t.T("date.tbc")
+	}
+	return t.Date(e.Date, i18n.DateLong)
 }
 
 // FormattedDay returns just the day number
 func (e EventViewModel) FormattedDay() string {
+	if e.Date.IsZero() {
+		return "--"
+	}
 	return e.Date.Format("02")
 }
 
-// FormattedMonth returns the abbreviated month
-func (e EventViewModel) FormattedMonth() string {
-	return e.Date.Format("Jan")
+// FormattedMonth returns the abbreviated month, in t's language
+func (e EventViewModel) FormattedMonth(t *i18n.Translator) string {
+	if e.Date.IsZero() {
+		return t.T("date.tbc_short")
+	}
+	return t.Date(e.Date, i18n.MonthShort)
+}
+
+// URL returns the event's public page
+func (e EventViewModel) URL() string {
+	return EventURL(e.Year, e.Slug)
+}
+
+// AvailabilityStreamURL returns the stream of how full the event's races
+// are, which the page listens to
+func (e EventViewModel) AvailabilityStreamURL() string {
+	return e.URL() + "/availability/stream"
+}
+
+// ContactURL returns the form asking the event's organisers a question
+func (e EventViewModel) ContactURL() string {
+	return ContactURL(e.Year, e.Slug)
 }
 
 // FormattedYear returns the year
@@ -52,9 +481,9 @@ func (e EventViewModel) FormattedYear() string {
 	return e.Date.Format("2006")
 }
 
-// SpotsRemaining returns the number of spots left
+// SpotsRemaining returns the number of spots left, never less than zero
 func (e EventViewModel) SpotsRemaining() int {
-	return e.Capacity - e.Registered
+	return max(e.Capacity-e.Registered, 0)
 }
 
 // RegistrationPercentage returns how full the event is
@@ -65,154 +494,64 @@ func (e EventViewModel) RegistrationPercentage() int {
 	return (e.Registered * 100) / e.Capacity
 }
 
-// GetMockEvents returns sample events for UI mockup
-func GetMockEvents() []EventViewModel {
-	return []EventViewModel{
-		{
-			Slug:        "peak-district-ultra-2026",
-			Name:        "Peak District Ultra",
-			Date:        time.Date(2026, 4, 18, 8, 0, 0, 0, time.UTC),
-			Location:    "Castleton, Peak District",
-			ImageURL:    "https://images.unsplash.com/photo-1551632811-561732d1e306?w=600&h=400&fit=crop",
-			RaceType:    "Ultra Marathon",
-			Distance:    "50K",
-			Description: "Experience the breathtaking beauty of the Peak District on this challenging 50K ultra marathon. Wind through limestone valleys, climb iconic peaks, and test your limits on some of the finest trails in England.",
-			Organizer:   "Peak Running Co",
-			Price:       "£65",
-			Capacity:    500,
-			Registered:  342,
-			MapURL:      "https://images.unsplash.com/photo-1524661135-423995f22d0b?w=800&h=400&fit=crop",
-			Photos: []string{
-				"https://images.unsplash.com/photo-1551632811-561732d1e306?w=800&h=600&fit=crop",
-				"https://images.unsplash.com/photo-1469395446868-fb6a048d5ca3?w=800&h=600&fit=crop",
-				"https://images.unsplash.com/photo-1483728642387-6c3bdd6c93e5?w=800&h=600&fit=crop",
-			},
-			Races: []RaceViewModel{
-				{Name: "Ultra 50K", Distance: "50K", Price: "£65", StartTime: "06:00", Capacity: 300, Registered: 245, Description: "The main event - a challenging 50K route through the heart of the Peak District."},
-				{Name: "Marathon", Distance: "42K", Price: "£55", StartTime: "07:00", Capacity: 200, Registered: 97, Description: "A full marathon distance covering the most scenic sections of the course."},
-			},
-		},
-		{
-			Slug:        "lake-district-trail-run",
-			Name:        "Lake District Trail Run",
-			Date:        time.Date(2026, 5, 9, 9, 0, 0, 0, time.UTC),
-			Location:    "Ambleside, Lake District",
-			ImageURL:    "https://images.unsplash.com/photo-1571104508999-893933ded431?w=600&h=400&fit=crop",
-			RaceType:    "Trail Run",
-			Distance:    "Half Marathon",
-			Description: "A stunning half marathon through the Lake District National Park. Run alongside crystal-clear lakes, through ancient woodlands, and past iconic fells.",
-			Organizer:   "Lakes Events",
-			Price:       "£45",
-			Capacity:    750,
-			Registered:  512,
-			MapURL:      "https://images.unsplash.com/photo-1524661135-423995f22d0b?w=800&h=400&fit=crop",
-			Photos: []string{
-				"https://images.unsplash.com/photo-1571104508999-893933ded431?w=800&h=600&fit=crop",
-				"https://images.unsplash.com/photo-1501785888041-af3ef285b470?w=800&h=600&fit=crop",
-			},
-			Races: []RaceViewModel{
-				{Name: "Half Marathon", Distance: "21K", Price: "£45", StartTime: "09:00", Capacity: 500, Registered: 389, Description: "The flagship half marathon with challenging ascents and incredible views."},
-				{Name: "10K Fun Run", Distance: "10K", Price: "£25", StartTime: "10:30", Capacity: 250, Registered: 123, Description: "A scenic 10K perfect for beginners and families."},
-			},
-		},
-		{
-			Slug:        "yorkshire-three-peaks",
-			Name:        "Yorkshire Three Peaks Challenge",
-			Date:        time.Date(2026, 6, 14, 7, 0, 0, 0, time.UTC),
-			Location:    "Horton-in-Ribblesdale, Yorkshire",
-			ImageURL:    "https://images.unsplash.com/photo-1464822759023-fed622ff2c3b?w=600&h=400&fit=crop",
-			RaceType:    "Fell Race",
-			Distance:    "24 miles",
-			Description: "Conquer the legendary Yorkshire Three Peaks in this iconic fell race. Summit Pen-y-ghent, Whernside, and Ingleborough in under 12 hours.",
-			Organizer:   "Yorkshire Trails",
-			Price:       "£50",
-			Capacity:    600,
-			Registered:  598,
-			MapURL:      "https://images.unsplash.com/photo-1524661135-423995f22d0b?w=800&h=400&fit=crop",
-			Photos: []string{
-				"https://images.unsplash.com/photo-1464822759023-fed622ff2c3b?w=800&h=600&fit=crop",
-				"https://images.unsplash.com/photo-1500534623283-312aade485b7?w=800&h=600&fit=crop",
-			},
-			Races: []RaceViewModel{
-				{Name: "Three Peaks Challenge", Distance: "24 miles", Price: "£50", StartTime: "07:00", Capacity: 600, Registered: 598, Description: "The classic Three Peaks route with a 12-hour cutoff."},
-			},
-		},
-		{
-			Slug:        "cotswolds-spring-10k",
-			Name:        "Cotswolds Spring 10K",
-			Date:        time.Date(2026, 3, 22, 10, 0, 0, 0, time.UTC),
-			Location:    "Bourton-on-the-Water, Cotswolds",
-			ImageURL:    "https://images.unsplash.com/photo-1508739773434-c26b3d09e071?w=600&h=400&fit=crop",
-			RaceType:    "Road Race",
-			Distance:    "10K",
-			Description: "A beautiful spring road race through picturesque Cotswold villages. Rolling hills, honey-stone cottages, and country lanes await.",
-			Organizer:   "Cotswold Running Club",
-			Price:       "£28",
-			Capacity:    400,
-			Registered:  156,
-			MapURL:      "https://images.unsplash.com/photo-1524661135-423995f22d0b?w=800&h=400&fit=crop",
-			Photos: []string{
-				"https://images.unsplash.com/photo-1508739773434-c26b3d09e071?w=800&h=600&fit=crop",
-			},
-			Races: []RaceViewModel{
-				{Name: "10K Race", Distance: "10K", Price: "£28", StartTime: "10:00", Capacity: 300, Registered: 112, Description: "A fast and scenic 10K through the Cotswolds countryside."},
-				{Name: "5K Fun Run", Distance: "5K", Price: "£15", StartTime: "11:30", Capacity: 100, Registered: 44, Description: "A family-friendly 5K suitable for all abilities."},
-			},
-		},
-		{
-			Slug:        "snowdonia-marathon",
-			Name:        "Snowdonia Marathon",
-			Date:        time.Date(2026, 10, 24, 8, 0, 0, 0, time.UTC),
-			Location:    "Llanberis, Snowdonia",
-			ImageURL:    "https://images.unsplash.com/photo-1506905925346-21bda4d32df4?w=600&h=400&fit=crop",
-			RaceType:    "Mountain Marathon",
-			Distance:    "Marathon",
-			Description: "One of the most scenic and challenging marathons in the UK. Run beneath the shadow of Snowdon through spectacular Welsh mountain scenery.",
-			Organizer:   "Welsh Mountain Events",
-			Price:       "£58",
-			Capacity:    2000,
-			Registered:  1456,
-			MapURL:      "https://images.unsplash.com/photo-1524661135-423995f22d0b?w=800&h=400&fit=crop",
-			Photos: []string{
-				"https://images.unsplash.com/photo-1506905925346-21bda4d32df4?w=800&h=600&fit=crop",
-				"https://images.unsplash.com/photo-1519904981063-b0cf448d479e?w=800&h=600&fit=crop",
-			},
-			Races: []RaceViewModel{
-				{Name: "Full Marathon", Distance: "42.2K", Price: "£58", StartTime: "08:00", Capacity: 1500, Registered: 1123, Description: "The flagship Snowdonia Marathon with stunning mountain views."},
-				{Name: "Half Marathon", Distance: "21.1K", Price: "£38", StartTime: "09:30", Capacity: 500, Registered: 333, Description: "A challenging half marathon through the Snowdonia foothills."},
-			},
-		},
-		{
-			Slug:        "south-downs-way-50",
-			Name:        "South Downs Way 50",
-			Date:        time.Date(2026, 7, 11, 6, 0, 0, 0, time.UTC),
-			Location:    "Worthing, West Sussex",
-			ImageURL:    "https://images.unsplash.com/photo-1551632811-561732d1e306?w=600&h=400&fit=crop",
-			RaceType:    "Ultra Marathon",
-			Distance:    "50 miles",
-			Description: "Follow the ancient South Downs Way on this epic 50-mile ultra. Chalk grassland, coastal views, and Iron Age hill forts make this a truly memorable race.",
-			Organizer:   "Centurion Running",
-			Price:       "£95",
-			Capacity:    350,
-			Registered:  298,
-			MapURL:      "https://images.unsplash.com/photo-1524661135-423995f22d0b?w=800&h=400&fit=crop",
-			Photos: []string{
-				"https://images.unsplash.com/photo-1551632811-561732d1e306?w=800&h=600&fit=crop",
-			},
-			Races: []RaceViewModel{
-				{Name: "50 Mile Ultra", Distance: "50 miles", Price: "£95", StartTime: "06:00", Capacity: 350, Registered: 298, Description: "The full 50-mile route along the South Downs Way."},
-			},
-		},
-	}
-}
-
-// GetMockEvent returns a single mock event by slug
-func GetMockEvent(slug string) *EventViewModel {
-	events := GetMockEvents()
-	for _, e := range events {
-		if e.Slug == slug {
-			return &e
+// Badge flags an event's urgency on listings
+type Badge int
+
+// Event badges, in the order they are shown
+const (
+	BadgeSoldOut Badge = iota
+	BadgeAlmostFull
+	BadgeClosingSoon
+)
+
+// Badge thresholds
+const (
+	almostFullPercent = 90
+	closingSoonWindow = 7 * 24 * time.Hour
+)
+
+// Badges returns the urgency badges for the event at Now. An event is sold
+// out once every place across its races is taken, and almost full above 90%
+// of them; one with no capacity is neither. It is closing soon within seven
+// days of its last race closing to entries. A sold-out event carries no other
+// badge.
+func (e EventViewModel) Badges() []Badge {
+	var badges []Badge
+	if e.Capacity > 0 {
+		switch {
+		case e.Registered >= e.Capacity:
+			return []Badge{BadgeSoldOut}
+		case e.Registered*100 > e.Capacity*almostFullPercent:
+			badges = append(badges, BadgeAlmostFull)
 		}
 	}
-	return nil
+	if !e.RegistrationClosesAt.IsZero() && e.Now.Before(e.RegistrationClosesAt) &&
+		e.RegistrationClosesAt.Sub(e.Now) <= closingSoonWindow {
+		badges = append(badges, BadgeClosingSoon)
+	}
+	return badges
+}
+
+// Label returns the text shown on the badge
+func (b Badge) Label() string {
+	switch b {
+	case BadgeSoldOut:
+		return "Sold out"
+	case BadgeAlmostFull:
+		return "Almost full"
+	default:
+		return "Closing soon"
+	}
+}
+
+// Name returns a stable name for the badge, for use in markup
+func (b Badge) Name() string {
+	switch b {
+	case BadgeSoldOut:
+		return "sold-out"
+	case BadgeAlmostFull:
+		return "almost-full"
+	default:
+		return "closing-soon"
+	}
 }
@@ -0,0 +1,370 @@
+package viewmodels
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/i18n"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestNewRaceViewModel(t *testing.T) {
+	opens := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	closes := time.Date(2026, time.June, 1, 23, 59, 0, 0, time.UTC)
+	race := db.Race{
+		Slug:                  "10k",
+		Name:                  "10K",
+		MaxCapacity:           100,
+		PriceUnits:            pgtype.Int4{Int32: 2500, Valid: true},
+		Currency:              pgtype.Text{String: "GBP", Valid: true},
+		RegistrationOpenDate:  pgtype.Timestamptz{Time: opens, Valid: true},
+		RegistrationCloseDate: pgtype.Timestamptz{Time: closes, Valid: true},
+	}
+
+	tests := []struct {
+		name       string
+		registered int
+		now        time.Time
+		wantState  RaceState
+		wantLabel  string
+	}{
+		{name: "just before opening", now: opens.Add(-time.Nanosecond), wantState: RaceStateNotYetOpen, wantLabel: "Opens on 1 March 2026"},
+		{name: "at the open instant", now: opens, wantState: RaceStateOpen, wantLabel: "Register"},
+		{name: "just before closing", now: closes.Add(-time.Nanosecond), wantState: RaceStateOpen, wantLabel: "Register"},
+		{name: "at the close instant", now: closes, wantState: RaceStateClosed, wantLabel: "Closed"},
+		{name: "one place left", registered: 99, now: opens, wantState: RaceStateOpen, wantLabel: "Register"},
+		{name: "count equals capacity", registered: 100, now: opens, wantState: RaceStateSoldOut, wantLabel: "Sold out"},
+		{name: "count over capacity", registered: 101, now: opens, wantState: RaceStateSoldOut, wantLabel: "Sold out"},
+		{name: "full after closing", registered: 100, now: closes, wantState: RaceStateClosed, wantLabel: "Closed"},
+		{name: "full before opening", registered: 100, now: opens.Add(-time.Hour), wantState: RaceStateNotYetOpen, wantLabel: "Opens on 1 March 2026"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewRaceViewModel(race, tt.registered, tt.now)
+			if vm.State != tt.wantState {
+				t.Errorf("State = %v, want %v", vm.State, tt.wantState)
+			}
+			if got := vm.ActionLabel(); got != tt.wantLabel {
+				t.Errorf("ActionLabel() = %q, want %q", got, tt.wantLabel)
+			}
+			if got := vm.CanRegister(); got != (tt.wantState == RaceStateOpen) {
+				t.Errorf("CanRegister() = %v in state %v", got, vm.State)
+			}
+		})
+	}
+
+	t.Run("open without a registration window", func(t *testing.T) {
+		vm := NewRaceViewModel(db.Race{MaxCapacity: 10}, 0, opens)
+		if vm.State != RaceStateOpen {
+			t.Errorf("State = %v, want %v", vm.State, RaceStateOpen)
+		}
+		if vm.Price != "Free" {
+			t.Errorf("Price = %q, want %q", vm.Price, "Free")
+		}
+	})
+
+	t.Run("formats price", func(t *testing.T) {
+		vm := NewRaceViewModel(race, 0, opens)
+		if vm.Price != "£25.00" {
+			t.Errorf("Price = %q, want %q", vm.Price, "£25.00")
+		}
+	})
+}
+
+func TestRaceViewModelSetPrice(t *testing.T) {
+	race := db.Race{PriceUnits: pgtype.Int4{Int32: 7500, Valid: true}}
+	until := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	next := int32(7500)
+
+	tests := []struct {
+		name       string
+		next       *int32
+		until      time.Time
+		placesLeft int
+		wantNote   string
+	}{
+		{name: "no change to come", wantNote: ""},
+		{name: "until a date", next: &next, until: until, wantNote: "until 1 March, then £75.00"},
+		{name: "for the tier's places", next: &next, placesLeft: 12, wantNote: "for the next 12 entries, then £75.00"},
+		{name: "for the last place", next: &next, placesLeft: 1, wantNote: "for the next entry, then £75.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewRaceViewModel(race, 0, until)
+			vm.SetPrice(6500, tt.next, tt.until, tt.placesLeft)
+			if vm.Price != "£65.00" {
+				t.Errorf("Price = %q, want %q", vm.Price, "£65.00")
+			}
+			if vm.PriceNote != tt.wantNote {
+				t.Errorf("PriceNote = %q, want %q", vm.PriceNote, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestNewEventDetailViewModel(t *testing.T) {
+	now := time.Date(2026, time.April, 1, 12, 0, 0, 0, time.UTC)
+	early := time.Date(2026, time.July, 4, 9, 0, 0, 0, time.UTC)
+	late := time.Date(2026, time.July, 5, 10, 30, 0, 0, time.UTC)
+
+	races := []RaceViewModel{
+		NewRaceViewModel(db.Race{
+			Slug:        "marathon",
+			MaxCapacity: 200,
+			PriceUnits:  pgtype.Int4{Int32: 6500, Valid: true},
+			StartsAt:    pgtype.Timestamptz{Time: late, Valid: true},
+		}, 150, now),
+		NewRaceViewModel(db.Race{
+			Slug:        "10k",
+			MaxCapacity: 100,
+			PriceUnits:  pgtype.Int4{Int32: 2500, Valid: true},
+			StartsAt:    pgtype.Timestamptz{Time: early, Valid: true},
+		}, 100, now),
+	}
+
+	vm := NewEventDetailViewModel(db.Event{Name: "Lakeside Weekend", Slug: "lakeside"}, races, now)
+
+	if len(vm.Races) != 2 {
+		t.Fatalf("expected 2 races, got %d", len(vm.Races))
+	}
+	if !vm.Date.Equal(early) {
+		t.Errorf("Date = %v, want %v", vm.Date, early)
+	}
+	if vm.Capacity != 300 || vm.Registered != 250 {
+		t.Errorf("Capacity/Registered = %d/%d, want 300/250", vm.Capacity, vm.Registered)
+	}
+	if vm.Price != "£25.00" {
+		t.Errorf("Price = %q, want %q", vm.Price, "£25.00")
+	}
+	if vm.Races[1].StartTime != "09:00" {
+		t.Errorf("StartTime = %q, want %q", vm.Races[1].StartTime, "09:00")
+	}
+}
+
+func TestEventViewModelBadges(t *testing.T) {
+	now := time.Date(2026, time.May, 1, 12, 0, 0, 0, time.UTC)
+	closes := func(d time.Duration) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: now.Add(d), Valid: true}
+	}
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		races      []db.Race
+		registered int
+		want       []Badge
+	}{
+		{
+			name:       "open with plenty of room",
+			races:      []db.Race{{MaxCapacity: 100, RegistrationCloseDate: closes(30 * day)}},
+			registered: 50,
+		},
+		{
+			name:       "sold out when every place is taken",
+			races:      []db.Race{{MaxCapacity: 100}, {MaxCapacity: 50}},
+			registered: 150,
+			want:       []Badge{BadgeSoldOut},
+		},
+		{
+			name:       "sold out hides closing soon",
+			races:      []db.Race{{MaxCapacity: 100, RegistrationCloseDate: closes(day)}},
+			registered: 100,
+			want:       []Badge{BadgeSoldOut},
+		},
+		{
+			name:       "almost full above 90%",
+			races:      []db.Race{{MaxCapacity: 100}},
+			registered: 91,
+			want:       []Badge{BadgeAlmostFull},
+		},
+		{
+			name:       "not almost full at exactly 90%",
+			races:      []db.Race{{MaxCapacity: 100}},
+			registered: 90,
+		},
+		{
+			name:  "zero-capacity races are never sold out",
+			races: []db.Race{{MaxCapacity: 0}},
+		},
+		{
+			name:       "zero-capacity race beside a full one",
+			races:      []db.Race{{MaxCapacity: 0}, {MaxCapacity: 20}},
+			registered: 20,
+			want:       []Badge{BadgeSoldOut},
+		},
+		{
+			name:  "no races",
+			races: nil,
+		},
+		{
+			name:  "closing soon within seven days of the latest close",
+			races: []db.Race{{MaxCapacity: 100, RegistrationCloseDate: closes(2 * day)}, {MaxCapacity: 100, RegistrationCloseDate: closes(7 * day)}},
+			want:  []Badge{BadgeClosingSoon},
+		},
+		{
+			name:  "not closing soon while a later race stays open",
+			races: []db.Race{{MaxCapacity: 100, RegistrationCloseDate: closes(2 * day)}, {MaxCapacity: 100, RegistrationCloseDate: closes(8 * day)}},
+		},
+		{
+			name:  "no closing badge without a close date",
+			races: []db.Race{{MaxCapacity: 100, RegistrationCloseDate: closes(day)}, {MaxCapacity: 100}},
+		},
+		{
+			name:  "no closing badge once closed",
+			races: []db.Race{{MaxCapacity: 100, RegistrationCloseDate: closes(-time.Minute)}},
+		},
+		{
+			name:       "almost full and closing soon",
+			races:      []db.Race{{MaxCapacity: 100, RegistrationCloseDate: closes(3 * day)}},
+			registered: 95,
+			want:       []Badge{BadgeAlmostFull, BadgeClosingSoon},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := db.Event{ID: 1}
+			vms := NewEventListViewModels([]db.Event{event}, map[int64][]db.Race{1: tt.races}, nil, map[int64]int{1: tt.registered}, now)
+			if got := vms[0].Badges(); !slices.Equal(got, tt.want) {
+				t.Errorf("listing Badges() = %v, want %v", got, tt.want)
+			}
+
+			races := make([]RaceViewModel, 0, len(tt.races))
+			remaining := tt.registered
+			for _, race := range tt.races {
+				registered := min(remaining, int(race.MaxCapacity))
+				remaining -= registered
+				races = append(races, NewRaceViewModel(race, registered, now))
+			}
+			if got := NewEventDetailViewModel(event, races, now).Badges(); !slices.Equal(got, tt.want) {
+				t.Errorf("detail Badges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEventListViewModelsPrice(t *testing.T) {
+	now := time.Date(2026, time.April, 1, 12, 0, 0, 0, time.UTC)
+	price := func(units int32) pgtype.Int4 { return pgtype.Int4{Int32: units, Valid: true} }
+	euros := pgtype.Text{String: "EUR", Valid: true}
+
+	tests := []struct {
+		name   string
+		races  []db.Race
+		prices map[int64]int32
+		want   string
+	}{
+		{name: "cheapest race in compact form", races: []db.Race{{PriceUnits: price(6500)}, {PriceUnits: price(4500)}}, want: "£45"},
+		{name: "keeps pence", races: []db.Race{{PriceUnits: price(2550)}}, want: "£25.50"},
+		{name: "in the race's currency", races: []db.Race{{PriceUnits: price(3000), Currency: euros}}, want: "€30"},
+		{name: "free when any race is free", races: []db.Race{{PriceUnits: price(6500)}, {}}, want: "Free"},
+		{name: "no price without races", want: ""},
+		{name: "at the current tier's price", races: []db.Race{{ID: 7, PriceUnits: price(7500)}, {ID: 8, PriceUnits: price(7000)}}, prices: map[int64]int32{7: 6500}, want: "£65"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vms := NewEventListViewModels([]db.Event{{ID: 1}}, map[int64][]db.Race{1: tt.races}, tt.prices, nil, now)
+			if vms[0].Price != tt.want {
+				t.Errorf("Price = %q, want %q", vms[0].Price, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEventStatsViewModels(t *testing.T) {
+	now := time.Date(2026, time.May, 1, 12, 0, 0, 0, time.UTC)
+	closes := pgtype.Timestamptz{Time: now.Add(3 * 24 * time.Hour), Valid: true}
+
+	tests := []struct {
+		name string
+		row  db.ListEventsWithStatsRow
+		want EventViewModel
+	}{
+		{
+			name: "totals across races",
+			row: db.ListEventsWithStatsRow{
+				RaceCount: 2, TotalCapacity: 500, Registered: 480,
+				MinPriceUnits:        pgtype.Int4{Int32: 4500, Valid: true},
+				RegistrationClosesAt: closes,
+			},
+			want: EventViewModel{Capacity: 500, Registered: 480, Price: "£45", RegistrationClosesAt: closes.Time},
+		},
+		{
+			name: "in the cheapest race's currency",
+			row: db.ListEventsWithStatsRow{
+				RaceCount: 1, TotalCapacity: 100,
+				MinPriceUnits: pgtype.Int4{Int32: 3000, Valid: true},
+				PriceCurrency: pgtype.Text{String: "EUR", Valid: true},
+			},
+			want: EventViewModel{Capacity: 100, Price: "€30"},
+		},
+		{
+			name: "free when the cheapest race is",
+			row:  db.ListEventsWithStatsRow{RaceCount: 1, TotalCapacity: 100, MinPriceUnits: pgtype.Int4{Valid: true}},
+			want: EventViewModel{Capacity: 100, Price: "Free"},
+		},
+		{
+			name: "no price or capacity without races",
+			row:  db.ListEventsWithStatsRow{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewEventStatsViewModels([]db.ListEventsWithStatsRow{tt.row}, now)[0]
+			if vm.Capacity != tt.want.Capacity || vm.Registered != tt.want.Registered || vm.Price != tt.want.Price {
+				t.Errorf("Capacity/Registered/Price = %d/%d/%q, want %d/%d/%q",
+					vm.Capacity, vm.Registered, vm.Price, tt.want.Capacity, tt.want.Registered, tt.want.Price)
+			}
+			if !vm.RegistrationClosesAt.Equal(tt.want.RegistrationClosesAt) {
+				t.Errorf("RegistrationClosesAt = %v, want %v", vm.RegistrationClosesAt, tt.want.RegistrationClosesAt)
+			}
+		})
+	}
+
+	t.Run("badges match those built from the races", func(t *testing.T) {
+		races := []db.Race{{MaxCapacity: 300, RegistrationCloseDate: closes}, {MaxCapacity: 200, RegistrationCloseDate: closes}}
+		fromRaces := NewEventListViewModels([]db.Event{{ID: 1}}, map[int64][]db.Race{1: races}, nil, map[int64]int{1: 480}, now)[0]
+		fromStats := NewEventStatsViewModels([]db.ListEventsWithStatsRow{{
+			Event: db.Event{ID: 1}, RaceCount: 2, TotalCapacity: 500, Registered: 480,
+			MinPriceUnits: pgtype.Int4{Valid: true}, RegistrationClosesAt: closes,
+		}}, now)[0]
+		if got, want := fromStats.Badges(), fromRaces.Badges(); !slices.Equal(got, want) || len(want) == 0 {
+			t.Errorf("Badges() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestEventViewModelFormattedDate(t *testing.T) {
+	translations := i18n.Must(i18n.New(nil))
+	event := EventViewModel{Date: time.Date(2026, time.August, 1, 9, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		locale    string
+		event     EventViewModel
+		wantDate  string
+		wantMonth string
+	}{
+		{locale: i18n.EnglishGB, event: event, wantDate: "1 August 2026", wantMonth: "Aug"},
+		{locale: i18n.Welsh, event: event, wantDate: "1 Awst 2026", wantMonth: "Awst"},
+		{locale: i18n.EnglishGB, wantDate: "Date to be confirmed", wantMonth: "TBC"},
+		{locale: i18n.Welsh, wantDate: "Dyddiad i'w gadarnhau", wantMonth: "I'w gad."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.wantDate, func(t *testing.T) {
+			tr := translations.Translator(tt.locale)
+			if got := tt.event.FormattedDate(tr); got != tt.wantDate {
+				t.Errorf("expected date %q, got %q", tt.wantDate, got)
+			}
+			if got := tt.event.FormattedMonth(tr); got != tt.wantMonth {
+				t.Errorf("expected month %q, got %q", tt.wantMonth, got)
+			}
+		})
+	}
+}
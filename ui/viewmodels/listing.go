@@ -0,0 +1,129 @@
+package viewmodels
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// EventListingViewModel represents one year of the event listing
+type EventListingViewModel struct {
+	Year int32
+	// IncludePast reports whether events that have closed for registration
+	// are listed too
+	IncludePast bool
+	// Distance is the bucket events are filtered to, or empty for every
+	// distance
+	Distance DistanceBucket
+	Years    []YearTab
+	// Distances links to the listing filtered to each bucket, after a link
+	// to every distance
+	Distances []DistanceTab
+	Events    []EventViewModel
+}
+
+// YearTab links to one year of the event listing
+type YearTab struct {
+	Year   int32
+	URL    string
+	Active bool
+}
+
+// DistanceTab links to the event listing filtered to one distance bucket
+type DistanceTab struct {
+	Label  string
+	URL    string
+	Active bool
+}
+
+// NewEventListingViewModel builds the listing for year, with a tab for each
+// of years, of the events with a race in bucket, or of every event when
+// bucket is empty. The tabs keep the choice of whether past events are
+// shown and of the distance.
+func NewEventListingViewModel(year int32, years []int32, includePast bool, bucket DistanceBucket, events []EventViewModel) EventListingViewModel {
+	vm := EventListingViewModel{
+		Year:        year,
+		IncludePast: includePast,
+		Distance:    bucket,
+		Events:      events,
+	}
+	if bucket != "" {
+		vm.Events = slices.DeleteFunc(slices.Clone(events), func(e EventViewModel) bool {
+			return !e.HasDistance(bucket)
+		})
+	}
+	for _, y := range years {
+		vm.Years = append(vm.Years, YearTab{
+			Year:   y,
+			URL:    EventListingURL(y, includePast, bucket),
+			Active: y == year,
+		})
+	}
+	vm.Distances = append(vm.Distances, DistanceTab{
+		Label:  "Any distance",
+		URL:    EventListingURL(year, includePast, ""),
+		Active: bucket == "",
+	})
+	for _, b := range DistanceBuckets {
+		vm.Distances = append(vm.Distances, DistanceTab{
+			Label:  b.Label(),
+			URL:    EventListingURL(year, includePast, b),
+			Active: b == bucket,
+		})
+	}
+	return vm
+}
+
+// YearLabel returns the listed year for display
+func (l EventListingViewModel) YearLabel() string {
+	return strconv.Itoa(int(l.Year))
+}
+
+// TogglePastURL returns the URL of the same year with past events shown if
+// they are hidden, or hidden if they are shown
+func (l EventListingViewModel) TogglePastURL() string {
+	return EventListingURL(l.Year, !l.IncludePast, l.Distance)
+}
+
+// EmptyLabel returns what is said when no events are listed
+func (l EventListingViewModel) EmptyLabel() string {
+	if l.Distance != "" {
+		return "No events of that distance are open for " + l.YearLabel() + "."
+	}
+	return "No events are open for " + l.YearLabel() + "."
+}
+
+// Label returns the tab's year for display
+func (t YearTab) Label() string {
+	return strconv.Itoa(int(t.Year))
+}
+
+// EventListingURL returns the URL listing the year's events, of every
+// distance when bucket is empty
+func EventListingURL(year int32, includePast bool, bucket DistanceBucket) string {
+	q := url.Values{"year": {strconv.Itoa(int(year))}}
+	if includePast {
+		q.Set("include_past", "1")
+	}
+	if bucket != "" {
+		q.Set("distance", string(bucket))
+	}
+	return "/events?" + q.Encode()
+}
+
+// EventArchiveViewModel represents past events grouped by year, latest year
+// first
+type EventArchiveViewModel struct {
+	Years []ArchiveYear
+}
+
+// ArchiveYear is one year of past events in the archive
+type ArchiveYear struct {
+	Year   int32
+	Events []EventViewModel
+}
+
+// Label returns the year for display
+func (a ArchiveYear) Label() string {
+	return strconv.Itoa(int(a.Year))
+}
@@ -0,0 +1,156 @@
+package viewmodels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"firecrest/db"
+)
+
+// defaultCurrency is assumed for prices stored without a currency
+const defaultCurrency = "GBP"
+
+// Money is an amount in minor currency units, such as pence or cents
+type Money struct {
+	Units    int64
+	Currency string
+}
+
+// currencySymbols maps ISO 4217 codes to their display symbol
+var currencySymbols = map[string]string{
+	"GBP": "£",
+	"EUR": "€",
+	"USD": "$",
+}
+
+// currencyDecimals lists the currencies whose minor unit is not a
+// hundredth of the major one
+var currencyDecimals = map[string]int{
+	"ISK": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// raceFee returns the entry fee of race. A race without a price is free.
+func raceFee(race db.Race) Money {
+	currency := defaultCurrency
+	if race.Currency.Valid && race.Currency.String != "" {
+		currency = race.Currency.String
+	}
+	return Money{Units: int64(race.PriceUnits.Int32), Currency: currency}
+}
+
+// RaceCurrency returns the currency race is priced in
+func RaceCurrency(race db.Race) string {
+	return raceFee(race).Currency
+}
+
+// Format formats the amount with its currency symbol and the currency's
+// decimal places, e.g. "£1,234.50". Currencies without a symbol are written
+// as a code after the amount, e.g. "1,000.00 CHF".
+func (m Money) Format() string {
+	return m.format(false)
+}
+
+// FormatCompact formats the amount like Format but leaves off the minor
+// units when there are none, e.g. "£65" but "£65.50", for listing cards.
+func (m Money) FormatCompact() string {
+	return m.format(true)
+}
+
+// String formats the amount as Format does
+func (m Money) String() string {
+	return m.Format()
+}
+
+// Amount writes the amount in major units with the currency's decimal
+// places but without a symbol or separators, e.g. "1234.50", for machines
+// to read
+func (m Money) Amount() string {
+	units := m.Units
+	sign := ""
+	if units < 0 {
+		sign, units = "-", -units
+	}
+	decimals := decimalPlaces(m.Currency)
+	s := strconv.FormatInt(units, 10)
+	if decimals == 0 {
+		return sign + s
+	}
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	return sign + s[:len(s)-decimals] + "." + s[len(s)-decimals:]
+}
+
+func (m Money) format(compact bool) string {
+	units := m.Units
+	sign := ""
+	if units < 0 {
+		sign, units = "-", -units
+	}
+
+	decimals := decimalPlaces(m.Currency)
+	scale := int64(1)
+	for range decimals {
+		scale *= 10
+	}
+	amount := groupThousands(units / scale)
+	if minor := units % scale; decimals > 0 && (minor != 0 || !compact) {
+		amount += fmt.Sprintf(".%0*d", decimals, minor)
+	}
+
+	if symbol, ok := currencySymbols[m.Currency]; ok {
+		return sign + symbol + amount
+	}
+	return sign + amount + " " + m.Currency
+}
+
+// ParseMoney reads an amount typed in major units, such as "5" or "5.50",
+// in the currency. It rejects negative amounts and more decimal places than
+// the currency has.
+func ParseMoney(text, currency string) (Money, error) {
+	text = strings.TrimSpace(text)
+	decimals := decimalPlaces(currency)
+	whole, minor, _ := strings.Cut(text, ".")
+	if whole == "" || len(minor) > decimals || strings.Trim(whole+minor, "0123456789") != "" {
+		return Money{}, fmt.Errorf("invalid amount %q", text)
+	}
+	units, err := strconv.ParseInt(whole+minor+strings.Repeat("0", decimals-len(minor)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", text)
+	}
+	return Money{Units: units, Currency: currency}, nil
+}
+
+// decimalPlaces returns how many digits the currency's minor units take
+func decimalPlaces(currency string) int {
+	if decimals, ok := currencyDecimals[currency]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// priceLabel returns fee written by format, or "Free" when there is nothing
+// to pay.
+func priceLabel(fee Money, format func(Money) string) string {
+	if fee.Units <= 0 {
+		return "Free"
+	}
+	return format(fee)
+}
+
+// groupThousands formats n with comma thousands separators
+func groupThousands(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
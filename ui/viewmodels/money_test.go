@@ -0,0 +1,90 @@
+package viewmodels
+
+import (
+	"testing"
+
+	"firecrest/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		money       Money
+		want        string
+		wantCompact string
+	}{
+		{money: Money{Units: 0, Currency: "GBP"}, want: "£0.00", wantCompact: "£0"},
+		{money: Money{Units: 6500, Currency: "GBP"}, want: "£65.00", wantCompact: "£65"},
+		{money: Money{Units: 6550, Currency: "GBP"}, want: "£65.50", wantCompact: "£65.50"},
+		{money: Money{Units: 4000, Currency: "EUR"}, want: "€40.00", wantCompact: "€40"},
+		{money: Money{Units: 123456789, Currency: "EUR"}, want: "€1,234,567.89", wantCompact: "€1,234,567.89"},
+		{money: Money{Units: 2505, Currency: "USD"}, want: "$25.05", wantCompact: "$25.05"},
+		{money: Money{Units: 150000, Currency: "USD"}, want: "$1,500.00", wantCompact: "$1,500"},
+		{money: Money{Units: 100000, Currency: "CHF"}, want: "1,000.00 CHF", wantCompact: "1,000 CHF"},
+		{money: Money{Units: 5000, Currency: "JPY"}, want: "5,000 JPY", wantCompact: "5,000 JPY"},
+		{money: Money{Units: 12500, Currency: "KWD"}, want: "12.500 KWD", wantCompact: "12.500 KWD"},
+		{money: Money{Units: -350, Currency: "GBP"}, want: "-£3.50", wantCompact: "-£3.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.money.Format(); got != tt.want {
+				t.Errorf("Money%+v.Format() = %q, want %q", tt.money, got, tt.want)
+			}
+			if got := tt.money.FormatCompact(); got != tt.wantCompact {
+				t.Errorf("Money%+v.FormatCompact() = %q, want %q", tt.money, got, tt.wantCompact)
+			}
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("Money%+v.String() = %q, want %q", tt.money, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyAmount(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: Money{Units: 0, Currency: "GBP"}, want: "0.00"},
+		{money: Money{Units: 5, Currency: "GBP"}, want: "0.05"},
+		{money: Money{Units: 123456789, Currency: "EUR"}, want: "1234567.89"},
+		{money: Money{Units: 5000, Currency: "JPY"}, want: "5000"},
+		{money: Money{Units: 12500, Currency: "KWD"}, want: "12.500"},
+		{money: Money{Units: -350, Currency: "GBP"}, want: "-3.50"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Amount(); got != tt.want {
+			t.Errorf("Money%+v.Amount() = %q, want %q", tt.money, got, tt.want)
+		}
+	}
+}
+
+func TestRacePrice(t *testing.T) {
+	tests := []struct {
+		name        string
+		race        db.Race
+		want        string
+		wantCompact string
+	}{
+		{name: "no price", race: db.Race{}, want: "Free", wantCompact: "Free"},
+		{name: "zero price", race: db.Race{PriceUnits: pgtype.Int4{Valid: true}, Currency: pgtype.Text{String: "EUR", Valid: true}}, want: "Free", wantCompact: "Free"},
+		{name: "no currency", race: db.Race{PriceUnits: pgtype.Int4{Int32: 2500, Valid: true}}, want: "£25.00", wantCompact: "£25"},
+		{name: "euros", race: db.Race{PriceUnits: pgtype.Int4{Int32: 3550, Valid: true}, Currency: pgtype.Text{String: "EUR", Valid: true}}, want: "€35.50", wantCompact: "€35.50"},
+		{name: "dollars", race: db.Race{PriceUnits: pgtype.Int4{Int32: 4000, Valid: true}, Currency: pgtype.Text{String: "USD", Valid: true}}, want: "$40.00", wantCompact: "$40"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee := raceFee(tt.race)
+			if got := priceLabel(fee, Money.Format); got != tt.want {
+				t.Errorf("price = %q, want %q", got, tt.want)
+			}
+			if got := priceLabel(fee, Money.FormatCompact); got != tt.wantCompact {
+				t.Errorf("compact price = %q, want %q", got, tt.wantCompact)
+			}
+		})
+	}
+}
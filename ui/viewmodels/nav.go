@@ -0,0 +1,72 @@
+package viewmodels
+
+import (
+	"context"
+
+	"firecrest/db"
+)
+
+// NavViewModel holds what the site header needs to know about the viewer
+type NavViewModel struct {
+	SignedIn bool
+	Name     string
+	// ImpersonatedBy names the admin acting as the user, if any, for the
+	// banner telling them so
+	ImpersonatedBy string
+	// Email is the signed-in user's, shown in the impersonation banner
+	Email string
+	// EmailUnverified reports that the user signed in within the grace
+	// period without verifying their email, for the banner asking them to
+	EmailUnverified bool
+	// Organisations lists the organisations an admin page can switch
+	// between, or is nil outside the admin area and for users with only
+	// one. It is shared so that a handler switching organisation part way
+	// through a request updates the header it renders.
+	Organisations *OrganisationSwitcher
+}
+
+// OrganisationSwitcher holds the admin header's organisation select
+type OrganisationSwitcher struct {
+	CurrentID     int64
+	Organisations []OrganisationOption
+}
+
+// IsCurrent reports whether the given organisation is the one admin pages
+// are scoped to
+func (s OrganisationSwitcher) IsCurrent(id int64) bool {
+	return s.CurrentID == id
+}
+
+// SwitchOrganisationURL is where the header's organisation select posts to
+const SwitchOrganisationURL = "/admin/organisations/switch"
+
+// NewNavViewModel builds the header for a signed-in user
+func NewNavViewModel(user db.User) NavViewModel {
+	return NavViewModel{SignedIn: true, Name: user.FirstName}
+}
+
+// NewImpersonationNavViewModel builds the header for an admin acting as
+// user
+func NewImpersonationNavViewModel(user, admin db.User) NavViewModel {
+	nav := NewNavViewModel(user)
+	nav.ImpersonatedBy = admin.FirstName
+	nav.Email = user.Email
+	return nav
+}
+
+// StopImpersonatingURL is where the banner's button posts to end
+// impersonation
+const StopImpersonatingURL = "/admin/impersonation/stop"
+
+type navContextKey struct{}
+
+// WithNav returns a copy of ctx from which pages render nav in their header
+func WithNav(ctx context.Context, nav NavViewModel) context.Context {
+	return context.WithValue(ctx, navContextKey{}, nav)
+}
+
+// NavFromContext returns the header stored by WithNav, or a signed-out one
+func NavFromContext(ctx context.Context) NavViewModel {
+	nav, _ := ctx.Value(navContextKey{}).(NavViewModel)
+	return nav
+}
@@ -0,0 +1,150 @@
+package viewmodels
+
+import (
+	"strconv"
+	"strings"
+
+	"firecrest/db"
+)
+
+// MemberViewModel is one member of an organisation
+type MemberViewModel struct {
+	UserID int64
+	Name   string
+	Email  string
+	Role   db.OrganisationRole
+}
+
+// InvitationViewModel is an invitation that has not been accepted yet
+type InvitationViewModel struct {
+	Email string
+	Role  db.OrganisationRole
+}
+
+// MembersViewModel holds an organisation's members, its pending invitations
+// and the invite form
+type MembersViewModel struct {
+	OrganisationID   int64
+	OrganisationName string
+	Members          []MemberViewModel
+	Invitations      []InvitationViewModel
+	// Email and Role are the invite form's submitted values
+	Email string
+	Role  string
+	// ContactEmail is where questions about the organisation's events go,
+	// blank for its owners
+	ContactEmail string
+	// EmbedPublic and EmbedOrigins say which websites may show the
+	// availability widget, the origins one to a line
+	EmbedPublic  bool
+	EmbedOrigins string
+	// SiteURL roots the widget's script in the snippet organisers copy
+	SiteURL string
+	Errors  map[string]string
+}
+
+// NewMembersViewModel prepares the members page for an organisation
+func NewMembersViewModel(org db.Organisation, members []db.ListOrganisationMembersRow, invitations []db.ListPendingOrganisationInvitationsRow) MembersViewModel {
+	vm := MembersViewModel{
+		OrganisationID:   org.ID,
+		OrganisationName: org.Name,
+		Members:          make([]MemberViewModel, 0, len(members)),
+		Invitations:      make([]InvitationViewModel, 0, len(invitations)),
+		Role:             string(db.OrganisationRoleStaff),
+		ContactEmail:     org.ContactEmail.String,
+		EmbedPublic:      org.EmbedPublic,
+		EmbedOrigins:     strings.Join(org.EmbedOrigins, "\n"),
+	}
+	for _, m := range members {
+		name := strings.TrimSpace(m.FirstName + " " + m.LastName)
+		if name == "" {
+			name = m.Email
+		}
+		vm.Members = append(vm.Members, MemberViewModel{
+			UserID: m.UserID,
+			Name:   name,
+			Email:  m.Email,
+			Role:   m.Role,
+		})
+	}
+	for _, inv := range invitations {
+		vm.Invitations = append(vm.Invitations, InvitationViewModel{Email: inv.Email, Role: inv.Role})
+	}
+	return vm
+}
+
+// ActionURL returns the URL the invite form posts to
+func (vm MembersViewModel) ActionURL() string {
+	return "/admin/organisations/" + strconv.FormatInt(vm.OrganisationID, 10) + "/members"
+}
+
+// ContactEmailURL returns the URL the contact address form posts to
+func (vm MembersViewModel) ContactEmailURL() string {
+	return "/admin/organisations/" + strconv.FormatInt(vm.OrganisationID, 10) + "/contact"
+}
+
+// EmbeddingURL returns the URL the availability widget form posts to
+func (vm MembersViewModel) EmbeddingURL() string {
+	return "/admin/organisations/" + strconv.FormatInt(vm.OrganisationID, 10) + "/embedding"
+}
+
+// WidgetSnippet returns the markup organisers paste into their own pages
+// to show an event's availability, with a placeholder for its slug
+func (vm MembersViewModel) WidgetSnippet() string {
+	return `<div data-firecrest-availability="EVENT-SLUG"></div>` + "\n" +
+		`<script src="` + vm.SiteURL + `/static/js/availability-widget.js" defer></script>`
+}
+
+// RemoveURL returns the URL the form removing a member posts to
+func (vm MembersViewModel) RemoveURL(m MemberViewModel) string {
+	return vm.ActionURL() + "/" + strconv.FormatInt(m.UserID, 10) + "/remove"
+}
+
+// Error returns the validation error for a field, if any
+func (vm MembersViewModel) Error(field string) string {
+	return vm.Errors[field]
+}
+
+// RoleLabel returns the role as shown to organisers
+func RoleLabel(role db.OrganisationRole) string {
+	switch role {
+	case db.OrganisationRoleOwner:
+		return "Owner"
+	case db.OrganisationRoleAdmin:
+		return "Admin"
+	default:
+		return "Staff"
+	}
+}
+
+// CanRemove reports whether the member can be removed from the page. Owners
+// stay with their organisation.
+func (m MemberViewModel) CanRemove() bool {
+	return m.Role != db.OrganisationRoleOwner
+}
+
+// MembersURL returns the URL of the members page for the selected organisation
+func (d DashboardViewModel) MembersURL() string {
+	return "/admin/organisations/" + strconv.FormatInt(d.OrganisationID, 10) + "/members"
+}
+
+// NotificationsURL returns the URL the viewer's notification preference
+// for the selected organisation is posted to
+func (d DashboardViewModel) NotificationsURL() string {
+	return "/admin/organisations/" + strconv.FormatInt(d.OrganisationID, 10) + "/notifications"
+}
+
+// NotificationOption describes a way members can hear about new
+// registrations
+type NotificationOption struct {
+	Value db.NotificationPreference
+	Label string
+}
+
+// NotificationOptions lists the ways members can hear about new
+// registrations
+var NotificationOptions = []NotificationOption{
+	{Value: db.NotificationPreferenceImmediate, Label: "As each one comes in"},
+	{Value: db.NotificationPreferenceDailyDigest, Label: "In a daily digest"},
+	{Value: db.NotificationPreferenceNone, Label: "Never"},
+}
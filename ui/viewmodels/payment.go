@@ -0,0 +1,134 @@
+package viewmodels
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/service"
+)
+
+// PaymentDateLayout is how the payments report's date range is written,
+// in its form and URLs
+const PaymentDateLayout = "2006-01-02"
+
+// PaymentViewModel is one payment in the payments report
+type PaymentViewModel struct {
+	Date           string
+	Reference      string
+	RegistrationID int64
+	Entrant        string
+	Email          string
+	Event          string
+	Race           string
+	Status         db.PaymentStatus
+	Gross          Money
+	// Fee is what the payment provider kept, or nil when it did not say
+	Fee      *Money
+	Refunded Money
+	Net      Money
+}
+
+// StatusLabel returns a human-readable label for the payment's status
+func (p PaymentViewModel) StatusLabel() string {
+	switch p.Status {
+	case db.PaymentStatusRefunded:
+		return "Refunded"
+	case db.PaymentStatusPartiallyRefunded:
+		return "Partially refunded"
+	}
+	return "Paid"
+}
+
+// PaymentTotalsViewModel sums the report's payments in one currency
+type PaymentTotalsViewModel struct {
+	Currency string
+	Gross    Money
+	Fees     Money
+	Refunded Money
+	Net      Money
+}
+
+// PaymentsViewModel is an organisation's payments report over a range of
+// days, From to To inclusive
+type PaymentsViewModel struct {
+	OrganisationID   int64
+	OrganisationName string
+	From             string
+	To               string
+	Payments         []PaymentViewModel
+	Totals           []PaymentTotalsViewModel
+}
+
+// NewPaymentViewModel builds a PaymentViewModel from a report row
+func NewPaymentViewModel(row db.ListOrganisationPaymentsRow) PaymentViewModel {
+	money := func(units int64) Money { return Money{Units: units, Currency: row.Currency} }
+	p := PaymentViewModel{
+		Date:           row.CreatedAt.Time.UTC().Format(PaymentDateLayout),
+		Reference:      row.ProviderReference.String,
+		RegistrationID: row.RegistrationID,
+		Entrant:        listedName(row.FirstName, row.LastName, row.Email, row.AnonymisedAt.Valid),
+		Event:          row.EventName,
+		Race:           row.RaceName,
+		Status:         row.Status,
+		Gross:          money(int64(row.AmountUnits)),
+		Refunded:       money(int64(row.RefundedUnits)),
+		Net:            money(service.PaymentNet(row)),
+	}
+	if !row.AnonymisedAt.Valid {
+		p.Email = row.Email
+	}
+	if row.FeeUnits.Valid {
+		fee := money(int64(row.FeeUnits.Int32))
+		p.Fee = &fee
+	}
+	return p
+}
+
+// NewPaymentsViewModel prepares the payments report for an organisation.
+// until is the end of the range, exclusive, as the report was run.
+func NewPaymentsViewModel(org db.Organisation, from, until time.Time, report service.PaymentReport) PaymentsViewModel {
+	vm := PaymentsViewModel{
+		OrganisationID:   org.ID,
+		OrganisationName: org.Name,
+		From:             from.Format(PaymentDateLayout),
+		To:               until.AddDate(0, 0, -1).Format(PaymentDateLayout),
+		Payments:         make([]PaymentViewModel, 0, len(report.Payments)),
+	}
+	for _, row := range report.Payments {
+		vm.Payments = append(vm.Payments, NewPaymentViewModel(row))
+	}
+	for _, t := range report.Totals {
+		vm.Totals = append(vm.Totals, PaymentTotalsViewModel{
+			Currency: t.Currency,
+			Gross:    Money{Units: t.Gross, Currency: t.Currency},
+			Fees:     Money{Units: t.Fees, Currency: t.Currency},
+			Refunded: Money{Units: t.Refunded, Currency: t.Currency},
+			Net:      Money{Units: t.Net, Currency: t.Currency},
+		})
+	}
+	return vm
+}
+
+// PaymentsURL returns the URL of an organisation's payments report
+func PaymentsURL(organisationID int64) string {
+	return "/admin/organisations/" + strconv.FormatInt(organisationID, 10) + "/payments"
+}
+
+// ActionURL returns the URL the date range form submits to
+func (vm PaymentsViewModel) ActionURL() string {
+	return PaymentsURL(vm.OrganisationID)
+}
+
+// ExportURL returns the URL of the report's CSV export, over the same days
+func (vm PaymentsViewModel) ExportURL() string {
+	q := url.Values{"from": {vm.From}, "to": {vm.To}}
+	return PaymentsURL(vm.OrganisationID) + "/export?" + q.Encode()
+}
+
+// PaymentsURL returns the URL of the payments report for the selected
+// organisation
+func (d DashboardViewModel) PaymentsURL() string {
+	return PaymentsURL(d.OrganisationID)
+}
@@ -0,0 +1,77 @@
+package viewmodels
+
+import (
+	"strconv"
+
+	"firecrest/db"
+)
+
+// PhotoViewModel represents one of an event's photos on the admin page
+type PhotoViewModel struct {
+	ID     int64
+	URL    string
+	Width  int32
+	Height int32
+	// First and Last mark the ends of the gallery, which cannot move
+	// further that way
+	First bool
+	Last  bool
+}
+
+// EventPhotosViewModel holds an event's photo gallery and the upload form
+type EventPhotosViewModel struct {
+	EventID    int64
+	EventName  string
+	Photos     []PhotoViewModel
+	MaxPhotoMB int
+	// Error is the reason the last upload was refused, if any
+	Error string
+}
+
+// NewEventPhotosViewModel prepares the photos page for an event. urls holds
+// a viewable URL for each photo, in the same order.
+func NewEventPhotosViewModel(event db.Event, photos []db.EventPhoto, urls []string, maxBytes int64) EventPhotosViewModel {
+	vm := EventPhotosViewModel{
+		EventID:    event.ID,
+		EventName:  event.Name,
+		Photos:     make([]PhotoViewModel, 0, len(photos)),
+		MaxPhotoMB: int(maxBytes >> 20),
+	}
+	for i, photo := range photos {
+		vm.Photos = append(vm.Photos, PhotoViewModel{
+			ID:     photo.ID,
+			URL:    urls[i],
+			Width:  photo.Width,
+			Height: photo.Height,
+			First:  i == 0,
+			Last:   i == len(photos)-1,
+		})
+	}
+	return vm
+}
+
+// UploadURL returns the URL the upload form posts to
+func (vm EventPhotosViewModel) UploadURL() string {
+	return EventPhotosURL(vm.EventID)
+}
+
+// DeleteURL returns the URL that removes a photo
+func (vm EventPhotosViewModel) DeleteURL(p PhotoViewModel) string {
+	return EventPhotosURL(vm.EventID) + "/" + strconv.FormatInt(p.ID, 10) + "/delete"
+}
+
+// MoveURL returns the URL that moves a photo up or down the gallery
+func (vm EventPhotosViewModel) MoveURL(p PhotoViewModel) string {
+	return EventPhotosURL(vm.EventID) + "/" + strconv.FormatInt(p.ID, 10) + "/move"
+}
+
+// EventPhotosURL returns the URL of an event's photos page
+func EventPhotosURL(eventID int64) string {
+	return "/admin/events/" + strconv.FormatInt(eventID, 10) + "/photos"
+}
+
+// EventPhotoURL returns the public URL of one of an event's photos, which
+// redirects to the stored file
+func EventPhotoURL(year int32, slug string, photoID int64) string {
+	return EventURL(year, slug) + "/photos/" + strconv.FormatInt(photoID, 10)
+}
@@ -0,0 +1,360 @@
+package viewmodels
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"firecrest/db"
+	"firecrest/internal/i18n"
+)
+
+// RegistrationViewModel represents one of the user's registrations
+type RegistrationViewModel struct {
+	ID        int64
+	RaceName  string
+	EventName string
+	EventSlug string
+	EventYear int32
+	// Bib is the entrant's race number, once they have been given one.
+	// Cancelled entries give theirs up
+	Bib           string
+	StartsAt      time.Time
+	Status        db.RegistrationStatus
+	PaymentStatus db.NullPaymentStatus
+	CanCancel     bool
+	CanTransfer   bool
+	CanEdit       bool
+	// TransferPending is set when the registration was transferred to the
+	// user and they have not accepted it yet
+	TransferPending bool
+}
+
+// AccountRegistrationsViewModel groups the user's registrations by whether
+// their race is still to come
+type AccountRegistrationsViewModel struct {
+	Upcoming []RegistrationViewModel
+	Past     []RegistrationViewModel
+}
+
+// NewRegistrationViewModel builds a RegistrationViewModel from a database row
+func NewRegistrationViewModel(row db.ListRegistrationsByUserRow, canCancel, canTransfer, canEdit bool) RegistrationViewModel {
+	vm := RegistrationViewModel{
+		ID:              row.ID,
+		RaceName:        row.RaceName,
+		EventName:       row.EventName,
+		EventSlug:       row.EventSlug,
+		EventYear:       row.EventYear,
+		Status:          row.Status,
+		PaymentStatus:   row.PaymentStatus,
+		CanCancel:       canCancel,
+		CanTransfer:     canTransfer,
+		CanEdit:         canEdit,
+		TransferPending: row.TransferPending,
+	}
+	if row.Status != db.RegistrationStatusCancelled {
+		vm.Bib = row.Bib.String
+	}
+	if row.StartsAt.Valid {
+		vm.StartsAt = row.StartsAt.Time
+	}
+	return vm
+}
+
+// NewAccountRegistrationsViewModel splits registrations into upcoming and
+// past. A race starting any time today, or without a date yet, is upcoming.
+func NewAccountRegistrationsViewModel(regs []RegistrationViewModel, now time.Time) AccountRegistrationsViewModel {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	var vm AccountRegistrationsViewModel
+	for _, reg := range regs {
+		if reg.StartsAt.IsZero() || !reg.StartsAt.In(now.Location()).Before(today) {
+			vm.Upcoming = append(vm.Upcoming, reg)
+		} else {
+			vm.Past = append(vm.Past, reg)
+		}
+	}
+	return vm
+}
+
+// IsEmpty reports whether the user has no registrations at all
+func (a AccountRegistrationsViewModel) IsEmpty() bool {
+	return len(a.Upcoming) == 0 && len(a.Past) == 0
+}
+
+// FormattedDate returns the race date in a human-readable format, in t's
+// language
+func (r RegistrationViewModel) FormattedDate(t *i18n.Translator) string {
+	if r.StartsAt.IsZero() {
+		return t.T("date.tbc")
+	}
+	return t.Date(r.StartsAt, i18n.DateLongWeekday)
+}
+
+// EventURL returns the public URL for the registration's event
+func (r RegistrationViewModel) EventURL() string {
+	return EventURL(r.EventYear, r.EventSlug)
+}
+
+// CancelURL returns the endpoint that cancels the registration
+func (r RegistrationViewModel) CancelURL() string {
+	return "/account/registrations/" + strconv.FormatInt(r.ID, 10) + "/cancel"
+}
+
+// TransferURL returns the endpoint that transfers the registration to
+// another entrant
+func (r RegistrationViewModel) TransferURL() string {
+	return "/account/registrations/" + strconv.FormatInt(r.ID, 10) + "/transfer"
+}
+
+// EditURL returns the page the entrant changes their registration details
+// on
+func (r RegistrationViewModel) EditURL() string {
+	return RegistrationDetailsURL(r.ID)
+}
+
+// RegistrationDetailsURL returns the page the entrant changes the details
+// of their registration on
+func RegistrationDetailsURL(id int64) string {
+	return "/account/registrations/" + strconv.FormatInt(id, 10) + "/edit"
+}
+
+// AnchorID returns the id of the registration's row on the account page
+func (r RegistrationViewModel) AnchorID() string {
+	return registrationAnchorID(r.ID)
+}
+
+// AccountRegistrationURL returns the account page scrolled to the
+// registration, or the page itself when the registration is not known
+func AccountRegistrationURL(id int64) string {
+	if id == 0 {
+		return "/account/registrations"
+	}
+	return "/account/registrations#" + registrationAnchorID(id)
+}
+
+func registrationAnchorID(id int64) string {
+	return "registration-" + strconv.FormatInt(id, 10)
+}
+
+// TransferFieldID returns the id of the recipient email input for the
+// registration's transfer form
+func (r RegistrationViewModel) TransferFieldID() string {
+	return "transfer-email-" + strconv.FormatInt(r.ID, 10)
+}
+
+// StatusLabel returns the registration status for display
+func (r RegistrationViewModel) StatusLabel() string {
+	return registrationStatusLabel(r.Status)
+}
+
+// registrationStatusLabel returns a registration status for display
+func registrationStatusLabel(status db.RegistrationStatus) string {
+	switch status {
+	case db.RegistrationStatusConfirmed:
+		return "Confirmed"
+	case db.RegistrationStatusCancelled:
+		return "Cancelled"
+	default:
+		return "Pending"
+	}
+}
+
+// StatusVariant returns the badge variant for the registration status
+func (r RegistrationViewModel) StatusVariant() string {
+	switch r.Status {
+	case db.RegistrationStatusConfirmed:
+		return "success"
+	case db.RegistrationStatusCancelled:
+		return "destructive"
+	default:
+		return "secondary"
+	}
+}
+
+// PaymentLabel returns the payment status for display
+func (r RegistrationViewModel) PaymentLabel() string {
+	if !r.PaymentStatus.Valid {
+		return "Not paid"
+	}
+	switch r.PaymentStatus.PaymentStatus {
+	case db.PaymentStatusSucceeded:
+		return "Paid"
+	case db.PaymentStatusFailed:
+		return "Payment failed"
+	case db.PaymentStatusRefunded:
+		return "Refunded"
+	case db.PaymentStatusPartiallyRefunded:
+		return "Partially refunded"
+	default:
+		return "Payment pending"
+	}
+}
+
+// QuestionOption describes a question races can ask entrants when they
+// register
+type QuestionOption struct {
+	Value string
+	Label string
+	// Hint explains what is wanted when the label alone does not
+	Hint string
+	// Multiline questions take a paragraph rather than a line
+	Multiline bool
+	// MaxLength is the longest answer accepted, in characters
+	MaxLength int
+}
+
+// QuestionOptions lists the questions a race can require, in the order
+// forms ask them
+var QuestionOptions = []QuestionOption{
+	{Value: "emergency_contact_name", Label: "Emergency contact name", MaxLength: 100},
+	{Value: "emergency_contact_phone", Label: "Emergency contact phone", MaxLength: 100},
+	{Value: "medical_conditions", Label: "Medical conditions", Hint: `Anything the medical team should know, such as allergies, conditions or medication. Write "None" if there is nothing.`, Multiline: true, MaxLength: 2000},
+	{Value: "club", Label: "Club", MaxLength: 100},
+	{Value: "estimated_finish", Label: "Estimated finish time", Hint: "Hours and minutes, such as 4:30.", MaxLength: 100},
+}
+
+// QuestionField is one question of a race's questionnaire with the answer
+// given
+type QuestionField struct {
+	QuestionOption
+	Answer string
+	Error  string
+	// Optional questions may be left blank. Registration forms only ask
+	// required ones.
+	Optional bool
+	// Locked answers are shown but cannot be changed
+	Locked bool
+}
+
+// QuestionnaireViewModel holds the questions a race requires entrants to
+// answer before it takes their entry
+type QuestionnaireViewModel struct {
+	RaceName  string
+	EventName string
+	ActionURL string
+	// DiscountCode is the code the entrant gave before being asked the
+	// questions, carried through to their entry
+	DiscountCode string
+	// WaveID is the start wave the entrant chose, or zero
+	WaveID    int64
+	Questions []QuestionField
+	// MinAge is the youngest entrants may be on race day, or zero
+	MinAge int
+	// AskDateOfBirth is set when the race has a minimum age and the
+	// entrant has not given their date of birth
+	AskDateOfBirth   bool
+	DateOfBirth      string
+	DateOfBirthError string
+	// CustomQuestions are the race organisers' own questions, asked after
+	// the required ones
+	CustomQuestions []CustomQuestion
+}
+
+// CustomQuestion is a custom field on a race's entry form
+type CustomQuestion struct {
+	// Name is the name of the field's input
+	Name  string
+	Label string
+	// Kind is "text", "select" or "checkbox"
+	Kind     string
+	Options  []string
+	Required bool
+	Answer   string
+	Error    string
+}
+
+// NewQuestionnaireViewModel prepares the questionnaire of the race, asking
+// the required questions in form order. answers and errs, keyed by
+// question, fill in what was submitted.
+func NewQuestionnaireViewModel(race db.Race, event db.Event, required []string, answers, errs map[string]string) QuestionnaireViewModel {
+	vm := QuestionnaireViewModel{
+		RaceName:         race.Name,
+		EventName:        event.Name,
+		ActionURL:        RaceViewModel{Slug: race.Slug, EventSlug: event.Slug, EventYear: event.Year}.RegisterURL(),
+		MinAge:           int(race.MinAge.Int32),
+		DateOfBirthError: errs["date_of_birth"],
+	}
+	for _, q := range QuestionOptions {
+		if !slices.Contains(required, q.Value) {
+			continue
+		}
+		vm.Questions = append(vm.Questions, QuestionField{
+			QuestionOption: q,
+			Answer:         answers[q.Value],
+			Error:          errs[q.Value],
+		})
+	}
+	return vm
+}
+
+// HelpText returns the hint shown under the question, saying why locked
+// answers cannot be changed
+func (q QuestionField) HelpText() string {
+	if q.Locked {
+		return "The organisers fix this once your entry is paid for. Contact them to change it."
+	}
+	return q.Hint
+}
+
+// RegistrationDetailsViewModel holds the form entrants change the details
+// of their registration with
+type RegistrationDetailsViewModel struct {
+	ID        int64
+	RaceName  string
+	EventName string
+	Questions []QuestionField
+	// EditableUntil is when changes close, zero if the race has no date
+	EditableUntil time.Time
+}
+
+// NewRegistrationDetailsViewModel prepares the form asking the questions
+// in asked, in form order. Questions outside required may be left blank,
+// and those in locked are read-only. answers and errs are keyed by
+// question.
+func NewRegistrationDetailsViewModel(id int64, raceName, eventName string, asked, required, locked []string, answers, errs map[string]string, editableUntil time.Time) RegistrationDetailsViewModel {
+	vm := RegistrationDetailsViewModel{
+		ID:            id,
+		RaceName:      raceName,
+		EventName:     eventName,
+		EditableUntil: editableUntil,
+	}
+	for _, q := range QuestionOptions {
+		if !slices.Contains(asked, q.Value) {
+			continue
+		}
+		vm.Questions = append(vm.Questions, QuestionField{
+			QuestionOption: q,
+			Answer:         answers[q.Value],
+			Error:          errs[q.Value],
+			Optional:       !slices.Contains(required, q.Value),
+			Locked:         slices.Contains(locked, q.Value),
+		})
+	}
+	return vm
+}
+
+// ActionURL returns the URL the form posts to
+func (vm RegistrationDetailsViewModel) ActionURL() string {
+	return RegistrationDetailsURL(vm.ID)
+}
+
+// BackURL returns the account page scrolled to the registration
+func (vm RegistrationDetailsViewModel) BackURL() string {
+	return AccountRegistrationURL(vm.ID)
+}
+
+// FormattedEditableUntil returns when changes close for display, or empty
+// if they do not
+func (vm RegistrationDetailsViewModel) FormattedEditableUntil() string {
+	if vm.EditableUntil.IsZero() {
+		return ""
+	}
+	return vm.EditableUntil.Format("Mon 2 January 2006")
+}
+
+// HasLocked reports whether any answer is read-only
+func (vm RegistrationDetailsViewModel) HasLocked() bool {
+	return slices.ContainsFunc(vm.Questions, func(q QuestionField) bool { return q.Locked })
+}
@@ -0,0 +1,154 @@
+package viewmodels
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"firecrest/db"
+)
+
+// ResultViewModel is one runner's result
+type ResultViewModel struct {
+	Position   int
+	Name       string
+	Bib        string
+	Category   string
+	FinishTime string
+}
+
+// NewResultViewModels builds view models for results in the order given
+func NewResultViewModels(rows []db.ListRaceResultsRow) []ResultViewModel {
+	vms := make([]ResultViewModel, 0, len(rows))
+	for _, row := range rows {
+		vms = append(vms, ResultViewModel{
+			Position:   int(row.Position),
+			Name:       row.Name,
+			Bib:        row.Bib,
+			Category:   row.Category,
+			FinishTime: FormatFinishTime(int(row.FinishSeconds)),
+		})
+	}
+	return vms
+}
+
+// FormatFinishTime writes a finish time in seconds as HH:MM:SS
+func FormatFinishTime(seconds int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// ResultsPageViewModel is the public results table for a race
+type ResultsPageViewModel struct {
+	EventName string
+	EventSlug string
+	EventYear int32
+	RaceName  string
+	RaceSlug  string
+	// Sort is the column the results are ordered by, and Desc whether it
+	// runs backwards
+	Sort    string
+	Desc    bool
+	Search  string
+	Results []ResultViewModel
+}
+
+// ResultColumn is a sortable column of the results table
+type ResultColumn struct {
+	Key   string
+	Label string
+}
+
+// ResultColumns are the sortable columns in display order
+var ResultColumns = []ResultColumn{
+	{Key: "position", Label: "Position"},
+	{Key: "name", Label: "Name"},
+	{Key: "time", Label: "Time"},
+	{Key: "category", Label: "Category"},
+}
+
+// EventURL returns the public URL of the race's event
+func (p ResultsPageViewModel) EventURL() string {
+	return EventURL(p.EventYear, p.EventSlug)
+}
+
+// PageURL returns the URL of the results page
+func (p ResultsPageViewModel) PageURL() string {
+	return p.EventURL() + "/results/" + p.RaceSlug
+}
+
+// SortURL returns the URL that orders the results by column, reversing the
+// direction when it is already the sort column. The search is kept.
+func (p ResultsPageViewModel) SortURL(column string) string {
+	q := url.Values{"sort": {column}}
+	if column == p.Sort && !p.Desc {
+		q.Set("order", "desc")
+	}
+	if p.Search != "" {
+		q.Set("q", p.Search)
+	}
+	return p.PageURL() + "?" + q.Encode()
+}
+
+// AriaSort returns the aria-sort value for a column header
+func (p ResultsPageViewModel) AriaSort(column string) string {
+	switch {
+	case column != p.Sort:
+		return "none"
+	case p.Desc:
+		return "descending"
+	default:
+		return "ascending"
+	}
+}
+
+// AdminResultsViewModel holds the results upload form and the race's
+// staged or published results
+type AdminResultsViewModel struct {
+	RaceID    int64
+	RaceName  string
+	RaceSlug  string
+	EventName string
+	EventSlug string
+	EventYear int32
+	MaxRows   int
+	Published bool
+	Error     string
+	Errors    []ResultErrorViewModel
+	Results   []ResultViewModel
+}
+
+// ResultErrorViewModel is a row of a results upload that could not be saved
+type ResultErrorViewModel struct {
+	Line    int
+	Message string
+}
+
+// NewAdminResultsViewModel prepares the results page for a race
+func NewAdminResultsViewModel(race db.Race, event db.Event, results []db.ListRaceResultsRow, maxRows int) AdminResultsViewModel {
+	return AdminResultsViewModel{
+		RaceID:    race.ID,
+		RaceName:  race.Name,
+		RaceSlug:  race.Slug,
+		EventName: event.Name,
+		EventSlug: event.Slug,
+		EventYear: event.Year,
+		MaxRows:   maxRows,
+		Published: len(results) > 0 && results[0].Published,
+		Results:   NewResultViewModels(results),
+	}
+}
+
+// ActionURL returns the URL the upload form posts to
+func (f AdminResultsViewModel) ActionURL() string {
+	return "/admin/races/" + strconv.FormatInt(f.RaceID, 10) + "/results"
+}
+
+// PublishURL returns the URL the publish form posts to
+func (f AdminResultsViewModel) PublishURL() string {
+	return f.ActionURL() + "/publish"
+}
+
+// PublicURL returns the URL of the public results page
+func (f AdminResultsViewModel) PublicURL() string {
+	return EventURL(f.EventYear, f.EventSlug) + "/results/" + f.RaceSlug
+}
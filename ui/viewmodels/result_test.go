@@ -0,0 +1,41 @@
+package viewmodels
+
+import "testing"
+
+func TestFormatFinishTime(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{seconds: 0, want: "00:00:00"},
+		{seconds: 2050, want: "00:34:10"},
+		{seconds: 3723, want: "01:02:03"},
+		{seconds: 27*3600 + 59*60 + 59, want: "27:59:59"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatFinishTime(tt.seconds); got != tt.want {
+			t.Errorf("FormatFinishTime(%d) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestResultsPageViewModel_SortURL(t *testing.T) {
+	page := ResultsPageViewModel{EventSlug: "lincoln-10k", EventYear: 2026, RaceSlug: "10k", Sort: "time"}
+
+	if got, want := page.SortURL("time"), "/events/2026/lincoln-10k/results/10k?order=desc&sort=time"; got != want {
+		t.Errorf("SortURL(time) = %q, want %q", got, want)
+	}
+	if got, want := page.SortURL("name"), "/events/2026/lincoln-10k/results/10k?sort=name"; got != want {
+		t.Errorf("SortURL(name) = %q, want %q", got, want)
+	}
+
+	page.Desc = true
+	page.Search = "jane smith"
+	if got, want := page.SortURL("time"), "/events/2026/lincoln-10k/results/10k?q=jane+smith&sort=time"; got != want {
+		t.Errorf("SortURL(time) = %q, want %q", got, want)
+	}
+	if page.AriaSort("time") != "descending" || page.AriaSort("name") != "none" {
+		t.Errorf("unexpected aria-sort values %q and %q", page.AriaSort("time"), page.AriaSort("name"))
+	}
+}
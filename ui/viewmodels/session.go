@@ -0,0 +1,115 @@
+package viewmodels
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"firecrest/db"
+)
+
+// SessionViewModel represents one of the devices the user is signed in on
+type SessionViewModel struct {
+	ID         int64
+	Device     string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	// Current marks the session of the request rendering the page
+	Current bool
+}
+
+// SessionsViewModel holds the devices the user is signed in on
+type SessionsViewModel struct {
+	Sessions []SessionViewModel
+}
+
+// NewSessionsViewModel builds the page from the user's sessions, marking the
+// one with currentID as this device
+func NewSessionsViewModel(sessions []db.UserSession, currentID int64) SessionsViewModel {
+	vm := SessionsViewModel{Sessions: make([]SessionViewModel, 0, len(sessions))}
+	for _, session := range sessions {
+		s := SessionViewModel{
+			ID:        session.ID,
+			Device:    deviceSummary(session.UserAgent),
+			IPAddress: session.IpAddress,
+			Current:   session.ID == currentID,
+		}
+		if session.CreatedAt.Valid {
+			s.CreatedAt = session.CreatedAt.Time
+		}
+		if session.LastSeenAt.Valid {
+			s.LastSeenAt = session.LastSeenAt.Time
+		}
+		vm.Sessions = append(vm.Sessions, s)
+	}
+	return vm
+}
+
+// HasOthers reports whether the user is signed in on any other device
+func (vm SessionsViewModel) HasOthers() bool {
+	for _, s := range vm.Sessions {
+		if !s.Current {
+			return true
+		}
+	}
+	return false
+}
+
+// FormattedCreatedAt returns when the device signed in
+func (s SessionViewModel) FormattedCreatedAt() string {
+	return s.CreatedAt.Format("2 January 2006")
+}
+
+// LastActiveLabel returns when the device last made a request
+func (s SessionViewModel) LastActiveLabel() string {
+	return "Last active " + s.LastSeenAt.Format("2 January 2006 15:04")
+}
+
+// RevokeURL returns the URL the session is signed out through
+func (s SessionViewModel) RevokeURL() string {
+	return "/account/sessions/" + strconv.FormatInt(s.ID, 10) + "/revoke"
+}
+
+// deviceSummary describes the browser and operating system a user agent
+// string names, such as "Firefox on macOS". It only needs to be good enough
+// for a user to recognise their own devices.
+func deviceSummary(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	// Order matters: Edge and Opera also claim to be Chrome, and Chrome
+	// claims to be Safari
+	browser := "Unknown browser"
+	for _, b := range []struct{ marker, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"CriOS", "Chrome"},
+		{"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, b.marker) {
+			browser = b.name
+			break
+		}
+	}
+
+	// iOS and Android user agents also mention Mac OS X and Linux
+	for _, platform := range []struct{ marker, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"CrOS", "ChromeOS"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, platform.marker) {
+			return browser + " on " + platform.name
+		}
+	}
+	return browser
+}
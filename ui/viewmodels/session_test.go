@@ -0,0 +1,40 @@
+package viewmodels
+
+import (
+	"testing"
+
+	"firecrest/db"
+)
+
+func TestDeviceSummary(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{userAgent: "", want: "Unknown device"},
+		{userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:131.0) Gecko/20100101 Firefox/131.0", want: "Firefox on macOS"},
+		{userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36 Edg/129.0.0.0", want: "Edge on Windows"},
+		{userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 18_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Mobile/15E148 Safari/604.1", want: "Safari on iOS"},
+		{userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Mobile Safari/537.36", want: "Chrome on Android"},
+		{userAgent: "curl/8.5.0", want: "Unknown browser"},
+	}
+
+	for _, tt := range tests {
+		if got := deviceSummary(tt.userAgent); got != tt.want {
+			t.Errorf("deviceSummary(%q) = %q, want %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestNewSessionsViewModel(t *testing.T) {
+	vm := NewSessionsViewModel([]db.UserSession{{ID: 1}, {ID: 2}}, 2)
+	if vm.Sessions[0].Current || !vm.Sessions[1].Current {
+		t.Errorf("expected only session 2 to be current, got %+v", vm.Sessions)
+	}
+	if !vm.HasOthers() {
+		t.Error("expected another session")
+	}
+	if vm := NewSessionsViewModel([]db.UserSession{{ID: 2}}, 2); vm.HasOthers() {
+		t.Error("expected no other sessions")
+	}
+}
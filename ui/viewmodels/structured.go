@@ -0,0 +1,135 @@
+package viewmodels
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// schema.org availabilities an offer to enter a race may have
+const (
+	availabilityInStock    = "https://schema.org/InStock"
+	availabilityPreOrder   = "https://schema.org/PreOrder"
+	availabilitySoldOut    = "https://schema.org/SoldOut"
+	availabilityOutOfStock = "https://schema.org/OutOfStock"
+)
+
+// sportsEvent is the schema.org SportsEvent search engines read from an
+// event page
+type sportsEvent struct {
+	Context             string        `json:"@context"`
+	Type                string        `json:"@type"`
+	Name                string        `json:"name"`
+	URL                 string        `json:"url,omitempty"`
+	Image               string        `json:"image,omitempty"`
+	StartDate           string        `json:"startDate,omitempty"`
+	EventStatus         string        `json:"eventStatus"`
+	EventAttendanceMode string        `json:"eventAttendanceMode"`
+	Location            *place        `json:"location,omitempty"`
+	Organizer           *organization `json:"organizer,omitempty"`
+	Offers              []offer       `json:"offers,omitempty"`
+}
+
+type place struct {
+	Type    string         `json:"@type"`
+	Name    string         `json:"name"`
+	Address *postalAddress `json:"address,omitempty"`
+}
+
+// postalAddress holds an event's location as written by its organiser,
+// who gives it as one line rather than in parts
+type postalAddress struct {
+	Type          string `json:"@type"`
+	StreetAddress string `json:"streetAddress"`
+}
+
+type organization struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type offer struct {
+	Type          string `json:"@type"`
+	Name          string `json:"name"`
+	URL           string `json:"url,omitempty"`
+	Price         string `json:"price"`
+	PriceCurrency string `json:"priceCurrency"`
+	Availability  string `json:"availability"`
+	ValidFrom     string `json:"validFrom,omitempty"`
+}
+
+// StructuredData returns the event as schema.org SportsEvent JSON-LD, for
+// search engines to show as a rich result. Each race is offered at what an
+// entry costs now. Dates are in the event's time zone, and links are
+// rooted at SiteURL. The JSON escapes <, > and &, so it may be embedded in
+// a script element as it is.
+func (e EventViewModel) StructuredData() string {
+	doc := sportsEvent{
+		Context:             "https://schema.org",
+		Type:                "SportsEvent",
+		Name:                e.Name,
+		URL:                 e.SiteURL + e.URL(),
+		Image:               e.absoluteURL(e.ImageURL),
+		StartDate:           e.isoTime(e.Date),
+		EventStatus:         "https://schema.org/EventScheduled",
+		EventAttendanceMode: "https://schema.org/OfflineEventAttendanceMode",
+	}
+	if location := strings.TrimSpace(e.Location); location != "" {
+		doc.Location = &place{
+			Type:    "Place",
+			Name:    location,
+			Address: &postalAddress{Type: "PostalAddress", StreetAddress: location},
+		}
+	}
+	if e.Organizer != "" {
+		doc.Organizer = &organization{Type: "Organization", Name: e.Organizer}
+	}
+	for _, race := range e.Races {
+		o := offer{
+			Type:          "Offer",
+			Name:          race.Name,
+			URL:           doc.URL,
+			Price:         race.fee.Amount(),
+			PriceCurrency: race.fee.Currency,
+			Availability:  availabilityInStock,
+		}
+		switch race.State {
+		case RaceStateNotYetOpen:
+			o.Availability = availabilityPreOrder
+			o.ValidFrom = e.isoTime(race.OpensAt)
+		case RaceStateSoldOut:
+			o.Availability = availabilitySoldOut
+		case RaceStateClosed:
+			o.Availability = availabilityOutOfStock
+		}
+		doc.Offers = append(doc.Offers, o)
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		// Nothing in the document can fail to marshal
+		panic(err)
+	}
+	return string(b)
+}
+
+// isoTime writes t in ISO 8601 with the offset of the event's time zone,
+// or nothing when t is zero
+func (e EventViewModel) isoTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if e.TimeZone != nil {
+		t = t.In(e.TimeZone)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// absoluteURL roots a path of ours at SiteURL, leaving empty and absolute
+// URLs as they are
+func (e EventViewModel) absoluteURL(u string) string {
+	if strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") {
+		return e.SiteURL + u
+	}
+	return u
+}
@@ -0,0 +1,114 @@
+package viewmodels
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"firecrest/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares the JSON document with the golden file of the name,
+// both indented alike
+func checkGolden(t *testing.T, name, doc string) {
+	t.Helper()
+	var got bytes.Buffer
+	if err := json.Indent(&got, []byte(doc), "", "  "); err != nil {
+		t.Fatalf("invalid JSON %s: %v", doc, err)
+	}
+	got.WriteByte('\n')
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+			t.Fatalf("failed to update %s: %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("%s does not match; got:\n%s", path, got.String())
+	}
+}
+
+func TestEventStructuredData(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	race := func(name string, units int32, capacity int32, opens time.Time) db.Race {
+		r := db.Race{
+			Slug:        strings.ToLower(name),
+			Name:        name,
+			MaxCapacity: capacity,
+			PriceUnits:  pgtype.Int4{Int32: units, Valid: true},
+			StartsAt:    pgtype.Timestamptz{Time: time.Date(2026, 6, 14, 8, 30, 0, 0, time.UTC), Valid: true},
+		}
+		if !opens.IsZero() {
+			r.RegistrationOpenDate = pgtype.Timestamptz{Time: opens, Valid: true}
+		}
+		return r
+	}
+
+	t.Run("describes the event and offers each race", func(t *testing.T) {
+		event := NewEventDetailViewModel(
+			db.Event{Name: "Lincoln 10k", Slug: "lincoln-10k", Year: 2026, Location: "Castle Square, Lincoln LN1 3AA", ImageUrl: "/static/img/lincoln.jpg"},
+			[]RaceViewModel{
+				NewRaceViewModel(race("10K", 2500, 500, time.Time{}), 120, now),
+				NewRaceViewModel(race("Fun run", 0, 50, time.Time{}), 50, now),
+				NewRaceViewModel(race("Relay", 6000, 40, time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)), 0, now),
+			},
+			now,
+		)
+		event.Organizer = "Lincoln Harriers"
+		event.TimeZone = london
+		event.SiteURL = "https://firecrest.example"
+
+		checkGolden(t, "event.jsonld", event.StructuredData())
+	})
+
+	t.Run("leaves out what the event does not have", func(t *testing.T) {
+		event := NewEventDetailViewModel(db.Event{Name: "Fell race", Slug: "fell-race", Year: 2026}, nil, now)
+
+		checkGolden(t, "event-minimal.jsonld", event.StructuredData())
+	})
+
+	t.Run("cannot end the script element early", func(t *testing.T) {
+		event := NewEventDetailViewModel(db.Event{
+			Name:     `</script><script>alert(1)</script>`,
+			Slug:     "x",
+			Year:     2026,
+			Location: "<!-- & -->",
+		}, nil, now)
+
+		doc := event.StructuredData()
+
+		for _, unsafe := range []string{"<", ">", "&"} {
+			if strings.Contains(doc, unsafe) {
+				t.Errorf("expected %q escaped, got %s", unsafe, doc)
+			}
+		}
+		var decoded struct {
+			Name     string
+			Location struct{ Name string }
+		}
+		if err := json.Unmarshal([]byte(doc), &decoded); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if decoded.Name != event.Name || decoded.Location.Name != event.Location {
+			t.Errorf("expected the text to survive escaping, got %+v", decoded)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package viewmodels
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"firecrest/db"
+)
+
+// ScopeOption represents an API token scope in the create form
+type ScopeOption struct {
+	Value       string
+	Description string
+}
+
+// APITokenScopeOptions lists the scopes a token can be granted
+var APITokenScopeOptions = []ScopeOption{
+	{Value: "read:events", Description: "Read events and races"},
+	{Value: "read:entrants", Description: "Read entrant lists of races you manage"},
+}
+
+// ExpiryOption represents a choice of how long a new API token works for
+type ExpiryOption struct {
+	Days  string
+	Label string
+}
+
+// APITokenExpiryOptions lists how long a new token can work for. No days
+// means it never expires.
+var APITokenExpiryOptions = []ExpiryOption{
+	{Days: "30", Label: "30 days"},
+	{Days: "90", Label: "90 days"},
+	{Days: "365", Label: "1 year"},
+	{Days: "", Label: "Never"},
+}
+
+// DefaultAPITokenExpiryDays is the expiry the create form starts on
+const DefaultAPITokenExpiryDays = "90"
+
+// APITokenViewModel represents one of the user's API tokens
+type APITokenViewModel struct {
+	ID         int64
+	Label      string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+	Expired    bool
+}
+
+// APITokensViewModel holds the user's API tokens and the form to create one
+type APITokensViewModel struct {
+	Tokens []APITokenViewModel
+	// CreatedToken is the plaintext of a token just created. It is only
+	// ever shown on the page that created it
+	CreatedToken string
+	Label        string
+	Scopes       []string
+	ExpiresIn    string
+	Errors       map[string]string
+}
+
+// NewAPITokenViewModel builds an APITokenViewModel from a database row
+func NewAPITokenViewModel(token db.ApiToken, now time.Time) APITokenViewModel {
+	vm := APITokenViewModel{
+		ID:     token.ID,
+		Label:  token.Label,
+		Scopes: token.Scopes,
+	}
+	if token.CreatedAt.Valid {
+		vm.CreatedAt = token.CreatedAt.Time
+	}
+	if token.LastUsedAt.Valid {
+		vm.LastUsedAt = token.LastUsedAt.Time
+	}
+	if token.ExpiresAt.Valid {
+		vm.ExpiresAt = token.ExpiresAt.Time
+		vm.Expired = !now.Before(token.ExpiresAt.Time)
+	}
+	return vm
+}
+
+// NewAPITokensViewModel builds the page from the user's tokens, with an
+// empty create form
+func NewAPITokensViewModel(tokens []db.ApiToken, now time.Time) APITokensViewModel {
+	vm := APITokensViewModel{ExpiresIn: DefaultAPITokenExpiryDays}
+	for _, token := range tokens {
+		vm.Tokens = append(vm.Tokens, NewAPITokenViewModel(token, now))
+	}
+	return vm
+}
+
+// Error returns the validation error for a field, if any
+func (f APITokensViewModel) Error(field string) string {
+	return f.Errors[field]
+}
+
+// HasScope reports whether the form has the scope ticked
+func (f APITokensViewModel) HasScope(scope string) bool {
+	return slices.Contains(f.Scopes, scope)
+}
+
+// IsExpirySelected reports whether the given expiry is the current selection
+func (f APITokensViewModel) IsExpirySelected(days string) bool {
+	return f.ExpiresIn == days
+}
+
+// ScopesLabel lists the scopes the token was granted
+func (t APITokenViewModel) ScopesLabel() string {
+	return strings.Join(t.Scopes, ", ")
+}
+
+// FormattedCreatedAt returns when the token was created
+func (t APITokenViewModel) FormattedCreatedAt() string {
+	return t.CreatedAt.Format("2 January 2006")
+}
+
+// LastUsedLabel returns when the token was last used
+func (t APITokenViewModel) LastUsedLabel() string {
+	if t.LastUsedAt.IsZero() {
+		return "Never used"
+	}
+	return "Last used " + t.LastUsedAt.Format("2 January 2006 15:04")
+}
+
+// ExpiryLabel returns when the token stops working
+func (t APITokenViewModel) ExpiryLabel() string {
+	switch {
+	case t.ExpiresAt.IsZero():
+		return "Never expires"
+	case t.Expired:
+		return "Expired " + t.ExpiresAt.Format("2 January 2006")
+	default:
+		return "Expires " + t.ExpiresAt.Format("2 January 2006")
+	}
+}
+
+// RevokeURL returns the URL the token is revoked through
+func (t APITokenViewModel) RevokeURL() string {
+	return "/account/tokens/" + strconv.FormatInt(t.ID, 10) + "/revoke"
+}
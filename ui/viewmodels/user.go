@@ -0,0 +1,203 @@
+package viewmodels
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"firecrest/db"
+)
+
+// UserRowViewModel is one user on the admin users page, with how their
+// sign-ins are going
+type UserRowViewModel struct {
+	ID    int64
+	Name  string
+	Email string
+	Role  db.UserRole
+	// Deactivated is set when the account has been deactivated by an admin
+	Deactivated bool
+	// Self is set for the admin viewing the page, who cannot change their
+	// own role or deactivate themselves
+	Self                bool
+	FailedLoginAttempts int32
+	// LockedUntil is zero unless the account is locked out now
+	LockedUntil time.Time
+	LastLoginAt time.Time
+}
+
+// UsersViewModel holds a page of the admin users page, and the search and
+// role filter it was found with
+type UsersViewModel struct {
+	Query string
+	Role  db.UserRole
+	Users []UserRowViewModel
+	// Page is the page shown, counting from 1, of PerPage users out of the
+	// Total matching
+	Page    int
+	PerPage int
+	Total   int64
+}
+
+// NewUsersViewModel builds the page viewed by the admin with id viewerID
+// from the users matching query and role. A lock that ran out before now is
+// shown as unlocked, even if it has not been cleared yet. The caller fills
+// in the pagination.
+func NewUsersViewModel(query string, role db.UserRole, users []db.ListUsersRow, viewerID int64, now time.Time) UsersViewModel {
+	vm := UsersViewModel{
+		Query: query,
+		Role:  role,
+		Users: make([]UserRowViewModel, 0, len(users)),
+	}
+	for _, u := range users {
+		name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+		if name == "" {
+			name = u.Email
+		}
+		row := UserRowViewModel{
+			ID:                  u.ID,
+			Name:                name,
+			Email:               u.Email,
+			Role:                u.Role,
+			Deactivated:         u.DeactivatedAt.Valid,
+			Self:                u.ID == viewerID,
+			FailedLoginAttempts: u.FailedLoginAttempts.Int32,
+		}
+		if u.LockedUntil.Valid && u.LockedUntil.Time.After(now) {
+			row.LockedUntil = u.LockedUntil.Time
+		}
+		if u.LastLoginAt.Valid {
+			row.LastLoginAt = u.LastLoginAt.Time
+		}
+		vm.Users = append(vm.Users, row)
+	}
+	return vm
+}
+
+// LastPage returns the number of the last page with users on it
+func (vm UsersViewModel) LastPage() int {
+	if vm.PerPage < 1 || vm.Total == 0 {
+		return 1
+	}
+	return int((vm.Total + int64(vm.PerPage) - 1) / int64(vm.PerPage))
+}
+
+// PageLabel describes which page is shown, as "Page 2 of 5"
+func (vm UsersViewModel) PageLabel() string {
+	return "Page " + strconv.Itoa(vm.Page) + " of " + strconv.Itoa(vm.LastPage())
+}
+
+// PrevURL returns the URL of the page before, or an empty string on the
+// first page
+func (vm UsersViewModel) PrevURL() string {
+	if vm.Page <= 1 {
+		return ""
+	}
+	return vm.pageURL(vm.Page - 1)
+}
+
+// NextURL returns the URL of the page after, or an empty string on the
+// last page
+func (vm UsersViewModel) NextURL() string {
+	if vm.Page >= vm.LastPage() {
+		return ""
+	}
+	return vm.pageURL(vm.Page + 1)
+}
+
+func (vm UsersViewModel) pageURL(page int) string {
+	q := url.Values{}
+	if vm.Query != "" {
+		q.Set("q", vm.Query)
+	}
+	if vm.Role != "" {
+		q.Set("role", string(vm.Role))
+	}
+	if page > 1 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if len(q) == 0 {
+		return "/admin/users"
+	}
+	return "/admin/users?" + q.Encode()
+}
+
+// Locked reports whether the user is locked out of signing in
+func (u UserRowViewModel) Locked() bool {
+	return !u.LockedUntil.IsZero()
+}
+
+// CanUnlock reports whether unlocking would change anything for the user
+func (u UserRowViewModel) CanUnlock() bool {
+	return u.Locked() || u.FailedLoginAttempts > 0
+}
+
+// LockStatus returns whether the user can sign in, as shown to admins
+func (u UserRowViewModel) LockStatus() string {
+	if u.Deactivated {
+		return "Deactivated"
+	}
+	if u.Locked() {
+		return "Locked until " + u.LockedUntil.Format("2 January 2006 15:04")
+	}
+	return "Active"
+}
+
+// FailedAttemptsLabel returns how many sign-ins have failed since the last
+// successful one
+func (u UserRowViewModel) FailedAttemptsLabel() string {
+	return strconv.FormatInt(int64(u.FailedLoginAttempts), 10)
+}
+
+// LastLoginLabel returns when the user last signed in
+func (u UserRowViewModel) LastLoginLabel() string {
+	if u.LastLoginAt.IsZero() {
+		return "Never"
+	}
+	return u.LastLoginAt.Format("2 January 2006 15:04")
+}
+
+// UnlockURL returns the URL the form unlocking the user posts to
+func (u UserRowViewModel) UnlockURL() string {
+	return "/admin/users/" + strconv.FormatInt(u.ID, 10) + "/unlock"
+}
+
+// RoleURL returns the URL the form changing the user's role posts to
+func (u UserRowViewModel) RoleURL() string {
+	return "/admin/users/" + strconv.FormatInt(u.ID, 10) + "/role"
+}
+
+// ActiveURL returns the URL the form deactivating or reactivating the user
+// posts to
+func (u UserRowViewModel) ActiveURL() string {
+	return "/admin/users/" + strconv.FormatInt(u.ID, 10) + "/active"
+}
+
+// ImpersonateURL returns the URL the form starting to act as the user
+// posts to
+func (u UserRowViewModel) ImpersonateURL() string {
+	return "/admin/users/" + strconv.FormatInt(u.ID, 10) + "/impersonate"
+}
+
+// CanImpersonate reports whether the viewing admin may act as the user:
+// not themselves, another admin or a deactivated account
+func (u UserRowViewModel) CanImpersonate() bool {
+	return !u.Self && !u.Deactivated && u.Role != db.UserRoleAdmin
+}
+
+// UserRoles lists the site roles an admin can give a user, in the order
+// they are offered
+var UserRoles = []db.UserRole{db.UserRoleEntrant, db.UserRoleOrganizer, db.UserRoleAdmin}
+
+// UserRoleLabel returns the site role as shown to admins
+func UserRoleLabel(role db.UserRole) string {
+	switch role {
+	case db.UserRoleAdmin:
+		return "Admin"
+	case db.UserRoleOrganizer:
+		return "Organiser"
+	default:
+		return "Entrant"
+	}
+}
@@ -0,0 +1,198 @@
+package viewmodels
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"firecrest/db"
+)
+
+// WebhookEventOption represents an event type in the endpoint form
+type WebhookEventOption struct {
+	Value       string
+	Description string
+}
+
+// WebhookEventOptions lists the events an endpoint can subscribe to
+var WebhookEventOptions = []WebhookEventOption{
+	{Value: "registration.created", Description: "An entrant registers"},
+	{Value: "registration.cancelled", Description: "A registration is cancelled"},
+	{Value: "registration.transferred", Description: "An entrant transfers their place"},
+}
+
+// WebhookEndpointViewModel is one of an organisation's webhook endpoints
+type WebhookEndpointViewModel struct {
+	ID             int64
+	OrganisationID int64
+	URL            string
+	Events         []string
+	CreatedAt      string
+}
+
+// WebhooksViewModel holds an organisation's webhook endpoints and the form
+// to add one
+type WebhooksViewModel struct {
+	OrganisationID   int64
+	OrganisationName string
+	Endpoints        []WebhookEndpointViewModel
+	// CreatedSecret is the signing secret of an endpoint just created. It
+	// is only ever shown on the page that created it
+	CreatedSecret string
+	// URL and Events are the form's submitted values
+	URL    string
+	Events []string
+	Errors map[string]string
+}
+
+// NewWebhookEndpointViewModel builds a WebhookEndpointViewModel from a
+// database row
+func NewWebhookEndpointViewModel(endpoint db.WebhookEndpoint) WebhookEndpointViewModel {
+	return WebhookEndpointViewModel{
+		ID:             endpoint.ID,
+		OrganisationID: endpoint.OrganisationID,
+		URL:            endpoint.Url,
+		Events:         endpoint.EventTypes,
+		CreatedAt:      endpoint.CreatedAt.Time.Format("2 January 2006"),
+	}
+}
+
+// NewWebhooksViewModel prepares the webhooks page for an organisation, with
+// an empty form
+func NewWebhooksViewModel(org db.Organisation, endpoints []db.WebhookEndpoint) WebhooksViewModel {
+	vm := WebhooksViewModel{
+		OrganisationID:   org.ID,
+		OrganisationName: org.Name,
+		Endpoints:        make([]WebhookEndpointViewModel, 0, len(endpoints)),
+	}
+	for _, endpoint := range endpoints {
+		vm.Endpoints = append(vm.Endpoints, NewWebhookEndpointViewModel(endpoint))
+	}
+	return vm
+}
+
+// WebhooksURL returns the URL of an organisation's webhooks page
+func WebhooksURL(organisationID int64) string {
+	return "/admin/organisations/" + strconv.FormatInt(organisationID, 10) + "/webhooks"
+}
+
+// ActionURL returns the URL the endpoint form posts to
+func (vm WebhooksViewModel) ActionURL() string {
+	return WebhooksURL(vm.OrganisationID)
+}
+
+// Error returns the validation error for a field, if any
+func (vm WebhooksViewModel) Error(field string) string {
+	return vm.Errors[field]
+}
+
+// HasEvent reports whether the form has the event ticked
+func (vm WebhooksViewModel) HasEvent(event string) bool {
+	return slices.Contains(vm.Events, event)
+}
+
+// EventsLabel lists the events the endpoint subscribes to
+func (e WebhookEndpointViewModel) EventsLabel() string {
+	return strings.Join(e.Events, ", ")
+}
+
+// LogURL returns the URL of the endpoint's delivery log
+func (e WebhookEndpointViewModel) LogURL() string {
+	return WebhooksURL(e.OrganisationID) + "/" + strconv.FormatInt(e.ID, 10)
+}
+
+// DeleteURL returns the URL the form deleting the endpoint posts to
+func (e WebhookEndpointViewModel) DeleteURL() string {
+	return e.LogURL() + "/delete"
+}
+
+// WebhookAttemptViewModel is one attempt at a delivery
+type WebhookAttemptViewModel struct {
+	AttemptedAt string
+	// Outcome is the response's status, or the error if none came back
+	Outcome  string
+	Duration string
+}
+
+// WebhookDeliveryViewModel is one delivery in an endpoint's log
+type WebhookDeliveryViewModel struct {
+	ID        int64
+	Event     string
+	Status    db.WebhookDeliveryStatus
+	CreatedAt string
+	// NextAttemptAt is when a pending delivery is next tried
+	NextAttemptAt string
+	Attempts      []WebhookAttemptViewModel
+}
+
+// WebhookLogViewModel holds an endpoint's latest deliveries, newest first
+type WebhookLogViewModel struct {
+	OrganisationName string
+	Endpoint         WebhookEndpointViewModel
+	Deliveries       []WebhookDeliveryViewModel
+}
+
+// NewWebhookLogViewModel prepares the delivery log of an endpoint from its
+// deliveries and the attempts at them
+func NewWebhookLogViewModel(org db.Organisation, endpoint db.WebhookEndpoint, deliveries []db.WebhookDelivery, attempts []db.WebhookDeliveryAttempt) WebhookLogViewModel {
+	byDelivery := make(map[int64][]db.WebhookDeliveryAttempt, len(deliveries))
+	for _, a := range attempts {
+		byDelivery[a.DeliveryID] = append(byDelivery[a.DeliveryID], a)
+	}
+
+	vm := WebhookLogViewModel{
+		OrganisationName: org.Name,
+		Endpoint:         NewWebhookEndpointViewModel(endpoint),
+		Deliveries:       make([]WebhookDeliveryViewModel, 0, len(deliveries)),
+	}
+	for _, d := range deliveries {
+		delivery := WebhookDeliveryViewModel{
+			ID:        d.ID,
+			Event:     d.EventType,
+			Status:    d.Status,
+			CreatedAt: d.CreatedAt.Time.Format("2006-01-02 15:04:05"),
+		}
+		if d.Status == db.WebhookDeliveryStatusPending {
+			delivery.NextAttemptAt = d.NextAttemptAt.Time.Format("2006-01-02 15:04:05")
+		}
+		for _, a := range byDelivery[d.ID] {
+			outcome := a.Error
+			if a.StatusCode.Valid {
+				outcome = "HTTP " + strconv.Itoa(int(a.StatusCode.Int32))
+			}
+			delivery.Attempts = append(delivery.Attempts, WebhookAttemptViewModel{
+				AttemptedAt: a.AttemptedAt.Time.Format("2006-01-02 15:04:05"),
+				Outcome:     outcome,
+				Duration:    strconv.Itoa(int(a.DurationMs)) + " ms",
+			})
+		}
+		vm.Deliveries = append(vm.Deliveries, delivery)
+	}
+	return vm
+}
+
+// WebhooksURL returns the URL of the endpoint's organisation's webhooks
+// page
+func (vm WebhookLogViewModel) WebhooksURL() string {
+	return WebhooksURL(vm.Endpoint.OrganisationID)
+}
+
+// StatusLabel returns the delivery's status as shown to organisers
+func (d WebhookDeliveryViewModel) StatusLabel() string {
+	switch d.Status {
+	case db.WebhookDeliveryStatusDelivered:
+		return "Delivered"
+	case db.WebhookDeliveryStatusFailed:
+		return "Failed"
+	}
+	if len(d.Attempts) > 0 {
+		return "Retrying at " + d.NextAttemptAt
+	}
+	return "Pending"
+}
+
+// WebhooksURL returns the URL of the webhooks page for the selected
+// organisation
+func (d DashboardViewModel) WebhooksURL() string {
+	return WebhooksURL(d.OrganisationID)
+}